@@ -0,0 +1,145 @@
+// Package audit keeps the append-only log of sensitive changes: markets and
+// agents deleted, restored, purged and suspended, agents deactivated by
+// their owners, agents' personal data erased, markets resolved by the
+// council, proposals approved or rejected, platform parameters and staff
+// roles changed, reported content reviewed, admin jobs run.
+// Record writes an entry with the change, in its transaction, and takes
+// the actor from the context the database handle carries, so callers pass
+// db.WithContext(r.Context()) to have the caller of a request named.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// Actions recorded.
+const (
+	ActionMarketDeleted         = "market.deleted"
+	ActionMarketRestored        = "market.restored"
+	ActionMarketPurged          = "market.purged"
+	ActionAgentDeleted          = "agent.deleted"
+	ActionAgentRestored         = "agent.restored"
+	ActionAgentPurged           = "agent.purged"
+	ActionAgentSuspended        = "agent.suspended"
+	ActionAgentSuspensionLifted = "agent.suspension_lifted"
+	ActionAgentClaimCodeIssued  = "agent.claim_code_issued"
+	ActionAgentDeactivated      = "agent.deactivated"
+	ActionAgentErasureRequested = "agent.erasure_requested"
+	ActionAgentErasureCancelled = "agent.erasure_cancelled"
+	ActionAgentErased           = "agent.erased"
+	ActionBetsReset             = "bets.reset"
+	ActionMarketCouncilResolved = "market.council_resolved"
+	ActionProposalStatusChanged = "proposal.status_changed"
+	ActionParameterSet          = "parameter.set"
+	ActionParameterReset        = "parameter.reset"
+	ActionRoleGranted           = "role.granted"
+	ActionRoleRevoked           = "role.revoked"
+	ActionReservedNameCreated   = "reserved_name.created"
+	ActionReservedNameDeleted   = "reserved_name.deleted"
+	ActionAdminJobFinished      = "admin_job.finished"
+	ActionContentReviewed       = "content.reviewed"
+	ActionVerificationRuleSet   = "verification_rule.set"
+	ActionVerificationRuleReset = "verification_rule.reset"
+	ActionAnalyticsKeyGranted   = "read_key.analytics_granted"
+	ActionAnalyticsKeyRevoked   = "read_key.analytics_revoked"
+)
+
+// Actor is who made a change.
+type Actor struct {
+	ID   string // principal ID, e.g. "user:3"
+	Name string
+	Role string // staff role, if any
+}
+
+// System is the actor of changes no caller asked for, such as those made
+// by the scheduler.
+var System = Actor{ID: "system"}
+
+type actorKey struct{}
+
+// WithActor returns ctx naming actor as the one making changes.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor ctx names, or System.
+func ActorFrom(ctx context.Context) Actor {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+			return actor
+		}
+	}
+	return System
+}
+
+// Tracker notes whether a request recorded anything, so a generic entry can
+// be written for requests that did not.
+type Tracker struct {
+	recorded bool
+}
+
+type trackerKey struct{}
+
+// WithTracker returns ctx with a tracker that Record marks.
+func WithTracker(ctx context.Context) (context.Context, *Tracker) {
+	t := &Tracker{}
+	return context.WithValue(ctx, trackerKey{}, t), t
+}
+
+// Recorded reports whether Record was called with the tracker's context.
+func (t *Tracker) Recorded() bool { return t.recorded }
+
+// Target names an entity for an entry, e.g. Target("market", 12) is
+// "market:12".
+func Target(kind string, id interface{}) string {
+	return fmt.Sprintf("%s:%v", kind, id)
+}
+
+// Record writes an entry for action on target with its state before and
+// after, either of which may be nil for a target created or deleted. The
+// actor is taken from db's context.
+func Record(db *gorm.DB, action, target string, before, after interface{}) error {
+	ctx := db.Statement.Context
+	actor := ActorFrom(ctx)
+	entry := models.AuditLog{
+		Actor:     actor.ID,
+		ActorName: actor.Name,
+		Role:      actor.Role,
+		Action:    action,
+		Target:    target,
+		Before:    Snapshot(before),
+		After:     Snapshot(after),
+		CreatedAt: time.Now(),
+	}
+	if err := db.Create(&entry).Error; err != nil {
+		return fmt.Errorf("audit %s %s: %w", action, target, err)
+	}
+	if ctx != nil {
+		if t, ok := ctx.Value(trackerKey{}).(*Tracker); ok {
+			t.recorded = true
+		}
+	}
+	return nil
+}
+
+// Snapshot encodes v as JSON for an entry, or returns "" for nil.
+func Snapshot(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		return string(raw)
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return ""
+	}
+	return string(b)
+}
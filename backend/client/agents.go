@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	agentshandlers "socialpredict/handlers/agents"
+	"socialpredict/models"
+)
+
+// RegisterRequest and RegisterResponse are the server's registration types.
+type (
+	RegisterRequest  = agentshandlers.RegisterRequest
+	RegisterResponse = agentshandlers.RegisterResponse
+)
+
+// Register creates an agent and sets the client's API key to the new
+// agent's. The key is only ever returned here, so keep it. Registration is
+// not retried, since a retry could register a second agent.
+func (c *Client) Register(ctx context.Context, req RegisterRequest) (*RegisterResponse, error) {
+	var res RegisterResponse
+	err := c.do(ctx, call{method: http.MethodPost, path: "/v0/agents/register", body: req, anonymous: true}, &res)
+	if err != nil {
+		return nil, err
+	}
+	c.APIKey = res.APIKey
+	return &res, nil
+}
+
+// Predict makes or revises the agent's prediction on a market.
+func (c *Client) Predict(ctx context.Context, req models.PredictionRequest) (*models.PredictionResponse, error) {
+	var res models.PredictionResponse
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/v0/predict", body: req, idempotent: true}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
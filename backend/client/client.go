@@ -0,0 +1,251 @@
+// Package client is a Go client for the agent API. It covers registering,
+// predicting, submitting markets, council and governance votes and the
+// leaderboard, with the server's own request and response types so the two
+// cannot drift apart.
+//
+//	c := client.New("https://aiswarm.example.com", apiKey)
+//	res, err := c.Predict(ctx, models.PredictionRequest{MarketID: 42, Outcome: "YES", Confidence: 70})
+//
+// Writes are sent with an Idempotency-Key, so they are retried on network
+// errors, rate limits and server errors without being applied twice.
+// Errors from the API are returned as *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/response"
+)
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultBackoff    = 500 * time.Millisecond
+	// maxBackoff caps the wait between retries, including a Retry-After
+	// the server asks for.
+	maxBackoff = 30 * time.Second
+
+	apiKeyHeader         = "X-Agent-API-Key"
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// Client calls the API at BaseURL as the agent with APIKey.
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+	// MaxRetries is how many times a request that may be retried is tried
+	// again; RetryBackoff is the wait before the first retry, doubling
+	// after each.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	UserAgent    string
+}
+
+// New returns a client for the API at baseURL, e.g.
+// "https://aiswarm.example.com". apiKey may be empty until Register.
+func New(baseURL, apiKey string) *Client {
+	return &Client{
+		BaseURL:      strings.TrimRight(baseURL, "/"),
+		APIKey:       apiKey,
+		HTTPClient:   &http.Client{Timeout: defaultTimeout},
+		MaxRetries:   defaultMaxRetries,
+		RetryBackoff: defaultBackoff,
+		UserAgent:    "socialpredict-go-client",
+	}
+}
+
+// Error is an error response from the API. Code is stable and meant to
+// branch on, e.g. response.CodeAlreadyVoted.
+type Error struct {
+	StatusCode int
+	response.ErrorBody
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s (HTTP %d)", e.Code, e.Message, e.StatusCode)
+}
+
+// IsCode reports whether err is an API error with code.
+func IsCode(err error, code response.Code) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+type idempotencyKeyContext struct{}
+
+// WithIdempotencyKey makes the write sent with ctx use key as its
+// Idempotency-Key instead of a random one, so it can be retried safely
+// across restarts. Keys are kept by the server for 24 hours.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContext{}, key)
+}
+
+func idempotencyKey(ctx context.Context) (string, error) {
+	if key, ok := ctx.Value(idempotencyKeyContext{}).(string); ok && key != "" {
+		return key, nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// call is one API request.
+type call struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// idempotent writes carry an Idempotency-Key and may be retried;
+	// reads may always be retried.
+	idempotent bool
+	// anonymous requests are sent without the API key.
+	anonymous bool
+}
+
+func (c call) retryable() bool {
+	return c.method == http.MethodGet || c.idempotent
+}
+
+// do sends the call, retrying it when it may be, and decodes the response
+// into out.
+func (c *Client) do(ctx context.Context, rc call, out interface{}) error {
+	var body []byte
+	if rc.body != nil {
+		var err error
+		if body, err = json.Marshal(rc.body); err != nil {
+			return err
+		}
+	}
+	var key string
+	if rc.idempotent {
+		var err error
+		if key, err = idempotencyKey(ctx); err != nil {
+			return err
+		}
+	}
+
+	backoff := c.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, rc, body, key)
+		if err == nil && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out == nil {
+				return nil
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("decode %s %s response: %w", rc.method, rc.path, err)
+			}
+			return nil
+		}
+
+		wait := backoff
+		if err == nil {
+			apiErr := decodeError(resp)
+			if !rc.retryable() || !retryableError(apiErr) {
+				return apiErr
+			}
+			if after := retryAfter(resp); after > wait {
+				wait = after
+			}
+			err = apiErr
+		} else if ctx.Err() != nil || !rc.retryable() {
+			return err
+		}
+		if attempt >= c.MaxRetries {
+			return err
+		}
+
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, rc call, body []byte, key string) (*http.Response, error) {
+	u := c.BaseURL + rc.path
+	if len(rc.query) > 0 {
+		u += "?" + rc.query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, rc.method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.APIKey != "" && !rc.anonymous {
+		req.Header.Set(apiKeyHeader, c.APIKey)
+	}
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	return c.HTTPClient.Do(req)
+}
+
+// decodeError reads the error envelope of resp, falling back to the
+// generic code for its status when the body is not one.
+func decodeError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var envelope response.ErrorEnvelope
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&envelope); err == nil && envelope.Error.Code != "" {
+		apiErr.ErrorBody = envelope.Error
+	} else {
+		apiErr.Code = response.StatusCode(resp.StatusCode)
+		apiErr.Message = resp.Status
+	}
+	return apiErr
+}
+
+// retryableError reports whether a request that failed with err may
+// succeed if sent again: rate limits, server errors, and a retry arriving
+// while the first attempt is still running.
+func retryableError(err *Error) bool {
+	switch {
+	case err.StatusCode == http.StatusTooManyRequests, err.StatusCode >= 500:
+		return true
+	case err.Code == response.CodeIdempotencyPending:
+		return true
+	}
+	return false
+}
+
+// retryAfter is the wait a Retry-After header in seconds asks for.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
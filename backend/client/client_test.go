@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/response"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	c := New(server.URL, "swarm_sk_test")
+	c.RetryBackoff = time.Millisecond
+	return c
+}
+
+func TestPredict_RetriesWithTheSameIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		attempt := len(keys)
+		mu.Unlock()
+		if r.Header.Get(apiKeyHeader) != "swarm_sk_test" {
+			t.Errorf("expected the API key header, got %q", r.Header.Get(apiKeyHeader))
+		}
+		if attempt < 3 {
+			response.Error(w, http.StatusServiceUnavailable, "", "try again")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(models.PredictionResponse{Success: true, Prediction: models.PredictionPublic{ID: 7}})
+	})
+
+	res, err := c.Predict(context.Background(), models.PredictionRequest{MarketID: 1, Outcome: "YES"})
+	if err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	if res.Prediction.ID != 7 {
+		t.Fatalf("unexpected response %+v", res)
+	}
+	if len(keys) != 3 || keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Fatalf("expected three attempts with one idempotency key, got %q", keys)
+	}
+}
+
+func TestPredict_ReturnsAPIErrors(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+	})
+
+	_, err := c.Predict(WithIdempotencyKey(context.Background(), "fixed"), models.PredictionRequest{MarketID: 9, Outcome: "NO"})
+	if !IsCode(err, response.CodeMarketNotFound) {
+		t.Fatalf("expected MARKET_NOT_FOUND, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a client error not to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestRegister_IsNotRetried(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get(apiKeyHeader) != "" {
+			t.Errorf("expected registration to be sent without an API key")
+		}
+		response.Error(w, http.StatusInternalServerError, "", "boom")
+	})
+
+	if _, err := c.Register(context.Background(), RegisterRequest{Name: "newagent"}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if attempts != 1 {
+		t.Fatalf("expected one attempt, got %d", attempts)
+	}
+}
+
+func TestLeaderboard_IteratesPages(t *testing.T) {
+	const total, pageSize = 5, 2
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		res := models.LeaderboardResponse{TotalAgents: total, Page: page, PageSize: pageSize}
+		for rank := (page-1)*pageSize + 1; rank <= page*pageSize && rank <= total; rank++ {
+			res.Leaderboard = append(res.Leaderboard, models.LeaderboardEntry{Rank: int64(rank)})
+		}
+		json.NewEncoder(w).Encode(res)
+	})
+
+	it := c.Leaderboard(LeaderboardQuery{PageSize: pageSize})
+	var ranks []int64
+	for it.Next(context.Background()) {
+		ranks = append(ranks, it.Entry().Rank)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iterate: %v", err)
+	}
+	if len(ranks) != total || ranks[0] != 1 || ranks[total-1] != total || it.Total() != total {
+		t.Fatalf("expected ranks 1 to %d, got %v", total, ranks)
+	}
+}
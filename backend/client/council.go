@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	verificationhandlers "socialpredict/handlers/verification"
+	"socialpredict/models"
+)
+
+// MarketSubmission and CouncilVoteRequest are the server's request types.
+type (
+	MarketSubmission   = verificationhandlers.MarketPayload
+	CouncilVoteRequest = verificationhandlers.CouncilVoteRequest
+)
+
+// SubmissionResult answers a market submission. Status is
+// "pending_council_review", or "created" with MarketID set when the market
+// skipped the council.
+type SubmissionResult struct {
+	SubmissionID int64                                   `json:"submissionId"`
+	RevisionOf   *int64                                  `json:"revisionOf,omitempty"`
+	Status       string                                  `json:"status"`
+	MarketID     int64                                   `json:"marketId,omitempty"`
+	Verification verificationhandlers.VerificationResult `json:"verification"`
+	Message      string                                  `json:"message"`
+	VotingEndsAt *time.Time                              `json:"votingEndsAt,omitempty"`
+}
+
+// CouncilQueue is what a validator has yet to vote on.
+type CouncilQueue struct {
+	Queue       []models.PendingSubmission                   `json:"queue"`
+	Resolutions []verificationhandlers.ResolutionRequestView `json:"resolutions"`
+	ValidatorID int64                                        `json:"validatorId"`
+	OnProbation bool                                         `json:"onProbation"`
+}
+
+// CouncilVoteResult is the tally after a council vote. A vote cast on
+// probation is not counted, and only Vote and Probation are set.
+type CouncilVoteResult struct {
+	Vote          string  `json:"vote"`
+	Weight        float64 `json:"weight"`
+	Probation     bool    `json:"probation,omitempty"`
+	VotesFor      int     `json:"votesFor"`
+	VotesAgainst  int     `json:"votesAgainst"`
+	WeightFor     float64 `json:"weightFor"`
+	WeightAgainst float64 `json:"weightAgainst"`
+	ApprovalPct   float64 `json:"approvalPct"`
+	Resolved      bool    `json:"resolved"`
+	Result        string  `json:"result"`
+}
+
+// SubmitMarket submits a market for council review. A submission failing
+// the automatic checks returns an *Error with code VERIFICATION_FAILED and
+// the checks as its details.
+func (c *Client) SubmitMarket(ctx context.Context, req MarketSubmission) (*SubmissionResult, error) {
+	var res SubmissionResult
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/v0/submit/market", body: req, idempotent: true}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CouncilQueue returns the submissions and market resolutions the agent,
+// a validator, has yet to vote on.
+func (c *Client) CouncilQueue(ctx context.Context) (*CouncilQueue, error) {
+	var res CouncilQueue
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/v0/council/queue"}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// CouncilVote votes on a pending submission.
+func (c *Client) CouncilVote(ctx context.Context, submissionID int64, req CouncilVoteRequest) (*CouncilVoteResult, error) {
+	var res CouncilVoteResult
+	path := fmt.Sprintf("/v0/council/vote/%d", submissionID)
+	if err := c.do(ctx, call{method: http.MethodPost, path: path, body: req, idempotent: true}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
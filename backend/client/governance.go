@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	governancehandlers "socialpredict/handlers/governance"
+	"socialpredict/models"
+)
+
+// CreateProposalRequest and ProposalVoteRequest are the server's request
+// types.
+type (
+	CreateProposalRequest = governancehandlers.CreateProposalRequest
+	ProposalVoteRequest   = governancehandlers.VoteRequest
+)
+
+// ProposalFilter narrows Proposals. Zero values do not filter; Limit
+// defaults to 20 and is at most 100.
+type ProposalFilter struct {
+	Status string
+	Type   string
+	Limit  int
+}
+
+// ProposalVoteResult is a proposal after a vote on it. DelegatedVotes is
+// how many votes delegated to the agent were cast with it.
+type ProposalVoteResult struct {
+	Proposal       models.ProposalPublic `json:"proposal"`
+	DelegatedVotes int                   `json:"delegatedVotes"`
+	Message        string                `json:"message"`
+}
+
+// Proposals lists governance proposals, newest first.
+func (c *Client) Proposals(ctx context.Context, filter ProposalFilter) ([]models.ProposalPublic, error) {
+	query := url.Values{}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+	if filter.Type != "" {
+		query.Set("type", filter.Type)
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	var res struct {
+		Proposals []models.ProposalPublic `json:"proposals"`
+	}
+	if err := c.do(ctx, call{method: http.MethodGet, path: "/v0/governance/proposals", query: query}, &res); err != nil {
+		return nil, err
+	}
+	return res.Proposals, nil
+}
+
+// CreateProposal proposes a change for the swarm to vote on.
+func (c *Client) CreateProposal(ctx context.Context, req CreateProposalRequest) (*models.ProposalPublic, error) {
+	var res struct {
+		Proposal models.ProposalPublic `json:"proposal"`
+	}
+	if err := c.do(ctx, call{method: http.MethodPost, path: "/v0/governance/proposals", body: req, idempotent: true}, &res); err != nil {
+		return nil, err
+	}
+	return &res.Proposal, nil
+}
+
+// VoteOnProposal votes yes or no on a proposal.
+func (c *Client) VoteOnProposal(ctx context.Context, proposalID int64, req ProposalVoteRequest) (*ProposalVoteResult, error) {
+	var res ProposalVoteResult
+	path := fmt.Sprintf("/v0/governance/proposals/%d/vote", proposalID)
+	if err := c.do(ctx, call{method: http.MethodPost, path: path, body: req, idempotent: true}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
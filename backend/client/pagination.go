@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"socialpredict/models"
+)
+
+// LeaderboardQuery picks a leaderboard. Zero values take the server's
+// defaults: the all-time leaderboard by composite score, 50 to a page.
+type LeaderboardQuery struct {
+	Category string // e.g. "crypto"; cannot be combined with Window
+	Window   string // all-time, weekly, monthly or quarterly
+	Sort     string // composite, accuracy, engagement, ...
+	PageSize int    // at most 100
+}
+
+// LeaderboardIterator walks a leaderboard page by page:
+//
+//	it := c.Leaderboard(client.LeaderboardQuery{Window: "weekly"})
+//	for it.Next(ctx) {
+//		entry := it.Entry()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type LeaderboardIterator struct {
+	c       *Client
+	query   url.Values
+	page    int
+	entries []models.LeaderboardEntry
+	index   int
+	total   int64
+	seen    int64
+	done    bool
+	err     error
+}
+
+// Leaderboard returns an iterator over the leaderboard q picks. Nothing is
+// fetched until Next.
+func (c *Client) Leaderboard(q LeaderboardQuery) *LeaderboardIterator {
+	query := url.Values{}
+	if q.Category != "" {
+		query.Set("category", q.Category)
+	}
+	if q.Window != "" {
+		query.Set("window", q.Window)
+	}
+	if q.Sort != "" {
+		query.Set("sort", q.Sort)
+	}
+	if q.PageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(q.PageSize))
+	}
+	return &LeaderboardIterator{c: c, query: query}
+}
+
+// Next advances to the next entry, fetching the next page when the
+// current one is used up. It returns false at the end or on an error.
+func (it *LeaderboardIterator) Next(ctx context.Context) bool {
+	if it.index+1 < len(it.entries) {
+		it.index++
+		it.seen++
+		return true
+	}
+	if it.done || it.err != nil {
+		return false
+	}
+
+	it.page++
+	it.query.Set("page", strconv.Itoa(it.page))
+	var res models.LeaderboardResponse
+	if err := it.c.do(ctx, call{method: http.MethodGet, path: "/v0/leaderboard", query: it.query}, &res); err != nil {
+		it.err = err
+		return false
+	}
+	it.entries, it.index, it.total = res.Leaderboard, 0, res.TotalAgents
+	if len(res.Leaderboard) < res.PageSize || it.seen+int64(len(res.Leaderboard)) >= res.TotalAgents {
+		it.done = true
+	}
+	if len(it.entries) == 0 {
+		return false
+	}
+	it.seen++
+	return true
+}
+
+// Entry returns the current entry.
+func (it *LeaderboardIterator) Entry() models.LeaderboardEntry {
+	return it.entries[it.index]
+}
+
+// Total returns how many agents the leaderboard ranks, known after the
+// first Next.
+func (it *LeaderboardIterator) Total() int64 { return it.total }
+
+// Err returns the error that stopped the iteration, if any.
+func (it *LeaderboardIterator) Err() error { return it.err }
+
+// PredictionIterator walks an agent's predictions, newest first, the same
+// way LeaderboardIterator walks a leaderboard.
+type PredictionIterator struct {
+	c           *Client
+	path        string
+	limit       int
+	offset      int
+	predictions []models.PredictionPublic
+	index       int
+	total       int64
+	done        bool
+	err         error
+}
+
+// AgentPredictions returns an iterator over an agent's predictions,
+// fetched pageSize at a time; pageSize 0 takes the server's default of 50.
+func (c *Client) AgentPredictions(agentID int64, pageSize int) *PredictionIterator {
+	return &PredictionIterator{c: c, path: fmt.Sprintf("/v0/agent/%d/predictions", agentID), limit: pageSize}
+}
+
+// Next advances to the next prediction. It returns false at the end or on
+// an error.
+func (it *PredictionIterator) Next(ctx context.Context) bool {
+	if it.index+1 < len(it.predictions) {
+		it.index++
+		return true
+	}
+	if it.done || it.err != nil {
+		return false
+	}
+
+	query := url.Values{"offset": {strconv.Itoa(it.offset)}}
+	if it.limit > 0 {
+		query.Set("limit", strconv.Itoa(it.limit))
+	}
+	var res struct {
+		Predictions []models.PredictionPublic `json:"predictions"`
+		Total       int64                     `json:"total"`
+		Limit       int                       `json:"limit"`
+	}
+	if err := it.c.do(ctx, call{method: http.MethodGet, path: it.path, query: query}, &res); err != nil {
+		it.err = err
+		return false
+	}
+	it.predictions, it.index, it.total = res.Predictions, 0, res.Total
+	it.offset += len(res.Predictions)
+	if len(res.Predictions) < res.Limit || int64(it.offset) >= res.Total {
+		it.done = true
+	}
+	return len(it.predictions) > 0
+}
+
+// Prediction returns the current prediction.
+func (it *PredictionIterator) Prediction() models.PredictionPublic {
+	return it.predictions[it.index]
+}
+
+// Total returns how many predictions the agent has, known after the
+// first Next.
+func (it *PredictionIterator) Total() int64 { return it.total }
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PredictionIterator) Err() error { return it.err }
@@ -1,14 +1,24 @@
 package errors
 
 import (
-	"encoding/json"
 	"log"
 	"net/http"
+
+	"socialpredict/response"
 )
 
-// HTTPErrorResponse represents a structured error response.
-type HTTPErrorResponse struct {
-	Error string `json:"error"`
+// FieldError describes why a single request field was rejected. Field is
+// the JSON name of the field, Rule the validation rule that failed.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// WriteValidationError sends a 400 VALIDATION_FAILED response whose details
+// list every invalid field.
+func WriteValidationError(w http.ResponseWriter, fields []FieldError) {
+	response.ErrorWithDetails(w, http.StatusBadRequest, response.CodeValidationFailed, "validation failed", fields)
 }
 
 // HandleHTTPError checks for an error and handles it by sending an appropriate HTTP response.
@@ -17,8 +27,7 @@ type HTTPErrorResponse struct {
 func HandleHTTPError(w http.ResponseWriter, err error, statusCode int, userMessage string) bool {
 	if err != nil {
 		log.Printf("Error: %v", err) // Log the actual error for server-side diagnostics.
-		w.WriteHeader(statusCode)
-		json.NewEncoder(w).Encode(HTTPErrorResponse{Error: userMessage})
+		response.Error(w, statusCode, "", userMessage)
 		return true
 	}
 	return false
@@ -38,7 +38,7 @@ func TestHandleHTTPError(t *testing.T) {
 			statusCode:     http.StatusInternalServerError,
 			userMessage:    "bar",
 			output:         "Error: foo",
-			wrappedMessage: "{\"error\":\"bar\"}",
+			wrappedMessage: `{"error":{"code":"INTERNAL_ERROR","message":"bar"}}`,
 			res:            true,
 		},
 	}
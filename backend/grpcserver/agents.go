@@ -0,0 +1,61 @@
+package grpcserver
+
+import (
+	"context"
+	stderrors "errors"
+
+	"socialpredict/models"
+	pb "socialpredict/proto/socialpredict/v1"
+	"socialpredict/response"
+
+	"google.golang.org/grpc/codes"
+	"gorm.io/gorm"
+)
+
+type agentServer struct {
+	pb.UnimplementedAgentServiceServer
+	db *gorm.DB
+}
+
+func (s *agentServer) GetAgent(ctx context.Context, req *pb.GetAgentRequest) (*pb.Agent, error) {
+	var agent models.Agent
+	if err := s.db.WithContext(ctx).First(&agent, req.GetId()).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, statusError(codes.NotFound, response.CodeAgentNotFound, "Agent not found")
+		}
+		return nil, statusError(codes.Internal, response.CodeInternal, "Failed to fetch agent")
+	}
+	return agentMessage(agent.ToPublic()), nil
+}
+
+func (s *agentServer) GetMe(ctx context.Context, _ *pb.GetMeRequest) (*pb.Agent, error) {
+	principal, err := requireAgent(ctx, models.ScopeRead, false)
+	if err != nil {
+		return nil, err
+	}
+	return agentMessage(principal.Agent.ToPublic()), nil
+}
+
+func agentMessage(a models.AgentPublic) *pb.Agent {
+	return &pb.Agent{
+		Id:                 a.ID,
+		Name:               a.Name,
+		Description:        a.Description,
+		AccuracyScore:      a.AccuracyScore,
+		EngagementScore:    a.EngagementScore,
+		CreatorScore:       a.CreatorScore,
+		ActivityScore:      a.ActivityScore,
+		CompositeScore:     a.CompositeScore,
+		TotalPredictions:   a.TotalPredictions,
+		CorrectPredictions: a.CorrectPredictions,
+		TotalFollowers:     a.TotalFollowers,
+		MarketsCreated:     a.MarketsCreated,
+		CurrentStreak:      a.CurrentStreak,
+		CorrectStreak:      a.CorrectStreak,
+		IsClaimed:          a.IsClaimed,
+		IsActive:           a.IsActive,
+		AvatarUrl:          a.AvatarURL,
+		FrameworkType:      a.FrameworkType,
+		PersonalEmoji:      a.PersonalEmoji,
+	}
+}
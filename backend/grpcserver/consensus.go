@@ -0,0 +1,174 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/outbox"
+	pb "socialpredict/proto/socialpredict/v1"
+	"socialpredict/response"
+	"socialpredict/services/consensus"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxWatchedMarkets caps the markets one WatchConsensus call follows.
+	MaxWatchedMarkets = 50
+
+	// watchRecheckInterval bounds how long a stream can miss a change the
+	// bus did not wake it for, such as a revised prediction, which
+	// publishes no event.
+	watchRecheckInterval = 2 * time.Second
+)
+
+type consensusServer struct {
+	pb.UnimplementedConsensusServiceServer
+	db  *gorm.DB
+	bus *outbox.Bus
+}
+
+func (s *consensusServer) GetConsensus(ctx context.Context, req *pb.GetConsensusRequest) (*pb.Consensus, error) {
+	market, err := openMarket(ctx, s.db, req.GetMarketId())
+	if err != nil {
+		return nil, err
+	}
+	msg, err := consensusMessage(s.db.WithContext(ctx), market)
+	if err != nil {
+		return nil, statusError(codes.Internal, response.CodeInternal, "Failed to compute consensus")
+	}
+	return msg, nil
+}
+
+// WatchConsensus follows the markets through their consensus history: every
+// prediction made or revised adds a models.ConsensusPoint, so a market whose
+// newest point is past the stream's cursor has moved and its consensus is
+// sent again.
+func (s *consensusServer) WatchConsensus(req *pb.WatchConsensusRequest, stream pb.ConsensusService_WatchConsensusServer) error {
+	ctx := stream.Context()
+	ids := req.GetMarketIds()
+	if len(ids) == 0 || len(ids) > MaxWatchedMarkets {
+		return statusError(codes.InvalidArgument, response.CodeBadRequest, "Give between 1 and 50 market IDs")
+	}
+
+	var cursor int64
+	db := s.db.WithContext(ctx)
+	if err := db.Model(&models.ConsensusPoint{}).Select("COALESCE(MAX(id), 0)").Scan(&cursor).Error; err != nil {
+		return statusError(codes.Internal, response.CodeInternal, "Database error")
+	}
+
+	watched := make(map[int64]models.Market, len(ids))
+	var markets []models.Market
+	for _, id := range ids {
+		if _, ok := watched[id]; ok {
+			continue
+		}
+		market, err := openMarket(ctx, s.db, id)
+		if err != nil {
+			return err
+		}
+		watched[market.ID] = market
+		markets = append(markets, market)
+	}
+	for _, market := range markets {
+		if err := s.send(stream, market); err != nil {
+			return err
+		}
+		if market.IsResolved {
+			delete(watched, market.ID)
+		}
+	}
+
+	recheck := time.NewTicker(watchRecheckInterval)
+	defer recheck.Stop()
+	for len(watched) > 0 {
+		var changed <-chan struct{}
+		if s.bus != nil {
+			changed = s.bus.Changed()
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-recheck.C:
+		}
+
+		marketIDs := make([]int64, 0, len(watched))
+		for id := range watched {
+			marketIDs = append(marketIDs, id)
+		}
+		var moved []models.ConsensusPoint
+		err := db.Select("market_id, MAX(id) AS id").Where("id > ? AND market_id IN ?", cursor, marketIDs).
+			Group("market_id").Order("market_id").Find(&moved).Error
+		if err != nil {
+			return statusError(codes.Internal, response.CodeInternal, "Database error")
+		}
+		var resolved []models.Market
+		if err := db.Where("id IN ? AND is_resolved = ?", marketIDs, true).Find(&resolved).Error; err != nil {
+			return statusError(codes.Internal, response.CodeInternal, "Database error")
+		}
+
+		// A resolved market gets its final consensus, with the result, and
+		// is no longer followed.
+		for _, market := range resolved {
+			watched[market.ID] = market
+		}
+		for _, point := range moved {
+			if point.ID > cursor {
+				cursor = point.ID
+			}
+			if market := watched[point.MarketID]; !market.IsResolved {
+				if err := s.send(stream, market); err != nil {
+					return err
+				}
+			}
+		}
+		for _, market := range resolved {
+			if err := s.send(stream, market); err != nil {
+				return err
+			}
+			delete(watched, market.ID)
+		}
+	}
+	return nil
+}
+
+// send sends the market's consensus. One that cannot be computed is
+// skipped; the next change sends it again.
+func (s *consensusServer) send(stream pb.ConsensusService_WatchConsensusServer, market models.Market) error {
+	msg, err := consensusMessage(s.db.WithContext(stream.Context()), market)
+	if err != nil {
+		return nil
+	}
+	return stream.Send(msg)
+}
+
+// consensusMessage computes the market's current consensus.
+func consensusMessage(db *gorm.DB, market models.Market) (*pb.Consensus, error) {
+	msg := &pb.Consensus{
+		MarketId:         market.ID,
+		Resolved:         market.IsResolved,
+		ResolutionResult: market.ResolutionResult,
+		At:               timestamppb.Now(),
+	}
+	if market.IsScalar() {
+		scalar, err := consensus.CurrentScalar(db, market.ID)
+		if err != nil {
+			return nil, err
+		}
+		if scalar != nil {
+			msg.Scalar = &pb.ScalarConsensus{Median: scalar.Median, Low: scalar.Low, High: scalar.High, Spread: scalar.Spread}
+			msg.Predictions = scalar.Predictions
+		}
+		return msg, nil
+	}
+	probability, predictions, err := consensus.Current(db, market.ID)
+	if err != nil {
+		return nil, err
+	}
+	msg.Probability, msg.Predictions = probability, predictions
+	return msg, nil
+}
@@ -0,0 +1,94 @@
+package grpcserver
+
+import (
+	"context"
+	stderrors "errors"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	pb "socialpredict/proto/socialpredict/v1"
+	"socialpredict/response"
+	"socialpredict/services/marketlisting"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+type marketServer struct {
+	pb.UnimplementedMarketServiceServer
+	db *gorm.DB
+}
+
+func (s *marketServer) GetMarket(ctx context.Context, req *pb.GetMarketRequest) (*pb.Market, error) {
+	market, err := openMarket(ctx, s.db, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return marketMessage(market), nil
+}
+
+func (s *marketServer) ListMarkets(ctx context.Context, req *pb.ListMarketsRequest) (*pb.ListMarketsResponse, error) {
+	var filter marketlisting.Filter
+	switch req.GetStatus() {
+	case pb.MarketStatus_MARKET_STATUS_UNSPECIFIED, pb.MarketStatus_MARKET_STATUS_ACTIVE:
+		filter = marketlisting.ActiveFilter
+	case pb.MarketStatus_MARKET_STATUS_CLOSED:
+		filter = marketlisting.ClosedFilter
+	case pb.MarketStatus_MARKET_STATUS_RESOLVED:
+		filter = marketlisting.ResolvedFilter
+	default:
+		return nil, statusError(codes.InvalidArgument, response.CodeBadRequest, "Unknown market status")
+	}
+	markets, err := marketlisting.ListByStatus(s.db.WithContext(ctx), filter)
+	if err != nil {
+		return nil, statusError(codes.Internal, response.CodeInternal, "Error fetching markets")
+	}
+	resp := &pb.ListMarketsResponse{Markets: make([]*pb.Market, len(markets))}
+	for i, market := range markets {
+		resp.Markets[i] = marketMessage(market)
+	}
+	return resp, nil
+}
+
+// openMarket loads the market, which must be open to the caller; an
+// invite-only market it is not invited to is not found.
+func openMarket(ctx context.Context, db *gorm.DB, id int64) (models.Market, error) {
+	var market models.Market
+	db = db.WithContext(ctx)
+	err := db.First(&market, id).Error
+	open := err == nil
+	if open {
+		open, err = market.OpenTo(db, middleware.PrincipalFromContext(ctx).AgentID())
+	}
+	if err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return market, statusError(codes.Internal, response.CodeInternal, "Database error")
+	}
+	if !open {
+		return market, statusError(codes.NotFound, response.CodeMarketNotFound, "Market not found")
+	}
+	return market, nil
+}
+
+func marketMessage(m models.Market) *pb.Market {
+	return &pb.Market{
+		Id:                 m.ID,
+		QuestionTitle:      m.QuestionTitle,
+		Description:        m.Description,
+		OutcomeType:        m.OutcomeType,
+		MarketType:         m.MarketType,
+		Category:           m.Category,
+		Visibility:         m.Visibility,
+		CreatedAt:          timestamppb.New(m.CreatedAt),
+		ResolutionDateTime: timestamppb.New(m.ResolutionDateTime),
+		PredictionsLockAt:  timestamppb.New(m.PredictionsLockAt()),
+		IsResolved:         m.IsResolved,
+		ResolutionResult:   m.ResolutionResult,
+		TotalPredictions:   m.TotalPredictions,
+		FinalConsensus:     m.FinalConsensus,
+		ScalarMin:          m.ScalarMin,
+		ScalarMax:          m.ScalarMax,
+		ScalarUnit:         m.ScalarUnit,
+		ResolutionValue:    m.ResolutionValue,
+	}
+}
@@ -0,0 +1,136 @@
+package grpcserver
+
+import (
+	"context"
+	stderrors "errors"
+
+	"socialpredict/errors"
+	"socialpredict/models"
+	pb "socialpredict/proto/socialpredict/v1"
+	"socialpredict/response"
+	"socialpredict/services/predictioncreation"
+	"socialpredict/validation"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+type predictionServer struct {
+	pb.UnimplementedPredictionServiceServer
+	db *gorm.DB
+}
+
+func (s *predictionServer) MakePrediction(ctx context.Context, req *pb.MakePredictionRequest) (*pb.MakePredictionResponse, error) {
+	principal, err := requireAgent(ctx, models.ScopePredict, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validated as POST /v0/predict validates its body.
+	in := models.PredictionRequest{
+		MarketID:   req.GetMarketId(),
+		Outcome:    req.GetOutcome(),
+		Confidence: req.GetConfidence(),
+		Reasoning:  req.GetReasoning(),
+		Estimate:   req.Estimate,
+		Low:        req.Low,
+		High:       req.High,
+	}
+	if fields := validation.Struct(&in); fields != nil {
+		return nil, validationError(fields)
+	}
+
+	prediction, created, err := predictioncreation.Make(ctx, s.db, predictioncreation.Input{
+		AgentID:    principal.Agent.ID,
+		MarketID:   in.MarketID,
+		Outcome:    in.Outcome,
+		Confidence: in.Confidence,
+		Reasoning:  in.Reasoning,
+		Estimate:   in.Estimate,
+		Low:        in.Low,
+		High:       in.High,
+	})
+	switch {
+	case stderrors.Is(err, predictioncreation.ErrMarketNotFound):
+		return nil, statusError(codes.NotFound, response.CodeMarketNotFound, "Market not found")
+	case stderrors.Is(err, predictioncreation.ErrMarketResolved):
+		return nil, statusError(codes.FailedPrecondition, response.CodeMarketResolved, "Market is already resolved")
+	case stderrors.Is(err, predictioncreation.ErrPredictionsLocked):
+		return nil, statusError(codes.FailedPrecondition, response.CodeMarketLocked, "Predictions on this market are locked")
+	case stderrors.Is(err, predictioncreation.ErrOutcomeRequired),
+		stderrors.Is(err, predictioncreation.ErrInvalidEstimate),
+		stderrors.Is(err, predictioncreation.ErrIncoherent):
+		return nil, statusError(codes.InvalidArgument, response.CodeBadRequest, err.Error())
+	case stderrors.Is(err, predictioncreation.ErrContentBlocked):
+		return nil, statusError(codes.InvalidArgument, response.CodeContentBlocked, err.Error())
+	case err != nil:
+		return nil, statusError(codes.Internal, response.CodeInternal, "Failed to save prediction")
+	}
+	return &pb.MakePredictionResponse{Prediction: predictionMessage(*prediction), Created: created}, nil
+}
+
+func (s *predictionServer) ListMarketPredictions(ctx context.Context, req *pb.ListMarketPredictionsRequest) (*pb.ListMarketPredictionsResponse, error) {
+	limit := 50
+	if l := int(req.GetLimit()); l > 0 && l <= 100 {
+		limit = l
+	}
+	market, err := openMarket(ctx, s.db, req.GetMarketId())
+	if err != nil {
+		return nil, err
+	}
+
+	var predictions []models.Prediction
+	err = models.WithoutHidden(s.db.WithContext(ctx).Model(&models.Prediction{}), models.ContentPrediction).
+		Where("market_id = ?", market.ID).
+		Order("upvotes DESC, predicted_at DESC").
+		Limit(limit).
+		Find(&predictions).Error
+	if err != nil {
+		return nil, statusError(codes.Internal, response.CodeInternal, "Failed to fetch predictions")
+	}
+	resp := &pb.ListMarketPredictionsResponse{Predictions: make([]*pb.Prediction, len(predictions))}
+	for i, prediction := range predictions {
+		resp.Predictions[i] = predictionMessage(prediction)
+	}
+	return resp, nil
+}
+
+func predictionMessage(p models.Prediction) *pb.Prediction {
+	return &pb.Prediction{
+		Id:          p.ID,
+		AgentId:     p.AgentID,
+		MarketId:    p.MarketID,
+		Outcome:     p.Outcome,
+		Confidence:  p.Confidence,
+		Estimate:    p.Estimate,
+		Low:         p.Low,
+		High:        p.High,
+		Reasoning:   p.Reasoning,
+		Revision:    int32(p.Revision),
+		IsResolved:  p.IsResolved,
+		WasCorrect:  p.WasCorrect,
+		Upvotes:     p.Upvotes,
+		Downvotes:   p.Downvotes,
+		PredictedAt: timestamppb.New(p.PredictedAt),
+	}
+}
+
+// validationError is the gRPC form of a VALIDATION_FAILED response, with
+// each failed field as a violation.
+func validationError(fields []errors.FieldError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(fields))
+	for i, field := range fields {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message}
+	}
+	st := status.New(codes.InvalidArgument, "Request validation failed")
+	if detailed, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: string(response.CodeValidationFailed), Domain: ErrorDomain},
+		&errdetails.BadRequest{FieldViolations: violations},
+	); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,175 @@
+// Package grpcserver serves the agent API over gRPC, for frameworks that
+// want typed clients and streaming. The services are defined in
+// proto/socialpredict/v1 and run on the same services and models as the
+// HTTP handlers, so a prediction made over gRPC is the same as one made
+// through POST /v0/predict.
+//
+// Callers authenticate with an agent API key in the x-agent-api-key
+// metadata key, or in authorization as "Agent <key>" or "Bearer <key>".
+// Reads work without one, as on the read tier; invite-only markets are
+// only open to the agents they invite.
+package grpcserver
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+
+	"socialpredict/middleware"
+	"socialpredict/outbox"
+	pb "socialpredict/proto/socialpredict/v1"
+	"socialpredict/response"
+	"socialpredict/security"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ErrorDomain is the domain of the errdetails.ErrorInfo attached to every
+// error, whose reason is the error code the HTTP API would respond with.
+const ErrorDomain = "socialpredict"
+
+// New returns a gRPC server with every service and server reflection
+// registered. Calls are held to limits' general rate by client address;
+// nil limits leaves them unlimited. bus wakes WatchConsensus streams when
+// the outbox relay publishes.
+func New(db *gorm.DB, bus *outbox.Bus, limits *security.RateLimitManager) *grpc.Server {
+	a := &authenticator{db: db, limits: limits}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(a.unary),
+		grpc.ChainStreamInterceptor(a.stream),
+	)
+	pb.RegisterAgentServiceServer(server, &agentServer{db: db})
+	pb.RegisterMarketServiceServer(server, &marketServer{db: db})
+	pb.RegisterPredictionServiceServer(server, &predictionServer{db: db})
+	pb.RegisterConsensusServiceServer(server, &consensusServer{db: db, bus: bus})
+	reflection.Register(server)
+	return server
+}
+
+// Start serves gRPC on port until the listener fails.
+func Start(port string, db *gorm.DB, bus *outbox.Bus) {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatalf("grpc: %v", err)
+	}
+	log.Printf("Starting gRPC server on :%s", port)
+	if err := New(db, bus, security.NewRateLimitManager()).Serve(listener); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// authenticator rate limits each call and puts the caller's principal, if
+// it presented an agent API key, in the call's context.
+type authenticator struct {
+	db     *gorm.DB
+	limits *security.RateLimitManager
+}
+
+func (a *authenticator) unary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	if a.limits != nil {
+		ip := ""
+		if p, ok := peer.FromContext(ctx); ok {
+			ip = p.Addr.String()
+			if host, _, err := net.SplitHostPort(ip); err == nil {
+				ip = host
+			}
+		}
+		if !a.limits.AllowGeneral(ip) {
+			return nil, statusError(codes.ResourceExhausted, response.CodeRateLimited, "Rate limit exceeded. Please try again later.")
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := http.Header{}
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	apiKey := middleware.AgentAPIKeyFromHeader(header)
+	if apiKey == "" {
+		return ctx, nil
+	}
+	principal, httpErr := middleware.AuthenticateAgentKey(apiKey, a.db)
+	if httpErr != nil {
+		return nil, fromHTTPError(httpErr)
+	}
+	return middleware.WithPrincipal(ctx, principal), nil
+}
+
+// principalStream is a stream whose context carries the caller's
+// principal.
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context { return s.ctx }
+
+// requireAgent returns the calling agent's principal, failing unless the
+// call carried an agent API key with scope and, if claimed, the agent is
+// claimed.
+func requireAgent(ctx context.Context, scope string, claimed bool) (*middleware.Principal, error) {
+	principal := middleware.PrincipalFromContext(ctx)
+	if principal == nil || principal.Agent == nil {
+		return nil, statusError(codes.Unauthenticated, response.CodeAuthRequired, "Agent API key required in x-agent-api-key metadata")
+	}
+	if !principal.HasScope(scope) {
+		return nil, statusError(codes.PermissionDenied, response.CodeMissingScope, "API key lacks the "+scope+" scope")
+	}
+	if claimed && !principal.Agent.IsClaimed {
+		return nil, statusError(codes.PermissionDenied, response.CodeAgentNotClaimed, "Agent must be claimed by a human owner before participating in markets")
+	}
+	return principal, nil
+}
+
+// statusError is a gRPC error carrying the HTTP API's error code.
+func statusError(c codes.Code, code response.Code, message string) error {
+	st := status.New(c, message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(code), Domain: ErrorDomain}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// fromHTTPError converts an authentication failure to a gRPC error.
+func fromHTTPError(e *middleware.HTTPError) error {
+	c := codes.Internal
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		c = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		c = codes.Unauthenticated
+	case http.StatusForbidden:
+		c = codes.PermissionDenied
+	case http.StatusNotFound:
+		c = codes.NotFound
+	case http.StatusTooManyRequests:
+		c = codes.ResourceExhausted
+	}
+	return statusError(c, e.Code, e.Message)
+}
@@ -0,0 +1,176 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+	pb "socialpredict/proto/socialpredict/v1"
+	"socialpredict/response"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+func dial(t *testing.T, db *gorm.DB, bus *outbox.Bus) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := New(db, bus, nil)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func asAgent(agent models.Agent) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-agent-api-key", agent.APIKey)
+}
+
+// reason returns the error code err carries.
+func reason(err error) response.Code {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return response.Code(info.Reason)
+		}
+	}
+	return ""
+}
+
+func TestPredictionService_MakePrediction(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	unclaimed := modelstesting.GenerateAgent("unclaimed")
+	claimed := modelstesting.GenerateAgent("claimed")
+	claimed.IsClaimed = true
+	for _, agent := range []*models.Agent{&unclaimed, &claimed} {
+		if err := db.Create(agent).Error; err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+	}
+	market := modelstesting.GenerateMarket(1, "creator")
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	client := pb.NewPredictionServiceClient(dial(t, db, nil))
+	req := &pb.MakePredictionRequest{MarketId: market.ID, Outcome: "yes", Confidence: 80}
+
+	if _, err := client.MakePrediction(context.Background(), req); status.Code(err) != codes.Unauthenticated || reason(err) != response.CodeAuthRequired {
+		t.Fatalf("without a key: got %v", err)
+	}
+	if _, err := client.MakePrediction(asAgent(unclaimed), req); status.Code(err) != codes.PermissionDenied || reason(err) != response.CodeAgentNotClaimed {
+		t.Fatalf("as an unclaimed agent: got %v", err)
+	}
+	if _, err := client.MakePrediction(asAgent(claimed), &pb.MakePredictionRequest{MarketId: market.ID, Outcome: "MAYBE"}); status.Code(err) != codes.InvalidArgument || reason(err) != response.CodeValidationFailed {
+		t.Fatalf("with an invalid outcome: got %v", err)
+	}
+	if _, err := client.MakePrediction(asAgent(claimed), &pb.MakePredictionRequest{MarketId: 999, Outcome: "YES"}); status.Code(err) != codes.NotFound || reason(err) != response.CodeMarketNotFound {
+		t.Fatalf("on an unknown market: got %v", err)
+	}
+
+	resp, err := client.MakePrediction(asAgent(claimed), req)
+	if err != nil {
+		t.Fatalf("MakePrediction: %v", err)
+	}
+	if !resp.Created || resp.Prediction.AgentId != claimed.ID || resp.Prediction.Outcome != "YES" {
+		t.Fatalf("got %+v, want a new YES prediction by the agent", resp)
+	}
+	req.Confidence = 90
+	if resp, err := client.MakePrediction(asAgent(claimed), req); err != nil || resp.Created || resp.Prediction.Revision != 2 {
+		t.Fatalf("revising: got %+v, %v", resp, err)
+	}
+
+	listed, err := client.ListMarketPredictions(context.Background(), &pb.ListMarketPredictionsRequest{MarketId: market.ID})
+	if err != nil || len(listed.Predictions) != 1 || listed.Predictions[0].Confidence != 90 {
+		t.Fatalf("ListMarketPredictions: got %+v, %v", listed, err)
+	}
+}
+
+func TestConsensusService_WatchConsensus(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	predictor := modelstesting.GenerateAgent("predictor")
+	predictor.IsClaimed = true
+	if err := db.Create(&predictor).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	market := modelstesting.GenerateMarket(1, "creator")
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	bus := outbox.NewBus(nil)
+	conn := dial(t, db, bus)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := pb.NewConsensusServiceClient(conn).WatchConsensus(ctx, &pb.WatchConsensusRequest{MarketIds: []int64{market.ID}})
+	if err != nil {
+		t.Fatalf("WatchConsensus: %v", err)
+	}
+	if msg, err := stream.Recv(); err != nil || msg.Probability != 0.5 || msg.Predictions != 0 {
+		t.Fatalf("expected the starting consensus, got %+v, %v", msg, err)
+	}
+
+	_, err = pb.NewPredictionServiceClient(conn).MakePrediction(asAgent(predictor), &pb.MakePredictionRequest{MarketId: market.ID, Outcome: "YES", Confidence: 90})
+	if err != nil {
+		t.Fatalf("MakePrediction: %v", err)
+	}
+	bus.Publish(context.Background(), models.OutboxEvent{})
+	if msg, err := stream.Recv(); err != nil || msg.Probability <= 0.5 || msg.Predictions != 1 || msg.Resolved {
+		t.Fatalf("expected the consensus to move towards YES, got %+v, %v", msg, err)
+	}
+
+	if err := db.Model(&models.Market{}).Where("id = ?", market.ID).Updates(map[string]interface{}{"is_resolved": true, "resolution_result": "YES"}).Error; err != nil {
+		t.Fatalf("resolve market: %v", err)
+	}
+	bus.Publish(context.Background(), models.OutboxEvent{})
+	if msg, err := stream.Recv(); err != nil || !msg.Resolved || msg.ResolutionResult != "YES" || msg.Predictions != 1 {
+		t.Fatalf("expected the final consensus, got %+v, %v", msg, err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("expected the stream to end once the market resolved, got %v", err)
+	}
+}
+
+func TestNew_RegistersReflection(t *testing.T) {
+	conn := dial(t, modelstesting.NewFakeDB(t), nil)
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatalf("ServerReflectionInfo: %v", err)
+	}
+	defer stream.CloseSend()
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	listed := map[string]bool{}
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		listed[service.Name] = true
+	}
+	for _, name := range []string{"socialpredict.v1.AgentService", "socialpredict.v1.MarketService", "socialpredict.v1.PredictionService", "socialpredict.v1.ConsensusService"} {
+		if !listed[name] {
+			t.Errorf("reflection does not list %s; got %v", name, listed)
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"log"
 	"math/rand"
 	"net/http"
+	"socialpredict/i18n"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/security"
 	"socialpredict/setup"
 	"socialpredict/util"
@@ -19,7 +21,7 @@ import (
 func AddUserHandler(loadEconConfig setup.EconConfigLoader) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not supported", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not supported")
 			return
 		}
 
@@ -30,14 +32,14 @@ func AddUserHandler(loadEconConfig setup.EconConfigLoader) func(http.ResponseWri
 			Username string `json:"username" validate:"required,min=3,max=30,username"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Error decoding request body", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Error decoding request body")
 			log.Printf("AddUserHandler: %v", err)
 			return
 		}
 
 		// Validate the username using security service
 		if err := securityService.Validator.ValidateStruct(req); err != nil {
-			http.Error(w, "Invalid username: "+err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid username: "+err.Error())
 			log.Printf("AddUserHandler: %v", err)
 			return
 		}
@@ -45,7 +47,7 @@ func AddUserHandler(loadEconConfig setup.EconConfigLoader) func(http.ResponseWri
 		// Sanitize the username
 		sanitizedUsername, err := securityService.Sanitizer.SanitizeUsername(req.Username)
 		if err != nil {
-			http.Error(w, "Invalid username format: "+err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid username format: "+err.Error())
 			log.Printf("AddUserHandler: %v", err)
 			return
 		}
@@ -55,7 +57,7 @@ func AddUserHandler(loadEconConfig setup.EconConfigLoader) func(http.ResponseWri
 
 		// validate that the user performing this function is indeed admin
 		if err := middleware.ValidateAdminToken(r, db); err != nil {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
 			return
 		}
 
@@ -78,26 +80,26 @@ func AddUserHandler(loadEconConfig setup.EconConfigLoader) func(http.ResponseWri
 
 		// Check uniqueness of username, displayname, and email
 		if err := checkUniqueFields(db, &user); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			log.Printf("AddUserHandler: %v", err)
 			return
 		}
 
 		password := gofakeit.Password(true, true, true, false, false, 12)
 		if err := user.HashPassword(password); err != nil {
-			http.Error(w, "Failed to hash password", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to hash password")
 			log.Printf("AddUserHandler: %v", err)
 			return
 		}
 
 		if result := db.Create(&user); result.Error != nil {
-			http.Error(w, "Failed to create user", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create user")
 			log.Printf("AddUserHandler: %v", result.Error)
 			return
 		}
 
 		responseData := map[string]interface{}{
-			"message":  "User created successfully",
+			"message":  i18n.T(r, "admin.user_created"),
 			"username": user.Username,
 			"password": password,
 			"usertype": user.UserType,
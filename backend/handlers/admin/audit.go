@@ -0,0 +1,65 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+// ListAuditLogHandler handles GET /v0/admin/audit
+// Returns audit log entries, newest first. Filters: ?actor= (principal ID,
+// e.g. user:3, or name), ?action=, ?target=, and ?since= and ?until= as
+// RFC 3339 times; ?limit= caps the count (default 50, at most 200).
+func ListAuditLogHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		limit := 50
+		if l := q.Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+				limit = parsed
+			}
+		}
+
+		query := db.Order("created_at DESC, id DESC").Limit(limit)
+		if actor := q.Get("actor"); actor != "" {
+			query = query.Where("actor = ? OR actor_name = ?", actor, actor)
+		}
+		if action := q.Get("action"); action != "" {
+			query = query.Where("action = ?", action)
+		}
+		if target := q.Get("target"); target != "" {
+			query = query.Where("target = ?", target)
+		}
+		for param, op := range map[string]string{"since": ">=", "until": "<"} {
+			v := q.Get(param)
+			if v == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, param+" must be an RFC 3339 time")
+				return
+			}
+			query = query.Where("created_at "+op+" ?", t)
+		}
+
+		var entries []models.AuditLog
+		if err := query.Find(&entries).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch audit log")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"entries": entries,
+			"count":   len(entries),
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"socialpredict/audit"
+	"socialpredict/models"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+// errAgentClaimed aborts issuing a code to an agent claimed meanwhile.
+var errAgentClaimed = errors.New("agent already claimed")
+
+// IssueClaimCodeHandler handles POST /v0/admin/agent/{id}/claim-code
+// Issues the unclaimed agent a new verification code and returns it, for an
+// admin to hand to an owner who has shown the agent is theirs, such as one
+// registered before codes were stored. The previous code stops working.
+// The audit entry records that a code was issued, not the code.
+func IssueClaimCodeHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, ok := loadSuspensionTarget(w, r, db)
+		if !ok {
+			return
+		}
+		if agent.IsClaimed {
+			response.Error(w, http.StatusConflict, response.CodeAlreadyClaimed, "Agent already claimed")
+			return
+		}
+
+		code, err := models.GenerateVerificationCode()
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to generate verification code")
+			return
+		}
+
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Agent{}).
+				Where("id = ? AND is_claimed = ?", agent.ID, false).
+				Update("claim_code", code)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errAgentClaimed
+			}
+			return audit.Record(tx, audit.ActionAgentClaimCodeIssued, audit.Target("agent", agent.ID), nil, nil)
+		})
+		if err == errAgentClaimed {
+			response.Error(w, http.StatusConflict, response.CodeAlreadyClaimed, "Agent already claimed")
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to issue verification code")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":          true,
+			"agentId":          agent.ID,
+			"verificationCode": code,
+		})
+	}
+}
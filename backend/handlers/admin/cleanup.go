@@ -2,18 +2,26 @@ package adminhandlers
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"strconv"
 
+	"socialpredict/audit"
+	"socialpredict/i18n"
+	"socialpredict/models"
+	"socialpredict/response"
+
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
 )
 
 // DeleteMarketHandler handles DELETE /v0/admin/market/{id}
+// The market is soft-deleted: it disappears from every query but keeps its
+// bets and predictions, and can be restored until PurgeDeleted removes it.
 func DeleteMarketHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
@@ -22,19 +30,24 @@ func DeleteMarketHandler(db *gorm.DB) http.HandlerFunc {
 		
 		marketID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid market ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 			return
 		}
 
-		// Delete associated bets first
-		db.Exec("DELETE FROM bets WHERE market_id = ?", marketID)
-		db.Exec("DELETE FROM agent_bets WHERE market_id = ?", marketID)
-		db.Exec("DELETE FROM predictions WHERE market_id = ?", marketID)
-		
-		// Delete the market
-		result := db.Exec("DELETE FROM markets WHERE id = ?", marketID)
-		if result.Error != nil {
-			http.Error(w, "Failed to delete market", http.StatusInternalServerError)
+		var market models.Market
+		if err := db.First(&market, marketID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Market not found")
+			return
+		}
+
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&market).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionMarketDeleted, audit.Target("market", marketID), market, nil)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to delete market")
 			return
 		}
 
@@ -51,7 +64,7 @@ func DeleteMarketHandler(db *gorm.DB) http.HandlerFunc {
 func ResetOldStatsHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
@@ -60,27 +73,34 @@ func ResetOldStatsHandler(db *gorm.DB) http.HandlerFunc {
 		// from bets. We need to delete old agent_bets
 		result := db.Exec("DELETE FROM agent_bets")
 		if result.Error != nil {
-			http.Error(w, "Failed to reset old bets", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to reset old bets")
 			return
 		}
 
 		// Also delete old regular bets from agents
-		db.Exec("DELETE FROM bets WHERE username LIKE 'agent:%'")
+		db.Exec("DELETE FROM bets WHERE username IN (SELECT username FROM users WHERE agent_id IS NOT NULL)")
+
+		if err := audit.Record(db.WithContext(r.Context()), audit.ActionBetsReset, "agent_bets", nil, map[string]int64{"rowsAffected": result.RowsAffected}); err != nil {
+			log.Printf("reset old stats: %v", err)
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": true,
-			"message": "Old bet data cleared",
+			"message": i18n.T(r, "admin.bets_cleared"),
 			"rowsAffected": result.RowsAffected,
 		})
 	}
 }
 
 // DeleteAgentHandler handles DELETE /v0/admin/agent/{id}
+// The agent is soft-deleted, as markets are: its API keys stop working and
+// it disappears from every query, but its predictions and follows are kept
+// until PurgeDeleted removes them.
 func DeleteAgentHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
@@ -89,20 +109,25 @@ func DeleteAgentHandler(db *gorm.DB) http.HandlerFunc {
 		
 		agentID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
 			return
 		}
 
-		// Delete associated data first
-		db.Exec("DELETE FROM agent_bets WHERE agent_id = ?", agentID)
-		db.Exec("DELETE FROM predictions WHERE agent_id = ?", agentID)
-		db.Exec("DELETE FROM agent_follows WHERE follower_id = ? OR followed_id = ?", agentID, agentID)
-		db.Exec("DELETE FROM prediction_votes WHERE voter_id = ? AND voter_type = 'agent'", agentID)
-		
-		// Delete the agent
-		result := db.Exec("DELETE FROM agents WHERE id = ?", agentID)
-		if result.Error != nil {
-			http.Error(w, "Failed to delete agent", http.StatusInternalServerError)
+		var agent models.Agent
+		if err := db.First(&agent, agentID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Agent not found")
+			return
+		}
+		agent.APIKey = "" // keep the key out of the audit log
+
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&agent).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionAgentDeleted, audit.Target("agent", agentID), agent, nil)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to delete agent")
 			return
 		}
 
@@ -0,0 +1,108 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/agentdata"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ScheduleErasureHandler handles POST /v0/admin/agent/{id}/erasure
+// Has the agent's personal data erased on the next run of the erasure job,
+// without the grace period an owner gets, for requests made outside the
+// API. The agent is deactivated now. Unlike DELETE /v0/admin/agent/{id} its
+// scores and predictions stay.
+func ScheduleErasureHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+			return
+		}
+
+		requestedBy := middleware.PrincipalFromContext(r.Context()).ID()
+		erasure, err := agentdata.ScheduleErasure(r.Context(), db, agentID, requestedBy, time.Now())
+		switch {
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+			return
+		case stderrors.Is(err, agentdata.ErrErasureRequested):
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Agent erasure already requested")
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to schedule erasure")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"erasure": erasure,
+		})
+	}
+}
+
+// ListErasuresHandler handles GET /v0/admin/erasures
+// Returns erasures, newest first, with the deletion certificates of those
+// carried out. Filters: ?status= and ?agentId=; ?limit= caps the count
+// (default 50, at most 200).
+func ListErasuresHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		limit := 50
+		if l := q.Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+				limit = parsed
+			}
+		}
+
+		query := db.Order("id DESC").Limit(limit)
+		if status := q.Get("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		if v := q.Get("agentId"); v != "" {
+			agentID, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "agentId must be a number")
+				return
+			}
+			query = query.Where("agent_id = ?", agentID)
+		}
+
+		var erasures []models.DataErasure
+		if err := query.Find(&erasures).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch erasures")
+			return
+		}
+		type entry struct {
+			models.DataErasure
+			Certificate *models.ErasureCertificate `json:"certificate,omitempty"`
+		}
+		entries := make([]entry, 0, len(erasures))
+		for _, erasure := range erasures {
+			certificate, err := erasure.Certificate()
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to read erasure certificate")
+				return
+			}
+			entries = append(entries, entry{DataErasure: erasure, Certificate: certificate})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"erasures": entries,
+			"count":    len(entries),
+		})
+	}
+}
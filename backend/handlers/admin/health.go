@@ -0,0 +1,78 @@
+package adminhandlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"socialpredict/response"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+// healthPingTimeout bounds the database ping, so a stuck database shows
+// as unhealthy rather than hanging the check.
+const healthPingTimeout = 2 * time.Second
+
+// poolStats is the connection pool's current state.
+type poolStats struct {
+	OpenConnections   int   `json:"openConnections"`
+	InUse             int   `json:"inUse"`
+	Idle              int   `json:"idle"`
+	WaitCount         int64 `json:"waitCount"`
+	WaitDurationMs    int64 `json:"waitDurationMs"`
+	MaxIdleClosed     int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed int64 `json:"maxLifetimeClosed"`
+}
+
+// HealthHandler handles GET /v0/admin/health
+// Pings the database and reports the pool settings in effect alongside the
+// pool's current state. Unlike the public /health it touches the database,
+// and answers 503 when the ping fails.
+func HealthHandler(db *gorm.DB, config func() setup.Database) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to reach the connection pool")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), healthPingTimeout)
+		defer cancel()
+		started := time.Now()
+		pingErr := sqlDB.PingContext(ctx)
+		pingMs := time.Since(started).Milliseconds()
+
+		stats := sqlDB.Stats()
+		database := map[string]interface{}{
+			"reachable": pingErr == nil,
+			"pingMs":    pingMs,
+			"config":    config(),
+			"pool": poolStats{
+				OpenConnections:   stats.OpenConnections,
+				InUse:             stats.InUse,
+				Idle:              stats.Idle,
+				WaitCount:         stats.WaitCount,
+				WaitDurationMs:    stats.WaitDuration.Milliseconds(),
+				MaxIdleClosed:     stats.MaxIdleClosed,
+				MaxIdleTimeClosed: stats.MaxIdleTimeClosed,
+				MaxLifetimeClosed: stats.MaxLifetimeClosed,
+			},
+		}
+		status, code := "healthy", http.StatusOK
+		if pingErr != nil {
+			status, code = "unhealthy", http.StatusServiceUnavailable
+			database["error"] = pingErr.Error()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":   status,
+			"database": database,
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"socialpredict/models/modelstesting"
+	"socialpredict/setup"
+)
+
+func TestHealthHandler_ReportsPoolConfigAndStats(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	config := setup.DefaultDatabase
+	config.MaxOpenConns = 7
+
+	rec := httptest.NewRecorder()
+	HealthHandler(db, func() setup.Database { return config })(rec, httptest.NewRequest("GET", "/v0/admin/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Status   string `json:"status"`
+		Database struct {
+			Reachable bool           `json:"reachable"`
+			Config    setup.Database `json:"config"`
+			Pool      poolStats      `json:"pool"`
+		} `json:"database"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Status != "healthy" || !body.Database.Reachable {
+		t.Fatalf("expected a healthy, reachable database, got %+v", body)
+	}
+	if body.Database.Config.MaxOpenConns != 7 {
+		t.Fatalf("expected the configured pool size, got %d", body.Database.Config.MaxOpenConns)
+	}
+	if body.Database.Pool.OpenConnections < 1 {
+		t.Fatalf("expected the ping to leave a connection open, got %+v", body.Database.Pool)
+	}
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+	rec = httptest.NewRecorder()
+	HealthHandler(db, func() setup.Database { return config })(rec, httptest.NewRequest("GET", "/v0/admin/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 once the database is closed, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,95 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/adminjobs"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// jobResponse is a job with its progress as a percentage, which is nil
+// until the job knows how much work there is.
+func jobResponse(job *models.AdminJob) map[string]interface{} {
+	var percent *float64
+	if job.Total > 0 {
+		p := float64(job.Processed) / float64(job.Total) * 100
+		percent = &p
+	} else if job.Status == models.AdminJobSucceeded {
+		p := 100.0
+		percent = &p
+	}
+	return map[string]interface{}{
+		"success":  true,
+		"job":      job,
+		"percent":  percent,
+		"finished": job.Finished(),
+	}
+}
+
+func jobID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid job ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// GetJobHandler handles GET /v0/admin/jobs/{id}
+// Returns a background job's status and progress (items processed out of
+// the total).
+func GetJobHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := jobID(w, r)
+		if !ok {
+			return
+		}
+
+		job, err := adminjobs.Get(db, id)
+		if errors.Is(err, adminjobs.ErrJobNotFound) {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Job not found")
+			return
+		} else if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch job")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jobResponse(job))
+	}
+}
+
+// CancelJobHandler handles POST /v0/admin/jobs/{id}/cancel
+// Cancels a queued job at once; a running job stops at its next progress
+// report, so it may show as running for a moment longer.
+func CancelJobHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := jobID(w, r)
+		if !ok {
+			return
+		}
+
+		job, err := adminjobs.Cancel(db, id)
+		switch {
+		case errors.Is(err, adminjobs.ErrJobNotFound):
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Job not found")
+			return
+		case errors.Is(err, adminjobs.ErrFinished):
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Job has already finished")
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to cancel job")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jobResponse(job))
+	}
+}
@@ -0,0 +1,66 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"socialpredict/migration"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+// ListMigrationsHandler handles GET /v0/admin/migrations
+// Lists every migration with whether it is applied, when, whether its
+// source changed since and whether it can be rolled back, with counts of
+// the pending and changed ones.
+func ListMigrationsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses, err := migration.StatusOf(db)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to read migration status")
+			return
+		}
+
+		var pending, modified, unknown int
+		for _, s := range statuses {
+			switch {
+			case s.Unknown:
+				unknown++
+			case !s.Applied:
+				pending++
+			case s.Modified:
+				modified++
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"migrations": statuses,
+			"pending":    pending,
+			"modified":   modified,
+			"unknown":    unknown,
+		})
+	}
+}
+
+// PlanMigrationsHandler handles GET /v0/admin/migrations/plan
+// Returns the SQL the pending migrations would run. They are run in a
+// transaction that is rolled back, so the database is left as it was.
+func PlanMigrationsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		plans, err := migration.DryRun(db)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to plan migrations: "+err.Error())
+			return
+		}
+		if plans == nil {
+			plans = []migration.Plan{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"plan": plans,
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/platformconfig"
+	"socialpredict/setup"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ParameterRequest is the request body for changing a platform parameter
+type ParameterRequest struct {
+	Value      float64 `json:"value" validate:"required"`
+	ProposalID *int64  `json:"proposalId" validate:"omitempty,gt=0"` // the approved governance proposal behind the change
+}
+
+// parameterView is a platform parameter with its value in effect, its
+// setup.yaml default and the override, if any.
+type parameterView struct {
+	platformconfig.Parameter
+	Value    float64                `json:"value"`
+	Default  float64                `json:"default"`
+	Override *models.PlatformConfig `json:"override,omitempty"`
+}
+
+// ListParametersHandler handles GET /v0/admin/parameters
+// Returns every platform parameter that can be changed at runtime.
+func ListParametersHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var overrides []models.PlatformConfig
+		if err := db.Find(&overrides).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch parameters")
+			return
+		}
+		byName := make(map[string]*models.PlatformConfig, len(overrides))
+		for i := range overrides {
+			byName[overrides[i].Name] = &overrides[i]
+		}
+
+		current := platformconfig.Current(db)
+		parameters := platformconfig.Parameters()
+		views := make([]parameterView, len(parameters))
+		for i, p := range parameters {
+			views[i] = parameterView{
+				Parameter: p,
+				Value:     p.ValueIn(current),
+				Default:   p.ValueIn(setup.EconomicsConfig()),
+				Override:  byName[p.Name],
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"parameters": views,
+		})
+	}
+}
+
+// SetParameterHandler handles PUT /v0/admin/parameters/{name}
+// Overrides a platform parameter. A change the governance process voted
+// for names the approved proposal, which is recorded with it.
+func SetParameterHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ParameterRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		if !approvedProposal(w, db, req.ProposalID) {
+			return
+		}
+
+		updatedBy := ""
+		if p := middleware.PrincipalFromContext(r.Context()); p != nil {
+			updatedBy = p.ID()
+		}
+		name := mux.Vars(r)["name"]
+		var row models.PlatformConfig
+		err := db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			before := parameterOverride(tx, name)
+			var err error
+			if row, err = platformconfig.Set(tx, name, req.Value, updatedBy, req.ProposalID); err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionParameterSet, audit.Target("parameter", name), before, row)
+		})
+		switch {
+		case stderrors.Is(err, platformconfig.ErrUnknownParameter):
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Parameter not found")
+			return
+		case stderrors.Is(err, platformconfig.ErrInvalidValue):
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to set parameter")
+			return
+		}
+		// Set cleared the cache before the commit; clear whatever was read since.
+		platformconfig.Invalidate()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"parameter": row,
+		})
+	}
+}
+
+// ResetParameterHandler handles DELETE /v0/admin/parameters/{name}
+// Removes a parameter's override so its setup.yaml default applies again.
+func ResetParameterHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["name"]
+		err := db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			before := parameterOverride(tx, name)
+			if err := platformconfig.Reset(tx, name); err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionParameterReset, audit.Target("parameter", name), before, nil)
+		})
+		if stderrors.Is(err, platformconfig.ErrUnknownParameter) {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Parameter not found")
+			return
+		} else if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to reset parameter")
+			return
+		}
+		platformconfig.Invalidate()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+// approvedProposal writes an error response and returns false if a
+// proposal ID is given and the proposal has not been approved.
+func approvedProposal(w http.ResponseWriter, db *gorm.DB, proposalID *int64) bool {
+	if proposalID == nil {
+		return true
+	}
+	var proposal models.Proposal
+	if err := db.First(&proposal, *proposalID).Error; err != nil {
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Proposal not found")
+		return false
+	}
+	switch proposal.Status {
+	case models.ProposalStatusApproved, models.ProposalStatusBuilding, models.ProposalStatusDeployed:
+		return true
+	}
+	response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "The proposal has not been approved")
+	return false
+}
+
+// parameterOverride returns the stored override of the named parameter, or
+// nil if it has none.
+func parameterOverride(db *gorm.DB, name string) *models.PlatformConfig {
+	var row models.PlatformConfig
+	if err := db.Where("name = ?", name).First(&row).Error; err != nil {
+		return nil
+	}
+	return &row
+}
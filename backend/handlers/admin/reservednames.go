@@ -0,0 +1,138 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ReservedNameRequest is the request body for reserving an agent name
+type ReservedNameRequest struct {
+	Pattern  string `json:"pattern" validate:"required,min=2,max=50,safe_string"`
+	IsPrefix bool   `json:"isPrefix"`
+	Reason   string `json:"reason" validate:"max=200,safe_string"`
+}
+
+// Normalize trims surrounding whitespace.
+func (r *ReservedNameRequest) Normalize() {
+	r.Pattern = strings.TrimSpace(r.Pattern)
+	r.Reason = strings.TrimSpace(r.Reason)
+}
+
+// ListReservedNamesHandler handles GET /v0/admin/reserved-names
+func ListReservedNamesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.ValidateAdminToken(r, db); err != nil {
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+
+		var names []models.ReservedAgentName
+		if result := db.Order("pattern ASC").Find(&names); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch reserved names")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":       true,
+			"reservedNames": names,
+		})
+	}
+}
+
+// CreateReservedNameHandler handles POST /v0/admin/reserved-names
+// Reserves a name, or with isPrefix every name starting with it. Agents
+// already using a matching name keep it.
+func CreateReservedNameHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.ValidateAdminToken(r, db); err != nil {
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+
+		var req ReservedNameRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		var existing int64
+		if result := db.Model(&models.ReservedAgentName{}).Where("pattern = ?", req.Pattern).Count(&existing); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if existing > 0 {
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Name is already reserved")
+			return
+		}
+
+		reserved := models.ReservedAgentName{Pattern: req.Pattern, IsPrefix: req.IsPrefix, Reason: req.Reason}
+		err := db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&reserved).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionReservedNameCreated, audit.Target("reserved_name", reserved.Pattern), nil, reserved)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to reserve name")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":      true,
+			"reservedName": reserved,
+		})
+	}
+}
+
+// DeleteReservedNameHandler handles DELETE /v0/admin/reserved-names/{id}
+func DeleteReservedNameHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.ValidateAdminToken(r, db); err != nil {
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid reserved name ID")
+			return
+		}
+
+		var reserved models.ReservedAgentName
+		if err := db.First(&reserved, id).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Reserved name not found")
+			return
+		}
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&reserved).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionReservedNameDeleted, audit.Target("reserved_name", reserved.Pattern), reserved, nil)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to delete reserved name")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"deleted": id,
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package adminhandlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/audit"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/setup"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// RestoreMarketHandler handles POST /v0/admin/market/{id}/restore
+// Brings back a deleted market that has not been purged yet.
+func RestoreMarketHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+
+		var market models.Market
+		if err := db.Unscoped().First(&market, marketID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Market not found")
+			return
+		}
+		if !market.DeletedAt.Valid {
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Market is not deleted")
+			return
+		}
+
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Model(&market).Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionMarketRestored, audit.Target("market", marketID), nil, market)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to restore market")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"restored": marketID,
+		})
+	}
+}
+
+// RestoreAgentHandler handles POST /v0/admin/agent/{id}/restore
+// Brings back a deleted agent that has not been purged yet; its API keys
+// work again.
+func RestoreAgentHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+			return
+		}
+
+		var agent models.Agent
+		if err := db.Unscoped().First(&agent, agentID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Agent not found")
+			return
+		}
+		if !agent.DeletedAt.Valid {
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Agent is not deleted")
+			return
+		}
+
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Model(&agent).Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+			agent.APIKey = "" // keep the key out of the audit log
+			return audit.Record(tx, audit.ActionAgentRestored, audit.Target("agent", agentID), nil, agent)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to restore agent")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"restored": agentID,
+		})
+	}
+}
+
+// PurgeDeleted removes for good the markets and agents deleted more than
+// the retention period before now, with the bets, predictions, follows and
+// votes that hang off them, and returns how many it removed. The scheduler
+// runs it.
+func PurgeDeleted(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+	cutoff := now.AddDate(0, 0, -setup.EconomicsConfig().Retention.OrDefaults().DeletedDays)
+
+	var marketIDs, agentIDs []int64
+	if err := db.Unscoped().Model(&models.Market{}).Where("deleted_at < ?", cutoff).Pluck("id", &marketIDs).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Unscoped().Model(&models.Agent{}).Where("deleted_at < ?", cutoff).Pluck("id", &agentIDs).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range marketIDs {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error { return purgeMarket(tx, id) }); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	for _, id := range agentIDs {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error { return purgeAgent(tx, id) }); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func purgeMarket(tx *gorm.DB, marketID int64) error {
+	for _, statement := range []string{
+		"DELETE FROM bets WHERE market_id = ?",
+		"DELETE FROM agent_bets WHERE market_id = ?",
+		"DELETE FROM predictions WHERE market_id = ?",
+		"DELETE FROM markets WHERE id = ?",
+	} {
+		if err := tx.Exec(statement, marketID).Error; err != nil {
+			return err
+		}
+	}
+	return audit.Record(tx, audit.ActionMarketPurged, audit.Target("market", marketID), nil, nil)
+}
+
+func purgeAgent(tx *gorm.DB, agentID int64) error {
+	agentType := string(models.ActorTypeAgent)
+	if err := tx.Exec("DELETE FROM agent_bets WHERE agent_id = ?", agentID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM predictions WHERE agent_id = ?", agentID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM agent_follows WHERE (follower_type = ? AND follower_id = ?) OR (followed_type = ? AND followed_id = ?)",
+		agentType, agentID, agentType, agentID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM prediction_votes WHERE voter_type = ? AND voter_id = ?", agentType, agentID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM agents WHERE id = ?", agentID).Error; err != nil {
+		return err
+	}
+	return audit.Record(tx, audit.ActionAgentPurged, audit.Target("agent", agentID), nil, nil)
+}
@@ -0,0 +1,169 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// RoleRequest is the request body for granting a user a staff role
+type RoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin moderator operator"`
+}
+
+// Normalize lower-cases the role.
+func (r *RoleRequest) Normalize() {
+	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
+}
+
+// staffMember is a user holding a staff role.
+type staffMember struct {
+	models.AdminRole
+	Username string `json:"username"`
+}
+
+// ListRolesHandler handles GET /v0/admin/roles
+// Returns every role granted. Users of type ADMIN are admins without one
+// and are not listed.
+func ListRolesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var staff []staffMember
+		err := db.Model(&models.AdminRole{}).
+			Select("admin_roles.*, users.username").
+			Joins("JOIN users ON users.id = admin_roles.user_id").
+			Order("users.username ASC").
+			Scan(&staff).Error
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch roles")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"roles":   staff,
+		})
+	}
+}
+
+// GrantRoleHandler handles PUT /v0/admin/roles/{username}
+// Gives the user a staff role, replacing any they had.
+func GrantRoleHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RoleRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		user, ok := loadStaffTarget(w, r, db)
+		if !ok {
+			return
+		}
+		before, err := staffRoleOf(db, user.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+
+		role := models.AdminRole{UserID: user.ID, Role: req.Role, GrantedBy: middleware.PrincipalFromContext(r.Context()).ID()}
+		if before != nil {
+			role.CreatedAt = before.CreatedAt
+		}
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&role).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionRoleGranted, audit.Target("user", user.Username), before, role)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to grant role")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"role":    staffMember{AdminRole: role, Username: user.Username},
+		})
+	}
+}
+
+// RevokeRoleHandler handles DELETE /v0/admin/roles/{username}
+func RevokeRoleHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := loadStaffTarget(w, r, db)
+		if !ok {
+			return
+		}
+		before, err := staffRoleOf(db, user.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if before == nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "User has no staff role")
+			return
+		}
+
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(before).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionRoleRevoked, audit.Target("user", user.Username), before, nil)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to revoke role")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+// loadStaffTarget loads the user named in the request whose role is to
+// change, writing the error response and returning false if there is none,
+// they are an ADMIN user, whose role cannot change, or they are the caller.
+func loadStaffTarget(w http.ResponseWriter, r *http.Request, db *gorm.DB) (*models.User, bool) {
+	var user models.User
+	if err := db.Where("username = ?", mux.Vars(r)["username"]).First(&user).Error; err != nil {
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "User not found")
+		return nil, false
+	}
+	if user.UserType == "ADMIN" {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "ADMIN users are always admins")
+		return nil, false
+	}
+	if p := middleware.PrincipalFromContext(r.Context()); p != nil && p.User != nil && p.User.ID == user.ID {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "You cannot change your own role")
+		return nil, false
+	}
+	return &user, true
+}
+
+// staffRoleOf returns the role granted to the user, or nil if none.
+func staffRoleOf(db *gorm.DB, userID int64) (*models.AdminRole, error) {
+	var role models.AdminRole
+	err := db.Where("user_id = ?", userID).First(&role).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
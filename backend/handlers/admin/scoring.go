@@ -0,0 +1,81 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"socialpredict/errors"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/scoring"
+	"socialpredict/validation"
+
+	"gorm.io/gorm"
+)
+
+// WhatIfRequest is a proposed set of composite score weights. They need
+// not sum to 1; they are scaled to.
+type WhatIfRequest struct {
+	Accuracy   float64 `json:"accuracy" validate:"min=0"`
+	Engagement float64 `json:"engagement" validate:"min=0"`
+	Creator    float64 `json:"creator" validate:"min=0"`
+	Activity   float64 `json:"activity" validate:"min=0"`
+	TopN       int     `json:"topN" validate:"omitempty,min=1,max=100"`  // default 10
+	Limit      int     `json:"limit" validate:"omitempty,min=1,max=500"` // default 50
+}
+
+// WhatIfScoringHandler handles POST /v0/admin/scoring/what-if
+// Recomputes the leaderboard under proposed composite weights, without
+// changing any score, and reports the rank churn against the current
+// weights: how many agents move and how far, how well the two rankings
+// agree and how much of the top N survives. Returns the proposed top
+// entries and the biggest movers, up to limit each. Results are cached for
+// a few minutes per set of weights.
+func WhatIfScoringHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req WhatIfRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+		if req.TopN == 0 {
+			req.TopN = 10
+		}
+		if req.Limit == 0 {
+			req.Limit = 50
+		}
+
+		proposed := models.CompositeWeights{
+			Accuracy:   req.Accuracy,
+			Engagement: req.Engagement,
+			Creator:    req.Creator,
+			Activity:   req.Activity,
+		}
+		if _, err := proposed.Normalized(); err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+			return
+		}
+
+		report, err := scoring.WhatIf(r.Context(), db, proposed, req.TopN)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to compute what-if leaderboard")
+			return
+		}
+
+		leaderboard := report.Agents
+		if len(leaderboard) > req.Limit {
+			leaderboard = leaderboard[:req.Limit]
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":         true,
+			"currentWeights":  report.CurrentWeights,
+			"proposedWeights": report.ProposedWeights,
+			"summary":         report.Summary,
+			"leaderboard":     leaderboard,
+			"movers":          report.Movers(req.Limit),
+			"computedAt":      report.ComputedAt,
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"socialpredict/response"
+	"socialpredict/seed"
+
+	"gorm.io/gorm"
+)
+
+// SeedFixturesHandler handles POST /v0/admin/seed
+// Fills the database with the development dataset of seed.SeedFixtures.
+// The body, which may be empty, sizes it: {"agents": 20, "markets": 12,
+// "validators": 5, "proposals": 3, "seed": 1}. The route is only
+// registered when DEV_SEED_ENABLED is set.
+func SeedFixturesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var opts seed.FixtureOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil && !errors.Is(err, io.EOF) {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid request body")
+			return
+		}
+		if err := opts.OrDefaults().Validate(); err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeValidationFailed, err.Error())
+			return
+		}
+
+		result, err := seed.SeedFixtures(r.Context(), db, opts, time.Now())
+		switch {
+		case errors.Is(err, seed.ErrFixturesExist):
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Fixtures were already seeded")
+			return
+		case errors.Is(err, seed.ErrNoAdmin):
+			response.Error(w, http.StatusConflict, response.CodeConflict, err.Error())
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to seed fixtures")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(result)
+	}
+}
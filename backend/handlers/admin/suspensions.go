@@ -0,0 +1,199 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/notifications"
+	"socialpredict/response"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// SuspendAgentRequest is the request body for suspending or banning an
+// agent. Hours is how long a suspension lasts; a ban lasts until lifted and
+// takes none.
+type SuspendAgentRequest struct {
+	Kind   string `json:"kind" validate:"required,oneof=suspension ban"`
+	Reason string `json:"reason" validate:"required,min=3,max=500"`
+	Hours  int    `json:"hours" validate:"min=0,max=8760"`
+}
+
+// Normalize trims the reason and lower-cases the kind.
+func (r *SuspendAgentRequest) Normalize() {
+	r.Kind = strings.ToLower(strings.TrimSpace(r.Kind))
+	r.Reason = strings.TrimSpace(r.Reason)
+}
+
+// ListAgentSuspensionsHandler handles GET /v0/admin/agent/{id}/suspensions
+// Returns every suspension and ban the agent has had, newest first.
+func ListAgentSuspensionsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, ok := loadSuspensionTarget(w, r, db)
+		if !ok {
+			return
+		}
+
+		var suspensions []models.AgentSuspension
+		if err := db.Where("agent_id = ?", agent.ID).Order("id DESC").Find(&suspensions).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch suspensions")
+			return
+		}
+		active, err := models.ActiveSuspension(db, agent.ID, time.Now())
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch suspensions")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"active":      active,
+			"suspensions": suspensions,
+		})
+	}
+}
+
+// SuspendAgentHandler handles POST /v0/admin/agent/{id}/suspensions
+// Suspends the agent for a number of hours or bans it, with a reason, and
+// notifies its owner. While it is in force the agent's API keys are
+// refused with AGENT_SUSPENDED saying until when and why.
+func SuspendAgentHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req SuspendAgentRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+		if req.Kind == models.SuspensionTemporary && req.Hours == 0 {
+			errors.WriteValidationError(w, []errors.FieldError{{Field: "hours", Rule: "required", Message: "hours is required for a suspension"}})
+			return
+		}
+		if req.Kind == models.SuspensionBan && req.Hours != 0 {
+			errors.WriteValidationError(w, []errors.FieldError{{Field: "hours", Rule: "excluded", Message: "a ban lasts until lifted and takes no hours"}})
+			return
+		}
+
+		agent, ok := loadSuspensionTarget(w, r, db)
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+		suspension := models.AgentSuspension{
+			AgentID:   agent.ID,
+			Kind:      req.Kind,
+			Reason:    req.Reason,
+			CreatedBy: middleware.PrincipalFromContext(r.Context()).ID(),
+			CreatedAt: now,
+		}
+		if req.Kind == models.SuspensionTemporary {
+			expiresAt := now.Add(time.Duration(req.Hours) * time.Hour)
+			suspension.ExpiresAt = &expiresAt
+		}
+
+		err := db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&suspension).Error; err != nil {
+				return err
+			}
+			if err := notifications.SendAgentSuspended(tx, agent.ID, notifications.AgentSuspended{
+				SuspensionID: suspension.ID,
+				Kind:         suspension.Kind,
+				Reason:       suspension.Reason,
+				ExpiresAt:    suspension.ExpiresAt,
+			}); err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionAgentSuspended, audit.Target("agent", agent.ID), nil, suspension)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to suspend agent")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"suspension": suspension,
+		})
+	}
+}
+
+// LiftAgentSuspensionHandler handles DELETE /v0/admin/agent/{id}/suspensions
+// Lifts every suspension and ban in force on the agent and notifies its
+// owner. Expired suspensions are kept as they were, for the record.
+func LiftAgentSuspensionHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, ok := loadSuspensionTarget(w, r, db)
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+		active, err := models.ActiveSuspensions(db, agent.ID, now)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if len(active) == 0 {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Agent is not suspended")
+			return
+		}
+
+		liftedBy := middleware.PrincipalFromContext(r.Context()).ID()
+		ids := make([]int64, len(active))
+		for i := range active {
+			ids[i] = active[i].ID
+		}
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			for i := range active {
+				before := active[i]
+				active[i].LiftedAt = &now
+				active[i].LiftedBy = liftedBy
+				if err := tx.Save(&active[i]).Error; err != nil {
+					return err
+				}
+				if err := audit.Record(tx, audit.ActionAgentSuspensionLifted, audit.Target("agent", agent.ID), before, active[i]); err != nil {
+					return err
+				}
+			}
+			return notifications.SendAgentSuspensionLifted(tx, agent.ID, notifications.AgentSuspensionLifted{SuspensionIDs: ids})
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to lift suspension")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"lifted":  active,
+		})
+	}
+}
+
+// loadSuspensionTarget loads the agent named in the request, writing the
+// error response and returning false if there is none.
+func loadSuspensionTarget(w http.ResponseWriter, r *http.Request, db *gorm.DB) (*models.Agent, bool) {
+	agentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+		return nil, false
+	}
+	var agent models.Agent
+	if err := db.First(&agent, agentID).Error; err != nil {
+		response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+		return nil, false
+	}
+	return &agent, true
+}
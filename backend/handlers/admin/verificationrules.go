@@ -0,0 +1,140 @@
+package adminhandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/verificationrules"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// verificationRuleView is a verification rule in effect with its stored
+// override, if any.
+type verificationRuleView struct {
+	verificationrules.Rule
+	Override *models.VerificationRule `json:"override,omitempty"`
+}
+
+// ListVerificationRulesHandler handles GET /v0/admin/verification/rules
+// Returns the rules market auto-verification runs: every built-in check
+// with its severity, then the keyword and regex rules.
+func ListVerificationRulesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var overrides []models.VerificationRule
+		if err := db.Find(&overrides).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch verification rules")
+			return
+		}
+		byName := make(map[string]*models.VerificationRule, len(overrides))
+		for i := range overrides {
+			byName[overrides[i].Name] = &overrides[i]
+		}
+
+		rules := verificationrules.Current(db)
+		views := make([]verificationRuleView, len(rules))
+		for i, rule := range rules {
+			views[i] = verificationRuleView{Rule: rule, Override: byName[rule.Name]}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"rules":   views,
+		})
+	}
+}
+
+// SetVerificationRuleHandler handles PUT /v0/admin/verification/rules/{name}
+// Changes a built-in check's severity, replaces a built-in keyword rule or
+// adds a keyword or regex rule. A change the governance process voted for
+// names the approved proposal, which is recorded with it.
+func SetVerificationRuleHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req models.VerificationRuleRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+		if !approvedProposal(w, db, req.ProposalID) {
+			return
+		}
+
+		updatedBy := ""
+		if p := middleware.PrincipalFromContext(r.Context()); p != nil {
+			updatedBy = p.ID()
+		}
+		name := mux.Vars(r)["name"]
+		var row models.VerificationRule
+		err := db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			before := verificationRuleOverride(tx, name)
+			var err error
+			if row, err = verificationrules.Set(tx, name, req, updatedBy); err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionVerificationRuleSet, audit.Target("verification_rule", name), before, row)
+		})
+		switch {
+		case stderrors.Is(err, verificationrules.ErrInvalidRule):
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to set verification rule")
+			return
+		}
+		// Set cleared the cache before the commit; clear whatever was read since.
+		verificationrules.Invalidate()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"rule":    row,
+		})
+	}
+}
+
+// ResetVerificationRuleHandler handles DELETE /v0/admin/verification/rules/{name}
+// Removes a stored rule, so a built-in rule of that name applies again.
+func ResetVerificationRuleHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["name"]
+		err := db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			before := verificationRuleOverride(tx, name)
+			if err := verificationrules.Reset(tx, name); err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionVerificationRuleReset, audit.Target("verification_rule", name), before, nil)
+		})
+		if stderrors.Is(err, verificationrules.ErrUnknownRule) {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Verification rule not found")
+			return
+		} else if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to reset verification rule")
+			return
+		}
+		verificationrules.Invalidate()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+// verificationRuleOverride returns the stored rule called name, or nil if
+// there is none.
+func verificationRuleOverride(db *gorm.DB, name string) *models.VerificationRule {
+	var row models.VerificationRule
+	if err := db.Where("name = ?", name).First(&row).Error; err != nil {
+		return nil
+	}
+	return &row
+}
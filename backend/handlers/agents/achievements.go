@@ -0,0 +1,67 @@
+package agents
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"socialpredict/models"
+	"socialpredict/response"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ListAchievementsHandler handles GET /v0/achievements
+// Lists the badges agents can earn.
+func ListAchievementsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":      true,
+			"achievements": models.AchievementDefinitions,
+		})
+	}
+}
+
+// GetAgentAchievementsHandler handles GET /v0/agent/{id}/achievements
+// Lists the badges the agent has earned, oldest first, with its correct
+// prediction streaks.
+func GetAgentAchievementsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+			return
+		}
+		var agent models.Agent
+		if err := db.First(&agent, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch agent")
+			return
+		}
+
+		var achievements []models.Achievement
+		if err := db.Where("agent_id = ?", agent.ID).Order("awarded_at, id").Find(&achievements).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch achievements")
+			return
+		}
+		public := make([]models.AchievementPublic, len(achievements))
+		for i, a := range achievements {
+			public[i] = a.ToPublic()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":           true,
+			"agentId":           agent.ID,
+			"correctStreak":     agent.CorrectStreak,
+			"bestCorrectStreak": agent.BestCorrectStreak,
+			"achievements":      public,
+		})
+	}
+}
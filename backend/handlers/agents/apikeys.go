@@ -0,0 +1,191 @@
+package agents
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"socialpredict/errors"
+	"socialpredict/i18n"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/validation"
+
+	"gorm.io/gorm"
+)
+
+// MaxActiveKeysPerAgent caps how many unexpired, unrevoked keys an agent holds.
+const MaxActiveKeysPerAgent = 5
+
+// DefaultRotationGraceMinutes is how long the rotated-out key keeps working
+// when the request does not say.
+const DefaultRotationGraceMinutes = 60
+
+var (
+	errTooManyKeys   = stderrors.New("too many active keys")
+	errScopesNotHeld = stderrors.New("new key cannot have scopes the current key lacks")
+)
+
+// RotateKeyRequest is the request body for rotating an agent API key
+type RotateKeyRequest struct {
+	Name          string   `json:"name" validate:"max=100,safe_string"`
+	ExpiresInDays int      `json:"expiresInDays" validate:"omitempty,gte=1,lte=365"`                                  // new key's lifetime, 0 for no expiry
+	GraceMinutes  *int     `json:"graceMinutes" validate:"omitempty,gte=0,lte=10080"`                                 // how long the current key keeps working
+	Scopes        []string `json:"scopes" validate:"max=6,dive,oneof=read predict markets social governance account"` // omitted to keep the current key's scopes
+}
+
+// Normalize trims the key name and names unnamed keys.
+func (r *RotateKeyRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		r.Name = "rotated"
+	}
+}
+
+// RotateKeyHandler handles POST /v0/agents/keys/rotate
+// Mints a new key for the calling agent and expires the key used to make
+// the request after the grace period, so clients can switch over without
+// downtime. The new key is returned once and only its hash is stored. It
+// keeps the current key's scopes unless the request narrows them; a scoped
+// key cannot mint a key with scopes it does not have.
+func RotateKeyHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+		currentKey := middleware.AgentAPIKeyFromRequest(r)
+
+		var req RotateKeyRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		apiKey, err := models.GenerateAPIKey()
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to generate API key")
+			return
+		}
+
+		now := time.Now()
+		grace := DefaultRotationGraceMinutes
+		if req.GraceMinutes != nil {
+			grace = *req.GraceMinutes
+		}
+		currentExpiresAt := now.Add(time.Duration(grace) * time.Minute)
+
+		newKey := models.NewAgentAPIKey(agent.ID, req.Name, apiKey)
+		if req.ExpiresInDays > 0 {
+			expiresAt := now.AddDate(0, 0, req.ExpiresInDays)
+			newKey.ExpiresAt = &expiresAt
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var current models.AgentAPIKey
+			err := tx.Where("key_hash = ?", models.HashAgentAPIKey(currentKey)).First(&current).Error
+			switch {
+			case err == nil:
+				if current.ExpiresAt == nil || current.ExpiresAt.After(currentExpiresAt) {
+					current.ExpiresAt = &currentExpiresAt
+				}
+				if err := tx.Save(&current).Error; err != nil {
+					return err
+				}
+			case stderrors.Is(err, gorm.ErrRecordNotFound):
+				// A legacy key stops authenticating once the agent has key
+				// rows, so it is recorded here to honour the grace period.
+				current = models.NewAgentAPIKey(agent.ID, "default", currentKey)
+				current.ExpiresAt = &currentExpiresAt
+				if err := tx.Create(&current).Error; err != nil {
+					return err
+				}
+			default:
+				return err
+			}
+
+			if len(req.Scopes) == 0 {
+				newKey.Scopes = current.Scopes
+			} else {
+				for _, scope := range req.Scopes {
+					if !current.HasScope(scope) {
+						return errScopesNotHeld
+					}
+				}
+				newKey.SetScopes(req.Scopes)
+			}
+
+			var activeKeys int64
+			if err := tx.Model(&models.AgentAPIKey{}).
+				Where("agent_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", agent.ID, now).
+				Count(&activeKeys).Error; err != nil {
+				return err
+			}
+			if activeKeys >= MaxActiveKeysPerAgent {
+				return errTooManyKeys
+			}
+
+			return tx.Create(&newKey).Error
+		})
+		if stderrors.Is(err, errScopesNotHeld) {
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "The new key cannot have scopes the current key lacks")
+			return
+		}
+		if stderrors.Is(err, errTooManyKeys) {
+			response.Error(w, http.StatusConflict, response.CodeConflict, "API key limit reached; wait for older keys to expire")
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to rotate API key")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":              true,
+			"key":                  newKey,
+			"apiKey":               apiKey,
+			"previousKeyExpiresAt": currentExpiresAt,
+			"message":              i18n.T(r, "agents.key_rotated"),
+		})
+	}
+}
+
+// ListKeysHandler handles GET /v0/agents/keys
+// Lists the calling agent's keys with their expiry and last use.
+func ListKeysHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var keys []models.AgentAPIKey
+		if result := db.Where("agent_id = ?", agent.ID).Order("id DESC").Find(&keys); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch keys")
+			return
+		}
+
+		now := time.Now()
+		type keyStatus struct {
+			models.AgentAPIKey
+			Active bool `json:"active"`
+		}
+		statuses := make([]keyStatus, len(keys))
+		for i, key := range keys {
+			statuses[i] = keyStatus{AgentAPIKey: key, Active: key.IsActive(now)}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"keys":    statuses,
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package agents
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestRotateKey_ShortLegacyKey(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	agent := models.Agent{Name: "veteran", APIKey: "swarm_sk_ab", ClaimToken: "claim_veteran", IsActive: true}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v0/agents/keys/rotate", strings.NewReader(`{}`))
+	req.Header.Set("X-Agent-API-Key", agent.APIKey)
+	rec := httptest.NewRecorder()
+	RotateKeyHandler(db)(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected the legacy key to be rotated, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		APIKey string `json:"apiKey"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.APIKey == "" || body.APIKey == agent.APIKey {
+		t.Fatalf("expected a new key, got %q", body.APIKey)
+	}
+
+	var legacy models.AgentAPIKey
+	if err := db.Where("key_hash = ?", models.HashAgentAPIKey(agent.APIKey)).First(&legacy).Error; err != nil {
+		t.Fatalf("expected the legacy key to be recorded for its grace period: %v", err)
+	}
+	if legacy.KeyPrefix != agent.APIKey || legacy.ExpiresAt == nil {
+		t.Fatalf("expected the whole short key as prefix and an expiry, got %q, %v", legacy.KeyPrefix, legacy.ExpiresAt)
+	}
+}
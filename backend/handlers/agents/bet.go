@@ -3,8 +3,13 @@ package agents
 import (
 	"encoding/json"
 	"net/http"
+	"socialpredict/errors"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/repository"
+	"socialpredict/response"
+	"socialpredict/validation"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -29,11 +34,16 @@ type AgentBet struct {
 
 // AgentBetRequest is the request body for placing an agent bet
 type AgentBetRequest struct {
-	MarketID   int64   `json:"marketId"`
-	Amount     int64   `json:"amount"`
-	Outcome    string  `json:"outcome"`    // "yes" or "no"
-	Confidence float64 `json:"confidence"` // 0-1
-	Reasoning  string  `json:"reasoning,omitempty"`
+	MarketID   int64   `json:"marketId" validate:"required,gt=0"`
+	Amount     int64   `json:"amount" validate:"required,gt=0"`
+	Outcome    string  `json:"outcome" validate:"required,oneof=yes no"` // "yes" or "no"
+	Confidence float64 `json:"confidence" validate:"gte=0,lte=1"`       // 0-1
+	Reasoning  string  `json:"reasoning,omitempty" validate:"max=2000"`
+}
+
+// Normalize lower-cases the outcome.
+func (r *AgentBetRequest) Normalize() {
+	r.Outcome = strings.ToLower(strings.TrimSpace(r.Outcome))
 }
 
 // AgentBetResponse is returned after placing a bet
@@ -55,44 +65,26 @@ type MarketStateInfo struct {
 func PlaceBetHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		// Validate agent (must be claimed)
 		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
 
 		var req AgentBetRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-
-		// Validate request
-		if req.MarketID <= 0 {
-			http.Error(w, "Market ID is required", http.StatusBadRequest)
-			return
-		}
-		if req.Amount <= 0 {
-			http.Error(w, "Amount must be positive", http.StatusBadRequest)
-			return
-		}
-		if req.Outcome != "yes" && req.Outcome != "no" {
-			http.Error(w, "Outcome must be 'yes' or 'no'", http.StatusBadRequest)
-			return
-		}
-		if req.Confidence < 0 || req.Confidence > 1 {
-			http.Error(w, "Confidence must be between 0 and 1", http.StatusBadRequest)
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 
 		// Check agent has sufficient balance
 		if agent.AccountBalance < req.Amount {
-			http.Error(w, "Insufficient balance", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeInsufficientFunds, "Insufficient balance")
 			return
 		}
 
@@ -100,15 +92,15 @@ func PlaceBetHandler(db *gorm.DB) http.HandlerFunc {
 		var market models.Market
 		if result := db.First(&market, req.MarketID); result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
-				http.Error(w, "Market not found", http.StatusNotFound)
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
 				return
 			}
-			http.Error(w, "Database error", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
 			return
 		}
 
 		if market.IsResolved {
-			http.Error(w, "Market is already resolved", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeMarketResolved, "Market is already resolved")
 			return
 		}
 
@@ -119,7 +111,7 @@ func PlaceBetHandler(db *gorm.DB) http.HandlerFunc {
 		agent.AccountBalance -= req.Amount
 		if result := tx.Save(agent); result.Error != nil {
 			tx.Rollback()
-			http.Error(w, "Failed to update balance", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update balance")
 			return
 		}
 
@@ -136,13 +128,21 @@ func PlaceBetHandler(db *gorm.DB) http.HandlerFunc {
 
 		if result := tx.Create(&bet); result.Error != nil {
 			tx.Rollback()
-			http.Error(w, "Failed to place bet", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to place bet")
 			return
 		}
 
-		// Also create a standard bet for compatibility with existing system
+		// Also create a standard bet for compatibility with existing system.
+		// bets.username references users, so the bet goes on the agent's
+		// shadow user.
+		shadowUser, err := repository.NewGormAgentRepo(tx).ShadowUser(agent)
+		if err != nil {
+			tx.Rollback()
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to resolve agent account")
+			return
+		}
 		standardBet := models.Bet{
-			Username: "agent:" + agent.Name, // Prefix to distinguish from human users
+			Username: shadowUser.Username,
 			MarketID: uint(req.MarketID), // Convert int64 to uint for compatibility
 			Amount:   req.Amount,
 			Outcome:  req.Outcome,
@@ -151,7 +151,7 @@ func PlaceBetHandler(db *gorm.DB) http.HandlerFunc {
 
 		if result := tx.Create(&standardBet); result.Error != nil {
 			tx.Rollback()
-			http.Error(w, "Failed to create standard bet", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create standard bet")
 			return
 		}
 
@@ -160,7 +160,7 @@ func PlaceBetHandler(db *gorm.DB) http.HandlerFunc {
 		agent.TotalWagered += req.Amount
 		if result := tx.Save(agent); result.Error != nil {
 			tx.Rollback()
-			http.Error(w, "Failed to update agent stats", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update agent stats")
 			return
 		}
 
@@ -187,20 +187,20 @@ func PlaceBetHandler(db *gorm.DB) http.HandlerFunc {
 func GetAgentBetsHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		// Validate agent
 		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
 
 		var bets []AgentBet
 		if result := db.Where("agent_id = ?", agent.ID).Order("placed_at DESC").Find(&bets); result.Error != nil {
-			http.Error(w, "Failed to fetch bets", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch bets")
 			return
 		}
 
@@ -217,7 +217,7 @@ func GetAgentBetsHandler(db *gorm.DB) http.HandlerFunc {
 func GetMarketAgentBetsHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
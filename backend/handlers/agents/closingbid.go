@@ -0,0 +1,115 @@
+package agents
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/auction"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ClosingBidRequest is an agent's sealed final probability
+type ClosingBidRequest struct {
+	Probability *float64 `json:"probability" validate:"required,gte=0,lte=1"` // 0-1 chance of YES
+}
+
+// ClosingAuctionStatus describes a market's closing auction. Bids are only
+// listed, and FinalConsensus only set, once the auction has been revealed.
+type ClosingAuctionStatus struct {
+	OpensAt        time.Time           `json:"opensAt"`
+	ClosesAt       time.Time           `json:"closesAt"`
+	Open           bool                `json:"open"`
+	SealedBids     int64               `json:"sealedBids"`
+	Revealed       bool                `json:"revealed"`
+	FinalConsensus *float64            `json:"finalConsensus,omitempty"`
+	Bids           []models.ClosingBid `json:"bids,omitempty"`
+}
+
+// SubmitClosingBidHandler handles POST /v0/markets/{marketId}/closing-bid
+// Claimed agents submit or replace their sealed bid during the final hour.
+func SubmitClosingBidHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		marketID, err := strconv.ParseInt(mux.Vars(r)["marketId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+
+		var req ClosingBidRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		var market models.Market
+		if result := db.First(&market, marketID); result.Error != nil {
+			if stderrors.Is(result.Error, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+
+		if _, err := auction.SubmitBid(r.Context(), db, market, agent.ID, *req.Probability, time.Now()); err != nil {
+			switch {
+			case stderrors.Is(err, auction.ErrNoAuction):
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Market has no closing auction")
+			case stderrors.Is(err, auction.ErrAuctionNotOpen):
+				response.Error(w, http.StatusConflict, response.CodeConflict, "Closing auction opens one hour before close")
+			default:
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record bid")
+			}
+			return
+		}
+
+		// The bid itself is not echoed back; it stays sealed until close.
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"marketId": market.ID,
+			"closesAt": market.ResolutionDateTime,
+		})
+	}
+}
+
+// closingAuctionStatus returns the market's auction status, or nil if the
+// market has no closing auction.
+func closingAuctionStatus(db *gorm.DB, market models.Market, now time.Time) (*ClosingAuctionStatus, error) {
+	if !market.ClosingAuction {
+		return nil, nil
+	}
+	count, err := auction.CountBids(db, market.ID)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := auction.RevealedBids(db, market)
+	if err != nil {
+		return nil, err
+	}
+	return &ClosingAuctionStatus{
+		OpensAt:        auction.Opens(market),
+		ClosesAt:       market.ResolutionDateTime,
+		Open:           auction.IsOpen(market, now),
+		SealedBids:     count,
+		Revealed:       auction.IsRevealed(market),
+		FinalConsensus: market.FinalConsensus,
+		Bids:           bids,
+	}, nil
+}
@@ -0,0 +1,84 @@
+package agents
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"socialpredict/response"
+	"socialpredict/services/headtohead"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// CompareAgentsHandler handles GET /v0/agents/compare?ids=1,2,3
+// Compares two to five agents head to head on the markets they predicted
+// on in common. ?limit= caps the shared markets listed, default 50, at most
+// 200.
+func CompareAgentsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ids []int64
+		for _, s := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "ids must be a comma-separated list of agent IDs")
+				return
+			}
+			ids = append(ids, id)
+		}
+		writeComparison(w, r, db, ids)
+	}
+}
+
+// HeadToHeadHandler handles GET /v0/agent/{id}/vs/{otherId}
+// Compares two agents, as CompareAgentsHandler does.
+func HeadToHeadHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+			return
+		}
+		otherID, err := strconv.ParseInt(vars["otherId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+			return
+		}
+		writeComparison(w, r, db, []int64{id, otherID})
+	}
+}
+
+func writeComparison(w http.ResponseWriter, r *http.Request, db *gorm.DB, ids []int64) {
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed >= 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	comparison, err := headtohead.Compare(r.Context(), db, ids, limit)
+	switch {
+	case stderrors.Is(err, headtohead.ErrTooFewAgents), stderrors.Is(err, headtohead.ErrTooManyAgents):
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+		return
+	case stderrors.Is(err, headtohead.ErrAgentNotFound):
+		response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+		return
+	case err != nil:
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to compare agents")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Success bool `json:"success"`
+		*headtohead.Comparison
+	}{true, comparison})
+}
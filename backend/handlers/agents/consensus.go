@@ -0,0 +1,279 @@
+package agents
+
+import (
+	"container/heap"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/services/consensus"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// ConsensusSourcePredictions computes the swarm consensus from agent
+	// predictions. It is the default.
+	ConsensusSourcePredictions = "predictions"
+	// ConsensusSourceLegacy computes it from the deprecated agent bets.
+	// TODO: drop once clients have moved off ?source=legacy.
+	ConsensusSourceLegacy = "legacy"
+)
+
+// How many top predictors a consensus lists: DefaultTopPredictors unless
+// ?top= asks for another number, up to MaxTopPredictors.
+const (
+	DefaultTopPredictors = 10
+	MaxTopPredictors     = 100
+)
+
+// ConsensusCacheTTL bounds how long a market's consensus is reused. A
+// prediction made, revised or withdrawn has it recomputed at once; the TTL
+// catches changes to the agents' weights.
+const ConsensusCacheTTL = 30 * time.Second
+
+type cachedConsensus struct {
+	fingerprint string
+	computedAt  time.Time
+	swarm       SwarmConsensus // with MaxTopPredictors top predictors
+}
+
+var consensusCache = struct {
+	sync.Mutex
+	markets map[int64]cachedConsensus
+}{markets: map[int64]cachedConsensus{}}
+
+// cachedPredictionConsensus returns the market's consensus with up to top
+// top predictors, from the cache while the market's predictions are as
+// they were and ConsensusCacheTTL has not passed.
+func cachedPredictionConsensus(db *gorm.DB, market models.Market, top int) (SwarmConsensus, error) {
+	fingerprint, err := predictionsFingerprint(db, market.ID)
+	if err != nil {
+		return SwarmConsensus{}, err
+	}
+	consensusCache.Lock()
+	cached, ok := consensusCache.markets[market.ID]
+	consensusCache.Unlock()
+	if !ok || cached.fingerprint != fingerprint || time.Since(cached.computedAt) >= ConsensusCacheTTL {
+		swarm, err := predictionConsensus(db, market, MaxTopPredictors)
+		if err != nil {
+			return SwarmConsensus{}, err
+		}
+		cached = cachedConsensus{fingerprint: fingerprint, computedAt: time.Now(), swarm: swarm}
+		consensusCache.Lock()
+		for id, c := range consensusCache.markets {
+			if time.Since(c.computedAt) >= ConsensusCacheTTL {
+				delete(consensusCache.markets, id)
+			}
+		}
+		consensusCache.markets[market.ID] = cached
+		consensusCache.Unlock()
+	}
+
+	swarm := cached.swarm
+	if len(swarm.TopPredictors) > top {
+		swarm.TopPredictors = swarm.TopPredictors[:top]
+	}
+	return swarm, nil
+}
+
+// predictionsFingerprint changes whenever a prediction on the market is
+// made, revised or withdrawn: it is their count and latest update.
+func predictionsFingerprint(db *gorm.DB, marketID int64) (string, error) {
+	var count int64
+	var latest sql.NullString
+	err := db.Model(&models.Prediction{}).
+		Select("COUNT(*), MAX(updated_at)").
+		Where("market_id = ?", marketID).
+		Row().Scan(&count, &latest)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d|%s", count, latest.String), nil
+}
+
+// predictionConsensus computes the swarm consensus of a market from the
+// predictions of its active agents, with up to top top predictors.
+func predictionConsensus(db *gorm.DB, market models.Market, top int) (SwarmConsensus, error) {
+	predictions, agents, err := consensus.Inputs(db, market.ID)
+	if err != nil {
+		return SwarmConsensus{}, err
+	}
+	var swarm SwarmConsensus
+	if market.IsScalar() {
+		swarm = calculateScalarConsensus(predictions, agents, top)
+	} else {
+		swarm = calculatePredictionConsensus(predictions, agents, top)
+	}
+	swarm.MarketID = market.ID
+	return swarm, nil
+}
+
+// calculatePredictionConsensus is the weighted mean chance of YES implied
+// by the predictions, as described in package consensus, listing up to top
+// top predictors. Predictions by agents missing from agents are left out.
+func calculatePredictionConsensus(predictions []models.Prediction, agents map[int64]models.Agent, top int) SwarmConsensus {
+	swarm := SwarmConsensus{
+		Source:               ConsensusSourcePredictions,
+		ConsensusProbability: 0.5,
+		TopPredictors:        []AgentPrediction{},
+	}
+	topPredictors := newTopPredictors(top)
+
+	var weightedYes, totalWeight, totalConfidence, totalReputation float64
+	uniqueAgents := make(map[int64]bool)
+	for _, p := range predictions {
+		agent, ok := agents[p.AgentID]
+		if !ok {
+			continue
+		}
+
+		yes := consensus.ImpliedYes(p)
+		weight := consensus.Weight(&agent)
+		outcome := strings.ToLower(p.Outcome)
+
+		uniqueAgents[agent.ID] = true
+		weightedYes += yes * weight
+		totalWeight += weight
+		totalConfidence += p.Confidence / 100
+		totalReputation += agent.Reputation
+		swarm.TotalPredictions++
+		if outcome == "yes" {
+			swarm.Breakdown.YesCount++
+			swarm.Breakdown.YesWeight += weight
+		} else {
+			swarm.Breakdown.NoCount++
+			swarm.Breakdown.NoWeight += weight
+		}
+
+		topPredictors.add(AgentPrediction{
+			AgentName:          agent.Name,
+			Outcome:            outcome,
+			Confidence:         p.Confidence / 100,
+			Reputation:         agent.Reputation,
+			CompositeScore:     agent.CompositeScore,
+			ImpliedProbability: yes,
+			Weight:             weight,
+			Reasoning:          p.Reasoning,
+		})
+	}
+	if swarm.TotalPredictions == 0 {
+		return swarm
+	}
+
+	swarm.TotalAgents = len(uniqueAgents)
+	swarm.ConsensusProbability = weightedYes / totalWeight
+	swarm.AverageConfidence = totalConfidence / float64(swarm.TotalPredictions)
+	swarm.AverageReputation = totalReputation / float64(swarm.TotalPredictions)
+	swarm.TopPredictors = topPredictors.sorted()
+	return swarm
+}
+
+// calculateScalarConsensus is the consensus of a scalar market: the
+// weighted median of the estimates with its spread, as described in package
+// consensus, listing up to top top predictors. Predictions by agents
+// missing from agents are left out.
+func calculateScalarConsensus(predictions []models.Prediction, agents map[int64]models.Agent, top int) SwarmConsensus {
+	swarm := SwarmConsensus{
+		Source:        ConsensusSourcePredictions,
+		TopPredictors: []AgentPrediction{},
+		Scalar:        consensus.ScalarOf(predictions, agents),
+	}
+	topPredictors := newTopPredictors(top)
+
+	var totalConfidence, totalReputation float64
+	uniqueAgents := make(map[int64]bool)
+	for _, p := range predictions {
+		agent, ok := agents[p.AgentID]
+		if !ok || p.Estimate == nil {
+			continue
+		}
+		uniqueAgents[agent.ID] = true
+		totalConfidence += p.Confidence / 100
+		totalReputation += agent.Reputation
+		swarm.TotalPredictions++
+		topPredictors.add(AgentPrediction{
+			AgentName:      agent.Name,
+			Outcome:        strings.ToLower(p.Outcome),
+			Confidence:     p.Confidence / 100,
+			Reputation:     agent.Reputation,
+			CompositeScore: agent.CompositeScore,
+			Weight:         consensus.Weight(&agent),
+			Reasoning:      p.Reasoning,
+			Estimate:       p.Estimate,
+			Low:            p.Low,
+			High:           p.High,
+		})
+	}
+	if swarm.TotalPredictions == 0 {
+		return swarm
+	}
+
+	swarm.TotalAgents = len(uniqueAgents)
+	swarm.AverageConfidence = totalConfidence / float64(swarm.TotalPredictions)
+	swarm.AverageReputation = totalReputation / float64(swarm.TotalPredictions)
+	swarm.TopPredictors = topPredictors.sorted()
+	return swarm
+}
+
+// topPredictors keeps the k heaviest predictions added to it, in a min-heap
+// on weight so the lightest is the one pushed out, taking O(n log k) for n
+// predictions. Of equal weights the earliest added ranks first.
+type topPredictors struct {
+	k     int
+	added int
+	heap  predictorHeap
+}
+
+type rankedPrediction struct {
+	AgentPrediction
+	order int
+}
+
+// predictorHeap is a min-heap on weight, then on lateness.
+type predictorHeap []rankedPrediction
+
+func (h predictorHeap) Len() int { return len(h) }
+func (h predictorHeap) Less(i, j int) bool {
+	if h[i].Weight != h[j].Weight {
+		return h[i].Weight < h[j].Weight
+	}
+	return h[i].order > h[j].order
+}
+func (h predictorHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *predictorHeap) Push(x interface{}) { *h = append(*h, x.(rankedPrediction)) }
+func (h *predictorHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+func newTopPredictors(k int) *topPredictors {
+	return &topPredictors{k: k}
+}
+
+func (t *topPredictors) add(p AgentPrediction) {
+	ranked := rankedPrediction{AgentPrediction: p, order: t.added}
+	t.added++
+	switch {
+	case t.k <= 0:
+	case len(t.heap) < t.k:
+		heap.Push(&t.heap, ranked)
+	case ranked.Weight > t.heap[0].Weight:
+		t.heap[0] = ranked
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+// sorted returns the predictions kept, heaviest first.
+func (t *topPredictors) sorted() []AgentPrediction {
+	out := make([]AgentPrediction, len(t.heap))
+	for i := len(t.heap) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(&t.heap).(rankedPrediction).AgentPrediction
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestSwarmConsensus_WeighsPredictionsByCompositeScore(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(0, user.Username)
+	market.ResolutionDateTime = time.Now().Add(24 * time.Hour)
+	db.Create(&market)
+
+	seed := func(name string, composite float64, predictions int64, outcome string, confidence float64) models.Agent {
+		agent := models.Agent{
+			Name: name, APIKey: "swarm_sk_" + name, ClaimToken: "claim_" + name, IsActive: true,
+			CompositeScore: composite, TotalPredictions: predictions, Reputation: composite / 100,
+		}
+		if err := db.Create(&agent).Error; err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+		db.Create(&models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: outcome, Confidence: confidence, PredictedAt: time.Now()})
+		return agent
+	}
+	seed("expert", 80, 100, "YES", 90) // weight 0.8 * 2
+	novice := seed("novice", 10, 0, "NO", 80)
+	banned := seed("banned", 100, 100, "YES", 100)
+	db.Model(&banned).Update("is_active", false)
+	db.Create(&AgentBet{AgentID: novice.ID, MarketID: market.ID, Amount: 100, Outcome: "no", Confidence: 0.8, PlacedAt: time.Now()})
+
+	get := func(query string) (int, SwarmConsensus) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v0/markets/%d/swarm%s", market.ID, query), nil)
+		rec := httptest.NewRecorder()
+		GetSwarmConsensusHandler(db)(rec, req)
+		var consensus SwarmConsensus
+		json.Unmarshal(rec.Body.Bytes(), &consensus)
+		return rec.Code, consensus
+	}
+
+	code, consensus := get("")
+	if code != http.StatusOK || consensus.Source != ConsensusSourcePredictions {
+		t.Fatalf("expected the prediction consensus, got %d %+v", code, consensus)
+	}
+	// (0.9 * 1.6 + 0.2 * 0.1) / 1.7; the deactivated agent does not count.
+	if want := 1.46 / 1.7; math.Abs(consensus.ConsensusProbability-want) > 1e-9 {
+		t.Fatalf("expected consensus %.4f, got %.4f", want, consensus.ConsensusProbability)
+	}
+	if consensus.TotalAgents != 2 || consensus.Breakdown.YesCount != 1 || consensus.Breakdown.NoCount != 1 {
+		t.Fatalf("unexpected counts %+v", consensus)
+	}
+	if top := consensus.TopPredictors[0]; top.AgentName != "expert" || top.Outcome != "yes" || top.Confidence != 0.9 {
+		t.Fatalf("expected the expert first, got %+v", top)
+	}
+
+	code, legacy := get("?source=legacy")
+	if code != http.StatusOK || legacy.Source != ConsensusSourceLegacy || legacy.TotalBets != 1 || legacy.ConsensusProbability != 0 {
+		t.Fatalf("expected the bet-based consensus, got %d %+v", code, legacy)
+	}
+	if code, _ := get("?source=tea-leaves"); code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown source to be rejected, got %d", code)
+	}
+}
+
+func TestTopPredictors_KeepsHeaviestInOrder(t *testing.T) {
+	weights := []float64{0.3, 0.9, 0.1, 0.9, 0.5, 0.7, 0.2}
+	top := newTopPredictors(4)
+	for i, w := range weights {
+		top.add(AgentPrediction{AgentName: fmt.Sprintf("agent%d", i), Weight: w})
+	}
+	var got []string
+	for _, p := range top.sorted() {
+		got = append(got, p.AgentName)
+	}
+	// Equal weights keep the order they were added in.
+	if want := "agent1,agent3,agent5,agent4"; strings.Join(got, ",") != want {
+		t.Fatalf("expected %s, got %v", want, got)
+	}
+
+	none := newTopPredictors(0)
+	none.add(AgentPrediction{Weight: 1})
+	if len(none.sorted()) != 0 {
+		t.Fatalf("expected no predictors for k=0")
+	}
+}
+
+func TestSwarmConsensus_TopParameterAndCacheInvalidation(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(0, user.Username)
+	market.ResolutionDateTime = time.Now().Add(24 * time.Hour)
+	db.Create(&market)
+
+	predict := func(name, outcome string) {
+		agent := models.Agent{Name: name, APIKey: "top_sk_" + name, ClaimToken: "claim_" + name, IsActive: true, CompositeScore: 50, TotalPredictions: 10}
+		if err := db.Create(&agent).Error; err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+		db.Create(&models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: outcome, Confidence: 90, PredictedAt: time.Now()})
+	}
+	get := func(query string) (int, SwarmConsensus) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/v0/markets/%d/swarm%s", market.ID, query), nil)
+		rec := httptest.NewRecorder()
+		GetSwarmConsensusHandler(db)(rec, req)
+		var consensus SwarmConsensus
+		json.Unmarshal(rec.Body.Bytes(), &consensus)
+		return rec.Code, consensus
+	}
+
+	for i := 0; i < 12; i++ {
+		predict(fmt.Sprintf("yes%d", i), "YES")
+	}
+	if _, consensus := get(""); len(consensus.TopPredictors) != DefaultTopPredictors || consensus.TotalPredictions != 12 {
+		t.Fatalf("expected %d top predictors of 12, got %d of %d", DefaultTopPredictors, len(consensus.TopPredictors), consensus.TotalPredictions)
+	}
+	if _, consensus := get("?top=3"); len(consensus.TopPredictors) != 3 {
+		t.Fatalf("expected 3 top predictors, got %d", len(consensus.TopPredictors))
+	}
+	if _, consensus := get("?top=50"); len(consensus.TopPredictors) != 12 {
+		t.Fatalf("expected every predictor, got %d", len(consensus.TopPredictors))
+	}
+	for _, bad := range []string{"?top=-1", "?top=101", "?top=ten"} {
+		if code, _ := get(bad); code != http.StatusBadRequest {
+			t.Fatalf("expected %s rejected, got %d", bad, code)
+		}
+	}
+
+	// A new prediction is counted at once, cache or not.
+	predict("no0", "NO")
+	if _, consensus := get(""); consensus.TotalPredictions != 13 || consensus.Breakdown.NoCount != 1 {
+		t.Fatalf("expected the new prediction counted, got %+v", consensus.Breakdown)
+	}
+}
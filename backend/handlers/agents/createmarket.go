@@ -2,26 +2,91 @@ package agents
 
 import (
 	"encoding/json"
-	"fmt"
+	"log"
 	"net/http"
+	"socialpredict/errors"
 	"socialpredict/middleware"
 	"socialpredict/models"
-	"socialpredict/security"
+	"socialpredict/repository"
+	"socialpredict/response"
+	"socialpredict/services/contentsafety"
+	"socialpredict/services/marketcreation"
+	"socialpredict/services/platformconfig"
+	"socialpredict/setup"
+	"socialpredict/validation"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-const maxQuestionTitleLength = 160
-
 // AgentCreateMarketRequest is the request body for creating a market as an agent
 type AgentCreateMarketRequest struct {
-	QuestionTitle      string    `json:"questionTitle"`
-	Description        string    `json:"description"`
-	ResolutionDateTime time.Time `json:"resolutionDateTime"`
-	YesLabel           string    `json:"yesLabel,omitempty"`
-	NoLabel            string    `json:"noLabel,omitempty"`
+	QuestionTitle      string    `json:"questionTitle" validate:"required"`
+	Description        string    `json:"description" validate:"max=2000"`
+	ResolutionDateTime time.Time `json:"resolutionDateTime" validate:"required"`
+	YesLabel           string    `json:"yesLabel,omitempty" validate:"max=20"`
+	NoLabel            string    `json:"noLabel,omitempty" validate:"max=20"`
+	Category           string    `json:"category,omitempty" validate:"max=50"`
+	Tags               []string  `json:"tags,omitempty" validate:"max=10,dive,max=30"`
+	ClosingAuction     bool      `json:"closingAuction,omitempty"` // opt into the sealed closing auction
+
+	ResolutionCriteria *models.ResolutionCriteria `json:"resolutionCriteria,omitempty"`
+	AutoResolve        *models.OracleSpec         `json:"autoResolve,omitempty"` // resolve from an oracle at close
+
+	// Lock predictions this many hours before resolution; 0 locks at resolution
+	PredictionLockHours float64 `json:"predictionLockHours,omitempty" validate:"gte=0"`
+	// public (default), unlisted or invite_only
+	Visibility string `json:"visibility,omitempty" validate:"omitempty,oneof=public unlisted invite_only"`
+
+	// BINARY (default) or SCALAR; scalar markets ask for a number between
+	// scalarMin and scalarMax
+	OutcomeType string   `json:"outcomeType,omitempty" validate:"omitempty,oneof=BINARY SCALAR"`
+	ScalarMin   *float64 `json:"scalarMin,omitempty" validate:"required_if=OutcomeType SCALAR"`
+	ScalarMax   *float64 `json:"scalarMax,omitempty" validate:"required_if=OutcomeType SCALAR"`
+	ScalarUnit  string   `json:"scalarUnit,omitempty" validate:"max=20"`
+	// standard (default), daily or realtime; daily and realtime markets
+	// resolve sooner and lock predictions earlier, see marketTypes in
+	// setup.yaml
+	MarketType string `json:"marketType,omitempty" validate:"omitempty,oneof=standard daily realtime"`
+}
+
+// Normalize upper-cases the outcome type and trims the resolution criteria
+// and oracle spec.
+func (r *AgentCreateMarketRequest) Normalize() {
+	r.OutcomeType = strings.ToUpper(strings.TrimSpace(r.OutcomeType))
+	r.MarketType = strings.ToLower(strings.TrimSpace(r.MarketType))
+	if r.ResolutionCriteria != nil {
+		r.ResolutionCriteria.Normalize()
+	}
+	if r.AutoResolve != nil {
+		r.AutoResolve.Normalize()
+	}
+}
+
+// Input is the market the request asks agentID to create.
+func (r AgentCreateMarketRequest) Input(agentID int64) marketcreation.Input {
+	return marketcreation.Input{
+		QuestionTitle:      r.QuestionTitle,
+		Description:        r.Description,
+		ResolutionDateTime: r.ResolutionDateTime,
+		YesLabel:           r.YesLabel,
+		NoLabel:            r.NoLabel,
+		Category:           r.Category,
+		Tags:               r.Tags,
+		CreatorAgentID:     agentID,
+		ClosingAuction:     r.ClosingAuction,
+		ResolutionCriteria: r.ResolutionCriteria,
+		AutoResolve:        r.AutoResolve,
+
+		PredictionLockHours: r.PredictionLockHours,
+		Visibility:          r.Visibility,
+		OutcomeType:         r.OutcomeType,
+		ScalarMin:           r.ScalarMin,
+		ScalarMax:           r.ScalarMax,
+		ScalarUnit:          r.ScalarUnit,
+		MarketType:          r.MarketType,
+	}
 }
 
 // AgentCreateMarketResponse is returned after creating a market
@@ -35,92 +100,47 @@ type AgentCreateMarketResponse struct {
 func CreateMarketHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		// Validate agent (must be claimed)
 		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
 
 		var req AgentCreateMarketRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-
-		// Validate question title
-		if len(req.QuestionTitle) < 1 || len(req.QuestionTitle) > maxQuestionTitleLength {
-			http.Error(w, fmt.Sprintf("Question title must be 1-%d characters", maxQuestionTitleLength), http.StatusBadRequest)
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 
-		// Validate description
-		if len(req.Description) > 2000 {
-			http.Error(w, "Description must be less than 2000 characters", http.StatusBadRequest)
+		// Blocked content is refused; flagged content is created and put in
+		// the moderation queue.
+		safety := contentsafety.Scan(r.Context(), platformconfig.Current(db).ContentSafety,
+			contentsafety.Field{Name: "questionTitle", Text: req.QuestionTitle},
+			contentsafety.Field{Name: "description", Text: req.Description})
+		if err := safety.Err(); err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeContentBlocked, err.Error())
 			return
 		}
 
-		// Validate resolution time (must be at least 1 hour in future)
-		minResolutionTime := time.Now().Add(time.Hour)
-		if req.ResolutionDateTime.Before(minResolutionTime) {
-			http.Error(w, "Resolution time must be at least 1 hour in the future", http.StatusBadRequest)
-			return
-		}
-
-		// Initialize security service for sanitization
-		securityService := security.NewSecurityService()
-		marketInput := security.MarketInput{
-			Title:       req.QuestionTitle,
-			Description: req.Description,
-			EndTime:     req.ResolutionDateTime.String(),
-		}
-		sanitizedInput, err := securityService.ValidateAndSanitizeMarketInput(marketInput)
+		creation := marketcreation.NewService(repository.NewGormRepositories(db), setup.EconomicsConfig)
+		newMarket, err := creation.Create(req.Input(agent.ID))
 		if err != nil {
-			http.Error(w, "Invalid market data: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-
-		// Set default labels
-		yesLabel := strings.TrimSpace(req.YesLabel)
-		noLabel := strings.TrimSpace(req.NoLabel)
-		if yesLabel == "" {
-			yesLabel = "YES"
-		}
-		if noLabel == "" {
-			noLabel = "NO"
-		}
-
-		// Validate labels
-		if len(yesLabel) > 20 || len(noLabel) > 20 {
-			http.Error(w, "Labels must be 20 characters or less", http.StatusBadRequest)
+			if marketcreation.IsValidationError(err) {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error creating market: "+err.Error())
 			return
 		}
-
-		// WORKAROUND: Use admin user for now to bypass FK constraint issues
-		// TODO: Fix proper agent user creation later
-		creatorUsername := "admin"
-		
-		// Store the actual agent name in the description
-		marketDescription := fmt.Sprintf("[Created by AI Agent: %s]\n\n%s", agent.Name, sanitizedInput.Description)
-
-		// Create the market
-		newMarket := models.Market{
-			QuestionTitle:      sanitizedInput.Title,
-			Description:        marketDescription,
-			ResolutionDateTime: req.ResolutionDateTime,
-			YesLabel:           yesLabel,
-			NoLabel:            noLabel,
-			CreatorUsername:    creatorUsername,
-		}
-
-		marketResult := db.Create(&newMarket)
-		if marketResult.Error != nil {
-			http.Error(w, "Error creating market: "+marketResult.Error.Error(), http.StatusInternalServerError)
-			return
+		if safety.Flagged() {
+			if err := contentsafety.Flag(db, models.ContentMarket, newMarket.ID, safety, time.Now()); err != nil {
+				log.Printf("contentsafety: flag market %d: %v", newMarket.ID, err)
+			}
 		}
 
 		// Return success response
@@ -128,7 +148,7 @@ func CreateMarketHandler(db *gorm.DB) http.HandlerFunc {
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(AgentCreateMarketResponse{
 			Success: true,
-			Market:  newMarket,
+			Market:  *newMarket,
 			Message: "Market created successfully",
 		})
 	}
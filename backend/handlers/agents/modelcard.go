@@ -0,0 +1,167 @@
+package agents
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/validation"
+
+	"gorm.io/gorm"
+)
+
+// ModelCardRequest describes what an agent runs on. The enums mirror
+// models.ModelProviders, ParameterScales, AgentTools and AutonomyLevels.
+type ModelCardRequest struct {
+	ModelFamily    string   `json:"modelFamily" validate:"max=50,safe_string"`
+	Provider       string   `json:"provider" validate:"omitempty,oneof=openai anthropic google meta mistral xai deepseek alibaba cohere open_source other"`
+	ParameterScale string   `json:"parameterScale" validate:"omitempty,oneof=small medium large frontier unknown"`
+	ToolsUsed      []string `json:"toolsUsed" validate:"max=10,dive,oneof=web_search code_execution retrieval news_feeds market_data calculator other"`
+	AutonomyLevel  string   `json:"autonomyLevel" validate:"omitempty,oneof=supervised semi_autonomous autonomous"`
+}
+
+// Normalize lower-cases every field and drops repeated tools.
+func (r *ModelCardRequest) Normalize() {
+	lower := func(s string) string { return strings.ToLower(strings.TrimSpace(s)) }
+	r.ModelFamily = lower(r.ModelFamily)
+	r.Provider = lower(r.Provider)
+	r.ParameterScale = lower(r.ParameterScale)
+	r.AutonomyLevel = lower(r.AutonomyLevel)
+
+	seen := make(map[string]bool, len(r.ToolsUsed))
+	tools := r.ToolsUsed[:0]
+	for _, tool := range r.ToolsUsed {
+		tool = lower(tool)
+		if tool == "" || seen[tool] {
+			continue
+		}
+		seen[tool] = true
+		tools = append(tools, tool)
+	}
+	r.ToolsUsed = tools
+}
+
+// ModelCard converts the request to a models.ModelCard.
+func (r ModelCardRequest) ModelCard() models.ModelCard {
+	return models.ModelCard{
+		ModelFamily:    r.ModelFamily,
+		Provider:       r.Provider,
+		ParameterScale: r.ParameterScale,
+		ToolsUsed:      r.ToolsUsed,
+		AutonomyLevel:  r.AutonomyLevel,
+	}
+}
+
+// UpdateModelCardHandler handles PUT /v0/agents/model-card
+// Replaces the calling agent's model card; omitted fields are cleared.
+func UpdateModelCardHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var req ModelCardRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		agent.SetModelCard(req.ModelCard())
+		result := db.Model(&models.Agent{}).Where("id = ?", agent.ID).Updates(map[string]interface{}{
+			"model_family":    agent.ModelFamily,
+			"model_provider":  agent.ModelProvider,
+			"parameter_scale": agent.ParameterScale,
+			"tools_used":      agent.ToolsUsed,
+			"autonomy_level":  agent.AutonomyLevel,
+		})
+		if result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update model card")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"agent":   agent.ToPublic(),
+		})
+	}
+}
+
+// ModelAccuracy is the pooled track record of every agent sharing a model
+// card value.
+type ModelAccuracy struct {
+	Value               string  `json:"value"`
+	Agents              int64   `json:"agents"`
+	TotalPredictions    int64   `json:"totalPredictions"`
+	ResolvedPredictions int64   `json:"resolvedPredictions"`
+	CorrectPredictions  int64   `json:"correctPredictions"`
+	AccuracyPercent     float64 `json:"accuracyPercent"`
+	AvgCompositeScore   float64 `json:"avgCompositeScore"`
+}
+
+// modelCardColumns maps ?groupBy= to the agents column it groups on.
+var modelCardColumns = map[string]string{
+	"model":    "model_family",
+	"provider": "model_provider",
+	"scale":    "parameter_scale",
+	"autonomy": "autonomy_level",
+}
+
+// GetModelAccuracyHandler handles GET /v0/analytics/models
+// Pools the predictions of active agents by model card field (?groupBy=
+// model, provider, scale or autonomy; default model), most accurate first.
+// The model card filters of the leaderboards apply too, so
+// ?groupBy=model&provider=openai compares OpenAI models.
+func GetModelAccuracyHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		groupBy := r.URL.Query().Get("groupBy")
+		if groupBy == "" {
+			groupBy = "model"
+		}
+		column, ok := modelCardColumns[groupBy]
+		if !ok {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "groupBy must be one of model, provider, scale, autonomy")
+			return
+		}
+
+		var groups []ModelAccuracy
+		query := db.Model(&models.Agent{}).
+			Select(column+" AS value, COUNT(*) AS agents, "+
+				"SUM(total_predictions) AS total_predictions, "+
+				"SUM(resolved_predictions) AS resolved_predictions, "+
+				"SUM(correct_predictions) AS correct_predictions, "+
+				"AVG(composite_score) AS avg_composite_score").
+			Where("is_active = ? AND "+column+" <> ''", true)
+		query = models.ModelCardFilterFromQuery(r.URL.Query()).Apply(query)
+		if err := query.Group(column).Scan(&groups).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch model analytics")
+			return
+		}
+
+		for i := range groups {
+			if groups[i].ResolvedPredictions > 0 {
+				groups[i].AccuracyPercent = float64(groups[i].CorrectPredictions) / float64(groups[i].ResolvedPredictions) * 100
+			}
+		}
+		sort.SliceStable(groups, func(i, j int) bool {
+			if groups[i].AccuracyPercent != groups[j].AccuracyPercent {
+				return groups[i].AccuracyPercent > groups[j].AccuracyPercent
+			}
+			return groups[i].ResolvedPredictions > groups[j].ResolvedPredictions
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"groupBy": groupBy,
+			"groups":  groups,
+		})
+	}
+}
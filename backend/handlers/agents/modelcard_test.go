@@ -0,0 +1,94 @@
+package agents
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func seedCardAgent(t *testing.T, db *gorm.DB, name string, card models.ModelCard, resolved, correct int64) {
+	t.Helper()
+	agent := models.Agent{
+		Name:                name,
+		APIKey:              "swarm_sk_" + name,
+		ClaimToken:          "claim_" + name,
+		IsActive:            true,
+		IsClaimed:           true,
+		TotalPredictions:    resolved,
+		ResolvedPredictions: resolved,
+		CorrectPredictions:  correct,
+	}
+	agent.SetModelCard(card)
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent %s: %v", name, err)
+	}
+}
+
+func TestModelCard_FiltersAndAccuracyByModel(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	seedCardAgent(t, db, "alpha", models.ModelCard{ModelFamily: "gpt-4o", Provider: "openai", ToolsUsed: []string{"web_search", "retrieval"}}, 10, 7)
+	seedCardAgent(t, db, "bravo", models.ModelCard{ModelFamily: "gpt-4o", Provider: "openai"}, 10, 5)
+	seedCardAgent(t, db, "charlie", models.ModelCard{ModelFamily: "claude-3-5-sonnet", Provider: "anthropic", ToolsUsed: []string{"retrieval"}}, 4, 4)
+	seedCardAgent(t, db, "delta", models.ModelCard{}, 3, 1)
+
+	leaderboard := func(query string) []models.AgentPublic {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		GetAgentLeaderboardHandler(db)(rec, httptest.NewRequest(http.MethodGet, "/v0/agents/leaderboard"+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("leaderboard%s: status %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var body struct {
+			Agents []models.AgentPublic `json:"agents"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		return body.Agents
+	}
+
+	if got := leaderboard("?model=GPT-4o"); len(got) != 2 {
+		t.Fatalf("expected 2 gpt-4o agents, got %d", len(got))
+	}
+	if got := leaderboard("?tool=retrieval"); len(got) != 2 {
+		t.Fatalf("expected 2 retrieval agents, got %d", len(got))
+	}
+	got := leaderboard("?provider=anthropic&tool=retrieval")
+	if len(got) != 1 || got[0].Name != "charlie" || got[0].ModelCard == nil || got[0].ModelCard.ModelFamily != "claude-3-5-sonnet" {
+		t.Fatalf("expected charlie with its model card, got %+v", got)
+	}
+	if got := leaderboard(""); len(got) != 4 {
+		t.Fatalf("expected all 4 agents unfiltered, got %d", len(got))
+	}
+
+	rec := httptest.NewRecorder()
+	GetModelAccuracyHandler(db)(rec, httptest.NewRequest(http.MethodGet, "/v0/analytics/models", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("analytics: status %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Groups []ModelAccuracy `json:"groups"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	// Agents without a model family are left out.
+	if len(body.Groups) != 2 {
+		t.Fatalf("expected 2 model groups, got %+v", body.Groups)
+	}
+	if body.Groups[0].Value != "claude-3-5-sonnet" || body.Groups[0].AccuracyPercent != 100 {
+		t.Fatalf("expected claude-3-5-sonnet first at 100%%, got %+v", body.Groups[0])
+	}
+	if gpt := body.Groups[1]; gpt.Value != "gpt-4o" || gpt.Agents != 2 || gpt.ResolvedPredictions != 20 || gpt.AccuracyPercent != 60 {
+		t.Fatalf("unexpected gpt-4o group %+v", gpt)
+	}
+
+	rec = httptest.NewRecorder()
+	GetModelAccuracyHandler(db)(rec, httptest.NewRequest(http.MethodGet, "/v0/analytics/models?groupBy=color", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown groupBy, got %d", rec.Code)
+	}
+}
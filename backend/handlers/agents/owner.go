@@ -0,0 +1,204 @@
+package agents
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/agentdata"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ownedAgentID authenticates the user and parses the agent ID in the path.
+// If either fails it writes the error response and returns ok=false.
+func ownedAgentID(w http.ResponseWriter, r *http.Request, db *gorm.DB) (user *models.User, agentID int64, ok bool) {
+	user, httpErr := middleware.ValidateTokenAndGetUser(r, db)
+	if httpErr != nil {
+		response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+		return nil, 0, false
+	}
+	agentID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+		return nil, 0, false
+	}
+	return user, agentID, true
+}
+
+// DeactivateAgentHandler handles DELETE /v0/user/agents/{id}
+// Lets the owner of a claimed agent deactivate it: its API keys stop
+// working and it drops off the leaderboards, but its predictions, markets
+// and comments stay.
+func DeactivateAgentHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, agentID, ok := ownedAgentID(w, r, db)
+		if !ok {
+			return
+		}
+
+		agent, err := agentdata.Deactivate(r.Context(), db, user.ID, agentID, time.Now())
+		switch {
+		case stderrors.Is(err, agentdata.ErrNotOwner):
+			response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+			return
+		case stderrors.Is(err, agentdata.ErrDeactivated):
+			response.Error(w, http.StatusConflict, response.CodeAgentDeactivated, "Agent is already deactivated")
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to deactivate agent")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"agent":   agent.ToPublic(),
+		})
+	}
+}
+
+// ExportAgentHandler handles GET /v0/user/agents/{id}/export
+// Streams an archive of everything the owner's agent has done, deactivated
+// or not: ?format=json (the default) for one JSON document, ?format=ndjson
+// for a record per line.
+func ExportAgentHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, agentID, ok := ownedAgentID(w, r, db)
+		if !ok {
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = agentdata.FormatJSON
+		}
+		contentType := "application/json"
+		switch format {
+		case agentdata.FormatJSON:
+		case agentdata.FormatNDJSON:
+			contentType = "application/x-ndjson"
+		default:
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "format must be json or ndjson")
+			return
+		}
+
+		agent, err := agentdata.Owned(db, user.ID, agentID)
+		if stderrors.Is(err, agentdata.ErrNotOwner) {
+			response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch agent")
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="agent-%d-export.%s"`, agent.ID, format))
+		// Once the archive has started the status cannot change, so a
+		// failure part way is logged and leaves it truncated.
+		if err := agentdata.Export(r.Context(), db, agent, format, w, time.Now()); err != nil {
+			log.Printf("export agent %d: %v", agent.ID, err)
+		}
+	}
+}
+
+// erasureResponse writes the erasure and, once carried out, its deletion
+// certificate.
+func erasureResponse(w http.ResponseWriter, status int, erasure *models.DataErasure) {
+	certificate, err := erasure.Certificate()
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to read erasure certificate")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"erasure":     erasure,
+		"certificate": certificate,
+	})
+}
+
+// RequestErasureHandler handles POST /v0/user/agents/{id}/erasure
+// Asks for the owner's agent's personal data to be erased: it is
+// deactivated now, and once the grace period has passed its name becomes a
+// pseudonym and its profile, reasoning and comments are blanked. Its scores
+// and predictions stay.
+func RequestErasureHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, agentID, ok := ownedAgentID(w, r, db)
+		if !ok {
+			return
+		}
+
+		requestedBy := middleware.PrincipalFromContext(r.Context()).ID()
+		erasure, err := agentdata.RequestErasure(r.Context(), db, user.ID, agentID, requestedBy, time.Now())
+		switch {
+		case stderrors.Is(err, agentdata.ErrNotOwner):
+			response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+			return
+		case stderrors.Is(err, agentdata.ErrErasureRequested):
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Agent erasure already requested")
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to request erasure")
+			return
+		}
+		erasureResponse(w, http.StatusAccepted, erasure)
+	}
+}
+
+// GetErasureHandler handles GET /v0/user/agents/{id}/erasure
+// Returns the latest erasure the user asked for of the agent, with its
+// deletion certificate once carried out. It stays available after the
+// agent's owner is erased.
+func GetErasureHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, agentID, ok := ownedAgentID(w, r, db)
+		if !ok {
+			return
+		}
+
+		erasure, err := agentdata.OwnerErasure(db, user.ID, agentID)
+		if stderrors.Is(err, agentdata.ErrNoErasure) {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "No erasure requested")
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch erasure")
+			return
+		}
+		erasureResponse(w, http.StatusOK, erasure)
+	}
+}
+
+// CancelErasureHandler handles DELETE /v0/user/agents/{id}/erasure
+// Withdraws a pending erasure during its grace period. The agent stays
+// deactivated.
+func CancelErasureHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, agentID, ok := ownedAgentID(w, r, db)
+		if !ok {
+			return
+		}
+
+		erasure, err := agentdata.CancelErasure(r.Context(), db, user.ID, agentID, time.Now())
+		if stderrors.Is(err, agentdata.ErrNoErasure) {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "No pending erasure")
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to cancel erasure")
+			return
+		}
+		erasureResponse(w, http.StatusOK, erasure)
+	}
+}
@@ -0,0 +1,42 @@
+package agents
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"socialpredict/models"
+	"socialpredict/response"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// GetAgentProfileHandler handles GET /v0/agent/{id}
+// Returns an agent's public profile, the same one GET
+// /v0/agents/by-name/{name} returns, for callers that only hold its ID.
+func GetAgentProfileHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+			return
+		}
+		var agent models.Agent
+		if err := db.First(&agent, id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch agent")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"agent":   agent.ToPublic(),
+		})
+	}
+}
@@ -1,20 +1,39 @@
 package agents
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"net/http"
+	"socialpredict/errors"
+	"socialpredict/i18n"
+	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/agentnames"
+	"socialpredict/validation"
 	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	"gorm.io/gorm"
 )
 
 // RegisterRequest is the request body for agent registration
 type RegisterRequest struct {
-	Name          string `json:"name"`
-	Description   string `json:"description,omitempty"`
-	FrameworkType string `json:"frameworkType,omitempty"`
+	Name          string            `json:"name" validate:"required,min=3,max=50,safe_string"`
+	Description   string            `json:"description,omitempty" validate:"max=500,safe_string"`
+	FrameworkType string            `json:"frameworkType,omitempty" validate:"max=50"`
+	ModelCard     *ModelCardRequest `json:"modelCard,omitempty"`
+}
+
+// Normalize trims surrounding whitespace.
+func (r *RegisterRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Description = strings.TrimSpace(r.Description)
+	r.FrameworkType = strings.TrimSpace(r.FrameworkType)
+	if r.ModelCard != nil {
+		r.ModelCard.Normalize()
+	}
 }
 
 // RegisterResponse is returned after successful registration
@@ -30,52 +49,40 @@ type RegisterResponse struct {
 func RegisterHandler(db *gorm.DB, baseURL string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		var req RegisterRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-
-		// Validate name
-		req.Name = strings.TrimSpace(req.Name)
-		if req.Name == "" {
-			http.Error(w, "Agent name is required", http.StatusBadRequest)
-			return
-		}
-		if len(req.Name) < 3 || len(req.Name) > 50 {
-			http.Error(w, "Agent name must be 3-50 characters", http.StatusBadRequest)
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 
-		// Check if name already exists
-		var existingAgent models.Agent
-		if db.Where("name = ?", req.Name).First(&existingAgent).Error == nil {
-			http.Error(w, "Agent name already taken", http.StatusConflict)
+		// Check the name is free, not reserved and not a look-alike
+		if err := agentnames.Check(db, req.Name, 0); err != nil {
+			writeNameError(w, err)
 			return
 		}
 
 		// Generate API key
 		apiKey, err := models.GenerateAPIKey()
 		if err != nil {
-			http.Error(w, "Failed to generate API key", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to generate API key")
 			return
 		}
 
 		// Generate claim token
 		claimToken, err := models.GenerateClaimToken()
 		if err != nil {
-			http.Error(w, "Failed to generate claim token", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to generate claim token")
 			return
 		}
 
 		// Generate verification code
 		verificationCode, err := models.GenerateVerificationCode()
 		if err != nil {
-			http.Error(w, "Failed to generate verification code", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to generate verification code")
 			return
 		}
 
@@ -85,15 +92,26 @@ func RegisterHandler(db *gorm.DB, baseURL string) http.HandlerFunc {
 			Description:    req.Description,
 			APIKey:         apiKey,
 			ClaimToken:     claimToken,
+			ClaimCode:      verificationCode,
 			FrameworkType:  req.FrameworkType,
 			Reputation:     0.5, // Start neutral
 			AccountBalance: 10000, // Starting balance
 			IsActive:       true,
 			IsClaimed:      false,
 		}
+		if req.ModelCard != nil {
+			agent.SetModelCard(req.ModelCard.ModelCard())
+		}
 
-		if result := db.Create(&agent); result.Error != nil {
-			http.Error(w, "Failed to create agent", http.StatusInternalServerError)
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&agent).Error; err != nil {
+				return err
+			}
+			key := models.NewAgentAPIKey(agent.ID, "default", apiKey)
+			return tx.Create(&key).Error
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create agent")
 			return
 		}
 
@@ -108,7 +126,7 @@ func RegisterHandler(db *gorm.DB, baseURL string) http.HandlerFunc {
 			APIKey:           apiKey,
 			ClaimURL:         claimURL,
 			VerificationCode: verificationCode,
-			Important:        "⚠️ SAVE YOUR API KEY! You need it for all requests. Send your human the claim URL to activate your account.",
+			Important:        i18n.T(r, "agents.save_api_key"),
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -119,23 +137,41 @@ func RegisterHandler(db *gorm.DB, baseURL string) http.HandlerFunc {
 
 // ClaimRequest is the request body for claiming an agent
 type ClaimRequest struct {
-	VerificationCode string `json:"verificationCode"`
+	VerificationCode string `json:"verificationCode" validate:"required,max=30"`
 }
 
-// ClaimHandler handles POST /v0/agents/claim/{claimToken}
+// Normalize trims surrounding whitespace and lowercases the code.
+func (r *ClaimRequest) Normalize() {
+	r.VerificationCode = strings.ToLower(strings.TrimSpace(r.VerificationCode))
+}
+
+// ClaimHandler handles POST /v0/agents/claim/{claimToken}. The caller must be
+// a logged-in user and present the verification code the agent was given at
+// registration; the user becomes the agent's owner and the claim token is
+// replaced so the claim URL cannot be used again. An agent without a code
+// cannot be claimed until an admin issues one.
 func ClaimHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
-		// Extract claim token from URL
-		// Assuming router passes it as a path variable
-		claimToken := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		user, httpErr := middleware.ValidateTokenAndGetUser(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
 
+		claimToken := mux.Vars(r)["claimToken"]
 		if claimToken == "" {
-			http.Error(w, "Claim token required", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Claim token required")
+			return
+		}
+
+		var req ClaimRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 
@@ -143,45 +179,62 @@ func ClaimHandler(db *gorm.DB) http.HandlerFunc {
 		var agent models.Agent
 		if result := db.Where("claim_token = ?", claimToken).First(&agent); result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
-				http.Error(w, "Invalid claim token", http.StatusNotFound)
+				response.Error(w, http.StatusNotFound, response.CodeNotFound, "Invalid claim token")
 				return
 			}
-			http.Error(w, "Database error", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
 			return
 		}
 
 		if agent.IsClaimed {
-			http.Error(w, "Agent already claimed", http.StatusConflict)
+			response.Error(w, http.StatusConflict, response.CodeAlreadyClaimed, "Agent already claimed")
 			return
 		}
 
-		// In a real implementation, we would:
-		// 1. Require user authentication (JWT)
-		// 2. Verify the human owns this claim somehow (OAuth, signature, etc.)
-		// For now, we just mark it as claimed
-
-		// Get user from JWT if available
-		// user, _ := middleware.ValidateTokenAndGetUser(r, db)
-		// if user != nil {
-		//     agent.OwnerUserID = &user.ID
-		// }
+		// Agents without a code can only be claimed once an admin reissues one.
+		if agent.ClaimCode == "" || subtle.ConstantTimeCompare([]byte(agent.ClaimCode), []byte(req.VerificationCode)) != 1 {
+			response.Error(w, http.StatusForbidden, response.CodeInvalidClaimCode, "Verification code does not match")
+			return
+		}
 
-		agent.IsClaimed = true
-		t := time.Now()
-		agent.ClaimedAt = &t
+		// A fresh token nobody holds retires the claim URL; the column is
+		// unique, so it cannot simply be cleared.
+		retired, err := models.GenerateClaimToken()
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to claim agent")
+			return
+		}
 
-		if result := db.Save(&agent); result.Error != nil {
-			http.Error(w, "Failed to claim agent", http.StatusInternalServerError)
+		now := time.Now()
+		result := db.Model(&models.Agent{}).
+			Where("id = ? AND claim_token = ? AND is_claimed = ?", agent.ID, claimToken, false).
+			Updates(map[string]interface{}{
+				"is_claimed":    true,
+				"owner_user_id": user.ID,
+				"claimed_at":    now,
+				"claim_token":   retired,
+				"claim_code":    "",
+			})
+		if result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to claim agent")
 			return
 		}
+		// Someone else claimed it between the read and the update.
+		if result.RowsAffected == 0 {
+			response.Error(w, http.StatusConflict, response.CodeAlreadyClaimed, "Agent already claimed")
+			return
+		}
+		agent.IsClaimed = true
+		agent.OwnerUserID = &user.ID
+		agent.ClaimedAt = &now
 
-		response := map[string]interface{}{
+		resp := map[string]interface{}{
 			"success": true,
-			"message": "Agent claimed successfully!",
+			"message": i18n.T(r, "agents.claimed"),
 			"agent":   agent.ToPublic(),
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		json.NewEncoder(w).Encode(resp)
 	}
 }
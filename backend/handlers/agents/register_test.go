@@ -0,0 +1,67 @@
+package agents
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/response"
+
+	"github.com/gorilla/mux"
+)
+
+func TestClaim_BindsOwnerAndRetiresToken(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	owner := modelstesting.GenerateUser("owner", 0)
+	if err := db.Create(&owner).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	agent := models.Agent{Name: "claimable", APIKey: "swarm_sk_claimable", ClaimToken: "swarm_claim_abc", ClaimCode: "swift-fox-a3b2", IsActive: true}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v0/agents/claim/{claimToken}", ClaimHandler(db))
+	claim := func(token, code string, user *models.User) (int, response.Code) {
+		t.Helper()
+		body, _ := json.Marshal(ClaimRequest{VerificationCode: code})
+		req := httptest.NewRequest(http.MethodPost, "/v0/agents/claim/"+token, bytes.NewReader(body))
+		if user != nil {
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{Tier: middleware.TierUser, User: user}))
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var envelope response.ErrorEnvelope
+		json.Unmarshal(rec.Body.Bytes(), &envelope)
+		return rec.Code, envelope.Error.Code
+	}
+
+	if status, _ := claim("swarm_claim_abc", "swift-fox-a3b2", nil); status != http.StatusUnauthorized {
+		t.Fatalf("expected an anonymous claim to be refused, got %d", status)
+	}
+	if status, code := claim("swarm_claim_abc", "bold-owl-0000", &owner); status != http.StatusForbidden || code != response.CodeInvalidClaimCode {
+		t.Fatalf("expected a wrong code to be refused, got %d %s", status, code)
+	}
+	if status, _ := claim("swarm_claim_abc", " Swift-Fox-A3B2 ", &owner); status != http.StatusOK {
+		t.Fatalf("expected the claim to succeed, got %d", status)
+	}
+
+	var claimed models.Agent
+	db.First(&claimed, agent.ID)
+	if !claimed.IsClaimed || claimed.ClaimedAt == nil || claimed.OwnerUserID == nil || *claimed.OwnerUserID != owner.ID {
+		t.Fatalf("expected the agent owned by user %d, got %+v", owner.ID, claimed)
+	}
+	if claimed.ClaimToken == "swarm_claim_abc" || claimed.ClaimCode != "" {
+		t.Fatalf("expected the claim token and code to be retired")
+	}
+	if status, _ := claim("swarm_claim_abc", "swift-fox-a3b2", &owner); status != http.StatusNotFound {
+		t.Fatalf("expected the used claim URL to stop working, got %d", status)
+	}
+}
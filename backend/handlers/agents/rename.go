@@ -0,0 +1,112 @@
+package agents
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/errors"
+	"socialpredict/i18n"
+	"socialpredict/middleware"
+	"socialpredict/response"
+	"socialpredict/services/agentnames"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// writeNameError writes the response for a name refused by agentnames.
+func writeNameError(w http.ResponseWriter, err error) {
+	var cooldown *agentnames.CooldownError
+	switch {
+	case stderrors.As(err, &cooldown):
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(cooldown.Until).Seconds())+1))
+		response.Error(w, http.StatusTooManyRequests, response.CodeRateLimited, err.Error())
+	case stderrors.Is(err, agentnames.ErrNameTaken):
+		response.Error(w, http.StatusConflict, response.CodeNameTaken, err.Error())
+	case stderrors.Is(err, agentnames.ErrNameReserved),
+		stderrors.Is(err, agentnames.ErrNameImpersonates):
+		response.Error(w, http.StatusConflict, response.CodeNameReserved, err.Error())
+	case stderrors.Is(err, agentnames.ErrSameName):
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to check agent name")
+	}
+}
+
+// RenameRequest is the request body for renaming an agent
+type RenameRequest struct {
+	Name string `json:"name" validate:"required,min=3,max=50,safe_string"`
+}
+
+// Normalize trims surrounding whitespace.
+func (r *RenameRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+}
+
+// RenameHandler handles POST /v0/agents/rename
+// Renames the calling agent, at most once per agentnames.RenameCooldown.
+// The old name redirects to the agent from GET /v0/agents/by-name/{name}
+// and cannot be taken by anyone else.
+func RenameHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var req RenameRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		change, err := agentnames.Rename(r.Context(), db, agent.ID, req.Name, time.Now())
+		if err != nil {
+			writeNameError(w, err)
+			return
+		}
+		agent.Name = change.NewName
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":      true,
+			"agent":        agent.ToPublic(),
+			"previousName": change.OldName,
+			"nextRenameAt": change.ChangedAt.Add(agentnames.RenameCooldown),
+			"message":      i18n.T(r, "agents.renamed", change.OldName),
+		})
+	}
+}
+
+// GetAgentByNameHandler handles GET /v0/agents/by-name/{name}
+// A former name answers with a permanent redirect to the current one.
+func GetAgentByNameHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, redirected, err := agentnames.Resolve(db, mux.Vars(r)["name"])
+		if err != nil {
+			if stderrors.Is(err, agentnames.ErrAgentNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if redirected {
+			http.Redirect(w, r, "/v0/agents/by-name/"+url.PathEscape(agent.Name), http.StatusMovedPermanently)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"agent":   agent.ToPublic(),
+		})
+	}
+}
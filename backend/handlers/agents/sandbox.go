@@ -0,0 +1,206 @@
+package agents
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"socialpredict/errors"
+	"socialpredict/i18n"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/validation"
+
+	"gorm.io/gorm"
+)
+
+// SandboxMarket is the synthetic, always-open market new agents practise on.
+// It is not stored with the real markets. Every agent has its own: it sees
+// only its own practice predictions, counted in Predictions and Correct.
+type SandboxMarket struct {
+	ID            string   `json:"id"`
+	QuestionTitle string   `json:"questionTitle"`
+	Description   string   `json:"description"`
+	Outcomes      []string `json:"outcomes"`
+	IsOpen        bool     `json:"isOpen"`
+	AgentID       int64    `json:"agentId,omitempty"`
+	Predictions   int64    `json:"predictions"`
+	Correct       int64    `json:"correct"`
+}
+
+var sandboxMarket = SandboxMarket{
+	ID:            "sandbox",
+	QuestionTitle: "Sandbox: will this coin flip land YES?",
+	Description: "A practice market for testing an integration. Predictions are accepted at any time " +
+		"and resolved at random immediately. They never affect scores, leaderboards or markets.",
+	Outcomes: []string{"YES", "NO"},
+	IsOpen:   true,
+}
+
+// sandboxMarketFor returns agentID's own sandbox market with its record so
+// far, or the bare market for callers that are not agents.
+func sandboxMarketFor(db *gorm.DB, agentID int64) (SandboxMarket, error) {
+	market := sandboxMarket
+	if agentID == 0 {
+		return market, nil
+	}
+	market.ID = "sandbox-" + strconv.FormatInt(agentID, 10)
+	market.AgentID = agentID
+	err := db.Model(&models.SandboxPrediction{}).
+		Select("COUNT(*) AS predictions, COALESCE(SUM(CASE WHEN was_correct THEN 1 ELSE 0 END), 0) AS correct").
+		Where("agent_id = ?", agentID).
+		Row().Scan(&market.Predictions, &market.Correct)
+	return market, err
+}
+
+// sandboxResolution picks the sandbox market's outcome for one prediction.
+var sandboxResolution = func() string {
+	if rand.N(2) == 0 {
+		return "NO"
+	}
+	return "YES"
+}
+
+// SandboxPredictionRequest is the request body for a sandbox prediction.
+type SandboxPredictionRequest struct {
+	Outcome    string  `json:"outcome" validate:"required,market_outcome"`
+	Confidence float64 `json:"confidence" validate:"omitempty,gt=0,lte=100"` // defaults to 50
+	Reasoning  string  `json:"reasoning" validate:"max=2000"`
+}
+
+// Normalize upper-cases the outcome and trims the reasoning.
+func (r *SandboxPredictionRequest) Normalize() {
+	r.Outcome = strings.ToUpper(strings.TrimSpace(r.Outcome))
+	r.Reasoning = strings.TrimSpace(r.Reasoning)
+}
+
+// SandboxMarketHandler handles GET /v0/sandbox/market
+// Returns the calling agent's sandbox market, or the bare market without an
+// agent key.
+func SandboxMarketHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		market, err := sandboxMarketFor(db, middleware.PrincipalFromContext(r.Context()).AgentID())
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load sandbox market")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"market":  market,
+		})
+	}
+}
+
+// SandboxPredictHandler handles POST /v0/sandbox/predict
+// Accepts a prediction on the agent's sandbox market from any agent,
+// claimed or not, resolves it at random on the spot and returns how it
+// would have scored, so a new agent can check its request and response
+// handling end to end.
+func SandboxPredictHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var req SandboxPredictionRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+		confidence := req.Confidence
+		if confidence == 0 {
+			confidence = 50
+		}
+
+		prediction := models.SandboxPrediction{
+			AgentID:    agent.ID,
+			Outcome:    req.Outcome,
+			Confidence: confidence,
+			Reasoning:  req.Reasoning,
+		}
+		prediction.Resolve(sandboxResolution())
+		if err := db.Create(&prediction).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to save sandbox prediction")
+			return
+		}
+		market, err := sandboxMarketFor(db, agent.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load sandbox market")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"market":     market,
+			"prediction": prediction,
+			"message":    i18n.T(r, "agents.sandbox_resolved", prediction.Resolution),
+		})
+	}
+}
+
+// OnboardingStep is one item of the onboarding checklist.
+type OnboardingStep struct {
+	Key      string `json:"key"`
+	Done     bool   `json:"done"`
+	Optional bool   `json:"optional,omitempty"`
+	Hint     string `json:"hint"`
+}
+
+// OnboardingStatusHandler handles GET /v0/agents/me/onboarding-status
+// Returns the calling agent's onboarding checklist and the next required
+// step, if any.
+func OnboardingStatusHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var sandboxPredictions int64
+		if err := db.Model(&models.SandboxPrediction{}).Where("agent_id = ?", agent.ID).Count(&sandboxPredictions).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load onboarding status")
+			return
+		}
+
+		steps := []OnboardingStep{
+			{Key: "registered", Done: true, Hint: "POST /v0/agents/register"},
+			{Key: "sandbox_prediction", Done: sandboxPredictions > 0, Hint: "POST /v0/sandbox/predict to test your integration"},
+			{Key: "claimed", Done: agent.IsClaimed, Hint: "Send your human the claim URL from registration"},
+			{Key: "model_card", Done: agent.ModelFamily != "", Hint: "PUT /v0/agents/model-card"},
+			{Key: "first_prediction", Done: agent.TotalPredictions > 0, Hint: "POST /v0/predict on an open market"},
+			{Key: "webhook", Done: agent.WebhookURL != "", Optional: true, Hint: "PUT /v0/agents/webhook to be notified instead of polling"},
+		}
+
+		var nextStep *OnboardingStep
+		completed := 0
+		for i := range steps {
+			if steps[i].Done {
+				completed++
+			} else if nextStep == nil && !steps[i].Optional {
+				nextStep = &steps[i]
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":            true,
+			"agentId":            agent.ID,
+			"complete":           nextStep == nil,
+			"completed":          completed,
+			"total":              len(steps),
+			"nextStep":           nextStep,
+			"steps":              steps,
+			"sandboxPredictions": sandboxPredictions,
+		})
+	}
+}
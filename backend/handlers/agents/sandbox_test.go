@@ -0,0 +1,125 @@
+package agents
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestSandbox_PredictionIsResolvedButNeverScored(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	agent := models.Agent{Name: "newcomer", APIKey: "swarm_sk_newcomer", ClaimToken: "claim_newcomer", IsActive: true}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	original := sandboxResolution
+	sandboxResolution = func() string { return "NO" }
+	t.Cleanup(func() { sandboxResolution = original })
+
+	onboarding := func() (nextStep string, sandboxPredictions int64) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/v0/agents/me/onboarding-status", nil)
+		req.Header.Set("X-Agent-API-Key", agent.APIKey)
+		rec := httptest.NewRecorder()
+		OnboardingStatusHandler(db)(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("onboarding status: %d %s", rec.Code, rec.Body.String())
+		}
+		var body struct {
+			NextStep           *OnboardingStep `json:"nextStep"`
+			SandboxPredictions int64           `json:"sandboxPredictions"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		if body.NextStep == nil {
+			return "", body.SandboxPredictions
+		}
+		return body.NextStep.Key, body.SandboxPredictions
+	}
+
+	if next, _ := onboarding(); next != "sandbox_prediction" {
+		t.Fatalf("expected the sandbox prediction to be the first step, got %q", next)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v0/sandbox/predict", strings.NewReader(`{"outcome":"yes","confidence":80}`))
+	req.Header.Set("X-Agent-API-Key", agent.APIKey)
+	rec := httptest.NewRecorder()
+	SandboxPredictHandler(db)(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected an unclaimed agent's sandbox prediction to be accepted, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Prediction models.SandboxPrediction `json:"prediction"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Prediction.Resolution != "NO" || body.Prediction.WasCorrect || body.Prediction.BrierScore != models.BrierScore("YES", 80, "NO") {
+		t.Fatalf("expected a scored miss, got %+v", body.Prediction)
+	}
+
+	if next, count := onboarding(); next != "claimed" || count != 1 {
+		t.Fatalf("expected claiming to be next after one sandbox prediction, got %q and %d", next, count)
+	}
+
+	var reloaded models.Agent
+	db.First(&reloaded, agent.ID)
+	var predictions int64
+	db.Model(&models.Prediction{}).Count(&predictions)
+	if reloaded.TotalPredictions != 0 || reloaded.ResolvedPredictions != 0 || predictions != 0 {
+		t.Fatalf("expected the sandbox to leave real stats alone, got %d total, %d resolved, %d predictions",
+			reloaded.TotalPredictions, reloaded.ResolvedPredictions, predictions)
+	}
+}
+
+func TestSandbox_EachAgentHasItsOwnMarket(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	first := models.Agent{Name: "first", APIKey: "swarm_sk_first", ClaimToken: "claim_first", IsActive: true}
+	second := models.Agent{Name: "second", APIKey: "swarm_sk_second", ClaimToken: "claim_second", IsActive: true}
+	db.Create(&first)
+	db.Create(&second)
+
+	original := sandboxResolution
+	sandboxResolution = func() string { return "YES" }
+	t.Cleanup(func() { sandboxResolution = original })
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v0/sandbox/predict", strings.NewReader(`{"outcome":"yes"}`))
+		req.Header.Set("X-Agent-API-Key", first.APIKey)
+		rec := httptest.NewRecorder()
+		SandboxPredictHandler(db)(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("sandbox prediction: %d %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	firstMarket, err := sandboxMarketFor(db, first.ID)
+	if err != nil {
+		t.Fatalf("load first sandbox: %v", err)
+	}
+	secondMarket, err := sandboxMarketFor(db, second.ID)
+	if err != nil {
+		t.Fatalf("load second sandbox: %v", err)
+	}
+	if firstMarket.ID == secondMarket.ID || firstMarket.AgentID != first.ID {
+		t.Fatalf("expected separate sandbox markets, got %q and %q", firstMarket.ID, secondMarket.ID)
+	}
+	if firstMarket.Predictions != 2 || firstMarket.Correct != 2 || secondMarket.Predictions != 0 {
+		t.Fatalf("expected only the first agent's sandbox to hold its predictions, got %+v and %+v", firstMarket, secondMarket)
+	}
+
+	rec := httptest.NewRecorder()
+	SandboxMarketHandler(db)(rec, httptest.NewRequest(http.MethodGet, "/v0/sandbox/market", nil))
+	var body struct {
+		Market SandboxMarket `json:"market"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Market.ID != "sandbox" || body.Market.AgentID != 0 || body.Market.Predictions != 0 {
+		t.Fatalf("expected the bare sandbox market for anonymous callers, got %+v", body.Market)
+	}
+}
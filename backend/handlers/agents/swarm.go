@@ -6,8 +6,12 @@ import (
 	"net/http"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/consensus"
+	"socialpredict/services/leaderboard"
 	"strconv"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -15,14 +19,20 @@ import (
 // SwarmConsensus represents the aggregated prediction from all agents
 type SwarmConsensus struct {
 	MarketID             int64               `json:"marketId"`
+	Source               string              `json:"source"` // predictions or legacy
 	ConsensusProbability float64             `json:"consensusProbability"` // Weighted average
 	TotalAgents          int                 `json:"totalAgents"`
+	TotalPredictions     int                 `json:"totalPredictions,omitempty"`
 	TotalBets            int                 `json:"totalBets"`
 	TotalWagered         int64               `json:"totalWagered"`
 	AverageConfidence    float64             `json:"averageConfidence"`
 	AverageReputation    float64             `json:"averageReputation"`
 	Breakdown            SwarmBreakdown      `json:"breakdown"`
 	TopPredictors        []AgentPrediction   `json:"topPredictors"`
+	ClosingAuction       *ClosingAuctionStatus `json:"closingAuction,omitempty"`
+	// Scalar markets: the weighted median estimate and its spread, in place
+	// of ConsensusProbability
+	Scalar *consensus.Scalar `json:"scalar,omitempty"`
 }
 
 // SwarmBreakdown shows the split between YES and NO predictions
@@ -44,13 +54,24 @@ type AgentPrediction struct {
 	Reputation  float64 `json:"reputation"`
 	Weight      float64 `json:"weight"`
 	Reasoning   string  `json:"reasoning,omitempty"`
+	// Set for predictions
+	CompositeScore     float64 `json:"compositeScore,omitempty"`
+	ImpliedProbability float64 `json:"impliedProbability,omitempty"` // chance of YES
+	// Set for predictions on scalar markets
+	Estimate *float64 `json:"estimate,omitempty"`
+	Low      *float64 `json:"low,omitempty"`
+	High     *float64 `json:"high,omitempty"`
 }
 
 // GetSwarmConsensusHandler handles GET /v0/markets/{marketId}/swarm
+// The consensus is computed from agent predictions; ?source=legacy computes
+// it from the deprecated agent bets instead. ?top= sets how many top
+// predictors are listed, 10 by default and at most 100. The prediction
+// consensus is cached per market until its predictions change.
 func GetSwarmConsensusHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
@@ -67,7 +88,7 @@ func GetSwarmConsensusHandler(db *gorm.DB) http.HandlerFunc {
 
 		marketID, err := strconv.ParseInt(marketIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid market ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 			return
 		}
 
@@ -75,48 +96,89 @@ func GetSwarmConsensusHandler(db *gorm.DB) http.HandlerFunc {
 		var market models.Market
 		if result := db.First(&market, marketID); result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
-				http.Error(w, "Market not found", http.StatusNotFound)
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
 				return
 			}
-			http.Error(w, "Database error", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
 			return
 		}
-
-		// Get all agent bets for this market
-		var agentBets []AgentBet
-		if result := db.Where("market_id = ?", marketID).Find(&agentBets); result.Error != nil {
-			http.Error(w, "Failed to fetch agent bets", http.StatusInternalServerError)
+		if open, err := market.OpenTo(db, middleware.PrincipalFromContext(r.Context()).AgentID()); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		} else if !open {
+			response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
 			return
 		}
 
-		// Get all agents who bet
-		agentIDs := make([]int64, len(agentBets))
-		for i, bet := range agentBets {
-			agentIDs[i] = bet.AgentID
+		top := DefaultTopPredictors
+		if t := r.URL.Query().Get("top"); t != "" {
+			parsed, err := strconv.Atoi(t)
+			if err != nil || parsed < 0 || parsed > MaxTopPredictors {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "top must be between 0 and 100")
+				return
+			}
+			top = parsed
 		}
 
-		var agents []models.Agent
-		if len(agentIDs) > 0 {
-			db.Where("id IN ?", agentIDs).Find(&agents)
-		}
+		var consensus SwarmConsensus
+		switch r.URL.Query().Get("source") {
+		case "", ConsensusSourcePredictions:
+			consensus, err = cachedPredictionConsensus(db, market, top)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch predictions")
+				return
+			}
+		case ConsensusSourceLegacy:
+			// Get all agent bets for this market
+			var agentBets []AgentBet
+			if result := db.Where("market_id = ?", marketID).Find(&agentBets); result.Error != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch agent bets")
+				return
+			}
+
+			// Get all agents who bet
+			agentIDs := make([]int64, len(agentBets))
+			for i, bet := range agentBets {
+				agentIDs[i] = bet.AgentID
+			}
+
+			var agents []models.Agent
+			if len(agentIDs) > 0 {
+				db.Where("id IN ?", agentIDs).Find(&agents)
+			}
 
-		// Create agent lookup map
-		agentMap := make(map[int64]models.Agent)
-		for _, agent := range agents {
-			agentMap[agent.ID] = agent
+			// Create agent lookup map
+			agentMap := make(map[int64]models.Agent)
+			for _, agent := range agents {
+				agentMap[agent.ID] = agent
+			}
+
+			// Calculate weighted consensus
+			consensus = calculateSwarmConsensus(agentBets, agentMap, top)
+			consensus.Source = ConsensusSourceLegacy
+			consensus.MarketID = marketID
+		default:
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "source must be predictions or legacy")
+			return
 		}
 
-		// Calculate weighted consensus
-		consensus := calculateSwarmConsensus(agentBets, agentMap)
-		consensus.MarketID = marketID
+		// Markets with a closing auction report its status and, once
+		// revealed, the official final consensus
+		auctionStatus, err := closingAuctionStatus(db, market, time.Now())
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch closing auction")
+			return
+		}
+		consensus.ClosingAuction = auctionStatus
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(consensus)
 	}
 }
 
-// calculateSwarmConsensus computes the weighted average prediction
-func calculateSwarmConsensus(bets []AgentBet, agents map[int64]models.Agent) SwarmConsensus {
+// calculateSwarmConsensus computes the weighted average prediction, listing
+// up to top top predictors
+func calculateSwarmConsensus(bets []AgentBet, agents map[int64]models.Agent, top int) SwarmConsensus {
 	if len(bets) == 0 {
 		return SwarmConsensus{
 			ConsensusProbability: 0.5, // Default neutral
@@ -133,8 +195,8 @@ func calculateSwarmConsensus(bets []AgentBet, agents map[int64]models.Agent) Swa
 		noCount          int
 		yesAmount        int64
 		noAmount         int64
-		topPredictors    []AgentPrediction
 	)
+	topPredictors := newTopPredictors(top)
 
 	// Unique agents
 	uniqueAgents := make(map[int64]bool)
@@ -173,7 +235,7 @@ func calculateSwarmConsensus(bets []AgentBet, agents map[int64]models.Agent) Swa
 		totalReputation += agent.Reputation
 
 		// Track top predictors
-		topPredictors = append(topPredictors, AgentPrediction{
+		topPredictors.add(AgentPrediction{
 			AgentName:  agent.Name,
 			Outcome:    bet.Outcome,
 			Amount:     bet.Amount,
@@ -192,21 +254,6 @@ func calculateSwarmConsensus(bets []AgentBet, agents map[int64]models.Agent) Swa
 		consensusProbability = 0.5
 	}
 
-	// Sort top predictors by weight (descending)
-	// Simple bubble sort for small arrays
-	for i := 0; i < len(topPredictors)-1; i++ {
-		for j := 0; j < len(topPredictors)-i-1; j++ {
-			if topPredictors[j].Weight < topPredictors[j+1].Weight {
-				topPredictors[j], topPredictors[j+1] = topPredictors[j+1], topPredictors[j]
-			}
-		}
-	}
-
-	// Limit to top 10
-	if len(topPredictors) > 10 {
-		topPredictors = topPredictors[:10]
-	}
-
 	avgConfidence := 0.0
 	avgReputation := 0.0
 	if len(bets) > 0 {
@@ -229,15 +276,17 @@ func calculateSwarmConsensus(bets []AgentBet, agents map[int64]models.Agent) Swa
 			YesAmount: yesAmount,
 			NoAmount:  noAmount,
 		},
-		TopPredictors: topPredictors,
+		TopPredictors: topPredictors.sorted(),
 	}
 }
 
 // GetAgentLeaderboardHandler handles GET /v0/agents/leaderboard
+// Ranks claimed agents with predictions the same way as GET /v0/leaderboard
+// does by default, on their composite score.
 func GetAgentLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
@@ -249,12 +298,15 @@ func GetAgentLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 			}
 		}
 
+		// Optional model card filters, e.g. ?model=gpt-4o
+		filter := models.ModelCardFilterFromQuery(r.URL.Query())
+
 		var agents []models.Agent
-		if result := db.Where("is_claimed = true AND total_predictions > 0").
-			Order("reputation DESC, total_predictions DESC").
+		if result := leaderboard.Ranked(db, filter, "composite_score").
+			Where("agents.is_claimed = ? AND agents.total_predictions > 0", true).
 			Limit(limit).
 			Find(&agents); result.Error != nil {
-			http.Error(w, "Failed to fetch leaderboard", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch leaderboard")
 			return
 		}
 
@@ -277,7 +329,7 @@ func GetAgentLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 func GetAgentStatusHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
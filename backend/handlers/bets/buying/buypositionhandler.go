@@ -7,6 +7,7 @@ import (
 	betutils "socialpredict/handlers/bets/betutils"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/setup"
 	"socialpredict/util"
 
@@ -18,20 +19,20 @@ func PlaceBetHandler(loadEconConfig setup.EconConfigLoader) func(http.ResponseWr
 		db := util.GetDB()
 		user, httperr := middleware.ValidateUserAndEnforcePasswordChangeGetUser(r, db)
 		if httperr != nil {
-			http.Error(w, httperr.Error(), httperr.StatusCode)
+			response.Error(w, httperr.StatusCode, httperr.Code, httperr.Message)
 			return
 		}
 
 		var betRequest models.Bet
 		err := json.NewDecoder(r.Body).Decode(&betRequest)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
 		bet, err := PlaceBetCore(user, betRequest, db, loadEconConfig)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
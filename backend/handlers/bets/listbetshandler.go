@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	marketshandlers "socialpredict/handlers/markets"
 	"socialpredict/handlers/math/probabilities/wpam"
 	"socialpredict/handlers/tradingdata"
 	"socialpredict/models"
@@ -28,6 +29,12 @@ func MarketBetsDisplayHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	marketIdStr := vars["marketId"]
 
+	// Database connection
+	db := util.GetDB()
+	if !marketshandlers.MarketOpenByID(w, r, db, marketIdStr) {
+		return
+	}
+
 	// Convert marketId to uint
 	parsedUint64, err := strconv.ParseUint(marketIdStr, 10, 32)
 	if err != nil {
@@ -37,9 +44,6 @@ func MarketBetsDisplayHandler(w http.ResponseWriter, r *http.Request) {
 	// Convert uint64 to uint safely.
 	marketIDUint := uint(parsedUint64)
 
-	// Database connection
-	db := util.GetDB()
-
 	// Fetch bets for the market
 	bets := tradingdata.GetBetsForMarket(db, marketIDUint)
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/setup"
 	"socialpredict/util"
 )
@@ -14,26 +15,26 @@ func SellPositionHandler(loadEconConfig setup.EconConfigLoader) func(w http.Resp
 		db := util.GetDB()
 		user, httperr := middleware.ValidateUserAndEnforcePasswordChangeGetUser(r, db)
 		if httperr != nil {
-			http.Error(w, httperr.Error(), httperr.StatusCode)
+			response.Error(w, httperr.StatusCode, httperr.Code, httperr.Message)
 			return
 		}
 
 		var redeemRequest models.Bet
 		err := json.NewDecoder(r.Body).Decode(&redeemRequest)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
 		// Load economic configuration
 		cfg := loadEconConfig()
 		if cfg == nil {
-			http.Error(w, "failed to load economic configuration", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "failed to load economic configuration")
 			return
 		}
 
 		if err := ProcessSellRequest(db, &redeemRequest, user, cfg); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
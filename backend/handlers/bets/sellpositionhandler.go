@@ -7,6 +7,7 @@ import (
 	positionsmath "socialpredict/handlers/math/positions"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/setup"
 	"socialpredict/util"
 	"strconv"
@@ -20,14 +21,14 @@ func SellPositionHandler(loadEconConfig setup.EconConfigLoader) func(w http.Resp
 		db := util.GetDB()
 		user, httperr := middleware.ValidateUserAndEnforcePasswordChangeGetUser(r, db)
 		if httperr != nil {
-			http.Error(w, httperr.Error(), httperr.StatusCode)
+			response.Error(w, httperr.StatusCode, httperr.Code, httperr.Message)
 			return
 		}
 
 		var redeemRequest models.Bet
 		err := json.NewDecoder(r.Body).Decode(&redeemRequest)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
@@ -40,14 +41,14 @@ func SellPositionHandler(loadEconConfig setup.EconConfigLoader) func(w http.Resp
 		// Calculate the net aggregate positions for the user
 		userNetPosition, err := positionsmath.CalculateMarketPositionForUser_WPAM_DBPM(db, marketIDStr, user.Username)
 		if userNetPosition.NoSharesOwned == 0 && userNetPosition.YesSharesOwned == 0 {
-			http.Error(w, "No position found for the given market", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "No position found for the given market")
 			return
 		}
 
 		// Check if the user is trying to redeem more than they own
 		if (redeemRequest.Outcome == "YES" && redeemRequest.Amount > userNetPosition.YesSharesOwned) ||
 			(redeemRequest.Outcome == "NO" && redeemRequest.Amount > userNetPosition.NoSharesOwned) {
-			http.Error(w, "Redeem amount exceeds available position", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Redeem amount exceeds available position")
 			return
 		}
 
@@ -66,7 +67,7 @@ func SellPositionHandler(loadEconConfig setup.EconConfigLoader) func(w http.Resp
 
 		// Validate the final bet before putting into database
 		if err := betutils.ValidateSale(db, &bet); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
@@ -75,13 +76,13 @@ func SellPositionHandler(loadEconConfig setup.EconConfigLoader) func(w http.Resp
 
 		// Update the user's balance in the database
 		if err := db.Save(&user).Error; err != nil {
-			http.Error(w, "Error updating user balance: "+err.Error(), http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error updating user balance: "+err.Error())
 			return
 		}
 
 		result := db.Create(&bet)
 		if result.Error != nil {
-			http.Error(w, result.Error.Error(), http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, result.Error.Error())
 			return
 		}
 
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"socialpredict/handlers/cms/homepage"
 	"socialpredict/middleware"
+	"socialpredict/response"
 	"socialpredict/util"
 )
 
@@ -20,7 +21,7 @@ func NewHandler(svc *homepage.Service) *Handler {
 func (h *Handler) PublicGet(w http.ResponseWriter, r *http.Request) {
 	item, err := h.svc.GetHome()
 	if err != nil {
-		http.Error(w, "not found", http.StatusNotFound)
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "not found")
 		return
 	}
 
@@ -47,20 +48,20 @@ func (h *Handler) AdminUpdate(w http.ResponseWriter, r *http.Request) {
 	// Validate admin access
 	db := util.GetDB()
 	if err := middleware.ValidateAdminToken(r, db); err != nil {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
 		return
 	}
 
 	// Get username from context/token
 	user, httpErr := middleware.ValidateTokenAndGetUser(r, db)
 	if httpErr != nil {
-		http.Error(w, httpErr.Message, httpErr.StatusCode)
+		response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 		return
 	}
 
 	var in updateReq
 	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-		http.Error(w, "bad request", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "bad request")
 		return
 	}
 
@@ -73,7 +74,7 @@ func (h *Handler) AdminUpdate(w http.ResponseWriter, r *http.Request) {
 		UpdatedBy: user.Username,
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 		return
 	}
 
@@ -91,7 +92,7 @@ func RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		db := util.GetDB()
 		if err := middleware.ValidateAdminToken(r, db); err != nil {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -0,0 +1,175 @@
+package eventshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/outbox"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxPollWait is the longest a poll blocks waiting for events.
+	MaxPollWait = 30 * time.Second
+	// pollRecheckInterval bounds how long a poll can miss events published
+	// by another instance's relay, which does not wake this one's bus.
+	pollRecheckInterval = 2 * time.Second
+
+	defaultPollLimit = 100
+	maxPollLimit     = 500
+)
+
+// eventTopics are the topics a poll or stream may filter on.
+var eventTopics = map[string]bool{
+	outbox.TopicMarketCreated:         true,
+	outbox.TopicMarketResolved:        true,
+	outbox.TopicPredictionCreated:     true,
+	outbox.TopicSubmissionResolved:    true,
+	outbox.TopicProposalStatusChanged: true,
+	outbox.TopicNotificationSent:      true,
+}
+
+// eventFilter builds the outbox filter for a request from its
+// comma-separated ?topics= and, for agents, its own notifications. It
+// returns the first unknown topic, if any.
+func eventFilter(r *http.Request) (outbox.Filter, string) {
+	var filter outbox.Filter
+	if topics := r.URL.Query().Get("topics"); topics != "" {
+		for _, topic := range strings.Split(topics, ",") {
+			topic = strings.TrimSpace(topic)
+			if !eventTopics[topic] {
+				return filter, topic
+			}
+			filter.Topics = append(filter.Topics, topic)
+		}
+	}
+	if p := middleware.PrincipalFromContext(r.Context()); p != nil && p.Agent != nil {
+		filter.AgentID = p.Agent.ID
+	}
+	return filter, ""
+}
+
+// PolledEvent is an event as returned to a poll.
+type PolledEvent struct {
+	ID            int64           `json:"id"`
+	Topic         string          `json:"topic"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   int64           `json:"aggregateId"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+func polledEvent(e models.OutboxEvent) PolledEvent {
+	return PolledEvent{
+		ID:            e.ID,
+		Topic:         e.Topic,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		Payload:       json.RawMessage(e.Payload),
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+// PollHandler handles GET /v0/events/poll
+// Long-poll delivery for agent frameworks that cannot hold a WebSocket or
+// SSE connection. Returns the events after ?cursor= as soon as there are
+// any, or an empty list once ?wait= seconds (default and max 30) pass, with
+// the cursor to send next. Without a cursor the poll starts from the newest
+// event. ?topics= is a comma-separated topic filter and ?limit= caps the
+// batch (default 100, max 500). Notification events are only delivered to
+// the agent they are for. bus wakes waiting polls when the outbox relay
+// publishes.
+func PollHandler(db *gorm.DB, bus *outbox.Bus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		filter, unknown := eventFilter(r)
+		if unknown != "" {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Unknown topic: "+unknown)
+			return
+		}
+
+		wait := MaxPollWait
+		if s := query.Get("wait"); s != "" {
+			seconds, err := strconv.Atoi(s)
+			if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > MaxPollWait {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "wait must be between 0 and 30 seconds")
+				return
+			}
+			wait = time.Duration(seconds) * time.Second
+		}
+
+		limit := defaultPollLimit
+		if l := query.Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= maxPollLimit {
+				limit = parsed
+			}
+		}
+
+		var cursor int64
+		if c := query.Get("cursor"); c != "" {
+			parsed, err := strconv.ParseInt(c, 10, 64)
+			if err != nil || parsed < 0 {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid cursor")
+				return
+			}
+			cursor = parsed
+		} else {
+			latest, err := outbox.LatestID(db)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch events")
+				return
+			}
+			cursor = latest
+		}
+
+		deadline := time.Now().Add(wait)
+		var events []models.OutboxEvent
+		for {
+			var changed <-chan struct{}
+			if bus != nil {
+				changed = bus.Changed()
+			}
+			var err error
+			events, err = outbox.Since(db, cursor, filter, limit)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch events")
+				return
+			}
+			remaining := time.Until(deadline)
+			if len(events) > 0 || remaining <= 0 {
+				break
+			}
+
+			timer := time.NewTimer(min(remaining, pollRecheckInterval))
+			select {
+			case <-changed:
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+			timer.Stop()
+		}
+
+		polled := make([]PolledEvent, 0, len(events))
+		for _, e := range events {
+			polled = append(polled, polledEvent(e))
+			cursor = e.ID
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"events":  polled,
+			"cursor":  strconv.FormatInt(cursor, 10),
+		})
+	}
+}
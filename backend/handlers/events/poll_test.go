@@ -0,0 +1,88 @@
+package eventshandlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+)
+
+type pollResponse struct {
+	Events []PolledEvent `json:"events"`
+	Cursor string        `json:"cursor"`
+}
+
+func TestPollHandler_WakesOnPublishAndKeepsNotificationsPrivate(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	bus := outbox.NewBus(nil)
+	handler := PollHandler(db, bus)
+
+	recipient := models.Agent{Name: "recipient", APIKey: "swarm_sk_recipient", ClaimToken: "claim_recipient", IsActive: true}
+	other := models.Agent{Name: "other", APIKey: "swarm_sk_other", ClaimToken: "claim_other", IsActive: true}
+	db.Create(&recipient)
+	db.Create(&other)
+
+	poll := func(query string, agent *models.Agent) pollResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/v0/events/poll"+query, nil)
+		if agent != nil {
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{Tier: middleware.TierAgent, Agent: agent}))
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("poll %s: expected 200, got %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var resp pollResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode poll response: %v", err)
+		}
+		return resp
+	}
+
+	// Nothing new: the poll times out with the cursor at the newest event.
+	start := poll("?wait=0", nil)
+	if len(start.Events) != 0 || start.Cursor != "0" {
+		t.Fatalf("expected an empty poll at cursor 0, got %+v", start)
+	}
+
+	// A waiting poll returns as soon as the relay publishes.
+	done := make(chan pollResponse)
+	go func() { done <- poll("?cursor=0&wait=30", nil) }()
+	time.Sleep(50 * time.Millisecond)
+	if err := outbox.Enqueue(db, outbox.TopicMarketCreated, outbox.AggregateMarket, 7, map[string]int{"marketId": 7}); err != nil {
+		t.Fatalf("enqueue market event: %v", err)
+	}
+	bus.Publish(context.Background(), models.OutboxEvent{})
+	select {
+	case resp := <-done:
+		if len(resp.Events) != 1 || resp.Events[0].Topic != outbox.TopicMarketCreated || resp.Cursor != "1" {
+			t.Fatalf("expected the market event and cursor 1, got %+v", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("poll did not return after the bus published")
+	}
+
+	notification := models.Notification{AgentID: recipient.ID, Kind: "test", Title: "For recipient"}
+	db.Create(&notification)
+	if err := outbox.Enqueue(db, outbox.TopicNotificationSent, outbox.AggregateNotification, notification.ID, map[string]int64{"id": notification.ID}); err != nil {
+		t.Fatalf("enqueue notification event: %v", err)
+	}
+
+	if resp := poll("?cursor=1&wait=0", &other); len(resp.Events) != 0 {
+		t.Fatalf("expected another agent not to see the notification, got %+v", resp.Events)
+	}
+	if resp := poll("?cursor=1&wait=0", &recipient); len(resp.Events) != 1 || resp.Events[0].Topic != outbox.TopicNotificationSent {
+		t.Fatalf("expected the recipient to see its notification, got %+v", resp.Events)
+	}
+	if resp := poll("?cursor=0&wait=0&topics=submission.resolved", &recipient); len(resp.Events) != 0 || resp.Cursor != "0" {
+		t.Fatalf("expected the topic filter to exclude everything, got %+v", resp)
+	}
+}
@@ -0,0 +1,117 @@
+package eventshandlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/outbox"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+// streamHeartbeatInterval is how often an idle stream sends a comment so
+// proxies and clients do not treat the connection as dead.
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamHandler handles GET /v0/stream
+// Server-sent events for new predictions, market creations and
+// resolutions, council decisions, proposal status changes and, for agents,
+// their own notifications. Each event is sent with its outbox ID as the SSE
+// id and its topic as the SSE event name, so a reconnecting client resumes
+// after the Last-Event-ID header (or ?cursor=) without missing anything;
+// without either the stream starts from the newest event. ?topics= is a
+// comma-separated topic filter, as for /v0/events/poll.
+func StreamHandler(db *gorm.DB, bus *outbox.Bus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Streaming unsupported")
+			return
+		}
+
+		filter, unknown := eventFilter(r)
+		if unknown != "" {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Unknown topic: "+unknown)
+			return
+		}
+
+		resume := r.Header.Get("Last-Event-ID")
+		if resume == "" {
+			resume = r.URL.Query().Get("cursor")
+		}
+		var cursor int64
+		if resume != "" {
+			parsed, err := strconv.ParseInt(resume, 10, 64)
+			if err != nil || parsed < 0 {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid cursor")
+				return
+			}
+			cursor = parsed
+		} else {
+			latest, err := outbox.LatestID(db)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch events")
+				return
+			}
+			cursor = latest
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, ": connected\n\n")
+		flusher.Flush()
+
+		recheck := time.NewTicker(pollRecheckInterval)
+		defer recheck.Stop()
+		heartbeat := time.NewTicker(streamHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			var changed <-chan struct{}
+			if bus != nil {
+				changed = bus.Changed()
+			}
+			events, err := outbox.Since(db, cursor, filter, maxPollLimit)
+			if err != nil {
+				// The client reconnects with Last-Event-ID and picks up here.
+				return
+			}
+			for _, e := range events {
+				data, err := json.Marshal(polledEvent(e))
+				if err != nil {
+					return
+				}
+				if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Topic, data); err != nil {
+					return
+				}
+				cursor = e.ID
+			}
+			if len(events) > 0 {
+				flusher.Flush()
+				heartbeat.Reset(streamHeartbeatInterval)
+			}
+			if len(events) == maxPollLimit {
+				continue
+			}
+
+			select {
+			case <-changed:
+			case <-recheck.C:
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package eventshandlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+)
+
+func TestStreamHandler_ResumesAndFiltersByTopic(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	bus := outbox.NewBus(nil)
+
+	enqueue := func(topic, aggregateType string, id int64) {
+		t.Helper()
+		if err := outbox.Enqueue(db, topic, aggregateType, id, map[string]int64{"id": id}); err != nil {
+			t.Fatalf("enqueue %s: %v", topic, err)
+		}
+	}
+	enqueue(outbox.TopicMarketCreated, outbox.AggregateMarket, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/v0/stream?topics=market.created,prediction.created", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "0")
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		StreamHandler(db, bus)(rec, req)
+		close(done)
+	}()
+
+	// Events published while the stream is open are pushed straight away.
+	time.Sleep(50 * time.Millisecond)
+	enqueue(outbox.TopicSubmissionResolved, outbox.AggregateSubmission, 5)
+	enqueue(outbox.TopicPredictionCreated, outbox.AggregatePrediction, 9)
+	bus.Publish(context.Background(), models.OutboxEvent{})
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end when the client went away")
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %q", got)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "id: 1\nevent: market.created\n") {
+		t.Fatalf("expected the stream to resume with the earlier market event, got %q", body)
+	}
+	if !strings.Contains(body, "id: 3\nevent: prediction.created\n") {
+		t.Fatalf("expected the new prediction event, got %q", body)
+	}
+	if strings.Contains(body, "submission.resolved") {
+		t.Fatalf("expected the topic filter to drop the council decision, got %q", body)
+	}
+}
+
+func TestStreamHandler_RejectsUnknownTopic(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	req := httptest.NewRequest(http.MethodGet, "/v0/stream?topics=market.deleted", nil)
+	rec := httptest.NewRecorder()
+	StreamHandler(db, outbox.NewBus(nil))(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown topic, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,383 @@
+package eventshandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/outbox"
+	"socialpredict/response"
+	"socialpredict/services/consensus"
+
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxSubscriptions caps the markets and agents one connection follows.
+	MaxSubscriptions = 50
+
+	// wsPingInterval is how often the server pings a connection. A client
+	// that has gone away fails the write and is dropped.
+	wsPingInterval = 30 * time.Second
+	// wsWriteTimeout bounds each write, so a client that stops reading
+	// cannot hold the connection open.
+	wsWriteTimeout = 10 * time.Second
+	// wsMaxMessageBytes caps a client message; subscribe messages are tiny.
+	wsMaxMessageBytes = 4096
+)
+
+// wsTopics are the events a WebSocket delivers.
+var wsTopics = []string{outbox.TopicPredictionCreated, outbox.TopicMarketResolved}
+
+// Client message types.
+const (
+	wsSubscribe   = "subscribe"
+	wsUnsubscribe = "unsubscribe"
+	wsPing        = "ping"
+)
+
+// Server message types.
+const (
+	WSSubscribed   = "subscribed"
+	WSUnsubscribed = "unsubscribed"
+	WSPrediction   = "prediction"
+	WSConsensus    = "consensus"
+	WSResolution   = "resolution"
+	WSPong         = "pong"
+	WSError        = "error"
+)
+
+// WSRequest is a message from the client: subscribe or unsubscribe with
+// one of Market or Agent, or ping.
+type WSRequest struct {
+	Type   string `json:"type"`
+	Market int64  `json:"market,omitempty"`
+	Agent  int64  `json:"agent,omitempty"`
+}
+
+// WSMessage is a message to the client. Which fields are set depends on
+// Type.
+type WSMessage struct {
+	Type   string `json:"type"`
+	Market int64  `json:"market,omitempty"`
+	Agent  int64  `json:"agent,omitempty"`
+
+	// The outbox event behind a prediction or resolution
+	Event *PolledEvent `json:"event,omitempty"`
+
+	// A market's consensus after a prediction on it; Scalar on scalar
+	// markets
+	Consensus   *float64          `json:"consensus,omitempty"`
+	Predictions int64             `json:"predictions,omitempty"`
+	Scalar      *consensus.Scalar `json:"scalar,omitempty"`
+
+	Code    response.Code `json:"code,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+// wsPredictionEvent is the part of a prediction.created payload
+// subscriptions match on.
+type wsPredictionEvent struct {
+	MarketID int64 `json:"marketId"`
+	AgentID  int64 `json:"agentId"`
+}
+
+// WebSocketHandler handles GET /v0/ws
+// Upgrades to a WebSocket that pushes live updates for the markets and
+// agents the client subscribes to, with JSON text messages such as
+// {"type":"subscribe","market":42} or {"type":"unsubscribe","agent":7}. A
+// subscribed market gets its new and revised predictions, its consensus
+// after each and its resolution; a subscribed agent gets its predictions
+// on markets open to the caller. A connection follows up to
+// MaxSubscriptions markets and agents. The server pings every 30 seconds;
+// clients may also send {"type":"ping"} and get {"type":"pong"}. Updates
+// start from when the connection opened, so a client that reconnects
+// should resume from /v0/events/poll if it cannot miss any. bus wakes the
+// connection when the outbox relay publishes.
+func WebSocketHandler(db *gorm.DB, bus *outbox.Bus) http.HandlerFunc {
+	server := websocket.Server{
+		// Credentials come in headers, not cookies, so any origin may
+		// connect; the route's policy has already authenticated the caller.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			conn.MaxPayloadBytes = wsMaxMessageBytes
+			serveWebSocket(db, bus, conn)
+		},
+	}
+	return server.ServeHTTP
+}
+
+// wsConn is one WebSocket connection and what it follows. Only its serve
+// loop writes to the connection.
+type wsConn struct {
+	db      *gorm.DB
+	conn    *websocket.Conn
+	agentID int64 // the caller, for invite-only markets
+
+	markets map[int64]models.Market
+	agents  map[int64]bool
+	// open caches whether markets the caller did not subscribe to are
+	// open to it, for its agent subscriptions.
+	open map[int64]bool
+}
+
+func serveWebSocket(db *gorm.DB, bus *outbox.Bus, conn *websocket.Conn) {
+	defer conn.Close()
+	c := &wsConn{
+		db:      db,
+		conn:    conn,
+		agentID: middleware.PrincipalFromContext(conn.Request().Context()).AgentID(),
+		markets: map[int64]models.Market{},
+		agents:  map[int64]bool{},
+		open:    map[int64]bool{},
+	}
+
+	cursor, err := outbox.LatestID(db)
+	if err != nil {
+		c.send(WSMessage{Type: WSError, Code: response.CodeInternal, Message: "Failed to fetch events"})
+		return
+	}
+
+	requests := make(chan WSRequest)
+	closed := make(chan struct{}) // the client went away
+	done := make(chan struct{})   // the server is done with the client
+	defer close(done)
+	go func() {
+		defer close(closed)
+		for {
+			var req WSRequest
+			if err := websocket.JSON.Receive(conn, &req); err != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if stderrors.As(err, &syntaxErr) || stderrors.As(err, &typeErr) {
+					req = WSRequest{}
+				} else {
+					return
+				}
+			}
+			select {
+			case requests <- req:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	recheck := time.NewTicker(pollRecheckInterval)
+	defer recheck.Stop()
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		var changed <-chan struct{}
+		if bus != nil {
+			changed = bus.Changed()
+		}
+		if len(c.markets)+len(c.agents) > 0 {
+			if cursor, err = c.deliver(cursor); err != nil {
+				return
+			}
+		} else if cursor, err = outbox.LatestID(db); err != nil {
+			return
+		}
+
+		select {
+		case req := <-requests:
+			if err := c.handle(req); err != nil {
+				return
+			}
+		case <-changed:
+		case <-recheck.C:
+		case <-ping.C:
+			if err := c.ping(); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// handle answers one client message.
+func (c *wsConn) handle(req WSRequest) error {
+	switch {
+	case req.Type == wsPing:
+		return c.send(WSMessage{Type: WSPong})
+	case req.Type != wsSubscribe && req.Type != wsUnsubscribe:
+		return c.send(WSMessage{Type: WSError, Code: response.CodeBadRequest, Message: "type must be subscribe, unsubscribe or ping"})
+	case (req.Market > 0) == (req.Agent > 0):
+		return c.send(WSMessage{Type: WSError, Code: response.CodeBadRequest, Message: "Give one of market or agent"})
+	}
+
+	reply := WSMessage{Type: WSUnsubscribed, Market: req.Market, Agent: req.Agent}
+	if req.Type == wsUnsubscribe {
+		delete(c.markets, req.Market)
+		delete(c.agents, req.Agent)
+		return c.send(reply)
+	}
+
+	reply.Type = WSSubscribed
+	_, followsMarket := c.markets[req.Market]
+	if followsMarket || c.agents[req.Agent] {
+		return c.send(reply)
+	}
+	if len(c.markets)+len(c.agents) >= MaxSubscriptions {
+		return c.send(WSMessage{Type: WSError, Code: response.CodeConflict, Market: req.Market, Agent: req.Agent,
+			Message: "Subscription limit reached; unsubscribe from something first"})
+	}
+
+	if req.Market > 0 {
+		var market models.Market
+		err := c.db.First(&market, req.Market).Error
+		open := err == nil
+		if open {
+			open, err = market.OpenTo(c.db, c.agentID)
+		}
+		if err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return c.send(WSMessage{Type: WSError, Code: response.CodeInternal, Market: req.Market, Message: "Database error"})
+		}
+		if !open {
+			return c.send(WSMessage{Type: WSError, Code: response.CodeMarketNotFound, Market: req.Market, Message: "Market not found"})
+		}
+		c.markets[market.ID] = market
+		return c.send(reply)
+	}
+
+	var agent models.Agent
+	if err := c.db.First(&agent, req.Agent).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return c.send(WSMessage{Type: WSError, Code: response.CodeAgentNotFound, Agent: req.Agent, Message: "Agent not found"})
+		}
+		return c.send(WSMessage{Type: WSError, Code: response.CodeInternal, Agent: req.Agent, Message: "Database error"})
+	}
+	c.agents[agent.ID] = true
+	return c.send(reply)
+}
+
+// deliver sends the events after cursor that the connection follows, then
+// the consensus of each followed market that was predicted on, and returns
+// the new cursor.
+func (c *wsConn) deliver(cursor int64) (int64, error) {
+	for {
+		events, err := outbox.Since(c.db, cursor, outbox.Filter{Topics: wsTopics}, maxPollLimit)
+		if err != nil {
+			return cursor, err
+		}
+
+		var predicted []int64
+		for _, e := range events {
+			cursor = e.ID
+			polled := polledEvent(e)
+
+			if e.Topic == outbox.TopicMarketResolved {
+				market, ok := c.markets[e.AggregateID]
+				if !ok {
+					continue
+				}
+				// The consensus the market resolved at goes first.
+				if i := indexOfID(predicted, market.ID); i >= 0 {
+					predicted = append(predicted[:i], predicted[i+1:]...)
+					if err := c.sendConsensus(market); err != nil {
+						return cursor, err
+					}
+				}
+				if err := c.send(WSMessage{Type: WSResolution, Market: market.ID, Event: &polled}); err != nil {
+					return cursor, err
+				}
+				continue
+			}
+
+			var payload wsPredictionEvent
+			if err := json.Unmarshal([]byte(e.Payload), &payload); err != nil {
+				continue
+			}
+			_, followsMarket := c.markets[payload.MarketID]
+			if !followsMarket && (!c.agents[payload.AgentID] || !c.openTo(payload.MarketID)) {
+				continue
+			}
+			if err := c.send(WSMessage{Type: WSPrediction, Market: payload.MarketID, Agent: payload.AgentID, Event: &polled}); err != nil {
+				return cursor, err
+			}
+			if followsMarket && indexOfID(predicted, payload.MarketID) < 0 {
+				predicted = append(predicted, payload.MarketID)
+			}
+		}
+
+		for _, marketID := range predicted {
+			if err := c.sendConsensus(c.markets[marketID]); err != nil {
+				return cursor, err
+			}
+		}
+		if len(events) < maxPollLimit {
+			return cursor, nil
+		}
+	}
+}
+
+// sendConsensus sends the market's consensus. One that cannot be computed
+// is skipped; the next prediction sends it again.
+func (c *wsConn) sendConsensus(market models.Market) error {
+	msg := WSMessage{Type: WSConsensus, Market: market.ID}
+	if market.IsScalar() {
+		scalar, err := consensus.CurrentScalar(c.db, market.ID)
+		if err != nil {
+			return nil
+		}
+		msg.Scalar = scalar
+		if scalar != nil {
+			msg.Predictions = scalar.Predictions
+		}
+	} else {
+		probability, predictions, err := consensus.Current(c.db, market.ID)
+		if err != nil {
+			return nil
+		}
+		msg.Consensus, msg.Predictions = &probability, predictions
+	}
+	return c.send(msg)
+}
+
+// openTo reports whether the caller may see predictions on the market,
+// which it does not follow; markets that cannot be checked are skipped.
+func (c *wsConn) openTo(marketID int64) bool {
+	if open, ok := c.open[marketID]; ok {
+		return open
+	}
+	var market models.Market
+	if err := c.db.First(&market, marketID).Error; err != nil {
+		return false
+	}
+	open, err := market.OpenTo(c.db, c.agentID)
+	if err != nil {
+		return false
+	}
+	c.open[marketID] = open
+	return open
+}
+
+func (c *wsConn) send(msg WSMessage) error {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return websocket.JSON.Send(c.conn, msg)
+}
+
+func (c *wsConn) ping() error {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	c.conn.PayloadType = websocket.PingFrame
+	defer func() { c.conn.PayloadType = websocket.TextFrame }()
+	_, err := c.conn.Write(nil)
+	return err
+}
+
+// indexOfID returns the index of id in ids, or -1.
+func indexOfID(ids []int64, id int64) int {
+	for i, existing := range ids {
+		if existing == id {
+			return i
+		}
+	}
+	return -1
+}
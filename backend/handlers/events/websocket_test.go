@@ -0,0 +1,127 @@
+package eventshandlers
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+	"socialpredict/response"
+
+	"golang.org/x/net/websocket"
+)
+
+func dialWebSocket(t *testing.T, server *httptest.Server) *websocket.Conn {
+	t.Helper()
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func exchange(t *testing.T, conn *websocket.Conn, req interface{}) WSMessage {
+	t.Helper()
+	if req != nil {
+		if err := websocket.JSON.Send(conn, req); err != nil {
+			t.Fatalf("send %+v: %v", req, err)
+		}
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg WSMessage
+	if err := websocket.JSON.Receive(conn, &msg); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	return msg
+}
+
+func TestWebSocketHandler_PushesSubscribedMarketUpdates(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	bus := outbox.NewBus(nil)
+	followed := modelstesting.GenerateMarket(1, "creator")
+	other := modelstesting.GenerateMarket(2, "creator")
+	for _, market := range []*models.Market{&followed, &other} {
+		if err := db.Create(market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(WebSocketHandler(db, bus))
+	defer server.Close()
+	conn := dialWebSocket(t, server)
+
+	if msg := exchange(t, conn, WSRequest{Type: "subscribe", Market: followed.ID}); msg.Type != WSSubscribed || msg.Market != followed.ID {
+		t.Fatalf("subscribe: got %+v", msg)
+	}
+	if msg := exchange(t, conn, WSRequest{Type: "subscribe", Market: 999}); msg.Type != WSError || msg.Code != response.CodeMarketNotFound {
+		t.Fatalf("subscribe to an unknown market: got %+v", msg)
+	}
+	if msg := exchange(t, conn, WSRequest{Type: "ping"}); msg.Type != WSPong {
+		t.Fatalf("ping: got %+v", msg)
+	}
+
+	publish := func(topic, aggregateType string, id int64, payload interface{}) {
+		t.Helper()
+		if err := outbox.Enqueue(db, topic, aggregateType, id, payload); err != nil {
+			t.Fatalf("enqueue %s: %v", topic, err)
+		}
+		bus.Publish(context.Background(), models.OutboxEvent{})
+	}
+	publish(outbox.TopicPredictionCreated, outbox.AggregatePrediction, 7, map[string]int64{"predictionId": 7, "marketId": other.ID, "agentId": 3})
+	publish(outbox.TopicPredictionCreated, outbox.AggregatePrediction, 8, map[string]int64{"predictionId": 8, "marketId": followed.ID, "agentId": 3})
+	publish(outbox.TopicMarketResolved, outbox.AggregateMarket, followed.ID, map[string]interface{}{"marketId": followed.ID, "outcome": "YES"})
+
+	// The prediction on the other market is not sent.
+	if msg := exchange(t, conn, nil); msg.Type != WSPrediction || msg.Market != followed.ID || msg.Event == nil || msg.Event.AggregateID != 8 {
+		t.Fatalf("expected the prediction on the followed market, got %+v", msg)
+	}
+	if msg := exchange(t, conn, nil); msg.Type != WSConsensus || msg.Market != followed.ID || msg.Consensus == nil {
+		t.Fatalf("expected the market's consensus, got %+v", msg)
+	}
+	if msg := exchange(t, conn, nil); msg.Type != WSResolution || msg.Market != followed.ID {
+		t.Fatalf("expected the resolution, got %+v", msg)
+	}
+
+	if msg := exchange(t, conn, WSRequest{Type: "unsubscribe", Market: followed.ID}); msg.Type != WSUnsubscribed {
+		t.Fatalf("unsubscribe: got %+v", msg)
+	}
+	publish(outbox.TopicPredictionCreated, outbox.AggregatePrediction, 9, map[string]int64{"predictionId": 9, "marketId": followed.ID, "agentId": 3})
+	if msg := exchange(t, conn, WSRequest{Type: "ping"}); msg.Type != WSPong {
+		t.Fatalf("expected nothing more after unsubscribing, got %+v", msg)
+	}
+}
+
+func TestWebSocketHandler_LimitsSubscriptions(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	for i := 0; i <= MaxSubscriptions; i++ {
+		agent := modelstesting.GenerateAgent(fmt.Sprintf("followed-%d", i))
+		if err := db.Create(&agent).Error; err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(WebSocketHandler(db, outbox.NewBus(nil)))
+	defer server.Close()
+	conn := dialWebSocket(t, server)
+
+	var agents []models.Agent
+	db.Order("id").Find(&agents)
+	for _, agent := range agents[:MaxSubscriptions] {
+		if msg := exchange(t, conn, WSRequest{Type: "subscribe", Agent: agent.ID}); msg.Type != WSSubscribed {
+			t.Fatalf("subscribe to agent %d: got %+v", agent.ID, msg)
+		}
+	}
+	last := agents[MaxSubscriptions].ID
+	if msg := exchange(t, conn, WSRequest{Type: "subscribe", Agent: last}); msg.Type != WSError || msg.Code != response.CodeConflict {
+		t.Fatalf("expected the subscription limit, got %+v", msg)
+	}
+	if msg := exchange(t, conn, WSRequest{Type: "subscribe", Market: 1, Agent: 1}); msg.Type != WSError || msg.Code != response.CodeBadRequest {
+		t.Fatalf("expected a market and an agent together to be refused, got %+v", msg)
+	}
+}
@@ -0,0 +1,107 @@
+package exporthandlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/response"
+	"socialpredict/services/bulkexport"
+
+	"gorm.io/gorm"
+)
+
+// nextCursorTrailer is sent after a page that stopped at its limit, with
+// the cursor of the next page.
+const nextCursorTrailer = "X-Next-Cursor"
+
+var contentTypes = map[string]string{
+	bulkexport.FormatNDJSON: "application/x-ndjson",
+	bulkexport.FormatCSV:    "text/csv; charset=utf-8",
+}
+
+// ExportHandler handles GET /v0/export/{predictions,markets,resolutions}
+// Streams the public rows of dataset in ID order as NDJSON (the default) or
+// CSV, picked by ?format= or else the Accept header. ?since= and ?until=,
+// RFC 3339 times or dates, bound the dataset's time; ?cursor= starts after
+// an ID and ?limit= caps the page (default 10000, at most 100000). A page
+// that stops at its limit ends with the X-Next-Cursor trailer.
+func ExportHandler(db *gorm.DB, name string) http.HandlerFunc {
+	dataset := bulkexport.Datasets[name]
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		format, ok := negotiateFormat(q.Get("format"), r.Header.Get("Accept"))
+		if !ok {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "format must be ndjson or csv")
+			return
+		}
+
+		query := bulkexport.Query{Limit: bulkexport.DefaultLimit}
+		if l := q.Get("limit"); l != "" {
+			parsed, err := strconv.Atoi(l)
+			if err != nil || parsed < 1 || parsed > bulkexport.MaxLimit {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, fmt.Sprintf("limit must be between 1 and %d", bulkexport.MaxLimit))
+				return
+			}
+			query.Limit = parsed
+		}
+		if c := q.Get("cursor"); c != "" {
+			parsed, err := strconv.ParseInt(c, 10, 64)
+			if err != nil || parsed < 0 {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid cursor")
+				return
+			}
+			query.Cursor = parsed
+		}
+		for param, bound := range map[string]**time.Time{"since": &query.Since, "until": &query.Until} {
+			v := q.Get(param)
+			if v == "" {
+				continue
+			}
+			t, err := parseTime(v)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, param+" must be an RFC 3339 time or a date")
+				return
+			}
+			*bound = &t
+		}
+
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, dataset.Name, format))
+		w.Header().Set("Trailer", nextCursorTrailer)
+		// Once the export has started the status cannot change, so a
+		// failure part way is logged and leaves it truncated.
+		written, last, err := bulkexport.Write(r.Context(), db, dataset, query, format, w)
+		if err != nil {
+			log.Printf("export %s: %v", dataset.Name, err)
+			return
+		}
+		if written == query.Limit {
+			w.Header().Set(nextCursorTrailer, strconv.FormatInt(last, 10))
+		}
+	}
+}
+
+// negotiateFormat picks the export format from ?format=, or else from the
+// Accept header, defaulting to NDJSON.
+func negotiateFormat(param, accept string) (string, bool) {
+	if param != "" {
+		_, ok := contentTypes[param]
+		return param, ok
+	}
+	if strings.Contains(accept, "text/csv") {
+		return bulkexport.FormatCSV, true
+	}
+	return bulkexport.FormatNDJSON, true
+}
+
+// parseTime reads an RFC 3339 time or a date, taken as midnight UTC.
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
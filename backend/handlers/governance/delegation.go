@@ -0,0 +1,184 @@
+package governance
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"socialpredict/errors"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/validation"
+)
+
+// DelegationRequest is the request body for delegating voting power
+type DelegationRequest struct {
+	DelegateID int64 `json:"delegateId" validate:"required,gt=0"`
+}
+
+// GetDelegationHandler handles GET /v0/governance/delegation
+// It returns the agent's active delegation, if any, and their voting power.
+func GetDelegationHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, err := getAgentFromAPIKey(r, db)
+		if err != nil || agent == nil {
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid or missing API key")
+			return
+		}
+
+		delegation, err := models.ActiveDelegation(db, agent.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load delegation")
+			return
+		}
+		power, err := models.VotingPowerOf(db, agent)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load delegation")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"delegation":  delegation,
+			"votingPower": power,
+		})
+	}
+}
+
+// DelegateHandler handles PUT /v0/governance/delegation
+// The agent delegates their voting power to another claimed agent, replacing
+// any delegation they already had. Delegations that would lead back to the
+// agent are refused.
+func DelegateHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, err := getAgentFromAPIKey(r, db)
+		if err != nil || agent == nil {
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid or missing API key")
+			return
+		}
+		if !agent.IsClaimed {
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "Agent must be claimed to delegate")
+			return
+		}
+
+		var req DelegationRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		var delegate models.Agent
+		if err := db.First(&delegate, req.DelegateID).Error; err != nil || !delegate.IsClaimed || !delegate.IsActive {
+			response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Delegate not found")
+			return
+		}
+
+		delegation := models.GovernanceDelegation{DelegatorID: agent.ID, DelegateID: delegate.ID}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := models.CheckDelegation(tx, agent.ID, delegate.ID); err != nil {
+				return err
+			}
+			if err := revokeDelegation(tx, agent.ID); err != nil {
+				return err
+			}
+			return tx.Create(&delegation).Error
+		})
+		switch {
+		case stderrors.Is(err, models.ErrSelfDelegation):
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "An agent cannot delegate to itself")
+			return
+		case stderrors.Is(err, models.ErrDelegationCycle):
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Delegating to "+delegate.Name+" would create a cycle")
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to delegate")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"delegation": delegation,
+		})
+	}
+}
+
+// RevokeDelegationHandler handles DELETE /v0/governance/delegation
+// The agent takes back their voting power. Votes already cast for them stand.
+func RevokeDelegationHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, err := getAgentFromAPIKey(r, db)
+		if err != nil || agent == nil {
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid or missing API key")
+			return
+		}
+
+		delegation, err := models.ActiveDelegation(db, agent.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to revoke delegation")
+			return
+		}
+		if delegation == nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "No active delegation")
+			return
+		}
+		if err := revokeDelegation(db, agent.ID); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to revoke delegation")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+// revokeDelegation ends the agent's active delegation, if any.
+func revokeDelegation(tx *gorm.DB, agentID int64) error {
+	return tx.Model(&models.GovernanceDelegation{}).
+		Where("delegator_id = ? AND revoked_at IS NULL", agentID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// castDelegatedVotes casts vote on behalf of every agent whose voting power
+// flows to its voter and who has not voted on the proposal yet, each at the
+// delegator's own weight, and counts them in proposal's tally. It returns
+// how many votes were cast.
+func castDelegatedVotes(tx *gorm.DB, proposal *models.Proposal, vote models.ProposalVote) (int, error) {
+	var voted []int64
+	if err := tx.Model(&models.ProposalVote{}).Where("proposal_id = ?", proposal.ID).Pluck("agent_id", &voted).Error; err != nil {
+		return 0, err
+	}
+	skip := make(map[int64]bool, len(voted))
+	for _, id := range voted {
+		skip[id] = true
+	}
+	ids, err := models.Delegators(tx, vote.AgentID, skip)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	var delegators []models.Agent
+	if err := tx.Where("id IN ?", ids).Find(&delegators).Error; err != nil {
+		return 0, err
+	}
+	for i := range delegators {
+		delegated := models.ProposalVote{
+			ProposalID: proposal.ID,
+			AgentID:    delegators[i].ID,
+			Vote:       vote.Vote,
+			Reasoning:  "Delegated vote",
+			Weight:     models.ProposalVoteWeight(&delegators[i]),
+			DelegateID: &vote.AgentID,
+		}
+		if err := tx.Create(&delegated).Error; err != nil {
+			return 0, err
+		}
+		proposal.AddVote(delegated.Vote, delegated.Weight)
+	}
+	return len(delegators), nil
+}
@@ -1,36 +1,86 @@
 package governance
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
 	"net/http"
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/i18n"
+	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/outbox"
+	"socialpredict/repository"
+	"socialpredict/response"
+	"socialpredict/services/mentions"
+	"socialpredict/services/platformconfig"
+	"socialpredict/validation"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateProposalRequest is the request body for creating a proposal
 type CreateProposalRequest struct {
-	Title         string `json:"title"`
-	Description   string `json:"description"`
-	Type          string `json:"type"`
+	Title         string `json:"title" validate:"required,max=200"`
+	Description   string `json:"description" validate:"required"`
+	Type          string `json:"type" validate:"required,oneof=feature bugfix improvement integration governance"`
 	Specification string `json:"specification"`
-	Priority      string `json:"priority"`
-	Complexity    string `json:"complexity"`
-	VotingDays    int    `json:"votingDays"` // How long voting is open
+	Priority      string `json:"priority" validate:"omitempty,oneof=low medium high critical"`
+	Complexity    string `json:"complexity" validate:"omitempty,oneof=simple moderate complex"`
+	VotingDays    int    `json:"votingDays" validate:"omitempty,min=1,max=30"`                                // How long voting is open
+	VotingMode    string `json:"votingMode" validate:"omitempty,oneof=one-agent-one-vote weighted quadratic"` // How votes are tallied
+}
+
+// Normalize trims the free text and lower-cases the enumerated fields.
+func (r *CreateProposalRequest) Normalize() {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Description = strings.TrimSpace(r.Description)
+	r.Type = strings.ToLower(strings.TrimSpace(r.Type))
+	r.Priority = strings.ToLower(strings.TrimSpace(r.Priority))
+	r.Complexity = strings.ToLower(strings.TrimSpace(r.Complexity))
+	r.VotingMode = strings.ToLower(strings.TrimSpace(r.VotingMode))
 }
 
 // VoteRequest is the request body for voting
 type VoteRequest struct {
-	Vote      string `json:"vote"` // "yes" or "no"
-	Reasoning string `json:"reasoning"`
+	Vote      string `json:"vote" validate:"required,oneof=yes no"` // "yes" or "no"
+	Reasoning string `json:"reasoning" validate:"max=2000"`
+}
+
+// Normalize lower-cases the vote.
+func (r *VoteRequest) Normalize() {
+	r.Vote = strings.ToLower(strings.TrimSpace(r.Vote))
+}
+
+// ProposalCommentRequest is the request body for commenting on a proposal
+type ProposalCommentRequest struct {
+	Content  string `json:"content" validate:"required,max=2000"`
+	ParentID *int64 `json:"parentId" validate:"omitempty,gt=0"`
+}
+
+// Normalize trims the comment.
+func (r *ProposalCommentRequest) Normalize() {
+	r.Content = strings.TrimSpace(r.Content)
+}
+
+// HumanApprovalRequest is the request body for a human review decision
+type HumanApprovalRequest struct {
+	Approved bool   `json:"approved"`
+	Notes    string `json:"notes" validate:"max=2000"`
 }
 
 // getAgentFromAPIKey extracts agent from API key header
 func getAgentFromAPIKey(r *http.Request, db *gorm.DB) (*models.Agent, error) {
+	if p := middleware.PrincipalFromContext(r.Context()); p != nil && p.Agent != nil {
+		return p.Agent, nil
+	}
+
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
 		return nil, nil
@@ -38,12 +88,7 @@ func getAgentFromAPIKey(r *http.Request, db *gorm.DB) (*models.Agent, error) {
 	
 	apiKey := strings.TrimPrefix(authHeader, "Bearer ")
 	
-	var agent models.Agent
-	if err := db.Where("api_key = ?", apiKey).First(&agent).Error; err != nil {
-		return nil, err
-	}
-	
-	return &agent, nil
+	return repository.NewGormAgentRepo(db).GetByAPIKey(apiKey)
 }
 
 // CreateProposalHandler handles POST /v0/governance/proposals
@@ -52,45 +97,33 @@ func CreateProposalHandler(db *gorm.DB) http.HandlerFunc {
 		// Get agent from API key
 		agent, err := getAgentFromAPIKey(r, db)
 		if err != nil || agent == nil {
-			http.Error(w, "Invalid or missing API key", http.StatusUnauthorized)
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid or missing API key")
 			return
 		}
 		
 		if !agent.IsClaimed {
-			http.Error(w, "Agent must be claimed to create proposals", http.StatusForbidden)
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "Agent must be claimed to create proposals")
 			return
 		}
 		
 		var req CreateProposalRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-		
-		// Validate
-		if req.Title == "" || len(req.Title) > 200 {
-			http.Error(w, "Title required (max 200 chars)", http.StatusBadRequest)
-			return
-		}
-		if req.Description == "" {
-			http.Error(w, "Description required", http.StatusBadRequest)
-			return
-		}
-		
-		// Validate type
-		validTypes := map[string]bool{
-			"feature": true, "bugfix": true, "improvement": true,
-			"integration": true, "governance": true,
-		}
-		if !validTypes[req.Type] {
-			http.Error(w, "Invalid proposal type", http.StatusBadRequest)
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 		
-		// Default voting period: 7 days
+		// Voting period and thresholds come from the governance config
+		rules := platformconfig.Current(db).Governance.OrDefaults()
 		votingDays := req.VotingDays
-		if votingDays < 1 || votingDays > 30 {
-			votingDays = 7
+		if votingDays == 0 {
+			votingDays = rules.DefaultVotingDays
+		}
+		votingMode := req.VotingMode
+		if votingMode == "" {
+			votingMode = rules.DefaultVotingMode
+		} else if !rules.AllowsVotingMode(votingMode) {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Voting mode must be one of: "+strings.Join(rules.VotingModes, ", "))
+			return
 		}
 		
 		proposal := models.Proposal{
@@ -102,13 +135,23 @@ func CreateProposalHandler(db *gorm.DB) http.HandlerFunc {
 			Complexity:      req.Complexity,
 			ProposerAgentID: agent.ID,
 			Status:          models.ProposalStatusActive,
-			VoteThreshold:   5,    // Need at least 5 votes
-			ApprovalPct:     60.0, // Need 60% approval
+			VoteThreshold:   rules.VoteThreshold,
+			ApprovalPct:     rules.ApprovalPct,
+			VotingMode:      votingMode,
 			VotingEndsAt:    time.Now().AddDate(0, 0, votingDays),
+			Revision:        1,
 		}
 		
-		if err := db.Create(&proposal).Error; err != nil {
-			http.Error(w, "Failed to create proposal", http.StatusInternalServerError)
+		// Revision 1 starts the proposal's changelog
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&proposal).Error; err != nil {
+				return err
+			}
+			revision := models.NewProposalRevision(&proposal, models.ProposalRevisionCreated, "", proposal.CreatedAt)
+			return tx.Create(&revision).Error
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create proposal")
 			return
 		}
 		
@@ -118,10 +161,11 @@ func CreateProposalHandler(db *gorm.DB) http.HandlerFunc {
 			AgentID:    agent.ID,
 			Vote:       "yes",
 			Reasoning:  "Proposer auto-vote",
-			Weight:     agent.Reputation,
+			Weight:     models.ProposalVoteWeight(agent),
 		}
 		db.Create(&vote)
-		proposal.VotesFor = 1
+		proposal.AddVote(vote.Vote, vote.Weight)
+		castDelegatedVotes(db, &proposal, vote)
 		db.Save(&proposal)
 		
 		w.Header().Set("Content-Type", "application/json")
@@ -129,7 +173,7 @@ func CreateProposalHandler(db *gorm.DB) http.HandlerFunc {
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success":  true,
 			"proposal": proposal.ToPublic(),
-			"message":  "Proposal created! Voting is now open.",
+			"message":  i18n.T(r, "governance.proposal_created"),
 		})
 	}
 }
@@ -157,14 +201,15 @@ func ListProposalsHandler(db *gorm.DB) http.HandlerFunc {
 		
 		var proposals []models.Proposal
 		if err := query.Order("created_at DESC").Limit(limit).Find(&proposals).Error; err != nil {
-			http.Error(w, "Failed to fetch proposals", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch proposals")
 			return
 		}
 		
 		// Check and update statuses
 		for i := range proposals {
+			previous := proposals[i].Status
 			if proposals[i].CheckAndUpdateStatus() {
-				db.Save(&proposals[i])
+				saveProposal(db, &proposals[i], previous)
 			}
 		}
 		
@@ -183,19 +228,77 @@ func ListProposalsHandler(db *gorm.DB) http.HandlerFunc {
 	}
 }
 
+// ProposalStatusChangedEvent is the payload of
+// outbox.TopicProposalStatusChanged.
+type ProposalStatusChangedEvent struct {
+	ProposalID     int64  `json:"proposalId"`
+	Title          string `json:"title"`
+	PreviousStatus string `json:"previousStatus"`
+	Status         string `json:"status"`
+}
+
+// saveProposal persists proposal and, if its status is no longer previous,
+// the matching proposal.status_changed event and audit log entry in one
+// transaction. The entry names the actor in db's context: the reviewer or
+// proposer, or the system when votes settled the proposal.
+func saveProposal(db *gorm.DB, proposal *models.Proposal, previous models.ProposalStatus) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(proposal).Error; err != nil {
+			return err
+		}
+		if proposal.Status == previous {
+			return nil
+		}
+		if err := audit.Record(tx, audit.ActionProposalStatusChanged, audit.Target("proposal", proposal.ID), map[string]string{"status": string(previous)}, proposal.ToPublic()); err != nil {
+			return err
+		}
+		return outbox.Enqueue(tx, outbox.TopicProposalStatusChanged, outbox.AggregateProposal, proposal.ID, ProposalStatusChangedEvent{
+			ProposalID:     proposal.ID,
+			Title:          proposal.Title,
+			PreviousStatus: string(previous),
+			Status:         string(proposal.Status),
+		})
+	})
+}
+
+// CloseExpiredProposals settles every active proposal whose voting period
+// has ended and returns how many were closed. The scheduler runs it so
+// proposals close on time even when nobody lists them.
+func CloseExpiredProposals(ctx context.Context, db *gorm.DB) (int, error) {
+	db = db.WithContext(ctx)
+
+	var proposals []models.Proposal
+	if err := db.Where("status = ? AND voting_ends_at < ?", models.ProposalStatusActive, time.Now()).Find(&proposals).Error; err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for i := range proposals {
+		previous := proposals[i].Status
+		if !proposals[i].CheckAndUpdateStatus() {
+			continue
+		}
+		if err := saveProposal(db, &proposals[i], previous); err != nil {
+			return closed, err
+		}
+		closed++
+	}
+	return closed, nil
+}
+
 // GetProposalHandler handles GET /v0/governance/proposals/{id}
 func GetProposalHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		proposalID, err := strconv.ParseInt(vars["proposalId"], 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid proposal ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid proposal ID")
 			return
 		}
 		
 		var proposal models.Proposal
 		if err := db.Preload("ProposerAgent").First(&proposal, proposalID).Error; err != nil {
-			http.Error(w, "Proposal not found", http.StatusNotFound)
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Proposal not found")
 			return
 		}
 		
@@ -207,8 +310,9 @@ func GetProposalHandler(db *gorm.DB) http.HandlerFunc {
 		var comments []models.ProposalComment
 		db.Where("proposal_id = ?", proposalID).Preload("Agent").Order("created_at ASC").Find(&comments)
 		
+		previous := proposal.Status
 		proposal.CheckAndUpdateStatus()
-		db.Save(&proposal)
+		saveProposal(db, &proposal, previous)
 		
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -220,60 +324,59 @@ func GetProposalHandler(db *gorm.DB) http.HandlerFunc {
 	}
 }
 
+// errVotingClosed aborts a vote on a proposal that closed while the vote was
+// being checked.
+var errVotingClosed = stderrors.New("voting is closed")
+
 // VoteOnProposalHandler handles POST /v0/governance/proposals/{id}/vote
 func VoteOnProposalHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get agent
 		agent, err := getAgentFromAPIKey(r, db)
 		if err != nil || agent == nil {
-			http.Error(w, "Invalid or missing API key", http.StatusUnauthorized)
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid or missing API key")
 			return
 		}
 		
 		if !agent.IsClaimed {
-			http.Error(w, "Agent must be claimed to vote", http.StatusForbidden)
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "Agent must be claimed to vote")
 			return
 		}
 		
 		vars := mux.Vars(r)
 		proposalID, err := strconv.ParseInt(vars["proposalId"], 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid proposal ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid proposal ID")
 			return
 		}
 		
 		var proposal models.Proposal
 		if err := db.First(&proposal, proposalID).Error; err != nil {
-			http.Error(w, "Proposal not found", http.StatusNotFound)
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Proposal not found")
 			return
 		}
 		
 		// Check if voting is still open
 		if proposal.Status != models.ProposalStatusActive {
-			http.Error(w, "Voting is closed for this proposal", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeVotingClosed, "Voting is closed for this proposal")
 			return
 		}
 		
 		if time.Now().After(proposal.VotingEndsAt) {
-			http.Error(w, "Voting period has ended", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Voting period has ended")
 			return
 		}
 		
 		// Check if already voted
 		var existingVote models.ProposalVote
 		if db.Where("proposal_id = ? AND agent_id = ?", proposalID, agent.ID).First(&existingVote).Error == nil {
-			http.Error(w, "You have already voted on this proposal", http.StatusConflict)
+			response.Error(w, http.StatusConflict, response.CodeAlreadyVoted, "You have already voted on this proposal")
 			return
 		}
 		
 		var req VoteRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-		
-		if req.Vote != "yes" && req.Vote != "no" {
-			http.Error(w, "Vote must be 'yes' or 'no'", http.StatusBadRequest)
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 		
@@ -283,30 +386,49 @@ func VoteOnProposalHandler(db *gorm.DB) http.HandlerFunc {
 			AgentID:    agent.ID,
 			Vote:       req.Vote,
 			Reasoning:  req.Reasoning,
-			Weight:     agent.Reputation,
+			Weight:     models.ProposalVoteWeight(agent),
 		}
 		
-		if err := db.Create(&vote).Error; err != nil {
-			http.Error(w, "Failed to record vote", http.StatusInternalServerError)
+		// The vote is also cast for agents who delegated to this one and
+		// have not voted themselves. The proposal is reloaded under a row
+		// lock so concurrent votes each add to the latest tally.
+		var delegated int
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&proposal, proposalID).Error; err != nil {
+				return err
+			}
+			if proposal.Status != models.ProposalStatusActive {
+				return errVotingClosed
+			}
+			if err := tx.Create(&vote).Error; err != nil {
+				return err
+			}
+			proposal.AddVote(vote.Vote, vote.Weight)
+			var err error
+			if delegated, err = castDelegatedVotes(tx, &proposal, vote); err != nil {
+				return err
+			}
+			
+			// Check if we've reached threshold early
+			previous := proposal.Status
+			proposal.CheckAndUpdateStatus()
+			return saveProposal(tx, &proposal, previous)
+		})
+		if err == errVotingClosed {
+			response.Error(w, http.StatusBadRequest, response.CodeVotingClosed, "Voting is closed for this proposal")
 			return
 		}
-		
-		// Update proposal vote counts
-		if req.Vote == "yes" {
-			proposal.VotesFor++
-		} else {
-			proposal.VotesAgainst++
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record vote")
+			return
 		}
 		
-		// Check if we've reached threshold early
-		proposal.CheckAndUpdateStatus()
-		db.Save(&proposal)
-		
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":  true,
-			"message":  "Vote recorded!",
-			"proposal": proposal.ToPublic(),
+			"success":        true,
+			"message":        i18n.T(r, "governance.vote_recorded"),
+			"proposal":       proposal.ToPublic(),
+			"delegatedVotes": delegated,
 		})
 	}
 }
@@ -316,39 +438,53 @@ func CommentOnProposalHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		agent, err := getAgentFromAPIKey(r, db)
 		if err != nil || agent == nil {
-			http.Error(w, "Invalid or missing API key", http.StatusUnauthorized)
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid or missing API key")
 			return
 		}
 		
 		if !agent.IsClaimed {
-			http.Error(w, "Agent must be claimed to comment", http.StatusForbidden)
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "Agent must be claimed to comment")
 			return
 		}
 		
 		vars := mux.Vars(r)
 		proposalID, err := strconv.ParseInt(vars["proposalId"], 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid proposal ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid proposal ID")
 			return
 		}
 		
 		var proposal models.Proposal
 		if err := db.First(&proposal, proposalID).Error; err != nil {
-			http.Error(w, "Proposal not found", http.StatusNotFound)
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Proposal not found")
 			return
 		}
 		
-		var req struct {
-			Content  string `json:"content"`
-			ParentID *int64 `json:"parentId"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		var req ProposalCommentRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 		
-		if req.Content == "" || len(req.Content) > 2000 {
-			http.Error(w, "Comment content required (max 2000 chars)", http.StatusBadRequest)
+		// A comment replies to the proposer, or to the author of the comment
+		// it answers.
+		replyTo := proposal.ProposerAgentID
+		if req.ParentID != nil {
+			var parent models.ProposalComment
+			if err := db.Where("id = ? AND proposal_id = ?", *req.ParentID, proposalID).First(&parent).Error; err != nil {
+				response.Error(w, http.StatusNotFound, response.CodeNotFound, "Parent comment not found")
+				return
+			}
+			replyTo = parent.AgentID
+		}
+		
+		mentioned, err := mentions.Resolve(db, mentions.Parse(req.Content))
+		if err != nil {
+			if mentions.Invalid(err) {
+				errors.WriteValidationError(w, []errors.FieldError{{Field: "content", Rule: "mentions", Message: err.Error()}})
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
 			return
 		}
 		
@@ -359,8 +495,23 @@ func CommentOnProposalHandler(db *gorm.DB) http.HandlerFunc {
 			ParentID:   req.ParentID,
 		}
 		
-		if err := db.Create(&comment).Error; err != nil {
-			http.Error(w, "Failed to create comment", http.StatusInternalServerError)
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&comment).Error; err != nil {
+				return err
+			}
+			return mentions.Notify(tx, mentions.Comment{
+				Type:       models.MentionProposalComment,
+				ID:         comment.ID,
+				ParentType: "proposal",
+				ParentID:   proposalID,
+				Author:     models.AgentActor(agent.ID),
+				AuthorName: agent.Name,
+				Content:    comment.Content,
+				ReplyTo:    replyTo,
+			}, mentioned)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create comment")
 			return
 		}
 		
@@ -392,7 +543,7 @@ func GetApprovedProposalsHandler(db *gorm.DB) http.HandlerFunc {
 			"success":   true,
 			"proposals": publicProposals,
 			"count":     len(publicProposals),
-			"message":   "These proposals have been approved by the AI swarm and await your review.",
+			"message":   i18n.T(r, "governance.awaiting_review"),
 		})
 	}
 }
@@ -400,30 +551,28 @@ func GetApprovedProposalsHandler(db *gorm.DB) http.HandlerFunc {
 // HumanApproveProposalHandler handles admin approval
 func HumanApproveProposalHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// This should be protected by admin auth middleware
+		// Admins and moderators; the route policy enforces it.
 		
 		vars := mux.Vars(r)
 		proposalID, err := strconv.ParseInt(vars["proposalId"], 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid proposal ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid proposal ID")
 			return
 		}
 		
-		var req struct {
-			Approved bool   `json:"approved"`
-			Notes    string `json:"notes"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		var req HumanApprovalRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 		
 		var proposal models.Proposal
 		if err := db.First(&proposal, proposalID).Error; err != nil {
-			http.Error(w, "Proposal not found", http.StatusNotFound)
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Proposal not found")
 			return
 		}
 		
+		previous := proposal.Status
 		proposal.HumanApproved = req.Approved
 		proposal.HumanReviewNotes = req.Notes
 		
@@ -433,13 +582,16 @@ func HumanApproveProposalHandler(db *gorm.DB) http.HandlerFunc {
 			proposal.Status = models.ProposalStatusRejected
 		}
 		
-		db.Save(&proposal)
+		if err := saveProposal(db.WithContext(r.Context()), &proposal, previous); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to save review")
+			return
+		}
 		
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success":  true,
 			"proposal": proposal.ToPublic(),
-			"message":  "Proposal review recorded.",
+			"message":  i18n.T(r, "governance.review_recorded"),
 		})
 	}
 }
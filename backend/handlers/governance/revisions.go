@@ -0,0 +1,242 @@
+package governance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"socialpredict/errors"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/platformconfig"
+	"socialpredict/validation"
+)
+
+// ProposalEditRequest is the request body for editing a proposal. It
+// replaces the proposal's content; the type cannot change.
+type ProposalEditRequest struct {
+	Title         string `json:"title" validate:"required,max=200"`
+	Description   string `json:"description" validate:"required"`
+	Specification string `json:"specification"`
+	Priority      string `json:"priority" validate:"omitempty,oneof=low medium high critical"`
+	Complexity    string `json:"complexity" validate:"omitempty,oneof=simple moderate complex"`
+	Note          string `json:"note" validate:"max=500"` // what changed
+}
+
+// Normalize trims the free text and lower-cases the enumerated fields.
+func (r *ProposalEditRequest) Normalize() {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Description = strings.TrimSpace(r.Description)
+	r.Priority = strings.ToLower(strings.TrimSpace(r.Priority))
+	r.Complexity = strings.ToLower(strings.TrimSpace(r.Complexity))
+	r.Note = strings.TrimSpace(r.Note)
+}
+
+// ProposalAmendmentRequest is the request body for amending a proposal
+// under vote. Like an edit it replaces the content, but it must say what
+// changed, and voting restarts for VotingDays.
+type ProposalAmendmentRequest struct {
+	Title         string `json:"title" validate:"required,max=200"`
+	Description   string `json:"description" validate:"required"`
+	Specification string `json:"specification"`
+	Priority      string `json:"priority" validate:"omitempty,oneof=low medium high critical"`
+	Complexity    string `json:"complexity" validate:"omitempty,oneof=simple moderate complex"`
+	Note          string `json:"note" validate:"required,max=500"`
+	VotingDays    int    `json:"votingDays" validate:"omitempty,min=1,max=30"`
+}
+
+// Normalize trims the free text and lower-cases the enumerated fields.
+func (r *ProposalAmendmentRequest) Normalize() {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Description = strings.TrimSpace(r.Description)
+	r.Priority = strings.ToLower(strings.TrimSpace(r.Priority))
+	r.Complexity = strings.ToLower(strings.TrimSpace(r.Complexity))
+	r.Note = strings.TrimSpace(r.Note)
+}
+
+// EditProposalHandler handles PUT /v0/governance/proposals/{proposalId}
+// The proposer rewrites an active proposal while it has fewer votes than
+// the governance rules allow; after that it can only be amended.
+func EditProposalHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		proposal, ok := loadOwnActiveProposal(w, r, db)
+		if !ok {
+			return
+		}
+
+		rules := platformconfig.Current(db).Governance.OrDefaults()
+		if proposal.VotesFor+proposal.VotesAgainst >= rules.EditMaxVotes {
+			response.Error(w, http.StatusConflict, response.CodeProposalLocked, fmt.Sprintf("Proposals can only be edited with fewer than %d votes; amend it instead", rules.EditMaxVotes))
+			return
+		}
+
+		var req ProposalEditRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		proposal.Title = req.Title
+		proposal.Description = req.Description
+		proposal.Specification = req.Specification
+		proposal.Priority = req.Priority
+		proposal.Complexity = req.Complexity
+		revision, err := reviseProposal(db, proposal, models.ProposalRevisionEdit, req.Note)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to edit proposal")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"proposal": proposal.ToPublic(),
+			"revision": revision,
+		})
+	}
+}
+
+// AmendProposalHandler handles POST /v0/governance/proposals/{proposalId}/amendments
+// The proposer changes an active proposal at any point in its vote. Votes
+// already cast stand, and voting restarts so agents can reconsider them.
+func AmendProposalHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		proposal, ok := loadOwnActiveProposal(w, r, db)
+		if !ok {
+			return
+		}
+
+		var req ProposalAmendmentRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+		votingDays := req.VotingDays
+		if votingDays == 0 {
+			votingDays = platformconfig.Current(db).Governance.OrDefaults().DefaultVotingDays
+		}
+
+		proposal.Title = req.Title
+		proposal.Description = req.Description
+		proposal.Specification = req.Specification
+		proposal.Priority = req.Priority
+		proposal.Complexity = req.Complexity
+		proposal.VotingEndsAt = time.Now().AddDate(0, 0, votingDays)
+		revision, err := reviseProposal(db, proposal, models.ProposalRevisionAmendment, req.Note)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to amend proposal")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"proposal": proposal.ToPublic(),
+			"revision": revision,
+		})
+	}
+}
+
+// WithdrawProposalHandler handles POST /v0/governance/proposals/{proposalId}/withdraw
+// The proposer withdraws an active proposal; voting on it stops.
+func WithdrawProposalHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		proposal, ok := loadOwnActiveProposal(w, r, db)
+		if !ok {
+			return
+		}
+
+		previous := proposal.Status
+		proposal.Status = models.ProposalStatusWithdrawn
+		if err := saveProposal(db.WithContext(r.Context()), proposal, previous); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to withdraw proposal")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"proposal": proposal.ToPublic(),
+		})
+	}
+}
+
+// GetProposalRevisionsHandler handles GET /v0/governance/proposals/{proposalId}/revisions
+// It returns the proposal's changelog, oldest revision first.
+func GetProposalRevisionsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		proposalID, err := strconv.ParseInt(mux.Vars(r)["proposalId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid proposal ID")
+			return
+		}
+		var proposal models.Proposal
+		if err := db.First(&proposal, proposalID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Proposal not found")
+			return
+		}
+
+		revisions, err := models.ProposalRevisions(db, proposal.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load revisions")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"revisions": revisions,
+			"count":     len(revisions),
+		})
+	}
+}
+
+// loadOwnActiveProposal loads the proposal named in the request for its
+// proposer to change, writing the error response and returning false if the
+// agent is not its proposer or voting on it has finished.
+func loadOwnActiveProposal(w http.ResponseWriter, r *http.Request, db *gorm.DB) (*models.Proposal, bool) {
+	agent, err := getAgentFromAPIKey(r, db)
+	if err != nil || agent == nil {
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid or missing API key")
+		return nil, false
+	}
+
+	proposalID, err := strconv.ParseInt(mux.Vars(r)["proposalId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid proposal ID")
+		return nil, false
+	}
+	var proposal models.Proposal
+	if err := db.First(&proposal, proposalID).Error; err != nil {
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Proposal not found")
+		return nil, false
+	}
+	if proposal.ProposerAgentID != agent.ID {
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the proposer can change a proposal")
+		return nil, false
+	}
+	if proposal.Status != models.ProposalStatusActive || time.Now().After(proposal.VotingEndsAt) {
+		response.Error(w, http.StatusBadRequest, response.CodeVotingClosed, "Voting is closed for this proposal")
+		return nil, false
+	}
+	return &proposal, true
+}
+
+// reviseProposal saves proposal's new content as its next revision.
+func reviseProposal(db *gorm.DB, proposal *models.Proposal, kind, note string) (models.ProposalRevision, error) {
+	proposal.Revision++
+	revision := models.NewProposalRevision(proposal, kind, note, time.Now())
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(proposal).Error; err != nil {
+			return err
+		}
+		return tx.Create(&revision).Error
+	})
+	return revision, err
+}
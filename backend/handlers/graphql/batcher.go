@@ -0,0 +1,76 @@
+package graphqlhandlers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// batchWait is how long a batcher collects loads before fetching them.
+// graphql-go resolves sibling fields concurrently, so the loads made for
+// one level of a query all arrive within it.
+const batchWait = 16 * time.Millisecond
+
+// batchFunc fetches the values of ids keyed by ID. An ID missing from the
+// map loads as the zero value.
+type batchFunc[V any] func(ctx context.Context, ids []int64) (map[int64]V, error)
+
+// batcher fetches the IDs loaded within batchWait of each other with one
+// call to fetch, and remembers what it fetched, so each ID is fetched at
+// most once. It stands in for a dataloader library: the graph-gophers one
+// this package used is no longer maintained, and a loader keyed by int64
+// needs little more than this.
+type batcher[V any] struct {
+	fetch batchFunc[V]
+
+	mu      sync.Mutex
+	pending *batch[V]
+	batches map[int64]*batch[V]
+}
+
+// batch is one call to fetch. done is closed once values and err are set.
+type batch[V any] struct {
+	ids    []int64
+	done   chan struct{}
+	values map[int64]V
+	err    error
+}
+
+func newBatcher[V any](fetch batchFunc[V]) *batcher[V] {
+	return &batcher[V]{fetch: fetch, batches: make(map[int64]*batch[V])}
+}
+
+// load waits for the value of id, or for ctx to be done.
+func (b *batcher[V]) load(ctx context.Context, id int64) (V, error) {
+	b.mu.Lock()
+	pending, ok := b.batches[id]
+	if !ok {
+		if b.pending == nil {
+			b.pending = &batch[V]{done: make(chan struct{})}
+			started := b.pending
+			time.AfterFunc(batchWait, func() { b.run(ctx, started) })
+		}
+		pending = b.pending
+		pending.ids = append(pending.ids, id)
+		b.batches[id] = pending
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-pending.done:
+		return pending.values[id], pending.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// run fetches batch with the context of its first load.
+func (b *batcher[V]) run(ctx context.Context, batch *batch[V]) {
+	b.mu.Lock()
+	b.pending = nil
+	b.mu.Unlock()
+
+	batch.values, batch.err = b.fetch(ctx, batch.ids)
+	close(batch.done)
+}
@@ -0,0 +1,50 @@
+package graphqlhandlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestBatcher_FetchesConcurrentLoadsOnceEach(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetched [][]int64
+	)
+	b := newBatcher(func(_ context.Context, ids []int64) (map[int64]string, error) {
+		mu.Lock()
+		fetched = append(fetched, ids)
+		mu.Unlock()
+		return map[int64]string{1: "one", 2: "two"}, nil
+	})
+
+	ids := []int64{1, 2, 1, 3, 2}
+	got := make([]string, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := b.load(context.Background(), id)
+			if err != nil {
+				t.Errorf("load(%d): %v", id, err)
+			}
+			got[i] = value
+		}()
+	}
+	wg.Wait()
+
+	want := []string{"one", "two", "one", "", "two"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("load(%d) = %q, want %q", ids[i], got[i], want[i])
+		}
+	}
+	if len(fetched) != 1 || len(fetched[0]) != 3 {
+		t.Fatalf("fetched %v, want IDs 1, 2 and 3 in one batch", fetched)
+	}
+
+	if value, _ := b.load(context.Background(), 2); value != "two" || len(fetched) != 1 {
+		t.Errorf("reloading 2 gave %q after %d fetches, want the remembered value", value, len(fetched))
+	}
+}
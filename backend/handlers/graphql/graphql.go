@@ -0,0 +1,58 @@
+// Package graphqlhandlers serves read-only GraphQL queries over markets,
+// agents, predictions and proposals, so clients can fetch nested data such
+// as a market's predictions and their agents' scores in one request. The
+// schema is schema.graphql.
+//
+// The package uses graph-gophers/graphql-go rather than gqlgen. Its
+// resolvers are plain Go types checked against schema.graphql when the
+// server starts, so there is no generated code to keep in step with the
+// schema, and the API is read-only and small enough not to need it.
+package graphqlhandlers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"socialpredict/response"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// maxQueryDepth bounds how deeply a query may nest, so a single request
+// cannot walk market → predictions → agent → predictions → ... without end.
+const maxQueryDepth = 8
+
+// Request is a GraphQL request body.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// GraphQLHandler handles POST /v0/graphql
+// Runs a query against schema.graphql and responds with the standard
+// GraphQL {"data": ..., "errors": [...]} body. Errors resolving a field are
+// reported in errors with a 200, as GraphQL clients expect; a body that is
+// not a GraphQL request is a 400. Nested fields are loaded in batches per
+// request, and invite-only markets are only visible to the agents they
+// invite.
+func GraphQLHandler(db *gorm.DB) http.HandlerFunc {
+	schema := graphql.MustParseSchema(schemaSource, &resolver{db: db}, graphql.MaxDepth(maxQueryDepth))
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Body must be a GraphQL request with a query")
+			return
+		}
+
+		result := schema.Exec(withLoaders(r.Context(), db), req.Query, req.OperationName, req.Variables)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
@@ -0,0 +1,146 @@
+package graphqlhandlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func query(t *testing.T, handler http.HandlerFunc, body string) map[string]interface{} {
+	t.Helper()
+	encoded, _ := json.Marshal(Request{Query: body})
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/v0/graphql", strings.NewReader(string(encoded))))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	var result struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []interface{}          `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("errors: %v", result.Errors)
+	}
+	return result.Data
+}
+
+// countQueries counts the queries db runs against table.
+func countQueries(t *testing.T, db *gorm.DB, table string) *int64 {
+	t.Helper()
+	var count int64
+	err := db.Callback().Query().After("gorm:query").Register("test:count_"+table, func(tx *gorm.DB) {
+		if tx.Statement.Table == table {
+			atomic.AddInt64(&count, 1)
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	return &count
+}
+
+func TestGraphQLHandler_BatchesNestedAgents(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	var agents []models.Agent
+	for i := 0; i < 3; i++ {
+		agent := modelstesting.GenerateAgent(fmt.Sprintf("agent-%d", i))
+		agent.CompositeScore = float64(10 * (i + 1))
+		if err := db.Create(&agent).Error; err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+		agents = append(agents, agent)
+	}
+	for id := int64(1); id <= 2; id++ {
+		market := modelstesting.GenerateMarket(id, "creator")
+		market.Visibility = models.MarketPublic
+		market.CreatedAt = time.Now().Add(time.Duration(id) * time.Minute)
+		if err := db.Create(&market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+		for _, agent := range agents {
+			prediction := models.Prediction{AgentID: agent.ID, MarketID: id, Outcome: "YES", Confidence: 70, Revision: 1, PredictedAt: time.Now()}
+			if err := db.Create(&prediction).Error; err != nil {
+				t.Fatalf("create prediction: %v", err)
+			}
+		}
+	}
+	handler := GraphQLHandler(db)
+	agentQueries := countQueries(t, db, "agents")
+
+	data := query(t, handler, `{
+		markets(limit: 10) {
+			id
+			consensus { probability }
+			predictions(limit: 2) { confidence agent { name scores { composite } } }
+		}
+	}`)
+
+	markets := data["markets"].([]interface{})
+	if len(markets) != 2 {
+		t.Fatalf("got %d markets, want 2", len(markets))
+	}
+	newest := markets[0].(map[string]interface{})
+	if newest["id"] != "2" || newest["consensus"] != nil {
+		t.Fatalf("expected the newest market first, without a recorded consensus, got %v", newest)
+	}
+	if predictions := newest["predictions"].([]interface{}); len(predictions) != 2 {
+		t.Fatalf("got %d predictions, want the limit of 2", len(predictions))
+	}
+	if n := atomic.LoadInt64(agentQueries); n != 1 {
+		t.Fatalf("loaded agents in %d queries, want them batched in 1", n)
+	}
+}
+
+func TestGraphQLHandler_HidesInviteOnlyMarkets(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	market := modelstesting.GenerateMarket(1, "creator")
+	agent := modelstesting.GenerateAgent("insider")
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	market.Visibility = models.MarketInviteOnly
+	market.CreatorAgentID = &agent.ID
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	prediction := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: "NO", Confidence: 60, Revision: 1, PredictedAt: time.Now()}
+	if err := db.Create(&prediction).Error; err != nil {
+		t.Fatalf("create prediction: %v", err)
+	}
+
+	data := query(t, GraphQLHandler(db), fmt.Sprintf(`{
+		market(id: "%d") { id }
+		prediction(id: "%d") { id }
+		agent(id: "%d") { name predictions { id } }
+	}`, market.ID, prediction.ID, agent.ID))
+	if data["market"] != nil || data["prediction"] != nil {
+		t.Fatalf("expected the invite-only market and its prediction to be hidden, got %v", data)
+	}
+	if agent := data["agent"].(map[string]interface{}); agent["name"] != "insider" || len(agent["predictions"].([]interface{})) != 0 {
+		t.Fatalf("expected the agent without its prediction on the invite-only market, got %v", agent)
+	}
+
+	// The agent itself sees them.
+	encoded, _ := json.Marshal(Request{Query: fmt.Sprintf(`{ market(id: "%d") { id } }`, market.ID)})
+	req := httptest.NewRequest(http.MethodPost, "/v0/graphql", strings.NewReader(string(encoded)))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{Tier: middleware.TierAgent, Agent: &agent}))
+	rec := httptest.NewRecorder()
+	GraphQLHandler(db)(rec, req)
+	if !strings.Contains(rec.Body.String(), fmt.Sprintf(`"market":{"id":"%d"}`, market.ID)) {
+		t.Fatalf("expected the creator to see its market, got %s", rec.Body.String())
+	}
+}
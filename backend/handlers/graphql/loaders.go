@@ -0,0 +1,135 @@
+package graphqlhandlers
+
+import (
+	"context"
+	"fmt"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/services/consensus"
+
+	"gorm.io/gorm"
+)
+
+// maxNestedPredictions caps the predictions fetched per market or agent;
+// their predictions fields take a limit up to it.
+const maxNestedPredictions = 100
+
+// loaders batch the lookups of one request's nested fields: the loads made
+// while resolving one level of a query are fetched together. They live
+// only as long as the request, so nothing is cached across requests.
+type loaders struct {
+	agents            *batcher[*models.Agent]          // nil if not found
+	markets           *batcher[*models.Market]         // nil if not found or not open to the caller
+	consensus         *batcher[*models.ConsensusPoint] // nil without predictions
+	marketPredictions *batcher[[]models.Prediction]    // most upvoted first
+	agentPredictions  *batcher[[]models.Prediction]    // newest first
+}
+
+type loadersKey struct{}
+
+func newLoaders(db *gorm.DB, agentID int64) *loaders {
+	return &loaders{
+		agents:            newBatcher(batchAgents(db)),
+		markets:           newBatcher(batchMarkets(db, agentID)),
+		consensus:         newBatcher(batchConsensus(db)),
+		marketPredictions: newBatcher(batchPredictions(db, agentID, "market_id", "upvotes DESC, predicted_at DESC, id")),
+		agentPredictions:  newBatcher(batchPredictions(db, agentID, "agent_id", "predicted_at DESC, id DESC")),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func batchAgents(db *gorm.DB) batchFunc[*models.Agent] {
+	return func(ctx context.Context, ids []int64) (map[int64]*models.Agent, error) {
+		var agents []models.Agent
+		if err := db.WithContext(ctx).Where("id IN ?", ids).Find(&agents).Error; err != nil {
+			return nil, err
+		}
+		byID := make(map[int64]*models.Agent, len(agents))
+		for i := range agents {
+			byID[agents[i].ID] = &agents[i]
+		}
+		return byID, nil
+	}
+}
+
+// batchMarkets loads markets, leaving out hidden ones and invite-only ones
+// the agent agentID, 0 for any other caller, is not invited to.
+func batchMarkets(db *gorm.DB, agentID int64) batchFunc[*models.Market] {
+	return func(ctx context.Context, ids []int64) (map[int64]*models.Market, error) {
+		db := db.WithContext(ctx)
+		var markets []models.Market
+		err := models.WithoutHidden(db.Model(&models.Market{}), models.ContentMarket).
+			Where("markets.id IN ?", ids).Find(&markets).Error
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int64]*models.Market, len(markets))
+		for i := range markets {
+			open, err := markets[i].OpenTo(db, agentID)
+			if err != nil {
+				return nil, err
+			}
+			if open {
+				byID[markets[i].ID] = &markets[i]
+			}
+		}
+		return byID, nil
+	}
+}
+
+func batchConsensus(db *gorm.DB) batchFunc[*models.ConsensusPoint] {
+	return func(ctx context.Context, ids []int64) (map[int64]*models.ConsensusPoint, error) {
+		latest, err := consensus.Latest(db.WithContext(ctx), ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int64]*models.ConsensusPoint, len(latest))
+		for id := range latest {
+			point := latest[id]
+			byID[id] = &point
+		}
+		return byID, nil
+	}
+}
+
+// batchPredictions loads up to maxNestedPredictions visible predictions
+// per value of column, in order, with one windowed query. Predictions on
+// invite-only markets the agent agentID is not invited to are left out.
+func batchPredictions(db *gorm.DB, agentID int64, column, order string) batchFunc[[]models.Prediction] {
+	return func(ctx context.Context, ids []int64) (map[int64][]models.Prediction, error) {
+		db := db.WithContext(ctx)
+		invited := db.Model(&models.MarketInvitation{}).Select("market_id").Where("agent_id = ?", agentID)
+		closed := db.Model(&models.Market{}).Select("id").
+			Where("visibility = ? AND (creator_agent_id IS NULL OR creator_agent_id <> ?) AND id NOT IN (?)", models.MarketInviteOnly, agentID, invited)
+		ranked := models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+			Select(fmt.Sprintf("predictions.*, ROW_NUMBER() OVER (PARTITION BY predictions.%s ORDER BY %s) AS row_rank", column, order)).
+			Where("predictions."+column+" IN ?", ids).
+			Where("predictions.market_id NOT IN (?)", closed)
+		var predictions []models.Prediction
+		err := db.Unscoped().Table("(?) AS ranked", ranked).Where("row_rank <= ?", maxNestedPredictions).
+			Order(order).Find(&predictions).Error
+		if err != nil {
+			return nil, err
+		}
+
+		byID := make(map[int64][]models.Prediction, len(ids))
+		for _, p := range predictions {
+			owner := p.MarketID
+			if column == "agent_id" {
+				owner = p.AgentID
+			}
+			byID[owner] = append(byID[owner], p)
+		}
+		return byID, nil
+	}
+}
+
+// withLoaders returns ctx carrying fresh loaders for the caller of ctx.
+func withLoaders(ctx context.Context, db *gorm.DB) context.Context {
+	agentID := middleware.PrincipalFromContext(ctx).AgentID()
+	return context.WithValue(ctx, loadersKey{}, newLoaders(db, agentID))
+}
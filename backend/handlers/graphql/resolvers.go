@@ -0,0 +1,342 @@
+package graphqlhandlers
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+
+	"socialpredict/models"
+	"socialpredict/services/marketlisting"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+var (
+	errInvalidID = stderrors.New("invalid ID")
+	errDatabase  = stderrors.New("database error")
+)
+
+// maxListLimit caps the top-level lists.
+const maxListLimit = 100
+
+// clamp keeps a limit argument between 1 and max.
+func clamp(limit int32, max int) int {
+	if limit < 1 {
+		return 1
+	}
+	if int(limit) > max {
+		return max
+	}
+	return int(limit)
+}
+
+func parseID(id graphql.ID) (int64, error) {
+	parsed, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil || parsed <= 0 {
+		return 0, errInvalidID
+	}
+	return parsed, nil
+}
+
+func toID(id int64) graphql.ID { return graphql.ID(strconv.FormatInt(id, 10)) }
+
+type resolver struct {
+	db *gorm.DB
+}
+
+type idArgs struct {
+	ID graphql.ID
+}
+
+type pageArgs struct {
+	Limit  int32
+	Offset int32
+}
+
+func (r *resolver) Market(ctx context.Context, args idArgs) (*marketResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return loadMarket(ctx, id)
+}
+
+func (r *resolver) Markets(ctx context.Context, args struct {
+	Status   string
+	Category *string
+	pageArgs
+}) ([]*marketResolver, error) {
+	filter := marketlisting.ActiveFilter
+	switch args.Status {
+	case "CLOSED":
+		filter = marketlisting.ClosedFilter
+	case "RESOLVED":
+		filter = marketlisting.ResolvedFilter
+	}
+	query := models.WithoutHidden(models.Listed(filter(r.db.WithContext(ctx).Model(&models.Market{}))), models.ContentMarket)
+	if args.Category != nil {
+		query = query.Where("markets.category = ?", *args.Category)
+	}
+	var markets []models.Market
+	err := query.Order("markets.created_at DESC, markets.id DESC").
+		Limit(clamp(args.Limit, maxListLimit)).Offset(int(args.Offset)).Find(&markets).Error
+	if err != nil {
+		return nil, errDatabase
+	}
+	out := make([]*marketResolver, len(markets))
+	for i := range markets {
+		out[i] = &marketResolver{&markets[i]}
+	}
+	return out, nil
+}
+
+func (r *resolver) Agent(ctx context.Context, args idArgs) (*agentResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return loadAgent(ctx, id)
+}
+
+func (r *resolver) AgentByName(ctx context.Context, args struct{ Name string }) (*agentResolver, error) {
+	var agent models.Agent
+	if err := r.db.WithContext(ctx).Where("name = ?", args.Name).First(&agent).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errDatabase
+	}
+	return &agentResolver{&agent}, nil
+}
+
+func (r *resolver) Agents(ctx context.Context, args pageArgs) ([]*agentResolver, error) {
+	var agents []models.Agent
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Order("composite_score DESC, id").
+		Limit(clamp(args.Limit, maxListLimit)).Offset(int(args.Offset)).Find(&agents).Error
+	if err != nil {
+		return nil, errDatabase
+	}
+	out := make([]*agentResolver, len(agents))
+	for i := range agents {
+		out[i] = &agentResolver{&agents[i]}
+	}
+	return out, nil
+}
+
+// Prediction returns a visible prediction on a market open to the caller.
+func (r *resolver) Prediction(ctx context.Context, args idArgs) (*predictionResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	var prediction models.Prediction
+	err = models.WithoutHidden(r.db.WithContext(ctx).Model(&models.Prediction{}), models.ContentPrediction).
+		First(&prediction, id).Error
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errDatabase
+	}
+	if market, err := loadMarket(ctx, prediction.MarketID); market == nil {
+		return nil, err
+	}
+	return &predictionResolver{&prediction}, nil
+}
+
+func (r *resolver) Proposal(ctx context.Context, args idArgs) (*proposalResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	var proposal models.Proposal
+	if err := r.db.WithContext(ctx).First(&proposal, id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errDatabase
+	}
+	return &proposalResolver{&proposal}, nil
+}
+
+func (r *resolver) Proposals(ctx context.Context, args struct {
+	Status *string
+	pageArgs
+}) ([]*proposalResolver, error) {
+	query := r.db.WithContext(ctx).Model(&models.Proposal{})
+	if args.Status != nil {
+		query = query.Where("status = ?", *args.Status)
+	}
+	var proposals []models.Proposal
+	err := query.Order("created_at DESC, id DESC").
+		Limit(clamp(args.Limit, maxListLimit)).Offset(int(args.Offset)).Find(&proposals).Error
+	if err != nil {
+		return nil, errDatabase
+	}
+	out := make([]*proposalResolver, len(proposals))
+	for i := range proposals {
+		out[i] = &proposalResolver{&proposals[i]}
+	}
+	return out, nil
+}
+
+func loadMarket(ctx context.Context, id int64) (*marketResolver, error) {
+	market, err := loadersFrom(ctx).markets.load(ctx, id)
+	if err != nil {
+		return nil, errDatabase
+	}
+	if market != nil {
+		return &marketResolver{market}, nil
+	}
+	return nil, nil
+}
+
+func loadAgent(ctx context.Context, id int64) (*agentResolver, error) {
+	agent, err := loadersFrom(ctx).agents.load(ctx, id)
+	if err != nil {
+		return nil, errDatabase
+	}
+	if agent != nil {
+		return &agentResolver{agent}, nil
+	}
+	return nil, nil
+}
+
+// loadPredictions returns up to limit of the predictions loader has for id.
+func loadPredictions(ctx context.Context, loader *batcher[[]models.Prediction], id int64, limit int32) ([]*predictionResolver, error) {
+	predictions, err := loader.load(ctx, id)
+	if err != nil {
+		return nil, errDatabase
+	}
+	if n := clamp(limit, maxNestedPredictions); len(predictions) > n {
+		predictions = predictions[:n]
+	}
+	out := make([]*predictionResolver, len(predictions))
+	for i := range predictions {
+		out[i] = &predictionResolver{&predictions[i]}
+	}
+	return out, nil
+}
+
+type marketResolver struct{ m *models.Market }
+
+func (r *marketResolver) ID() graphql.ID          { return toID(r.m.ID) }
+func (r *marketResolver) QuestionTitle() string   { return r.m.QuestionTitle }
+func (r *marketResolver) Description() string     { return r.m.Description }
+func (r *marketResolver) OutcomeType() string     { return r.m.OutcomeType }
+func (r *marketResolver) MarketType() string      { return r.m.MarketType }
+func (r *marketResolver) Category() string        { return r.m.Category }
+func (r *marketResolver) Visibility() string      { return r.m.Visibility }
+func (r *marketResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.m.CreatedAt} }
+func (r *marketResolver) ResolutionDateTime() graphql.Time {
+	return graphql.Time{Time: r.m.ResolutionDateTime}
+}
+func (r *marketResolver) IsResolved() bool         { return r.m.IsResolved }
+func (r *marketResolver) ResolutionResult() string { return r.m.ResolutionResult }
+func (r *marketResolver) TotalPredictions() int32  { return int32(r.m.TotalPredictions) }
+func (r *marketResolver) ScalarMin() *float64      { return r.m.ScalarMin }
+func (r *marketResolver) ScalarMax() *float64      { return r.m.ScalarMax }
+func (r *marketResolver) ScalarUnit() string       { return r.m.ScalarUnit }
+
+func (r *marketResolver) Consensus(ctx context.Context) (*consensusResolver, error) {
+	point, err := loadersFrom(ctx).consensus.load(ctx, r.m.ID)
+	if err != nil {
+		return nil, errDatabase
+	}
+	if point != nil {
+		return &consensusResolver{point}, nil
+	}
+	return nil, nil
+}
+
+func (r *marketResolver) CreatorAgent(ctx context.Context) (*agentResolver, error) {
+	if r.m.CreatorAgentID == nil {
+		return nil, nil
+	}
+	return loadAgent(ctx, *r.m.CreatorAgentID)
+}
+
+func (r *marketResolver) Predictions(ctx context.Context, args struct{ Limit int32 }) ([]*predictionResolver, error) {
+	return loadPredictions(ctx, loadersFrom(ctx).marketPredictions, r.m.ID, args.Limit)
+}
+
+type consensusResolver struct{ p *models.ConsensusPoint }
+
+func (r *consensusResolver) Probability() float64     { return r.p.Probability }
+func (r *consensusResolver) Value() *float64          { return r.p.Value }
+func (r *consensusResolver) Predictions() int32       { return int32(r.p.Predictions) }
+func (r *consensusResolver) RecordedAt() graphql.Time { return graphql.Time{Time: r.p.RecordedAt} }
+
+type agentResolver struct{ a *models.Agent }
+
+func (r *agentResolver) ID() graphql.ID               { return toID(r.a.ID) }
+func (r *agentResolver) Name() string                 { return r.a.Name }
+func (r *agentResolver) Description() string          { return r.a.Description }
+func (r *agentResolver) Scores() *agentScoresResolver { return &agentScoresResolver{r.a} }
+func (r *agentResolver) TotalPredictions() int32      { return int32(r.a.TotalPredictions) }
+func (r *agentResolver) CorrectPredictions() int32    { return int32(r.a.CorrectPredictions) }
+func (r *agentResolver) TotalFollowers() int32        { return int32(r.a.TotalFollowers) }
+func (r *agentResolver) MarketsCreated() int32        { return int32(r.a.MarketsCreated) }
+func (r *agentResolver) CurrentStreak() int32         { return int32(r.a.CurrentStreak) }
+func (r *agentResolver) IsClaimed() bool              { return r.a.IsClaimed }
+func (r *agentResolver) IsActive() bool               { return r.a.IsActive }
+func (r *agentResolver) AvatarUrl() string            { return r.a.AvatarURL }
+func (r *agentResolver) FrameworkType() string        { return r.a.FrameworkType }
+
+func (r *agentResolver) Predictions(ctx context.Context, args struct{ Limit int32 }) ([]*predictionResolver, error) {
+	return loadPredictions(ctx, loadersFrom(ctx).agentPredictions, r.a.ID, args.Limit)
+}
+
+type agentScoresResolver struct{ a *models.Agent }
+
+func (r *agentScoresResolver) Accuracy() float64   { return r.a.AccuracyScore }
+func (r *agentScoresResolver) Engagement() float64 { return r.a.EngagementScore }
+func (r *agentScoresResolver) Creator() float64    { return r.a.CreatorScore }
+func (r *agentScoresResolver) Activity() float64   { return r.a.ActivityScore }
+func (r *agentScoresResolver) Composite() float64  { return r.a.CompositeScore }
+
+type predictionResolver struct{ p *models.Prediction }
+
+func (r *predictionResolver) ID() graphql.ID            { return toID(r.p.ID) }
+func (r *predictionResolver) Outcome() string           { return r.p.Outcome }
+func (r *predictionResolver) Confidence() float64       { return r.p.Confidence }
+func (r *predictionResolver) Estimate() *float64        { return r.p.Estimate }
+func (r *predictionResolver) Low() *float64             { return r.p.Low }
+func (r *predictionResolver) High() *float64            { return r.p.High }
+func (r *predictionResolver) Reasoning() string         { return r.p.Reasoning }
+func (r *predictionResolver) Revision() int32           { return int32(r.p.Revision) }
+func (r *predictionResolver) IsResolved() bool          { return r.p.IsResolved }
+func (r *predictionResolver) WasCorrect() bool          { return r.p.WasCorrect }
+func (r *predictionResolver) Upvotes() int32            { return int32(r.p.Upvotes) }
+func (r *predictionResolver) Downvotes() int32          { return int32(r.p.Downvotes) }
+func (r *predictionResolver) PredictedAt() graphql.Time { return graphql.Time{Time: r.p.PredictedAt} }
+
+func (r *predictionResolver) Agent(ctx context.Context) (*agentResolver, error) {
+	return loadAgent(ctx, r.p.AgentID)
+}
+
+func (r *predictionResolver) Market(ctx context.Context) (*marketResolver, error) {
+	return loadMarket(ctx, r.p.MarketID)
+}
+
+type proposalResolver struct{ p *models.Proposal }
+
+func (r *proposalResolver) ID() graphql.ID             { return toID(r.p.ID) }
+func (r *proposalResolver) Title() string              { return r.p.Title }
+func (r *proposalResolver) Description() string        { return r.p.Description }
+func (r *proposalResolver) Type() string               { return string(r.p.Type) }
+func (r *proposalResolver) Status() string             { return string(r.p.Status) }
+func (r *proposalResolver) Priority() string           { return r.p.Priority }
+func (r *proposalResolver) Complexity() string         { return r.p.Complexity }
+func (r *proposalResolver) VotesFor() int32            { return int32(r.p.VotesFor) }
+func (r *proposalResolver) VotesAgainst() int32        { return int32(r.p.VotesAgainst) }
+func (r *proposalResolver) WeightFor() float64         { return r.p.WeightFor }
+func (r *proposalResolver) WeightAgainst() float64     { return r.p.WeightAgainst }
+func (r *proposalResolver) VotingMode() string         { return r.p.VotingMode }
+func (r *proposalResolver) VotingEndsAt() graphql.Time { return graphql.Time{Time: r.p.VotingEndsAt} }
+func (r *proposalResolver) CreatedAt() graphql.Time    { return graphql.Time{Time: r.p.CreatedAt} }
+
+func (r *proposalResolver) Proposer(ctx context.Context) (*agentResolver, error) {
+	return loadAgent(ctx, r.p.ProposerAgentID)
+}
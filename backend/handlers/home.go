@@ -3,11 +3,13 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+
+	"socialpredict/response"
 )
 
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method is not supported.", http.StatusNotFound)
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Method is not supported.")
 		return
 	}
 
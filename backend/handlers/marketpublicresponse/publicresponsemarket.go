@@ -23,6 +23,16 @@ type PublicResponseMarket struct {
 	CreatedAt               time.Time `json:"createdAt"`
 	YesLabel                string    `json:"yesLabel"`
 	NoLabel                 string    `json:"noLabel"`
+	Category                string    `json:"category"`
+	Tags                    []string  `json:"tags"`
+	// Set for markets created from an approved council submission
+	SourceSubmissionID *int64                   `json:"sourceSubmissionId,omitempty"`
+	Provenance         *models.MarketProvenance `json:"provenance,omitempty"`
+	// How the market resolves, if it was created with structured criteria
+	ResolutionCriteria *models.ResolutionCriteria `json:"resolutionCriteria,omitempty"`
+	// When the market stops taking and revising predictions
+	PredictionLockHours float64   `json:"predictionLockHours"`
+	PredictionsLockAt   time.Time `json:"predictionsLockAt"`
 }
 
 // GetPublicResponseMarketByID retrieves a market by its ID using an existing database connection,
@@ -56,6 +66,19 @@ func GetPublicResponseMarketByID(db *gorm.DB, marketId string) (PublicResponseMa
 		CreatedAt:               market.CreatedAt,
 		YesLabel:                market.YesLabel,
 		NoLabel:                 market.NoLabel,
+		Category:                market.Category,
+		SourceSubmissionID:      market.SourceSubmissionID,
+		ResolutionCriteria:      market.ResolutionCriteria(),
+		PredictionLockHours:     market.PredictionLockHours,
+		PredictionsLockAt:       market.PredictionsLockAt(),
+	}
+	tags, err := models.TagsForMarket(db, market.ID)
+	if err != nil {
+		return PublicResponseMarket{}, err
+	}
+	responseMarket.Tags = tags
+	if provenance, err := market.DecodeProvenance(); err == nil {
+		responseMarket.Provenance = provenance
 	}
 
 	return responseMarket, nil
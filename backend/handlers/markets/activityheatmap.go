@@ -0,0 +1,117 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/response"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ActivityGrid counts events by day of week (0 is Sunday) and hour of day.
+type ActivityGrid [7][24]int64
+
+func (g *ActivityGrid) add(times []time.Time, loc *time.Location) {
+	for _, t := range times {
+		local := t.In(loc)
+		g[local.Weekday()][local.Hour()]++
+	}
+}
+
+// ActivityPeak is the busiest hour of the week.
+type ActivityPeak struct {
+	Day   string `json:"day"`
+	Hour  int    `json:"hour"`
+	Count int64  `json:"count"`
+}
+
+// ActivityHeatmapHandler handles GET /v0/markets/{id}/activity-heatmap
+// Buckets the market's predictions, and the votes and comments on them, by
+// day of week and hour of day so operators and creators can see when the
+// swarm is most active. Optional ?tz= is an IANA time zone (default UTC) for
+// the buckets.
+func ActivityHeatmapHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+
+		loc := time.UTC
+		if tz := r.URL.Query().Get("tz"); tz != "" {
+			if loc, err = time.LoadLocation(tz); err != nil {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid time zone")
+				return
+			}
+		}
+
+		var market models.Market
+		if err := db.Select("id", "visibility", "creator_agent_id").First(&market, marketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if !marketOpen(w, r, db, market) {
+			return
+		}
+
+		var predictionTimes, voteTimes, commentTimes []time.Time
+		if err := db.Model(&models.Prediction{}).Where("market_id = ?", marketID).
+			Pluck("predicted_at", &predictionTimes).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch predictions")
+			return
+		}
+		if err := db.Model(&models.PredictionVote{}).
+			Joins("JOIN predictions ON predictions.id = prediction_votes.prediction_id").
+			Where("predictions.market_id = ?", marketID).
+			Pluck("prediction_votes.created_at", &voteTimes).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch votes")
+			return
+		}
+		if err := db.Model(&models.PredictionComment{}).
+			Joins("JOIN predictions ON predictions.id = prediction_comments.prediction_id").
+			Where("predictions.market_id = ?", marketID).
+			Pluck("prediction_comments.created_at", &commentTimes).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch comments")
+			return
+		}
+
+		var predictions, engagement ActivityGrid
+		predictions.add(predictionTimes, loc)
+		engagement.add(voteTimes, loc)
+		engagement.add(commentTimes, loc)
+
+		var peak *ActivityPeak
+		for day := range predictions {
+			for hour := range predictions[day] {
+				count := predictions[day][hour] + engagement[day][hour]
+				if count > 0 && (peak == nil || count > peak.Count) {
+					peak = &ActivityPeak{Day: time.Weekday(day).String(), Hour: hour, Count: count}
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":          true,
+			"marketId":         marketID,
+			"timezone":         loc.String(),
+			"days":             []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+			"predictions":      predictions,
+			"engagement":       engagement,
+			"totalPredictions": len(predictionTimes),
+			"totalEngagement":  len(voteTimes) + len(commentTimes),
+			"peak":             peak,
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+func TestActivityHeatmapHandler_BucketsByDayAndHour(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	creator := modelstesting.GenerateUser("creator", 0)
+	db.Create(&creator)
+	market := modelstesting.GenerateMarket(1, "creator")
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	agent := models.Agent{Name: "early", APIKey: "swarm_sk_early", ClaimToken: "claim_early", IsActive: true}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	monday := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	var first models.Prediction
+	for i, at := range []time.Time{monday, monday.Add(10 * time.Minute), monday.Add(24 * time.Hour)} {
+		p := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: "YES", Confidence: 60, PredictedAt: at}
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("create prediction: %v", err)
+		}
+		if i == 0 {
+			first = p
+		}
+	}
+	vote := models.PredictionVote{Model: gorm.Model{CreatedAt: monday.Add(20 * time.Minute)}, PredictionID: first.ID, VoterID: 1, VoterType: "user", VoteType: "up"}
+	comment := models.PredictionComment{Model: gorm.Model{CreatedAt: monday.Add(9 * time.Hour)}, PredictionID: first.ID, AuthorID: 1, AuthorType: "user", Content: "late reply"}
+	if err := db.Create(&vote).Error; err != nil {
+		t.Fatalf("create vote: %v", err)
+	}
+	if err := db.Create(&comment).Error; err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/markets/"+strconv.FormatInt(market.ID, 10)+"/activity-heatmap"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": strconv.FormatInt(market.ID, 10)})
+		rec := httptest.NewRecorder()
+		ActivityHeatmapHandler(db)(rec, req)
+		return rec
+	}
+
+	rec := get("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Predictions      ActivityGrid  `json:"predictions"`
+		Engagement       ActivityGrid  `json:"engagement"`
+		TotalPredictions int           `json:"totalPredictions"`
+		TotalEngagement  int           `json:"totalEngagement"`
+		Peak             *ActivityPeak `json:"peak"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if resp.Predictions[time.Monday][14] != 2 || resp.Predictions[time.Tuesday][14] != 1 || resp.TotalPredictions != 3 {
+		t.Fatalf("unexpected prediction buckets: Monday 14h %d, Tuesday 14h %d, total %d",
+			resp.Predictions[time.Monday][14], resp.Predictions[time.Tuesday][14], resp.TotalPredictions)
+	}
+	if resp.Engagement[time.Monday][14] != 1 || resp.Engagement[time.Monday][23] != 1 || resp.TotalEngagement != 2 {
+		t.Fatalf("unexpected engagement buckets: %v", resp.Engagement[time.Monday])
+	}
+	if resp.Peak == nil || resp.Peak.Day != "Monday" || resp.Peak.Hour != 14 || resp.Peak.Count != 3 {
+		t.Fatalf("expected Monday 14h with 3 events as the peak, got %+v", resp.Peak)
+	}
+
+	if rec := get("?tz=Not/AZone"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown time zone, got %d", rec.Code)
+	}
+}
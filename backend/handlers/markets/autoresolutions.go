@@ -0,0 +1,61 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/autoresolve"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// AutoResolutionsHandler handles GET /v0/markets/{id}/auto-resolutions
+// Returns the market's oracle spec, if it resolves automatically, and every
+// attempt to resolve it from the oracle, newest first, with the data each
+// attempt saw.
+func AutoResolutionsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+
+		var market models.Market
+		if err := db.First(&market, marketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if !marketOpen(w, r, db, market) {
+			return
+		}
+
+		var spec *models.OracleSpec
+		if market.AutoResolve {
+			spec, _ = market.OracleSpec()
+		}
+		attempts, err := autoresolve.History(db, marketID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch auto-resolutions")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"marketId":    marketID,
+			"autoResolve": market.AutoResolve,
+			"oracle":      spec,
+			"attempts":    attempts,
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+// CategoryCount is how many markets a category holds.
+type CategoryCount struct {
+	Category string `json:"category"`
+	Markets  int64  `json:"markets"`
+	Active   int64  `json:"active"`   // unresolved and still open
+	Resolved int64  `json:"resolved"` // resolved
+}
+
+// CategoriesHandler handles GET /v0/categories
+// Lists every category that has markets, largest first, with counts of its
+// markets overall, still open and resolved. Use a category with
+// GET /v0/markets?category= to browse it.
+func CategoriesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		categories, err := CountCategories(db, time.Now())
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to count categories")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"categories": categories,
+			"count":      len(categories),
+		})
+	}
+}
+
+// CountCategories returns the counts of listed markets in every category as
+// of now.
+func CountCategories(db *gorm.DB, now time.Time) ([]CategoryCount, error) {
+	categories := []CategoryCount{}
+	err := models.Listed(db.Model(&models.Market{})).
+		Select(`category,
+			COUNT(*) AS markets,
+			SUM(CASE WHEN is_resolved = ? AND resolution_date_time > ? THEN 1 ELSE 0 END) AS active,
+			SUM(CASE WHEN is_resolved = ? THEN 1 ELSE 0 END) AS resolved`, false, now, true).
+		Group("category").
+		Order("markets DESC, category").
+		Scan(&categories).Error
+	return categories, err
+}
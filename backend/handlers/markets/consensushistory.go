@@ -0,0 +1,71 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/consensus"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ConsensusHistoryHandler handles GET /v0/markets/{id}/consensus/history
+// Returns how the swarm consensus on the market moved over time, in hourly
+// buckets or daily ones with ?interval=day, oldest first. Each bucket has
+// the consensus at its end, its range and how many predictions moved it.
+// Resolved markets include the outcome the history led up to.
+func ConsensusHistoryHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+		interval := r.URL.Query().Get("interval")
+		if interval == "" {
+			interval = consensus.IntervalHour
+		}
+
+		var market models.Market
+		if err := db.First(&market, marketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if !marketOpen(w, r, db, market) {
+			return
+		}
+
+		buckets, err := consensus.History(db, marketID, interval)
+		if errors.Is(err, consensus.ErrInvalidInterval) {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch consensus history")
+			return
+		}
+
+		response := map[string]interface{}{
+			"success":            true,
+			"marketId":           marketID,
+			"interval":           interval,
+			"points":             buckets,
+			"resolutionDateTime": market.ResolutionDateTime,
+			"isResolved":         market.IsResolved,
+		}
+		if market.IsResolved {
+			response["resolutionResult"] = market.ResolutionResult
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,117 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/correlation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// CorrelatedMarket is another open market whose consensus moves with (or
+// against) the requested market's.
+type CorrelatedMarket struct {
+	MarketID      int64    `json:"marketId"`
+	QuestionTitle string   `json:"questionTitle"`
+	Coefficient   float64  `json:"coefficient"` // Pearson, -1 to 1
+	Samples       int      `json:"samples"`
+	Consensus     *float64 `json:"consensus,omitempty"`    // latest chance of YES
+	ConsensusGap  *float64 `json:"consensusGap,omitempty"` // Consensus minus the requested market's
+}
+
+// CorrelatedMarketsHandler handles GET /v0/markets/{id}/correlated
+// Lists the open markets whose swarm consensus is most strongly correlated
+// with this market's, so agents can spot related markets the swarm is
+// pricing inconsistently. Optional ?limit= (default 10, max 50).
+func CorrelatedMarketsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+
+		limit := 10
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 50 {
+				limit = parsed
+			}
+		}
+
+		var market models.Market
+		if err := db.First(&market, marketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if !marketOpen(w, r, db, market) {
+			return
+		}
+
+		correlations, err := correlation.Correlated(db, marketID, limit)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch correlations")
+			return
+		}
+
+		marketIDs := []int64{marketID}
+		for _, c := range correlations {
+			marketIDs = append(marketIDs, c.OtherMarketID)
+		}
+		latest, err := correlation.Latest(db, marketIDs)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch consensus")
+			return
+		}
+		var titles []models.Market
+		if err := db.Select("id", "question_title").Where("id IN ?", marketIDs).Find(&titles).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch markets")
+			return
+		}
+		titleByID := make(map[int64]string, len(titles))
+		for _, m := range titles {
+			titleByID[m.ID] = m.QuestionTitle
+		}
+
+		var consensus *float64
+		if snapshot, ok := latest[marketID]; ok {
+			consensus = &snapshot.Probability
+		}
+
+		correlated := make([]CorrelatedMarket, 0, len(correlations))
+		for _, c := range correlations {
+			entry := CorrelatedMarket{
+				MarketID:      c.OtherMarketID,
+				QuestionTitle: titleByID[c.OtherMarketID],
+				Coefficient:   c.Coefficient,
+				Samples:       c.Samples,
+			}
+			if snapshot, ok := latest[c.OtherMarketID]; ok {
+				other := snapshot.Probability
+				entry.Consensus = &other
+				if consensus != nil {
+					gap := other - *consensus
+					entry.ConsensusGap = &gap
+				}
+			}
+			correlated = append(correlated, entry)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"marketId":   marketID,
+			"consensus":  consensus,
+			"correlated": correlated,
+		})
+	}
+}
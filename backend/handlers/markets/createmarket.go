@@ -10,6 +10,7 @@ import (
 	"socialpredict/logging"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/security"
 	"socialpredict/setup"
 	"socialpredict/util"
@@ -69,7 +70,7 @@ func validateCustomLabels(yesLabel, noLabel string) error {
 func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 			return
 		}
 
@@ -80,7 +81,7 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 		db := util.GetDB()
 		user, httperr := middleware.ValidateUserAndEnforcePasswordChangeGetUser(r, db)
 		if httperr != nil {
-			http.Error(w, httperr.Error(), httperr.StatusCode)
+			response.Error(w, httperr.StatusCode, httperr.Code, httperr.Message)
 			return
 		}
 
@@ -92,7 +93,7 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 		if err != nil {
 			bodyBytes, _ := io.ReadAll(r.Body)
 			log.Printf("Error reading request body: %v, Body: %s", err, string(bodyBytes))
-			http.Error(w, "Error reading request body", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Error reading request body")
 			return
 		}
 
@@ -105,7 +106,7 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 
 		sanitizedMarketInput, err := securityService.ValidateAndSanitizeMarketInput(marketInput)
 		if err != nil {
-			http.Error(w, "Invalid market data: "+err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market data: "+err.Error())
 			return
 		}
 
@@ -115,18 +116,18 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 
 		// Additional legacy validations (kept for backwards compatibility)
 		if err = checkQuestionTitleLength(newMarket.QuestionTitle); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
 		if err = checkQuestionDescriptionLength(newMarket.Description); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
 		// Validate custom labels
 		if err = validateCustomLabels(newMarket.YesLabel, newMarket.NoLabel); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
@@ -138,11 +139,13 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 			newMarket.NoLabel = "NO"
 		}
 
+		newMarket.SetCreatedBy(models.UserActor(user.ID))
+
 		if err = util.CheckUserIsReal(db, newMarket.CreatorUsername); err != nil {
 			if err.Error() == "creator user not found" {
-				http.Error(w, err.Error(), http.StatusNotFound)
+				response.Error(w, http.StatusNotFound, response.CodeNotFound, err.Error())
 			} else {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, err.Error())
 			}
 			return
 		}
@@ -151,7 +154,7 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 
 		// Business logic validation: Check market resolution time
 		if err = validateMarketResolutionTime(newMarket.ResolutionDateTime, appConfig); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
@@ -161,7 +164,7 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 
 		// Maximum debt allowed check
 		if user.AccountBalance-marketCreateFee < -maximumDebtAllowed {
-			http.Error(w, "Insufficient balance", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeInsufficientFunds, "Insufficient balance")
 			return
 		}
 
@@ -173,7 +176,7 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 
 		// Update the user's balance in the database
 		if err := db.Save(&user).Error; err != nil {
-			http.Error(w, "Error updating user balance: "+err.Error(), http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error updating user balance: "+err.Error())
 			return
 		}
 
@@ -181,7 +184,7 @@ func CreateMarketHandler(loadEconConfig setup.EconConfigLoader) func(http.Respon
 		result := db.Create(&newMarket)
 		if result.Error != nil {
 			log.Printf("Error creating new market: %v", result.Error)
-			http.Error(w, "Error creating new market", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error creating new market")
 			return
 		}
 
@@ -0,0 +1,227 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	spErrors "socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/platformconfig"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MarketInvitationRequest is the request body for inviting agents to an
+// invite-only market
+type MarketInvitationRequest struct {
+	AgentIDs []int64 `json:"agentIds" validate:"required,min=1,max=100,dive,gt=0"`
+}
+
+// ListMarketInvitationsHandler handles GET /v0/markets/{id}/invitations
+// Lists the agents invited to the market, for its creator.
+func ListMarketInvitationsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		market, ok := invitationMarket(w, r, db)
+		if !ok {
+			return
+		}
+		writeInvitations(w, db, market.ID)
+	}
+}
+
+// InviteToMarketHandler handles POST /v0/markets/{id}/invitations
+// The creator of an invite-only market invites agents to see and predict on
+// it. Inviting an agent twice changes nothing.
+func InviteToMarketHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		market, ok := invitationMarket(w, r, db)
+		if !ok {
+			return
+		}
+		if market.Visibility != models.MarketInviteOnly {
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Only invite-only markets take invitations")
+			return
+		}
+
+		var req MarketInvitationRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			spErrors.WriteValidationError(w, fields)
+			return
+		}
+
+		var agentIDs []int64
+		if err := db.Model(&models.Agent{}).Where("id IN ? AND is_active = ?", req.AgentIDs, true).Pluck("id", &agentIDs).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		found := make(map[int64]bool, len(agentIDs))
+		for _, id := range agentIDs {
+			found[id] = true
+		}
+		for _, id := range req.AgentIDs {
+			if !found[id] {
+				response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, fmt.Sprintf("Agent %d not found", id))
+				return
+			}
+		}
+
+		limit := platformconfig.Current(db).PrivateMarkets.OrDefaults().MaxInvitations
+		err := db.Transaction(func(tx *gorm.DB) error {
+			invitations := make([]models.MarketInvitation, len(agentIDs))
+			for i, id := range agentIDs {
+				invitations[i] = models.MarketInvitation{MarketID: market.ID, AgentID: id}
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&invitations).Error; err != nil {
+				return err
+			}
+			var total int64
+			if err := tx.Model(&models.MarketInvitation{}).Where("market_id = ?", market.ID).Count(&total).Error; err != nil {
+				return err
+			}
+			if total > int64(limit) {
+				return errTooManyInvitations
+			}
+			return nil
+		})
+		if errors.Is(err, errTooManyInvitations) {
+			response.Error(w, http.StatusConflict, response.CodeConflict, fmt.Sprintf("A market can invite at most %d agents", limit))
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to invite agents")
+			return
+		}
+		writeInvitations(w, db, market.ID)
+	}
+}
+
+// RevokeMarketInvitationHandler handles DELETE /v0/markets/{id}/invitations/{agentId}
+// The creator withdraws an agent's invitation. Predictions the agent
+// already made stand.
+func RevokeMarketInvitationHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		market, ok := invitationMarket(w, r, db)
+		if !ok {
+			return
+		}
+		agentID, err := strconv.ParseInt(mux.Vars(r)["agentId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+			return
+		}
+
+		result := db.Where("market_id = ? AND agent_id = ?", market.ID, agentID).Delete(&models.MarketInvitation{})
+		if result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to revoke invitation")
+			return
+		}
+		if result.RowsAffected == 0 {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Agent is not invited to this market")
+			return
+		}
+		writeInvitations(w, db, market.ID)
+	}
+}
+
+var errTooManyInvitations = errors.New("too many invitations")
+
+// invitationMarket returns the market whose invitations the request
+// manages, having written the error response if the caller is not its
+// creator.
+func invitationMarket(w http.ResponseWriter, r *http.Request, db *gorm.DB) (*models.Market, bool) {
+	agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+	if httpErr != nil {
+		response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+		return nil, false
+	}
+	marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+		return nil, false
+	}
+
+	var market models.Market
+	if err := db.First(&market, marketID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+			return nil, false
+		}
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+		return nil, false
+	}
+	if market.CreatorAgentID == nil || *market.CreatorAgentID != agent.ID {
+		open, err := market.OpenTo(db, agent.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return nil, false
+		}
+		if !open {
+			response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+			return nil, false
+		}
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the market's creator can manage its invitations")
+		return nil, false
+	}
+	return &market, true
+}
+
+// marketOpen reports whether the caller may see the market's predictions
+// and consensus, having answered 404 if not, as if the market did not exist.
+func marketOpen(w http.ResponseWriter, r *http.Request, db *gorm.DB, market models.Market) bool {
+	open, err := market.OpenTo(db, middleware.PrincipalFromContext(r.Context()).AgentID())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+		return false
+	}
+	if !open {
+		response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+		return false
+	}
+	return true
+}
+
+// MarketOpenByID is marketOpen for handlers that hold only the market's ID.
+// It answers 400 for a malformed ID and 404 for a market that does not
+// exist or is closed to the caller.
+func MarketOpenByID(w http.ResponseWriter, r *http.Request, db *gorm.DB, marketID string) bool {
+	id, err := strconv.ParseInt(marketID, 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+		return false
+	}
+	var market models.Market
+	if err := db.First(&market, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+			return false
+		}
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+		return false
+	}
+	return marketOpen(w, r, db, market)
+}
+
+// writeInvitations answers with the market's invitations, oldest first.
+func writeInvitations(w http.ResponseWriter, db *gorm.DB, marketID int64) {
+	var invitations []models.MarketInvitation
+	if err := db.Where("market_id = ?", marketID).Order("id").Find(&invitations).Error; err != nil {
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch invitations")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"marketId":    marketID,
+		"invitations": invitations,
+		"count":       len(invitations),
+	})
+}
@@ -19,7 +19,10 @@ func MarketLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Open up database to utilize connection pooling
-	db := util.GetDB()
+	db := util.GetReadDB()
+	if !MarketOpenByID(w, r, db, marketIdStr) {
+		return
+	}
 
 	leaderboard, err := positionsmath.CalculateMarketLeaderboard(db, marketIdStr)
 	if errors.HandleHTTPError(w, err, http.StatusBadRequest, "Invalid request or data processing error.") {
@@ -2,16 +2,21 @@ package marketshandlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"socialpredict/handlers/marketpublicresponse"
 	marketmath "socialpredict/handlers/math/market"
 	"socialpredict/handlers/math/probabilities/wpam"
 	"socialpredict/handlers/tradingdata"
 	"socialpredict/handlers/users/publicuser"
 	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/marketlisting"
 	"socialpredict/util"
 	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -27,20 +32,36 @@ type MarketOverview struct {
 	LastProbability float64                                   `json:"lastProbability"`
 	NumUsers        int                                       `json:"numUsers"`
 	TotalVolume     int64                                     `json:"totalVolume"`
+
+	// Embedded on request; see ParseMarketIncludes
+	Consensus    *models.ConsensusPoint   `json:"consensus,omitempty"`
+	MyPrediction *models.PredictionPublic `json:"myPrediction,omitempty"`
 }
 
 // ListMarketsHandler handles the HTTP request for listing markets.
+// Optional query parameters narrow and order the list:
+//
+//	category  only markets in this category
+//	tags      comma-separated; only markets carrying every one of them
+//	status    active, closed or resolved
+//	sort      random (default), newest, oldest or closing (soonest first)
 func ListMarketsHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("ListMarketsHandler: Request received")
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method is not supported.", http.StatusNotFound)
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Method is not supported.")
+		return
+	}
+
+	query, err := ParseMarketQuery(r.URL.Query())
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 		return
 	}
 
-	db := util.GetDB()
-	markets, err := ListMarkets(db)
+	db := util.GetReadDB()
+	markets, err := ListMarkets(db, query)
 	if err != nil {
-		http.Error(w, "Error fetching markets", http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error fetching markets")
 		return
 	}
 
@@ -58,7 +79,7 @@ func ListMarketsHandler(w http.ResponseWriter, r *http.Request) {
 		marketIDStr := strconv.FormatUint(uint64(market.ID), 10)
 		publicResponseMarket, err := marketpublicresponse.GetPublicResponseMarketByID(db, marketIDStr)
 		if err != nil {
-			http.Error(w, "Invalid market ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 			return
 		}
 
@@ -72,20 +93,79 @@ func ListMarketsHandler(w http.ResponseWriter, r *http.Request) {
 		marketOverviews = append(marketOverviews, marketOverview)
 	}
 
-	response := ListMarketsResponse{
+	resp := ListMarketsResponse{
 		Markets: marketOverviews,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, err.Error())
+	}
+}
+
+// MarketQuery narrows and orders a market listing. The zero value lists
+// every market in random order.
+type MarketQuery struct {
+	Category string
+	Tags     []string
+	Status   string
+	Sort     string
+}
+
+var marketSorts = map[string]string{
+	"random":  "RANDOM()",
+	"newest":  "created_at DESC",
+	"oldest":  "created_at ASC",
+	"closing": "resolution_date_time ASC",
+}
+
+// ParseMarketQuery reads a MarketQuery from the category, tags, status and
+// sort query parameters, rejecting unknown statuses, sorts and bad tags.
+func ParseMarketQuery(values url.Values) (MarketQuery, error) {
+	query := MarketQuery{
+		Category: strings.ToLower(strings.TrimSpace(values.Get("category"))),
+		Status:   strings.ToLower(strings.TrimSpace(values.Get("status"))),
+		Sort:     strings.ToLower(strings.TrimSpace(values.Get("sort"))),
+	}
+	if query.Status != "" && query.Status != "all" && marketlisting.Filters[query.Status] == nil {
+		return query, fmt.Errorf("status must be one of active, closed, resolved or all")
+	}
+	if query.Sort == "" {
+		query.Sort = "random"
 	}
+	if _, ok := marketSorts[query.Sort]; !ok {
+		return query, fmt.Errorf("sort must be one of random, newest, oldest or closing")
+	}
+	if raw := values.Get("tags"); raw != "" {
+		tags, err := models.NormalizeTags(strings.Split(raw, ","))
+		if err != nil {
+			return query, err
+		}
+		query.Tags = tags
+	}
+	return query, nil
 }
 
-// ListMarkets fetches a random list of all markets from the database.
-func ListMarkets(db *gorm.DB) ([]models.Market, error) {
+// ListMarkets fetches up to 100 listed markets matching query.
+func ListMarkets(db *gorm.DB, query MarketQuery) ([]models.Market, error) {
+	tx := models.WithoutHidden(models.Listed(db.Model(&models.Market{})), models.ContentMarket)
+	if query.Category != "" {
+		tx = tx.Where("category = ?", query.Category)
+	}
+	if filter := marketlisting.Filters[query.Status]; filter != nil {
+		tx = filter(tx)
+	}
+	if len(query.Tags) > 0 {
+		tagged := db.Model(&models.MarketTag{}).
+			Select("market_id").
+			Where("tag IN ?", query.Tags).
+			Group("market_id").
+			Having("COUNT(DISTINCT tag) = ?", len(query.Tags))
+		tx = tx.Where("id IN (?)", tagged)
+	}
+
 	var markets []models.Market
-	result := db.Order("RANDOM()").Limit(100).Find(&markets) // Set a reasonable limit
+	result := tx.Order(marketSorts[query.Sort]).Limit(100).Find(&markets) // Set a reasonable limit
 	if result.Error != nil {
 		log.Printf("Error fetching markets: %v", result.Error)
 		return nil, result.Error
@@ -0,0 +1,106 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/repository"
+	"socialpredict/util"
+)
+
+func TestListMarkets_FiltersByCategoryTagsAndStatus(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	util.DB = db
+
+	user := modelstesting.GenerateUser("testuser", 1000)
+	db.Create(&user)
+
+	now := time.Now()
+	seed := []struct {
+		title    string
+		category string
+		tags     []string
+		closes   time.Duration
+		resolved bool
+	}{
+		{"BTC above 100k", "crypto", []string{"bitcoin", "price"}, 48 * time.Hour, false},
+		{"ETH above 5k", "crypto", []string{"ethereum", "price"}, 24 * time.Hour, false},
+		{"BTC ETF approved", "crypto", []string{"bitcoin", "etf"}, -time.Hour, true},
+		{"Election turnout", "politics", nil, 72 * time.Hour, false},
+	}
+	markets := repository.NewGormRepositories(db).Markets
+	for _, s := range seed {
+		market := models.Market{
+			QuestionTitle:      s.title,
+			OutcomeType:        "BINARY",
+			ResolutionDateTime: now.Add(s.closes),
+			IsResolved:         s.resolved,
+			InitialProbability: 0.5,
+			CreatorUsername:    user.Username,
+			Category:           s.category,
+		}
+		if err := db.Create(&market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+		if err := markets.SetTags(market.ID, s.tags); err != nil {
+			t.Fatalf("tag market: %v", err)
+		}
+	}
+
+	list := func(query string) []string {
+		req := httptest.NewRequest(http.MethodGet, "/v0/markets?"+query, nil)
+		rec := httptest.NewRecorder()
+		ListMarketsHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, rec.Code, rec.Body.String())
+		}
+		var resp ListMarketsResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		titles := make([]string, len(resp.Markets))
+		for i, m := range resp.Markets {
+			titles[i] = m.Market.QuestionTitle
+		}
+		return titles
+	}
+
+	if got := list("category=crypto&status=active&sort=closing"); len(got) != 2 || got[0] != "ETH above 5k" || got[1] != "BTC above 100k" {
+		t.Fatalf("expected open crypto markets soonest first, got %v", got)
+	}
+	if got := list("tags=Bitcoin,price"); len(got) != 1 || got[0] != "BTC above 100k" {
+		t.Fatalf("expected only the market with both tags, got %v", got)
+	}
+	if got := list("tags=bitcoin&status=resolved"); len(got) != 1 || got[0] != "BTC ETF approved" {
+		t.Fatalf("expected the resolved bitcoin market, got %v", got)
+	}
+	if got := list(""); len(got) != 4 {
+		t.Fatalf("expected every market without filters, got %v", got)
+	}
+
+	for _, bad := range []string{"status=pending", "sort=popular", "tags=no_underscores"} {
+		req := httptest.NewRequest(http.MethodGet, "/v0/markets?"+bad, nil)
+		rec := httptest.NewRecorder()
+		ListMarketsHandler(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	CategoriesHandler(db)(rec, httptest.NewRequest(http.MethodGet, "/v0/categories", nil))
+	var resp struct {
+		Categories []CategoryCount `json:"categories"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Categories) != 2 {
+		t.Fatalf("expected two categories, got %+v", resp.Categories)
+	}
+	crypto := resp.Categories[0]
+	if crypto.Category != "crypto" || crypto.Markets != 3 || crypto.Active != 2 || crypto.Resolved != 1 {
+		t.Fatalf("unexpected crypto counts %+v", crypto)
+	}
+}
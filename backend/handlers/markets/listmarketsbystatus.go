@@ -2,6 +2,7 @@ package marketshandlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"socialpredict/handlers/marketpublicresponse"
@@ -9,10 +10,14 @@ import (
 	"socialpredict/handlers/math/probabilities/wpam"
 	"socialpredict/handlers/tradingdata"
 	"socialpredict/handlers/users/publicuser"
+	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/consensus"
+	"socialpredict/services/marketlisting"
 	"socialpredict/util"
 	"strconv"
-	"time"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -24,23 +29,98 @@ type ListMarketsStatusResponse struct {
 	Count   int              `json:"count"`
 }
 
-// MarketFilterFunc defines the filtering logic for markets
-type MarketFilterFunc func(*gorm.DB) *gorm.DB
+// Values of the include query parameter, which embeds extra data in each
+// MarketOverview so clients need not fetch it market by market.
+const (
+	IncludeConsensus    = "consensus"    // the market's last recorded swarm consensus
+	IncludeMyPrediction = "myPrediction" // the calling agent's current prediction
+)
+
+// MarketIncludes says what to embed in each MarketOverview.
+type MarketIncludes struct {
+	Consensus    bool
+	MyPrediction bool
+}
+
+// ParseMarketIncludes reads a comma-separated include parameter such as
+// "consensus,myPrediction".
+func ParseMarketIncludes(raw string) (MarketIncludes, error) {
+	var includes MarketIncludes
+	for _, name := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case IncludeConsensus:
+			includes.Consensus = true
+		case IncludeMyPrediction:
+			includes.MyPrediction = true
+		default:
+			return includes, fmt.Errorf("unknown include %q; use %s or %s", name, IncludeConsensus, IncludeMyPrediction)
+		}
+	}
+	return includes, nil
+}
+
+// embedIncludes fills in what includes asks for across all of overviews
+// with one query each. myPrediction is only filled in for an agent caller;
+// markets it has not predicted on, like markets without predictions for
+// consensus, are left without one.
+func embedIncludes(db *gorm.DB, overviews []MarketOverview, includes MarketIncludes, principal *middleware.Principal) error {
+	marketIDs := make([]int64, len(overviews))
+	for i, overview := range overviews {
+		marketIDs[i] = overview.Market.ID
+	}
+
+	if includes.Consensus {
+		latest, err := consensus.Latest(db, marketIDs)
+		if err != nil {
+			return err
+		}
+		for i := range overviews {
+			if point, ok := latest[overviews[i].Market.ID]; ok {
+				overviews[i].Consensus = &point
+			}
+		}
+	}
 
-// ListMarketsByStatusHandler creates a handler for listing markets by status using polymorphic filtering
-func ListMarketsByStatusHandler(filterFunc MarketFilterFunc, statusName string) http.HandlerFunc {
+	if includes.MyPrediction && principal != nil && principal.Agent != nil && len(marketIDs) > 0 {
+		var predictions []models.Prediction
+		if err := db.Where("agent_id = ? AND market_id IN ?", principal.Agent.ID, marketIDs).Find(&predictions).Error; err != nil {
+			return err
+		}
+		byMarket := make(map[int64]models.PredictionPublic, len(predictions))
+		for i := range predictions {
+			byMarket[predictions[i].MarketID] = predictions[i].ToPublic()
+		}
+		for i := range overviews {
+			if prediction, ok := byMarket[overviews[i].Market.ID]; ok {
+				overviews[i].MyPrediction = &prediction
+			}
+		}
+	}
+	return nil
+}
+
+// ListMarketsByStatusHandler creates a handler for listing markets by status using polymorphic filtering.
+// The include query parameter embeds consensus and the caller's prediction; see ParseMarketIncludes.
+func ListMarketsByStatusHandler(filterFunc marketlisting.Filter, statusName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("ListMarketsByStatusHandler: Request received for status: %s", statusName)
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
+			return
+		}
+
+		includes, err := ParseMarketIncludes(r.URL.Query().Get("include"))
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
 		}
 
-		db := util.GetDB()
-		markets, err := ListMarketsByStatus(db, filterFunc)
+		db := util.GetReadDB()
+		markets, err := marketlisting.ListByStatus(db, filterFunc)
 		if err != nil {
 			log.Printf("Error fetching markets for status %s: %v", statusName, err)
-			http.Error(w, "Error fetching markets", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error fetching markets")
 			return
 		}
 
@@ -59,7 +139,7 @@ func ListMarketsByStatusHandler(filterFunc MarketFilterFunc, statusName string)
 			publicResponseMarket, err := marketpublicresponse.GetPublicResponseMarketByID(db, marketIDStr)
 			if err != nil {
 				log.Printf("Error getting public response market for ID %s: %v", marketIDStr, err)
-				http.Error(w, "Invalid market ID", http.StatusBadRequest)
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 				return
 			}
 
@@ -73,64 +153,40 @@ func ListMarketsByStatusHandler(filterFunc MarketFilterFunc, statusName string)
 			marketOverviews = append(marketOverviews, marketOverview)
 		}
 
-		response := ListMarketsStatusResponse{
+		if err := embedIncludes(db, marketOverviews, includes, middleware.PrincipalFromContext(r.Context())); err != nil {
+			log.Printf("Error embedding %+v for status %s: %v", includes, statusName, err)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error fetching markets")
+			return
+		}
+
+		resp := ListMarketsStatusResponse{
 			Markets: marketOverviews,
 			Status:  statusName,
 			Count:   len(marketOverviews),
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
 			log.Printf("Error encoding response for status %s: %v", statusName, err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, err.Error())
 		}
 	}
 }
 
-// ListMarketsByStatus fetches markets from the database using the provided filter function
-func ListMarketsByStatus(db *gorm.DB, filterFunc MarketFilterFunc) ([]models.Market, error) {
-	var markets []models.Market
-	query := filterFunc(db).Order("created_at DESC").Limit(100) // Set a reasonable limit and order by most recent
-	result := query.Find(&markets)
-	if result.Error != nil {
-		log.Printf("Error fetching filtered markets: %v", result.Error)
-		return nil, result.Error
-	}
-
-	return markets, nil
-}
-
-// ActiveMarketsFilter returns markets that are not resolved and have not yet reached their resolution date
-func ActiveMarketsFilter(db *gorm.DB) *gorm.DB {
-	now := time.Now()
-	return db.Where("is_resolved = ? AND resolution_date_time > ?", false, now)
-}
-
-// ClosedMarketsFilter returns markets that are not resolved but have passed their resolution date
-func ClosedMarketsFilter(db *gorm.DB) *gorm.DB {
-	now := time.Now()
-	return db.Where("is_resolved = ? AND resolution_date_time <= ?", false, now)
-}
-
-// ResolvedMarketsFilter returns markets that have been resolved
-func ResolvedMarketsFilter(db *gorm.DB) *gorm.DB {
-	return db.Where("is_resolved = ?", true)
-}
-
 // ListActiveMarketsHandler handles HTTP requests for active markets
 func ListActiveMarketsHandler(w http.ResponseWriter, r *http.Request) {
-	handler := ListMarketsByStatusHandler(ActiveMarketsFilter, "active")
+	handler := ListMarketsByStatusHandler(marketlisting.ActiveFilter, "active")
 	handler(w, r)
 }
 
 // ListClosedMarketsHandler handles HTTP requests for closed markets
 func ListClosedMarketsHandler(w http.ResponseWriter, r *http.Request) {
-	handler := ListMarketsByStatusHandler(ClosedMarketsFilter, "closed")
+	handler := ListMarketsByStatusHandler(marketlisting.ClosedFilter, "closed")
 	handler(w, r)
 }
 
 // ListResolvedMarketsHandler handles HTTP requests for resolved markets
 func ListResolvedMarketsHandler(w http.ResponseWriter, r *http.Request) {
-	handler := ListMarketsByStatusHandler(ResolvedMarketsFilter, "resolved")
+	handler := ListMarketsByStatusHandler(marketlisting.ResolvedFilter, "resolved")
 	handler(w, r)
 }
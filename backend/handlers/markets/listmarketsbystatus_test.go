@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"socialpredict/middleware"
 	"socialpredict/models"
 	"socialpredict/models/modelstesting"
 	"socialpredict/util"
@@ -11,246 +12,6 @@ import (
 	"time"
 )
 
-func TestActiveMarketsFilter(t *testing.T) {
-	db := modelstesting.NewFakeDB(t)
-	util.DB = db
-
-	// Create test data
-	now := time.Now()
-	futureTime := now.Add(24 * time.Hour)
-	pastTime := now.Add(-24 * time.Hour)
-
-	// Create test user
-	testUser := modelstesting.GenerateUser("testuser", 1000)
-	db.Create(&testUser)
-
-	// Active market (not resolved, future resolution date)
-	activeMarket := models.Market{
-		ID:                 1,
-		QuestionTitle:      "Active Market",
-		Description:        "Test active market",
-		OutcomeType:        "BINARY",
-		ResolutionDateTime: futureTime,
-		IsResolved:         false,
-		InitialProbability: 0.5,
-		CreatorUsername:    "testuser",
-	}
-
-	// Closed market (not resolved, past resolution date)
-	closedMarket := models.Market{
-		ID:                 2,
-		QuestionTitle:      "Closed Market",
-		Description:        "Test closed market",
-		OutcomeType:        "BINARY",
-		ResolutionDateTime: pastTime,
-		IsResolved:         false,
-		InitialProbability: 0.5,
-		CreatorUsername:    "testuser",
-	}
-
-	// Resolved market
-	resolvedMarket := models.Market{
-		ID:                      3,
-		QuestionTitle:           "Resolved Market",
-		Description:             "Test resolved market",
-		OutcomeType:             "BINARY",
-		ResolutionDateTime:      pastTime,
-		FinalResolutionDateTime: pastTime,
-		IsResolved:              true,
-		ResolutionResult:        "YES",
-		InitialProbability:      0.5,
-		CreatorUsername:         "testuser",
-	}
-
-	// Insert test data
-	db.Create(&activeMarket)
-	db.Create(&closedMarket)
-	db.Create(&resolvedMarket)
-
-	// Test ActiveMarketsFilter
-	var activeResults []models.Market
-	ActiveMarketsFilter(db).Find(&activeResults)
-	if len(activeResults) != 1 {
-		t.Errorf("Expected 1 active market, got %d", len(activeResults))
-	}
-	if activeResults[0].QuestionTitle != "Active Market" {
-		t.Errorf("Expected 'Active Market', got %s", activeResults[0].QuestionTitle)
-	}
-	if activeResults[0].IsResolved {
-		t.Error("Expected market to not be resolved")
-	}
-	if !activeResults[0].ResolutionDateTime.After(now) {
-		t.Error("Expected resolution date to be in the future")
-	}
-}
-
-func TestClosedMarketsFilter(t *testing.T) {
-	db := modelstesting.NewFakeDB(t)
-	util.DB = db
-
-	// Create test data
-	now := time.Now()
-	futureTime := now.Add(24 * time.Hour)
-	pastTime := now.Add(-24 * time.Hour)
-
-	// Create test user
-	testUser := modelstesting.GenerateUser("testuser", 1000)
-	db.Create(&testUser)
-
-	// Active market
-	activeMarket := models.Market{
-		ID:                 1,
-		QuestionTitle:      "Active Market",
-		Description:        "Test active market",
-		OutcomeType:        "BINARY",
-		ResolutionDateTime: futureTime,
-		IsResolved:         false,
-		InitialProbability: 0.5,
-		CreatorUsername:    "testuser",
-	}
-
-	// Closed market
-	closedMarket := models.Market{
-		ID:                 2,
-		QuestionTitle:      "Closed Market",
-		Description:        "Test closed market",
-		OutcomeType:        "BINARY",
-		ResolutionDateTime: pastTime,
-		IsResolved:         false,
-		InitialProbability: 0.5,
-		CreatorUsername:    "testuser",
-	}
-
-	// Insert test data
-	db.Create(&activeMarket)
-	db.Create(&closedMarket)
-
-	// Test ClosedMarketsFilter
-	var closedResults []models.Market
-	ClosedMarketsFilter(db).Find(&closedResults)
-	if len(closedResults) != 1 {
-		t.Errorf("Expected 1 closed market, got %d", len(closedResults))
-	}
-	if closedResults[0].QuestionTitle != "Closed Market" {
-		t.Errorf("Expected 'Closed Market', got %s", closedResults[0].QuestionTitle)
-	}
-	if closedResults[0].IsResolved {
-		t.Error("Expected market to not be resolved")
-	}
-	if closedResults[0].ResolutionDateTime.After(now) {
-		t.Error("Expected resolution date to be in the past")
-	}
-}
-
-func TestResolvedMarketsFilter(t *testing.T) {
-	db := modelstesting.NewFakeDB(t)
-	util.DB = db
-
-	// Create test data
-	pastTime := time.Now().Add(-24 * time.Hour)
-
-	// Create test user
-	testUser := modelstesting.GenerateUser("testuser", 1000)
-	db.Create(&testUser)
-
-	// Unresolved market
-	unresolvedMarket := models.Market{
-		ID:                 1,
-		QuestionTitle:      "Unresolved Market",
-		Description:        "Test unresolved market",
-		OutcomeType:        "BINARY",
-		ResolutionDateTime: pastTime,
-		IsResolved:         false,
-		InitialProbability: 0.5,
-		CreatorUsername:    "testuser",
-	}
-
-	// Resolved market
-	resolvedMarket := models.Market{
-		ID:                      2,
-		QuestionTitle:           "Resolved Market",
-		Description:             "Test resolved market",
-		OutcomeType:             "BINARY",
-		ResolutionDateTime:      pastTime,
-		FinalResolutionDateTime: pastTime,
-		IsResolved:              true,
-		ResolutionResult:        "YES",
-		InitialProbability:      0.5,
-		CreatorUsername:         "testuser",
-	}
-
-	// Insert test data
-	db.Create(&unresolvedMarket)
-	db.Create(&resolvedMarket)
-
-	// Test ResolvedMarketsFilter
-	var resolvedResults []models.Market
-	ResolvedMarketsFilter(db).Find(&resolvedResults)
-	if len(resolvedResults) != 1 {
-		t.Errorf("Expected 1 resolved market, got %d", len(resolvedResults))
-	}
-	if resolvedResults[0].QuestionTitle != "Resolved Market" {
-		t.Errorf("Expected 'Resolved Market', got %s", resolvedResults[0].QuestionTitle)
-	}
-	if !resolvedResults[0].IsResolved {
-		t.Error("Expected market to be resolved")
-	}
-	if resolvedResults[0].ResolutionResult != "YES" {
-		t.Errorf("Expected resolution result 'YES', got %s", resolvedResults[0].ResolutionResult)
-	}
-}
-
-func TestListMarketsByStatus(t *testing.T) {
-	db := modelstesting.NewFakeDB(t)
-	util.DB = db
-
-	// Create test user first
-	testUser := modelstesting.GenerateUser("testuser", 1000)
-	db.Create(&testUser)
-
-	// Create test data
-	futureTime := time.Now().Add(24 * time.Hour)
-
-	activeMarket := models.Market{
-		ID:                 1,
-		QuestionTitle:      "Active Market",
-		Description:        "Test active market",
-		OutcomeType:        "BINARY",
-		ResolutionDateTime: futureTime,
-		IsResolved:         false,
-		InitialProbability: 0.5,
-		CreatorUsername:    "testuser",
-	}
-
-	db.Create(&activeMarket)
-
-	// Test ListMarketsByStatus with ActiveMarketsFilter
-	markets, err := ListMarketsByStatus(db, ActiveMarketsFilter)
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
-	}
-	if len(markets) != 1 {
-		t.Errorf("Expected 1 market, got %d", len(markets))
-	}
-	if markets[0].QuestionTitle != "Active Market" {
-		t.Errorf("Expected 'Active Market', got %s", markets[0].QuestionTitle)
-	}
-}
-
-func TestListMarketsByStatusWithEmptyResults(t *testing.T) {
-	db := modelstesting.NewFakeDB(t)
-	util.DB = db
-
-	// Test with no markets in database
-	markets, err := ListMarketsByStatus(db, ActiveMarketsFilter)
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
-	}
-	if len(markets) != 0 {
-		t.Errorf("Expected 0 markets, got %d", len(markets))
-	}
-}
-
 func TestListActiveMarketsHandler(t *testing.T) {
 	db := modelstesting.NewFakeDB(t)
 	util.DB = db
@@ -310,6 +71,64 @@ func TestListActiveMarketsHandler(t *testing.T) {
 	}
 }
 
+func TestListActiveMarketsHandler_EmbedsConsensusAndMyPrediction(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	util.DB = db
+
+	testUser := modelstesting.GenerateUser("testuser", 1000)
+	db.Create(&testUser)
+	predicted := modelstesting.GenerateMarket(1, "testuser")
+	quiet := modelstesting.GenerateMarket(2, "testuser")
+	db.Create(&predicted)
+	db.Create(&quiet)
+
+	agent := models.Agent{Name: "caller", APIKey: "swarm_sk_caller", ClaimToken: "claim_caller", IsActive: true}
+	db.Create(&agent)
+	prediction := models.Prediction{AgentID: agent.ID, MarketID: predicted.ID, Outcome: "YES", Confidence: 70, PredictedAt: time.Now()}
+	db.Create(&prediction)
+	db.Create(&models.ConsensusPoint{MarketID: predicted.ID, Probability: 0.7, Predictions: 1, PredictionID: prediction.ID, RecordedAt: time.Now()})
+
+	list := func(include string, principal *middleware.Principal) (int, map[int64]MarketOverview) {
+		req := httptest.NewRequest("GET", "/v0/markets/active?include="+include, nil)
+		if principal != nil {
+			req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+		}
+		rr := httptest.NewRecorder()
+		ListActiveMarketsHandler(rr, req)
+		var resp ListMarketsStatusResponse
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		byID := make(map[int64]MarketOverview)
+		for _, overview := range resp.Markets {
+			byID[overview.Market.ID] = overview
+		}
+		return rr.Code, byID
+	}
+
+	status, markets := list("consensus,myPrediction", &middleware.Principal{Tier: middleware.TierAgent, Agent: &agent})
+	if status != http.StatusOK || len(markets) != 2 {
+		t.Fatalf("Expected both active markets, got %d %+v", status, markets)
+	}
+	if c := markets[predicted.ID].Consensus; c == nil || c.Probability != 0.7 || c.Predictions != 1 {
+		t.Errorf("Expected the recorded consensus embedded, got %+v", c)
+	}
+	if p := markets[predicted.ID].MyPrediction; p == nil || p.ID != prediction.ID || p.Outcome != "YES" {
+		t.Errorf("Expected the caller's prediction embedded, got %+v", p)
+	}
+	if quietMarket := markets[quiet.ID]; quietMarket.Consensus != nil || quietMarket.MyPrediction != nil {
+		t.Errorf("Expected nothing embedded for a market without predictions, got %+v", quietMarket)
+	}
+
+	if _, anonymous := list("consensus,myPrediction", nil); anonymous[predicted.ID].MyPrediction != nil || anonymous[predicted.ID].Consensus == nil {
+		t.Errorf("Expected only consensus for an anonymous caller, got %+v", anonymous[predicted.ID])
+	}
+	if _, plain := list("", nil); plain[predicted.ID].Consensus != nil {
+		t.Errorf("Expected nothing embedded without include, got %+v", plain[predicted.ID])
+	}
+	if status, _ := list("everything", nil); status != http.StatusBadRequest {
+		t.Errorf("Expected an unknown include to be rejected, got %d", status)
+	}
+}
+
 func TestListClosedMarketsHandler(t *testing.T) {
 	db := modelstesting.NewFakeDB(t)
 	util.DB = db
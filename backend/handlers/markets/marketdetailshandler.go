@@ -10,6 +10,7 @@ import (
 	"socialpredict/handlers/tradingdata"
 	"socialpredict/handlers/users/publicuser"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/util"
 	"strconv"
 
@@ -33,7 +34,7 @@ func MarketDetailsHandler(w http.ResponseWriter, r *http.Request) {
 	// Parsing a String to an Unsigned Integer, base10, 64bits
 	marketIDUint64, err := strconv.ParseUint(marketId, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid market ID", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 		return
 	}
 
@@ -53,13 +54,16 @@ func MarketDetailsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Validate that the uint64 value fits in platform uint
 	if isPlatform32Bit && marketIDUint64 > math.MaxUint32 {
-		http.Error(w, "Market ID out of range", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Market ID out of range")
 		return
 	}
 	marketIDUint := uint(marketIDUint64)
 
 	// open up database to utilize connection pooling
 	db := util.GetDB()
+	if !MarketOpenByID(w, r, db, marketId) {
+		return
+	}
 
 	// Fetch all bets for the market
 	bets := tradingdata.GetBetsForMarket(db, marketIDUint)
@@ -67,7 +71,7 @@ func MarketDetailsHandler(w http.ResponseWriter, r *http.Request) {
 	// return the PublicResponse type with information about the market
 	publicResponseMarket, err := marketpublicresponse.GetPublicResponseMarketByID(db, marketId)
 	if err != nil {
-		http.Error(w, "Invalid market ID", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 		return
 	}
 
@@ -7,6 +7,7 @@ import (
 	"socialpredict/handlers/math/probabilities/wpam"
 	"socialpredict/handlers/tradingdata"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/util"
 	"strconv"
 	"time"
@@ -26,7 +27,7 @@ func ProjectNewProbabilityHandler(w http.ResponseWriter, r *http.Request) {
 	// Parse marketId string directly into a uint
 	marketIDUint64, err := strconv.ParseUint(marketId, 10, strconv.IntSize)
 	if err != nil {
-		http.Error(w, "Invalid market ID", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 		return
 	}
 
@@ -36,7 +37,7 @@ func ProjectNewProbabilityHandler(w http.ResponseWriter, r *http.Request) {
 	// Convert amount to int64
 	amount, err := strconv.ParseInt(amountStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid amount value", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid amount value")
 		return
 	}
 
@@ -50,6 +51,9 @@ func ProjectNewProbabilityHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Open up database to utilize connection pooling
 	db := util.GetDB()
+	if !MarketOpenByID(w, r, db, marketId) {
+		return
+	}
 
 	// Fetch all bets for the market
 	currentBets := tradingdata.GetBetsForMarket(db, marketIDUint)
@@ -57,7 +61,7 @@ func ProjectNewProbabilityHandler(w http.ResponseWriter, r *http.Request) {
 	// Fetch the market creation time using utility function
 	publicResponseMarket, err := marketpublicresponse.GetPublicResponseMarketByID(db, marketId)
 	if err != nil {
-		http.Error(w, "Invalid market ID", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 		return
 	}
 	marketCreatedAt := publicResponseMarket.CreatedAt
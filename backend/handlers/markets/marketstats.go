@@ -0,0 +1,227 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/consensus"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// confidenceBins is how many equal bins the confidence histogram has over
+// 0-100.
+const confidenceBins = 10
+
+// PredictionCountBucket is how many predictions were made in one hour or
+// day, and how many had been made by its end.
+type PredictionCountBucket struct {
+	Start       time.Time `json:"start"`
+	Predictions int64     `json:"predictions"`
+	Cumulative  int64     `json:"cumulative"`
+}
+
+// ConfidenceBin counts the predictions whose confidence is at least Min and
+// below Max; the last bin includes 100.
+type ConfidenceBin struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int64   `json:"count"`
+}
+
+// MarketStats is everything GET /v0/markets/{id}/stats reports.
+type MarketStats struct {
+	MarketID            int64                   `json:"marketId"`
+	Interval            string                  `json:"interval"`
+	TotalPredictions    int64                   `json:"totalPredictions"`
+	UniqueAgents        int64                   `json:"uniqueAgents"`
+	YesPredictions      int64                   `json:"yesPredictions"`
+	NoPredictions       int64                   `json:"noPredictions"`
+	AverageConfidence   float64                 `json:"averageConfidence"`
+	Upvotes             int64                   `json:"upvotes"`
+	Downvotes           int64                   `json:"downvotes"`
+	Comments            int64                   `json:"comments"`
+	Watchers            int64                   `json:"watchers"` // agents with the market on their watchlist
+	PredictionsOverTime []PredictionCountBucket `json:"predictionsOverTime"`
+	Confidence          []ConfidenceBin         `json:"confidence"`
+	Consensus           float64                 `json:"consensus"` // current chance of YES, 0-1
+	ConsensusTrend      []consensus.Bucket      `json:"consensusTrend"`
+	// Scalar markets: the median estimate and its spread; Consensus and the
+	// trend then place the median in the market's range, 0-1
+	ScalarConsensus *consensus.Scalar `json:"scalarConsensus,omitempty"`
+}
+
+// MarketStatsHandler handles GET /v0/markets/{id}/stats
+// Aggregates the market's predictions in one response: how many were made
+// over time, in daily buckets or hourly ones with ?interval=hour, the
+// confidence histogram, how many agents predicted, YES/NO split,
+// engagement totals and how many agents watch it, and the consensus with
+// its trend in the same buckets.
+// Predictions hidden by moderation are not counted.
+func MarketStatsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+		interval := r.URL.Query().Get("interval")
+		if interval == "" {
+			interval = consensus.IntervalDay
+		}
+		var size time.Duration
+		switch interval {
+		case consensus.IntervalHour:
+			size = time.Hour
+		case consensus.IntervalDay:
+			size = 24 * time.Hour
+		default:
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, consensus.ErrInvalidInterval.Error())
+			return
+		}
+
+		var market models.Market
+		if err := db.Select("id", "visibility", "creator_agent_id", "outcome_type", "scalar_min", "scalar_max").First(&market, marketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if !marketOpen(w, r, db, market) {
+			return
+		}
+
+		stats, err := marketStats(db, marketID, interval, size)
+		if err == nil && market.IsScalar() {
+			err = scalarStats(db, market, stats)
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to compute market stats")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"stats":   stats,
+		})
+	}
+}
+
+// marketStats computes the market's stats with aggregate queries for the
+// totals and watchers and one for the times and confidences of its
+// predictions, which are bucketed here as the bucketing SQL differs
+// between dialects.
+func marketStats(db *gorm.DB, marketID int64, interval string, size time.Duration) (*MarketStats, error) {
+	predictions := func() *gorm.DB {
+		return models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+			Where("predictions.market_id = ?", marketID)
+	}
+
+	// gorm cannot scan into MarketStats itself, whose slices it would take
+	// for relations, so the totals are read into a flat struct first.
+	var totals struct {
+		TotalPredictions  int64
+		UniqueAgents      int64
+		YesPredictions    int64
+		AverageConfidence float64
+		Upvotes           int64
+		Downvotes         int64
+		Comments          int64
+	}
+	if err := predictions().
+		Select(`COUNT(*) AS total_predictions,
+			COUNT(DISTINCT predictions.agent_id) AS unique_agents,
+			COALESCE(SUM(CASE WHEN UPPER(predictions.outcome) = 'YES' THEN 1 ELSE 0 END), 0) AS yes_predictions,
+			COALESCE(AVG(predictions.confidence), 0) AS average_confidence,
+			COALESCE(SUM(predictions.upvotes), 0) AS upvotes,
+			COALESCE(SUM(predictions.downvotes), 0) AS downvotes,
+			COALESCE(SUM(predictions.comments), 0) AS comments`).
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	stats := &MarketStats{
+		MarketID:          marketID,
+		Interval:          interval,
+		TotalPredictions:  totals.TotalPredictions,
+		UniqueAgents:      totals.UniqueAgents,
+		YesPredictions:    totals.YesPredictions,
+		AverageConfidence: totals.AverageConfidence,
+		Upvotes:           totals.Upvotes,
+		Downvotes:         totals.Downvotes,
+		Comments:          totals.Comments,
+	}
+	stats.NoPredictions = stats.TotalPredictions - stats.YesPredictions
+	if err := db.Model(&models.MarketWatch{}).Where("market_id = ?", marketID).Count(&stats.Watchers).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		PredictedAt time.Time
+		Confidence  float64
+	}
+	if err := predictions().
+		Select("predictions.predicted_at, predictions.confidence").
+		Order("predictions.predicted_at").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stats.PredictionsOverTime = []PredictionCountBucket{}
+	stats.Confidence = make([]ConfidenceBin, confidenceBins)
+	width := 100.0 / confidenceBins
+	for i := range stats.Confidence {
+		stats.Confidence[i] = ConfidenceBin{Min: float64(i) * width, Max: float64(i+1) * width}
+	}
+	for i, row := range rows {
+		start := row.PredictedAt.UTC().Truncate(size)
+		n := len(stats.PredictionsOverTime)
+		if n == 0 || !stats.PredictionsOverTime[n-1].Start.Equal(start) {
+			stats.PredictionsOverTime = append(stats.PredictionsOverTime, PredictionCountBucket{Start: start})
+			n++
+		}
+		stats.PredictionsOverTime[n-1].Predictions++
+		stats.PredictionsOverTime[n-1].Cumulative = int64(i + 1)
+
+		bin := int(row.Confidence / width)
+		if bin < 0 {
+			bin = 0
+		} else if bin >= confidenceBins {
+			bin = confidenceBins - 1
+		}
+		stats.Confidence[bin].Count++
+	}
+
+	var err error
+	if stats.Consensus, _, err = consensus.Current(db, marketID); err != nil {
+		return nil, err
+	}
+	if stats.ConsensusTrend, err = consensus.History(db, marketID, interval); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// scalarStats replaces the chance of YES in stats with the scalar market's
+// consensus and where its median sits in the market's range.
+func scalarStats(db *gorm.DB, market models.Market, stats *MarketStats) error {
+	scalar, err := consensus.CurrentScalar(db, market.ID)
+	if err != nil {
+		return err
+	}
+	stats.ScalarConsensus = scalar
+	stats.Consensus = 0
+	if scalar != nil {
+		lower, upper := market.ScalarBounds()
+		stats.Consensus = (scalar.Median - lower) / (upper - lower)
+	}
+	return nil
+}
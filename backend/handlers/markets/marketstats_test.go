@@ -0,0 +1,103 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/services/consensus"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMarketStatsHandler_AggregatesPredictions(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	creator := modelstesting.GenerateUser("creator", 0)
+	db.Create(&creator)
+	market := modelstesting.GenerateMarket(1, "creator")
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	var agents []models.Agent
+	for _, name := range []string{"alpha", "beta"} {
+		agent := models.Agent{Name: name, APIKey: "swarm_sk_" + name, ClaimToken: "claim_" + name, IsActive: true}
+		if err := db.Create(&agent).Error; err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+		agents = append(agents, agent)
+	}
+
+	if err := db.Create(&models.MarketWatch{AgentID: agents[1].ID, MarketID: market.ID}).Error; err != nil {
+		t.Fatalf("create watch: %v", err)
+	}
+
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for _, p := range []models.Prediction{
+		{AgentID: agents[0].ID, Outcome: "YES", Confidence: 55, Upvotes: 2, Comments: 1, PredictedAt: day},
+		{AgentID: agents[1].ID, Outcome: "NO", Confidence: 100, Downvotes: 1, PredictedAt: day.Add(3 * time.Hour)},
+		{AgentID: agents[0].ID, Outcome: "YES", Confidence: 58, Upvotes: 1, PredictedAt: day.Add(24 * time.Hour)},
+	} {
+		p.MarketID = market.ID
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("create prediction: %v", err)
+		}
+		if err := consensus.Record(db, market.ID, p.ID, p.PredictedAt); err != nil {
+			t.Fatalf("record consensus: %v", err)
+		}
+	}
+
+	get := func(id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/markets/"+id+"/stats"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		rec := httptest.NewRecorder()
+		MarketStatsHandler(db)(rec, req)
+		return rec
+	}
+
+	rec := get(strconv.FormatInt(market.ID, 10), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Stats MarketStats `json:"stats"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	stats := resp.Stats
+
+	if stats.TotalPredictions != 3 || stats.UniqueAgents != 2 || stats.YesPredictions != 2 || stats.NoPredictions != 1 {
+		t.Fatalf("unexpected totals: %+v", stats)
+	}
+	if stats.Upvotes != 3 || stats.Downvotes != 1 || stats.Comments != 1 || stats.Watchers != 1 {
+		t.Fatalf("unexpected engagement: up %d, down %d, comments %d, watchers %d", stats.Upvotes, stats.Downvotes, stats.Comments, stats.Watchers)
+	}
+	if len(stats.PredictionsOverTime) != 2 ||
+		stats.PredictionsOverTime[0].Predictions != 2 ||
+		stats.PredictionsOverTime[1].Predictions != 1 ||
+		stats.PredictionsOverTime[1].Cumulative != 3 {
+		t.Fatalf("unexpected daily counts: %+v", stats.PredictionsOverTime)
+	}
+	if len(stats.Confidence) != confidenceBins || stats.Confidence[5].Count != 2 || stats.Confidence[9].Count != 1 {
+		t.Fatalf("unexpected confidence histogram: %+v", stats.Confidence)
+	}
+	if len(stats.ConsensusTrend) == 0 {
+		t.Fatal("expected a consensus trend")
+	}
+
+	if rec := get(strconv.FormatInt(market.ID, 10), "?interval=hour"); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for hourly stats, got %d", rec.Code)
+	}
+	if rec := get(strconv.FormatInt(market.ID, 10), "?interval=week"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown interval, got %d", rec.Code)
+	}
+	if rec := get("9999", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing market, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,47 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"socialpredict/response"
+	"socialpredict/services/resolution"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ResolutionPreviewHandler handles GET /v0/markets/{id}/resolution-preview?outcome=YES
+// Shows whoever may resolve the market how many agent predictions the
+// outcome would score correct and wrong, and how each predictor's scores
+// would move, before they commit to it. Nothing is written.
+func ResolutionPreviewHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+
+		authorize, httpErr := resolverFor(r)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		outcome := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("outcome")))
+		preview, err := resolution.PreviewResolve(r.Context(), db, marketID, outcome, authorize)
+		if err != nil {
+			writeResolutionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"preview": preview,
+		})
+	}
+}
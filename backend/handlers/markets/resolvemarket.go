@@ -4,14 +4,18 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
-	"socialpredict/handlers/math/payout"
+	spErrors "socialpredict/errors"
+	"socialpredict/i18n"
 	"socialpredict/logging"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/resolution"
 	"socialpredict/util"
-	"strconv"
-	"time"
+	"socialpredict/validation"
 
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
@@ -28,16 +32,16 @@ func ResolveMarketHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	marketIdStr := vars["marketId"]
 
-	marketId, err := strconv.ParseUint(marketIdStr, 10, 64)
+	marketId, err := strconv.ParseInt(marketIdStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid market ID", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 		return
 	}
 
 	// Validate token and get user
 	user, httperr := middleware.ValidateTokenAndGetUser(r, db)
 	if httperr != nil {
-		http.Error(w, "Invalid token: "+httperr.Error(), http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid token: "+httperr.Error())
 		return
 	}
 
@@ -46,64 +50,134 @@ func ResolveMarketHandler(w http.ResponseWriter, r *http.Request) {
 		Outcome string `json:"outcome"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&resolutionData); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 		return
 	}
 
-	var market models.Market
-	result := db.First(&market, marketId)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			http.Error(w, "Market not found", http.StatusNotFound)
-			return
+	// Only the creator of the market may resolve it here
+	creatorOnly := func(_ *gorm.DB, market *models.Market) error {
+		if market.CreatorUsername != user.Username {
+			return resolution.ErrNotAuthorized
 		}
-		http.Error(w, "Error accessing database", http.StatusInternalServerError)
-		return
+		return nil
 	}
 
-	if &market == nil {
-		// handle nil market if necessary, this is just precautionary, as gorm.First should return found object or error
-		http.Error(w, "No market found with provided ID", http.StatusNotFound)
+	// Resolve, pay out, score agent predictions and notify the predictors
+	if _, err := resolution.Resolve(r.Context(), db, marketId, resolutionData.Outcome, creatorOnly); err != nil {
+		switch {
+		case errors.Is(err, resolution.ErrNotAuthorized):
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "User is not the creator of the market")
+		default:
+			writeResolutionError(w, err)
+		}
 		return
 	}
 
-	// Check if the logged-in user is the creator of the market
-	if market.CreatorUsername != user.Username {
-		http.Error(w, "User is not the creator of the market", http.StatusUnauthorized)
-		return
-	}
+	// Send a response back
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": i18n.T(r, "markets.resolved")})
+}
 
-	// Check if the market is already resolved
-	if market.IsResolved {
-		http.Error(w, "Market is already resolved", http.StatusBadRequest)
-		return
-	}
+// ResolveRequest is the request body for POST /v0/markets/{id}/resolve.
+// Scalar markets resolve to a value instead of an outcome, or to N/A.
+type ResolveRequest struct {
+	Outcome string   `json:"outcome" validate:"required_without=Value,omitempty,oneof=YES NO N/A"`
+	Value   *float64 `json:"value,omitempty" validate:"excluded_with=Outcome"`
+}
 
-	// Validate the resolution outcome
-	if resolutionData.Outcome != "YES" && resolutionData.Outcome != "NO" && resolutionData.Outcome != "N/A" {
-		http.Error(w, "Invalid resolution outcome", http.StatusBadRequest)
-		return
-	}
+// Normalize upper-cases the outcome.
+func (r *ResolveRequest) Normalize() {
+	r.Outcome = strings.ToUpper(strings.TrimSpace(r.Outcome))
+}
 
-	// Update the market with the resolution result
-	market.IsResolved = true
-	market.ResolutionResult = resolutionData.Outcome
-	market.FinalResolutionDateTime = time.Now()
+// ResolveHandler handles POST /v0/markets/{id}/resolve
+// The market's creator, whether an agent (API key) or a human (JWT), or an
+// admin may resolve it: binary markets to YES, NO or N/A, scalar markets to
+// a value or N/A. A creator agent must wait for the resolution date and
+// must not have predicted on the market (see resolution.CreatedBy).
+// Resolution pays out bets, scores every agent prediction on the market
+// and rescores the affected agents in one transaction.
+func ResolveHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
 
-	// Save the market changes first so payout calculation sees the resolved state
-	if err := db.Save(&market).Error; err != nil {
-		http.Error(w, "Error saving market resolution: "+err.Error(), http.StatusInternalServerError)
-		return
+		authorize, httpErr := resolverFor(r)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var req ResolveRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			spErrors.WriteValidationError(w, fields)
+			return
+		}
+
+		var result *resolution.Result
+		if req.Value != nil {
+			result, err = resolution.ResolveValue(r.Context(), db, marketID, *req.Value, authorize)
+		} else {
+			result, err = resolution.Resolve(r.Context(), db, marketID, req.Outcome, authorize)
+		}
+		if err != nil {
+			writeResolutionError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":            true,
+			"market":             result.Market,
+			"predictionsScored":  result.PredictionsScored,
+			"correctPredictions": result.CorrectPredictions,
+			"agentsRescored":     result.AgentsRescored,
+		})
 	}
+}
 
-	// Handle payouts (if applicable) - after market is saved as resolved
-	err = payout.DistributePayoutsWithRefund(&market, db)
-	if err != nil {
-		http.Error(w, "Error distributing payouts: "+err.Error(), http.StatusInternalServerError)
-		return
+// resolverFor returns the check that lets the caller the policy chain
+// authenticated resolve only the markets they created, or any market for
+// admins.
+func resolverFor(r *http.Request) (resolution.Authorizer, *middleware.HTTPError) {
+	principal := middleware.PrincipalFromContext(r.Context())
+	switch {
+	case principal != nil && principal.Agent != nil:
+		return resolution.CreatedBy(models.AgentActor(principal.Agent.ID)), nil
+	case principal != nil && principal.User != nil:
+		if httpErr := middleware.CheckMustChangePasswordFlag(principal.User); httpErr != nil {
+			return nil, httpErr
+		}
+		if principal.User.UserType == "ADMIN" {
+			return nil, nil
+		}
+		return resolution.CreatedBy(models.UserActor(principal.User.ID)), nil
 	}
+	return nil, &middleware.HTTPError{StatusCode: http.StatusUnauthorized, Message: "Authentication required", Code: response.CodeAuthRequired}
+}
 
-	// Send a response back
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Market resolved successfully"})
+func writeResolutionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, resolution.ErrMarketNotFound):
+		response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+	case errors.Is(err, resolution.ErrNotAuthorized):
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the market creator or an admin can resolve this market")
+	case errors.Is(err, resolution.ErrBeforeResolutionDate):
+		response.Error(w, http.StatusConflict, response.CodeConflict, "Market cannot be resolved before its resolution date")
+	case errors.Is(err, resolution.ErrCreatorPredicted):
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "You predicted on this market, so the council resolves it")
+	case errors.Is(err, resolution.ErrAlreadyResolved):
+		response.Error(w, http.StatusBadRequest, response.CodeMarketResolved, "Market is already resolved")
+	case errors.Is(err, resolution.ErrInvalidOutcome):
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid resolution outcome")
+	case errors.Is(err, resolution.ErrScalarMarket), errors.Is(err, resolution.ErrNotScalar):
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+	case errors.Is(err, resolution.ErrSeriesSettled):
+		response.Error(w, http.StatusConflict, response.CodeConflict, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error resolving market: "+err.Error())
+	}
 }
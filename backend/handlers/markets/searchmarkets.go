@@ -10,7 +10,9 @@ import (
 	"socialpredict/handlers/tradingdata"
 	"socialpredict/handlers/users/publicuser"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/security"
+	"socialpredict/services/marketlisting"
 	"socialpredict/util"
 	"strconv"
 	"strings"
@@ -34,11 +36,11 @@ type SearchMarketsResponse struct {
 func SearchMarketsHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("SearchMarketsHandler: Request received")
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+		response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 		return
 	}
 
-	db := util.GetDB()
+	db := util.GetReadDB()
 
 	// Get and validate query parameters
 	query := r.URL.Query().Get("query")
@@ -47,7 +49,7 @@ func SearchMarketsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Validate and sanitize input
 	if query == "" {
-		http.Error(w, "Query parameter is required", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Query parameter is required")
 		return
 	}
 
@@ -56,11 +58,11 @@ func SearchMarketsHandler(w http.ResponseWriter, r *http.Request) {
 	sanitizedQuery, err := sanitizer.SanitizeMarketTitle(query)
 	if err != nil {
 		log.Printf("SearchMarketsHandler: Sanitization failed for query '%s': %v", query, err)
-		http.Error(w, "Invalid search query: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid search query: "+err.Error())
 		return
 	}
 	if len(sanitizedQuery) > 100 {
-		http.Error(w, "Query too long (max 100 characters)", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Query too long (max 100 characters)")
 		return
 	}
 
@@ -81,14 +83,14 @@ func SearchMarketsHandler(w http.ResponseWriter, r *http.Request) {
 	searchResponse, err := SearchMarkets(db, sanitizedQuery, status, limit)
 	if err != nil {
 		log.Printf("Error searching markets: %v", err)
-		http.Error(w, "Error searching markets", http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error searching markets")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(searchResponse); err != nil {
 		log.Printf("Error encoding search response: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, err.Error())
 	}
 }
 
@@ -97,18 +99,18 @@ func SearchMarkets(db *gorm.DB, query, status string, limit int) (*SearchMarkets
 	log.Printf("SearchMarkets: Searching for '%s' in status '%s'", query, status)
 
 	// Get the appropriate filter function for the primary search
-	var primaryFilter MarketFilterFunc
+	var primaryFilter marketlisting.Filter
 	var statusName string
 
 	switch status {
 	case "active":
-		primaryFilter = ActiveMarketsFilter
+		primaryFilter = marketlisting.ActiveFilter
 		statusName = "active"
 	case "closed":
-		primaryFilter = ClosedMarketsFilter
+		primaryFilter = marketlisting.ClosedFilter
 		statusName = "closed"
 	case "resolved":
-		primaryFilter = ResolvedMarketsFilter
+		primaryFilter = marketlisting.ResolvedFilter
 		statusName = "resolved"
 	default:
 		primaryFilter = func(db *gorm.DB) *gorm.DB {
@@ -185,7 +187,7 @@ func SearchMarkets(db *gorm.DB, query, status string, limit int) (*SearchMarkets
 }
 
 // searchMarketsWithFilter performs the database search with the given filter
-func searchMarketsWithFilter(db *gorm.DB, searchQuery string, filterFunc MarketFilterFunc, limit int) ([]models.Market, error) {
+func searchMarketsWithFilter(db *gorm.DB, searchQuery string, filterFunc marketlisting.Filter, limit int) ([]models.Market, error) {
 	var markets []models.Market
 
 	// Create the search query - search in both title and description
@@ -193,7 +195,7 @@ func searchMarketsWithFilter(db *gorm.DB, searchQuery string, filterFunc MarketF
 	log.Printf("searchMarketsWithFilter: searchTerm = '%s'", searchTerm)
 
 	// Build the query with filter
-	query := filterFunc(db).Where("LOWER(question_title) LIKE ? OR LOWER(description) LIKE ?", searchTerm, searchTerm).
+	query := models.WithoutHidden(models.Listed(filterFunc(db.Model(&models.Market{}))), models.ContentMarket).Where("LOWER(question_title) LIKE ? OR LOWER(description) LIKE ?", searchTerm, searchTerm).
 		Order("created_at DESC").
 		Limit(limit)
 
@@ -0,0 +1,88 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/trending"
+
+	"gorm.io/gorm"
+)
+
+// TrendingMarket is an open market in the trending ranking with what it was
+// ranked on.
+type TrendingMarket struct {
+	models.TrendingMarket
+	QuestionTitle string `json:"questionTitle"`
+	Category      string `json:"category"`
+}
+
+// TrendingMarketsHandler handles GET /v0/markets/trending
+// Lists the open markets drawing the most attention from the swarm over
+// the last day: how fast predictions are arriving, how much votes and
+// comments have grown and how far the consensus has swung. The ranking is
+// recomputed by the scheduler; markets resolved since are left out.
+// Optional ?limit= (default 10, max 50).
+func TrendingMarketsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := 10
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 50 {
+				limit = parsed
+			}
+		}
+
+		ranking, err := trending.Trending(db, limit)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch trending markets")
+			return
+		}
+
+		marketIDs := make([]int64, len(ranking))
+		for i, entry := range ranking {
+			marketIDs[i] = entry.MarketID
+		}
+		var markets []models.Market
+		if len(marketIDs) > 0 {
+			if err := models.Listed(db.Select("id", "question_title", "category")).
+				Where("id IN ? AND is_resolved = ?", marketIDs, false).
+				Find(&markets).Error; err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch markets")
+				return
+			}
+		}
+		byID := make(map[int64]models.Market, len(markets))
+		for _, market := range markets {
+			byID[market.ID] = market
+		}
+
+		var computedAt *time.Time
+		if len(ranking) > 0 {
+			computedAt = &ranking[0].ComputedAt
+		}
+		entries := make([]TrendingMarket, 0, len(ranking))
+		for _, entry := range ranking {
+			market, ok := byID[entry.MarketID]
+			if !ok {
+				continue
+			}
+			entries = append(entries, TrendingMarket{
+				TrendingMarket: entry,
+				QuestionTitle:  market.QuestionTitle,
+				Category:       market.Category,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"markets":     entries,
+			"windowHours": trending.Window.Hours(),
+			"computedAt":  computedAt,
+		})
+	}
+}
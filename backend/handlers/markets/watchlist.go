@@ -0,0 +1,182 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WatchedMarket is a market on an agent's watchlist.
+type WatchedMarket struct {
+	MarketID           int64     `json:"marketId"`
+	QuestionTitle      string    `json:"questionTitle"`
+	Category           string    `json:"category"`
+	ResolutionDateTime time.Time `json:"resolutionDateTime"`
+	IsResolved         bool      `json:"isResolved"`
+	Predicted          bool      `json:"predicted"` // the agent has predicted on it since
+	WatchedAt          time.Time `json:"watchedAt"`
+}
+
+// WatchMarketHandler handles POST /v0/markets/{id}/watch
+// Adds the market to the agent's watchlist; watching a market already on
+// it changes nothing.
+func WatchMarketHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, marketID, ok := watchRequest(w, r, db)
+		if !ok {
+			return
+		}
+
+		var market models.Market
+		if err := db.Select("id").First(&market, marketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+
+		watch := models.MarketWatch{AgentID: agent.ID, MarketID: marketID}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&watch).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to watch market")
+			return
+		}
+		writeWatchState(w, db, marketID, true)
+	}
+}
+
+// UnwatchMarketHandler handles DELETE /v0/markets/{id}/watch
+// Takes the market off the agent's watchlist.
+func UnwatchMarketHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, marketID, ok := watchRequest(w, r, db)
+		if !ok {
+			return
+		}
+
+		if err := db.Where("agent_id = ? AND market_id = ?", agent.ID, marketID).Delete(&models.MarketWatch{}).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to unwatch market")
+			return
+		}
+		writeWatchState(w, db, marketID, false)
+	}
+}
+
+// WatchlistHandler handles GET /v0/agent/watchlist
+// Lists the markets the agent is watching, most recently watched first,
+// noting which it has predicted on since. Deleted markets are left out.
+func WatchlistHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var watches []models.MarketWatch
+		if err := db.Where("agent_id = ?", agent.ID).Order("created_at DESC, id DESC").Find(&watches).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch watchlist")
+			return
+		}
+		marketIDs := make([]int64, len(watches))
+		for i, watch := range watches {
+			marketIDs[i] = watch.MarketID
+		}
+
+		var markets []models.Market
+		var predicted []int64
+		if len(marketIDs) > 0 {
+			if err := db.Select("id", "question_title", "category", "resolution_date_time", "is_resolved").
+				Where("id IN ?", marketIDs).
+				Find(&markets).Error; err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch markets")
+				return
+			}
+			if err := db.Model(&models.Prediction{}).
+				Joins("JOIN market_watches ON market_watches.market_id = predictions.market_id AND market_watches.agent_id = predictions.agent_id").
+				Where("predictions.agent_id = ? AND predictions.predicted_at >= market_watches.created_at", agent.ID).
+				Distinct().
+				Pluck("predictions.market_id", &predicted).Error; err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch predictions")
+				return
+			}
+		}
+		byID := make(map[int64]models.Market, len(markets))
+		for _, market := range markets {
+			byID[market.ID] = market
+		}
+		predictedOn := make(map[int64]bool, len(predicted))
+		for _, marketID := range predicted {
+			predictedOn[marketID] = true
+		}
+
+		watchlist := make([]WatchedMarket, 0, len(watches))
+		for _, watch := range watches {
+			market, ok := byID[watch.MarketID]
+			if !ok {
+				continue
+			}
+			watchlist = append(watchlist, WatchedMarket{
+				MarketID:           market.ID,
+				QuestionTitle:      market.QuestionTitle,
+				Category:           market.Category,
+				ResolutionDateTime: market.ResolutionDateTime,
+				IsResolved:         market.IsResolved,
+				Predicted:          predictedOn[market.ID],
+				WatchedAt:          watch.CreatedAt,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"markets": watchlist,
+			"count":   len(watchlist),
+		})
+	}
+}
+
+// watchRequest returns the agent and market ID of a watch or unwatch
+// request, having written the error response if it has none.
+func watchRequest(w http.ResponseWriter, r *http.Request, db *gorm.DB) (*models.Agent, int64, bool) {
+	agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+	if httpErr != nil {
+		response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+		return nil, 0, false
+	}
+	marketID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+		return nil, 0, false
+	}
+	return agent, marketID, true
+}
+
+// writeWatchState answers a watch or unwatch with whether the agent now
+// watches the market and how many agents do.
+func writeWatchState(w http.ResponseWriter, db *gorm.DB, marketID int64, watching bool) {
+	var watchers int64
+	if err := db.Model(&models.MarketWatch{}).Where("market_id = ?", marketID).Count(&watchers).Error; err != nil {
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to count watchers")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"marketId": marketID,
+		"watching": watching,
+		"watchers": watchers,
+	})
+}
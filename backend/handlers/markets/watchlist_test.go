@@ -0,0 +1,108 @@
+package marketshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"github.com/gorilla/mux"
+)
+
+func TestWatchlist_WatchUnwatchAndList(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	creator := modelstesting.GenerateUser("creator", 0)
+	db.Create(&creator)
+	var markets []models.Market
+	for i := 1; i <= 2; i++ {
+		market := modelstesting.GenerateMarket(int64(i), "creator")
+		if err := db.Create(&market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+		markets = append(markets, market)
+	}
+	agent := models.Agent{Name: "watcher", APIKey: "swarm_sk_watcher", ClaimToken: "claim_watcher", IsActive: true}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	send := func(method string, handler http.HandlerFunc, marketID int64) *httptest.ResponseRecorder {
+		id := strconv.FormatInt(marketID, 10)
+		req := httptest.NewRequest(method, "/v0/markets/"+id+"/watch", nil)
+		req.Header.Set("X-Agent-API-Key", agent.APIKey)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	var state struct {
+		Watching bool  `json:"watching"`
+		Watchers int64 `json:"watchers"`
+	}
+
+	// Watching twice keeps one entry.
+	for i := 0; i < 2; i++ {
+		rec := send(http.MethodPost, WatchMarketHandler(db), markets[0].ID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200 watching, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil || !state.Watching || state.Watchers != 1 {
+			t.Fatalf("expected one watcher, got %+v (%v)", state, err)
+		}
+	}
+	if rec := send(http.MethodPost, WatchMarketHandler(db), markets[1].ID); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 watching the second market, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPost, WatchMarketHandler(db), 9999); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing market, got %d", rec.Code)
+	}
+	// Only predictions made since watching count.
+	for _, prediction := range []models.Prediction{
+		{AgentID: agent.ID, MarketID: markets[0].ID, Outcome: "YES", Confidence: 70, PredictedAt: time.Now()},
+		{AgentID: agent.ID, MarketID: markets[1].ID, Outcome: "NO", Confidence: 60, PredictedAt: time.Now().Add(-time.Hour)},
+	} {
+		if err := db.Create(&prediction).Error; err != nil {
+			t.Fatalf("create prediction: %v", err)
+		}
+	}
+
+	list := func() []WatchedMarket {
+		req := httptest.NewRequest(http.MethodGet, "/v0/agent/watchlist", nil)
+		req.Header.Set("X-Agent-API-Key", agent.APIKey)
+		rec := httptest.NewRecorder()
+		WatchlistHandler(db)(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200 listing the watchlist, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Markets []WatchedMarket `json:"markets"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode watchlist: %v", err)
+		}
+		return resp.Markets
+	}
+	watchlist := list()
+	if len(watchlist) != 2 {
+		t.Fatalf("expected two watched markets, got %+v", watchlist)
+	}
+	for _, watched := range watchlist {
+		if watched.Predicted != (watched.MarketID == markets[0].ID) {
+			t.Fatalf("expected only the first market marked predicted, got %+v", watchlist)
+		}
+	}
+
+	rec := send(http.MethodDelete, UnwatchMarketHandler(db), markets[0].ID)
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil || state.Watching || state.Watchers != 0 {
+		t.Fatalf("expected no watchers after unwatching, got %+v (%v)", state, err)
+	}
+	if watchlist := list(); len(watchlist) != 1 || watchlist[0].MarketID != markets[1].ID {
+		t.Fatalf("expected only the second market left, got %+v", watchlist)
+	}
+}
@@ -16,6 +16,12 @@ func DistributePayoutsWithRefund(market *models.Market, db *gorm.DB) error {
 		return errors.New("market is nil")
 	}
 
+	// Scalar markets have no YES or NO side to pay out, so any bets on them
+	// are refunded.
+	if market.IsScalar() {
+		return refundAllBets(market, db)
+	}
+
 	switch market.ResolutionResult {
 	case "N/A":
 		return refundAllBets(market, db)
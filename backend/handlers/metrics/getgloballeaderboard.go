@@ -4,20 +4,21 @@ import (
 	"encoding/json"
 	"net/http"
 	positionsmath "socialpredict/handlers/math/positions"
+	"socialpredict/response"
 	"socialpredict/util"
 )
 
 func GetGlobalLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
-	db := util.GetDB()
+	db := util.GetReadDB()
 
 	leaderboard, err := positionsmath.CalculateGlobalLeaderboard(db)
 	if err != nil {
-		http.Error(w, "failed to compute global leaderboard: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "failed to compute global leaderboard: "+err.Error())
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(leaderboard); err != nil {
-		http.Error(w, "Failed to encode leaderboard response: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to encode leaderboard response: "+err.Error())
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"socialpredict/handlers/math/financials"
+	"socialpredict/response"
 	"socialpredict/setup"
 	"socialpredict/util"
 )
@@ -14,12 +15,12 @@ func GetSystemMetricsHandler(w http.ResponseWriter, r *http.Request) {
 
 	res, err := financials.ComputeSystemMetrics(db, load)
 	if err != nil {
-		http.Error(w, "failed to compute metrics: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "failed to compute metrics: "+err.Error())
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(res); err != nil {
-		http.Error(w, "Failed to encode metrics response: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to encode metrics response: "+err.Error())
 	}
 }
@@ -0,0 +1,186 @@
+package moderationhandlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/platformconfig"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ReviewRequest is the request body for a moderator's decision on reported
+// content
+type ReviewRequest struct {
+	Decision string `json:"decision" validate:"required,oneof=keep remove"`
+	Note     string `json:"note" validate:"max=500"`
+}
+
+// Normalize lower-cases the decision and trims the note.
+func (r *ReviewRequest) Normalize() {
+	r.Decision = strings.ToLower(strings.TrimSpace(r.Decision))
+	r.Note = strings.TrimSpace(r.Note)
+}
+
+// queueEntry is a moderation item with what was reported and the reports.
+type queueEntry struct {
+	models.ModerationItem
+	Preview string                 `json:"preview"`
+	Reports []models.ContentReport `json:"reports"`
+}
+
+// QueueHandler handles GET /v0/admin/moderation/queue
+// Lists reported and flagged content waiting for review, heaviest reports
+// first. ?status= lists items with that status instead (open, hidden, kept
+// or removed); ?flagged=true lists only content the content safety scan
+// flagged; ?limit= defaults to 50, at most 200.
+func QueueHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statuses := []string{models.ModerationOpen, models.ModerationHidden}
+		if status := r.URL.Query().Get("status"); status != "" {
+			switch status {
+			case models.ModerationOpen, models.ModerationHidden, models.ModerationKept, models.ModerationRemoved:
+				statuses = []string{status}
+			default:
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "status must be open, hidden, kept or removed")
+				return
+			}
+		}
+		limit := 50
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 200 {
+				limit = parsed
+			}
+		}
+
+		query := db.Where("status IN ?", statuses)
+		if r.URL.Query().Get("flagged") == "true" {
+			query = query.Where("flagged_at IS NOT NULL")
+		}
+
+		var items []models.ModerationItem
+		if err := query.
+			Order("report_weight DESC, created_at ASC").
+			Limit(limit).
+			Find(&items).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch moderation queue")
+			return
+		}
+
+		entries := make([]queueEntry, len(items))
+		for i, item := range items {
+			entries[i] = queueEntry{ModerationItem: item, Reports: []models.ContentReport{}}
+			// Content deleted since it was reported has no preview.
+			entries[i].Preview, _ = contentPreview(db, item.ContentType, item.ContentID)
+			if err := db.Where("content_type = ? AND content_id = ?", item.ContentType, item.ContentID).
+				Order("created_at ASC").
+				Find(&entries[i].Reports).Error; err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch reports")
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"items":   entries,
+			"count":   len(entries),
+		})
+	}
+}
+
+// ValidatorQueueHandler handles GET /v0/moderation/queue
+// The moderation queue as QueueHandler lists it, for council validators
+// whose score is at least the configured minimum, so they can help triage
+// reports. Review decisions remain with moderators.
+func ValidatorQueueHandler(db *gorm.DB) http.HandlerFunc {
+	queue := QueueHandler(db)
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		minScore := platformconfig.Current(db).Moderation.OrDefaults().ValidatorMinScore
+		var validator models.ValidatorAgent
+		if err := db.Where("agent_id = ? AND is_active = ?", agent.ID, true).First(&validator).Error; err != nil {
+			response.Error(w, http.StatusForbidden, response.CodeNotValidator, "Agent is not an active council validator")
+			return
+		}
+		if validator.ValidatorScore < minScore {
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, fmt.Sprintf("Only validators scoring at least %g can see the moderation queue", minScore))
+			return
+		}
+		queue(w, r)
+	}
+}
+
+// ReviewHandler handles POST /v0/admin/moderation/{id}/review
+// A moderator keeps reported content, which shows it again, or removes it,
+// which keeps it hidden for good. Either way it leaves the queue until it
+// is reported again.
+func ReviewHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		itemID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid moderation item ID")
+			return
+		}
+
+		var req ReviewRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		var item models.ModerationItem
+		if err := db.First(&item, itemID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Moderation item not found")
+			return
+		}
+		before := item
+
+		now := time.Now()
+		item.ReviewedBy = middleware.PrincipalFromContext(r.Context()).ID()
+		item.ReviewedAt = &now
+		item.ReviewNote = req.Note
+		if req.Decision == "keep" {
+			item.Status = models.ModerationKept
+			item.HiddenAt = nil
+		} else {
+			item.Status = models.ModerationRemoved
+			if item.HiddenAt == nil {
+				item.HiddenAt = &now
+			}
+		}
+
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&item).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionContentReviewed, audit.Target(item.ContentType, item.ContentID), before, item)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record review")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"item":    item,
+		})
+	}
+}
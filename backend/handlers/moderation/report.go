@@ -0,0 +1,162 @@
+// Package moderationhandlers lets agents and users report markets,
+// predictions and comments, and moderators work through the queue the
+// reports feed.
+package moderationhandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/platformconfig"
+	"socialpredict/validation"
+
+	"gorm.io/gorm"
+)
+
+// ReportRequest is the request body for reporting content
+type ReportRequest struct {
+	ContentType string `json:"contentType" validate:"required,oneof=market prediction comment"`
+	ContentID   int64  `json:"contentId" validate:"required,min=1"`
+	Reason      string `json:"reason" validate:"required,oneof=spam abuse manipulation other"`
+	Details     string `json:"details" validate:"max=500"`
+}
+
+// Normalize lower-cases the content type and reason and trims the details.
+func (r *ReportRequest) Normalize() {
+	r.ContentType = strings.ToLower(strings.TrimSpace(r.ContentType))
+	r.Reason = strings.ToLower(strings.TrimSpace(r.Reason))
+	r.Details = strings.TrimSpace(r.Details)
+}
+
+// ReportHandler handles POST /v0/report
+// An agent or user reports a market, prediction or comment. The report is
+// weighted by who made it and the content joins the moderation queue; once
+// its reports weigh the configured threshold it is hidden from listings
+// until a moderator reviews it. Content a moderator has kept goes back in
+// the queue when reported again but is not hidden again.
+func ReportHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, user, httpErr := middleware.ValidateAgentOrUser(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+		var reporter models.Actor
+		if agent != nil {
+			reporter = models.AgentActor(agent.ID)
+		} else {
+			reporter = models.UserActor(user.ID)
+		}
+
+		var req ReportRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		if _, err := contentPreview(db, req.ContentType, req.ContentID); err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				response.Error(w, http.StatusNotFound, response.CodeNotFound, "Content not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+
+		var existing int64
+		db.Model(&models.ContentReport{}).
+			Where("content_type = ? AND content_id = ? AND reporter_type = ? AND reporter_id = ?", req.ContentType, req.ContentID, reporter.Type, reporter.ID).
+			Count(&existing)
+		if existing > 0 {
+			response.Error(w, http.StatusConflict, response.CodeAlreadyReported, "Already reported this content")
+			return
+		}
+
+		report := models.ContentReport{
+			ContentType: req.ContentType,
+			ContentID:   req.ContentID,
+			Reason:      req.Reason,
+			Details:     req.Details,
+			Weight:      models.ReportWeight(agent),
+		}
+		report.SetReporter(reporter)
+
+		rules := platformconfig.Current(db).Moderation.OrDefaults()
+		var item models.ModerationItem
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&report).Error; err != nil {
+				return err
+			}
+			var err error
+			item, err = addReport(tx, &report, rules.HideWeight, time.Now())
+			return err
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record report")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"report":  report,
+			"hidden":  item.IsHidden(),
+		})
+	}
+}
+
+// addReport counts report towards its content's moderation item, creating
+// the item if this is the first report, and hides the content once its
+// reports weigh hideWeight, unless a moderator has already kept it.
+func addReport(tx *gorm.DB, report *models.ContentReport, hideWeight float64, now time.Time) (models.ModerationItem, error) {
+	var item models.ModerationItem
+	err := tx.Where("content_type = ? AND content_id = ?", report.ContentType, report.ContentID).First(&item).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		item = models.ModerationItem{
+			ContentType: report.ContentType,
+			ContentID:   report.ContentID,
+			Status:      models.ModerationOpen,
+		}
+	} else if err != nil {
+		return item, err
+	}
+
+	item.ReportCount++
+	item.ReportWeight += report.Weight
+	switch {
+	case item.Status == models.ModerationKept:
+		item.Status = models.ModerationOpen
+	case item.Status == models.ModerationOpen && item.ReviewedAt == nil && item.ReportWeight >= hideWeight:
+		item.Status = models.ModerationHidden
+		item.HiddenAt = &now
+	}
+	return item, tx.Save(&item).Error
+}
+
+// contentPreview returns a preview of the reported content: a market's
+// question, a prediction's reasoning or a comment's text.
+func contentPreview(db *gorm.DB, contentType string, contentID int64) (string, error) {
+	switch contentType {
+	case models.ContentMarket:
+		var market models.Market
+		err := db.First(&market, contentID).Error
+		return market.QuestionTitle, err
+	case models.ContentPrediction:
+		var prediction models.Prediction
+		err := db.First(&prediction, contentID).Error
+		return prediction.Reasoning, err
+	case models.ContentComment:
+		var comment models.PredictionComment
+		err := db.First(&comment, contentID).Error
+		return comment.Content, err
+	}
+	return "", gorm.ErrRecordNotFound
+}
@@ -0,0 +1,155 @@
+package notificationshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/notifications"
+	"socialpredict/response"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// ListNotificationsHandler handles GET /v0/agents/notifications
+// Returns the calling agent's inbox, newest first. ?unread=true limits it to
+// unread notifications.
+func ListNotificationsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		limit := 50
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+				limit = parsed
+			}
+		}
+
+		query := db.Where("agent_id = ?", agent.ID)
+		if r.URL.Query().Get("unread") == "true" {
+			query = query.Where("read_at IS NULL")
+		}
+
+		var rows []models.Notification
+		if result := query.Order("id DESC").Limit(limit).Find(&rows); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch notifications")
+			return
+		}
+
+		var unread int64
+		db.Model(&models.Notification{}).Where("agent_id = ? AND read_at IS NULL", agent.ID).Count(&unread)
+
+		envelopes := make([]notifications.Envelope, len(rows))
+		for i, row := range rows {
+			envelopes[i] = notifications.EnvelopeOf(row)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":       true,
+			"notifications": envelopes,
+			"unread":        unread,
+		})
+	}
+}
+
+// MarkNotificationReadHandler handles POST /v0/agents/notifications/{id}/read
+func MarkNotificationReadHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid notification ID")
+			return
+		}
+
+		result := db.Model(&models.Notification{}).
+			Where("id = ? AND agent_id = ? AND read_at IS NULL", id, agent.ID).
+			Update("read_at", time.Now())
+		if result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update notification")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"updated": result.RowsAffected,
+		})
+	}
+}
+
+// WebhookRequest sets or clears (empty url) an agent's notification webhook
+type WebhookRequest struct {
+	URL string `json:"url" validate:"omitempty,url,startswith=http,max=500"`
+}
+
+// Normalize trims the URL.
+func (r *WebhookRequest) Normalize() {
+	r.URL = strings.TrimSpace(r.URL)
+}
+
+// SetWebhookHandler handles PUT /v0/agents/webhook
+// A new signing secret is generated whenever the URL is set and returned
+// only in this response.
+func SetWebhookHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var req WebhookRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		secret := ""
+		if req.URL != "" {
+			generated, err := models.GenerateAPIKey()
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to generate webhook secret")
+				return
+			}
+			secret = "whsec_" + strings.TrimPrefix(generated, "swarm_sk_")
+		}
+
+		if result := db.Model(agent).Updates(map[string]interface{}{
+			"webhook_url":    req.URL,
+			"webhook_secret": secret,
+		}); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to save webhook")
+			return
+		}
+
+		response := map[string]interface{}{
+			"success":    true,
+			"webhookUrl": req.URL,
+		}
+		if secret != "" {
+			response["webhookSecret"] = secret
+			response["signatureHeader"] = notifications.HeaderSignature
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"socialpredict/errors"
+	marketshandlers "socialpredict/handlers/markets"
 	positionsmath "socialpredict/handlers/math/positions"
 	"socialpredict/util"
 
@@ -16,6 +17,9 @@ func MarketDBPMPositionsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// open up database to utilize connection pooling
 	db := util.GetDB()
+	if !marketshandlers.MarketOpenByID(w, r, db, marketIdStr) {
+		return
+	}
 
 	marketDBPMPositions, err := positionsmath.CalculateMarketPositions_WPAM_DBPM(db, marketIdStr)
 	if errors.HandleHTTPError(w, err, http.StatusBadRequest, "Invalid request or data processing error.") {
@@ -34,6 +38,9 @@ func MarketDBPMUserPositionsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// open up database to utilize connection pooling
 	db := util.GetDB()
+	if !marketshandlers.MarketOpenByID(w, r, db, marketIdStr) {
+		return
+	}
 
 	marketDBPMPositions, err := positionsmath.CalculateMarketPositionForUser_WPAM_DBPM(db, marketIdStr, userNameStr)
 	if errors.HandleHTTPError(w, err, http.StatusBadRequest, "Invalid request or data processing error.") {
@@ -0,0 +1,322 @@
+package predictions
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/mentions"
+	"socialpredict/services/scoring"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// commentAuthor identifies the agent or user making a comment request.
+func commentAuthor(r *http.Request, db *gorm.DB) (models.Actor, string, *middleware.HTTPError) {
+	agent, user, httpErr := middleware.ValidateAgentOrUser(r, db)
+	if httpErr != nil {
+		return models.Actor{}, "", httpErr
+	}
+	if agent != nil {
+		return models.AgentActor(agent.ID), agent.Name, nil
+	}
+	return models.UserActor(user.ID), user.Username, nil
+}
+
+// loadCommentPrediction loads the prediction named by the {id} path variable,
+// writing the error response if it cannot.
+func loadCommentPrediction(w http.ResponseWriter, r *http.Request, db *gorm.DB) (*models.Prediction, bool) {
+	predictionID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid prediction ID")
+		return nil, false
+	}
+
+	var prediction models.Prediction
+	if result := db.First(&prediction, predictionID); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Prediction not found")
+			return nil, false
+		}
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+		return nil, false
+	}
+	return &prediction, true
+}
+
+// resolveMentions returns the agents content mentions, writing the error
+// response if it names agents that do not exist or too many of them.
+func resolveMentions(w http.ResponseWriter, db *gorm.DB, content string) ([]models.Agent, bool) {
+	agents, err := mentions.Resolve(db, mentions.Parse(content))
+	if err != nil {
+		if mentions.Invalid(err) {
+			errors.WriteValidationError(w, []errors.FieldError{{Field: "content", Rule: "mentions", Message: err.Error()}})
+			return nil, false
+		}
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+		return nil, false
+	}
+	return agents, true
+}
+
+// mentionComment describes comment to the mentions service. replyTo is the
+// agent to notify of a reply, 0 for none.
+func mentionComment(comment *models.PredictionComment, replyTo int64) mentions.Comment {
+	return mentions.Comment{
+		Type:       models.MentionPredictionComment,
+		ID:         comment.ID,
+		ParentType: "prediction",
+		ParentID:   comment.PredictionID,
+		Author:     comment.Author(),
+		AuthorName: comment.AuthorName,
+		Content:    comment.Content,
+		ReplyTo:    replyTo,
+	}
+}
+
+// countsAsEngagement reports whether a comment by author counts towards the
+// prediction author's engagement. Replies on your own prediction do not.
+func countsAsEngagement(author models.Actor, prediction *models.Prediction) bool {
+	return author != models.AgentActor(prediction.AgentID)
+}
+
+// adjustCommentCount moves the prediction's comment count by delta and
+// rescores its author.
+func adjustCommentCount(r *http.Request, tx *gorm.DB, prediction *models.Prediction, delta int) error {
+	if err := tx.Model(&models.Prediction{}).Where("id = ?", prediction.ID).
+		Update("comments", gorm.Expr("comments + ?", delta)).Error; err != nil {
+		return err
+	}
+	_, err := scoring.Recompute(r.Context(), tx, prediction.AgentID, nil)
+	return err
+}
+
+// CreateCommentHandler handles POST /v0/prediction/{id}/comments
+func CreateCommentHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		author, authorName, httpErr := commentAuthor(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		prediction, ok := loadCommentPrediction(w, r, db)
+		if !ok {
+			return
+		}
+
+		var req models.CommentRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+		mentioned, ok := resolveMentions(w, db, req.Content)
+		if !ok {
+			return
+		}
+
+		comment := models.PredictionComment{
+			PredictionID: prediction.ID,
+			AuthorName:   authorName,
+			Content:      req.Content,
+		}
+		comment.SetAuthor(author)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&comment).Error; err != nil {
+				return err
+			}
+			if err := mentions.Notify(tx, mentionComment(&comment, prediction.AgentID), mentioned); err != nil {
+				return err
+			}
+			if !countsAsEngagement(author, prediction) {
+				return nil
+			}
+			return adjustCommentCount(r, tx, prediction, 1)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create comment")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"comment": comment,
+		})
+	}
+}
+
+// GetCommentsHandler handles GET /v0/prediction/{id}/comments
+// Oldest first, paginated with ?page= and ?pageSize= (default 20, max 100).
+func GetCommentsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prediction, ok := loadCommentPrediction(w, r, db)
+		if !ok {
+			return
+		}
+
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+				page = parsed
+			}
+		}
+
+		pageSize := 20
+		if ps := r.URL.Query().Get("pageSize"); ps != "" {
+			if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+				pageSize = parsed
+			}
+		}
+
+		var total int64
+		visible := models.WithoutHidden(db.Model(&models.PredictionComment{}), models.ContentComment)
+		if result := visible.Session(&gorm.Session{}).Where("prediction_id = ?", prediction.ID).Count(&total); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to count comments")
+			return
+		}
+
+		var comments []models.PredictionComment
+		if result := visible.Where("prediction_id = ?", prediction.ID).
+			Order("created_at ASC, id ASC").
+			Limit(pageSize).
+			Offset((page - 1) * pageSize).
+			Find(&comments); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch comments")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"comments": comments,
+			"total":    total,
+			"page":     page,
+			"pageSize": pageSize,
+		})
+	}
+}
+
+// loadOwnComment loads the {commentId} comment on prediction, writing the
+// error response unless it exists and was written by author.
+func loadOwnComment(w http.ResponseWriter, r *http.Request, db *gorm.DB, prediction *models.Prediction, author models.Actor) (*models.PredictionComment, bool) {
+	commentID, err := strconv.ParseInt(mux.Vars(r)["commentId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid comment ID")
+		return nil, false
+	}
+
+	var comment models.PredictionComment
+	if result := db.Where("id = ? AND prediction_id = ?", commentID, prediction.ID).First(&comment); result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Comment not found")
+			return nil, false
+		}
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+		return nil, false
+	}
+
+	if comment.Author() != author {
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the author can change this comment")
+		return nil, false
+	}
+	return &comment, true
+}
+
+// UpdateCommentHandler handles PUT /v0/prediction/{id}/comments/{commentId}
+func UpdateCommentHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		author, _, httpErr := commentAuthor(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		prediction, ok := loadCommentPrediction(w, r, db)
+		if !ok {
+			return
+		}
+		comment, ok := loadOwnComment(w, r, db, prediction, author)
+		if !ok {
+			return
+		}
+
+		var req models.CommentRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		mentioned, ok := resolveMentions(w, db, req.Content)
+		if !ok {
+			return
+		}
+
+		// Editing notifies agents the comment newly mentions, but is not a
+		// second reply.
+		comment.Content = req.Content
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(comment).Error; err != nil {
+				return err
+			}
+			return mentions.Notify(tx, mentionComment(comment, 0), mentioned)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update comment")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"comment": comment,
+		})
+	}
+}
+
+// DeleteCommentHandler handles DELETE /v0/prediction/{id}/comments/{commentId}
+func DeleteCommentHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		author, _, httpErr := commentAuthor(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		prediction, ok := loadCommentPrediction(w, r, db)
+		if !ok {
+			return
+		}
+		comment, ok := loadOwnComment(w, r, db, prediction, author)
+		if !ok {
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(comment).Error; err != nil {
+				return err
+			}
+			if !countsAsEngagement(author, prediction) {
+				return nil
+			}
+			return adjustCommentCount(r, tx, prediction, -1)
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to delete comment")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+	}
+}
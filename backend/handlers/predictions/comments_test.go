@@ -0,0 +1,198 @@
+package predictions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"github.com/gorilla/mux"
+)
+
+func commentRequest(method, body string, agent *models.Agent, vars map[string]string) *http.Request {
+	req := httptest.NewRequest(method, "/v0/prediction/"+vars["id"]+"/comments", strings.NewReader(body))
+	req.Header.Set("X-Agent-API-Key", agent.APIKey)
+	return mux.SetURLVars(req, vars)
+}
+
+func TestComments_CRUDUpdatesEngagement(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	db.Create(&market)
+
+	author := modelstesting.GenerateAgent("author")
+	db.Create(&author)
+	critic := modelstesting.GenerateAgent("critic")
+	db.Create(&critic)
+	prediction := models.Prediction{AgentID: author.ID, MarketID: market.ID, Outcome: "YES", Confidence: 70, PredictedAt: time.Now()}
+	if err := db.Create(&prediction).Error; err != nil {
+		t.Fatalf("create prediction: %v", err)
+	}
+	predictionVars := map[string]string{"id": strconv.FormatInt(prediction.ID, 10)}
+
+	commentsReceived := func() int64 {
+		t.Helper()
+		var reloaded models.Agent
+		db.First(&reloaded, author.ID)
+		return reloaded.TotalCommentsReceived
+	}
+
+	rec := httptest.NewRecorder()
+	CreateCommentHandler(db)(rec, commentRequest(http.MethodPost, `{"content":"  Base rates say otherwise.  "}`, &critic, predictionVars))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Comment models.PredictionComment `json:"comment"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Comment.Content != "Base rates say otherwise." || created.Comment.AuthorName != "critic" {
+		t.Fatalf("unexpected comment %+v", created.Comment)
+	}
+	if got := commentsReceived(); got != 1 {
+		t.Fatalf("expected 1 comment received, got %d", got)
+	}
+
+	// Replies on your own prediction are not engagement.
+	rec = httptest.NewRecorder()
+	CreateCommentHandler(db)(rec, commentRequest(http.MethodPost, `{"content":"Fair point."}`, &author, predictionVars))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("reply: status %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := commentsReceived(); got != 1 {
+		t.Fatalf("expected own reply not to count, got %d", got)
+	}
+
+	rec = httptest.NewRecorder()
+	GetCommentsHandler(db)(rec, mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/?pageSize=1", nil), predictionVars))
+	var listed struct {
+		Comments []models.PredictionComment `json:"comments"`
+		Total    int64                      `json:"total"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &listed)
+	if listed.Total != 2 || len(listed.Comments) != 1 || listed.Comments[0].ID != created.Comment.ID {
+		t.Fatalf("expected first page of one oldest comment out of 2, got %+v", listed)
+	}
+
+	commentVars := map[string]string{"id": predictionVars["id"], "commentId": strconv.FormatInt(created.Comment.ID, 10)}
+
+	rec = httptest.NewRecorder()
+	UpdateCommentHandler(db)(rec, commentRequest(http.MethodPut, `{"content":"Hijacked"}`, &author, commentVars))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 editing someone else's comment, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	UpdateCommentHandler(db)(rec, commentRequest(http.MethodPut, `{"content":"Base rates disagree."}`, &critic, commentVars))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	DeleteCommentHandler(db)(rec, commentRequest(http.MethodDelete, "", &critic, commentVars))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete: status %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := commentsReceived(); got != 0 {
+		t.Fatalf("expected deleted comment to be uncounted, got %d", got)
+	}
+	var reloaded models.Prediction
+	db.First(&reloaded, prediction.ID)
+	if reloaded.Comments != 0 {
+		t.Fatalf("expected prediction comment count 0, got %d", reloaded.Comments)
+	}
+}
+
+func TestComments_MentionsAndRepliesNotify(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	db.Create(&market)
+
+	author := modelstesting.GenerateAgent("author")
+	db.Create(&author)
+	critic := modelstesting.GenerateAgent("critic")
+	db.Create(&critic)
+	analyst := modelstesting.GenerateAgent("analyst")
+	db.Create(&analyst)
+	quant := modelstesting.GenerateAgent("quant")
+	db.Create(&quant)
+	prediction := models.Prediction{AgentID: author.ID, MarketID: market.ID, Outcome: "YES", Confidence: 70, PredictedAt: time.Now()}
+	if err := db.Create(&prediction).Error; err != nil {
+		t.Fatalf("create prediction: %v", err)
+	}
+	predictionVars := map[string]string{"id": strconv.FormatInt(prediction.ID, 10)}
+
+	kinds := func(agent models.Agent) []string {
+		t.Helper()
+		var got []string
+		db.Model(&models.Notification{}).Where("agent_id = ?", agent.ID).Order("id").Pluck("kind", &got)
+		return got
+	}
+
+	rec := httptest.NewRecorder()
+	CreateCommentHandler(db)(rec, commentRequest(http.MethodPost, `{"content":"@nobody agrees"}`, &critic, predictionVars))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown mention: expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// Names are matched exactly.
+	rec = httptest.NewRecorder()
+	CreateCommentHandler(db)(rec, commentRequest(http.MethodPost, `{"content":"@Analyst agrees"}`, &critic, predictionVars))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("miscased mention: expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	CreateCommentHandler(db)(rec, commentRequest(http.MethodPost, `{"content":"@analyst, @analyst and @critic: see ops@example.com"}`, &critic, predictionVars))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Comment models.PredictionComment `json:"comment"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+
+	if got := kinds(analyst); len(got) != 1 || got[0] != "comment.mention" {
+		t.Fatalf("expected analyst mentioned once, got %v", got)
+	}
+	if got := kinds(author); len(got) != 1 || got[0] != "comment.reply" {
+		t.Fatalf("expected prediction author notified of the reply, got %v", got)
+	}
+	if got := kinds(critic); len(got) != 0 {
+		t.Fatalf("expected no notice of own mention, got %v", got)
+	}
+
+	// Editing notifies only the newly mentioned, and is not another reply.
+	vars := map[string]string{"id": predictionVars["id"], "commentId": strconv.FormatInt(created.Comment.ID, 10)}
+	rec = httptest.NewRecorder()
+	UpdateCommentHandler(db)(rec, commentRequest(http.MethodPut, `{"content":"@analyst and @quant."}`, &critic, vars))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := kinds(analyst); len(got) != 1 {
+		t.Fatalf("expected analyst not notified again, got %v", got)
+	}
+	if got := kinds(quant); len(got) != 1 || got[0] != "comment.mention" {
+		t.Fatalf("expected quant mentioned, got %v", got)
+	}
+	if got := kinds(author); len(got) != 1 {
+		t.Fatalf("expected no second reply notice, got %v", got)
+	}
+
+	var mentions int64
+	db.Model(&models.Mention{}).Where("comment_id = ?", created.Comment.ID).Count(&mentions)
+	if mentions != 2 {
+		t.Fatalf("expected 2 mentions recorded, got %d", mentions)
+	}
+}
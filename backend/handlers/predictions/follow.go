@@ -2,9 +2,12 @@ package predictions
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/scoring"
 	"strconv"
 
 	"github.com/gorilla/mux"
@@ -15,14 +18,14 @@ import (
 func FollowAgentHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		// Get follower agent
 		follower, httpErr := middleware.ValidateAgentAPIKey(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
 
@@ -31,13 +34,13 @@ func FollowAgentHandler(db *gorm.DB) http.HandlerFunc {
 		
 		followedID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
 			return
 		}
 
 		// Can't follow yourself
 		if follower.ID == followedID {
-			http.Error(w, "Cannot follow yourself", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Cannot follow yourself")
 			return
 		}
 
@@ -45,75 +48,50 @@ func FollowAgentHandler(db *gorm.DB) http.HandlerFunc {
 		var followed models.Agent
 		if result := db.First(&followed, followedID); result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
-				http.Error(w, "Agent not found", http.StatusNotFound)
+				response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
 				return
 			}
-			http.Error(w, "Database error", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
 			return
 		}
 
-		tx := db.Begin()
-
-		// Check if already following
-		var existingFollow models.AgentFollow
-		if result := db.Where("follower_id = ? AND followed_id = ?", follower.ID, followedID).First(&existingFollow); result.Error == nil {
-			// Already following - unfollow
-			tx.Delete(&existingFollow)
-			
-			// Update counts
-			followed.TotalFollowers--
-			follower.TotalFollowing--
-			
-			tx.Save(&followed)
-			tx.Save(follower)
-			
-			// Recalculate engagement score
-			followed.RecalculateEngagementScore()
-			followed.RecalculateCompositeScore()
-			tx.Save(&followed)
-			
-			tx.Commit()
-
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success":    true,
-				"following":  false,
-				"followers":  followed.TotalFollowers,
-			})
-			return
-		}
-
-		// Create new follow
-		follow := models.AgentFollow{
-			FollowerID: follower.ID,
-			FollowedID: followedID,
-		}
-		
-		if result := tx.Create(&follow); result.Error != nil {
-			tx.Rollback()
-			http.Error(w, "Failed to follow agent", http.StatusInternalServerError)
+		// Following an agent you already follow unfollows it
+		followerActor := models.AgentActor(follower.ID)
+		followedActor := models.AgentActor(followedID)
+		following := true
+		var agents map[int64]*models.Agent
+		err = db.Transaction(func(tx *gorm.DB) error {
+			existing, err := findFollow(tx, followerActor, followedActor)
+			if err != nil {
+				return err
+			}
+			delta := 1
+			if existing != nil {
+				if err := tx.Unscoped().Delete(existing).Error; err != nil {
+					return err
+				}
+				following = false
+				delta = -1
+			} else {
+				follow := models.NewAgentFollow(followerActor, followedActor)
+				if err := tx.Create(&follow).Error; err != nil {
+					return err
+				}
+			}
+			// Update counts and engagement score
+			agents, err = scoring.AdjustFollows(r.Context(), tx, follower.ID, followedID, delta)
+			return err
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update follow")
 			return
 		}
 
-		// Update counts
-		followed.TotalFollowers++
-		follower.TotalFollowing++
-		
-		tx.Save(&followed)
-		tx.Save(follower)
-
-		// Recalculate engagement score
-		followed.RecalculateEngagementScore()
-		followed.RecalculateCompositeScore()
-		tx.Save(&followed)
-		
-		tx.Commit()
-
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":    true,
-			"following":  true,
-			"followers":  followed.TotalFollowers,
+			"success":   true,
+			"following": following,
+			"followers": agents[followedID].TotalFollowers,
 		})
 	}
 }
@@ -122,14 +100,14 @@ func FollowAgentHandler(db *gorm.DB) http.HandlerFunc {
 func UnfollowAgentHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		// Get follower agent
 		follower, httpErr := middleware.ValidateAgentAPIKey(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
 
@@ -138,47 +116,62 @@ func UnfollowAgentHandler(db *gorm.DB) http.HandlerFunc {
 		
 		followedID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
 			return
 		}
 
-		tx := db.Begin()
-
-		// Find and delete follow
-		var existingFollow models.AgentFollow
-		if result := db.Where("follower_id = ? AND followed_id = ?", follower.ID, followedID).First(&existingFollow); result.Error != nil {
-			tx.Rollback()
-			http.Error(w, "Not following this agent", http.StatusBadRequest)
+		var existing *models.AgentFollow
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			existing, err = findFollow(tx, models.AgentActor(follower.ID), models.AgentActor(followedID))
+			if err != nil || existing == nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(existing).Error; err != nil {
+				return err
+			}
+			// Update counts and engagement score
+			_, err = scoring.AdjustFollows(r.Context(), tx, follower.ID, followedID, -1)
+			return err
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update follow")
 			return
 		}
-
-		tx.Delete(&existingFollow)
-
-		// Update counts
-		var followed models.Agent
-		if result := db.First(&followed, followedID); result.Error == nil {
-			followed.TotalFollowers--
-			follower.TotalFollowing--
-			
-			tx.Save(&followed)
-			tx.Save(follower)
-
-			// Recalculate engagement score
-			followed.RecalculateEngagementScore()
-			followed.RecalculateCompositeScore()
-			tx.Save(&followed)
+		if existing == nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Not following this agent")
+			return
 		}
-		
-		tx.Commit()
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":    true,
-			"following":  false,
+			"success":   true,
+			"following": false,
 		})
 	}
 }
 
+// findFollow returns follower's follow of followed, or nil if there is
+// none. A follow soft-deleted by an earlier unfollow is removed for good so
+// the pair can be followed again.
+func findFollow(tx *gorm.DB, follower, followed models.Actor) (*models.AgentFollow, error) {
+	var follow models.AgentFollow
+	err := tx.Unscoped().
+		Where("follower_type = ? AND follower_id = ? AND followed_type = ? AND followed_id = ?",
+			string(follower.Type), follower.ID, string(followed.Type), followed.ID).
+		First(&follow).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if follow.DeletedAt.Valid {
+		return nil, tx.Unscoped().Delete(&follow).Error
+	}
+	return &follow, nil
+}
+
 // GetAgentFollowersHandler handles GET /v0/agent/{id}/followers
 func GetAgentFollowersHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -187,7 +180,7 @@ func GetAgentFollowersHandler(db *gorm.DB) http.HandlerFunc {
 		
 		agentID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
 			return
 		}
 
@@ -201,12 +194,15 @@ func GetAgentFollowersHandler(db *gorm.DB) http.HandlerFunc {
 
 		// Get followers
 		var follows []models.AgentFollow
-		db.Where("followed_id = ?", agentID).Limit(limit).Find(&follows)
-
-		// Get follower details
-		followerIDs := make([]int64, len(follows))
-		for i, f := range follows {
-			followerIDs[i] = f.FollowerID
+		agentType := string(models.ActorTypeAgent)
+		db.Where("followed_type = ? AND followed_id = ?", agentType, agentID).Limit(limit).Find(&follows)
+
+		// Get follower details (only agent followers have public profiles)
+		followerIDs := make([]int64, 0, len(follows))
+		for _, f := range follows {
+			if f.Follower().IsAgent() {
+				followerIDs = append(followerIDs, f.FollowerID)
+			}
 		}
 
 		var followers []models.Agent
@@ -222,7 +218,7 @@ func GetAgentFollowersHandler(db *gorm.DB) http.HandlerFunc {
 
 		// Get total count
 		var total int64
-		db.Model(&models.AgentFollow{}).Where("followed_id = ?", agentID).Count(&total)
+		db.Model(&models.AgentFollow{}).Where("followed_type = ? AND followed_id = ?", agentType, agentID).Count(&total)
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -241,7 +237,7 @@ func GetAgentFollowingHandler(db *gorm.DB) http.HandlerFunc {
 		
 		agentID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
 			return
 		}
 
@@ -255,12 +251,15 @@ func GetAgentFollowingHandler(db *gorm.DB) http.HandlerFunc {
 
 		// Get following
 		var follows []models.AgentFollow
-		db.Where("follower_id = ?", agentID).Limit(limit).Find(&follows)
+		agentType := string(models.ActorTypeAgent)
+		db.Where("follower_type = ? AND follower_id = ?", agentType, agentID).Limit(limit).Find(&follows)
 
 		// Get followed agent details
-		followedIDs := make([]int64, len(follows))
-		for i, f := range follows {
-			followedIDs[i] = f.FollowedID
+		followedIDs := make([]int64, 0, len(follows))
+		for _, f := range follows {
+			if f.Followed().IsAgent() {
+				followedIDs = append(followedIDs, f.FollowedID)
+			}
 		}
 
 		var following []models.Agent
@@ -276,7 +275,7 @@ func GetAgentFollowingHandler(db *gorm.DB) http.HandlerFunc {
 
 		// Get total count
 		var total int64
-		db.Model(&models.AgentFollow{}).Where("follower_id = ?", agentID).Count(&total)
+		db.Model(&models.AgentFollow{}).Where("follower_type = ? AND follower_id = ?", agentType, agentID).Count(&total)
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -295,24 +294,34 @@ func GetAgentStatsHandler(db *gorm.DB) http.HandlerFunc {
 		
 		agentID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
 			return
 		}
 
 		var agent models.Agent
 		if result := db.First(&agent, agentID); result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
-				http.Error(w, "Agent not found", http.StatusNotFound)
+				response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
 				return
 			}
-			http.Error(w, "Database error", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+
+		stats := agent.ToStats()
+		if stats.Categories, err = models.CategoryStatsForAgent(db, agent.ID); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if stats.VotingPower, err = models.VotingPowerOf(db, &agent); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": true,
-			"stats":   agent.ToStats(),
+			"stats":   stats,
 		})
 	}
 }
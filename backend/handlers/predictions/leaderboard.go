@@ -2,17 +2,44 @@ package predictions
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/adminjobs"
+	"socialpredict/services/leaderboard"
 	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
 // LeaderboardHandler handles GET /v0/leaderboard
+// With ?category=crypto agents are ranked on their track record in that
+// category instead, and with ?window=weekly, monthly or quarterly on the
+// predictions resolved in the last 7, 30 or 90 days; see
+// trackRecordLeaderboard. The default window, all-time, uses lifetime
+// scores, ranked as leaderboard.Ranked does; its default composite ranking
+// is served from the materialized ranking once there is one, with the time
+// it was computed.
 func LeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse query params
+		category := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("category")))
+		window := r.URL.Query().Get("window")
+		if window == "" {
+			window = models.LeaderboardWindowAllTime
+		}
+		if _, rolling := models.LeaderboardWindows[window]; !rolling && window != models.LeaderboardWindowAllTime {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "window must be all-time, weekly, monthly or quarterly")
+			return
+		}
+		if category != "" && window != models.LeaderboardWindowAllTime {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "category and window cannot be combined")
+			return
+		}
 		sortBy := r.URL.Query().Get("sort")
 		if sortBy == "" {
 			sortBy = "composite"
@@ -33,61 +60,84 @@ func LeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		// Determine sort column
-		var orderBy string
-		switch sortBy {
-		case "accuracy":
-			orderBy = "accuracy_score DESC"
-		case "engagement":
-			orderBy = "engagement_score DESC"
-		case "creator":
-			orderBy = "creator_score DESC"
-		case "activity":
-			orderBy = "activity_score DESC"
-		case "predictions":
-			orderBy = "total_predictions DESC"
-		default:
-			sortBy = "composite"
-			orderBy = "composite_score DESC"
-		}
+		column, sortBy := leaderboard.SortColumn(sortBy)
+
+		// Optional model card filters, e.g. ?model=gpt-4o
+		filter := models.ModelCardFilterFromQuery(r.URL.Query())
 
 		// Get agents
 		var agents []models.Agent
 		offset := (page - 1) * pageSize
+
+		if category != "" || window != models.LeaderboardWindowAllTime {
+			// These rank on accuracy unless asked otherwise
+			sortBy = r.URL.Query().Get("sort")
+			lb := models.LeaderboardResponse{Page: page, PageSize: pageSize, Category: category}
+			var err error
+			if category != "" {
+				lb.Leaderboard, lb.TotalAgents, lb.SortBy, err = trackRecordLeaderboard(db, filter, "agent_category_stats", "category", category, sortBy, pageSize, offset)
+			} else {
+				lb.Window = window
+				lb.Leaderboard, lb.TotalAgents, lb.SortBy, err = trackRecordLeaderboard(db, filter, "leaderboard_snapshots", "time_window", window, sortBy, pageSize, offset)
+				if err == nil {
+					lb.ComputedAt, err = models.LeaderboardComputedAt(db, window)
+				}
+			}
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch leaderboard")
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(lb)
+			return
+		}
 		
-		result := db.Where("is_active = ?", true).
-			Order(orderBy).
+		// With enough agents the scheduler materializes the default
+		// ranking, so deep pages need not sort and skip every agent before
+		// them.
+		if sortBy == "composite" && filter == (models.ModelCardFilter{}) {
+			standings, totalAgents, computedAt, err := leaderboard.CachedRanking(db, pageSize, offset)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch leaderboard")
+				return
+			}
+			if computedAt != nil {
+				entries := make([]models.LeaderboardEntry, len(standings))
+				for i, standing := range standings {
+					entries[i] = leaderboardEntry(standing.Agent, standing.Rank)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(models.LeaderboardResponse{
+					Leaderboard: entries,
+					TotalAgents: totalAgents,
+					SortBy:      sortBy,
+					Page:        page,
+					PageSize:    pageSize,
+					ComputedAt:  computedAt,
+				})
+				return
+			}
+		}
+
+		result := leaderboard.Ranked(db, filter, column).
 			Limit(pageSize).
 			Offset(offset).
 			Find(&agents)
 			
 		if result.Error != nil {
-			http.Error(w, "Failed to fetch leaderboard", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch leaderboard")
 			return
 		}
 
 		// Convert to leaderboard entries
 		entries := make([]models.LeaderboardEntry, len(agents))
 		for i, agent := range agents {
-			entries[i] = models.LeaderboardEntry{
-				Rank:               int64(offset + i + 1),
-				AgentID:            agent.ID,
-				AgentName:          agent.Name,
-				AvatarURL:          agent.AvatarURL,
-				PersonalEmoji:      agent.PersonalEmoji,
-				CompositeScore:     agent.CompositeScore,
-				AccuracyScore:      agent.AccuracyScore,
-				EngagementScore:    agent.EngagementScore,
-				CreatorScore:       agent.CreatorScore,
-				ActivityScore:      agent.ActivityScore,
-				TotalPredictions:   agent.TotalPredictions,
-				CorrectPredictions: agent.CorrectPredictions,
-				CurrentStreak:      agent.CurrentStreak,
-			}
+			entries[i] = leaderboardEntry(agent, int64(offset+i+1))
 		}
 
 		// Get total count
 		var totalAgents int64
-		db.Model(&models.Agent{}).Where("is_active = ?", true).Count(&totalAgents)
+		leaderboard.Eligible(db, filter).Count(&totalAgents)
 
 		response := models.LeaderboardResponse{
 			Leaderboard: entries,
@@ -102,74 +152,145 @@ func LeaderboardHandler(db *gorm.DB) http.HandlerFunc {
 	}
 }
 
+// leaderboardEntry is agent's all-time leaderboard entry at rank.
+func leaderboardEntry(agent models.Agent, rank int64) models.LeaderboardEntry {
+	return models.LeaderboardEntry{
+		Rank:               rank,
+		AgentID:            agent.ID,
+		AgentName:          agent.Name,
+		AvatarURL:          agent.AvatarURL,
+		PersonalEmoji:      agent.PersonalEmoji,
+		CompositeScore:     agent.CompositeScore,
+		AccuracyScore:      agent.AccuracyScore,
+		EngagementScore:    agent.EngagementScore,
+		CreatorScore:       agent.CreatorScore,
+		ActivityScore:      agent.ActivityScore,
+		TotalPredictions:   agent.TotalPredictions,
+		CorrectPredictions: agent.CorrectPredictions,
+		CurrentStreak:      agent.CurrentStreak,
+	}
+}
+
+// trackRecord is the part of an AgentCategoryStats or LeaderboardSnapshot
+// row a leaderboard shows.
+type trackRecord struct {
+	AgentID             int64
+	ResolvedPredictions int64
+	CorrectPredictions  int64
+	AccuracyScore       float64
+}
+
+// trackRecordLeaderboard ranks the active agents with a row in table, an
+// AgentCategoryStats or LeaderboardSnapshot table, whose column equals
+// value. Accuracy scores and prediction counts in the entries come from
+// that row; the other scores are agent-wide. It returns the page of
+// entries, how many agents qualify and the sort used.
+func trackRecordLeaderboard(db *gorm.DB, filter models.ModelCardFilter, table, column, value, sortBy string, pageSize, offset int) ([]models.LeaderboardEntry, int64, string, error) {
+	var orderBy string
+	switch sortBy {
+	case "calibrated":
+		orderBy = table + ".calibrated_accuracy_score DESC"
+	case "predictions":
+		orderBy = table + ".resolved_predictions DESC"
+	case "composite":
+		orderBy = "agents.composite_score DESC"
+	case "engagement":
+		orderBy = "agents.engagement_score DESC"
+	case "creator":
+		orderBy = "agents.creator_score DESC"
+	case "activity":
+		orderBy = "agents.activity_score DESC"
+	default:
+		sortBy = "accuracy"
+		orderBy = table + ".accuracy_score DESC"
+	}
+
+	query := func() *gorm.DB {
+		return filter.Apply(db.Table(table).
+			Joins("JOIN agents ON agents.id = "+table+".agent_id")).
+			Where(table+"."+column+" = ? AND agents.is_active = ? AND agents.deleted_at IS NULL", value, true)
+	}
+
+	var totalAgents int64
+	if err := query().Count(&totalAgents).Error; err != nil {
+		return nil, 0, sortBy, err
+	}
+	var records []trackRecord
+	if err := query().Select(table + ".agent_id, " + table + ".resolved_predictions, " + table + ".correct_predictions, " + table + ".accuracy_score").
+		Order(orderBy).Order("agents.id").Limit(pageSize).Offset(offset).Scan(&records).Error; err != nil {
+		return nil, 0, sortBy, err
+	}
+
+	agentIDs := make([]int64, len(records))
+	for i, record := range records {
+		agentIDs[i] = record.AgentID
+	}
+	var agents []models.Agent
+	if len(agentIDs) > 0 {
+		if err := db.Where("id IN ?", agentIDs).Find(&agents).Error; err != nil {
+			return nil, 0, sortBy, err
+		}
+	}
+	byID := make(map[int64]models.Agent, len(agents))
+	for _, agent := range agents {
+		byID[agent.ID] = agent
+	}
+
+	entries := make([]models.LeaderboardEntry, len(records))
+	for i, record := range records {
+		agent := byID[record.AgentID]
+		entries[i] = models.LeaderboardEntry{
+			Rank:               int64(offset + i + 1),
+			AgentID:            agent.ID,
+			AgentName:          agent.Name,
+			AvatarURL:          agent.AvatarURL,
+			PersonalEmoji:      agent.PersonalEmoji,
+			CompositeScore:     agent.CompositeScore,
+			AccuracyScore:      record.AccuracyScore,
+			EngagementScore:    agent.EngagementScore,
+			CreatorScore:       agent.CreatorScore,
+			ActivityScore:      agent.ActivityScore,
+			TotalPredictions:   record.ResolvedPredictions,
+			CorrectPredictions: record.CorrectPredictions,
+			CurrentStreak:      agent.CurrentStreak,
+		}
+	}
+	return entries, totalAgents, sortBy, nil
+}
+
 // RecalculateAllScoresHandler handles POST /v0/admin/recalculate-scores
-// Admin endpoint to trigger score recalculation for all agents
+// Admin endpoint to queue a recalculation of every agent's scores. It
+// returns the job straight away; follow it at GET /v0/admin/jobs/{id}.
+// While one recalculation is queued or running another is refused with 409
+// and the active job.
 func RecalculateAllScoresHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
-		// TODO: Add admin authentication check
-
-		var agents []models.Agent
-		if result := db.Find(&agents); result.Error != nil {
-			http.Error(w, "Failed to fetch agents", http.StatusInternalServerError)
-			return
+		requestedBy := ""
+		if p := middleware.PrincipalFromContext(r.Context()); p != nil && p.User != nil {
+			requestedBy = p.User.Username
 		}
 
-		updated := 0
-		for _, agent := range agents {
-			// Recalculate prediction stats from predictions table
-			var totalPredictions int64
-			var resolvedPredictions int64
-			var correctPredictions int64
-			
-			db.Model(&models.Prediction{}).Where("agent_id = ?", agent.ID).Count(&totalPredictions)
-			db.Model(&models.Prediction{}).Where("agent_id = ? AND is_resolved = ?", agent.ID, true).Count(&resolvedPredictions)
-			db.Model(&models.Prediction{}).Where("agent_id = ? AND is_resolved = ? AND was_correct = ?", agent.ID, true, true).Count(&correctPredictions)
-			
-			agent.TotalPredictions = totalPredictions
-			agent.ResolvedPredictions = resolvedPredictions
-			agent.CorrectPredictions = correctPredictions
-			
-			// Recalculate engagement stats
-			var upvoteSum, downvoteSum, commentSum int64
-			db.Model(&models.Prediction{}).Where("agent_id = ?", agent.ID).
-				Select("COALESCE(SUM(upvotes), 0)").Row().Scan(&upvoteSum)
-			db.Model(&models.Prediction{}).Where("agent_id = ?", agent.ID).
-				Select("COALESCE(SUM(downvotes), 0)").Row().Scan(&downvoteSum)
-			db.Model(&models.Prediction{}).Where("agent_id = ?", agent.ID).
-				Select("COALESCE(SUM(comments), 0)").Row().Scan(&commentSum)
-			
-			agent.TotalUpvotesReceived = upvoteSum
-			agent.TotalDownvotesReceived = downvoteSum
-			agent.TotalCommentsReceived = commentSum
-			
-			// Recalculate follower count
-			var followerCount int64
-			db.Model(&models.AgentFollow{}).Where("followed_id = ?", agent.ID).Count(&followerCount)
-			agent.TotalFollowers = followerCount
-			
-			// Recalculate creator stats
-			var marketsCreated int64
-			db.Model(&models.Market{}).Where("creator_agent_id = ?", agent.ID).Count(&marketsCreated)
-			agent.MarketsCreated = marketsCreated
-			
-			// Recalculate all scores
-			agent.RecalculateAllScores()
-			
-			if result := db.Save(&agent); result.Error == nil {
-				updated++
-			}
+		// Counters are rebuilt from the predictions, follows and markets tables
+		job, err := adminjobs.Enqueue(db, models.AdminJobRecalculateScores, requestedBy)
+		status := http.StatusAccepted
+		if errors.Is(err, adminjobs.ErrAlreadyActive) {
+			status = http.StatusConflict
+		} else if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to queue recalculation")
+			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", fmt.Sprintf("/v0/admin/jobs/%d", job.ID))
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":      true,
-			"agentsUpdated": updated,
-			"totalAgents":  len(agents),
+			"success": status == http.StatusAccepted,
+			"job":     job,
 		})
 	}
 }
@@ -0,0 +1,183 @@
+package predictions
+
+import (
+	"fmt"
+	"strings"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// What GET /v0/market/{id}/predictions can include, by ?include=.
+const (
+	IncludeAgent     = "agent"     // the agent's public stats inline
+	IncludeCategory  = "category"  // the agent's record in the market's category; implies agent
+	IncludeReasoning = "reasoning" // the reasoning text
+	IncludeConsensus = "consensus" // yes/no counts over all the market's predictions
+)
+
+// defaultMarketPredictionIncludes are what the listing includes without
+// ?include=, as it always has.
+var defaultMarketPredictionIncludes = []string{IncludeReasoning, IncludeConsensus}
+
+// includes is the set of parts a listing returns.
+type includes map[string]bool
+
+// parseIncludes reads a comma-separated ?include= value. Empty means
+// defaults; "none" means nothing optional.
+func parseIncludes(raw string, allowed, defaults []string) (includes, error) {
+	set := includes{}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		for _, part := range defaults {
+			set[part] = true
+		}
+		return set, nil
+	}
+	if raw == "none" {
+		return set, nil
+	}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		known := false
+		for _, a := range allowed {
+			known = known || a == part
+		}
+		if !known {
+			return nil, fmt.Errorf("include must be a comma-separated list of %s", strings.Join(allowed, ", "))
+		}
+		set[part] = true
+	}
+	return set, nil
+}
+
+// predictionRow is a prediction with its agent's public fields, read in
+// one query. AgentRowID is nil when the agent is gone.
+type predictionRow struct {
+	models.Prediction
+	AgentRowID                   *int64
+	AgentName                    string
+	AgentAvatarURL               string
+	AgentPersonalEmoji           string
+	AgentIsClaimed               bool
+	AgentCompositeScore          float64
+	AgentAccuracyScore           float64
+	AgentCalibratedAccuracyScore float64
+	AgentTotalPredictions        int64
+	AgentCorrectPredictions      int64
+	AgentCorrectStreak           int64
+}
+
+// predictionAgentColumns are the agent columns predictionRow reads. The
+// agents are left joined, so the defaults stand in for a deleted agent.
+const predictionAgentColumns = `agents.id AS agent_row_id,
+	COALESCE(agents.name, '') AS agent_name,
+	COALESCE(agents.avatar_url, '') AS agent_avatar_url,
+	COALESCE(agents.personal_emoji, '') AS agent_personal_emoji,
+	COALESCE(agents.is_claimed, false) AS agent_is_claimed,
+	COALESCE(agents.composite_score, 0) AS agent_composite_score,
+	COALESCE(agents.accuracy_score, 0) AS agent_accuracy_score,
+	COALESCE(agents.calibrated_accuracy_score, 0) AS agent_calibrated_accuracy_score,
+	COALESCE(agents.total_predictions, 0) AS agent_total_predictions,
+	COALESCE(agents.correct_predictions, 0) AS agent_correct_predictions,
+	COALESCE(agents.correct_streak, 0) AS agent_correct_streak`
+
+// withAgents selects the predictions of query with their agents' public
+// fields, joined rather than preloaded.
+func withAgents(query *gorm.DB) *gorm.DB {
+	return query.
+		Select("predictions.*, " + predictionAgentColumns).
+		Joins("LEFT JOIN agents ON agents.id = predictions.agent_id AND agents.deleted_at IS NULL")
+}
+
+// toPublic converts the row, with the agent's stats inline if withAgent.
+func (row predictionRow) toPublic(withAgent bool) models.PredictionPublic {
+	pub := row.Prediction.ToPublic()
+	pub.AgentName = row.AgentName
+	if withAgent && row.AgentRowID != nil {
+		pub.Agent = &models.PredictionAgent{
+			ID:                      *row.AgentRowID,
+			Name:                    row.AgentName,
+			AvatarURL:               row.AgentAvatarURL,
+			PersonalEmoji:           row.AgentPersonalEmoji,
+			IsClaimed:               row.AgentIsClaimed,
+			CompositeScore:          row.AgentCompositeScore,
+			AccuracyScore:           row.AgentAccuracyScore,
+			CalibratedAccuracyScore: row.AgentCalibratedAccuracyScore,
+			TotalPredictions:        row.AgentTotalPredictions,
+			CorrectPredictions:      row.AgentCorrectPredictions,
+			CorrectStreak:           row.AgentCorrectStreak,
+		}
+	}
+	return pub
+}
+
+// attachCategoryStats sets on each inline agent its record in category,
+// read for all of them in one query. Agents without one are left without.
+func attachCategoryStats(db *gorm.DB, predictions []models.PredictionPublic, category string) error {
+	var agentIDs []int64
+	for _, p := range predictions {
+		if p.Agent != nil {
+			agentIDs = append(agentIDs, p.Agent.ID)
+		}
+	}
+	if len(agentIDs) == 0 {
+		return nil
+	}
+	var rows []models.AgentCategoryStats
+	if err := db.Where("agent_id IN ? AND category = ?", agentIDs, category).Find(&rows).Error; err != nil {
+		return err
+	}
+	byAgent := make(map[int64]*models.AgentCategoryStats, len(rows))
+	for i := range rows {
+		byAgent[rows[i].AgentID] = &rows[i]
+	}
+	for i := range predictions {
+		if predictions[i].Agent != nil {
+			predictions[i].Agent.Category = byAgent[predictions[i].Agent.ID]
+		}
+	}
+	return nil
+}
+
+// predictionCounts summarizes all of a market's visible predictions.
+type predictionCounts struct {
+	YesCount         int64   `json:"yesCount"`
+	NoCount          int64   `json:"noCount"`
+	AvgConfidence    float64 `json:"avgConfidence"`
+	TotalPredictions int64   `json:"totalPredictions"`
+}
+
+// countPredictions counts the market's visible predictions by outcome in
+// one grouped query. Predictions without a YES outcome count as NO.
+func countPredictions(db *gorm.DB, marketID int64) (predictionCounts, error) {
+	var groups []struct {
+		Outcome    string
+		Count      int64
+		Confidence float64
+	}
+	err := models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+		Select("outcome, COUNT(*) AS count, SUM(confidence) AS confidence").
+		Where("market_id = ?", marketID).
+		Group("outcome").
+		Scan(&groups).Error
+	if err != nil {
+		return predictionCounts{}, err
+	}
+	var counts predictionCounts
+	var totalConfidence float64
+	for _, g := range groups {
+		if g.Outcome == "YES" {
+			counts.YesCount += g.Count
+		} else {
+			counts.NoCount += g.Count
+		}
+		counts.TotalPredictions += g.Count
+		totalConfidence += g.Confidence
+	}
+	if counts.TotalPredictions > 0 {
+		counts.AvgConfidence = totalConfidence / float64(counts.TotalPredictions)
+	}
+	return counts, nil
+}
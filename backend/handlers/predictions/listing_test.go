@@ -0,0 +1,84 @@
+package predictions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMarketPredictions_JoinsAgentsAndCountsAcrossPages(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	market.Category = "crypto"
+	db.Create(&market)
+
+	var agents []models.Agent
+	for i, outcome := range []string{"YES", "YES", "NO"} {
+		agent := modelstesting.GenerateAgent("lister" + strconv.Itoa(i))
+		agent.CompositeScore = float64(40 + i)
+		db.Create(&agent)
+		agents = append(agents, agent)
+		prediction := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: outcome, Confidence: 60, Reasoning: "because", Upvotes: int64(10 - i), PredictedAt: time.Now()}
+		if err := db.Create(&prediction).Error; err != nil {
+			t.Fatalf("create prediction: %v", err)
+		}
+	}
+	db.Create(&models.AgentCategoryStats{AgentID: agents[0].ID, Category: "crypto", ResolvedPredictions: 4, CorrectPredictions: 3, AccuracyScore: 60})
+
+	type listing struct {
+		Predictions []models.PredictionPublic `json:"predictions"`
+		Total       int                       `json:"total"`
+		Consensus   *predictionCounts         `json:"consensus"`
+	}
+	get := func(query string) (int, listing) {
+		req := httptest.NewRequest(http.MethodGet, "/v0/market/"+strconv.FormatInt(market.ID, 10)+"/predictions"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": strconv.FormatInt(market.ID, 10)})
+		rec := httptest.NewRecorder()
+		GetMarketPredictionsHandler(db)(rec, req)
+		var out listing
+		json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	code, page := get("?limit=2")
+	if code != http.StatusOK || page.Total != 2 || page.Predictions[0].AgentName != "lister0" || page.Predictions[0].Reasoning != "because" {
+		t.Fatalf("expected the default listing, got %d %+v", code, page)
+	}
+	if page.Predictions[0].Agent != nil {
+		t.Fatalf("expected no inline agent by default")
+	}
+	// The counts cover every prediction, not just the page.
+	if c := page.Consensus; c == nil || c.YesCount != 2 || c.NoCount != 1 || c.TotalPredictions != 3 || c.AvgConfidence != 60 {
+		t.Fatalf("unexpected consensus %+v", page.Consensus)
+	}
+
+	_, page = get("?include=category")
+	first := page.Predictions[0].Agent
+	if first == nil || first.ID != agents[0].ID || first.CompositeScore != 40 || first.Category == nil || first.Category.CorrectPredictions != 3 {
+		t.Fatalf("expected the agent inline with its category record, got %+v", first)
+	}
+	if page.Predictions[1].Agent == nil || page.Predictions[1].Agent.Category != nil {
+		t.Fatalf("expected an agent without a category record to have none")
+	}
+	if page.Predictions[0].Reasoning != "" || page.Consensus != nil {
+		t.Fatalf("expected only what was asked for, got %+v", page)
+	}
+
+	if _, page = get("?include=none"); page.Predictions[0].Reasoning != "" || page.Consensus != nil || page.Predictions[0].AgentName == "" {
+		t.Fatalf("expected the bare listing, got %+v", page)
+	}
+	if code, _ := get("?include=everything"); code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown include rejected, got %d", code)
+	}
+}
@@ -2,12 +2,16 @@ package predictions
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"net/http"
+	"socialpredict/errors"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/predictioncreation"
+	"socialpredict/services/scoring"
+	"socialpredict/validation"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
@@ -18,133 +22,68 @@ import (
 func MakePredictionHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		// Validate agent (must be claimed)
 		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
 
 		var req models.PredictionRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
-
-		// Validate request
-		if req.MarketID <= 0 {
-			http.Error(w, "Market ID is required", http.StatusBadRequest)
-			return
-		}
-		
-		outcome := strings.ToUpper(req.Outcome)
-		if outcome != "YES" && outcome != "NO" {
-			http.Error(w, "Outcome must be 'YES' or 'NO'", http.StatusBadRequest)
-			return
-		}
-		
-		// Default confidence to 50 if not provided
-		confidence := req.Confidence
-		if confidence <= 0 || confidence > 100 {
-			confidence = 50
-		}
-
-		// Check market exists and is active
-		var market models.Market
-		if result := db.First(&market, req.MarketID); result.Error != nil {
-			if result.Error == gorm.ErrRecordNotFound {
-				http.Error(w, "Market not found", http.StatusNotFound)
-				return
-			}
-			http.Error(w, "Database error", http.StatusInternalServerError)
+		prediction, created, err := predictioncreation.Make(r.Context(), db, predictioncreation.Input{
+			AgentID:    agent.ID,
+			MarketID:   req.MarketID,
+			Outcome:    req.Outcome,
+			Confidence: req.Confidence,
+			Reasoning:  req.Reasoning,
+			Estimate:   req.Estimate,
+			Low:        req.Low,
+			High:       req.High,
+		})
+		switch {
+		case stderrors.Is(err, predictioncreation.ErrMarketNotFound):
+			response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
 			return
-		}
-
-		if market.IsResolved {
-			http.Error(w, "Market is already resolved", http.StatusBadRequest)
+		case stderrors.Is(err, predictioncreation.ErrMarketResolved):
+			response.Error(w, http.StatusBadRequest, response.CodeMarketResolved, "Market is already resolved")
 			return
-		}
-
-		// Check if agent already predicted on this market (optional - could allow changing)
-		var existingPrediction models.Prediction
-		if result := db.Where("agent_id = ? AND market_id = ?", agent.ID, req.MarketID).First(&existingPrediction); result.Error == nil {
-			// Agent already predicted - update instead
-			existingPrediction.Outcome = outcome
-			existingPrediction.Confidence = confidence
-			existingPrediction.Reasoning = req.Reasoning
-			
-			if result := db.Save(&existingPrediction); result.Error != nil {
-				http.Error(w, "Failed to update prediction", http.StatusInternalServerError)
-				return
-			}
-			
-			response := models.PredictionResponse{
-				Success:    true,
-				Prediction: existingPrediction.ToPublic(),
-				Message:    "Prediction updated",
-			}
-			
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(response)
+		case stderrors.Is(err, predictioncreation.ErrPredictionsLocked):
+			response.Error(w, http.StatusConflict, response.CodeMarketLocked, "Predictions on this market are locked")
 			return
-		}
-
-		// Create new prediction
-		prediction := models.Prediction{
-			AgentID:     agent.ID,
-			MarketID:    req.MarketID,
-			Outcome:     outcome,
-			Confidence:  confidence,
-			Reasoning:   req.Reasoning,
-			PredictedAt: time.Now(),
-		}
-
-		tx := db.Begin()
-
-		if result := tx.Create(&prediction); result.Error != nil {
-			tx.Rollback()
-			http.Error(w, "Failed to create prediction", http.StatusInternalServerError)
+		case stderrors.Is(err, predictioncreation.ErrOutcomeRequired),
+			stderrors.Is(err, predictioncreation.ErrInvalidEstimate),
+			stderrors.Is(err, predictioncreation.ErrIncoherent):
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 			return
-		}
-
-		// Update agent stats and activity
-		agent.TotalPredictions++
-		agent.UpdateActivity()
-		agent.RecalculateActivityScore()
-		agent.RecalculateCompositeScore()
-		
-		if result := tx.Save(agent); result.Error != nil {
-			tx.Rollback()
-			http.Error(w, "Failed to update agent stats", http.StatusInternalServerError)
+		case stderrors.Is(err, predictioncreation.ErrContentBlocked):
+			response.Error(w, http.StatusBadRequest, response.CodeContentBlocked, err.Error())
 			return
-		}
-
-		// Update market prediction count
-		market.TotalPredictions++
-		if result := tx.Save(&market); result.Error != nil {
-			tx.Rollback()
-			http.Error(w, "Failed to update market stats", http.StatusInternalServerError)
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to save prediction")
 			return
 		}
 
-		tx.Commit()
-
-		// Load related data for response
-		prediction.Agent = agent
-		prediction.Market = &market
-
 		response := models.PredictionResponse{
 			Success:    true,
 			Prediction: prediction.ToPublic(),
-			Message:    "Prediction created successfully",
+			Message:    "Prediction updated",
+		}
+		status := http.StatusOK
+		if created {
+			response.Message = "Prediction created successfully"
+			status = http.StatusCreated
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(response)
 	}
 }
@@ -157,17 +96,17 @@ func GetPredictionHandler(db *gorm.DB) http.HandlerFunc {
 		
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid prediction ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid prediction ID")
 			return
 		}
 
 		var prediction models.Prediction
 		if result := db.Preload("Agent").Preload("Market").First(&prediction, id); result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
-				http.Error(w, "Prediction not found", http.StatusNotFound)
+				response.Error(w, http.StatusNotFound, response.CodeNotFound, "Prediction not found")
 				return
 			}
-			http.Error(w, "Database error", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
 			return
 		}
 
@@ -179,6 +118,45 @@ func GetPredictionHandler(db *gorm.DB) http.HandlerFunc {
 	}
 }
 
+// GetPredictionHistoryHandler handles GET /v0/prediction/{id}/history
+// It lists every revision of the prediction, oldest first, and which one
+// was scored once the market resolved.
+func GetPredictionHistoryHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid prediction ID")
+			return
+		}
+
+		var prediction models.Prediction
+		if result := db.First(&prediction, id); result.Error != nil {
+			if result.Error == gorm.ErrRecordNotFound {
+				response.Error(w, http.StatusNotFound, response.CodeNotFound, "Prediction not found")
+				return
+			}
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+
+		revisions, err := models.PredictionRevisions(db, prediction.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch prediction history")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":        true,
+			"predictionId":   prediction.ID,
+			"revision":       prediction.Revision,
+			"scoredRevision": prediction.ScoredRevision,
+			"lateFlip":       prediction.LateFlip,
+			"revisions":      revisions,
+		})
+	}
+}
+
 // GetAgentPredictionsHandler handles GET /v0/agent/{id}/predictions
 func GetAgentPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -187,7 +165,7 @@ func GetAgentPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 		
 		agentID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid agent ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
 			return
 		}
 
@@ -207,7 +185,8 @@ func GetAgentPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		var predictions []models.Prediction
-		result := db.Preload("Market").
+		result := models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+			Preload("Market").
 			Where("agent_id = ?", agentID).
 			Order("predicted_at DESC").
 			Limit(limit).
@@ -215,7 +194,7 @@ func GetAgentPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 			Find(&predictions)
 			
 		if result.Error != nil {
-			http.Error(w, "Failed to fetch predictions", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch predictions")
 			return
 		}
 
@@ -227,7 +206,7 @@ func GetAgentPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 
 		// Get total count
 		var total int64
-		db.Model(&models.Prediction{}).Where("agent_id = ?", agentID).Count(&total)
+		models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).Where("agent_id = ?", agentID).Count(&total)
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -241,6 +220,11 @@ func GetAgentPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 }
 
 // GetMarketPredictionsHandler handles GET /v0/market/{id}/predictions
+// Predictions come with their agents joined in the same query. ?include=
+// picks the optional parts: agent (the agent's stats inline), category
+// (its record in the market's category), reasoning and consensus (counts
+// over all the market's predictions). Without it reasoning and consensus
+// are included; include=none leaves them all out.
 func GetMarketPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -248,7 +232,7 @@ func GetMarketPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 		
 		marketID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid market ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
 			return
 		}
 
@@ -259,54 +243,73 @@ func GetMarketPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 				limit = parsed
 			}
 		}
+		include, err := parseIncludes(r.URL.Query().Get("include"),
+			[]string{IncludeAgent, IncludeCategory, IncludeReasoning, IncludeConsensus}, defaultMarketPredictionIncludes)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+			return
+		}
+		if include[IncludeCategory] {
+			include[IncludeAgent] = true
+		}
 
-		var predictions []models.Prediction
-		result := db.Preload("Agent").
-			Where("market_id = ?", marketID).
-			Order("upvotes DESC, predicted_at DESC").
-			Limit(limit).
-			Find(&predictions)
-			
-		if result.Error != nil {
-			http.Error(w, "Failed to fetch predictions", http.StatusInternalServerError)
+		// Invite-only markets are hidden from agents they do not invite
+		var market models.Market
+		open := true
+		err = db.Select("id", "visibility", "creator_agent_id", "category").First(&market, marketID).Error
+		if err == nil {
+			open, err = market.OpenTo(db, middleware.PrincipalFromContext(r.Context()).AgentID())
+		}
+		if err != nil && err != gorm.ErrRecordNotFound {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if !open {
+			response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
 			return
 		}
 
-		// Convert to public
-		publicPredictions := make([]models.PredictionPublic, len(predictions))
-		for i, p := range predictions {
-			publicPredictions[i] = p.ToPublic()
+		var rows []predictionRow
+		result := withAgents(models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction)).
+			Where("predictions.market_id = ?", marketID).
+			Order("predictions.upvotes DESC, predictions.predicted_at DESC").
+			Limit(limit).
+			Scan(&rows)
+		if result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch predictions")
+			return
 		}
 
-		// Calculate consensus
-		yesCount := 0
-		noCount := 0
-		totalConfidence := 0.0
-		for _, p := range predictions {
-			if p.Outcome == "YES" {
-				yesCount++
-			} else {
-				noCount++
+		publicPredictions := make([]models.PredictionPublic, len(rows))
+		for i, row := range rows {
+			publicPredictions[i] = row.toPublic(include[IncludeAgent])
+			if !include[IncludeReasoning] {
+				publicPredictions[i].Reasoning = ""
 			}
-			totalConfidence += p.Confidence
 		}
-		
-		avgConfidence := 0.0
-		if len(predictions) > 0 {
-			avgConfidence = totalConfidence / float64(len(predictions))
+		if include[IncludeCategory] && market.Category != "" {
+			if err := attachCategoryStats(db, publicPredictions, market.Category); err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch category stats")
+				return
+			}
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
+		body := map[string]interface{}{
 			"success":     true,
 			"predictions": publicPredictions,
-			"total":       len(predictions),
-			"consensus": map[string]interface{}{
-				"yesCount":      yesCount,
-				"noCount":       noCount,
-				"avgConfidence": avgConfidence,
-			},
-		})
+			"total":       len(publicPredictions),
+		}
+		if include[IncludeConsensus] {
+			counts, err := countPredictions(db, marketID)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to count predictions")
+				return
+			}
+			body["consensus"] = counts
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
 	}
 }
 
@@ -314,7 +317,7 @@ func GetMarketPredictionsHandler(db *gorm.DB) http.HandlerFunc {
 func VotePredictionHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method not allowed")
 			return
 		}
 
@@ -323,52 +326,45 @@ func VotePredictionHandler(db *gorm.DB) http.HandlerFunc {
 		
 		predictionID, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid prediction ID", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid prediction ID")
 			return
 		}
 
 		// Get voter (agent or user)
 		agent, agentErr := middleware.ValidateAgentAPIKey(r, db)
 		
-		var voterID int64
-		var voterType string
+		var voter models.Actor
 		
 		if agentErr == nil && agent != nil {
-			voterID = agent.ID
-			voterType = "agent"
+			voter = models.AgentActor(agent.ID)
 		} else {
 			// Try to get user from session (if logged in)
 			// For now, require agent authentication
-			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Authentication required")
 			return
 		}
 
 		var req models.VoteRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-
-		voteType := strings.ToLower(req.VoteType)
-		if voteType != "up" && voteType != "down" {
-			http.Error(w, "Vote type must be 'up' or 'down'", http.StatusBadRequest)
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
+		voteType := req.VoteType
 
 		// Check prediction exists
 		var prediction models.Prediction
 		if result := db.First(&prediction, predictionID); result.Error != nil {
 			if result.Error == gorm.ErrRecordNotFound {
-				http.Error(w, "Prediction not found", http.StatusNotFound)
+				response.Error(w, http.StatusNotFound, response.CodeNotFound, "Prediction not found")
 				return
 			}
-			http.Error(w, "Database error", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
 			return
 		}
 
 		// Can't vote on your own prediction
-		if voterType == "agent" && prediction.AgentID == voterID {
-			http.Error(w, "Cannot vote on your own prediction", http.StatusBadRequest)
+		if voter == models.AgentActor(prediction.AgentID) {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Cannot vote on your own prediction")
 			return
 		}
 
@@ -376,8 +372,8 @@ func VotePredictionHandler(db *gorm.DB) http.HandlerFunc {
 
 		// Check for existing vote
 		var existingVote models.PredictionVote
-		if result := db.Where("prediction_id = ? AND voter_id = ? AND voter_type = ?", 
-			predictionID, voterID, voterType).First(&existingVote); result.Error == nil {
+		if result := db.Where("prediction_id = ? AND voter_type = ? AND voter_id = ?", 
+			predictionID, string(voter.Type), voter.ID).First(&existingVote); result.Error == nil {
 			
 			// Remove old vote
 			if existingVote.VoteType == "up" {
@@ -403,10 +399,9 @@ func VotePredictionHandler(db *gorm.DB) http.HandlerFunc {
 			// New vote
 			vote := models.PredictionVote{
 				PredictionID: predictionID,
-				VoterID:      voterID,
-				VoterType:    voterType,
 				VoteType:     voteType,
 			}
+			vote.SetVoter(voter)
 			tx.Create(&vote)
 			
 			if voteType == "up" {
@@ -419,22 +414,10 @@ func VotePredictionHandler(db *gorm.DB) http.HandlerFunc {
 		tx.Save(&prediction)
 
 		// Update prediction author's engagement score
-		var author models.Agent
-		if result := tx.First(&author, prediction.AgentID); result.Error == nil {
-			author.TotalUpvotesReceived = 0
-			author.TotalDownvotesReceived = 0
-			
-			// Recalculate total votes
-			var upvoteSum, downvoteSum int64
-			tx.Model(&models.Prediction{}).Where("agent_id = ?", author.ID).
-				Select("COALESCE(SUM(upvotes), 0) as upvote_sum, COALESCE(SUM(downvotes), 0) as downvote_sum").
-				Row().Scan(&upvoteSum, &downvoteSum)
-			
-			author.TotalUpvotesReceived = upvoteSum
-			author.TotalDownvotesReceived = downvoteSum
-			author.RecalculateEngagementScore()
-			author.RecalculateCompositeScore()
-			tx.Save(&author)
+		if _, err := scoring.Recompute(r.Context(), tx, prediction.AgentID, nil); err != nil {
+			tx.Rollback()
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update author score")
+			return
 		}
 
 		tx.Commit()
@@ -0,0 +1,234 @@
+package readkeyshandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/i18n"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// MaxActiveKeysPerOwner caps how many unrevoked read-only keys one owner holds.
+const MaxActiveKeysPerOwner = 10
+
+// CreateReadKeyRequest is the request body for minting a read-only key
+type CreateReadKeyRequest struct {
+	Name string `json:"name" validate:"required,max=100,safe_string"`
+}
+
+// Normalize trims the key name.
+func (r *CreateReadKeyRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+}
+
+// CreateReadKeyHandler handles POST /v0/readkeys
+// Only humans who own at least one agent can mint keys. The key itself is
+// returned once and only its hash is stored.
+func CreateReadKeyHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, httpErr := middleware.ValidateUserAndEnforcePasswordChangeGetUser(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var req CreateReadKeyRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		var ownedAgents int64
+		if result := db.Model(&models.Agent{}).Where("owner_user_id = ?", user.ID).Count(&ownedAgents); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+			return
+		}
+		if ownedAgents == 0 {
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only owners of claimed agents can mint read-only keys")
+			return
+		}
+
+		var activeKeys int64
+		db.Model(&models.ReadAPIKey{}).Where("owner_user_id = ? AND revoked_at IS NULL", user.ID).Count(&activeKeys)
+		if activeKeys >= MaxActiveKeysPerOwner {
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Read-only key limit reached; revoke an existing key first")
+			return
+		}
+
+		key, err := models.GenerateReadAPIKey()
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to generate key")
+			return
+		}
+
+		readKey := models.ReadAPIKey{
+			OwnerUserID: user.ID,
+			Name:        req.Name,
+			KeyHash:     models.HashReadAPIKey(key),
+			KeyPrefix:   key[:len(models.ReadAPIKeyPrefix)+6],
+		}
+		if result := db.Create(&readKey); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to save key")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"key":     readKey,
+			"apiKey":  key,
+			"message": i18n.T(r, "readkeys.store_now", middleware.ReadAPIKeyHeader),
+		})
+	}
+}
+
+// ListReadKeysHandler handles GET /v0/readkeys
+func ListReadKeysHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, httpErr := middleware.ValidateTokenAndGetUser(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var keys []models.ReadAPIKey
+		if result := db.Where("owner_user_id = ?", user.ID).Order("id DESC").Find(&keys); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch keys")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"keys":    keys,
+		})
+	}
+}
+
+// RevokeReadKeyHandler handles DELETE /v0/readkeys/{id}
+func RevokeReadKeyHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, httpErr := middleware.ValidateTokenAndGetUser(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid key ID")
+			return
+		}
+
+		result := db.Model(&models.ReadAPIKey{}).
+			Where("id = ? AND owner_user_id = ? AND revoked_at IS NULL", id, user.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to revoke key")
+			return
+		}
+		if result.RowsAffected == 0 {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Key not found")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+		})
+	}
+}
+
+// ReadUsageHandler handles GET /v0/admin/read-usage
+// Reports public read traffic per access tier since startup and the busiest
+// read-only keys.
+func ReadUsageHandler(db *gorm.DB, meter *middleware.ReadMeter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := middleware.ValidateAdminToken(r, db); err != nil {
+			response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "unauthorized")
+			return
+		}
+
+		var topKeys []models.ReadAPIKey
+		if result := db.Order("request_count DESC").Limit(20).Find(&topKeys); result.Error != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch key usage")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"tiers":   meter.Snapshot(),
+			"topKeys": topKeys,
+		})
+	}
+}
+
+// GrantAnalyticsHandler handles PUT /v0/admin/readkeys/{id}/analytics
+// Marks a read-only key as an analytics key, for research and dashboards
+// that read in bulk. The key keeps its value and owner; only its rate limit
+// changes, on its next request.
+func GrantAnalyticsHandler(db *gorm.DB) http.HandlerFunc {
+	return setAnalyticsHandler(db, true)
+}
+
+// RevokeAnalyticsHandler handles DELETE /v0/admin/readkeys/{id}/analytics
+// Returns an analytics key to the read-only key limit.
+func RevokeAnalyticsHandler(db *gorm.DB) http.HandlerFunc {
+	return setAnalyticsHandler(db, false)
+}
+
+func setAnalyticsHandler(db *gorm.DB, analytics bool) http.HandlerFunc {
+	action := audit.ActionAnalyticsKeyRevoked
+	if analytics {
+		action = audit.ActionAnalyticsKeyGranted
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid key ID")
+			return
+		}
+
+		var key models.ReadAPIKey
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("id = ? AND revoked_at IS NULL", id).First(&key).Error; err != nil {
+				return err
+			}
+			if key.Analytics == analytics {
+				return nil
+			}
+			before := key
+			if err := tx.Model(&key).Update("analytics", analytics).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, action, audit.Target("read_key", key.ID), before, key)
+		})
+		if err == gorm.ErrRecordNotFound {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Key not found")
+			return
+		}
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update key")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"key":     key,
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package serieshandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"socialpredict/errors"
+	agentshandlers "socialpredict/handlers/agents"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/marketcreation"
+	"socialpredict/services/series"
+	"socialpredict/setup"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// CreateSeriesRequest is the request body for creating a market series
+// together with its markets
+type CreateSeriesRequest struct {
+	Title       string `json:"title" validate:"required,max=160"`
+	Description string `json:"description" validate:"max=2000"`
+	// At most one market of a mutually exclusive series resolves YES
+	MutuallyExclusive bool                                      `json:"mutuallyExclusive"`
+	Markets           []agentshandlers.AgentCreateMarketRequest `json:"markets" validate:"required,min=2,max=20,dive"`
+}
+
+// Normalize trims the title and description and normalizes each market.
+func (r *CreateSeriesRequest) Normalize() {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Description = strings.TrimSpace(r.Description)
+	for i := range r.Markets {
+		r.Markets[i].Normalize()
+	}
+}
+
+// writeSeriesError answers with the response for an error from the series
+// service.
+func writeSeriesError(w http.ResponseWriter, err error) {
+	switch {
+	case stderrors.Is(err, series.ErrSeriesNotFound):
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Series not found")
+	case stderrors.Is(err, series.ErrNotCreator):
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the series' creator can add markets to it")
+	case stderrors.Is(err, series.ErrSeriesFull), stderrors.Is(err, series.ErrSeriesSettled):
+		response.Error(w, http.StatusConflict, response.CodeConflict, err.Error())
+	case series.IsInvalid(err):
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+	}
+}
+
+// seriesID returns the series ID of the request, having written the error
+// response if it has none.
+func seriesID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["seriesId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid series ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateSeriesHandler handles POST /v0/series
+// The agent creates a series and all of its markets at once; if any market
+// cannot be created, none is.
+func CreateSeriesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var req CreateSeriesRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		created := &models.MarketSeries{
+			Title:             req.Title,
+			Description:       req.Description,
+			CreatorAgentID:    agent.ID,
+			MutuallyExclusive: req.MutuallyExclusive,
+		}
+		inputs := make([]marketcreation.Input, len(req.Markets))
+		for i, market := range req.Markets {
+			inputs[i] = market.Input(agent.ID)
+		}
+		markets, err := series.Create(db, setup.EconomicsConfig, created, inputs)
+		if err != nil {
+			writeSeriesError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"series":  created,
+			"markets": markets,
+		})
+	}
+}
+
+// GetSeriesHandler handles GET /v0/series/{seriesId}
+// It returns the series, its markets in the order they were created and the
+// consensus of each. A mutually exclusive series also gives each binary
+// market's share of the series' total chance of YES.
+func GetSeriesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := seriesID(w, r)
+		if !ok {
+			return
+		}
+
+		found, err := series.Load(db, id)
+		if err != nil {
+			writeSeriesError(w, err)
+			return
+		}
+		markets, err := series.Markets(db, found.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch markets")
+			return
+		}
+		consensus, err := series.ConsensusOf(db, found, markets)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to compute consensus")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"series":    found,
+			"markets":   markets,
+			"consensus": consensus,
+		})
+	}
+}
+
+// AddSeriesMarketHandler handles POST /v0/series/{seriesId}/markets
+// The series' creator adds a market to it.
+func AddSeriesMarketHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+		id, ok := seriesID(w, r)
+		if !ok {
+			return
+		}
+
+		var req agentshandlers.AgentCreateMarketRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		market, err := series.AddMarket(db, setup.EconomicsConfig, id, agent.ID, req.Input(agent.ID))
+		if err != nil {
+			writeSeriesError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"market":  market,
+		})
+	}
+}
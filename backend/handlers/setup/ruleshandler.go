@@ -0,0 +1,205 @@
+package setup
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/response"
+	"socialpredict/services/auction"
+	"socialpredict/services/marketcreation"
+	"socialpredict/setup"
+	"socialpredict/validation"
+)
+
+// MarketRules bounds the markets agents create or submit.
+type MarketRules struct {
+	MinQuestionLength         int     `json:"minQuestionLength"`
+	MaxQuestionLength         int     `json:"maxQuestionLength"`
+	MinDescriptionLength      int     `json:"minDescriptionLength"`
+	MaxDescriptionLength      int     `json:"maxDescriptionLength"`
+	MaxLabelLength            int     `json:"maxLabelLength"`
+	MinimumFutureHours        float64 `json:"minimumFutureHours"`
+	MinInitialProbability     float64 `json:"minInitialProbability"`
+	MaxInitialProbability     float64 `json:"maxInitialProbability"`
+	DefaultInitialProbability float64 `json:"defaultInitialProbability"`
+	CreateMarketCost          int64   `json:"createMarketCost"`
+	ClosingAuctionMinutes     int     `json:"closingAuctionMinutes"` // sealed bids open this long before close
+}
+
+// BettingRules are the costs and limits of placing bets.
+type BettingRules struct {
+	MinimumBet         int64 `json:"minimumBet"`
+	InitialBetFee      int64 `json:"initialBetFee"`
+	BuySharesFee       int64 `json:"buySharesFee"`
+	SellSharesFee      int64 `json:"sellSharesFee"`
+	MaximumDebtAllowed int64 `json:"maximumDebtAllowed"`
+}
+
+// CouncilRules are the validator council's review policies per submission
+// type, the bar for joining it and the checks on submitted predictions.
+type CouncilRules struct {
+	ValidatorMinPredictions int64                         `json:"validatorMinPredictions"`
+	MinReasoningLength      int                           `json:"minReasoningLength"`
+	Policies                map[string]CouncilPolicyRules `json:"policies"`
+}
+
+// CouncilPolicyRules is the review policy for one submission type.
+type CouncilPolicyRules struct {
+	VotesRequired     int     `json:"votesRequired"`
+	ApprovalThreshold float64 `json:"approvalThreshold"`
+	VotingHours       float64 `json:"votingHours"`
+}
+
+// GovernanceRules are the voting rules for platform proposals.
+type GovernanceRules struct {
+	DefaultVotingDays int      `json:"defaultVotingDays"`
+	VoteThreshold     int64    `json:"voteThreshold"`
+	ApprovalPct       float64  `json:"approvalPct"`
+	EditMaxVotes      int64    `json:"editMaxVotes"`
+	VotingModes       []string `json:"votingModes"`
+	DefaultVotingMode string   `json:"defaultVotingMode"`
+}
+
+// PredictionRules are how revised predictions are scored.
+type PredictionRules struct {
+	LateFlipHours   float64 `json:"lateFlipHours"`
+	LateFlipPenalty float64 `json:"lateFlipPenalty"`
+}
+
+// DisputeRules are how long a resolved market can be disputed and how much
+// dispute weight sends it back to the council.
+type DisputeRules struct {
+	WindowHours    float64 `json:"windowHours"`
+	WeightRequired float64 `json:"weightRequired"`
+}
+
+// Rules is the response of GET /v0/rules.
+type Rules struct {
+	Markets     MarketRules                  `json:"markets"`
+	Betting     BettingRules                 `json:"betting"`
+	Council     CouncilRules                 `json:"council"`
+	Governance  GovernanceRules              `json:"governance"`
+	Predictions PredictionRules              `json:"predictions"`
+	Disputes    DisputeRules                 `json:"disputes"`
+	Requests    map[string]map[string]string `json:"requests"`
+	Routes      map[string]middleware.Policy `json:"routes,omitempty"` // auth, scopes, rate class and idempotency per endpoint
+}
+
+// councilSubmissionTypes are the submission types whose policies are
+// published, configured or not.
+var councilSubmissionTypes = []string{"market", "prediction", "resolution"}
+
+// GetRulesHandler handles GET /v0/rules
+// It returns the live values of every policy an agent has to respect, so
+// frameworks can adapt when governance changes the config instead of
+// guessing. requests maps an endpoint ("POST /v0/agents/register") to its
+// request DTO, whose validate tags are published as the field rules, and
+// routes, if not nil, returns the policy of every route.
+func GetRulesHandler(loadEconomicsConfig setup.EconConfigLoader, requests map[string]interface{}, routes func() map[string]middleware.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		config := loadEconomicsConfig()
+		if config == nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load economic config")
+			return
+		}
+
+		rules := BuildRules(config, requests)
+		if routes != nil {
+			rules.Routes = routes()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(rules); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to encode rules")
+		}
+	}
+}
+
+// BuildRules collects the rules from config and the request DTOs.
+func BuildRules(config *setup.EconomicConfig, requests map[string]interface{}) Rules {
+	economics := config.Economics
+	verification := config.Verification.OrDefaults()
+	governance := config.Governance.OrDefaults()
+	predictions := config.Predictions.OrDefaults()
+	disputes := config.Disputes.OrDefaults()
+
+	types := append([]string(nil), councilSubmissionTypes...)
+	for submissionType := range config.Council.Policies {
+		if !contains(types, submissionType) {
+			types = append(types, submissionType)
+		}
+	}
+	sort.Strings(types)
+
+	policies := make(map[string]CouncilPolicyRules, len(types))
+	for _, submissionType := range types {
+		policy := config.Council.PolicyFor(submissionType)
+		policies[submissionType] = CouncilPolicyRules{
+			VotesRequired:     policy.VotesRequired,
+			ApprovalThreshold: policy.ApprovalThreshold,
+			VotingHours:       policy.VotingHours,
+		}
+	}
+
+	requestRules := make(map[string]map[string]string, len(requests))
+	for endpoint, dto := range requests {
+		requestRules[endpoint] = validation.Describe(dto)
+	}
+
+	return Rules{
+		Markets: MarketRules{
+			MinQuestionLength:         verification.MinQuestionLength,
+			MaxQuestionLength:         marketcreation.MaxQuestionTitleLength,
+			MinDescriptionLength:      verification.MinDescriptionLength,
+			MaxDescriptionLength:      marketcreation.MaxDescriptionLength,
+			MaxLabelLength:            marketcreation.MaxLabelLength,
+			MinimumFutureHours:        marketcreation.MinimumFutureHours(config),
+			MinInitialProbability:     marketcreation.MinInitialProbability,
+			MaxInitialProbability:     marketcreation.MaxInitialProbability,
+			DefaultInitialProbability: economics.MarketCreation.InitialMarketProbability,
+			CreateMarketCost:          economics.MarketIncentives.CreateMarketCost,
+			ClosingAuctionMinutes:     int(auction.Window / time.Minute),
+		},
+		Betting: BettingRules{
+			MinimumBet:         economics.Betting.MinimumBet,
+			InitialBetFee:      economics.Betting.BetFees.InitialBetFee,
+			BuySharesFee:       economics.Betting.BetFees.BuySharesFee,
+			SellSharesFee:      economics.Betting.BetFees.SellSharesFee,
+			MaximumDebtAllowed: economics.User.MaximumDebtAllowed,
+		},
+		Council: CouncilRules{
+			ValidatorMinPredictions: verification.ValidatorMinPredictions,
+			MinReasoningLength:      verification.MinReasoningLength,
+			Policies:                policies,
+		},
+		Governance: GovernanceRules{
+			DefaultVotingDays: governance.DefaultVotingDays,
+			VoteThreshold:     governance.VoteThreshold,
+			ApprovalPct:       governance.ApprovalPct,
+			EditMaxVotes:      governance.EditMaxVotes,
+			VotingModes:       governance.VotingModes,
+			DefaultVotingMode: governance.DefaultVotingMode,
+		},
+		Predictions: PredictionRules{
+			LateFlipHours:   predictions.LateFlipHours,
+			LateFlipPenalty: predictions.LateFlipPenalty,
+		},
+		Disputes: DisputeRules{
+			WindowHours:    disputes.WindowHours,
+			WeightRequired: disputes.WeightRequired,
+		},
+		Requests: requestRules,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package setup
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"socialpredict/setup"
+)
+
+type testRequest struct {
+	Name     string `json:"name" validate:"required,min=3,max=50"`
+	Note     string `json:"note"`
+	Internal string `json:"-" validate:"required"`
+}
+
+func TestGetRulesHandler_PublishesLiveConfig(t *testing.T) {
+	config := &setup.EconomicConfig{}
+	config.Economics.MarketCreation.MinimumFutureHours = 2
+	config.Economics.Betting.MinimumBet = 3
+	config.Council.Policies = map[string]setup.CouncilPolicy{
+		"market": {VotesRequired: 4, ApprovalThreshold: 70, VotingHours: 12},
+	}
+	config.Verification.ValidatorMinPredictions = 9
+	config.Governance.DefaultVotingDays = 3
+
+	handler := GetRulesHandler(func() *setup.EconomicConfig { return config }, map[string]interface{}{
+		"POST /v0/test": testRequest{},
+	}, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/rules", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(rec.Body.Bytes(), &rules); err != nil {
+		t.Fatalf("decode rules: %v", err)
+	}
+	if rules.Markets.MinimumFutureHours != 2 || rules.Betting.MinimumBet != 3 {
+		t.Fatalf("expected configured economics, got %+v / %+v", rules.Markets, rules.Betting)
+	}
+	if rules.Markets.MinQuestionLength != setup.DefaultVerification.MinQuestionLength {
+		t.Fatalf("expected unset verification rule to use its default, got %d", rules.Markets.MinQuestionLength)
+	}
+	if rules.Council.ValidatorMinPredictions != 9 || rules.Council.Policies["market"].VotesRequired != 4 {
+		t.Fatalf("unexpected council rules: %+v", rules.Council)
+	}
+	if got := rules.Council.Policies["resolution"]; got.VotesRequired != setup.DefaultCouncilPolicy.VotesRequired {
+		t.Fatalf("expected unconfigured submission type to use the default policy, got %+v", got)
+	}
+	if rules.Governance.DefaultVotingDays != 3 || rules.Governance.VoteThreshold != setup.DefaultGovernance.VoteThreshold {
+		t.Fatalf("unexpected governance rules: %+v", rules.Governance)
+	}
+
+	fields := rules.Requests["POST /v0/test"]
+	if len(fields) != 1 || fields["name"] != "required,min=3,max=50" {
+		t.Fatalf("expected only the name rule to be published, got %v", fields)
+	}
+
+	// Governance changing the config shows up on the next request.
+	config.Verification.ValidatorMinPredictions = 20
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v0/rules", nil))
+	json.Unmarshal(rec.Body.Bytes(), &rules)
+	if rules.Council.ValidatorMinPredictions != 20 {
+		t.Fatalf("expected updated validator minimum, got %d", rules.Council.ValidatorMinPredictions)
+	}
+}
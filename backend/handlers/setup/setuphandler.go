@@ -3,6 +3,7 @@ package setup
 import (
 	"encoding/json"
 	"net/http"
+	"socialpredict/response"
 	"socialpredict/setup"
 )
 
@@ -10,14 +11,14 @@ func GetSetupHandler(loadEconomicsConfig func() (*setup.EconomicConfig, error))
 	return func(w http.ResponseWriter, r *http.Request) {
 		appConfig, err := loadEconomicsConfig()
 		if err != nil {
-			http.Error(w, "Failed to load economic config", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load economic config")
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		err = json.NewEncoder(w).Encode(appConfig.Economics)
 		if err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to encode response")
 		}
 	}
 }
@@ -34,19 +35,19 @@ func GetFrontendSetupHandler(loadEconomicsConfig func() (*setup.EconomicConfig,
 	return func(w http.ResponseWriter, r *http.Request) {
 		_, err := loadEconomicsConfig()
 		if err != nil {
-			http.Error(w, "Failed to load frontend config", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load frontend config")
 			return
 		}
 
-		response := frontendConfigResponse{
+		resp := frontendConfigResponse{
 			Charts: frontendChartsResponse{
 				SigFigs: setup.ChartSigFigs(),
 			},
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Failed to encode frontend setup response", http.StatusInternalServerError)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to encode frontend setup response")
 			return
 		}
 	}
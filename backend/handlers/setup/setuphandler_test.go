@@ -35,8 +35,8 @@ func TestGetSetupHandler(t *testing.T) {
 				return nil, http.ErrBodyNotAllowed
 			},
 			ExpectedStatus:   http.StatusInternalServerError,
-			ExpectedResponse: "Failed to load economic config",
-			IsJSONResponse:   false,
+			ExpectedResponse: `{"error":{"code":"INTERNAL_ERROR","message":"Failed to load economic config"}}`,
+			IsJSONResponse:   true,
 		},
 	}
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/setup"
 	"socialpredict/util"
 
@@ -45,30 +46,30 @@ type StatsResponse struct {
 func StatsHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
-		db := util.GetDB()
+		db := util.GetReadDB()
 
 		// Calculate financial stats
 		financialStats, err := calculateFinancialStats(db)
 		if err != nil {
-			http.Error(w, "Failed to calculate financial stats: "+err.Error(), http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to calculate financial stats: "+err.Error())
 			return
 		}
 
 		// Load setup configuration
 		setupConfig, err := loadSetupConfiguration()
 		if err != nil {
-			http.Error(w, "Failed to load setup configuration: "+err.Error(), http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load setup configuration: "+err.Error())
 			return
 		}
 
-		response := StatsResponse{
+		resp := StatsResponse{
 			FinancialStats:     financialStats,
 			SetupConfiguration: setupConfig,
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Failed to encode stats response: "+err.Error(), http.StatusInternalServerError)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to encode stats response: "+err.Error())
 		}
 	}
 }
@@ -0,0 +1,345 @@
+package teamshandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/teams"
+	"socialpredict/validation"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// InviteRequest is the request body for inviting an agent to a team
+type InviteRequest struct {
+	AgentID int64 `json:"agentId" validate:"required,gt=0"`
+}
+
+// TeamMemberEntry is an active member of a team with its scores.
+type TeamMemberEntry struct {
+	AgentID        int64   `json:"agentId"`
+	AgentName      string  `json:"agentName"`
+	Captain        bool    `json:"captain"`
+	CompositeScore float64 `json:"compositeScore"`
+	AccuracyScore  float64 `json:"accuracyScore"`
+}
+
+// writeTeamError answers with the response for an error from the teams
+// service.
+func writeTeamError(w http.ResponseWriter, err error) {
+	switch {
+	case stderrors.Is(err, teams.ErrTeamNotFound):
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Team not found")
+	case stderrors.Is(err, teams.ErrAgentNotFound):
+		response.Error(w, http.StatusNotFound, response.CodeAgentNotFound, "Agent not found")
+	case stderrors.Is(err, teams.ErrNotMember):
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Agent is not on this team")
+	case stderrors.Is(err, teams.ErrNameTaken):
+		response.Error(w, http.StatusConflict, response.CodeNameTaken, "Team name already taken")
+	case stderrors.Is(err, teams.ErrOnAnotherTeam):
+		response.Error(w, http.StatusConflict, response.CodeConflict, "Agent is already on a team; leave it first")
+	case stderrors.Is(err, teams.ErrAlreadyMember):
+		response.Error(w, http.StatusConflict, response.CodeConflict, "Agent is already on this team")
+	case stderrors.Is(err, teams.ErrNotCaptain):
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the team captain can do this")
+	case stderrors.Is(err, teams.ErrNotInvited):
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "Agent has not been invited to this team")
+	default:
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+	}
+}
+
+// teamID returns the team ID of the request, having written the error
+// response if it has none.
+func teamID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["teamId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid team ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateTeamHandler handles POST /v0/teams
+// The agent founds a team and becomes its captain. An agent already on a
+// team must leave it first.
+func CreateTeamHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var req models.TeamRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		team, err := teams.Create(db, agent.ID, req.Name, req.Description)
+		if err != nil {
+			writeTeamError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"team":    team,
+		})
+	}
+}
+
+// GetTeamHandler handles GET /v0/teams/{teamId}
+// It returns the team, its active members, best first, and its aggregate
+// score.
+func GetTeamHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := teamID(w, r)
+		if !ok {
+			return
+		}
+
+		team, err := teams.Load(db, id)
+		if err != nil {
+			writeTeamError(w, err)
+			return
+		}
+		agents, err := teams.Members(db, team.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch members")
+			return
+		}
+		score, err := teams.Score(db, team.ID)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to score team")
+			return
+		}
+
+		members := make([]TeamMemberEntry, len(agents))
+		for i, agent := range agents {
+			members[i] = TeamMemberEntry{
+				AgentID:        agent.ID,
+				AgentName:      agent.Name,
+				Captain:        agent.ID == team.CaptainID,
+				CompositeScore: agent.CompositeScore,
+				AccuracyScore:  agent.AccuracyScore,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"team":    team,
+			"members": members,
+			"score":   score,
+		})
+	}
+}
+
+// TeamLeaderboardHandler handles GET /v0/teams/leaderboard
+// Teams are ranked on their members' average composite score, or with
+// ?sort=best on their best member's.
+func TeamLeaderboardHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+				page = parsed
+			}
+		}
+		pageSize := 50
+		if ps := r.URL.Query().Get("pageSize"); ps != "" {
+			if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+				pageSize = parsed
+			}
+		}
+
+		entries, total, sortBy, err := teams.Leaderboard(db, r.URL.Query().Get("sort"), pageSize, (page-1)*pageSize)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch leaderboard")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"leaderboard": entries,
+			"totalTeams":  total,
+			"sortBy":      sortBy,
+			"page":        page,
+			"pageSize":    pageSize,
+		})
+	}
+}
+
+// GetTeamPredictionsHandler handles GET /v0/teams/{teamId}/predictions
+// It lists the predictions tagged with the team, newest first.
+func GetTeamPredictionsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := teamID(w, r)
+		if !ok {
+			return
+		}
+		if _, err := teams.Load(db, id); err != nil {
+			writeTeamError(w, err)
+			return
+		}
+
+		limit := 50
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+				limit = parsed
+			}
+		}
+		offset := 0
+		if o := r.URL.Query().Get("offset"); o != "" {
+			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+				offset = parsed
+			}
+		}
+
+		var predictions []models.Prediction
+		if err := models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+			Preload("Agent").
+			Preload("Market").
+			Where("team_id = ?", id).
+			Order("predicted_at DESC").
+			Limit(limit).
+			Offset(offset).
+			Find(&predictions).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch predictions")
+			return
+		}
+		var total int64
+		if err := models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+			Where("team_id = ?", id).
+			Count(&total).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch predictions")
+			return
+		}
+
+		publicPredictions := make([]models.PredictionPublic, len(predictions))
+		for i, p := range predictions {
+			publicPredictions[i] = p.ToPublic()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"predictions": publicPredictions,
+			"total":       total,
+			"limit":       limit,
+			"offset":      offset,
+		})
+	}
+}
+
+// InviteHandler handles POST /v0/teams/{teamId}/invites
+// The team's captain invites a claimed agent, who is notified and joins by
+// accepting at POST /v0/teams/{teamId}/join.
+func InviteHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+		id, ok := teamID(w, r)
+		if !ok {
+			return
+		}
+
+		var req InviteRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		invite, err := teams.Invite(db, id, agent.ID, req.AgentID)
+		if err != nil {
+			writeTeamError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"invite":  invite,
+		})
+	}
+}
+
+// JoinTeamHandler handles POST /v0/teams/{teamId}/join
+// The agent accepts its invitation to the team. Its predictions from then
+// on are tagged with the team.
+func JoinTeamHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+		id, ok := teamID(w, r)
+		if !ok {
+			return
+		}
+
+		member, err := teams.Join(db, id, agent.ID)
+		if err != nil {
+			writeTeamError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"member":  member,
+		})
+	}
+}
+
+// RemoveMemberHandler handles DELETE /v0/teams/{teamId}/members/{agentId}
+// An agent leaves the team, or declines its invitation, by removing itself;
+// the captain may remove anyone. A captain who leaves hands the team to its
+// longest-standing member, and a team left empty is disbanded.
+func RemoveMemberHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateAgentAPIKey(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+		id, ok := teamID(w, r)
+		if !ok {
+			return
+		}
+		memberID, err := strconv.ParseInt(mux.Vars(r)["agentId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid agent ID")
+			return
+		}
+
+		team, err := teams.Remove(db, id, agent.ID, memberID)
+		if err != nil {
+			writeTeamError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"team":      team,
+			"disbanded": team == nil,
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package templateshandlers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/marketcreation"
+	"socialpredict/services/templates"
+	"socialpredict/setup"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// recentMarkets is how many of a template's markets GetTemplateHandler
+// returns.
+const recentMarkets = 20
+
+// writeTemplateError answers with the response for an error from the
+// templates service.
+func writeTemplateError(w http.ResponseWriter, err error) {
+	switch {
+	case stderrors.Is(err, templates.ErrTemplateNotFound):
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Market template not found")
+	case stderrors.Is(err, templates.ErrNotCreator):
+		response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the template's creator can change it or create its markets")
+	case stderrors.Is(err, templates.ErrInvalidTemplate),
+		stderrors.Is(err, templates.ErrScheduledOnly),
+		stderrors.Is(err, templates.ErrPaused),
+		stderrors.Is(err, templates.ErrMarketOpen):
+		response.Error(w, http.StatusConflict, response.CodeConflict, err.Error())
+	case marketcreation.IsValidationError(err):
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+	}
+}
+
+// templateID returns the template ID of the request, having written the
+// error response if it has none.
+func templateID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["templateId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid template ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// ListTemplatesHandler handles GET /v0/templates
+// It lists approved market templates, newest first, optionally only those
+// with the given status or creator.
+func ListTemplatesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := db.Model(&models.MarketTemplate{})
+		switch status := r.URL.Query().Get("status"); status {
+		case "":
+		case models.TemplateActive, models.TemplatePaused:
+			query = query.Where("status = ?", status)
+		default:
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "status must be active or paused")
+			return
+		}
+		if creator := r.URL.Query().Get("creatorAgentId"); creator != "" {
+			creatorID, err := strconv.ParseInt(creator, 10, 64)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid creator agent ID")
+				return
+			}
+			query = query.Where("creator_agent_id = ?", creatorID)
+		}
+
+		limit := 50
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+				limit = parsed
+			}
+		}
+		offset := 0
+		if o := r.URL.Query().Get("offset"); o != "" {
+			if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+				offset = parsed
+			}
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch templates")
+			return
+		}
+		var found []models.MarketTemplate
+		if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&found).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch templates")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"templates": found,
+			"total":     total,
+			"limit":     limit,
+			"offset":    offset,
+		})
+	}
+}
+
+// GetTemplateHandler handles GET /v0/templates/{templateId}
+// It returns the template, the market it creates and its latest markets,
+// newest first.
+func GetTemplateHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := templateID(w, r)
+		if !ok {
+			return
+		}
+
+		template, err := templates.Load(db, id)
+		if err != nil {
+			writeTemplateError(w, err)
+			return
+		}
+		spec, err := template.Request()
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to decode template")
+			return
+		}
+		markets, err := templates.Markets(db, template.ID, recentMarkets)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch markets")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"template": template,
+			"spec":     spec,
+			"markets":  markets,
+		})
+	}
+}
+
+// PauseTemplateHandler handles POST /v0/templates/{templateId}/pause
+// The template's creator stops it creating markets; the markets it already
+// created are unaffected.
+func PauseTemplateHandler(db *gorm.DB) http.HandlerFunc {
+	return setStatusHandler(db, models.TemplatePaused)
+}
+
+// ResumeTemplateHandler handles POST /v0/templates/{templateId}/resume
+// The template's creator resumes a paused template. Its next market is due
+// the next time its schedule comes round; missed runs are not made up.
+func ResumeTemplateHandler(db *gorm.DB) http.HandlerFunc {
+	return setStatusHandler(db, models.TemplateActive)
+}
+
+// CreateMarketNowHandler handles POST /v0/templates/{templateId}/markets
+// The creator of a daily or realtime template opens its next market now
+// instead of waiting for the schedule. The market skips council review, as
+// the council approved it with the template.
+func CreateMarketNowHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+		id, ok := templateID(w, r)
+		if !ok {
+			return
+		}
+
+		market, err := templates.CreateNow(db, setup.EconomicsConfig, id, agent.ID, time.Now())
+		if err != nil {
+			writeTemplateError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"market":  market,
+		})
+	}
+}
+
+func setStatusHandler(db *gorm.DB, status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+		id, ok := templateID(w, r)
+		if !ok {
+			return
+		}
+
+		template, err := templates.SetStatus(db, id, agent.ID, status, time.Now())
+		if err != nil {
+			writeTemplateError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"template": template,
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"socialpredict/middleware"
+	"socialpredict/response"
 	"socialpredict/security"
 	"socialpredict/util"
 )
@@ -14,7 +15,7 @@ type ChangeDescriptionRequest struct {
 
 func ChangeDescription(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+		response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 		return
 	}
 
@@ -24,32 +25,32 @@ func ChangeDescription(w http.ResponseWriter, r *http.Request) {
 	db := util.GetDB()
 	user, httperr := middleware.ValidateTokenAndGetUser(r, db)
 	if httperr != nil {
-		http.Error(w, "Invalid token: "+httperr.Error(), http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid token: "+httperr.Error())
 		return
 	}
 
 	var request ChangeDescriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
 	// Validate description length and content
 	if len(request.Description) > 2000 {
-		http.Error(w, "Description exceeds maximum length of 2000 characters", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Description exceeds maximum length of 2000 characters")
 		return
 	}
 
 	// Sanitize the description to prevent XSS
 	sanitizedDescription, err := securityService.Sanitizer.SanitizeDescription(request.Description)
 	if err != nil {
-		http.Error(w, "Invalid description: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid description: "+err.Error())
 		return
 	}
 
 	user.Description = sanitizedDescription
 	if err := db.Save(&user).Error; err != nil {
-		http.Error(w, "Failed to update description: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update description: "+err.Error())
 		return
 	}
 
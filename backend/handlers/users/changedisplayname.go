@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"socialpredict/middleware"
+	"socialpredict/response"
 	"socialpredict/security"
 	"socialpredict/util"
 )
@@ -14,7 +15,7 @@ type ChangeDisplayNameRequest struct {
 
 func ChangeDisplayName(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+		response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 		return
 	}
 
@@ -24,32 +25,32 @@ func ChangeDisplayName(w http.ResponseWriter, r *http.Request) {
 	db := util.GetDB()
 	user, httperr := middleware.ValidateTokenAndGetUser(r, db)
 	if httperr != nil {
-		http.Error(w, "Invalid token: "+httperr.Error(), http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid token: "+httperr.Error())
 		return
 	}
 
 	var request ChangeDisplayNameRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
 	// Validate display name length and content
 	if len(request.DisplayName) > 50 || len(request.DisplayName) < 1 {
-		http.Error(w, "Display name must be between 1 and 50 characters", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Display name must be between 1 and 50 characters")
 		return
 	}
 
 	// Sanitize the display name to prevent XSS
 	sanitizedDisplayName, err := securityService.Sanitizer.SanitizeDisplayName(request.DisplayName)
 	if err != nil {
-		http.Error(w, "Invalid display name: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid display name: "+err.Error())
 		return
 	}
 
 	user.DisplayName = sanitizedDisplayName
 	if err := db.Save(&user).Error; err != nil {
-		http.Error(w, "Failed to update display name: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update display name: "+err.Error())
 		return
 	}
 
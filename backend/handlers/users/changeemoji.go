@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"socialpredict/middleware"
+	"socialpredict/response"
 	"socialpredict/security"
 	"socialpredict/util"
 )
@@ -14,7 +15,7 @@ type ChangeEmojiRequest struct {
 
 func ChangeEmoji(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+		response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 		return
 	}
 
@@ -24,37 +25,37 @@ func ChangeEmoji(w http.ResponseWriter, r *http.Request) {
 	db := util.GetDB()
 	user, httperr := middleware.ValidateTokenAndGetUser(r, db)
 	if httperr != nil {
-		http.Error(w, "Invalid token: "+httperr.Error(), http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid token: "+httperr.Error())
 		return
 	}
 
 	var request ChangeEmojiRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
 	// Validate emoji length and content
 	if len(request.Emoji) > 20 {
-		http.Error(w, "Emoji exceeds maximum length of 20 characters", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Emoji exceeds maximum length of 20 characters")
 		return
 	}
 
 	if request.Emoji == "" {
-		http.Error(w, "Emoji cannot be blank", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Emoji cannot be blank")
 		return
 	}
 
 	// Sanitize the emoji to prevent XSS
 	sanitizedEmoji, err := securityService.Sanitizer.SanitizeEmoji(request.Emoji)
 	if err != nil {
-		http.Error(w, "Invalid emoji: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid emoji: "+err.Error())
 		return
 	}
 
 	user.PersonalEmoji = sanitizedEmoji
 	if err := db.Save(&user).Error; err != nil {
-		http.Error(w, "Failed to update emoji: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update emoji: "+err.Error())
 		return
 	}
 
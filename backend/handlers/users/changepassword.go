@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"socialpredict/logger"
 	"socialpredict/middleware"
+	"socialpredict/response"
 	"socialpredict/security"
 	"socialpredict/util"
 
@@ -18,7 +19,7 @@ type ChangePasswordRequest struct {
 
 func ChangePassword(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+		response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 		return
 	}
 
@@ -30,49 +31,49 @@ func ChangePassword(w http.ResponseWriter, r *http.Request) {
 	db := util.GetDB()
 	user, httperr := middleware.ValidateTokenAndGetUser(r, db)
 	if httperr != nil {
-		http.Error(w, "Invalid token: "+httperr.Error(), http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid token: "+httperr.Error())
 		logger.LogError("ChangePassword", "ValidateTokenAndGetUser", httperr)
 		return
 	}
 
 	var req ChangePasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Error decoding request body", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Error decoding request body")
 		logger.LogError("ChangePassword", "DecodeRequestBody", err)
 		return
 	}
 
 	// Validate input fields
 	if req.CurrentPassword == "" {
-		http.Error(w, "Current password is required", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Current password is required")
 		logger.LogError("ChangePassword", "ValidateInputFields", fmt.Errorf("Current password is required"))
 		return
 	}
 
 	if req.NewPassword == "" {
-		http.Error(w, "New password is required", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "New password is required")
 		logger.LogError("ChangePassword", "ValidateInputFields", fmt.Errorf("New password is required"))
 		return
 	}
 
 	// Check if the current password is correct
 	if !user.CheckPasswordHash(req.CurrentPassword) {
-		http.Error(w, "Current password is incorrect", http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Current password is incorrect")
 		logger.LogError("ChangePassword", "CheckPasswordHash", fmt.Errorf("Current password is incorrect"))
 		return
 	}
 
 	// Validate new password strength
 	if _, err := securityService.Sanitizer.SanitizePassword(req.NewPassword); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		// http.Error(w, "New password does not meet security requirements: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+		// response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "New password does not meet security requirements: "+err.Error())
 		logger.LogError("ChangePassword", "ValidateNewPasswordStrength", err)
 		return
 	}
 
 	// Hash the new password
 	if err := user.HashPassword(req.NewPassword); err != nil {
-		http.Error(w, "Failed to hash new password", http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to hash new password")
 		logger.LogError("ChangePassword", "HashNewPassword", err)
 		return
 	}
@@ -82,7 +83,7 @@ func ChangePassword(w http.ResponseWriter, r *http.Request) {
 
 	// Update the password and MustChangePassword in the database
 	if result := db.Save(&user); result.Error != nil {
-		http.Error(w, "Failed to update password", http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update password")
 		logger.LogError("ChangePassword", "UpdatePasswordInDB", result.Error)
 		return
 	}
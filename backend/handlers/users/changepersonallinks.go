@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"socialpredict/middleware"
+	"socialpredict/response"
 	"socialpredict/security"
 	"socialpredict/util"
 )
@@ -18,7 +19,7 @@ type ChangePersonalLinksRequest struct {
 
 func ChangePersonalLinks(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+		response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 		return
 	}
 
@@ -28,13 +29,13 @@ func ChangePersonalLinks(w http.ResponseWriter, r *http.Request) {
 	db := util.GetDB()
 	user, httperr := middleware.ValidateTokenAndGetUser(r, db)
 	if httperr != nil {
-		http.Error(w, "Invalid token: "+httperr.Error(), http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid token: "+httperr.Error())
 		return
 	}
 
 	var request ChangePersonalLinksRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
@@ -53,14 +54,14 @@ func ChangePersonalLinks(w http.ResponseWriter, r *http.Request) {
 
 		// Validate link length
 		if len(link) > 200 {
-			http.Error(w, "Personal link exceeds maximum length of 200 characters", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Personal link exceeds maximum length of 200 characters")
 			return
 		}
 
 		// Sanitize the link
 		sanitizedLink, err := securityService.Sanitizer.SanitizePersonalLink(link)
 		if err != nil {
-			http.Error(w, "Invalid personal link: "+err.Error(), http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid personal link: "+err.Error())
 			return
 		}
 		sanitizedLinks[i] = sanitizedLink
@@ -79,7 +80,7 @@ func ChangePersonalLinks(w http.ResponseWriter, r *http.Request) {
 		"PersonalLink3": user.PersonalLink3,
 		"PersonalLink4": user.PersonalLink4,
 	}).Error; err != nil {
-		http.Error(w, "Failed to update personal links: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update personal links: "+err.Error())
 		return
 	}
 
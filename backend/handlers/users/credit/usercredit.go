@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"socialpredict/handlers/users/publicuser"
+	"socialpredict/response"
 	"socialpredict/setup"
 	"socialpredict/util"
 
@@ -31,7 +32,7 @@ type UserCredit struct {
 func GetUserCreditHandler(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+		response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 		return
 	}
 
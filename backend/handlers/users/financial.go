@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"socialpredict/handlers/math/financials"
 	"socialpredict/handlers/users/publicuser"
+	"socialpredict/response"
 	"socialpredict/setup"
 	"socialpredict/util"
 
@@ -18,7 +19,7 @@ import (
 func GetUserFinancialHandlerWithDB(db *gorm.DB, econConfigLoader func() (*setup.EconomicConfig, error)) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method is not supported.", http.StatusMethodNotAllowed)
+			response.Error(w, http.StatusMethodNotAllowed, response.CodeMethodNotAllowed, "Method is not supported.")
 			return
 		}
 
@@ -27,7 +28,7 @@ func GetUserFinancialHandlerWithDB(db *gorm.DB, econConfigLoader func() (*setup.
 		username := vars["username"]
 
 		if username == "" {
-			http.Error(w, "Username parameter is required", http.StatusBadRequest)
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Username parameter is required")
 			return
 		}
 
@@ -38,7 +39,7 @@ func GetUserFinancialHandlerWithDB(db *gorm.DB, econConfigLoader func() (*setup.
 		econ, err := econConfigLoader()
 		if err != nil {
 			log.Printf("Error loading economic config: %v", err)
-			http.Error(w, "Unable to load configuration", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Unable to load configuration")
 			return
 		}
 
@@ -46,7 +47,7 @@ func GetUserFinancialHandlerWithDB(db *gorm.DB, econConfigLoader func() (*setup.
 		snapshot, err := financials.ComputeUserFinancials(db, username, userPublicInfo.AccountBalance, econ)
 		if err != nil {
 			log.Printf("Error generating user financial snapshot: %v", err)
-			http.Error(w, "Unable to generate financial snapshot", http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Unable to generate financial snapshot")
 			return
 		}
 
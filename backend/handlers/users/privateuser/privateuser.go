@@ -6,6 +6,7 @@ import (
 	"socialpredict/handlers/users/publicuser"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/util"
 )
 
@@ -33,7 +34,7 @@ func GetPrivateProfileUserResponse(w http.ResponseWriter, r *http.Request) {
 	// Validate the token and get the user
 	user, httperr := middleware.ValidateTokenAndGetUser(r, db)
 	if httperr != nil {
-		http.Error(w, "Invalid token: "+httperr.Error(), http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid token: "+httperr.Error())
 		return
 	}
 
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	positionsmath "socialpredict/handlers/math/positions"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/util"
 	"sort"
 	"strconv"
@@ -39,7 +40,7 @@ func GetPortfolio(w http.ResponseWriter, r *http.Request) {
 	userbets, err := fetchUserBets(db, username)
 	if err != nil {
 		log.Printf("Error fetching user bets: %v", err)
-		http.Error(w, "Error fetching user bets", http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error fetching user bets")
 		return
 	}
 
@@ -50,7 +51,7 @@ func GetPortfolio(w http.ResponseWriter, r *http.Request) {
 	userPositionsPortfolio, err := processMarketMap(db, marketMap, username)
 	if err != nil {
 		log.Printf("Error processing market map: %v", err)
-		http.Error(w, "Error processing market map", http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error processing market map")
 		return
 	}
 
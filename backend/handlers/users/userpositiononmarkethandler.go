@@ -5,6 +5,7 @@ import (
 	"net/http"
 	positionsmath "socialpredict/handlers/math/positions"
 	"socialpredict/middleware"
+	"socialpredict/response"
 	"socialpredict/util"
 
 	"github.com/gorilla/mux"
@@ -18,13 +19,13 @@ func UserMarketPositionHandler(w http.ResponseWriter, r *http.Request) {
 	db := util.GetDB()
 	user, httperr := middleware.ValidateTokenAndGetUser(r, db)
 	if httperr != nil {
-		http.Error(w, "Invalid token: "+httperr.Error(), http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeUnauthorized, "Invalid token: "+httperr.Error())
 		return
 	}
 
 	userPosition, err := positionsmath.CalculateMarketPositionForUser_WPAM_DBPM(db, marketId, user.Username)
 	if err != nil {
-		http.Error(w, "Error calculating user market position: "+err.Error(), http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error calculating user market position: "+err.Error())
 		return
 	}
 
@@ -0,0 +1,30 @@
+package verification
+
+import (
+	"context"
+
+	"socialpredict/models"
+	"socialpredict/services/contentsafety"
+	"socialpredict/services/platformconfig"
+
+	"gorm.io/gorm"
+)
+
+// contentSafetyCheck scans a submission's text for prohibited content.
+// Blocked content fails the submission; flagged content goes to the council
+// with a warning. The check is set in setup.yaml's contentSafety section
+// rather than as a verification rule.
+func contentSafetyCheck(db *gorm.DB, fields ...contentsafety.Field) VerificationCheck {
+	result := contentsafety.Scan(context.Background(), platformconfig.Current(db).ContentSafety, fields...)
+	check := VerificationCheck{Name: "content_safety", Passed: true, Reason: "No prohibited content found", Severity: models.RuleSeverityReject}
+	switch {
+	case result.Blocked():
+		check.Passed = false
+		check.Reason = "Prohibited content: " + result.Reason()
+	case result.Flagged():
+		check.Passed = false
+		check.Severity = models.RuleSeverityWarn
+		check.Reason = "Flagged for review: " + result.Reason()
+	}
+	return check
+}
@@ -0,0 +1,361 @@
+package verification
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/notifications"
+	"socialpredict/repository"
+	"socialpredict/response"
+	"socialpredict/validation"
+)
+
+// submissionCommentType is the comment type of submission comments in
+// comment.reply notifications.
+const submissionCommentType = "submission_comment"
+
+// SubmissionCommentRequest is the request body for commenting on a
+// submission.
+type SubmissionCommentRequest struct {
+	Content  string `json:"content" validate:"required,max=2000"`
+	ParentID *int64 `json:"parentId" validate:"omitempty,gt=0"`
+}
+
+// Normalize trims the comment.
+func (r *SubmissionCommentRequest) Normalize() {
+	r.Content = strings.TrimSpace(r.Content)
+}
+
+// ChangeRequest is the request body for asking a submitter for changes.
+type ChangeRequest struct {
+	Request string `json:"request" validate:"required,max=2000"` // what to change
+}
+
+// Normalize trims the request.
+func (r *ChangeRequest) Normalize() {
+	r.Request = strings.TrimSpace(r.Request)
+}
+
+var (
+	// errNotRevisable is returned for revising an open submission the
+	// council has not asked to change.
+	errNotRevisable   = stderrors.New("the council has not asked for changes to this submission")
+	errAlreadyRevised = stderrors.New("submission was already revised")
+)
+
+// revisable returns why original cannot be revised, or nil if it can. An
+// open submission the council asked to change can be, and so can a
+// rejected one; either only once.
+func revisable(original *PendingSubmission) error {
+	switch {
+	case original.SupersededBy != nil:
+		return errAlreadyRevised
+	case original.FinalStatus == "rejected":
+		return nil
+	case original.FinalStatus != "":
+		return errSubmissionClosed
+	case original.ChangesRequestedAt == nil:
+		return errNotRevisable
+	}
+	return nil
+}
+
+// insertSubmission stores submission using tx. A revision of original is
+// linked to it under original's row lock, so a submission is revised at
+// most once; an open original is closed as superseded, a rejected one stays
+// rejected.
+func insertSubmission(tx *gorm.DB, submission, original *PendingSubmission) error {
+	if original == nil {
+		return tx.Create(submission).Error
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(original, original.ID).Error; err != nil {
+		return err
+	}
+	if err := revisable(original); err != nil {
+		return err
+	}
+
+	submission.RevisionOf = &original.ID
+	submission.Revision = original.Revision + 1
+	if err := tx.Create(submission).Error; err != nil {
+		return err
+	}
+	original.SupersededBy = &submission.ID
+	if original.FinalStatus != "" {
+		return tx.Save(original).Error
+	}
+	now := time.Now()
+	original.CouncilStatus = "superseded"
+	original.FinalStatus = "superseded"
+	original.ResolvedAt = &now
+	return saveSubmission(tx, original, nil)
+}
+
+// submissionCreated writes the error response for err, the result of
+// storing a submission, and reports whether it was stored.
+func submissionCreated(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case stderrors.Is(err, errSubmissionClosed):
+		response.Error(w, http.StatusConflict, response.CodeConflict, "Submission was decided and cannot be revised")
+	case stderrors.Is(err, errNotRevisable), stderrors.Is(err, errAlreadyRevised):
+		response.Error(w, http.StatusConflict, response.CodeConflict, err.Error())
+	case repository.IsConflict(err):
+		response.Error(w, http.StatusConflict, response.CodeConflict, "Submission was updated concurrently, please retry")
+	default:
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create submission")
+	}
+	return false
+}
+
+// loadDiscussion authenticates the caller and loads the submission whose
+// discussion they want. Only validators, counted or on probation, and the
+// submitter take part; anyone else gets a 403 written for them.
+func loadDiscussion(w http.ResponseWriter, r *http.Request, db *gorm.DB) (agent *models.Agent, submission *PendingSubmission, ok bool) {
+	agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+	if httpErr != nil {
+		response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+		return nil, nil, false
+	}
+	submissionID, err := strconv.ParseInt(mux.Vars(r)["submissionId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid submission ID")
+		return nil, nil, false
+	}
+	submission = &PendingSubmission{}
+	if err := db.First(submission, submissionID).Error; err != nil {
+		response.Error(w, http.StatusNotFound, response.CodeSubmissionNotFound, "Submission not found")
+		return nil, nil, false
+	}
+	if submission.SubmitterAgentID == agent.ID {
+		return agent, submission, true
+	}
+
+	var validators int64
+	if err := db.Model(&ValidatorAgent{}).Where("agent_id = ? AND (is_active = ? OR on_probation = ?)", agent.ID, true, true).Count(&validators).Error; err != nil {
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Database error")
+		return nil, nil, false
+	}
+	if validators == 0 {
+		response.Error(w, http.StatusForbidden, response.CodeNotValidator, "Only council validators and the submitter can see this discussion")
+		return nil, nil, false
+	}
+	return agent, submission, true
+}
+
+// GetSubmissionCommentsHandler handles GET /v0/submissions/{submissionId}/comments
+// Returns the council's discussion of a submission, oldest first, to
+// validators and the submitter. Replies carry the parentId of the comment
+// they answer.
+func GetSubmissionCommentsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, submission, ok := loadDiscussion(w, r, db)
+		if !ok {
+			return
+		}
+
+		var comments []models.SubmissionComment
+		if err := db.Where("submission_id = ?", submission.ID).Order("id").Find(&comments).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch comments")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":            true,
+			"submissionId":       submission.ID,
+			"changesRequestedAt": submission.ChangesRequestedAt,
+			"revisionOf":         submission.RevisionOf,
+			"supersededBy":       submission.SupersededBy,
+			"comments":           comments,
+			"count":              len(comments),
+		})
+	}
+}
+
+// CommentOnSubmissionHandler handles POST /v0/submissions/{submissionId}/comments
+// A validator or the submitter adds to the discussion of an open
+// submission. The submitter is told of comments on its submission, and the
+// author of a comment of the replies to it.
+func CommentOnSubmissionHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, submission, ok := loadDiscussion(w, r, db)
+		if !ok {
+			return
+		}
+		if submission.FinalStatus != "" {
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Submission is closed")
+			return
+		}
+
+		var req SubmissionCommentRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		replyTo := submission.SubmitterAgentID
+		if req.ParentID != nil {
+			var parent models.SubmissionComment
+			if err := db.Where("id = ? AND submission_id = ?", *req.ParentID, submission.ID).First(&parent).Error; err != nil {
+				response.Error(w, http.StatusNotFound, response.CodeNotFound, "Parent comment not found")
+				return
+			}
+			replyTo = parent.AgentID
+		}
+
+		comment := models.SubmissionComment{
+			SubmissionID: submission.ID,
+			AgentID:      agent.ID,
+			ParentID:     req.ParentID,
+			Kind:         models.SubmissionCommentNote,
+			Content:      req.Content,
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&comment).Error; err != nil {
+				return err
+			}
+			if replyTo == agent.ID {
+				return nil
+			}
+			return notifications.SendCommentReply(tx, replyTo, submissionCommentNotice(&comment, agent))
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create comment")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"comment": comment,
+		})
+	}
+}
+
+// submissionCommentNotice is the comment.reply notification data for
+// comment by author.
+func submissionCommentNotice(comment *models.SubmissionComment, author *models.Agent) notifications.CommentNotice {
+	excerpt := comment.Content
+	if runes := []rune(excerpt); len(runes) > 200 {
+		excerpt = string(runes[:197]) + "..."
+	}
+	return notifications.CommentNotice{
+		CommentType: submissionCommentType,
+		CommentID:   comment.ID,
+		ParentType:  "submission",
+		ParentID:    comment.SubmissionID,
+		AuthorType:  string(models.ActorTypeAgent),
+		AuthorID:    author.ID,
+		AuthorName:  author.Name,
+		Excerpt:     excerpt,
+	}
+}
+
+// RequestChangesHandler handles POST /v0/council/request-changes/{submissionId}
+// A validator asks the submitter to revise a submission still open for
+// voting. The request is posted to the discussion and the submitter
+// notified; voting carries on until the submitter resubmits, which
+// supersedes the submission, or it is decided.
+func RequestChangesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, _, submission, ok := loadVoteTarget(w, r, db)
+		if !ok {
+			return
+		}
+
+		var req ChangeRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		comment := models.SubmissionComment{
+			SubmissionID: submission.ID,
+			AgentID:      agent.ID,
+			Kind:         models.SubmissionCommentChangeRequest,
+			Content:      req.Request,
+		}
+		err := withSubmissionLock(r.Context(), db, submission, func(tx *gorm.DB) error {
+			if err := tx.Create(&comment).Error; err != nil {
+				return err
+			}
+			now := time.Now()
+			submission.ChangesRequestedAt = &now
+			if err := tx.Save(submission).Error; err != nil {
+				return err
+			}
+			return notifications.SendSubmissionChangesRequested(tx, submission.SubmitterAgentID, notifications.SubmissionChangesRequested{
+				SubmissionID:   submission.ID,
+				SubmissionType: submission.SubmissionType,
+				ValidatorID:    agent.ID,
+				ValidatorName:  agent.Name,
+				Request:        req.Request,
+			})
+		})
+		if !voteSaved(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":            true,
+			"comment":            comment,
+			"changesRequestedAt": submission.ChangesRequestedAt,
+		})
+	}
+}
+
+// ResubmitHandler handles POST /v0/submissions/{submissionId}/resubmit
+// The submitter answers a request for changes, or a rejection, with a
+// revised payload of the same type, as for POST /v0/submit/market or
+// /v0/submit/template. The revision is verified and voted on afresh,
+// linked to the submission it revises; an open one is closed as
+// superseded.
+func ResubmitHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, original, ok := loadDiscussion(w, r, db)
+		if !ok {
+			return
+		}
+		if original.SubmitterAgentID != agent.ID {
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the submitter can resubmit")
+			return
+		}
+		if !submissionCreated(w, revisable(original)) {
+			return
+		}
+
+		switch original.SubmissionType {
+		case models.SubmissionTypeMarket:
+			var payload MarketPayload
+			if fields := validation.Decode(r, &payload); fields != nil {
+				errors.WriteValidationError(w, fields)
+				return
+			}
+			submitMarket(w, r, db, agent, payload, original)
+		case models.SubmissionTypeTemplate:
+			var payload TemplatePayload
+			if fields := validation.Decode(r, &payload); fields != nil {
+				errors.WriteValidationError(w, fields)
+				return
+			}
+			submitTemplate(w, r, db, agent, payload, original)
+		default:
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Only market and template submissions can be resubmitted")
+		}
+	}
+}
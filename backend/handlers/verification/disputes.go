@@ -0,0 +1,205 @@
+package verification
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"socialpredict/errors"
+	marketshandlers "socialpredict/handlers/markets"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/platformconfig"
+	"socialpredict/validation"
+)
+
+// ResolutionDisputeRequest is an agent's challenge to how a market was
+// resolved, with links to the evidence against it.
+type ResolutionDisputeRequest struct {
+	Reason   string   `json:"reason" validate:"required,max=2000"`
+	Evidence []string `json:"evidence" validate:"required,min=1,max=10,dive,required,url,max=500"`
+}
+
+// Normalize trims the reason and the evidence links.
+func (r *ResolutionDisputeRequest) Normalize() {
+	r.Reason = strings.TrimSpace(r.Reason)
+	for i, link := range r.Evidence {
+		r.Evidence[i] = strings.TrimSpace(link)
+	}
+}
+
+// ResolutionDisputeView is a dispute with its evidence decoded.
+type ResolutionDisputeView struct {
+	models.ResolutionDispute
+	Evidence []string `json:"evidence"`
+}
+
+// FileDisputeHandler handles POST /v0/markets/{marketId}/disputes
+// An agent who predicted on a resolved market disputes its outcome within
+// the dispute window. Disputes are weighted by the agent's accuracy; once
+// their combined weight reaches the configured requirement, the council
+// votes on the market's resolution again. A market is reviewed at most
+// once.
+func FileDisputeHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		marketID, err := strconv.ParseInt(mux.Vars(r)["marketId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+		var market models.Market
+		if err := db.First(&market, marketID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+			return
+		}
+		if !market.IsResolved {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Only a resolved market can be disputed")
+			return
+		}
+
+		rules := platformconfig.Current(db).Disputes.OrDefaults()
+		now := time.Now()
+		if now.After(market.FinalResolutionDateTime.Add(rules.Window())) {
+			response.Error(w, http.StatusConflict, response.CodeDisputeClosed, fmt.Sprintf("Markets can only be disputed within %g hours of resolving", rules.WindowHours))
+			return
+		}
+		var reviews int64
+		db.Model(&models.ResolutionRequest{}).Where("market_id = ? AND round > ?", market.ID, 1).Count(&reviews)
+		if reviews > 0 {
+			response.Error(w, http.StatusConflict, response.CodeDisputeClosed, "This market's resolution has already been sent back to the council")
+			return
+		}
+
+		var predictions int64
+		db.Model(&models.Prediction{}).Where("market_id = ? AND agent_id = ?", market.ID, agent.ID).Count(&predictions)
+		if predictions == 0 {
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only agents who predicted on this market can dispute it")
+			return
+		}
+		var existing models.ResolutionDispute
+		if err := db.Where("market_id = ? AND agent_id = ?", market.ID, agent.ID).First(&existing).Error; err == nil {
+			response.Error(w, http.StatusConflict, response.CodeAlreadyDisputed, "Already disputed this market")
+			return
+		}
+
+		var disputeReq ResolutionDisputeRequest
+		if fields := validation.Decode(r, &disputeReq); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		dispute := models.ResolutionDispute{
+			MarketID: market.ID,
+			AgentID:  agent.ID,
+			Outcome:  market.ResolutionResult,
+			Reason:   disputeReq.Reason,
+			Weight:   models.DisputeWeight(agent),
+		}
+		if err := dispute.SetEvidenceLinks(disputeReq.Evidence); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record dispute")
+			return
+		}
+
+		var weight float64
+		var review *models.ResolutionRequest
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&dispute).Error; err != nil {
+				return err
+			}
+			var err error
+			if weight, err = models.PendingDisputeWeight(tx, market.ID); err != nil {
+				return err
+			}
+			if weight < rules.WeightRequired {
+				return nil
+			}
+			review, err = reopenResolution(tx, &market, now)
+			return err
+		})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record dispute")
+			return
+		}
+
+		result := map[string]interface{}{
+			"success":        true,
+			"dispute":        dispute,
+			"disputeWeight":  weight,
+			"weightRequired": rules.WeightRequired,
+			"reopened":       review != nil,
+		}
+		if review != nil {
+			result["requestId"] = review.ID
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// reopenResolution sends market's resolution back to the council for a
+// second round and attaches the disputes that triggered it.
+func reopenResolution(tx *gorm.DB, market *models.Market, now time.Time) (*models.ResolutionRequest, error) {
+	request := newResolutionRequest(tx, market.ID, now)
+	request.Round = 2
+	request.DisputedOutcome = market.ResolutionResult
+	if err := tx.Create(&request).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.ResolutionDispute{}).
+		Where("market_id = ? AND request_id IS NULL", market.ID).
+		Update("request_id", request.ID).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetDisputesHandler handles GET /v0/markets/{marketId}/disputes
+// It returns the disputes filed against the market's resolution and the
+// evidence cited.
+func GetDisputesHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		marketID, err := strconv.ParseInt(mux.Vars(r)["marketId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid market ID")
+			return
+		}
+		if !marketshandlers.MarketOpenByID(w, r, db, mux.Vars(r)["marketId"]) {
+			return
+		}
+
+		var disputes []models.ResolutionDispute
+		if err := db.Where("market_id = ?", marketID).Order("id").Find(&disputes).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load disputes")
+			return
+		}
+		views := make([]ResolutionDisputeView, len(disputes))
+		for i, dispute := range disputes {
+			links, err := dispute.EvidenceLinks()
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load disputes")
+				return
+			}
+			views[i] = ResolutionDisputeView{ResolutionDispute: dispute, Evidence: links}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  true,
+			"disputes": views,
+			"count":    len(views),
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package verification
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"socialpredict/errors"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/validation"
+)
+
+// rejectionReasons counts the rejection reasons given in the counted reject
+// votes on the submission.
+func rejectionReasons(db *gorm.DB, submissionID int64) (map[string]int, error) {
+	var rows []struct {
+		RejectionReason string
+		Votes           int
+	}
+	if err := db.Model(&CouncilVote{}).
+		Select("rejection_reason, COUNT(*) AS votes").
+		Where("submission_id = ? AND vote = ? AND probation = ? AND rejection_reason <> ''", submissionID, "reject", false).
+		Group("rejection_reason").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	reasons := make(map[string]int, len(rows))
+	for _, row := range rows {
+		reasons[row.RejectionReason] = row.Votes
+	}
+	return reasons, nil
+}
+
+// ReviseMarketHandler handles POST /v0/submit/market/{submissionId}/revise
+// The submitter revises a rejected market submission, or one the council
+// asked to change, by sending only the fields to change. They are applied
+// over the original payload, nested fields such as resolutionCriteria
+// included, and the result is verified and submitted as a revision of it.
+func ReviseMarketHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, original, ok := loadDiscussion(w, r, db)
+		if !ok {
+			return
+		}
+		if original.SubmitterAgentID != agent.ID {
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, "Only the submitter can revise a submission")
+			return
+		}
+		if original.SubmissionType != models.SubmissionTypeMarket {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Only market submissions are revised here; use POST /v0/submissions/{submissionId}/resubmit")
+			return
+		}
+		if !submissionCreated(w, revisable(original)) {
+			return
+		}
+
+		var payload MarketPayload
+		if err := json.Unmarshal([]byte(original.Payload), &payload); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to read the original submission")
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			errors.WriteValidationError(w, []errors.FieldError{{Field: "body", Rule: "json", Message: "request body is not valid JSON: " + err.Error()}})
+			return
+		}
+		if fields := validation.Struct(&payload); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+		submitMarket(w, r, db, agent, payload, original)
+	}
+}
+
+// SubmitterRecord is how an agent's submissions fared with the council.
+type SubmitterRecord struct {
+	AgentID           int64          `json:"agentId"`
+	AgentName         string         `json:"agentName"`
+	Rejected          int            `json:"rejected"`
+	RejectedRevisions int            `json:"rejectedRevisions"` // rejected submissions that were themselves revisions
+	MaxRevision       int            `json:"maxRevision"`       // the highest revision among the rejected ones
+	Reasons           map[string]int `json:"reasons"`           // reject votes by rejection reason
+}
+
+// GetRepeatSubmittersHandler handles GET /v0/admin/submissions/repeat-offenders
+// Lists agents with at least minRejections (default 3) submissions rejected
+// in the last days (default 30), most rejections first, with how many of
+// them were revisions and the reasons validators gave. Agents who keep
+// resubmitting what the council keeps rejecting, for the same reasons,
+// stand out.
+func GetRepeatSubmittersHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		days := 30
+		if d := r.URL.Query().Get("days"); d != "" {
+			if parsed, err := strconv.Atoi(d); err == nil && parsed > 0 && parsed <= 365 {
+				days = parsed
+			}
+		}
+		minRejections := 3
+		if m := r.URL.Query().Get("minRejections"); m != "" {
+			if parsed, err := strconv.Atoi(m); err == nil && parsed > 0 {
+				minRejections = parsed
+			}
+		}
+		since := time.Now().AddDate(0, 0, -days)
+
+		var rows []struct {
+			SubmitterAgentID  int64
+			Rejected          int
+			RejectedRevisions int
+			MaxRevision       int
+		}
+		if err := db.Model(&PendingSubmission{}).
+			Select("submitter_agent_id, COUNT(*) AS rejected, SUM(CASE WHEN revision > 1 THEN 1 ELSE 0 END) AS rejected_revisions, MAX(revision) AS max_revision").
+			Where("final_status = ? AND resolved_at >= ?", "rejected", since).
+			Group("submitter_agent_id").
+			Having("COUNT(*) >= ?", minRejections).
+			Order("rejected DESC, submitter_agent_id").
+			Limit(100).
+			Scan(&rows).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch submissions")
+			return
+		}
+
+		records := make([]SubmitterRecord, 0, len(rows))
+		byAgent := make(map[int64]*SubmitterRecord, len(rows))
+		ids := make([]int64, 0, len(rows))
+		for _, row := range rows {
+			records = append(records, SubmitterRecord{
+				AgentID:           row.SubmitterAgentID,
+				Rejected:          row.Rejected,
+				RejectedRevisions: row.RejectedRevisions,
+				MaxRevision:       row.MaxRevision,
+				Reasons:           map[string]int{},
+			})
+			ids = append(ids, row.SubmitterAgentID)
+		}
+		for i := range records {
+			byAgent[records[i].AgentID] = &records[i]
+		}
+
+		if len(ids) > 0 {
+			var agents []models.Agent
+			if err := db.Select("id", "name").Where("id IN ?", ids).Find(&agents).Error; err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch agents")
+				return
+			}
+			for _, agent := range agents {
+				byAgent[agent.ID].AgentName = agent.Name
+			}
+
+			var reasons []struct {
+				SubmitterAgentID int64
+				RejectionReason  string
+				Votes            int
+			}
+			if err := db.Model(&CouncilVote{}).
+				Select("pending_submissions.submitter_agent_id, council_votes.rejection_reason, COUNT(*) AS votes").
+				Joins("JOIN pending_submissions ON pending_submissions.id = council_votes.submission_id").
+				Where("pending_submissions.submitter_agent_id IN ? AND pending_submissions.final_status = ? AND pending_submissions.resolved_at >= ?", ids, "rejected", since).
+				Where("council_votes.vote = ? AND council_votes.probation = ? AND council_votes.rejection_reason <> ''", "reject", false).
+				Group("pending_submissions.submitter_agent_id, council_votes.rejection_reason").
+				Scan(&reasons).Error; err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch rejection reasons")
+				return
+			}
+			for _, reason := range reasons {
+				byAgent[reason.SubmitterAgentID].Reasons[reason.RejectionReason] = reason.Votes
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":       true,
+			"submitters":    records,
+			"count":         len(records),
+			"days":          days,
+			"minRejections": minRejections,
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"socialpredict/models"
+	"socialpredict/services/contentsafety"
+	"socialpredict/services/marketcreation"
+	"socialpredict/services/platformconfig"
+	"socialpredict/services/similarity"
+	"socialpredict/services/verificationrules"
+	"socialpredict/setup"
+)
+
+// marketCheck is a built-in market check. Its severity can be changed, or
+// the check turned off, through services/verificationrules.
+type marketCheck struct {
+	name        string
+	description string
+	run         func(*marketCheckInput) (passed bool, reason string)
+}
+
+// marketCheckInput is the submission the market checks run on, with the
+// thresholds in effect.
+type marketCheckInput struct {
+	payload MarketPayload
+	db      *gorm.DB
+	rules   setup.Verification
+	// Existing markets most similar to the submission, set by no_duplicate
+	similar []similarity.Match
+}
+
+// marketChecks are the built-in market checks in the order they run.
+var marketChecks = []marketCheck{
+	{"future_resolution_date", "The resolution date is in the future", checkFutureResolutionDate},
+	{"question_length", "The question is between verification.minQuestionLength and the maximum length", checkQuestionLength},
+	{"description_length", "The description is at least verification.minDescriptionLength long", checkDescriptionLength},
+	{"initial_probability", "The initial probability is between 1% and 99%", checkInitialProbability},
+	{"no_duplicate", "No existing market is at least verification.duplicateSimilarity similar", checkNoDuplicate},
+	{"resolution_criteria", "The resolution criteria give a source, threshold, timezone and tie-breaker", checkResolutionCriteria},
+	{"market_input", "The market passes the validation it gets when it is created", checkMarketInput},
+}
+
+func init() {
+	for _, c := range marketChecks {
+		verificationrules.RegisterCheck(c.name, c.description)
+	}
+}
+
+// verifyMarket runs FREE verification checks (no paid APIs): the built-in
+// checks that are not off, the content safety scan, then the keyword and
+// regex rules. A failed check
+// that only warns is listed in Warnings and does not fail the submission.
+func verifyMarket(payload MarketPayload, db *gorm.DB) VerificationResult {
+	in := &marketCheckInput{
+		payload: payload,
+		db:      db,
+		rules:   platformconfig.Current(db).Verification.OrDefaults(),
+	}
+	rules := verificationrules.Current(db)
+
+	var checks []VerificationCheck
+	for _, c := range marketChecks {
+		severity := rules.Severity(c.name)
+		if severity == models.RuleSeverityOff {
+			continue
+		}
+		passed, reason := c.run(in)
+		checks = append(checks, VerificationCheck{Name: c.name, Passed: passed, Reason: reason, Severity: severity})
+	}
+	checks = append(checks, contentSafetyCheck(db,
+		contentsafety.Field{Name: "questionTitle", Text: payload.QuestionTitle},
+		contentsafety.Field{Name: "description", Text: payload.Description}))
+	for _, rule := range rules.Matchers() {
+		check := VerificationCheck{Name: rule.Name, Passed: true, Reason: "Matches none of the rule's " + rule.Kind + " patterns", Severity: rule.Severity}
+		if pattern, matched := rule.Match(payload.QuestionTitle, payload.Description); matched {
+			message := rule.Message
+			if message == "" {
+				message = "Matches a " + rule.Kind + " rule"
+			}
+			check.Passed = false
+			check.Reason = fmt.Sprintf("%s (matched %q)", message, pattern)
+		}
+		checks = append(checks, check)
+	}
+
+	result := verificationResult(checks)
+	result.SimilarMarkets = in.similar
+	return result
+}
+
+// verificationResult fails the submission on any failed check that does
+// not only warn.
+func verificationResult(checks []VerificationCheck) VerificationResult {
+	result := VerificationResult{Passed: true, Checks: checks}
+	for _, check := range checks {
+		if check.Passed {
+			continue
+		}
+		message := fmt.Sprintf("%s: %s", check.Name, check.Reason)
+		if check.Severity == models.RuleSeverityWarn {
+			result.Warnings = append(result.Warnings, message)
+			continue
+		}
+		result.Passed = false
+		result.Errors = append(result.Errors, message)
+	}
+	return result
+}
+
+func checkFutureResolutionDate(in *marketCheckInput) (bool, string) {
+	resDate, err := time.Parse(time.RFC3339, in.payload.ResolutionDateTime)
+	if err != nil {
+		return false, "Invalid date format (use RFC3339: 2026-12-31T23:59:59Z)"
+	}
+	if resDate.Before(time.Now()) {
+		return false, fmt.Sprintf("Resolution date %s is in the past", in.payload.ResolutionDateTime)
+	}
+	return true, "Resolution date is in the future"
+}
+
+func checkQuestionLength(in *marketCheckInput) (bool, string) {
+	if len(in.payload.QuestionTitle) < in.rules.MinQuestionLength {
+		return false, fmt.Sprintf("Question too short (minimum %d characters)", in.rules.MinQuestionLength)
+	}
+	if len(in.payload.QuestionTitle) > marketcreation.MaxQuestionTitleLength {
+		return false, fmt.Sprintf("Question too long (maximum %d characters)", marketcreation.MaxQuestionTitleLength)
+	}
+	return true, "Question length OK"
+}
+
+func checkDescriptionLength(in *marketCheckInput) (bool, string) {
+	if len(in.payload.Description) < in.rules.MinDescriptionLength {
+		return false, "Description too short - must include clear resolution criteria"
+	}
+	return true, "Description provided"
+}
+
+func checkInitialProbability(in *marketCheckInput) (bool, string) {
+	p := in.payload.InitialProbability
+	if p < marketcreation.MinInitialProbability || p > marketcreation.MaxInitialProbability {
+		return false, "Initial probability must be between 1% and 99%"
+	}
+	return true, "Initial probability is reasonable"
+}
+
+// checkNoDuplicate reports similar existing markets so the council can
+// compare them; only a near match fails.
+func checkNoDuplicate(in *marketCheckInput) (bool, string) {
+	similar, err := similarity.Similar(context.Background(), in.db, in.payload.QuestionTitle, in.rules.SimilarMarketsShown, in.rules.SimilarMarketMinScore)
+	if err != nil {
+		return false, "Could not check for duplicate markets"
+	}
+	in.similar = similar
+	if len(similar) > 0 && similar[0].Score >= in.rules.DuplicateSimilarity {
+		return false, fmt.Sprintf("Market %d %q is too similar (%.0f%% similar)", similar[0].MarketID, similar[0].QuestionTitle, similar[0].Score*100)
+	}
+	if len(similar) > 0 {
+		return true, fmt.Sprintf("No duplicate markets found (%d similar markets listed for comparison)", len(similar))
+	}
+	return true, "No duplicate markets found"
+}
+
+// checkMarketInput checks that the payload passes the same validation and
+// sanitization the market will go through when it is created after
+// approval.
+func checkMarketInput(in *marketCheckInput) (bool, string) {
+	input, err := in.payload.creationInput(0)
+	if err != nil {
+		return false, "Invalid resolution date"
+	}
+	if _, err := newMarketCreation(in.db).Prepare(input, nil); err != nil {
+		return false, err.Error()
+	}
+	return true, "Market input is valid"
+}
+
+// checkResolutionCriteria checks that every resolution criterion is given:
+// an http(s) source of truth, the exact threshold, an IANA timezone and a
+// tie-breaking rule.
+func checkResolutionCriteria(in *marketCheckInput) (bool, string) {
+	c := in.payload.ResolutionCriteria
+	if c == nil {
+		c = &models.ResolutionCriteria{}
+	}
+
+	var problems []string
+	if c.SourceURL == "" {
+		problems = append(problems, "sourceUrl is missing")
+	} else if u, err := url.Parse(c.SourceURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, "sourceUrl must be an http(s) URL")
+	}
+	if c.Threshold == "" {
+		problems = append(problems, "threshold is missing")
+	}
+	if c.Timezone == "" {
+		problems = append(problems, "timezone is missing")
+	} else if _, err := time.LoadLocation(c.Timezone); err != nil || c.Timezone == "Local" {
+		problems = append(problems, fmt.Sprintf("timezone %q is not an IANA timezone", c.Timezone))
+	}
+	if c.TieBreaker == "" {
+		problems = append(problems, "tieBreaker is missing")
+	}
+
+	if len(problems) > 0 {
+		return false, "Resolution criteria: " + strings.Join(problems, "; ")
+	}
+	return true, "Resolution criteria are complete"
+}
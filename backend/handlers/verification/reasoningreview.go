@@ -0,0 +1,174 @@
+package verification
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/scoring"
+	"socialpredict/validation"
+)
+
+var (
+	errOwnPrediction = stderrors.New("cannot review the reasoning of your own prediction")
+	errNoReasoning   = stderrors.New("prediction has no reasoning to review")
+)
+
+// loadReviewer authenticates the validator spot reviewing reasoning,
+// counted or on probation. If it is not one it writes the error response
+// and returns ok=false.
+func loadReviewer(w http.ResponseWriter, r *http.Request, db *gorm.DB) (agent *models.Agent, ok bool) {
+	agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+	if httpErr != nil {
+		response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+		return nil, false
+	}
+	var validators int64
+	if err := db.Model(&ValidatorAgent{}).Where("agent_id = ? AND (is_active = ? OR on_probation = ?)", agent.ID, true, true).Count(&validators).Error; err != nil {
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to check validator")
+		return nil, false
+	}
+	if validators == 0 {
+		response.Error(w, http.StatusForbidden, response.CodeNotValidator, "Agent is not an active council validator")
+		return nil, false
+	}
+	return agent, true
+}
+
+// GetReasoningReviewQueueHandler handles GET /v0/council/reasoning/queue
+// Lists predictions whose reasoning the validator can spot review: other
+// agents' predictions with reasoning it has not reviewed at their current
+// revision, least reviewed first, then newest. ?limit= defaults to 20, at
+// most 100.
+func GetReasoningReviewQueueHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, ok := loadReviewer(w, r, db)
+		if !ok {
+			return
+		}
+		limit := 20
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
+				limit = parsed
+			}
+		}
+
+		reviewed := db.Model(&models.ReasoningReview{}).Select("id").
+			Where("prediction_id = predictions.id AND revision = predictions.revision AND validator_agent_id = ?", agent.ID)
+		var predictions []models.Prediction
+		if err := models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+			Preload("Agent").Preload("Market").
+			Where("agent_id <> ? AND reasoning <> ''", agent.ID).
+			Where("NOT EXISTS (?)", reviewed).
+			Order("reasoning_reviews ASC, predicted_at DESC").
+			Limit(limit).
+			Find(&predictions).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch reasoning to review")
+			return
+		}
+
+		public := make([]models.PredictionPublic, len(predictions))
+		for i := range predictions {
+			public[i] = predictions[i].ToPublic()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"predictions": public,
+		})
+	}
+}
+
+// ReviewReasoningHandler handles POST /v0/council/reasoning/{predictionId}/review
+// Records a validator's rating of a prediction's reasoning at its current
+// revision, replacing the validator's earlier rating of that revision, then
+// rescores the reasoning and its author.
+func ReviewReasoningHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, ok := loadReviewer(w, r, db)
+		if !ok {
+			return
+		}
+		predictionID, err := strconv.ParseInt(mux.Vars(r)["predictionId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid prediction ID")
+			return
+		}
+		var req models.ReasoningReviewRequest
+		if fields := validation.Decode(r, &req); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		var prediction models.Prediction
+		var review models.ReasoningReview
+		err = db.WithContext(r.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&prediction, predictionID).Error; err != nil {
+				return err
+			}
+			if prediction.AgentID == agent.ID {
+				return errOwnPrediction
+			}
+			if prediction.Reasoning == "" {
+				return errNoReasoning
+			}
+
+			err := tx.Where("prediction_id = ? AND revision = ? AND validator_agent_id = ?", prediction.ID, prediction.Revision, agent.ID).
+				First(&review).Error
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return err
+			}
+			review.PredictionID, review.Revision, review.ValidatorAgentID = prediction.ID, prediction.Revision, agent.ID
+			review.Rating, review.Note = req.Rating, req.Note
+			if err := tx.Save(&review).Error; err != nil {
+				return err
+			}
+
+			var ratings []int
+			if err := tx.Model(&models.ReasoningReview{}).
+				Where("prediction_id = ? AND revision = ?", prediction.ID, prediction.Revision).
+				Pluck("rating", &ratings).Error; err != nil {
+				return err
+			}
+			prediction.ScoreReasoning(ratings)
+			if err := tx.Model(&prediction).Updates(map[string]interface{}{
+				"reasoning_score":   prediction.ReasoningScore,
+				"reasoning_reviews": prediction.ReasoningReviews,
+			}).Error; err != nil {
+				return err
+			}
+			_, err = scoring.Recompute(r.Context(), tx, prediction.AgentID, nil)
+			return err
+		})
+		switch {
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Prediction not found")
+			return
+		case stderrors.Is(err, errOwnPrediction):
+			response.Error(w, http.StatusForbidden, response.CodeOwnSubmission, "Cannot review the reasoning of your own prediction")
+			return
+		case stderrors.Is(err, errNoReasoning):
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Prediction has no reasoning to review")
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record review")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":          true,
+			"review":           review,
+			"reasoningScore":   prediction.ReasoningScore,
+			"reasoningReviews": prediction.ReasoningReviews,
+		})
+	}
+}
@@ -0,0 +1,483 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"socialpredict/audit"
+	"socialpredict/errors"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/repository"
+	"socialpredict/response"
+	"socialpredict/services/platformconfig"
+	"socialpredict/services/resolution"
+	"socialpredict/validation"
+)
+
+// ResolutionVoteRequest is a validator's vote on how a market resolved,
+// with links to the evidence for it.
+type ResolutionVoteRequest struct {
+	Outcome  string   `json:"outcome" validate:"required,oneof=YES NO N/A"`
+	Evidence []string `json:"evidence" validate:"required,min=1,max=10,dive,required,url,max=500"`
+	Reason   string   `json:"reason" validate:"max=2000"`
+}
+
+// Normalize upper-cases the outcome and trims the evidence links.
+func (r *ResolutionVoteRequest) Normalize() {
+	r.Outcome = strings.ToUpper(strings.TrimSpace(r.Outcome))
+	if r.Outcome == "NA" {
+		r.Outcome = resolution.OutcomeNA
+	}
+	for i, link := range r.Evidence {
+		r.Evidence[i] = strings.TrimSpace(link)
+	}
+}
+
+// ResolutionVoteView is a resolution vote with its evidence decoded.
+type ResolutionVoteView struct {
+	models.ResolutionVote
+	Evidence []string `json:"evidence"`
+}
+
+// ResolutionRequestView is a resolution request with the market it is for
+// and how the vote stands.
+type ResolutionRequestView struct {
+	models.ResolutionRequest
+	QuestionTitle string               `json:"questionTitle"`
+	Leading       string               `json:"leading,omitempty"`
+	LeadingPct    float64              `json:"leadingPct"`
+	Votes         []ResolutionVoteView `json:"votes,omitempty"`
+}
+
+// OpenResolutionRequests asks the council to resolve every unresolved
+// market whose resolution date has passed by now. Markets that resolve
+// themselves from an oracle are left to the auto-resolver, and scalar
+// markets, which the council cannot vote a value for, to their creator or
+// an admin. Each request is
+// stamped with the council's resolution policy. It returns how many
+// requests were opened.
+func OpenResolutionRequests(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+
+	var marketIDs []int64
+	requested := db.Model(&models.ResolutionRequest{}).Select("market_id")
+	if err := db.Model(&models.Market{}).
+		Where("is_resolved = ? AND auto_resolve = ? AND resolution_date_time <= ?", false, false, now).
+		Where("outcome_type <> ?", models.OutcomeScalar).
+		Where("id NOT IN (?)", requested).
+		Order("id").
+		Pluck("id", &marketIDs).Error; err != nil {
+		return 0, err
+	}
+
+	opened := 0
+	for _, marketID := range marketIDs {
+		if err := ctx.Err(); err != nil {
+			return opened, err
+		}
+		request := newResolutionRequest(db, marketID, now)
+		if err := db.Create(&request).Error; err != nil {
+			return opened, err
+		}
+		opened++
+	}
+	return opened, nil
+}
+
+// newResolutionRequest builds a first-round resolution request for the
+// market with the council's resolution policy stamped on, so later policy
+// changes do not affect votes in progress.
+func newResolutionRequest(db *gorm.DB, marketID int64, now time.Time) models.ResolutionRequest {
+	policy := platformconfig.Current(db).Council.PolicyFor(models.SubmissionTypeResolution)
+	return models.ResolutionRequest{
+		MarketID:          marketID,
+		Status:            models.ResolutionRequestVoting,
+		Round:             1,
+		VotesRequired:     policy.VotesRequired,
+		MinVoters:         policy.MinVoters,
+		ApprovalThreshold: policy.ApprovalThreshold,
+		VotingEndsAt:      now.Add(policy.VotingDuration()),
+	}
+}
+
+// VoteOnResolutionHandler handles POST /v0/council/resolutions/{requestId}/vote
+// An active validator votes YES, NO or N/A with links to their evidence.
+// Once enough validators have voted and one outcome holds the approval
+// threshold of the vote weight, the market is resolved with it through the
+// resolution pipeline, which pays out and scores its predictions.
+func VoteOnResolutionHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, validator, request, ok := loadResolutionVoteTarget(w, r, db)
+		if !ok {
+			return
+		}
+
+		var existing models.ResolutionVote
+		if err := db.Where("request_id = ? AND validator_id = ?", request.ID, agent.ID).First(&existing).Error; err == nil {
+			response.Error(w, http.StatusConflict, response.CodeAlreadyVoted, "Already voted on this resolution")
+			return
+		}
+
+		var voteReq ResolutionVoteRequest
+		if fields := validation.Decode(r, &voteReq); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		vote := models.ResolutionVote{
+			RequestID:   request.ID,
+			ValidatorID: agent.ID,
+			Outcome:     voteReq.Outcome,
+			Reason:      voteReq.Reason,
+			Weight:      voteWeight(validator),
+		}
+		if err := vote.SetEvidenceLinks(voteReq.Evidence); err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record vote")
+			return
+		}
+		request.AddVote(vote.Outcome, vote.Weight)
+
+		now := time.Now()
+		validator.LastVotedAt = &now
+		validator.TotalValidations++
+		db.Save(validator)
+
+		err := settleResolution(r.Context(), db, request, &vote)
+		switch {
+		case stderrors.Is(err, resolution.ErrAlreadyResolved):
+			response.Error(w, http.StatusConflict, response.CodeMarketResolved, "Market was resolved before the council decided")
+			return
+		case repository.IsConflict(err):
+			response.Error(w, http.StatusConflict, response.CodeConflict, "Resolution was updated concurrently, please retry")
+			return
+		case err != nil:
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record vote")
+			return
+		}
+
+		leading, leadingPct := request.Leading()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"vote":       vote.Outcome,
+			"weight":     vote.Weight,
+			"votesYes":   request.VotesYes,
+			"votesNo":    request.VotesNo,
+			"votesNA":    request.VotesNA,
+			"leading":    leading,
+			"leadingPct": leadingPct,
+			"resolved":   request.Status == models.ResolutionRequestResolved,
+			"outcome":    request.Outcome,
+		})
+	}
+}
+
+// loadResolutionVoteTarget authenticates the voting validator and loads the
+// resolution request they are voting on. Validators on probation do not
+// vote on resolutions, and nobody votes on the resolution of a market they
+// created. Only a disputed resolution is voted on once the market is
+// resolved. If the vote cannot be cast it writes the error response and
+// returns ok=false.
+func loadResolutionVoteTarget(w http.ResponseWriter, r *http.Request, db *gorm.DB) (agent *models.Agent, validator *ValidatorAgent, request *models.ResolutionRequest, ok bool) {
+	agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+	if httpErr != nil {
+		response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+		return nil, nil, nil, false
+	}
+
+	validator = &ValidatorAgent{}
+	if err := db.Where("agent_id = ? AND is_active = ?", agent.ID, true).First(validator).Error; err != nil {
+		response.Error(w, http.StatusForbidden, response.CodeNotValidator, "Only active council validators vote on resolutions")
+		return nil, nil, nil, false
+	}
+
+	requestID, err := strconv.ParseInt(mux.Vars(r)["requestId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid resolution request ID")
+		return nil, nil, nil, false
+	}
+	request = &models.ResolutionRequest{}
+	if err := db.First(request, requestID).Error; err != nil {
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Resolution request not found")
+		return nil, nil, nil, false
+	}
+	if request.Status != models.ResolutionRequestVoting || time.Now().After(request.VotingEndsAt) {
+		response.Error(w, http.StatusBadRequest, response.CodeVotingClosed, "Resolution is no longer open for voting")
+		return nil, nil, nil, false
+	}
+
+	var market models.Market
+	if err := db.First(&market, request.MarketID).Error; err != nil {
+		response.Error(w, http.StatusNotFound, response.CodeMarketNotFound, "Market not found")
+		return nil, nil, nil, false
+	}
+	if market.IsResolved && !request.IsDispute() {
+		response.Error(w, http.StatusConflict, response.CodeMarketResolved, "Market is already resolved")
+		return nil, nil, nil, false
+	}
+	if market.CreatedBy() == models.AgentActor(agent.ID) {
+		response.Error(w, http.StatusForbidden, response.CodeOwnSubmission, "Cannot vote on the resolution of your own market")
+		return nil, nil, nil, false
+	}
+
+	return agent, validator, request, true
+}
+
+// settleResolution saves request with vote, which is not stored yet,
+// resolving the market if enough validators have voted and one outcome has
+// reached the approval threshold.
+func settleResolution(ctx context.Context, db *gorm.DB, request *models.ResolutionRequest, vote *models.ResolutionVote) error {
+	if request.Voters() >= request.VotesRequired {
+		if outcome := request.Decided(); outcome != "" {
+			return resolveByCouncil(ctx, db, request, outcome, vote)
+		}
+	}
+	return saveResolutionRequest(db, request, vote)
+}
+
+// resolveByCouncil resolves request's market with outcome, saving request
+// and vote (if any) in the resolution transaction. A disputed resolution
+// that the council overturns re-resolves the market, taking back the scores
+// given for the disputed outcome; one it upholds leaves the market as it
+// is. The decision goes in the audit log under the validator whose vote
+// settled it. If the market was resolved some other way first, request is
+// superseded instead and resolution.ErrAlreadyResolved returned.
+func resolveByCouncil(ctx context.Context, db *gorm.DB, request *models.ResolutionRequest, outcome string, vote *models.ResolutionVote) error {
+	now := time.Now()
+	record := func(tx *gorm.DB, _ *models.Market) error {
+		// Work on copies so a rolled back attempt leaves nothing stale behind
+		// for the retry.
+		resolved := *request
+		resolved.Status = models.ResolutionRequestResolved
+		resolved.Outcome = outcome
+		resolved.ResolvedAt = &now
+		var saved *models.ResolutionVote
+		if vote != nil {
+			copied := *vote
+			saved = &copied
+		}
+		if err := saveResolutionRequest(tx, &resolved, saved); err != nil {
+			return err
+		}
+		var before interface{}
+		if request.IsDispute() {
+			before = map[string]string{"outcome": request.DisputedOutcome}
+		}
+		if err := audit.Record(tx, audit.ActionMarketCouncilResolved, audit.Target("market", request.MarketID), before, resolved); err != nil {
+			return err
+		}
+		*request = resolved
+		if saved != nil {
+			*vote = *saved
+		}
+		return nil
+	}
+
+	var err error
+	switch {
+	case request.IsDispute() && outcome == request.DisputedOutcome:
+		err = record(db.WithContext(ctx), nil)
+	case request.IsDispute():
+		_, err = resolution.Reresolve(ctx, db, request.MarketID, outcome, record)
+	default:
+		_, err = resolution.ResolveRecorded(ctx, db, request.MarketID, outcome, nil, record)
+	}
+	if !stderrors.Is(err, resolution.ErrAlreadyResolved) {
+		return err
+	}
+
+	var current models.ResolutionRequest
+	if err := db.First(&current, request.ID).Error; err != nil {
+		return err
+	}
+	current.Status = models.ResolutionRequestSuperseded
+	current.ResolvedAt = &now
+	if err := saveResolutionRequest(db, &current, nil); err != nil {
+		return err
+	}
+	*request = current
+	return resolution.ErrAlreadyResolved
+}
+
+// saveResolutionRequest persists request together with the vote that
+// changed it (if any) in one transaction. A stale request version rolls
+// back the vote too, so the validator can simply vote again.
+func saveResolutionRequest(db *gorm.DB, request *models.ResolutionRequest, vote *models.ResolutionVote) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if vote != nil {
+			if err := tx.Save(vote).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(request).Error
+	})
+}
+
+// ProcessExpiredResolutions settles every open resolution request: first
+// rounds whose market was resolved some other way are superseded, and
+// requests whose voting period has ended resolve the market with the
+// outcome that reached the approval threshold, given at least MinVoters
+// votes, or expire. An expired first round leaves the market for its
+// creator or an admin to resolve; an expired dispute leaves the outcome as
+// it was. It returns how many requests were settled; a request updated
+// concurrently is skipped and picked up on the next run.
+func ProcessExpiredResolutions(ctx context.Context, db *gorm.DB) (int, error) {
+	db = db.WithContext(ctx)
+	now := time.Now()
+
+	resolvedMarkets := db.Model(&models.Market{}).Select("id").Where("is_resolved = ?", true)
+	superseded := db.Model(&models.ResolutionRequest{}).
+		Where("status = ? AND round = ? AND market_id IN (?)", models.ResolutionRequestVoting, 1, resolvedMarkets).
+		Updates(map[string]interface{}{"status": models.ResolutionRequestSuperseded, "resolved_at": now})
+	if superseded.Error != nil {
+		return 0, superseded.Error
+	}
+	processed := int(superseded.RowsAffected)
+
+	var requests []models.ResolutionRequest
+	if err := db.Where("status = ? AND voting_ends_at < ?", models.ResolutionRequestVoting, now).Find(&requests).Error; err != nil {
+		return processed, err
+	}
+	for i := range requests {
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+		request := &requests[i]
+
+		var outcome string
+		if request.Voters() > 0 && request.Voters() >= request.MinVoters {
+			outcome = request.Decided()
+		}
+		var err error
+		if outcome == "" {
+			request.Status = models.ResolutionRequestExpired
+			request.ResolvedAt = &now
+			err = saveResolutionRequest(db, request, nil)
+		} else {
+			err = resolveByCouncil(ctx, db, request, outcome, nil)
+		}
+		if err != nil && !stderrors.Is(err, resolution.ErrAlreadyResolved) {
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// GetResolutionRequestsHandler handles GET /v0/council/resolutions
+// It lists resolution requests, open ones by default; ?status= picks
+// another status.
+func GetResolutionRequestsHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "":
+			status = models.ResolutionRequestVoting
+		case models.ResolutionRequestVoting, models.ResolutionRequestResolved, models.ResolutionRequestExpired, models.ResolutionRequestSuperseded:
+		default:
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "status must be voting, resolved, expired or superseded")
+			return
+		}
+
+		var requests []models.ResolutionRequest
+		if err := db.Where("status = ?", status).Order("created_at DESC").Limit(50).Find(&requests).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load resolution requests")
+			return
+		}
+		views, err := resolutionRequestViews(db, requests)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load resolution requests")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":     true,
+			"resolutions": views,
+			"count":       len(views),
+		})
+	}
+}
+
+// GetResolutionRequestHandler handles GET /v0/council/resolutions/{requestId}
+// It returns the request with every vote cast on it and the evidence cited.
+func GetResolutionRequestHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID, err := strconv.ParseInt(mux.Vars(r)["requestId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid resolution request ID")
+			return
+		}
+		var request models.ResolutionRequest
+		if err := db.First(&request, requestID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Resolution request not found")
+			return
+		}
+		views, err := resolutionRequestViews(db, []models.ResolutionRequest{request})
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load resolution request")
+			return
+		}
+		view := views[0]
+
+		var votes []models.ResolutionVote
+		if err := db.Where("request_id = ?", request.ID).Order("id").Find(&votes).Error; err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load resolution votes")
+			return
+		}
+		view.Votes = make([]ResolutionVoteView, len(votes))
+		for i, vote := range votes {
+			links, err := vote.EvidenceLinks()
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to load resolution votes")
+				return
+			}
+			view.Votes[i] = ResolutionVoteView{ResolutionVote: vote, Evidence: links}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"resolution": view,
+		})
+	}
+}
+
+// resolutionRequestViews pairs each request with its market's question and
+// the leading outcome.
+func resolutionRequestViews(db *gorm.DB, requests []models.ResolutionRequest) ([]ResolutionRequestView, error) {
+	marketIDs := make([]int64, len(requests))
+	for i, request := range requests {
+		marketIDs[i] = request.MarketID
+	}
+	var markets []models.Market
+	if len(marketIDs) > 0 {
+		if err := db.Unscoped().Select("id, question_title").Where("id IN ?", marketIDs).Find(&markets).Error; err != nil {
+			return nil, err
+		}
+	}
+	titles := make(map[int64]string, len(markets))
+	for _, market := range markets {
+		titles[market.ID] = market.QuestionTitle
+	}
+
+	views := make([]ResolutionRequestView, len(requests))
+	for i, request := range requests {
+		leading, leadingPct := request.Leading()
+		views[i] = ResolutionRequestView{
+			ResolutionRequest: request,
+			QuestionTitle:     titles[request.MarketID],
+			Leading:           leading,
+			LeadingPct:        leadingPct,
+		}
+	}
+	return views, nil
+}
@@ -0,0 +1,274 @@
+package verification
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"socialpredict/models"
+	"socialpredict/response"
+)
+
+const (
+	// maxSubmissionJobAttempts is how many times a submission job is tried
+	// before it fails for good.
+	maxSubmissionJobAttempts = 10
+	submissionJobBatchSize   = 50
+	submissionJobMaxBackoff  = time.Hour
+)
+
+// submissionJobKinds maps the submission types that are applied once
+// approved to the job that applies them.
+var submissionJobKinds = map[string]string{
+	models.SubmissionTypeMarket:     models.SubmissionJobCreateMarket,
+	models.SubmissionTypePrediction: models.SubmissionJobCreatePrediction,
+	models.SubmissionTypeTemplate:   models.SubmissionJobCreateTemplate,
+}
+
+// permanentError marks a job failure that retrying cannot fix, such as a
+// payload that does not parse.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error { return permanentError{err} }
+
+// enqueueSubmissionJob records, using tx, the job that applies the approved
+// submission. Submission types with nothing to apply get none.
+func enqueueSubmissionJob(tx *gorm.DB, submission *PendingSubmission) error {
+	kind, ok := submissionJobKinds[submission.SubmissionType]
+	if !ok {
+		return nil
+	}
+	return tx.Create(&models.SubmissionJob{
+		SubmissionID:  submission.ID,
+		Kind:          kind,
+		Status:        models.SubmissionJobPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// RunSubmissionJobs runs the submission jobs that are due at now and
+// returns how many succeeded.
+func RunSubmissionJobs(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	var ids []int64
+	if err := db.WithContext(ctx).Model(&models.SubmissionJob{}).
+		Where("status = ? AND next_attempt_at <= ?", models.SubmissionJobPending, now).
+		Order("id").
+		Limit(submissionJobBatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return succeeded, err
+		}
+		job, err := runSubmissionJob(ctx, db, id, now)
+		if err != nil {
+			return succeeded, err
+		}
+		if job != nil && job.Status == models.SubmissionJobSucceeded {
+			succeeded++
+		}
+	}
+	return succeeded, nil
+}
+
+// runSubmissionJob makes one attempt at the pending job id. The market or
+// prediction is created and the job marked succeeded in one transaction
+// holding the job's row lock (SKIP LOCKED on Postgres; SQLite ignores it),
+// so it is created exactly once however many instances run jobs. A failed
+// attempt is retried with backoff until the job runs out of attempts, or
+// fails for good straight away if retrying cannot help. It returns the job
+// as it now stands, or nil if it is no longer pending or another instance
+// is running it; the error is for failures to record the attempt.
+func runSubmissionJob(ctx context.Context, db *gorm.DB, id int64, now time.Time) (*models.SubmissionJob, error) {
+	var job models.SubmissionJob
+	var attemptErr error
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND status = ?", id, models.SubmissionJobPending).
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		resultID, err := applySubmissionJob(ctx, tx, &job)
+		if err != nil {
+			attemptErr = err
+			return err
+		}
+		job.Status = models.SubmissionJobSucceeded
+		job.Attempts++
+		job.ResultID = resultID
+		job.LastError = ""
+		job.CompletedAt = &now
+		return tx.Save(&job).Error
+	})
+	switch {
+	case attemptErr != nil:
+	case stderrors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		return &job, nil
+	}
+
+	// The attempt was rolled back; record it outside the transaction.
+	job.Attempts++
+	job.LastError = attemptErr.Error()
+	if len(job.LastError) > 500 {
+		job.LastError = job.LastError[:500]
+	}
+	job.NextAttemptAt = now.Add(submissionJobBackoff(job.Attempts))
+	var permanentErr permanentError
+	if stderrors.As(attemptErr, &permanentErr) || job.Attempts >= maxSubmissionJobAttempts {
+		job.Status = models.SubmissionJobFailed
+		job.CompletedAt = &now
+	}
+	if err := db.WithContext(ctx).Model(&models.SubmissionJob{}).
+		Where("id = ? AND status = ?", job.ID, models.SubmissionJobPending).
+		Updates(map[string]interface{}{
+			"status":          job.Status,
+			"attempts":        job.Attempts,
+			"last_error":      job.LastError,
+			"next_attempt_at": job.NextAttemptAt,
+			"completed_at":    job.CompletedAt,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("record submission job %d: %w", job.ID, err)
+	}
+	return &job, nil
+}
+
+// applySubmissionJob does the work of job using tx and returns the ID of
+// the market, prediction or template it created.
+func applySubmissionJob(ctx context.Context, tx *gorm.DB, job *models.SubmissionJob) (int64, error) {
+	var submission PendingSubmission
+	if err := tx.First(&submission, job.SubmissionID).Error; err != nil {
+		return 0, fmt.Errorf("load submission: %w", err)
+	}
+
+	switch job.Kind {
+	case models.SubmissionJobCreateMarket:
+		market, err := createApprovedMarket(tx, &submission)
+		if err != nil {
+			return 0, err
+		}
+		return market.ID, nil
+	case models.SubmissionJobCreatePrediction:
+		prediction, err := createApprovedPrediction(ctx, tx, &submission)
+		if err != nil {
+			return 0, err
+		}
+		return prediction.ID, nil
+	case models.SubmissionJobCreateTemplate:
+		template, err := createApprovedTemplate(tx, &submission)
+		if err != nil {
+			return 0, err
+		}
+		return template.ID, nil
+	default:
+		return 0, permanent(fmt.Errorf("unknown submission job kind %q", job.Kind))
+	}
+}
+
+// submissionJobBackoff doubles the retry delay with each failed attempt,
+// from a minute up to submissionJobMaxBackoff.
+func submissionJobBackoff(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < submissionJobMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > submissionJobMaxBackoff {
+		return submissionJobMaxBackoff
+	}
+	return delay
+}
+
+// applyApproved makes the first attempt at the job applying submission,
+// just approved, so the market, prediction or template is usually there by
+// the time the vote is answered, and describes the outcome. If the attempt
+// fails the scheduler retries the job.
+func applyApproved(ctx context.Context, db *gorm.DB, submission *PendingSubmission) string {
+	var job models.SubmissionJob
+	if err := db.WithContext(ctx).Where("submission_id = ?", submission.ID).First(&job).Error; err != nil {
+		return "Submission approved by council"
+	}
+	if ran, err := runSubmissionJob(ctx, db, job.ID, time.Now()); err == nil && ran != nil {
+		job = *ran
+	}
+	return describeSubmissionJob(&job)
+}
+
+// describeSubmissionJob describes where job has got to.
+func describeSubmissionJob(job *models.SubmissionJob) string {
+	what := "market"
+	switch job.Kind {
+	case models.SubmissionJobCreatePrediction:
+		what = "prediction"
+	case models.SubmissionJobCreateTemplate:
+		what = "market template"
+	}
+	switch {
+	case job.Status == models.SubmissionJobSucceeded && what == "market":
+		return fmt.Sprintf("Market created with ID %d", job.ResultID)
+	case job.Status == models.SubmissionJobSucceeded && what == "market template":
+		return fmt.Sprintf("Market template created with ID %d", job.ResultID)
+	case job.Status == models.SubmissionJobSucceeded:
+		return fmt.Sprintf("Prediction created with ID %d", job.ResultID)
+	case job.Status == models.SubmissionJobFailed:
+		return fmt.Sprintf("Failed to create %s: %s", what, job.LastError)
+	case job.Attempts > 0:
+		return fmt.Sprintf("Submission approved; creating the %s failed and will be retried: %s", what, job.LastError)
+	default:
+		return fmt.Sprintf("Submission approved; the %s is being created", what)
+	}
+}
+
+// GetSubmissionHandler handles GET /v0/submissions/{submissionId}
+// Returns a submission with, once approved, the job applying it: whether
+// the market, prediction or template has been created, how many attempts it
+// took and the last error if it is still being retried or failed.
+func GetSubmissionHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		submissionID, err := strconv.ParseInt(mux.Vars(r)["submissionId"], 10, 64)
+		if err != nil {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid submission ID")
+			return
+		}
+
+		var submission PendingSubmission
+		if err := db.First(&submission, submissionID).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "Submission not found")
+			return
+		}
+
+		var job *models.SubmissionJob
+		var found models.SubmissionJob
+		err = db.Where("submission_id = ?", submission.ID).First(&found).Error
+		switch {
+		case err == nil:
+			job = &found
+		case !stderrors.Is(err, gorm.ErrRecordNotFound):
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch submission job")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":    true,
+			"submission": submission,
+			"job":        job,
+		})
+	}
+}
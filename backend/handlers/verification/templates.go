@@ -0,0 +1,134 @@
+package verification
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"socialpredict/errors"
+	"socialpredict/i18n"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/services/marketcreation"
+	"socialpredict/services/platformconfig"
+	"socialpredict/services/templates"
+	"socialpredict/validation"
+)
+
+// TemplatePayload is the payload for market template submissions. Content
+// rules are reported by verifyTemplate.
+type TemplatePayload = models.MarketTemplateRequest
+
+// SubmitTemplateHandler handles POST /v0/submit/template
+// The council approves a recurring question once; the scheduler then
+// creates its markets without further review.
+func SubmitTemplateHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var payload TemplatePayload
+		if fields := validation.Decode(r, &payload); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+
+		submitTemplate(w, r, db, agent, payload, nil)
+	}
+}
+
+// submitTemplate verifies payload and submits it for council review. A
+// revision of original supersedes it.
+func submitTemplate(w http.ResponseWriter, r *http.Request, db *gorm.DB, agent *models.Agent, payload TemplatePayload, original *PendingSubmission) {
+	result := verifyTemplate(payload, db, time.Now())
+	if !result.Passed {
+		response.ErrorWithDetails(w, http.StatusBadRequest, response.CodeVerificationFailed, i18n.T(r, "verification.auto_failed"), result)
+		return
+	}
+	payloadJSON, _ := json.Marshal(payload)
+	resultJSON, _ := json.Marshal(result)
+
+	policy := platformconfig.Current(db).Council.PolicyFor(models.SubmissionTypeTemplate)
+	submission := newSubmission(models.SubmissionTypeTemplate, agent.ID, policy, time.Now())
+	submission.Payload = string(payloadJSON)
+	submission.AutoVerificationStatus = "passed"
+	submission.AutoVerificationResult = string(resultJSON)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return insertSubmission(tx, &submission, original)
+	})
+	if !submissionCreated(w, err) {
+		return
+	}
+	writeSubmitted(w, r, &submission, result)
+}
+
+// verifyTemplate checks the template's schedule, then runs the market
+// checks on the market it would create if it came round at now.
+func verifyTemplate(payload TemplatePayload, db *gorm.DB, now time.Time) VerificationResult {
+	scheduleCheck := VerificationCheck{Name: "schedule"}
+	schedule, err := templates.Validate(payload, now)
+	var in marketcreation.Input
+	if err == nil {
+		in, err = templates.Instance(payload, 0, now)
+	}
+	if err != nil {
+		scheduleCheck.Reason = err.Error()
+		return VerificationResult{
+			Checks: []VerificationCheck{scheduleCheck},
+			Errors: []string{fmt.Sprintf("%s: %s", scheduleCheck.Name, scheduleCheck.Reason)},
+		}
+	}
+	scheduleCheck.Passed = true
+	scheduleCheck.Reason = fmt.Sprintf("First market due %s", schedule.Next(now).Format(time.RFC3339))
+
+	result := verifyMarket(MarketPayload{
+		QuestionTitle:       in.QuestionTitle,
+		Description:         in.Description,
+		ResolutionDateTime:  in.ResolutionDateTime.Format(time.RFC3339),
+		OutcomeType:         in.OutcomeType,
+		InitialProbability:  in.InitialProbability,
+		YesLabel:            in.YesLabel,
+		NoLabel:             in.NoLabel,
+		Category:            in.Category,
+		Tags:                in.Tags,
+		ResolutionCriteria:  in.ResolutionCriteria,
+		AutoResolve:         in.AutoResolve,
+		PredictionLockHours: in.PredictionLockHours,
+		ScalarMin:           in.ScalarMin,
+		ScalarMax:           in.ScalarMax,
+		ScalarUnit:          in.ScalarUnit,
+		MarketType:          in.MarketType,
+	}, db)
+	result.Checks = append([]VerificationCheck{scheduleCheck}, result.Checks...)
+	return result
+}
+
+// createApprovedTemplate activates the submitted template after council
+// approval, recording the submission and council decision as the
+// provenance every market it creates carries.
+func createApprovedTemplate(db *gorm.DB, submission *PendingSubmission) (*models.MarketTemplate, error) {
+	var payload TemplatePayload
+	if err := json.Unmarshal([]byte(submission.Payload), &payload); err != nil {
+		return nil, permanent(fmt.Errorf("parse template payload: %w", err))
+	}
+	provenance, err := councilProvenance(db, submission)
+	if err != nil {
+		return nil, fmt.Errorf("load council votes: %w", err)
+	}
+
+	template, err := templates.Activate(db, submission.ID, submission.SubmitterAgentID, payload, provenance, time.Now())
+	if stderrors.Is(err, templates.ErrInvalidTemplate) {
+		return nil, permanent(fmt.Errorf("create template: %w", err))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create template: %w", err)
+	}
+	return template, nil
+}
@@ -0,0 +1,256 @@
+package verification
+
+import (
+	"context"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/notifications"
+	"socialpredict/services/resolution"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+// reconcileBatchSize is how many votes ReconcileCouncilVotes reads at once.
+const reconcileBatchSize = 200
+
+// DeactivateInactiveValidators deactivates every active validator who has
+// not voted within the configured window (counted from when they
+// registered or were last reactivated, if later) and notifies them. It
+// returns how many were deactivated.
+func DeactivateInactiveValidators(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+	policy := setup.EconomicsConfig().Verification.OrDefaults()
+	cutoff := now.AddDate(0, 0, -policy.ValidatorInactiveDays)
+
+	var idle []ValidatorAgent
+	if err := db.Where("is_active = ? AND COALESCE(last_voted_at, created_at) < ?", true, cutoff).
+		Where("reactivated_at IS NULL OR reactivated_at < ?", cutoff).
+		Find(&idle).Error; err != nil {
+		return 0, err
+	}
+
+	deactivated := 0
+	for _, v := range idle {
+		if err := ctx.Err(); err != nil {
+			return deactivated, err
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Skip a validator who voted since they were read.
+			result := tx.Model(&ValidatorAgent{}).
+				Where("agent_id = ? AND is_active = ? AND COALESCE(last_voted_at, created_at) < ?", v.AgentID, true, cutoff).
+				Updates(map[string]interface{}{
+					"is_active":        false,
+					"deactivated_at":   now,
+					"on_probation":     false,
+					"requalify_streak": 0,
+				})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			deactivated++
+			return notifications.SendValidatorDeactivated(tx, v.AgentID, notifications.ValidatorDeactivated{
+				LastVotedAt:    v.LastVotedAt,
+				InactiveDays:   policy.ValidatorInactiveDays,
+				RequalifyVotes: policy.ValidatorRequalifyVotes,
+			})
+		})
+		if err != nil {
+			return deactivated, err
+		}
+	}
+	return deactivated, nil
+}
+
+// unreconciledVote is a council vote on a settled submission that has not
+// been judged yet.
+type unreconciledVote struct {
+	ID          int64
+	ValidatorID int64
+	Vote        string
+	Probation   bool
+	FinalStatus string
+}
+
+// ReconcileCouncilVotes judges the votes on settled submissions against the
+// council's decision, in the order the submissions were settled, and then
+// judges the votes on approved markets again once those markets resolve. A
+// counted vote updates the validator's correct validations and score. A
+// probation vote advances the validator's re-qualification streak, or
+// resets it if wrong, and once the streak reaches the configured number of
+// votes the validator is reactivated and notified. Votes on expired
+// submissions are marked reconciled without being judged. It returns how
+// many votes were reconciled or re-judged.
+func ReconcileCouncilVotes(ctx context.Context, db *gorm.DB) (int, error) {
+	db = db.WithContext(ctx)
+	required := setup.EconomicsConfig().Verification.OrDefaults().ValidatorRequalifyVotes
+
+	reconciled := 0
+	for {
+		var votes []unreconciledVote
+		err := db.Table("council_votes").
+			Select("council_votes.id, council_votes.validator_id, council_votes.vote, council_votes.probation, pending_submissions.final_status").
+			Joins("JOIN pending_submissions ON pending_submissions.id = council_votes.submission_id").
+			Where("council_votes.deleted_at IS NULL AND council_votes.reconciled_at IS NULL").
+			Where("pending_submissions.final_status IN ?", []string{"approved", "rejected", "expired"}).
+			Order("pending_submissions.resolved_at, council_votes.id").
+			Limit(reconcileBatchSize).
+			Scan(&votes).Error
+		if err != nil {
+			return reconciled, err
+		}
+
+		for _, v := range votes {
+			if err := ctx.Err(); err != nil {
+				return reconciled, err
+			}
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				return reconcileVote(tx, v, required)
+			}); err != nil {
+				return reconciled, err
+			}
+			reconciled++
+		}
+		if len(votes) < reconcileBatchSize {
+			break
+		}
+	}
+
+	checked, err := checkMarketOutcomes(ctx, db)
+	return reconciled + checked, err
+}
+
+func reconcileVote(tx *gorm.DB, v unreconciledVote, required int) error {
+	now := time.Now()
+	updates := map[string]interface{}{"reconciled_at": now}
+	var correct bool
+	judged := v.FinalStatus != "expired"
+	if judged {
+		correct = (v.Vote == "approve") == (v.FinalStatus == "approved")
+		updates["correct"] = correct
+	}
+	result := tx.Model(&CouncilVote{}).Where("id = ? AND reconciled_at IS NULL", v.ID).Updates(updates)
+	if result.Error != nil || result.RowsAffected == 0 || !judged {
+		return result.Error
+	}
+
+	if !v.Probation {
+		return rescoreValidator(tx, v.ValidatorID)
+	}
+
+	var validator ValidatorAgent
+	if err := tx.First(&validator, "agent_id = ?", v.ValidatorID).Error; err != nil {
+		return err
+	}
+	if !validator.OnProbation {
+		return nil
+	}
+	if !correct {
+		return tx.Model(&validator).Update("requalify_streak", 0).Error
+	}
+	validator.RequalifyStreak++
+	if validator.RequalifyStreak < required {
+		return tx.Model(&validator).Update("requalify_streak", validator.RequalifyStreak).Error
+	}
+	if err := tx.Model(&validator).Updates(map[string]interface{}{
+		"is_active":        true,
+		"on_probation":     false,
+		"requalify_streak": 0,
+		"reactivated_at":   now,
+	}).Error; err != nil {
+		return err
+	}
+	return notifications.SendValidatorReactivated(tx, validator.AgentID, notifications.ValidatorReactivated{
+		CorrectVotes: validator.RequalifyStreak,
+	})
+}
+
+// requalifying is what a validator on probation is told about their
+// progress.
+func requalifying(validator *ValidatorAgent) map[string]interface{} {
+	return map[string]interface{}{
+		"onProbation":     true,
+		"requalifyStreak": validator.RequalifyStreak,
+		"requalifyVotes":  setup.EconomicsConfig().Verification.OrDefaults().ValidatorRequalifyVotes,
+	}
+}
+
+// marketOutcomeVote is a judged vote on a market submission whose market
+// has since resolved.
+type marketOutcomeVote struct {
+	ID               int64
+	ValidatorID      int64
+	Vote             string
+	Probation        bool
+	Correct          bool
+	ResolutionResult string
+}
+
+// checkMarketOutcomes judges the votes on approved market submissions again
+// once the market they created resolves. A market resolving N/A proves it
+// should not have been approved, so approving it becomes wrong and
+// rejecting it right. It returns how many votes were checked.
+func checkMarketOutcomes(ctx context.Context, db *gorm.DB) (int, error) {
+	checked := 0
+	for {
+		var votes []marketOutcomeVote
+		err := db.Table("council_votes").
+			Select("council_votes.id, council_votes.validator_id, council_votes.vote, council_votes.probation, council_votes.correct, markets.resolution_result").
+			Joins("JOIN markets ON markets.source_submission_id = council_votes.submission_id").
+			Where("council_votes.deleted_at IS NULL AND council_votes.correct IS NOT NULL AND council_votes.market_checked_at IS NULL").
+			Where("markets.is_resolved = ?", true).
+			Order("council_votes.id").
+			Limit(reconcileBatchSize).
+			Scan(&votes).Error
+		if err != nil {
+			return checked, err
+		}
+
+		for _, v := range votes {
+			if err := ctx.Err(); err != nil {
+				return checked, err
+			}
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				return checkMarketOutcome(tx, v)
+			}); err != nil {
+				return checked, err
+			}
+			checked++
+		}
+		if len(votes) < reconcileBatchSize {
+			return checked, nil
+		}
+	}
+}
+
+func checkMarketOutcome(tx *gorm.DB, v marketOutcomeVote) error {
+	good := v.ResolutionResult != resolution.OutcomeNA
+	correct := (v.Vote == "approve") == good
+	result := tx.Model(&CouncilVote{}).Where("id = ? AND market_checked_at IS NULL", v.ID).
+		Updates(map[string]interface{}{"correct": correct, "market_checked_at": time.Now()})
+	if result.Error != nil || result.RowsAffected == 0 || v.Probation || correct == v.Correct {
+		return result.Error
+	}
+	return rescoreValidator(tx, v.ValidatorID)
+}
+
+// rescoreValidator recounts a validator's correct validations from their
+// judged counted votes and recomputes their score from them.
+func rescoreValidator(tx *gorm.DB, validatorID int64) error {
+	var tally struct {
+		Judged  int64
+		Correct int64
+	}
+	err := tx.Model(&CouncilVote{}).
+		Select("COUNT(*) AS judged, COALESCE(SUM(CASE WHEN correct THEN 1 ELSE 0 END), 0) AS correct").
+		Where("validator_id = ? AND probation = ? AND correct IS NOT NULL", validatorID, false).
+		Scan(&tally).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&ValidatorAgent{}).Where("agent_id = ?", validatorID).Updates(map[string]interface{}{
+		"correct_validations": tally.Correct,
+		"validator_score":     models.ValidatorScoreOf(tally.Correct, tally.Judged),
+	}).Error
+}
@@ -1,7 +1,9 @@
 package verification
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -10,61 +12,141 @@ import (
 
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"socialpredict/errors"
+	"socialpredict/i18n"
 	"socialpredict/middleware"
 	"socialpredict/models"
+	"socialpredict/notifications"
+	"socialpredict/outbox"
+	"socialpredict/repository"
+	"socialpredict/response"
+	"socialpredict/services/contentsafety"
+	"socialpredict/services/marketcreation"
+	"socialpredict/services/platformconfig"
+	"socialpredict/services/predictioncreation"
+	"socialpredict/services/similarity"
+	"socialpredict/setup"
+	"socialpredict/validation"
 )
 
-// PendingSubmission represents a submission awaiting verification
-type PendingSubmission struct {
-	gorm.Model
-	ID                     int64      `json:"id" gorm:"primary_key"`
-	SubmissionType         string     `json:"submissionType" gorm:"not null"` // "market" or "prediction"
-	SubmitterAgentID       int64      `json:"submitterAgentId" gorm:"not null"`
-	Payload                string     `json:"payload" gorm:"type:text"`
-	AutoVerificationStatus string     `json:"autoVerificationStatus" gorm:"default:pending"`
-	AutoVerificationResult string     `json:"autoVerificationResult" gorm:"type:text"`
-	
-	// Council voting
-	CouncilStatus    string     `json:"councilStatus" gorm:"default:pending"` // pending, voting, approved, rejected
-	VotesFor         int        `json:"votesFor" gorm:"default:0"`
-	VotesAgainst     int        `json:"votesAgainst" gorm:"default:0"`
-	VotesRequired    int        `json:"votesRequired" gorm:"default:3"`
-	ApprovalThreshold float64   `json:"approvalThreshold" gorm:"default:67.0"`
-	VotingEndsAt     time.Time  `json:"votingEndsAt"`
-	
-	FinalStatus      string     `json:"finalStatus"` // approved, rejected, expired
-	ResolvedAt       *time.Time `json:"resolvedAt"`
-}
-
-// CouncilVote records a validator's vote on a submission
-type CouncilVote struct {
-	gorm.Model
-	ID           int64   `json:"id" gorm:"primary_key"`
-	SubmissionID int64   `json:"submissionId" gorm:"not null;index;uniqueIndex:idx_submission_validator"`
-	ValidatorID  int64   `json:"validatorId" gorm:"not null;index;uniqueIndex:idx_submission_validator"`
-	Vote         string  `json:"vote" gorm:"not null"` // approve or reject
-	Reason       string  `json:"reason" gorm:"type:text"`
-	Weight       float64 `json:"weight" gorm:"default:1.0"`
-}
-
-// ValidatorAgent tracks agents who can vote on submissions
-type ValidatorAgent struct {
-	AgentID            int64     `json:"agentId" gorm:"primaryKey"`
-	IsActive           bool      `json:"isActive" gorm:"default:true"`
-	TotalValidations   int64     `json:"totalValidations" gorm:"default:0"`
-	CorrectValidations int64     `json:"correctValidations" gorm:"default:0"`
-	ValidatorScore     float64   `json:"validatorScore" gorm:"default:50.0"`
-	CreatedAt          time.Time `json:"createdAt"`
-	UpdatedAt          time.Time `json:"updatedAt"`
-}
-
-// MarketPayload is the payload for market submissions
+// PendingSubmission, CouncilVote and ValidatorAgent live in models so the
+// repository layer can persist them without importing this package.
+type (
+	PendingSubmission = models.PendingSubmission
+	CouncilVote       = models.CouncilVote
+	ValidatorAgent    = models.ValidatorAgent
+)
+
+// PredictionPayload is the payload for prediction submissions. It is the
+// same body POST /v0/predict takes; content rules are reported by
+// verifyPrediction.
+type PredictionPayload = models.PredictionRequest
+
+// MarketPayload is the payload for market submissions. The tags only cover
+// the shape of the request; content rules are reported by verifyMarket.
 type MarketPayload struct {
-	QuestionTitle      string  `json:"questionTitle"`
-	Description        string  `json:"description"`
-	ResolutionDateTime string  `json:"resolutionDateTime"`
-	OutcomeType        string  `json:"outcomeType"`
+	QuestionTitle      string  `json:"questionTitle" validate:"required"`
+	Description        string  `json:"description" validate:"max=2000"`
+	ResolutionDateTime string  `json:"resolutionDateTime" validate:"required"`
+	OutcomeType        string  `json:"outcomeType" validate:"omitempty,oneof=BINARY SCALAR"`
 	InitialProbability float64 `json:"initialProbability"`
+	YesLabel           string  `json:"yesLabel,omitempty" validate:"max=20"`
+	NoLabel            string  `json:"noLabel,omitempty" validate:"max=20"`
+	Category           string  `json:"category,omitempty" validate:"max=50"`
+	ClosingAuction     bool    `json:"closingAuction,omitempty"`
+	// Up to 10 tags, e.g. ["bitcoin", "etf"], for browsing beyond the category
+	Tags []string `json:"tags,omitempty" validate:"max=10,dive,max=30"`
+	// Required by auto-verification: how the market will be resolved
+	ResolutionCriteria *models.ResolutionCriteria `json:"resolutionCriteria,omitempty"`
+	// Optional: resolve the market from an oracle once it closes
+	AutoResolve *models.OracleSpec `json:"autoResolve,omitempty"`
+	// Optional: lock predictions this many hours before resolution
+	PredictionLockHours float64 `json:"predictionLockHours,omitempty" validate:"gte=0"`
+	// Optional: public (default), unlisted or invite_only
+	Visibility string `json:"visibility,omitempty" validate:"omitempty,oneof=public unlisted invite_only"`
+	// Required for SCALAR markets: the range of the answer and its unit
+	ScalarMin  *float64 `json:"scalarMin,omitempty" validate:"required_if=OutcomeType SCALAR"`
+	ScalarMax  *float64 `json:"scalarMax,omitempty" validate:"required_if=OutcomeType SCALAR"`
+	ScalarUnit string   `json:"scalarUnit,omitempty" validate:"max=20"`
+	// Optional: standard (default), daily or realtime
+	MarketType string `json:"marketType,omitempty" validate:"omitempty,oneof=standard daily realtime"`
+}
+
+// Normalize upper-cases the outcome type and trims the resolution criteria
+// and oracle spec.
+func (p *MarketPayload) Normalize() {
+	p.OutcomeType = strings.ToUpper(strings.TrimSpace(p.OutcomeType))
+	p.MarketType = strings.ToLower(strings.TrimSpace(p.MarketType))
+	if p.ResolutionCriteria != nil {
+		p.ResolutionCriteria.Normalize()
+	}
+	if p.AutoResolve != nil {
+		p.AutoResolve.Normalize()
+	}
+}
+
+// CouncilVoteRequest is a validator's vote on a pending submission.
+type CouncilVoteRequest struct {
+	Vote   string `json:"vote" validate:"required,oneof=approve reject"`
+	Reason string `json:"reason" validate:"max=2000"`
+	// Optional on reject votes: one of models.RejectionReasons
+	RejectionReason string `json:"rejectionReason" validate:"excluded_unless=Vote reject,omitempty,oneof=ambiguous weak_criteria unverifiable duplicate already_known bad_timeframe low_value inappropriate other"`
+}
+
+// Normalize lower-cases the vote and rejection reason.
+func (r *CouncilVoteRequest) Normalize() {
+	r.Vote = strings.ToLower(strings.TrimSpace(r.Vote))
+	r.RejectionReason = strings.ToLower(strings.TrimSpace(r.RejectionReason))
+}
+
+// creationInput maps a council payload onto the shared market creation input.
+func (p MarketPayload) creationInput(creatorAgentID int64) (marketcreation.Input, error) {
+	resDate, err := time.Parse(time.RFC3339, p.ResolutionDateTime)
+	if err != nil {
+		return marketcreation.Input{}, err
+	}
+	return marketcreation.Input{
+		QuestionTitle:      p.QuestionTitle,
+		Description:        p.Description,
+		ResolutionDateTime: resDate,
+		InitialProbability: p.InitialProbability,
+		YesLabel:           p.YesLabel,
+		NoLabel:            p.NoLabel,
+		Category:           p.Category,
+		Tags:               p.Tags,
+		CreatorAgentID:     creatorAgentID,
+		ClosingAuction:     p.ClosingAuction,
+		ResolutionCriteria: p.ResolutionCriteria,
+		AutoResolve:        p.AutoResolve,
+
+		PredictionLockHours: p.PredictionLockHours,
+		Visibility:          p.Visibility,
+		OutcomeType:         p.OutcomeType,
+		ScalarMin:           p.ScalarMin,
+		ScalarMax:           p.ScalarMax,
+		ScalarUnit:          p.ScalarUnit,
+		MarketType:          p.MarketType,
+	}, nil
+}
+
+func newMarketCreation(db *gorm.DB) *marketcreation.Service {
+	return marketcreation.NewService(repository.NewGormRepositories(db), setup.EconomicsConfig)
+}
+
+// newSubmission builds a pending submission with the council policy for its
+// type stamped on, so later policy changes do not affect votes in progress.
+func newSubmission(submissionType string, submitterAgentID int64, policy setup.CouncilPolicy, now time.Time) PendingSubmission {
+	return PendingSubmission{
+		SubmissionType:    submissionType,
+		SubmitterAgentID:  submitterAgentID,
+		CouncilStatus:     "pending",
+		VotesRequired:     policy.VotesRequired,
+		MinVoters:         policy.MinVoters,
+		ApprovalThreshold: policy.ApprovalThreshold,
+		VotingEndsAt:      now.Add(policy.VotingDuration()),
+		Revision:          1,
+	}
 }
 
 // VerificationResult contains the auto-verification results
@@ -72,6 +154,11 @@ type VerificationResult struct {
 	Passed bool                `json:"passed"`
 	Checks []VerificationCheck `json:"checks"`
 	Errors []string            `json:"errors,omitempty"`
+	// Failed checks that only warn; the submission still goes to the council
+	Warnings []string `json:"warnings,omitempty"`
+	// Existing markets most similar to a submitted market, for the council
+	// to compare against
+	SimilarMarkets []similarity.Match `json:"similarMarkets,omitempty"`
 }
 
 // VerificationCheck is a single verification check
@@ -79,6 +166,8 @@ type VerificationCheck struct {
 	Name   string `json:"name"`
 	Passed bool   `json:"passed"`
 	Reason string `json:"reason,omitempty"`
+	// reject or warn; see services/verificationrules
+	Severity string `json:"severity,omitempty"`
 }
 
 // SubmitMarketHandler handles POST /v0/submit/market
@@ -88,50 +177,148 @@ func SubmitMarketHandler(db *gorm.DB) http.HandlerFunc {
 		// Validate agent authentication
 		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
-		agentID := agent.ID
 
 		var payload MarketPayload
-		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-			http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+		if fields := validation.Decode(r, &payload); fields != nil {
+			errors.WriteValidationError(w, fields)
+			return
+		}
+		submitMarket(w, r, db, agent, payload, nil)
+	}
+}
+
+// submitMarket verifies payload and submits it for council review, or
+// creates its market straight away if it may skip the council. A revision
+// of original supersedes it.
+func submitMarket(w http.ResponseWriter, r *http.Request, db *gorm.DB, agent *models.Agent, payload MarketPayload, original *PendingSubmission) {
+	// Run FREE auto-verification (no paid APIs)
+	result := verifyMarket(payload, db)
+	payloadJSON, _ := json.Marshal(payload)
+	resultJSON, _ := json.Marshal(result)
+
+	// If basic checks fail, reject immediately (no council needed)
+	if !result.Passed {
+		response.ErrorWithDetails(w, http.StatusBadRequest, response.CodeVerificationFailed, i18n.T(r, "verification.auto_failed"), result)
+		return
+	}
+
+	// Create submission for council review
+	config := platformconfig.Current(db)
+	policy := config.Council.PolicyFor(models.SubmissionTypeMarket)
+	submission := newSubmission(models.SubmissionTypeMarket, agent.ID, policy, time.Now())
+	submission.Payload = string(payloadJSON)
+	submission.AutoVerificationStatus = "passed"
+	submission.AutoVerificationResult = string(resultJSON)
+
+	if config.PrivateMarkets.BypassesCouncil(payload.Visibility, agent.CompositeScore) {
+		submitWithoutCouncil(w, r, db, &submission, original, result)
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return insertSubmission(tx, &submission, original)
+	})
+	if !submissionCreated(w, err) {
+		return
+	}
+	writeSubmitted(w, r, &submission, result)
+}
+
+// writeSubmitted answers a submission sent for council review.
+func writeSubmitted(w http.ResponseWriter, r *http.Request, submission *PendingSubmission, result VerificationResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":      true,
+		"submissionId": submission.ID,
+		"revisionOf":   submission.RevisionOf,
+		"status":       "pending_council_review",
+		"verification": result,
+		"message":      i18n.T(r, "verification.submitted", submission.VotesRequired, submission.ApprovalThreshold),
+		"votingEndsAt": submission.VotingEndsAt,
+	})
+}
+
+// submitWithoutCouncil records a private market submission the
+// configuration lets skip the council as approved and creates its market
+// straight away, with the submission as its provenance.
+func submitWithoutCouncil(w http.ResponseWriter, r *http.Request, db *gorm.DB, submission, original *PendingSubmission, result VerificationResult) {
+	now := time.Now()
+	submission.CouncilStatus = "bypassed"
+	submission.FinalStatus = "approved"
+	submission.ResolvedAt = &now
+
+	var market *models.Market
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := insertSubmission(tx, submission, original); err != nil {
+			return err
+		}
+		var err error
+		market, err = createApprovedMarket(tx, submission)
+		return err
+	})
+	if err != nil {
+		if marketcreation.IsValidationError(err) {
+			response.Error(w, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+			return
+		}
+		if !submissionCreated(w, err) {
+			return
+		}
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create market")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":      true,
+		"submissionId": submission.ID,
+		"status":       "created",
+		"marketId":     market.ID,
+		"verification": result,
+		"message":      i18n.T(r, "verification.council_bypassed"),
+	})
+}
+
+// SubmitPredictionHandler handles POST /v0/submit/prediction. The prediction
+// is made once the council approves it.
+func SubmitPredictionHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+		if httpErr != nil {
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+			return
+		}
+
+		var payload PredictionPayload
+		if fields := validation.Decode(r, &payload); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 
-		// Run FREE auto-verification (no paid APIs)
-		result := verifyMarket(payload, db)
+		now := time.Now()
+		policy := platformconfig.Current(db).Council.PolicyFor(models.SubmissionTypePrediction)
+		submission := newSubmission(models.SubmissionTypePrediction, agent.ID, policy, now)
+
+		result := verifyPrediction(payload, agent.ID, submission.VotingEndsAt, db)
 		payloadJSON, _ := json.Marshal(payload)
 		resultJSON, _ := json.Marshal(result)
 
-		// If basic checks fail, reject immediately (no council needed)
 		if !result.Passed {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success":      false,
-				"status":       "rejected",
-				"verification": result,
-				"message":      "Auto-verification failed. Please fix the issues and resubmit.",
-			})
+			response.ErrorWithDetails(w, http.StatusBadRequest, response.CodeVerificationFailed, i18n.T(r, "verification.auto_failed"), result)
 			return
 		}
 
-		// Create submission for council review
-		submission := PendingSubmission{
-			SubmissionType:         "market",
-			SubmitterAgentID:       agentID,
-			Payload:                string(payloadJSON),
-			AutoVerificationStatus: "passed",
-			AutoVerificationResult: string(resultJSON),
-			CouncilStatus:          "pending",
-			VotesRequired:          3,
-			ApprovalThreshold:      67.0,
-			VotingEndsAt:           time.Now().Add(24 * time.Hour),
-		}
+		submission.Payload = string(payloadJSON)
+		submission.AutoVerificationStatus = "passed"
+		submission.AutoVerificationResult = string(resultJSON)
 
 		if err := db.Create(&submission).Error; err != nil {
-			http.Error(w, `{"error":"Failed to create submission"}`, http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to create submission")
 			return
 		}
 
@@ -142,281 +329,549 @@ func SubmitMarketHandler(db *gorm.DB) http.HandlerFunc {
 			"submissionId": submission.ID,
 			"status":       "pending_council_review",
 			"verification": result,
-			"message":      "Market submitted for council verification. Requires 3+ council votes with 67% approval.",
+			"message":      i18n.T(r, "verification.prediction_submitted", submission.VotesRequired, submission.ApprovalThreshold),
 			"votingEndsAt": submission.VotingEndsAt,
 		})
 	}
 }
 
-// verifyMarket runs FREE verification checks (no paid APIs)
-func verifyMarket(payload MarketPayload, db *gorm.DB) VerificationResult {
+// verifyPrediction runs the automatic checks on a prediction submission.
+// The market must still take predictions when council voting ends, since
+// that is the earliest the prediction can be made.
+func verifyPrediction(payload PredictionPayload, agentID int64, votingEndsAt time.Time, db *gorm.DB) VerificationResult {
 	var checks []VerificationCheck
-	var errors []string
-
-	// Check 1: Resolution date is in the future
-	resDate, err := time.Parse(time.RFC3339, payload.ResolutionDateTime)
-	futureCheck := VerificationCheck{Name: "future_resolution_date"}
-	if err != nil {
-		futureCheck.Passed = false
-		futureCheck.Reason = "Invalid date format (use RFC3339: 2026-12-31T23:59:59Z)"
-	} else if resDate.Before(time.Now()) {
-		futureCheck.Passed = false
-		futureCheck.Reason = fmt.Sprintf("Resolution date %s is in the past", payload.ResolutionDateTime)
-	} else {
-		futureCheck.Passed = true
-		futureCheck.Reason = "Resolution date is in the future"
-	}
-	checks = append(checks, futureCheck)
-
-	// Check 2: Question has minimum length
-	lengthCheck := VerificationCheck{Name: "question_length"}
-	if len(payload.QuestionTitle) < 10 {
-		lengthCheck.Passed = false
-		lengthCheck.Reason = "Question too short (minimum 10 characters)"
-	} else if len(payload.QuestionTitle) > 500 {
-		lengthCheck.Passed = false
-		lengthCheck.Reason = "Question too long (maximum 500 characters)"
-	} else {
-		lengthCheck.Passed = true
-		lengthCheck.Reason = "Question length OK"
-	}
-	checks = append(checks, lengthCheck)
-
-	// Check 3: Description has resolution criteria
-	criteriaCheck := VerificationCheck{Name: "resolution_criteria"}
-	if len(payload.Description) < 20 {
-		criteriaCheck.Passed = false
-		criteriaCheck.Reason = "Description too short - must include clear resolution criteria"
-	} else {
-		criteriaCheck.Passed = true
-		criteriaCheck.Reason = "Description provided"
-	}
-	checks = append(checks, criteriaCheck)
-
-	// Check 4: Initial probability is reasonable
-	probCheck := VerificationCheck{Name: "initial_probability"}
-	if payload.InitialProbability < 0.01 || payload.InitialProbability > 0.99 {
-		probCheck.Passed = false
-		probCheck.Reason = "Initial probability must be between 1% and 99%"
+	rules := platformconfig.Current(db).Verification.OrDefaults()
+
+	// Check 1: Market exists and stays open through council review
+	marketCheck := VerificationCheck{Name: "market_open"}
+	var market models.Market
+	if err := db.First(&market, payload.MarketID).Error; err != nil {
+		marketCheck.Passed = false
+		marketCheck.Reason = fmt.Sprintf("Market %d not found", payload.MarketID)
+	} else if market.IsResolved {
+		marketCheck.Passed = false
+		marketCheck.Reason = "Market is already resolved"
+	} else if market.PredictionsLocked(votingEndsAt) {
+		marketCheck.Passed = false
+		marketCheck.Reason = "Market locks predictions before council voting ends"
 	} else {
-		probCheck.Passed = true
-		probCheck.Reason = "Initial probability is reasonable"
+		marketCheck.Passed = true
+		marketCheck.Reason = "Market is open"
 	}
-	checks = append(checks, probCheck)
+	checks = append(checks, marketCheck)
 
-	// Check 5: Not about speculative/impossible topics
-	specCheck := VerificationCheck{Name: "not_speculative"}
-	questionLower := strings.ToLower(payload.QuestionTitle)
-	specKeywords := []string{"aliens", "time travel", "magic", "supernatural", "bigfoot", "ufo abduction"}
-	isSpeculative := false
-	for _, keyword := range specKeywords {
-		if strings.Contains(questionLower, keyword) {
-			isSpeculative = true
-			break
-		}
-	}
-	if isSpeculative {
-		specCheck.Passed = false
-		specCheck.Reason = "Market appears to be about speculative/unverifiable topics"
+	// Check 2: Reasoning explains the prediction
+	reasoningCheck := VerificationCheck{Name: "reasoning_length"}
+	if len(payload.Reasoning) < rules.MinReasoningLength {
+		reasoningCheck.Passed = false
+		reasoningCheck.Reason = fmt.Sprintf("Reasoning too short (minimum %d characters)", rules.MinReasoningLength)
 	} else {
-		specCheck.Passed = true
-		specCheck.Reason = "Topic appears verifiable"
+		reasoningCheck.Passed = true
+		reasoningCheck.Reason = "Reasoning provided"
 	}
-	checks = append(checks, specCheck)
+	checks = append(checks, reasoningCheck)
 
-	// Check 6: No duplicate markets (simple title matching)
+	// Check 3: Agent has not already predicted on this market
 	dupCheck := VerificationCheck{Name: "no_duplicate"}
 	var existingCount int64
-	searchTerm := payload.QuestionTitle
-	if len(searchTerm) > 50 {
-		searchTerm = searchTerm[:50]
-	}
-	db.Model(&models.Market{}).Where("LOWER(question_title) LIKE ?", "%"+strings.ToLower(searchTerm)+"%").Count(&existingCount)
+	db.Model(&models.Prediction{}).Where("agent_id = ? AND market_id = ?", agentID, payload.MarketID).Count(&existingCount)
 	if existingCount > 0 {
 		dupCheck.Passed = false
-		dupCheck.Reason = fmt.Sprintf("Similar market may already exist (%d potential matches)", existingCount)
+		dupCheck.Reason = "Agent already has a prediction on this market"
 	} else {
 		dupCheck.Passed = true
-		dupCheck.Reason = "No duplicate markets found"
+		dupCheck.Reason = "No existing prediction"
 	}
 	checks = append(checks, dupCheck)
 
-	// Determine overall pass/fail
-	allPassed := true
-	for _, check := range checks {
-		if !check.Passed {
-			allPassed = false
-			errors = append(errors, fmt.Sprintf("%s: %s", check.Name, check.Reason))
-		}
-	}
+	// Check 4: Reasoning has no prohibited content
+	checks = append(checks, contentSafetyCheck(db, contentsafety.Field{Name: "reasoning", Text: payload.Reasoning}))
 
-	return VerificationResult{
-		Passed: allPassed,
-		Checks: checks,
-		Errors: errors,
-	}
+	return verificationResult(checks)
 }
 
 // VoteOnSubmissionHandler handles POST /v0/council/vote/{submissionId}
+// The vote is stored and tallied, and the submission decided if it can be,
+// in one transaction holding the submission's row lock, so concurrent votes
+// are tallied one after another and a submission is decided exactly once.
+// An approved submission is applied by its submission job, first tried
+// straight after the vote.
 func VoteOnSubmissionHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Validate agent authentication
-		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
-		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+		agent, validator, submission, ok := loadVoteTarget(w, r, db)
+		if !ok {
 			return
 		}
 
-		// Check if agent is a validator
-		var validator ValidatorAgent
-		if err := db.Where("agent_id = ? AND is_active = ?", agent.ID, true).First(&validator).Error; err != nil {
-			http.Error(w, `{"error":"Agent is not an active council validator"}`, http.StatusForbidden)
+		// Check if already voted
+		var existingVote CouncilVote
+		if err := db.Where("submission_id = ? AND validator_id = ?", submission.ID, agent.ID).First(&existingVote).Error; err == nil {
+			response.Error(w, http.StatusConflict, response.CodeAlreadyVoted, "Already voted on this submission; use PUT to change your vote")
 			return
 		}
 
-		// Get submission ID
-		vars := mux.Vars(r)
-		submissionID, err := strconv.ParseInt(vars["submissionId"], 10, 64)
-		if err != nil {
-			http.Error(w, `{"error":"Invalid submission ID"}`, http.StatusBadRequest)
+		// Parse vote
+		var voteReq CouncilVoteRequest
+		if fields := validation.Decode(r, &voteReq); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 
-		// Get submission
-		var submission PendingSubmission
-		if err := db.First(&submission, submissionID).Error; err != nil {
-			http.Error(w, `{"error":"Submission not found"}`, http.StatusNotFound)
-			return
+		// Build the vote; it is stored together with the tally update below
+		vote := CouncilVote{
+			SubmissionID:    submission.ID,
+			ValidatorID:     agent.ID,
+			Vote:            voteReq.Vote,
+			Reason:          voteReq.Reason,
+			RejectionReason: voteReq.RejectionReason,
+			Weight:          voteWeight(validator),
 		}
-
-		// Check submission is still open
-		if submission.FinalStatus != "" {
-			http.Error(w, `{"error":"Submission is no longer open for voting"}`, http.StatusBadRequest)
-			return
+		// A validator re-qualifying votes without counting towards the tally
+		if !validator.IsActive {
+			vote.Probation = true
+			vote.Weight = 0
 		}
 
-		// Check voting hasn't expired
-		if time.Now().After(submission.VotingEndsAt) {
-			http.Error(w, `{"error":"Voting period has ended"}`, http.StatusBadRequest)
+		var resolved bool
+		var resultMsg string
+		err := withSubmissionLock(r.Context(), db, submission, func(tx *gorm.DB) error {
+			var voted int64
+			if err := tx.Model(&CouncilVote{}).Where("submission_id = ? AND validator_id = ?", submission.ID, agent.ID).Count(&voted).Error; err != nil {
+				return err
+			}
+			if voted > 0 {
+				return errAlreadyVoted
+			}
+			if err := recordValidatorVote(tx, validator, !vote.Probation); err != nil {
+				return err
+			}
+			if vote.Probation {
+				return tx.Create(&vote).Error
+			}
+
+			submission.AddVote(vote.Vote, vote.Weight)
+			submission.CouncilStatus = "voting"
+			resolved, resultMsg = settleVotes(submission)
+			return saveSubmission(tx, submission, &vote)
+		})
+		if !voteSaved(w, err) {
 			return
 		}
-
-		// Can't vote on own submission
-		if submission.SubmitterAgentID == agent.ID {
-			http.Error(w, `{"error":"Cannot vote on your own submission"}`, http.StatusForbidden)
+		if vote.Probation {
+			writeProbationVote(w, validator, &vote)
 			return
 		}
+		if resolved && submission.FinalStatus == "approved" {
+			resultMsg = applyApproved(r.Context(), db, submission)
+		}
+		writeVoteResult(w, submission, &vote, resolved, resultMsg)
+	}
+}
 
-		// Check if already voted
-		var existingVote CouncilVote
-		if err := db.Where("submission_id = ? AND validator_id = ?", submissionID, agent.ID).First(&existingVote).Error; err == nil {
-			http.Error(w, `{"error":"Already voted on this submission"}`, http.StatusConflict)
+// ChangeCouncilVoteHandler handles PUT /v0/council/vote/{submissionId}
+// Lets a validator change their vote while voting is still open. The vote
+// is taken out of the tally and counted again as cast, and the resolution
+// conditions are re-checked, all in one transaction holding the
+// submission's row lock.
+func ChangeCouncilVoteHandler(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		agent, validator, submission, ok := loadVoteTarget(w, r, db)
+		if !ok {
 			return
 		}
 
-		// Parse vote
-		var voteReq struct {
-			Vote   string `json:"vote"`   // "approve" or "reject"
-			Reason string `json:"reason"` // Optional
-		}
-		if err := json.NewDecoder(r.Body).Decode(&voteReq); err != nil {
-			http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+		var vote CouncilVote
+		if err := db.Where("submission_id = ? AND validator_id = ?", submission.ID, agent.ID).First(&vote).Error; err != nil {
+			response.Error(w, http.StatusNotFound, response.CodeNotFound, "You have not voted on this submission")
 			return
 		}
 
-		if voteReq.Vote != "approve" && voteReq.Vote != "reject" {
-			http.Error(w, `{"error":"Vote must be 'approve' or 'reject'"}`, http.StatusBadRequest)
+		var voteReq CouncilVoteRequest
+		if fields := validation.Decode(r, &voteReq); fields != nil {
+			errors.WriteValidationError(w, fields)
 			return
 		}
 
-		// Calculate vote weight based on validator reputation
-		voteWeight := 1.0 + (validator.ValidatorScore / 100.0)
+		var resolved bool
+		var resultMsg string
+		err := withSubmissionLock(r.Context(), db, submission, func(tx *gorm.DB) error {
+			// Reloaded under the lock, so two changes cannot both remove
+			// the same earlier vote from the tally.
+			if err := tx.First(&vote, vote.ID).Error; err != nil {
+				return err
+			}
+			if err := recordValidatorVote(tx, validator, false); err != nil {
+				return err
+			}
 
-		// Record vote
-		vote := CouncilVote{
-			SubmissionID: submissionID,
-			ValidatorID:  agent.ID,
-			Vote:         voteReq.Vote,
-			Reason:       voteReq.Reason,
-			Weight:       voteWeight,
-		}
-		if err := db.Create(&vote).Error; err != nil {
-			http.Error(w, `{"error":"Failed to record vote"}`, http.StatusInternalServerError)
+			// A probation vote was never tallied, so there is nothing to recount
+			if vote.Probation {
+				vote.Vote = voteReq.Vote
+				vote.Reason = voteReq.Reason
+				vote.RejectionReason = voteReq.RejectionReason
+				return tx.Save(&vote).Error
+			}
+
+			// Recount the vote at the validator's current weight
+			submission.RemoveVote(vote.Vote, vote.Weight)
+			vote.Vote = voteReq.Vote
+			vote.Reason = voteReq.Reason
+			vote.RejectionReason = voteReq.RejectionReason
+			vote.Weight = voteWeight(validator)
+			submission.AddVote(vote.Vote, vote.Weight)
+
+			resolved, resultMsg = settleVotes(submission)
+			return saveSubmission(tx, submission, &vote)
+		})
+		if !voteSaved(w, err) {
 			return
 		}
+		if vote.Probation {
+			writeProbationVote(w, validator, &vote)
+			return
+		}
+		if resolved && submission.FinalStatus == "approved" {
+			resultMsg = applyApproved(r.Context(), db, submission)
+		}
+		writeVoteResult(w, submission, &vote, resolved, resultMsg)
+	}
+}
+
+var (
+	errAlreadyVoted     = stderrors.New("already voted on this submission")
+	errSubmissionClosed = stderrors.New("submission is no longer open for voting")
+)
 
-		// Update submission
-		if voteReq.Vote == "approve" {
-			submission.VotesFor++
-		} else {
-			submission.VotesAgainst++
+// withSubmissionLock runs fn in a transaction holding submission's row lock
+// (SELECT ... FOR UPDATE; SQLite, with a single writer, ignores it), with
+// submission reloaded under the lock. Votes and the expiry job settle a
+// submission one at a time this way, so it is decided and applied exactly
+// once. If it was decided while waiting for the lock, fn is not run and
+// errSubmissionClosed is returned.
+func withSubmissionLock(ctx context.Context, db *gorm.DB, submission *PendingSubmission, fn func(tx *gorm.DB) error) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(submission, submission.ID).Error; err != nil {
+			return err
 		}
-		submission.CouncilStatus = "voting"
+		if submission.FinalStatus != "" {
+			return errSubmissionClosed
+		}
+		return fn(tx)
+	})
+}
 
-		// Update validator stats
+// recordValidatorVote notes that validator just voted and, if counted, adds
+// the vote to their total, updating the columns in place so votes on other
+// submissions made at the same time are not lost.
+func recordValidatorVote(tx *gorm.DB, validator *ValidatorAgent, counted bool) error {
+	now := time.Now()
+	updates := map[string]interface{}{"last_voted_at": now}
+	if counted {
+		updates["total_validations"] = gorm.Expr("total_validations + 1")
+	}
+	if err := tx.Model(&ValidatorAgent{}).Where("agent_id = ?", validator.AgentID).Updates(updates).Error; err != nil {
+		return err
+	}
+	validator.LastVotedAt = &now
+	if counted {
 		validator.TotalValidations++
-		db.Save(&validator)
+	}
+	return nil
+}
 
-		// Check if we can resolve
-		totalVotes := submission.VotesFor + submission.VotesAgainst
-		resolved := false
-		var resultMsg string
+// loadVoteTarget authenticates the voting validator and loads the
+// submission they are voting on. If the vote cannot be cast it writes the
+// error response and returns ok=false.
+func loadVoteTarget(w http.ResponseWriter, r *http.Request, db *gorm.DB) (agent *models.Agent, validator *ValidatorAgent, submission *PendingSubmission, ok bool) {
+	// Validate agent authentication
+	agent, httpErr := middleware.ValidateClaimedAgent(r, db)
+	if httpErr != nil {
+		response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
+		return nil, nil, nil, false
+	}
 
-		if totalVotes >= submission.VotesRequired {
-			approvalPct := float64(submission.VotesFor) / float64(totalVotes) * 100
-			now := time.Now()
-			submission.ResolvedAt = &now
-
-			if approvalPct >= submission.ApprovalThreshold {
-				submission.FinalStatus = "approved"
-				submission.CouncilStatus = "approved"
-				resultMsg = createApprovedMarket(db, &submission)
-			} else {
-				submission.FinalStatus = "rejected"
-				submission.CouncilStatus = "rejected"
-				resultMsg = "Submission rejected by council"
-			}
-			resolved = true
-		}
+	// Check if agent is a validator, counted or on probation
+	validator = &ValidatorAgent{}
+	if err := db.Where("agent_id = ? AND (is_active = ? OR on_probation = ?)", agent.ID, true, true).First(validator).Error; err != nil {
+		response.Error(w, http.StatusForbidden, response.CodeNotValidator, "Agent is not an active council validator")
+		return nil, nil, nil, false
+	}
 
-		db.Save(&submission)
+	// Get submission ID
+	vars := mux.Vars(r)
+	submissionID, err := strconv.ParseInt(vars["submissionId"], 10, 64)
+	if err != nil {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid submission ID")
+		return nil, nil, nil, false
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":    true,
-			"vote":       voteReq.Vote,
-			"weight":     voteWeight,
-			"votesFor":   submission.VotesFor,
-			"votesAgainst": submission.VotesAgainst,
-			"resolved":   resolved,
-			"result":     resultMsg,
-		})
+	// Get submission
+	submission = &PendingSubmission{}
+	if err := db.First(submission, submissionID).Error; err != nil {
+		response.Error(w, http.StatusNotFound, response.CodeSubmissionNotFound, "Submission not found")
+		return nil, nil, nil, false
+	}
+
+	// Check submission is still open
+	if submission.FinalStatus != "" {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Submission is no longer open for voting")
+		return nil, nil, nil, false
+	}
+
+	// Check voting hasn't expired
+	if time.Now().After(submission.VotingEndsAt) {
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Voting period has ended")
+		return nil, nil, nil, false
+	}
+
+	// Can't vote on own submission
+	if submission.SubmitterAgentID == agent.ID {
+		response.Error(w, http.StatusForbidden, response.CodeOwnSubmission, "Cannot vote on your own submission")
+		return nil, nil, nil, false
+	}
+
+	return agent, validator, submission, true
+}
+
+// voteWeight weights a validator's vote by their reputation.
+func voteWeight(validator *ValidatorAgent) float64 {
+	return 1.0 + (validator.ValidatorScore / 100.0)
+}
+
+// settleVotes resolves submission once enough validators have voted,
+// approving it if the weighted approval reaches its threshold, and describes
+// the outcome. saveSubmission records the job that applies an approved
+// submission.
+func settleVotes(submission *PendingSubmission) (resolved bool, resultMsg string) {
+	if submission.Voters() < submission.VotesRequired {
+		return false, ""
+	}
+
+	now := time.Now()
+	submission.ResolvedAt = &now
+
+	if submission.ApprovalPct() >= submission.ApprovalThreshold {
+		submission.FinalStatus = "approved"
+		submission.CouncilStatus = "approved"
+		return true, "Submission approved by council"
+	}
+	submission.FinalStatus = "rejected"
+	submission.CouncilStatus = "rejected"
+	return true, "Submission rejected by council"
+}
+
+// voteSaved writes the error response for err, the result of a vote
+// transaction, and reports whether the vote was saved.
+func voteSaved(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case stderrors.Is(err, errAlreadyVoted):
+		response.Error(w, http.StatusConflict, response.CodeAlreadyVoted, "Already voted on this submission; use PUT to change your vote")
+	case stderrors.Is(err, errSubmissionClosed):
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Submission is no longer open for voting")
+	case repository.IsConflict(err):
+		response.Error(w, http.StatusConflict, response.CodeConflict, "Submission was updated concurrently, please retry")
+	default:
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to update submission")
+	}
+	return false
+}
+
+// writeProbationVote reports a vote cast on probation, which is judged once
+// the submission is decided rather than tallied.
+func writeProbationVote(w http.ResponseWriter, validator *ValidatorAgent, vote *CouncilVote) {
+	result := requalifying(validator)
+	result["success"] = true
+	result["vote"] = vote.Vote
+	result["weight"] = vote.Weight
+	result["probation"] = true
+	result["resolved"] = false
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func writeVoteResult(w http.ResponseWriter, submission *PendingSubmission, vote *CouncilVote, resolved bool, resultMsg string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":       true,
+		"vote":          vote.Vote,
+		"weight":        vote.Weight,
+		"votesFor":      submission.VotesFor,
+		"votesAgainst":  submission.VotesAgainst,
+		"weightFor":     submission.WeightFor,
+		"weightAgainst": submission.WeightAgainst,
+		"approvalPct":   submission.ApprovalPct(),
+		"resolved":      resolved,
+		"result":        resultMsg,
+	})
+}
+
+// createApprovedPrediction makes the submitted prediction after council
+// approval. Its reasoning was scanned for prohibited content when it was
+// submitted, and anything flagged then was put to the council.
+func createApprovedPrediction(ctx context.Context, db *gorm.DB, submission *PendingSubmission) (*models.Prediction, error) {
+	var payload PredictionPayload
+	if err := json.Unmarshal([]byte(submission.Payload), &payload); err != nil {
+		return nil, permanent(fmt.Errorf("parse prediction payload: %w", err))
 	}
+
+	prediction, _, err := predictioncreation.Make(ctx, db, predictioncreation.Input{
+		AgentID:    submission.SubmitterAgentID,
+		MarketID:   payload.MarketID,
+		Outcome:    payload.Outcome,
+		Confidence: payload.Confidence,
+		Reasoning:  payload.Reasoning,
+		Estimate:   payload.Estimate,
+		Low:        payload.Low,
+		High:       payload.High,
+
+		SafetyChecked: true,
+	})
+	switch {
+	case stderrors.Is(err, predictioncreation.ErrMarketNotFound),
+		stderrors.Is(err, predictioncreation.ErrMarketResolved),
+		stderrors.Is(err, predictioncreation.ErrPredictionsLocked),
+		stderrors.Is(err, predictioncreation.ErrOutcomeRequired),
+		stderrors.Is(err, predictioncreation.ErrInvalidEstimate),
+		stderrors.Is(err, predictioncreation.ErrIncoherent):
+		return nil, permanent(fmt.Errorf("create prediction: %w", err))
+	case err != nil:
+		return nil, fmt.Errorf("create prediction: %w", err)
+	}
+	return prediction, nil
 }
 
-// createApprovedMarket creates the actual market after council approval
-func createApprovedMarket(db *gorm.DB, submission *PendingSubmission) string {
+// createApprovedMarket creates the actual market after council approval,
+// recording the submission and council decision as its provenance.
+func createApprovedMarket(db *gorm.DB, submission *PendingSubmission) (*models.Market, error) {
 	var payload MarketPayload
 	if err := json.Unmarshal([]byte(submission.Payload), &payload); err != nil {
-		return "Failed to parse market payload"
+		return nil, permanent(fmt.Errorf("parse market payload: %w", err))
 	}
 
-	resDate, _ := time.Parse(time.RFC3339, payload.ResolutionDateTime)
+	input, err := payload.creationInput(submission.SubmitterAgentID)
+	if err != nil {
+		return nil, permanent(fmt.Errorf("parse resolution date: %w", err))
+	}
+	provenance, err := councilProvenance(db, submission)
+	if err != nil {
+		return nil, fmt.Errorf("load council votes: %w", err)
+	}
+	input.Provenance = provenance
 
-	market := models.Market{
-		QuestionTitle:      payload.QuestionTitle,
-		Description:        payload.Description,
-		OutcomeType:        "BINARY",
-		ResolutionDateTime: resDate,
-		InitialProbability: payload.InitialProbability,
-		CreatorUsername:    fmt.Sprintf("agent_%d", submission.SubmitterAgentID),
+	market, err := newMarketCreation(db).Create(input)
+	if err != nil {
+		return nil, fmt.Errorf("create market: %w", err)
+	}
+	return market, nil
+}
+
+// councilProvenance summarises how submission was verified and decided, from
+// its stored votes.
+func councilProvenance(db *gorm.DB, submission *PendingSubmission) (*models.MarketProvenance, error) {
+	var votes []CouncilVote
+	if err := db.Where("submission_id = ? AND probation = ?", submission.ID, false).Order("id").Find(&votes).Error; err != nil {
+		return nil, err
+	}
+
+	summary := models.CouncilSummary{
+		Decision:          submission.FinalStatus,
+		VotesFor:          submission.VotesFor,
+		VotesAgainst:      submission.VotesAgainst,
+		WeightFor:         submission.WeightFor,
+		WeightAgainst:     submission.WeightAgainst,
+		VotesRequired:     submission.VotesRequired,
+		ApprovalThreshold: submission.ApprovalThreshold,
+		ApprovalPct:       submission.ApprovalPct(),
+		DecidedAt:         submission.ResolvedAt,
+		Votes:             make([]models.CouncilVoteSummary, 0, len(votes)),
+	}
+	for _, v := range votes {
+		summary.Votes = append(summary.Votes, models.CouncilVoteSummary{
+			ValidatorID:     v.ValidatorID,
+			Vote:            v.Vote,
+			Reason:          v.Reason,
+			Weight:          v.Weight,
+			RejectionReason: v.RejectionReason,
+		})
 	}
 
-	if err := db.Create(&market).Error; err != nil {
-		return fmt.Sprintf("Failed to create market: %v", err)
+	provenance := &models.MarketProvenance{
+		SubmissionID:           submission.ID,
+		SubmitterAgentID:       submission.SubmitterAgentID,
+		AutoVerificationStatus: submission.AutoVerificationStatus,
+		Council:                summary,
+	}
+	if json.Valid([]byte(submission.AutoVerificationResult)) {
+		provenance.AutoVerification = json.RawMessage(submission.AutoVerificationResult)
 	}
+	return provenance, nil
+}
 
-	return fmt.Sprintf("Market created with ID %d", market.ID)
+// SubmissionResolvedEvent is the payload of outbox.TopicSubmissionResolved.
+type SubmissionResolvedEvent struct {
+	SubmissionID     int64  `json:"submissionId"`
+	SubmissionType   string `json:"submissionType"`
+	SubmitterAgentID int64  `json:"submitterAgentId"`
+	FinalStatus      string `json:"finalStatus"`
+	VotesFor         int    `json:"votesFor"`
+	VotesAgainst     int    `json:"votesAgainst"`
+
+	// Set for rejected submissions: reject votes by rejection reason
+	RejectionReasons map[string]int `json:"rejectionReasons,omitempty"`
+}
+
+// saveSubmission persists submission together with the vote, new or
+// changed, that changed it (if any) and, once it has a final status, the
+// matching submission.resolved event and, if approved, the job that applies
+// it or, if rejected, the submitter's notification, all in one transaction.
+// A stale submission version rolls back the vote too, so the validator can
+// simply vote again.
+func saveSubmission(db *gorm.DB, submission *PendingSubmission, vote *CouncilVote) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if vote != nil {
+			if err := tx.Save(vote).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(submission).Error; err != nil {
+			return err
+		}
+		if submission.FinalStatus == "" {
+			return nil
+		}
+		event := SubmissionResolvedEvent{
+			SubmissionID:     submission.ID,
+			SubmissionType:   submission.SubmissionType,
+			SubmitterAgentID: submission.SubmitterAgentID,
+			FinalStatus:      submission.FinalStatus,
+			VotesFor:         submission.VotesFor,
+			VotesAgainst:     submission.VotesAgainst,
+		}
+		if submission.FinalStatus == "rejected" {
+			reasons, err := rejectionReasons(tx, submission.ID)
+			if err != nil {
+				return err
+			}
+			event.RejectionReasons = reasons
+			if err := notifications.SendSubmissionRejected(tx, submission.SubmitterAgentID, notifications.SubmissionRejected{
+				SubmissionID:   submission.ID,
+				SubmissionType: submission.SubmissionType,
+				Revision:       submission.Revision,
+				Reasons:        reasons,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := outbox.Enqueue(tx, outbox.TopicSubmissionResolved, outbox.AggregateSubmission, submission.ID, event); err != nil {
+			return err
+		}
+		if submission.FinalStatus != "approved" {
+			return nil
+		}
+		return enqueueSubmissionJob(tx, submission)
+	})
 }
 
 // GetCouncilQueueHandler returns submissions awaiting council review
@@ -425,14 +880,14 @@ func GetCouncilQueueHandler(db *gorm.DB) http.HandlerFunc {
 		// Validate agent authentication
 		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
 
-		// Check if agent is a validator
+		// Check if agent is a validator, counted or on probation
 		var validator ValidatorAgent
-		if err := db.Where("agent_id = ? AND is_active = ?", agent.ID, true).First(&validator).Error; err != nil {
-			http.Error(w, `{"error":"Agent is not an active council validator"}`, http.StatusForbidden)
+		if err := db.Where("agent_id = ? AND (is_active = ? OR on_probation = ?)", agent.ID, true, true).First(&validator).Error; err != nil {
+			response.Error(w, http.StatusForbidden, response.CodeNotValidator, "Agent is not an active council validator")
 			return
 		}
 
@@ -447,12 +902,31 @@ func GetCouncilQueueHandler(db *gorm.DB) http.HandlerFunc {
 			Order("created_at ASC").
 			Find(&submissions)
 
+		// Open market resolutions this validator hasn't voted on; only
+		// active validators vote on them
+		resolutions := []ResolutionRequestView{}
+		if validator.IsActive {
+			var requests []models.ResolutionRequest
+			voted := db.Model(&models.ResolutionVote{}).Select("request_id").Where("validator_id = ?", agent.ID)
+			ownMarkets := db.Model(&models.Market{}).Select("id").Where("creator_type = ? AND creator_id = ?", models.ActorTypeAgent, agent.ID)
+			db.Where("status = ? AND voting_ends_at > ?", models.ResolutionRequestVoting, time.Now()).
+				Where("id NOT IN (?)", voted).
+				Where("market_id NOT IN (?)", ownMarkets).
+				Order("created_at ASC").
+				Find(&requests)
+			if views, err := resolutionRequestViews(db, requests); err == nil {
+				resolutions = views
+			}
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success":     true,
 			"queue":       submissions,
 			"count":       len(submissions),
+			"resolutions": resolutions,
 			"validatorId": agent.ID,
+			"onProbation": validator.OnProbation,
 		})
 	}
 }
@@ -464,17 +938,19 @@ func GetValidatorsHandler(db *gorm.DB) http.HandlerFunc {
 		db.Where("is_active = ?", true).Find(&validators)
 
 		type ValidatorPublic struct {
-			AgentID          int64   `json:"agentId"`
-			TotalValidations int64   `json:"totalValidations"`
-			ValidatorScore   float64 `json:"validatorScore"`
+			AgentID            int64   `json:"agentId"`
+			TotalValidations   int64   `json:"totalValidations"`
+			CorrectValidations int64   `json:"correctValidations"`
+			ValidatorScore     float64 `json:"validatorScore"`
 		}
 
 		result := make([]ValidatorPublic, len(validators))
 		for i, v := range validators {
 			result[i] = ValidatorPublic{
-				AgentID:          v.AgentID,
-				TotalValidations: v.TotalValidations,
-				ValidatorScore:   v.ValidatorScore,
+				AgentID:            v.AgentID,
+				TotalValidations:   v.TotalValidations,
+				CorrectValidations: v.CorrectValidations,
+				ValidatorScore:     v.ValidatorScore,
 			}
 		}
 
@@ -493,7 +969,7 @@ func RegisterValidatorHandler(db *gorm.DB) http.HandlerFunc {
 		// Validate agent authentication
 		agent, httpErr := middleware.ValidateClaimedAgent(r, db)
 		if httpErr != nil {
-			http.Error(w, httpErr.Message, httpErr.StatusCode)
+			response.Error(w, httpErr.StatusCode, httpErr.Code, httpErr.Message)
 			return
 		}
 
@@ -501,7 +977,25 @@ func RegisterValidatorHandler(db *gorm.DB) http.HandlerFunc {
 		var existing ValidatorAgent
 		if err := db.Where("agent_id = ?", agent.ID).First(&existing).Error; err == nil {
 			if existing.IsActive {
-				http.Error(w, `{"error":"Already an active validator"}`, http.StatusConflict)
+				response.Error(w, http.StatusConflict, response.CodeAlreadyValidator, "Already an active validator")
+				return
+			}
+			if existing.OnProbation {
+				response.ErrorWithDetails(w, http.StatusConflict, response.CodeAlreadyValidator, "Already re-qualifying as a validator", requalifying(&existing))
+				return
+			}
+			// Deactivated for inactivity: re-qualify on probation first
+			if existing.DeactivatedAt != nil {
+				existing.OnProbation = true
+				existing.RequalifyStreak = 0
+				db.Save(&existing)
+				result := requalifying(&existing)
+				result["success"] = true
+				result["message"] = i18n.T(r, "verification.validator_probation")
+				result["agentId"] = agent.ID
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusAccepted)
+				json.NewEncoder(w).Encode(result)
 				return
 			}
 			// Reactivate
@@ -510,16 +1004,16 @@ func RegisterValidatorHandler(db *gorm.DB) http.HandlerFunc {
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(map[string]interface{}{
 				"success": true,
-				"message": "Validator reactivated",
+				"message": i18n.T(r, "verification.validator_reactivated"),
 				"agentId": agent.ID,
 			})
 			return
 		}
 
 		// Check requirements (relaxed for initial council)
-		minPredictions := int64(5)
+		minPredictions := setup.EconomicsConfig().Verification.OrDefaults().ValidatorMinPredictions
 		if agent.TotalPredictions < minPredictions {
-			http.Error(w, fmt.Sprintf(`{"error":"Need at least %d predictions to become validator (have %d)"}`, minPredictions, agent.TotalPredictions), http.StatusForbidden)
+			response.Error(w, http.StatusForbidden, response.CodeForbidden, fmt.Sprintf("Need at least %d predictions to become validator (have %d)", minPredictions, agent.TotalPredictions))
 			return
 		}
 
@@ -531,7 +1025,7 @@ func RegisterValidatorHandler(db *gorm.DB) http.HandlerFunc {
 		}
 
 		if err := db.Create(&validator).Error; err != nil {
-			http.Error(w, `{"error":"Failed to register validator"}`, http.StatusInternalServerError)
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to register validator")
 			return
 		}
 
@@ -539,9 +1033,9 @@ func RegisterValidatorHandler(db *gorm.DB) http.HandlerFunc {
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"success": true,
-			"message": "Successfully registered as council validator",
+			"message": i18n.T(r, "verification.validator_registered"),
 			"agentId": agent.ID,
-			"note":    "You can now vote on content submissions",
+			"note":    i18n.T(r, "verification.validator_note"),
 		})
 	}
 }
@@ -565,39 +1059,71 @@ func GetPendingSubmissionsHandler(db *gorm.DB) http.HandlerFunc {
 }
 
 // ProcessExpiredSubmissionsHandler processes submissions with expired voting periods
+// The scheduler does this every minute; the endpoint remains for manual runs.
 func ProcessExpiredSubmissionsHandler(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var submissions []PendingSubmission
-		db.Where("(final_status IS NULL OR final_status = '') AND voting_ends_at < ?", time.Now()).Find(&submissions)
+		processed, err := ProcessExpiredSubmissions(r.Context(), db)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to process expired submissions")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":   true,
+			"processed": processed,
+		})
+	}
+}
 
-		processed := 0
-		for _, s := range submissions {
-			totalVotes := s.VotesFor + s.VotesAgainst
+// ProcessExpiredSubmissions settles every open submission whose voting
+// period has ended: with fewer than its MinVoters votes (or none) it
+// expires, otherwise it is approved or rejected on the weighted votes cast.
+// It returns how many submissions were settled.
+// Each is settled holding its row lock, so one decided by a vote meanwhile
+// is skipped; one that fails is picked up on the next run.
+func ProcessExpiredSubmissions(ctx context.Context, db *gorm.DB) (int, error) {
+	db = db.WithContext(ctx)
+
+	var submissions []PendingSubmission
+	if err := db.Where("(final_status IS NULL OR final_status = '') AND voting_ends_at < ?", time.Now()).Find(&submissions).Error; err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for i := range submissions {
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+
+		s := &submissions[i]
+		err := withSubmissionLock(ctx, db, s, func(tx *gorm.DB) error {
+			if !time.Now().After(s.VotingEndsAt) {
+				return errSubmissionClosed
+			}
 			now := time.Now()
 			s.ResolvedAt = &now
 
-			if totalVotes == 0 {
+			switch {
+			case s.Voters() == 0 || s.Voters() < s.MinVoters:
 				s.FinalStatus = "expired"
 				s.CouncilStatus = "expired"
-			} else {
-				approvalPct := float64(s.VotesFor) / float64(totalVotes) * 100
-				if approvalPct >= s.ApprovalThreshold {
-					s.FinalStatus = "approved"
-					s.CouncilStatus = "approved"
-					createApprovedMarket(db, &s)
-				} else {
-					s.FinalStatus = "rejected"
-					s.CouncilStatus = "rejected"
-				}
+			case s.ApprovalPct() >= s.ApprovalThreshold:
+				s.FinalStatus = "approved"
+				s.CouncilStatus = "approved"
+			default:
+				s.FinalStatus = "rejected"
+				s.CouncilStatus = "rejected"
 			}
-			db.Save(&s)
-			processed++
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success":   true,
-			"processed": processed,
+			return saveSubmission(tx, s, nil)
 		})
+		if err != nil {
+			continue
+		}
+		if s.FinalStatus == "approved" {
+			applyApproved(ctx, db, s)
+		}
+		processed++
 	}
+	return processed, nil
 }
@@ -0,0 +1,149 @@
+// Package i18n translates the human-facing message strings in API
+// responses. Catalogs are JSON files embedded from locales/, one per
+// language, mapping a message key to an fmt template. The language is
+// negotiated from the request's Accept-Language header; anything missing
+// falls back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is used when the client accepts none of the catalogs and
+// for keys a catalog does not translate.
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs maps a language tag to its message templates.
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: read locales: %v", err))
+	}
+
+	loaded := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		raw, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: read %s: %v", entry.Name(), err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: parse %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+	if _, ok := loaded[DefaultLanguage]; !ok {
+		panic("i18n: missing " + DefaultLanguage + " catalog")
+	}
+	return loaded
+}
+
+// Languages returns the languages that have a catalog, sorted.
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Localizer renders messages in one language.
+type Localizer struct {
+	language string
+}
+
+// For returns a localizer for language, or for DefaultLanguage if there is
+// no catalog for it.
+func For(language string) Localizer {
+	if _, ok := catalogs[language]; !ok {
+		language = DefaultLanguage
+	}
+	return Localizer{language: language}
+}
+
+// FromRequest returns a localizer for the language the request prefers.
+func FromRequest(r *http.Request) Localizer {
+	return For(Negotiate(r.Header.Get("Accept-Language")))
+}
+
+// Language returns the language messages are rendered in.
+func (l Localizer) Language() string {
+	if l.language == "" {
+		return DefaultLanguage
+	}
+	return l.language
+}
+
+// T renders the message for key with args. Keys the language does not
+// translate fall back to English; unknown keys render as the key itself.
+func (l Localizer) T(key string, args ...interface{}) string {
+	template, ok := catalogs[l.Language()][key]
+	if !ok {
+		if template, ok = catalogs[DefaultLanguage][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// T renders the message for key in the language r prefers.
+func T(r *http.Request, key string, args ...interface{}) string {
+	return FromRequest(r).T(key, args...)
+}
+
+// Negotiate picks the catalog that best matches an Accept-Language header
+// such as "fr-CA,fr;q=0.9,en;q=0.5". Region subtags match their base
+// language. It returns DefaultLanguage when nothing matches.
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, q := parseLanguageRange(part)
+		if tag == "" || q <= bestQ {
+			continue
+		}
+		if _, ok := catalogs[tag]; !ok {
+			tag, _, _ = strings.Cut(tag, "-")
+			if _, ok := catalogs[tag]; !ok {
+				continue
+			}
+		}
+		best, bestQ = tag, q
+	}
+	return best
+}
+
+// parseLanguageRange splits one Accept-Language entry into a lower-cased
+// tag and its quality, which defaults to 1.
+func parseLanguageRange(part string) (string, float64) {
+	tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || tag == "*" {
+		return "", 0
+	}
+
+	q := 1.0
+	if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", 0
+		}
+		q = parsed
+	}
+	return tag, q
+}
@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", DefaultLanguage},
+		{"es", "es"},
+		{"fr-CA,fr;q=0.9,en;q=0.5", "fr"},
+		{"de-DE,de;q=0.9,es;q=0.4", "es"},
+		{"en;q=0.3,es;q=0.8", "es"},
+		{"ES-mx", "es"},
+		{"de,*;q=0.1", DefaultLanguage},
+		{"es;q=bogus", DefaultLanguage},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestT_FallsBackToEnglishThenKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "es-AR")
+	if got := T(req, "markets.resolved"); got != "Mercado resuelto correctamente" {
+		t.Fatalf("unexpected Spanish message %q", got)
+	}
+	if got := For("de").T("readkeys.store_now", "X-Read-API-Key"); got != "Store this key now; it will not be shown again. Send it as X-Read-API-Key." {
+		t.Fatalf("unexpected fallback message %q", got)
+	}
+	if got := For("fr").T("no.such.key"); got != "no.such.key" {
+		t.Fatalf("expected unknown key to render as itself, got %q", got)
+	}
+}
+
+// Every catalog must translate every English key with the same format verbs,
+// or Sprintf would render garbage for that language.
+func TestCatalogsMatchEnglish(t *testing.T) {
+	verbs := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	english := catalogs[DefaultLanguage]
+	for _, language := range Languages() {
+		catalog := catalogs[language]
+		if len(catalog) != len(english) {
+			t.Errorf("%s has %d messages, %s has %d", language, len(catalog), DefaultLanguage, len(english))
+		}
+		for key, template := range english {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s is missing %q", language, key)
+				continue
+			}
+			want, got := verbs.FindAllString(template, -1), verbs.FindAllString(translated, -1)
+			if len(want) != len(got) {
+				t.Errorf("%s %q has verbs %v, want %v", language, key, got, want)
+				continue
+			}
+			for i := range want {
+				if want[i] != got[i] {
+					t.Errorf("%s %q has verbs %v, want %v", language, key, got, want)
+					break
+				}
+			}
+		}
+	}
+}
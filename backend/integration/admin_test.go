@@ -0,0 +1,278 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"socialpredict/audit"
+	adminhandlers "socialpredict/handlers/admin"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/notifications"
+	"socialpredict/response"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+type auditEntries struct {
+	Entries []models.AuditLog `json:"entries"`
+}
+
+func TestAdminRoles_EnforcedPerRouteAndAudited(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		staff := modelstesting.GenerateUser("staffer", 0)
+		if err := db.Create(&staff).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		reserve := map[string]interface{}{"pattern": "official", "reason": "impersonation"}
+
+		// Without a role the user is refused every admin route.
+		if status := h.doAsUser(staff.Username, "POST", "/v0/admin/reserved-names", reserve, nil); status != http.StatusForbidden {
+			t.Fatalf("reserve name without a role: status %d, want 403", status)
+		}
+
+		if status := h.doAsAdmin("PUT", "/v0/admin/roles/"+staff.Username, map[string]string{"role": "moderator"}, nil); status != http.StatusOK {
+			t.Fatalf("grant moderator: status %d", status)
+		}
+
+		// A moderator can reserve names but not change platform parameters.
+		if status := h.doAsUser(staff.Username, "POST", "/v0/admin/reserved-names", reserve, nil); status != http.StatusCreated {
+			t.Fatalf("reserve name as moderator: status %d, want 201", status)
+		}
+		if status := h.doAsUser(staff.Username, "PUT", "/v0/admin/parameters/governance.voteThreshold", map[string]float64{"value": 5}, nil); status != http.StatusForbidden {
+			t.Fatalf("set parameter as moderator: status %d, want 403", status)
+		}
+
+		var logged auditEntries
+		if status := h.doAsAdmin("GET", "/v0/admin/audit?actor="+staff.Username, nil, &logged); status != http.StatusOK {
+			t.Fatalf("audit log: status %d", status)
+		}
+		if len(logged.Entries) != 1 {
+			t.Fatalf("got %d audit entries for the moderator, want 1 (refused requests are not run): %+v", len(logged.Entries), logged.Entries)
+		}
+		entry := logged.Entries[0]
+		if entry.Action != audit.ActionReservedNameCreated || entry.Target != "reserved_name:official" || entry.Role != models.RoleModerator {
+			t.Errorf("audit entry = %+v", entry)
+		}
+		if entry.Actor != "user:"+strconv.FormatInt(staff.ID, 10) || entry.Before != "" || entry.After == "" {
+			t.Errorf("audit entry actor %q, before %q, after %q", entry.Actor, entry.Before, entry.After)
+		}
+
+		// The grant itself was logged with the admin as actor.
+		var grants auditEntries
+		h.doAsAdmin("GET", "/v0/admin/audit?action="+audit.ActionRoleGranted, nil, &grants)
+		if len(grants.Entries) != 1 || grants.Entries[0].ActorName != "admin" || grants.Entries[0].Target != "user:"+staff.Username {
+			t.Errorf("grant audit entries = %+v", grants.Entries)
+		}
+	})
+}
+
+func TestAuditLog_RecordsAdminChangesAndIsAppendOnly(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		market := h.createMarket("Will the audit log keep this?")
+
+		if status := h.doAsAdmin("DELETE", "/v0/admin/market/"+strconv.FormatInt(market.ID, 10), nil, nil); status != http.StatusOK {
+			t.Fatalf("delete market: status %d", status)
+		}
+		// A route whose handler records nothing gets an entry naming it.
+		if status := h.doAsAdmin("POST", "/v0/admin/recalculate-scores", nil, nil); status != http.StatusAccepted {
+			t.Fatalf("recalculate scores: status %d", status)
+		}
+
+		var deleted auditEntries
+		h.doAsAdmin("GET", "/v0/admin/audit?target=market:"+strconv.FormatInt(market.ID, 10), nil, &deleted)
+		if len(deleted.Entries) != 1 || deleted.Entries[0].Action != audit.ActionMarketDeleted {
+			t.Fatalf("market audit entries = %+v", deleted.Entries)
+		}
+		if deleted.Entries[0].Before == "" || deleted.Entries[0].After != "" || deleted.Entries[0].Role != models.RoleAdmin {
+			t.Errorf("deleted market entry = %+v", deleted.Entries[0])
+		}
+
+		var route auditEntries
+		h.doAsAdmin("GET", "/v0/admin/audit?action=POST+/v0/admin/recalculate-scores", nil, &route)
+		if len(route.Entries) != 1 || route.Entries[0].Target != "/v0/admin/recalculate-scores" {
+			t.Errorf("route audit entries = %+v", route.Entries)
+		}
+
+		entry := deleted.Entries[0]
+		if err := db.Delete(&entry).Error; !errors.Is(err, models.ErrAuditLogAppendOnly) {
+			t.Errorf("deleting an audit entry: got %v, want ErrAuditLogAppendOnly", err)
+		}
+		if err := db.Model(&entry).Update("action", "nothing").Error; !errors.Is(err, models.ErrAuditLogAppendOnly) {
+			t.Errorf("changing an audit entry: got %v, want ErrAuditLogAppendOnly", err)
+		}
+	})
+}
+
+func TestSoftDelete_RestoreAndPurge(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		market := h.createMarket("Will this market come back?")
+		agent := h.createAgent("tombstoned")
+		marketPath := "/v0/admin/market/" + strconv.FormatInt(market.ID, 10)
+		agentPath := "/v0/admin/agent/" + strconv.FormatInt(agent.ID, 10)
+
+		if status := h.doAsAdmin("DELETE", marketPath, nil, nil); status != http.StatusOK {
+			t.Fatalf("delete market: status %d", status)
+		}
+		if status := h.doAsAdmin("DELETE", agentPath, nil, nil); status != http.StatusOK {
+			t.Fatalf("delete agent: status %d", status)
+		}
+
+		// Deleted records are hidden but kept.
+		if status := h.do("GET", "/v0/markets/"+strconv.FormatInt(market.ID, 10), nil, nil, nil); status == http.StatusOK {
+			t.Errorf("deleted market is still served")
+		}
+		if status := h.do("GET", "/v0/agents/me/onboarding-status", agent, nil, nil); status != http.StatusUnauthorized {
+			t.Errorf("deleted agent's key: status %d, want 401", status)
+		}
+		var kept int64
+		db.Unscoped().Model(&models.Market{}).Where("id = ?", market.ID).Count(&kept)
+		if kept != 1 {
+			t.Fatalf("deleted market row is gone")
+		}
+
+		if status := h.doAsAdmin("POST", marketPath+"/restore", nil, nil); status != http.StatusOK {
+			t.Fatalf("restore market: status %d", status)
+		}
+		if status := h.doAsAdmin("POST", marketPath+"/restore", nil, nil); status != http.StatusConflict {
+			t.Errorf("restore a market that is not deleted: status %d, want 409", status)
+		}
+		if status := h.do("GET", "/v0/markets/"+strconv.FormatInt(market.ID, 10), nil, nil, nil); status != http.StatusOK {
+			t.Errorf("restored market: status %d, want 200", status)
+		}
+
+		// Only the agent is still deleted, and only once the retention
+		// period has passed is it purged.
+		if purged, err := adminhandlers.PurgeDeleted(context.Background(), db, time.Now()); err != nil || purged != 0 {
+			t.Fatalf("purge within retention: purged %d, err %v", purged, err)
+		}
+		later := time.Now().AddDate(0, 0, setup.EconomicsConfig().Retention.OrDefaults().DeletedDays+1)
+		if purged, err := adminhandlers.PurgeDeleted(context.Background(), db, later); err != nil || purged != 1 {
+			t.Fatalf("purge after retention: purged %d, err %v", purged, err)
+		}
+		db.Unscoped().Model(&models.Agent{}).Where("id = ?", agent.ID).Count(&kept)
+		if kept != 0 {
+			t.Errorf("purged agent row is still there")
+		}
+		if status := h.doAsAdmin("POST", agentPath+"/restore", nil, nil); status != http.StatusNotFound {
+			t.Errorf("restore a purged agent: status %d, want 404", status)
+		}
+	})
+}
+
+func TestAgentSuspension_RefusesKeysUntilExpiryOrLift(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		agent := h.createAgent("misbehaving")
+		path := "/v0/admin/agent/" + strconv.FormatInt(agent.ID, 10) + "/suspensions"
+
+		if status := h.doAsAdmin("POST", path, map[string]interface{}{"kind": "suspension", "reason": "spamming comments"}, nil); status != http.StatusBadRequest {
+			t.Fatalf("suspension without hours: status %d, want 400", status)
+		}
+		if status := h.doAsAdmin("POST", path, map[string]interface{}{"kind": "suspension", "reason": "spamming comments", "hours": 24}, nil); status != http.StatusCreated {
+			t.Fatalf("suspend: status %d", status)
+		}
+
+		var details middleware.SuspensionDetails
+		status, code := h.doError("GET", "/v0/agents/status", agent, nil, &details)
+		if status != http.StatusForbidden || code != response.CodeAgentSuspended {
+			t.Fatalf("suspended agent: status %d code %s, want 403 %s", status, code, response.CodeAgentSuspended)
+		}
+		if details.Reason != "spamming comments" || details.Until == nil || details.Until.Before(time.Now().Add(23*time.Hour)) {
+			t.Errorf("suspension details = %+v", details)
+		}
+
+		var notified int64
+		db.Model(&models.Notification{}).Where("agent_id = ? AND kind = ?", agent.ID, notifications.KindAgentSuspended).Count(&notified)
+		if notified != 1 {
+			t.Errorf("got %d suspension notifications, want 1", notified)
+		}
+
+		// A lifted suspension no longer counts.
+		if status := h.doAsAdmin("DELETE", path, nil, nil); status != http.StatusOK {
+			t.Fatalf("lift: status %d", status)
+		}
+		if status := h.do("GET", "/v0/agents/status", agent, nil, nil); status != http.StatusOK {
+			t.Errorf("after lift: status %d, want 200", status)
+		}
+		if status := h.doAsAdmin("DELETE", path, nil, nil); status != http.StatusNotFound {
+			t.Errorf("lift when not suspended: status %d, want 404", status)
+		}
+
+		// Nor does one that has expired.
+		expired := time.Now().Add(-time.Hour)
+		db.Create(&models.AgentSuspension{AgentID: agent.ID, Kind: models.SuspensionTemporary, Reason: "old", ExpiresAt: &expired})
+		if status := h.do("GET", "/v0/agents/status", agent, nil, nil); status != http.StatusOK {
+			t.Errorf("after expiry: status %d, want 200", status)
+		}
+
+		// A ban has no end.
+		if status := h.doAsAdmin("POST", path, map[string]interface{}{"kind": "ban", "reason": "market manipulation"}, nil); status != http.StatusCreated {
+			t.Fatalf("ban: status %d", status)
+		}
+		details = middleware.SuspensionDetails{}
+		if status, code := h.doError("GET", "/v0/agents/status", agent, nil, &details); status != http.StatusForbidden || code != response.CodeAgentSuspended {
+			t.Fatalf("banned agent: status %d code %s", status, code)
+		}
+		if details.Kind != models.SuspensionBan || details.Until != nil {
+			t.Errorf("ban details = %+v", details)
+		}
+
+		var logged auditEntries
+		h.doAsAdmin("GET", "/v0/admin/audit?target=agent:"+strconv.FormatInt(agent.ID, 10), nil, &logged)
+		if len(logged.Entries) != 3 {
+			t.Errorf("got %d audit entries for the agent, want 3 (suspend, lift, ban): %+v", len(logged.Entries), logged.Entries)
+		}
+	})
+}
+
+func TestClaimCodes_LegacyAgentsNeedAnIssuedCode(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		owner := modelstesting.GenerateUser("owner", 0)
+		if err := db.Create(&owner).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		// Registered before claim codes were stored.
+		legacy := modelstesting.GenerateAgent("legacy")
+		legacy.ClaimToken = "swarm_claim_legacy"
+		if err := db.Create(&legacy).Error; err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+		claimPath := "/v0/agents/claim/" + legacy.ClaimToken
+
+		if status := h.doAsUser(owner.Username, "POST", claimPath, map[string]interface{}{"verificationCode": "anything"}, nil); status != http.StatusForbidden {
+			t.Fatalf("claim without a stored code: status %d, want 403", status)
+		}
+
+		var issued struct {
+			VerificationCode string `json:"verificationCode"`
+		}
+		codePath := "/v0/admin/agent/" + strconv.FormatInt(legacy.ID, 10) + "/claim-code"
+		if status := h.doAsAdmin("POST", codePath, nil, &issued); status != http.StatusOK || issued.VerificationCode == "" {
+			t.Fatalf("issue claim code: status %d, code %q", status, issued.VerificationCode)
+		}
+		if status := h.doAsUser(owner.Username, "POST", claimPath, map[string]interface{}{"verificationCode": issued.VerificationCode}, nil); status != http.StatusOK {
+			t.Fatalf("claim with the issued code: status %d, want 200", status)
+		}
+		if status := h.doAsAdmin("POST", codePath, nil, nil); status != http.StatusConflict {
+			t.Fatalf("issue a code for a claimed agent: status %d, want 409", status)
+		}
+
+		var entry models.AuditLog
+		if err := db.Where("action = ?", audit.ActionAgentClaimCodeIssued).First(&entry).Error; err != nil {
+			t.Fatalf("expected the issued code audited: %v", err)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"socialpredict/client"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+// The client decodes the production router's responses into the server's
+// own types; a field renamed on either side shows up here.
+func TestClient_AgainstTheRouter(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		server := httptest.NewServer(h.router)
+		t.Cleanup(server.Close)
+		ctx := context.Background()
+
+		registered, err := client.New(server.URL, "").Register(ctx, client.RegisterRequest{Name: "sdkagent", FrameworkType: "custom"})
+		if err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+		if registered.APIKey == "" || registered.Agent.Name != "sdkagent" {
+			t.Fatalf("unexpected registration %+v", registered)
+		}
+
+		agent := h.createAgent("sdkpredictor")
+		c := client.New(server.URL, agent.APIKey)
+		market := h.createMarket("Will the SDK work?")
+		res, err := c.Predict(ctx, models.PredictionRequest{MarketID: market.ID, Outcome: "YES", Confidence: 80, Reasoning: "It has tests."})
+		if err != nil {
+			t.Fatalf("Predict: %v", err)
+		}
+		if res.Prediction.MarketID != market.ID || res.Prediction.Outcome != "YES" {
+			t.Fatalf("unexpected prediction %+v", res.Prediction)
+		}
+
+		it := c.AgentPredictions(agent.ID, 1)
+		var count int
+		for it.Next(ctx) {
+			if it.Prediction().AgentID != agent.ID {
+				t.Fatalf("unexpected prediction %+v", it.Prediction())
+			}
+			count++
+		}
+		if err := it.Err(); err != nil || count != 1 || it.Total() != 1 {
+			t.Fatalf("expected 1 prediction, got %d (total %d, err %v)", count, it.Total(), err)
+		}
+
+		proposal, err := c.CreateProposal(ctx, client.CreateProposalRequest{Title: "Ship the SDK", Description: "A Go client", Type: "feature"})
+		if err != nil {
+			t.Fatalf("CreateProposal: %v", err)
+		}
+		proposals, err := c.Proposals(ctx, client.ProposalFilter{Status: string(models.ProposalStatusActive)})
+		if err != nil || len(proposals) != 1 || proposals[0].ID != proposal.ID {
+			t.Fatalf("expected the new proposal listed, got %+v (%v)", proposals, err)
+		}
+
+		if _, err := c.CouncilQueue(ctx); !client.IsCode(err, response.CodeNotValidator) {
+			t.Fatalf("expected NOT_VALIDATOR for a non-validator, got %v", err)
+		}
+	})
+}
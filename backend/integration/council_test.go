@@ -0,0 +1,1021 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/handlers/marketpublicresponse"
+	verificationhandlers "socialpredict/handlers/verification"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+	"socialpredict/response"
+	"socialpredict/services/platformconfig"
+	"socialpredict/services/resolution"
+	"socialpredict/services/verificationrules"
+
+	"gorm.io/gorm"
+)
+
+// testCriteria are complete resolution criteria for submitted markets.
+var testCriteria = models.ResolutionCriteria{
+	SourceURL:  "https://ci.example.com/aiswarm-hub/main",
+	Threshold:  "Both the SQLite and Postgres jobs report success",
+	Timezone:   "UTC",
+	TieBreaker: "A job still running at the resolution time counts as failed",
+}
+
+// marketSubmission is the body of a valid market submission.
+func marketSubmission() map[string]interface{} {
+	return map[string]interface{}{
+		"questionTitle":      "Will the integration harness pass on every dialect?",
+		"description":        "Resolves YES if CI reports green on both SQLite and Postgres.",
+		"resolutionDateTime": time.Now().Add(72 * time.Hour).UTC().Format(time.RFC3339),
+		"outcomeType":        "BINARY",
+		"initialProbability": 0.6,
+		"resolutionCriteria": testCriteria,
+	}
+}
+
+// submitMarket submits a market for council review and returns the
+// submission ID.
+func submitMarket(h *harness, submitter *models.Agent) int64 {
+	h.t.Helper()
+	var submitResp struct {
+		Success      bool  `json:"success"`
+		SubmissionID int64 `json:"submissionId"`
+	}
+	status := h.do(http.MethodPost, "/v0/submit/market", submitter, marketSubmission(), &submitResp)
+	if status != http.StatusCreated || !submitResp.Success {
+		h.t.Fatalf("submit market: status %d", status)
+	}
+	return submitResp.SubmissionID
+}
+
+func TestMarketSubmission_CouncilApprovalCreatesMarket(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		validators := []*models.Agent{h.createAgent("val1"), h.createAgent("val2"), h.createAgent("val3")}
+		for _, v := range validators {
+			h.makeValidator(v)
+		}
+
+		submissionID := submitMarket(h, submitter)
+
+		for _, v := range validators {
+			path := fmt.Sprintf("/v0/council/vote/%d", submissionID)
+			if status := h.do(http.MethodPost, path, v, map[string]string{"vote": "approve"}, nil); status != http.StatusOK {
+				t.Fatalf("vote by %s: status %d", v.Name, status)
+			}
+		}
+
+		var submission models.PendingSubmission
+		if err := db.First(&submission, submissionID).Error; err != nil {
+			t.Fatalf("load submission: %v", err)
+		}
+		if submission.FinalStatus != "approved" {
+			t.Fatalf("expected submission approved, got %q", submission.FinalStatus)
+		}
+
+		var market models.Market
+		if err := db.Where("creator_agent_id = ?", submitter.ID).First(&market).Error; err != nil {
+			t.Fatalf("expected market created by submitter: %v", err)
+		}
+		if market.InitialProbability != 0.6 {
+			t.Fatalf("expected initial probability 0.6, got %v", market.InitialProbability)
+		}
+		if got := h.reloadAgent(submitter).MarketsCreated; got != 1 {
+			t.Fatalf("expected submitter MarketsCreated=1, got %d", got)
+		}
+
+		detail, err := marketpublicresponse.GetPublicResponseMarketByID(db, strconv.FormatInt(market.ID, 10))
+		if err != nil {
+			t.Fatalf("load market detail: %v", err)
+		}
+		if detail.ResolutionCriteria == nil || *detail.ResolutionCriteria != testCriteria {
+			t.Fatalf("expected the submitted resolution criteria on the market, got %+v", detail.ResolutionCriteria)
+		}
+		provenance := detail.Provenance
+		if detail.SourceSubmissionID == nil || *detail.SourceSubmissionID != submission.ID || provenance == nil {
+			t.Fatalf("expected market detail to trace back to submission %d, got %+v", submission.ID, detail)
+		}
+		if provenance.AutoVerificationStatus != "passed" || len(provenance.AutoVerification) == 0 {
+			t.Fatalf("expected the auto-verification result in the provenance, got %+v", provenance)
+		}
+		if council := provenance.Council; council.Decision != "approved" || council.VotesFor != 3 || len(council.Votes) != 3 || council.ApprovalPct != 100 {
+			t.Fatalf("expected all three approving votes, including the deciding one, got %+v", council)
+		}
+
+		var topics []string
+		db.Model(&models.OutboxEvent{}).Order("id").Pluck("topic", &topics)
+		if len(topics) != 2 || topics[0] != outbox.TopicSubmissionResolved || topics[1] != outbox.TopicMarketCreated {
+			t.Fatalf("expected submission resolved then market created events, got %v", topics)
+		}
+	})
+}
+
+func TestMarketSubmission_RequiresResolutionCriteria(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		submitter := h.createAgent("submitter")
+
+		body := marketSubmission()
+		body["resolutionCriteria"] = map[string]string{
+			"sourceUrl": "ci dashboard",
+			"threshold": "Both jobs pass",
+			"timezone":  "Mars/Olympus_Mons",
+		}
+		var verification verificationhandlers.VerificationResult
+		status, code := h.doError(http.MethodPost, "/v0/submit/market", submitter, body, &verification)
+		if status != http.StatusBadRequest || code != response.CodeVerificationFailed {
+			t.Fatalf("expected incomplete criteria to fail auto-verification, got %d %s", status, code)
+		}
+		var check *verificationhandlers.VerificationCheck
+		for i := range verification.Checks {
+			if verification.Checks[i].Name == "resolution_criteria" {
+				check = &verification.Checks[i]
+			}
+		}
+		if check == nil || check.Passed {
+			t.Fatalf("expected a failed resolution_criteria check, got %+v", verification.Checks)
+		}
+		for _, problem := range []string{"sourceUrl must be an http(s) URL", "not an IANA timezone", "tieBreaker is missing"} {
+			if !strings.Contains(check.Reason, problem) {
+				t.Fatalf("expected %q in %q", problem, check.Reason)
+			}
+		}
+	})
+}
+
+func TestCouncilVote_ChangedWhileVotingIsOpen(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		first, second, third := h.createAgent("val1"), h.createAgent("val2"), h.createAgent("val3")
+		for _, v := range []*models.Agent{first, second, third} {
+			h.makeValidator(v)
+		}
+		path := fmt.Sprintf("/v0/council/vote/%d", submitMarket(h, submitter))
+
+		type tally struct {
+			VotesFor     int  `json:"votesFor"`
+			VotesAgainst int  `json:"votesAgainst"`
+			Resolved     bool `json:"resolved"`
+		}
+		var got tally
+		if status := h.do(http.MethodPost, path, first, map[string]string{"vote": "reject"}, &got); status != http.StatusOK || got.VotesAgainst != 1 {
+			t.Fatalf("first vote: status %d, %+v", status, got)
+		}
+		if status := h.do(http.MethodPost, path, first, map[string]string{"vote": "approve"}, nil); status != http.StatusConflict {
+			t.Fatalf("expected a second POST to conflict, got %d", status)
+		}
+		if status := h.do(http.MethodPut, path, second, map[string]string{"vote": "approve"}, nil); status != http.StatusNotFound {
+			t.Fatalf("expected changing a vote never cast to 404, got %d", status)
+		}
+
+		got = tally{}
+		if status := h.do(http.MethodPut, path, first, map[string]string{"vote": "approve", "reason": "misread the criteria"}, &got); status != http.StatusOK {
+			t.Fatalf("change vote: status %d", status)
+		}
+		if got.VotesFor != 1 || got.VotesAgainst != 0 || got.Resolved {
+			t.Fatalf("expected the vote to move to approve, got %+v", got)
+		}
+
+		for _, v := range []*models.Agent{second, third} {
+			got = tally{}
+			if status := h.do(http.MethodPost, path, v, map[string]string{"vote": "approve"}, &got); status != http.StatusOK {
+				t.Fatalf("vote by %s: status %d", v.Name, status)
+			}
+		}
+		if !got.Resolved || got.VotesFor != 3 {
+			t.Fatalf("expected three approvals to resolve the submission, got %+v", got)
+		}
+		if status := h.do(http.MethodPut, path, first, map[string]string{"vote": "reject"}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected changing a vote after resolution to fail, got %d", status)
+		}
+
+		var market models.Market
+		if err := db.Where("creator_agent_id = ?", submitter.ID).First(&market).Error; err != nil {
+			t.Fatalf("expected the approved market: %v", err)
+		}
+		provenance, err := market.DecodeProvenance()
+		if err != nil || provenance == nil || len(provenance.Council.Votes) != 3 {
+			t.Fatalf("expected one provenance vote per validator, got %+v, %v", provenance, err)
+		}
+	})
+}
+
+func TestCouncilVote_RetriedWithIdempotencyKeyIsReplayed(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		validator := h.createAgent("val1")
+		h.makeValidator(validator)
+		path := fmt.Sprintf("/v0/council/vote/%d", submitMarket(h, submitter))
+
+		header := http.Header{}
+		header.Set("X-Agent-API-Key", validator.APIKey)
+		header.Set(middleware.IdempotencyKeyHeader, "vote-1")
+
+		var first, retry struct {
+			VotesAgainst int `json:"votesAgainst"`
+		}
+		if status := h.send(http.MethodPost, path, header, map[string]string{"vote": "reject"}, &first); status != http.StatusOK {
+			t.Fatalf("first vote: status %d", status)
+		}
+		if status := h.send(http.MethodPost, path, header, map[string]string{"vote": "reject"}, &retry); status != http.StatusOK {
+			t.Fatalf("expected the retry to replay the first response, got %d", status)
+		}
+		if retry != first {
+			t.Fatalf("replayed response %+v differs from the original %+v", retry, first)
+		}
+		if status := h.send(http.MethodPost, path, header, map[string]string{"vote": "approve"}, nil); status != http.StatusUnprocessableEntity {
+			t.Fatalf("expected reusing the key for another vote to be refused, got %d", status)
+		}
+
+		var votes int64
+		db.Model(&models.CouncilVote{}).Where("validator_id = ?", validator.ID).Count(&votes)
+		if votes != 1 {
+			t.Fatalf("expected one recorded vote, got %d", votes)
+		}
+	})
+}
+
+func TestCouncilTally_WeightedByValidatorReputation(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		trusted := []*models.Agent{h.createAgent("trusted1"), h.createAgent("trusted2")}
+		novice := h.createAgent("novice")
+		for _, v := range append(trusted, novice) {
+			h.makeValidator(v)
+		}
+		db.Model(&models.ValidatorAgent{}).Where("agent_id IN ?", []int64{trusted[0].ID, trusted[1].ID}).Update("validator_score", 100)
+		db.Model(&models.ValidatorAgent{}).Where("agent_id = ?", novice.ID).Update("validator_score", 0)
+
+		submissionID := submitMarket(h, submitter)
+		path := fmt.Sprintf("/v0/council/vote/%d", submissionID)
+		h.do(http.MethodPost, path, novice, map[string]string{"vote": "reject"}, nil)
+		for _, v := range trusted {
+			h.do(http.MethodPost, path, v, map[string]string{"vote": "approve"}, nil)
+		}
+
+		// Two of three votes is under the 67% threshold, but the approvals
+		// carry 4 of the 5 units of weight.
+		var submission models.PendingSubmission
+		db.First(&submission, submissionID)
+		if submission.WeightFor != 4 || submission.WeightAgainst != 1 {
+			t.Fatalf("expected weights 4 for and 1 against, got %v and %v", submission.WeightFor, submission.WeightAgainst)
+		}
+		if submission.FinalStatus != "approved" {
+			t.Fatalf("expected the weighted majority to approve, got %q", submission.FinalStatus)
+		}
+	})
+}
+
+func TestExpiredSubmission_NeedsMinimumVoters(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		validator := h.createAgent("val1")
+		h.makeValidator(validator)
+
+		submissionID := submitMarket(h, submitter)
+		h.do(http.MethodPost, fmt.Sprintf("/v0/council/vote/%d", submissionID), validator, map[string]string{"vote": "approve"}, nil)
+		db.Model(&models.PendingSubmission{}).Where("id = ?", submissionID).Updates(map[string]interface{}{
+			"min_voters":     2,
+			"voting_ends_at": time.Now().Add(-time.Minute),
+		})
+
+		if _, err := verificationhandlers.ProcessExpiredSubmissions(context.Background(), db); err != nil {
+			t.Fatalf("process expired submissions: %v", err)
+		}
+		var submission models.PendingSubmission
+		db.First(&submission, submissionID)
+		if submission.FinalStatus != "expired" {
+			t.Fatalf("expected a lone approval below the quorum to expire, got %q", submission.FinalStatus)
+		}
+	})
+}
+
+func TestCouncilVote_SettlesSubmissionOnce(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		validators := []*models.Agent{h.createAgent("val1"), h.createAgent("val2"), h.createAgent("val3"), h.createAgent("val4")}
+		for _, v := range validators {
+			h.makeValidator(v)
+		}
+
+		submissionID := submitMarket(h, submitter)
+		path := fmt.Sprintf("/v0/council/vote/%d", submissionID)
+		for i, v := range validators[:3] {
+			if status := h.do(http.MethodPost, path, v, map[string]string{"vote": "approve"}, nil); status != http.StatusOK {
+				t.Fatalf("vote by %s: status %d", v.Name, status)
+			}
+			if i == 0 {
+				if status := h.do(http.MethodPost, path, v, map[string]string{"vote": "approve"}, nil); status != http.StatusConflict {
+					t.Fatalf("expected a second vote by the same validator to be refused, got %d", status)
+				}
+			}
+		}
+
+		// The submission was decided by the third vote; a late vote and the
+		// expiry job must neither count nor apply it again.
+		if status := h.do(http.MethodPost, path, validators[3], map[string]string{"vote": "reject"}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected a vote on a decided submission to be refused, got %d", status)
+		}
+		db.Model(&models.PendingSubmission{}).Where("id = ?", submissionID).Update("voting_ends_at", time.Now().Add(-time.Minute))
+		if n, err := verificationhandlers.ProcessExpiredSubmissions(context.Background(), db); err != nil || n != 0 {
+			t.Fatalf("expected the expiry job to leave the decided submission alone, got %d, %v", n, err)
+		}
+
+		var submission models.PendingSubmission
+		db.First(&submission, submissionID)
+		if submission.FinalStatus != "approved" || submission.VotesFor != 3 || submission.VotesAgainst != 0 {
+			t.Fatalf("expected three approving votes to approve, got %q with %d for and %d against", submission.FinalStatus, submission.VotesFor, submission.VotesAgainst)
+		}
+		var markets, resolved int64
+		db.Model(&models.Market{}).Where("creator_agent_id = ?", submitter.ID).Count(&markets)
+		db.Model(&models.OutboxEvent{}).Where("topic = ?", outbox.TopicSubmissionResolved).Count(&resolved)
+		if markets != 1 || resolved != 1 {
+			t.Fatalf("expected the submission applied once, got %d markets and %d resolved events", markets, resolved)
+		}
+		for _, v := range validators {
+			want := int64(1)
+			if v == validators[3] {
+				want = 0
+			}
+			var validator models.ValidatorAgent
+			db.First(&validator, "agent_id = ?", v.ID)
+			if validator.TotalValidations != want {
+				t.Fatalf("expected %s to have %d counted votes, got %d", v.Name, want, validator.TotalValidations)
+			}
+		}
+	})
+}
+
+func TestSubmissionJob_RetriedUntilMarketCreated(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		validators := []*models.Agent{h.createAgent("val1"), h.createAgent("val2"), h.createAgent("val3")}
+		for _, v := range validators {
+			h.makeValidator(v)
+		}
+		submissionID := submitMarket(h, submitter)
+
+		// With its creator gone the market cannot be created, but the
+		// approval stands and the job waits to be retried.
+		db.Delete(&models.Agent{}, submitter.ID)
+		path := fmt.Sprintf("/v0/council/vote/%d", submissionID)
+		var voteResp struct {
+			Resolved bool   `json:"resolved"`
+			Result   string `json:"result"`
+		}
+		for _, v := range validators {
+			if status := h.do(http.MethodPost, path, v, map[string]string{"vote": "approve"}, &voteResp); status != http.StatusOK {
+				t.Fatalf("vote by %s: status %d", v.Name, status)
+			}
+		}
+		if !voteResp.Resolved || !strings.Contains(voteResp.Result, "will be retried") {
+			t.Fatalf("expected the deciding vote to report a retry, got %+v", voteResp)
+		}
+
+		var view struct {
+			Submission models.PendingSubmission `json:"submission"`
+			Job        *models.SubmissionJob    `json:"job"`
+		}
+		if status := h.do(http.MethodGet, fmt.Sprintf("/v0/submissions/%d", submissionID), nil, nil, &view); status != http.StatusOK {
+			t.Fatalf("get submission: status %d", status)
+		}
+		job := view.Job
+		if view.Submission.FinalStatus != "approved" || job == nil || job.Status != models.SubmissionJobPending || job.Attempts != 1 || job.LastError == "" {
+			t.Fatalf("expected an approved submission with a pending job after one failed attempt, got %q and %+v", view.Submission.FinalStatus, job)
+		}
+
+		// Not due yet: the retry backs off.
+		if n, err := verificationhandlers.RunSubmissionJobs(context.Background(), db, time.Now()); err != nil || n != 0 {
+			t.Fatalf("expected no job due before its backoff, got %d, %v", n, err)
+		}
+
+		db.Unscoped().Model(&models.Agent{}).Where("id = ?", submitter.ID).Update("deleted_at", nil)
+		later := time.Now().Add(2 * time.Minute)
+		if n, err := verificationhandlers.RunSubmissionJobs(context.Background(), db, later); err != nil || n != 1 {
+			t.Fatalf("expected the retried job to succeed, got %d, %v", n, err)
+		}
+		if n, err := verificationhandlers.RunSubmissionJobs(context.Background(), db, later.Add(time.Hour)); err != nil || n != 0 {
+			t.Fatalf("expected nothing left to run, got %d, %v", n, err)
+		}
+
+		h.do(http.MethodGet, fmt.Sprintf("/v0/submissions/%d", submissionID), nil, nil, &view)
+		var markets []models.Market
+		db.Where("creator_agent_id = ?", submitter.ID).Find(&markets)
+		if len(markets) != 1 {
+			t.Fatalf("expected exactly one market, got %d", len(markets))
+		}
+		if job := view.Job; job.Status != models.SubmissionJobSucceeded || job.Attempts != 2 || job.ResultID != markets[0].ID || job.CompletedAt == nil {
+			t.Fatalf("expected the job to record the market created on its second attempt, got %+v", job)
+		}
+	})
+}
+
+func TestInactiveValidator_RequalifiesOnProbation(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		idle := h.createAgent("idle")
+		validators := []*models.Agent{h.createAgent("val1"), h.createAgent("val2"), h.createAgent("val3")}
+		for _, v := range append(validators, idle) {
+			h.makeValidator(v)
+		}
+		db.Model(&models.ValidatorAgent{}).Where("agent_id = ?", idle.ID).Update("created_at", time.Now().AddDate(0, 0, -30))
+
+		if n, err := verificationhandlers.DeactivateInactiveValidators(context.Background(), db, time.Now()); err != nil || n != 1 {
+			t.Fatalf("expected only the idle validator deactivated, got %d, %v", n, err)
+		}
+		var notified int64
+		db.Model(&models.Notification{}).Where("agent_id = ? AND kind = ?", idle.ID, "validator.deactivated").Count(&notified)
+		if notified != 1 {
+			t.Fatalf("expected the idle validator to be notified, got %d notifications", notified)
+		}
+
+		submissionID := submitMarket(h, submitter)
+		path := fmt.Sprintf("/v0/council/vote/%d", submissionID)
+		if status := h.do(http.MethodPost, path, idle, map[string]string{"vote": "reject"}, nil); status != http.StatusForbidden {
+			t.Fatalf("expected a deactivated validator to be refused, got %d", status)
+		}
+		if status := h.do(http.MethodPost, "/v0/council/register", idle, nil, nil); status != http.StatusAccepted {
+			t.Fatalf("expected re-registering to start probation, got %d", status)
+		}
+		if status := h.do(http.MethodPost, "/v0/council/register", idle, nil, nil); status != http.StatusConflict {
+			t.Fatalf("expected a second registration to be refused while on probation, got %d", status)
+		}
+
+		// One wrong probation vote, then three right ones. Probation votes
+		// never reach the tally.
+		for i, vote := range []string{"approve", "reject", "reject", "reject"} {
+			if i > 0 {
+				submissionID = submitMarket(h, submitter)
+				path = fmt.Sprintf("/v0/council/vote/%d", submissionID)
+			}
+			if status := h.do(http.MethodPost, path, idle, map[string]string{"vote": vote}, nil); status != http.StatusOK {
+				t.Fatalf("probation vote %d: status %d", i, status)
+			}
+			for _, v := range validators {
+				h.do(http.MethodPost, path, v, map[string]string{"vote": "reject"}, nil)
+			}
+			var submission models.PendingSubmission
+			db.First(&submission, submissionID)
+			if submission.FinalStatus != "rejected" || submission.VotesAgainst != 3 || submission.VotesFor != 0 {
+				t.Fatalf("expected the council alone to reject submission %d, got %+v", i, submission)
+			}
+		}
+
+		if n, err := verificationhandlers.ReconcileCouncilVotes(context.Background(), db); err != nil || n != 16 {
+			t.Fatalf("expected all 16 votes reconciled, got %d, %v", n, err)
+		}
+		var requalified models.ValidatorAgent
+		db.First(&requalified, "agent_id = ?", idle.ID)
+		if !requalified.IsActive || requalified.OnProbation || requalified.ReactivatedAt == nil {
+			t.Fatalf("expected the validator reactivated after three correct votes, got %+v", requalified)
+		}
+		db.Model(&models.Notification{}).Where("agent_id = ? AND kind = ?", idle.ID, "validator.reactivated").Count(&notified)
+		if notified != 1 {
+			t.Fatalf("expected a reactivation notification, got %d", notified)
+		}
+		var counted models.ValidatorAgent
+		db.First(&counted, "agent_id = ?", validators[0].ID)
+		if counted.CorrectValidations != 4 {
+			t.Fatalf("expected four correct validations credited, got %d", counted.CorrectValidations)
+		}
+	})
+}
+
+func TestValidatorScore_FollowsSubmissionAndMarketOutcome(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		approvers := []*models.Agent{h.createAgent("val1"), h.createAgent("val2"), h.createAgent("val3")}
+		doubter := h.createAgent("doubter")
+		for _, v := range append(approvers, doubter) {
+			h.makeValidator(v)
+		}
+
+		submissionID := submitMarket(h, submitter)
+		db.Model(&models.PendingSubmission{}).Where("id = ?", submissionID).Update("votes_required", 4)
+		path := fmt.Sprintf("/v0/council/vote/%d", submissionID)
+		h.do(http.MethodPost, path, doubter, map[string]string{"vote": "reject"}, nil)
+		for _, v := range approvers {
+			h.do(http.MethodPost, path, v, map[string]string{"vote": "approve"}, nil)
+		}
+
+		scores := func() (approver, doubting models.ValidatorAgent) {
+			db.First(&approver, "agent_id = ?", approvers[0].ID)
+			db.First(&doubting, "agent_id = ?", doubter.ID)
+			return approver, doubting
+		}
+
+		// The council approved the market, so the approvers were right.
+		if n, err := verificationhandlers.ReconcileCouncilVotes(context.Background(), db); err != nil || n != 4 {
+			t.Fatalf("expected 4 votes reconciled, got %d, %v", n, err)
+		}
+		approver, doubting := scores()
+		if approver.CorrectValidations != 1 || approver.ValidatorScore != models.ValidatorScoreOf(1, 1) {
+			t.Fatalf("expected the approver credited, got %+v", approver)
+		}
+		if doubting.CorrectValidations != 0 || doubting.ValidatorScore != models.ValidatorScoreOf(0, 1) {
+			t.Fatalf("expected the doubter marked down, got %+v", doubting)
+		}
+
+		// The market resolving N/A proves the doubter right after all.
+		db.Model(&models.Market{}).Where("source_submission_id = ?", submissionID).
+			Updates(map[string]interface{}{"is_resolved": true, "resolution_result": "N/A"})
+		if n, err := verificationhandlers.ReconcileCouncilVotes(context.Background(), db); err != nil || n != 4 {
+			t.Fatalf("expected 4 votes checked against the market, got %d, %v", n, err)
+		}
+		approver, doubting = scores()
+		if approver.CorrectValidations != 0 || approver.ValidatorScore != models.ValidatorScoreOf(0, 1) {
+			t.Fatalf("expected the approver marked down, got %+v", approver)
+		}
+		if doubting.CorrectValidations != 1 || doubting.ValidatorScore != models.ValidatorScoreOf(1, 1) {
+			t.Fatalf("expected the doubter credited, got %+v", doubting)
+		}
+		if n, _ := verificationhandlers.ReconcileCouncilVotes(context.Background(), db); n != 0 {
+			t.Fatalf("expected nothing left to reconcile, got %d", n)
+		}
+	})
+}
+
+func TestMarketResolution_DecidedByCouncilVote(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		ctx := context.Background()
+
+		market := h.createMarket("Will the council agree on how this resolved?")
+		stale := h.createMarket("Will anyone vote on this one?")
+		predictor := h.createAgent("predictor")
+		body := map[string]interface{}{"marketId": market.ID, "outcome": "YES", "confidence": 80}
+		if status := h.do(http.MethodPost, "/v0/predict", predictor, body, nil); status != http.StatusCreated {
+			t.Fatalf("predict: status %d", status)
+		}
+		validators := []*models.Agent{h.createAgent("val1"), h.createAgent("val2"), h.createAgent("val3")}
+		for _, v := range validators {
+			h.makeValidator(v)
+		}
+
+		db.Model(&models.Market{}).Where("id IN ?", []int64{market.ID, stale.ID}).Update("resolution_date_time", time.Now().Add(-time.Hour))
+		opened, err := verificationhandlers.OpenResolutionRequests(ctx, db, time.Now())
+		if err != nil || opened != 2 {
+			t.Fatalf("expected two resolution requests, got %d (%v)", opened, err)
+		}
+		if again, _ := verificationhandlers.OpenResolutionRequests(ctx, db, time.Now()); again != 0 {
+			t.Fatalf("expected markets to be asked about once, got %d more", again)
+		}
+		var request models.ResolutionRequest
+		db.Where("market_id = ?", market.ID).First(&request)
+		db.Model(&request).Updates(map[string]interface{}{"votes_required": 3, "approval_threshold": 75})
+		path := fmt.Sprintf("/v0/council/resolutions/%d/vote", request.ID)
+
+		if status, code := h.doError(http.MethodPost, path, validators[0], map[string]interface{}{"outcome": "YES"}, nil); status != http.StatusBadRequest || code != response.CodeValidationFailed {
+			t.Fatalf("expected a vote without evidence to be refused, got %d %s", status, code)
+		}
+
+		type tally struct {
+			VotesYes int    `json:"votesYes"`
+			VotesNo  int    `json:"votesNo"`
+			Resolved bool   `json:"resolved"`
+			Outcome  string `json:"outcome"`
+		}
+		var got tally
+		for i, outcome := range []string{"YES", "NO", "YES"} {
+			got = tally{}
+			vote := map[string]interface{}{"outcome": outcome, "evidence": []string{"https://ci.example.com/aiswarm-hub/main"}}
+			if status := h.do(http.MethodPost, path, validators[i], vote, &got); status != http.StatusOK {
+				t.Fatalf("vote by %s: status %d", validators[i].Name, status)
+			}
+		}
+		if got.Resolved {
+			t.Fatalf("expected a two to one split to stay below the threshold, got %+v", got)
+		}
+
+		// A fourth validator tips YES over the threshold.
+		fourth := h.createAgent("val4")
+		h.makeValidator(fourth)
+		got = tally{}
+		vote := map[string]interface{}{"outcome": "yes", "evidence": []string{"https://ci.example.com/aiswarm-hub/main/42"}}
+		if status := h.do(http.MethodPost, path, fourth, vote, &got); status != http.StatusOK {
+			t.Fatalf("vote by %s: status %d", fourth.Name, status)
+		}
+		if !got.Resolved || got.Outcome != "YES" || got.VotesYes != 3 {
+			t.Fatalf("expected three of four votes to resolve the market YES, got %+v", got)
+		}
+
+		var resolved models.Market
+		db.First(&resolved, market.ID)
+		if !resolved.IsResolved || resolved.ResolutionResult != "YES" {
+			t.Fatalf("expected the market resolved YES, got %v %q", resolved.IsResolved, resolved.ResolutionResult)
+		}
+		if got := h.reloadAgent(predictor); got.ResolvedPredictions != 1 || got.CorrectPredictions != 1 {
+			t.Fatalf("expected the prediction scored correct, got %d/%d", got.CorrectPredictions, got.ResolvedPredictions)
+		}
+
+		var detail struct {
+			Resolution verificationhandlers.ResolutionRequestView `json:"resolution"`
+		}
+		if status := h.do(http.MethodGet, fmt.Sprintf("/v0/council/resolutions/%d", request.ID), nil, nil, &detail); status != http.StatusOK {
+			t.Fatalf("get resolution: status %d", status)
+		}
+		if detail.Resolution.Status != models.ResolutionRequestResolved || len(detail.Resolution.Votes) != 4 || len(detail.Resolution.Votes[3].Evidence) != 1 {
+			t.Fatalf("expected the resolved request with every vote and its evidence, got %+v", detail.Resolution)
+		}
+
+		// Nobody voted on the other market, so its request expires and the
+		// market is left unresolved.
+		db.Model(&models.ResolutionRequest{}).Where("market_id = ?", stale.ID).Update("voting_ends_at", time.Now().Add(-time.Minute))
+		if _, err := verificationhandlers.ProcessExpiredResolutions(ctx, db); err != nil {
+			t.Fatalf("process expired resolutions: %v", err)
+		}
+		var expired models.ResolutionRequest
+		db.Where("market_id = ?", stale.ID).First(&expired)
+		var open models.Market
+		db.First(&open, stale.ID)
+		if expired.Status != models.ResolutionRequestExpired || open.IsResolved {
+			t.Fatalf("expected an unvoted request to expire and leave the market open, got %q resolved=%v", expired.Status, open.IsResolved)
+		}
+	})
+}
+
+func TestResolutionDispute_CouncilOverturnsOutcomeAndScores(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		ctx := context.Background()
+
+		if _, err := platformconfig.Set(db, "disputes.weightRequired", 2, "test", nil); err != nil {
+			t.Fatalf("set dispute weight: %v", err)
+		}
+		t.Cleanup(platformconfig.Invalidate)
+
+		market := h.createMarket("Will the dispute process overturn a bad resolution?")
+		first, second, late, backer := h.createAgent("first"), h.createAgent("second"), h.createAgent("late"), h.createAgent("backer")
+		for agent, outcome := range map[*models.Agent]string{first: "NO", second: "NO", late: "NO", backer: "YES"} {
+			body := map[string]interface{}{"marketId": market.ID, "outcome": outcome, "confidence": 80}
+			if status := h.do(http.MethodPost, "/v0/predict", agent, body, nil); status != http.StatusCreated {
+				t.Fatalf("predict %s by %s: status %d", outcome, agent.Name, status)
+			}
+		}
+		if _, err := resolution.Resolve(ctx, db, market.ID, resolution.OutcomeYes, nil); err != nil {
+			t.Fatalf("resolve: %v", err)
+		}
+
+		path := fmt.Sprintf("/v0/markets/%d/disputes", market.ID)
+		dispute := map[string]interface{}{"reason": "The source reported failure at the deadline", "evidence": []string{"https://ci.example.com/aiswarm-hub/main/7"}}
+		outsider := h.createAgent("outsider")
+		if status, code := h.doError(http.MethodPost, path, outsider, dispute, nil); status != http.StatusForbidden || code != response.CodeForbidden {
+			t.Fatalf("expected an agent without a prediction to be refused, got %d %s", status, code)
+		}
+
+		type filed struct {
+			Reopened  bool  `json:"reopened"`
+			RequestID int64 `json:"requestId"`
+		}
+		var got filed
+		if status := h.do(http.MethodPost, path, first, dispute, &got); status != http.StatusCreated || got.Reopened {
+			t.Fatalf("first dispute: status %d, %+v", status, got)
+		}
+		if status, code := h.doError(http.MethodPost, path, first, dispute, nil); status != http.StatusConflict || code != response.CodeAlreadyDisputed {
+			t.Fatalf("expected a second dispute by the same agent to conflict, got %d %s", status, code)
+		}
+		if status := h.do(http.MethodPost, path, second, dispute, &got); status != http.StatusCreated || !got.Reopened {
+			t.Fatalf("expected the second dispute to reopen voting, got %d %+v", status, got)
+		}
+		if status, code := h.doError(http.MethodPost, path, late, dispute, nil); status != http.StatusConflict || code != response.CodeDisputeClosed {
+			t.Fatalf("expected disputes to close once the council reviews, got %d %s", status, code)
+		}
+
+		var review models.ResolutionRequest
+		db.First(&review, got.RequestID)
+		if review.Round != 2 || review.DisputedOutcome != "YES" {
+			t.Fatalf("expected a second round reviewing YES, got %+v", review)
+		}
+		db.Model(&review).Updates(map[string]interface{}{"votes_required": 3, "approval_threshold": 75})
+
+		var tally struct {
+			Resolved bool   `json:"resolved"`
+			Outcome  string `json:"outcome"`
+		}
+		for _, name := range []string{"val1", "val2", "val3"} {
+			validator := h.createAgent(name)
+			h.makeValidator(validator)
+			vote := map[string]interface{}{"outcome": "NO", "evidence": []string{"https://ci.example.com/aiswarm-hub/main/7"}}
+			if status := h.do(http.MethodPost, fmt.Sprintf("/v0/council/resolutions/%d/vote", review.ID), validator, vote, &tally); status != http.StatusOK {
+				t.Fatalf("vote by %s: status %d", name, status)
+			}
+		}
+		if !tally.Resolved || tally.Outcome != "NO" {
+			t.Fatalf("expected the council to overturn the outcome to NO, got %+v", tally)
+		}
+
+		var overturned models.Market
+		db.First(&overturned, market.ID)
+		if overturned.ResolutionResult != "NO" {
+			t.Fatalf("expected the market re-resolved NO, got %q", overturned.ResolutionResult)
+		}
+		if got := h.reloadAgent(first); got.ResolvedPredictions != 1 || got.CorrectPredictions != 1 {
+			t.Fatalf("expected the disputer's prediction to count as correct, got %d/%d", got.CorrectPredictions, got.ResolvedPredictions)
+		}
+		if got := h.reloadAgent(backer); got.ResolvedPredictions != 1 || got.CorrectPredictions != 0 {
+			t.Fatalf("expected the backer's credit taken back, got %d/%d", got.CorrectPredictions, got.ResolvedPredictions)
+		}
+	})
+}
+
+func TestSubmissionDiscussion_ChangesRequestedThenResubmitted(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		validator := h.createAgent("validator")
+		outsider := h.createAgent("outsider")
+		h.makeValidator(validator)
+
+		submissionID := submitMarket(h, submitter)
+		comments := fmt.Sprintf("/v0/submissions/%d/comments", submissionID)
+		resubmit := fmt.Sprintf("/v0/submissions/%d/resubmit", submissionID)
+
+		if status, _ := h.doError(http.MethodGet, comments, outsider, nil, nil); status != http.StatusForbidden {
+			t.Fatalf("expected the discussion hidden from outsiders, got %d", status)
+		}
+		if status, _ := h.doError(http.MethodPost, resubmit, submitter, marketSubmission(), nil); status != http.StatusConflict {
+			t.Fatalf("expected no resubmission before changes are requested, got %d", status)
+		}
+
+		var asked struct {
+			Comment models.SubmissionComment `json:"comment"`
+		}
+		if status := h.do(http.MethodPost, fmt.Sprintf("/v0/council/request-changes/%d", submissionID), validator,
+			map[string]string{"request": "Name the CI workflow the criteria refer to"}, &asked); status != http.StatusCreated {
+			t.Fatalf("request changes: status %d", status)
+		}
+		if status, _ := h.doError(http.MethodPost, fmt.Sprintf("/v0/council/request-changes/%d", submissionID), submitter,
+			map[string]string{"request": "Approve it as it is"}, nil); status != http.StatusForbidden {
+			t.Fatalf("expected only validators to request changes, got %d", status)
+		}
+		var notified int64
+		db.Model(&models.Notification{}).Where("agent_id = ? AND kind = ?", submitter.ID, "submission.changes_requested").Count(&notified)
+		if notified != 1 {
+			t.Fatalf("expected the submitter notified of the change request, got %d notifications", notified)
+		}
+
+		if status := h.do(http.MethodPost, comments, submitter,
+			map[string]interface{}{"content": "It is the main workflow; resubmitting.", "parentId": asked.Comment.ID}, nil); status != http.StatusCreated {
+			t.Fatalf("reply: status %d", status)
+		}
+		var thread struct {
+			Comments           []models.SubmissionComment `json:"comments"`
+			ChangesRequestedAt *time.Time                 `json:"changesRequestedAt"`
+		}
+		if status := h.do(http.MethodGet, comments, validator, nil, &thread); status != http.StatusOK {
+			t.Fatalf("get discussion: status %d", status)
+		}
+		if len(thread.Comments) != 2 || thread.Comments[0].Kind != models.SubmissionCommentChangeRequest ||
+			thread.Comments[1].ParentID == nil || *thread.Comments[1].ParentID != asked.Comment.ID || thread.ChangesRequestedAt == nil {
+			t.Fatalf("unexpected discussion %+v", thread)
+		}
+
+		revised := marketSubmission()
+		revised["description"] = "Resolves YES if the main CI workflow reports green on both SQLite and Postgres."
+		if status, _ := h.doError(http.MethodPost, resubmit, validator, revised, nil); status != http.StatusForbidden {
+			t.Fatalf("expected only the submitter to resubmit, got %d", status)
+		}
+		var resubmitted struct {
+			SubmissionID int64  `json:"submissionId"`
+			RevisionOf   *int64 `json:"revisionOf"`
+		}
+		if status := h.do(http.MethodPost, resubmit, submitter, revised, &resubmitted); status != http.StatusCreated {
+			t.Fatalf("resubmit: status %d", status)
+		}
+		if resubmitted.RevisionOf == nil || *resubmitted.RevisionOf != submissionID {
+			t.Fatalf("expected the revision linked to submission %d, got %v", submissionID, resubmitted.RevisionOf)
+		}
+
+		var original models.PendingSubmission
+		if err := db.First(&original, submissionID).Error; err != nil {
+			t.Fatalf("load original: %v", err)
+		}
+		if original.FinalStatus != "superseded" || original.SupersededBy == nil || *original.SupersededBy != resubmitted.SubmissionID {
+			t.Fatalf("expected the original superseded by the revision, got %+v", original)
+		}
+		if status, _ := h.doError(http.MethodPost, fmt.Sprintf("/v0/council/vote/%d", submissionID), validator, map[string]string{"vote": "approve"}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected no votes on a superseded submission, got %d", status)
+		}
+		if status, _ := h.doError(http.MethodPost, resubmit, submitter, revised, nil); status != http.StatusConflict {
+			t.Fatalf("expected a submission to be revised once, got %d", status)
+		}
+		if status := h.do(http.MethodPost, fmt.Sprintf("/v0/council/vote/%d", resubmitted.SubmissionID), validator, map[string]string{"vote": "approve"}, nil); status != http.StatusOK {
+			t.Fatalf("vote on revision: status %d", status)
+		}
+	})
+}
+
+func TestSubmissionFeedback_RejectedWithReasonsThenRevised(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		validators := []*models.Agent{h.createAgent("val1"), h.createAgent("val2"), h.createAgent("val3")}
+		for _, v := range validators {
+			h.makeValidator(v)
+		}
+
+		submissionID := submitMarket(h, submitter)
+		path := fmt.Sprintf("/v0/council/vote/%d", submissionID)
+		revise := fmt.Sprintf("/v0/submit/market/%d/revise", submissionID)
+
+		if status, _ := h.doError(http.MethodPost, path, validators[0],
+			map[string]string{"vote": "approve", "rejectionReason": "ambiguous"}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected a rejection reason refused on an approval, got %d", status)
+		}
+		if status, _ := h.doError(http.MethodPost, path, validators[0],
+			map[string]string{"vote": "reject", "rejectionReason": "boring"}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected an unknown rejection reason refused, got %d", status)
+		}
+		if status, _ := h.doError(http.MethodPost, revise, submitter, map[string]string{"description": "Clearer"}, nil); status != http.StatusConflict {
+			t.Fatalf("expected no revision while the vote is open, got %d", status)
+		}
+		for i, reason := range []string{"ambiguous", "ambiguous", "weak_criteria"} {
+			if status := h.do(http.MethodPost, path, validators[i],
+				map[string]string{"vote": "reject", "rejectionReason": reason}, nil); status != http.StatusOK {
+				t.Fatalf("reject vote %d: status %d", i, status)
+			}
+		}
+
+		var notice models.Notification
+		if err := db.Where("agent_id = ? AND kind = ?", submitter.ID, "submission.rejected").First(&notice).Error; err != nil {
+			t.Fatalf("expected the submitter notified of the rejection: %v", err)
+		}
+		if !strings.Contains(notice.Data, `"ambiguous":2`) || !strings.Contains(notice.Data, `"weak_criteria":1`) {
+			t.Fatalf("expected the rejection reasons in the notification, got %s", notice.Data)
+		}
+
+		if status, _ := h.doError(http.MethodPost, revise, validators[0], map[string]string{"description": "Clearer"}, nil); status != http.StatusForbidden {
+			t.Fatalf("expected only the submitter to revise, got %d", status)
+		}
+		description := "Resolves YES if the main CI workflow reports green on both SQLite and Postgres."
+		var revised struct {
+			SubmissionID int64  `json:"submissionId"`
+			RevisionOf   *int64 `json:"revisionOf"`
+		}
+		if status := h.do(http.MethodPost, revise, submitter, map[string]interface{}{
+			"description":        description,
+			"resolutionCriteria": map[string]string{"sourceUrl": "https://ci.example.com/aiswarm-hub/main/ci.yml"},
+		}, &revised); status != http.StatusCreated {
+			t.Fatalf("revise: status %d", status)
+		}
+		if revised.RevisionOf == nil || *revised.RevisionOf != submissionID {
+			t.Fatalf("expected the revision linked to submission %d, got %v", submissionID, revised.RevisionOf)
+		}
+
+		var revision, original models.PendingSubmission
+		db.First(&revision, revised.SubmissionID)
+		db.First(&original, submissionID)
+		if revision.Revision != 2 || original.FinalStatus != "rejected" || original.SupersededBy == nil || *original.SupersededBy != revision.ID {
+			t.Fatalf("expected a second revision of the still rejected original, got %+v and %+v", revision, original)
+		}
+		var payload verificationhandlers.MarketPayload
+		if err := json.Unmarshal([]byte(revision.Payload), &payload); err != nil {
+			t.Fatalf("parse revision payload: %v", err)
+		}
+		if payload.Description != description || payload.QuestionTitle != marketSubmission()["questionTitle"] ||
+			payload.ResolutionCriteria == nil || payload.ResolutionCriteria.SourceURL != "https://ci.example.com/aiswarm-hub/main/ci.yml" ||
+			payload.ResolutionCriteria.TieBreaker != testCriteria.TieBreaker {
+			t.Fatalf("expected the edits applied over the original payload, got %+v", payload)
+		}
+		if status, _ := h.doError(http.MethodPost, revise, submitter, map[string]string{"description": "Again"}, nil); status != http.StatusConflict {
+			t.Fatalf("expected a submission to be revised once, got %d", status)
+		}
+
+		var offenders struct {
+			Submitters []verificationhandlers.SubmitterRecord `json:"submitters"`
+		}
+		if status := h.doAsAdmin(http.MethodGet, "/v0/admin/submissions/repeat-offenders?minRejections=1", nil, &offenders); status != http.StatusOK {
+			t.Fatalf("repeat offenders: status %d", status)
+		}
+		if len(offenders.Submitters) != 1 || offenders.Submitters[0].AgentID != submitter.ID || offenders.Submitters[0].Rejected != 1 ||
+			offenders.Submitters[0].Reasons["ambiguous"] != 2 || offenders.Submitters[0].AgentName != submitter.Name {
+			t.Fatalf("unexpected repeat offenders %+v", offenders.Submitters)
+		}
+	})
+}
+
+func TestMarketSubmission_VerificationRulesTunedAtRuntime(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		t.Cleanup(verificationrules.Invalidate)
+		t.Cleanup(platformconfig.Invalidate)
+		submitter := h.createAgent("submitter")
+
+		operator := modelstesting.GenerateUser("operator", 0)
+		if err := db.Create(&operator).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		if status := h.doAsAdmin(http.MethodPut, "/v0/admin/roles/"+operator.Username, map[string]string{"role": "operator"}, nil); status != http.StatusOK {
+			t.Fatalf("grant operator: status %d", status)
+		}
+		setRule := func(name string, rule map[string]interface{}) {
+			t.Helper()
+			if status := h.doAsUser(operator.Username, http.MethodPut, "/v0/admin/verification/rules/"+name, rule, nil); status != http.StatusOK {
+				t.Fatalf("set rule %s: status %d", name, status)
+			}
+		}
+		submit := func(title string) (int, verificationhandlers.VerificationResult) {
+			t.Helper()
+			body := marketSubmission()
+			body["questionTitle"] = title
+			var created struct {
+				Verification verificationhandlers.VerificationResult `json:"verification"`
+			}
+			status := h.do(http.MethodPost, "/v0/submit/market", submitter, body, &created)
+			if status != http.StatusCreated {
+				var failed verificationhandlers.VerificationResult
+				status, _ = h.doError(http.MethodPost, "/v0/submit/market", submitter, body, &failed)
+				return status, failed
+			}
+			return status, created.Verification
+		}
+
+		if status, _ := submit("Will aliens be confirmed by NASA before 2030?"); status != http.StatusBadRequest {
+			t.Fatalf("expected the built-in speculative rule to reject, got %d", status)
+		}
+		setRule("not_speculative", map[string]interface{}{"kind": "keyword", "severity": "off", "patterns": []string{"aliens"}})
+		setRule("named_source", map[string]interface{}{
+			"kind": "regex", "severity": "warn", "field": "any",
+			"patterns": []string{`(?i)\bNASA\b`}, "message": "Name the announcement that settles it",
+		})
+		status, result := submit("Will aliens be confirmed by NASA before 2030?")
+		if status != http.StatusCreated || !result.Passed || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "named_source") {
+			t.Fatalf("expected the submission through with one warning, got %d %+v", status, result)
+		}
+
+		setRule("question_length", map[string]interface{}{"kind": "check", "severity": "warn"})
+		if status := h.doAsAdmin(http.MethodPut, "/v0/admin/parameters/verification.minQuestionLength", map[string]float64{"value": 100}, nil); status != http.StatusOK {
+			t.Fatalf("set minQuestionLength: status %d", status)
+		}
+		status, result = submit("Will the v2 release ship before 2027?")
+		if status != http.StatusCreated || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "minimum 100 characters") {
+			t.Fatalf("expected the raised minimum to warn, got %d %+v", status, result)
+		}
+
+		setRule("no_lotteries", map[string]interface{}{"kind": "keyword", "severity": "reject", "patterns": []string{"Lottery"}})
+		status, result = submit("Who will win the state lottery this week?")
+		if status != http.StatusBadRequest || result.Passed || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], `no_lotteries: Matches a keyword rule (matched "lottery")`) {
+			t.Fatalf("expected the keyword rule to reject, got %d %+v", status, result)
+		}
+
+		if status, _ := h.doError(http.MethodPut, "/v0/admin/verification/rules/question_length", nil,
+			map[string]interface{}{"kind": "regex", "severity": "warn", "patterns": []string{"x"}}, nil); status != http.StatusUnauthorized {
+			t.Fatalf("expected the rules closed to agents, got %d", status)
+		}
+		if status := h.doAsUser(operator.Username, http.MethodPut, "/v0/admin/verification/rules/bad_regex",
+			map[string]interface{}{"kind": "regex", "severity": "warn", "patterns": []string{"(unclosed"}}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected an invalid regex refused, got %d", status)
+		}
+		if status := h.doAsUser(operator.Username, http.MethodDelete, "/v0/admin/verification/rules/not_speculative", nil, nil); status != http.StatusOK {
+			t.Fatalf("reset rule: status %d", status)
+		}
+		var listed struct {
+			Rules []struct {
+				Name     string `json:"name"`
+				Kind     string `json:"kind"`
+				Severity string `json:"severity"`
+			} `json:"rules"`
+		}
+		if status := h.doAsUser(operator.Username, http.MethodGet, "/v0/admin/verification/rules", nil, &listed); status != http.StatusOK {
+			t.Fatalf("list rules: status %d", status)
+		}
+		severities := map[string]string{}
+		for _, rule := range listed.Rules {
+			severities[rule.Name] = rule.Severity
+		}
+		if severities["question_length"] != "warn" || severities["not_speculative"] != "reject" || severities["no_lotteries"] != "reject" || severities["market_input"] != "reject" {
+			t.Fatalf("unexpected rules %+v", listed.Rules)
+		}
+	})
+}
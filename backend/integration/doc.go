@@ -0,0 +1,4 @@
+// Package integration holds API-level tests that drive the real router
+// against every supported database dialect. See harness_test.go for the
+// helpers and modelstesting.ForEachDialect for how dialects are selected.
+package integration
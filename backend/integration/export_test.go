@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+func TestExport_FormatsAndCursor(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		agent := h.createAgent("researcher")
+		for _, title := range []string{"First export market", "Second export market", "Third export market"} {
+			market := h.createMarket(title)
+			body := models.PredictionRequest{MarketID: market.ID, Outcome: "NO", Confidence: 65}
+			if status := h.do(http.MethodPost, "/v0/predict", agent, body, nil); status != http.StatusCreated {
+				t.Fatalf("predict: status %d", status)
+			}
+		}
+
+		rec := h.record(http.MethodGet, "/v0/export/markets?limit=2", http.Header{"Accept": {"text/csv"}}, nil)
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+			t.Fatalf("expected CSV, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		if len(lines) != 3 || !strings.HasPrefix(lines[0], "id,questionTitle,") {
+			t.Fatalf("expected a header and 2 markets, got %q", rec.Body.String())
+		}
+		cursor := rec.Result().Trailer.Get("X-Next-Cursor")
+		if cursor == "" {
+			t.Fatalf("expected the next cursor after a full page")
+		}
+
+		rec = h.record(http.MethodGet, "/v0/export/markets?format=ndjson&cursor="+cursor, nil, nil)
+		if lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n"); rec.Code != http.StatusOK || len(lines) != 1 || !strings.Contains(lines[0], "Third export market") {
+			t.Fatalf("expected the last market on the next page, got %d %q", rec.Code, rec.Body.String())
+		}
+		if rec.Result().Trailer.Get("X-Next-Cursor") != "" {
+			t.Fatalf("expected no next cursor after the last page")
+		}
+
+		rec = h.record(http.MethodGet, "/v0/export/predictions?since=2000-01-01", nil, nil)
+		if lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n"); rec.Code != http.StatusOK || len(lines) != 3 {
+			t.Fatalf("expected 3 predictions as NDJSON, got %d %q", rec.Code, rec.Body.String())
+		}
+
+		if status, code := h.doError(http.MethodGet, "/v0/export/resolutions?format=xml", nil, nil, nil); status != http.StatusBadRequest || code != response.CodeBadRequest {
+			t.Fatalf("expected an unknown format to be refused, got %d %s", status, code)
+		}
+	})
+}
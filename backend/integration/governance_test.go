@@ -0,0 +1,230 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+func TestGovernanceDelegation_DelegateVotesWithDelegatedPower(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		proposer := h.createAgent("proposer")
+		trusted := h.createAgent("trusted")
+		small := h.createAgent("small")
+		smaller := h.createAgent("smaller")
+		for agent, score := range map[*models.Agent]float64{proposer: 0, trusted: 50, small: 20, smaller: 0} {
+			if err := db.Model(agent).Update("composite_score", score).Error; err != nil {
+				t.Fatalf("set composite score: %v", err)
+			}
+		}
+
+		// smaller -> small -> trusted
+		for delegator, delegate := range map[*models.Agent]*models.Agent{small: trusted, smaller: small} {
+			body := map[string]interface{}{"delegateId": delegate.ID}
+			if status := h.do(http.MethodPut, "/v0/governance/delegation", delegator, body, nil); status != http.StatusOK {
+				t.Fatalf("delegate %s to %s: status %d", delegator.Name, delegate.Name, status)
+			}
+		}
+		if status, code := h.doError(http.MethodPut, "/v0/governance/delegation", trusted, map[string]interface{}{"delegateId": smaller.ID}, nil); status != http.StatusConflict || code != response.CodeConflict {
+			t.Fatalf("expected a delegation cycle to be refused, got %d %s", status, code)
+		}
+		if status, _ := h.doError(http.MethodPut, "/v0/governance/delegation", trusted, map[string]interface{}{"delegateId": trusted.ID}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected self-delegation to be refused, got %d", status)
+		}
+
+		var profile struct {
+			Stats models.AgentStats `json:"stats"`
+		}
+		if status := h.do(http.MethodGet, fmt.Sprintf("/v0/agent/%d/stats", trusted.ID), nil, nil, &profile); status != http.StatusOK {
+			t.Fatalf("get stats: status %d", status)
+		}
+		if power := profile.Stats.VotingPower; power.Delegators != 2 || math.Abs(power.Total-3.7) > 1e-9 {
+			t.Fatalf("expected 3.7 voting power from 2 delegators, got %+v", power)
+		}
+
+		var created struct {
+			Proposal models.ProposalPublic `json:"proposal"`
+		}
+		body := map[string]interface{}{"title": "Delegate votes", "description": "Let delegates vote for others", "type": "governance"}
+		if status := h.do(http.MethodPost, "/v0/governance/proposals", proposer, body, &created); status != http.StatusCreated {
+			t.Fatalf("create proposal: status %d", status)
+		}
+		path := fmt.Sprintf("/v0/governance/proposals/%d/vote", created.Proposal.ID)
+
+		// A delegator who votes keeps their own power
+		if status := h.do(http.MethodPost, path, smaller, map[string]interface{}{"vote": "no"}, nil); status != http.StatusOK {
+			t.Fatalf("vote as smaller: status %d", status)
+		}
+		var voted struct {
+			Proposal       models.ProposalPublic `json:"proposal"`
+			DelegatedVotes int                   `json:"delegatedVotes"`
+		}
+		if status := h.do(http.MethodPost, path, trusted, map[string]interface{}{"vote": "yes"}, &voted); status != http.StatusOK {
+			t.Fatalf("vote as trusted: status %d", status)
+		}
+		if voted.DelegatedVotes != 1 {
+			t.Fatalf("expected one delegated vote, got %d", voted.DelegatedVotes)
+		}
+		if voted.Proposal.VotesFor != 3 || voted.Proposal.VotesAgainst != 1 || math.Abs(voted.Proposal.WeightFor-3.7) > 1e-9 {
+			t.Fatalf("expected 3 votes for weighing 3.7 and 1 against, got %+v", voted.Proposal)
+		}
+
+		if status, code := h.doError(http.MethodPost, path, small, map[string]interface{}{"vote": "no"}, nil); status != http.StatusConflict || code != response.CodeAlreadyVoted {
+			t.Fatalf("expected the delegated vote to count as small's vote, got %d %s", status, code)
+		}
+	})
+}
+
+func TestProposalLifecycle_EditAmendAndWithdraw(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		proposer := h.createAgent("proposer")
+		voters := []*models.Agent{h.createAgent("first"), h.createAgent("second")}
+
+		var created struct {
+			Proposal models.ProposalPublic `json:"proposal"`
+		}
+		body := map[string]interface{}{"title": "Draft title", "description": "First draft", "type": "feature"}
+		if status := h.do(http.MethodPost, "/v0/governance/proposals", proposer, body, &created); status != http.StatusCreated {
+			t.Fatalf("create proposal: status %d", status)
+		}
+		path := fmt.Sprintf("/v0/governance/proposals/%d", created.Proposal.ID)
+
+		edit := map[string]interface{}{"title": "Better title", "description": "Second draft", "note": "Clarified the title"}
+		if status := h.do(http.MethodPut, path, proposer, edit, nil); status != http.StatusOK {
+			t.Fatalf("edit proposal: status %d", status)
+		}
+		if status, _ := h.doError(http.MethodPut, path, voters[0], edit, nil); status != http.StatusForbidden {
+			t.Fatalf("expected only the proposer to edit, got %d", status)
+		}
+
+		for _, voter := range voters {
+			if status := h.do(http.MethodPost, path+"/vote", voter, map[string]interface{}{"vote": "yes"}, nil); status != http.StatusOK {
+				t.Fatalf("vote as %s: status %d", voter.Name, status)
+			}
+		}
+		if status, code := h.doError(http.MethodPut, path, proposer, edit, nil); status != http.StatusConflict || code != response.CodeProposalLocked {
+			t.Fatalf("expected edits to lock once voting is under way, got %d %s", status, code)
+		}
+
+		var amended struct {
+			Proposal models.ProposalPublic `json:"proposal"`
+		}
+		amendment := map[string]interface{}{"title": "Better title", "description": "Third draft", "note": "Narrowed the scope", "votingDays": 10}
+		if status := h.do(http.MethodPost, path+"/amendments", proposer, amendment, &amended); status != http.StatusCreated {
+			t.Fatalf("amend proposal: status %d", status)
+		}
+		if amended.Proposal.Revision != 3 || !amended.Proposal.VotingEndsAt.After(created.Proposal.VotingEndsAt) || amended.Proposal.VotesFor != 3 {
+			t.Fatalf("expected revision 3 with its votes and a later voting deadline, got %+v", amended.Proposal)
+		}
+
+		var changelog struct {
+			Revisions []models.ProposalRevision `json:"revisions"`
+		}
+		if status := h.do(http.MethodGet, path+"/revisions", nil, nil, &changelog); status != http.StatusOK {
+			t.Fatalf("get revisions: status %d", status)
+		}
+		kinds := []string{models.ProposalRevisionCreated, models.ProposalRevisionEdit, models.ProposalRevisionAmendment}
+		if len(changelog.Revisions) != len(kinds) {
+			t.Fatalf("expected %d revisions, got %+v", len(kinds), changelog.Revisions)
+		}
+		for i, revision := range changelog.Revisions {
+			if revision.Revision != i+1 || revision.Kind != kinds[i] {
+				t.Fatalf("revision %d: expected %s, got %+v", i+1, kinds[i], revision)
+			}
+		}
+		if changelog.Revisions[0].Title != "Draft title" || changelog.Revisions[2].Note != "Narrowed the scope" {
+			t.Fatalf("expected the changelog to keep each version, got %+v", changelog.Revisions)
+		}
+
+		var withdrawn struct {
+			Proposal models.ProposalPublic `json:"proposal"`
+		}
+		if status := h.do(http.MethodPost, path+"/withdraw", proposer, nil, &withdrawn); status != http.StatusOK {
+			t.Fatalf("withdraw proposal: status %d", status)
+		}
+		if withdrawn.Proposal.Status != models.ProposalStatusWithdrawn {
+			t.Fatalf("expected the proposal to be withdrawn, got %s", withdrawn.Proposal.Status)
+		}
+		voter := h.createAgent("late")
+		if status, code := h.doError(http.MethodPost, path+"/vote", voter, map[string]interface{}{"vote": "no"}, nil); status != http.StatusBadRequest || code != response.CodeVotingClosed {
+			t.Fatalf("expected voting to stop once withdrawn, got %d %s", status, code)
+		}
+	})
+}
+
+func TestProposalComments_MentionAndReplyNotify(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		proposer := h.createAgent("proposer")
+		critic := h.createAgent("critic")
+		analyst := h.createAgent("analyst")
+		kinds := func(agent *models.Agent) []string {
+			t.Helper()
+			var got []string
+			db.Model(&models.Notification{}).Where("agent_id = ?", agent.ID).Order("id").Pluck("kind", &got)
+			return got
+		}
+
+		var created struct {
+			Proposal models.ProposalPublic `json:"proposal"`
+		}
+		body := map[string]interface{}{"title": "Threaded discussion", "description": "Let agents discuss proposals", "type": "feature"}
+		if status := h.do(http.MethodPost, "/v0/governance/proposals", proposer, body, &created); status != http.StatusCreated {
+			t.Fatalf("create proposal: status %d", status)
+		}
+		path := fmt.Sprintf("/v0/governance/proposals/%d/comments", created.Proposal.ID)
+
+		if status, code := h.doError(http.MethodPost, path, critic, map[string]interface{}{"content": "@nobody should see this"}, nil); status != http.StatusBadRequest || code != response.CodeValidationFailed {
+			t.Fatalf("expected an unknown mention to be refused, got %d %s", status, code)
+		}
+
+		var comment struct {
+			Comment models.ProposalComment `json:"comment"`
+		}
+		if status := h.do(http.MethodPost, path, critic, map[string]interface{}{"content": "@analyst what do you make of this?"}, &comment); status != http.StatusCreated {
+			t.Fatalf("comment: status %d", status)
+		}
+		if got := kinds(analyst); len(got) != 1 || got[0] != "comment.mention" {
+			t.Fatalf("expected analyst mentioned, got %v", got)
+		}
+		if got := kinds(proposer); len(got) != 1 || got[0] != "comment.reply" {
+			t.Fatalf("expected the proposer told of the comment, got %v", got)
+		}
+
+		// A threaded reply goes to the parent comment's author, not the
+		// proposer.
+		reply := map[string]interface{}{"content": "Looks sound to me.", "parentId": comment.Comment.ID}
+		if status := h.do(http.MethodPost, path, analyst, reply, nil); status != http.StatusCreated {
+			t.Fatalf("reply: status %d", status)
+		}
+		if got := kinds(critic); len(got) != 1 || got[0] != "comment.reply" {
+			t.Fatalf("expected critic told of the reply, got %v", got)
+		}
+		if got := kinds(proposer); len(got) != 1 {
+			t.Fatalf("expected no second notice for the proposer, got %v", got)
+		}
+
+		missing := map[string]interface{}{"content": "Replying to nothing", "parentId": comment.Comment.ID + 100}
+		if status, _ := h.doError(http.MethodPost, path, analyst, missing, nil); status != http.StatusNotFound {
+			t.Fatalf("expected a missing parent comment to be refused, got %d", status)
+		}
+
+		var mentions int64
+		db.Model(&models.Mention{}).Where("comment_type = ? AND comment_id = ?", models.MentionProposalComment, comment.Comment.ID).Count(&mentions)
+		if mentions != 1 {
+			t.Fatalf("expected one mention recorded, got %d", mentions)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+	"socialpredict/response"
+	"socialpredict/security"
+	"socialpredict/server"
+	"socialpredict/util"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+)
+
+// harness wires the production router to a test database.
+type harness struct {
+	t          *testing.T
+	db         *gorm.DB
+	router     http.Handler
+	adminToken string
+}
+
+func newHarness(t *testing.T, db *gorm.DB) *harness {
+	t.Helper()
+
+	// Several legacy handlers still read the package-level connection.
+	origDB := util.DB
+	util.DB = db
+	t.Cleanup(func() { util.DB = origDB })
+
+	// Agent markets are attached to the admin user until agents are actors.
+	admin := modelstesting.GenerateUser("admin", 0)
+	admin.UserType = "ADMIN"
+	if err := db.Create(&admin).Error; err != nil {
+		t.Fatalf("seed admin user: %v", err)
+	}
+
+	t.Setenv("JWT_SIGNING_KEY", "integration-test-key")
+	adminToken := signToken(t, admin.Username)
+
+	securityService := security.NewCustomSecurityService(security.RateLimitConfig{
+		LoginRate:       rate.Inf,
+		LoginBurst:      1000,
+		GeneralRate:     rate.Inf,
+		GeneralBurst:    1000,
+		CleanupInterval: time.Minute,
+
+		AnonymousReadRate:  rate.Inf,
+		AnonymousReadBurst: 1000,
+		ReadKeyRate:        rate.Inf,
+		ReadKeyBurst:       1000,
+
+		ExportRate:  rate.Inf,
+		ExportBurst: 1000,
+	})
+
+	return &harness{t: t, db: db, router: server.NewRouter(db, db, securityService, outbox.NewBus(nil)), adminToken: adminToken}
+}
+
+// createAgent inserts a claimed, active agent and returns it with its API key.
+func (h *harness) createAgent(name string) *models.Agent {
+	h.t.Helper()
+	agent := &models.Agent{
+		Name:             name,
+		APIKey:           "swarm_sk_" + name,
+		ClaimToken:       "swarm_claim_" + name,
+		IsClaimed:        true,
+		IsActive:         true,
+		TotalPredictions: 10,
+	}
+	if err := h.db.Create(agent).Error; err != nil {
+		h.t.Fatalf("create agent %s: %v", name, err)
+	}
+	return agent
+}
+
+// makeValidator registers agent as an active council validator.
+func (h *harness) makeValidator(agent *models.Agent) {
+	h.t.Helper()
+	validator := models.ValidatorAgent{AgentID: agent.ID, IsActive: true, ValidatorScore: 50}
+	if err := h.db.Create(&validator).Error; err != nil {
+		h.t.Fatalf("create validator %d: %v", agent.ID, err)
+	}
+}
+
+// do sends an API request as agent (nil for anonymous) and decodes the JSON
+// response into out when out is non-nil.
+func (h *harness) do(method, path string, agent *models.Agent, body interface{}, out interface{}) int {
+	h.t.Helper()
+	header := http.Header{}
+	if agent != nil {
+		header.Set("X-Agent-API-Key", agent.APIKey)
+	}
+	return h.send(method, path, header, body, out)
+}
+
+// doAsAdmin sends an API request as the seeded admin user.
+func (h *harness) doAsAdmin(method, path string, body interface{}, out interface{}) int {
+	h.t.Helper()
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+h.adminToken)
+	return h.send(method, path, header, body, out)
+}
+
+// doAsUser sends an API request with a token for the user called username.
+func (h *harness) doAsUser(username, method, path string, body interface{}, out interface{}) int {
+	h.t.Helper()
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+signToken(h.t, username))
+	return h.send(method, path, header, body, out)
+}
+
+// signToken returns a session token for the user called username.
+func signToken(t *testing.T, username string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.UserClaims{
+		Username:       username,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}).SignedString([]byte("integration-test-key"))
+	if err != nil {
+		t.Fatalf("sign token for %s: %v", username, err)
+	}
+	return token
+}
+
+// doError sends an API request as agent that is expected to fail and
+// returns the status and error code, decoding the envelope's details into
+// details when it is non-nil.
+func (h *harness) doError(method, path string, agent *models.Agent, body interface{}, details interface{}) (int, response.Code) {
+	h.t.Helper()
+	header := http.Header{}
+	if agent != nil {
+		header.Set("X-Agent-API-Key", agent.APIKey)
+	}
+	rec := h.record(method, path, header, body)
+
+	var envelope struct {
+		Error struct {
+			Code    response.Code   `json:"code"`
+			Details json.RawMessage `json:"details"`
+		} `json:"error"`
+	}
+	if rec.Code < 300 {
+		return rec.Code, ""
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		h.t.Fatalf("%s %s: decode error envelope %q: %v", method, path, rec.Body.String(), err)
+	}
+	if details != nil && len(envelope.Error.Details) > 0 {
+		if err := json.Unmarshal(envelope.Error.Details, details); err != nil {
+			h.t.Fatalf("%s %s: decode error details %q: %v", method, path, envelope.Error.Details, err)
+		}
+	}
+	return rec.Code, envelope.Error.Code
+}
+
+func (h *harness) send(method, path string, header http.Header, body interface{}, out interface{}) int {
+	h.t.Helper()
+	rec := h.record(method, path, header, body)
+
+	if out != nil && rec.Code < 300 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			h.t.Fatalf("%s %s: decode response %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+// record sends an API request and returns the recorded response.
+func (h *harness) record(method, path string, header http.Header, body interface{}) *httptest.ResponseRecorder {
+	h.t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			h.t.Fatalf("marshal request body: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	h.router.ServeHTTP(rec, req)
+
+	if rec.Code >= 300 {
+		h.t.Logf("%s %s -> %d: %s", method, path, rec.Code, rec.Body.String())
+	}
+	return rec
+}
+
+func (h *harness) reloadAgent(agent *models.Agent) *models.Agent {
+	h.t.Helper()
+	var fresh models.Agent
+	if err := h.db.First(&fresh, agent.ID).Error; err != nil {
+		h.t.Fatalf("reload agent %d: %v", agent.ID, err)
+	}
+	return &fresh
+}
+
+func (h *harness) createMarket(title string) *models.Market {
+	h.t.Helper()
+	market := &models.Market{
+		QuestionTitle:      title,
+		Description:        fmt.Sprintf("Resolution criteria for %q", title),
+		OutcomeType:        "BINARY",
+		ResolutionDateTime: time.Now().Add(72 * time.Hour),
+		InitialProbability: 0.5,
+		CreatorUsername:    "admin",
+	}
+	if err := h.db.Create(market).Error; err != nil {
+		h.t.Fatalf("create market: %v", err)
+	}
+	return market
+}
@@ -0,0 +1,186 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"socialpredict/audit"
+	marketshandlers "socialpredict/handlers/markets"
+	verificationhandlers "socialpredict/handlers/verification"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/response"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+type moderationQueue struct {
+	Items []struct {
+		models.ModerationItem
+		Preview string                 `json:"preview"`
+		Reports []models.ContentReport `json:"reports"`
+	} `json:"items"`
+}
+
+func TestContentReports_HideAtThresholdUntilReviewed(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		author := h.createAgent("spammer")
+		market := h.createMarket("Will the spam be caught?")
+		prediction := models.Prediction{AgentID: author.ID, MarketID: market.ID, Outcome: "YES", Confidence: 0.6}
+		if err := db.Create(&prediction).Error; err != nil {
+			t.Fatalf("create prediction: %v", err)
+		}
+		comment := models.PredictionComment{PredictionID: prediction.ID, AuthorName: author.Name, Content: "buy my course"}
+		comment.SetAuthor(models.AgentActor(author.ID))
+		if err := db.Create(&comment).Error; err != nil {
+			t.Fatalf("create comment: %v", err)
+		}
+		commentsPath := "/v0/prediction/" + strconv.FormatInt(prediction.ID, 10) + "/comments"
+		visibleComments := func() int64 {
+			var listed struct {
+				Total int64 `json:"total"`
+			}
+			if status := h.do("GET", commentsPath, nil, nil, &listed); status != http.StatusOK {
+				t.Fatalf("list comments: status %d", status)
+			}
+			return listed.Total
+		}
+
+		// Reports weigh 1 plus the reporter's accuracy, so the comment is
+		// hidden by the report that takes the weight to the threshold.
+		hideWeight := setup.EconomicsConfig().Moderation.OrDefaults().HideWeight
+		report := map[string]interface{}{"contentType": "comment", "contentId": comment.ID, "reason": "spam"}
+		var reporters []*models.Agent
+		var weight float64
+		for i := 0; weight < hideWeight; i++ {
+			reporter := h.reloadAgent(h.createAgent(fmt.Sprintf("reporter%d", i)))
+			reporters = append(reporters, reporter)
+			weight += models.ReportWeight(reporter)
+			var reported struct {
+				Hidden bool `json:"hidden"`
+			}
+			if status := h.do("POST", "/v0/report", reporter, report, &reported); status != http.StatusCreated {
+				t.Fatalf("report %d: status %d", i, status)
+			}
+			if reached := weight >= hideWeight; reported.Hidden != reached {
+				t.Fatalf("after report %d (weight %g): hidden = %v, want %v", i, weight, reported.Hidden, reached)
+			}
+		}
+		if status, code := h.doError("POST", "/v0/report", reporters[0], report, nil); status != http.StatusConflict || code != response.CodeAlreadyReported {
+			t.Errorf("report twice: status %d code %s, want 409 %s", status, code, response.CodeAlreadyReported)
+		}
+		if total := visibleComments(); total != 0 {
+			t.Errorf("hidden comment still listed: total %d", total)
+		}
+
+		// Validators see the queue only with a high enough score.
+		validator := reporters[0]
+		h.makeValidator(validator)
+		if status := h.do("GET", "/v0/moderation/queue", validator, nil, nil); status != http.StatusForbidden {
+			t.Errorf("queue as low-score validator: status %d, want 403", status)
+		}
+		db.Model(&models.ValidatorAgent{}).Where("agent_id = ?", validator.ID).Update("validator_score", 90)
+		var queue moderationQueue
+		if status := h.do("GET", "/v0/moderation/queue", validator, nil, &queue); status != http.StatusOK {
+			t.Fatalf("queue as validator: status %d", status)
+		}
+		if len(queue.Items) != 1 || queue.Items[0].Status != models.ModerationHidden || queue.Items[0].Preview != comment.Content || len(queue.Items[0].Reports) != len(reporters) {
+			t.Fatalf("queue = %+v", queue.Items)
+		}
+
+		// Keeping it shows it again and takes it out of the queue.
+		itemPath := "/v0/admin/moderation/" + strconv.FormatInt(queue.Items[0].ID, 10) + "/review"
+		if status := h.doAsAdmin("POST", itemPath, map[string]string{"decision": "keep", "note": "not spam"}, nil); status != http.StatusOK {
+			t.Fatalf("review: status %d", status)
+		}
+		if total := visibleComments(); total != 1 {
+			t.Errorf("kept comment: total %d, want 1", total)
+		}
+		queue = moderationQueue{}
+		h.doAsAdmin("GET", "/v0/admin/moderation/queue", nil, &queue)
+		if len(queue.Items) != 0 {
+			t.Errorf("queue after review = %+v", queue.Items)
+		}
+		var logged auditEntries
+		h.doAsAdmin("GET", "/v0/admin/audit?action="+audit.ActionContentReviewed, nil, &logged)
+		if len(logged.Entries) != 1 || logged.Entries[0].Target != "comment:"+strconv.FormatInt(comment.ID, 10) {
+			t.Errorf("review audit entries = %+v", logged.Entries)
+		}
+
+		// A removed market drops out of market listings.
+		h.do("POST", "/v0/report", reporters[1], map[string]interface{}{"contentType": "market", "contentId": market.ID, "reason": "manipulation"}, nil)
+		var item models.ModerationItem
+		db.Where("content_type = ? AND content_id = ?", models.ContentMarket, market.ID).First(&item)
+		if status := h.doAsAdmin("POST", "/v0/admin/moderation/"+strconv.FormatInt(item.ID, 10)+"/review", map[string]string{"decision": "remove"}, nil); status != http.StatusOK {
+			t.Fatalf("remove market: status %d", status)
+		}
+		markets, err := marketshandlers.ListMarkets(db, marketshandlers.MarketQuery{Sort: "newest"})
+		if err != nil {
+			t.Fatalf("list markets: %v", err)
+		}
+		for _, listed := range markets {
+			if listed.ID == market.ID {
+				t.Errorf("removed market is still listed")
+			}
+		}
+	})
+}
+
+func TestContentSafety_BlocksAndFlagsAgentText(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		agent := h.createAgent("writer")
+		market := h.createMarket("Will the content scan keep the feed clean?")
+
+		// At submission, blocked content fails verification and flagged
+		// content goes to the council with a warning.
+		submission := marketSubmission()
+		submission["description"] = "Resolves YES per the CI run; questions to jane.doe@example.com"
+		var failed verificationhandlers.VerificationResult
+		if status, _ := h.doError(http.MethodPost, "/v0/submit/market", agent, submission, &failed); status != http.StatusBadRequest ||
+			len(failed.Errors) != 1 || !strings.Contains(failed.Errors[0], "content_safety: Prohibited content: personal_data (email_address in description)") {
+			t.Fatalf("expected personal data to fail verification, got %d %+v", status, failed)
+		}
+		submission["description"] = "Resolves YES if CI reports green on both SQLite and Postgres, whoever doxxed the maintainer."
+		var submitted struct {
+			Verification verificationhandlers.VerificationResult `json:"verification"`
+		}
+		if status := h.do(http.MethodPost, "/v0/submit/market", agent, submission, &submitted); status != http.StatusCreated ||
+			len(submitted.Verification.Warnings) != 1 || !strings.Contains(submitted.Verification.Warnings[0], "content_safety: Flagged for review: harassment") {
+			t.Fatalf("expected harassment to reach the council with a warning, got %d %+v", status, submitted.Verification)
+		}
+
+		// Made directly, blocked content is refused and flagged content is
+		// recorded and queued for moderators.
+		predict := map[string]interface{}{"marketId": market.ID, "outcome": "YES", "reasoning": "Whoever loses, someone should kill the referee"}
+		if status, code := h.doError(http.MethodPost, "/v0/predict", agent, predict, nil); status != http.StatusBadRequest || code != response.CodeContentBlocked {
+			t.Fatalf("blocked reasoning: status %d code %s", status, code)
+		}
+		predict["reasoning"] = "Their maintainer got doxxed last week, so the release slips"
+		var predicted struct {
+			Prediction models.Prediction `json:"prediction"`
+		}
+		if status := h.do(http.MethodPost, "/v0/predict", agent, predict, &predicted); status != http.StatusCreated {
+			t.Fatalf("flagged reasoning: status %d", status)
+		}
+		create := map[string]interface{}{"questionTitle": "Who is at 555-867-5309 tonight?", "resolutionDateTime": submission["resolutionDateTime"]}
+		if status, code := h.doError(http.MethodPost, "/v0/agents/create", agent, create, nil); status != http.StatusBadRequest || code != response.CodeContentBlocked {
+			t.Fatalf("blocked market: status %d code %s", status, code)
+		}
+
+		h.createMarket("Will anyone report this one?")
+		var queue moderationQueue
+		if status := h.doAsAdmin(http.MethodGet, "/v0/admin/moderation/queue?flagged=true", nil, &queue); status != http.StatusOK {
+			t.Fatalf("queue: status %d", status)
+		}
+		if len(queue.Items) != 1 || queue.Items[0].ContentType != models.ContentPrediction || queue.Items[0].ContentID != predicted.Prediction.ID ||
+			queue.Items[0].SafetyFlags != "harassment" || queue.Items[0].FlaggedAt == nil || queue.Items[0].Preview == "" {
+			t.Fatalf("flagged queue = %+v", queue.Items)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/repository"
+	"socialpredict/response"
+	"socialpredict/services/agentdata"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+func TestOwnerDeactivation_ExportsThenRetiresKeys(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		owner := modelstesting.GenerateUser("owner", 0)
+		stranger := modelstesting.GenerateUser("stranger", 0)
+		for _, u := range []*models.User{&owner, &stranger} {
+			if err := db.Create(u).Error; err != nil {
+				t.Fatalf("create user: %v", err)
+			}
+		}
+		agent := h.createAgent("retiring")
+		if err := db.Model(agent).Update("owner_user_id", owner.ID).Error; err != nil {
+			t.Fatalf("set owner: %v", err)
+		}
+		market := h.createMarket("Will the owner export before deactivating?")
+		body := map[string]interface{}{"marketId": market.ID, "outcome": "YES", "confidence": 70, "reasoning": "Owners like backups"}
+		if status := h.do(http.MethodPost, "/v0/predict", agent, body, nil); status != http.StatusCreated {
+			t.Fatalf("predict: status %d", status)
+		}
+
+		exportPath := fmt.Sprintf("/v0/user/agents/%d/export", agent.ID)
+		if status := h.doAsUser("stranger", http.MethodGet, exportPath, nil, nil); status != http.StatusNotFound {
+			t.Fatalf("expected another user's export to be not found, got %d", status)
+		}
+
+		var archive struct {
+			Agent       models.AgentPublic  `json:"agent"`
+			Predictions []models.Prediction `json:"predictions"`
+			Markets     []json.RawMessage   `json:"markets"`
+		}
+		if status := h.doAsUser("owner", http.MethodGet, exportPath, nil, &archive); status != http.StatusOK {
+			t.Fatalf("export: status %d", status)
+		}
+		if archive.Agent.ID != agent.ID || len(archive.Predictions) != 1 || archive.Predictions[0].Reasoning != "Owners like backups" || archive.Markets == nil {
+			t.Fatalf("expected the agent and its prediction in the archive, got %+v", archive)
+		}
+
+		header := http.Header{}
+		header.Set("Authorization", "Bearer "+signToken(t, "owner"))
+		rec := h.record(http.MethodGet, exportPath+"?format=ndjson", header, nil)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("ndjson export: status %d, %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		var types []string
+		for lines := bufio.NewScanner(strings.NewReader(rec.Body.String())); lines.Scan(); {
+			var line struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(lines.Bytes(), &line); err != nil {
+				t.Fatalf("decode line %q: %v", lines.Text(), err)
+			}
+			types = append(types, line.Type)
+		}
+		if strings.Join(types, ",") != "export,agent,stats,prediction,prediction_revision" {
+			t.Fatalf("unexpected ndjson records %v", types)
+		}
+
+		deletePath := fmt.Sprintf("/v0/user/agents/%d", agent.ID)
+		if status := h.doAsUser("stranger", http.MethodDelete, deletePath, nil, nil); status != http.StatusNotFound {
+			t.Fatalf("expected another user's deactivation to be not found, got %d", status)
+		}
+		if status := h.doAsUser("owner", http.MethodDelete, deletePath, nil, nil); status != http.StatusOK {
+			t.Fatalf("deactivate: status %d", status)
+		}
+		if status, code := h.doError(http.MethodGet, "/v0/agents/status", agent, nil, nil); status != http.StatusUnauthorized || code != response.CodeInvalidAPIKey {
+			t.Fatalf("expected the retired key refused, got %d %s", status, code)
+		}
+		if h.reloadAgent(agent).IsActive {
+			t.Fatalf("expected the agent inactive")
+		}
+
+		var board models.LeaderboardResponse
+		if status := h.do(http.MethodGet, "/v0/leaderboard", nil, nil, &board); status != http.StatusOK {
+			t.Fatalf("leaderboard: status %d", status)
+		}
+		for _, entry := range board.Leaderboard {
+			if entry.AgentID == agent.ID {
+				t.Fatalf("expected the deactivated agent off the leaderboard")
+			}
+		}
+
+		if status := h.doAsUser("owner", http.MethodDelete, deletePath, nil, nil); status != http.StatusConflict {
+			t.Fatalf("expected deactivating twice to conflict, got %d", status)
+		}
+		if status := h.doAsUser("owner", http.MethodGet, exportPath, nil, &archive); status != http.StatusOK {
+			t.Fatalf("expected a deactivated agent still exportable, got %d", status)
+		}
+	})
+}
+
+func TestOwnerErasure_AnonymizesAfterGracePeriod(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		owner := modelstesting.GenerateUser("owner", 0)
+		if err := db.Create(&owner).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		agent := h.createAgent("forgettable")
+		if err := db.Model(agent).Updates(map[string]interface{}{"owner_user_id": owner.ID, "description": "Runs on my laptop"}).Error; err != nil {
+			t.Fatalf("set owner: %v", err)
+		}
+		market := h.createMarket("Will the agent be forgotten?")
+		body := map[string]interface{}{"marketId": market.ID, "outcome": "YES", "confidence": 80, "reasoning": "My owner told me so"}
+		if status := h.do(http.MethodPost, "/v0/predict", agent, body, nil); status != http.StatusCreated {
+			t.Fatalf("predict: status %d", status)
+		}
+		var prediction models.Prediction
+		if err := db.Where("agent_id = ?", agent.ID).First(&prediction).Error; err != nil {
+			t.Fatalf("load prediction: %v", err)
+		}
+		commentPath := fmt.Sprintf("/v0/prediction/%d/comments", prediction.ID)
+		if status := h.do(http.MethodPost, commentPath, agent, map[string]interface{}{"content": "Signed, forgettable"}, nil); status != http.StatusCreated {
+			t.Fatalf("comment: status %d", status)
+		}
+		shadow, err := repository.NewGormAgentRepo(db).ShadowUser(agent)
+		if err != nil {
+			t.Fatalf("shadow user: %v", err)
+		}
+		if err := db.Create(&models.Bet{Username: shadow.Username, MarketID: uint(market.ID), Amount: 10, Outcome: "YES"}).Error; err != nil {
+			t.Fatalf("create bet: %v", err)
+		}
+
+		erasurePath := fmt.Sprintf("/v0/user/agents/%d/erasure", agent.ID)
+		if status := h.doAsUser("owner", http.MethodPost, erasurePath, nil, nil); status != http.StatusAccepted {
+			t.Fatalf("request erasure: status %d", status)
+		}
+		if status := h.doAsUser("owner", http.MethodPost, erasurePath, nil, nil); status != http.StatusConflict {
+			t.Fatalf("expected asking twice to conflict, got %d", status)
+		}
+		if h.reloadAgent(agent).IsActive {
+			t.Fatalf("expected the agent deactivated on request")
+		}
+
+		// Nothing is erased during the grace period.
+		if n, err := agentdata.EraseDue(context.Background(), db, time.Now()); err != nil || n != 0 {
+			t.Fatalf("expected nothing due yet, got %d, %v", n, err)
+		}
+		later := time.Now().AddDate(0, 0, setup.EconomicsConfig().Retention.OrDefaults().ErasureDays+1)
+		if n, err := agentdata.EraseDue(context.Background(), db, later); err != nil || n != 1 {
+			t.Fatalf("expected one erasure, got %d, %v", n, err)
+		}
+
+		erased := h.reloadAgent(agent)
+		if erased.Name != models.ErasedAgentName(agent.ID) || erased.Description != "" || erased.OwnerUserID != nil {
+			t.Fatalf("expected the agent anonymized, got %+v", erased)
+		}
+		if erased.TotalPredictions != 1 {
+			t.Fatalf("expected the agent's stats kept, got %d predictions", erased.TotalPredictions)
+		}
+		if err := db.First(&prediction, prediction.ID).Error; err != nil || prediction.Reasoning != "" || prediction.Outcome != "YES" {
+			t.Fatalf("expected the prediction kept without its reasoning, got %+v, %v", prediction, err)
+		}
+		var comment models.PredictionComment
+		if err := db.Where("prediction_id = ?", prediction.ID).First(&comment).Error; err != nil ||
+			comment.Content != models.ErasedContent || comment.AuthorName != erased.Name {
+			t.Fatalf("expected the comment blanked, got %+v, %v", comment, err)
+		}
+		var bet models.Bet
+		if err := db.Where("market_id = ?", market.ID).First(&bet).Error; err != nil || bet.Username != models.AgentShadowUsername(erased.Name) {
+			t.Fatalf("expected the bet moved to the pseudonym, got %+v, %v", bet, err)
+		}
+
+		var got struct {
+			Erasure     models.DataErasure         `json:"erasure"`
+			Certificate *models.ErasureCertificate `json:"certificate"`
+		}
+		if status := h.doAsUser("owner", http.MethodGet, erasurePath, nil, &got); status != http.StatusOK {
+			t.Fatalf("get erasure: status %d", status)
+		}
+		if got.Erasure.Status != models.ErasureCompleted || got.Certificate == nil {
+			t.Fatalf("expected a completed erasure with its certificate, got %+v", got)
+		}
+		if hash, err := got.Certificate.Hash(); err != nil || hash != got.Erasure.CertificateHash {
+			t.Fatalf("expected the certificate to match its hash, got %s, %v", hash, err)
+		}
+		if got.Certificate.Erased["predictions"] != 1 || got.Certificate.Erased["predictionComments"] != 1 || got.Certificate.Erased["bets"] != 1 {
+			t.Fatalf("unexpected erased counts %v", got.Certificate.Erased)
+		}
+		if status := h.doAsUser("owner", http.MethodDelete, erasurePath, nil, nil); status != http.StatusNotFound {
+			t.Fatalf("expected a completed erasure not to be cancellable, got %d", status)
+		}
+	})
+}
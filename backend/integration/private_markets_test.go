@@ -0,0 +1,211 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+// listedMarketIDs returns the IDs of the markets GET /v0/markets lists.
+func listedMarketIDs(h *harness) map[int64]bool {
+	h.t.Helper()
+	var listing struct {
+		Markets []struct {
+			Market struct {
+				ID int64 `json:"id"`
+			} `json:"market"`
+		} `json:"markets"`
+	}
+	if status := h.do(http.MethodGet, "/v0/markets", nil, nil, &listing); status != http.StatusOK {
+		h.t.Fatalf("list markets: status %d", status)
+	}
+	ids := make(map[int64]bool, len(listing.Markets))
+	for _, m := range listing.Markets {
+		ids[m.Market.ID] = true
+	}
+	return ids
+}
+
+func TestPrivateMarkets_InvitationsGateListingAndPredictions(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		creator := h.createAgent("experimenter")
+		guest := h.createAgent("guest")
+		outsider := h.createAgent("outsider")
+		public := h.createMarket("Will the public market stay listed?")
+
+		create := func(title, visibility string) models.Market {
+			t.Helper()
+			var created struct {
+				Market models.Market `json:"market"`
+			}
+			body := map[string]interface{}{
+				"questionTitle":      title,
+				"description":        "Resolves YES if the closed experiment says so.",
+				"resolutionDateTime": time.Now().Add(72 * time.Hour).UTC().Format(time.RFC3339),
+				"resolutionCriteria": testCriteria,
+				"visibility":         visibility,
+			}
+			if status := h.do(http.MethodPost, "/v0/agents/create", creator, body, &created); status != http.StatusCreated {
+				t.Fatalf("create %s market: status %d", visibility, status)
+			}
+			if created.Market.Visibility != visibility {
+				t.Fatalf("expected visibility %q, got %q", visibility, created.Market.Visibility)
+			}
+			return created.Market
+		}
+		private := create("Will the closed experiment converge?", models.MarketInviteOnly)
+		unlisted := create("Will the unlisted market stay findable by link?", models.MarketUnlisted)
+
+		listed := listedMarketIDs(h)
+		if !listed[public.ID] || listed[private.ID] || listed[unlisted.ID] {
+			t.Fatalf("expected only the public market to be listed, got %v", listed)
+		}
+
+		predict := func(agent *models.Agent, marketID int64) int {
+			status, _ := h.doError(http.MethodPost, "/v0/predict", agent, map[string]interface{}{"marketId": marketID, "outcome": "YES", "confidence": 70}, nil)
+			return status
+		}
+		if status := predict(outsider, unlisted.ID); status != http.StatusCreated {
+			t.Fatalf("expected anyone to predict on an unlisted market, got %d", status)
+		}
+		if status := predict(guest, private.ID); status != http.StatusNotFound {
+			t.Fatalf("expected an uninvited agent to be refused, got %d", status)
+		}
+
+		path := fmt.Sprintf("/v0/markets/%d/invitations", private.ID)
+		if status, _ := h.doError(http.MethodPost, path, guest, map[string]interface{}{"agentIds": []int64{guest.ID}}, nil); status != http.StatusNotFound {
+			t.Fatalf("expected an uninvited agent not to see the market's invitations, got %d", status)
+		}
+		if status, code := h.doError(http.MethodPost, fmt.Sprintf("/v0/markets/%d/invitations", public.ID), creator, map[string]interface{}{"agentIds": []int64{guest.ID}}, nil); status != http.StatusForbidden || code != response.CodeForbidden {
+			t.Fatalf("expected invitations to a market the agent did not create to be refused, got %d %s", status, code)
+		}
+		var invited struct {
+			Count int `json:"count"`
+		}
+		if status := h.do(http.MethodPost, path, creator, map[string]interface{}{"agentIds": []int64{guest.ID, guest.ID}}, &invited); status != http.StatusOK || invited.Count != 1 {
+			t.Fatalf("invite guest: status %d, %+v", status, invited)
+		}
+		if status, _ := h.doError(http.MethodGet, path, guest, nil, nil); status != http.StatusForbidden {
+			t.Fatalf("expected only the creator to list invitations, got %d", status)
+		}
+
+		if status := predict(guest, private.ID); status != http.StatusCreated {
+			t.Fatalf("expected the invited agent to predict, got %d", status)
+		}
+		if status := predict(creator, private.ID); status != http.StatusCreated {
+			t.Fatalf("expected the creator to predict, got %d", status)
+		}
+		if status, _ := h.doError(http.MethodGet, fmt.Sprintf("/v0/market/%d/predictions", private.ID), nil, nil, nil); status != http.StatusNotFound {
+			t.Fatalf("expected the private market's predictions to be hidden from anonymous readers, got %d", status)
+		}
+		if status := h.do(http.MethodGet, fmt.Sprintf("/v0/market/%d/predictions", private.ID), guest, nil, nil); status != http.StatusOK {
+			t.Fatalf("expected the invited agent to read predictions, got %d", status)
+		}
+		if status, _ := h.doError(http.MethodGet, fmt.Sprintf("/v0/markets/%d/swarm", private.ID), outsider, nil, nil); status != http.StatusNotFound {
+			t.Fatalf("expected the private market's consensus to be hidden from outsiders, got %d", status)
+		}
+
+		if status := h.do(http.MethodDelete, fmt.Sprintf("%s/%d", path, guest.ID), creator, nil, nil); status != http.StatusOK {
+			t.Fatalf("revoke invitation: status %d", status)
+		}
+		if status := predict(guest, private.ID); status != http.StatusNotFound {
+			t.Fatalf("expected a revoked agent to be refused, got %d", status)
+		}
+	})
+}
+
+func TestPrivateMarkets_SubmissionBypassesCouncil(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		submitter := h.createAgent("submitter")
+		body := marketSubmission()
+		body["visibility"] = models.MarketInviteOnly
+
+		var created struct {
+			Status   string `json:"status"`
+			MarketID int64  `json:"marketId"`
+		}
+		if status := h.do(http.MethodPost, "/v0/submit/market", submitter, body, &created); status != http.StatusCreated {
+			t.Fatalf("submit private market: status %d", status)
+		}
+		if created.Status != "created" || created.MarketID == 0 {
+			t.Fatalf("expected the invite-only market to skip the council, got %+v", created)
+		}
+		var market models.Market
+		if err := db.First(&market, created.MarketID).Error; err != nil {
+			t.Fatalf("load market: %v", err)
+		}
+		if market.Visibility != models.MarketInviteOnly || market.CreatorAgentID == nil || *market.CreatorAgentID != submitter.ID {
+			t.Fatalf("unexpected market %+v", market)
+		}
+
+		// Public submissions still go to the council
+		var pending struct {
+			Status string `json:"status"`
+		}
+		if status := h.do(http.MethodPost, "/v0/submit/market", submitter, marketSubmission(), &pending); status != http.StatusCreated {
+			t.Fatalf("submit public market: status %d", status)
+		}
+		if pending.Status == "created" {
+			t.Fatalf("expected the public market to await the council")
+		}
+	})
+}
+
+func TestPrivateMarkets_ReadEndpointsHideInviteOnlyMarkets(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		creator := h.createAgent("experimenter")
+		outsider := h.createAgent("outsider")
+		market := h.createMarket("Will the closed experiment converge?")
+		db.Model(&models.Market{}).Where("id = ?", market.ID).Updates(map[string]interface{}{"visibility": models.MarketInviteOnly, "creator_agent_id": creator.ID})
+
+		for _, endpoint := range []string{"stats", "consensus/history", "activity-heatmap", "correlated"} {
+			path := fmt.Sprintf("/v0/markets/%d/%s", market.ID, endpoint)
+			if status, _ := h.doError(http.MethodGet, path, outsider, nil, nil); status != http.StatusNotFound {
+				t.Fatalf("expected %s to be hidden from outsiders, got %d", endpoint, status)
+			}
+			if status := h.do(http.MethodGet, path, creator, nil, nil); status != http.StatusOK {
+				t.Fatalf("expected %s to be open to the creator, got %d", endpoint, status)
+			}
+		}
+
+		for _, path := range []string{
+			fmt.Sprintf("/v0/markets/%d", market.ID),
+			fmt.Sprintf("/v0/markets/bets/%d", market.ID),
+			fmt.Sprintf("/v0/markets/positions/%d", market.ID),
+			fmt.Sprintf("/v0/markets/leaderboard/%d", market.ID),
+			fmt.Sprintf("/v0/markets/%d/disputes", market.ID),
+		} {
+			if status, code := h.doError(http.MethodGet, path, outsider, nil, nil); status != http.StatusNotFound || code != response.CodeMarketNotFound {
+				t.Fatalf("expected %s to be hidden from outsiders, got %d %s", path, status, code)
+			}
+			if status, _ := h.doError(http.MethodGet, path, nil, nil, nil); status != http.StatusNotFound {
+				t.Fatalf("expected %s to be hidden from anonymous readers, got %d", path, status)
+			}
+			if status := h.do(http.MethodGet, path, creator, nil, nil); status != http.StatusOK {
+				t.Fatalf("expected %s to be open to the creator, got %d", path, status)
+			}
+		}
+
+		var details struct {
+			Market struct {
+				ID int64 `json:"id"`
+			} `json:"market"`
+		}
+		if status := h.do(http.MethodGet, fmt.Sprintf("/v0/markets/%d", market.ID), creator, nil, &details); status != http.StatusOK || details.Market.ID != market.ID {
+			t.Fatalf("expected the creator to read the market's details, got %d %+v", status, details)
+		}
+	})
+}
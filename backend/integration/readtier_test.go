@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"socialpredict/audit"
+	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func TestReadTier_PublicWithoutCredentials(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		agent := h.createAgent("open-book")
+		market := h.createMarket("Will the public tier stay public?")
+
+		for _, path := range []string{
+			"/v0/markets",
+			fmt.Sprintf("/v0/markets/%d", market.ID),
+			fmt.Sprintf("/v0/markets/%d/swarm", market.ID),
+			"/v0/leaderboard",
+			"/v0/council/validators",
+			fmt.Sprintf("/v0/agent/%d", agent.ID),
+		} {
+			rec := h.record(http.MethodGet, path, nil, nil)
+			if rec.Code != http.StatusOK || rec.Header().Get(middleware.AccessTierHeader) != string(middleware.TierAnonymous) {
+				t.Fatalf("GET %s without credentials: got %d %q, want 200 anonymous", path, rec.Code, rec.Header().Get(middleware.AccessTierHeader))
+			}
+		}
+
+		var profile struct {
+			Agent models.AgentPublic `json:"agent"`
+		}
+		if status := h.do(http.MethodGet, fmt.Sprintf("/v0/agent/%d", agent.ID), nil, nil, &profile); status != http.StatusOK || profile.Agent.Name != agent.Name {
+			t.Fatalf("agent profile: status %d, got %+v", status, profile.Agent)
+		}
+		if status := h.do(http.MethodGet, "/v0/agent/999999", nil, nil, nil); status != http.StatusNotFound {
+			t.Fatalf("unknown agent profile: status %d, want 404", status)
+		}
+
+		// An agent key is accepted but not needed, and is served on its own tier.
+		header := http.Header{}
+		header.Set("X-Agent-API-Key", agent.APIKey)
+		rec := h.record(http.MethodGet, "/v0/markets", header, nil)
+		if rec.Code != http.StatusOK || rec.Header().Get(middleware.AccessTierHeader) != string(middleware.TierAgent) {
+			t.Fatalf("GET /v0/markets as an agent: got %d %q", rec.Code, rec.Header().Get(middleware.AccessTierHeader))
+		}
+	})
+}
+
+func TestReadTier_AnalyticsKeyGrantedByOperator(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		key, err := models.GenerateReadAPIKey()
+		if err != nil {
+			t.Fatalf("GenerateReadAPIKey: %v", err)
+		}
+		readKey := models.ReadAPIKey{OwnerUserID: 1, Name: "research", KeyHash: models.HashReadAPIKey(key), KeyPrefix: key[:15]}
+		if err := db.Create(&readKey).Error; err != nil {
+			t.Fatalf("create read key: %v", err)
+		}
+		header := http.Header{}
+		header.Set(middleware.ReadAPIKeyHeader, key)
+		tierOf := func() string {
+			rec := h.record(http.MethodGet, "/v0/markets", header, nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET /v0/markets with a read key: status %d", rec.Code)
+			}
+			return rec.Header().Get(middleware.AccessTierHeader)
+		}
+
+		if tier := tierOf(); tier != string(middleware.TierReadKey) {
+			t.Fatalf("new key served as %q, want read_key", tier)
+		}
+
+		path := fmt.Sprintf("/v0/admin/readkeys/%d/analytics", readKey.ID)
+		var granted struct {
+			Key models.ReadAPIKey `json:"key"`
+		}
+		if status := h.doAsAdmin(http.MethodPut, path, nil, &granted); status != http.StatusOK || !granted.Key.Analytics {
+			t.Fatalf("grant analytics: status %d, key %+v", status, granted.Key)
+		}
+		if tier := tierOf(); tier != string(middleware.TierAnalytics) {
+			t.Fatalf("analytics key served as %q, want analytics", tier)
+		}
+
+		if status := h.doAsAdmin(http.MethodDelete, path, nil, nil); status != http.StatusOK {
+			t.Fatalf("revoke analytics: status %d", status)
+		}
+		if tier := tierOf(); tier != string(middleware.TierReadKey) {
+			t.Fatalf("key served as %q after revoking analytics, want read_key", tier)
+		}
+
+		var logged auditEntries
+		if status := h.doAsAdmin(http.MethodGet, "/v0/admin/audit?action="+audit.ActionAnalyticsKeyGranted, nil, &logged); status != http.StatusOK || len(logged.Entries) != 1 {
+			t.Fatalf("audit log: status %d, %d analytics grants, want 1", status, len(logged.Entries))
+		}
+
+		if status := h.doAsAdmin(http.MethodPut, "/v0/admin/readkeys/999999/analytics", nil, nil); status != http.StatusNotFound {
+			t.Fatalf("grant analytics to an unknown key: status %d, want 404", status)
+		}
+	})
+}
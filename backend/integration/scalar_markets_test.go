@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/response"
+	"socialpredict/services/consensus"
+
+	"gorm.io/gorm"
+)
+
+func TestScalarMarkets_IntervalPredictionsConsensusAndCRPS(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		creator := h.createAgent("forecaster")
+		inside := h.createAgent("inside")
+		outside := h.createAgent("outside")
+
+		body := map[string]interface{}{
+			"questionTitle":      "What will the average rainfall be in April?",
+			"description":        "The monthly average rainfall in millimetres.",
+			"resolutionDateTime": time.Now().Add(72 * time.Hour).UTC().Format(time.RFC3339),
+			"resolutionCriteria": testCriteria,
+			"outcomeType":        "scalar",
+			"scalarUnit":         "mm",
+		}
+		if status, _ := h.doError(http.MethodPost, "/v0/agents/create", creator, body, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected a scalar market without bounds to be refused, got %d", status)
+		}
+		body["scalarMin"], body["scalarMax"] = 0, 200
+		var created struct {
+			Market models.Market `json:"market"`
+		}
+		if status := h.do(http.MethodPost, "/v0/agents/create", creator, body, &created); status != http.StatusCreated {
+			t.Fatalf("create scalar market: status %d", status)
+		}
+		market := created.Market
+		if !market.IsScalar() || market.ScalarMax == nil || *market.ScalarMax != 200 {
+			t.Fatalf("unexpected market %+v", market)
+		}
+
+		predict := func(agent *models.Agent, body map[string]interface{}) int {
+			body["marketId"] = market.ID
+			status, _ := h.doError(http.MethodPost, "/v0/predict", agent, body, nil)
+			return status
+		}
+		if status := predict(inside, map[string]interface{}{"outcome": "YES", "confidence": 70}); status != http.StatusBadRequest {
+			t.Fatalf("expected a YES prediction on a scalar market to be refused, got %d", status)
+		}
+		if status := predict(inside, map[string]interface{}{"estimate": 80, "low": 90, "high": 100, "confidence": 70}); status != http.StatusBadRequest {
+			t.Fatalf("expected an estimate outside its interval to be refused, got %d", status)
+		}
+		if status := predict(inside, map[string]interface{}{"estimate": 250, "low": 200, "high": 300, "confidence": 70}); status != http.StatusBadRequest {
+			t.Fatalf("expected an interval past the market's range to be refused, got %d", status)
+		}
+		if status := predict(inside, map[string]interface{}{"estimate": 80, "low": 60, "high": 100, "confidence": 80}); status != http.StatusCreated {
+			t.Fatalf("predict inside: status %d", status)
+		}
+		if status := predict(outside, map[string]interface{}{"estimate": 150, "low": 140, "high": 160, "confidence": 90}); status != http.StatusCreated {
+			t.Fatalf("predict outside: status %d", status)
+		}
+
+		var swarm struct {
+			Scalar *consensus.Scalar `json:"scalar"`
+		}
+		if status := h.do(http.MethodGet, fmt.Sprintf("/v0/markets/%d/swarm", market.ID), nil, nil, &swarm); status != http.StatusOK {
+			t.Fatalf("swarm: status %d", status)
+		}
+		if swarm.Scalar == nil || swarm.Scalar.Predictions != 2 || swarm.Scalar.Median != 80 || swarm.Scalar.Spread != 70 {
+			t.Fatalf("expected the weighted median of the estimates, got %+v", swarm.Scalar)
+		}
+
+		path := fmt.Sprintf("/v0/markets/%d/resolve", market.ID)
+		if status, code := h.doError(http.MethodPost, path, creator, map[string]interface{}{"value": 85}, nil); status != http.StatusConflict || code != response.CodeConflict {
+			t.Fatalf("expected the creator to wait for the resolution date, got %d %s", status, code)
+		}
+		db.Model(&models.Market{}).Where("id = ?", market.ID).Update("resolution_date_time", time.Now().Add(-time.Hour))
+		if status, _ := h.doError(http.MethodPost, path, creator, map[string]interface{}{"outcome": "YES"}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected a YES resolution of a scalar market to be refused, got %d", status)
+		}
+		if status := h.do(http.MethodPost, path, creator, map[string]interface{}{"value": 85}, nil); status != http.StatusOK {
+			t.Fatalf("resolve scalar market: status %d", status)
+		}
+
+		var resolved models.Market
+		db.First(&resolved, market.ID)
+		if resolved.ResolutionResult != "85" || resolved.ResolutionValue == nil || *resolved.ResolutionValue != 85 {
+			t.Fatalf("expected the market to resolve at 85, got %q %v", resolved.ResolutionResult, resolved.ResolutionValue)
+		}
+		scored := func(agent *models.Agent) models.Prediction {
+			var p models.Prediction
+			db.Where("market_id = ? AND agent_id = ?", market.ID, agent.ID).First(&p)
+			if !p.IsResolved || p.CRPS == nil {
+				t.Fatalf("expected %s's prediction to be scored, got %+v", agent.Name, p)
+			}
+			return p
+		}
+		near, far := scored(inside), scored(outside)
+		if !near.WasCorrect || far.WasCorrect || *near.CRPS >= *far.CRPS {
+			t.Fatalf("expected the interval around 85 to win, got %+v and %+v", near, far)
+		}
+	})
+}
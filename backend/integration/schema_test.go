@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+// TestMigrations_CoverLiveModels fails when a migration leaves a column of a
+// live model missing on either dialect.
+func TestMigrations_CoverLiveModels(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		liveModels := []interface{}{
+			&models.User{},
+			&models.Market{},
+			&models.Bet{},
+			&models.Agent{},
+			&models.Prediction{},
+			&models.PredictionVote{},
+			&models.PredictionComment{},
+			&models.AgentFollow{},
+			&models.Proposal{},
+			&models.ProposalVote{},
+			&models.ProposalComment{},
+			&models.ProposalRevision{},
+			&models.GovernanceDelegation{},
+			&models.PlatformConfig{},
+			&models.AdminRole{},
+			&models.AuditLog{},
+			&models.AgentSuspension{},
+			&models.ContentReport{},
+			&models.ModerationItem{},
+			&models.PendingSubmission{},
+			&models.CouncilVote{},
+			&models.SubmissionJob{},
+			&models.ValidatorAgent{},
+			&models.OutboxEvent{},
+			&models.Notification{},
+			&models.ReadAPIKey{},
+			&models.AgentAPIKey{},
+			&models.SchedulerLease{},
+			&models.ClosingBid{},
+			&models.ConsensusSnapshot{},
+			&models.MarketCorrelation{},
+			&models.ReservedAgentName{},
+			&models.AgentNameChange{},
+			&models.IdempotencyRecord{},
+			&models.SandboxPrediction{},
+			&models.AdminJob{},
+			&models.AutoResolution{},
+			&models.MarketTag{},
+			&models.ConsensusPoint{},
+			&models.PredictionRevision{},
+			&models.AgentCategoryStats{},
+			&models.LeaderboardSnapshot{},
+			&models.AgentRanking{},
+			&models.TrendingMarket{},
+			&models.MarketWatch{},
+			&models.Mention{},
+			&models.Team{},
+			&models.TeamMember{},
+			&models.MarketInvitation{},
+			&models.MarketSeries{},
+			&models.MarketTemplate{},
+			&models.SubmissionComment{},
+			&models.VerificationRule{},
+			&models.ReasoningReview{},
+			&models.Achievement{},
+			&models.OutboxCursor{},
+			&models.DataErasure{},
+			&models.ResolutionRequest{},
+			&models.ResolutionVote{},
+			&models.ResolutionDispute{},
+		}
+
+		m := db.Migrator()
+		for _, model := range liveModels {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				t.Fatalf("parse %T: %v", model, err)
+			}
+			if !m.HasTable(model) {
+				t.Errorf("missing table %s for %T", stmt.Schema.Table, model)
+				continue
+			}
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName == "" {
+					continue
+				}
+				if !m.HasColumn(model, field.DBName) {
+					t.Errorf("missing column %s.%s", stmt.Schema.Table, field.DBName)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/services/adminjobs"
+	"socialpredict/services/scoring"
+
+	"gorm.io/gorm"
+)
+
+func TestPredictionResolution_UpdatesAccuracyScores(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		market := h.createMarket("Will resolution scoring agree across dialects?")
+		right := h.createAgent("right")
+		wrong := h.createAgent("wrong")
+
+		for agent, outcome := range map[*models.Agent]string{right: "YES", wrong: "NO"} {
+			body := map[string]interface{}{"marketId": market.ID, "outcome": outcome, "confidence": 80}
+			if status := h.do(http.MethodPost, "/v0/predict", agent, body, nil); status != http.StatusCreated {
+				t.Fatalf("predict %s by %s: status %d", outcome, agent.Name, status)
+			}
+		}
+
+		// Resolve the market YES and mark predictions, mirroring the
+		// resolution step with a single dialect-neutral statement.
+		if err := db.Model(market).Updates(map[string]interface{}{"is_resolved": true, "resolution_result": "YES"}).Error; err != nil {
+			t.Fatalf("resolve market: %v", err)
+		}
+		if err := db.Exec("UPDATE predictions SET is_resolved = ?, was_correct = (outcome = ?) WHERE market_id = ?", true, "YES", market.ID).Error; err != nil {
+			t.Fatalf("resolve predictions: %v", err)
+		}
+
+		var queued struct {
+			Job models.AdminJob `json:"job"`
+		}
+		if status := h.doAsAdmin(http.MethodPost, "/v0/admin/recalculate-scores", nil, &queued); status != http.StatusAccepted {
+			t.Fatalf("recalculate scores: status %d", status)
+		}
+		// The scheduler runs queued jobs in production.
+		if _, err := adminjobs.RunPending(context.Background(), db); err != nil {
+			t.Fatalf("run admin jobs: %v", err)
+		}
+		var finished struct {
+			Job models.AdminJob `json:"job"`
+		}
+		if status := h.doAsAdmin(http.MethodGet, fmt.Sprintf("/v0/admin/jobs/%d", queued.Job.ID), nil, &finished); status != http.StatusOK {
+			t.Fatalf("get recalculation job: status %d", status)
+		}
+		if finished.Job.Status != models.AdminJobSucceeded || finished.Job.Processed != 2 || finished.Job.Total != 2 {
+			t.Fatalf("expected the recalculation to finish 2 of 2 agents, got %+v", finished.Job)
+		}
+
+		gotRight := h.reloadAgent(right)
+		gotWrong := h.reloadAgent(wrong)
+		if gotRight.CorrectPredictions != 1 || gotRight.ResolvedPredictions != 1 {
+			t.Fatalf("expected right agent 1/1 correct, got %d/%d", gotRight.CorrectPredictions, gotRight.ResolvedPredictions)
+		}
+		if gotWrong.CorrectPredictions != 0 || gotWrong.ResolvedPredictions != 1 {
+			t.Fatalf("expected wrong agent 0/1 correct, got %d/%d", gotWrong.CorrectPredictions, gotWrong.ResolvedPredictions)
+		}
+		if !(gotRight.AccuracyScore > 50 && gotWrong.AccuracyScore < 50) {
+			t.Fatalf("expected accuracy to split around 50, got right=%.2f wrong=%.2f", gotRight.AccuracyScore, gotWrong.AccuracyScore)
+		}
+	})
+}
+
+func TestReasoningQuality_ReviewedRigorBeatsUpvotedOneLiners(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		market := h.createMarket("Will the Fed cut rates in June?")
+		terse, rigorous := h.createAgent("terse"), h.createAgent("rigorous")
+		reviewer := h.createAgent("reviewer")
+		h.makeValidator(reviewer)
+
+		predict := func(agent *models.Agent, reasoning string) models.PredictionPublic {
+			t.Helper()
+			var made models.PredictionResponse
+			body := map[string]interface{}{"marketId": market.ID, "outcome": "YES", "confidence": 70, "reasoning": reasoning}
+			if status := h.do(http.MethodPost, "/v0/predict", agent, body, &made); status != http.StatusCreated && status != http.StatusOK {
+				t.Fatalf("predict by %s: status %d", agent.Name, status)
+			}
+			return made.Prediction
+		}
+		oneLiner := predict(terse, "Cut incoming")
+		argued := predict(rigorous, `Core PCE has run at 2.6% for three months, according to the BEA
+(https://www.bea.gov/data/personal-income). Futures price a 64% chance of a June cut.
+Two governors have said policy is restrictive. A strong May payrolls print is the risk.`)
+		if oneLiner.ReasoningScore >= argued.ReasoningScore {
+			t.Fatalf("heuristic scored the one-liner %v and the argued reasoning %v", oneLiner.ReasoningScore, argued.ReasoningScore)
+		}
+		// The one-liner is the crowd favourite.
+		db.Model(&models.Prediction{}).Where("id = ?", oneLiner.ID).Update("upvotes", 40)
+
+		var queue struct {
+			Predictions []models.PredictionPublic `json:"predictions"`
+		}
+		if status := h.do(http.MethodGet, "/v0/council/reasoning/queue", reviewer, nil, &queue); status != http.StatusOK || len(queue.Predictions) != 2 {
+			t.Fatalf("review queue: status %d, %d predictions", status, len(queue.Predictions))
+		}
+		review := func(predictionID int64, rating int) (int, float64) {
+			t.Helper()
+			var reviewed struct {
+				ReasoningScore float64 `json:"reasoningScore"`
+			}
+			status := h.do(http.MethodPost, fmt.Sprintf("/v0/council/reasoning/%d/review", predictionID), reviewer, map[string]interface{}{"rating": rating}, &reviewed)
+			return status, reviewed.ReasoningScore
+		}
+		if status, score := review(oneLiner.ID, 1); status != http.StatusOK || score >= oneLiner.ReasoningScore {
+			t.Fatalf("review one-liner: status %d score %v", status, score)
+		}
+		if status, score := review(argued.ID, 5); status != http.StatusOK || score <= argued.ReasoningScore {
+			t.Fatalf("review argued: status %d score %v", status, score)
+		}
+		if status, _ := review(argued.ID, 4); status != http.StatusOK {
+			t.Fatalf("re-review: status %d", status)
+		}
+		var reviews int64
+		db.Model(&models.ReasoningReview{}).Where("prediction_id = ?", argued.ID).Count(&reviews)
+		if reviews != 1 {
+			t.Fatalf("expected a re-review to replace the first, got %d reviews", reviews)
+		}
+		queue.Predictions = nil
+		h.do(http.MethodGet, "/v0/council/reasoning/queue", reviewer, nil, &queue)
+		if len(queue.Predictions) != 0 {
+			t.Fatalf("expected reviewed reasoning out of the queue, got %+v", queue.Predictions)
+		}
+		if status, code := h.doError(http.MethodPost, fmt.Sprintf("/v0/council/reasoning/%d/review", argued.ID), terse, map[string]interface{}{"rating": 1}, nil); status != http.StatusForbidden {
+			t.Fatalf("review by a non-validator: status %d code %s", status, code)
+		}
+
+		// Recalculated, the well-argued predictor out-engages the upvoted one.
+		if _, err := scoring.RecomputeAgents(context.Background(), db, terse.ID, rigorous.ID); err != nil {
+			t.Fatalf("recompute: %v", err)
+		}
+		terse, rigorous = h.reloadAgent(terse), h.reloadAgent(rigorous)
+		if terse.EngagementScore >= rigorous.EngagementScore {
+			t.Fatalf("engagement: terse %v (reasoning %v), rigorous %v (reasoning %v)",
+				terse.EngagementScore, terse.MeanReasoningScore, rigorous.EngagementScore, rigorous.MeanReasoningScore)
+		}
+
+		// Revised reasoning is scored afresh; reviews were of the old text.
+		revised := predict(rigorous, "Changed my mind after the payrolls print")
+		if revised.ReasoningReviews != 0 || revised.ReasoningScore >= argued.ReasoningScore {
+			t.Fatalf("revised prediction = %+v", revised)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/response"
+	"socialpredict/services/series"
+
+	"gorm.io/gorm"
+)
+
+func seriesMarket(title string) map[string]interface{} {
+	return map[string]interface{}{
+		"questionTitle":      title,
+		"description":        "Resolves YES if the launch happens in the quarter.",
+		"resolutionDateTime": time.Now().Add(72 * time.Hour).UTC().Format(time.RFC3339),
+		"resolutionCriteria": testCriteria,
+	}
+}
+
+func TestSeries_ExclusiveMarketsStayCoherent(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		creator := h.createAgent("planner")
+		forecaster := h.createAgent("forecaster")
+
+		body := map[string]interface{}{
+			"title":             "When will the launch happen?",
+			"mutuallyExclusive": true,
+			"markets": []map[string]interface{}{
+				seriesMarket("Will the launch happen in Q1?"),
+				seriesMarket("Will the launch happen in q1? "),
+			},
+		}
+		if status, _ := h.doError(http.MethodPost, "/v0/series", creator, body, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected a series asking the same question twice to be refused, got %d", status)
+		}
+		scalar := seriesMarket("How many days will the launch slip?")
+		scalar["outcomeType"], scalar["scalarMin"], scalar["scalarMax"] = "SCALAR", 0, 90
+		body["markets"] = []map[string]interface{}{seriesMarket("Will the launch happen in Q1?"), scalar}
+		if status, _ := h.doError(http.MethodPost, "/v0/series", creator, body, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected a scalar market in an exclusive series to be refused, got %d", status)
+		}
+		var count int64
+		db.Model(&models.MarketSeries{}).Count(&count)
+		if count != 0 {
+			t.Fatalf("expected refused series not to be stored, got %d", count)
+		}
+
+		body["markets"] = []map[string]interface{}{
+			seriesMarket("Will the launch happen in Q1?"),
+			seriesMarket("Will the launch happen in Q2?"),
+			seriesMarket("Will the launch happen in Q3?"),
+		}
+		var created struct {
+			Series  models.MarketSeries `json:"series"`
+			Markets []models.Market     `json:"markets"`
+		}
+		if status := h.do(http.MethodPost, "/v0/series", creator, body, &created); status != http.StatusCreated {
+			t.Fatalf("create series: status %d", status)
+		}
+		if len(created.Markets) != 3 || created.Markets[0].SeriesID == nil || *created.Markets[0].SeriesID != created.Series.ID {
+			t.Fatalf("expected three markets in the series, got %+v", created.Markets)
+		}
+		q1, q2, q3 := created.Markets[0], created.Markets[1], created.Markets[2]
+
+		predict := func(market models.Market, outcome string, confidence float64) (int, response.Code) {
+			return h.doError(http.MethodPost, "/v0/predict", forecaster, map[string]interface{}{"marketId": market.ID, "outcome": outcome, "confidence": confidence}, nil)
+		}
+		if status, _ := predict(q1, "YES", 60); status != http.StatusCreated {
+			t.Fatalf("predict Q1: status %d", status)
+		}
+		if status, code := predict(q2, "YES", 50); status != http.StatusBadRequest || code != response.CodeBadRequest {
+			t.Fatalf("expected chances adding up to 110%% to be refused, got %d %s", status, code)
+		}
+		if status, _ := predict(q2, "YES", 30); status != http.StatusCreated {
+			t.Fatalf("predict Q2: status %d", status)
+		}
+		// NO at 80% is a 20% chance of YES, on top of 90% already
+		if status, _ := predict(q3, "NO", 80); status != http.StatusBadRequest {
+			t.Fatalf("expected an incoherent NO to be refused, got %d", status)
+		}
+		if status, _ := predict(q3, "NO", 95); status != http.StatusCreated {
+			t.Fatalf("predict Q3: status %d", status)
+		}
+
+		path := fmt.Sprintf("/v0/series/%d", created.Series.ID)
+		var got struct {
+			Markets   []models.Market  `json:"markets"`
+			Consensus series.Consensus `json:"consensus"`
+		}
+		if status := h.do(http.MethodGet, path, nil, nil, &got); status != http.StatusOK {
+			t.Fatalf("get series: status %d", status)
+		}
+		if len(got.Consensus.Markets) != 3 || math.Abs(got.Consensus.TotalProbability-0.95) > 1e-9 {
+			t.Fatalf("expected the series' total chance of YES to be 95%%, got %+v", got.Consensus)
+		}
+		shares := 0.0
+		for _, m := range got.Consensus.Markets {
+			shares += *m.Share
+		}
+		if math.Abs(shares-1) > 1e-9 {
+			t.Fatalf("expected the shares to add up to 1, got %v", shares)
+		}
+
+		if status, _ := h.doError(http.MethodPost, path+"/markets", forecaster, seriesMarket("Will the launch happen in Q4?"), nil); status != http.StatusForbidden {
+			t.Fatalf("expected only the creator to add markets, got %d", status)
+		}
+		db.Model(&models.Market{}).Where("series_id = ?", created.Series.ID).Update("resolution_date_time", time.Now().Add(-time.Hour))
+		if status := h.do(http.MethodPost, fmt.Sprintf("/v0/markets/%d/resolve", q1.ID), creator, map[string]interface{}{"outcome": "YES"}, nil); status != http.StatusOK {
+			t.Fatalf("resolve Q1: status %d", status)
+		}
+		if status, code := h.doError(http.MethodPost, fmt.Sprintf("/v0/markets/%d/resolve", q2.ID), creator, map[string]interface{}{"outcome": "YES"}, nil); status != http.StatusConflict || code != response.CodeConflict {
+			t.Fatalf("expected a second YES in the series to be refused, got %d %s", status, code)
+		}
+		if status, _ := h.doError(http.MethodPost, path+"/markets", creator, seriesMarket("Will the launch happen in Q4?"), nil); status != http.StatusConflict {
+			t.Fatalf("expected a settled series to take no more markets, got %d", status)
+		}
+	})
+}
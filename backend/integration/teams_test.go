@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/notifications"
+	"socialpredict/response"
+
+	"gorm.io/gorm"
+)
+
+func TestTeams_MembershipScoresAndTaggedPredictions(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+
+		captain := h.createAgent("captain")
+		member := h.createAgent("member")
+		solo := h.createAgent("solo")
+		market := h.createMarket("Will teams outpredict agents?")
+
+		var created struct {
+			Team models.Team `json:"team"`
+		}
+		if status := h.do(http.MethodPost, "/v0/teams", captain, map[string]interface{}{"name": "Hive Mind"}, &created); status != http.StatusCreated {
+			t.Fatalf("create team: status %d", status)
+		}
+		path := fmt.Sprintf("/v0/teams/%d", created.Team.ID)
+		if status, code := h.doError(http.MethodPost, "/v0/teams", solo, map[string]interface{}{"name": "hive mind"}, nil); status != http.StatusConflict || code != response.CodeNameTaken {
+			t.Fatalf("expected team names to be unique regardless of case, got %d %s", status, code)
+		}
+
+		if status, _ := h.doError(http.MethodPost, path+"/join", member, nil, nil); status != http.StatusForbidden {
+			t.Fatalf("expected joining without an invitation to be refused, got %d", status)
+		}
+		if status, _ := h.doError(http.MethodPost, path+"/invites", member, map[string]interface{}{"agentId": solo.ID}, nil); status != http.StatusForbidden {
+			t.Fatalf("expected only the captain to invite, got %d", status)
+		}
+		if status := h.do(http.MethodPost, path+"/invites", captain, map[string]interface{}{"agentId": member.ID}, nil); status != http.StatusOK {
+			t.Fatalf("invite member: status %d", status)
+		}
+		var invites int64
+		db.Model(&models.Notification{}).Where("agent_id = ? AND kind = ?", member.ID, notifications.KindTeamInvite).Count(&invites)
+		if invites != 1 {
+			t.Fatalf("expected the invited agent to be notified once, got %d", invites)
+		}
+		if status := h.do(http.MethodPost, path+"/join", member, nil, nil); status != http.StatusOK {
+			t.Fatalf("join team: status %d", status)
+		}
+		if status, code := h.doError(http.MethodPost, "/v0/teams", member, map[string]interface{}{"name": "Breakaway"}, nil); status != http.StatusConflict || code != response.CodeConflict {
+			t.Fatalf("expected a member to be refused a second team, got %d %s", status, code)
+		}
+
+		predict := map[string]interface{}{"marketId": market.ID, "outcome": "YES", "confidence": 70}
+		if status := h.do(http.MethodPost, "/v0/predict", member, predict, nil); status != http.StatusCreated {
+			t.Fatalf("predict as member: status %d", status)
+		}
+		if status := h.do(http.MethodPost, "/v0/predict", solo, predict, nil); status != http.StatusCreated {
+			t.Fatalf("predict as solo: status %d", status)
+		}
+		var tagged struct {
+			Predictions []models.PredictionPublic `json:"predictions"`
+			Total       int64                     `json:"total"`
+		}
+		if status := h.do(http.MethodGet, path+"/predictions", nil, nil, &tagged); status != http.StatusOK {
+			t.Fatalf("team predictions: status %d", status)
+		}
+		if tagged.Total != 1 || tagged.Predictions[0].AgentID != member.ID {
+			t.Fatalf("expected only the member's prediction to be tagged, got %+v", tagged)
+		}
+
+		if status := h.do(http.MethodPost, "/v0/teams", solo, map[string]interface{}{"name": "Lone Wolf"}, nil); status != http.StatusCreated {
+			t.Fatalf("create second team: status %d", status)
+		}
+		for agent, score := range map[*models.Agent]float64{captain: 30, member: 70, solo: 60} {
+			db.Model(&models.Agent{}).Where("id = ?", agent.ID).Update("composite_score", score)
+		}
+
+		var team struct {
+			Members []struct {
+				AgentID int64 `json:"agentId"`
+				Captain bool  `json:"captain"`
+			} `json:"members"`
+			Score models.TeamScore `json:"score"`
+		}
+		if status := h.do(http.MethodGet, path, nil, nil, &team); status != http.StatusOK {
+			t.Fatalf("get team: status %d", status)
+		}
+		if team.Score.Members != 2 || team.Score.AverageCompositeScore != 50 || team.Score.BestCompositeScore != 70 || team.Score.BestAgentID != member.ID || team.Score.TeamPredictions != 1 {
+			t.Fatalf("unexpected team score %+v", team.Score)
+		}
+		if len(team.Members) != 2 || team.Members[0].AgentID != member.ID || !team.Members[1].Captain {
+			t.Fatalf("expected members best first with the captain marked, got %+v", team.Members)
+		}
+
+		var board struct {
+			Leaderboard []models.TeamLeaderboardEntry `json:"leaderboard"`
+			TotalTeams  int64                         `json:"totalTeams"`
+		}
+		if status := h.do(http.MethodGet, "/v0/teams/leaderboard", nil, nil, &board); status != http.StatusOK {
+			t.Fatalf("team leaderboard: status %d", status)
+		}
+		if board.TotalTeams != 2 || board.Leaderboard[0].TeamName != "Lone Wolf" {
+			t.Fatalf("expected Lone Wolf to lead on average score, got %+v", board)
+		}
+		if status := h.do(http.MethodGet, "/v0/teams/leaderboard?sort=best", nil, nil, &board); status != http.StatusOK {
+			t.Fatalf("team leaderboard by best: status %d", status)
+		}
+		if board.Leaderboard[0].TeamName != "Hive Mind" {
+			t.Fatalf("expected Hive Mind to lead on best member, got %+v", board)
+		}
+
+		// The captain leaving hands the team on; the last member leaving disbands it
+		var left struct {
+			Team      *models.Team `json:"team"`
+			Disbanded bool         `json:"disbanded"`
+		}
+		if status := h.do(http.MethodDelete, fmt.Sprintf("%s/members/%d", path, captain.ID), captain, nil, &left); status != http.StatusOK {
+			t.Fatalf("captain leaves: status %d", status)
+		}
+		if left.Disbanded || left.Team.CaptainID != member.ID {
+			t.Fatalf("expected captaincy to pass to the member, got %+v", left)
+		}
+		if status := h.do(http.MethodDelete, fmt.Sprintf("%s/members/%d", path, member.ID), member, nil, &left); status != http.StatusOK {
+			t.Fatalf("member leaves: status %d", status)
+		}
+		if !left.Disbanded {
+			t.Fatalf("expected the empty team to be disbanded")
+		}
+		if status, _ := h.doError(http.MethodGet, path, nil, nil, nil); status != http.StatusNotFound {
+			t.Fatalf("expected the disbanded team to be gone, got %d", status)
+		}
+	})
+}
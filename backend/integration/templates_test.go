@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/services/platformconfig"
+	"socialpredict/services/templates"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+func TestTemplates_ApprovedOnceThenInstantiatedOnSchedule(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		if _, err := platformconfig.Set(db, "council.template.votesRequired", 1, "test", nil); err != nil {
+			t.Fatalf("set template votes: %v", err)
+		}
+		t.Cleanup(platformconfig.Invalidate)
+
+		submitter := h.createAgent("submitter")
+		validator := h.createAgent("validator")
+		h.makeValidator(validator)
+
+		threshold := 100000.0
+		body := map[string]interface{}{
+			"schedule":           "0 0 * * *",
+			"durationHours":      24,
+			"marketType":         models.MarketTypeDaily,
+			"questionTitle":      "Will BTC close above $100k on {date}?",
+			"description":        "Resolves YES if the BTC-USD daily close on {date} is above $100,000.",
+			"initialProbability": 0.5,
+			"resolutionCriteria": map[string]interface{}{
+				"sourceUrl":  "https://exchange.example.com/btc-usd",
+				"threshold":  "BTC-USD close on {date} above 100000.00",
+				"tieBreaker": "A close of exactly 100000.00 resolves NO",
+			},
+			"autoResolve": map[string]interface{}{"oracle": "crypto_price", "symbol": "btc-usd", "operator": ">", "threshold": threshold},
+		}
+		if status, _ := h.doError(http.MethodPost, "/v0/submit/template", submitter, map[string]interface{}{
+			"schedule": "0 0 30 2 *", "durationHours": 24, "questionTitle": "Will it ever be 30 February?",
+		}, nil); status != http.StatusBadRequest {
+			t.Fatalf("expected a schedule that never comes round to fail verification, got %d", status)
+		}
+
+		var submitted struct {
+			SubmissionID int64 `json:"submissionId"`
+		}
+		if status := h.do(http.MethodPost, "/v0/submit/template", submitter, body, &submitted); status != http.StatusCreated {
+			t.Fatalf("submit template: status %d", status)
+		}
+		if status := h.do(http.MethodPost, fmt.Sprintf("/v0/council/vote/%d", submitted.SubmissionID), validator, map[string]string{"vote": "approve"}, nil); status != http.StatusOK {
+			t.Fatalf("approve template: status %d", status)
+		}
+
+		var template models.MarketTemplate
+		if err := db.Where("submission_id = ?", submitted.SubmissionID).First(&template).Error; err != nil {
+			t.Fatalf("expected the approved template to be created: %v", err)
+		}
+		if template.Status != models.TemplateActive || template.Timezone != "UTC" || template.NextRunAt.Hour() != 0 {
+			t.Fatalf("unexpected template %+v", template)
+		}
+		var markets int64
+		db.Model(&models.Market{}).Count(&markets)
+		if markets != 0 {
+			t.Fatalf("expected no market before the schedule comes round, got %d", markets)
+		}
+
+		// Each run creates one market without going back to the council
+		ctx := context.Background()
+		due := template.NextRunAt
+		for i := 0; i < 2; i++ {
+			created, err := templates.RunDue(ctx, db, setup.EconomicsConfig, due.Add(time.Minute))
+			if err != nil {
+				t.Fatalf("RunDue: %v", err)
+			}
+			if want := 1 - i; created != want {
+				t.Fatalf("run %d: expected %d markets created, got %d", i+1, want, created)
+			}
+		}
+
+		var market models.Market
+		if err := db.Where("template_id = ?", template.ID).First(&market).Error; err != nil {
+			t.Fatalf("load instance: %v", err)
+		}
+		date := due.Format("2006-01-02")
+		if want := "Will BTC close above $100k on " + date + "?"; market.QuestionTitle != want {
+			t.Fatalf("expected title %q, got %q", want, market.QuestionTitle)
+		}
+		if !market.ResolutionDateTime.Equal(due.Add(24*time.Hour)) || market.MarketType != models.MarketTypeDaily {
+			t.Fatalf("unexpected instance %+v", market)
+		}
+		if market.CreatorAgentID == nil || *market.CreatorAgentID != submitter.ID {
+			t.Fatalf("expected the instance to be the submitter's, got %v", market.CreatorAgentID)
+		}
+		spec, err := market.OracleSpec()
+		if err != nil || spec == nil || !market.AutoResolve || spec.Symbol != "BTC-USD" || spec.Threshold == nil || *spec.Threshold != threshold {
+			t.Fatalf("expected the template's oracle to be inherited, got %+v (%v)", spec, err)
+		}
+		if criteria := market.ResolutionCriteria(); criteria == nil || criteria.Threshold != "BTC-USD close on "+date+" above 100000.00" || criteria.Timezone != "UTC" {
+			t.Fatalf("expected the template's criteria to be inherited, got %+v", criteria)
+		}
+		if market.SourceSubmissionID == nil || *market.SourceSubmissionID != submitted.SubmissionID {
+			t.Fatalf("expected the instance to trace back to the template's approval, got %v", market.SourceSubmissionID)
+		}
+
+		if err := db.First(&template, template.ID).Error; err != nil {
+			t.Fatalf("reload template: %v", err)
+		}
+		if template.Instances != 1 || !template.NextRunAt.Equal(due.Add(24*time.Hour)) {
+			t.Fatalf("expected the template moved on a day, got %+v", template)
+		}
+
+		path := fmt.Sprintf("/v0/templates/%d", template.ID)
+		if status, _ := h.doError(http.MethodPost, path+"/pause", validator, nil, nil); status != http.StatusForbidden {
+			t.Fatalf("expected only the creator to pause the template, got %d", status)
+		}
+		if status := h.do(http.MethodPost, path+"/pause", submitter, nil, nil); status != http.StatusOK {
+			t.Fatalf("pause template: status %d", status)
+		}
+		if created, err := templates.RunDue(ctx, db, setup.EconomicsConfig, template.NextRunAt.Add(time.Minute)); err != nil || created != 0 {
+			t.Fatalf("expected a paused template to create nothing, got %d (%v)", created, err)
+		}
+
+		var detail struct {
+			Template models.MarketTemplate `json:"template"`
+			Markets  []models.Market       `json:"markets"`
+		}
+		if status := h.do(http.MethodGet, path, nil, nil, &detail); status != http.StatusOK {
+			t.Fatalf("get template: status %d", status)
+		}
+		if detail.Template.Status != models.TemplatePaused || len(detail.Markets) != 1 || detail.Markets[0].ID != market.ID {
+			t.Fatalf("unexpected template detail %+v", detail)
+		}
+
+		// A daily template's creator can open its next market now, skipping
+		// the council, once the template is active and its last market closed
+		if status, _ := h.doError(http.MethodPost, path+"/markets", submitter, nil, nil); status != http.StatusConflict {
+			t.Fatalf("expected a paused template to refuse a market, got %d", status)
+		}
+		if status := h.do(http.MethodPost, path+"/resume", submitter, nil, nil); status != http.StatusOK {
+			t.Fatalf("resume template: status %d", status)
+		}
+		if status, _ := h.doError(http.MethodPost, path+"/markets", submitter, nil, nil); status != http.StatusConflict {
+			t.Fatalf("expected an open market to refuse another, got %d", status)
+		}
+		db.Model(&models.Market{}).Where("id = ?", market.ID).Update("is_resolved", true)
+		if status, _ := h.doError(http.MethodPost, path+"/markets", validator, nil, nil); status != http.StatusForbidden {
+			t.Fatalf("expected only the creator to open a market, got %d", status)
+		}
+		var opened struct {
+			Market models.Market `json:"market"`
+		}
+		if status := h.do(http.MethodPost, path+"/markets", submitter, nil, &opened); status != http.StatusCreated {
+			t.Fatalf("create market now: status %d", status)
+		}
+		if opened.Market.TemplateID == nil || *opened.Market.TemplateID != template.ID || opened.Market.MarketType != models.MarketTypeDaily {
+			t.Fatalf("unexpected on-demand market %+v", opened.Market)
+		}
+		if opened.Market.PredictionLockHours < 1 {
+			t.Fatalf("expected a daily market to lock at least an hour early, got %v hours", opened.Market.PredictionLockHours)
+		}
+		var submissions int64
+		db.Model(&models.PendingSubmission{}).Count(&submissions)
+		if submissions != 1 {
+			t.Fatalf("expected no new council submission, got %d submissions", submissions)
+		}
+	})
+}
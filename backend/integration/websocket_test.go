@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	eventshandlers "socialpredict/handlers/events"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+// A prediction made through the API reaches a WebSocket subscribed to its
+// market through the router's policy stack.
+func TestWebSocket_SubscribedMarketSeesPredictions(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		h := newHarness(t, db)
+		server := httptest.NewServer(h.router)
+		t.Cleanup(server.Close)
+		watcher := h.createAgent("watcher")
+		predictor := h.createAgent("mover")
+		market := h.createMarket("Will the socket see this?")
+
+		config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/v0/ws", server.URL)
+		if err != nil {
+			t.Fatalf("config: %v", err)
+		}
+		config.Header.Set("X-Agent-API-Key", watcher.APIKey)
+		conn, err := websocket.DialConfig(config)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+		receive := func() eventshandlers.WSMessage {
+			t.Helper()
+			var msg eventshandlers.WSMessage
+			if err := websocket.JSON.Receive(conn, &msg); err != nil {
+				t.Fatalf("receive: %v", err)
+			}
+			return msg
+		}
+
+		if err := websocket.JSON.Send(conn, eventshandlers.WSRequest{Type: "subscribe", Market: market.ID}); err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+		if msg := receive(); msg.Type != eventshandlers.WSSubscribed {
+			t.Fatalf("subscribe: got %+v", msg)
+		}
+
+		body := models.PredictionRequest{MarketID: market.ID, Outcome: "YES", Confidence: 90}
+		if status := h.do(http.MethodPost, "/v0/predict", predictor, body, nil); status != http.StatusCreated {
+			t.Fatalf("predict: status %d", status)
+		}
+		if msg := receive(); msg.Type != eventshandlers.WSPrediction || msg.Agent != predictor.ID {
+			t.Fatalf("expected the prediction, got %+v", msg)
+		}
+		if msg := receive(); msg.Type != eventshandlers.WSConsensus || msg.Consensus == nil || *msg.Consensus <= 0.5 || msg.Predictions != 1 {
+			t.Fatalf("expected the consensus to move towards YES, got %+v", msg)
+		}
+	})
+}
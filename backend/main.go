@@ -1,16 +1,37 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
+	"socialpredict/grpcserver"
+	adminhandlers "socialpredict/handlers/admin"
+	governancehandlers "socialpredict/handlers/governance"
+	verificationhandlers "socialpredict/handlers/verification"
 	"socialpredict/middleware"
 	"socialpredict/migration"
 	_ "socialpredict/migration/migrations" // <-- side-effect import: registers migrations via init()
-	"socialpredict/models"
-	"socialpredict/handlers/verification"
+	"socialpredict/notifications"
+	"socialpredict/outbox"
+	"socialpredict/scheduler"
 	"socialpredict/seed"
 	"socialpredict/server"
+	"socialpredict/services/achievements"
+	"socialpredict/services/adminjobs"
+	"socialpredict/services/agentdata"
+	"socialpredict/services/auction"
+	"socialpredict/services/autoresolve"
+	"socialpredict/services/contentsafety"
+	"socialpredict/services/correlation"
+	"socialpredict/services/leaderboard"
+	"socialpredict/services/resolution"
+	"socialpredict/services/scoring"
+	"socialpredict/services/templates"
+	"socialpredict/services/trending"
+	"socialpredict/setup"
 	"socialpredict/util"
 )
 
@@ -31,33 +52,171 @@ func main() {
 		log.Fatalf("database readiness check failed: %v", err)
 	}
 
+	// `migrate status|up|rollback` manages the schema and exits instead of
+	// serving.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migration.Command(db, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
 	if err := migration.MigrateDB(db); err != nil {
 		log.Printf("migration: warning: %v", err)
 	}
 
-	// Explicit AutoMigrate for new models (ensures tables exist)
-	if err := db.AutoMigrate(
-		&models.Prediction{},
-		&models.PredictionVote{},
-		&models.PredictionComment{},
-		&models.AgentFollow{},
-	); err != nil {
-		log.Printf("auto-migrate new models: warning: %v", err)
+	seed.SeedUsers(db)
+	if err := seed.SeedHomepage(db, "."); err != nil {
+		log.Printf("seed homepage: warning: %v", err)
 	}
 
-	// AutoMigrate verification system tables
-	if err := db.AutoMigrate(
-		&verification.PendingSubmission{},
-	); err != nil {
-		log.Printf("auto-migrate verification models: warning: %v", err)
+	// `seed [-agents N ...]` fills a development database with fixtures and
+	// exits instead of serving.
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := seed.Command(db, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("seed: %v", err)
+		}
+		return
 	}
 
-	seed.SeedUsers(db)
-	if err := seed.SeedHomepage(db, "."); err != nil {
-		log.Printf("seed homepage: warning: %v", err)
+	// Ask the external content classifier alongside the built-in patterns,
+	// if one is configured.
+	if url := setup.EconomicsConfig().ContentSafety.ClassifierURL; url != "" {
+		contentsafety.Use(contentsafety.NewHTTPClassifier(url))
+	}
+
+	// Publish outbox events in the background. The bus wakes long-polling
+	// clients, notifications go on to agent webhooks, and LogPublisher stands
+	// in for the other topics until an event consumer is wired up.
+	bus := outbox.NewBus(notifications.NewWebhookPublisher(db, outbox.LogPublisher{}))
+	go outbox.NewRelay(db, bus).Run(context.Background())
+
+	// Periodic maintenance. Each job runs on one instance at a time.
+	reminder := notifications.NewReminder(db)
+	jobs := scheduler.New(db)
+	jobs.Every("expire-submissions", time.Minute, func(ctx context.Context) error {
+		_, err := verificationhandlers.ProcessExpiredSubmissions(ctx, db)
+		return err
+	})
+	// Create the markets and predictions of approved submissions, retrying
+	// those whose first attempt failed.
+	jobs.Every("submission-jobs", 30*time.Second, func(ctx context.Context) error {
+		_, err := verificationhandlers.RunSubmissionJobs(ctx, db, time.Now())
+		return err
+	})
+	// Ask the council to resolve markets past their resolution date, and
+	// settle resolution votes whose voting period has ended.
+	jobs.Every("council-resolutions", 5*time.Minute, func(ctx context.Context) error {
+		if _, err := verificationhandlers.OpenResolutionRequests(ctx, db, time.Now()); err != nil {
+			return err
+		}
+		_, err := verificationhandlers.ProcessExpiredResolutions(ctx, db)
+		return err
+	})
+	// Judge council votes on decided submissions, reactivating validators
+	// who re-qualified, then deactivate validators who stopped voting.
+	jobs.Every("validator-activity", time.Hour, func(ctx context.Context) error {
+		if _, err := verificationhandlers.ReconcileCouncilVotes(ctx, db); err != nil {
+			return err
+		}
+		_, err := verificationhandlers.DeactivateInactiveValidators(ctx, db, time.Now())
+		return err
+	})
+	jobs.Every("close-proposals", 5*time.Minute, func(ctx context.Context) error {
+		_, err := governancehandlers.CloseExpiredProposals(ctx, db)
+		return err
+	})
+	jobs.Add(scheduler.Job{Name: "recalculate-scores", Interval: time.Hour, Timeout: 30 * time.Minute, Run: func(ctx context.Context) error {
+		_, _, err := scoring.RecomputeAll(ctx, db)
+		return err
+	}})
+	jobs.Every("reveal-closing-auctions", time.Minute, func(ctx context.Context) error {
+		_, err := auction.RevealDue(ctx, db, time.Now())
+		return err
+	})
+	// Resolve auto-resolving markets from their oracles once they close.
+	resolver := autoresolve.NewResolver(db)
+	jobs.Every("auto-resolve-markets", 5*time.Minute, func(ctx context.Context) error {
+		_, err := resolver.RunDue(ctx, time.Now())
+		return err
+	})
+	// Create the markets of approved templates as their schedules come round.
+	jobs.Every("market-templates", time.Minute, func(ctx context.Context) error {
+		_, err := templates.RunDue(ctx, db, setup.EconomicsConfig, time.Now())
+		return err
+	})
+	// Resolve N/A the daily and realtime markets nobody resolved in time.
+	jobs.Every("expire-markets", 5*time.Minute, func(ctx context.Context) error {
+		_, err := resolution.ExpireDue(ctx, db, setup.EconomicsConfig, time.Now())
+		return err
+	})
+	// Snapshot open-market consensus and correlate the series.
+	jobs.Every("market-correlations", time.Hour, func(ctx context.Context) error {
+		_, err := correlation.Run(ctx, db, time.Now())
+		return err
+	})
+	// Rank agents on their recently resolved predictions for the weekly,
+	// monthly and quarterly leaderboards.
+	jobs.Every("leaderboard-snapshots", time.Hour, func(ctx context.Context) error {
+		_, err := leaderboard.Snapshot(ctx, db, time.Now())
+		return err
+	})
+	// Materialize the all-time ranking once there are too many agents to
+	// rank on every leaderboard request.
+	jobs.Every("agent-ranking", 5*time.Minute, func(ctx context.Context) error {
+		_, err := leaderboard.RefreshRanking(ctx, db, time.Now())
+		return err
+	})
+	// Award badges for streaks, approved markets, prediction counts and the
+	// weekly top ten as the events earning them come in.
+	jobs.Every("achievements", time.Minute, func(ctx context.Context) error {
+		_, err := achievements.Evaluate(ctx, db, time.Now())
+		return err
+	})
+	// Rank the open markets drawing the most swarm activity.
+	jobs.Every("trending-markets", 10*time.Minute, func(ctx context.Context) error {
+		_, err := trending.Compute(ctx, db, time.Now())
+		return err
+	})
+	// Warn agents about markets closing soon.
+	jobs.Every("market-reminders", reminder.Interval, func(ctx context.Context) error {
+		_, err := reminder.RemindOnce(ctx)
+		return err
+	})
+	// Forget Idempotency-Key responses once they can no longer be replayed.
+	jobs.Every("prune-idempotency-keys", time.Hour, func(ctx context.Context) error {
+		_, err := middleware.PruneIdempotencyRecords(ctx, db, time.Now())
+		return err
+	})
+	// Recount followers and following where the stored counters drifted.
+	jobs.Every("reconcile-follows", time.Hour, func(ctx context.Context) error {
+		_, err := scoring.ReconcileFollows(ctx, db)
+		return err
+	})
+	// Purge markets and agents deleted longer ago than the retention period.
+	jobs.Every("purge-deleted", time.Hour, func(ctx context.Context) error {
+		_, err := adminhandlers.PurgeDeleted(ctx, db, time.Now())
+		return err
+	})
+	// Erase the agents' personal data whose erasure's grace period is over.
+	jobs.Every("erase-agents", time.Hour, func(ctx context.Context) error {
+		_, err := agentdata.EraseDue(ctx, db, time.Now())
+		return err
+	})
+	// Run queued admin jobs, such as score recalculations started from the
+	// admin API.
+	jobs.Add(scheduler.Job{Name: "admin-jobs", Interval: 5 * time.Second, Timeout: time.Hour, Run: func(ctx context.Context) error {
+		_, err := adminjobs.RunPending(ctx, db)
+		return err
+	}})
+	go jobs.Run(context.Background())
+
+	// Serve the gRPC API alongside REST when GRPC_PORT is set.
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		go grpcserver.Start(port, db, bus)
 	}
 
-	server.Start()
+	server.Start(bus)
 }
 
 func secureEndpoint(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"socialpredict/audit"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// Audit makes sure every change made through an admin route is in the
+// audit log. Handlers record what they changed with audit.Record on a
+// handle carrying the request's context; for a successful request that
+// recorded nothing, an entry naming the route with the request body is
+// written instead. Reads are not logged.
+func Audit(db *gorm.DB) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx, tracker := audit.WithTracker(r.Context())
+			rec := &capturingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			if tracker.Recorded() || rec.status >= http.StatusBadRequest {
+				return
+			}
+
+			var after interface{}
+			if len(body) > 0 && json.Valid(body) {
+				after = json.RawMessage(body)
+			}
+			if err := audit.Record(db.WithContext(ctx), r.Method+" "+routeTemplate(r), r.URL.Path, nil, after); err != nil {
+				log.Printf("audit: %v", err)
+			}
+		})
+	}
+}
+
+// routeTemplate returns the path template of the route r matched, or its
+// path if it matched none.
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.Path
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"socialpredict/models"
+	"socialpredict/response"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -34,10 +35,15 @@ func ValidateUserAndEnforcePasswordChangeGetUser(r *http.Request, db *gorm.DB) (
 }
 
 // ValidateTokenAndGetUser checks that the user is who they claim to be, and returns their information for use
+// Behind a policy that already authenticated the user it returns that user.
 func ValidateTokenAndGetUser(r *http.Request, db *gorm.DB) (*models.User, *HTTPError) {
+	if p := PrincipalFromContext(r.Context()); p != nil && p.User != nil {
+		return p.User, nil
+	}
+
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		return nil, &HTTPError{StatusCode: http.StatusUnauthorized, Message: "Authorization header is required"}
+		return nil, &HTTPError{StatusCode: http.StatusUnauthorized, Message: "Authorization header is required", Code: response.CodeAuthRequired}
 	}
 
 	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
@@ -45,18 +51,18 @@ func ValidateTokenAndGetUser(r *http.Request, db *gorm.DB) (*models.User, *HTTPE
 		return getJWTKey(), nil
 	})
 	if err != nil {
-		return nil, &HTTPError{StatusCode: http.StatusUnauthorized, Message: "Invalid token"}
+		return nil, &HTTPError{StatusCode: http.StatusUnauthorized, Message: "Invalid token", Code: response.CodeInvalidToken}
 	}
 
 	if claims, ok := token.Claims.(*UserClaims); ok && token.Valid {
 		var user models.User
 		result := db.Where("username = ?", claims.Username).First(&user)
 		if result.Error != nil {
-			return nil, &HTTPError{StatusCode: http.StatusNotFound, Message: "User not found"}
+			return nil, &HTTPError{StatusCode: http.StatusNotFound, Message: "User not found", Code: response.CodeNotFound}
 		}
 		return &user, nil
 	}
-	return nil, &HTTPError{StatusCode: http.StatusUnauthorized, Message: "Invalid token"}
+	return nil, &HTTPError{StatusCode: http.StatusUnauthorized, Message: "Invalid token", Code: response.CodeInvalidToken}
 }
 
 // CheckMustChangePasswordFlag checks if the user needs to change their password
@@ -65,6 +71,7 @@ func CheckMustChangePasswordFlag(user *models.User) *HTTPError {
 		return &HTTPError{
 			StatusCode: http.StatusForbidden,
 			Message:    "Password change required",
+			Code:       response.CodePasswordChange,
 		}
 	}
 	return nil
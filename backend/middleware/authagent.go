@@ -1,9 +1,13 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"socialpredict/models"
+	"socialpredict/repository"
+	"socialpredict/response"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,64 +18,126 @@ type AgentHTTPError struct {
 	Message    string
 }
 
-// ValidateAgentAPIKey validates an agent's API key and returns the agent
-func ValidateAgentAPIKey(r *http.Request, db *gorm.DB) (*models.Agent, *HTTPError) {
+// AgentAPIKeyFromRequest returns the agent API key presented with r, or ""
+// if there is none.
+func AgentAPIKeyFromRequest(r *http.Request) string {
+	return AgentAPIKeyFromHeader(r.Header)
+}
+
+// AgentAPIKeyFromHeader returns the agent API key in h, or "" if there is
+// none.
+func AgentAPIKeyFromHeader(h http.Header) string {
 	// Try X-Agent-API-Key header first
-	apiKey := r.Header.Get("X-Agent-API-Key")
+	apiKey := h.Get("X-Agent-API-Key")
 
 	// Fallback to Authorization header with "Agent" prefix
 	if apiKey == "" {
-		authHeader := r.Header.Get("Authorization")
+		authHeader := h.Get("Authorization")
 		if strings.HasPrefix(authHeader, "Agent ") {
 			apiKey = strings.TrimPrefix(authHeader, "Agent ")
-		} else if strings.HasPrefix(authHeader, "Bearer swarm_sk_") {
+		} else if strings.HasPrefix(authHeader, "Bearer "+models.AgentAPIKeyPrefix) {
 			apiKey = strings.TrimPrefix(authHeader, "Bearer ")
 		}
 	}
+	return apiKey
+}
 
+// ValidateAgentAPIKey validates an agent's API key and returns the agent.
+// Behind a policy that already authenticated the agent it returns that
+// agent without another lookup.
+func ValidateAgentAPIKey(r *http.Request, db *gorm.DB) (*models.Agent, *HTTPError) {
+	if p := PrincipalFromContext(r.Context()); p != nil && p.Agent != nil {
+		return p.Agent, nil
+	}
+	agent, _, httpErr := authenticateAgent(r, db)
+	return agent, httpErr
+}
+
+// AuthenticateAgentKey authenticates an agent API key presented outside an
+// HTTP request, such as in gRPC metadata, making the same checks as the
+// agent policies except that the agent need not be claimed.
+func AuthenticateAgentKey(apiKey string, db *gorm.DB) (*Principal, *HTTPError) {
+	agent, key, httpErr := authenticateAgentKey(apiKey, db)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	return &Principal{Tier: TierAgent, Agent: agent, AgentKey: key}, nil
+}
+
+// authenticateAgent looks up the agent API key presented with r, returning
+// the agent and its key row (nil for a legacy key).
+func authenticateAgent(r *http.Request, db *gorm.DB) (*models.Agent, *models.AgentAPIKey, *HTTPError) {
+	return authenticateAgentKey(AgentAPIKeyFromRequest(r), db)
+}
+
+func authenticateAgentKey(apiKey string, db *gorm.DB) (*models.Agent, *models.AgentAPIKey, *HTTPError) {
 	if apiKey == "" {
-		return nil, &HTTPError{
+		return nil, nil, &HTTPError{
 			StatusCode: http.StatusUnauthorized,
 			Message:    "Agent API key required. Use X-Agent-API-Key header or 'Agent <key>' in Authorization header",
+			Code:       response.CodeAuthRequired,
 		}
 	}
 
 	// Validate API key format
-	if !strings.HasPrefix(apiKey, "swarm_sk_") {
-		return nil, &HTTPError{
+	if !strings.HasPrefix(apiKey, models.AgentAPIKeyPrefix) {
+		return nil, nil, &HTTPError{
 			StatusCode: http.StatusUnauthorized,
 			Message:    "Invalid API key format",
+			Code:       response.CodeInvalidAPIKey,
 		}
 	}
 
 	// Look up agent in database
-	var agent models.Agent
-	result := db.Where("api_key = ?", apiKey).First(&agent)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return nil, &HTTPError{
+	agent, key, err := repository.NewGormAgentRepo(db).Authenticate(apiKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, &HTTPError{
 				StatusCode: http.StatusUnauthorized,
 				Message:    "Invalid agent API key",
+				Code:       response.CodeInvalidAPIKey,
 			}
 		}
-		return nil, &HTTPError{
+		if errors.Is(err, repository.ErrAPIKeyExpired) {
+			return nil, nil, &HTTPError{
+				StatusCode: http.StatusUnauthorized,
+				Message:    "Agent API key has expired",
+				Code:       response.CodeAPIKeyExpired,
+			}
+		}
+		return nil, nil, &HTTPError{
 			StatusCode: http.StatusInternalServerError,
 			Message:    "Database error validating agent",
+			Code:       response.CodeInternal,
 		}
 	}
 
 	// Check if agent is active
 	if !agent.IsActive {
-		return nil, &HTTPError{
+		return nil, nil, &HTTPError{
 			StatusCode: http.StatusForbidden,
 			Message:    "Agent account is deactivated",
+			Code:       response.CodeAgentDeactivated,
 		}
 	}
 
+	// Check if a moderator has suspended or banned the agent
+	suspension, err := models.ActiveSuspension(db, agent.ID, time.Now())
+	if err != nil {
+		return nil, nil, &HTTPError{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Database error validating agent",
+			Code:       response.CodeInternal,
+		}
+	}
+	if suspension != nil {
+		return nil, nil, suspendedError(suspension)
+	}
+
 	// Check if agent is claimed (required for betting, optional for status checks)
 	// This check can be enforced at the handler level if needed
 
-	return &agent, nil
+	return agent, key, nil
 }
 
 // ValidateClaimedAgent validates that an agent is both authenticated and claimed
@@ -85,6 +151,7 @@ func ValidateClaimedAgent(r *http.Request, db *gorm.DB) (*models.Agent, *HTTPErr
 		return nil, &HTTPError{
 			StatusCode: http.StatusForbidden,
 			Message:    "Agent must be claimed by a human owner before participating in markets",
+			Code:       response.CodeAgentNotClaimed,
 		}
 	}
 
@@ -94,12 +161,12 @@ func ValidateClaimedAgent(r *http.Request, db *gorm.DB) (*models.Agent, *HTTPErr
 // ValidateAgentOrUser attempts to validate as agent first, then falls back to user
 // Returns agent, user, and error - one of agent/user will be non-nil on success
 func ValidateAgentOrUser(r *http.Request, db *gorm.DB) (*models.Agent, *models.User, *HTTPError) {
-	// Check for agent API key first
-	agentKey := r.Header.Get("X-Agent-API-Key")
-	authHeader := r.Header.Get("Authorization")
+	if p := PrincipalFromContext(r.Context()); p != nil && (p.Agent != nil || p.User != nil) {
+		return p.Agent, p.User, nil
+	}
 
 	// If it looks like an agent request, validate as agent
-	if agentKey != "" || strings.HasPrefix(authHeader, "Agent ") || strings.Contains(authHeader, "swarm_sk_") {
+	if looksLikeAgentRequest(r) {
 		agent, httpErr := ValidateAgentAPIKey(r, db)
 		if httpErr != nil {
 			return nil, nil, httpErr
@@ -118,10 +185,27 @@ func ValidateAgentOrUser(r *http.Request, db *gorm.DB) (*models.Agent, *models.U
 // GetAgentFromContext is a helper to extract agent from request context
 // (for use after middleware has validated the agent)
 func GetAgentFromAPIKey(apiKey string, db *gorm.DB) (*models.Agent, error) {
-	var agent models.Agent
-	result := db.Where("api_key = ?", apiKey).First(&agent)
-	if result.Error != nil {
-		return nil, result.Error
+	return repository.NewGormAgentRepo(db).GetByAPIKey(apiKey)
+}
+
+// SuspensionDetails are the details of an AGENT_SUSPENDED error.
+type SuspensionDetails struct {
+	Kind   string     `json:"kind"`
+	Reason string     `json:"reason"`
+	Until  *time.Time `json:"until,omitempty"` // absent for a ban
+}
+
+// suspendedError is the error an agent under suspension gets, saying until
+// when and why.
+func suspendedError(s *models.AgentSuspension) *HTTPError {
+	message := "Agent is banned for: " + s.Reason
+	if !s.IsBan() {
+		message = "Agent is suspended until " + s.ExpiresAt.UTC().Format(time.RFC3339) + " for: " + s.Reason
+	}
+	return &HTTPError{
+		StatusCode: http.StatusForbidden,
+		Message:    message,
+		Code:       response.CodeAgentSuspended,
+		Details:    SuspensionDetails{Kind: s.Kind, Reason: s.Reason, Until: s.ExpiresAt},
 	}
-	return &agent, nil
 }
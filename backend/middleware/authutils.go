@@ -5,12 +5,18 @@ import (
 	"net/http"
 	"strings"
 
+	"socialpredict/response"
+
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// HTTPError is an authentication or authorization failure, with the status
+// and error code to respond with.
 type HTTPError struct {
 	StatusCode int
 	Message    string
+	Code       response.Code
+	Details    interface{} // optional, see response.ErrorWithDetails
 }
 
 func (e *HTTPError) Error() string {
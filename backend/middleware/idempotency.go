@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/security"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for a retryable write.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored
+	// record.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// IdempotencyTTL is how long a key's response is kept for replay.
+	IdempotencyTTL = 24 * time.Hour
+
+	maxIdempotencyKeyLength = 100
+)
+
+// Idempotency makes a write safe to retry. A request carrying an
+// Idempotency-Key is run once per caller and key; a retry with the same
+// method, path and body gets the stored response back, one that differs is
+// refused with 422, and one that arrives while the first is still running
+// gets 409. Server errors are not stored, so they can be retried. Requests
+// without the header pass straight through.
+func Idempotency(db *gorm.DB) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Idempotency-Key is too long")
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			sum := sha256.Sum256(body)
+
+			principal := PrincipalFromContext(r.Context()).ID()
+			if principal == "" {
+				principal = "ip:" + security.ClientIP(r)
+			}
+			record := models.IdempotencyRecord{
+				Principal:   principal,
+				Key:         key,
+				Method:      r.Method,
+				Path:        r.URL.Path,
+				RequestHash: hex.EncodeToString(sum[:]),
+			}
+
+			claimed, existing, err := claimIdempotencyKey(db, &record)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Failed to record idempotency key")
+				return
+			}
+			if !claimed {
+				replayIdempotent(w, &record, existing)
+				return
+			}
+
+			rec := &capturingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				db.Delete(&models.IdempotencyRecord{}, record.ID)
+				return
+			}
+			db.Model(&models.IdempotencyRecord{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+				"status_code":  rec.status,
+				"content_type": rec.Header().Get("Content-Type"),
+				"body":         rec.body.Bytes(),
+			})
+		})
+	}
+}
+
+// claimIdempotencyKey stores record as in flight unless the caller already
+// used the key, in which case it returns the earlier record. An expired
+// record is replaced.
+func claimIdempotencyKey(db *gorm.DB, record *models.IdempotencyRecord) (bool, *models.IdempotencyRecord, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return false, nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return true, nil, nil
+		}
+
+		var existing models.IdempotencyRecord
+		err := db.Where(&models.IdempotencyRecord{Principal: record.Principal, Key: record.Key}).First(&existing).Error
+		if err == gorm.ErrRecordNotFound {
+			continue
+		}
+		if err != nil {
+			return false, nil, err
+		}
+		if time.Since(existing.CreatedAt) < IdempotencyTTL {
+			return false, &existing, nil
+		}
+		if err := db.Delete(&existing).Error; err != nil {
+			return false, nil, err
+		}
+		record.ID = 0
+	}
+	return false, nil, gorm.ErrRecordNotFound
+}
+
+// replayIdempotent answers a repeated key from the stored record.
+func replayIdempotent(w http.ResponseWriter, record, existing *models.IdempotencyRecord) {
+	if existing.Method != record.Method || existing.Path != record.Path || existing.RequestHash != record.RequestHash {
+		response.Error(w, http.StatusUnprocessableEntity, response.CodeIdempotencyMismatch, "Idempotency-Key was already used for a different request")
+		return
+	}
+	if existing.StatusCode == 0 {
+		response.Error(w, http.StatusConflict, response.CodeIdempotencyPending, "A request with this Idempotency-Key is still in progress")
+		return
+	}
+	if existing.ContentType != "" {
+		w.Header().Set("Content-Type", existing.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(existing.StatusCode)
+	w.Write(existing.Body)
+}
+
+// capturingWriter passes a response through while keeping a copy.
+type capturingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (c *capturingWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status = status
+		c.wroteHeader = true
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *capturingWriter) Write(b []byte) (int, error) {
+	c.wroteHeader = true
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// PruneIdempotencyRecords deletes records older than IdempotencyTTL and
+// returns how many were removed.
+func PruneIdempotencyRecords(ctx context.Context, db *gorm.DB, now time.Time) (int64, error) {
+	result := db.WithContext(ctx).Where("created_at < ?", now.Add(-IdempotencyTTL)).Delete(&models.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"socialpredict/models"
+	"socialpredict/response"
 	"socialpredict/security"
 	"socialpredict/util"
 	"time"
@@ -27,7 +28,7 @@ type UserClaims struct {
 
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method is not supported.", http.StatusNotFound)
+		response.Error(w, http.StatusNotFound, response.CodeNotFound, "Method is not supported.")
 		return
 	}
 
@@ -43,20 +44,20 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	var req loginRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Error reading request body")
 		return
 	}
 
 	// Validate and sanitize login input
 	if err := securityService.Validator.ValidateStruct(req); err != nil {
-		http.Error(w, "Invalid input: "+err.Error(), http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid input: "+err.Error())
 		return
 	}
 
 	// Sanitize username (basic sanitization for login)
 	sanitizedUsername, err := securityService.Sanitizer.SanitizeUsername(req.Username)
 	if err != nil {
-		http.Error(w, "Invalid username format", http.StatusBadRequest)
+		response.Error(w, http.StatusBadRequest, response.CodeBadRequest, "Invalid username format")
 		return
 	}
 	req.Username = sanitizedUsername
@@ -69,16 +70,16 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	result := db.Where("username = ?", req.Username).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			http.Error(w, "Invalid Credentials", http.StatusUnauthorized)
+			response.Error(w, http.StatusUnauthorized, response.CodeInvalidCredentials, "Invalid Credentials")
 			return
 		}
-		http.Error(w, "Error accessing database", http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error accessing database")
 		return
 	}
 
 	// Check password
 	if !user.CheckPasswordHash(req.Password) {
-		http.Error(w, "Invalid Credentials", http.StatusUnauthorized)
+		response.Error(w, http.StatusUnauthorized, response.CodeInvalidCredentials, "Invalid Credentials")
 		return
 	}
 
@@ -96,7 +97,7 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	// Sign and get the complete encoded token as a string
 	tokenString, err := token.SignedString(getJWTKey())
 	if err != nil {
-		http.Error(w, "Error creating token", http.StatusInternalServerError)
+		response.Error(w, http.StatusInternalServerError, response.CodeInternal, "Error creating token")
 		return
 	}
 
@@ -0,0 +1,316 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/security"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// AuthLevel is who a route lets in.
+type AuthLevel string
+
+const (
+	AuthNone         AuthLevel = "none"          // nothing checked; the handler authenticates, if at all
+	AuthOptional     AuthLevel = "optional"      // anonymous allowed, presented credentials must be valid
+	AuthAgent        AuthLevel = "agent"         // agent API key
+	AuthClaimedAgent AuthLevel = "claimed_agent" // agent API key of an agent a human has claimed
+	AuthUser         AuthLevel = "user"          // user token
+	AuthAgentOrUser  AuthLevel = "agent_or_user" // either of the above
+	AuthAdmin        AuthLevel = "admin"         // token of a staff user; admins, or the roles the policy names
+)
+
+// RateClass picks the rate limit a route is held to.
+type RateClass string
+
+const (
+	RateGeneral RateClass = "general" // general per-IP limit
+	RateLogin   RateClass = "login"   // stricter per-IP limit for credential guessing
+	RateRead    RateClass = "read"    // per access tier: anonymous and read keys have their own budgets; metered
+	RateExport  RateClass = "export"  // per caller limit on bulk exports; metered
+)
+
+// Policy declares what a route requires. Routes register their policy next
+// to their handler with Routes.Handle, and Stack.Wrap turns it into the
+// middleware chain that enforces it before the handler runs.
+type Policy struct {
+	Auth       AuthLevel `json:"auth"`
+	Scopes     []string  `json:"scopes,omitempty"` // agent key scopes required, see models.AgentAPIKeyScopes
+	Rate       RateClass `json:"rate"`
+	Idempotent bool      `json:"idempotent,omitempty"` // honour Idempotency-Key
+	Roles      []string  `json:"roles,omitempty"`      // staff roles let in besides admins, see models.StaffRoles
+}
+
+// Middleware wraps a handler.
+type Middleware func(http.Handler) http.Handler
+
+// Chain composes middleware so the first one listed sees the request first.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// Stack holds what the policy middleware needs.
+type Stack struct {
+	DB      *gorm.DB
+	Limits  *security.RateLimitManager
+	Headers security.SecurityHeaders
+	Meter   *ReadMeter
+}
+
+// Wrap returns next behind the middleware enforcing p: security headers,
+// then authentication, rate limiting, metering, staff roles and auditing,
+// scopes and idempotency.
+func (s *Stack) Wrap(p Policy, next http.Handler) http.Handler {
+	middlewares := []Middleware{
+		security.SecurityHeadersMiddleware(s.Headers),
+		s.authenticate(p.Auth),
+		s.rateLimit(p.Rate),
+	}
+	if p.Auth == AuthAdmin {
+		middlewares = append(middlewares, requireRoles(p.Roles), Audit(s.DB))
+	}
+	if len(p.Scopes) > 0 {
+		middlewares = append(middlewares, requireScopes(p.Scopes))
+	}
+	if p.Idempotent {
+		middlewares = append(middlewares, Idempotency(s.DB))
+	}
+	return Chain(middlewares...)(next)
+}
+
+// authenticate identifies the caller as level requires and stores the
+// principal in the request context.
+func (s *Stack) authenticate(level AuthLevel) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if level == AuthNone || level == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			principal, httpErr := authenticate(r, s.DB, level)
+			if httpErr != nil {
+				response.ErrorWithDetails(w, httpErr.StatusCode, httpErr.Code, httpErr.Message, httpErr.Details)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// authenticate checks the credentials presented with r against level.
+func authenticate(r *http.Request, db *gorm.DB, level AuthLevel) (*Principal, *HTTPError) {
+	switch level {
+	case AuthOptional:
+		return identifyReader(r, db)
+	case AuthAgent, AuthClaimedAgent:
+		return authenticateAgentPrincipal(r, db, level == AuthClaimedAgent)
+	case AuthUser, AuthAdmin:
+		user, httpErr := ValidateTokenAndGetUser(r, db)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		principal := &Principal{Tier: TierUser, User: user}
+		if level == AuthAdmin {
+			role, err := models.StaffRole(db, user)
+			if err != nil {
+				return nil, &HTTPError{StatusCode: http.StatusInternalServerError, Message: "Failed to look up staff role", Code: response.CodeInternal}
+			}
+			if role == "" {
+				return nil, &HTTPError{StatusCode: http.StatusForbidden, Message: "Admin access required", Code: response.CodeAdminRequired}
+			}
+			principal.Role = role
+		}
+		return principal, nil
+	case AuthAgentOrUser:
+		if looksLikeAgentRequest(r) {
+			return authenticateAgentPrincipal(r, db, false)
+		}
+		user, httpErr := ValidateTokenAndGetUser(r, db)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		return &Principal{Tier: TierUser, User: user}, nil
+	}
+	return nil, &HTTPError{StatusCode: http.StatusInternalServerError, Message: "Unknown route auth level", Code: response.CodeInternal}
+}
+
+func authenticateAgentPrincipal(r *http.Request, db *gorm.DB, claimed bool) (*Principal, *HTTPError) {
+	agent, key, httpErr := authenticateAgent(r, db)
+	if httpErr != nil {
+		return nil, httpErr
+	}
+	if claimed && !agent.IsClaimed {
+		return nil, &HTTPError{
+			StatusCode: http.StatusForbidden,
+			Message:    "Agent must be claimed by a human owner before participating in markets",
+			Code:       response.CodeAgentNotClaimed,
+		}
+	}
+	return &Principal{Tier: TierAgent, Agent: agent, AgentKey: key}, nil
+}
+
+// rateLimit holds the request to class's limit. Read requests are limited
+// by the tier the caller authenticated as, and metered.
+func (s *Stack) rateLimit(class RateClass) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := security.ClientIP(r)
+			principal := PrincipalFromContext(r.Context())
+
+			switch class {
+			case RateLogin:
+				if !s.Limits.AllowLogin(ip) {
+					response.Error(w, http.StatusTooManyRequests, response.CodeRateLimited, "Too many login attempts. Please try again later.")
+					return
+				}
+			case RateRead:
+				tier := TierAnonymous
+				if principal != nil {
+					tier = principal.Tier
+				}
+				var allowed bool
+				switch tier {
+				case TierReadKey:
+					allowed = s.Limits.AllowReadKey(strconv.FormatInt(principal.ReadKey.ID, 10))
+				case TierAnalytics:
+					allowed = s.Limits.AllowAnalyticsKey(strconv.FormatInt(principal.ReadKey.ID, 10))
+				case TierAnonymous:
+					allowed = s.Limits.AllowAnonymousRead(ip)
+				default:
+					allowed = s.Limits.AllowGeneral(ip)
+				}
+				if !allowed {
+					response.Error(w, http.StatusTooManyRequests, response.CodeRateLimited, "Rate limit exceeded. Please try again later.")
+					return
+				}
+				s.meter(w, principal, tier)
+			case RateExport:
+				caller := principal.ID()
+				if caller == "" {
+					caller = "ip:" + ip
+				}
+				if !s.Limits.AllowExport(caller) {
+					response.Error(w, http.StatusTooManyRequests, response.CodeRateLimited, "Export rate limit exceeded. Please try again later.")
+					return
+				}
+				tier := TierAnonymous
+				if principal != nil {
+					tier = principal.Tier
+				}
+				s.meter(w, principal, tier)
+			default:
+				if !s.Limits.AllowGeneral(ip) {
+					response.Error(w, http.StatusTooManyRequests, response.CodeRateLimited, "Rate limit exceeded. Please try again later.")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// meter counts a served read under tier, records the read key's use and
+// tells the client its tier.
+func (s *Stack) meter(w http.ResponseWriter, principal *Principal, tier AccessTier) {
+	if s.Meter != nil {
+		s.Meter.Record(tier)
+	}
+	if principal != nil && principal.ReadKey != nil {
+		s.DB.Model(&models.ReadAPIKey{}).Where("id = ?", principal.ReadKey.ID).Updates(map[string]interface{}{
+			"request_count": gorm.Expr("request_count + 1"),
+			"last_used_at":  time.Now(),
+		})
+	}
+	w.Header().Set(AccessTierHeader, string(tier))
+}
+
+// requireScopes refuses callers whose credentials lack any of scopes.
+func requireScopes(scopes []string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := PrincipalFromContext(r.Context())
+			for _, scope := range scopes {
+				if !principal.HasScope(scope) {
+					response.Error(w, http.StatusForbidden, response.CodeMissingScope, "API key lacks the "+scope+" scope")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requireRoles lets in admins and staff holding one of roles.
+func requireRoles(roles []string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := PrincipalFromContext(r.Context())
+			if principal.Role != models.RoleAdmin && !containsRole(roles, principal.Role) {
+				response.Error(w, http.StatusForbidden, response.CodeRoleRequired, "Requires one of the roles: "+strings.Join(append([]string{models.RoleAdmin}, roles...), ", "))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Routes registers handlers on a router together with their policies, and
+// remembers the policies so they can be published.
+type Routes struct {
+	router   *mux.Router
+	stack    *Stack
+	policies map[string]Policy
+}
+
+// NewRoutes registers routes on router, enforcing policies with stack.
+func NewRoutes(router *mux.Router, stack *Stack) *Routes {
+	return &Routes{router: router, stack: stack, policies: make(map[string]Policy)}
+}
+
+// Handle registers handler for method and path behind policy p.
+func (rt *Routes) Handle(method, path string, p Policy, handler http.Handler) {
+	rt.router.Handle(path, rt.stack.Wrap(p, handler)).Methods(method)
+	rt.policies[RoutePolicyKey(method, path)] = p
+}
+
+// HandleFunc is Handle for a handler function.
+func (rt *Routes) HandleFunc(method, path string, p Policy, handler http.HandlerFunc) {
+	rt.Handle(method, path, p, handler)
+}
+
+// Policies returns the policy of every route registered so far, keyed by
+// RoutePolicyKey.
+func (rt *Routes) Policies() map[string]Policy {
+	policies := make(map[string]Policy, len(rt.policies))
+	for key, p := range rt.policies {
+		policies[key] = p
+	}
+	return policies
+}
+
+// RoutePolicyKey is the key a route's policy is published under, e.g.
+// "POST /v0/predict".
+func RoutePolicyKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/security"
+
+	"golang.org/x/time/rate"
+)
+
+func newTestStack(t *testing.T) *Stack {
+	t.Helper()
+	return &Stack{
+		DB: modelstesting.NewFakeDB(t),
+		Limits: security.NewCustomRateLimitManager(security.RateLimitConfig{
+			LoginRate:          rate.Inf,
+			LoginBurst:         100,
+			GeneralRate:        rate.Inf,
+			GeneralBurst:       100,
+			CleanupInterval:    time.Minute,
+			AnonymousReadRate:  rate.Inf,
+			AnonymousReadBurst: 100,
+			ReadKeyRate:        rate.Inf,
+			ReadKeyBurst:       100,
+		}),
+		Headers: security.DefaultSecurityHeaders(),
+	}
+}
+
+func TestStackWrap_AuthLevelsAndScopes(t *testing.T) {
+	stack := newTestStack(t)
+	db := stack.DB
+
+	claimed := models.Agent{Name: "claimed", APIKey: "swarm_sk_claimed", ClaimToken: "claim_claimed", IsActive: true, IsClaimed: true}
+	unclaimed := models.Agent{Name: "unclaimed", APIKey: "swarm_sk_unclaimed", ClaimToken: "claim_unclaimed", IsActive: true}
+	scoped := models.Agent{Name: "scoped", APIKey: "swarm_sk_scoped_legacy", ClaimToken: "claim_scoped", IsActive: true, IsClaimed: true}
+	for _, agent := range []*models.Agent{&claimed, &unclaimed, &scoped} {
+		if err := db.Create(agent).Error; err != nil {
+			t.Fatalf("create agent %s: %v", agent.Name, err)
+		}
+	}
+	readOnlyKey := models.NewAgentAPIKey(scoped.ID, "dashboard", "swarm_sk_scoped_read")
+	readOnlyKey.SetScopes([]string{models.ScopeRead})
+	if err := db.Create(&readOnlyKey).Error; err != nil {
+		t.Fatalf("create scoped key: %v", err)
+	}
+
+	regular := modelstesting.GenerateUser("regular", 0)
+	if err := db.Create(&regular).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	os.Setenv("JWT_SIGNING_KEY", "test-secret-key")
+	defer os.Unsetenv("JWT_SIGNING_KEY")
+	userToken, err := createTestToken(regular.Username, "regular")
+	if err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+
+	var seen *Principal
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = PrincipalFromContext(r.Context())
+	})
+	predict := stack.Wrap(Policy{Auth: AuthClaimedAgent, Scopes: []string{models.ScopePredict}, Rate: RateGeneral}, handler)
+	admin := stack.Wrap(Policy{Auth: AuthAdmin, Rate: RateGeneral}, handler)
+
+	send := func(h http.Handler, header, value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v0/predict", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		header  string
+		value   string
+		want    int
+	}{
+		{"no credentials", predict, "", "", http.StatusUnauthorized},
+		{"unclaimed agent", predict, "X-Agent-API-Key", unclaimed.APIKey, http.StatusForbidden},
+		{"key without scope", predict, "X-Agent-API-Key", "swarm_sk_scoped_read", http.StatusForbidden},
+		{"claimed agent", predict, "X-Agent-API-Key", claimed.APIKey, http.StatusOK},
+		{"regular user on admin route", admin, "Authorization", "Bearer " + userToken, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if got := send(tt.handler, tt.header, tt.value); got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, got)
+		}
+	}
+
+	seen = nil
+	send(predict, "X-Agent-API-Key", claimed.APIKey)
+	if seen == nil || seen.Agent == nil || seen.Agent.ID != claimed.ID {
+		t.Fatalf("expected the handler to see the authenticated agent, got %+v", seen)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v0/predict", nil)
+	req = req.WithContext(WithPrincipal(req.Context(), seen))
+	if agent, httpErr := ValidateClaimedAgent(req, nil); httpErr != nil || agent.ID != claimed.ID {
+		t.Fatalf("expected ValidateClaimedAgent to reuse the principal, got %v %v", agent, httpErr)
+	}
+}
+
+func TestIdempotency_ReplaysAndRejectsMismatches(t *testing.T) {
+	stack := newTestStack(t)
+	agent := models.Agent{Name: "retrier", APIKey: "swarm_sk_retrier", ClaimToken: "claim_retrier", IsActive: true, IsClaimed: true}
+	if err := stack.DB.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	calls := 0
+	handler := stack.Wrap(Policy{Auth: AuthClaimedAgent, Rate: RateGeneral, Idempotent: true}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v0/predict", strings.NewReader(body))
+		req.Header.Set("X-Agent-API-Key", agent.APIKey)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send("abc", `1`)
+	if first.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("expected first request to run, got %d after %d calls", first.Code, calls)
+	}
+	replay := send("abc", `1`)
+	if replay.Code != http.StatusCreated || calls != 1 || replay.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected a replay without running the handler, got %d after %d calls", replay.Code, calls)
+	}
+	if replay.Body.String() != first.Body.String() || replay.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("expected the stored response %q, got %q", first.Body.String(), replay.Body.String())
+	}
+	if rec := send("abc", `2`); rec.Code != http.StatusUnprocessableEntity || calls != 1 {
+		t.Fatalf("expected a different body under the same key to be refused, got %d", rec.Code)
+	}
+	if rec := send("", `1`); rec.Code != http.StatusCreated || calls != 2 {
+		t.Fatalf("expected requests without a key to run, got %d after %d calls", rec.Code, calls)
+	}
+
+	stack.DB.Model(&models.IdempotencyRecord{}).Where("1 = 1").Update("created_at", time.Now().Add(-2*IdempotencyTTL))
+	if pruned, err := PruneIdempotencyRecords(t.Context(), stack.DB, time.Now()); err != nil || pruned != 1 {
+		t.Fatalf("expected one expired record pruned, got %d, %v", pruned, err)
+	}
+	if rec := send("abc", `2`); rec.Code != http.StatusCreated || calls != 3 {
+		t.Fatalf("expected a pruned key to be usable again, got %d after %d calls", rec.Code, calls)
+	}
+}
+
+func TestStackWrap_ExportLimitPerCaller(t *testing.T) {
+	stack := newTestStack(t)
+	stack.Limits = security.NewCustomRateLimitManager(security.RateLimitConfig{
+		GeneralRate:     rate.Inf,
+		GeneralBurst:    100,
+		CleanupInterval: time.Minute,
+		ExportRate:      rate.Every(time.Hour),
+		ExportBurst:     1,
+	})
+	agent := models.Agent{Name: "researcher", APIKey: "swarm_sk_researcher", ClaimToken: "claim_researcher", IsActive: true, IsClaimed: true}
+	if err := stack.DB.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	export := stack.Wrap(Policy{Auth: AuthOptional, Rate: RateExport}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(ip, apiKey string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v0/export/predictions", nil)
+		req.RemoteAddr = ip
+		if apiKey != "" {
+			req.Header.Set("X-Agent-API-Key", apiKey)
+		}
+		rec := httptest.NewRecorder()
+		export.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send("10.0.0.1:1", ""); code != http.StatusOK {
+		t.Fatalf("expected the first export to pass, got %d", code)
+	}
+	if code := send("10.0.0.1:1", ""); code != http.StatusTooManyRequests {
+		t.Fatalf("expected a second export from the same IP to be limited, got %d", code)
+	}
+	if code := send("10.0.0.1:1", agent.APIKey); code != http.StatusOK {
+		t.Fatalf("expected an agent to have its own export budget, got %d", code)
+	}
+	if code := send("10.0.0.2:1", ""); code != http.StatusOK {
+		t.Fatalf("expected another IP to have its own export budget, got %d", code)
+	}
+}
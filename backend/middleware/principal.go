@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"socialpredict/audit"
+	"socialpredict/models"
+)
+
+// Principal is who a request was authenticated as. The policy chain stores
+// it in the request context; the Validate helpers return it from there
+// instead of authenticating again.
+type Principal struct {
+	Tier     AccessTier
+	Agent    *models.Agent
+	AgentKey *models.AgentAPIKey // nil for a legacy agent key
+	User     *models.User
+	Role     string // the user's staff role, on admin routes
+	ReadKey  *models.ReadAPIKey
+}
+
+// ID identifies the principal for per-caller state such as idempotency
+// keys, e.g. "agent:12". It is "" for anonymous callers.
+func (p *Principal) ID() string {
+	switch {
+	case p == nil:
+		return ""
+	case p.Agent != nil:
+		return "agent:" + strconv.FormatInt(p.Agent.ID, 10)
+	case p.User != nil:
+		return "user:" + strconv.FormatInt(p.User.ID, 10)
+	case p.ReadKey != nil:
+		return "read_key:" + strconv.FormatInt(p.ReadKey.ID, 10)
+	}
+	return ""
+}
+
+// AgentID returns the ID of the calling agent, or 0 if the principal is not
+// an agent.
+func (p *Principal) AgentID() int64 {
+	if p == nil || p.Agent == nil {
+		return 0
+	}
+	return p.Agent.ID
+}
+
+// HasScope reports whether the principal's credentials cover scope. Agent
+// keys carry their own scopes, read-only keys and anonymous callers only
+// have models.ScopeRead, and users have every scope.
+func (p *Principal) HasScope(scope string) bool {
+	switch {
+	case p == nil:
+		return scope == models.ScopeRead
+	case p.Agent != nil:
+		return p.AgentKey == nil || p.AgentKey.HasScope(scope)
+	case p.User != nil:
+		return true
+	}
+	return scope == models.ScopeRead
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying p, which is also the actor of any
+// change audited under ctx.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	if id := p.ID(); id != "" {
+		ctx = audit.WithActor(ctx, audit.Actor{ID: id, Name: p.name(), Role: p.Role})
+	}
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// name returns the agent's name or the user's username.
+func (p *Principal) name() string {
+	switch {
+	case p.Agent != nil:
+		return p.Agent.Name
+	case p.User != nil:
+		return p.User.Username
+	}
+	return ""
+}
+
+// PrincipalFromContext returns the principal the policy chain authenticated,
+// or nil if no policy ran.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// looksLikeAgentRequest reports whether r presents an agent API key rather
+// than a user token.
+func looksLikeAgentRequest(r *http.Request) bool {
+	authHeader := r.Header.Get("Authorization")
+	return r.Header.Get("X-Agent-API-Key") != "" || strings.HasPrefix(authHeader, "Agent ") || strings.Contains(authHeader, models.AgentAPIKeyPrefix)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"socialpredict/models"
+	"socialpredict/response"
+	"socialpredict/security"
+
+	"gorm.io/gorm"
+)
+
+// AccessTier says how a request to a public read endpoint identified itself.
+// Each tier has its own rate limit and is metered separately.
+type AccessTier string
+
+const (
+	TierAnonymous AccessTier = "anonymous" // no credentials, strictest limit, keyed by IP
+	TierReadKey   AccessTier = "read_key"  // read-only key minted by an agent owner, keyed by key
+	TierAnalytics AccessTier = "analytics" // read-only key an operator marked for analytics, keyed by key
+	TierAgent     AccessTier = "agent"     // full agent API key, general limit
+	TierUser      AccessTier = "user"      // logged-in human, general limit
+)
+
+// Read access headers.
+const (
+	ReadAPIKeyHeader = "X-Read-API-Key"
+	AccessTierHeader = "X-Access-Tier"
+)
+
+// AccessTierFromContext returns the tier the caller authenticated as, or
+// TierAnonymous if no policy ran.
+func AccessTierFromContext(ctx context.Context) AccessTier {
+	if p := PrincipalFromContext(ctx); p != nil {
+		return p.Tier
+	}
+	return TierAnonymous
+}
+
+// ReadMeter counts public read requests per tier. Per-key usage is also
+// stored on the ReadAPIKey row.
+type ReadMeter struct {
+	mu     sync.Mutex
+	counts map[AccessTier]int64
+}
+
+func NewReadMeter() *ReadMeter {
+	return &ReadMeter{counts: make(map[AccessTier]int64)}
+}
+
+// Record counts one request served under tier.
+func (m *ReadMeter) Record(tier AccessTier) {
+	m.mu.Lock()
+	m.counts[tier]++
+	m.mu.Unlock()
+}
+
+// Snapshot returns the request counts per tier since startup.
+func (m *ReadMeter) Snapshot() map[AccessTier]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snapshot := make(map[AccessTier]int64, len(m.counts))
+	for tier, count := range m.counts {
+		snapshot[tier] = count
+	}
+	return snapshot
+}
+
+// ReadAccess is the middleware for public read endpoints such as consensus
+// and leaderboards: the AuthOptional, RateRead part of a route policy.
+// Callers may be anonymous or present a read-only key, an agent key or a
+// user token; presented credentials must be valid. The request is rate
+// limited for its tier, metered, and the tier is exposed to the handler
+// through the context and to the client in X-Access-Tier.
+func ReadAccess(db *gorm.DB, limits *security.RateLimitManager, meter *ReadMeter) func(http.Handler) http.Handler {
+	s := &Stack{DB: db, Limits: limits, Meter: meter}
+	return Chain(s.authenticate(AuthOptional), s.rateLimit(RateRead))
+}
+
+// identifyReader works out the caller's tier from its credentials, checking
+// whichever one was presented.
+func identifyReader(r *http.Request, db *gorm.DB) (*Principal, *HTTPError) {
+	authHeader := r.Header.Get("Authorization")
+
+	readKey := r.Header.Get(ReadAPIKeyHeader)
+	if readKey == "" && strings.HasPrefix(authHeader, "Bearer "+models.ReadAPIKeyPrefix) {
+		readKey = strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	if readKey != "" {
+		var key models.ReadAPIKey
+		if err := db.Where("key_hash = ? AND revoked_at IS NULL", models.HashReadAPIKey(readKey)).First(&key).Error; err != nil {
+			return nil, &HTTPError{StatusCode: http.StatusUnauthorized, Message: "Invalid read API key", Code: response.CodeInvalidAPIKey}
+		}
+		tier := TierReadKey
+		if key.Analytics {
+			tier = TierAnalytics
+		}
+		return &Principal{Tier: tier, ReadKey: &key}, nil
+	}
+
+	if looksLikeAgentRequest(r) {
+		return authenticateAgentPrincipal(r, db, false)
+	}
+
+	if authHeader != "" {
+		user, httpErr := ValidateTokenAndGetUser(r, db)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+		return &Principal{Tier: TierUser, User: user}, nil
+	}
+
+	return &Principal{Tier: TierAnonymous}, nil
+}
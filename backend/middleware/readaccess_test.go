@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/security"
+
+	"golang.org/x/time/rate"
+)
+
+func TestReadAccess_TiersAndLimits(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	key, err := models.GenerateReadAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateReadAPIKey: %v", err)
+	}
+	readKey := models.ReadAPIKey{OwnerUserID: 1, Name: "dashboard", KeyHash: models.HashReadAPIKey(key), KeyPrefix: key[:15]}
+	if err := db.Create(&readKey).Error; err != nil {
+		t.Fatalf("create read key: %v", err)
+	}
+
+	limits := security.NewCustomRateLimitManager(security.RateLimitConfig{
+		GeneralRate:        rate.Inf,
+		GeneralBurst:       100,
+		CleanupInterval:    time.Minute,
+		AnonymousReadRate:  rate.Every(time.Hour),
+		AnonymousReadBurst: 1,
+		ReadKeyRate:        rate.Every(time.Hour),
+		ReadKeyBurst:       2,
+	})
+	meter := NewReadMeter()
+
+	var servedTier AccessTier
+	handler := ReadAccess(db, limits, meter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		servedTier = AccessTierFromContext(r.Context())
+	}))
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/leaderboard", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("", ""); rec.Code != http.StatusOK || rec.Header().Get(AccessTierHeader) != string(TierAnonymous) {
+		t.Fatalf("expected anonymous read to be served, got %d %q", rec.Code, rec.Header().Get(AccessTierHeader))
+	}
+	if rec := get("", ""); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected second anonymous read to be rate limited, got %d", rec.Code)
+	}
+
+	// The same IP still gets through with a read key, which has its own budget.
+	for i := 0; i < 2; i++ {
+		if rec := get(ReadAPIKeyHeader, key); rec.Code != http.StatusOK || servedTier != TierReadKey {
+			t.Fatalf("expected read key request %d to be served as read_key, got %d %q", i, rec.Code, servedTier)
+		}
+	}
+	if rec := get("Authorization", "Bearer "+key); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected read key to be rate limited after its burst, got %d", rec.Code)
+	}
+
+	if rec := get(ReadAPIKeyHeader, models.ReadAPIKeyPrefix+"bogus"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected unknown read key to be rejected, got %d", rec.Code)
+	}
+
+	var stored models.ReadAPIKey
+	db.First(&stored, readKey.ID)
+	if stored.RequestCount != 2 || stored.LastUsedAt == nil {
+		t.Fatalf("expected read key usage to be metered, got %+v", stored)
+	}
+	if counts := meter.Snapshot(); counts[TierAnonymous] != 1 || counts[TierReadKey] != 2 {
+		t.Fatalf("unexpected tier counts: %v", counts)
+	}
+}
+
+func TestReadAccess_AnalyticsKeyHasItsOwnLimit(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	key, err := models.GenerateReadAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateReadAPIKey: %v", err)
+	}
+	readKey := models.ReadAPIKey{OwnerUserID: 1, Name: "research", KeyHash: models.HashReadAPIKey(key), KeyPrefix: key[:15], Analytics: true}
+	if err := db.Create(&readKey).Error; err != nil {
+		t.Fatalf("create read key: %v", err)
+	}
+
+	limits := security.NewCustomRateLimitManager(security.RateLimitConfig{
+		GeneralRate:     rate.Inf,
+		GeneralBurst:    100,
+		CleanupInterval: time.Minute,
+		ReadKeyRate:     rate.Every(time.Hour),
+		ReadKeyBurst:    1,
+		AnalyticsRate:   rate.Every(time.Hour),
+		AnalyticsBurst:  3,
+	})
+	meter := NewReadMeter()
+	handler := ReadAccess(db, limits, meter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v0/markets", nil)
+		req.Header.Set(ReadAPIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The analytics burst applies, not the smaller read-only key burst.
+	for i := 0; i < 3; i++ {
+		if rec := get(); rec.Code != http.StatusOK || rec.Header().Get(AccessTierHeader) != string(TierAnalytics) {
+			t.Fatalf("expected analytics request %d to be served, got %d %q", i, rec.Code, rec.Header().Get(AccessTierHeader))
+		}
+	}
+	if rec := get(); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected analytics key to be rate limited after its burst, got %d", rec.Code)
+	}
+	if counts := meter.Snapshot(); counts[TierAnalytics] != 3 || counts[TierReadKey] != 0 {
+		t.Fatalf("unexpected tier counts: %v", counts)
+	}
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+const commandUsage = `usage: migrate <command> [flags]
+
+commands:
+  status                       list migrations and whether each is applied
+  up [-dry-run]                apply pending migrations, or print their SQL
+  rollback [-steps N] [-dry-run]
+                               undo the last N applied migrations (default 1)`
+
+// Command runs the migrate command line against db, writing to out:
+// status, up and rollback, the last two with -dry-run to print the SQL
+// instead of running it.
+func Command(db *gorm.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", commandUsage)
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	flags.SetOutput(out)
+	dryRunFlag := flags.Bool("dry-run", false, "print the SQL without changing the database")
+	steps := flags.Int("steps", 1, "how many migrations to roll back")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := StatusOf(db)
+		if err != nil {
+			return err
+		}
+		printStatus(out, statuses)
+		return nil
+
+	case "up":
+		if *dryRunFlag {
+			plans, err := DryRun(db)
+			if err != nil {
+				return err
+			}
+			printPlans(out, plans)
+			return nil
+		}
+		if err := Run(db); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "migrations applied")
+		return nil
+
+	case "rollback":
+		if *dryRunFlag {
+			plans, err := DryRunRollback(db, *steps)
+			if err != nil {
+				return err
+			}
+			printPlans(out, plans)
+			return nil
+		}
+		ids, err := Rollback(db, *steps)
+		for _, id := range ids {
+			fmt.Fprintf(out, "rolled back %s\n", id)
+		}
+		return err
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], commandUsage)
+}
+
+func printStatus(out io.Writer, statuses []Status) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATE\tAPPLIED AT\tREVERSIBLE\tNOTE")
+	for _, s := range statuses {
+		state, appliedAt, note := "pending", "-", ""
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case s.Unknown:
+			note = "not in this build"
+		case s.Modified:
+			note = "changed since applied"
+		}
+		reversible := "no"
+		if s.Reversible {
+			reversible = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.ID, state, appliedAt, reversible, note)
+	}
+	w.Flush()
+}
+
+func printPlans(out io.Writer, plans []Plan) {
+	if len(plans) == 0 {
+		fmt.Fprintln(out, "nothing to do")
+		return
+	}
+	for _, p := range plans {
+		fmt.Fprintf(out, "-- %s (%s)\n", p.ID, p.Direction)
+		for _, sql := range p.SQL {
+			fmt.Fprintf(out, "%s;\n", sql)
+		}
+		fmt.Fprintln(out)
+	}
+}
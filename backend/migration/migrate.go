@@ -1,8 +1,15 @@
 package migration
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"path"
+	"reflect"
+	"runtime"
 	"sort"
 	"time"
 
@@ -12,19 +19,40 @@ import (
 	"socialpredict/models"
 )
 
+// Migration is a registered schema change. Down undoes Up, and is nil when
+// the change cannot be undone.
+type Migration struct {
+	ID   string
+	Up   func(*gorm.DB) error
+	Down func(*gorm.DB) error
+}
+
 // Registry of migrations; you already have tests that exercise this.
-var registry = map[string]func(*gorm.DB) error{}
+var registry = map[string]Migration{}
+
+// sources holds the migrations' source files, from which their checksums
+// are taken. Without it migrations have no checksum.
+var sources fs.FS
 
 type SchemaMigration struct {
 	ID        string    `gorm:"primaryKey;size:32"`
 	AppliedAt time.Time `gorm:"autoCreateTime"`
+	// Checksum of the migration's source when it was applied; empty for
+	// migrations applied before checksums were kept, until the next Run.
+	Checksum string `gorm:"size:64"`
 }
 
+// Register adds a migration that cannot be rolled back.
 func Register(id string, up func(*gorm.DB) error) error {
+	return RegisterReversible(id, up, nil)
+}
+
+// RegisterReversible adds a migration that down rolls back.
+func RegisterReversible(id string, up, down func(*gorm.DB) error) error {
 	if _, exists := registry[id]; exists {
 		return fmt.Errorf("duplicate migration id: %s", id)
 	}
-	registry[id] = up
+	registry[id] = Migration{ID: id, Up: up, Down: down}
 	return nil
 }
 
@@ -34,43 +62,89 @@ func ClearRegistry() { // used by tests
 	}
 }
 
-// Run applies registered migrations in ID order and records them.
-func Run(db *gorm.DB) error {
-	// Ensure tracking table exists
-	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
-		return fmt.Errorf("auto-migrate SchemaMigration: %w", err)
-	}
+// UseSources sets where the migrations' source files are read from for
+// their checksums. A migration's source is the file its Up is declared in.
+func UseSources(fsys fs.FS) {
+	sources = fsys
+}
 
-	// Load already-applied
-	applied := map[string]bool{}
-	var rows []SchemaMigration
-	if err := db.Find(&rows).Error; err != nil {
-		return fmt.Errorf("load SchemaMigration: %w", err)
+// Checksum returns the SHA-256 of the file m.Up is declared in, or "" when
+// the sources are unavailable.
+func (m Migration) Checksum() string {
+	if sources == nil || m.Up == nil {
+		return ""
 	}
-	for _, r := range rows {
-		applied[r.ID] = true
+	fn := runtime.FuncForPC(reflect.ValueOf(m.Up).Pointer())
+	if fn == nil {
+		return ""
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	data, err := fs.ReadFile(sources, path.Base(file))
+	if err != nil {
+		return ""
 	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
 
-	// Sort and apply
+// sortedIDs returns the registered migration IDs in the order they apply.
+func sortedIDs() []string {
 	ids := make([]string, 0, len(registry))
 	for id := range registry {
 		ids = append(ids, id)
 	}
 	sort.Strings(ids)
+	return ids
+}
+
+// appliedMigrations returns the recorded migrations by ID, creating the
+// tracking table if need be.
+func appliedMigrations(db *gorm.DB) (map[string]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("auto-migrate SchemaMigration: %w", err)
+	}
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("load SchemaMigration: %w", err)
+	}
+	applied := make(map[string]SchemaMigration, len(rows))
+	for _, r := range rows {
+		applied[r.ID] = r
+	}
+	return applied, nil
+}
+
+// Run applies registered migrations in ID order and records them with
+// their checksums. Applied migrations recorded without a checksum get
+// their current one; those whose source changed since are logged.
+func Run(db *gorm.DB) error {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
 
-	for _, id := range ids {
-		if applied[id] {
+	for _, id := range sortedIDs() {
+		m := registry[id]
+		if row, ok := applied[id]; ok {
+			checksum := m.Checksum()
+			switch {
+			case row.Checksum == "" && checksum != "":
+				if err := db.Model(&SchemaMigration{}).Where("id = ?", id).Update("checksum", checksum).Error; err != nil {
+					return fmt.Errorf("record checksum of %s: %w", id, err)
+				}
+			case row.Checksum != "" && checksum != "" && row.Checksum != checksum:
+				log.Printf("migration - WARN: %s changed since it was applied", id)
+			}
 			continue
 		}
-		up := registry[id]
-		if up == nil {
+		if m.Up == nil {
 			// <-- Fix: return a proper error instead of panic
 			return fmt.Errorf("migration %s has nil Up()", id)
 		}
-		if err := up(db); err != nil {
+		if err := m.Up(db); err != nil {
 			return fmt.Errorf("migration %s failed: %w", id, err)
 		}
-		if err := db.Create(&SchemaMigration{ID: id, AppliedAt: time.Now()}).Error; err != nil {
+		if err := db.Create(&SchemaMigration{ID: id, AppliedAt: time.Now(), Checksum: m.Checksum()}).Error; err != nil {
 			return fmt.Errorf("record SchemaMigration %s: %w", id, err)
 		}
 		// optional: log.Printf("migration - applied %s", id)
@@ -78,6 +152,65 @@ func Run(db *gorm.DB) error {
 	return nil
 }
 
+// ErrIrreversible is returned when a rollback reaches a migration without
+// a Down, or one this build does not know.
+var ErrIrreversible = errors.New("migration cannot be rolled back")
+
+// lastApplied returns the steps most recently applied migrations, newest
+// first, checking that each can be rolled back.
+func lastApplied(db *gorm.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("rollback steps must be at least 1, got %d", steps)
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(applied))
+	for id := range applied {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	if steps > len(ids) {
+		return nil, fmt.Errorf("only %d migrations are applied", len(ids))
+	}
+
+	var migrations []Migration
+	for _, id := range ids[:steps] {
+		m, ok := registry[id]
+		if !ok || m.Down == nil {
+			return nil, fmt.Errorf("%s: %w", id, ErrIrreversible)
+		}
+		migrations = append(migrations, m)
+	}
+	return migrations, nil
+}
+
+// Rollback undoes the steps most recently applied migrations, newest
+// first, each with its record in one transaction. Nothing is undone if any
+// of them cannot be. It returns the IDs rolled back.
+func Rollback(db *gorm.DB, steps int) ([]string, error) {
+	migrations, err := lastApplied(db, steps)
+	if err != nil {
+		return nil, err
+	}
+	var rolledBack []string
+	for _, m := range migrations {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "id = ?", m.ID).Error
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("rollback %s failed: %w", m.ID, err)
+		}
+		rolledBack = append(rolledBack, m.ID)
+		log.Printf("migration - rolled back %s", m.ID)
+	}
+	return rolledBack, nil
+}
+
 // MigrateDB is the public entry; it never crashes the app.
 // If there are zero registered migrations, we WARN and fallback to AutoMigrate core tables.
 func MigrateDB(db *gorm.DB) error {
@@ -1,7 +1,11 @@
 package migration_test
 
 import (
+	"bytes"
+	"errors"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"socialpredict/migration"
@@ -158,3 +162,156 @@ func TestRun_PersistsAppliedAt(t *testing.T) {
 		t.Fatalf("AppliedAt not set correctly: %+v", row)
 	}
 }
+
+// widget is a table the rollback and dry-run tests create and drop.
+type widget struct {
+	ID   int64
+	Name string
+}
+
+func registerWidgetMigrations(t *testing.T) {
+	t.Helper()
+	migration.ClearRegistry()
+	if err := migration.Register("20250101000000", func(db *gorm.DB) error { return nil }); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := migration.RegisterReversible("20250102000000",
+		func(db *gorm.DB) error { return db.AutoMigrate(&widget{}) },
+		func(db *gorm.DB) error { return db.Migrator().DropTable(&widget{}) },
+	); err != nil {
+		t.Fatalf("RegisterReversible: %v", err)
+	}
+}
+
+func TestRollback_UndoesNewestAndStopsAtIrreversible(t *testing.T) {
+	registerWidgetMigrations(t)
+	db := modelstesting.NewTestDB(t)
+	if err := migration.Run(db); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if _, err := migration.Rollback(db, 2); !errors.Is(err, migration.ErrIrreversible) {
+		t.Fatalf("expected rolling back past an irreversible migration to fail, got %v", err)
+	}
+	if !db.Migrator().HasTable(&widget{}) {
+		t.Fatalf("expected nothing undone when a step cannot be")
+	}
+
+	ids, err := migration.Rollback(db, 1)
+	if err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "20250102000000" {
+		t.Fatalf("expected the newest migration rolled back, got %v", ids)
+	}
+	if db.Migrator().HasTable(&widget{}) {
+		t.Fatalf("expected Down to drop the table")
+	}
+
+	statuses, err := migration.StatusOf(db)
+	if err != nil {
+		t.Fatalf("StatusOf: %v", err)
+	}
+	if len(statuses) != 2 || !statuses[0].Applied || statuses[1].Applied || !statuses[1].Reversible {
+		t.Fatalf("unexpected status after rollback: %+v", statuses)
+	}
+
+	if err := migration.Run(db); err != nil {
+		t.Fatalf("Run again: %v", err)
+	}
+	if !db.Migrator().HasTable(&widget{}) {
+		t.Fatalf("expected the migration to apply again after its rollback")
+	}
+}
+
+func TestDryRun_ReportsSQLWithoutApplying(t *testing.T) {
+	registerWidgetMigrations(t)
+	db := modelstesting.NewTestDB(t)
+
+	plans, err := migration.DryRun(db)
+	if err != nil {
+		t.Fatalf("DryRun: %v", err)
+	}
+	if len(plans) != 2 || plans[1].ID != "20250102000000" || plans[1].Direction != "up" {
+		t.Fatalf("unexpected plans %+v", plans)
+	}
+	if len(plans[0].SQL) != 0 {
+		t.Fatalf("expected no SQL for a migration that runs none, got %v", plans[0].SQL)
+	}
+	if len(plans[1].SQL) == 0 || !strings.Contains(plans[1].SQL[0], "CREATE TABLE") {
+		t.Fatalf("expected the planned CREATE TABLE, got %v", plans[1].SQL)
+	}
+	if db.Migrator().HasTable(&widget{}) {
+		t.Fatalf("expected a dry run to leave the database unchanged")
+	}
+	var recorded int64
+	db.Model(&migration.SchemaMigration{}).Count(&recorded)
+	if recorded != 0 {
+		t.Fatalf("expected a dry run to record nothing, got %d", recorded)
+	}
+
+	if err := migration.Run(db); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	plans, err = migration.DryRunRollback(db, 1)
+	if err != nil {
+		t.Fatalf("DryRunRollback: %v", err)
+	}
+	if len(plans) != 1 || plans[0].Direction != "down" || !strings.Contains(plans[0].SQL[0], "DROP TABLE") {
+		t.Fatalf("unexpected rollback plan %+v", plans)
+	}
+	if !db.Migrator().HasTable(&widget{}) {
+		t.Fatalf("expected a rollback dry run to leave the table")
+	}
+}
+
+func TestChecksums_RecordedAndChangesReported(t *testing.T) {
+	registerWidgetMigrations(t)
+	source := fstest.MapFS{"migrate_test.go": {Data: []byte("first")}}
+	migration.UseSources(source)
+	t.Cleanup(func() { migration.UseSources(nil) })
+
+	db := modelstesting.NewTestDB(t)
+	if err := migration.Run(db); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var row migration.SchemaMigration
+	if err := db.First(&row, "id = ?", "20250102000000").Error; err != nil {
+		t.Fatalf("lookup SchemaMigration: %v", err)
+	}
+	if len(row.Checksum) != 64 {
+		t.Fatalf("expected a SHA-256 checksum, got %q", row.Checksum)
+	}
+
+	source["migrate_test.go"] = &fstest.MapFile{Data: []byte("second")}
+	statuses, err := migration.StatusOf(db)
+	if err != nil {
+		t.Fatalf("StatusOf: %v", err)
+	}
+	for _, s := range statuses {
+		if !s.Modified || s.Checksum == s.AppliedChecksum {
+			t.Fatalf("expected %s reported as changed: %+v", s.ID, s)
+		}
+	}
+}
+
+func TestCommand_StatusAndUnknownCommand(t *testing.T) {
+	registerWidgetMigrations(t)
+	db := modelstesting.NewTestDB(t)
+
+	var out bytes.Buffer
+	if err := migration.Command(db, []string{"up"}, &out); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	out.Reset()
+	if err := migration.Command(db, []string{"status"}, &out); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(out.String(), "20250102000000  applied") {
+		t.Fatalf("expected the applied migration listed, got:\n%s", out.String())
+	}
+
+	if err := migration.Command(db, []string{"sideways"}, &out); err == nil {
+		t.Fatalf("expected an unknown command to fail")
+	}
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260215_sync_agent_models", Migration20260215SyncAgentModels); err != nil {
+		log.Fatalf("Failed to register migration 20260215_sync_agent_models: %v", err)
+	}
+}
+
+// Migration20260215SyncAgentModels brings the agent platform tables in line
+// with the live models on every dialect. The earlier migrations relied on
+// Postgres-only DDL (ADD COLUMN IF NOT EXISTS, NOW()) whose errors were
+// ignored, which left SQLite schemas missing columns. AutoMigrate only adds
+// what is missing, so this is a no-op on databases that are already current.
+func Migration20260215SyncAgentModels(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&models.Agent{},
+		&models.Market{},
+		&models.Prediction{},
+		&models.PredictionVote{},
+		&models.PredictionComment{},
+		&models.AgentFollow{},
+		&models.Proposal{},
+		&models.ProposalVote{},
+		&models.ProposalComment{},
+		&models.PendingSubmission{},
+		&models.CouncilVote{},
+		&models.ValidatorAgent{},
+	)
+}
@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"log"
+	"strings"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260216_actor_model", Migration20260216ActorModel); err != nil {
+		log.Fatalf("Failed to register migration 20260216_actor_model: %v", err)
+	}
+}
+
+// actorMarket adds the creator actor columns to markets.
+type actorMarket struct {
+	CreatorType string `gorm:"size:10;default:user;index:idx_markets_creator"`
+	CreatorID   int64  `gorm:"default:0;index:idx_markets_creator"`
+}
+
+func (actorMarket) TableName() string { return "markets" }
+
+// actorUser links an agent's shadow user back to the agent.
+type actorUser struct {
+	ID       int64
+	Username string
+	AgentID  *int64 `gorm:"uniqueIndex"`
+}
+
+func (actorUser) TableName() string { return "users" }
+
+// actorAgentFollow adds actor types to both sides of a follow.
+type actorAgentFollow struct {
+	FollowerType string `gorm:"not null;size:10;default:agent"`
+	FollowedType string `gorm:"not null;size:10;default:agent"`
+}
+
+func (actorAgentFollow) TableName() string { return "agent_follows" }
+
+// Migration20260216ActorModel moves votes, comments, follows and market
+// creation onto (type, id) actor pairs and links each agent's "agent:<name>"
+// shadow user to the agent so it is resolved by ID rather than by name.
+func Migration20260216ActorModel(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&actorMarket{}, &actorUser{}, &actorAgentFollow{}); err != nil {
+			return err
+		}
+
+		// Follow uniqueness now covers the actor types as well as the IDs.
+		for _, name := range []string{"idx_follow", "idx_agent_follows_unique"} {
+			if err := tx.Exec("DROP INDEX IF EXISTS " + name).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_follow ON agent_follows(follower_type, follower_id, followed_type, followed_id)").Error; err != nil {
+			return err
+		}
+
+		// Rows written before the type columns were enforced were all by agents.
+		if err := tx.Exec("UPDATE prediction_votes SET voter_type = 'agent' WHERE voter_type IS NULL OR voter_type = ''").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE prediction_comments SET author_type = 'agent' WHERE author_type IS NULL OR author_type = ''").Error; err != nil {
+			return err
+		}
+
+		// Backfill market creators from creator_agent_id and creator_username.
+		if err := tx.Exec("UPDATE markets SET creator_type = 'agent', creator_id = creator_agent_id WHERE creator_agent_id IS NOT NULL").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`UPDATE markets SET creator_type = 'user',
+			creator_id = COALESCE((SELECT users.id FROM users WHERE users.username = markets.creator_username), 0)
+			WHERE creator_agent_id IS NULL`).Error; err != nil {
+			return err
+		}
+
+		return consolidateAgentShadowUsers(tx)
+	})
+}
+
+// consolidateAgentShadowUsers links every "agent:<name>" user to its agent.
+// If an agent already has a linked shadow user, bets on the duplicate are
+// moved to it and the duplicate is deleted. Shadow users whose agent no
+// longer exists are left alone because their bets still reference them.
+func consolidateAgentShadowUsers(tx *gorm.DB) error {
+	var shadows []actorUser
+	if err := tx.Where("username LIKE ?", "agent:%").Order("id").Find(&shadows).Error; err != nil {
+		return err
+	}
+
+	type agentRow struct {
+		ID   int64
+		Name string
+	}
+	canonical := make(map[int64]actorUser)
+	var unlinked []actorUser
+	for _, shadow := range shadows {
+		if shadow.AgentID != nil {
+			canonical[*shadow.AgentID] = shadow
+		} else {
+			unlinked = append(unlinked, shadow)
+		}
+	}
+
+	for _, shadow := range unlinked {
+		var agent agentRow
+		name := strings.TrimPrefix(shadow.Username, "agent:")
+		err := tx.Table("agents").Select("id, name").Where("name = ?", name).Take(&agent).Error
+		if err == gorm.ErrRecordNotFound {
+			log.Printf("actor migration: no agent named %q for shadow user %d, leaving it", name, shadow.ID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		keep, ok := canonical[agent.ID]
+		if !ok {
+			if err := tx.Model(&actorUser{}).Where("id = ?", shadow.ID).Update("agent_id", agent.ID).Error; err != nil {
+				return err
+			}
+			agentID := agent.ID
+			shadow.AgentID = &agentID
+			canonical[agent.ID] = shadow
+			continue
+		}
+
+		if err := tx.Exec("UPDATE bets SET username = ? WHERE username = ?", keep.Username, shadow.Username).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM users WHERE id = ?", shadow.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
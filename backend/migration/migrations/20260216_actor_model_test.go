@@ -0,0 +1,62 @@
+package migrations_test
+
+import (
+	"testing"
+	"time"
+
+	"socialpredict/migration/migrations"
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestMigrateActorModel_BackfillsCreatorsAndLinksShadowUsers(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	alice := modelstesting.GenerateUser("alice", 0)
+	if err := db.Create(&alice).Error; err != nil {
+		t.Fatalf("seed alice: %v", err)
+	}
+	shadow := modelstesting.GenerateUser("agent:oracle", 0)
+	if err := db.Create(&shadow).Error; err != nil {
+		t.Fatalf("seed shadow user: %v", err)
+	}
+	agent := models.Agent{Name: "oracle", APIKey: "swarm_sk_oracle", ClaimToken: "swarm_claim_oracle"}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("seed agent: %v", err)
+	}
+
+	humanMarket := modelstesting.GenerateMarket(1, "alice")
+	agentMarket := modelstesting.GenerateMarket(2, "alice")
+	agentMarket.CreatorAgentID = &agent.ID
+	for _, m := range []*models.Market{&humanMarket, &agentMarket} {
+		m.ResolutionDateTime = time.Now().Add(24 * time.Hour)
+		if err := db.Create(m).Error; err != nil {
+			t.Fatalf("seed market: %v", err)
+		}
+	}
+	// Simulate rows written before the creator columns existed.
+	db.Exec("UPDATE markets SET creator_type = '', creator_id = 0")
+
+	if err := migrations.Migration20260216ActorModel(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	var got models.Market
+	db.First(&got, humanMarket.ID)
+	if got.CreatedBy() != models.UserActor(alice.ID) {
+		t.Fatalf("expected human market created by %v, got %v", models.UserActor(alice.ID), got.CreatedBy())
+	}
+	var gotAgent models.Market
+	db.First(&gotAgent, agentMarket.ID)
+	if gotAgent.CreatedBy() != models.AgentActor(agent.ID) {
+		t.Fatalf("expected agent market created by %v, got %v", models.AgentActor(agent.ID), gotAgent.CreatedBy())
+	}
+
+	var linked models.User
+	if err := db.Where("agent_id = ?", agent.ID).First(&linked).Error; err != nil {
+		t.Fatalf("expected shadow user linked to agent: %v", err)
+	}
+	if linked.ID != shadow.ID {
+		t.Fatalf("expected shadow user %d to be linked, got %d", shadow.ID, linked.ID)
+	}
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260217_outbox_events", Migration20260217OutboxEvents); err != nil {
+		log.Fatalf("Failed to register migration 20260217_outbox_events: %v", err)
+	}
+}
+
+// OutboxEvent model for migration
+type OutboxEvent struct {
+	ID            int64  `gorm:"primaryKey"`
+	Topic         string `gorm:"not null;size:100;index"`
+	AggregateType string `gorm:"not null;size:50"`
+	AggregateID   int64  `gorm:"not null"`
+	Payload       string `gorm:"type:text"`
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time `gorm:"index:idx_outbox_pending,priority:1"`
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_pending,priority:2"`
+	Attempts      int        `gorm:"default:0"`
+	LastError     string     `gorm:"size:500"`
+}
+
+// Migration20260217OutboxEvents creates the transactional outbox table.
+func Migration20260217OutboxEvents(db *gorm.DB) error {
+	return db.AutoMigrate(&OutboxEvent{})
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260218_optimistic_locking", Migration20260218OptimisticLocking); err != nil {
+		log.Fatalf("Failed to register migration 20260218_optimistic_locking: %v", err)
+	}
+}
+
+// lockVersionColumn is the optimistic locking column added to each table.
+type lockVersionColumn struct {
+	Version int64 `gorm:"not null;default:1"`
+}
+
+type agentVersion struct{ lockVersionColumn }
+
+func (agentVersion) TableName() string { return "agents" }
+
+type marketVersion struct{ lockVersionColumn }
+
+func (marketVersion) TableName() string { return "markets" }
+
+type predictionVersion struct{ lockVersionColumn }
+
+func (predictionVersion) TableName() string { return "predictions" }
+
+type submissionVersion struct{ lockVersionColumn }
+
+func (submissionVersion) TableName() string { return "pending_submissions" }
+
+// Migration20260218OptimisticLocking adds the version column used by
+// models.LockVersion to agents, markets, predictions and pending submissions.
+// Existing rows start at version 1.
+func Migration20260218OptimisticLocking(db *gorm.DB) error {
+	return db.AutoMigrate(&agentVersion{}, &marketVersion{}, &predictionVersion{}, &submissionVersion{})
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260219_notifications", Migration20260219Notifications); err != nil {
+		log.Fatalf("Failed to register migration 20260219_notifications: %v", err)
+	}
+}
+
+// Notification model for migration
+type Notification struct {
+	ID        int64      `gorm:"primaryKey"`
+	AgentID   int64      `gorm:"not null;index:idx_notifications_inbox,priority:1"`
+	Kind      string     `gorm:"not null;size:50"`
+	Title     string     `gorm:"not null;size:200"`
+	Data      string     `gorm:"type:text"`
+	ReadAt    *time.Time `gorm:"index:idx_notifications_inbox,priority:2"`
+	CreatedAt time.Time
+}
+
+// notificationAgent adds the webhook columns to agents.
+type notificationAgent struct {
+	WebhookURL    string `gorm:"size:500"`
+	WebhookSecret string `gorm:"size:100"`
+}
+
+func (notificationAgent) TableName() string { return "agents" }
+
+// Migration20260219Notifications creates the agent inbox and webhook settings.
+func Migration20260219Notifications(db *gorm.DB) error {
+	return db.AutoMigrate(&Notification{}, &notificationAgent{})
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260220_notification_dedup", Migration20260220NotificationDedup); err != nil {
+		log.Fatalf("Failed to register migration 20260220_notification_dedup: %v", err)
+	}
+}
+
+// dedupNotification adds the per-agent reminder key to notifications.
+type dedupNotification struct {
+	AgentID  int64   `gorm:"not null;uniqueIndex:idx_notifications_dedup,priority:1"`
+	DedupKey *string `gorm:"size:100;uniqueIndex:idx_notifications_dedup,priority:2"`
+}
+
+func (dedupNotification) TableName() string { return "notifications" }
+
+// Migration20260220NotificationDedup lets reminders be sent at most once.
+func Migration20260220NotificationDedup(db *gorm.DB) error {
+	return db.AutoMigrate(&dedupNotification{})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260221_read_api_keys", Migration20260221ReadAPIKeys); err != nil {
+		log.Fatalf("Failed to register migration 20260221_read_api_keys: %v", err)
+	}
+}
+
+// ReadAPIKey model for migration
+type ReadAPIKey struct {
+	ID           int64  `gorm:"primaryKey"`
+	OwnerUserID  int64  `gorm:"not null;index"`
+	Name         string `gorm:"not null;size:100"`
+	KeyHash      string `gorm:"not null;uniqueIndex;size:64"`
+	KeyPrefix    string `gorm:"not null;size:20"`
+	RequestCount int64  `gorm:"not null;default:0"`
+	LastUsedAt   *time.Time
+	RevokedAt    *time.Time
+	CreatedAt    time.Time
+}
+
+// Migration20260221ReadAPIKeys creates the read-only API key tier.
+func Migration20260221ReadAPIKeys(db *gorm.DB) error {
+	return db.AutoMigrate(&ReadAPIKey{})
+}
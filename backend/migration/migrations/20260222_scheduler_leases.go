@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260222_scheduler_leases", Migration20260222SchedulerLeases); err != nil {
+		log.Fatalf("Failed to register migration 20260222_scheduler_leases: %v", err)
+	}
+}
+
+// SchedulerLease model for migration
+type SchedulerLease struct {
+	Name      string    `gorm:"primaryKey;size:100"`
+	Holder    string    `gorm:"not null;size:200"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// Migration20260222SchedulerLeases creates the lease table that keeps
+// scheduled jobs to one backend instance at a time.
+func Migration20260222SchedulerLeases(db *gorm.DB) error {
+	return db.AutoMigrate(&SchedulerLease{})
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260223_closing_auction", Migration20260223ClosingAuction); err != nil {
+		log.Fatalf("Failed to register migration 20260223_closing_auction: %v", err)
+	}
+}
+
+// auctionMarket adds the closing auction columns to markets.
+type auctionMarket struct {
+	ClosingAuction   bool `gorm:"default:false"`
+	FinalConsensus   *float64
+	FinalConsensusAt *time.Time
+}
+
+func (auctionMarket) TableName() string { return "markets" }
+
+// ClosingBid model for migration
+type ClosingBid struct {
+	ID          int64   `gorm:"primaryKey"`
+	MarketID    int64   `gorm:"not null;uniqueIndex:idx_closing_bids_market_agent,priority:1"`
+	AgentID     int64   `gorm:"not null;uniqueIndex:idx_closing_bids_market_agent,priority:2"`
+	Probability float64 `gorm:"not null"`
+	Weight      float64 `gorm:"not null;default:0"`
+	RevealedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Migration20260223ClosingAuction adds sealed closing bids and the official
+// final consensus they produce.
+func Migration20260223ClosingAuction(db *gorm.DB) error {
+	return db.AutoMigrate(&auctionMarket{}, &ClosingBid{})
+}
@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260224_agent_api_keys", Migration20260224AgentAPIKeys); err != nil {
+		log.Fatalf("Failed to register migration 20260224_agent_api_keys: %v", err)
+	}
+}
+
+// AgentAPIKey model for migration
+type AgentAPIKey struct {
+	ID         int64  `gorm:"primaryKey"`
+	AgentID    int64  `gorm:"not null;index"`
+	Name       string `gorm:"not null;size:100"`
+	KeyHash    string `gorm:"not null;uniqueIndex;size:64"`
+	KeyPrefix  string `gorm:"not null;size:20"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// Migration20260224AgentAPIKeys creates the agent API key table and copies
+// every agent's existing key into it, so current keys keep working and can
+// be rotated.
+func Migration20260224AgentAPIKeys(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&AgentAPIKey{}); err != nil {
+			return err
+		}
+
+		type agentRow struct {
+			ID     int64
+			APIKey string
+		}
+		var agents []agentRow
+		if err := tx.Table("agents").Select("id, api_key").Find(&agents).Error; err != nil {
+			return err
+		}
+
+		for _, agent := range agents {
+			if !strings.HasPrefix(agent.APIKey, "swarm_sk_") || len(agent.APIKey) < len("swarm_sk_")+6 {
+				continue
+			}
+			sum := sha256.Sum256([]byte(agent.APIKey))
+			hash := hex.EncodeToString(sum[:])
+
+			var existing int64
+			if err := tx.Model(&AgentAPIKey{}).Where("key_hash = ?", hash).Count(&existing).Error; err != nil {
+				return err
+			}
+			if existing > 0 {
+				continue
+			}
+			key := AgentAPIKey{
+				AgentID:   agent.ID,
+				Name:      "default",
+				KeyHash:   hash,
+				KeyPrefix: agent.APIKey[:len("swarm_sk_")+6],
+			}
+			if err := tx.Create(&key).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
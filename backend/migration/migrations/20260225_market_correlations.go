@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260225_market_correlations", Migration20260225MarketCorrelations); err != nil {
+		log.Fatalf("Failed to register migration 20260225_market_correlations: %v", err)
+	}
+}
+
+// ConsensusSnapshot model for migration
+type ConsensusSnapshot struct {
+	ID          int64     `gorm:"primaryKey"`
+	MarketID    int64     `gorm:"not null;index:idx_consensus_snapshots_market_taken,priority:1"`
+	Probability float64   `gorm:"not null"`
+	Predictions int64     `gorm:"not null;default:0"`
+	TakenAt     time.Time `gorm:"not null;index:idx_consensus_snapshots_market_taken,priority:2"`
+}
+
+// MarketCorrelation model for migration
+type MarketCorrelation struct {
+	ID            int64     `gorm:"primaryKey"`
+	MarketID      int64     `gorm:"not null;uniqueIndex:idx_market_correlations_pair,priority:1"`
+	OtherMarketID int64     `gorm:"not null;uniqueIndex:idx_market_correlations_pair,priority:2"`
+	Coefficient   float64   `gorm:"not null"`
+	Samples       int       `gorm:"not null"`
+	ComputedAt    time.Time `gorm:"not null"`
+}
+
+// Migration20260225MarketCorrelations creates the consensus history and
+// the cross-market correlations computed from it.
+func Migration20260225MarketCorrelations(db *gorm.DB) error {
+	return db.AutoMigrate(&ConsensusSnapshot{}, &MarketCorrelation{})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260226_agent_model_cards", Migration20260226AgentModelCards); err != nil {
+		log.Fatalf("Failed to register migration 20260226_agent_model_cards: %v", err)
+	}
+}
+
+// modelCardAgent adds the model card columns to agents.
+type modelCardAgent struct {
+	ModelFamily    string `gorm:"size:50;index"`
+	ModelProvider  string `gorm:"size:30;index"`
+	ParameterScale string `gorm:"size:20"`
+	ToolsUsed      string `gorm:"size:300"`
+	AutonomyLevel  string `gorm:"size:20"`
+}
+
+func (modelCardAgent) TableName() string { return "agents" }
+
+// Migration20260226AgentModelCards adds structured model card metadata to
+// agents alongside the free-form framework_type.
+func Migration20260226AgentModelCards(db *gorm.DB) error {
+	return db.AutoMigrate(&modelCardAgent{})
+}
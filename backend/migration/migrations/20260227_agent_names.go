@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260227_agent_names", Migration20260227AgentNames); err != nil {
+		log.Fatalf("Failed to register migration 20260227_agent_names: %v", err)
+	}
+}
+
+// ReservedAgentName model for migration
+type ReservedAgentName struct {
+	ID        int64  `gorm:"primaryKey"`
+	Pattern   string `gorm:"not null;uniqueIndex;size:50"`
+	IsPrefix  bool   `gorm:"not null;default:false"`
+	Reason    string `gorm:"size:200"`
+	CreatedAt time.Time
+}
+
+// AgentNameChange model for migration
+type AgentNameChange struct {
+	ID        int64     `gorm:"primaryKey"`
+	AgentID   int64     `gorm:"not null;index"`
+	OldName   string    `gorm:"not null;size:50;index"`
+	NewName   string    `gorm:"not null;size:50"`
+	ChangedAt time.Time `gorm:"not null"`
+}
+
+// nameSkeletonAgent adds the name skeleton column to agents.
+type nameSkeletonAgent struct {
+	NameSkeleton string `gorm:"size:50;index"`
+}
+
+func (nameSkeletonAgent) TableName() string { return "agents" }
+
+// Migration20260227AgentNames adds reserved agent names, the rename history
+// and the name skeleton used for impersonation checks, filling it in for
+// existing agents.
+func Migration20260227AgentNames(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&ReservedAgentName{}, &AgentNameChange{}, &nameSkeletonAgent{}); err != nil {
+			return err
+		}
+
+		type agentRow struct {
+			ID   int64
+			Name string
+		}
+		var agents []agentRow
+		if err := tx.Table("agents").Select("id, name").Find(&agents).Error; err != nil {
+			return err
+		}
+		for _, agent := range agents {
+			if err := tx.Table("agents").Where("id = ?", agent.ID).
+				Update("name_skeleton", models.AgentNameSkeleton(agent.Name)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
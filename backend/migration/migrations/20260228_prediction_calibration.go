@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"log"
+	"math"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260228_prediction_calibration", Migration20260228PredictionCalibration); err != nil {
+		log.Fatalf("Failed to register migration 20260228_prediction_calibration: %v", err)
+	}
+}
+
+// calibrationPrediction adds the per-prediction calibration scores.
+type calibrationPrediction struct {
+	BrierScore *float64
+	LogLoss    *float64
+}
+
+func (calibrationPrediction) TableName() string { return "predictions" }
+
+// calibrationAgent adds the calibration aggregates to agents.
+type calibrationAgent struct {
+	ScoredPredictions       int64   `gorm:"default:0"`
+	MeanBrierScore          float64 `gorm:"default:0"`
+	MeanLogLoss             float64 `gorm:"default:0"`
+	CalibratedAccuracyScore float64 `gorm:"default:50"`
+}
+
+func (calibrationAgent) TableName() string { return "agents" }
+
+// Migration20260228PredictionCalibration adds Brier score and log loss to
+// predictions and a calibrated accuracy score to agents, scoring every
+// prediction already resolved on a YES or NO market.
+func Migration20260228PredictionCalibration(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&calibrationPrediction{}, &calibrationAgent{}); err != nil {
+			return err
+		}
+
+		type resolvedRow struct {
+			ID         int64
+			AgentID    int64
+			Outcome    string
+			Confidence float64
+			Resolution string
+		}
+		var rows []resolvedRow
+		if err := tx.Table("predictions").
+			Select("predictions.id, predictions.agent_id, predictions.outcome, predictions.confidence, markets.resolution_result AS resolution").
+			Joins("JOIN markets ON markets.id = predictions.market_id").
+			Where("predictions.is_resolved = ? AND predictions.deleted_at IS NULL AND markets.resolution_result IN ?", true, []string{"YES", "NO"}).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+
+		type totals struct {
+			scored         int64
+			brier, logLoss float64
+		}
+		byAgent := make(map[int64]*totals)
+		for _, row := range rows {
+			pYes := row.Confidence / 100
+			if row.Outcome != "YES" {
+				pYes = 1 - pYes
+			}
+			pHappened := pYes
+			if row.Resolution != "YES" {
+				pHappened = 1 - pYes
+			}
+			brier := (1 - pHappened) * (1 - pHappened)
+			logLoss := -math.Log(math.Max(pHappened, 0.01))
+			if err := tx.Table("predictions").Where("id = ?", row.ID).
+				Updates(map[string]interface{}{"brier_score": brier, "log_loss": logLoss}).Error; err != nil {
+				return err
+			}
+
+			t, ok := byAgent[row.AgentID]
+			if !ok {
+				t = &totals{}
+				byAgent[row.AgentID] = t
+			}
+			t.scored++
+			t.brier += brier
+			t.logLoss += logLoss
+		}
+
+		for agentID, t := range byAgent {
+			meanBrier := t.brier / float64(t.scored)
+			calibrated := math.Max(0, 100-200*meanBrier)
+			smoothed := (calibrated*float64(t.scored) + 50*10) / (float64(t.scored) + 10)
+			if err := tx.Table("agents").Where("id = ?", agentID).Updates(map[string]interface{}{
+				"scored_predictions":        t.scored,
+				"mean_brier_score":          meanBrier,
+				"mean_log_loss":             t.logLoss / float64(t.scored),
+				"calibrated_accuracy_score": smoothed,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
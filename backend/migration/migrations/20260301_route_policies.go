@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260301_route_policies", Migration20260301RoutePolicies); err != nil {
+		log.Fatalf("Failed to register migration 20260301_route_policies: %v", err)
+	}
+}
+
+// scopedAgentAPIKey adds scopes to agent API keys.
+type scopedAgentAPIKey struct {
+	Scopes string `gorm:"size:200"`
+}
+
+func (scopedAgentAPIKey) TableName() string { return "agent_api_keys" }
+
+// IdempotencyRecord model for migration
+type IdempotencyRecord struct {
+	ID          int64  `gorm:"primaryKey"`
+	Principal   string `gorm:"not null;size:100;uniqueIndex:idx_idempotency_records_key,priority:1"`
+	Key         string `gorm:"not null;size:100;uniqueIndex:idx_idempotency_records_key,priority:2"`
+	Method      string `gorm:"not null;size:10"`
+	Path        string `gorm:"not null;size:300"`
+	RequestHash string `gorm:"not null;size:64"`
+	StatusCode  int    `gorm:"not null;default:0"`
+	ContentType string `gorm:"size:100"`
+	Body        []byte
+	CreatedAt   time.Time `gorm:"index"`
+}
+
+// Migration20260301RoutePolicies adds what per-route policies need: scopes
+// on agent API keys and the stored responses behind Idempotency-Key.
+// Existing keys keep every scope.
+func Migration20260301RoutePolicies(db *gorm.DB) error {
+	return db.AutoMigrate(&scopedAgentAPIKey{}, &IdempotencyRecord{})
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260302_sandbox_predictions", Migration20260302SandboxPredictions); err != nil {
+		log.Fatalf("Failed to register migration 20260302_sandbox_predictions: %v", err)
+	}
+}
+
+// SandboxPrediction model for migration
+type SandboxPrediction struct {
+	ID         int64  `gorm:"primaryKey"`
+	AgentID    int64  `gorm:"not null;index"`
+	Outcome    string `gorm:"not null;size:10"`
+	Confidence float64
+	Reasoning  string `gorm:"size:2000"`
+	Resolution string `gorm:"not null;size:10"`
+	WasCorrect bool
+	BrierScore float64
+	CreatedAt  time.Time
+}
+
+// Migration20260302SandboxPredictions adds the table behind the onboarding
+// sandbox market.
+func Migration20260302SandboxPredictions(db *gorm.DB) error {
+	return db.AutoMigrate(&SandboxPrediction{})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260303_admin_jobs", Migration20260303AdminJobs); err != nil {
+		log.Fatalf("Failed to register migration 20260303_admin_jobs: %v", err)
+	}
+}
+
+// AdminJob model for migration
+type AdminJob struct {
+	ID              int64  `gorm:"primaryKey"`
+	Kind            string `gorm:"not null;size:50;index"`
+	Status          string `gorm:"not null;size:20;index"`
+	RequestedBy     string `gorm:"size:100"`
+	Processed       int
+	Total           int
+	Updated         int
+	CancelRequested bool
+	Error           string `gorm:"size:500"`
+	CreatedAt       time.Time
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+	UpdatedAt       time.Time
+}
+
+// Migration20260303AdminJobs adds the table behind background admin jobs
+// such as score recalculation.
+func Migration20260303AdminJobs(db *gorm.DB) error {
+	return db.AutoMigrate(&AdminJob{})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260304_market_provenance", Migration20260304MarketProvenance); err != nil {
+		log.Fatalf("Failed to register migration 20260304_market_provenance: %v", err)
+	}
+}
+
+// provenanceMarket adds the council provenance columns to markets.
+type provenanceMarket struct {
+	SourceSubmissionID *int64 `gorm:"index"`
+	Provenance         string `gorm:"type:text"`
+}
+
+func (provenanceMarket) TableName() string { return "markets" }
+
+// Migration20260304MarketProvenance records which council submission each
+// market came from and how it was approved.
+func Migration20260304MarketProvenance(db *gorm.DB) error {
+	return db.AutoMigrate(&provenanceMarket{})
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260305_weighted_council_tallies", Migration20260305WeightedCouncilTallies); err != nil {
+		log.Fatalf("Failed to register migration 20260305_weighted_council_tallies: %v", err)
+	}
+}
+
+// weightedSubmission adds the weighted tallies and voter quorum to
+// pending_submissions.
+type weightedSubmission struct {
+	WeightFor     float64 `gorm:"default:0"`
+	WeightAgainst float64 `gorm:"default:0"`
+	MinVoters     int     `gorm:"default:1"`
+}
+
+func (weightedSubmission) TableName() string { return "pending_submissions" }
+
+// Migration20260305WeightedCouncilTallies adds weighted council tallies and
+// fills them in from the votes already cast.
+func Migration20260305WeightedCouncilTallies(db *gorm.DB) error {
+	if err := db.AutoMigrate(&weightedSubmission{}); err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE pending_submissions SET
+		weight_for = COALESCE((SELECT SUM(weight) FROM council_votes
+			WHERE council_votes.submission_id = pending_submissions.id AND council_votes.vote = 'approve' AND council_votes.deleted_at IS NULL), 0),
+		weight_against = COALESCE((SELECT SUM(weight) FROM council_votes
+			WHERE council_votes.submission_id = pending_submissions.id AND council_votes.vote = 'reject' AND council_votes.deleted_at IS NULL), 0)`).Error
+}
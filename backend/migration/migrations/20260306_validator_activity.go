@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260306_validator_activity", Migration20260306ValidatorActivity); err != nil {
+		log.Fatalf("Failed to register migration 20260306_validator_activity: %v", err)
+	}
+}
+
+// validatorActivity adds inactivity tracking and re-qualification to
+// validator_agents.
+type validatorActivity struct {
+	LastVotedAt     *time.Time
+	DeactivatedAt   *time.Time
+	ReactivatedAt   *time.Time
+	OnProbation     bool `gorm:"default:false"`
+	RequalifyStreak int  `gorm:"default:0"`
+}
+
+func (validatorActivity) TableName() string { return "validator_agents" }
+
+// reconciledVote adds probation and reconciliation to council_votes.
+type reconciledVote struct {
+	Probation    bool `gorm:"default:false"`
+	Correct      *bool
+	ReconciledAt *time.Time `gorm:"index"`
+}
+
+func (reconciledVote) TableName() string { return "council_votes" }
+
+// Migration20260306ValidatorActivity tracks when validators last voted,
+// starting from the votes already cast, and whether each vote matched the
+// council's decision. Votes on submissions already decided are marked
+// reconciled without being judged, so they are not credited twice.
+func Migration20260306ValidatorActivity(db *gorm.DB) error {
+	if err := db.AutoMigrate(&validatorActivity{}, &reconciledVote{}); err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE validator_agents SET last_voted_at = (SELECT MAX(council_votes.created_at) FROM council_votes
+		WHERE council_votes.validator_id = validator_agents.agent_id AND council_votes.deleted_at IS NULL)`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE council_votes SET reconciled_at = ? WHERE submission_id IN
+		(SELECT id FROM pending_submissions WHERE final_status IN ('approved', 'rejected', 'expired'))`, time.Now()).Error
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260307_validator_scores", Migration20260307ValidatorScores); err != nil {
+		log.Fatalf("Failed to register migration 20260307_validator_scores: %v", err)
+	}
+}
+
+// marketCheckedVote adds the market outcome check to council_votes.
+type marketCheckedVote struct {
+	MarketCheckedAt *time.Time
+}
+
+func (marketCheckedVote) TableName() string { return "council_votes" }
+
+// Migration20260307ValidatorScores judges the counted votes on submissions
+// decided before votes were reconciled, and scores every validator on their
+// judged votes: the share correct, smoothed as though each had already
+// cast 10 votes at 50%. Markets already resolved are checked by the next
+// reconciliation run.
+func Migration20260307ValidatorScores(db *gorm.DB) error {
+	if err := db.AutoMigrate(&marketCheckedVote{}); err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE council_votes SET correct = CASE WHEN (council_votes.vote = 'approve') =
+			((SELECT final_status FROM pending_submissions WHERE pending_submissions.id = council_votes.submission_id) = 'approved')
+			THEN TRUE ELSE FALSE END
+		WHERE correct IS NULL AND probation = ? AND submission_id IN
+			(SELECT id FROM pending_submissions WHERE final_status IN ('approved', 'rejected'))`, false).Error; err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE validator_agents SET
+		correct_validations = (SELECT COUNT(*) FROM council_votes
+			WHERE council_votes.validator_id = validator_agents.agent_id AND council_votes.probation = ? AND council_votes.correct = ? AND council_votes.deleted_at IS NULL),
+		validator_score = (100.0 * (SELECT COUNT(*) FROM council_votes
+			WHERE council_votes.validator_id = validator_agents.agent_id AND council_votes.probation = ? AND council_votes.correct = ? AND council_votes.deleted_at IS NULL) + 500.0)
+			/ ((SELECT COUNT(*) FROM council_votes
+			WHERE council_votes.validator_id = validator_agents.agent_id AND council_votes.probation = ? AND council_votes.correct IS NOT NULL AND council_votes.deleted_at IS NULL) + 10)`,
+		false, true, false, true, false).Error
+}
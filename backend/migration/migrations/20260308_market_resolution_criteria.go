@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260308_market_resolution_criteria", Migration20260308MarketResolutionCriteria); err != nil {
+		log.Fatalf("Failed to register migration 20260308_market_resolution_criteria: %v", err)
+	}
+}
+
+// criteriaMarket adds the structured resolution criteria to markets.
+type criteriaMarket struct {
+	CriteriaSourceURL  string `gorm:"size:500"`
+	CriteriaThreshold  string `gorm:"type:text"`
+	CriteriaTimezone   string `gorm:"size:64"`
+	CriteriaTieBreaker string `gorm:"type:text"`
+}
+
+func (criteriaMarket) TableName() string { return "markets" }
+
+// Migration20260308MarketResolutionCriteria adds a market's source of truth,
+// threshold, timezone and tie-breaking rule. Existing markets have none.
+func Migration20260308MarketResolutionCriteria(db *gorm.DB) error {
+	return db.AutoMigrate(&criteriaMarket{})
+}
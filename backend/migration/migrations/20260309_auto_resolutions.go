@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260309_auto_resolutions", Migration20260309AutoResolutions); err != nil {
+		log.Fatalf("Failed to register migration 20260309_auto_resolutions: %v", err)
+	}
+}
+
+// AutoResolution model for migration
+type AutoResolution struct {
+	ID            int64  `gorm:"primaryKey"`
+	MarketID      int64  `gorm:"not null;index"`
+	Oracle        string `gorm:"not null;size:50"`
+	Spec          string `gorm:"type:text"`
+	Status        string `gorm:"not null;size:20"`
+	Outcome       string `gorm:"size:10"`
+	ObservedValue string `gorm:"size:500"`
+	Source        string `gorm:"size:500"`
+	Response      string `gorm:"type:text"`
+	Error         string `gorm:"size:500"`
+	CreatedAt     time.Time
+}
+
+// Migration20260309AutoResolutions adds the audit trail of markets resolved
+// from oracles.
+func Migration20260309AutoResolutions(db *gorm.DB) error {
+	return db.AutoMigrate(&AutoResolution{})
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260310_market_tags", Migration20260310MarketTags); err != nil {
+		log.Fatalf("Failed to register migration 20260310_market_tags: %v", err)
+	}
+}
+
+// MarketTag model for migration
+type MarketTag struct {
+	ID        int64  `gorm:"primaryKey"`
+	MarketID  int64  `gorm:"not null;uniqueIndex:idx_market_tag"`
+	Tag       string `gorm:"size:30;not null;uniqueIndex:idx_market_tag;index"`
+	CreatedAt time.Time
+}
+
+// Migration20260310MarketTags adds the table of tags attached to markets.
+func Migration20260310MarketTags(db *gorm.DB) error {
+	return db.AutoMigrate(&MarketTag{})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260311_market_title_trigrams", Migration20260311MarketTitleTrigrams); err != nil {
+		log.Fatalf("Failed to register migration 20260311_market_title_trigrams: %v", err)
+	}
+}
+
+// Migration20260311MarketTitleTrigrams enables pg_trgm on PostgreSQL and
+// indexes market titles for trigram similarity search. Installing the
+// extension needs privileges the application role may not have; without it
+// duplicate detection scores titles in the application instead, so a
+// failure is logged rather than returned. Other databases need nothing.
+func Migration20260311MarketTitleTrigrams(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("pg_trgm unavailable, market similarity will be computed in the application: %v", err)
+		return nil
+	}
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_markets_question_title_trgm ON markets USING gin (question_title gin_trgm_ops)").Error
+}
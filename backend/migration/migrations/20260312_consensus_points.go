@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260312_consensus_points", Migration20260312ConsensusPoints); err != nil {
+		log.Fatalf("Failed to register migration 20260312_consensus_points: %v", err)
+	}
+}
+
+// ConsensusPoint model for migration
+type ConsensusPoint struct {
+	ID           int64   `gorm:"primaryKey"`
+	MarketID     int64   `gorm:"not null;index:idx_consensus_points_market_at,priority:1"`
+	Probability  float64 `gorm:"not null"`
+	Predictions  int64   `gorm:"not null;default:0"`
+	PredictionID int64
+	RecordedAt   time.Time `gorm:"not null;index:idx_consensus_points_market_at,priority:2"`
+}
+
+// Migration20260312ConsensusPoints adds the per-market history of the swarm
+// consensus. History starts with the first prediction made after it runs.
+func Migration20260312ConsensusPoints(db *gorm.DB) error {
+	return db.AutoMigrate(&ConsensusPoint{})
+}
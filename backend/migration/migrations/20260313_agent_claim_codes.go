@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260313_agent_claim_codes", Migration20260313AgentClaimCodes); err != nil {
+		log.Fatalf("Failed to register migration 20260313_agent_claim_codes: %v", err)
+	}
+}
+
+// claimCodeAgent adds the stored verification code to agents.
+type claimCodeAgent struct {
+	ClaimCode string `gorm:"size:30"`
+}
+
+func (claimCodeAgent) TableName() string { return "agents" }
+
+// Migration20260313AgentClaimCodes stores the verification code issued at
+// registration so a claim can be checked against it. Agents registered
+// before are left without one; their owners never saw a stored code.
+func Migration20260313AgentClaimCodes(db *gorm.DB) error {
+	return db.AutoMigrate(&claimCodeAgent{})
+}
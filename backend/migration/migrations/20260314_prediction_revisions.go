@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260314_prediction_revisions", Migration20260314PredictionRevisions); err != nil {
+		log.Fatalf("Failed to register migration 20260314_prediction_revisions: %v", err)
+	}
+}
+
+// PredictionRevision model for migration
+type PredictionRevision struct {
+	ID           int64  `gorm:"primaryKey"`
+	PredictionID int64  `gorm:"not null;uniqueIndex:idx_prediction_revision"`
+	Revision     int    `gorm:"not null;uniqueIndex:idx_prediction_revision"`
+	Outcome      string `gorm:"not null;size:10"`
+	Confidence   float64
+	Reasoning    string    `gorm:"size:2000"`
+	RevisedAt    time.Time `gorm:"not null"`
+}
+
+// revisedPrediction adds the revision bookkeeping to predictions.
+type revisedPrediction struct {
+	Revision       int `gorm:"not null;default:1"`
+	ScoredRevision *int
+	LateFlip       bool `gorm:"default:false"`
+}
+
+func (revisedPrediction) TableName() string { return "predictions" }
+
+// Migration20260314PredictionRevisions adds the revision history of
+// predictions. Earlier overwrites are lost, so each existing prediction
+// starts with its current values as revision 1, dated when they were last
+// saved.
+func Migration20260314PredictionRevisions(db *gorm.DB) error {
+	if err := db.AutoMigrate(&PredictionRevision{}, &revisedPrediction{}); err != nil {
+		return err
+	}
+	return db.Exec(`INSERT INTO prediction_revisions (prediction_id, revision, outcome, confidence, reasoning, revised_at)
+		SELECT id, 1, outcome, confidence, reasoning, COALESCE(updated_at, predicted_at) FROM predictions
+		WHERE deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM prediction_revisions WHERE prediction_revisions.prediction_id = predictions.id)`).Error
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260315_prediction_lock", Migration20260315PredictionLock); err != nil {
+		log.Fatalf("Failed to register migration 20260315_prediction_lock: %v", err)
+	}
+}
+
+// lockedMarket adds the prediction lock window to markets.
+type lockedMarket struct {
+	PredictionLockHours float64 `gorm:"not null;default:0"`
+}
+
+func (lockedMarket) TableName() string { return "markets" }
+
+// Migration20260315PredictionLock lets markets lock predictions some hours
+// before they resolve. Existing markets lock at their resolution time.
+func Migration20260315PredictionLock(db *gorm.DB) error {
+	return db.AutoMigrate(&lockedMarket{})
+}
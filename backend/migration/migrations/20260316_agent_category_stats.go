@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260316_agent_category_stats", Migration20260316AgentCategoryStats); err != nil {
+		log.Fatalf("Failed to register migration 20260316_agent_category_stats: %v", err)
+	}
+}
+
+// AgentCategoryStats model for migration
+type AgentCategoryStats struct {
+	AgentID                 int64   `gorm:"primaryKey;autoIncrement:false"`
+	Category                string  `gorm:"primaryKey;size:50;index"`
+	ResolvedPredictions     int64   `gorm:"not null;default:0"`
+	CorrectPredictions      int64   `gorm:"not null;default:0"`
+	AccuracyScore           float64 `gorm:"not null;default:50"`
+	ScoredPredictions       int64   `gorm:"not null;default:0"`
+	MeanBrierScore          float64 `gorm:"not null;default:0"`
+	CalibratedAccuracyScore float64 `gorm:"not null;default:50"`
+	UpdatedAt               time.Time
+}
+
+func (AgentCategoryStats) TableName() string { return "agent_category_stats" }
+
+// Migration20260316AgentCategoryStats adds per-category agent track records.
+// They are filled in as agents are rescored; POST
+// /v0/admin/recalculate-scores fills them in for every agent at once.
+func Migration20260316AgentCategoryStats(db *gorm.DB) error {
+	return db.AutoMigrate(&AgentCategoryStats{})
+}
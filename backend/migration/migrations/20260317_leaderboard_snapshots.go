@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260317_leaderboard_snapshots", Migration20260317LeaderboardSnapshots); err != nil {
+		log.Fatalf("Failed to register migration 20260317_leaderboard_snapshots: %v", err)
+	}
+}
+
+// LeaderboardSnapshot model for migration
+type LeaderboardSnapshot struct {
+	Window                  string    `gorm:"column:time_window;primaryKey;size:20"`
+	AgentID                 int64     `gorm:"primaryKey;autoIncrement:false"`
+	ResolvedPredictions     int64     `gorm:"not null;default:0"`
+	CorrectPredictions      int64     `gorm:"not null;default:0"`
+	AccuracyScore           float64   `gorm:"not null;default:50"`
+	ScoredPredictions       int64     `gorm:"not null;default:0"`
+	MeanBrierScore          float64   `gorm:"not null;default:0"`
+	CalibratedAccuracyScore float64   `gorm:"not null;default:50"`
+	ComputedAt              time.Time `gorm:"not null"`
+}
+
+// Migration20260317LeaderboardSnapshots adds the rolling-window leaderboards.
+// They are empty until the scheduler first takes a snapshot.
+func Migration20260317LeaderboardSnapshots(db *gorm.DB) error {
+	return db.AutoMigrate(&LeaderboardSnapshot{})
+}
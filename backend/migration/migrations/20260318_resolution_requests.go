@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260318_resolution_requests", Migration20260318ResolutionRequests); err != nil {
+		log.Fatalf("Failed to register migration 20260318_resolution_requests: %v", err)
+	}
+}
+
+// ResolutionRequest model for migration
+type ResolutionRequest struct {
+	ID                int64     `gorm:"primary_key"`
+	Version           int64     `gorm:"not null;default:1"`
+	MarketID          int64     `gorm:"not null;uniqueIndex"`
+	Status            string    `gorm:"not null;default:voting;index"`
+	VotesYes          int       `gorm:"not null;default:0"`
+	VotesNo           int       `gorm:"not null;default:0"`
+	VotesNA           int       `gorm:"not null;default:0"`
+	WeightYes         float64   `gorm:"not null;default:0"`
+	WeightNo          float64   `gorm:"not null;default:0"`
+	WeightNA          float64   `gorm:"not null;default:0"`
+	VotesRequired     int       `gorm:"not null"`
+	MinVoters         int       `gorm:"not null"`
+	ApprovalThreshold float64   `gorm:"not null"`
+	VotingEndsAt      time.Time `gorm:"index"`
+	Outcome           string
+	ResolvedAt        *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (ResolutionRequest) TableName() string { return "resolution_requests" }
+
+// ResolutionVote model for migration
+type ResolutionVote struct {
+	ID          int64   `gorm:"primary_key"`
+	RequestID   int64   `gorm:"not null;uniqueIndex:idx_resolution_vote_validator"`
+	ValidatorID int64   `gorm:"not null;index;uniqueIndex:idx_resolution_vote_validator"`
+	Outcome     string  `gorm:"not null"`
+	Reason      string  `gorm:"type:text"`
+	Evidence    string  `gorm:"type:text"`
+	Weight      float64 `gorm:"not null;default:1"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (ResolutionVote) TableName() string { return "resolution_votes" }
+
+// Migration20260318ResolutionRequests adds council resolution voting for
+// markets that do not resolve themselves from an oracle.
+func Migration20260318ResolutionRequests(db *gorm.DB) error {
+	return db.AutoMigrate(&ResolutionRequest{}, &ResolutionVote{})
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260319_resolution_disputes", Migration20260319ResolutionDisputes); err != nil {
+		log.Fatalf("Failed to register migration 20260319_resolution_disputes: %v", err)
+	}
+}
+
+// resolutionRound adds the round to resolution requests; a market now has
+// one request per round rather than one in all.
+type resolutionRound struct {
+	MarketID        int64 `gorm:"not null;uniqueIndex:idx_resolution_request_round"`
+	Round           int   `gorm:"not null;default:1;uniqueIndex:idx_resolution_request_round"`
+	DisputedOutcome string
+}
+
+func (resolutionRound) TableName() string { return "resolution_requests" }
+
+// ResolutionDispute model for migration
+type ResolutionDispute struct {
+	ID        int64   `gorm:"primary_key"`
+	MarketID  int64   `gorm:"not null;uniqueIndex:idx_resolution_dispute_agent"`
+	AgentID   int64   `gorm:"not null;index;uniqueIndex:idx_resolution_dispute_agent"`
+	Outcome   string  `gorm:"not null"`
+	Reason    string  `gorm:"type:text"`
+	Evidence  string  `gorm:"type:text"`
+	Weight    float64 `gorm:"not null;default:1"`
+	RequestID *int64  `gorm:"index"`
+	CreatedAt time.Time
+}
+
+func (ResolutionDispute) TableName() string { return "resolution_disputes" }
+
+// Migration20260319ResolutionDisputes lets a disputed market go back to the
+// council for a second round of resolution voting.
+func Migration20260319ResolutionDisputes(db *gorm.DB) error {
+	m := db.Migrator()
+	if m.HasIndex(&resolutionRound{}, "idx_resolution_requests_market_id") {
+		if err := m.DropIndex(&resolutionRound{}, "idx_resolution_requests_market_id"); err != nil {
+			return err
+		}
+	}
+	return db.AutoMigrate(&resolutionRound{}, &ResolutionDispute{})
+}
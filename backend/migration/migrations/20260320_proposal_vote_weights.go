@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260320_proposal_vote_weights", Migration20260320ProposalVoteWeights); err != nil {
+		log.Fatalf("Failed to register migration 20260320_proposal_vote_weights: %v", err)
+	}
+}
+
+// proposalWeights adds the weighted tally to proposals.
+type proposalWeights struct {
+	WeightFor     float64 `gorm:"default:0"`
+	WeightAgainst float64 `gorm:"default:0"`
+}
+
+func (proposalWeights) TableName() string { return "proposals" }
+
+// Migration20260320ProposalVoteWeights weights governance votes by the
+// voter's composite score. Votes cast so far carry the deprecated
+// reputation, so they are reweighted from each voter's current composite
+// score, and every proposal's weighted tally is computed from them.
+func Migration20260320ProposalVoteWeights(db *gorm.DB) error {
+	if err := db.AutoMigrate(&proposalWeights{}); err != nil {
+		return err
+	}
+	if err := db.Exec(`UPDATE proposal_votes SET weight = 1 + COALESCE(
+		(SELECT agents.composite_score FROM agents WHERE agents.id = proposal_votes.agent_id), 0) / 100`).Error; err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE proposals SET
+		weight_for = (SELECT COALESCE(SUM(weight), 0) FROM proposal_votes
+			WHERE proposal_votes.proposal_id = proposals.id AND proposal_votes.vote = 'yes' AND proposal_votes.deleted_at IS NULL),
+		weight_against = (SELECT COALESCE(SUM(weight), 0) FROM proposal_votes
+			WHERE proposal_votes.proposal_id = proposals.id AND proposal_votes.vote = 'no' AND proposal_votes.deleted_at IS NULL)`).Error
+}
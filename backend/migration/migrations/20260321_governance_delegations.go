@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260321_governance_delegations", Migration20260321GovernanceDelegations); err != nil {
+		log.Fatalf("Failed to register migration 20260321_governance_delegations: %v", err)
+	}
+}
+
+// GovernanceDelegation model for migration
+type GovernanceDelegation struct {
+	ID          int64 `gorm:"primary_key"`
+	DelegatorID int64 `gorm:"not null;index"`
+	DelegateID  int64 `gorm:"not null;index"`
+	CreatedAt   time.Time
+	RevokedAt   *time.Time `gorm:"index"`
+}
+
+func (GovernanceDelegation) TableName() string { return "governance_delegations" }
+
+// proposalVoteDelegate records which delegate cast a proposal vote.
+type proposalVoteDelegate struct {
+	DelegateID *int64 `gorm:"index"`
+}
+
+func (proposalVoteDelegate) TableName() string { return "proposal_votes" }
+
+// Migration20260321GovernanceDelegations lets agents delegate their
+// governance voting power to another agent.
+func Migration20260321GovernanceDelegations(db *gorm.DB) error {
+	return db.AutoMigrate(&GovernanceDelegation{}, &proposalVoteDelegate{})
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260322_proposal_revisions", Migration20260322ProposalRevisions); err != nil {
+		log.Fatalf("Failed to register migration 20260322_proposal_revisions: %v", err)
+	}
+}
+
+// ProposalRevision model for migration
+type ProposalRevision struct {
+	ID            int64  `gorm:"primaryKey"`
+	ProposalID    int64  `gorm:"not null;uniqueIndex:idx_proposal_revision"`
+	Revision      int    `gorm:"not null;uniqueIndex:idx_proposal_revision"`
+	Kind          string `gorm:"not null;size:20"`
+	Title         string `gorm:"not null;size:200"`
+	Description   string `gorm:"type:text"`
+	Specification string `gorm:"type:text"`
+	Priority      string `gorm:"size:20"`
+	Complexity    string `gorm:"size:20"`
+	Note          string `gorm:"size:500"`
+	VotingEndsAt  time.Time
+	RevisedAt     time.Time `gorm:"not null"`
+}
+
+func (ProposalRevision) TableName() string { return "proposal_revisions" }
+
+// proposalRevision adds the revision number to proposals.
+type proposalRevision struct {
+	Revision int `gorm:"not null;default:1"`
+}
+
+func (proposalRevision) TableName() string { return "proposals" }
+
+// Migration20260322ProposalRevisions lets proposers edit, amend and withdraw
+// proposals, keeping a changelog of their content. Existing proposals get
+// their current content as revision 1.
+func Migration20260322ProposalRevisions(db *gorm.DB) error {
+	if err := db.AutoMigrate(&proposalRevision{}, &ProposalRevision{}); err != nil {
+		return err
+	}
+	return db.Exec(`INSERT INTO proposal_revisions
+		(proposal_id, revision, kind, title, description, specification, priority, complexity, note, voting_ends_at, revised_at)
+		SELECT id, 1, 'created', title, description, specification, priority, complexity, '', voting_ends_at, created_at
+		FROM proposals
+		WHERE NOT EXISTS (SELECT 1 FROM proposal_revisions WHERE proposal_revisions.proposal_id = proposals.id)`).Error
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260323_proposal_voting_modes", Migration20260323ProposalVotingModes); err != nil {
+		log.Fatalf("Failed to register migration 20260323_proposal_voting_modes: %v", err)
+	}
+}
+
+// proposalVotingMode adds the voting mode to proposals.
+type proposalVotingMode struct {
+	VotingMode string `gorm:"not null;size:20;default:'weighted'"`
+}
+
+func (proposalVotingMode) TableName() string { return "proposals" }
+
+// Migration20260323ProposalVotingModes lets proposers choose how votes on
+// their proposal are tallied. Existing proposals were tallied by weight.
+func Migration20260323ProposalVotingModes(db *gorm.DB) error {
+	return db.AutoMigrate(&proposalVotingMode{})
+}
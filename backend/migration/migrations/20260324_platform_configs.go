@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260324_platform_configs", Migration20260324PlatformConfigs); err != nil {
+		log.Fatalf("Failed to register migration 20260324_platform_configs: %v", err)
+	}
+}
+
+// PlatformConfig model for migration
+type PlatformConfig struct {
+	Name       string  `gorm:"primaryKey;size:100"`
+	Kind       string  `gorm:"not null;size:10"`
+	Value      float64 `gorm:"not null"`
+	ProposalID *int64
+	UpdatedBy  string `gorm:"size:100"`
+	UpdatedAt  time.Time
+}
+
+func (PlatformConfig) TableName() string { return "platform_configs" }
+
+// Migration20260324PlatformConfigs stores platform parameters changed at
+// runtime. Parameters without a row keep their setup.yaml default.
+func Migration20260324PlatformConfigs(db *gorm.DB) error {
+	return db.AutoMigrate(&PlatformConfig{})
+}
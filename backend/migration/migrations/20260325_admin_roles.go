@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260325_admin_roles", Migration20260325AdminRoles); err != nil {
+		log.Fatalf("Failed to register migration 20260325_admin_roles: %v", err)
+	}
+}
+
+// AdminRole model for migration
+type AdminRole struct {
+	UserID    int64  `gorm:"primaryKey;autoIncrement:false"`
+	Role      string `gorm:"not null;size:20"`
+	GrantedBy string `gorm:"size:100"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (AdminRole) TableName() string { return "admin_roles" }
+
+// AuditLog model for migration
+type AuditLog struct {
+	ID        int64     `gorm:"primaryKey"`
+	Actor     string    `gorm:"not null;size:100;index"`
+	ActorName string    `gorm:"size:100"`
+	Role      string    `gorm:"size:20"`
+	Action    string    `gorm:"not null;size:200;index"`
+	Target    string    `gorm:"size:300;index"`
+	Before    string    `gorm:"type:text"`
+	After     string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (AuditLog) TableName() string { return "audit_logs" }
+
+// Migration20260325AdminRoles adds staff roles below admin and the audit
+// log of actions taken through the admin API.
+func Migration20260325AdminRoles(db *gorm.DB) error {
+	return db.AutoMigrate(&AdminRole{}, &AuditLog{})
+}
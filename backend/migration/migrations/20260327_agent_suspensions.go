@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260327_agent_suspensions", Migration20260327AgentSuspensions); err != nil {
+		log.Fatalf("Failed to register migration 20260327_agent_suspensions: %v", err)
+	}
+}
+
+// AgentSuspension model for migration
+type AgentSuspension struct {
+	ID        int64  `gorm:"primaryKey"`
+	AgentID   int64  `gorm:"not null;index"`
+	Kind      string `gorm:"not null;size:20"`
+	Reason    string `gorm:"not null;size:500"`
+	ExpiresAt *time.Time
+	CreatedBy string `gorm:"size:100"`
+	CreatedAt time.Time
+	LiftedAt  *time.Time
+	LiftedBy  string `gorm:"size:100"`
+}
+
+func (AgentSuspension) TableName() string { return "agent_suspensions" }
+
+// Migration20260327AgentSuspensions adds moderator suspensions and bans of
+// agents.
+func Migration20260327AgentSuspensions(db *gorm.DB) error {
+	return db.AutoMigrate(&AgentSuspension{})
+}
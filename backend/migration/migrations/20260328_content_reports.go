@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260328_content_reports", Migration20260328ContentReports); err != nil {
+		log.Fatalf("Failed to register migration 20260328_content_reports: %v", err)
+	}
+}
+
+// ContentReport model for migration
+type ContentReport struct {
+	ID           int64   `gorm:"primary_key"`
+	ContentType  string  `gorm:"not null;size:20;uniqueIndex:idx_content_report_reporter;index:idx_content_report_content"`
+	ContentID    int64   `gorm:"not null;uniqueIndex:idx_content_report_reporter;index:idx_content_report_content"`
+	ReporterType string  `gorm:"not null;size:10;uniqueIndex:idx_content_report_reporter"`
+	ReporterID   int64   `gorm:"not null;uniqueIndex:idx_content_report_reporter"`
+	Reason       string  `gorm:"not null;size:20"`
+	Details      string  `gorm:"size:500"`
+	Weight       float64 `gorm:"not null;default:1"`
+	CreatedAt    time.Time
+}
+
+func (ContentReport) TableName() string { return "content_reports" }
+
+// ModerationItem model for migration
+type ModerationItem struct {
+	ID           int64   `gorm:"primary_key"`
+	ContentType  string  `gorm:"not null;size:20;uniqueIndex:idx_moderation_item_content"`
+	ContentID    int64   `gorm:"not null;uniqueIndex:idx_moderation_item_content"`
+	Status       string  `gorm:"not null;size:20;index"`
+	ReportCount  int     `gorm:"not null;default:0"`
+	ReportWeight float64 `gorm:"not null;default:0"`
+	HiddenAt     *time.Time
+	ReviewedBy   string `gorm:"size:100"`
+	ReviewedAt   *time.Time
+	ReviewNote   string `gorm:"size:500"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (ModerationItem) TableName() string { return "moderation_items" }
+
+// Migration20260328ContentReports adds reports of markets, predictions and
+// comments and the moderation queue they feed.
+func Migration20260328ContentReports(db *gorm.DB) error {
+	return db.AutoMigrate(&ContentReport{}, &ModerationItem{})
+}
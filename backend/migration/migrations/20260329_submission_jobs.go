@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260329_submission_jobs", Migration20260329SubmissionJobs); err != nil {
+		log.Fatalf("Failed to register migration 20260329_submission_jobs: %v", err)
+	}
+}
+
+// SubmissionJob model for migration
+type SubmissionJob struct {
+	ID            int64     `gorm:"primaryKey"`
+	SubmissionID  int64     `gorm:"not null;uniqueIndex"`
+	Kind          string    `gorm:"not null;size:50"`
+	Status        string    `gorm:"not null;size:20;index:idx_submission_job_due,priority:1"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"index:idx_submission_job_due,priority:2"`
+	LastError     string    `gorm:"size:500"`
+	ResultID      int64
+	CreatedAt     time.Time
+	CompletedAt   *time.Time
+	UpdatedAt     time.Time
+}
+
+func (SubmissionJob) TableName() string { return "submission_jobs" }
+
+// Migration20260329SubmissionJobs adds the jobs that apply approved council
+// submissions, retried until the market or prediction is created.
+func Migration20260329SubmissionJobs(db *gorm.DB) error {
+	return db.AutoMigrate(&SubmissionJob{})
+}
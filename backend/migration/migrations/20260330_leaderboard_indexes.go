@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260330_leaderboard_indexes", Migration20260330LeaderboardIndexes); err != nil {
+		log.Fatalf("Failed to register migration 20260330_leaderboard_indexes: %v", err)
+	}
+}
+
+// AgentRanking model for migration
+type AgentRanking struct {
+	AgentID        int64     `gorm:"primaryKey;autoIncrement:false"`
+	Rank           int64     `gorm:"not null;uniqueIndex"`
+	CompositeScore float64   `gorm:"not null;default:0"`
+	ComputedAt     time.Time `gorm:"not null"`
+}
+
+func (AgentRanking) TableName() string { return "agent_rankings" }
+
+// Migration20260330LeaderboardIndexes indexes the agents leaderboards rank
+// on, replacing the index on the deprecated reputation column, and adds the
+// materialized all-time ranking.
+func Migration20260330LeaderboardIndexes(db *gorm.DB) error {
+	if err := db.AutoMigrate(&AgentRanking{}); err != nil {
+		return err
+	}
+	statements := []string{
+		"CREATE INDEX IF NOT EXISTS idx_agents_active_composite ON agents (is_active, composite_score DESC, id) WHERE deleted_at IS NULL",
+		"CREATE INDEX IF NOT EXISTS idx_agents_active_accuracy ON agents (is_active, accuracy_score DESC, id) WHERE deleted_at IS NULL",
+		"CREATE INDEX IF NOT EXISTS idx_agents_active_predictions ON agents (is_active, total_predictions DESC, id) WHERE deleted_at IS NULL",
+		"DROP INDEX IF EXISTS idx_agents_reputation",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
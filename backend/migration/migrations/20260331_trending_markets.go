@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260331_trending_markets", Migration20260331TrendingMarkets); err != nil {
+		log.Fatalf("Failed to register migration 20260331_trending_markets: %v", err)
+	}
+}
+
+// TrendingMarket model for migration
+type TrendingMarket struct {
+	MarketID         int64     `gorm:"primaryKey;autoIncrement:false"`
+	Rank             int64     `gorm:"not null;uniqueIndex"`
+	Score            float64   `gorm:"not null;default:0"`
+	Predictions      int64     `gorm:"not null;default:0"`
+	Velocity         float64   `gorm:"not null;default:0"`
+	Engagement       int64     `gorm:"not null;default:0"`
+	EngagementGrowth float64   `gorm:"not null;default:0"`
+	Volatility       float64   `gorm:"not null;default:0"`
+	ComputedAt       time.Time `gorm:"not null"`
+}
+
+func (TrendingMarket) TableName() string { return "trending_markets" }
+
+// Migration20260331TrendingMarkets adds the cached trending market ranking.
+func Migration20260331TrendingMarkets(db *gorm.DB) error {
+	return db.AutoMigrate(&TrendingMarket{})
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260401_market_watches", Migration20260401MarketWatches); err != nil {
+		log.Fatalf("Failed to register migration 20260401_market_watches: %v", err)
+	}
+}
+
+// MarketWatch model for migration
+type MarketWatch struct {
+	ID        int64 `gorm:"primaryKey"`
+	AgentID   int64 `gorm:"not null;uniqueIndex:idx_market_watches_agent_market,priority:1"`
+	MarketID  int64 `gorm:"not null;uniqueIndex:idx_market_watches_agent_market,priority:2;index"`
+	CreatedAt time.Time
+}
+
+func (MarketWatch) TableName() string { return "market_watches" }
+
+// Migration20260401MarketWatches adds agents' market watchlists.
+func Migration20260401MarketWatches(db *gorm.DB) error {
+	return db.AutoMigrate(&MarketWatch{})
+}
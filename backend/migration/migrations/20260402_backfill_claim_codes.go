@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260402_backfill_claim_codes", Migration20260402BackfillClaimCodes); err != nil {
+		log.Fatalf("Failed to register migration 20260402_backfill_claim_codes: %v", err)
+	}
+}
+
+// Migration20260402BackfillClaimCodes gives every unclaimed agent registered
+// before claim codes were stored a fresh one, so its claim URL alone no
+// longer claims it. Owners never saw these codes; an admin reissues one to
+// an owner who proves the agent is theirs.
+func Migration20260402BackfillClaimCodes(db *gorm.DB) error {
+	var ids []int64
+	if err := db.Table("agents").
+		Where("is_claimed = ? AND (claim_code IS NULL OR claim_code = '')", false).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		code, err := models.GenerateVerificationCode()
+		if err != nil {
+			return err
+		}
+		if err := db.Table("agents").Where("id = ?", id).Update("claim_code", code).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
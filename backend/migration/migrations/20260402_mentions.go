@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260402_mentions", Migration20260402Mentions); err != nil {
+		log.Fatalf("Failed to register migration 20260402_mentions: %v", err)
+	}
+}
+
+// Mention model for migration
+type Mention struct {
+	ID          int64  `gorm:"primaryKey"`
+	CommentType string `gorm:"not null;size:20;uniqueIndex:idx_mentions_comment_agent,priority:1"`
+	CommentID   int64  `gorm:"not null;uniqueIndex:idx_mentions_comment_agent,priority:2"`
+	AgentID     int64  `gorm:"not null;uniqueIndex:idx_mentions_comment_agent,priority:3;index"`
+	AuthorType  string `gorm:"not null;size:10"`
+	AuthorID    int64  `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+func (Mention) TableName() string { return "mentions" }
+
+// Migration20260402Mentions records the agents comments mention.
+func Migration20260402Mentions(db *gorm.DB) error {
+	return db.AutoMigrate(&Mention{})
+}
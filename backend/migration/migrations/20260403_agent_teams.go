@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260403_agent_teams", Migration20260403AgentTeams); err != nil {
+		log.Fatalf("Failed to register migration 20260403_agent_teams: %v", err)
+	}
+}
+
+// Team model for migration
+type Team struct {
+	ID          int64  `gorm:"primaryKey"`
+	Name        string `gorm:"not null;size:50;uniqueIndex"`
+	Description string `gorm:"size:500"`
+	CaptainID   int64  `gorm:"not null;index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (Team) TableName() string { return "teams" }
+
+// TeamMember model for migration
+type TeamMember struct {
+	ID        int64  `gorm:"primaryKey"`
+	TeamID    int64  `gorm:"not null;uniqueIndex:idx_team_members_team_agent,priority:1"`
+	AgentID   int64  `gorm:"not null;uniqueIndex:idx_team_members_team_agent,priority:2;index"`
+	Status    string `gorm:"not null;size:10"`
+	InvitedBy int64
+	CreatedAt time.Time
+	JoinedAt  *time.Time
+}
+
+func (TeamMember) TableName() string { return "team_members" }
+
+// teamPrediction adds the team tag to predictions.
+type teamPrediction struct {
+	TeamID *int64 `gorm:"index"`
+}
+
+func (teamPrediction) TableName() string { return "predictions" }
+
+// Migration20260403AgentTeams adds agent teams and tags predictions with
+// the team their agent was on. Existing predictions are left untagged.
+func Migration20260403AgentTeams(db *gorm.DB) error {
+	return db.AutoMigrate(&Team{}, &TeamMember{}, &teamPrediction{})
+}
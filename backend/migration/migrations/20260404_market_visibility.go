@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260404_market_visibility", Migration20260404MarketVisibility); err != nil {
+		log.Fatalf("Failed to register migration 20260404_market_visibility: %v", err)
+	}
+}
+
+// visibleMarket adds the visibility to markets.
+type visibleMarket struct {
+	Visibility string `gorm:"size:12;not null;default:public;index"`
+}
+
+func (visibleMarket) TableName() string { return "markets" }
+
+// MarketInvitation model for migration
+type MarketInvitation struct {
+	ID        int64 `gorm:"primaryKey"`
+	MarketID  int64 `gorm:"not null;uniqueIndex:idx_market_invitations_market_agent,priority:1"`
+	AgentID   int64 `gorm:"not null;uniqueIndex:idx_market_invitations_market_agent,priority:2;index"`
+	CreatedAt time.Time
+}
+
+func (MarketInvitation) TableName() string { return "market_invitations" }
+
+// Migration20260404MarketVisibility lets markets be unlisted or
+// invite-only. Existing markets stay public.
+func Migration20260404MarketVisibility(db *gorm.DB) error {
+	return db.AutoMigrate(&visibleMarket{}, &MarketInvitation{})
+}
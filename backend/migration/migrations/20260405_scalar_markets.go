@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.Register("20260405_scalar_markets", Migration20260405ScalarMarkets); err != nil {
+		log.Fatalf("Failed to register migration 20260405_scalar_markets: %v", err)
+	}
+}
+
+// scalarMarket adds the range, unit and resolved value of scalar markets.
+type scalarMarket struct {
+	ScalarMin       *float64
+	ScalarMax       *float64
+	ScalarUnit      string `gorm:"size:20"`
+	ResolutionValue *float64
+}
+
+func (scalarMarket) TableName() string { return "markets" }
+
+// scalarPrediction adds the estimate, interval and CRPS of scalar predictions.
+type scalarPrediction struct {
+	Estimate *float64
+	Low      *float64
+	High     *float64
+	CRPS     *float64 `gorm:"column:crps"`
+}
+
+func (scalarPrediction) TableName() string { return "predictions" }
+
+// scalarRevision adds the estimate and interval to prediction revisions.
+type scalarRevision struct {
+	Estimate *float64
+	Low      *float64
+	High     *float64
+}
+
+func (scalarRevision) TableName() string { return "prediction_revisions" }
+
+// scalarConsensusPoint adds the median value of scalar consensus points.
+type scalarConsensusPoint struct {
+	Value *float64
+}
+
+func (scalarConsensusPoint) TableName() string { return "consensus_points" }
+
+// Migration20260405ScalarMarkets lets markets ask for a number in a range
+// rather than YES or NO. Existing markets stay binary.
+func Migration20260405ScalarMarkets(db *gorm.DB) error {
+	return db.AutoMigrate(&scalarMarket{}, &scalarPrediction{}, &scalarRevision{}, &scalarConsensusPoint{})
+}
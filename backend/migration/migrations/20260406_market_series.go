@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260406_market_series", Migration20260406MarketSeries, Down20260406MarketSeries); err != nil {
+		log.Fatalf("Failed to register migration 20260406_market_series: %v", err)
+	}
+}
+
+// seriesMarket adds the series a market belongs to.
+type seriesMarket struct {
+	SeriesID *int64 `gorm:"index"`
+}
+
+func (seriesMarket) TableName() string { return "markets" }
+
+// MarketSeries model for migration
+type MarketSeries struct {
+	ID                int64  `gorm:"primaryKey"`
+	Title             string `gorm:"not null;size:160"`
+	Description       string `gorm:"size:2000"`
+	CreatorAgentID    int64  `gorm:"not null;index"`
+	MutuallyExclusive bool   `gorm:"not null;default:false"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (MarketSeries) TableName() string { return "market_series" }
+
+// Migration20260406MarketSeries groups related markets into series.
+// Existing markets belong to none.
+func Migration20260406MarketSeries(db *gorm.DB) error {
+	return db.AutoMigrate(&seriesMarket{}, &MarketSeries{})
+}
+
+// Down20260406MarketSeries drops market series.
+func Down20260406MarketSeries(db *gorm.DB) error {
+	if err := dropColumns(db, &seriesMarket{}, "SeriesID"); err != nil {
+		return err
+	}
+	return db.Migrator().DropTable(&MarketSeries{})
+}
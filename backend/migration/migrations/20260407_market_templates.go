@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260407_market_templates", Migration20260407MarketTemplates, Down20260407MarketTemplates); err != nil {
+		log.Fatalf("Failed to register migration 20260407_market_templates: %v", err)
+	}
+}
+
+// templateMarket adds the template a market was created from.
+type templateMarket struct {
+	TemplateID *int64 `gorm:"index"`
+}
+
+func (templateMarket) TableName() string { return "markets" }
+
+// MarketTemplate model for migration
+type MarketTemplate struct {
+	ID             int64     `gorm:"primaryKey"`
+	SubmissionID   int64     `gorm:"not null;uniqueIndex"`
+	CreatorAgentID int64     `gorm:"not null;index"`
+	QuestionTitle  string    `gorm:"not null;size:160"`
+	Schedule       string    `gorm:"not null;size:100"`
+	Timezone       string    `gorm:"not null;size:64"`
+	MarketType     string    `gorm:"not null;size:10;default:standard"`
+	Status         string    `gorm:"not null;size:10;default:active;index:idx_market_templates_due,priority:1"`
+	NextRunAt      time.Time `gorm:"index:idx_market_templates_due,priority:2"`
+	LastRunAt      *time.Time
+	Instances      int64  `gorm:"not null;default:0"`
+	LastError      string `gorm:"size:500"`
+	Spec           string `gorm:"type:text"`
+	Provenance     string `gorm:"type:text"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Migration20260407MarketTemplates adds market templates, from which the
+// scheduler creates recurring markets. Existing markets have no template.
+func Migration20260407MarketTemplates(db *gorm.DB) error {
+	return db.AutoMigrate(&templateMarket{}, &MarketTemplate{})
+}
+
+// Down20260407MarketTemplates drops market templates. Markets created from
+// them stay.
+func Down20260407MarketTemplates(db *gorm.DB) error {
+	if err := dropColumns(db, &templateMarket{}, "TemplateID"); err != nil {
+		return err
+	}
+	return db.Migrator().DropTable(&MarketTemplate{})
+}
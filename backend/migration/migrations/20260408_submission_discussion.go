@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260408_submission_discussion", Migration20260408SubmissionDiscussion, Down20260408SubmissionDiscussion); err != nil {
+		log.Fatalf("Failed to register migration 20260408_submission_discussion: %v", err)
+	}
+}
+
+// discussedSubmission adds change requests and the links between a
+// submission and its revision.
+type discussedSubmission struct {
+	ChangesRequestedAt *time.Time
+	RevisionOf         *int64 `gorm:"index"`
+	SupersededBy       *int64
+}
+
+func (discussedSubmission) TableName() string { return "pending_submissions" }
+
+// SubmissionComment model for migration
+type SubmissionComment struct {
+	ID           int64 `gorm:"primaryKey"`
+	SubmissionID int64 `gorm:"not null;index"`
+	AgentID      int64 `gorm:"not null;index"`
+	ParentID     *int64
+	Kind         string `gorm:"not null;size:20;default:comment"`
+	Content      string `gorm:"type:text;not null"`
+	CreatedAt    time.Time
+}
+
+// Migration20260408SubmissionDiscussion adds the council's discussion
+// threads on submissions. Existing submissions have no discussion and no
+// revisions.
+func Migration20260408SubmissionDiscussion(db *gorm.DB) error {
+	return db.AutoMigrate(&discussedSubmission{}, &SubmissionComment{})
+}
+
+// Down20260408SubmissionDiscussion drops the discussion threads and the
+// links between submissions and their revisions.
+func Down20260408SubmissionDiscussion(db *gorm.DB) error {
+	if err := dropColumns(db, &discussedSubmission{}, "ChangesRequestedAt", "RevisionOf", "SupersededBy"); err != nil {
+		return err
+	}
+	return db.Migrator().DropTable(&SubmissionComment{})
+}
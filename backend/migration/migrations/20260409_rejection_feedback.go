@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260409_rejection_feedback", Migration20260409RejectionFeedback, Down20260409RejectionFeedback); err != nil {
+		log.Fatalf("Failed to register migration 20260409_rejection_feedback: %v", err)
+	}
+}
+
+// rejectionVote adds the reason from the rejection taxonomy to a vote.
+type rejectionVote struct {
+	RejectionReason string `gorm:"size:20"`
+}
+
+func (rejectionVote) TableName() string { return "council_votes" }
+
+// revisedSubmission numbers a submission's revisions.
+type revisedSubmission struct {
+	Revision int `gorm:"not null;default:1"`
+}
+
+func (revisedSubmission) TableName() string { return "pending_submissions" }
+
+// Migration20260409RejectionFeedback adds rejection reasons to council
+// votes and revision numbers to submissions. Existing votes have no reason.
+// Existing submissions are first submissions, except those that revise
+// another (see 20260408_submission_discussion), which become second ones.
+func Migration20260409RejectionFeedback(db *gorm.DB) error {
+	if err := db.AutoMigrate(&rejectionVote{}, &revisedSubmission{}); err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE pending_submissions SET revision = 2 WHERE revision_of IS NOT NULL`).Error
+}
+
+// Down20260409RejectionFeedback drops rejection reasons and revision
+// numbers.
+func Down20260409RejectionFeedback(db *gorm.DB) error {
+	if err := dropColumns(db, &rejectionVote{}, "RejectionReason"); err != nil {
+		return err
+	}
+	return dropColumns(db, &revisedSubmission{}, "Revision")
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260410_verification_rules", Migration20260410VerificationRules, Down20260410VerificationRules); err != nil {
+		log.Fatalf("Failed to register migration 20260410_verification_rules: %v", err)
+	}
+}
+
+// VerificationRule model for migration
+type VerificationRule struct {
+	Name       string `gorm:"primaryKey;size:64"`
+	Kind       string `gorm:"not null;size:10"`
+	Severity   string `gorm:"not null;size:10"`
+	Field      string `gorm:"size:20"`
+	Patterns   string `gorm:"type:text"`
+	Message    string `gorm:"size:200"`
+	ProposalID *int64
+	UpdatedBy  string `gorm:"size:100"`
+	UpdatedAt  time.Time
+}
+
+// Migration20260410VerificationRules adds the verification rules set at
+// runtime. There are none yet, so the built-in rules apply.
+func Migration20260410VerificationRules(db *gorm.DB) error {
+	return db.AutoMigrate(&VerificationRule{})
+}
+
+// Down20260410VerificationRules drops the runtime rules, leaving the
+// built-in ones.
+func Down20260410VerificationRules(db *gorm.DB) error {
+	return db.Migrator().DropTable(&VerificationRule{})
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260411_content_safety", Migration20260411ContentSafety, Down20260411ContentSafety); err != nil {
+		log.Fatalf("Failed to register migration 20260411_content_safety: %v", err)
+	}
+}
+
+// flaggedModerationItem records what the content safety scan flagged.
+type flaggedModerationItem struct {
+	SafetyFlags string `gorm:"size:100"`
+	FlaggedAt   *time.Time
+}
+
+func (flaggedModerationItem) TableName() string { return "moderation_items" }
+
+// Migration20260411ContentSafety adds the content safety flags to the
+// moderation queue. Existing items were reported, not flagged.
+func Migration20260411ContentSafety(db *gorm.DB) error {
+	return db.AutoMigrate(&flaggedModerationItem{})
+}
+
+// Down20260411ContentSafety drops the content safety flags. Flagged items
+// stay in the queue as if reported.
+func Down20260411ContentSafety(db *gorm.DB) error {
+	return dropColumns(db, &flaggedModerationItem{}, "SafetyFlags", "FlaggedAt")
+}
@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260412_reasoning_quality", Migration20260412ReasoningQuality, Down20260412ReasoningQuality); err != nil {
+		log.Fatalf("Failed to register migration 20260412_reasoning_quality: %v", err)
+	}
+}
+
+// ReasoningReview model for migration
+type ReasoningReview struct {
+	ID               int64  `gorm:"primaryKey"`
+	PredictionID     int64  `gorm:"not null;uniqueIndex:idx_reasoning_review"`
+	Revision         int    `gorm:"not null;uniqueIndex:idx_reasoning_review"`
+	ValidatorAgentID int64  `gorm:"not null;uniqueIndex:idx_reasoning_review;index"`
+	Rating           int    `gorm:"not null"`
+	Note             string `gorm:"size:500"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// scoredReasoningPrediction adds the reasoning score to predictions.
+type scoredReasoningPrediction struct {
+	ReasoningScore   float64 `gorm:"default:0"`
+	ReasoningReviews int64   `gorm:"default:0"`
+}
+
+func (scoredReasoningPrediction) TableName() string { return "predictions" }
+
+// reasoningAgent adds the mean reasoning score to agents.
+type reasoningAgent struct {
+	MeanReasoningScore float64 `gorm:"default:0"`
+}
+
+func (reasoningAgent) TableName() string { return "agents" }
+
+// Migration20260412ReasoningQuality adds validators' spot reviews of
+// prediction reasoning and reasoning scores, scoring existing predictions'
+// reasoning by the heuristic alone and averaging them per agent. Engagement
+// scores take the new averages into account on their next recalculation.
+func Migration20260412ReasoningQuality(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&ReasoningReview{}, &scoredReasoningPrediction{}, &reasoningAgent{}); err != nil {
+			return err
+		}
+
+		type predictionRow struct {
+			ID        int64
+			Reasoning string
+		}
+		var batch []predictionRow
+		err := tx.Table("predictions").Select("id, reasoning").Where("reasoning <> ''").
+			FindInBatches(&batch, 500, func(batchTx *gorm.DB, _ int) error {
+				for _, p := range batch {
+					if err := tx.Table("predictions").Where("id = ?", p.ID).
+						Update("reasoning_score", models.ReasoningHeuristic(p.Reasoning)).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE agents SET mean_reasoning_score =
+			(SELECT COALESCE(AVG(reasoning_score), 0) FROM predictions WHERE predictions.agent_id = agents.id AND predictions.deleted_at IS NULL)`).Error
+	})
+}
+
+// Down20260412ReasoningQuality drops the reasoning reviews and scores.
+func Down20260412ReasoningQuality(db *gorm.DB) error {
+	if err := dropColumns(db, &reasoningAgent{}, "MeanReasoningScore"); err != nil {
+		return err
+	}
+	if err := dropColumns(db, &scoredReasoningPrediction{}, "ReasoningScore", "ReasoningReviews"); err != nil {
+		return err
+	}
+	return db.Migrator().DropTable(&ReasoningReview{})
+}
@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260413_achievements", Migration20260413Achievements, Down20260413Achievements); err != nil {
+		log.Fatalf("Failed to register migration 20260413_achievements: %v", err)
+	}
+}
+
+// Achievement model for migration
+type Achievement struct {
+	ID        int64     `gorm:"primaryKey"`
+	AgentID   int64     `gorm:"not null;uniqueIndex:idx_agent_achievement"`
+	Key       string    `gorm:"not null;size:50;uniqueIndex:idx_agent_achievement;index"`
+	AwardedAt time.Time `gorm:"not null"`
+}
+
+// OutboxCursor model for migration
+type OutboxCursor struct {
+	Consumer    string `gorm:"primaryKey;size:50"`
+	LastEventID int64  `gorm:"not null;default:0"`
+	UpdatedAt   time.Time
+}
+
+// achievementAgent adds the correct prediction streaks and badges to
+// agents.
+type achievementAgent struct {
+	CorrectStreak     int64  `gorm:"default:0"`
+	BestCorrectStreak int64  `gorm:"default:0"`
+	Badges            string `gorm:"size:255"`
+}
+
+func (achievementAgent) TableName() string { return "agents" }
+
+// Migration20260413Achievements adds badges and the outbox cursor the
+// achievements job reads from. There is no cursor yet, so the job starts
+// from the first event and its first runs award the badges agents already
+// earned.
+func Migration20260413Achievements(db *gorm.DB) error {
+	return db.AutoMigrate(&Achievement{}, &OutboxCursor{}, &achievementAgent{})
+}
+
+// Down20260413Achievements drops badges, streaks and the outbox cursor.
+func Down20260413Achievements(db *gorm.DB) error {
+	if err := dropColumns(db, &achievementAgent{}, "CorrectStreak", "BestCorrectStreak", "Badges"); err != nil {
+		return err
+	}
+	return db.Migrator().DropTable(&Achievement{}, &OutboxCursor{})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260414_data_erasure", Migration20260414DataErasure, Down20260414DataErasure); err != nil {
+		log.Fatalf("Failed to register migration 20260414_data_erasure: %v", err)
+	}
+}
+
+// DataErasure model for migration
+type DataErasure struct {
+	ID              int64     `gorm:"primaryKey"`
+	AgentID         int64     `gorm:"not null;index"`
+	RequestedBy     string    `gorm:"size:100"`
+	RequesterUserID *int64    `gorm:"index"`
+	Status          string    `gorm:"not null;size:20;index"`
+	RequestedAt     time.Time `gorm:"not null"`
+	ScheduledFor    time.Time `gorm:"not null;index"`
+	CompletedAt     *time.Time
+	CancelledAt     *time.Time
+	Pseudonym       string `gorm:"size:50"`
+	Erased          string `gorm:"type:text"`
+	CertificateHash string `gorm:"size:64"`
+}
+
+// Migration20260414DataErasure adds agent data erasures, which become
+// deletion certificates once carried out.
+func Migration20260414DataErasure(db *gorm.DB) error {
+	return db.AutoMigrate(&DataErasure{})
+}
+
+// Down20260414DataErasure drops the erasures and their certificates. Data
+// already erased stays erased.
+func Down20260414DataErasure(db *gorm.DB) error {
+	return db.Migrator().DropTable(&DataErasure{})
+}
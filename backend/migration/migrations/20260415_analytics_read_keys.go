@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"log"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	if err := migration.RegisterReversible("20260415_analytics_read_keys", Migration20260415AnalyticsReadKeys, Down20260415AnalyticsReadKeys); err != nil {
+		log.Fatalf("Failed to register migration 20260415_analytics_read_keys: %v", err)
+	}
+}
+
+// analyticsReadKey adds the analytics flag to read-only keys.
+type analyticsReadKey struct {
+	Analytics bool `gorm:"not null;default:false"`
+}
+
+func (analyticsReadKey) TableName() string { return "read_api_keys" }
+
+// Migration20260415AnalyticsReadKeys lets read-only keys be marked as
+// analytics keys, which have their own, higher rate limit. Existing keys
+// stay ordinary read-only keys.
+func Migration20260415AnalyticsReadKeys(db *gorm.DB) error {
+	return db.AutoMigrate(&analyticsReadKey{})
+}
+
+// Down20260415AnalyticsReadKeys drops the flag; analytics keys go back to
+// the read-only key limit.
+func Down20260415AnalyticsReadKeys(db *gorm.DB) error {
+	return dropColumns(db, &analyticsReadKey{}, "Analytics")
+}
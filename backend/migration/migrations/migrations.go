@@ -0,0 +1,38 @@
+// Package migrations registers the schema migrations, one per file, named
+// after the migration's ID.
+package migrations
+
+import (
+	"embed"
+
+	"socialpredict/migration"
+
+	"gorm.io/gorm"
+)
+
+// sources are the migration files, whose checksums are recorded as they
+// are applied.
+//
+//go:embed *.go
+var sources embed.FS
+
+func init() {
+	migration.UseSources(sources)
+}
+
+// dropColumns drops the named fields of model's table, with their indexes.
+// It is for the Down of migrations that added columns.
+func dropColumns(db *gorm.DB, model interface{}, fields ...string) error {
+	migrator := db.Migrator()
+	for _, field := range fields {
+		if migrator.HasIndex(model, field) {
+			if err := migrator.DropIndex(model, field); err != nil {
+				return err
+			}
+		}
+		if err := migrator.DropColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"socialpredict/migration"
+	"socialpredict/models/modelstesting"
+)
+
+func TestReversibleMigrations_RollBackAndReapply(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	statuses, err := migration.StatusOf(db)
+	if err != nil {
+		t.Fatalf("StatusOf: %v", err)
+	}
+	steps := 0
+	for i := len(statuses) - 1; i >= 0 && statuses[i].Reversible; i-- {
+		steps++
+	}
+	if steps == 0 {
+		t.Fatalf("expected the latest migrations to be reversible")
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.Checksum == "" || s.AppliedChecksum != s.Checksum {
+			t.Fatalf("expected %s applied with its checksum: %+v", s.ID, s)
+		}
+	}
+
+	if _, err := migration.Rollback(db, steps); err != nil {
+		t.Fatalf("Rollback(%d): %v", steps, err)
+	}
+	if db.Migrator().HasTable("data_erasures") || db.Migrator().HasColumn("markets", "series_id") {
+		t.Fatalf("expected the rolled back tables and columns dropped")
+	}
+
+	if err := migration.Run(db); err != nil {
+		t.Fatalf("Run after rollback: %v", err)
+	}
+	if !db.Migrator().HasTable("data_erasures") || !db.Migrator().HasColumn("markets", "series_id") {
+		t.Fatalf("expected the migrations applied again")
+	}
+}
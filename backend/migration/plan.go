@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Plan is the SQL a migration runs in one direction, "up" or "down".
+type Plan struct {
+	ID        string   `json:"id"`
+	Direction string   `json:"direction"`
+	SQL       []string `json:"sql"`
+}
+
+// errDryRun rolls back a dry run's transaction.
+var errDryRun = errors.New("dry run")
+
+// sqlRecorder is a GORM logger that keeps the statements that change the
+// schema or data, leaving out reads and savepoints.
+type sqlRecorder struct {
+	mu  sync.Mutex
+	sql []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	sql = strings.TrimSpace(sql)
+	verb := strings.ToUpper(strings.SplitN(sql, " ", 2)[0])
+	switch verb {
+	case "", "SELECT", "PRAGMA", "SHOW", "SAVEPOINT", "RELEASE", "ROLLBACK":
+		return
+	}
+	r.mu.Lock()
+	r.sql = append(r.sql, sql)
+	r.mu.Unlock()
+}
+
+// dryRun runs each step in one transaction that is then rolled back,
+// recording the SQL of each. Later steps see the changes of earlier ones,
+// as they would for real.
+func dryRun(db *gorm.DB, migrations []Migration, direction string) ([]Plan, error) {
+	plans := make([]Plan, 0, len(migrations))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range migrations {
+			recorder := &sqlRecorder{}
+			step := m.Up
+			if direction == "down" {
+				step = m.Down
+			}
+			if err := step(tx.Session(&gorm.Session{Logger: recorder})); err != nil {
+				return err
+			}
+			plans = append(plans, Plan{ID: m.ID, Direction: direction, SQL: recorder.sql})
+		}
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// DryRun returns the SQL the pending migrations would run, without
+// changing the database: they run in a transaction that is rolled back.
+func DryRun(db *gorm.DB) ([]Plan, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, id := range sortedIDs() {
+		if _, ok := applied[id]; ok {
+			continue
+		}
+		if registry[id].Up == nil {
+			return nil, errors.New("migration " + id + " has nil Up()")
+		}
+		pending = append(pending, registry[id])
+	}
+	return dryRun(db, pending, "up")
+}
+
+// DryRunRollback returns the SQL Rollback would run for steps, without
+// changing the database.
+func DryRunRollback(db *gorm.DB, steps int) ([]Plan, error) {
+	migrations, err := lastApplied(db, steps)
+	if err != nil {
+		return nil, err
+	}
+	return dryRun(db, migrations, "down")
+}
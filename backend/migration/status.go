@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status is where a migration stands against a database.
+type Status struct {
+	ID        string     `json:"id"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// Checksum of the migration's source in this build, and as it was when
+	// applied.
+	Checksum        string `json:"checksum,omitempty"`
+	AppliedChecksum string `json:"appliedChecksum,omitempty"`
+	// Modified is set when the source changed since the migration was
+	// applied.
+	Modified   bool `json:"modified"`
+	Reversible bool `json:"reversible"`
+	// Unknown is set for a migration applied to the database that this
+	// build does not have, as after a downgrade.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// StatusOf returns every migration registered or applied, in ID order.
+func StatusOf(db *gorm.DB) ([]Status, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := sortedIDs()
+	for id := range applied {
+		if _, ok := registry[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	statuses := make([]Status, 0, len(ids))
+	for _, id := range ids {
+		m, known := registry[id]
+		status := Status{ID: id, Unknown: !known, Reversible: known && m.Down != nil}
+		if known {
+			status.Checksum = m.Checksum()
+		}
+		if row, ok := applied[id]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.AppliedChecksum = row.Checksum
+			status.Modified = row.Checksum != "" && status.Checksum != "" && row.Checksum != status.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
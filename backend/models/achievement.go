@@ -0,0 +1,117 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Achievement keys.
+const (
+	AchievementCorrectStreak  = "correct_streak_10"
+	AchievementFirstMarket    = "first_market_approved"
+	AchievementPredictions100 = "predictions_100"
+	AchievementWeeklyTopTen   = "weekly_top_10"
+)
+
+// Achievement thresholds.
+const (
+	AchievementStreakLength     = 10  // correct predictions in a row
+	AchievementPredictionsCount = 100 // predictions made
+	AchievementWeeklyRank       = 10  // places on the weekly leaderboard
+)
+
+// AchievementDefinition describes a badge agents can earn.
+type AchievementDefinition struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AchievementDefinitions are the badges agents can earn, in the order they
+// are shown.
+var AchievementDefinitions = []AchievementDefinition{
+	{Key: AchievementCorrectStreak, Name: "Hot Streak", Description: "Ten resolved predictions in a row correct"},
+	{Key: AchievementFirstMarket, Name: "Market Maker", Description: "First market submission approved by the council"},
+	{Key: AchievementPredictions100, Name: "Centurion", Description: "One hundred predictions made"},
+	{Key: AchievementWeeklyTopTen, Name: "Weekly Top 10", Description: "Ranked in the top ten of the weekly leaderboard"},
+}
+
+// AchievementDefinitionFor returns the definition of the badge key, if
+// there is one.
+func AchievementDefinitionFor(key string) (AchievementDefinition, bool) {
+	for _, d := range AchievementDefinitions {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return AchievementDefinition{}, false
+}
+
+// Achievement is a badge an agent earned. Badges are kept once earned, even
+// if a later re-resolution breaks the streak that earned them.
+type Achievement struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	AgentID   int64     `json:"agentId" gorm:"not null;uniqueIndex:idx_agent_achievement"`
+	Key       string    `json:"key" gorm:"not null;size:50;uniqueIndex:idx_agent_achievement;index"`
+	AwardedAt time.Time `json:"awardedAt" gorm:"not null"`
+}
+
+// AchievementPublic is an earned badge with its definition.
+type AchievementPublic struct {
+	AchievementDefinition
+	AwardedAt time.Time `json:"awardedAt"`
+}
+
+// ToPublic returns the badge with its definition.
+func (a Achievement) ToPublic() AchievementPublic {
+	definition, ok := AchievementDefinitionFor(a.Key)
+	if !ok {
+		definition = AchievementDefinition{Key: a.Key, Name: a.Key}
+	}
+	return AchievementPublic{AchievementDefinition: definition, AwardedAt: a.AwardedAt}
+}
+
+// BadgeSummary is a badge as shown on an agent's profile.
+type BadgeSummary struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// BadgeSummaries returns the agent's badges, in the order they are shown.
+func (a *Agent) BadgeSummaries() []BadgeSummary {
+	if a.Badges == "" {
+		return nil
+	}
+	earned := strings.Split(a.Badges, ",")
+	var badges []BadgeSummary
+	for _, d := range AchievementDefinitions {
+		for _, key := range earned {
+			if key == d.Key {
+				badges = append(badges, BadgeSummary{Key: d.Key, Name: d.Name})
+				break
+			}
+		}
+	}
+	return badges
+}
+
+// HasBadge reports whether the agent earned the badge key.
+func (a *Agent) HasBadge(key string) bool {
+	for _, earned := range strings.Split(a.Badges, ",") {
+		if earned == key {
+			return true
+		}
+	}
+	return false
+}
+
+// AddBadge records that the agent earned the badge key.
+func (a *Agent) AddBadge(key string) {
+	if a.HasBadge(key) {
+		return
+	}
+	if a.Badges != "" {
+		a.Badges += ","
+	}
+	a.Badges += key
+}
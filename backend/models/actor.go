@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ActorType identifies which table an Actor's ID refers to.
+type ActorType string
+
+const (
+	ActorTypeAgent ActorType = "agent"
+	ActorTypeUser  ActorType = "user"
+)
+
+// Actor is anyone who can vote, comment, follow or create a market: an AI
+// agent (agents.id) or a human user (users.id). Records that can be written
+// by either store the pair as <prefix>_type and <prefix>_id columns and
+// expose it through an accessor, so callers never compare type strings.
+type Actor struct {
+	Type ActorType `json:"type"`
+	ID   int64     `json:"id"`
+}
+
+// AgentActor returns the actor for the agent with the given ID.
+func AgentActor(id int64) Actor {
+	return Actor{Type: ActorTypeAgent, ID: id}
+}
+
+// UserActor returns the actor for the user with the given ID.
+func UserActor(id int64) Actor {
+	return Actor{Type: ActorTypeUser, ID: id}
+}
+
+// IsAgent reports whether the actor is an AI agent.
+func (a Actor) IsAgent() bool {
+	return a.Type == ActorTypeAgent
+}
+
+// IsUser reports whether the actor is a human user.
+func (a Actor) IsUser() bool {
+	return a.Type == ActorTypeUser
+}
+
+// Valid reports whether the actor has a known type and a positive ID.
+func (a Actor) Valid() bool {
+	return (a.Type == ActorTypeAgent || a.Type == ActorTypeUser) && a.ID > 0
+}
+
+// String renders the actor as "type:id" for logs and error messages.
+func (a Actor) String() string {
+	return fmt.Sprintf("%s:%d", a.Type, a.ID)
+}
+
+// agentShadowPrefix marks the user rows that anchor agent bets, which still
+// reference users.username.
+const agentShadowPrefix = "agent:"
+
+// AgentShadowUsername returns the username of the user row that anchors
+// bets placed by the named agent.
+func AgentShadowUsername(agentName string) string {
+	return agentShadowPrefix + agentName
+}
+
+// IsAgentShadowUsername reports whether username belongs to an agent's
+// shadow user rather than a human.
+func IsAgentShadowUsername(username string) bool {
+	return strings.HasPrefix(username, agentShadowPrefix)
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Admin job statuses.
+const (
+	AdminJobQueued    = "queued"
+	AdminJobRunning   = "running"
+	AdminJobSucceeded = "succeeded"
+	AdminJobFailed    = "failed"
+	AdminJobCancelled = "cancelled"
+)
+
+// Admin job kinds.
+const (
+	AdminJobRecalculateScores = "recalculate-scores"
+)
+
+// AdminJob is a long admin operation run in the background. Processed and
+// Total report progress while it runs; CancelRequested asks the instance
+// running it to stop at the next progress report.
+type AdminJob struct {
+	ID              int64      `json:"id" gorm:"primaryKey"`
+	Kind            string     `json:"kind" gorm:"not null;size:50;index"`
+	Status          string     `json:"status" gorm:"not null;size:20;index"`
+	RequestedBy     string     `json:"requestedBy" gorm:"size:100"`
+	Processed       int        `json:"processed"`
+	Total           int        `json:"total"`
+	Updated         int        `json:"updated"`
+	CancelRequested bool       `json:"cancelRequested"`
+	Error           string     `json:"error,omitempty" gorm:"size:500"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// Finished reports whether the job has stopped for good.
+func (j *AdminJob) Finished() bool {
+	return j.Status == AdminJobSucceeded || j.Status == AdminJobFailed || j.Status == AdminJobCancelled
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Staff roles. Admins can do everything; moderators review content and
+// proposals; operators run jobs and watch the platform.
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleOperator  = "operator"
+)
+
+// StaffRoles are the roles that can be granted to a user.
+var StaffRoles = []string{RoleAdmin, RoleModerator, RoleOperator}
+
+// AdminRole grants a user a staff role. Users of type ADMIN are admins
+// without one.
+type AdminRole struct {
+	UserID    int64     `json:"userId" gorm:"primaryKey;autoIncrement:false"`
+	Role      string    `json:"role" gorm:"not null;size:20"`
+	GrantedBy string    `json:"grantedBy" gorm:"size:100"` // principal ID of the admin who granted it
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// StaffRole returns user's staff role, or "" if they are not staff.
+func StaffRole(db *gorm.DB, user *User) (string, error) {
+	if user.UserType == "ADMIN" {
+		return RoleAdmin, nil
+	}
+	var role AdminRole
+	err := db.Where("user_id = ?", user.ID).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return role.Role, err
+}
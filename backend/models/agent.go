@@ -13,7 +13,9 @@ import (
 type Agent struct {
 	gorm.Model
 	ID          int64  `json:"id" gorm:"primary_key"`
+	Version     LockVersion `json:"-" gorm:"not null;default:1"` // optimistic locking
 	Name        string `json:"name" gorm:"unique;not null;size:50"`
+	NameSkeleton string `json:"-" gorm:"size:50;index"` // AgentNameSkeleton(Name), kept by BeforeSave
 	Description string `json:"description" gorm:"size:500"`
 
 	// Authentication
@@ -22,6 +24,7 @@ type Agent struct {
 	// Ownership - human who claimed this agent
 	OwnerUserID *int64     `json:"ownerUserId,omitempty"`
 	ClaimToken  string     `json:"-" gorm:"unique"` // Used for claim verification
+	ClaimCode   string     `json:"-" gorm:"size:30"` // Verification code shown at registration, checked on claim
 	ClaimedAt   *time.Time `json:"claimedAt,omitempty"`
 
 	// === KNOWLEDGE-BASED SCORING SYSTEM ===
@@ -31,12 +34,21 @@ type Agent struct {
 	CorrectPredictions int64 `json:"correctPredictions" gorm:"default:0"`
 	ResolvedPredictions int64 `json:"resolvedPredictions" gorm:"default:0"`
 
+	// Calibration over resolved predictions with a Brier score, see Prediction.Score
+	ScoredPredictions int64   `json:"scoredPredictions" gorm:"default:0"`
+	MeanBrierScore    float64 `json:"meanBrierScore" gorm:"default:0"`
+	MeanLogLoss       float64 `json:"meanLogLoss" gorm:"default:0"`
+
+	// Mean reasoning score of the agent's predictions, see ReasoningScore
+	MeanReasoningScore float64 `json:"meanReasoningScore" gorm:"default:0"`
+
 	// Reputation Scores (0-100 scale)
 	AccuracyScore   float64 `json:"accuracyScore" gorm:"default:50"`   // Prediction accuracy
 	EngagementScore float64 `json:"engagementScore" gorm:"default:0"`  // Social engagement received
 	CreatorScore    float64 `json:"creatorScore" gorm:"default:0"`     // Market creation quality
 	ActivityScore   float64 `json:"activityScore" gorm:"default:0"`    // Consistent participation
 	CompositeScore  float64 `json:"compositeScore" gorm:"default:12.5"` // Weighted combination
+	CalibratedAccuracyScore float64 `json:"calibratedAccuracyScore" gorm:"default:50"` // Confidence-aware accuracy, not in the composite
 
 	// Legacy field - kept for backward compatibility but no longer used
 	Reputation float64 `json:"reputation" gorm:"default:0.5"`
@@ -54,6 +66,14 @@ type Agent struct {
 	LongestStreak   int64      `json:"longestStreak" gorm:"default:0"`
 	DaysActiveMonth int64      `json:"daysActiveMonth" gorm:"default:0"`
 
+	// Resolved predictions correct in a row, in resolution order, kept by
+	// the achievements job
+	CorrectStreak     int64 `json:"correctStreak" gorm:"default:0"`
+	BestCorrectStreak int64 `json:"bestCorrectStreak" gorm:"default:0"`
+
+	// Badges earned: comma-separated achievement keys, see BadgeSummaries
+	Badges string `json:"-" gorm:"size:255"`
+
 	// Creator Stats
 	MarketsCreated      int64   `json:"marketsCreated" gorm:"default:0"`
 	MarketEngagementAvg float64 `json:"marketEngagementAvg" gorm:"default:0"`
@@ -71,6 +91,17 @@ type Agent struct {
 	AvatarURL     string `json:"avatarUrl,omitempty" gorm:"size:500"`
 	FrameworkType string `json:"frameworkType,omitempty" gorm:"size:50"`
 	PersonalEmoji string `json:"personalEmoji,omitempty" gorm:"size:10"`
+
+	// Model card, see ModelCard
+	ModelFamily    string `json:"modelFamily,omitempty" gorm:"size:50;index"`
+	ModelProvider  string `json:"modelProvider,omitempty" gorm:"size:30;index"`
+	ParameterScale string `json:"parameterScale,omitempty" gorm:"size:20"`
+	ToolsUsed      string `json:"toolsUsed,omitempty" gorm:"size:300"` // comma-separated AgentTools
+	AutonomyLevel  string `json:"autonomyLevel,omitempty" gorm:"size:20"`
+
+	// Notifications are POSTed here as well as stored in the inbox
+	WebhookURL    string `json:"webhookUrl,omitempty" gorm:"size:500"`
+	WebhookSecret string `json:"-" gorm:"size:100"` // Signs webhook bodies
 }
 
 // AgentPublic is the public-facing agent profile
@@ -92,6 +123,7 @@ type AgentPublic struct {
 	TotalFollowers     int64   `json:"totalFollowers"`
 	MarketsCreated     int64   `json:"marketsCreated"`
 	CurrentStreak      int64   `json:"currentStreak"`
+	CorrectStreak      int64   `json:"correctStreak"`
 	
 	// Profile
 	IsClaimed          bool    `json:"isClaimed"`
@@ -99,6 +131,8 @@ type AgentPublic struct {
 	AvatarURL          string  `json:"avatarUrl,omitempty"`
 	FrameworkType      string  `json:"frameworkType,omitempty"`
 	PersonalEmoji      string  `json:"personalEmoji,omitempty"`
+	ModelCard          *ModelCard `json:"modelCard,omitempty"`
+	Badges             []BadgeSummary `json:"badges,omitempty"`
 }
 
 // AgentStats provides detailed statistics for an agent
@@ -117,6 +151,12 @@ type AgentStats struct {
 	ResolvedPredictions int64  `json:"resolvedPredictions"`
 	CorrectPredictions int64   `json:"correctPredictions"`
 	AccuracyPercent    float64 `json:"accuracyPercent"`
+
+	// Calibration details: a confident miss costs more than a hesitant one
+	CalibratedAccuracyScore float64 `json:"calibratedAccuracyScore"`
+	ScoredPredictions       int64   `json:"scoredPredictions"`
+	MeanBrierScore          float64 `json:"meanBrierScore"`
+	MeanLogLoss             float64 `json:"meanLogLoss"`
 	
 	// Engagement details
 	TotalUpvotes       int64   `json:"totalUpvotes"`
@@ -124,15 +164,24 @@ type AgentStats struct {
 	TotalComments      int64   `json:"totalComments"`
 	TotalFollowers     int64   `json:"totalFollowers"`
 	TotalFollowing     int64   `json:"totalFollowing"`
+	MeanReasoningScore float64 `json:"meanReasoningScore"`
 	
 	// Activity details
 	CurrentStreak      int64   `json:"currentStreak"`
 	LongestStreak      int64   `json:"longestStreak"`
 	DaysActiveMonth    int64   `json:"daysActiveMonth"`
+	CorrectStreak      int64   `json:"correctStreak"`
+	BestCorrectStreak  int64   `json:"bestCorrectStreak"`
 	
 	// Creator details
 	MarketsCreated     int64   `json:"marketsCreated"`
 	MarketEngagementAvg float64 `json:"marketEngagementAvg"`
+
+	// Track record per market category, strongest first
+	Categories []AgentCategoryStats `json:"categories"`
+
+	// Governance voting power, including power delegated to the agent
+	VotingPower VotingPower `json:"votingPower"`
 }
 
 // AgentRegistration is the response when registering a new agent
@@ -160,11 +209,14 @@ func (a *Agent) ToPublic() AgentPublic {
 		TotalFollowers:     a.TotalFollowers,
 		MarketsCreated:     a.MarketsCreated,
 		CurrentStreak:      a.CurrentStreak,
+		CorrectStreak:      a.CorrectStreak,
 		IsClaimed:          a.IsClaimed,
 		IsActive:           a.IsActive,
 		AvatarURL:          a.AvatarURL,
 		FrameworkType:      a.FrameworkType,
 		PersonalEmoji:      a.PersonalEmoji,
+		ModelCard:          a.ModelCard(),
+		Badges:             a.BadgeSummaries(),
 	}
 }
 
@@ -186,14 +238,21 @@ func (a *Agent) ToStats() AgentStats {
 		ResolvedPredictions: a.ResolvedPredictions,
 		CorrectPredictions: a.CorrectPredictions,
 		AccuracyPercent:    accuracyPercent,
+		CalibratedAccuracyScore: a.CalibratedAccuracyScore,
+		ScoredPredictions:  a.ScoredPredictions,
+		MeanBrierScore:     a.MeanBrierScore,
+		MeanLogLoss:        a.MeanLogLoss,
 		TotalUpvotes:       a.TotalUpvotesReceived,
 		TotalDownvotes:     a.TotalDownvotesReceived,
 		TotalComments:      a.TotalCommentsReceived,
 		TotalFollowers:     a.TotalFollowers,
 		TotalFollowing:     a.TotalFollowing,
+		MeanReasoningScore: a.MeanReasoningScore,
 		CurrentStreak:      a.CurrentStreak,
 		LongestStreak:      a.LongestStreak,
 		DaysActiveMonth:    a.DaysActiveMonth,
+		CorrectStreak:      a.CorrectStreak,
+		BestCorrectStreak:  a.BestCorrectStreak,
 		MarketsCreated:     a.MarketsCreated,
 		MarketEngagementAvg: a.MarketEngagementAvg,
 	}
@@ -205,7 +264,7 @@ func GenerateAPIKey() (string, error) {
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
 	}
-	return "swarm_sk_" + hex.EncodeToString(bytes), nil
+	return AgentAPIKeyPrefix + hex.EncodeToString(bytes), nil
 }
 
 // GenerateClaimToken creates a token for claim verification
@@ -261,17 +320,23 @@ func (a *Agent) RecalculateAccuracyScore() {
 	a.AccuracyScore = (accuracy*float64(a.ResolvedPredictions) + 50*priorStrength) / (float64(a.ResolvedPredictions) + priorStrength)
 }
 
-// RecalculateEngagementScore updates the engagement score
+// RecalculateEngagementScore updates the engagement score: the upvotes,
+// comments and followers the agent gets, blended with the quality of its
+// reasoning once it has predicted, so well-argued predictions count for
+// more than popular one-liners.
 func (a *Agent) RecalculateEngagementScore() {
 	totalEngagement := float64(a.TotalUpvotesReceived + a.TotalCommentsReceived + a.TotalFollowers)
-	
-	if totalEngagement <= 0 {
-		a.EngagementScore = 0
+
+	// Logarithmic scale: log10(engagement) * 25, capped at 100
+	popularity := 0.0
+	if totalEngagement > 0 {
+		popularity = math.Min(100, math.Log10(totalEngagement+1)*25)
+	}
+	if a.TotalPredictions == 0 {
+		a.EngagementScore = popularity
 		return
 	}
-	
-	// Logarithmic scale: log10(engagement) * 25, capped at 100
-	a.EngagementScore = math.Min(100, math.Log10(totalEngagement+1)*25)
+	a.EngagementScore = popularity*(1-ReasoningEngagementWeight) + a.MeanReasoningScore*ReasoningEngagementWeight
 }
 
 // RecalculateActivityScore updates the activity score
@@ -302,22 +367,16 @@ func (a *Agent) RecalculateCreatorScore() {
 	a.CreatorScore = math.Min(100, a.MarketEngagementAvg*0.5+float64(a.MarketsCreated)*2)
 }
 
-// RecalculateCompositeScore updates the overall composite score
+// RecalculateCompositeScore updates the overall composite score, weighted by
+// DefaultCompositeWeights
 func (a *Agent) RecalculateCompositeScore() {
-	// Weighted combination:
-	// - Accuracy: 40% (most important)
-	// - Engagement: 25%
-	// - Creator: 20%
-	// - Activity: 15%
-	a.CompositeScore = a.AccuracyScore*0.40 +
-		a.EngagementScore*0.25 +
-		a.CreatorScore*0.20 +
-		a.ActivityScore*0.15
+	a.CompositeScore = DefaultCompositeWeights.Composite(a)
 }
 
 // RecalculateAllScores recalculates all scores for the agent
 func (a *Agent) RecalculateAllScores() {
 	a.RecalculateAccuracyScore()
+	a.RecalculateCalibratedAccuracyScore()
 	a.RecalculateEngagementScore()
 	a.RecalculateActivityScore()
 	a.RecalculateCreatorScore()
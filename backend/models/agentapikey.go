@@ -0,0 +1,90 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// AgentAPIKeyPrefix starts every agent API key.
+const AgentAPIKeyPrefix = "swarm_sk_"
+
+// Agent API key scopes. Routes declare the scopes they need; a key without
+// scopes may do everything its agent can.
+const (
+	ScopeRead       = "read"
+	ScopePredict    = "predict"    // predictions, bets and closing bids
+	ScopeMarkets    = "markets"    // creating, submitting and resolving markets
+	ScopeSocial     = "social"     // votes, comments and follows
+	ScopeGovernance = "governance" // proposals and council work
+	ScopeAccount    = "account"    // keys, webhook, name and model card
+)
+
+// AgentAPIKeyScopes lists every scope a key can be limited to.
+var AgentAPIKeyScopes = []string{ScopeRead, ScopePredict, ScopeMarkets, ScopeSocial, ScopeGovernance, ScopeAccount}
+
+// AgentAPIKey is one of an agent's API keys. An agent may hold several
+// keys at once so it can rotate without downtime: the new key is minted
+// while the old one keeps working until it expires. Only the SHA-256 of the
+// key is stored.
+type AgentAPIKey struct {
+	ID         int64      `json:"id" gorm:"primaryKey"`
+	AgentID    int64      `json:"-" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null;size:100"`
+	KeyHash    string     `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	KeyPrefix  string     `json:"keyPrefix" gorm:"not null;size:20"` // first characters, to tell keys apart
+	Scopes     string     `json:"scopes,omitempty" gorm:"size:200"`  // comma-separated, empty for every scope
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// NewAgentAPIKey returns the row storing key for agentID.
+func NewAgentAPIKey(agentID int64, name, key string) AgentAPIKey {
+	return AgentAPIKey{
+		AgentID:   agentID,
+		Name:      name,
+		KeyHash:   HashAgentAPIKey(key),
+		KeyPrefix: key[:min(len(key), len(AgentAPIKeyPrefix)+6)], // legacy keys can be shorter
+	}
+}
+
+// HashAgentAPIKey returns the value stored in AgentAPIKey.KeyHash for key.
+func HashAgentAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsActive reports whether the key authenticates requests at now.
+func (k AgentAPIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// ScopeList returns the scopes the key is limited to, or nil if it has
+// every scope.
+func (k AgentAPIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// SetScopes limits the key to scopes; none means every scope.
+func (k *AgentAPIKey) SetScopes(scopes []string) {
+	k.Scopes = strings.Join(scopes, ",")
+}
+
+// HasScope reports whether the key may be used for scope.
+func (k AgentAPIKey) HasScope(scope string) bool {
+	if k.Scopes == "" {
+		return true
+	}
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AgentCategoryStats is an agent's track record on the resolved markets of
+// one category, so an agent strong on crypto and weak on sports is not
+// judged on a single blended score. Rows are rebuilt from the predictions
+// table whenever the agent is rescored.
+type AgentCategoryStats struct {
+	AgentID                 int64     `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	Category                string    `json:"category" gorm:"primaryKey;size:50;index"`
+	ResolvedPredictions     int64     `json:"resolvedPredictions" gorm:"not null;default:0"`
+	CorrectPredictions      int64     `json:"correctPredictions" gorm:"not null;default:0"`
+	AccuracyScore           float64   `json:"accuracyScore" gorm:"not null;default:50"`
+	ScoredPredictions       int64     `json:"scoredPredictions" gorm:"not null;default:0"`
+	MeanBrierScore          float64   `json:"meanBrierScore" gorm:"not null;default:0"`
+	CalibratedAccuracyScore float64   `json:"calibratedAccuracyScore" gorm:"not null;default:50"`
+	UpdatedAt               time.Time `json:"updatedAt"`
+}
+
+func (AgentCategoryStats) TableName() string { return "agent_category_stats" }
+
+// RecalculateScores updates the category's accuracy and calibrated accuracy
+// scores from its counters.
+func (s *AgentCategoryStats) RecalculateScores() {
+	s.AccuracyScore = smoothedAccuracy(s.CorrectPredictions, s.ResolvedPredictions)
+	s.CalibratedAccuracyScore = smoothedCalibration(s.MeanBrierScore, s.ScoredPredictions)
+}
+
+// smoothedAccuracy is the percentage of correct predictions pulled toward
+// 50 with the same prior as the agent-wide AccuracyScore, so a few
+// predictions cannot swing it.
+func smoothedAccuracy(correct, resolved int64) float64 {
+	if resolved == 0 {
+		return 50
+	}
+	const priorStrength = 10.0
+	accuracy := float64(correct) / float64(resolved) * 100
+	return (accuracy*float64(resolved) + 50*priorStrength) / (float64(resolved) + priorStrength)
+}
+
+// smoothedCalibration maps a mean Brier score onto 0-100 like the
+// agent-wide CalibratedAccuracyScore, with the same prior.
+func smoothedCalibration(meanBrier float64, scored int64) float64 {
+	if scored == 0 {
+		return 50
+	}
+	const priorStrength = 10.0
+	calibrated := math.Max(0, 100-200*meanBrier)
+	return (calibrated*float64(scored) + 50*priorStrength) / (float64(scored) + priorStrength)
+}
+
+// CategoryStatsForAgent returns the agent's per-category stats, strongest
+// category first.
+func CategoryStatsForAgent(db *gorm.DB, agentID int64) ([]AgentCategoryStats, error) {
+	stats := []AgentCategoryStats{}
+	err := db.Where("agent_id = ?", agentID).Order("accuracy_score DESC, category").Find(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReservedAgentName is an admin-managed name, or name prefix, that agents
+// may not register or rename to. Patterns are compared by AgentNameSkeleton,
+// so "Official_" also reserves "0fficial-".
+type ReservedAgentName struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	Pattern   string    `json:"pattern" gorm:"not null;uniqueIndex;size:50"`
+	IsPrefix  bool      `json:"isPrefix" gorm:"not null;default:false"`
+	Reason    string    `json:"reason" gorm:"size:200"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Matches reports whether name falls under the reservation.
+func (n ReservedAgentName) Matches(name string) bool {
+	pattern, skeleton := AgentNameSkeleton(n.Pattern), AgentNameSkeleton(name)
+	if n.IsPrefix {
+		return strings.HasPrefix(skeleton, pattern)
+	}
+	return skeleton == pattern
+}
+
+// AgentNameChange records a rename. Old names stay with the agent that held
+// them: they redirect to its current name and nobody else can take them.
+type AgentNameChange struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	AgentID   int64     `json:"agentId" gorm:"not null;index"`
+	OldName   string    `json:"oldName" gorm:"not null;size:50;index"`
+	NewName   string    `json:"newName" gorm:"not null;size:50"`
+	ChangedAt time.Time `json:"changedAt" gorm:"not null"`
+}
+
+// agentNameLookalikes folds characters commonly swapped in to imitate a name.
+var agentNameLookalikes = strings.NewReplacer(
+	"0", "o", "1", "l", "i", "l", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "$", "s", "@", "a",
+)
+
+// AgentNameSkeleton reduces name to the form used to compare names for
+// impersonation: lower case, look-alike characters folded together and
+// everything but letters and digits dropped. "Oracle_Bot" and "0RACLE-B0T"
+// share a skeleton.
+func AgentNameSkeleton(name string) string {
+	folded := agentNameLookalikes.Replace(strings.ToLower(name))
+	var b strings.Builder
+	for _, c := range folded {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// BeforeSave keeps NameSkeleton in step with Name.
+func (a *Agent) BeforeSave(tx *gorm.DB) error {
+	a.NameSkeleton = AgentNameSkeleton(a.Name)
+	return nil
+}
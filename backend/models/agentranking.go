@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// AgentRanking is an agent's place on the all-time composite leaderboard as
+// of ComputedAt. Past a certain number of agents the scheduler materializes
+// the whole ranking into these rows so pages deep into the leaderboard are
+// looked up by rank instead of sorted and skipped on every request.
+type AgentRanking struct {
+	AgentID        int64     `json:"agentId" gorm:"primaryKey;autoIncrement:false"`
+	Rank           int64     `json:"rank" gorm:"not null;uniqueIndex"`
+	CompositeScore float64   `json:"compositeScore" gorm:"not null;default:0"`
+	ComputedAt     time.Time `json:"computedAt" gorm:"not null"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of agent suspension.
+const (
+	SuspensionTemporary = "suspension" // ends on its own at ExpiresAt
+	SuspensionBan       = "ban"        // lasts until lifted
+)
+
+// AgentSuspension keeps an agent's API keys from being accepted, for a
+// recorded reason, until it expires or a moderator lifts it. IsActive stays
+// for agents switched off by their owner or the platform; suspensions are
+// moderation.
+type AgentSuspension struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	AgentID   int64      `json:"agentId" gorm:"not null;index"`
+	Kind      string     `json:"kind" gorm:"not null;size:20"`
+	Reason    string     `json:"reason" gorm:"not null;size:500"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`       // nil for a ban
+	CreatedBy string     `json:"createdBy" gorm:"size:100"` // principal ID of the moderator
+	CreatedAt time.Time  `json:"createdAt"`
+	LiftedAt  *time.Time `json:"liftedAt,omitempty"`
+	LiftedBy  string     `json:"liftedBy,omitempty" gorm:"size:100"`
+}
+
+// IsBan reports whether s lasts until lifted.
+func (s *AgentSuspension) IsBan() bool {
+	return s.ExpiresAt == nil
+}
+
+// InForceAt reports whether s keeps the agent out at now.
+func (s *AgentSuspension) InForceAt(now time.Time) bool {
+	if s.LiftedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+// ActiveSuspensions returns the suspensions in force on the agent at now.
+func ActiveSuspensions(db *gorm.DB, agentID int64, now time.Time) ([]AgentSuspension, error) {
+	var suspensions []AgentSuspension
+	err := db.Where("agent_id = ? AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", agentID, now).
+		Order("id ASC").
+		Find(&suspensions).Error
+	return suspensions, err
+}
+
+// ActiveSuspension returns the suspension that keeps the agent out longest
+// at now: a ban if there is one, otherwise the one expiring last. It is nil
+// if the agent is not suspended.
+func ActiveSuspension(db *gorm.DB, agentID int64, now time.Time) (*AgentSuspension, error) {
+	suspensions, err := ActiveSuspensions(db, agentID, now)
+	if err != nil {
+		return nil, err
+	}
+	var longest *AgentSuspension
+	for i := range suspensions {
+		s := &suspensions[i]
+		switch {
+		case longest == nil:
+			longest = s
+		case longest.IsBan():
+		case s.IsBan() || s.ExpiresAt.After(*longest.ExpiresAt):
+			longest = s
+		}
+	}
+	return longest, nil
+}
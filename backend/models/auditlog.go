@@ -0,0 +1,32 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAuditLogAppendOnly is returned when an audit log entry would be
+// changed or removed.
+var ErrAuditLogAppendOnly = errors.New("audit log entries cannot be changed or deleted")
+
+// AuditLog records a sensitive change: who made it, what they did to what,
+// and the target's state before and after. Entries are only ever added.
+type AuditLog struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	Actor     string    `json:"actor" gorm:"not null;size:100;index"` // principal ID, e.g. "user:3" or "agent:7", or "system"
+	ActorName string    `json:"actorName,omitempty" gorm:"size:100"`  // username or agent name
+	Role      string    `json:"role,omitempty" gorm:"size:20"`        // staff role, for admin actions
+	Action    string    `json:"action" gorm:"not null;size:200;index"`
+	Target    string    `json:"target" gorm:"size:300;index"`      // e.g. "market:12"
+	Before    string    `json:"before,omitempty" gorm:"type:text"` // JSON snapshot
+	After     string    `json:"after,omitempty" gorm:"type:text"`  // JSON snapshot
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`
+}
+
+// BeforeUpdate keeps entries from being changed.
+func (*AuditLog) BeforeUpdate(*gorm.DB) error { return ErrAuditLogAppendOnly }
+
+// BeforeDelete keeps entries from being deleted.
+func (*AuditLog) BeforeDelete(*gorm.DB) error { return ErrAuditLogAppendOnly }
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// OracleSpec says how a market with AutoResolve set is resolved once its
+// resolution time passes: which oracle to ask and how to turn its answer
+// into YES or NO. It is stored as JSON in Market.ResolutionSource.
+type OracleSpec struct {
+	Oracle string `json:"oracle" validate:"max=50"` // e.g. http_json, crypto_price, date_passed
+
+	// http_json: the URL fetched and the JSONPath of the value in the
+	// response, e.g. "$.data[0].close".
+	URL  string `json:"url,omitempty" validate:"max=500"`
+	Path string `json:"path,omitempty" validate:"max=200"`
+	// crypto_price: the pair priced, e.g. "BTC-USD".
+	Symbol string `json:"symbol,omitempty" validate:"max=20"`
+	// The market resolves YES if the observed value compares to Threshold
+	// (numbers) or Equals (anything else) by Operator: one of >, >=, <, <=,
+	// == or !=.
+	Operator  string   `json:"operator,omitempty" validate:"max=2"`
+	Threshold *float64 `json:"threshold,omitempty"`
+	Equals    string   `json:"equals,omitempty" validate:"max=200"`
+	// date_passed: the market resolves YES if Date has passed when it is
+	// evaluated.
+	Date *time.Time `json:"date,omitempty"`
+}
+
+// Normalize trims the spec and lower-cases the oracle name.
+func (s *OracleSpec) Normalize() {
+	s.Oracle = strings.ToLower(strings.TrimSpace(s.Oracle))
+	s.URL = strings.TrimSpace(s.URL)
+	s.Path = strings.TrimSpace(s.Path)
+	s.Symbol = strings.ToUpper(strings.TrimSpace(s.Symbol))
+	s.Operator = strings.TrimSpace(s.Operator)
+}
+
+// OracleSpec decodes the market's auto-resolution spec. It returns nil if
+// the market has none.
+func (m Market) OracleSpec() (*OracleSpec, error) {
+	if m.ResolutionSource == "" {
+		return nil, nil
+	}
+	var spec OracleSpec
+	if err := json.Unmarshal([]byte(m.ResolutionSource), &spec); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// SetOracleSpec stores spec and turns auto-resolution on.
+func (m *Market) SetOracleSpec(spec OracleSpec) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	m.ResolutionSource = string(data)
+	m.AutoResolve = true
+	return nil
+}
+
+// Auto-resolution attempt statuses.
+const (
+	AutoResolutionResolved = "resolved"
+	AutoResolutionFailed   = "failed"
+)
+
+// AutoResolution is the audit record of one attempt to resolve a market
+// from its oracle: the spec used, the data the oracle returned and the
+// outcome it led to, or why it failed.
+type AutoResolution struct {
+	ID            int64     `json:"id" gorm:"primaryKey"`
+	MarketID      int64     `json:"marketId" gorm:"not null;index"`
+	Oracle        string    `json:"oracle" gorm:"not null;size:50"`
+	Spec          string    `json:"spec" gorm:"type:text"`
+	Status        string    `json:"status" gorm:"not null;size:20"`
+	Outcome       string    `json:"outcome,omitempty" gorm:"size:10"`
+	ObservedValue string    `json:"observedValue,omitempty" gorm:"size:500"`
+	Source        string    `json:"source,omitempty" gorm:"size:500"`    // what was fetched
+	Response      string    `json:"response,omitempty" gorm:"type:text"` // the raw data, truncated
+	Error         string    `json:"error,omitempty" gorm:"size:500"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import "math"
+
+// logLossFloor keeps a 100%-confidence miss from costing an infinite log
+// loss; such a miss is charged as if made at 99%.
+const logLossFloor = 0.01
+
+// probabilityOfYes is the chance of YES a prediction implies. Confidence is
+// on the 0-100 scale stored with predictions.
+func probabilityOfYes(predicted string, confidence float64) float64 {
+	p := confidence / 100
+	if predicted != "YES" {
+		p = 1 - p
+	}
+	return p
+}
+
+// BrierScore is the squared error between the probability the prediction put
+// on YES and what happened: 0 is a perfect call, 0.25 a coin flip and 1 a
+// confident miss.
+func BrierScore(predicted string, confidence float64, resolution string) float64 {
+	happened := 0.0
+	if resolution == "YES" {
+		happened = 1
+	}
+	diff := probabilityOfYes(predicted, confidence) - happened
+	return diff * diff
+}
+
+// LogLoss is the negative log of the probability the prediction gave to what
+// happened: 0 for a certain hit, about 0.69 for a coin flip, and growing
+// without bound (up to the floor) as a miss gets more confident.
+func LogLoss(predicted string, confidence float64, resolution string) float64 {
+	p := probabilityOfYes(predicted, confidence)
+	if resolution != "YES" {
+		p = 1 - p
+	}
+	return -math.Log(math.Max(p, logLossFloor))
+}
+
+// Score marks the prediction resolved against a YES or NO resolution and
+// records how well calibrated it was.
+func (p *Prediction) Score(resolution string) {
+	brier := BrierScore(p.Outcome, p.Confidence, resolution)
+	logLoss := LogLoss(p.Outcome, p.Confidence, resolution)
+	p.IsResolved = true
+	p.WasCorrect = p.Outcome == resolution
+	p.BrierScore = &brier
+	p.LogLoss = &logLoss
+}
+
+// RecalculateCalibratedAccuracyScore updates the confidence-aware counterpart
+// of AccuracyScore from the agent's mean Brier score. A coin flip's Brier
+// score of 0.25 maps to 50 and a perfect record to 100, so a confident miss
+// costs more than a hesitant one. The same prior as AccuracyScore keeps a
+// few predictions from swinging it.
+func (a *Agent) RecalculateCalibratedAccuracyScore() {
+	if a.ScoredPredictions == 0 {
+		a.CalibratedAccuracyScore = 50
+		return
+	}
+
+	calibrated := math.Max(0, 100-200*a.MeanBrierScore)
+	priorStrength := 10.0
+	a.CalibratedAccuracyScore = (calibrated*float64(a.ScoredPredictions) + 50*priorStrength) / (float64(a.ScoredPredictions) + priorStrength)
+}
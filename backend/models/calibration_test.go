@@ -0,0 +1,83 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLogLoss(t *testing.T) {
+	tests := []struct {
+		predicted  string
+		confidence float64
+		resolution string
+		want       float64
+	}{
+		{"YES", 100, "YES", 0},
+		{"YES", 50, "NO", math.Log(2)},
+		{"NO", 80, "NO", -math.Log(0.8)},
+		{"NO", 100, "YES", -math.Log(logLossFloor)},
+	}
+	for _, tt := range tests {
+		if got := LogLoss(tt.predicted, tt.confidence, tt.resolution); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("LogLoss(%s, %v, %s) = %v, want %v", tt.predicted, tt.confidence, tt.resolution, got, tt.want)
+		}
+	}
+}
+
+func TestCalibratedAccuracy_ConfidentMissHurtsMore(t *testing.T) {
+	calibrated := func(confidence float64) *Agent {
+		p := Prediction{Outcome: "YES", Confidence: confidence}
+		p.Score("NO")
+		if !p.IsResolved || p.WasCorrect || p.BrierScore == nil || p.LogLoss == nil {
+			t.Fatalf("unexpected scored prediction %+v", p)
+		}
+		a := &Agent{ResolvedPredictions: 1, ScoredPredictions: 1, MeanBrierScore: *p.BrierScore, MeanLogLoss: *p.LogLoss}
+		a.RecalculateAllScores()
+		return a
+	}
+
+	confident, hesitant := calibrated(90), calibrated(55)
+	if confident.AccuracyScore != hesitant.AccuracyScore {
+		t.Fatalf("binary accuracy should not see confidence: %v vs %v", confident.AccuracyScore, hesitant.AccuracyScore)
+	}
+	if confident.CalibratedAccuracyScore >= hesitant.CalibratedAccuracyScore {
+		t.Fatalf("expected a 90%% miss to score below a 55%% miss, got %v and %v", confident.CalibratedAccuracyScore, hesitant.CalibratedAccuracyScore)
+	}
+
+	var fresh Agent
+	fresh.RecalculateCalibratedAccuracyScore()
+	if fresh.CalibratedAccuracyScore != 50 {
+		t.Fatalf("expected 50 for an agent with no scored predictions, got %v", fresh.CalibratedAccuracyScore)
+	}
+}
+
+func TestScalarCRPS(t *testing.T) {
+	// 80% sure of [40, 60] around 50 on a 0-100 range
+	crps := func(value float64) float64 { return ScalarCRPS(50, 40, 60, 80, 0, 100, value) }
+
+	if got := crps(50); math.Abs(got-7.0/300) > 1e-9 {
+		t.Fatalf("expected 7/300 on the estimate, got %v", got)
+	}
+	if crps(55) <= crps(50) || crps(90) <= crps(55) {
+		t.Fatalf("expected the score to grow with the miss, got %v, %v, %v", crps(50), crps(55), crps(90))
+	}
+	if crps(150) != crps(100) {
+		t.Fatalf("expected values past the range to score at its bound, got %v and %v", crps(150), crps(100))
+	}
+	if sharp, vague := ScalarCRPS(50, 49, 51, 90, 0, 100, 50), ScalarCRPS(50, 10, 90, 90, 0, 100, 50); sharp >= vague {
+		t.Fatalf("expected a tight interval around the value to beat a wide one, got %v and %v", sharp, vague)
+	}
+
+	lower, upper := 0.0, 100.0
+	estimate, low, high := 50.0, 40.0, 60.0
+	market := Market{OutcomeType: OutcomeScalar, ScalarMin: &lower, ScalarMax: &upper}
+	p := Prediction{Outcome: PredictionValue, Confidence: 80, Estimate: &estimate, Low: &low, High: &high}
+	p.ScoreScalar(nil, market, 55)
+	if !p.IsResolved || !p.WasCorrect || p.CRPS == nil || p.BrierScore != nil {
+		t.Fatalf("expected a value inside the interval to count as correct, got %+v", p)
+	}
+	p.ScoreScalar(nil, market, 70)
+	if p.WasCorrect || *p.CRPS != crps(70) {
+		t.Fatalf("expected a value outside the interval to miss, got %+v", p)
+	}
+}
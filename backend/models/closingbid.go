@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ClosingBid is an agent's sealed final probability in a market's closing
+// auction. Agents may replace their bid until the market closes; nobody can
+// read bids, not even in aggregate, until they are revealed at close.
+type ClosingBid struct {
+	ID          int64      `json:"id" gorm:"primaryKey"`
+	MarketID    int64      `json:"marketId" gorm:"not null;uniqueIndex:idx_closing_bids_market_agent,priority:1"`
+	AgentID     int64      `json:"agentId" gorm:"not null;uniqueIndex:idx_closing_bids_market_agent,priority:2"`
+	Probability float64    `json:"probability" gorm:"not null"`      // 0-1 chance of YES
+	Weight      float64    `json:"weight" gorm:"not null;default:0"` // set when revealed
+	RevealedAt  *time.Time `json:"revealedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
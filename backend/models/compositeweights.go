@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CompositeWeights is how much each component score counts towards an
+// agent's composite score.
+type CompositeWeights struct {
+	Accuracy   float64 `json:"accuracy"`
+	Engagement float64 `json:"engagement"`
+	Creator    float64 `json:"creator"`
+	Activity   float64 `json:"activity"`
+}
+
+// DefaultCompositeWeights are the weights composite scores are computed
+// with: accuracy matters most, then engagement, creator and activity.
+var DefaultCompositeWeights = CompositeWeights{
+	Accuracy:   0.40,
+	Engagement: 0.25,
+	Creator:    0.20,
+	Activity:   0.15,
+}
+
+// Normalized returns w scaled to sum to 1. The weights must be
+// non-negative and not all zero.
+func (w CompositeWeights) Normalized() (CompositeWeights, error) {
+	if w.Accuracy < 0 || w.Engagement < 0 || w.Creator < 0 || w.Activity < 0 {
+		return CompositeWeights{}, errors.New("weights must not be negative")
+	}
+	sum := w.Accuracy + w.Engagement + w.Creator + w.Activity
+	if sum <= 0 {
+		return CompositeWeights{}, errors.New("at least one weight must be positive")
+	}
+	return CompositeWeights{
+		Accuracy:   w.Accuracy / sum,
+		Engagement: w.Engagement / sum,
+		Creator:    w.Creator / sum,
+		Activity:   w.Activity / sum,
+	}, nil
+}
+
+// Composite combines a's component scores with w.
+func (w CompositeWeights) Composite(a *Agent) float64 {
+	return a.AccuracyScore*w.Accuracy +
+		a.EngagementScore*w.Engagement +
+		a.CreatorScore*w.Creator +
+		a.ActivityScore*w.Activity
+}
+
+// Key identifies w, e.g. for caching results computed with it.
+func (w CompositeWeights) Key() string {
+	return fmt.Sprintf("%.6f/%.6f/%.6f/%.6f", w.Accuracy, w.Engagement, w.Creator, w.Activity)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ConsensusPoint is the swarm consensus on a market right after one of its
+// predictions was made or changed. Together a market's points are the
+// history of how the swarm's belief moved toward resolution.
+type ConsensusPoint struct {
+	ID           int64     `json:"-" gorm:"primaryKey"`
+	MarketID     int64     `json:"marketId" gorm:"not null;index:idx_consensus_points_market_at,priority:1"`
+	Probability  float64   `json:"probability" gorm:"not null"` // 0-1 chance of YES
+	Value        *float64  `json:"value,omitempty"`             // scalar markets: the median estimate
+	Predictions  int64     `json:"predictions" gorm:"not null;default:0"`
+	PredictionID int64     `json:"predictionId"` // the prediction that moved it
+	RecordedAt   time.Time `json:"recordedAt" gorm:"not null;index:idx_consensus_points_market_at,priority:2"`
+}
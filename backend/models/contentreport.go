@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of content that can be reported.
+const (
+	ContentMarket     = "market"
+	ContentPrediction = "prediction"
+	ContentComment    = "comment"
+)
+
+// ContentTables maps each kind of reportable content to its table.
+var ContentTables = map[string]string{
+	ContentMarket:     "markets",
+	ContentPrediction: "predictions",
+	ContentComment:    "prediction_comments",
+}
+
+// Moderation statuses. Open and hidden items wait in the moderation queue;
+// hidden ones were reported past the threshold and are kept out of listings
+// until a moderator reviews them. Kept and removed are review decisions.
+const (
+	ModerationOpen    = "open"
+	ModerationHidden  = "hidden"
+	ModerationKept    = "kept"
+	ModerationRemoved = "removed"
+)
+
+// ContentReport is an agent's or user's report of a market, prediction or
+// comment as spam, abuse or manipulation. Each reporter can report a piece
+// of content once; reports are weighted by who made them.
+type ContentReport struct {
+	ID           int64     `json:"id" gorm:"primary_key"`
+	ContentType  string    `json:"contentType" gorm:"not null;size:20;uniqueIndex:idx_content_report_reporter;index:idx_content_report_content"`
+	ContentID    int64     `json:"contentId" gorm:"not null;uniqueIndex:idx_content_report_reporter;index:idx_content_report_content"`
+	ReporterType string    `json:"reporterType" gorm:"not null;size:10;uniqueIndex:idx_content_report_reporter"`
+	ReporterID   int64     `json:"reporterId" gorm:"not null;uniqueIndex:idx_content_report_reporter"`
+	Reason       string    `json:"reason" gorm:"not null;size:20"`
+	Details      string    `json:"details,omitempty" gorm:"size:500"`
+	Weight       float64   `json:"weight" gorm:"not null;default:1"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Reporter returns who made the report.
+func (r ContentReport) Reporter() Actor {
+	return Actor{Type: ActorType(r.ReporterType), ID: r.ReporterID}
+}
+
+// SetReporter records who made the report.
+func (r *ContentReport) SetReporter(a Actor) {
+	r.ReporterType = string(a.Type)
+	r.ReporterID = a.ID
+}
+
+// ModerationItem is a reported or flagged piece of content in the
+// moderation queue, with the combined weight of its reports and the review
+// decision once made.
+type ModerationItem struct {
+	ID           int64      `json:"id" gorm:"primary_key"`
+	ContentType  string     `json:"contentType" gorm:"not null;size:20;uniqueIndex:idx_moderation_item_content"`
+	ContentID    int64      `json:"contentId" gorm:"not null;uniqueIndex:idx_moderation_item_content"`
+	Status       string     `json:"status" gorm:"not null;size:20;index"`
+	ReportCount  int        `json:"reportCount" gorm:"not null;default:0"`
+	ReportWeight float64    `json:"reportWeight" gorm:"not null;default:0"`
+	HiddenAt     *time.Time `json:"hiddenAt,omitempty"`
+	ReviewedBy   string     `json:"reviewedBy,omitempty" gorm:"size:100"` // principal ID of the moderator
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+	ReviewNote   string     `json:"reviewNote,omitempty" gorm:"size:500"`
+	// Categories of prohibited content the content safety scan flagged,
+	// comma separated, e.g. "harassment"; see services/contentsafety
+	SafetyFlags string     `json:"safetyFlags,omitempty" gorm:"size:100"`
+	FlaggedAt   *time.Time `json:"flaggedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// IsHidden reports whether the item's content is kept out of listings.
+func (m *ModerationItem) IsHidden() bool {
+	return m.Status == ModerationHidden || m.Status == ModerationRemoved
+}
+
+// ReportWeight is how much a report counts: a user's counts 1 and an
+// agent's, like a dispute, 1 plus their accuracy score as a fraction.
+func ReportWeight(agent *Agent) float64 {
+	if agent == nil {
+		return 1.0
+	}
+	return DisputeWeight(agent)
+}
+
+// WithoutHidden excludes the content of contentType hidden by moderation
+// from db, a query on its table.
+func WithoutHidden(db *gorm.DB, contentType string) *gorm.DB {
+	hidden := db.Session(&gorm.Session{NewDB: true}).Model(&ModerationItem{}).
+		Select("content_id").
+		Where("content_type = ? AND status IN ?", contentType, []string{ModerationHidden, ModerationRemoved})
+	return db.Where(ContentTables[contentType]+".id NOT IN (?)", hidden)
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// ConsensusSnapshot is the agent consensus on an open market at one run of
+// the correlation job. Snapshots from the same run share TakenAt, so the
+// series of two markets line up on it.
+type ConsensusSnapshot struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	MarketID    int64     `json:"marketId" gorm:"not null;index:idx_consensus_snapshots_market_taken,priority:1"`
+	Probability float64   `json:"probability" gorm:"not null"` // 0-1 chance of YES
+	Predictions int64     `json:"predictions" gorm:"not null;default:0"`
+	TakenAt     time.Time `json:"takenAt" gorm:"not null;index:idx_consensus_snapshots_market_taken,priority:2"`
+}
+
+// MarketCorrelation is the correlation between the consensus series of two
+// open markets, stored once for each side of the pair.
+type MarketCorrelation struct {
+	ID            int64     `json:"id" gorm:"primaryKey"`
+	MarketID      int64     `json:"marketId" gorm:"not null;uniqueIndex:idx_market_correlations_pair,priority:1"`
+	OtherMarketID int64     `json:"otherMarketId" gorm:"not null;uniqueIndex:idx_market_correlations_pair,priority:2"`
+	Coefficient   float64   `json:"coefficient" gorm:"not null"` // Pearson, -1 to 1
+	Samples       int       `json:"samples" gorm:"not null"`
+	ComputedAt    time.Time `json:"computedAt" gorm:"not null"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Statuses of a data erasure.
+const (
+	ErasurePending   = "pending"   // waiting out the grace period
+	ErasureCompleted = "completed" // carried out; the record is the certificate
+	ErasureCancelled = "cancelled" // withdrawn during the grace period
+)
+
+// ErasedContent replaces the text of an erased agent's comments, which are
+// kept so the threads they are in still read in order.
+const ErasedContent = "[erased]"
+
+// ErasedAgentName is the pseudonym an erased agent is left with.
+func ErasedAgentName(agentID int64) string {
+	return fmt.Sprintf("erased-agent-%d", agentID)
+}
+
+// DataErasure is a request to erase an agent's personal data: its name,
+// profile and owner, and the reasoning and comments it wrote. Its scores,
+// counters and predictions stay, so aggregate statistics are unchanged.
+// Once carried out the record is the deletion certificate, see Certificate.
+type DataErasure struct {
+	ID              int64      `json:"id" gorm:"primaryKey"`
+	AgentID         int64      `json:"agentId" gorm:"not null;index"`
+	RequestedBy     string     `json:"requestedBy" gorm:"size:100"` // principal ID of the owner or admin
+	RequesterUserID *int64     `json:"-" gorm:"index"`              // the owner who asked, who keeps access once ownership is erased
+	Status          string     `json:"status" gorm:"not null;size:20;index"`
+	RequestedAt     time.Time  `json:"requestedAt" gorm:"not null"`
+	ScheduledFor    time.Time  `json:"scheduledFor" gorm:"not null;index"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+	CancelledAt     *time.Time `json:"cancelledAt,omitempty"`
+	Pseudonym       string     `json:"pseudonym,omitempty" gorm:"size:50"`
+	Erased          string     `json:"-" gorm:"type:text"` // JSON records erased by kind, see ErasureCertificate
+	CertificateHash string     `json:"certificateHash,omitempty" gorm:"size:64"`
+}
+
+// ErasureCertificate attests that an agent's personal data was erased:
+// when, under which pseudonym, and how many records of each kind were
+// anonymized or deleted.
+type ErasureCertificate struct {
+	ErasureID   int64            `json:"erasureId"`
+	AgentID     int64            `json:"agentId"`
+	Pseudonym   string           `json:"pseudonym"`
+	RequestedAt time.Time        `json:"requestedAt"`
+	CompletedAt time.Time        `json:"completedAt"`
+	Erased      map[string]int64 `json:"erased"`
+}
+
+// Hash returns the hex SHA-256 of the certificate's JSON. Map keys are
+// written sorted, so the same certificate always hashes the same.
+func (c ErasureCertificate) Hash() (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Certificate returns the deletion certificate of a completed erasure, or
+// nil for one not carried out. Times are kept to the second in UTC, as the
+// database may not keep more.
+func (e DataErasure) Certificate() (*ErasureCertificate, error) {
+	if e.Status != ErasureCompleted || e.CompletedAt == nil {
+		return nil, nil
+	}
+	certificate := ErasureCertificate{
+		ErasureID:   e.ID,
+		AgentID:     e.AgentID,
+		Pseudonym:   e.Pseudonym,
+		RequestedAt: e.RequestedAt.UTC().Truncate(time.Second),
+		CompletedAt: e.CompletedAt.UTC().Truncate(time.Second),
+		Erased:      map[string]int64{},
+	}
+	if e.Erased != "" {
+		if err := json.Unmarshal([]byte(e.Erased), &certificate.Erased); err != nil {
+			return nil, err
+		}
+	}
+	return &certificate, nil
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GovernanceDelegation lends an agent's governance voting power to another
+// agent. While it is active, the delegate's vote on a proposal is cast for
+// the delegator too, unless the delegator has already voted on it. Power
+// delegated to an agent who delegates in turn flows on to their delegate.
+// Revoking a delegation keeps the record.
+type GovernanceDelegation struct {
+	ID          int64      `json:"id" gorm:"primary_key"`
+	DelegatorID int64      `json:"delegatorId" gorm:"not null;index"`
+	DelegateID  int64      `json:"delegateId" gorm:"not null;index"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty" gorm:"index"`
+}
+
+var (
+	ErrSelfDelegation  = errors.New("an agent cannot delegate to itself")
+	ErrDelegationCycle = errors.New("delegation would create a cycle")
+)
+
+// ActiveDelegation returns the agent's active delegation, or nil if it has
+// none.
+func ActiveDelegation(db *gorm.DB, agentID int64) (*GovernanceDelegation, error) {
+	var delegation GovernanceDelegation
+	err := db.Where("delegator_id = ? AND revoked_at IS NULL", agentID).First(&delegation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+// CheckDelegation returns ErrSelfDelegation or ErrDelegationCycle if
+// delegatorID may not delegate to delegateID: a delegation may not lead,
+// through the delegate's own chain, back to the delegator.
+func CheckDelegation(db *gorm.DB, delegatorID, delegateID int64) error {
+	if delegatorID == delegateID {
+		return ErrSelfDelegation
+	}
+	seen := map[int64]bool{}
+	for current := delegateID; !seen[current]; {
+		seen[current] = true
+		next, err := ActiveDelegation(db, current)
+		if err != nil || next == nil {
+			return err
+		}
+		if next.DelegateID == delegatorID {
+			return ErrDelegationCycle
+		}
+		current = next.DelegateID
+	}
+	return nil
+}
+
+// Delegators returns the agents whose voting power flows to agentID, directly
+// or through a chain of delegations. Agents in skip, such as those who have
+// already voted, keep their power, along with whatever was delegated to
+// them.
+func Delegators(db *gorm.DB, agentID int64, skip map[int64]bool) ([]int64, error) {
+	var delegators []int64
+	seen := map[int64]bool{agentID: true}
+	for frontier := []int64{agentID}; len(frontier) > 0; {
+		var ids []int64
+		if err := db.Model(&GovernanceDelegation{}).
+			Where("delegate_id IN ? AND revoked_at IS NULL", frontier).
+			Order("delegator_id").
+			Pluck("delegator_id", &ids).Error; err != nil {
+			return nil, err
+		}
+		frontier = nil
+		for _, id := range ids {
+			if seen[id] || skip[id] {
+				continue
+			}
+			seen[id] = true
+			delegators = append(delegators, id)
+			frontier = append(frontier, id)
+		}
+	}
+	return delegators, nil
+}
+
+// VotingPower is an agent's effective governance voting power: their own
+// vote weight plus the weight delegated to them.
+type VotingPower struct {
+	Own        float64 `json:"own"`
+	Delegated  float64 `json:"delegated"`
+	Total      float64 `json:"total"`
+	Delegators int     `json:"delegators"`
+	DelegateID *int64  `json:"delegateId,omitempty"` // whom the agent has delegated to, if anyone
+}
+
+// VotingPowerOf returns agent's voting power at their current scores.
+func VotingPowerOf(db *gorm.DB, agent *Agent) (VotingPower, error) {
+	power := VotingPower{Own: ProposalVoteWeight(agent)}
+	active, err := ActiveDelegation(db, agent.ID)
+	if err != nil {
+		return power, err
+	}
+	if active != nil {
+		power.DelegateID = &active.DelegateID
+	}
+
+	ids, err := Delegators(db, agent.ID, nil)
+	if err != nil {
+		return power, err
+	}
+	if len(ids) > 0 {
+		var delegators []Agent
+		if err := db.Select("id, composite_score").Where("id IN ?", ids).Find(&delegators).Error; err != nil {
+			return power, err
+		}
+		for i := range delegators {
+			power.Delegated += ProposalVoteWeight(&delegators[i])
+		}
+	}
+	power.Delegators = len(ids)
+	power.Total = power.Own + power.Delegated
+	return power, nil
+}
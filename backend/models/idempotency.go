@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyRecord remembers the response to a request sent with an
+// Idempotency-Key so a retry gets the same response instead of repeating
+// the write. Keys are scoped to the caller that sent them.
+type IdempotencyRecord struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	Principal   string    `json:"principal" gorm:"not null;size:100;uniqueIndex:idx_idempotency_records_key,priority:1"` // e.g. "agent:12"
+	Key         string    `json:"key" gorm:"not null;size:100;uniqueIndex:idx_idempotency_records_key,priority:2"`
+	Method      string    `json:"method" gorm:"not null;size:10"`
+	Path        string    `json:"path" gorm:"not null;size:300"`
+	RequestHash string    `json:"-" gorm:"not null;size:64"`            // SHA-256 of the request body
+	StatusCode  int       `json:"statusCode" gorm:"not null;default:0"` // 0 while the first request is in flight
+	ContentType string    `json:"-" gorm:"size:100"`
+	Body        []byte    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"index"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Leaderboard windows. All-time rankings are read straight from the agents
+// table; the rolling windows are served from LeaderboardSnapshot rows.
+const (
+	LeaderboardWindowAllTime   = "all-time"
+	LeaderboardWindowWeekly    = "weekly"
+	LeaderboardWindowMonthly   = "monthly"
+	LeaderboardWindowQuarterly = "quarterly"
+)
+
+// LeaderboardWindows maps each rolling window to how far back it looks.
+var LeaderboardWindows = map[string]time.Duration{
+	LeaderboardWindowWeekly:    7 * 24 * time.Hour,
+	LeaderboardWindowMonthly:   30 * 24 * time.Hour,
+	LeaderboardWindowQuarterly: 90 * 24 * time.Hour,
+}
+
+// LeaderboardSnapshot is an agent's track record on the predictions resolved
+// within one rolling window, as of ComputedAt. Each snapshot run replaces
+// every row of the window, so agents whose predictions have all aged out
+// drop off its leaderboard.
+type LeaderboardSnapshot struct {
+	Window                  string    `json:"window" gorm:"column:time_window;primaryKey;size:20"` // WINDOW is reserved in SQL
+	AgentID                 int64     `json:"agentId" gorm:"primaryKey;autoIncrement:false"`
+	ResolvedPredictions     int64     `json:"resolvedPredictions" gorm:"not null;default:0"`
+	CorrectPredictions      int64     `json:"correctPredictions" gorm:"not null;default:0"`
+	AccuracyScore           float64   `json:"accuracyScore" gorm:"not null;default:50"`
+	ScoredPredictions       int64     `json:"scoredPredictions" gorm:"not null;default:0"`
+	MeanBrierScore          float64   `json:"meanBrierScore" gorm:"not null;default:0"`
+	CalibratedAccuracyScore float64   `json:"calibratedAccuracyScore" gorm:"not null;default:50"`
+	ComputedAt              time.Time `json:"computedAt" gorm:"not null"`
+}
+
+// RecalculateScores updates the snapshot's accuracy and calibrated accuracy
+// scores from its counters, the same way as for AgentCategoryStats.
+func (s *LeaderboardSnapshot) RecalculateScores() {
+	s.AccuracyScore = smoothedAccuracy(s.CorrectPredictions, s.ResolvedPredictions)
+	s.CalibratedAccuracyScore = smoothedCalibration(s.MeanBrierScore, s.ScoredPredictions)
+}
+
+// LeaderboardComputedAt returns when the window's snapshot was last taken,
+// or nil if it has not been taken yet or nobody qualified.
+func LeaderboardComputedAt(db *gorm.DB, window string) (*time.Time, error) {
+	var snapshot LeaderboardSnapshot
+	err := db.Where("time_window = ?", window).Order("computed_at DESC").Limit(1).Find(&snapshot).Error
+	if err != nil || snapshot.ComputedAt.IsZero() {
+		return nil, err
+	}
+	return &snapshot.ComputedAt, nil
+}
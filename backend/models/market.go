@@ -9,6 +9,7 @@ import (
 type Market struct {
 	gorm.Model
 	ID                      int64     `json:"id" gorm:"primary_key"`
+	Version                 LockVersion `json:"-" gorm:"not null;default:1"` // optimistic locking
 	QuestionTitle           string    `json:"questionTitle" gorm:"not null"`
 	Description             string    `json:"description" gorm:"not null"`
 	OutcomeType             string    `json:"outcomeType" gorm:"not null"`
@@ -25,14 +26,28 @@ type Market struct {
 	
 	// === NEW: Knowledge System Fields ===
 	
-	// Creator tracking (for agent-created markets)
+	// Creator tracking. CreatorType/CreatorID identify the actor who created
+	// the market; CreatorAgentID is kept in sync for existing queries.
+	CreatorType     string `json:"creatorType" gorm:"size:10;default:user;index:idx_markets_creator"`
+	CreatorID       int64  `json:"creatorId" gorm:"default:0;index:idx_markets_creator"`
 	CreatorAgentID  *int64 `json:"creatorAgentId,omitempty" gorm:"index"`
 	
+	// Who can see and predict on the market: MarketPublic, MarketUnlisted
+	// or MarketInviteOnly; see OpenTo.
+	Visibility string `json:"visibility" gorm:"size:12;not null;default:public;index"`
+
+	// Series the market belongs to, if any; see MarketSeries.
+	SeriesID *int64 `json:"seriesId,omitempty" gorm:"index"`
+
+	// Template the market was created from, if any; see MarketTemplate.
+	TemplateID *int64 `json:"templateId,omitempty" gorm:"index"`
+
 	// Market type for real-time/daily predictions
 	MarketType       string `json:"marketType" gorm:"default:standard"`  // "standard", "realtime", "daily"
 	
-	// Auto-resolution for real-time markets
-	ResolutionSource string `json:"resolutionSource,omitempty"` // API endpoint for auto-resolution
+	// Auto-resolution: ResolutionSource holds the JSON OracleSpec the
+	// auto-resolve job reads; see OracleSpec and SetOracleSpec.
+	ResolutionSource string `json:"resolutionSource,omitempty"`
 	AutoResolve      bool   `json:"autoResolve" gorm:"default:false"`
 	
 	// Category for filtering
@@ -41,4 +56,62 @@ type Market struct {
 	// Engagement stats
 	TotalPredictions int64  `json:"totalPredictions" gorm:"default:0"`
 	TotalEngagement  int64  `json:"totalEngagement" gorm:"default:0"`  // upvotes + comments on predictions
+
+	// Closing auction: in the final hour agents submit sealed probabilities
+	// that are revealed at close and become the official final consensus.
+	ClosingAuction   bool       `json:"closingAuction" gorm:"default:false"`
+	FinalConsensus   *float64   `json:"finalConsensus,omitempty"`
+	FinalConsensusAt *time.Time `json:"finalConsensusAt,omitempty"`
+
+	// Provenance of markets created from an approved council submission:
+	// the submission and a JSON MarketProvenance snapshot of how it was
+	// verified and voted on. Read it with DecodeProvenance.
+	SourceSubmissionID *int64 `json:"sourceSubmissionId,omitempty" gorm:"index"`
+	Provenance         string `json:"-" gorm:"type:text"`
+
+	// Structured resolution criteria; read them with ResolutionCriteria.
+	CriteriaSourceURL  string `json:"-" gorm:"size:500"`
+	CriteriaThreshold  string `json:"-" gorm:"type:text"`
+	CriteriaTimezone   string `json:"-" gorm:"size:64"`
+	CriteriaTieBreaker string `json:"-" gorm:"type:text"`
+
+	// Predictions lock this many hours before ResolutionDateTime; zero
+	// locks them at the resolution time. See PredictionsLockAt.
+	PredictionLockHours float64 `json:"predictionLockHours" gorm:"not null;default:0"`
+
+	// Scalar markets (OutcomeType OutcomeScalar) ask for a number between
+	// ScalarMin and ScalarMax, in ScalarUnit, and resolve to ResolutionValue.
+	ScalarMin       *float64 `json:"scalarMin,omitempty"`
+	ScalarMax       *float64 `json:"scalarMax,omitempty"`
+	ScalarUnit      string   `json:"scalarUnit,omitempty" gorm:"size:20"`
+	ResolutionValue *float64 `json:"resolutionValue,omitempty"`
+}
+
+// PredictionsLockAt returns when the market stops taking and revising
+// predictions.
+func (m Market) PredictionsLockAt() time.Time {
+	return m.ResolutionDateTime.Add(-time.Duration(m.PredictionLockHours * float64(time.Hour)))
+}
+
+// PredictionsLocked reports whether predictions on the market are locked
+// at now.
+func (m Market) PredictionsLocked(now time.Time) bool {
+	return !now.Before(m.PredictionsLockAt())
+}
+
+// CreatedBy returns the actor who created the market.
+func (m Market) CreatedBy() Actor {
+	return Actor{Type: ActorType(m.CreatorType), ID: m.CreatorID}
+}
+
+// SetCreatedBy records the actor who created the market.
+func (m *Market) SetCreatedBy(a Actor) {
+	m.CreatorType = string(a.Type)
+	m.CreatorID = a.ID
+	if a.IsAgent() {
+		agentID := a.ID
+		m.CreatorAgentID = &agentID
+	} else {
+		m.CreatorAgentID = nil
+	}
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Market visibilities.
+const (
+	MarketPublic     = "public"      // listed and open to every agent
+	MarketUnlisted   = "unlisted"    // open to any agent with its ID, but left out of listings
+	MarketInviteOnly = "invite_only" // open only to its creator and the agents it invites
+)
+
+// MarketVisibilities are the visibilities a market can be created with.
+var MarketVisibilities = []string{MarketPublic, MarketUnlisted, MarketInviteOnly}
+
+// MarketInvitation lets an agent see and predict on an invite-only market,
+// for closed experiments among a known set of agents.
+type MarketInvitation struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	MarketID  int64     `json:"marketId" gorm:"not null;uniqueIndex:idx_market_invitations_market_agent,priority:1"`
+	AgentID   int64     `json:"agentId" gorm:"not null;uniqueIndex:idx_market_invitations_market_agent,priority:2;index"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Listed limits a markets query to the markets that listings, search and
+// rankings show.
+func Listed(db *gorm.DB) *gorm.DB {
+	return db.Where("markets.visibility = ?", MarketPublic)
+}
+
+// OpenTo reports whether the agent agentID, 0 for any other caller, may see
+// the market and predict on it. Public and unlisted markets are open to
+// all; invite-only markets to their creator and the agents they invite.
+func (m Market) OpenTo(db *gorm.DB, agentID int64) (bool, error) {
+	if m.Visibility != MarketInviteOnly {
+		return true, nil
+	}
+	if agentID == 0 {
+		return false, nil
+	}
+	if m.CreatorAgentID != nil && *m.CreatorAgentID == agentID {
+		return true, nil
+	}
+	var invited int64
+	err := db.Model(&MarketInvitation{}).Where("market_id = ? AND agent_id = ?", m.ID, agentID).Count(&invited).Error
+	return invited > 0, err
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MarketProvenance explains why a market exists: the council submission it
+// came from, what auto-verification found and how the council voted. It is
+// captured when the market is created, so later changes to the submission
+// or votes do not rewrite it.
+type MarketProvenance struct {
+	SubmissionID           int64           `json:"submissionId"`
+	SubmitterAgentID       int64           `json:"submitterAgentId"`
+	AutoVerificationStatus string          `json:"autoVerificationStatus"`
+	AutoVerification       json.RawMessage `json:"autoVerification,omitempty"` // the submission's verification checks
+	Council                CouncilSummary  `json:"council"`
+}
+
+// CouncilSummary is the council's decision on a submission and the votes
+// behind it.
+type CouncilSummary struct {
+	Decision          string               `json:"decision"`
+	VotesFor          int                  `json:"votesFor"`
+	VotesAgainst      int                  `json:"votesAgainst"`
+	WeightFor         float64              `json:"weightFor"`
+	WeightAgainst     float64              `json:"weightAgainst"`
+	VotesRequired     int                  `json:"votesRequired"`
+	ApprovalThreshold float64              `json:"approvalThreshold"`
+	ApprovalPct       float64              `json:"approvalPct"` // share of the vote weight in favour
+	DecidedAt         *time.Time           `json:"decidedAt,omitempty"`
+	Votes             []CouncilVoteSummary `json:"votes"`
+}
+
+// CouncilVoteSummary is one validator's vote.
+type CouncilVoteSummary struct {
+	ValidatorID int64   `json:"validatorId"`
+	Vote        string  `json:"vote"`
+	Reason      string  `json:"reason,omitempty"`
+	Weight      float64 `json:"weight"`
+	// One of RejectionReasons, for reject votes
+	RejectionReason string `json:"rejectionReason,omitempty"`
+}
+
+// DecodeProvenance returns the market's provenance, or nil if it was not
+// created from a council submission.
+func (m Market) DecodeProvenance() (*MarketProvenance, error) {
+	if m.Provenance == "" {
+		return nil, nil
+	}
+	var provenance MarketProvenance
+	if err := json.Unmarshal([]byte(m.Provenance), &provenance); err != nil {
+		return nil, err
+	}
+	return &provenance, nil
+}
+
+// SetProvenance records p as the market's provenance.
+func (m *Market) SetProvenance(p MarketProvenance) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	submissionID := p.SubmissionID
+	m.SourceSubmissionID = &submissionID
+	m.Provenance = string(raw)
+	return nil
+}
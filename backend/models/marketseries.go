@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MarketSeries groups related markets, such as "Will X happen in Q1?"
+// through "...in Q4?", so they can be created and read as a set. In a
+// mutually exclusive series at most one market can resolve YES, so an
+// agent's implied chances of YES across its open markets may not add up to
+// more than 100%.
+type MarketSeries struct {
+	ID                int64     `json:"id" gorm:"primaryKey"`
+	Title             string    `json:"title" gorm:"not null;size:160"`
+	Description       string    `json:"description" gorm:"size:2000"`
+	CreatorAgentID    int64     `json:"creatorAgentId" gorm:"not null;index"`
+	MutuallyExclusive bool      `json:"mutuallyExclusive" gorm:"not null;default:false"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func (MarketSeries) TableName() string { return "market_series" }
+
+// ExclusiveSibling reports whether the market is in a mutually exclusive
+// series another of whose markets has already resolved YES.
+func (m Market) ExclusiveSibling(db *gorm.DB) (bool, error) {
+	if m.SeriesID == nil {
+		return false, nil
+	}
+	var resolved int64
+	err := db.Model(&Market{}).
+		Joins("JOIN market_series ON market_series.id = markets.series_id").
+		Where("markets.series_id = ? AND markets.id <> ? AND market_series.mutually_exclusive = ?", *m.SeriesID, m.ID, true).
+		Where("markets.is_resolved = ? AND markets.resolution_result = ?", true, "YES").
+		Count(&resolved).Error
+	return resolved > 0, err
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxMarketTags is how many tags one market can carry.
+	MaxMarketTags = 10
+	// MaxTagLength is the longest tag allowed.
+	MaxTagLength = 30
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// MarketTag attaches a free-form tag to a market. Tags complement the single
+// Category: a market is in one category but can carry several tags, and a
+// tag is shared by every market that carries it.
+type MarketTag struct {
+	ID        int64     `json:"-" gorm:"primaryKey"`
+	MarketID  int64     `json:"marketId" gorm:"not null;uniqueIndex:idx_market_tag"`
+	Tag       string    `json:"tag" gorm:"size:30;not null;uniqueIndex:idx_market_tag;index"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NormalizeTags lowercases and trims tags, turns inner spaces into hyphens
+// and drops blanks and duplicates. It rejects more than MaxMarketTags tags
+// and tags that are too long or are not letters, digits and hyphens.
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+		}
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("tag %q may only contain letters, digits and hyphens", tag)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxMarketTags {
+		return nil, fmt.Errorf("a market can have at most %d tags", MaxMarketTags)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// TagsForMarket returns the market's tags in alphabetical order.
+func TagsForMarket(db *gorm.DB, marketID int64) ([]string, error) {
+	tags := []string{}
+	err := db.Model(&MarketTag{}).Where("market_id = ?", marketID).Order("tag").Pluck("tag", &tags).Error
+	return tags, err
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Market template statuses. An active template creates a market each time
+// its schedule comes round; a paused one creates none until it is resumed.
+const (
+	TemplateActive = "active"
+	TemplatePaused = "paused"
+)
+
+// Market types. Standard markets are created one at a time; daily and
+// realtime markets are usually created by a MarketTemplate.
+const (
+	MarketTypeStandard = "standard"
+	MarketTypeDaily    = "daily"
+	MarketTypeRealtime = "realtime"
+)
+
+// MarketTypes lists the valid values of Market.MarketType.
+var MarketTypes = []string{MarketTypeStandard, MarketTypeDaily, MarketTypeRealtime}
+
+// MarketTemplateRequest describes a recurring question: the market to
+// create each time Schedule comes round and how long each stays open. It is
+// the payload of a template submission and what an approved template keeps
+// in MarketTemplate.Spec.
+//
+// QuestionTitle, Description and the resolution criteria's Threshold may
+// use the placeholders {date}, {weekday}, {month}, {year} and {time}, filled
+// in with each market's opening time in Timezone.
+type MarketTemplateRequest struct {
+	// Cron schedule, "minute hour day-of-month month day-of-week", e.g.
+	// "0 9 * * 1-5", or one of @hourly, @daily, @weekly and @monthly
+	Schedule string `json:"schedule" validate:"required,max=100"`
+	// IANA timezone the schedule is read in; defaults to the resolution
+	// criteria's timezone
+	Timezone string `json:"timezone,omitempty" validate:"max=64"`
+	// Hours from a market's creation to its resolution
+	DurationHours float64 `json:"durationHours" validate:"required,gt=0,lte=8760"`
+	MarketType    string  `json:"marketType,omitempty" validate:"omitempty,oneof=standard daily realtime"`
+
+	QuestionTitle      string   `json:"questionTitle" validate:"required,max=160"`
+	Description        string   `json:"description" validate:"max=2000"`
+	OutcomeType        string   `json:"outcomeType,omitempty" validate:"omitempty,oneof=BINARY SCALAR"`
+	InitialProbability float64  `json:"initialProbability"`
+	YesLabel           string   `json:"yesLabel,omitempty" validate:"max=20"`
+	NoLabel            string   `json:"noLabel,omitempty" validate:"max=20"`
+	Category           string   `json:"category,omitempty" validate:"max=50"`
+	Tags               []string `json:"tags,omitempty" validate:"max=10,dive,max=30"`
+	// Every market inherits the template's resolution criteria and oracle
+	ResolutionCriteria  *ResolutionCriteria `json:"resolutionCriteria,omitempty"`
+	AutoResolve         *OracleSpec         `json:"autoResolve,omitempty"`
+	PredictionLockHours float64             `json:"predictionLockHours,omitempty" validate:"gte=0"`
+	ScalarMin           *float64            `json:"scalarMin,omitempty" validate:"required_if=OutcomeType SCALAR"`
+	ScalarMax           *float64            `json:"scalarMax,omitempty" validate:"required_if=OutcomeType SCALAR"`
+	ScalarUnit          string              `json:"scalarUnit,omitempty" validate:"max=20"`
+}
+
+// Normalize trims the request, upper-cases the outcome type, lower-cases
+// the market type and defaults the timezone.
+func (r *MarketTemplateRequest) Normalize() {
+	r.Schedule = strings.TrimSpace(r.Schedule)
+	r.Timezone = strings.TrimSpace(r.Timezone)
+	r.MarketType = strings.ToLower(strings.TrimSpace(r.MarketType))
+	r.QuestionTitle = strings.TrimSpace(r.QuestionTitle)
+	r.OutcomeType = strings.ToUpper(strings.TrimSpace(r.OutcomeType))
+	if r.ResolutionCriteria != nil {
+		r.ResolutionCriteria.Normalize()
+	}
+	if r.AutoResolve != nil {
+		r.AutoResolve.Normalize()
+	}
+	if r.Timezone == "" && r.ResolutionCriteria != nil {
+		r.Timezone = r.ResolutionCriteria.Timezone
+	}
+	if r.Timezone == "" {
+		r.Timezone = "UTC"
+	}
+}
+
+// MarketTemplate is a recurring question the council approved once. The
+// scheduler creates a market from it each time its schedule comes round,
+// without going back to the council; see services/templates.
+type MarketTemplate struct {
+	ID int64 `json:"id" gorm:"primaryKey"`
+	// The approved template submission
+	SubmissionID   int64  `json:"submissionId" gorm:"not null;uniqueIndex"`
+	CreatorAgentID int64  `json:"creatorAgentId" gorm:"not null;index"`
+	QuestionTitle  string `json:"questionTitle" gorm:"not null;size:160"`
+	Schedule       string `json:"schedule" gorm:"not null;size:100"`
+	Timezone       string `json:"timezone" gorm:"not null;size:64"`
+	MarketType     string `json:"marketType" gorm:"not null;size:10;default:standard"`
+	Status         string `json:"status" gorm:"not null;size:10;default:active;index:idx_market_templates_due,priority:1"`
+	// When the next market is due
+	NextRunAt time.Time  `json:"nextRunAt" gorm:"index:idx_market_templates_due,priority:2"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	// Markets created so far
+	Instances int64 `json:"instances" gorm:"not null;default:0"`
+	// Why the template was paused, if it was paused because a market could
+	// not be created from it
+	LastError string `json:"lastError,omitempty" gorm:"size:500"`
+	// The JSON MarketTemplateRequest markets are made from, and the JSON
+	// MarketProvenance of its approval, which every market carries
+	Spec       string    `json:"-" gorm:"type:text"`
+	Provenance string    `json:"-" gorm:"type:text"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Request decodes the template's spec.
+func (t MarketTemplate) Request() (MarketTemplateRequest, error) {
+	var req MarketTemplateRequest
+	err := json.Unmarshal([]byte(t.Spec), &req)
+	return req, err
+}
+
+// DecodeProvenance decodes the provenance of the template's approval, or
+// returns nil if it has none.
+func (t MarketTemplate) DecodeProvenance() (*MarketProvenance, error) {
+	if t.Provenance == "" {
+		return nil, nil
+	}
+	var provenance MarketProvenance
+	if err := json.Unmarshal([]byte(t.Provenance), &provenance); err != nil {
+		return nil, err
+	}
+	return &provenance, nil
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// MarketWatch is an agent's bookmark on a market it means to predict on
+// later. An agent watches a market at most once.
+type MarketWatch struct {
+	ID        int64     `json:"id" gorm:"primaryKey"`
+	AgentID   int64     `json:"agentId" gorm:"not null;uniqueIndex:idx_market_watches_agent_market,priority:1"`
+	MarketID  int64     `json:"marketId" gorm:"not null;uniqueIndex:idx_market_watches_agent_market,priority:2;index"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Kinds of comment that can mention agents.
+const (
+	MentionPredictionComment = "prediction_comment"
+	MentionProposalComment   = "proposal_comment"
+)
+
+// Mention is an agent named with @name in a comment. A comment mentions each
+// agent once however often it repeats the name, so editing a comment only
+// records, and notifies, the agents it newly mentions.
+type Mention struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	CommentType string    `json:"commentType" gorm:"not null;size:20;uniqueIndex:idx_mentions_comment_agent,priority:1"`
+	CommentID   int64     `json:"commentId" gorm:"not null;uniqueIndex:idx_mentions_comment_agent,priority:2"`
+	AgentID     int64     `json:"agentId" gorm:"not null;uniqueIndex:idx_mentions_comment_agent,priority:3;index"`
+	AuthorType  string    `json:"authorType" gorm:"not null;size:10"`
+	AuthorID    int64     `json:"authorId" gorm:"not null"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
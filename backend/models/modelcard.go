@@ -0,0 +1,103 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Model card values. Request DTOs validate against the same lists.
+var (
+	ModelProviders  = []string{"openai", "anthropic", "google", "meta", "mistral", "xai", "deepseek", "alibaba", "cohere", "open_source", "other"}
+	ParameterScales = []string{"small", "medium", "large", "frontier", "unknown"} // <10B, 10-70B, 70-300B, beyond
+	AgentTools      = []string{"web_search", "code_execution", "retrieval", "news_feeds", "market_data", "calculator", "other"}
+	AutonomyLevels  = []string{"supervised", "semi_autonomous", "autonomous"}
+)
+
+// ModelCard is the structured description of what an agent runs on. Every
+// field is optional; an agent that has not filled any in has no card.
+type ModelCard struct {
+	ModelFamily    string   `json:"modelFamily,omitempty"` // e.g. "gpt-4o", "claude-3-5-sonnet"
+	Provider       string   `json:"provider,omitempty"`
+	ParameterScale string   `json:"parameterScale,omitempty"`
+	ToolsUsed      []string `json:"toolsUsed,omitempty"`
+	AutonomyLevel  string   `json:"autonomyLevel,omitempty"`
+}
+
+// IsEmpty reports whether no field of the card is set.
+func (c ModelCard) IsEmpty() bool {
+	return c.ModelFamily == "" && c.Provider == "" && c.ParameterScale == "" &&
+		len(c.ToolsUsed) == 0 && c.AutonomyLevel == ""
+}
+
+// ModelCard returns the agent's model card, or nil if it has none.
+func (a *Agent) ModelCard() *ModelCard {
+	card := ModelCard{
+		ModelFamily:    a.ModelFamily,
+		Provider:       a.ModelProvider,
+		ParameterScale: a.ParameterScale,
+		AutonomyLevel:  a.AutonomyLevel,
+	}
+	if a.ToolsUsed != "" {
+		card.ToolsUsed = strings.Split(a.ToolsUsed, ",")
+	}
+	if card.IsEmpty() {
+		return nil
+	}
+	return &card
+}
+
+// SetModelCard replaces the agent's model card.
+func (a *Agent) SetModelCard(card ModelCard) {
+	a.ModelFamily = card.ModelFamily
+	a.ModelProvider = card.Provider
+	a.ParameterScale = card.ParameterScale
+	a.ToolsUsed = strings.Join(card.ToolsUsed, ",")
+	a.AutonomyLevel = card.AutonomyLevel
+}
+
+// ModelCardFilter narrows agent queries to a model card. Empty fields do
+// not filter.
+type ModelCardFilter struct {
+	ModelFamily    string
+	Provider       string
+	ParameterScale string
+	Tool           string
+	AutonomyLevel  string
+}
+
+// ModelCardFilterFromQuery reads ?model=, ?provider=, ?scale=, ?tool= and
+// ?autonomy= from query.
+func ModelCardFilterFromQuery(query url.Values) ModelCardFilter {
+	get := func(key string) string {
+		return strings.ToLower(strings.TrimSpace(query.Get(key)))
+	}
+	return ModelCardFilter{
+		ModelFamily:    get("model"),
+		Provider:       get("provider"),
+		ParameterScale: get("scale"),
+		Tool:           get("tool"),
+		AutonomyLevel:  get("autonomy"),
+	}
+}
+
+// Apply adds the filter's conditions to an agents query.
+func (f ModelCardFilter) Apply(db *gorm.DB) *gorm.DB {
+	if f.ModelFamily != "" {
+		db = db.Where("model_family = ?", f.ModelFamily)
+	}
+	if f.Provider != "" {
+		db = db.Where("model_provider = ?", f.Provider)
+	}
+	if f.ParameterScale != "" {
+		db = db.Where("parameter_scale = ?", f.ParameterScale)
+	}
+	if f.Tool != "" {
+		db = db.Where("(',' || tools_used || ',') LIKE ?", "%,"+f.Tool+",%")
+	}
+	if f.AutonomyLevel != "" {
+		db = db.Where("autonomy_level = ?", f.AutonomyLevel)
+	}
+	return db
+}
@@ -0,0 +1,173 @@
+package modelstesting
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"socialpredict/migration"
+	"socialpredict/models"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// PostgresDSNEnv names the environment variable holding a DSN for a scratch
+// Postgres server. When it is unset, ForEachDialect starts a Postgres
+// container with docker instead.
+const PostgresDSNEnv = "TEST_POSTGRES_DSN"
+
+// PostgresContainer is the name of the container ForEachDialect starts. It
+// is left running so later test runs and other packages reuse it; remove it
+// with docker rm -f aiswarm-hub-test-postgres.
+const PostgresContainer = "aiswarm-hub-test-postgres"
+
+var (
+	postgresOnce sync.Once
+	postgresDSN  string
+	postgresErr  error
+)
+
+// ForEachDialect runs fn as a subtest against a freshly migrated SQLite
+// in-memory database and against an isolated Postgres schema. Running the
+// same assertions on both catches SQL that only works on one dialect. The
+// Postgres run uses TEST_POSTGRES_DSN when it is set and a docker container
+// otherwise, and is skipped only when neither is available.
+func ForEachDialect(t *testing.T, fn func(t *testing.T, db *gorm.DB)) {
+	t.Helper()
+
+	t.Run("sqlite", func(t *testing.T) {
+		fn(t, NewFakeDB(t))
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		dsn, err := postgresTestDSN()
+		if err != nil {
+			t.Skipf("no Postgres for the dialect run: %v", err)
+		}
+		fn(t, newPostgresTestDB(t, dsn))
+	})
+}
+
+// postgresTestDSN returns TEST_POSTGRES_DSN, or else the DSN of the test
+// container, starting it the first time it is needed.
+func postgresTestDSN() (string, error) {
+	if dsn := os.Getenv(PostgresDSNEnv); dsn != "" {
+		return dsn, nil
+	}
+	postgresOnce.Do(func() {
+		postgresDSN, postgresErr = startPostgresContainer()
+	})
+	return postgresDSN, postgresErr
+}
+
+// startPostgresContainer runs postgres:16 on a free local port, or reuses the
+// container if it already exists, and waits until it accepts
+// connections. Test packages run in parallel, so losing the race to create
+// the container falls back to using the winner's.
+func startPostgresContainer() (string, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return "", fmt.Errorf("%s not set and docker not found", PostgresDSNEnv)
+	}
+	if !containerRunning() && exec.Command("docker", "start", PostgresContainer).Run() != nil {
+		out, err := exec.Command("docker", "run", "-d", "--name", PostgresContainer,
+			"-p", "127.0.0.1::5432", "-e", "POSTGRES_PASSWORD=test", "postgres:16").CombinedOutput()
+		if err != nil && !containerRunning() {
+			return "", fmt.Errorf("start postgres container: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	out, err := exec.Command("docker", "port", PostgresContainer, "5432/tcp").Output()
+	if err != nil {
+		return "", fmt.Errorf("find postgres container port: %v", err)
+	}
+	address := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	port := address[strings.LastIndex(address, ":")+1:]
+	dsn := fmt.Sprintf("host=127.0.0.1 port=%s user=postgres password=test dbname=postgres sslmode=disable", port)
+
+	deadline := time.Now().Add(60 * time.Second)
+	for {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err == nil {
+			sqlDB, dbErr := db.DB()
+			if dbErr == nil {
+				err = sqlDB.Ping()
+				sqlDB.Close()
+			}
+			if err == nil {
+				return dsn, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("postgres container not ready: %v", err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// containerRunning reports whether the test container is up.
+func containerRunning() bool {
+	out, err := exec.Command("docker", "inspect", "-f", "{{.State.Running}}", PostgresContainer).Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// newPostgresTestDB creates a uniquely named schema, points a new connection
+// at it, runs every registered migration and drops the schema on cleanup.
+func newPostgresTestDB(t *testing.T, dsn string) *gorm.DB {
+	t.Helper()
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+
+	schema := fmt.Sprintf("it_%s_%d", sanitizeSchemaName(t.Name()), time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA IF EXISTS " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	db, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open postgres schema %s: %v", schema, err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := models.RegisterOptimisticLocking(db); err != nil {
+		t.Fatalf("register optimistic locking: %v", err)
+	}
+	if err := migration.MigrateDB(db); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	return db
+}
+
+func sanitizeSchemaName(name string) string {
+	name = strings.ToLower(name)
+	var b strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	out := b.String()
+	if len(out) > 30 {
+		out = out[:30]
+	}
+	return out
+}
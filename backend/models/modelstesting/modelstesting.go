@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"socialpredict/migration"
+	"socialpredict/models"
 	// Import migrations to ensure init() functions run during tests
 	_ "socialpredict/migration/migrations"
 
@@ -19,6 +20,9 @@ func NewFakeDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("Failed to connect to the database: %v", err)
 	}
+	if err := models.RegisterOptimisticLocking(db); err != nil {
+		t.Fatalf("Failed to register optimistic locking: %v", err)
+	}
 	if err := migration.MigrateDB(db); err != nil {
 		t.Fatalf("Failed to run migrations: %v", err)
 	}
@@ -57,6 +57,17 @@ func GenerateMarket(id int64, creatorUsername string) models.Market {
 	}
 }
 
+// GenerateAgent returns an active, unclaimed agent named name whose API key
+// and claim token are derived from the name.
+func GenerateAgent(name string) models.Agent {
+	return models.Agent{
+		Name:       name,
+		APIKey:     "swarm_sk_" + name,
+		ClaimToken: "claim_" + name,
+		IsActive:   true,
+	}
+}
+
 var userCounter int64 = 0
 
 func GenerateUser(username string, startingBalance int64) models.User {
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Notification is a message in an agent's inbox. Data carries the
+// kind-specific payload as JSON. Agents with a webhook configured also
+// receive each notification by POST, delivered through the outbox.
+// DedupKey, when set, is unique per agent so reminders are sent once.
+type Notification struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	AgentID   int64      `json:"agentId" gorm:"not null;index:idx_notifications_inbox,priority:1;uniqueIndex:idx_notifications_dedup,priority:1"`
+	DedupKey  *string    `json:"-" gorm:"size:100;uniqueIndex:idx_notifications_dedup,priority:2"`
+	Kind      string     `json:"kind" gorm:"not null;size:50"`
+	Title     string     `json:"title" gorm:"not null;size:200"`
+	Data      string     `json:"data,omitempty" gorm:"type:text"`
+	ReadAt    *time.Time `json:"readAt,omitempty" gorm:"index:idx_notifications_inbox,priority:2"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LockVersion is the type of a model's optimistic locking column. A model
+// opts in by declaring
+//
+//	Version LockVersion `json:"-" gorm:"not null;default:1"`
+//
+// and every whole-row update (Save, or Updates with a struct) of a loaded
+// row is then made conditional on the version it was loaded with.
+type LockVersion int64
+
+// ErrStaleVersion is returned when a versioned row was changed by someone
+// else since it was loaded. Reload and retry.
+var ErrStaleVersion = errors.New("record was modified concurrently")
+
+const expectedVersionKey = "optimistic_lock:expected_version"
+
+var lockVersionType = reflect.TypeOf(LockVersion(0))
+
+// RegisterOptimisticLocking installs the version check on db's update
+// callbacks. Call it once per connection, before serving requests.
+func RegisterOptimisticLocking(db *gorm.DB) error {
+	if err := db.Callback().Update().Before("gorm:update").Register("socialpredict:optimistic_lock", applyVersionCheck); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("gorm:update").Register("socialpredict:optimistic_lock_verify", verifyVersionCheck)
+}
+
+func versionField(db *gorm.DB) (reflect.Value, bool) {
+	stmt := db.Statement
+	if stmt.Schema == nil || stmt.ReflectValue.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	// Column updates (Update/Updates with a map) do not overwrite the row.
+	if _, ok := stmt.Dest.(map[string]interface{}); ok {
+		return reflect.Value{}, false
+	}
+	field := stmt.Schema.LookUpField("Version")
+	if field == nil || field.FieldType != lockVersionType {
+		return reflect.Value{}, false
+	}
+	return field.ReflectValueOf(stmt.Context, stmt.ReflectValue), true
+}
+
+func applyVersionCheck(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	value, ok := versionField(db)
+	if !ok || value.Int() == 0 {
+		return
+	}
+	expected := value.Int()
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "version"}, Value: expected},
+	}})
+	db.Statement.SetColumn("Version", LockVersion(expected+1), true)
+	db.InstanceSet(expectedVersionKey, expected)
+}
+
+func verifyVersionCheck(db *gorm.DB) {
+	expected, ok := db.InstanceGet(expectedVersionKey)
+	if !ok {
+		return
+	}
+	value, ok := versionField(db)
+	if !ok {
+		return
+	}
+	if db.Error != nil || db.Statement.RowsAffected == 0 {
+		value.SetInt(expected.(int64))
+		if db.Error == nil {
+			db.AddError(ErrStaleVersion)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// OutboxEvent is a domain event written in the same transaction as the state
+// change it describes. The outbox relay publishes undelivered rows and stamps
+// DeliveredAt, so an event is never lost if the process dies after commit.
+type OutboxEvent struct {
+	ID            int64      `json:"id" gorm:"primaryKey"`
+	Topic         string     `json:"topic" gorm:"not null;size:100;index"`
+	AggregateType string     `json:"aggregateType" gorm:"not null;size:50"`
+	AggregateID   int64      `json:"aggregateId" gorm:"not null"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	DeliveredAt   *time.Time `json:"deliveredAt" gorm:"index:idx_outbox_pending,priority:1"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"index:idx_outbox_pending,priority:2"`
+	Attempts      int        `json:"attempts" gorm:"default:0"`
+	LastError     string     `json:"lastError,omitempty" gorm:"size:500"`
+}
+
+// OutboxCursor is how far a consumer reading the outbox in the background,
+// such as the achievements job, has got: the ID of the last event it
+// handled.
+type OutboxCursor struct {
+	Consumer    string    `json:"consumer" gorm:"primaryKey;size:50"`
+	LastEventID int64     `json:"lastEventId" gorm:"not null;default:0"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
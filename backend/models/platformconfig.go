@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Platform parameter kinds.
+const (
+	ParameterInt   = "int"
+	ParameterFloat = "float"
+)
+
+// PlatformConfig overrides the setup.yaml default of one platform
+// parameter, such as the votes the council needs to decide a market.
+type PlatformConfig struct {
+	Name       string    `json:"name" gorm:"primaryKey;size:100"` // e.g. council.market.votesRequired
+	Kind       string    `json:"kind" gorm:"not null;size:10"`    // int or float
+	Value      float64   `json:"value" gorm:"not null"`
+	ProposalID *int64    `json:"proposalId,omitempty"` // the governance proposal that approved the change, if any
+	UpdatedBy  string    `json:"updatedBy" gorm:"size:100"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,17 +12,38 @@ import (
 type Prediction struct {
 	gorm.Model
 	ID       int64 `json:"id" gorm:"primary_key"`
+	Version  LockVersion `json:"-" gorm:"not null;default:1"` // optimistic locking
 	AgentID  int64 `json:"agentId" gorm:"not null;index"`
 	MarketID int64 `json:"marketId" gorm:"not null;index"`
+	TeamID   *int64 `json:"teamId,omitempty" gorm:"index"` // team the agent was on when it predicted
 
 	// Prediction details
-	Outcome    string  `json:"outcome" gorm:"not null;size:10"`  // "YES" or "NO"
+	Outcome    string  `json:"outcome" gorm:"not null;size:10"`  // "YES", "NO" or PredictionValue
 	Confidence float64 `json:"confidence" gorm:"default:50"`     // 0-100 confidence level
 	Reasoning  string  `json:"reasoning" gorm:"size:2000"`       // Why this prediction
 
+	// Quality of the reasoning from 0 to 100, see ReasoningScore, and how
+	// many validators spot reviewed the current revision's reasoning
+	ReasoningScore   float64 `json:"reasoningScore" gorm:"default:0"`
+	ReasoningReviews int64   `json:"reasoningReviews" gorm:"default:0"`
+
+	// Scalar markets: the point estimate and the interval the agent is
+	// Confidence% sure the value lands in
+	Estimate *float64 `json:"estimate,omitempty"`
+	Low      *float64 `json:"low,omitempty"`
+	High     *float64 `json:"high,omitempty"`
+
 	// Resolution
 	IsResolved bool `json:"isResolved" gorm:"default:false;index"`
 	WasCorrect bool `json:"wasCorrect" gorm:"default:false"`
+	BrierScore *float64 `json:"brierScore,omitempty"` // set by Score; nil until resolved YES or NO
+	LogLoss    *float64 `json:"logLoss,omitempty"`
+	CRPS       *float64 `json:"crps,omitempty" gorm:"column:crps"` // set by ScoreScalar
+
+	// Revisions, see PredictionRevision
+	Revision       int  `json:"revision" gorm:"not null;default:1"` // latest revision
+	ScoredRevision *int `json:"scoredRevision,omitempty"`           // revision scored at resolution
+	LateFlip       bool `json:"lateFlip" gorm:"default:false"`      // scored revision flipped the outcome just before close
 
 	// Engagement stats
 	Upvotes   int64 `json:"upvotes" gorm:"default:0"`
@@ -42,11 +64,18 @@ type PredictionPublic struct {
 	ID          int64     `json:"id"`
 	AgentID     int64     `json:"agentId"`
 	AgentName   string    `json:"agentName,omitempty"`
+	Agent       *PredictionAgent `json:"agent,omitempty"` // set by listings that include it
 	MarketID    int64     `json:"marketId"`
 	MarketTitle string    `json:"marketTitle,omitempty"`
+	TeamID      *int64    `json:"teamId,omitempty"`
 	Outcome     string    `json:"outcome"`
 	Confidence  float64   `json:"confidence"`
+	Estimate    *float64  `json:"estimate,omitempty"`
+	Low         *float64  `json:"low,omitempty"`
+	High        *float64  `json:"high,omitempty"`
 	Reasoning   string    `json:"reasoning,omitempty"`
+	ReasoningScore   float64 `json:"reasoningScore"`
+	ReasoningReviews int64   `json:"reasoningReviews,omitempty"`
 	IsResolved  bool      `json:"isResolved"`
 	WasCorrect  bool      `json:"wasCorrect"`
 	Upvotes     int64     `json:"upvotes"`
@@ -54,14 +83,31 @@ type PredictionPublic struct {
 	Comments    int64     `json:"comments"`
 	PredictedAt time.Time `json:"predictedAt"`
 	ResolvedAt  *time.Time `json:"resolvedAt,omitempty"`
+	CRPS        *float64   `json:"crps,omitempty"`
+
+	Revision       int  `json:"revision"`
+	ScoredRevision *int `json:"scoredRevision,omitempty"`
+	LateFlip       bool `json:"lateFlip,omitempty"`
 }
 
 // PredictionRequest is the request body for making a prediction
 type PredictionRequest struct {
-	MarketID   int64   `json:"marketId" binding:"required"`
-	Outcome    string  `json:"outcome" binding:"required"`  // "YES" or "NO"
-	Confidence float64 `json:"confidence"`                  // 0-100, optional
-	Reasoning  string  `json:"reasoning"`                   // optional but encouraged
+	MarketID   int64   `json:"marketId" validate:"required,gt=0"`
+	Outcome    string  `json:"outcome" validate:"required_without=Estimate,omitempty,market_outcome"` // "YES" or "NO"
+	Confidence float64 `json:"confidence" validate:"omitempty,gt=0,lte=100"`                         // 0-100, optional (defaults to 50)
+	Reasoning  string  `json:"reasoning" validate:"max=2000"`                                        // optional but encouraged
+
+	// Scalar markets take an estimate and the interval [low, high] the
+	// agent is confidence% sure the value lands in, instead of an outcome
+	Estimate *float64 `json:"estimate,omitempty" validate:"excluded_with=Outcome"`
+	Low      *float64 `json:"low,omitempty" validate:"required_with=Estimate,omitempty,ltefield=Estimate"`
+	High     *float64 `json:"high,omitempty" validate:"required_with=Estimate,omitempty,gtefield=Estimate"`
+}
+
+// Normalize upper-cases the outcome and trims the reasoning.
+func (r *PredictionRequest) Normalize() {
+	r.Outcome = strings.ToUpper(strings.TrimSpace(r.Outcome))
+	r.Reasoning = strings.TrimSpace(r.Reasoning)
 }
 
 // PredictionResponse is the response after making a prediction
@@ -77,9 +123,15 @@ func (p *Prediction) ToPublic() PredictionPublic {
 		ID:          p.ID,
 		AgentID:     p.AgentID,
 		MarketID:    p.MarketID,
+		TeamID:      p.TeamID,
 		Outcome:     p.Outcome,
 		Confidence:  p.Confidence,
+		Estimate:    p.Estimate,
+		Low:         p.Low,
+		High:        p.High,
 		Reasoning:   p.Reasoning,
+		ReasoningScore:   p.ReasoningScore,
+		ReasoningReviews: p.ReasoningReviews,
 		IsResolved:  p.IsResolved,
 		WasCorrect:  p.WasCorrect,
 		Upvotes:     p.Upvotes,
@@ -87,6 +139,11 @@ func (p *Prediction) ToPublic() PredictionPublic {
 		Comments:    p.Comments,
 		PredictedAt: p.PredictedAt,
 		ResolvedAt:  p.ResolvedAt,
+		CRPS:        p.CRPS,
+
+		Revision:       p.Revision,
+		ScoredRevision: p.ScoredRevision,
+		LateFlip:       p.LateFlip,
 	}
 
 	if p.Agent != nil {
@@ -99,6 +156,13 @@ func (p *Prediction) ToPublic() PredictionPublic {
 	return pub
 }
 
+// ScoreReasoning sets the prediction's reasoning score from its reasoning
+// and the validators' ratings of it.
+func (p *Prediction) ScoreReasoning(ratings []int) {
+	p.ReasoningScore = ReasoningScore(p.Reasoning, ratings)
+	p.ReasoningReviews = int64(len(ratings))
+}
+
 // PredictionVote represents a vote on a prediction
 type PredictionVote struct {
 	gorm.Model
@@ -109,9 +173,25 @@ type PredictionVote struct {
 	VoteType     string `json:"voteType" gorm:"not null;size:10"`   // "up" or "down"
 }
 
+// Voter returns who cast the vote.
+func (v PredictionVote) Voter() Actor {
+	return Actor{Type: ActorType(v.VoterType), ID: v.VoterID}
+}
+
+// SetVoter records who cast the vote.
+func (v *PredictionVote) SetVoter(a Actor) {
+	v.VoterType = string(a.Type)
+	v.VoterID = a.ID
+}
+
 // VoteRequest is the request body for voting
 type VoteRequest struct {
-	VoteType string `json:"voteType" binding:"required"` // "up" or "down"
+	VoteType string `json:"voteType" validate:"required,oneof=up down"`
+}
+
+// Normalize lower-cases the vote type.
+func (r *VoteRequest) Normalize() {
+	r.VoteType = strings.ToLower(strings.TrimSpace(r.VoteType))
 }
 
 // PredictionComment represents a comment on a prediction
@@ -125,17 +205,56 @@ type PredictionComment struct {
 	Content      string `json:"content" gorm:"not null;size:1000"`
 }
 
+// Author returns who wrote the comment.
+func (c PredictionComment) Author() Actor {
+	return Actor{Type: ActorType(c.AuthorType), ID: c.AuthorID}
+}
+
+// SetAuthor records who wrote the comment.
+func (c *PredictionComment) SetAuthor(a Actor) {
+	c.AuthorType = string(a.Type)
+	c.AuthorID = a.ID
+}
+
 // CommentRequest is the request body for commenting
 type CommentRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content string `json:"content" validate:"required,max=1000"`
 }
 
-// AgentFollow represents a follow relationship between agents
+// Normalize trims the comment content.
+func (r *CommentRequest) Normalize() {
+	r.Content = strings.TrimSpace(r.Content)
+}
+
+// AgentFollow represents a follow relationship between two actors. Only
+// agents can be followed today, but either side may be a user.
 type AgentFollow struct {
 	gorm.Model
-	ID         int64 `json:"id" gorm:"primary_key"`
-	FollowerID int64 `json:"followerId" gorm:"not null;index;uniqueIndex:idx_follow"`
-	FollowedID int64 `json:"followedId" gorm:"not null;index;uniqueIndex:idx_follow"`
+	ID           int64  `json:"id" gorm:"primary_key"`
+	FollowerType string `json:"followerType" gorm:"not null;size:10;default:agent;uniqueIndex:idx_follow"`
+	FollowerID   int64  `json:"followerId" gorm:"not null;index;uniqueIndex:idx_follow"`
+	FollowedType string `json:"followedType" gorm:"not null;size:10;default:agent;uniqueIndex:idx_follow"`
+	FollowedID   int64  `json:"followedId" gorm:"not null;index;uniqueIndex:idx_follow"`
+}
+
+// Follower returns who is following.
+func (f AgentFollow) Follower() Actor {
+	return Actor{Type: ActorType(f.FollowerType), ID: f.FollowerID}
+}
+
+// Followed returns who is being followed.
+func (f AgentFollow) Followed() Actor {
+	return Actor{Type: ActorType(f.FollowedType), ID: f.FollowedID}
+}
+
+// NewAgentFollow builds a follow from follower to followed.
+func NewAgentFollow(follower, followed Actor) AgentFollow {
+	return AgentFollow{
+		FollowerType: string(follower.Type),
+		FollowerID:   follower.ID,
+		FollowedType: string(followed.Type),
+		FollowedID:   followed.ID,
+	}
 }
 
 // LeaderboardEntry represents an entry in the leaderboard
@@ -162,4 +281,10 @@ type LeaderboardResponse struct {
 	SortBy      string             `json:"sortBy"`
 	Page        int                `json:"page"`
 	PageSize    int                `json:"pageSize"`
+	Category    string             `json:"category,omitempty"` // set for a category leaderboard
+
+	// Set for a rolling-window leaderboard; ComputedAt is when its snapshot
+	// was taken
+	Window     string     `json:"window,omitempty"`
+	ComputedAt *time.Time `json:"computedAt,omitempty"`
 }
@@ -0,0 +1,19 @@
+package models
+
+// PredictionAgent is the agent behind a prediction as listings return it
+// inline, with the stats clients would otherwise fetch agent by agent.
+type PredictionAgent struct {
+	ID                      int64   `json:"id"`
+	Name                    string  `json:"name"`
+	AvatarURL               string  `json:"avatarUrl,omitempty"`
+	PersonalEmoji           string  `json:"personalEmoji,omitempty"`
+	IsClaimed               bool    `json:"isClaimed"`
+	CompositeScore          float64 `json:"compositeScore"`
+	AccuracyScore           float64 `json:"accuracyScore"`
+	CalibratedAccuracyScore float64 `json:"calibratedAccuracyScore"`
+	TotalPredictions        int64   `json:"totalPredictions"`
+	CorrectPredictions      int64   `json:"correctPredictions"`
+	CorrectStreak           int64   `json:"correctStreak"`
+	// The agent's track record in the market's category, when asked for
+	Category *AgentCategoryStats `json:"category,omitempty"`
+}
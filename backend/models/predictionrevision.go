@@ -0,0 +1,107 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PredictionRevision is one version of an agent's prediction on a market.
+// Making a prediction records revision 1 and every update records the next,
+// so the Prediction row holds the latest revision and the earlier ones are
+// kept here.
+type PredictionRevision struct {
+	ID           int64     `json:"id" gorm:"primaryKey"`
+	PredictionID int64     `json:"predictionId" gorm:"not null;uniqueIndex:idx_prediction_revision"`
+	Revision     int       `json:"revision" gorm:"not null;uniqueIndex:idx_prediction_revision"`
+	Outcome      string    `json:"outcome" gorm:"not null;size:10"`
+	Confidence   float64   `json:"confidence"`
+	Estimate     *float64  `json:"estimate,omitempty"`
+	Low          *float64  `json:"low,omitempty"`
+	High         *float64  `json:"high,omitempty"`
+	Reasoning    string    `json:"reasoning" gorm:"size:2000"`
+	RevisedAt    time.Time `json:"revisedAt" gorm:"not null"`
+}
+
+// NewPredictionRevision snapshots p as its current revision.
+func NewPredictionRevision(p *Prediction, at time.Time) PredictionRevision {
+	return PredictionRevision{
+		PredictionID: p.ID,
+		Revision:     p.Revision,
+		Outcome:      p.Outcome,
+		Confidence:   p.Confidence,
+		Estimate:     p.Estimate,
+		Low:          p.Low,
+		High:         p.High,
+		Reasoning:    p.Reasoning,
+		RevisedAt:    at,
+	}
+}
+
+// PredictionRevisions returns the revisions of a prediction, oldest first.
+func PredictionRevisions(db *gorm.DB, predictionID int64) ([]PredictionRevision, error) {
+	var revisions []PredictionRevision
+	err := db.Where("prediction_id = ?", predictionID).Order("revision").Find(&revisions).Error
+	return revisions, err
+}
+
+// ScoringRevision returns the revision of a prediction that counts when its
+// market closes at closesAt: the last one made at or before the close, and
+// the one before it, if any, to tell whether it flipped the outcome. It
+// returns nil when the prediction has no revision made before the close.
+func ScoringRevision(db *gorm.DB, predictionID int64, closesAt time.Time) (final, previous *PredictionRevision, err error) {
+	var revisions []PredictionRevision
+	err = db.Where("prediction_id = ? AND revised_at <= ?", predictionID, closesAt).
+		Order("revision DESC").Limit(2).Find(&revisions).Error
+	if err != nil || len(revisions) == 0 {
+		return nil, nil, err
+	}
+	if len(revisions) == 2 {
+		previous = &revisions[1]
+	}
+	return &revisions[0], previous, nil
+}
+
+// IsLateFlip reports whether final changed the outcome of previous within
+// window of closesAt.
+func IsLateFlip(final, previous *PredictionRevision, closesAt time.Time, window time.Duration) bool {
+	if final == nil || previous == nil || final.Outcome == previous.Outcome {
+		return false
+	}
+	return !final.RevisedAt.Before(closesAt.Add(-window))
+}
+
+// ScoreRevision scores p as Score does, but on revision r rather than its
+// latest values. A late flip adds penalty to the Brier score, capped at 1.
+func (p *Prediction) ScoreRevision(r *PredictionRevision, resolution string, lateFlip bool, penalty float64) {
+	if r == nil {
+		p.Score(resolution)
+		return
+	}
+	brier := BrierScore(r.Outcome, r.Confidence, resolution)
+	if lateFlip {
+		brier = math.Min(1, brier+penalty)
+	}
+	logLoss := LogLoss(r.Outcome, r.Confidence, resolution)
+	p.IsResolved = true
+	p.WasCorrect = r.Outcome == resolution
+	p.BrierScore = &brier
+	p.LogLoss = &logLoss
+	revision := r.Revision
+	p.ScoredRevision = &revision
+	p.LateFlip = lateFlip
+}
+
+// Unscore takes back what ScoreRevision set, leaving p unresolved so it can
+// be scored again when its market's outcome is overturned.
+func (p *Prediction) Unscore() {
+	p.IsResolved = false
+	p.WasCorrect = false
+	p.BrierScore = nil
+	p.LogLoss = nil
+	p.CRPS = nil
+	p.ScoredRevision = nil
+	p.LateFlip = false
+	p.ResolvedAt = nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,6 +17,14 @@ const (
 	ProposalStatusRejected  ProposalStatus = "rejected"
 	ProposalStatusBuilding  ProposalStatus = "building"
 	ProposalStatusDeployed  ProposalStatus = "deployed"
+	ProposalStatusWithdrawn ProposalStatus = "withdrawn" // by the proposer, while voting
+)
+
+// Proposal voting modes: how much each vote counts in the tally.
+const (
+	ProposalVotingOneAgentOneVote = "one-agent-one-vote" // every vote counts 1
+	ProposalVotingWeighted        = "weighted"           // a vote counts its weight
+	ProposalVotingQuadratic       = "quadratic"          // a vote counts the square root of its weight
 )
 
 // ProposalType categorizes what kind of change is being proposed
@@ -43,6 +52,7 @@ type Proposal struct {
 	Specification string       `json:"specification" gorm:"type:text"` // Detailed technical spec
 	Priority      string       `json:"priority" gorm:"size:20"`        // low, medium, high, critical
 	Complexity    string       `json:"complexity" gorm:"size:20"`      // simple, moderate, complex
+	Revision      int          `json:"revision" gorm:"not null;default:1"` // latest ProposalRevision
 	
 	// Proposer
 	ProposerAgentID int64      `json:"proposerAgentId" gorm:"not null;index"`
@@ -52,8 +62,11 @@ type Proposal struct {
 	Status        ProposalStatus `json:"status" gorm:"not null;default:'active'"`
 	VotesFor      int64          `json:"votesFor" gorm:"default:0"`
 	VotesAgainst  int64          `json:"votesAgainst" gorm:"default:0"`
+	WeightFor     float64        `json:"weightFor" gorm:"default:0"`      // sum of yes vote weights, as counted
+	WeightAgainst float64        `json:"weightAgainst" gorm:"default:0"`  // sum of no vote weights, as counted
 	VoteThreshold int64          `json:"voteThreshold" gorm:"default:5"`    // Min votes needed
 	ApprovalPct   float64        `json:"approvalPct" gorm:"default:60.0"`   // % needed to pass
+	VotingMode    string         `json:"votingMode" gorm:"not null;size:20;default:'weighted'"` // chosen by the proposer
 	
 	// Timeline
 	VotingEndsAt  time.Time      `json:"votingEndsAt"`
@@ -79,7 +92,8 @@ type ProposalVote struct {
 	
 	Vote       string `json:"vote" gorm:"not null;size:10"` // "yes" or "no"
 	Reasoning  string `json:"reasoning" gorm:"type:text"`
-	Weight     float64 `json:"weight" gorm:"default:1.0"`   // Based on agent reputation
+	Weight     float64 `json:"weight" gorm:"default:1.0"`   // ProposalVoteWeight of the agent when they voted
+	DelegateID *int64  `json:"delegateId,omitempty" gorm:"index"` // set when cast by the agent's delegate
 	
 	Agent      Agent  `json:"agent" gorm:"foreignKey:AgentID"`
 }
@@ -106,14 +120,19 @@ type ProposalPublic struct {
 	Specification   string         `json:"specification"`
 	Priority        string         `json:"priority"`
 	Complexity      string         `json:"complexity"`
+	Revision        int            `json:"revision"`
 	ProposerAgentID int64          `json:"proposerAgentId"`
 	ProposerName    string         `json:"proposerName"`
 	Status          ProposalStatus `json:"status"`
 	VotesFor        int64          `json:"votesFor"`
 	VotesAgainst    int64          `json:"votesAgainst"`
+	WeightFor       float64        `json:"weightFor"`
+	WeightAgainst   float64        `json:"weightAgainst"`
 	VoteThreshold   int64          `json:"voteThreshold"`
 	ApprovalPct     float64        `json:"approvalPct"`
-	CurrentPct      float64        `json:"currentPct"` // Calculated
+	VotingMode      string         `json:"votingMode"`
+	CurrentPct      float64        `json:"currentPct"`  // Calculated from the raw votes
+	WeightedPct     float64        `json:"weightedPct"` // Calculated from the vote weights; decides the proposal
 	VotingEndsAt    time.Time      `json:"votingEndsAt"`
 	HumanApproved   bool           `json:"humanApproved"`
 	CreatedAt       time.Time      `json:"createdAt"`
@@ -140,14 +159,19 @@ func (p *Proposal) ToPublic() ProposalPublic {
 		Specification:   p.Specification,
 		Priority:        p.Priority,
 		Complexity:      p.Complexity,
+		Revision:        p.Revision,
 		ProposerAgentID: p.ProposerAgentID,
 		ProposerName:    proposerName,
 		Status:          p.Status,
 		VotesFor:        p.VotesFor,
 		VotesAgainst:    p.VotesAgainst,
+		WeightFor:       p.WeightFor,
+		WeightAgainst:   p.WeightAgainst,
 		VoteThreshold:   p.VoteThreshold,
 		ApprovalPct:     p.ApprovalPct,
+		VotingMode:      p.VotingMode,
 		CurrentPct:      currentPct,
+		WeightedPct:     p.WeightedPct(),
 		VotingEndsAt:    p.VotingEndsAt,
 		HumanApproved:   p.HumanApproved,
 		CreatedAt:       p.CreatedAt,
@@ -166,9 +190,8 @@ func (p *Proposal) CheckAndUpdateStatus() bool {
 			return true
 		}
 		
-		// Check approval percentage
-		approvalPct := float64(p.VotesFor) / float64(totalVotes) * 100
-		if approvalPct >= p.ApprovalPct {
+		// Check approval percentage of the vote weight
+		if p.WeightedPct() >= p.ApprovalPct {
 			p.Status = ProposalStatusApproved
 			now := time.Now()
 			p.ApprovedAt = &now
@@ -179,3 +202,46 @@ func (p *Proposal) CheckAndUpdateStatus() bool {
 	}
 	return false
 }
+
+// ProposalVoteWeight is how much agent's vote on a proposal counts: 1 plus
+// their composite score as a fraction, so the best agents count double. It
+// is captured on the vote when cast, so later score changes do not move
+// tallies already made.
+func ProposalVoteWeight(agent *Agent) float64 {
+	return 1.0 + agent.CompositeScore/100.0
+}
+
+// AddVote counts a vote ("yes" or "no") of weight in the tally, as the
+// proposal's voting mode counts it.
+func (p *Proposal) AddVote(vote string, weight float64) {
+	weight = p.CountedWeight(weight)
+	if vote == "yes" {
+		p.VotesFor++
+		p.WeightFor += weight
+	} else {
+		p.VotesAgainst++
+		p.WeightAgainst += weight
+	}
+}
+
+// CountedWeight returns how much a vote of weight counts in the proposal's
+// tally under its voting mode. Proposals without a mode are weighted.
+func (p Proposal) CountedWeight(weight float64) float64 {
+	switch p.VotingMode {
+	case ProposalVotingOneAgentOneVote:
+		return 1
+	case ProposalVotingQuadratic:
+		return math.Sqrt(weight)
+	default:
+		return weight
+	}
+}
+
+// WeightedPct returns the percentage of the vote weight cast in favour.
+func (p Proposal) WeightedPct() float64 {
+	total := p.WeightFor + p.WeightAgainst
+	if total <= 0 {
+		return 0
+	}
+	return p.WeightFor / total * 100
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestProposalCheckAndUpdateStatus_DecidesOnVoteWeight(t *testing.T) {
+	weak := &Agent{CompositeScore: 0}
+	strong := &Agent{CompositeScore: 100}
+
+	p := Proposal{Status: ProposalStatusActive, VoteThreshold: 5, ApprovalPct: 50, VotingEndsAt: time.Now().Add(-time.Minute)}
+	for i := 0; i < 3; i++ {
+		p.AddVote("yes", ProposalVoteWeight(weak))
+	}
+	p.AddVote("no", ProposalVoteWeight(strong))
+	p.AddVote("no", ProposalVoteWeight(strong))
+
+	if !p.CheckAndUpdateStatus() || p.Status != ProposalStatusRejected {
+		t.Fatalf("expected two heavy no votes to outweigh three light yes votes, got %s", p.Status)
+	}
+	public := p.ToPublic()
+	if public.VotesFor != 3 || public.WeightFor != 3 || public.WeightAgainst != 4 || public.CurrentPct != 60 {
+		t.Fatalf("expected raw and weighted counts side by side, got %+v", public)
+	}
+}
+
+func TestProposalAddVote_TalliesByVotingMode(t *testing.T) {
+	strong := ProposalVoteWeight(&Agent{CompositeScore: 100})
+	tests := []struct {
+		mode string
+		want float64
+	}{
+		{ProposalVotingOneAgentOneVote, 1},
+		{ProposalVotingWeighted, 2},
+		{ProposalVotingQuadratic, math.Sqrt2},
+		{"", 2},
+	}
+	for _, tt := range tests {
+		p := Proposal{VotingMode: tt.mode}
+		p.AddVote("no", strong)
+		if math.Abs(p.WeightAgainst-tt.want) > 1e-9 || p.VotesAgainst != 1 {
+			t.Errorf("mode %q: expected a weight-2 vote to count %g, got %g", tt.mode, tt.want, p.WeightAgainst)
+		}
+	}
+}
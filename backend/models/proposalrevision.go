@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of proposal revision.
+const (
+	ProposalRevisionCreated   = "created"
+	ProposalRevisionEdit      = "edit"      // made before voting got under way
+	ProposalRevisionAmendment = "amendment" // made during voting; restarts the voting window
+)
+
+// ProposalRevision is one version of a proposal's content. Creating a
+// proposal records revision 1 and every edit or amendment records the next,
+// so the Proposal row holds the latest revision and the changelog is kept
+// here.
+type ProposalRevision struct {
+	ID            int64     `json:"id" gorm:"primaryKey"`
+	ProposalID    int64     `json:"proposalId" gorm:"not null;uniqueIndex:idx_proposal_revision"`
+	Revision      int       `json:"revision" gorm:"not null;uniqueIndex:idx_proposal_revision"`
+	Kind          string    `json:"kind" gorm:"not null;size:20"`
+	Title         string    `json:"title" gorm:"not null;size:200"`
+	Description   string    `json:"description" gorm:"type:text"`
+	Specification string    `json:"specification" gorm:"type:text"`
+	Priority      string    `json:"priority" gorm:"size:20"`
+	Complexity    string    `json:"complexity" gorm:"size:20"`
+	Note          string    `json:"note" gorm:"size:500"` // the proposer's summary of what changed
+	VotingEndsAt  time.Time `json:"votingEndsAt"`
+	RevisedAt     time.Time `json:"revisedAt" gorm:"not null"`
+}
+
+// NewProposalRevision snapshots p as its current revision.
+func NewProposalRevision(p *Proposal, kind, note string, at time.Time) ProposalRevision {
+	return ProposalRevision{
+		ProposalID:    p.ID,
+		Revision:      p.Revision,
+		Kind:          kind,
+		Title:         p.Title,
+		Description:   p.Description,
+		Specification: p.Specification,
+		Priority:      p.Priority,
+		Complexity:    p.Complexity,
+		Note:          note,
+		VotingEndsAt:  p.VotingEndsAt,
+		RevisedAt:     at,
+	}
+}
+
+// ProposalRevisions returns the revisions of a proposal, oldest first.
+func ProposalRevisions(db *gorm.DB, proposalID int64) ([]ProposalRevision, error) {
+	var revisions []ProposalRevision
+	err := db.Where("proposal_id = ?", proposalID).Order("revision").Find(&revisions).Error
+	return revisions, err
+}
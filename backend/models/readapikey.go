@@ -0,0 +1,45 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// ReadAPIKeyPrefix starts every read-only API key.
+const ReadAPIKeyPrefix = "swarm_rk_"
+
+// ReadAPIKey is a read-only API key minted by a human agent owner for a
+// frontend or bot that only reads public data (consensus, leaderboards,
+// stats). It grants a higher rate limit than anonymous access and nothing
+// else. An operator may mark a key Analytics, for research and dashboards
+// that read in bulk, which raises its limit further. Only the SHA-256 of
+// the key is stored.
+type ReadAPIKey struct {
+	ID           int64      `json:"id" gorm:"primaryKey"`
+	OwnerUserID  int64      `json:"-" gorm:"not null;index"`
+	Name         string     `json:"name" gorm:"not null;size:100"`
+	KeyHash      string     `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	KeyPrefix    string     `json:"keyPrefix" gorm:"not null;size:20"` // first characters, to tell keys apart
+	Analytics    bool       `json:"analytics" gorm:"not null;default:false"`
+	RequestCount int64      `json:"requestCount" gorm:"not null;default:0"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// GenerateReadAPIKey creates a secure random read-only API key.
+func GenerateReadAPIKey() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return ReadAPIKeyPrefix + hex.EncodeToString(bytes), nil
+}
+
+// HashReadAPIKey returns the value stored in ReadAPIKey.KeyHash for key.
+func HashReadAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
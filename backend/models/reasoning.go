@@ -0,0 +1,105 @@
+package models
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ReasoningEngagementWeight is how much of an agent's engagement score
+// comes from the quality of its reasoning rather than the upvotes,
+// comments and followers it gets.
+const ReasoningEngagementWeight = 0.5
+
+// Reasoning quality heuristics: points for length, sources, figures and
+// structure, 100 in all.
+const (
+	reasoningLengthPoints    = 40 // full marks at reasoningFullWords words
+	reasoningSourcePoints    = 30 // 15 per source cited
+	reasoningFigurePoints    = 15 // 5 per figure quoted
+	reasoningStructurePoints = 15 // 5 per sentence after the first
+	reasoningFullWords       = 60
+)
+
+var (
+	reasoningURL         = regexp.MustCompile(`https?://\S+`)
+	reasoningAttribution = regexp.MustCompile(`(?i)\b(according to|reported by|per the|source:|sources:|data from|as of|cited by|published by)\b|\[\d+\]`)
+	reasoningFigure      = regexp.MustCompile(`\d[\d,.]*\s?(%|percent|bp|bps|k|m|bn)?`)
+	reasoningSentence    = regexp.MustCompile(`[.!?]+(\s|$)`)
+)
+
+// ReasoningHeuristic scores reasoning from 0 to 100 by its length, the
+// sources it cites, the figures it quotes and how many sentences it runs
+// to. Empty reasoning scores 0 and a one-liner little more.
+func ReasoningHeuristic(reasoning string) float64 {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return 0
+	}
+
+	words := float64(len(strings.Fields(reasoning)))
+	score := math.Min(1, words/reasoningFullWords) * reasoningLengthPoints
+
+	sources := len(reasoningURL.FindAllString(reasoning, -1)) + len(reasoningAttribution.FindAllString(reasoning, -1))
+	score += math.Min(reasoningSourcePoints, float64(sources)*15)
+
+	figures := len(reasoningFigure.FindAllString(reasoningURL.ReplaceAllString(reasoning, ""), -1))
+	score += math.Min(reasoningFigurePoints, float64(figures)*5)
+
+	if sentences := len(reasoningSentence.FindAllString(reasoning, -1)); sentences > 1 {
+		score += math.Min(reasoningStructurePoints, float64(sentences-1)*5)
+	}
+
+	return math.Min(100, score)
+}
+
+// ReasoningScore is the quality of reasoning from 0 to 100: the heuristic,
+// blended with the validators' spot review ratings (1 to 5) once there are
+// any. Reviews count for more the more of them there are: one counts for
+// half the score, three for three quarters.
+func ReasoningScore(reasoning string, ratings []int) float64 {
+	heuristic := ReasoningHeuristic(reasoning)
+	if len(ratings) == 0 {
+		return heuristic
+	}
+	sum := 0
+	for _, r := range ratings {
+		sum += r
+	}
+	reviewed := (float64(sum)/float64(len(ratings)) - 1) / 4 * 100
+	weight := float64(len(ratings)) / float64(len(ratings)+1)
+	return heuristic*(1-weight) + reviewed*weight
+}
+
+// Reasoning review ratings.
+const (
+	MinReasoningRating = 1 // unsupported
+	MaxReasoningRating = 5 // rigorous
+)
+
+// ReasoningReview is a council validator's spot review of the reasoning of
+// one revision of a prediction. A validator reviews each revision once;
+// reviewing it again replaces the rating.
+type ReasoningReview struct {
+	ID               int64     `json:"id" gorm:"primaryKey"`
+	PredictionID     int64     `json:"predictionId" gorm:"not null;uniqueIndex:idx_reasoning_review"`
+	Revision         int       `json:"revision" gorm:"not null;uniqueIndex:idx_reasoning_review"`
+	ValidatorAgentID int64     `json:"validatorAgentId" gorm:"not null;uniqueIndex:idx_reasoning_review;index"`
+	Rating           int       `json:"rating" gorm:"not null"`
+	Note             string    `json:"note,omitempty" gorm:"size:500"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// ReasoningReviewRequest is a validator's spot review of a prediction's
+// reasoning.
+type ReasoningReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Note   string `json:"note,omitempty" validate:"max=500"`
+}
+
+// Normalize trims the note.
+func (r *ReasoningReviewRequest) Normalize() {
+	r.Note = strings.TrimSpace(r.Note)
+}
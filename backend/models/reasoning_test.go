@@ -0,0 +1,53 @@
+package models
+
+import "testing"
+
+const wellArgued = `The Fed has held rates at 5.25-5.50% for four meetings. According to the
+March dot plot, 9 of 19 members expect a cut by June, and CPI fell to 3.1% in
+January (https://www.bls.gov/cpi/). Futures price a 62% chance of a June cut.
+A hot February print is the main risk, so I stop short of high confidence.`
+
+func TestReasoningHeuristic_WellArguedBeatsOneLiner(t *testing.T) {
+	empty, oneLiner, argued := ReasoningHeuristic("  "), ReasoningHeuristic("Rates go down, trust me"), ReasoningHeuristic(wellArgued)
+	if empty != 0 {
+		t.Errorf("empty reasoning scored %v, want 0", empty)
+	}
+	if oneLiner >= 20 {
+		t.Errorf("one-liner scored %v, want under 20", oneLiner)
+	}
+	if argued < 80 || argued > 100 {
+		t.Errorf("well-argued reasoning scored %v, want 80-100", argued)
+	}
+}
+
+func TestReasoningScore_ReviewsOutweighTheHeuristicAsTheyAddUp(t *testing.T) {
+	heuristic := ReasoningHeuristic(wellArgued)
+	if got := ReasoningScore(wellArgued, nil); got != heuristic {
+		t.Errorf("unreviewed score = %v, want the heuristic %v", got, heuristic)
+	}
+	if got, want := ReasoningScore(wellArgued, []int{1}), heuristic/2; got != want {
+		t.Errorf("one 1-star review: score = %v, want %v", got, want)
+	}
+	if got, want := ReasoningScore(wellArgued, []int{1, 1, 1}), heuristic/4; got != want {
+		t.Errorf("three 1-star reviews: score = %v, want %v", got, want)
+	}
+	if got := ReasoningScore("Rates go down, trust me", []int{5, 5, 5}); got < 75 {
+		t.Errorf("three 5-star reviews of a one-liner: score = %v, want at least 75", got)
+	}
+}
+
+func TestRecalculateEngagementScore_BlendsReasoningQuality(t *testing.T) {
+	popular := &Agent{TotalPredictions: 10, TotalUpvotesReceived: 99, MeanReasoningScore: 5}
+	rigorous := &Agent{TotalPredictions: 10, TotalUpvotesReceived: 9, MeanReasoningScore: 90}
+	popular.RecalculateEngagementScore()
+	rigorous.RecalculateEngagementScore()
+	if popular.EngagementScore >= rigorous.EngagementScore {
+		t.Errorf("popular one-liners scored %v, rigorous reasoning %v; want rigorous higher", popular.EngagementScore, rigorous.EngagementScore)
+	}
+
+	newcomer := &Agent{TotalFollowers: 9}
+	newcomer.RecalculateEngagementScore()
+	if newcomer.EngagementScore != 25 {
+		t.Errorf("agent without predictions scored %v, want 25 from popularity alone", newcomer.EngagementScore)
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import "strings"
+
+// ResolutionCriteria spells out how a market will be resolved, so the
+// outcome does not hang on how its free-text description is read.
+type ResolutionCriteria struct {
+	// SourceURL is the page or feed that is the source of truth.
+	SourceURL string `json:"sourceUrl" validate:"max=500"`
+	// Threshold is the exact condition for YES, e.g. "BTC/USD close at or
+	// above 100000.00".
+	Threshold string `json:"threshold" validate:"max=500"`
+	// Timezone is the IANA zone the resolution date and any daily figures
+	// are read in, e.g. "America/New_York".
+	Timezone string `json:"timezone" validate:"max=64"`
+	// TieBreaker says how the market resolves if the source is ambiguous,
+	// late, revised or lands exactly on the threshold.
+	TieBreaker string `json:"tieBreaker" validate:"max=1000"`
+}
+
+// Normalize trims the criteria.
+func (c *ResolutionCriteria) Normalize() {
+	c.SourceURL = strings.TrimSpace(c.SourceURL)
+	c.Threshold = strings.TrimSpace(c.Threshold)
+	c.Timezone = strings.TrimSpace(c.Timezone)
+	c.TieBreaker = strings.TrimSpace(c.TieBreaker)
+}
+
+// IsZero reports whether no criterion is set.
+func (c ResolutionCriteria) IsZero() bool {
+	return c == ResolutionCriteria{}
+}
+
+// ResolutionCriteria returns the market's structured resolution criteria,
+// or nil if it was created without them.
+func (m Market) ResolutionCriteria() *ResolutionCriteria {
+	c := ResolutionCriteria{
+		SourceURL:  m.CriteriaSourceURL,
+		Threshold:  m.CriteriaThreshold,
+		Timezone:   m.CriteriaTimezone,
+		TieBreaker: m.CriteriaTieBreaker,
+	}
+	if c.IsZero() {
+		return nil
+	}
+	return &c
+}
+
+// SetResolutionCriteria records c on the market.
+func (m *Market) SetResolutionCriteria(c ResolutionCriteria) {
+	m.CriteriaSourceURL = c.SourceURL
+	m.CriteriaThreshold = c.Threshold
+	m.CriteriaTimezone = c.Timezone
+	m.CriteriaTieBreaker = c.TieBreaker
+}
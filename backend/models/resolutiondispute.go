@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ResolutionDispute is an agent's challenge to the outcome a market was
+// resolved with, filed within the dispute window by an agent who predicted
+// on it. Disputes are weighted by the disputing agent's accuracy; enough of
+// them send the resolution back to the council.
+type ResolutionDispute struct {
+	ID        int64     `json:"id" gorm:"primary_key"`
+	MarketID  int64     `json:"marketId" gorm:"not null;uniqueIndex:idx_resolution_dispute_agent"`
+	AgentID   int64     `json:"agentId" gorm:"not null;index;uniqueIndex:idx_resolution_dispute_agent"`
+	Outcome   string    `json:"outcome" gorm:"not null"` // the outcome being disputed
+	Reason    string    `json:"reason" gorm:"type:text"`
+	Evidence  string    `json:"-" gorm:"type:text"` // JSON array of links
+	Weight    float64   `json:"weight" gorm:"not null;default:1"`
+	RequestID *int64    `json:"requestId,omitempty" gorm:"index"` // the council round it opened, once opened
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EvidenceLinks returns the links the agent cited for their dispute.
+func (d ResolutionDispute) EvidenceLinks() ([]string, error) {
+	return decodeLinks(d.Evidence)
+}
+
+// SetEvidenceLinks records links as the evidence for the dispute.
+func (d *ResolutionDispute) SetEvidenceLinks(links []string) error {
+	raw, err := encodeLinks(links)
+	d.Evidence = raw
+	return err
+}
+
+// DisputeWeight is how much agent's dispute counts: like a validator's
+// vote, 1 plus their accuracy score as a fraction.
+func DisputeWeight(agent *Agent) float64 {
+	return 1.0 + agent.AccuracyScore/100.0
+}
+
+// PendingDisputeWeight returns the combined weight of the disputes of the
+// market's current resolution that have not yet opened a council round.
+func PendingDisputeWeight(db *gorm.DB, marketID int64) (float64, error) {
+	var weight float64
+	err := db.Model(&ResolutionDispute{}).
+		Select("COALESCE(SUM(weight), 0)").
+		Where("market_id = ? AND request_id IS NULL", marketID).
+		Scan(&weight).Error
+	return weight, err
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// Resolution request statuses.
+const (
+	ResolutionRequestVoting     = "voting"
+	ResolutionRequestResolved   = "resolved"
+	ResolutionRequestExpired    = "expired"    // voting ended without a decisive outcome
+	ResolutionRequestSuperseded = "superseded" // the market was resolved some other way
+)
+
+// ResolutionRequest asks the validator council to resolve a market whose
+// resolution date has passed. Validators vote YES, NO or N/A with evidence;
+// the outcome with enough of the vote weight resolves the market. A market
+// whose resolution is disputed gets a second round, which may overturn the
+// outcome it was resolved with.
+type ResolutionRequest struct {
+	ID       int64       `json:"id" gorm:"primary_key"`
+	Version  LockVersion `json:"-" gorm:"not null;default:1"` // optimistic locking
+	MarketID int64       `json:"marketId" gorm:"not null;uniqueIndex:idx_resolution_request_round"`
+	Status   string      `json:"status" gorm:"not null;default:voting;index"`
+
+	// Round 1 is the first resolution; round 2 reviews a disputed one
+	Round           int    `json:"round" gorm:"not null;default:1;uniqueIndex:idx_resolution_request_round"`
+	DisputedOutcome string `json:"disputedOutcome,omitempty"` // the outcome a round 2 reviews
+
+	VotesYes  int     `json:"votesYes" gorm:"not null;default:0"`
+	VotesNo   int     `json:"votesNo" gorm:"not null;default:0"`
+	VotesNA   int     `json:"votesNA" gorm:"not null;default:0"`
+	WeightYes float64 `json:"weightYes" gorm:"not null;default:0"`
+	WeightNo  float64 `json:"weightNo" gorm:"not null;default:0"`
+	WeightNA  float64 `json:"weightNA" gorm:"not null;default:0"`
+
+	// Stamped from the council's resolution policy when the request opens
+	VotesRequired     int       `json:"votesRequired" gorm:"not null"` // voters that decide it early
+	MinVoters         int       `json:"minVoters" gorm:"not null"`     // voters needed to decide it at all
+	ApprovalThreshold float64   `json:"approvalThreshold" gorm:"not null"`
+	VotingEndsAt      time.Time `json:"votingEndsAt" gorm:"index"`
+
+	Outcome    string     `json:"outcome,omitempty"` // YES, NO or N/A once resolved
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// AddVote counts a vote for outcome ("YES", "NO" or "N/A") of weight in the
+// tally.
+func (r *ResolutionRequest) AddVote(outcome string, weight float64) {
+	switch outcome {
+	case "YES":
+		r.VotesYes++
+		r.WeightYes += weight
+	case "NO":
+		r.VotesNo++
+		r.WeightNo += weight
+	default:
+		r.VotesNA++
+		r.WeightNA += weight
+	}
+}
+
+// RemoveVote takes a vote previously counted with AddVote out of the tally.
+func (r *ResolutionRequest) RemoveVote(outcome string, weight float64) {
+	switch outcome {
+	case "YES":
+		r.VotesYes--
+		r.WeightYes = math.Max(0, r.WeightYes-weight)
+	case "NO":
+		r.VotesNo--
+		r.WeightNo = math.Max(0, r.WeightNo-weight)
+	default:
+		r.VotesNA--
+		r.WeightNA = math.Max(0, r.WeightNA-weight)
+	}
+}
+
+// IsDispute reports whether the request reviews a disputed resolution.
+func (r ResolutionRequest) IsDispute() bool {
+	return r.Round > 1
+}
+
+// Voters returns how many distinct validators have voted; a validator has
+// at most one vote on a request.
+func (r ResolutionRequest) Voters() int {
+	return r.VotesYes + r.VotesNo + r.VotesNA
+}
+
+// Leading returns the outcome with the most vote weight and its share of
+// the total weight as a percentage. Ties go to N/A, then NO, as neither
+// side has made its case; with no votes it returns "" and 0.
+func (r ResolutionRequest) Leading() (string, float64) {
+	total := r.WeightYes + r.WeightNo + r.WeightNA
+	if total <= 0 {
+		return "", 0
+	}
+	outcome, weight := "N/A", r.WeightNA
+	if r.WeightNo > weight {
+		outcome, weight = "NO", r.WeightNo
+	}
+	if r.WeightYes > weight {
+		outcome, weight = "YES", r.WeightYes
+	}
+	return outcome, weight / total * 100
+}
+
+// Decided returns the outcome that has reached the approval threshold, or
+// "" if none has.
+func (r ResolutionRequest) Decided() string {
+	outcome, pct := r.Leading()
+	if outcome == "" || pct < r.ApprovalThreshold {
+		return ""
+	}
+	return outcome
+}
+
+// ResolutionVote records a validator's vote on a resolution request.
+type ResolutionVote struct {
+	ID          int64     `json:"id" gorm:"primary_key"`
+	RequestID   int64     `json:"requestId" gorm:"not null;uniqueIndex:idx_resolution_vote_validator"`
+	ValidatorID int64     `json:"validatorId" gorm:"not null;index;uniqueIndex:idx_resolution_vote_validator"`
+	Outcome     string    `json:"outcome" gorm:"not null"` // YES, NO or N/A
+	Reason      string    `json:"reason" gorm:"type:text"`
+	Evidence    string    `json:"-" gorm:"type:text"` // JSON array of links
+	Weight      float64   `json:"weight" gorm:"not null;default:1"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// EvidenceLinks returns the links the validator cited for their vote.
+func (v ResolutionVote) EvidenceLinks() ([]string, error) {
+	return decodeLinks(v.Evidence)
+}
+
+// SetEvidenceLinks records links as the evidence for the vote.
+func (v *ResolutionVote) SetEvidenceLinks(links []string) error {
+	raw, err := encodeLinks(links)
+	v.Evidence = raw
+	return err
+}
+
+// decodeLinks decodes evidence links stored as a JSON array.
+func decodeLinks(raw string) ([]string, error) {
+	links := []string{}
+	if raw == "" {
+		return links, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// encodeLinks encodes evidence links as a JSON array for storage.
+func encodeLinks(links []string) (string, error) {
+	raw, err := json.Marshal(links)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// SandboxPrediction is a prediction on the synthetic sandbox market, where
+// new agents test their integration. It is resolved as soon as it is made
+// and lives in its own table, so no scoring, leaderboard or market listing
+// ever sees it.
+type SandboxPrediction struct {
+	ID         int64     `json:"id" gorm:"primaryKey"`
+	AgentID    int64     `json:"agentId" gorm:"not null;index"`
+	Outcome    string    `json:"outcome" gorm:"not null;size:10"`
+	Confidence float64   `json:"confidence"`
+	Reasoning  string    `json:"reasoning,omitempty" gorm:"size:2000"`
+	Resolution string    `json:"resolution" gorm:"not null;size:10"` // "YES" or "NO"
+	WasCorrect bool      `json:"wasCorrect"`
+	BrierScore float64   `json:"brierScore"` // what the prediction would have scored
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Resolve settles the prediction as resolution.
+func (p *SandboxPrediction) Resolve(resolution string) {
+	p.Resolution = resolution
+	p.WasCorrect = p.Outcome == resolution
+	p.BrierScore = BrierScore(p.Outcome, p.Confidence, resolution)
+}
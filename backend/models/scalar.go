@@ -0,0 +1,134 @@
+package models
+
+import (
+	"math"
+	"strconv"
+)
+
+// Market outcome types. A binary market asks YES or NO; a scalar market
+// asks for a number between its ScalarMin and ScalarMax.
+const (
+	OutcomeBinary = "BINARY"
+	OutcomeScalar = "SCALAR"
+)
+
+// PredictionValue is the Outcome of a prediction on a scalar market, whose
+// answer is its Estimate rather than a side.
+const PredictionValue = "VALUE"
+
+// IsScalar reports whether the market asks for a number.
+func (m Market) IsScalar() bool {
+	return m.OutcomeType == OutcomeScalar
+}
+
+// ScalarBounds returns the range of a scalar market's answer.
+func (m Market) ScalarBounds() (lower, upper float64) {
+	if m.ScalarMin != nil {
+		lower = *m.ScalarMin
+	}
+	if m.ScalarMax != nil {
+		upper = *m.ScalarMax
+	}
+	return lower, upper
+}
+
+// FormatScalarValue is how a scalar resolution is recorded in
+// Market.ResolutionResult.
+func FormatScalarValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// ScalarCRPS is the continuous ranked probability score of a scalar
+// prediction against the resolved value, divided by the width of the
+// market's range [lower, upper] so it runs from 0 (all belief on the value)
+// to 1 (all belief on the far end of the range), like a Brier score.
+//
+// The prediction is read as a distribution over the range whose CDF is
+// piecewise linear through the quantiles it states: 0 at lower, (1-c)/2 at
+// low, the median at estimate, (1+c)/2 at high and 1 at upper, where c is
+// the confidence that the value lies in [low, high]. Values outside the
+// range are scored at the nearer bound.
+func ScalarCRPS(estimate, low, high, confidence, lower, upper, value float64) float64 {
+	width := upper - lower
+	if width <= 0 {
+		return 0
+	}
+	value = math.Min(math.Max(value, lower), upper)
+	c := math.Min(math.Max(confidence/100, 0), 1)
+	xs := []float64{lower, low, estimate, high, upper}
+	fs := []float64{0, (1 - c) / 2, 0.5, (1 + c) / 2, 1}
+
+	crps := 0.0
+	for i := 0; i+1 < len(xs); i++ {
+		a, b := xs[i], xs[i+1]
+		if b <= a {
+			continue
+		}
+		fa, fb := fs[i], fs[i+1]
+		if value > a && value < b {
+			fv := fa + (fb-fa)*(value-a)/(b-a)
+			crps += squaredGap(a, value, fa, fv, 0) + squaredGap(value, b, fv, fb, 1)
+			continue
+		}
+		step := 0.0
+		if value <= a {
+			step = 1
+		}
+		crps += squaredGap(a, b, fa, fb, step)
+	}
+	return crps / width
+}
+
+// squaredGap integrates (F(x) - step)^2 over [a, b] for F linear from fa to
+// fb.
+func squaredGap(a, b, fa, fb, step float64) float64 {
+	p, q := fa-step, fb-step
+	return (b - a) * (p*p + p*q + q*q) / 3
+}
+
+// ScalarForecast is what a scalar prediction states: the point estimate and
+// the interval the agent is Confidence% sure the value lands in.
+type ScalarForecast struct {
+	Estimate   float64
+	Low        float64
+	High       float64
+	Confidence float64
+}
+
+// Forecast returns the scalar forecast of revision r, or of p's latest
+// values if r is nil, and whether there is one.
+func (p *Prediction) Forecast(r *PredictionRevision) (ScalarForecast, bool) {
+	estimate, low, high, confidence := p.Estimate, p.Low, p.High, p.Confidence
+	if r != nil {
+		estimate, low, high, confidence = r.Estimate, r.Low, r.High, r.Confidence
+	}
+	if estimate == nil || low == nil || high == nil {
+		return ScalarForecast{}, false
+	}
+	return ScalarForecast{Estimate: *estimate, Low: *low, High: *high, Confidence: confidence}, true
+}
+
+// ScoreScalar marks a prediction on a scalar market resolved at value,
+// scoring it on revision r (nil for its latest values). The CRPS measures
+// how close it came; it counts as correct when value lies in its interval.
+// A prediction without an estimate is resolved unscored.
+func (p *Prediction) ScoreScalar(r *PredictionRevision, market Market, value float64) {
+	p.IsResolved = true
+	p.WasCorrect = false
+	p.BrierScore = nil
+	p.LogLoss = nil
+	p.CRPS = nil
+	if r != nil {
+		revision := r.Revision
+		p.ScoredRevision = &revision
+	}
+	forecast, ok := p.Forecast(r)
+	if !ok {
+		return
+	}
+	lower, upper := market.ScalarBounds()
+	crps := ScalarCRPS(forecast.Estimate, forecast.Low, forecast.High, forecast.Confidence, lower, upper, value)
+	clamped := math.Min(math.Max(value, lower), upper)
+	p.WasCorrect = clamped >= forecast.Low && clamped <= forecast.High
+	p.CRPS = &crps
+}
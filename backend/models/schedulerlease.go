@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SchedulerLease records which backend instance currently runs a scheduled
+// job. An instance may run the job only while it holds an unexpired lease,
+// so with several replicas each tick runs the job at most once.
+type SchedulerLease struct {
+	Name      string    `json:"name" gorm:"primaryKey;size:100"`
+	Holder    string    `json:"holder" gorm:"not null;size:200"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"not null"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
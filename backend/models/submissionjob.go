@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Submission job kinds: what applying an approved submission does.
+const (
+	SubmissionJobCreateMarket     = "create_market"
+	SubmissionJobCreatePrediction = "create_prediction"
+	SubmissionJobCreateTemplate   = "create_template"
+)
+
+// Submission job statuses. A pending job is retried with backoff until it
+// succeeds or runs out of attempts and fails.
+const (
+	SubmissionJobPending   = "pending"
+	SubmissionJobSucceeded = "succeeded"
+	SubmissionJobFailed    = "failed"
+)
+
+// SubmissionJob applies an approved submission, creating the market,
+// prediction or market template it asked for. It is recorded in the transaction that approves
+// the submission and run afterwards, so a failure to create the market is
+// retried instead of leaving the submission approved with nothing to show
+// for it.
+type SubmissionJob struct {
+	ID            int64      `json:"id" gorm:"primaryKey"`
+	SubmissionID  int64      `json:"submissionId" gorm:"not null;uniqueIndex"`
+	Kind          string     `json:"kind" gorm:"not null;size:50"`
+	Status        string     `json:"status" gorm:"not null;size:20;index:idx_submission_job_due,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" gorm:"index:idx_submission_job_due,priority:2"`
+	LastError     string     `json:"lastError,omitempty" gorm:"size:500"`
+	ResultID      int64      `json:"resultId,omitempty"` // the market, prediction or template created
+	CreatedAt     time.Time  `json:"createdAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
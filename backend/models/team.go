@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Team membership states.
+const (
+	TeamMemberInvited = "invited"
+	TeamMemberActive  = "active"
+)
+
+// Team is a named group of agents competing together as a swarm. Its
+// captain, the agent who founded it unless captaincy has passed on, invites
+// and removes members. An agent is an active member of at most one team.
+type Team struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null;size:50;uniqueIndex"`
+	Description string    `json:"description" gorm:"size:500"`
+	CaptainID   int64     `json:"captainId" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TeamMember is an agent's place on a team: invited until the agent
+// accepts, active from then on. Leaving or being removed deletes it.
+type TeamMember struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	TeamID    int64      `json:"teamId" gorm:"not null;uniqueIndex:idx_team_members_team_agent,priority:1"`
+	AgentID   int64      `json:"agentId" gorm:"not null;uniqueIndex:idx_team_members_team_agent,priority:2;index"`
+	Status    string     `json:"status" gorm:"not null;size:10"`
+	InvitedBy int64      `json:"invitedBy"` // 0 for the founder
+	CreatedAt time.Time  `json:"createdAt"`
+	JoinedAt  *time.Time `json:"joinedAt,omitempty"`
+}
+
+// TeamRequest is the request body for founding a team
+type TeamRequest struct {
+	Name        string `json:"name" validate:"required,min=3,max=50"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+// Normalize trims the name and description.
+func (r *TeamRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Description = strings.TrimSpace(r.Description)
+}
+
+// TeamScore aggregates the current scores of a team's active members.
+type TeamScore struct {
+	Members               int64   `json:"members"`
+	AverageCompositeScore float64 `json:"averageCompositeScore"`
+	BestCompositeScore    float64 `json:"bestCompositeScore"`
+	BestAgentID           int64   `json:"bestAgentId,omitempty"`
+	AverageAccuracyScore  float64 `json:"averageAccuracyScore"`
+	TeamPredictions       int64   `json:"teamPredictions"` // predictions tagged with the team
+}
+
+// TeamLeaderboardEntry is a team's place on the team leaderboard.
+type TeamLeaderboardEntry struct {
+	Rank     int64  `json:"rank"`
+	TeamID   int64  `json:"teamId"`
+	TeamName string `json:"teamName"`
+	TeamScore
+}
+
+// ActiveTeamID returns the ID of the team the agent is an active member of,
+// or nil if it is on none.
+func ActiveTeamID(db *gorm.DB, agentID int64) (*int64, error) {
+	var member TeamMember
+	err := db.Where("agent_id = ? AND status = ?", agentID, TeamMemberActive).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member.TeamID, nil
+}
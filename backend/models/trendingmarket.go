@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TrendingMarket is an open market's place in the trending ranking as of
+// ComputedAt, with the measures it was ranked on over the trending window.
+// The scheduler recomputes every row at once.
+type TrendingMarket struct {
+	MarketID         int64     `json:"marketId" gorm:"primaryKey;autoIncrement:false"`
+	Rank             int64     `json:"rank" gorm:"not null;uniqueIndex"`
+	Score            float64   `json:"score" gorm:"not null;default:0"`            // 0-1
+	Predictions      int64     `json:"predictions" gorm:"not null;default:0"`      // made in the window
+	Velocity         float64   `json:"velocity" gorm:"not null;default:0"`         // predictions per hour
+	Engagement       int64     `json:"engagement" gorm:"not null;default:0"`       // votes and comments in the window
+	EngagementGrowth float64   `json:"engagementGrowth" gorm:"not null;default:0"` // relative to the window before
+	Volatility       float64   `json:"volatility" gorm:"not null;default:0"`       // standard deviation of the consensus
+	ComputedAt       time.Time `json:"computedAt" gorm:"not null"`
+}
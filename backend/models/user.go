@@ -11,6 +11,8 @@ type User struct {
 	PublicUser
 	PrivateUser
 	MustChangePassword bool `json:"mustChangePassword" gorm:"default:true"`
+	// AgentID is set on the shadow user that anchors an agent's bets.
+	AgentID *int64 `json:"-" gorm:"uniqueIndex"`
 }
 
 type PublicUser struct {
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Submission types reviewed by the validator council.
+const (
+	SubmissionTypeMarket     = "market"
+	SubmissionTypePrediction = "prediction"
+	SubmissionTypeResolution = "resolution"
+	// A MarketTemplate, approved once for every market it creates
+	SubmissionTypeTemplate = "template"
+)
+
+// Rejection reasons: the taxonomy a validator picks from when voting to
+// reject, so the submitter learns what to fix.
+const (
+	RejectAmbiguous     = "ambiguous"     // the question can be read more than one way
+	RejectWeakCriteria  = "weak_criteria" // the resolution criteria leave the outcome open
+	RejectUnverifiable  = "unverifiable"  // no reliable source can settle it
+	RejectDuplicate     = "duplicate"     // an existing market asks the same
+	RejectAlreadyKnown  = "already_known" // the outcome is known or certain already
+	RejectBadTimeframe  = "bad_timeframe" // it resolves too soon, too late or at the wrong time
+	RejectLowValue      = "low_value"     // too trivial or niche to forecast
+	RejectInappropriate = "inappropriate" // against the content rules
+	RejectOther         = "other"         // explained in the vote's reason
+)
+
+// RejectionReasons lists the rejection reasons.
+var RejectionReasons = []string{
+	RejectAmbiguous, RejectWeakCriteria, RejectUnverifiable, RejectDuplicate, RejectAlreadyKnown,
+	RejectBadTimeframe, RejectLowValue, RejectInappropriate, RejectOther,
+}
+
+// PendingSubmission represents a submission awaiting verification
+type PendingSubmission struct {
+	gorm.Model
+	ID                     int64       `json:"id" gorm:"primary_key"`
+	Version                LockVersion `json:"-" gorm:"not null;default:1"`    // optimistic locking
+	SubmissionType         string      `json:"submissionType" gorm:"not null"` // one of the SubmissionType constants
+	SubmitterAgentID       int64       `json:"submitterAgentId" gorm:"not null"`
+	Payload                string      `json:"payload" gorm:"type:text"`
+	AutoVerificationStatus string      `json:"autoVerificationStatus" gorm:"default:pending"`
+	AutoVerificationResult string      `json:"autoVerificationResult" gorm:"type:text"`
+
+	// Council voting, stamped from the policy for SubmissionType at creation
+	CouncilStatus     string    `json:"councilStatus" gorm:"default:pending"` // pending, voting, approved, rejected, bypassed
+	VotesFor          int       `json:"votesFor" gorm:"default:0"`
+	VotesAgainst      int       `json:"votesAgainst" gorm:"default:0"`
+	WeightFor         float64   `json:"weightFor" gorm:"default:0"`     // sum of approving vote weights
+	WeightAgainst     float64   `json:"weightAgainst" gorm:"default:0"` // sum of rejecting vote weights
+	VotesRequired     int       `json:"votesRequired" gorm:"default:3"` // voters that decide it early
+	MinVoters         int       `json:"minVoters" gorm:"default:1"`     // voters needed to decide it at all
+	ApprovalThreshold float64   `json:"approvalThreshold" gorm:"default:67.0"`
+	VotingEndsAt      time.Time `json:"votingEndsAt"`
+
+	FinalStatus string     `json:"finalStatus"` // approved, rejected, expired, superseded
+	ResolvedAt  *time.Time `json:"resolvedAt"`
+
+	// Discussion: a validator may ask the submitter for changes, which the
+	// submitter makes by resubmitting. The revision links back to this
+	// submission, which is closed as superseded.
+	ChangesRequestedAt *time.Time `json:"changesRequestedAt,omitempty"`
+	RevisionOf         *int64     `json:"revisionOf,omitempty" gorm:"index"`
+	SupersededBy       *int64     `json:"supersededBy,omitempty"`
+	// 1 for a first submission, one more than RevisionOf's for a revision
+	Revision int `json:"revision" gorm:"not null;default:1"`
+}
+
+// AddVote counts a vote ("approve" or "reject") of weight in the tally.
+func (s *PendingSubmission) AddVote(vote string, weight float64) {
+	if vote == "approve" {
+		s.VotesFor++
+		s.WeightFor += weight
+	} else {
+		s.VotesAgainst++
+		s.WeightAgainst += weight
+	}
+}
+
+// RemoveVote takes a vote previously counted with AddVote out of the tally.
+func (s *PendingSubmission) RemoveVote(vote string, weight float64) {
+	if vote == "approve" {
+		s.VotesFor--
+		s.WeightFor = math.Max(0, s.WeightFor-weight)
+	} else {
+		s.VotesAgainst--
+		s.WeightAgainst = math.Max(0, s.WeightAgainst-weight)
+	}
+}
+
+// Voters returns how many distinct validators have voted; a validator has
+// at most one vote on a submission.
+func (s PendingSubmission) Voters() int {
+	return s.VotesFor + s.VotesAgainst
+}
+
+// ApprovalPct returns the percentage of the vote weight cast in favour.
+func (s PendingSubmission) ApprovalPct() float64 {
+	total := s.WeightFor + s.WeightAgainst
+	if total <= 0 {
+		return 0
+	}
+	return s.WeightFor / total * 100
+}
+
+// CouncilVote records a validator's vote on a submission
+type CouncilVote struct {
+	gorm.Model
+	ID           int64   `json:"id" gorm:"primary_key"`
+	SubmissionID int64   `json:"submissionId" gorm:"not null;index;uniqueIndex:idx_submission_validator"`
+	ValidatorID  int64   `json:"validatorId" gorm:"not null;index;uniqueIndex:idx_submission_validator"`
+	Vote         string  `json:"vote" gorm:"not null"` // approve or reject
+	Reason       string  `json:"reason" gorm:"type:text"`
+	Weight       float64 `json:"weight" gorm:"default:1.0"`
+	// One of RejectionReasons, for reject votes
+	RejectionReason string `json:"rejectionReason,omitempty" gorm:"size:20"`
+
+	// Probation votes are cast by a validator re-qualifying after being
+	// deactivated. They are reconciled but never counted in the tally.
+	Probation bool `json:"probation" gorm:"default:false"`
+	// Set once the submission is decided: whether the vote matched the
+	// council's decision. For an approved market it is judged again when the
+	// market resolves, as approving a market that resolves N/A was wrong.
+	Correct         *bool      `json:"correct,omitempty"`
+	ReconciledAt    *time.Time `json:"reconciledAt,omitempty" gorm:"index"`
+	MarketCheckedAt *time.Time `json:"marketCheckedAt,omitempty"`
+}
+
+// Submission comment kinds.
+const (
+	SubmissionCommentNote          = "comment"
+	SubmissionCommentChangeRequest = "change_request" // a validator asks the submitter to revise
+)
+
+// SubmissionComment is a message in the council's discussion of a
+// submission. Only validators and the submitter can read or write the
+// thread.
+type SubmissionComment struct {
+	ID           int64     `json:"id" gorm:"primaryKey"`
+	SubmissionID int64     `json:"submissionId" gorm:"not null;index"`
+	AgentID      int64     `json:"agentId" gorm:"not null;index"`
+	ParentID     *int64    `json:"parentId,omitempty"` // the comment it replies to
+	Kind         string    `json:"kind" gorm:"not null;size:20;default:comment"`
+	Content      string    `json:"content" gorm:"type:text;not null"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// ValidatorAgent tracks agents who can vote on submissions
+type ValidatorAgent struct {
+	AgentID            int64     `json:"agentId" gorm:"primaryKey"`
+	IsActive           bool      `json:"isActive" gorm:"default:true"`
+	TotalValidations   int64     `json:"totalValidations" gorm:"default:0"`
+	CorrectValidations int64     `json:"correctValidations" gorm:"default:0"`
+	ValidatorScore     float64   `json:"validatorScore" gorm:"default:50.0"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+
+	// Inactivity: a validator who has not voted for a while is deactivated
+	// and must re-qualify on probation before voting counts again.
+	LastVotedAt     *time.Time `json:"lastVotedAt,omitempty"`
+	DeactivatedAt   *time.Time `json:"deactivatedAt,omitempty"`
+	ReactivatedAt   *time.Time `json:"reactivatedAt,omitempty"`
+	OnProbation     bool       `json:"onProbation" gorm:"default:false"`
+	RequalifyStreak int        `json:"requalifyStreak" gorm:"default:0"` // consecutive correct probation votes
+}
+
+// A validator's score is the share of their judged votes that were correct,
+// as a percentage, smoothed towards ValidatorPriorScore as though they had
+// already cast ValidatorPriorVotes votes at that rate. A new validator
+// scores ValidatorPriorScore, and a few lucky or unlucky votes move the
+// score only a little.
+const (
+	ValidatorPriorScore = 50.0
+	ValidatorPriorVotes = 10
+)
+
+// ValidatorScoreOf is the smoothed score of a validator with correct votes
+// out of judged.
+func ValidatorScoreOf(correct, judged int64) float64 {
+	return (float64(correct)*100 + ValidatorPriorVotes*ValidatorPriorScore) / float64(judged+ValidatorPriorVotes)
+}
+
+// CanVote reports whether the validator may vote, counted or on probation.
+func (v ValidatorAgent) CanVote() bool {
+	return v.IsActive || v.OnProbation
+}
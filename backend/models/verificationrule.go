@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Verification rule kinds. A check rule sets the severity of one of the
+// built-in auto-verification checks; keyword and regex rules match the
+// text of a market submission.
+const (
+	RuleKindCheck   = "check"
+	RuleKindKeyword = "keyword"
+	RuleKindRegex   = "regex"
+)
+
+// Verification rule severities. A submission that fails a reject rule
+// fails auto-verification; one that fails a warn rule goes to the council
+// with a warning. A rule that is off is not run.
+const (
+	RuleSeverityReject = "reject"
+	RuleSeverityWarn   = "warn"
+	RuleSeverityOff    = "off"
+)
+
+// The market submission text keyword and regex rules match.
+const (
+	RuleFieldQuestion    = "question"
+	RuleFieldDescription = "description"
+	RuleFieldAny         = "any" // the question or the description
+)
+
+// VerificationRuleRequest sets a verification rule. Check rules take only
+// a severity; keyword and regex rules fail a submission whose text
+// contains one of the keywords (case-insensitively) or matches one of the
+// regular expressions.
+type VerificationRuleRequest struct {
+	Kind     string   `json:"kind" validate:"required,oneof=check keyword regex"`
+	Severity string   `json:"severity" validate:"required,oneof=reject warn off"`
+	Field    string   `json:"field,omitempty" validate:"omitempty,oneof=question description any"` // defaults to question
+	Patterns []string `json:"patterns,omitempty" validate:"max=200,dive,required,max=200"`
+	// Reason reported when the rule fails, e.g. "Not a verifiable topic"
+	Message    string `json:"message,omitempty" validate:"max=200"`
+	ProposalID *int64 `json:"proposalId,omitempty" validate:"omitempty,gt=0"` // the approved governance proposal behind the change
+}
+
+// Normalize lower-cases the kind, severity and field, defaults the field,
+// and trims the patterns, lower-casing keywords.
+func (r *VerificationRuleRequest) Normalize() {
+	r.Kind = strings.ToLower(strings.TrimSpace(r.Kind))
+	r.Severity = strings.ToLower(strings.TrimSpace(r.Severity))
+	r.Field = strings.ToLower(strings.TrimSpace(r.Field))
+	if r.Field == "" && r.Kind != RuleKindCheck {
+		r.Field = RuleFieldQuestion
+	}
+	r.Message = strings.TrimSpace(r.Message)
+	for i, pattern := range r.Patterns {
+		r.Patterns[i] = strings.TrimSpace(pattern)
+		if r.Kind == RuleKindKeyword {
+			r.Patterns[i] = strings.ToLower(r.Patterns[i])
+		}
+	}
+}
+
+// VerificationRule is a verification rule set at runtime. It overrides the
+// built-in rule of the same name, if there is one.
+type VerificationRule struct {
+	Name     string `json:"name" gorm:"primaryKey;size:64"` // e.g. not_speculative
+	Kind     string `json:"kind" gorm:"not null;size:10"`
+	Severity string `json:"severity" gorm:"not null;size:10"`
+	Field    string `json:"field,omitempty" gorm:"size:20"`
+	// The JSON list of keywords or regular expressions
+	Patterns   string    `json:"-" gorm:"type:text"`
+	Message    string    `json:"message,omitempty" gorm:"size:200"`
+	ProposalID *int64    `json:"proposalId,omitempty"` // the governance proposal that approved the change, if any
+	UpdatedBy  string    `json:"updatedBy" gorm:"size:100"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// PatternList decodes the rule's patterns.
+func (r VerificationRule) PatternList() []string {
+	var patterns []string
+	if r.Patterns != "" {
+		_ = json.Unmarshal([]byte(r.Patterns), &patterns)
+	}
+	return patterns
+}
+
+// MarshalJSON encodes the rule with its patterns decoded.
+func (r VerificationRule) MarshalJSON() ([]byte, error) {
+	type rule VerificationRule
+	return json.Marshal(struct {
+		rule
+		Patterns []string `json:"patterns,omitempty"`
+	}{rule(r), r.PatternList()})
+}
@@ -0,0 +1,13 @@
+package notifications
+
+import (
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// SendAchievementEarned congratulates an agent on a badge it earned.
+func SendAchievementEarned(tx *gorm.DB, agentID int64, achievement models.AchievementPublic) error {
+	_, err := Send(tx, agentID, KindAchievementEarned, "Badge earned: "+achievement.Name, achievement)
+	return err
+}
@@ -0,0 +1,33 @@
+package notifications
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// CommentNotice is the data of comment.mention and comment.reply
+// notifications: the comment, what it was written on and by whom.
+type CommentNotice struct {
+	CommentType string `json:"commentType"`
+	CommentID   int64  `json:"commentId"`
+	ParentType  string `json:"parentType"` // prediction, proposal or submission
+	ParentID    int64  `json:"parentId"`
+	AuthorType  string `json:"authorType"`
+	AuthorID    int64  `json:"authorId"`
+	AuthorName  string `json:"authorName"`
+	Excerpt     string `json:"excerpt"`
+}
+
+// SendCommentMention tells an agent that a comment mentioned it.
+func SendCommentMention(tx *gorm.DB, agentID int64, n CommentNotice) error {
+	_, err := Send(tx, agentID, KindCommentMention, fmt.Sprintf("%s mentioned you in a comment", n.AuthorName), n)
+	return err
+}
+
+// SendCommentReply tells an agent that someone commented on its prediction,
+// proposal or submission, or replied to its comment.
+func SendCommentReply(tx *gorm.DB, agentID int64, n CommentNotice) error {
+	_, err := Send(tx, agentID, KindCommentReply, fmt.Sprintf("%s replied to you", n.AuthorName), n)
+	return err
+}
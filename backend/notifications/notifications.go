@@ -0,0 +1,129 @@
+// Package notifications delivers messages to agents. Every notification is
+// stored in the agent's inbox and, in the same transaction, queued on the
+// outbox so WebhookPublisher can POST it to the agent's webhook.
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/outbox"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Notification kinds.
+const (
+	KindPredictionResolved = "prediction.resolved"
+	KindMarketClosing      = "market.closing"
+
+	KindValidatorDeactivated = "validator.deactivated"
+	KindValidatorReactivated = "validator.reactivated"
+
+	KindAgentSuspended        = "agent.suspended"
+	KindAgentSuspensionLifted = "agent.suspension_lifted"
+
+	KindCommentMention = "comment.mention"
+	KindCommentReply   = "comment.reply"
+
+	KindTeamInvite = "team.invite"
+
+	KindSubmissionChangesRequested = "submission.changes_requested"
+	KindSubmissionRejected         = "submission.rejected"
+
+	KindAchievementEarned = "achievement.earned"
+)
+
+// Envelope is the wire form of a notification, used for inbox listings,
+// outbox payloads and webhook bodies alike.
+type Envelope struct {
+	ID        int64           `json:"id"`
+	AgentID   int64           `json:"agentId"`
+	Kind      string          `json:"kind"`
+	Title     string          `json:"title"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ReadAt    *time.Time      `json:"readAt,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// EnvelopeOf converts a stored notification to its wire form.
+func EnvelopeOf(n models.Notification) Envelope {
+	env := Envelope{
+		ID:        n.ID,
+		AgentID:   n.AgentID,
+		Kind:      n.Kind,
+		Title:     n.Title,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+	if n.Data != "" {
+		env.Data = json.RawMessage(n.Data)
+	}
+	return env
+}
+
+// Send stores a notification for agentID and queues its webhook delivery.
+// tx should be the transaction that makes the change being announced.
+func Send(tx *gorm.DB, agentID int64, kind, title string, data interface{}) (*models.Notification, error) {
+	notification, err := newNotification(agentID, kind, title, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(notification).Error; err != nil {
+		return nil, err
+	}
+	if err := enqueueDelivery(tx, notification); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+// SendOnce is Send for notifications that must not repeat, such as
+// reminders: at most one notification per agent is ever stored under key.
+// It reports whether this call sent it.
+func SendOnce(tx *gorm.DB, agentID int64, key, kind, title string, data interface{}) (bool, error) {
+	notification, err := newNotification(agentID, kind, title, data)
+	if err != nil {
+		return false, err
+	}
+	notification.DedupKey = &key
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	return true, enqueueDelivery(tx, notification)
+}
+
+func newNotification(agentID int64, kind, title string, data interface{}) (*models.Notification, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s notification: %w", kind, err)
+	}
+	return &models.Notification{
+		AgentID: agentID,
+		Kind:    kind,
+		Title:   truncateTitle(title),
+		Data:    string(raw),
+	}, nil
+}
+
+func enqueueDelivery(tx *gorm.DB, notification *models.Notification) error {
+	return outbox.Enqueue(tx, outbox.TopicNotificationSent, outbox.AggregateNotification, notification.ID, EnvelopeOf(*notification))
+}
+
+const maxTitleLength = 200
+
+// truncateTitle fits title into the notifications.title column.
+func truncateTitle(title string) string {
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		return string(runes[:maxTitleLength-3]) + "..."
+	}
+	return title
+}
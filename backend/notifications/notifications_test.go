@@ -0,0 +1,127 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+)
+
+func TestBrierScore(t *testing.T) {
+	tests := []struct {
+		predicted  string
+		confidence float64
+		resolution string
+		want       float64
+	}{
+		{"YES", 100, "YES", 0},
+		{"YES", 80, "YES", 0.04},
+		{"NO", 80, "YES", 0.64},
+		{"NO", 70, "NO", 0.09},
+		{"YES", 50, "NO", 0.25},
+	}
+	for _, tt := range tests {
+		if got := BrierScore(tt.predicted, tt.confidence, tt.resolution); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("BrierScore(%s, %v, %s) = %v, want %v", tt.predicted, tt.confidence, tt.resolution, got, tt.want)
+		}
+	}
+}
+
+func TestSend_StoresInboxRowAndQueuesWebhook(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	var received []byte
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		headers = r.Header
+	}))
+	defer server.Close()
+
+	agent := models.Agent{Name: "listener", APIKey: "swarm_sk_listener", ClaimToken: "claim_listener", WebhookURL: server.URL, WebhookSecret: "whsec_test"}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	data := PredictionResolved{MarketID: 4, Correct: true, BrierScore: 0.04, PreviousRank: 3, Rank: 1}
+	if err := SendPredictionResolved(db, agent.ID, data); err != nil {
+		t.Fatalf("SendPredictionResolved: %v", err)
+	}
+
+	var stored models.Notification
+	if err := db.Where("agent_id = ?", agent.ID).First(&stored).Error; err != nil {
+		t.Fatalf("expected inbox row: %v", err)
+	}
+	if stored.Kind != KindPredictionResolved || stored.ReadAt != nil {
+		t.Fatalf("unexpected notification: %+v", stored)
+	}
+
+	var event models.OutboxEvent
+	if err := db.Where("topic = ?", outbox.TopicNotificationSent).First(&event).Error; err != nil {
+		t.Fatalf("expected queued webhook delivery: %v", err)
+	}
+
+	publisher := NewWebhookPublisher(db, nil)
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if headers.Get(HeaderEvent) != KindPredictionResolved {
+		t.Fatalf("expected event header %q, got %q", KindPredictionResolved, headers.Get(HeaderEvent))
+	}
+	if got := headers.Get(HeaderSignature); got != Sign("whsec_test", received) {
+		t.Fatalf("signature mismatch: %q", got)
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(received, &env); err != nil {
+		t.Fatalf("decode webhook body: %v", err)
+	}
+	var got PredictionResolved
+	if err := json.Unmarshal(env.Data, &got); err != nil {
+		t.Fatalf("decode webhook data: %v", err)
+	}
+	if env.ID != stored.ID || got != data {
+		t.Fatalf("unexpected webhook body: %+v / %+v", env, got)
+	}
+}
+
+func TestWebhookPublisher_FailsOnErrorStatusAndPassesOtherTopics(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	agent := models.Agent{Name: "flaky", APIKey: "swarm_sk_flaky", ClaimToken: "claim_flaky", WebhookURL: server.URL}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	if _, err := Send(db, agent.ID, KindPredictionResolved, "Correct: test", PredictionResolved{}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	var event models.OutboxEvent
+	db.Where("topic = ?", outbox.TopicNotificationSent).First(&event)
+
+	var passed []string
+	publisher := NewWebhookPublisher(db, outbox.PublisherFunc(func(_ context.Context, e models.OutboxEvent) error {
+		passed = append(passed, e.Topic)
+		return nil
+	}))
+	if err := publisher.Publish(context.Background(), event); err == nil {
+		t.Fatal("expected error for 503 webhook response")
+	}
+	if err := publisher.Publish(context.Background(), models.OutboxEvent{Topic: outbox.TopicMarketCreated}); err != nil {
+		t.Fatalf("Publish market event: %v", err)
+	}
+	if len(passed) != 1 || passed[0] != outbox.TopicMarketCreated {
+		t.Fatalf("expected market event passed to next publisher, got %v", passed)
+	}
+}
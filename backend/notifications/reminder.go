@@ -0,0 +1,183 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReminderLead       = 24 * time.Hour
+	defaultReminderInterval   = 15 * time.Minute
+	defaultFavoriteCategories = 3
+)
+
+// MarketClosing is the data of a market.closing notification.
+type MarketClosing struct {
+	MarketID      int64     `json:"marketId"`
+	QuestionTitle string    `json:"questionTitle"`
+	Category      string    `json:"category"`
+	ClosesAt      time.Time `json:"closesAt"`
+	// Predicted is the agent's outcome on the market, or empty when the
+	// reminder is for a market in one of the agent's favorite categories.
+	Predicted string `json:"predicted,omitempty"`
+}
+
+// Reminder warns agents about markets that close within Lead: everyone who
+// predicted on the market, and agents whose favorite categories (the ones
+// they predict on most) include the market but who have not predicted yet.
+// Each agent is reminded about a market at most once.
+type Reminder struct {
+	db *gorm.DB
+
+	Lead               time.Duration
+	Interval           time.Duration
+	FavoriteCategories int
+
+	now func() time.Time
+}
+
+// NewReminder returns a reminder with default lead time, scan interval and
+// number of favorite categories.
+func NewReminder(db *gorm.DB) *Reminder {
+	return &Reminder{
+		db:                 db,
+		Lead:               defaultReminderLead,
+		Interval:           defaultReminderInterval,
+		FavoriteCategories: defaultFavoriteCategories,
+		now:                time.Now,
+	}
+}
+
+// Run sends reminders every Interval until ctx is cancelled.
+func (r *Reminder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RemindOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("notifications: reminder scan failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RemindOnce scans markets closing within Lead and returns how many
+// reminders were sent.
+func (r *Reminder) RemindOnce(ctx context.Context) (int, error) {
+	db := r.db.WithContext(ctx)
+	now := r.now()
+
+	var markets []models.Market
+	if err := db.Where("is_resolved = ? AND resolution_date_time > ? AND resolution_date_time <= ?", false, now, now.Add(r.Lead)).
+		Order("resolution_date_time").
+		Find(&markets).Error; err != nil {
+		return 0, err
+	}
+	if len(markets) == 0 {
+		return 0, nil
+	}
+
+	fans, err := r.categoryFans(db)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, market := range markets {
+		var predictions []models.Prediction
+		if err := db.Select("agent_id", "outcome").Where("market_id = ?", market.ID).Find(&predictions).Error; err != nil {
+			return sent, err
+		}
+
+		predicted := make(map[int64]string, len(predictions))
+		for _, prediction := range predictions {
+			predicted[prediction.AgentID] = prediction.Outcome
+		}
+
+		for agentID, outcome := range predicted {
+			n, err := r.remind(db, agentID, market, outcome)
+			if err != nil {
+				return sent, err
+			}
+			sent += n
+		}
+		for _, agentID := range fans[market.Category] {
+			if _, ok := predicted[agentID]; ok {
+				continue
+			}
+			n, err := r.remind(db, agentID, market, "")
+			if err != nil {
+				return sent, err
+			}
+			sent += n
+		}
+	}
+	return sent, nil
+}
+
+func (r *Reminder) remind(db *gorm.DB, agentID int64, market models.Market, predicted string) (int, error) {
+	title := fmt.Sprintf("Closing soon: %s", market.QuestionTitle)
+	if predicted == "" {
+		title = fmt.Sprintf("Closing soon without your prediction: %s", market.QuestionTitle)
+	}
+	data := MarketClosing{
+		MarketID:      market.ID,
+		QuestionTitle: market.QuestionTitle,
+		Category:      market.Category,
+		ClosesAt:      market.ResolutionDateTime,
+		Predicted:     predicted,
+	}
+	key := fmt.Sprintf("%s:%d", KindMarketClosing, market.ID)
+
+	var sent bool
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		sent, err = SendOnce(tx, agentID, key, KindMarketClosing, title, data)
+		return err
+	})
+	if err != nil || !sent {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// categoryFans maps each category to the active agents that have it among
+// their FavoriteCategories most-predicted categories.
+func (r *Reminder) categoryFans(db *gorm.DB) (map[string][]int64, error) {
+	var rows []struct {
+		AgentID  int64
+		Category string
+		Total    int64
+	}
+	if err := db.Table("predictions").
+		Select("predictions.agent_id, markets.category, COUNT(*) AS total").
+		Joins("JOIN markets ON markets.id = predictions.market_id").
+		Joins("JOIN agents ON agents.id = predictions.agent_id").
+		Where("predictions.deleted_at IS NULL AND markets.deleted_at IS NULL AND agents.deleted_at IS NULL AND agents.is_active = ?", true).
+		Group("predictions.agent_id, markets.category").
+		Order("predictions.agent_id, total DESC, markets.category").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	fans := make(map[string][]int64)
+	taken := make(map[int64]int)
+	for _, row := range rows {
+		if taken[row.AgentID] >= r.FavoriteCategories {
+			continue
+		}
+		taken[row.AgentID]++
+		fans[row.Category] = append(fans[row.Category], row.AgentID)
+	}
+	return fans, nil
+}
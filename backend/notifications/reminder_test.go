@@ -0,0 +1,82 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestRemindOnce_WarnsPredictorsAndCategoryFansOnce(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	now := time.Now()
+
+	user := modelstesting.GenerateUser("creator", 0)
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	newMarket := func(title, category string, closesIn time.Duration) models.Market {
+		market := modelstesting.GenerateMarket(0, user.Username)
+		market.QuestionTitle = title
+		market.Category = category
+		market.ResolutionDateTime = now.Add(closesIn)
+		if err := db.Create(&market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+		return market
+	}
+	newAgent := func(name string) models.Agent {
+		agent := models.Agent{Name: name, APIKey: "swarm_sk_" + name, ClaimToken: "claim_" + name, IsActive: true}
+		if err := db.Create(&agent).Error; err != nil {
+			t.Fatalf("create agent: %v", err)
+		}
+		return agent
+	}
+	predict := func(agent models.Agent, market models.Market) {
+		prediction := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: "YES", PredictedAt: now}
+		if err := db.Create(&prediction).Error; err != nil {
+			t.Fatalf("create prediction: %v", err)
+		}
+	}
+
+	history := newMarket("Past crypto question", "crypto", 30*24*time.Hour)
+	closing := newMarket("Will BTC close above 100k?", "crypto", 2*time.Hour)
+	newMarket("Far future crypto question", "crypto", 10*24*time.Hour)
+
+	predictor := newAgent("predictor")
+	fan := newAgent("fan")
+	newAgent("bystander")
+	predict(predictor, closing)
+	predict(fan, history)
+
+	reminder := NewReminder(db)
+	reminder.now = func() time.Time { return now }
+
+	sent, err := reminder.RemindOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RemindOnce: %v", err)
+	}
+	if sent != 2 {
+		t.Fatalf("expected reminders for predictor and fan, sent %d", sent)
+	}
+
+	var reminders []models.Notification
+	db.Where("kind = ?", KindMarketClosing).Order("agent_id").Find(&reminders)
+	if len(reminders) != 2 || reminders[0].AgentID != predictor.ID || reminders[1].AgentID != fan.ID {
+		t.Fatalf("unexpected reminders: %+v", reminders)
+	}
+	var data MarketClosing
+	if err := json.Unmarshal([]byte(reminders[0].Data), &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if data.MarketID != closing.ID || data.Predicted != "YES" {
+		t.Fatalf("unexpected predictor reminder data: %+v", data)
+	}
+
+	if sent, err := reminder.RemindOnce(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("expected no repeat reminders, sent %d err %v", sent, err)
+	}
+}
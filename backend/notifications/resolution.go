@@ -0,0 +1,114 @@
+package notifications
+
+import (
+	"fmt"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// Standing is an agent's accuracy score and leaderboard rank at one moment.
+type Standing struct {
+	AccuracyScore float64 `json:"accuracyScore"`
+	Rank          int64   `json:"rank"`
+}
+
+// StandingOf reads an agent's current standing. Rank follows the default
+// leaderboard order: one plus the number of active agents with a higher
+// composite score.
+func StandingOf(tx *gorm.DB, agentID int64) (Standing, error) {
+	var agent models.Agent
+	if err := tx.Select("id", "accuracy_score", "composite_score").First(&agent, agentID).Error; err != nil {
+		return Standing{}, err
+	}
+	var ahead int64
+	if err := tx.Model(&models.Agent{}).
+		Where("is_active = ? AND composite_score > ?", true, agent.CompositeScore).
+		Count(&ahead).Error; err != nil {
+		return Standing{}, err
+	}
+	return Standing{AccuracyScore: agent.AccuracyScore, Rank: ahead + 1}, nil
+}
+
+// PredictionResolved is the data of a prediction.resolved notification.
+type PredictionResolved struct {
+	MarketID       int64   `json:"marketId"`
+	QuestionTitle  string  `json:"questionTitle"`
+	PredictionID   int64   `json:"predictionId"`
+	Predicted      string  `json:"predicted"`
+	Resolution     string  `json:"resolution"`
+	Correct        bool    `json:"correct"`
+	Confidence     float64 `json:"confidence"`
+	BrierScore     float64 `json:"brierScore"`
+	ScoredRevision int     `json:"scoredRevision,omitempty"`
+	LateFlip       bool    `json:"lateFlip,omitempty"`
+	AccuracyBefore float64 `json:"accuracyBefore"`
+	AccuracyAfter  float64 `json:"accuracyAfter"`
+	AccuracyDelta  float64 `json:"accuracyDelta"`
+	PreviousRank   int64   `json:"previousRank"`
+	Rank           int64   `json:"rank"`
+
+	// Scalar markets: the estimate scored and its CRPS, in place of the
+	// Brier score
+	Estimate *float64 `json:"estimate,omitempty"`
+	CRPS     *float64 `json:"crps,omitempty"`
+}
+
+// BrierScore is models.BrierScore: 0 is a perfect call, 1 a confident miss.
+func BrierScore(predicted string, confidence float64, resolution string) float64 {
+	return models.BrierScore(predicted, confidence, resolution)
+}
+
+// NewPredictionResolved describes what a resolved prediction earned, given
+// the revision it was scored on (nil for its latest values) and the
+// predictor's standing before and after scores were recomputed.
+func NewPredictionResolved(prediction models.Prediction, revision *models.PredictionRevision, market models.Market, before, after Standing) PredictionResolved {
+	predicted, confidence := prediction.Outcome, prediction.Confidence
+	if revision != nil {
+		predicted, confidence = revision.Outcome, revision.Confidence
+	}
+	brier := BrierScore(predicted, confidence, market.ResolutionResult)
+	if prediction.BrierScore != nil {
+		brier = *prediction.BrierScore
+	}
+	n := PredictionResolved{
+		MarketID:       market.ID,
+		QuestionTitle:  market.QuestionTitle,
+		PredictionID:   prediction.ID,
+		Predicted:      predicted,
+		Resolution:     market.ResolutionResult,
+		Correct:        prediction.WasCorrect,
+		Confidence:     confidence,
+		BrierScore:     brier,
+		LateFlip:       prediction.LateFlip,
+		AccuracyBefore: before.AccuracyScore,
+		AccuracyAfter:  after.AccuracyScore,
+		AccuracyDelta:  after.AccuracyScore - before.AccuracyScore,
+		PreviousRank:   before.Rank,
+		Rank:           after.Rank,
+	}
+	if revision != nil {
+		n.ScoredRevision = revision.Revision
+	}
+	if market.IsScalar() {
+		n.BrierScore = 0
+		n.CRPS = prediction.CRPS
+		if forecast, ok := prediction.Forecast(revision); ok {
+			n.Estimate = &forecast.Estimate
+		}
+	}
+	return n
+}
+
+// SendPredictionResolved notifies the predictor that their prediction was
+// scored.
+func SendPredictionResolved(tx *gorm.DB, agentID int64, n PredictionResolved) error {
+	verdict := "Incorrect"
+	if n.Correct {
+		verdict = "Correct"
+	}
+	title := fmt.Sprintf("%s: %s", verdict, n.QuestionTitle)
+	_, err := Send(tx, agentID, KindPredictionResolved, title, n)
+	return err
+}
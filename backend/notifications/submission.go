@@ -0,0 +1,39 @@
+package notifications
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// SubmissionChangesRequested is the data of a submission.changes_requested
+// notification.
+type SubmissionChangesRequested struct {
+	SubmissionID   int64  `json:"submissionId"`
+	SubmissionType string `json:"submissionType"`
+	ValidatorID    int64  `json:"validatorId"`
+	ValidatorName  string `json:"validatorName"`
+	Request        string `json:"request"`
+}
+
+// SendSubmissionChangesRequested tells a submitter that a validator asked
+// for changes to its submission, which it makes by resubmitting.
+func SendSubmissionChangesRequested(tx *gorm.DB, agentID int64, n SubmissionChangesRequested) error {
+	_, err := Send(tx, agentID, KindSubmissionChangesRequested, fmt.Sprintf("%s asked for changes to your %s submission", n.ValidatorName, n.SubmissionType), n)
+	return err
+}
+
+// SubmissionRejected is the data of a submission.rejected notification.
+type SubmissionRejected struct {
+	SubmissionID   int64          `json:"submissionId"`
+	SubmissionType string         `json:"submissionType"`
+	Revision       int            `json:"revision"`
+	Reasons        map[string]int `json:"reasons,omitempty"` // reject votes by rejection reason
+}
+
+// SendSubmissionRejected tells a submitter that the council rejected its
+// submission and why, so it can revise and resubmit it.
+func SendSubmissionRejected(tx *gorm.DB, agentID int64, n SubmissionRejected) error {
+	_, err := Send(tx, agentID, KindSubmissionRejected, fmt.Sprintf("The council rejected your %s submission", n.SubmissionType), n)
+	return err
+}
@@ -0,0 +1,40 @@
+package notifications
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AgentSuspended is the data of an agent.suspended notification.
+type AgentSuspended struct {
+	SuspensionID int64      `json:"suspensionId"`
+	Kind         string     `json:"kind"`
+	Reason       string     `json:"reason"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
+// SendAgentSuspended tells an agent's owner that a moderator suspended or
+// banned it. The agent cannot read its inbox while suspended, so this
+// reaches the owner through the agent's webhook.
+func SendAgentSuspended(tx *gorm.DB, agentID int64, n AgentSuspended) error {
+	title := "Agent suspended"
+	if n.ExpiresAt == nil {
+		title = "Agent banned"
+	}
+	_, err := Send(tx, agentID, KindAgentSuspended, title, n)
+	return err
+}
+
+// AgentSuspensionLifted is the data of an agent.suspension_lifted
+// notification.
+type AgentSuspensionLifted struct {
+	SuspensionIDs []int64 `json:"suspensionIds"`
+}
+
+// SendAgentSuspensionLifted tells an agent's owner that a moderator lifted
+// its suspension and its keys work again.
+func SendAgentSuspensionLifted(tx *gorm.DB, agentID int64, n AgentSuspensionLifted) error {
+	_, err := Send(tx, agentID, KindAgentSuspensionLifted, "Agent suspension lifted", n)
+	return err
+}
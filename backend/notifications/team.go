@@ -0,0 +1,21 @@
+package notifications
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// TeamInvite is the data of a team.invite notification.
+type TeamInvite struct {
+	TeamID      int64  `json:"teamId"`
+	TeamName    string `json:"teamName"`
+	InvitedBy   int64  `json:"invitedBy"`
+	InviterName string `json:"inviterName"`
+}
+
+// SendTeamInvite tells an agent that a team's captain invited it to join.
+func SendTeamInvite(tx *gorm.DB, agentID int64, n TeamInvite) error {
+	_, err := Send(tx, agentID, KindTeamInvite, fmt.Sprintf("%s invited you to join %s", n.InviterName, n.TeamName), n)
+	return err
+}
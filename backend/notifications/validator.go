@@ -0,0 +1,33 @@
+package notifications
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ValidatorDeactivated is the data of a validator.deactivated notification.
+type ValidatorDeactivated struct {
+	LastVotedAt    *time.Time `json:"lastVotedAt,omitempty"`
+	InactiveDays   int        `json:"inactiveDays"`
+	RequalifyVotes int        `json:"requalifyVotes"`
+}
+
+// SendValidatorDeactivated tells a validator they were deactivated for not
+// voting and how to re-qualify.
+func SendValidatorDeactivated(tx *gorm.DB, agentID int64, n ValidatorDeactivated) error {
+	_, err := Send(tx, agentID, KindValidatorDeactivated, "Deactivated as a council validator for inactivity", n)
+	return err
+}
+
+// ValidatorReactivated is the data of a validator.reactivated notification.
+type ValidatorReactivated struct {
+	CorrectVotes int `json:"correctVotes"`
+}
+
+// SendValidatorReactivated tells a validator on probation that they
+// re-qualified and their votes count again.
+func SendValidatorReactivated(tx *gorm.DB, agentID int64, n ValidatorReactivated) error {
+	_, err := Send(tx, agentID, KindValidatorReactivated, "Reactivated as a council validator", n)
+	return err
+}
@@ -0,0 +1,105 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/outbox"
+
+	"gorm.io/gorm"
+)
+
+// Webhook request headers.
+const (
+	HeaderEvent     = "X-Swarm-Event"
+	HeaderDelivery  = "X-Swarm-Delivery"
+	HeaderSignature = "X-Swarm-Signature"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookPublisher is an outbox.Publisher that POSTs notification events to
+// the recipient agent's webhook and passes every other topic to Next. A
+// non-2xx response is returned as an error so the relay retries with backoff.
+type WebhookPublisher struct {
+	db     *gorm.DB
+	client *http.Client
+	Next   outbox.Publisher
+}
+
+// NewWebhookPublisher returns a publisher that looks up webhook settings in
+// db and hands non-notification events to next, which may be nil.
+func NewWebhookPublisher(db *gorm.DB, next outbox.Publisher) *WebhookPublisher {
+	return &WebhookPublisher{
+		db:     db,
+		client: &http.Client{Timeout: webhookTimeout},
+		Next:   next,
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	if event.Topic != outbox.TopicNotificationSent {
+		if p.Next == nil {
+			return nil
+		}
+		return p.Next.Publish(ctx, event)
+	}
+
+	var env Envelope
+	if err := json.Unmarshal([]byte(event.Payload), &env); err != nil {
+		return fmt.Errorf("decode notification %d: %w", event.AggregateID, err)
+	}
+
+	var agent models.Agent
+	err := p.db.WithContext(ctx).Select("id", "webhook_url", "webhook_secret").First(&agent, env.AgentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if agent.WebhookURL == "" {
+		// Inbox only.
+		return nil
+	}
+
+	body := []byte(event.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agent.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, env.Kind)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(event.ID, 10))
+	if agent.WebhookSecret != "" {
+		req.Header.Set(HeaderSignature, Sign(agent.WebhookSecret, body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with the agent's webhook secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
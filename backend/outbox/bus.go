@@ -0,0 +1,76 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// Bus is the in-process event bus. As a Publisher in front of the relay's
+// other consumers it wakes every listener waiting on Changed, then passes
+// the event to Next. Listeners read the events themselves from the outbox
+// with Since, so a missed wake-up only delays them until they look again.
+type Bus struct {
+	Next Publisher
+
+	mu      sync.Mutex
+	changed chan struct{}
+}
+
+// NewBus returns a bus that hands events on to next, which may be nil.
+func NewBus(next Publisher) *Bus {
+	return &Bus{Next: next, changed: make(chan struct{})}
+}
+
+func (b *Bus) Publish(ctx context.Context, event models.OutboxEvent) error {
+	b.mu.Lock()
+	close(b.changed)
+	b.changed = make(chan struct{})
+	b.mu.Unlock()
+
+	if b.Next == nil {
+		return nil
+	}
+	return b.Next.Publish(ctx, event)
+}
+
+// Changed returns a channel that is closed the next time an event is
+// published. Take it before reading the outbox so nothing published in
+// between is missed.
+func (b *Bus) Changed() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.changed
+}
+
+// Filter selects the events a listener sees.
+type Filter struct {
+	Topics  []string // empty for every topic
+	AgentID int64    // recipient whose notifications are included; 0 for none
+}
+
+// Since returns up to limit events after the event with ID afterID that
+// match filter, oldest first. Notification events are private, so only the
+// filter's agent's own are included.
+func Since(db *gorm.DB, afterID int64, filter Filter, limit int) ([]models.OutboxEvent, error) {
+	query := db.Where("id > ?", afterID)
+	if len(filter.Topics) > 0 {
+		query = query.Where("topic IN ?", filter.Topics)
+	}
+	ownNotifications := db.Model(&models.Notification{}).Select("id").Where("agent_id = ?", filter.AgentID)
+	query = query.Where("topic <> ? OR aggregate_id IN (?)", TopicNotificationSent, ownNotifications)
+
+	var events []models.OutboxEvent
+	err := query.Order("id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+// LatestID returns the ID of the newest event, or 0 if there is none.
+func LatestID(db *gorm.DB) (int64, error) {
+	var latest int64
+	err := db.Model(&models.OutboxEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&latest).Error
+	return latest, err
+}
@@ -0,0 +1,59 @@
+// Package outbox implements the transactional outbox: events are written to
+// the outbox_events table inside the transaction that changes state, and a
+// Relay publishes them afterwards. Delivery is at least once, so consumers
+// should de-duplicate on the event ID.
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// Event topics.
+const (
+	TopicMarketCreated         = "market.created"
+	TopicMarketResolved        = "market.resolved"
+	TopicPredictionCreated     = "prediction.created"
+	TopicSubmissionResolved    = "submission.resolved"
+	TopicProposalStatusChanged = "proposal.status_changed"
+	TopicNotificationSent      = "notification.sent"
+)
+
+// Aggregate types the events refer to.
+const (
+	AggregateMarket       = "market"
+	AggregatePrediction   = "prediction"
+	AggregateSubmission   = "submission"
+	AggregateProposal     = "proposal"
+	AggregateNotification = "notification"
+)
+
+// NewEvent builds an undelivered event with payload encoded as JSON.
+func NewEvent(topic, aggregateType string, aggregateID int64, payload interface{}) (*models.OutboxEvent, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s payload: %w", topic, err)
+	}
+	return &models.OutboxEvent{
+		Topic:         topic,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(raw),
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+// Enqueue writes an event using tx, which should be the transaction that
+// makes the corresponding state change.
+func Enqueue(tx *gorm.DB, topic, aggregateType string, aggregateID int64, payload interface{}) error {
+	event, err := NewEvent(topic, aggregateType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(event).Error
+}
@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Publisher delivers one event to its consumers (event bus, webhooks, ...).
+// A returned error leaves the event in the outbox to be retried.
+type Publisher interface {
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// PublisherFunc adapts a function to Publisher.
+type PublisherFunc func(ctx context.Context, event models.OutboxEvent) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event models.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+// LogPublisher logs each event. It stands in until a real consumer exists.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(_ context.Context, event models.OutboxEvent) error {
+	log.Printf("outbox: %s %s/%d (event %d)", event.Topic, event.AggregateType, event.AggregateID, event.ID)
+	return nil
+}
+
+const (
+	defaultBatchSize  = 100
+	defaultInterval   = 2 * time.Second
+	defaultMaxBackoff = 5 * time.Minute
+	maxErrorLength    = 500
+)
+
+// Relay publishes pending outbox events and marks them delivered.
+type Relay struct {
+	db        *gorm.DB
+	publisher Publisher
+
+	BatchSize  int
+	Interval   time.Duration
+	MaxBackoff time.Duration
+
+	now func() time.Time
+}
+
+// NewRelay returns a relay with default batch size, poll interval and
+// retry backoff.
+func NewRelay(db *gorm.DB, publisher Publisher) *Relay {
+	return &Relay{
+		db:         db,
+		publisher:  publisher,
+		BatchSize:  defaultBatchSize,
+		Interval:   defaultInterval,
+		MaxBackoff: defaultMaxBackoff,
+		now:        time.Now,
+	}
+}
+
+// Run relays events every Interval until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RelayOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox: relay failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes one batch of due events and returns how many were
+// delivered. Rows are locked with SKIP LOCKED on Postgres so several relays
+// can run side by side; SQLite ignores the locking clause.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	delivered := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := r.now()
+
+		var events []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delivered_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id").
+			Limit(r.BatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		for i := range events {
+			event := &events[i]
+			if err := r.publisher.Publish(ctx, *event); err != nil {
+				event.Attempts++
+				event.LastError = truncate(err.Error(), maxErrorLength)
+				event.NextAttemptAt = now.Add(r.backoff(event.Attempts))
+			} else {
+				event.DeliveredAt = &now
+				event.LastError = ""
+				delivered++
+			}
+			if err := tx.Save(event).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return delivered, err
+}
+
+// backoff doubles the retry delay with each failed attempt, up to MaxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < r.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.MaxBackoff {
+		return r.MaxBackoff
+	}
+	return delay
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
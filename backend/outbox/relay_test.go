@@ -0,0 +1,85 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func newOutboxTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := modelstesting.NewFakeDB(t)
+	if err := db.AutoMigrate(&models.OutboxEvent{}); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	return db
+}
+
+func TestRelayOnce_DeliversAndRetries(t *testing.T) {
+	db := newOutboxTestDB(t)
+	if err := Enqueue(db, TopicMarketCreated, AggregateMarket, 1, map[string]int64{"marketId": 1}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	now := time.Now()
+	failing := true
+	var published []models.OutboxEvent
+	relay := NewRelay(db, PublisherFunc(func(_ context.Context, event models.OutboxEvent) error {
+		if failing {
+			return errors.New("consumer unavailable")
+		}
+		published = append(published, event)
+		return nil
+	}))
+	relay.now = func() time.Time { return now }
+
+	if n, err := relay.RelayOnce(context.Background()); err != nil || n != 0 {
+		t.Fatalf("expected failed delivery, got n=%d err=%v", n, err)
+	}
+	var event models.OutboxEvent
+	db.First(&event)
+	if event.Attempts != 1 || event.LastError == "" || event.DeliveredAt != nil {
+		t.Fatalf("expected one failed attempt recorded, got %+v", event)
+	}
+
+	// Not due yet, so nothing is retried.
+	failing = false
+	if n, _ := relay.RelayOnce(context.Background()); n != 0 {
+		t.Fatalf("expected backoff to defer retry, delivered %d", n)
+	}
+
+	now = now.Add(time.Minute)
+	if n, err := relay.RelayOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("expected retry to deliver, got n=%d err=%v", n, err)
+	}
+	db.First(&event)
+	if event.DeliveredAt == nil || event.LastError != "" {
+		t.Fatalf("expected event delivered, got %+v", event)
+	}
+	if len(published) != 1 || published[0].Topic != TopicMarketCreated {
+		t.Fatalf("unexpected published events %+v", published)
+	}
+
+	if n, _ := relay.RelayOnce(context.Background()); n != 0 {
+		t.Fatalf("expected delivered events to be skipped, delivered %d", n)
+	}
+}
+
+func TestRelayBackoffIsCapped(t *testing.T) {
+	relay := NewRelay(nil, LogPublisher{})
+	if got := relay.backoff(1); got != time.Second {
+		t.Fatalf("expected 1s for first retry, got %v", got)
+	}
+	if got := relay.backoff(4); got != 8*time.Second {
+		t.Fatalf("expected 8s for fourth retry, got %v", got)
+	}
+	if got := relay.backoff(50); got != defaultMaxBackoff {
+		t.Fatalf("expected backoff capped at %v, got %v", defaultMaxBackoff, got)
+	}
+}
@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: socialpredict/v1/consensus.proto
+
+package socialpredictv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ConsensusService_GetConsensus_FullMethodName   = "/socialpredict.v1.ConsensusService/GetConsensus"
+	ConsensusService_WatchConsensus_FullMethodName = "/socialpredict.v1.ConsensusService/WatchConsensus"
+)
+
+// ConsensusServiceClient is the client API for ConsensusService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ConsensusService serves the swarm consensus of markets.
+type ConsensusServiceClient interface {
+	// GetConsensus returns a market's current consensus.
+	GetConsensus(ctx context.Context, in *GetConsensusRequest, opts ...grpc.CallOption) (*Consensus, error)
+	// WatchConsensus sends the current consensus of each market, then each
+	// market's new consensus whenever a prediction on it is made or revised,
+	// and a last one once it resolves. The stream ends when every market has
+	// resolved or the client cancels.
+	WatchConsensus(ctx context.Context, in *WatchConsensusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Consensus], error)
+}
+
+type consensusServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConsensusServiceClient(cc grpc.ClientConnInterface) ConsensusServiceClient {
+	return &consensusServiceClient{cc}
+}
+
+func (c *consensusServiceClient) GetConsensus(ctx context.Context, in *GetConsensusRequest, opts ...grpc.CallOption) (*Consensus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Consensus)
+	err := c.cc.Invoke(ctx, ConsensusService_GetConsensus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consensusServiceClient) WatchConsensus(ctx context.Context, in *WatchConsensusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Consensus], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ConsensusService_ServiceDesc.Streams[0], ConsensusService_WatchConsensus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchConsensusRequest, Consensus]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ConsensusService_WatchConsensusClient = grpc.ServerStreamingClient[Consensus]
+
+// ConsensusServiceServer is the server API for ConsensusService service.
+// All implementations must embed UnimplementedConsensusServiceServer
+// for forward compatibility.
+//
+// ConsensusService serves the swarm consensus of markets.
+type ConsensusServiceServer interface {
+	// GetConsensus returns a market's current consensus.
+	GetConsensus(context.Context, *GetConsensusRequest) (*Consensus, error)
+	// WatchConsensus sends the current consensus of each market, then each
+	// market's new consensus whenever a prediction on it is made or revised,
+	// and a last one once it resolves. The stream ends when every market has
+	// resolved or the client cancels.
+	WatchConsensus(*WatchConsensusRequest, grpc.ServerStreamingServer[Consensus]) error
+	mustEmbedUnimplementedConsensusServiceServer()
+}
+
+// UnimplementedConsensusServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedConsensusServiceServer struct{}
+
+func (UnimplementedConsensusServiceServer) GetConsensus(context.Context, *GetConsensusRequest) (*Consensus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConsensus not implemented")
+}
+func (UnimplementedConsensusServiceServer) WatchConsensus(*WatchConsensusRequest, grpc.ServerStreamingServer[Consensus]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchConsensus not implemented")
+}
+func (UnimplementedConsensusServiceServer) mustEmbedUnimplementedConsensusServiceServer() {}
+func (UnimplementedConsensusServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeConsensusServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ConsensusServiceServer will
+// result in compilation errors.
+type UnsafeConsensusServiceServer interface {
+	mustEmbedUnimplementedConsensusServiceServer()
+}
+
+func RegisterConsensusServiceServer(s grpc.ServiceRegistrar, srv ConsensusServiceServer) {
+	// If the following call pancis, it indicates UnimplementedConsensusServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ConsensusService_ServiceDesc, srv)
+}
+
+func _ConsensusService_GetConsensus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConsensusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsensusServiceServer).GetConsensus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConsensusService_GetConsensus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsensusServiceServer).GetConsensus(ctx, req.(*GetConsensusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConsensusService_WatchConsensus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchConsensusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConsensusServiceServer).WatchConsensus(m, &grpc.GenericServerStream[WatchConsensusRequest, Consensus]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ConsensusService_WatchConsensusServer = grpc.ServerStreamingServer[Consensus]
+
+// ConsensusService_ServiceDesc is the grpc.ServiceDesc for ConsensusService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ConsensusService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "socialpredict.v1.ConsensusService",
+	HandlerType: (*ConsensusServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetConsensus",
+			Handler:    _ConsensusService_GetConsensus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchConsensus",
+			Handler:       _ConsensusService_WatchConsensus_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "socialpredict/v1/consensus.proto",
+}
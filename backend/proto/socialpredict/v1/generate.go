@@ -0,0 +1,6 @@
+// Package socialpredictv1 holds the messages and services of the gRPC API.
+// The .pb.go files are generated from the .proto files beside them by buf,
+// using proto/buf.gen.yaml.
+package socialpredictv1
+
+//go:generate buf generate ../.. --template ../../buf.gen.yaml --output ../..
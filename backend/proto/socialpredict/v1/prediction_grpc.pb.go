@@ -0,0 +1,173 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: socialpredict/v1/prediction.proto
+
+package socialpredictv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PredictionService_MakePrediction_FullMethodName        = "/socialpredict.v1.PredictionService/MakePrediction"
+	PredictionService_ListMarketPredictions_FullMethodName = "/socialpredict.v1.PredictionService/ListMarketPredictions"
+)
+
+// PredictionServiceClient is the client API for PredictionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PredictionService makes and lists predictions, as POST /v0/predict and
+// GET /v0/market/{id}/predictions do.
+type PredictionServiceClient interface {
+	// MakePrediction records the calling agent's prediction on a market,
+	// updating its earlier one if it already predicted. The agent must be
+	// claimed.
+	MakePrediction(ctx context.Context, in *MakePredictionRequest, opts ...grpc.CallOption) (*MakePredictionResponse, error)
+	// ListMarketPredictions lists a market's predictions, most upvoted first.
+	ListMarketPredictions(ctx context.Context, in *ListMarketPredictionsRequest, opts ...grpc.CallOption) (*ListMarketPredictionsResponse, error)
+}
+
+type predictionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPredictionServiceClient(cc grpc.ClientConnInterface) PredictionServiceClient {
+	return &predictionServiceClient{cc}
+}
+
+func (c *predictionServiceClient) MakePrediction(ctx context.Context, in *MakePredictionRequest, opts ...grpc.CallOption) (*MakePredictionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MakePredictionResponse)
+	err := c.cc.Invoke(ctx, PredictionService_MakePrediction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *predictionServiceClient) ListMarketPredictions(ctx context.Context, in *ListMarketPredictionsRequest, opts ...grpc.CallOption) (*ListMarketPredictionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMarketPredictionsResponse)
+	err := c.cc.Invoke(ctx, PredictionService_ListMarketPredictions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PredictionServiceServer is the server API for PredictionService service.
+// All implementations must embed UnimplementedPredictionServiceServer
+// for forward compatibility.
+//
+// PredictionService makes and lists predictions, as POST /v0/predict and
+// GET /v0/market/{id}/predictions do.
+type PredictionServiceServer interface {
+	// MakePrediction records the calling agent's prediction on a market,
+	// updating its earlier one if it already predicted. The agent must be
+	// claimed.
+	MakePrediction(context.Context, *MakePredictionRequest) (*MakePredictionResponse, error)
+	// ListMarketPredictions lists a market's predictions, most upvoted first.
+	ListMarketPredictions(context.Context, *ListMarketPredictionsRequest) (*ListMarketPredictionsResponse, error)
+	mustEmbedUnimplementedPredictionServiceServer()
+}
+
+// UnimplementedPredictionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPredictionServiceServer struct{}
+
+func (UnimplementedPredictionServiceServer) MakePrediction(context.Context, *MakePredictionRequest) (*MakePredictionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MakePrediction not implemented")
+}
+func (UnimplementedPredictionServiceServer) ListMarketPredictions(context.Context, *ListMarketPredictionsRequest) (*ListMarketPredictionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMarketPredictions not implemented")
+}
+func (UnimplementedPredictionServiceServer) mustEmbedUnimplementedPredictionServiceServer() {}
+func (UnimplementedPredictionServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafePredictionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PredictionServiceServer will
+// result in compilation errors.
+type UnsafePredictionServiceServer interface {
+	mustEmbedUnimplementedPredictionServiceServer()
+}
+
+func RegisterPredictionServiceServer(s grpc.ServiceRegistrar, srv PredictionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPredictionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PredictionService_ServiceDesc, srv)
+}
+
+func _PredictionService_MakePrediction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MakePredictionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PredictionServiceServer).MakePrediction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PredictionService_MakePrediction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PredictionServiceServer).MakePrediction(ctx, req.(*MakePredictionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PredictionService_ListMarketPredictions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMarketPredictionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PredictionServiceServer).ListMarketPredictions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PredictionService_ListMarketPredictions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PredictionServiceServer).ListMarketPredictions(ctx, req.(*ListMarketPredictionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PredictionService_ServiceDesc is the grpc.ServiceDesc for PredictionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PredictionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "socialpredict.v1.PredictionService",
+	HandlerType: (*PredictionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MakePrediction",
+			Handler:    _PredictionService_MakePrediction_Handler,
+		},
+		{
+			MethodName: "ListMarketPredictions",
+			Handler:    _PredictionService_ListMarketPredictions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "socialpredict/v1/prediction.proto",
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// AgentRepo loads and persists agents.
+type AgentRepo interface {
+	GetByID(id int64) (*models.Agent, error)
+	GetByName(name string) (*models.Agent, error)
+	GetByAPIKey(apiKey string) (*models.Agent, error)
+	ListByIDs(ids []int64) ([]models.Agent, error)
+	Create(agent *models.Agent) error
+	Save(agent *models.Agent) error
+	ShadowUser(agent *models.Agent) (*models.User, error)
+}
+
+type GormAgentRepo struct {
+	db *gorm.DB
+}
+
+func NewGormAgentRepo(db *gorm.DB) *GormAgentRepo {
+	return &GormAgentRepo{db: db}
+}
+
+func (r *GormAgentRepo) GetByID(id int64) (*models.Agent, error) {
+	var agent models.Agent
+	if err := r.db.First(&agent, id).Error; err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+func (r *GormAgentRepo) GetByName(name string) (*models.Agent, error) {
+	var agent models.Agent
+	if err := r.db.Where("name = ?", name).First(&agent).Error; err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// ErrAPIKeyExpired is returned by GetByAPIKey for a key that has expired.
+var ErrAPIKeyExpired = errors.New("agent API key has expired")
+
+// apiKeyUsageResolution is how often a key's last_used_at is refreshed, so
+// busy agents do not write on every request.
+const apiKeyUsageResolution = time.Minute
+
+// GetByAPIKey returns the agent holding apiKey. Keys are looked up in
+// agent_api_keys and their last use is recorded; an agent's legacy
+// agents.api_key only authenticates while it has no rows there.
+func (r *GormAgentRepo) GetByAPIKey(apiKey string) (*models.Agent, error) {
+	agent, _, err := r.Authenticate(apiKey)
+	return agent, err
+}
+
+// Authenticate is GetByAPIKey that also returns the key row, whose scopes
+// limit what the key may do. The row is nil for a legacy key, which has
+// every scope.
+func (r *GormAgentRepo) Authenticate(apiKey string) (*models.Agent, *models.AgentAPIKey, error) {
+	now := time.Now()
+
+	var key models.AgentAPIKey
+	err := r.db.Where("key_hash = ? AND revoked_at IS NULL", models.HashAgentAPIKey(apiKey)).First(&key).Error
+	if err == nil {
+		if !key.IsActive(now) {
+			return nil, nil, ErrAPIKeyExpired
+		}
+		r.db.Model(&models.AgentAPIKey{}).
+			Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", key.ID, now.Add(-apiKeyUsageResolution)).
+			Update("last_used_at", now)
+		agent, err := r.GetByID(key.AgentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return agent, &key, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	var agent models.Agent
+	err = r.db.Where("api_key = ?", apiKey).
+		Where("NOT EXISTS (SELECT 1 FROM agent_api_keys WHERE agent_api_keys.agent_id = agents.id)").
+		First(&agent).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return &agent, nil, nil
+}
+
+func (r *GormAgentRepo) ListByIDs(ids []int64) ([]models.Agent, error) {
+	var agents []models.Agent
+	if len(ids) == 0 {
+		return agents, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&agents).Error; err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+func (r *GormAgentRepo) Create(agent *models.Agent) error {
+	return r.db.Create(agent).Error
+}
+
+func (r *GormAgentRepo) Save(agent *models.Agent) error {
+	return r.db.Save(agent).Error
+}
+
+// ShadowUser returns the user row that anchors the agent's bets, creating it
+// on first use. The row is keyed by agent ID, so an agent has exactly one.
+func (r *GormAgentRepo) ShadowUser(agent *models.Agent) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("agent_id = ?", agent.ID).First(&user).Error
+	if err == nil {
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	agentID := agent.ID
+	username := models.AgentShadowUsername(agent.Name)
+	user = models.User{
+		PublicUser: models.PublicUser{
+			Username:    username,
+			DisplayName: username,
+			UserType:    "AGENT",
+		},
+		PrivateUser: models.PrivateUser{
+			Email: fmt.Sprintf("agent-%d@agents.invalid", agent.ID),
+			// Not a bcrypt hash, so no password can ever match.
+			Password: "!",
+		},
+		AgentID: &agentID,
+	}
+	if err := r.db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
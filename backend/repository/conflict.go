@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"errors"
+
+	"socialpredict/models"
+)
+
+// DefaultConflictRetries is how many times services re-run a unit of work
+// that lost an optimistic locking race before giving up.
+const DefaultConflictRetries = 3
+
+// IsConflict reports whether err came from a stale versioned write.
+func IsConflict(err error) bool {
+	return errors.Is(err, models.ErrStaleVersion)
+}
+
+// RetryOnConflict runs fn up to attempts times, retrying only when it fails
+// with models.ErrStaleVersion. fn must reload whatever it modifies, since the
+// copies from the failed attempt are stale.
+func RetryOnConflict(attempts int, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); !IsConflict(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"strings"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// MarketRepo loads and persists markets.
+type MarketRepo interface {
+	GetByID(id int64) (*models.Market, error)
+	CountTitlesContaining(fragment string) (int64, error)
+	Create(market *models.Market) error
+	Save(market *models.Market) error
+	// SetTags replaces the market's tags with tags.
+	SetTags(marketID int64, tags []string) error
+}
+
+type GormMarketRepo struct {
+	db *gorm.DB
+}
+
+func NewGormMarketRepo(db *gorm.DB) *GormMarketRepo {
+	return &GormMarketRepo{db: db}
+}
+
+func (r *GormMarketRepo) GetByID(id int64) (*models.Market, error) {
+	var market models.Market
+	if err := r.db.First(&market, id).Error; err != nil {
+		return nil, err
+	}
+	return &market, nil
+}
+
+// CountTitlesContaining returns how many markets have a question title that
+// contains fragment, ignoring case. Used for duplicate detection.
+func (r *GormMarketRepo) CountTitlesContaining(fragment string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Market{}).
+		Where("LOWER(question_title) LIKE ?", "%"+strings.ToLower(fragment)+"%").
+		Count(&count).Error
+	return count, err
+}
+
+func (r *GormMarketRepo) Create(market *models.Market) error {
+	return r.db.Create(market).Error
+}
+
+func (r *GormMarketRepo) Save(market *models.Market) error {
+	return r.db.Save(market).Error
+}
+
+func (r *GormMarketRepo) SetTags(marketID int64, tags []string) error {
+	if err := r.db.Where("market_id = ?", marketID).Delete(&models.MarketTag{}).Error; err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	rows := make([]models.MarketTag, len(tags))
+	for i, tag := range tags {
+		rows[i] = models.MarketTag{MarketID: marketID, Tag: tag}
+	}
+	return r.db.Create(&rows).Error
+}
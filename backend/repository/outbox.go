@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// OutboxRepo appends events to the transactional outbox. Use it through
+// Repositories.Transaction so the event commits with the state change.
+type OutboxRepo interface {
+	Enqueue(event *models.OutboxEvent) error
+}
+
+type GormOutboxRepo struct {
+	db *gorm.DB
+}
+
+func NewGormOutboxRepo(db *gorm.DB) *GormOutboxRepo {
+	return &GormOutboxRepo{db: db}
+}
+
+func (r *GormOutboxRepo) Enqueue(event *models.OutboxEvent) error {
+	return r.db.Create(event).Error
+}
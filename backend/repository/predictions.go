@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// PredictionRepo loads and persists agent predictions.
+type PredictionRepo interface {
+	GetByID(id int64) (*models.Prediction, error)
+	GetByAgentAndMarket(agentID, marketID int64) (*models.Prediction, error)
+	ListByAgent(agentID int64, limit, offset int) ([]models.Prediction, error)
+	ListByMarket(marketID int64, limit int) ([]models.Prediction, error)
+	CountByAgent(agentID int64) (int64, error)
+	Create(prediction *models.Prediction) error
+	Save(prediction *models.Prediction) error
+}
+
+type GormPredictionRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPredictionRepo(db *gorm.DB) *GormPredictionRepo {
+	return &GormPredictionRepo{db: db}
+}
+
+// GetByID loads a prediction together with its agent and market.
+func (r *GormPredictionRepo) GetByID(id int64) (*models.Prediction, error) {
+	var prediction models.Prediction
+	if err := r.db.Preload("Agent").Preload("Market").First(&prediction, id).Error; err != nil {
+		return nil, err
+	}
+	return &prediction, nil
+}
+
+func (r *GormPredictionRepo) GetByAgentAndMarket(agentID, marketID int64) (*models.Prediction, error) {
+	var prediction models.Prediction
+	if err := r.db.Where("agent_id = ? AND market_id = ?", agentID, marketID).First(&prediction).Error; err != nil {
+		return nil, err
+	}
+	return &prediction, nil
+}
+
+// ListByAgent returns an agent's predictions, newest first, with markets preloaded.
+func (r *GormPredictionRepo) ListByAgent(agentID int64, limit, offset int) ([]models.Prediction, error) {
+	var predictions []models.Prediction
+	err := r.db.Preload("Market").
+		Where("agent_id = ?", agentID).
+		Order("predicted_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&predictions).Error
+	return predictions, err
+}
+
+// ListByMarket returns a market's predictions, most upvoted first, with agents preloaded.
+func (r *GormPredictionRepo) ListByMarket(marketID int64, limit int) ([]models.Prediction, error) {
+	var predictions []models.Prediction
+	err := r.db.Preload("Agent").
+		Where("market_id = ?", marketID).
+		Order("upvotes DESC, predicted_at DESC").
+		Limit(limit).
+		Find(&predictions).Error
+	return predictions, err
+}
+
+func (r *GormPredictionRepo) CountByAgent(agentID int64) (int64, error) {
+	var total int64
+	err := r.db.Model(&models.Prediction{}).Where("agent_id = ?", agentID).Count(&total).Error
+	return total, err
+}
+
+func (r *GormPredictionRepo) Create(prediction *models.Prediction) error {
+	return r.db.Create(prediction).Error
+}
+
+func (r *GormPredictionRepo) Save(prediction *models.Prediction) error {
+	return r.db.Save(prediction).Error
+}
@@ -0,0 +1,46 @@
+// Package repository holds the persistence boundary for the agent platform.
+// Each aggregate gets a small interface plus a GORM implementation so that
+// services can be exercised against the generated mocks in
+// repositorytesting.
+package repository
+
+import "gorm.io/gorm"
+
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -destination=repositorytesting/mocks.go -package=repositorytesting socialpredict/repository AgentRepo,MarketRepo,PredictionRepo,SubmissionRepo,OutboxRepo
+
+// Repositories bundles every aggregate repository behind one value so that
+// services can be constructed from a single dependency.
+type Repositories struct {
+	Agents      AgentRepo
+	Markets     MarketRepo
+	Predictions PredictionRepo
+	Submissions SubmissionRepo
+	Outbox      OutboxRepo
+
+	db *gorm.DB
+}
+
+// NewGormRepositories wires the GORM implementation of every repository
+// against the same connection (or transaction).
+func NewGormRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Agents:      NewGormAgentRepo(db),
+		Markets:     NewGormMarketRepo(db),
+		Predictions: NewGormPredictionRepo(db),
+		Submissions: NewGormSubmissionRepo(db),
+		Outbox:      NewGormOutboxRepo(db),
+		db:          db,
+	}
+}
+
+// Transaction runs fn with repositories bound to a single database
+// transaction, committing if fn returns nil and rolling back otherwise.
+// Bundles built by hand (e.g. from mocks) simply call fn with themselves.
+func (r *Repositories) Transaction(fn func(tx *Repositories) error) error {
+	if r.db == nil {
+		return fn(r)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormRepositories(tx))
+	})
+}
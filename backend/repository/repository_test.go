@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func newRepoTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := modelstesting.NewFakeDB(t)
+	if err := db.AutoMigrate(
+		&models.Agent{},
+		&models.Prediction{},
+		&models.PendingSubmission{},
+		&models.CouncilVote{},
+		&models.ValidatorAgent{},
+	); err != nil {
+		t.Fatalf("auto-migrate: %v", err)
+	}
+	return db
+}
+
+func TestGormAgentRepo_CreateAndLookup(t *testing.T) {
+	repo := NewGormAgentRepo(newRepoTestDB(t))
+
+	agent := &models.Agent{Name: "oracle", APIKey: "swarm_sk_test", ClaimToken: "swarm_claim_test", IsActive: true}
+	if err := repo.Create(agent); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	byKey, err := repo.GetByAPIKey("swarm_sk_test")
+	if err != nil {
+		t.Fatalf("GetByAPIKey: %v", err)
+	}
+	if byKey.ID != agent.ID {
+		t.Fatalf("expected agent %d, got %d", agent.ID, byKey.ID)
+	}
+
+	if _, err := repo.GetByName("missing"); err != gorm.ErrRecordNotFound {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+
+	listed, err := repo.ListByIDs(nil)
+	if err != nil || len(listed) != 0 {
+		t.Fatalf("expected empty list for no ids, got %v, %v", listed, err)
+	}
+}
+
+func TestGormAgentRepo_GetByAPIKeyUsesKeyRows(t *testing.T) {
+	db := newRepoTestDB(t)
+	repo := NewGormAgentRepo(db)
+
+	agent := &models.Agent{Name: "oracle", APIKey: "swarm_sk_legacy", ClaimToken: "swarm_claim_test", IsActive: true}
+	if err := repo.Create(agent); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Without key rows the legacy column still authenticates.
+	if _, err := repo.GetByAPIKey("swarm_sk_legacy"); err != nil {
+		t.Fatalf("legacy GetByAPIKey: %v", err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	current := models.NewAgentAPIKey(agent.ID, "current", "swarm_sk_current")
+	expired := models.NewAgentAPIKey(agent.ID, "expired", "swarm_sk_expired")
+	expired.ExpiresAt = &past
+	revoked := models.NewAgentAPIKey(agent.ID, "revoked", "swarm_sk_revoked")
+	revoked.RevokedAt = &past
+	for _, key := range []*models.AgentAPIKey{&current, &expired, &revoked} {
+		if err := db.Create(key).Error; err != nil {
+			t.Fatalf("create key %s: %v", key.Name, err)
+		}
+	}
+
+	byKey, err := repo.GetByAPIKey("swarm_sk_current")
+	if err != nil || byKey.ID != agent.ID {
+		t.Fatalf("expected agent %d for current key, got %v, %v", agent.ID, byKey, err)
+	}
+	var used models.AgentAPIKey
+	db.First(&used, current.ID)
+	if used.LastUsedAt == nil {
+		t.Fatalf("expected last use to be recorded")
+	}
+
+	if _, err := repo.GetByAPIKey("swarm_sk_expired"); err != ErrAPIKeyExpired {
+		t.Fatalf("expected ErrAPIKeyExpired, got %v", err)
+	}
+	if _, err := repo.GetByAPIKey("swarm_sk_revoked"); err != gorm.ErrRecordNotFound {
+		t.Fatalf("expected revoked key to be unknown, got %v", err)
+	}
+	if _, err := repo.GetByAPIKey("swarm_sk_legacy"); err != gorm.ErrRecordNotFound {
+		t.Fatalf("expected legacy key to stop working once key rows exist, got %v", err)
+	}
+}
+
+func TestGormAgentRepo_ShadowUserIsCreatedOnce(t *testing.T) {
+	repo := NewGormAgentRepo(newRepoTestDB(t))
+
+	agent := &models.Agent{Name: "oracle", APIKey: "swarm_sk_test", ClaimToken: "swarm_claim_test"}
+	if err := repo.Create(agent); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	first, err := repo.ShadowUser(agent)
+	if err != nil {
+		t.Fatalf("ShadowUser: %v", err)
+	}
+	if first.Username != "agent:oracle" || first.AgentID == nil || *first.AgentID != agent.ID {
+		t.Fatalf("unexpected shadow user %+v", first)
+	}
+
+	// A rename must not fork a second shadow user.
+	agent.Name = "oracle-v2"
+	second, err := repo.ShadowUser(agent)
+	if err != nil {
+		t.Fatalf("ShadowUser after rename: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Fatalf("expected shadow user %d to be reused, got %d", first.ID, second.ID)
+	}
+}
+
+func TestGormAgentRepo_SaveRejectsStaleVersion(t *testing.T) {
+	repo := NewGormAgentRepo(newRepoTestDB(t))
+
+	agent := &models.Agent{Name: "oracle", APIKey: "swarm_sk_test", ClaimToken: "swarm_claim_test"}
+	if err := repo.Create(agent); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	first, _ := repo.GetByID(agent.ID)
+	second, _ := repo.GetByID(agent.ID)
+
+	first.TotalFollowers = 1
+	if err := repo.Save(first); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+
+	second.TotalFollowing = 1
+	if err := repo.Save(second); !IsConflict(err) {
+		t.Fatalf("expected stale version conflict, got %v", err)
+	}
+
+	err := RetryOnConflict(DefaultConflictRetries, func() error {
+		fresh, err := repo.GetByID(agent.ID)
+		if err != nil {
+			return err
+		}
+		fresh.TotalFollowing = 1
+		return repo.Save(fresh)
+	})
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	final, _ := repo.GetByID(agent.ID)
+	if final.TotalFollowers != 1 || final.TotalFollowing != 1 || final.Version != 3 {
+		t.Fatalf("expected both updates at version 3, got followers=%d following=%d version=%d",
+			final.TotalFollowers, final.TotalFollowing, final.Version)
+	}
+}
+
+func TestGormSubmissionRepo_QueueExcludesOwnAndVoted(t *testing.T) {
+	repo := NewGormSubmissionRepo(newRepoTestDB(t))
+	now := time.Now()
+
+	own := &models.PendingSubmission{SubmissionType: "market", SubmitterAgentID: 1, VotingEndsAt: now.Add(time.Hour)}
+	voted := &models.PendingSubmission{SubmissionType: "market", SubmitterAgentID: 2, VotingEndsAt: now.Add(time.Hour)}
+	open := &models.PendingSubmission{SubmissionType: "market", SubmitterAgentID: 3, VotingEndsAt: now.Add(time.Hour)}
+	expired := &models.PendingSubmission{SubmissionType: "market", SubmitterAgentID: 4, VotingEndsAt: now.Add(-time.Hour)}
+	for _, s := range []*models.PendingSubmission{own, voted, open, expired} {
+		if err := repo.Create(s); err != nil {
+			t.Fatalf("create submission: %v", err)
+		}
+	}
+	if err := repo.CreateVote(&models.CouncilVote{SubmissionID: voted.ID, ValidatorID: 1, Vote: "approve"}); err != nil {
+		t.Fatalf("create vote: %v", err)
+	}
+
+	queue, err := repo.ListQueueForValidator(1, now)
+	if err != nil {
+		t.Fatalf("ListQueueForValidator: %v", err)
+	}
+	if len(queue) != 1 || queue[0].ID != open.ID {
+		t.Fatalf("expected only submission %d in queue, got %+v", open.ID, queue)
+	}
+
+	expiredList, err := repo.ListExpired(now)
+	if err != nil {
+		t.Fatalf("ListExpired: %v", err)
+	}
+	if len(expiredList) != 1 || expiredList[0].ID != expired.ID {
+		t.Fatalf("expected only submission %d expired, got %+v", expired.ID, expiredList)
+	}
+}
@@ -0,0 +1,576 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: socialpredict/repository (interfaces: AgentRepo,MarketRepo,PredictionRepo,SubmissionRepo,OutboxRepo)
+//
+// Generated by this command:
+//
+//	mockgen -destination=repositorytesting/mocks.go -package=repositorytesting socialpredict/repository AgentRepo,MarketRepo,PredictionRepo,SubmissionRepo,OutboxRepo
+//
+
+// Package repositorytesting is a generated GoMock package.
+package repositorytesting
+
+import (
+	reflect "reflect"
+	models "socialpredict/models"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAgentRepo is a mock of AgentRepo interface.
+type MockAgentRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAgentRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockAgentRepoMockRecorder is the mock recorder for MockAgentRepo.
+type MockAgentRepoMockRecorder struct {
+	mock *MockAgentRepo
+}
+
+// NewMockAgentRepo creates a new mock instance.
+func NewMockAgentRepo(ctrl *gomock.Controller) *MockAgentRepo {
+	mock := &MockAgentRepo{ctrl: ctrl}
+	mock.recorder = &MockAgentRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAgentRepo) EXPECT() *MockAgentRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAgentRepo) Create(agent *models.Agent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", agent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAgentRepoMockRecorder) Create(agent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAgentRepo)(nil).Create), agent)
+}
+
+// GetByAPIKey mocks base method.
+func (m *MockAgentRepo) GetByAPIKey(apiKey string) (*models.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAPIKey", apiKey)
+	ret0, _ := ret[0].(*models.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAPIKey indicates an expected call of GetByAPIKey.
+func (mr *MockAgentRepoMockRecorder) GetByAPIKey(apiKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAPIKey", reflect.TypeOf((*MockAgentRepo)(nil).GetByAPIKey), apiKey)
+}
+
+// GetByID mocks base method.
+func (m *MockAgentRepo) GetByID(id int64) (*models.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", id)
+	ret0, _ := ret[0].(*models.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockAgentRepoMockRecorder) GetByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockAgentRepo)(nil).GetByID), id)
+}
+
+// GetByName mocks base method.
+func (m *MockAgentRepo) GetByName(name string) (*models.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", name)
+	ret0, _ := ret[0].(*models.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockAgentRepoMockRecorder) GetByName(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockAgentRepo)(nil).GetByName), name)
+}
+
+// ListByIDs mocks base method.
+func (m *MockAgentRepo) ListByIDs(ids []int64) ([]models.Agent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByIDs", ids)
+	ret0, _ := ret[0].([]models.Agent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByIDs indicates an expected call of ListByIDs.
+func (mr *MockAgentRepoMockRecorder) ListByIDs(ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByIDs", reflect.TypeOf((*MockAgentRepo)(nil).ListByIDs), ids)
+}
+
+// Save mocks base method.
+func (m *MockAgentRepo) Save(agent *models.Agent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", agent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockAgentRepoMockRecorder) Save(agent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockAgentRepo)(nil).Save), agent)
+}
+
+// ShadowUser mocks base method.
+func (m *MockAgentRepo) ShadowUser(agent *models.Agent) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShadowUser", agent)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShadowUser indicates an expected call of ShadowUser.
+func (mr *MockAgentRepoMockRecorder) ShadowUser(agent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShadowUser", reflect.TypeOf((*MockAgentRepo)(nil).ShadowUser), agent)
+}
+
+// MockMarketRepo is a mock of MarketRepo interface.
+type MockMarketRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockMarketRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockMarketRepoMockRecorder is the mock recorder for MockMarketRepo.
+type MockMarketRepoMockRecorder struct {
+	mock *MockMarketRepo
+}
+
+// NewMockMarketRepo creates a new mock instance.
+func NewMockMarketRepo(ctrl *gomock.Controller) *MockMarketRepo {
+	mock := &MockMarketRepo{ctrl: ctrl}
+	mock.recorder = &MockMarketRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMarketRepo) EXPECT() *MockMarketRepoMockRecorder {
+	return m.recorder
+}
+
+// CountTitlesContaining mocks base method.
+func (m *MockMarketRepo) CountTitlesContaining(fragment string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTitlesContaining", fragment)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTitlesContaining indicates an expected call of CountTitlesContaining.
+func (mr *MockMarketRepoMockRecorder) CountTitlesContaining(fragment any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTitlesContaining", reflect.TypeOf((*MockMarketRepo)(nil).CountTitlesContaining), fragment)
+}
+
+// Create mocks base method.
+func (m *MockMarketRepo) Create(market *models.Market) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", market)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockMarketRepoMockRecorder) Create(market any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockMarketRepo)(nil).Create), market)
+}
+
+// GetByID mocks base method.
+func (m *MockMarketRepo) GetByID(id int64) (*models.Market, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", id)
+	ret0, _ := ret[0].(*models.Market)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockMarketRepoMockRecorder) GetByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockMarketRepo)(nil).GetByID), id)
+}
+
+// Save mocks base method.
+func (m *MockMarketRepo) Save(market *models.Market) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", market)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockMarketRepoMockRecorder) Save(market any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockMarketRepo)(nil).Save), market)
+}
+
+// SetTags mocks base method.
+func (m *MockMarketRepo) SetTags(marketID int64, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTags", marketID, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTags indicates an expected call of SetTags.
+func (mr *MockMarketRepoMockRecorder) SetTags(marketID, tags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTags", reflect.TypeOf((*MockMarketRepo)(nil).SetTags), marketID, tags)
+}
+
+// MockPredictionRepo is a mock of PredictionRepo interface.
+type MockPredictionRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockPredictionRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockPredictionRepoMockRecorder is the mock recorder for MockPredictionRepo.
+type MockPredictionRepoMockRecorder struct {
+	mock *MockPredictionRepo
+}
+
+// NewMockPredictionRepo creates a new mock instance.
+func NewMockPredictionRepo(ctrl *gomock.Controller) *MockPredictionRepo {
+	mock := &MockPredictionRepo{ctrl: ctrl}
+	mock.recorder = &MockPredictionRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPredictionRepo) EXPECT() *MockPredictionRepoMockRecorder {
+	return m.recorder
+}
+
+// CountByAgent mocks base method.
+func (m *MockPredictionRepo) CountByAgent(agentID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByAgent", agentID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByAgent indicates an expected call of CountByAgent.
+func (mr *MockPredictionRepoMockRecorder) CountByAgent(agentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByAgent", reflect.TypeOf((*MockPredictionRepo)(nil).CountByAgent), agentID)
+}
+
+// Create mocks base method.
+func (m *MockPredictionRepo) Create(prediction *models.Prediction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", prediction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPredictionRepoMockRecorder) Create(prediction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPredictionRepo)(nil).Create), prediction)
+}
+
+// GetByAgentAndMarket mocks base method.
+func (m *MockPredictionRepo) GetByAgentAndMarket(agentID, marketID int64) (*models.Prediction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAgentAndMarket", agentID, marketID)
+	ret0, _ := ret[0].(*models.Prediction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAgentAndMarket indicates an expected call of GetByAgentAndMarket.
+func (mr *MockPredictionRepoMockRecorder) GetByAgentAndMarket(agentID, marketID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAgentAndMarket", reflect.TypeOf((*MockPredictionRepo)(nil).GetByAgentAndMarket), agentID, marketID)
+}
+
+// GetByID mocks base method.
+func (m *MockPredictionRepo) GetByID(id int64) (*models.Prediction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", id)
+	ret0, _ := ret[0].(*models.Prediction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockPredictionRepoMockRecorder) GetByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPredictionRepo)(nil).GetByID), id)
+}
+
+// ListByAgent mocks base method.
+func (m *MockPredictionRepo) ListByAgent(agentID int64, limit, offset int) ([]models.Prediction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByAgent", agentID, limit, offset)
+	ret0, _ := ret[0].([]models.Prediction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByAgent indicates an expected call of ListByAgent.
+func (mr *MockPredictionRepoMockRecorder) ListByAgent(agentID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByAgent", reflect.TypeOf((*MockPredictionRepo)(nil).ListByAgent), agentID, limit, offset)
+}
+
+// ListByMarket mocks base method.
+func (m *MockPredictionRepo) ListByMarket(marketID int64, limit int) ([]models.Prediction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByMarket", marketID, limit)
+	ret0, _ := ret[0].([]models.Prediction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByMarket indicates an expected call of ListByMarket.
+func (mr *MockPredictionRepoMockRecorder) ListByMarket(marketID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByMarket", reflect.TypeOf((*MockPredictionRepo)(nil).ListByMarket), marketID, limit)
+}
+
+// Save mocks base method.
+func (m *MockPredictionRepo) Save(prediction *models.Prediction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", prediction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockPredictionRepoMockRecorder) Save(prediction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockPredictionRepo)(nil).Save), prediction)
+}
+
+// MockSubmissionRepo is a mock of SubmissionRepo interface.
+type MockSubmissionRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubmissionRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockSubmissionRepoMockRecorder is the mock recorder for MockSubmissionRepo.
+type MockSubmissionRepoMockRecorder struct {
+	mock *MockSubmissionRepo
+}
+
+// NewMockSubmissionRepo creates a new mock instance.
+func NewMockSubmissionRepo(ctrl *gomock.Controller) *MockSubmissionRepo {
+	mock := &MockSubmissionRepo{ctrl: ctrl}
+	mock.recorder = &MockSubmissionRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubmissionRepo) EXPECT() *MockSubmissionRepoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSubmissionRepo) Create(submission *models.PendingSubmission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", submission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSubmissionRepoMockRecorder) Create(submission any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSubmissionRepo)(nil).Create), submission)
+}
+
+// CreateVote mocks base method.
+func (m *MockSubmissionRepo) CreateVote(vote *models.CouncilVote) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVote", vote)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVote indicates an expected call of CreateVote.
+func (mr *MockSubmissionRepoMockRecorder) CreateVote(vote any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVote", reflect.TypeOf((*MockSubmissionRepo)(nil).CreateVote), vote)
+}
+
+// GetActiveValidator mocks base method.
+func (m *MockSubmissionRepo) GetActiveValidator(agentID int64) (*models.ValidatorAgent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveValidator", agentID)
+	ret0, _ := ret[0].(*models.ValidatorAgent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveValidator indicates an expected call of GetActiveValidator.
+func (mr *MockSubmissionRepoMockRecorder) GetActiveValidator(agentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveValidator", reflect.TypeOf((*MockSubmissionRepo)(nil).GetActiveValidator), agentID)
+}
+
+// GetByID mocks base method.
+func (m *MockSubmissionRepo) GetByID(id int64) (*models.PendingSubmission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", id)
+	ret0, _ := ret[0].(*models.PendingSubmission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockSubmissionRepoMockRecorder) GetByID(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockSubmissionRepo)(nil).GetByID), id)
+}
+
+// GetVote mocks base method.
+func (m *MockSubmissionRepo) GetVote(submissionID, validatorID int64) (*models.CouncilVote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVote", submissionID, validatorID)
+	ret0, _ := ret[0].(*models.CouncilVote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVote indicates an expected call of GetVote.
+func (mr *MockSubmissionRepoMockRecorder) GetVote(submissionID, validatorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVote", reflect.TypeOf((*MockSubmissionRepo)(nil).GetVote), submissionID, validatorID)
+}
+
+// ListExpired mocks base method.
+func (m *MockSubmissionRepo) ListExpired(now time.Time) ([]models.PendingSubmission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpired", now)
+	ret0, _ := ret[0].([]models.PendingSubmission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpired indicates an expected call of ListExpired.
+func (mr *MockSubmissionRepoMockRecorder) ListExpired(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpired", reflect.TypeOf((*MockSubmissionRepo)(nil).ListExpired), now)
+}
+
+// ListOpen mocks base method.
+func (m *MockSubmissionRepo) ListOpen(limit int) ([]models.PendingSubmission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpen", limit)
+	ret0, _ := ret[0].([]models.PendingSubmission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpen indicates an expected call of ListOpen.
+func (mr *MockSubmissionRepoMockRecorder) ListOpen(limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpen", reflect.TypeOf((*MockSubmissionRepo)(nil).ListOpen), limit)
+}
+
+// ListQueueForValidator mocks base method.
+func (m *MockSubmissionRepo) ListQueueForValidator(validatorID int64, now time.Time) ([]models.PendingSubmission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQueueForValidator", validatorID, now)
+	ret0, _ := ret[0].([]models.PendingSubmission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListQueueForValidator indicates an expected call of ListQueueForValidator.
+func (mr *MockSubmissionRepoMockRecorder) ListQueueForValidator(validatorID, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQueueForValidator", reflect.TypeOf((*MockSubmissionRepo)(nil).ListQueueForValidator), validatorID, now)
+}
+
+// Save mocks base method.
+func (m *MockSubmissionRepo) Save(submission *models.PendingSubmission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", submission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockSubmissionRepoMockRecorder) Save(submission any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSubmissionRepo)(nil).Save), submission)
+}
+
+// SaveValidator mocks base method.
+func (m *MockSubmissionRepo) SaveValidator(validator *models.ValidatorAgent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveValidator", validator)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveValidator indicates an expected call of SaveValidator.
+func (mr *MockSubmissionRepoMockRecorder) SaveValidator(validator any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveValidator", reflect.TypeOf((*MockSubmissionRepo)(nil).SaveValidator), validator)
+}
+
+// MockOutboxRepo is a mock of OutboxRepo interface.
+type MockOutboxRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepoMockRecorder
+	isgomock struct{}
+}
+
+// MockOutboxRepoMockRecorder is the mock recorder for MockOutboxRepo.
+type MockOutboxRepoMockRecorder struct {
+	mock *MockOutboxRepo
+}
+
+// NewMockOutboxRepo creates a new mock instance.
+func NewMockOutboxRepo(ctrl *gomock.Controller) *MockOutboxRepo {
+	mock := &MockOutboxRepo{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepo) EXPECT() *MockOutboxRepoMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockOutboxRepo) Enqueue(event *models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enqueue", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockOutboxRepoMockRecorder) Enqueue(event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockOutboxRepo)(nil).Enqueue), event)
+}
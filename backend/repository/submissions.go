@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// SubmissionRepo loads and persists council submissions, their votes and
+// the validators allowed to cast them.
+type SubmissionRepo interface {
+	GetByID(id int64) (*models.PendingSubmission, error)
+	ListOpen(limit int) ([]models.PendingSubmission, error)
+	ListExpired(now time.Time) ([]models.PendingSubmission, error)
+	ListQueueForValidator(validatorID int64, now time.Time) ([]models.PendingSubmission, error)
+	Create(submission *models.PendingSubmission) error
+	Save(submission *models.PendingSubmission) error
+
+	GetVote(submissionID, validatorID int64) (*models.CouncilVote, error)
+	CreateVote(vote *models.CouncilVote) error
+
+	GetActiveValidator(agentID int64) (*models.ValidatorAgent, error)
+	SaveValidator(validator *models.ValidatorAgent) error
+}
+
+type GormSubmissionRepo struct {
+	db *gorm.DB
+}
+
+func NewGormSubmissionRepo(db *gorm.DB) *GormSubmissionRepo {
+	return &GormSubmissionRepo{db: db}
+}
+
+func (r *GormSubmissionRepo) GetByID(id int64) (*models.PendingSubmission, error) {
+	var submission models.PendingSubmission
+	if err := r.db.First(&submission, id).Error; err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// ListOpen returns submissions without a final status, newest first.
+func (r *GormSubmissionRepo) ListOpen(limit int) ([]models.PendingSubmission, error) {
+	var submissions []models.PendingSubmission
+	err := r.db.Where("final_status IS NULL OR final_status = ''").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// ListExpired returns open submissions whose voting window closed before now.
+func (r *GormSubmissionRepo) ListExpired(now time.Time) ([]models.PendingSubmission, error) {
+	var submissions []models.PendingSubmission
+	err := r.db.Where("(final_status IS NULL OR final_status = '') AND voting_ends_at < ?", now).
+		Find(&submissions).Error
+	return submissions, err
+}
+
+// ListQueueForValidator returns open submissions the validator may still vote
+// on: not their own, not yet voted on, and still inside the voting window.
+func (r *GormSubmissionRepo) ListQueueForValidator(validatorID int64, now time.Time) ([]models.PendingSubmission, error) {
+	var submissions []models.PendingSubmission
+	voted := r.db.Model(&models.CouncilVote{}).Select("submission_id").Where("validator_id = ?", validatorID)
+	err := r.db.Where("final_status IS NULL OR final_status = ''").
+		Where("submitter_agent_id != ?", validatorID).
+		Where("voting_ends_at > ?", now).
+		Where("id NOT IN (?)", voted).
+		Order("created_at ASC").
+		Find(&submissions).Error
+	return submissions, err
+}
+
+func (r *GormSubmissionRepo) Create(submission *models.PendingSubmission) error {
+	return r.db.Create(submission).Error
+}
+
+func (r *GormSubmissionRepo) Save(submission *models.PendingSubmission) error {
+	return r.db.Save(submission).Error
+}
+
+func (r *GormSubmissionRepo) GetVote(submissionID, validatorID int64) (*models.CouncilVote, error) {
+	var vote models.CouncilVote
+	if err := r.db.Where("submission_id = ? AND validator_id = ?", submissionID, validatorID).First(&vote).Error; err != nil {
+		return nil, err
+	}
+	return &vote, nil
+}
+
+func (r *GormSubmissionRepo) CreateVote(vote *models.CouncilVote) error {
+	return r.db.Create(vote).Error
+}
+
+func (r *GormSubmissionRepo) GetActiveValidator(agentID int64) (*models.ValidatorAgent, error) {
+	var validator models.ValidatorAgent
+	if err := r.db.Where("agent_id = ? AND is_active = ?", agentID, true).First(&validator).Error; err != nil {
+		return nil, err
+	}
+	return &validator, nil
+}
+
+func (r *GormSubmissionRepo) SaveValidator(validator *models.ValidatorAgent) error {
+	return r.db.Save(validator).Error
+}
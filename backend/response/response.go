@@ -0,0 +1,155 @@
+// Package response writes error responses in the envelope every endpoint
+// uses:
+//
+//	{"error": {"code": "ALREADY_VOTED", "message": "...", "details": ...}}
+//
+// The code is stable and meant for clients to branch on; the message is for
+// people and may change. Details are optional and specific to the code, e.g.
+// the invalid fields of a VALIDATION_FAILED.
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Code is a machine-readable error code.
+type Code string
+
+// Generic codes, one for each error status the API returns. Used when
+// nothing more specific applies.
+const (
+	CodeBadRequest       Code = "BAD_REQUEST"
+	CodeUnauthorized     Code = "UNAUTHORIZED"
+	CodeForbidden        Code = "FORBIDDEN"
+	CodeNotFound         Code = "NOT_FOUND"
+	CodeMethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	CodeConflict         Code = "CONFLICT"
+	CodeGone             Code = "GONE"
+	CodeTooLarge         Code = "PAYLOAD_TOO_LARGE"
+	CodeUnprocessable    Code = "UNPROCESSABLE"
+	CodeRateLimited      Code = "RATE_LIMITED"
+	CodeInternal         Code = "INTERNAL_ERROR"
+	CodeUnavailable      Code = "SERVICE_UNAVAILABLE"
+)
+
+// Specific codes.
+const (
+	CodeValidationFailed Code = "VALIDATION_FAILED"
+
+	// Authentication and authorization
+	CodeAuthRequired       Code = "AUTH_REQUIRED"
+	CodeInvalidToken       Code = "INVALID_TOKEN"
+	CodeInvalidCredentials Code = "INVALID_CREDENTIALS"
+	CodeInvalidAPIKey      Code = "INVALID_API_KEY"
+	CodeAPIKeyExpired      Code = "API_KEY_EXPIRED"
+	CodeMissingScope       Code = "MISSING_SCOPE"
+	CodeAdminRequired      Code = "ADMIN_REQUIRED"
+	CodeRoleRequired       Code = "ROLE_REQUIRED"
+	CodePasswordChange     Code = "PASSWORD_CHANGE_REQUIRED"
+
+	// Agents
+	CodeAgentNotClaimed   Code = "AGENT_NOT_CLAIMED"
+	CodeAgentDeactivated  Code = "AGENT_DEACTIVATED"
+	CodeAgentSuspended    Code = "AGENT_SUSPENDED"
+	CodeAgentNotFound     Code = "AGENT_NOT_FOUND"
+	CodeAlreadyClaimed    Code = "ALREADY_CLAIMED"
+	CodeInvalidClaimCode  Code = "INVALID_VERIFICATION_CODE"
+	CodeNameTaken         Code = "NAME_TAKEN"
+	CodeNameReserved      Code = "NAME_RESERVED"
+	CodeAlreadyPredicted  Code = "ALREADY_PREDICTED"
+	CodeInsufficientFunds Code = "INSUFFICIENT_BALANCE"
+
+	// Markets
+	CodeMarketNotFound  Code = "MARKET_NOT_FOUND"
+	CodeMarketResolved  Code = "MARKET_RESOLVED"
+	CodeMarketClosed    Code = "MARKET_CLOSED"
+	CodeMarketLocked    Code = "PREDICTIONS_LOCKED"
+	CodeDisputeClosed   Code = "DISPUTE_CLOSED"
+	CodeAlreadyDisputed Code = "ALREADY_DISPUTED"
+
+	// Governance
+	CodeProposalLocked Code = "PROPOSAL_LOCKED"
+
+	// Moderation
+	CodeAlreadyReported Code = "ALREADY_REPORTED"
+	CodeContentBlocked  Code = "CONTENT_BLOCKED"
+
+	// Council
+	CodeNotValidator        Code = "NOT_VALIDATOR"
+	CodeAlreadyValidator    Code = "ALREADY_VALIDATOR"
+	CodeAlreadyVoted        Code = "ALREADY_VOTED"
+	CodeSubmissionNotFound  Code = "SUBMISSION_NOT_FOUND"
+	CodeVotingClosed        Code = "VOTING_CLOSED"
+	CodeOwnSubmission       Code = "OWN_SUBMISSION"
+	CodeVerificationFailed  Code = "VERIFICATION_FAILED"
+	CodeIdempotencyMismatch Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyPending  Code = "IDEMPOTENCY_KEY_IN_PROGRESS"
+)
+
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusGone:                  CodeGone,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// StatusCode returns the generic code for an HTTP status.
+func StatusCode(status int) Code {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// ErrorBody is the content of the envelope.
+type ErrorBody struct {
+	Code    Code        `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// ErrorEnvelope is the body of every error response.
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// Error writes an error response with status, code and message. An empty
+// code is replaced by the generic code for status.
+func Error(w http.ResponseWriter, status int, code Code, message string) {
+	ErrorWithDetails(w, status, code, message, nil)
+}
+
+// ErrorWithDetails is Error with code-specific details.
+func ErrorWithDetails(w http.ResponseWriter, status int, code Code, message string, details interface{}) {
+	if code == "" {
+		code = StatusCode(status)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(ErrorEnvelope{Error: ErrorBody{Code: code, Message: message, Details: details}})
+}
+
+// NotFound answers requests for routes that do not exist.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Error(w, http.StatusNotFound, CodeNotFound, "No route for "+r.Method+" "+r.URL.Path)
+}
+
+// MethodNotAllowed answers requests for a route with a method it does not
+// serve.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Error(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path)
+}
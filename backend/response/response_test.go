@@ -0,0 +1,60 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestError_WritesEnvelope(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Error(rec, http.StatusConflict, CodeAlreadyVoted, "Already voted on this submission")
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", got)
+	}
+	var body ErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body is not JSON: %v (%s)", err, rec.Body.String())
+	}
+	if body.Error.Code != CodeAlreadyVoted || body.Error.Message != "Already voted on this submission" {
+		t.Fatalf("unexpected envelope: %+v", body.Error)
+	}
+}
+
+func TestError_EmptyCodeFallsBackToStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   Code
+	}{
+		{http.StatusNotFound, CodeNotFound},
+		{http.StatusTooManyRequests, CodeRateLimited},
+		{http.StatusBadGateway, CodeInternal},
+		{http.StatusTeapot, CodeBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		Error(rec, tt.status, "", "boom")
+		var body ErrorEnvelope
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("status %d: body is not JSON: %v", tt.status, err)
+		}
+		if body.Error.Code != tt.want {
+			t.Errorf("status %d: code = %s, want %s", tt.status, body.Error.Code, tt.want)
+		}
+	}
+}
+
+func TestErrorWithDetails_KeepsDetailsAndHTML(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ErrorWithDetails(rec, http.StatusBadRequest, CodeValidationFailed, "a <b> & c", []string{"question"})
+
+	want := `{"error":{"code":"VALIDATION_FAILED","message":"a <b> & c","details":["question"]}}` + "\n"
+	if rec.Body.String() != want {
+		t.Fatalf("body = %s, want %s", rec.Body.String(), want)
+	}
+}
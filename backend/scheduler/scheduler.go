@@ -0,0 +1,161 @@
+// Package scheduler runs periodic maintenance jobs, such as expiring council
+// submissions and closing proposal voting, inside the backend instead of
+// waiting for someone to hit an endpoint. Every backend instance runs the
+// scheduler; a lease row per job in scheduler_leases makes sure only one
+// instance runs a given job at a time.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobFunc does one run of a job.
+type JobFunc func(ctx context.Context) error
+
+// Job is a function run every Interval. A run may take at most Timeout,
+// which is also how long its lease lasts; it defaults to Interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Timeout  time.Duration
+	Run      JobFunc
+}
+
+// Scheduler runs jobs on tickers, taking the job's lease before each run.
+type Scheduler struct {
+	db     *gorm.DB
+	holder string
+	jobs   []Job
+
+	now func() time.Time
+}
+
+// New returns a scheduler whose leases are held under a name unique to this
+// process.
+func New(db *gorm.DB) *Scheduler {
+	return &Scheduler{
+		db:     db,
+		holder: holderName(),
+		now:    time.Now,
+	}
+}
+
+// Every adds a job run every interval.
+func (s *Scheduler) Every(name string, interval time.Duration, run JobFunc) {
+	s.Add(Job{Name: name, Interval: interval, Run: run})
+}
+
+// Add adds job. Jobs must be added before Run.
+func (s *Scheduler) Add(job Job) {
+	if job.Timeout <= 0 {
+		job.Timeout = job.Interval
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Run runs every job once immediately and then every Interval until ctx is
+// cancelled. It returns once all jobs have stopped.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx, job); err != nil && ctx.Err() == nil {
+			log.Printf("scheduler: %s failed: %v", job.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs job if this instance can take its lease, and reports whether
+// it ran. The lease is released afterwards so the next tick on any instance
+// can take it.
+func (s *Scheduler) RunOnce(ctx context.Context, job Job) (bool, error) {
+	acquired, err := s.acquire(ctx, job.Name, job.Timeout)
+	if err != nil || !acquired {
+		return false, err
+	}
+	defer s.release(job.Name)
+
+	runCtx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+	return true, job.Run(runCtx)
+}
+
+// acquire takes the named lease for ttl if nobody else holds an unexpired
+// one. Creating the row and taking over an expired lease are both single
+// statements, so two instances racing for the lease cannot both win.
+func (s *Scheduler) acquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	db := s.db.WithContext(ctx)
+	now := s.now()
+	lease := models.SchedulerLease{Name: name, Holder: s.holder, ExpiresAt: now.Add(ttl)}
+
+	created := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&lease)
+	if created.Error != nil {
+		return false, fmt.Errorf("create lease %s: %w", name, created.Error)
+	}
+	if created.RowsAffected == 1 {
+		return true, nil
+	}
+
+	taken := db.Model(&models.SchedulerLease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", name, s.holder, now).
+		Updates(map[string]interface{}{"holder": s.holder, "expires_at": lease.ExpiresAt})
+	if taken.Error != nil {
+		return false, fmt.Errorf("take lease %s: %w", name, taken.Error)
+	}
+	return taken.RowsAffected == 1, nil
+}
+
+// release expires the named lease if this instance still holds it. It uses
+// its own context so a cancelled run still gives the lease back.
+func (s *Scheduler) release(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.db.WithContext(ctx).Model(&models.SchedulerLease{}).
+		Where("name = ? AND holder = ?", name, s.holder).
+		Update("expires_at", s.now()).Error
+	if err != nil {
+		log.Printf("scheduler: release lease %s: %v", name, err)
+	}
+}
+
+// holderName identifies this process in scheduler_leases.
+func holderName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s/%d/%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
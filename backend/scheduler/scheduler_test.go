@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestRunOnce_OnlyOneInstanceHoldsTheLease(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	now := time.Date(2026, 2, 22, 12, 0, 0, 0, time.UTC)
+
+	first, second := New(db), New(db)
+	first.holder, second.holder = "first", "second"
+	first.now = func() time.Time { return now }
+	second.now = func() time.Time { return now }
+
+	runs := 0
+	var secondRan bool
+	job := Job{Name: "expire-submissions", Interval: time.Minute, Timeout: time.Minute}
+	job.Run = func(ctx context.Context) error {
+		runs++
+		// While the first instance is running, the second must not.
+		ran, err := second.RunOnce(ctx, Job{Name: job.Name, Timeout: time.Minute, Run: func(context.Context) error {
+			runs++
+			return nil
+		}})
+		secondRan = ran
+		return err
+	}
+
+	ran, err := first.RunOnce(context.Background(), job)
+	if err != nil || !ran {
+		t.Fatalf("expected first instance to run, ran=%v err=%v", ran, err)
+	}
+	if secondRan || runs != 1 {
+		t.Fatalf("expected only the lease holder to run, runs=%d secondRan=%v", runs, secondRan)
+	}
+
+	// Once released, the next tick on any instance can take the lease.
+	now = now.Add(time.Second)
+	ran, err = second.RunOnce(context.Background(), Job{Name: job.Name, Timeout: time.Minute, Run: func(context.Context) error {
+		return errors.New("boom")
+	}})
+	if !ran || err == nil {
+		t.Fatalf("expected second instance to run and report its error, ran=%v err=%v", ran, err)
+	}
+
+	var lease models.SchedulerLease
+	db.First(&lease, "name = ?", job.Name)
+	if lease.Holder != "second" || lease.ExpiresAt.After(now) {
+		t.Fatalf("expected lease released by second, got %+v", lease)
+	}
+}
+
+func TestAcquire_TakesOverExpiredLease(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	now := time.Date(2026, 2, 22, 12, 0, 0, 0, time.UTC)
+
+	crashed, survivor := New(db), New(db)
+	crashed.holder, survivor.holder = "crashed", "survivor"
+	crashed.now = func() time.Time { return now }
+	survivor.now = func() time.Time { return now }
+
+	// The crashed instance took the lease and never released it.
+	if ok, err := crashed.acquire(context.Background(), "close-proposals", 5*time.Minute); err != nil || !ok {
+		t.Fatalf("acquire: ok=%v err=%v", ok, err)
+	}
+	if ok, _ := survivor.acquire(context.Background(), "close-proposals", 5*time.Minute); ok {
+		t.Fatal("expected unexpired lease to be refused")
+	}
+
+	now = now.Add(6 * time.Minute)
+	if ok, err := survivor.acquire(context.Background(), "close-proposals", 5*time.Minute); err != nil || !ok {
+		t.Fatalf("expected expired lease to be taken over, ok=%v err=%v", ok, err)
+	}
+}
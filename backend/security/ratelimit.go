@@ -5,6 +5,8 @@ import (
 	"sync"
 	"time"
 
+	"socialpredict/response"
+
 	"golang.org/x/time/rate"
 )
 
@@ -70,6 +72,18 @@ type RateLimitConfig struct {
 	GeneralRate     rate.Limit // requests per second for general API
 	GeneralBurst    int        // max burst for general API
 	CleanupInterval time.Duration
+
+	// Public read endpoints. Zero values fall back to the defaults.
+	AnonymousReadRate  rate.Limit // requests per second per IP without a key
+	AnonymousReadBurst int        // max burst per IP without a key
+	ReadKeyRate        rate.Limit // requests per second per read-only key
+	ReadKeyBurst       int        // max burst per read-only key
+	AnalyticsRate      rate.Limit // requests per second per analytics key
+	AnalyticsBurst     int        // max burst per analytics key
+
+	// Bulk exports, per caller. Zero values fall back to the defaults.
+	ExportRate  rate.Limit // exports started per second per caller
+	ExportBurst int        // max burst of exports per caller
 }
 
 // DefaultRateLimitConfig returns sensible default rate limits
@@ -80,6 +94,16 @@ func DefaultRateLimitConfig() RateLimitConfig {
 		GeneralRate:     rate.Every(time.Second),      // 1 request per second
 		GeneralBurst:    10,                           // Allow burst of 10 requests
 		CleanupInterval: 5 * time.Minute,              // Cleanup every 5 minutes
+
+		AnonymousReadRate:  rate.Every(2 * time.Second), // 1 request per 2 seconds
+		AnonymousReadBurst: 5,                           // Allow burst of 5 requests
+		ReadKeyRate:        5,                           // 5 requests per second
+		ReadKeyBurst:       50,                          // Allow burst of 50 requests
+		AnalyticsRate:      25,                          // 25 requests per second
+		AnalyticsBurst:     250,                         // Allow burst of 250 requests
+
+		ExportRate:  rate.Every(10 * time.Second), // 1 export per 10 seconds
+		ExportBurst: 3,                            // Allow burst of 3 exports
 	}
 }
 
@@ -92,7 +116,7 @@ func RateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Handler {
 
 			// Check rate limit
 			if !limiter.GetLimiter(ip).Allow() {
-				http.Error(w, "Rate limit exceeded. Please try again later.", http.StatusTooManyRequests)
+				response.Error(w, http.StatusTooManyRequests, response.CodeRateLimited, "Rate limit exceeded. Please try again later.")
 				return
 			}
 
@@ -110,7 +134,7 @@ func LoginRateLimitMiddleware(limiter *RateLimiter) func(http.Handler) http.Hand
 
 			// Check rate limit
 			if !limiter.GetLimiter(ip).Allow() {
-				http.Error(w, "Too many login attempts. Please try again later.", http.StatusTooManyRequests)
+				response.Error(w, http.StatusTooManyRequests, response.CodeRateLimited, "Too many login attempts. Please try again later.")
 				return
 			}
 
@@ -152,13 +176,34 @@ func getClientIP(r *http.Request) string {
 
 // RateLimitManager manages multiple rate limiters for different endpoints
 type RateLimitManager struct {
-	loginLimiter   *RateLimiter
-	generalLimiter *RateLimiter
+	loginLimiter         *RateLimiter
+	generalLimiter       *RateLimiter
+	anonymousReadLimiter *RateLimiter
+	readKeyLimiter       *RateLimiter
+	analyticsLimiter     *RateLimiter
+	exportLimiter        *RateLimiter
 }
 
 // NewRateLimitManager creates a new rate limit manager with default configuration
 func NewRateLimitManager() *RateLimitManager {
-	config := DefaultRateLimitConfig()
+	return NewCustomRateLimitManager(DefaultRateLimitConfig())
+}
+
+// NewCustomRateLimitManager creates a rate limit manager with custom configuration
+func NewCustomRateLimitManager(config RateLimitConfig) *RateLimitManager {
+	defaults := DefaultRateLimitConfig()
+	if config.AnonymousReadRate == 0 || config.AnonymousReadBurst == 0 {
+		config.AnonymousReadRate, config.AnonymousReadBurst = defaults.AnonymousReadRate, defaults.AnonymousReadBurst
+	}
+	if config.ReadKeyRate == 0 || config.ReadKeyBurst == 0 {
+		config.ReadKeyRate, config.ReadKeyBurst = defaults.ReadKeyRate, defaults.ReadKeyBurst
+	}
+	if config.AnalyticsRate == 0 || config.AnalyticsBurst == 0 {
+		config.AnalyticsRate, config.AnalyticsBurst = defaults.AnalyticsRate, defaults.AnalyticsBurst
+	}
+	if config.ExportRate == 0 || config.ExportBurst == 0 {
+		config.ExportRate, config.ExportBurst = defaults.ExportRate, defaults.ExportBurst
+	}
 
 	return &RateLimitManager{
 		loginLimiter: NewRateLimiter(
@@ -171,20 +216,24 @@ func NewRateLimitManager() *RateLimitManager {
 			config.GeneralBurst,
 			config.CleanupInterval,
 		),
-	}
-}
-
-// NewCustomRateLimitManager creates a rate limit manager with custom configuration
-func NewCustomRateLimitManager(config RateLimitConfig) *RateLimitManager {
-	return &RateLimitManager{
-		loginLimiter: NewRateLimiter(
-			config.LoginRate,
-			config.LoginBurst,
+		anonymousReadLimiter: NewRateLimiter(
+			config.AnonymousReadRate,
+			config.AnonymousReadBurst,
 			config.CleanupInterval,
 		),
-		generalLimiter: NewRateLimiter(
-			config.GeneralRate,
-			config.GeneralBurst,
+		readKeyLimiter: NewRateLimiter(
+			config.ReadKeyRate,
+			config.ReadKeyBurst,
+			config.CleanupInterval,
+		),
+		analyticsLimiter: NewRateLimiter(
+			config.AnalyticsRate,
+			config.AnalyticsBurst,
+			config.CleanupInterval,
+		),
+		exportLimiter: NewRateLimiter(
+			config.ExportRate,
+			config.ExportBurst,
 			config.CleanupInterval,
 		),
 	}
@@ -199,3 +248,43 @@ func (rlm *RateLimitManager) GetLoginMiddleware() func(http.Handler) http.Handle
 func (rlm *RateLimitManager) GetGeneralMiddleware() func(http.Handler) http.Handler {
 	return RateLimitMiddleware(rlm.generalLimiter)
 }
+
+// AllowLogin reports whether a login attempt from ip fits the login limit.
+func (rlm *RateLimitManager) AllowLogin(ip string) bool {
+	return rlm.loginLimiter.GetLimiter(ip).Allow()
+}
+
+// AllowGeneral reports whether a request from ip fits the general limit.
+func (rlm *RateLimitManager) AllowGeneral(ip string) bool {
+	return rlm.generalLimiter.GetLimiter(ip).Allow()
+}
+
+// AllowAnonymousRead reports whether an unauthenticated read from ip fits
+// the anonymous read limit.
+func (rlm *RateLimitManager) AllowAnonymousRead(ip string) bool {
+	return rlm.anonymousReadLimiter.GetLimiter(ip).Allow()
+}
+
+// AllowReadKey reports whether a read with the given read-only key fits the
+// per-key limit. Limits follow the key, not the caller's IP.
+func (rlm *RateLimitManager) AllowReadKey(keyID string) bool {
+	return rlm.readKeyLimiter.GetLimiter(keyID).Allow()
+}
+
+// AllowAnalyticsKey reports whether a read with the given analytics key
+// fits the analytics limit, which is kept apart from the read-only key
+// limit so the two can be tuned independently.
+func (rlm *RateLimitManager) AllowAnalyticsKey(keyID string) bool {
+	return rlm.analyticsLimiter.GetLimiter(keyID).Allow()
+}
+
+// AllowExport reports whether caller, the principal or IP making the
+// request, may start another bulk export.
+func (rlm *RateLimitManager) AllowExport(caller string) bool {
+	return rlm.exportLimiter.GetLimiter(caller).Allow()
+}
+
+// ClientIP returns the address rate limits are keyed on for r.
+func ClientIP(r *http.Request) string {
+	return getClientIP(r)
+}
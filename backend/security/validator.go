@@ -17,18 +17,23 @@ type Validator struct {
 // NewValidator creates a new validator instance with custom rules
 func NewValidator() *Validator {
 	validate := validator.New()
+	RegisterValidations(validate)
 
-	// Register custom validation functions
+	return &Validator{
+		validate: validate,
+	}
+}
+
+// RegisterValidations adds the custom validation rules (username,
+// strong_password, safe_string, market_outcome, positive_amount, market_id)
+// to validate so other validators can share them.
+func RegisterValidations(validate *validator.Validate) {
 	validate.RegisterValidation("username", validateUsername)
 	validate.RegisterValidation("strong_password", validateStrongPassword)
 	validate.RegisterValidation("safe_string", validateSafeString)
 	validate.RegisterValidation("market_outcome", validateMarketOutcome)
 	validate.RegisterValidation("positive_amount", validatePositiveAmount)
 	validate.RegisterValidation("market_id", validateMarketID)
-
-	return &Validator{
-		validate: validate,
-	}
 }
 
 // ValidateStruct validates a struct using the configured validator
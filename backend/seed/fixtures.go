@@ -0,0 +1,458 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"text/tabwriter"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/services/scoring"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+// FixturePrefix starts the name of every agent SeedFixtures creates.
+const FixturePrefix = "seed-"
+
+// ErrFixturesExist is returned when the fixtures were already seeded.
+var ErrFixturesExist = errors.New("fixtures already seeded")
+
+// ErrNoAdmin is returned when there is no admin user to own the fixture
+// agents and their markets; SeedUsers creates it.
+var ErrNoAdmin = errors.New("the admin user must exist before seeding fixtures")
+
+// FixtureOptions sizes the development dataset. Zero values take the
+// defaults. The same Seed gives the same dataset, apart from API keys.
+type FixtureOptions struct {
+	Agents     int   `json:"agents"`
+	Markets    int   `json:"markets"`
+	Validators int   `json:"validators"`
+	Proposals  int   `json:"proposals"`
+	Seed       int64 `json:"seed"`
+}
+
+// DefaultFixtureOptions fill any option left unset.
+var DefaultFixtureOptions = FixtureOptions{
+	Agents:     20,
+	Markets:    12,
+	Validators: 5,
+	Proposals:  3,
+	Seed:       1,
+}
+
+// Fixture limits keep an accidental request from filling the database.
+const (
+	MaxFixtureAgents    = 200
+	MaxFixtureMarkets   = 200
+	MaxFixtureProposals = 50
+)
+
+// OrDefaults returns o with unset options taken from DefaultFixtureOptions.
+func (o FixtureOptions) OrDefaults() FixtureOptions {
+	if o.Agents <= 0 {
+		o.Agents = DefaultFixtureOptions.Agents
+	}
+	if o.Markets <= 0 {
+		o.Markets = DefaultFixtureOptions.Markets
+	}
+	if o.Validators <= 0 {
+		o.Validators = DefaultFixtureOptions.Validators
+	}
+	if o.Proposals <= 0 {
+		o.Proposals = DefaultFixtureOptions.Proposals
+	}
+	if o.Seed == 0 {
+		o.Seed = DefaultFixtureOptions.Seed
+	}
+	return o
+}
+
+// Validate reports options outside the fixture limits.
+func (o FixtureOptions) Validate() error {
+	switch {
+	case o.Agents > MaxFixtureAgents:
+		return fmt.Errorf("agents must be at most %d", MaxFixtureAgents)
+	case o.Markets > MaxFixtureMarkets:
+		return fmt.Errorf("markets must be at most %d", MaxFixtureMarkets)
+	case o.Validators > o.Agents:
+		return fmt.Errorf("validators must not exceed agents (%d)", o.Agents)
+	case o.Proposals > MaxFixtureProposals:
+		return fmt.Errorf("proposals must be at most %d", MaxFixtureProposals)
+	}
+	return nil
+}
+
+// FixtureAgent is a seeded agent with the API key to act as it.
+type FixtureAgent struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	APIKey    string `json:"apiKey"`
+	Claimed   bool   `json:"claimed"`
+	Validator bool   `json:"validator"`
+}
+
+// FixtureResult is what SeedFixtures created.
+type FixtureResult struct {
+	Agents          []FixtureAgent `json:"agents"`
+	Markets         int            `json:"markets"`
+	ResolvedMarkets int            `json:"resolvedMarkets"`
+	Predictions     int            `json:"predictions"`
+	Validators      int            `json:"validators"`
+	Proposals       int            `json:"proposals"`
+}
+
+var fixtureCategories = []string{"politics", "crypto", "sports", "technology", "science", "economics"}
+
+// fixtureQuestions are market questions by category; %d is a year.
+var fixtureQuestions = map[string][]string{
+	"politics":   {"Will turnout in the %d general election exceed 65%%?", "Will the incumbent party hold the senate after %d?"},
+	"crypto":     {"Will BTC close %d above its all-time high?", "Will ETH staking exceed 40%% of supply by the end of %d?"},
+	"sports":     {"Will the defending champions reach the %d final?", "Will a new world record be set in the 100m in %d?"},
+	"technology": {"Will a 2nm chip ship in consumer phones in %d?", "Will any browser pass 70%% market share in %d?"},
+	"science":    {"Will a crewed lunar landing happen by the end of %d?", "Will global mean temperature set a new record in %d?"},
+	"economics":  {"Will US CPI inflation be below 3%% at the end of %d?", "Will the ECB cut rates more than twice in %d?"},
+}
+
+var fixtureReasoning = []string{
+	"Base rates from the last decade favour this outcome, and recent polling has not moved enough to change that.",
+	"Momentum has shifted over the past month; the leading indicators point the other way from the consensus.",
+	"Gut call. Low confidence.",
+	"Historical data suggests regression to the mean. Adjusting for seasonality, the trend still holds, though the sample is small.",
+	"Market structure and liquidity make a sharp move unlikely before resolution; I weight the status quo heavily.",
+	"Expert forecasts are split, so I lean on the reference class of similar events, which resolved this way about two thirds of the time.",
+}
+
+var fixtureNames = []string{"oracle", "augur", "sibyl", "pythia", "seer", "delphi", "vizier", "cassandra", "haruspex", "almanac"}
+
+var fixtureFrameworks = []string{"langchain", "autogen", "crewai", "custom"}
+
+var fixtureProposals = []struct {
+	Title string
+	Type  models.ProposalType
+}{
+	{"Show calibration curves on agent profiles", models.ProposalTypeFeature},
+	{"Weight council votes by validator score", models.ProposalTypeGovernance},
+	{"Fix stale consensus after prediction edits", models.ProposalTypeBugfix},
+	{"Add a sports results oracle", models.ProposalTypeIntegration},
+	{"Cache leaderboard pages", models.ProposalTypeImprovement},
+}
+
+// SeedFixtures fills the database with a realistic development dataset:
+// agents of varied skill, most claimed by the admin user, open and resolved
+// markets across categories, predictions with mixed confidence, validators
+// and active proposals. Agent scores are then recomputed from the
+// predictions. It refuses to run twice.
+func SeedFixtures(ctx context.Context, db *gorm.DB, opts FixtureOptions, now time.Time) (*FixtureResult, error) {
+	opts = opts.OrDefaults()
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var existing int64
+	if err := db.WithContext(ctx).Unscoped().Model(&models.Agent{}).Where("name LIKE ?", FixturePrefix+"%").Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrFixturesExist
+	}
+	var admin models.User
+	if err := db.WithContext(ctx).Where("username = ?", "admin").First(&admin).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoAdmin
+	} else if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	result := &FixtureResult{}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		agents, skills, err := seedAgents(tx, rng, opts, admin.ID, now, result)
+		if err != nil {
+			return err
+		}
+		if err := seedValidators(tx, rng, agents, opts.Validators, result); err != nil {
+			return err
+		}
+		if err := seedMarkets(tx, rng, agents, skills, opts.Markets, now, result); err != nil {
+			return err
+		}
+		return seedProposals(tx, rng, agents, opts.Proposals, now, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := scoring.RecomputeAll(ctx, db); err != nil {
+		return nil, fmt.Errorf("recompute scores: %w", err)
+	}
+	return result, nil
+}
+
+// seedAgents creates the agents with their API keys. Each gets a skill,
+// the chance it calls a resolved market right; three in four are claimed.
+func seedAgents(tx *gorm.DB, rng *rand.Rand, opts FixtureOptions, ownerID int64, now time.Time, result *FixtureResult) ([]models.Agent, []float64, error) {
+	agents := make([]models.Agent, 0, opts.Agents)
+	skills := make([]float64, 0, opts.Agents)
+	for i := 0; i < opts.Agents; i++ {
+		apiKey, err := models.GenerateAPIKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		claimToken, err := models.GenerateClaimToken()
+		if err != nil {
+			return nil, nil, err
+		}
+		lastActive := now.Add(-time.Duration(rng.Intn(72)) * time.Hour)
+		agent := models.Agent{
+			Name:          fmt.Sprintf("%s%s-%d", FixturePrefix, fixtureNames[i%len(fixtureNames)], i+1),
+			Description:   "Development fixture agent.",
+			APIKey:        apiKey,
+			ClaimToken:    claimToken,
+			FrameworkType: fixtureFrameworks[rng.Intn(len(fixtureFrameworks))],
+			Reputation:    0.5,
+			IsActive:      true,
+			LastActiveAt:  &lastActive,
+		}
+		if i%4 != 3 {
+			claimedAt := now.Add(-time.Duration(30+rng.Intn(60)) * 24 * time.Hour)
+			agent.IsClaimed = true
+			agent.OwnerUserID = &ownerID
+			agent.ClaimedAt = &claimedAt
+		}
+		if err := tx.Create(&agent).Error; err != nil {
+			return nil, nil, fmt.Errorf("create agent %s: %w", agent.Name, err)
+		}
+		key := models.NewAgentAPIKey(agent.ID, "default", apiKey)
+		if err := tx.Create(&key).Error; err != nil {
+			return nil, nil, err
+		}
+		agents = append(agents, agent)
+		skills = append(skills, 0.35+0.5*rng.Float64())
+		result.Agents = append(result.Agents, FixtureAgent{ID: agent.ID, Name: agent.Name, APIKey: apiKey, Claimed: agent.IsClaimed})
+	}
+	return agents, skills, nil
+}
+
+// seedValidators makes the first claimed agents validators with a record
+// of council votes behind them.
+func seedValidators(tx *gorm.DB, rng *rand.Rand, agents []models.Agent, count int, result *FixtureResult) error {
+	for i := range agents {
+		if result.Validators == count {
+			break
+		}
+		if !agents[i].IsClaimed {
+			continue
+		}
+		judged := int64(5 + rng.Intn(40))
+		correct := judged * int64(50+rng.Intn(45)) / 100
+		validator := models.ValidatorAgent{
+			AgentID:            agents[i].ID,
+			IsActive:           true,
+			TotalValidations:   judged,
+			CorrectValidations: correct,
+			ValidatorScore:     models.ValidatorScoreOf(correct, judged),
+		}
+		if err := tx.Create(&validator).Error; err != nil {
+			return err
+		}
+		result.Agents[i].Validator = true
+		result.Validators++
+	}
+	return nil
+}
+
+// seedMarkets creates the markets, a third of them resolved, and has some
+// of the agents predict on each. On resolved markets an agent calls it
+// right with the chance of its skill.
+func seedMarkets(tx *gorm.DB, rng *rand.Rand, agents []models.Agent, skills []float64, count int, now time.Time, result *FixtureResult) error {
+	for i := 0; i < count; i++ {
+		category := fixtureCategories[i%len(fixtureCategories)]
+		questions := fixtureQuestions[category]
+		creator := agents[rng.Intn(len(agents))]
+		resolved := i%3 == 2
+
+		created := now.Add(-time.Duration(1+rng.Intn(10)) * 24 * time.Hour)
+		resolvesAt := now.Add(time.Duration(3+rng.Intn(60)) * 24 * time.Hour)
+		if resolved {
+			created = now.Add(-time.Duration(30+rng.Intn(30)) * 24 * time.Hour)
+			resolvesAt = now.Add(-time.Duration(1+rng.Intn(20)) * 24 * time.Hour)
+		}
+		truth := "NO"
+		if rng.Float64() < 0.5 {
+			truth = "YES"
+		}
+
+		market := models.Market{
+			QuestionTitle:      fmt.Sprintf(questions[(i/len(fixtureCategories))%len(questions)], now.Year()+i/(2*len(fixtureCategories))),
+			Description:        fmt.Sprintf("[Created by AI Agent: %s]\n\nDevelopment fixture market.", creator.Name),
+			OutcomeType:        "BINARY",
+			ResolutionDateTime: resolvesAt,
+			InitialProbability: 0.5,
+			CreatorUsername:    "admin",
+			MarketType:         "standard",
+			Visibility:         models.MarketPublic,
+			Category:           category,
+		}
+		market.CreatedAt = created
+		market.SetCreatedBy(models.AgentActor(creator.ID))
+		if resolved {
+			market.IsResolved = true
+			market.ResolutionResult = truth
+			market.FinalResolutionDateTime = resolvesAt
+		}
+		if err := tx.Create(&market).Error; err != nil {
+			return fmt.Errorf("create market: %w", err)
+		}
+
+		var predictions int64
+		for a := range agents {
+			if rng.Float64() > 0.4+0.4*skills[a] {
+				continue
+			}
+			predictedAt := created.Add(time.Duration(rng.Int63n(int64(minDuration(now, resolvesAt).Sub(created)))))
+			prediction := fixturePrediction(rng, agents[a].ID, market.ID, truth, skills[a], predictedAt)
+			if resolved {
+				prediction.Score(truth)
+				resolvedAt := resolvesAt
+				scored := prediction.Revision
+				prediction.ResolvedAt = &resolvedAt
+				prediction.ScoredRevision = &scored
+			}
+			if err := tx.Create(&prediction).Error; err != nil {
+				return fmt.Errorf("create prediction: %w", err)
+			}
+			revision := models.NewPredictionRevision(&prediction, predictedAt)
+			if err := tx.Create(&revision).Error; err != nil {
+				return err
+			}
+			predictions++
+		}
+
+		if err := tx.Model(&market).Update("total_predictions", predictions).Error; err != nil {
+			return err
+		}
+		result.Markets++
+		result.Predictions += int(predictions)
+		if resolved {
+			result.ResolvedMarkets++
+		}
+	}
+	return nil
+}
+
+// fixturePrediction is an agent's call on a market: right with the chance
+// of its skill, at a confidence that rises with its skill, give or take.
+func fixturePrediction(rng *rand.Rand, agentID, marketID int64, truth string, skill float64, at time.Time) models.Prediction {
+	outcome := truth
+	if rng.Float64() > skill {
+		outcome = map[string]string{"YES": "NO", "NO": "YES"}[truth]
+	}
+	confidence := 50 + 45*skill*rng.Float64() + 10*rng.Float64()
+	if confidence > 99 {
+		confidence = 99
+	}
+	reasoning := fixtureReasoning[rng.Intn(len(fixtureReasoning))]
+	return models.Prediction{
+		AgentID:        agentID,
+		MarketID:       marketID,
+		Outcome:        outcome,
+		Confidence:     float64(int(confidence)),
+		Reasoning:      reasoning,
+		ReasoningScore: models.ReasoningHeuristic(reasoning),
+		Revision:       1,
+		Upvotes:        int64(rng.Intn(8)),
+		Downvotes:      int64(rng.Intn(3)),
+		PredictedAt:    at,
+	}
+}
+
+// seedProposals creates proposals still open for voting, each from a
+// claimed agent.
+func seedProposals(tx *gorm.DB, rng *rand.Rand, agents []models.Agent, count int, now time.Time, result *FixtureResult) error {
+	rules := setup.DefaultGovernance
+	if cfg := setup.EconomicsConfig(); cfg != nil {
+		rules = cfg.Governance.OrDefaults()
+	}
+	var proposers []models.Agent
+	for _, agent := range agents {
+		if agent.IsClaimed {
+			proposers = append(proposers, agent)
+		}
+	}
+	if len(proposers) == 0 {
+		return nil
+	}
+
+	for i := 0; i < count; i++ {
+		template := fixtureProposals[i%len(fixtureProposals)]
+		created := now.Add(-time.Duration(rng.Intn(48)) * time.Hour)
+		proposal := models.Proposal{
+			Title:           template.Title,
+			Description:     "Development fixture proposal.",
+			Type:            template.Type,
+			Priority:        []string{"low", "medium", "high"}[rng.Intn(3)],
+			Complexity:      []string{"simple", "moderate", "complex"}[rng.Intn(3)],
+			ProposerAgentID: proposers[rng.Intn(len(proposers))].ID,
+			Status:          models.ProposalStatusActive,
+			VoteThreshold:   rules.VoteThreshold,
+			ApprovalPct:     rules.ApprovalPct,
+			VotingMode:      rules.DefaultVotingMode,
+			VotingEndsAt:    created.AddDate(0, 0, rules.DefaultVotingDays),
+			Revision:        1,
+		}
+		if i >= len(fixtureProposals) {
+			proposal.Title = fmt.Sprintf("%s (%d)", template.Title, i/len(fixtureProposals)+1)
+		}
+		proposal.CreatedAt = created
+		if err := tx.Create(&proposal).Error; err != nil {
+			return fmt.Errorf("create proposal: %w", err)
+		}
+		revision := models.NewProposalRevision(&proposal, models.ProposalRevisionCreated, "", created)
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		result.Proposals++
+	}
+	return nil
+}
+
+func minDuration(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// Command runs the seed command line against db, writing the seeded agents
+// and their API keys to out.
+func Command(db *gorm.DB, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(out)
+	var opts FixtureOptions
+	flags.IntVar(&opts.Agents, "agents", DefaultFixtureOptions.Agents, "how many agents to create")
+	flags.IntVar(&opts.Markets, "markets", DefaultFixtureOptions.Markets, "how many markets to create, a third of them resolved")
+	flags.IntVar(&opts.Validators, "validators", DefaultFixtureOptions.Validators, "how many agents to make validators")
+	flags.IntVar(&opts.Proposals, "proposals", DefaultFixtureOptions.Proposals, "how many active proposals to create")
+	flags.Int64Var(&opts.Seed, "seed", DefaultFixtureOptions.Seed, "random seed; the same seed gives the same dataset")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	result, err := SeedFixtures(context.Background(), db, opts, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "seeded %d agents, %d markets (%d resolved), %d predictions, %d validators, %d proposals\n\n",
+		len(result.Agents), result.Markets, result.ResolvedMarkets, result.Predictions, result.Validators, result.Proposals)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCLAIMED\tVALIDATOR\tAPI KEY")
+	for _, a := range result.Agents {
+		fmt.Fprintf(w, "%d\t%s\t%t\t%t\t%s\n", a.ID, a.Name, a.Claimed, a.Validator, a.APIKey)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,74 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestSeedFixtures_CreatesScoredDataset(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	admin := modelstesting.GenerateUser("admin", 0)
+	if err := db.Create(&admin).Error; err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+
+	opts := FixtureOptions{Agents: 8, Markets: 6, Validators: 2, Proposals: 2}
+	result, err := SeedFixtures(context.Background(), db, opts, time.Now())
+	if err != nil {
+		t.Fatalf("SeedFixtures: %v", err)
+	}
+	if len(result.Agents) != 8 || result.Markets != 6 || result.ResolvedMarkets != 2 ||
+		result.Validators != 2 || result.Proposals != 2 || result.Predictions == 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	var scored int64
+	db.Model(&models.Prediction{}).Where("is_resolved = ? AND brier_score IS NOT NULL", true).Count(&scored)
+	if scored == 0 {
+		t.Fatalf("expected scored predictions on the resolved markets")
+	}
+	var active int64
+	db.Model(&models.Proposal{}).Where("status = ?", models.ProposalStatusActive).Count(&active)
+	if active != 2 {
+		t.Fatalf("expected 2 active proposals, got %d", active)
+	}
+	var withPredictions int64
+	db.Model(&models.Agent{}).Where("total_predictions > 0").Count(&withPredictions)
+	if withPredictions == 0 {
+		t.Fatalf("expected agent counters recomputed from the predictions")
+	}
+
+	if _, err := SeedFixtures(context.Background(), db, opts, time.Now()); !errors.Is(err, ErrFixturesExist) {
+		t.Fatalf("expected ErrFixturesExist on a second run, got %v", err)
+	}
+}
+
+func TestSeedFixtures_RequiresAdmin(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	if _, err := SeedFixtures(context.Background(), db, FixtureOptions{}, time.Now()); !errors.Is(err, ErrNoAdmin) {
+		t.Fatalf("expected ErrNoAdmin, got %v", err)
+	}
+}
+
+func TestCommand_PrintsAgentKeys(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	admin := modelstesting.GenerateUser("admin", 0)
+	if err := db.Create(&admin).Error; err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Command(db, []string{"-agents", "3", "-markets", "2", "-validators", "1", "-proposals", "1"}, &out); err != nil {
+		t.Fatalf("Command: %v", err)
+	}
+	if !strings.Contains(out.String(), "seeded 3 agents") || !strings.Contains(out.String(), FixturePrefix) {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
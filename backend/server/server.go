@@ -10,22 +10,35 @@ import (
 	predictionshandlers "socialpredict/handlers/predictions"
 	verificationhandlers "socialpredict/handlers/verification"
 	governancehandlers "socialpredict/handlers/governance"
+	graphqlhandlers "socialpredict/handlers/graphql"
 	betshandlers "socialpredict/handlers/bets"
 	buybetshandlers "socialpredict/handlers/bets/buying"
 	sellbetshandlers "socialpredict/handlers/bets/selling"
 	"socialpredict/handlers/cms/homepage"
 	cmshomehttp "socialpredict/handlers/cms/homepage/http"
+	eventshandlers "socialpredict/handlers/events"
+	exporthandlers "socialpredict/handlers/export"
 	marketshandlers "socialpredict/handlers/markets"
 	metricshandlers "socialpredict/handlers/metrics"
+	moderationhandlers "socialpredict/handlers/moderation"
+	notificationshandlers "socialpredict/handlers/notifications"
+	readkeyshandlers "socialpredict/handlers/readkeys"
 	positions "socialpredict/handlers/positions"
+	serieshandlers "socialpredict/handlers/series"
 	setuphandlers "socialpredict/handlers/setup"
 	statshandlers "socialpredict/handlers/stats"
+	teamshandlers "socialpredict/handlers/teams"
+	templateshandlers "socialpredict/handlers/templates"
 	usershandlers "socialpredict/handlers/users"
 	usercredit "socialpredict/handlers/users/credit"
 	privateuser "socialpredict/handlers/users/privateuser"
 	"socialpredict/handlers/users/publicuser"
 	"socialpredict/middleware"
+	"socialpredict/models"
+	"socialpredict/outbox"
+	"socialpredict/response"
 	"socialpredict/security"
+	"socialpredict/services/platformconfig"
 	"socialpredict/setup"
 	"socialpredict/util"
 	"strconv"
@@ -33,6 +46,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
+	"gorm.io/gorm"
 )
 
 // CORS helpers configured via environment variables
@@ -81,8 +95,8 @@ func buildCORSFromEnv() *cors.Cors {
 	}
 	origins := getListEnv("CORS_ALLOW_ORIGINS", "*")
 	methods := getListEnv("CORS_ALLOW_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-	headers := getListEnv("CORS_ALLOW_HEADERS", "Content-Type,Authorization")
-	expose := getListEnv("CORS_EXPOSE_HEADERS", "")
+	headers := getListEnv("CORS_ALLOW_HEADERS", "Content-Type,Authorization,"+middleware.IdempotencyKeyHeader)
+	expose := getListEnv("CORS_EXPOSE_HEADERS", middleware.IdempotentReplayedHeader)
 	allowCreds := getBoolEnv("CORS_ALLOW_CREDENTIALS", false)
 	maxAge := getIntEnv("CORS_MAX_AGE", 600)
 
@@ -96,88 +110,171 @@ func buildCORSFromEnv() *cors.Cors {
 	})
 }
 
-func Start() {
-	// Initialize security service
-	securityService := security.NewSecurityService()
-
-	// CORS handler (configurable via env)
-	c := buildCORSFromEnv()
-
-	// Initialize mux router
+// NewRouter builds the full API router against db. It is separate from Start
+// so integration tests can drive the real routes through httptest. The
+// read-heavy endpoints (leaderboards, market listings, consensus and stats)
+// query readDB instead, which may be a read replica; nothing that writes
+// uses it.
+//
+// Every route is registered with the policy it requires: who may call it,
+// which agent key scopes it needs, which rate limit applies and whether it
+// honours Idempotency-Key. The policy chain enforces it before the handler
+// runs, and GET /v0/rules publishes it.
+func NewRouter(db, readDB *gorm.DB, securityService *security.SecurityService, bus *outbox.Bus) *mux.Router {
 	router := mux.NewRouter()
+	router.NotFoundHandler = http.HandlerFunc(response.NotFound)
+	router.MethodNotAllowedHandler = http.HandlerFunc(response.MethodNotAllowed)
+
+	// The public read tier: markets, consensus, leaderboards, stats and agent
+	// profiles need no credentials. Anonymous callers, read-only keys,
+	// analytics keys, agents and users are each rate limited on their own
+	// tier; see the read policy.
+	readMeter := middleware.NewReadMeter()
+	routes := middleware.NewRoutes(router, &middleware.Stack{
+		DB:      db,
+		Limits:  securityService.RateManager,
+		Headers: securityService.Headers,
+		Meter:   readMeter,
+	})
 
-	// Define endpoint handlers using Gorilla Mux router
-	// This defines all functions starting with /api/
-
-	// Apply security middleware to all routes
-	securityMiddleware := securityService.SecurityMiddleware()
-	loginSecurityMiddleware := securityService.LoginSecurityMiddleware()
+	public := middleware.Policy{Auth: middleware.AuthNone, Rate: middleware.RateGeneral}
+	read := middleware.Policy{Auth: middleware.AuthOptional, Rate: middleware.RateRead}
+	export := middleware.Policy{Auth: middleware.AuthOptional, Rate: middleware.RateExport}
+	login := middleware.Policy{Auth: middleware.AuthNone, Rate: middleware.RateLogin}
+	user := middleware.Policy{Auth: middleware.AuthUser, Rate: middleware.RateGeneral}
+	admin := middleware.Policy{Auth: middleware.AuthAdmin, Rate: middleware.RateGeneral}
+	moderator := middleware.Policy{Auth: middleware.AuthAdmin, Roles: []string{models.RoleModerator}, Rate: middleware.RateGeneral}
+	operator := middleware.Policy{Auth: middleware.AuthAdmin, Roles: []string{models.RoleOperator}, Rate: middleware.RateGeneral}
+	agent := func(scopes ...string) middleware.Policy {
+		return middleware.Policy{Auth: middleware.AuthAgent, Scopes: scopes, Rate: middleware.RateGeneral}
+	}
+	claimedAgent := func(scopes ...string) middleware.Policy {
+		return middleware.Policy{Auth: middleware.AuthClaimedAgent, Scopes: scopes, Rate: middleware.RateGeneral}
+	}
+	agentOrUser := func(scopes ...string) middleware.Policy {
+		return middleware.Policy{Auth: middleware.AuthAgentOrUser, Scopes: scopes, Rate: middleware.RateGeneral}
+	}
+	idempotent := func(p middleware.Policy) middleware.Policy {
+		p.Idempotent = true
+		return p
+	}
 
-	// Health check endpoint for Railway/Docker
+	// Health check endpoint for Railway/Docker, outside any policy so probes
+	// are never rate limited
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"status":"healthy","service":"aiswarm-backend"}`))
 	}).Methods("GET")
 
-	router.HandleFunc("/v0/home", handlers.HomeHandler).Methods("GET")
-	router.Handle("/v0/login", loginSecurityMiddleware(http.HandlerFunc(middleware.LoginHandler))).Methods("POST")
+	routes.HandleFunc("GET", "/v0/home", public, handlers.HomeHandler)
+	routes.HandleFunc("POST", "/v0/login", login, middleware.LoginHandler)
 
 	// application setup and stats information
-	router.Handle("/v0/setup", securityMiddleware(http.HandlerFunc(setuphandlers.GetSetupHandler(setup.LoadEconomicsConfig)))).Methods("GET")
-	router.Handle("/v0/setup/frontend", securityMiddleware(http.HandlerFunc(setuphandlers.GetFrontendSetupHandler(setup.LoadEconomicsConfig)))).Methods("GET")
-	router.Handle("/v0/stats", securityMiddleware(http.HandlerFunc(statshandlers.StatsHandler()))).Methods("GET")
-	router.Handle("/v0/system/metrics", securityMiddleware(http.HandlerFunc(metricshandlers.GetSystemMetricsHandler))).Methods("GET")
-	router.Handle("/v0/global/leaderboard", securityMiddleware(http.HandlerFunc(metricshandlers.GetGlobalLeaderboardHandler))).Methods("GET")
+	routes.HandleFunc("GET", "/v0/setup", public, setuphandlers.GetSetupHandler(setup.LoadEconomicsConfig))
+	routes.HandleFunc("GET", "/v0/setup/frontend", public, setuphandlers.GetFrontendSetupHandler(setup.LoadEconomicsConfig))
+	// The rules in effect include parameters changed at runtime.
+	effectiveConfig := func() *setup.EconomicConfig { return platformconfig.Current(db) }
+	routes.Handle("GET", "/v0/rules", public, setuphandlers.GetRulesHandler(effectiveConfig, map[string]interface{}{
+		"POST /v0/agents/register":                              agentshandlers.RegisterRequest{},
+		"POST /v0/agents/claim/{claimToken}":                    agentshandlers.ClaimRequest{},
+		"POST /v0/agents/create":                                agentshandlers.AgentCreateMarketRequest{},
+		"POST /v0/agents/bet":                                   agentshandlers.AgentBetRequest{},
+		"PUT /v0/agents/webhook":                                notificationshandlers.WebhookRequest{},
+		"POST /v0/agents/keys/rotate":                           agentshandlers.RotateKeyRequest{},
+		"PUT /v0/agents/model-card":                             agentshandlers.ModelCardRequest{},
+		"POST /v0/agents/rename":                                agentshandlers.RenameRequest{},
+		"PUT /v0/admin/parameters/{name}":                       adminhandlers.ParameterRequest{},
+		"PUT /v0/admin/verification/rules/{name}":               models.VerificationRuleRequest{},
+		"POST /v0/admin/reserved-names":                         adminhandlers.ReservedNameRequest{},
+		"PUT /v0/admin/roles/{username}":                        adminhandlers.RoleRequest{},
+		"POST /v0/admin/agent/{id}/suspensions":                 adminhandlers.SuspendAgentRequest{},
+		"POST /v0/admin/moderation/{id}/review":                 moderationhandlers.ReviewRequest{},
+		"POST /v0/report":                                       moderationhandlers.ReportRequest{},
+		"POST /v0/admin/scoring/what-if":                        adminhandlers.WhatIfRequest{},
+		"POST /v0/predict":                                      models.PredictionRequest{},
+		"POST /v0/sandbox/predict":                              agentshandlers.SandboxPredictionRequest{},
+		"POST /v0/prediction/{id}/vote":                         models.VoteRequest{},
+		"POST /v0/prediction/{id}/comments":                     models.CommentRequest{},
+		"PUT /v0/prediction/{id}/comments/{commentId}":          models.CommentRequest{},
+		"POST /v0/submit/market":                                verificationhandlers.MarketPayload{},
+		"POST /v0/submit/market/{submissionId}/revise":          verificationhandlers.MarketPayload{},
+		"POST /v0/submit/prediction":                            verificationhandlers.PredictionPayload{},
+		"POST /v0/submit/template":                              verificationhandlers.TemplatePayload{},
+		"POST /v0/council/vote/{submissionId}":                  verificationhandlers.CouncilVoteRequest{},
+		"PUT /v0/council/vote/{submissionId}":                   verificationhandlers.CouncilVoteRequest{},
+		"POST /v0/submissions/{submissionId}/comments":          verificationhandlers.SubmissionCommentRequest{},
+		"POST /v0/council/request-changes/{submissionId}":       verificationhandlers.ChangeRequest{},
+		"POST /v0/council/reasoning/{predictionId}/review":      models.ReasoningReviewRequest{},
+		"POST /v0/council/resolutions/{requestId}/vote":         verificationhandlers.ResolutionVoteRequest{},
+		"POST /v0/markets/{marketId}/disputes":                  verificationhandlers.ResolutionDisputeRequest{},
+		"POST /v0/governance/proposals":                         governancehandlers.CreateProposalRequest{},
+		"POST /v0/governance/proposals/{proposalId}/vote":       governancehandlers.VoteRequest{},
+		"POST /v0/governance/proposals/{proposalId}/comments":   governancehandlers.ProposalCommentRequest{},
+		"PUT /v0/governance/proposals/{proposalId}":             governancehandlers.ProposalEditRequest{},
+		"POST /v0/governance/proposals/{proposalId}/amendments": governancehandlers.ProposalAmendmentRequest{},
+		"PUT /v0/governance/delegation":                         governancehandlers.DelegationRequest{},
+		"POST /v0/teams":                                        models.TeamRequest{},
+		"POST /v0/teams/{teamId}/invites":                       teamshandlers.InviteRequest{},
+		"POST /v0/markets/{id}/resolve":                         marketshandlers.ResolveRequest{},
+		"POST /v0/markets/{id}/invitations":                     marketshandlers.MarketInvitationRequest{},
+		"POST /v0/series":                                       serieshandlers.CreateSeriesRequest{},
+		"POST /v0/series/{seriesId}/markets":                    agentshandlers.AgentCreateMarketRequest{},
+		"POST /v0/markets/{marketId}/closing-bid":               agentshandlers.ClosingBidRequest{},
+	}, routes.Policies))
+	routes.HandleFunc("GET", "/v0/stats", read, statshandlers.StatsHandler())
+	routes.HandleFunc("GET", "/v0/system/metrics", read, metricshandlers.GetSystemMetricsHandler)
+	routes.HandleFunc("GET", "/v0/global/leaderboard", read, metricshandlers.GetGlobalLeaderboardHandler)
 
 	// markets display, market information
-	router.Handle("/v0/markets", securityMiddleware(http.HandlerFunc(marketshandlers.ListMarketsHandler))).Methods("GET")
-	router.Handle("/v0/markets/search", securityMiddleware(http.HandlerFunc(marketshandlers.SearchMarketsHandler))).Methods("GET")
-	router.Handle("/v0/markets/active", securityMiddleware(http.HandlerFunc(marketshandlers.ListActiveMarketsHandler))).Methods("GET")
-	router.Handle("/v0/markets/closed", securityMiddleware(http.HandlerFunc(marketshandlers.ListClosedMarketsHandler))).Methods("GET")
-	router.Handle("/v0/markets/resolved", securityMiddleware(http.HandlerFunc(marketshandlers.ListResolvedMarketsHandler))).Methods("GET")
-	router.Handle("/v0/markets/{marketId}", securityMiddleware(http.HandlerFunc(marketshandlers.MarketDetailsHandler))).Methods("GET")
-	router.Handle("/v0/marketprojection/{marketId}/{amount}/{outcome}/", securityMiddleware(http.HandlerFunc(marketshandlers.ProjectNewProbabilityHandler))).Methods("GET")
+	routes.HandleFunc("GET", "/v0/markets", read, marketshandlers.ListMarketsHandler)
+	routes.HandleFunc("GET", "/v0/markets/search", read, marketshandlers.SearchMarketsHandler)
+	routes.HandleFunc("GET", "/v0/markets/active", read, marketshandlers.ListActiveMarketsHandler)
+	routes.Handle("GET", "/v0/markets/trending", read, marketshandlers.TrendingMarketsHandler(readDB))
+	routes.HandleFunc("GET", "/v0/markets/closed", read, marketshandlers.ListClosedMarketsHandler)
+	routes.HandleFunc("GET", "/v0/markets/resolved", read, marketshandlers.ListResolvedMarketsHandler)
+	routes.HandleFunc("GET", "/v0/categories", read, marketshandlers.CategoriesHandler(readDB))
+	routes.HandleFunc("GET", "/v0/markets/{marketId}", read, marketshandlers.MarketDetailsHandler)
+	routes.HandleFunc("GET", "/v0/marketprojection/{marketId}/{amount}/{outcome}/", read, marketshandlers.ProjectNewProbabilityHandler)
 
 	// handle market positions, get trades
-	router.Handle("/v0/markets/bets/{marketId}", securityMiddleware(http.HandlerFunc(betshandlers.MarketBetsDisplayHandler))).Methods("GET")
-	router.Handle("/v0/markets/positions/{marketId}", securityMiddleware(http.HandlerFunc(positions.MarketDBPMPositionsHandler))).Methods("GET")
-	router.Handle("/v0/markets/positions/{marketId}/{username}", securityMiddleware(http.HandlerFunc(positions.MarketDBPMUserPositionsHandler))).Methods("GET")
-	router.Handle("/v0/markets/leaderboard/{marketId}", securityMiddleware(http.HandlerFunc(marketshandlers.MarketLeaderboardHandler))).Methods("GET")
+	routes.HandleFunc("GET", "/v0/markets/bets/{marketId}", read, betshandlers.MarketBetsDisplayHandler)
+	routes.HandleFunc("GET", "/v0/markets/positions/{marketId}", read, positions.MarketDBPMPositionsHandler)
+	routes.HandleFunc("GET", "/v0/markets/positions/{marketId}/{username}", read, positions.MarketDBPMUserPositionsHandler)
+	routes.HandleFunc("GET", "/v0/markets/leaderboard/{marketId}", read, marketshandlers.MarketLeaderboardHandler)
 
 	// handle public user stuff
-	router.Handle("/v0/userinfo/{username}", securityMiddleware(http.HandlerFunc(publicuser.GetPublicUserResponse))).Methods("GET")
-	router.Handle("/v0/usercredit/{username}", securityMiddleware(http.HandlerFunc(usercredit.GetUserCreditHandler))).Methods("GET")
-	router.Handle("/v0/portfolio/{username}", securityMiddleware(http.HandlerFunc(publicuser.GetPortfolio))).Methods("GET")
-	router.Handle("/v0/users/{username}/financial", securityMiddleware(http.HandlerFunc(usershandlers.GetUserFinancialHandler))).Methods("GET")
+	routes.HandleFunc("GET", "/v0/userinfo/{username}", public, publicuser.GetPublicUserResponse)
+	routes.HandleFunc("GET", "/v0/usercredit/{username}", public, usercredit.GetUserCreditHandler)
+	routes.HandleFunc("GET", "/v0/portfolio/{username}", public, publicuser.GetPortfolio)
+	routes.HandleFunc("GET", "/v0/users/{username}/financial", public, usershandlers.GetUserFinancialHandler)
 
 	// handle private user stuff, display sensitive profile information to customize
-	router.Handle("/v0/privateprofile", securityMiddleware(http.HandlerFunc(privateuser.GetPrivateProfileUserResponse))).Methods("GET")
+	routes.HandleFunc("GET", "/v0/privateprofile", user, privateuser.GetPrivateProfileUserResponse)
 
-	// changing profile stuff - apply security middleware
-	router.Handle("/v0/changepassword", securityMiddleware(http.HandlerFunc(usershandlers.ChangePassword))).Methods("POST")
-	router.Handle("/v0/profilechange/displayname", securityMiddleware(http.HandlerFunc(usershandlers.ChangeDisplayName))).Methods("POST")
-	router.Handle("/v0/profilechange/emoji", securityMiddleware(http.HandlerFunc(usershandlers.ChangeEmoji))).Methods("POST")
-	router.Handle("/v0/profilechange/description", securityMiddleware(http.HandlerFunc(usershandlers.ChangeDescription))).Methods("POST")
-	router.Handle("/v0/profilechange/links", securityMiddleware(http.HandlerFunc(usershandlers.ChangePersonalLinks))).Methods("POST")
+	// changing profile stuff
+	routes.HandleFunc("POST", "/v0/changepassword", user, usershandlers.ChangePassword)
+	routes.HandleFunc("POST", "/v0/profilechange/displayname", user, usershandlers.ChangeDisplayName)
+	routes.HandleFunc("POST", "/v0/profilechange/emoji", user, usershandlers.ChangeEmoji)
+	routes.HandleFunc("POST", "/v0/profilechange/description", user, usershandlers.ChangeDescription)
+	routes.HandleFunc("POST", "/v0/profilechange/links", user, usershandlers.ChangePersonalLinks)
 
 	// handle private user actions such as resolve a market, make a bet, create a market, change profile
-	router.Handle("/v0/resolve/{marketId}", securityMiddleware(http.HandlerFunc(marketshandlers.ResolveMarketHandler))).Methods("POST")
-	router.Handle("/v0/bet", securityMiddleware(http.HandlerFunc(buybetshandlers.PlaceBetHandler(setup.EconomicsConfig)))).Methods("POST")
-	router.Handle("/v0/userposition/{marketId}", securityMiddleware(http.HandlerFunc(usershandlers.UserMarketPositionHandler))).Methods("GET")
-	router.Handle("/v0/sell", securityMiddleware(http.HandlerFunc(sellbetshandlers.SellPositionHandler(setup.EconomicsConfig)))).Methods("POST")
-	router.Handle("/v0/create", securityMiddleware(http.HandlerFunc(marketshandlers.CreateMarketHandler(setup.EconomicsConfig)))).Methods("POST")
-
-	// admin stuff - apply security middleware
-	router.Handle("/v0/admin/createuser", securityMiddleware(http.HandlerFunc(adminhandlers.AddUserHandler(setup.EconomicsConfig)))).Methods("POST")
-
-	// homepage content routes
-	db := util.GetDB()
-	
+	routes.HandleFunc("POST", "/v0/resolve/{marketId}", user, marketshandlers.ResolveMarketHandler)
+	routes.Handle("POST", "/v0/markets/{id}/resolve", agentOrUser(models.ScopeMarkets), marketshandlers.ResolveHandler(db))
+	routes.Handle("GET", "/v0/markets/{id}/resolution-preview", agentOrUser(models.ScopeMarkets), marketshandlers.ResolutionPreviewHandler(db))
+	routes.HandleFunc("POST", "/v0/bet", idempotent(user), buybetshandlers.PlaceBetHandler(setup.EconomicsConfig))
+	routes.HandleFunc("GET", "/v0/userposition/{marketId}", user, usershandlers.UserMarketPositionHandler)
+	routes.HandleFunc("POST", "/v0/sell", idempotent(user), sellbetshandlers.SellPositionHandler(setup.EconomicsConfig))
+	routes.HandleFunc("POST", "/v0/create", idempotent(user), marketshandlers.CreateMarketHandler(setup.EconomicsConfig))
+
+	// admin stuff
+	routes.HandleFunc("POST", "/v0/admin/createuser", admin, adminhandlers.AddUserHandler(setup.EconomicsConfig))
+
 	// ============================================
 	// AI AGENT ENDPOINTS (AI Swarm Prediction Market)
 	// ============================================
-	
+
 	// Get base URL for claim URLs
 	baseURL := os.Getenv("BASE_URL")
 	if baseURL == "" {
@@ -185,20 +282,63 @@ func Start() {
 	}
 
 	// Agent registration and authentication
-	router.HandleFunc("/v0/agents/register", agentshandlers.RegisterHandler(db, baseURL)).Methods("POST")
-	router.HandleFunc("/v0/agents/claim/{claimToken}", agentshandlers.ClaimHandler(db)).Methods("POST")
-	router.Handle("/v0/agents/status", securityMiddleware(http.HandlerFunc(agentshandlers.GetAgentStatusHandler(db)))).Methods("GET")
-	
+	routes.HandleFunc("POST", "/v0/agents/register", public, agentshandlers.RegisterHandler(db, baseURL))
+	routes.HandleFunc("POST", "/v0/agents/claim/{claimToken}", user, agentshandlers.ClaimHandler(db))
+	routes.HandleFunc("GET", "/v0/agents/status", agent(models.ScopeRead), agentshandlers.GetAgentStatusHandler(db))
+	routes.HandleFunc("GET", "/v0/agents/keys", agent(models.ScopeAccount), agentshandlers.ListKeysHandler(db))
+	routes.HandleFunc("POST", "/v0/agents/keys/rotate", agent(models.ScopeAccount), agentshandlers.RotateKeyHandler(db))
+	routes.HandleFunc("PUT", "/v0/agents/model-card", agent(models.ScopeAccount), agentshandlers.UpdateModelCardHandler(db))
+	routes.HandleFunc("POST", "/v0/agents/rename", agent(models.ScopeAccount), agentshandlers.RenameHandler(db))
+	routes.HandleFunc("GET", "/v0/agents/by-name/{name}", read, agentshandlers.GetAgentByNameHandler(db))
+	routes.HandleFunc("GET", "/v0/agents/compare", read, agentshandlers.CompareAgentsHandler(db))
+	routes.HandleFunc("GET", "/v0/agents/me/onboarding-status", agent(models.ScopeRead), agentshandlers.OnboardingStatusHandler(db))
+
+	// Sandbox market for testing an integration; never scored
+	routes.HandleFunc("GET", "/v0/sandbox/market", read, agentshandlers.SandboxMarketHandler(readDB))
+	routes.HandleFunc("POST", "/v0/sandbox/predict", agent(models.ScopePredict), agentshandlers.SandboxPredictHandler(db))
+
 	// Agent betting (requires claimed agent)
-	router.Handle("/v0/agents/bet", securityMiddleware(http.HandlerFunc(agentshandlers.PlaceBetHandler(db)))).Methods("POST")
-	router.Handle("/v0/agents/bets", securityMiddleware(http.HandlerFunc(agentshandlers.GetAgentBetsHandler(db)))).Methods("GET")
-	
+	routes.HandleFunc("POST", "/v0/agents/bet", idempotent(claimedAgent(models.ScopePredict)), agentshandlers.PlaceBetHandler(db))
+	routes.HandleFunc("GET", "/v0/agents/bets", agent(models.ScopeRead), agentshandlers.GetAgentBetsHandler(db))
+
 	// Agent market creation (requires claimed agent)
-	router.Handle("/v0/agents/create", securityMiddleware(http.HandlerFunc(agentshandlers.CreateMarketHandler(db)))).Methods("POST")
-	
+	routes.HandleFunc("POST", "/v0/agents/create", idempotent(claimedAgent(models.ScopeMarkets)), agentshandlers.CreateMarketHandler(db))
+
+	// Read-only API keys, minted by agent owners for read-only clients
+	routes.HandleFunc("POST", "/v0/readkeys", user, readkeyshandlers.CreateReadKeyHandler(db))
+	routes.HandleFunc("GET", "/v0/readkeys", user, readkeyshandlers.ListReadKeysHandler(db))
+	routes.HandleFunc("DELETE", "/v0/readkeys/{id}", user, readkeyshandlers.RevokeReadKeyHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/read-usage", operator, readkeyshandlers.ReadUsageHandler(db, readMeter))
+	routes.HandleFunc("PUT", "/v0/admin/readkeys/{id}/analytics", operator, readkeyshandlers.GrantAnalyticsHandler(db))
+	routes.HandleFunc("DELETE", "/v0/admin/readkeys/{id}/analytics", operator, readkeyshandlers.RevokeAnalyticsHandler(db))
+
+	// Owners managing their claimed agents
+	routes.HandleFunc("DELETE", "/v0/user/agents/{id}", user, agentshandlers.DeactivateAgentHandler(db))
+	routes.HandleFunc("GET", "/v0/user/agents/{id}/export", user, agentshandlers.ExportAgentHandler(db))
+	routes.HandleFunc("POST", "/v0/user/agents/{id}/erasure", user, agentshandlers.RequestErasureHandler(db))
+	routes.HandleFunc("GET", "/v0/user/agents/{id}/erasure", user, agentshandlers.GetErasureHandler(db))
+	routes.HandleFunc("DELETE", "/v0/user/agents/{id}/erasure", user, agentshandlers.CancelErasureHandler(db))
+
+	// Agent notifications: inbox and webhook delivery
+	routes.HandleFunc("GET", "/v0/agents/notifications", agent(models.ScopeAccount), notificationshandlers.ListNotificationsHandler(db))
+	routes.HandleFunc("POST", "/v0/agents/notifications/{id}/read", agent(models.ScopeAccount), notificationshandlers.MarkNotificationReadHandler(db))
+	routes.HandleFunc("PUT", "/v0/agents/webhook", agent(models.ScopeAccount), notificationshandlers.SetWebhookHandler(db))
+
 	// Swarm consensus and leaderboard (legacy)
-	router.Handle("/v0/markets/{marketId}/swarm", securityMiddleware(http.HandlerFunc(agentshandlers.GetSwarmConsensusHandler(db)))).Methods("GET")
-	router.Handle("/v0/agents/leaderboard", securityMiddleware(http.HandlerFunc(agentshandlers.GetAgentLeaderboardHandler(db)))).Methods("GET")
+	routes.HandleFunc("GET", "/v0/markets/{marketId}/swarm", read, agentshandlers.GetSwarmConsensusHandler(readDB))
+	routes.Handle("GET", "/v0/markets/{id}/correlated", read, marketshandlers.CorrelatedMarketsHandler(db))
+	routes.Handle("GET", "/v0/markets/{id}/activity-heatmap", read, marketshandlers.ActivityHeatmapHandler(db))
+	routes.Handle("GET", "/v0/markets/{id}/auto-resolutions", read, marketshandlers.AutoResolutionsHandler(db))
+	routes.Handle("GET", "/v0/markets/{id}/consensus/history", read, marketshandlers.ConsensusHistoryHandler(readDB))
+	routes.Handle("GET", "/v0/markets/{id}/stats", read, marketshandlers.MarketStatsHandler(readDB))
+	routes.Handle("POST", "/v0/markets/{id}/watch", agent(models.ScopeSocial), marketshandlers.WatchMarketHandler(db))
+	routes.Handle("DELETE", "/v0/markets/{id}/watch", agent(models.ScopeSocial), marketshandlers.UnwatchMarketHandler(db))
+	routes.HandleFunc("GET", "/v0/markets/{id}/invitations", agent(models.ScopeMarkets), marketshandlers.ListMarketInvitationsHandler(db))
+	routes.HandleFunc("POST", "/v0/markets/{id}/invitations", agent(models.ScopeMarkets), marketshandlers.InviteToMarketHandler(db))
+	routes.HandleFunc("DELETE", "/v0/markets/{id}/invitations/{agentId}", agent(models.ScopeMarkets), marketshandlers.RevokeMarketInvitationHandler(db))
+	routes.HandleFunc("POST", "/v0/markets/{marketId}/closing-bid", claimedAgent(models.ScopePredict), agentshandlers.SubmitClosingBidHandler(db))
+	routes.HandleFunc("GET", "/v0/agents/leaderboard", read, agentshandlers.GetAgentLeaderboardHandler(readDB))
+	routes.HandleFunc("GET", "/v0/analytics/models", read, agentshandlers.GetModelAccuracyHandler(readDB))
 
 	// ============================================
 	// KNOWLEDGE-BASED PREDICTION SYSTEM (NEW)
@@ -206,80 +346,207 @@ func Start() {
 	// ============================================
 
 	// Make predictions (replaces /v0/agents/bet)
-	router.Handle("/v0/predict", securityMiddleware(http.HandlerFunc(predictionshandlers.MakePredictionHandler(db)))).Methods("POST")
-	router.Handle("/v0/prediction/{id}", securityMiddleware(http.HandlerFunc(predictionshandlers.GetPredictionHandler(db)))).Methods("GET")
-	router.Handle("/v0/prediction/{id}/vote", securityMiddleware(http.HandlerFunc(predictionshandlers.VotePredictionHandler(db)))).Methods("POST")
-	
+	routes.HandleFunc("POST", "/v0/predict", idempotent(claimedAgent(models.ScopePredict)), predictionshandlers.MakePredictionHandler(db))
+	routes.HandleFunc("GET", "/v0/prediction/{id}", read, predictionshandlers.GetPredictionHandler(db))
+	routes.HandleFunc("GET", "/v0/prediction/{id}/history", read, predictionshandlers.GetPredictionHistoryHandler(db))
+	routes.HandleFunc("POST", "/v0/prediction/{id}/vote", agent(models.ScopeSocial), predictionshandlers.VotePredictionHandler(db))
+	routes.HandleFunc("POST", "/v0/prediction/{id}/comments", idempotent(agentOrUser(models.ScopeSocial)), predictionshandlers.CreateCommentHandler(db))
+	routes.HandleFunc("GET", "/v0/prediction/{id}/comments", read, predictionshandlers.GetCommentsHandler(db))
+	routes.HandleFunc("PUT", "/v0/prediction/{id}/comments/{commentId}", agentOrUser(models.ScopeSocial), predictionshandlers.UpdateCommentHandler(db))
+	routes.HandleFunc("DELETE", "/v0/prediction/{id}/comments/{commentId}", agentOrUser(models.ScopeSocial), predictionshandlers.DeleteCommentHandler(db))
+
 	// Agent predictions and stats
-	router.Handle("/v0/agent/{id}/predictions", securityMiddleware(http.HandlerFunc(predictionshandlers.GetAgentPredictionsHandler(db)))).Methods("GET")
-	router.Handle("/v0/agent/{id}/stats", securityMiddleware(http.HandlerFunc(predictionshandlers.GetAgentStatsHandler(db)))).Methods("GET")
-	
+	routes.HandleFunc("GET", "/v0/agent/{id}/predictions", read, predictionshandlers.GetAgentPredictionsHandler(db))
+	routes.HandleFunc("GET", "/v0/agent/{id}/stats", read, predictionshandlers.GetAgentStatsHandler(readDB))
+	routes.HandleFunc("GET", "/v0/agent/{id}/vs/{otherId}", read, agentshandlers.HeadToHeadHandler(db))
+	routes.HandleFunc("GET", "/v0/agent/{id}/achievements", read, agentshandlers.GetAgentAchievementsHandler(db))
+	routes.HandleFunc("GET", "/v0/achievements", read, agentshandlers.ListAchievementsHandler())
+	routes.Handle("GET", "/v0/agent/watchlist", agent(models.ScopeRead), marketshandlers.WatchlistHandler(db))
+	routes.HandleFunc("GET", "/v0/agent/{id}", read, agentshandlers.GetAgentProfileHandler(db))
+
 	// Market predictions
-	router.Handle("/v0/market/{id}/predictions", securityMiddleware(http.HandlerFunc(predictionshandlers.GetMarketPredictionsHandler(db)))).Methods("GET")
-	
+	routes.HandleFunc("GET", "/v0/market/{id}/predictions", read, predictionshandlers.GetMarketPredictionsHandler(readDB))
+
 	// Follow system
-	router.Handle("/v0/agent/{id}/follow", securityMiddleware(http.HandlerFunc(predictionshandlers.FollowAgentHandler(db)))).Methods("POST")
-	router.Handle("/v0/agent/{id}/follow", securityMiddleware(http.HandlerFunc(predictionshandlers.UnfollowAgentHandler(db)))).Methods("DELETE")
-	router.Handle("/v0/agent/{id}/followers", securityMiddleware(http.HandlerFunc(predictionshandlers.GetAgentFollowersHandler(db)))).Methods("GET")
-	router.Handle("/v0/agent/{id}/following", securityMiddleware(http.HandlerFunc(predictionshandlers.GetAgentFollowingHandler(db)))).Methods("GET")
-	
+	routes.HandleFunc("POST", "/v0/agent/{id}/follow", agent(models.ScopeSocial), predictionshandlers.FollowAgentHandler(db))
+	routes.HandleFunc("DELETE", "/v0/agent/{id}/follow", agent(models.ScopeSocial), predictionshandlers.UnfollowAgentHandler(db))
+	routes.HandleFunc("GET", "/v0/agent/{id}/followers", read, predictionshandlers.GetAgentFollowersHandler(db))
+	routes.HandleFunc("GET", "/v0/agent/{id}/following", read, predictionshandlers.GetAgentFollowingHandler(db))
+
 	// New reputation-based leaderboard
-	router.Handle("/v0/leaderboard", securityMiddleware(http.HandlerFunc(predictionshandlers.LeaderboardHandler(db)))).Methods("GET")
-	
-	// Admin: Recalculate all scores
-	router.Handle("/v0/admin/recalculate-scores", securityMiddleware(http.HandlerFunc(predictionshandlers.RecalculateAllScoresHandler(db)))).Methods("POST")
+	routes.HandleFunc("GET", "/v0/leaderboard", read, predictionshandlers.LeaderboardHandler(readDB))
+
+	// Bulk exports for research, streamed from the read replicas
+	for _, dataset := range []string{"predictions", "markets", "resolutions"} {
+		routes.HandleFunc("GET", "/v0/export/"+dataset, export, exporthandlers.ExportHandler(readDB, dataset))
+	}
+
+	// Agent teams
+	routes.HandleFunc("POST", "/v0/teams", claimedAgent(models.ScopeSocial), teamshandlers.CreateTeamHandler(db))
+	routes.HandleFunc("GET", "/v0/teams/leaderboard", read, teamshandlers.TeamLeaderboardHandler(readDB))
+	routes.HandleFunc("GET", "/v0/teams/{teamId}", read, teamshandlers.GetTeamHandler(db))
+	routes.HandleFunc("GET", "/v0/teams/{teamId}/predictions", read, teamshandlers.GetTeamPredictionsHandler(db))
+	routes.HandleFunc("POST", "/v0/teams/{teamId}/invites", claimedAgent(models.ScopeSocial), teamshandlers.InviteHandler(db))
+	routes.HandleFunc("POST", "/v0/teams/{teamId}/join", claimedAgent(models.ScopeSocial), teamshandlers.JoinTeamHandler(db))
+	routes.HandleFunc("DELETE", "/v0/teams/{teamId}/members/{agentId}", agent(models.ScopeSocial), teamshandlers.RemoveMemberHandler(db))
+
+	// Market series
+	routes.HandleFunc("POST", "/v0/series", idempotent(claimedAgent(models.ScopeMarkets)), serieshandlers.CreateSeriesHandler(db))
+	routes.HandleFunc("GET", "/v0/series/{seriesId}", read, serieshandlers.GetSeriesHandler(db))
+	routes.HandleFunc("POST", "/v0/series/{seriesId}/markets", idempotent(claimedAgent(models.ScopeMarkets)), serieshandlers.AddSeriesMarketHandler(db))
+
+	// Market templates: recurring questions approved once by the council
+	routes.HandleFunc("GET", "/v0/templates", read, templateshandlers.ListTemplatesHandler(db))
+	routes.HandleFunc("GET", "/v0/templates/{templateId}", read, templateshandlers.GetTemplateHandler(db))
+	routes.HandleFunc("POST", "/v0/templates/{templateId}/pause", claimedAgent(models.ScopeMarkets), templateshandlers.PauseTemplateHandler(db))
+	routes.HandleFunc("POST", "/v0/templates/{templateId}/resume", claimedAgent(models.ScopeMarkets), templateshandlers.ResumeTemplateHandler(db))
+	routes.HandleFunc("POST", "/v0/templates/{templateId}/markets", idempotent(claimedAgent(models.ScopeMarkets)), templateshandlers.CreateMarketNowHandler(db))
+
+	// Admin: Database health, the pool settings in effect and the schema
+	// migrations
+	routes.HandleFunc("GET", "/v0/admin/health", operator, adminhandlers.HealthHandler(db, util.DBConfig))
+	routes.HandleFunc("GET", "/v0/admin/migrations", operator, adminhandlers.ListMigrationsHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/migrations/plan", admin, adminhandlers.PlanMigrationsHandler(db))
+
+	// Admin: Fill a development database with fixture agents, markets,
+	// predictions, validators and proposals. Never enable in production.
+	if getBoolEnv("DEV_SEED_ENABLED", false) {
+		routes.HandleFunc("POST", "/v0/admin/seed", admin, adminhandlers.SeedFixturesHandler(db))
+	}
+
+	// Admin: Recalculate all scores in the background and follow the job
+	routes.HandleFunc("POST", "/v0/admin/recalculate-scores", operator, predictionshandlers.RecalculateAllScoresHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/jobs/{id}", operator, adminhandlers.GetJobHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/jobs/{id}/cancel", operator, adminhandlers.CancelJobHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/scoring/what-if", operator, adminhandlers.WhatIfScoringHandler(db))
+
+	// Live event delivery: server-sent events, or long-polling for clients
+	// that cannot stream, and WebSocket subscriptions to markets and agents
+	routes.HandleFunc("GET", "/v0/events/poll", read, eventshandlers.PollHandler(db, bus))
+	routes.HandleFunc("GET", "/v0/stream", read, eventshandlers.StreamHandler(db, bus))
+	routes.HandleFunc("GET", "/v0/ws", read, eventshandlers.WebSocketHandler(db, bus))
+
+	// Nested read queries over markets, agents, predictions and proposals
+	routes.HandleFunc("POST", "/v0/graphql", read, graphqlhandlers.GraphQLHandler(readDB))
 
 	// ============================================
 	// AI GOVERNANCE (Proposals & Voting)
 	// ============================================
-	
+
 	// Public proposal endpoints
-	router.HandleFunc("/v0/governance/proposals", governancehandlers.ListProposalsHandler(db)).Methods("GET")
-	router.HandleFunc("/v0/governance/proposals/{proposalId}", governancehandlers.GetProposalHandler(db)).Methods("GET")
-	
+	routes.HandleFunc("GET", "/v0/governance/proposals", read, governancehandlers.ListProposalsHandler(db))
+	routes.HandleFunc("GET", "/v0/governance/proposals/{proposalId}", read, governancehandlers.GetProposalHandler(db))
+	routes.HandleFunc("GET", "/v0/governance/proposals/{proposalId}/revisions", read, governancehandlers.GetProposalRevisionsHandler(db))
+
 	// Agent-authenticated proposal endpoints
-	router.HandleFunc("/v0/governance/proposals", governancehandlers.CreateProposalHandler(db)).Methods("POST")
-	router.HandleFunc("/v0/governance/proposals/{proposalId}/vote", governancehandlers.VoteOnProposalHandler(db)).Methods("POST")
-	router.HandleFunc("/v0/governance/proposals/{proposalId}/comments", governancehandlers.CommentOnProposalHandler(db)).Methods("POST")
-	
+	routes.HandleFunc("POST", "/v0/governance/proposals", idempotent(claimedAgent(models.ScopeGovernance)), governancehandlers.CreateProposalHandler(db))
+	routes.HandleFunc("POST", "/v0/governance/proposals/{proposalId}/vote", idempotent(claimedAgent(models.ScopeGovernance)), governancehandlers.VoteOnProposalHandler(db))
+	routes.HandleFunc("POST", "/v0/governance/proposals/{proposalId}/comments", claimedAgent(models.ScopeGovernance), governancehandlers.CommentOnProposalHandler(db))
+	routes.HandleFunc("PUT", "/v0/governance/proposals/{proposalId}", claimedAgent(models.ScopeGovernance), governancehandlers.EditProposalHandler(db))
+	routes.HandleFunc("POST", "/v0/governance/proposals/{proposalId}/amendments", idempotent(claimedAgent(models.ScopeGovernance)), governancehandlers.AmendProposalHandler(db))
+	routes.HandleFunc("POST", "/v0/governance/proposals/{proposalId}/withdraw", claimedAgent(models.ScopeGovernance), governancehandlers.WithdrawProposalHandler(db))
+	routes.HandleFunc("GET", "/v0/governance/delegation", claimedAgent(models.ScopeGovernance), governancehandlers.GetDelegationHandler(db))
+	routes.HandleFunc("PUT", "/v0/governance/delegation", claimedAgent(models.ScopeGovernance), governancehandlers.DelegateHandler(db))
+	routes.HandleFunc("DELETE", "/v0/governance/delegation", claimedAgent(models.ScopeGovernance), governancehandlers.RevokeDelegationHandler(db))
+
 	// Admin endpoints for human review
-	router.Handle("/v0/admin/governance/pending", securityMiddleware(http.HandlerFunc(governancehandlers.GetApprovedProposalsHandler(db)))).Methods("GET")
-	router.Handle("/v0/admin/governance/proposals/{proposalId}/review", securityMiddleware(http.HandlerFunc(governancehandlers.HumanApproveProposalHandler(db)))).Methods("POST")
+	routes.HandleFunc("GET", "/v0/admin/governance/pending", moderator, governancehandlers.GetApprovedProposalsHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/governance/proposals/{proposalId}/review", moderator, governancehandlers.HumanApproveProposalHandler(db))
 
 	// Admin cleanup endpoints
-	router.Handle("/v0/admin/market/{id}", securityMiddleware(http.HandlerFunc(adminhandlers.DeleteMarketHandler(db)))).Methods("DELETE")
-	router.Handle("/v0/admin/agent/{id}", securityMiddleware(http.HandlerFunc(adminhandlers.DeleteAgentHandler(db)))).Methods("DELETE")
-	router.Handle("/v0/admin/reset-old-stats", securityMiddleware(http.HandlerFunc(adminhandlers.ResetOldStatsHandler(db)))).Methods("POST")
+	routes.HandleFunc("DELETE", "/v0/admin/market/{id}", admin, adminhandlers.DeleteMarketHandler(db))
+	routes.HandleFunc("DELETE", "/v0/admin/agent/{id}", admin, adminhandlers.DeleteAgentHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/market/{id}/restore", admin, adminhandlers.RestoreMarketHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/agent/{id}/restore", admin, adminhandlers.RestoreAgentHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/agent/{id}/claim-code", admin, adminhandlers.IssueClaimCodeHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/agent/{id}/erasure", admin, adminhandlers.ScheduleErasureHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/erasures", admin, adminhandlers.ListErasuresHandler(db))
+
+	// Agent moderation
+	routes.HandleFunc("GET", "/v0/admin/agent/{id}/suspensions", moderator, adminhandlers.ListAgentSuspensionsHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/agent/{id}/suspensions", moderator, adminhandlers.SuspendAgentHandler(db))
+	routes.HandleFunc("DELETE", "/v0/admin/agent/{id}/suspensions", moderator, adminhandlers.LiftAgentSuspensionHandler(db))
+
+	// Content reports and the moderation queue
+	routes.HandleFunc("POST", "/v0/report", agentOrUser(models.ScopeSocial), moderationhandlers.ReportHandler(db))
+	routes.HandleFunc("GET", "/v0/moderation/queue", claimedAgent(models.ScopeGovernance), moderationhandlers.ValidatorQueueHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/moderation/queue", moderator, moderationhandlers.QueueHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/moderation/{id}/review", moderator, moderationhandlers.ReviewHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/parameters", operator, adminhandlers.ListParametersHandler(db))
+	routes.HandleFunc("PUT", "/v0/admin/parameters/{name}", admin, adminhandlers.SetParameterHandler(db))
+	routes.HandleFunc("DELETE", "/v0/admin/parameters/{name}", admin, adminhandlers.ResetParameterHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/verification/rules", operator, adminhandlers.ListVerificationRulesHandler(db))
+	routes.HandleFunc("PUT", "/v0/admin/verification/rules/{name}", operator, adminhandlers.SetVerificationRuleHandler(db))
+	routes.HandleFunc("DELETE", "/v0/admin/verification/rules/{name}", operator, adminhandlers.ResetVerificationRuleHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/reserved-names", moderator, adminhandlers.ListReservedNamesHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/reserved-names", moderator, adminhandlers.CreateReservedNameHandler(db))
+	routes.HandleFunc("DELETE", "/v0/admin/reserved-names/{id}", moderator, adminhandlers.DeleteReservedNameHandler(db))
+	routes.HandleFunc("POST", "/v0/admin/reset-old-stats", admin, adminhandlers.ResetOldStatsHandler(db))
+
+	// Staff roles and the audit log
+	routes.HandleFunc("GET", "/v0/admin/roles", admin, adminhandlers.ListRolesHandler(db))
+	routes.HandleFunc("PUT", "/v0/admin/roles/{username}", admin, adminhandlers.GrantRoleHandler(db))
+	routes.HandleFunc("DELETE", "/v0/admin/roles/{username}", admin, adminhandlers.RevokeRoleHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/audit", admin, adminhandlers.ListAuditLogHandler(db))
 
 	// ============================================
 	// VERIFICATION SYSTEM (Agent Council)
 	// All market/prediction creation must go through council voting
 	// No paid APIs - uses agent collective intelligence
 	// ============================================
-	
+
 	// Submit content for verification
-	router.Handle("/v0/submit/market", securityMiddleware(http.HandlerFunc(verificationhandlers.SubmitMarketHandler(db)))).Methods("POST")
-	
+	routes.HandleFunc("POST", "/v0/submit/market", idempotent(claimedAgent(models.ScopeMarkets)), verificationhandlers.SubmitMarketHandler(db))
+	routes.HandleFunc("POST", "/v0/submit/market/{submissionId}/revise", idempotent(claimedAgent(models.ScopeMarkets)), verificationhandlers.ReviseMarketHandler(db))
+	routes.HandleFunc("POST", "/v0/submit/prediction", idempotent(claimedAgent(models.ScopePredict)), verificationhandlers.SubmitPredictionHandler(db))
+	routes.HandleFunc("POST", "/v0/submit/template", idempotent(claimedAgent(models.ScopeMarkets)), verificationhandlers.SubmitTemplateHandler(db))
+
 	// View pending submissions
-	router.Handle("/v0/submissions/pending", securityMiddleware(http.HandlerFunc(verificationhandlers.GetPendingSubmissionsHandler(db)))).Methods("GET")
-	router.Handle("/v0/pending", securityMiddleware(http.HandlerFunc(verificationhandlers.GetPendingSubmissionsHandler(db)))).Methods("GET") // Legacy alias
-	
+	routes.HandleFunc("GET", "/v0/submissions/pending", read, verificationhandlers.GetPendingSubmissionsHandler(db))
+	routes.HandleFunc("GET", "/v0/pending", read, verificationhandlers.GetPendingSubmissionsHandler(db)) // Legacy alias
+	routes.HandleFunc("GET", "/v0/submissions/{submissionId}", read, verificationhandlers.GetSubmissionHandler(db))
+	routes.HandleFunc("GET", "/v0/submissions/{submissionId}/comments", claimedAgent(models.ScopeRead), verificationhandlers.GetSubmissionCommentsHandler(db))
+	routes.HandleFunc("POST", "/v0/submissions/{submissionId}/comments", claimedAgent(models.ScopeSocial), verificationhandlers.CommentOnSubmissionHandler(db))
+	routes.HandleFunc("POST", "/v0/submissions/{submissionId}/resubmit", idempotent(claimedAgent(models.ScopeMarkets)), verificationhandlers.ResubmitHandler(db))
+
 	// Council voting endpoints (requires validator status)
-	router.Handle("/v0/council/queue", securityMiddleware(http.HandlerFunc(verificationhandlers.GetCouncilQueueHandler(db)))).Methods("GET")
-	router.Handle("/v0/council/vote/{submissionId}", securityMiddleware(http.HandlerFunc(verificationhandlers.VoteOnSubmissionHandler(db)))).Methods("POST")
-	router.Handle("/v0/council/validators", securityMiddleware(http.HandlerFunc(verificationhandlers.GetValidatorsHandler(db)))).Methods("GET")
-	router.Handle("/v0/council/register", securityMiddleware(http.HandlerFunc(verificationhandlers.RegisterValidatorHandler(db)))).Methods("POST")
-	
+	routes.HandleFunc("GET", "/v0/council/queue", claimedAgent(models.ScopeGovernance), verificationhandlers.GetCouncilQueueHandler(db))
+	routes.HandleFunc("POST", "/v0/council/vote/{submissionId}", idempotent(claimedAgent(models.ScopeGovernance)), verificationhandlers.VoteOnSubmissionHandler(db))
+	routes.HandleFunc("PUT", "/v0/council/vote/{submissionId}", claimedAgent(models.ScopeGovernance), verificationhandlers.ChangeCouncilVoteHandler(db))
+	routes.HandleFunc("POST", "/v0/council/request-changes/{submissionId}", claimedAgent(models.ScopeGovernance), verificationhandlers.RequestChangesHandler(db))
+	routes.HandleFunc("GET", "/v0/council/reasoning/queue", claimedAgent(models.ScopeGovernance), verificationhandlers.GetReasoningReviewQueueHandler(db))
+	routes.HandleFunc("POST", "/v0/council/reasoning/{predictionId}/review", claimedAgent(models.ScopeGovernance), verificationhandlers.ReviewReasoningHandler(db))
+	routes.HandleFunc("GET", "/v0/council/resolutions", read, verificationhandlers.GetResolutionRequestsHandler(db))
+	routes.HandleFunc("GET", "/v0/council/resolutions/{requestId}", read, verificationhandlers.GetResolutionRequestHandler(db))
+	routes.HandleFunc("POST", "/v0/council/resolutions/{requestId}/vote", idempotent(claimedAgent(models.ScopeGovernance)), verificationhandlers.VoteOnResolutionHandler(db))
+	routes.HandleFunc("GET", "/v0/markets/{marketId}/disputes", read, verificationhandlers.GetDisputesHandler(db))
+	routes.HandleFunc("POST", "/v0/markets/{marketId}/disputes", idempotent(claimedAgent(models.ScopePredict)), verificationhandlers.FileDisputeHandler(db))
+	routes.HandleFunc("GET", "/v0/council/validators", read, verificationhandlers.GetValidatorsHandler(db))
+	routes.HandleFunc("POST", "/v0/council/register", claimedAgent(models.ScopeGovernance), verificationhandlers.RegisterValidatorHandler(db))
+
 	// Admin: process expired submissions
-	router.Handle("/v0/admin/submissions/process-expired", securityMiddleware(http.HandlerFunc(verificationhandlers.ProcessExpiredSubmissionsHandler(db)))).Methods("POST")
+	routes.HandleFunc("POST", "/v0/admin/submissions/process-expired", operator, verificationhandlers.ProcessExpiredSubmissionsHandler(db))
+	routes.HandleFunc("GET", "/v0/admin/submissions/repeat-offenders", moderator, verificationhandlers.GetRepeatSubmittersHandler(db))
 
 	homepageRepo := homepage.NewGormRepository(db)
 	homepageRenderer := homepage.NewDefaultRenderer()
 	homepageSvc := homepage.NewService(homepageRepo, homepageRenderer)
 	homepageHandler := cmshomehttp.NewHandler(homepageSvc)
 
-	router.HandleFunc("/v0/content/home", homepageHandler.PublicGet).Methods("GET")
-	router.Handle("/v0/admin/content/home", securityMiddleware(http.HandlerFunc(homepageHandler.AdminUpdate))).Methods("PUT")
+	routes.HandleFunc("GET", "/v0/content/home", public, homepageHandler.PublicGet)
+	routes.HandleFunc("PUT", "/v0/admin/content/home", moderator, homepageHandler.AdminUpdate)
+
+	return router
+}
+
+func Start(bus *outbox.Bus) {
+	// Initialize security service
+	securityService := security.NewSecurityService()
+
+	// CORS handler (configurable via env)
+	c := buildCORSFromEnv()
+
+	router := NewRouter(util.GetDB(), util.GetReadDB(), securityService, bus)
 
 	// Apply CORS middleware if enabled
 	handler := http.Handler(router)
@@ -0,0 +1,246 @@
+// Package achievements awards agents badges, such as ten correct
+// predictions in a row or a place in the weekly top ten. The scheduler runs
+// Evaluate, which reads the market, submission and prediction events from
+// the outbox since its last run and re-evaluates the agents they concern,
+// keeping their correct prediction streaks as it goes.
+package achievements
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/notifications"
+	"socialpredict/outbox"
+	"socialpredict/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Consumer is the achievements job's outbox cursor.
+const Consumer = "achievements"
+
+// Outbox events read per batch, and at most per run, so a backlog is
+// worked through over several runs.
+const (
+	batchSize       = 500
+	maxEventsPerRun = 5000
+)
+
+// topics are the events that can earn a badge.
+var topics = []string{outbox.TopicMarketResolved, outbox.TopicSubmissionResolved, outbox.TopicPredictionCreated}
+
+// Evaluate re-evaluates the agents concerned by the events since the last
+// run, then the weekly leaderboard's top ten, and returns how many badges
+// it awarded.
+func Evaluate(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+	cursor := models.OutboxCursor{Consumer: Consumer}
+	if err := db.FirstOrCreate(&cursor).Error; err != nil {
+		return 0, err
+	}
+
+	awarded := 0
+	for read := 0; read < maxEventsPerRun; {
+		events, err := outbox.Since(db, cursor.LastEventID, outbox.Filter{Topics: topics}, batchSize)
+		if err != nil {
+			return awarded, err
+		}
+		if len(events) == 0 {
+			break
+		}
+		agentIDs, err := concerned(db, events)
+		if err != nil {
+			return awarded, err
+		}
+		for _, agentID := range agentIDs {
+			n, err := EvaluateAgent(ctx, db, agentID, now)
+			if err != nil {
+				return awarded, err
+			}
+			awarded += n
+		}
+		cursor.LastEventID = events[len(events)-1].ID
+		if err := db.Save(&cursor).Error; err != nil {
+			return awarded, err
+		}
+		read += len(events)
+	}
+
+	n, err := awardWeeklyTopTen(ctx, db, now)
+	return awarded + n, err
+}
+
+// concerned returns the agents events concern, in order: those who
+// predicted on a resolved market, submitted a resolved submission or made a
+// prediction.
+func concerned(db *gorm.DB, events []models.OutboxEvent) ([]int64, error) {
+	seen := make(map[int64]bool)
+	var agentIDs, marketIDs []int64
+	add := func(id int64) {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			agentIDs = append(agentIDs, id)
+		}
+	}
+	for _, event := range events {
+		switch event.Topic {
+		case outbox.TopicMarketResolved:
+			marketIDs = append(marketIDs, event.AggregateID)
+		case outbox.TopicSubmissionResolved:
+			var payload struct {
+				SubmitterAgentID int64  `json:"submitterAgentId"`
+				FinalStatus      string `json:"finalStatus"`
+			}
+			if json.Unmarshal([]byte(event.Payload), &payload) == nil && payload.FinalStatus == "approved" {
+				add(payload.SubmitterAgentID)
+			}
+		case outbox.TopicPredictionCreated:
+			var payload struct {
+				AgentID int64 `json:"agentId"`
+			}
+			if json.Unmarshal([]byte(event.Payload), &payload) == nil {
+				add(payload.AgentID)
+			}
+		}
+	}
+	if len(marketIDs) > 0 {
+		var predictors []int64
+		if err := db.Model(&models.Prediction{}).Distinct("agent_id").
+			Where("market_id IN ?", marketIDs).Pluck("agent_id", &predictors).Error; err != nil {
+			return nil, err
+		}
+		sort.Slice(predictors, func(i, j int) bool { return predictors[i] < predictors[j] })
+		for _, id := range predictors {
+			add(id)
+		}
+	}
+	return agentIDs, nil
+}
+
+// EvaluateAgent recomputes the agent's correct prediction streaks and
+// awards it the badges it has earned and not yet been given, returning how
+// many. The weekly top ten badge is awarded by Evaluate.
+func EvaluateAgent(ctx context.Context, db *gorm.DB, agentID int64, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+	var outcomes []bool
+	if err := db.Model(&models.Prediction{}).
+		Where("agent_id = ? AND is_resolved = ?", agentID, true).
+		Order("resolved_at, id").
+		Pluck("was_correct", &outcomes).Error; err != nil {
+		return 0, err
+	}
+	current, best := streaks(outcomes)
+
+	var approvedMarkets int64
+	if err := db.Model(&models.PendingSubmission{}).
+		Where("submitter_agent_id = ? AND submission_type = ? AND final_status = ?", agentID, models.SubmissionTypeMarket, "approved").
+		Count(&approvedMarkets).Error; err != nil {
+		return 0, err
+	}
+
+	return award(db, agentID, now, func(agent *models.Agent) []string {
+		agent.CorrectStreak, agent.BestCorrectStreak = current, best
+		var earned []string
+		if best >= models.AchievementStreakLength {
+			earned = append(earned, models.AchievementCorrectStreak)
+		}
+		if approvedMarkets > 0 {
+			earned = append(earned, models.AchievementFirstMarket)
+		}
+		if agent.TotalPredictions >= models.AchievementPredictionsCount {
+			earned = append(earned, models.AchievementPredictions100)
+		}
+		return earned
+	})
+}
+
+// streaks returns the run of trues at the end of outcomes and the longest
+// run anywhere in it.
+func streaks(outcomes []bool) (current, best int64) {
+	for _, correct := range outcomes {
+		if correct {
+			current++
+		} else {
+			current = 0
+		}
+		if current > best {
+			best = current
+		}
+	}
+	return current, best
+}
+
+// awardWeeklyTopTen awards the weekly top ten badge to the agents in the
+// top ten of the weekly leaderboard, ranked on accuracy as it is by
+// default.
+func awardWeeklyTopTen(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	var top []int64
+	if err := db.Table("leaderboard_snapshots").
+		Joins("JOIN agents ON agents.id = leaderboard_snapshots.agent_id").
+		Where("leaderboard_snapshots.time_window = ? AND agents.is_active = ? AND agents.deleted_at IS NULL", models.LeaderboardWindowWeekly, true).
+		Order("leaderboard_snapshots.accuracy_score DESC").Order("agents.id").
+		Limit(models.AchievementWeeklyRank).
+		Pluck("leaderboard_snapshots.agent_id", &top).Error; err != nil {
+		return 0, err
+	}
+	awarded := 0
+	for _, agentID := range top {
+		n, err := award(db.WithContext(ctx), agentID, now, func(agent *models.Agent) []string {
+			return []string{models.AchievementWeeklyTopTen}
+		})
+		if err != nil {
+			return awarded, err
+		}
+		awarded += n
+	}
+	return awarded, nil
+}
+
+// award loads the agent, lets update change it and name the badges it has
+// earned, and gives it those it does not have yet, sending a notification
+// for each. The agent is saved only if it changed. A deleted agent is
+// skipped.
+func award(db *gorm.DB, agentID int64, now time.Time, update func(agent *models.Agent) []string) (int, error) {
+	awarded := 0
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		awarded = 0
+		return db.Transaction(func(tx *gorm.DB) error {
+			var agent models.Agent
+			if err := tx.First(&agent, agentID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+			before := agent
+			for _, key := range update(&agent) {
+				if agent.HasBadge(key) {
+					continue
+				}
+				achievement := models.Achievement{AgentID: agent.ID, Key: key, AwardedAt: now}
+				created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&achievement)
+				if created.Error != nil {
+					return created.Error
+				}
+				agent.AddBadge(key)
+				if created.RowsAffected == 0 {
+					continue
+				}
+				if err := notifications.SendAchievementEarned(tx, agent.ID, achievement.ToPublic()); err != nil {
+					return err
+				}
+				awarded++
+			}
+			if agent.CorrectStreak == before.CorrectStreak && agent.BestCorrectStreak == before.BestCorrectStreak && agent.Badges == before.Badges {
+				return nil
+			}
+			return tx.Save(&agent).Error
+		})
+	})
+	return awarded, err
+}
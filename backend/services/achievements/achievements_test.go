@@ -0,0 +1,117 @@
+package achievements
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+
+	"gorm.io/gorm"
+)
+
+func TestEvaluate_AwardsBadgesOnceFromEvents(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		now := time.Now()
+
+		user := modelstesting.GenerateUser("creator", 0)
+		db.Create(&user)
+		market := modelstesting.GenerateMarket(0, user.Username)
+		db.Create(&market)
+		streaker := modelstesting.GenerateAgent("streaker")
+		creator := modelstesting.GenerateAgent("creator_agent")
+		for _, a := range []*models.Agent{&streaker, &creator} {
+			if err := db.Create(a).Error; err != nil {
+				t.Fatalf("create agent: %v", err)
+			}
+		}
+		if err := db.Model(&creator).Update("total_predictions", models.AchievementPredictionsCount).Error; err != nil {
+			t.Fatalf("update agent: %v", err)
+		}
+
+		// A miss, then eleven correct in a row.
+		for i := 0; i < 12; i++ {
+			at := now.Add(time.Duration(i-12) * time.Hour)
+			prediction := models.Prediction{AgentID: streaker.ID, MarketID: market.ID, Outcome: "YES",
+				IsResolved: true, WasCorrect: i > 0, ResolvedAt: &at, PredictedAt: at}
+			if err := db.Create(&prediction).Error; err != nil {
+				t.Fatalf("create prediction: %v", err)
+			}
+		}
+		submission := models.PendingSubmission{SubmissionType: models.SubmissionTypeMarket, SubmitterAgentID: creator.ID, FinalStatus: "approved"}
+		if err := db.Create(&submission).Error; err != nil {
+			t.Fatalf("create submission: %v", err)
+		}
+		for _, event := range []struct {
+			topic, aggregate string
+			id               int64
+			payload          interface{}
+		}{
+			{outbox.TopicMarketResolved, outbox.AggregateMarket, market.ID, map[string]interface{}{"marketId": market.ID}},
+			{outbox.TopicSubmissionResolved, outbox.AggregateSubmission, submission.ID, map[string]interface{}{"submitterAgentId": creator.ID, "finalStatus": "approved"}},
+		} {
+			if err := outbox.Enqueue(db, event.topic, event.aggregate, event.id, event.payload); err != nil {
+				t.Fatalf("enqueue: %v", err)
+			}
+		}
+		if err := db.Create(&models.LeaderboardSnapshot{Window: models.LeaderboardWindowWeekly, AgentID: creator.ID, ComputedAt: now}).Error; err != nil {
+			t.Fatalf("create snapshot: %v", err)
+		}
+
+		awarded, err := Evaluate(ctx, db, now)
+		if err != nil || awarded != 4 {
+			t.Fatalf("expected 4 badges awarded, got %d, %v", awarded, err)
+		}
+
+		var agent models.Agent
+		db.First(&agent, streaker.ID)
+		if agent.CorrectStreak != 11 || agent.BestCorrectStreak != 11 {
+			t.Fatalf("expected an 11 correct streak, got %d best %d", agent.CorrectStreak, agent.BestCorrectStreak)
+		}
+		if badges := agent.ToPublic().Badges; len(badges) != 1 || badges[0].Key != models.AchievementCorrectStreak {
+			t.Fatalf("expected the streak badge, got %+v", badges)
+		}
+		var creatorAgent models.Agent
+		db.First(&creatorAgent, creator.ID)
+		if badges := creatorAgent.ToPublic().Badges; len(badges) != 3 || badges[0].Key != models.AchievementFirstMarket ||
+			badges[1].Key != models.AchievementPredictions100 || badges[2].Key != models.AchievementWeeklyTopTen {
+			t.Fatalf("expected the market, predictions and weekly badges, got %+v", badges)
+		}
+
+		var notified int64
+		db.Model(&models.Notification{}).Where("kind = ?", "achievement.earned").Count(&notified)
+		if notified != 4 {
+			t.Fatalf("expected a notification per badge, got %d", notified)
+		}
+
+		// Nothing new: the events are not read again and no badge is given
+		// twice.
+		if awarded, err := Evaluate(ctx, db, now); err != nil || awarded != 0 {
+			t.Fatalf("expected nothing awarded again, got %d, %v", awarded, err)
+		}
+		var cursor models.OutboxCursor
+		db.First(&cursor, "consumer = ?", Consumer)
+		if latest, _ := outbox.LatestID(db); cursor.LastEventID == 0 || cursor.LastEventID > latest {
+			t.Fatalf("expected the cursor past the events, got %d of %d", cursor.LastEventID, latest)
+		}
+	})
+}
+
+func TestStreaks(t *testing.T) {
+	tests := []struct {
+		outcomes      []bool
+		current, best int64
+	}{
+		{nil, 0, 0},
+		{[]bool{true, true, false, true}, 1, 2},
+		{[]bool{false, true, true, true}, 3, 3},
+	}
+	for _, tt := range tests {
+		if current, best := streaks(tt.outcomes); current != tt.current || best != tt.best {
+			t.Errorf("streaks(%v) = %d, %d; want %d, %d", tt.outcomes, current, best, tt.current, tt.best)
+		}
+	}
+}
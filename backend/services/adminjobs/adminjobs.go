@@ -0,0 +1,225 @@
+// Package adminjobs runs long admin operations, such as recalculating every
+// agent's scores, in the background so the request that starts one returns
+// straight away. Jobs are rows in admin_jobs: the scheduler's admin-jobs job
+// runs queued jobs one at a time on whichever instance holds its lease, and
+// any instance can report a job's progress or cancel it.
+package adminjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"socialpredict/audit"
+	"socialpredict/models"
+	"socialpredict/services/scoring"
+
+	"gorm.io/gorm"
+)
+
+// progressInterval is the least time between progress writes, so a job over
+// many agents does not write on every one. Cancellation is noticed on these
+// writes.
+var progressInterval = time.Second
+
+var (
+	ErrJobNotFound   = errors.New("job not found")
+	ErrUnknownKind   = errors.New("unknown job kind")
+	ErrAlreadyActive = errors.New("a job of this kind is already queued or running")
+	ErrFinished      = errors.New("job has already finished")
+
+	errCancelled = errors.New("job cancelled")
+)
+
+// Runner does the work of one kind of job, reporting progress as it goes,
+// and returns how many items it updated.
+type Runner func(ctx context.Context, db *gorm.DB, progress scoring.Progress) (updated int, err error)
+
+var runners = map[string]Runner{
+	models.AdminJobRecalculateScores: func(ctx context.Context, db *gorm.DB, progress scoring.Progress) (int, error) {
+		updated, _, err := scoring.RecomputeAllWithProgress(ctx, db, progress)
+		return updated, err
+	},
+}
+
+// Enqueue queues a job of kind. Only one job of a kind may be queued or
+// running at a time: if one already is, it is returned with
+// ErrAlreadyActive.
+func Enqueue(db *gorm.DB, kind, requestedBy string) (*models.AdminJob, error) {
+	if _, ok := runners[kind]; !ok {
+		return nil, ErrUnknownKind
+	}
+
+	var job models.AdminJob
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("kind = ? AND status IN ?", kind, []string{models.AdminJobQueued, models.AdminJobRunning}).First(&job).Error
+		if err == nil {
+			return ErrAlreadyActive
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		job = models.AdminJob{Kind: kind, Status: models.AdminJobQueued, RequestedBy: requestedBy}
+		return tx.Create(&job).Error
+	})
+	if err != nil && !errors.Is(err, ErrAlreadyActive) {
+		return nil, err
+	}
+	return &job, err
+}
+
+// Get returns the job with id.
+func Get(db *gorm.DB, id int64) (*models.AdminJob, error) {
+	var job models.AdminJob
+	if err := db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Cancel cancels a queued job on the spot and asks a running one to stop at
+// its next progress report. It returns the job as it now stands, or
+// ErrFinished if it had already stopped.
+func Cancel(db *gorm.DB, id int64) (*models.AdminJob, error) {
+	now := time.Now()
+	dequeued := db.Model(&models.AdminJob{}).
+		Where("id = ? AND status = ?", id, models.AdminJobQueued).
+		Updates(map[string]interface{}{"status": models.AdminJobCancelled, "cancel_requested": true, "finished_at": now})
+	if dequeued.Error != nil {
+		return nil, dequeued.Error
+	}
+	if dequeued.RowsAffected == 0 {
+		requested := db.Model(&models.AdminJob{}).
+			Where("id = ? AND status = ?", id, models.AdminJobRunning).
+			Update("cancel_requested", true)
+		if requested.Error != nil {
+			return nil, requested.Error
+		}
+		if requested.RowsAffected == 0 {
+			job, err := Get(db, id)
+			if err != nil {
+				return nil, err
+			}
+			return job, ErrFinished
+		}
+	}
+	return Get(db, id)
+}
+
+// RunPending runs queued jobs, oldest first, until none are left and
+// returns how many it ran. The caller must be the only instance running
+// jobs, as the scheduler lease ensures, so any job still marked running was
+// interrupted and is failed first.
+func RunPending(ctx context.Context, db *gorm.DB) (int, error) {
+	interrupted := db.WithContext(ctx).Model(&models.AdminJob{}).
+		Where("status = ?", models.AdminJobRunning).
+		Updates(map[string]interface{}{"status": models.AdminJobFailed, "error": "interrupted", "finished_at": time.Now()})
+	if interrupted.Error != nil {
+		return 0, interrupted.Error
+	}
+
+	ran := 0
+	for ctx.Err() == nil {
+		var job models.AdminJob
+		err := db.WithContext(ctx).Where("status = ?", models.AdminJobQueued).Order("id").First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ran, nil
+		}
+		if err != nil {
+			return ran, err
+		}
+
+		// Claim the job unless it was cancelled since it was read.
+		startedAt := time.Now()
+		claimed := db.WithContext(ctx).Model(&models.AdminJob{}).
+			Where("id = ? AND status = ?", job.ID, models.AdminJobQueued).
+			Updates(map[string]interface{}{"status": models.AdminJobRunning, "started_at": startedAt})
+		if claimed.Error != nil {
+			return ran, claimed.Error
+		}
+		if claimed.RowsAffected == 0 {
+			continue
+		}
+		job.Status = models.AdminJobRunning
+		job.StartedAt = &startedAt
+
+		if err := run(ctx, db, &job); err != nil {
+			return ran, err
+		}
+		ran++
+	}
+	return ran, ctx.Err()
+}
+
+// run runs one claimed job and records how it ended. It returns an error
+// only if that record could not be written.
+func run(ctx context.Context, db *gorm.DB, job *models.AdminJob) error {
+	var lastWrite time.Time
+	progress := func(processed, total int) error {
+		job.Processed, job.Total = processed, total
+		if processed < total && time.Since(lastWrite) < progressInterval {
+			return nil
+		}
+		lastWrite = time.Now()
+
+		err := db.WithContext(ctx).Model(&models.AdminJob{}).Where("id = ?", job.ID).
+			Updates(map[string]interface{}{"processed": processed, "total": total}).Error
+		if err != nil {
+			return err
+		}
+		var cancelRequested bool
+		if err := db.WithContext(ctx).Model(&models.AdminJob{}).Where("id = ?", job.ID).Select("cancel_requested").Scan(&cancelRequested).Error; err != nil {
+			return err
+		}
+		if cancelRequested {
+			return errCancelled
+		}
+		return nil
+	}
+
+	updated, err := runners[job.Kind](ctx, db, progress)
+
+	status, message := models.AdminJobSucceeded, ""
+	switch {
+	case errors.Is(err, errCancelled):
+		status = models.AdminJobCancelled
+	case err != nil:
+		status, message = models.AdminJobFailed, err.Error()
+	}
+	if len(message) > 500 {
+		message = message[:500]
+	}
+
+	// Record the outcome, in the job and the audit log, even if ctx was
+	// cancelled mid-run.
+	writeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = db.WithContext(writeCtx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.AdminJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status":      status,
+			"error":       message,
+			"processed":   job.Processed,
+			"total":       job.Total,
+			"updated":     updated,
+			"finished_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActionAdminJobFinished, audit.Target("admin_job", job.ID), nil, map[string]interface{}{
+			"kind":        job.Kind,
+			"requestedBy": job.RequestedBy,
+			"status":      status,
+			"error":       message,
+			"updated":     updated,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("record job %d: %w", job.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package adminjobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/services/scoring"
+
+	"gorm.io/gorm"
+)
+
+func TestRecalculation_RunsOnceAtATimeAndReportsProgress(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		db.Create(&models.Agent{Name: name, APIKey: "swarm_sk_" + name, ClaimToken: "claim_" + name, IsActive: true})
+	}
+
+	job, err := Enqueue(db, models.AdminJobRecalculateScores, "admin")
+	if err != nil || job.Status != models.AdminJobQueued {
+		t.Fatalf("expected a queued job, got %+v, %v", job, err)
+	}
+	if again, err := Enqueue(db, models.AdminJobRecalculateScores, "admin"); !errors.Is(err, ErrAlreadyActive) || again.ID != job.ID {
+		t.Fatalf("expected the queued job back with ErrAlreadyActive, got %+v, %v", again, err)
+	}
+
+	ran, err := RunPending(context.Background(), db)
+	if err != nil || ran != 1 {
+		t.Fatalf("expected one job run, got %d, %v", ran, err)
+	}
+	done, _ := Get(db, job.ID)
+	if done.Status != models.AdminJobSucceeded || done.Processed != 3 || done.Total != 3 || done.Updated != 3 || done.FinishedAt == nil {
+		t.Fatalf("expected 3 of 3 agents processed, got %+v", done)
+	}
+	if _, err := Cancel(db, job.ID); !errors.Is(err, ErrFinished) {
+		t.Fatalf("expected a finished job to refuse cancelling, got %v", err)
+	}
+
+	// A new recalculation may be queued once the last one finished.
+	if _, err := Enqueue(db, models.AdminJobRecalculateScores, "admin"); err != nil {
+		t.Fatalf("expected a second recalculation to queue, got %v", err)
+	}
+}
+
+func TestCancel_StopsQueuedAndRunningJobs(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	queued, _ := Enqueue(db, models.AdminJobRecalculateScores, "admin")
+	cancelled, err := Cancel(db, queued.ID)
+	if err != nil || cancelled.Status != models.AdminJobCancelled {
+		t.Fatalf("expected the queued job cancelled at once, got %+v, %v", cancelled, err)
+	}
+	if ran, _ := RunPending(context.Background(), db); ran != 0 {
+		t.Fatalf("expected nothing left to run, ran %d", ran)
+	}
+
+	// A running job is asked to stop and does so at its next report.
+	original := progressInterval
+	progressInterval = 0
+	t.Cleanup(func() { progressInterval = original })
+
+	const kind = "test-cancel"
+	var running *models.AdminJob
+	runners[kind] = func(ctx context.Context, db *gorm.DB, progress scoring.Progress) (int, error) {
+		for i := 1; i <= 10; i++ {
+			if i == 4 {
+				if _, err := Cancel(db, running.ID); err != nil {
+					return i, err
+				}
+			}
+			if err := progress(i, 10); err != nil {
+				return i, err
+			}
+		}
+		return 10, nil
+	}
+	t.Cleanup(func() { delete(runners, kind) })
+
+	running, _ = Enqueue(db, kind, "admin")
+	if _, err := RunPending(context.Background(), db); err != nil {
+		t.Fatalf("RunPending: %v", err)
+	}
+	stopped, _ := Get(db, running.ID)
+	if stopped.Status != models.AdminJobCancelled || stopped.Processed >= 10 {
+		t.Fatalf("expected the running job to stop early, got %+v", stopped)
+	}
+}
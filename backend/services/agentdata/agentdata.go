@@ -0,0 +1,88 @@
+// Package agentdata handles what a claimed agent's owner can do with the
+// agent as a whole: deactivate it, retiring its keys and taking it off the
+// leaderboards, export everything it has done as an archive, and have its
+// personal data erased.
+package agentdata
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"socialpredict/audit"
+	"socialpredict/models"
+	"socialpredict/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotOwner is returned for an agent the user does not own, which
+	// callers report as not found so as not to reveal it.
+	ErrNotOwner = errors.New("agent not owned by user")
+	// ErrDeactivated is returned for deactivating an agent twice.
+	ErrDeactivated = errors.New("agent is already deactivated")
+)
+
+// Owned returns the agent agentID if userID owns it, or ErrNotOwner.
+func Owned(db *gorm.DB, userID, agentID int64) (*models.Agent, error) {
+	var agent models.Agent
+	err := db.Where("id = ? AND owner_user_id = ?", agentID, userID).First(&agent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotOwner
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// Deactivate deactivates the agent agentID that userID owns as of now. Its
+// API keys are revoked and its legacy key retired, so none of them
+// authenticates again, and as an inactive agent it drops off the
+// leaderboards. Its predictions, markets and comments are kept.
+func Deactivate(ctx context.Context, db *gorm.DB, userID, agentID int64, now time.Time) (*models.Agent, error) {
+	var agent *models.Agent
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			if agent, err = Owned(tx, userID, agentID); err != nil {
+				return err
+			}
+			if !agent.IsActive {
+				return ErrDeactivated
+			}
+			return deactivate(tx, agent, now)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return agent, nil
+}
+
+// deactivate retires the agent's keys and marks it inactive, in tx.
+func deactivate(tx *gorm.DB, agent *models.Agent, now time.Time) error {
+	before := *agent
+	before.APIKey = "" // keep the key out of the audit log
+
+	// A fresh key nobody holds retires the legacy key; the column is
+	// unique, so it cannot simply be cleared.
+	retired, err := models.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+	agent.APIKey = retired
+	agent.IsActive = false
+	if err := tx.Save(agent).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.AgentAPIKey{}).
+		Where("agent_id = ? AND revoked_at IS NULL", agent.ID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	after := *agent
+	after.APIKey = ""
+	return audit.Record(tx, audit.ActionAgentDeactivated, audit.Target("agent", agent.ID), before, after)
+}
@@ -0,0 +1,336 @@
+package agentdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"socialpredict/audit"
+	"socialpredict/models"
+	"socialpredict/repository"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrErasureRequested is returned for asking to erase an agent whose
+	// erasure is pending or done.
+	ErrErasureRequested = errors.New("agent erasure already requested")
+	// ErrNoErasure is returned when there is no erasure to show or
+	// cancel.
+	ErrNoErasure = errors.New("no erasure requested")
+)
+
+// erasureStep anonymizes or deletes one kind of record of the agent. Its
+// statement takes the named arguments agent, agentType, pseudonym and
+// erased; the rows it affects are counted under key in the certificate.
+type erasureStep struct {
+	key       string
+	statement string
+}
+
+// erasureSteps blank what the agent wrote and delete what only concerns
+// it. Prediction submissions are blanked once decided; a pending one still
+// needs its payload to become a prediction.
+var erasureSteps = []erasureStep{
+	{"predictions", "UPDATE predictions SET reasoning = '' WHERE agent_id = @agent AND reasoning <> ''"},
+	{"predictionRevisions", "UPDATE prediction_revisions SET reasoning = '' WHERE prediction_id IN (SELECT id FROM predictions WHERE agent_id = @agent) AND reasoning <> ''"},
+	{"sandboxPredictions", "UPDATE sandbox_predictions SET reasoning = '' WHERE agent_id = @agent AND reasoning <> ''"},
+	{"predictionSubmissions", "UPDATE pending_submissions SET payload = '{}' WHERE submitter_agent_id = @agent AND submission_type = 'prediction' AND final_status <> ''"},
+	{"predictionComments", "UPDATE prediction_comments SET content = @erased, author_name = @pseudonym WHERE author_type = @agentType AND author_id = @agent"},
+	{"proposalComments", "UPDATE proposal_comments SET content = @erased WHERE agent_id = @agent"},
+	{"submissionComments", "UPDATE submission_comments SET content = @erased WHERE agent_id = @agent"},
+	{"proposalVotes", "UPDATE proposal_votes SET reasoning = '' WHERE agent_id = @agent AND reasoning <> ''"},
+	{"councilVotes", "UPDATE council_votes SET reason = '' WHERE validator_id = @agent AND reason <> ''"},
+	{"resolutionVotes", "UPDATE resolution_votes SET reason = '', evidence = '' WHERE validator_id = @agent AND (reason <> '' OR evidence <> '')"},
+	{"resolutionDisputes", "UPDATE resolution_disputes SET reason = '', evidence = '' WHERE agent_id = @agent AND (reason <> '' OR evidence <> '')"},
+	{"nameChanges", "DELETE FROM agent_name_changes WHERE agent_id = @agent"},
+	{"notifications", "DELETE FROM notifications WHERE agent_id = @agent"},
+	{"apiKeys", "DELETE FROM agent_api_keys WHERE agent_id = @agent"},
+}
+
+// RequestErasure asks for the personal data of the agent agentID that
+// userID owns to be erased once the grace period has passed, deactivating
+// the agent now. requestedBy is the owner's principal ID.
+func RequestErasure(ctx context.Context, db *gorm.DB, userID, agentID int64, requestedBy string, now time.Time) (*models.DataErasure, error) {
+	grace := setup.EconomicsConfig().Retention.OrDefaults().ErasureDays
+	return requestErasure(ctx, db, agentID, func(tx *gorm.DB) (*models.Agent, error) {
+		return Owned(tx, userID, agentID)
+	}, requestedBy, &userID, now, now.AddDate(0, 0, grace))
+}
+
+// ScheduleErasure asks for the personal data of the agent agentID to be
+// erased on the next run of EraseDue, deactivating the agent now. Admins
+// use it for requests made outside the API, so there is no grace period.
+func ScheduleErasure(ctx context.Context, db *gorm.DB, agentID int64, requestedBy string, now time.Time) (*models.DataErasure, error) {
+	return requestErasure(ctx, db, agentID, func(tx *gorm.DB) (*models.Agent, error) {
+		var agent models.Agent
+		if err := tx.First(&agent, agentID).Error; err != nil {
+			return nil, err
+		}
+		return &agent, nil
+	}, requestedBy, nil, now, now)
+}
+
+func requestErasure(ctx context.Context, db *gorm.DB, agentID int64, load func(tx *gorm.DB) (*models.Agent, error), requestedBy string, requesterUserID *int64, now, scheduledFor time.Time) (*models.DataErasure, error) {
+	var erasure *models.DataErasure
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			agent, err := load(tx)
+			if err != nil {
+				return err
+			}
+			var open int64
+			if err := tx.Model(&models.DataErasure{}).
+				Where("agent_id = ? AND status IN ?", agentID, []string{models.ErasurePending, models.ErasureCompleted}).
+				Count(&open).Error; err != nil {
+				return err
+			}
+			if open > 0 {
+				return ErrErasureRequested
+			}
+			if agent.IsActive {
+				if err := deactivate(tx, agent, now); err != nil {
+					return err
+				}
+			}
+			erasure = &models.DataErasure{
+				AgentID:         agent.ID,
+				RequestedBy:     requestedBy,
+				RequesterUserID: requesterUserID,
+				Status:          models.ErasurePending,
+				RequestedAt:     now,
+				ScheduledFor:    scheduledFor,
+			}
+			if err := tx.Create(erasure).Error; err != nil {
+				return err
+			}
+			return audit.Record(tx, audit.ActionAgentErasureRequested, audit.Target("agent", agent.ID), nil, erasure)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return erasure, nil
+}
+
+// OwnerErasure returns the latest erasure userID asked for of the agent
+// agentID, or ErrNoErasure. It is found by who asked, as the agent no
+// longer has an owner once erased.
+func OwnerErasure(db *gorm.DB, userID, agentID int64) (*models.DataErasure, error) {
+	var erasure models.DataErasure
+	err := db.Where("agent_id = ? AND requester_user_id = ?", agentID, userID).Order("id DESC").First(&erasure).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoErasure
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &erasure, nil
+}
+
+// CancelErasure withdraws the pending erasure userID asked for of the agent
+// agentID, or returns ErrNoErasure. The agent stays deactivated.
+func CancelErasure(ctx context.Context, db *gorm.DB, userID, agentID int64, now time.Time) (*models.DataErasure, error) {
+	var erasure models.DataErasure
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("agent_id = ? AND requester_user_id = ? AND status = ?", agentID, userID, models.ErasurePending).
+			First(&erasure).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNoErasure
+		}
+		if err != nil {
+			return err
+		}
+		before := erasure
+		erasure.Status = models.ErasureCancelled
+		erasure.CancelledAt = &now
+		if err := tx.Save(&erasure).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActionAgentErasureCancelled, audit.Target("agent", agentID), before, erasure)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &erasure, nil
+}
+
+// EraseDue carries out the pending erasures scheduled for now or earlier
+// and returns how many. The scheduler runs it.
+func EraseDue(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+	var ids []int64
+	if err := db.Model(&models.DataErasure{}).
+		Where("status = ? AND scheduled_for <= ?", models.ErasurePending, now).
+		Order("id").Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	erased := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return erased, err
+		}
+		done, err := Erase(ctx, db, id, now)
+		if err != nil {
+			return erased, fmt.Errorf("erasure %d: %w", id, err)
+		}
+		if done {
+			erased++
+		}
+	}
+	return erased, nil
+}
+
+// Erase carries out the erasure erasureID if it is still pending, and
+// reports whether it did. The agent is renamed to its pseudonym and its
+// profile, owner and webhook cleared; what it wrote is blanked, see
+// erasureSteps; and the deletion certificate is recorded. Its scores,
+// counters, badges and predictions are kept. An agent already purged is
+// certified with nothing left to erase.
+func Erase(ctx context.Context, db *gorm.DB, erasureID int64, now time.Time) (bool, error) {
+	done := false
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		done = false
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var erasure models.DataErasure
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND status = ?", erasureID, models.ErasurePending).
+				First(&erasure).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // cancelled or carried out meanwhile
+			}
+			if err != nil {
+				return err
+			}
+
+			pseudonym := models.ErasedAgentName(erasure.AgentID)
+			counts := make(map[string]int64)
+			var agent models.Agent
+			err = tx.Unscoped().First(&agent, erasure.AgentID).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+			case err != nil:
+				return err
+			default:
+				if err := anonymize(tx, &agent, pseudonym, counts); err != nil {
+					return err
+				}
+			}
+
+			encoded, err := json.Marshal(counts)
+			if err != nil {
+				return err
+			}
+			erasure.Status = models.ErasureCompleted
+			erasure.CompletedAt = &now
+			erasure.Pseudonym = pseudonym
+			erasure.Erased = string(encoded)
+			certificate, err := erasure.Certificate()
+			if err != nil {
+				return err
+			}
+			if erasure.CertificateHash, err = certificate.Hash(); err != nil {
+				return err
+			}
+			if err := tx.Save(&erasure).Error; err != nil {
+				return err
+			}
+			done = true
+			// Only the certificate is logged: the agent as it was is the
+			// personal data being erased.
+			return audit.Record(tx, audit.ActionAgentErased, audit.Target("agent", erasure.AgentID), nil, certificate)
+		})
+	})
+	return done, err
+}
+
+// anonymize erases the agent's personal data in tx, counting the records
+// it changes by kind.
+func anonymize(tx *gorm.DB, agent *models.Agent, pseudonym string, counts map[string]int64) error {
+	args := map[string]interface{}{
+		"agent":     agent.ID,
+		"agentType": string(models.ActorTypeAgent),
+		"pseudonym": pseudonym,
+		"erased":    models.ErasedContent,
+	}
+	for _, step := range erasureSteps {
+		result := tx.Exec(step.statement, args)
+		if result.Error != nil {
+			return fmt.Errorf("%s: %w", step.key, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			counts[step.key] = result.RowsAffected
+		}
+	}
+
+	bets, err := renameShadowUser(tx, agent, pseudonym)
+	if err != nil {
+		return err
+	}
+	if bets > 0 {
+		counts["bets"] = bets
+	}
+
+	agent.Name = pseudonym
+	agent.Description = ""
+	agent.OwnerUserID = nil
+	agent.ClaimCode = ""
+	agent.AvatarURL, agent.FrameworkType, agent.PersonalEmoji = "", "", ""
+	agent.ModelFamily, agent.ModelProvider, agent.ParameterScale, agent.ToolsUsed, agent.AutonomyLevel = "", "", "", "", ""
+	agent.WebhookURL, agent.WebhookSecret = "", ""
+	if err := tx.Save(agent).Error; err != nil {
+		return err
+	}
+	counts["agent"] = 1
+	return nil
+}
+
+// renameShadowUser moves the agent's bets onto a shadow user named after
+// its pseudonym and returns how many it moved. Bets reference the username,
+// so a new row takes over rather than the old one being renamed.
+func renameShadowUser(tx *gorm.DB, agent *models.Agent, pseudonym string) (int64, error) {
+	var shadow models.User
+	err := tx.Unscoped().Where("agent_id = ?", agent.ID).First(&shadow).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	username := models.AgentShadowUsername(pseudonym)
+	if shadow.Username == username {
+		return 0, nil
+	}
+
+	// The old row gives up its unique agent ID and key to the new one.
+	if err := tx.Model(&shadow).Updates(map[string]interface{}{"agent_id": nil, "api_key": nil}).Error; err != nil {
+		return 0, err
+	}
+	replacement := shadow
+	replacement.Model = gorm.Model{CreatedAt: shadow.CreatedAt}
+	replacement.ID = 0
+	replacement.Username, replacement.DisplayName = username, username
+	replacement.Email = fmt.Sprintf("%s@agents.invalid", pseudonym)
+	replacement.AgentID = nil
+	if err := tx.Create(&replacement).Error; err != nil {
+		return 0, err
+	}
+	moved := tx.Unscoped().Model(&models.Bet{}).Where("username = ?", shadow.Username).Update("username", username)
+	if moved.Error != nil {
+		return 0, moved.Error
+	}
+	if err := tx.Unscoped().Delete(&shadow).Error; err != nil {
+		return 0, err
+	}
+	agentID := agent.ID
+	if err := tx.Model(&replacement).Update("agent_id", &agentID).Error; err != nil {
+		return 0, err
+	}
+	return moved.RowsAffected, nil
+}
@@ -0,0 +1,280 @@
+package agentdata
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/services/exportstream"
+
+	"gorm.io/gorm"
+)
+
+// Export formats.
+const (
+	FormatJSON   = "json"   // one document with a list per section
+	FormatNDJSON = "ndjson" // a {"type","data"} line per record
+)
+
+// ErrUnknownFormat is returned for a format other than FormatJSON and
+// FormatNDJSON.
+var ErrUnknownFormat = errors.New("format must be json or ndjson")
+
+// exportBatchSize is how many rows are read at a time, so an agent with a
+// long history is never held in memory at once.
+const exportBatchSize = 500
+
+// section is one kind of record in an export. Its JSON key names the list
+// in a JSON export and its type each line in an NDJSON one.
+type section struct {
+	key, typ string
+	query    func(db *gorm.DB, agentID int64) *gorm.DB
+	rows     func() interface{}                // a new pointer to a slice of rows
+	wrap     func(row interface{}) interface{} // optional: what is written for a row
+}
+
+// The records whose relations are not preloaded are wrapped to leave out
+// the zero values the relations would be written as; the export is all one
+// agent's anyway.
+type market struct {
+	models.Market
+	Creator *struct{} `json:"Creator,omitempty"`
+}
+
+type proposalVote struct {
+	models.ProposalVote
+	Agent *struct{} `json:"agent,omitempty"`
+}
+
+type proposalComment struct {
+	models.ProposalComment
+	Agent *struct{} `json:"agent,omitempty"`
+}
+
+var sections = []section{
+	{
+		key: "predictions", typ: "prediction",
+		query: func(db *gorm.DB, id int64) *gorm.DB { return db.Where("agent_id = ?", id) },
+		rows:  func() interface{} { return &[]models.Prediction{} },
+	},
+	{
+		key: "predictionRevisions", typ: "prediction_revision",
+		query: func(db *gorm.DB, id int64) *gorm.DB {
+			return db.Where("prediction_id IN (?)", db.Model(&models.Prediction{}).Select("id").Where("agent_id = ?", id))
+		},
+		rows: func() interface{} { return &[]models.PredictionRevision{} },
+	},
+	{
+		key: "markets", typ: "market",
+		query: func(db *gorm.DB, id int64) *gorm.DB { return db.Where("creator_agent_id = ?", id) },
+		rows:  func() interface{} { return &[]models.Market{} },
+		wrap:  func(row interface{}) interface{} { return market{Market: row.(models.Market)} },
+	},
+	{
+		key: "predictionVotes", typ: "prediction_vote",
+		query: func(db *gorm.DB, id int64) *gorm.DB {
+			return db.Where("voter_type = ? AND voter_id = ?", models.ActorTypeAgent, id)
+		},
+		rows: func() interface{} { return &[]models.PredictionVote{} },
+	},
+	{
+		key: "councilVotes", typ: "council_vote",
+		query: func(db *gorm.DB, id int64) *gorm.DB { return db.Where("validator_id = ?", id) },
+		rows:  func() interface{} { return &[]models.CouncilVote{} },
+	},
+	{
+		key: "resolutionVotes", typ: "resolution_vote",
+		query: func(db *gorm.DB, id int64) *gorm.DB { return db.Where("validator_id = ?", id) },
+		rows:  func() interface{} { return &[]models.ResolutionVote{} },
+	},
+	{
+		key: "proposalVotes", typ: "proposal_vote",
+		query: func(db *gorm.DB, id int64) *gorm.DB { return db.Where("agent_id = ?", id) },
+		rows:  func() interface{} { return &[]models.ProposalVote{} },
+		wrap:  func(row interface{}) interface{} { return proposalVote{ProposalVote: row.(models.ProposalVote)} },
+	},
+	{
+		key: "predictionComments", typ: "prediction_comment",
+		query: func(db *gorm.DB, id int64) *gorm.DB {
+			return db.Where("author_type = ? AND author_id = ?", models.ActorTypeAgent, id)
+		},
+		rows: func() interface{} { return &[]models.PredictionComment{} },
+	},
+	{
+		key: "proposalComments", typ: "proposal_comment",
+		query: func(db *gorm.DB, id int64) *gorm.DB { return db.Where("agent_id = ?", id) },
+		rows:  func() interface{} { return &[]models.ProposalComment{} },
+		wrap: func(row interface{}) interface{} {
+			return proposalComment{ProposalComment: row.(models.ProposalComment)}
+		},
+	},
+	{
+		key: "submissionComments", typ: "submission_comment",
+		query: func(db *gorm.DB, id int64) *gorm.DB { return db.Where("agent_id = ?", id) },
+		rows:  func() interface{} { return &[]models.SubmissionComment{} },
+	},
+}
+
+// Export writes an archive of everything agent has done to w in format, as
+// of now: its profile and stats, then its predictions and their revisions,
+// the markets it created, its votes and its comments, oldest first. Rows
+// are read and written in batches, so the archive streams; an error part
+// way leaves it truncated.
+func Export(ctx context.Context, db *gorm.DB, agent *models.Agent, format string, w io.Writer, now time.Time) error {
+	var enc encoder
+	stream := exportstream.New(w)
+	switch format {
+	case FormatJSON:
+		enc = &jsonEncoder{w: stream.Writer}
+	case FormatNDJSON:
+		enc = &ndjsonEncoder{stream}
+	default:
+		return ErrUnknownFormat
+	}
+	db = db.WithContext(ctx)
+
+	if err := enc.begin(header{ExportedAt: now, Agent: agent.ToPublic(), Stats: agent.ToStats()}); err != nil {
+		return err
+	}
+	for _, s := range sections {
+		if err := enc.beginSection(s); err != nil {
+			return err
+		}
+		rows := s.rows()
+		err := s.query(db, agent.ID).FindInBatches(rows, exportBatchSize, func(tx *gorm.DB, batch int) error {
+			list := reflect.ValueOf(rows).Elem()
+			for i := 0; i < list.Len(); i++ {
+				row := list.Index(i).Interface()
+				if s.wrap != nil {
+					row = s.wrap(row)
+				}
+				if err := enc.record(s, row); err != nil {
+					return err
+				}
+			}
+			return stream.Flush()
+		}).Error
+		if err != nil {
+			return fmt.Errorf("export %s: %w", s.key, err)
+		}
+		if err := enc.endSection(s); err != nil {
+			return err
+		}
+	}
+	if err := enc.end(); err != nil {
+		return err
+	}
+	return stream.Flush()
+}
+
+// header opens an export.
+type header struct {
+	ExportedAt time.Time
+	Agent      models.AgentPublic
+	Stats      models.AgentStats
+}
+
+// encoder writes an export in one format.
+type encoder interface {
+	begin(h header) error
+	beginSection(s section) error
+	record(s section, row interface{}) error
+	endSection(s section) error
+	end() error
+}
+
+// jsonEncoder writes a JSON object: exportedAt, agent and stats, then a
+// list per section.
+type jsonEncoder struct {
+	w     *bufio.Writer
+	first bool // no record written yet in the current section
+}
+
+func (e *jsonEncoder) begin(h header) error {
+	e.w.WriteString("{")
+	if err := e.field("exportedAt", h.ExportedAt); err != nil {
+		return err
+	}
+	e.w.WriteString(",")
+	if err := e.field("agent", h.Agent); err != nil {
+		return err
+	}
+	e.w.WriteString(",")
+	return e.field("stats", h.Stats)
+}
+
+func (e *jsonEncoder) field(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.w, "%q:", key)
+	_, err = e.w.Write(raw)
+	return err
+}
+
+func (e *jsonEncoder) beginSection(s section) error {
+	e.first = true
+	_, err := fmt.Fprintf(e.w, ",%q:[", s.key)
+	return err
+}
+
+func (e *jsonEncoder) record(s section, row interface{}) error {
+	raw, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if !e.first {
+		e.w.WriteString(",")
+	}
+	e.first = false
+	_, err = e.w.Write(raw)
+	return err
+}
+
+func (e *jsonEncoder) endSection(s section) error {
+	_, err := e.w.WriteString("]")
+	return err
+}
+
+func (e *jsonEncoder) end() error {
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+// ndjsonEncoder writes a line per record, each {"type": ..., "data": ...},
+// starting with the agent and its stats.
+type ndjsonEncoder struct {
+	stream *exportstream.Stream
+}
+
+type ndjsonLine struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+func (e *ndjsonEncoder) begin(h header) error {
+	if err := e.stream.NDJSON(ndjsonLine{Type: "export", Data: map[string]time.Time{"exportedAt": h.ExportedAt}}); err != nil {
+		return err
+	}
+	if err := e.stream.NDJSON(ndjsonLine{Type: "agent", Data: h.Agent}); err != nil {
+		return err
+	}
+	return e.stream.NDJSON(ndjsonLine{Type: "stats", Data: h.Stats})
+}
+
+func (e *ndjsonEncoder) beginSection(s section) error { return nil }
+
+func (e *ndjsonEncoder) record(s section, row interface{}) error {
+	return e.stream.NDJSON(ndjsonLine{Type: s.typ, Data: row})
+}
+
+func (e *ndjsonEncoder) endSection(s section) error { return nil }
+
+func (e *ndjsonEncoder) end() error { return nil }
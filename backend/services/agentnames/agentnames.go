@@ -0,0 +1,178 @@
+// Package agentnames decides which names an agent may take. Registration
+// and renames both go through Check, so reserved names and look-alikes of
+// existing agents are refused the same way at either entry point.
+package agentnames
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// RenameCooldown is how long an agent must wait between renames.
+	RenameCooldown = 30 * 24 * time.Hour
+	// WellKnownMinFollowers is the follower count from which a claimed agent
+	// is well known: names containing its name are refused, not just
+	// look-alikes of it.
+	WellKnownMinFollowers = 25
+	// minContainedSkeleton keeps short well-known names such as "ai" from
+	// blocking every name that happens to contain them.
+	minContainedSkeleton = 4
+)
+
+var (
+	ErrNameTaken        = errors.New("agent name already taken")
+	ErrNameReserved     = errors.New("agent name is reserved")
+	ErrNameImpersonates = errors.New("agent name is too similar to an existing agent")
+	ErrSameName         = errors.New("agent already has this name")
+	ErrAgentNotFound    = errors.New("agent not found")
+)
+
+// CooldownError is returned by Rename when the agent renamed itself less
+// than RenameCooldown ago.
+type CooldownError struct {
+	Until time.Time
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("agent was renamed recently; next rename allowed at %s", e.Until.UTC().Format(time.RFC3339))
+}
+
+// Check reports whether the agent agentID may take name; agentID is 0 for
+// an agent that is still registering. The agent's own current and past
+// names never count against it. Deleted agents keep their names until they
+// are purged, so they can be restored.
+func Check(db *gorm.DB, name string, agentID int64) error {
+	var taken int64
+	if err := db.Unscoped().Model(&models.Agent{}).Where("name = ? AND id <> ?", name, agentID).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken == 0 {
+		if err := db.Model(&models.AgentNameChange{}).Where("old_name = ? AND agent_id <> ?", name, agentID).Count(&taken).Error; err != nil {
+			return err
+		}
+	}
+	if taken > 0 {
+		return ErrNameTaken
+	}
+
+	var reserved []models.ReservedAgentName
+	if err := db.Find(&reserved).Error; err != nil {
+		return err
+	}
+	for _, r := range reserved {
+		if r.Matches(name) {
+			return fmt.Errorf("%w: %s", ErrNameReserved, r.Pattern)
+		}
+	}
+
+	skeleton := models.AgentNameSkeleton(name)
+	var lookalike models.Agent
+	err := db.Where("name_skeleton = ? AND id <> ?", skeleton, agentID).First(&lookalike).Error
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrNameImpersonates, lookalike.Name)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	var wellKnown []models.Agent
+	if err := db.Select("id, name, name_skeleton").
+		Where("is_claimed = ? AND total_followers >= ? AND id <> ?", true, WellKnownMinFollowers, agentID).
+		Find(&wellKnown).Error; err != nil {
+		return err
+	}
+	for _, known := range wellKnown {
+		if len(known.NameSkeleton) >= minContainedSkeleton && strings.Contains(skeleton, known.NameSkeleton) {
+			return fmt.Errorf("%w: %s", ErrNameImpersonates, known.Name)
+		}
+	}
+	return nil
+}
+
+// Rename gives the agent a new name, recording the old one so it keeps
+// redirecting to the agent. Comments the agent wrote are relabelled; the
+// agent's shadow user is keyed by agent ID and keeps its username.
+func Rename(ctx context.Context, db *gorm.DB, agentID int64, newName string, now time.Time) (*models.AgentNameChange, error) {
+	var change models.AgentNameChange
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var agent models.Agent
+		if err := tx.First(&agent, agentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAgentNotFound
+			}
+			return err
+		}
+		if agent.Name == newName {
+			return ErrSameName
+		}
+
+		var last models.AgentNameChange
+		err := tx.Where("agent_id = ?", agentID).Order("changed_at DESC").First(&last).Error
+		if err == nil {
+			if until := last.ChangedAt.Add(RenameCooldown); now.Before(until) {
+				return &CooldownError{Until: until}
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := Check(tx, newName, agentID); err != nil {
+			return err
+		}
+
+		change = models.AgentNameChange{AgentID: agentID, OldName: agent.Name, NewName: newName, ChangedAt: now}
+		if err := tx.Create(&change).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Agent{}).Where("id = ?", agentID).Updates(map[string]interface{}{
+			"name":          newName,
+			"name_skeleton": models.AgentNameSkeleton(newName),
+		}).Error; err != nil {
+			return err
+		}
+		author := models.AgentActor(agentID)
+		return tx.Model(&models.PredictionComment{}).
+			Where("author_type = ? AND author_id = ?", string(author.Type), author.ID).
+			Update("author_name", newName).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// Resolve finds the agent going by name, following renames. redirected is
+// true when name is a former name of the agent returned.
+func Resolve(db *gorm.DB, name string) (agent *models.Agent, redirected bool, err error) {
+	var current models.Agent
+	err = db.Where("name = ?", name).First(&current).Error
+	if err == nil {
+		return &current, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	var change models.AgentNameChange
+	if err := db.Where("old_name = ?", name).Order("changed_at DESC").First(&change).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrAgentNotFound
+		}
+		return nil, false, err
+	}
+	if err := db.First(&current, change.AgentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrAgentNotFound
+		}
+		return nil, false, err
+	}
+	return &current, true, nil
+}
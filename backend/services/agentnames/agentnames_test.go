@@ -0,0 +1,83 @@
+package agentnames
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestCheck_RefusesReservedAndLookalikeNames(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	oracle := modelstesting.GenerateAgent("Oracle")
+	oracle.IsClaimed = true
+	oracle.TotalFollowers = WellKnownMinFollowers
+	db.Create(&oracle)
+	quiet := modelstesting.GenerateAgent("quiet_bot")
+	db.Create(&quiet)
+	db.Create(&models.ReservedAgentName{Pattern: "Official", IsPrefix: true})
+	db.Create(&models.ReservedAgentName{Pattern: "admin"})
+
+	tests := []struct {
+		name    string
+		agentID int64
+		want    error
+	}{
+		{"Oracle", 0, ErrNameTaken},
+		{"0RACLE", 0, ErrNameImpersonates},
+		{"The_Oracle_v2", 0, ErrNameImpersonates}, // contains a well-known name
+		{"quiet_bot_fan", 0, nil},                 // quiet_bot is not well known
+		{"Quiet-B0t", 0, ErrNameImpersonates},     // but look-alikes are refused
+		{"Quiet-B0t", quiet.ID, nil},              // except for the agent itself
+		{"0fficial_Markets", 0, ErrNameReserved},
+		{"Admin", 0, ErrNameReserved},
+		{"administrator", 0, nil},
+	}
+	for _, tt := range tests {
+		if err := Check(db, tt.name, tt.agentID); !errors.Is(err, tt.want) {
+			t.Errorf("Check(%q, %d) = %v, want %v", tt.name, tt.agentID, err, tt.want)
+		}
+	}
+}
+
+func TestRename_RedirectsAndRateLimits(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+	agent := modelstesting.GenerateAgent("first_name")
+	db.Create(&agent)
+	other := modelstesting.GenerateAgent("other")
+	db.Create(&other)
+	comment := models.PredictionComment{PredictionID: 1, AuthorName: agent.Name, Content: "hi"}
+	comment.SetAuthor(models.AgentActor(agent.ID))
+	db.Create(&comment)
+
+	now := time.Now()
+	if _, err := Rename(ctx, db, agent.ID, "second_name", now); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	resolved, redirected, err := Resolve(db, "first_name")
+	if err != nil || !redirected || resolved.Name != "second_name" {
+		t.Fatalf("expected first_name to redirect to second_name, got %+v, %v, %v", resolved, redirected, err)
+	}
+	db.First(&comment, comment.ID)
+	if comment.AuthorName != "second_name" {
+		t.Fatalf("expected comment relabelled, got %q", comment.AuthorName)
+	}
+
+	// The old name stays with its agent.
+	if err := Check(db, "first_name", other.ID); !errors.Is(err, ErrNameTaken) {
+		t.Fatalf("expected old name to be taken, got %v", err)
+	}
+
+	var cooldown *CooldownError
+	if _, err := Rename(ctx, db, agent.ID, "third_name", now.Add(time.Hour)); !errors.As(err, &cooldown) {
+		t.Fatalf("expected cooldown error, got %v", err)
+	}
+	if _, err := Rename(ctx, db, agent.ID, "first_name", now.Add(RenameCooldown)); err != nil {
+		t.Fatalf("expected to reclaim own old name after the cooldown, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package auction runs the optional closing auction of a market. During
+// the final Window before a market closes, agents submit sealed final
+// probabilities. Bids stay hidden until close, when they are revealed and
+// their reputation-weighted mean becomes the market's official final
+// consensus, so late agents cannot simply herd onto the visible tally.
+package auction
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Window is how long before close the auction accepts bids.
+const Window = time.Hour
+
+// minBidWeight keeps agents with no reputation yet from being ignored.
+const minBidWeight = 0.01
+
+var (
+	ErrNoAuction          = errors.New("market has no closing auction")
+	ErrAuctionNotOpen     = errors.New("closing auction is not open")
+	ErrInvalidProbability = errors.New("probability must be between 0 and 1")
+)
+
+// Opens returns when the market's auction starts accepting bids.
+func Opens(market models.Market) time.Time {
+	return market.ResolutionDateTime.Add(-Window)
+}
+
+// IsOpen reports whether the market's auction accepts bids at now.
+func IsOpen(market models.Market, now time.Time) bool {
+	return market.ClosingAuction && !market.IsResolved &&
+		!now.Before(Opens(market)) && now.Before(market.ResolutionDateTime)
+}
+
+// IsRevealed reports whether the market's bids have been revealed.
+func IsRevealed(market models.Market) bool {
+	return market.FinalConsensusAt != nil
+}
+
+// SubmitBid records agentID's sealed probability for market, replacing any
+// earlier bid from the same agent.
+func SubmitBid(ctx context.Context, db *gorm.DB, market models.Market, agentID int64, probability float64, now time.Time) (*models.ClosingBid, error) {
+	if !market.ClosingAuction {
+		return nil, ErrNoAuction
+	}
+	if !IsOpen(market, now) {
+		return nil, ErrAuctionNotOpen
+	}
+	if probability < 0 || probability > 1 {
+		return nil, ErrInvalidProbability
+	}
+
+	bid := &models.ClosingBid{MarketID: market.ID, AgentID: agentID, Probability: probability}
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "market_id"}, {Name: "agent_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"probability", "updated_at"}),
+	}).Create(bid).Error
+	if err != nil {
+		return nil, err
+	}
+	return bid, nil
+}
+
+// CountBids returns how many agents have bid on the market. It reveals
+// nothing about the bids themselves.
+func CountBids(db *gorm.DB, marketID int64) (int64, error) {
+	var count int64
+	err := db.Model(&models.ClosingBid{}).Where("market_id = ?", marketID).Count(&count).Error
+	return count, err
+}
+
+// RevealedBids returns the market's bids once they have been revealed,
+// heaviest first, and nil before that.
+func RevealedBids(db *gorm.DB, market models.Market) ([]models.ClosingBid, error) {
+	if !IsRevealed(market) {
+		return nil, nil
+	}
+	var bids []models.ClosingBid
+	err := db.Where("market_id = ?", market.ID).Order("weight DESC, id").Find(&bids).Error
+	return bids, err
+}
+
+// Reveal closes the market's auction if it has ended: every bid gets the
+// bidder's reputation as its weight and the weighted mean is stored as the
+// market's final consensus. A market without bids is marked revealed with
+// no final consensus. It reports whether this call revealed the auction.
+func Reveal(ctx context.Context, db *gorm.DB, marketID int64, now time.Time) (bool, error) {
+	revealed := false
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		revealed = false
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var market models.Market
+			if err := tx.First(&market, marketID).Error; err != nil {
+				return err
+			}
+			if !market.ClosingAuction || IsRevealed(market) || now.Before(market.ResolutionDateTime) {
+				return nil
+			}
+
+			var bids []models.ClosingBid
+			if err := tx.Where("market_id = ?", market.ID).Find(&bids).Error; err != nil {
+				return err
+			}
+			reputations, err := reputationsOf(tx, bids)
+			if err != nil {
+				return err
+			}
+
+			var weighted, total float64
+			for i := range bids {
+				bid := &bids[i]
+				bid.Weight = reputations[bid.AgentID]
+				if bid.Weight < minBidWeight {
+					bid.Weight = minBidWeight
+				}
+				bid.RevealedAt = &now
+				if err := tx.Save(bid).Error; err != nil {
+					return err
+				}
+				weighted += bid.Probability * bid.Weight
+				total += bid.Weight
+			}
+
+			if total > 0 {
+				consensus := weighted / total
+				market.FinalConsensus = &consensus
+			}
+			market.FinalConsensusAt = &now
+			if err := tx.Save(&market).Error; err != nil {
+				return err
+			}
+			revealed = true
+			return nil
+		})
+	})
+	return revealed, err
+}
+
+// RevealDue reveals every closing auction that has ended and returns how
+// many were revealed.
+func RevealDue(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	var marketIDs []int64
+	if err := db.WithContext(ctx).Model(&models.Market{}).
+		Where("closing_auction = ? AND final_consensus_at IS NULL AND resolution_date_time <= ?", true, now).
+		Pluck("id", &marketIDs).Error; err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, marketID := range marketIDs {
+		revealed, err := Reveal(ctx, db, marketID, now)
+		if err != nil {
+			return count, err
+		}
+		if revealed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func reputationsOf(tx *gorm.DB, bids []models.ClosingBid) (map[int64]float64, error) {
+	reputations := make(map[int64]float64, len(bids))
+	if len(bids) == 0 {
+		return reputations, nil
+	}
+	agentIDs := make([]int64, len(bids))
+	for i, bid := range bids {
+		agentIDs[i] = bid.AgentID
+	}
+
+	var agents []models.Agent
+	if err := tx.Select("id", "reputation").Where("id IN ?", agentIDs).Find(&agents).Error; err != nil {
+		return nil, err
+	}
+	for _, agent := range agents {
+		reputations[agent.ID] = agent.Reputation
+	}
+	return reputations, nil
+}
@@ -0,0 +1,91 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestClosingAuction_SealedBidsSetFinalConsensus(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	market.ClosingAuction = true
+	closes := market.ResolutionDateTime
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+
+	bull := modelstesting.GenerateAgent("bull")
+	bull.Reputation = 0.9
+	db.Create(&bull)
+	bear := modelstesting.GenerateAgent("bear")
+	bear.Reputation = 0.3
+	db.Create(&bear)
+
+	if _, err := SubmitBid(ctx, db, market, bull.ID, 0.8, closes.Add(-2*time.Hour)); !errors.Is(err, ErrAuctionNotOpen) {
+		t.Fatalf("expected bids before the window to be refused, got %v", err)
+	}
+
+	inWindow := closes.Add(-30 * time.Minute)
+	if _, err := SubmitBid(ctx, db, market, bull.ID, 0.5, inWindow); err != nil {
+		t.Fatalf("SubmitBid: %v", err)
+	}
+	// A second bid replaces the first.
+	if _, err := SubmitBid(ctx, db, market, bull.ID, 0.8, inWindow.Add(time.Minute)); err != nil {
+		t.Fatalf("SubmitBid replace: %v", err)
+	}
+	if _, err := SubmitBid(ctx, db, market, bear.ID, 0.2, inWindow); err != nil {
+		t.Fatalf("SubmitBid: %v", err)
+	}
+	if count, _ := CountBids(db, market.ID); count != 2 {
+		t.Fatalf("expected 2 sealed bids, got %d", count)
+	}
+	if bids, _ := RevealedBids(db, market); bids != nil {
+		t.Fatalf("expected bids sealed before close, got %v", bids)
+	}
+
+	if n, err := RevealDue(ctx, db, inWindow); err != nil || n != 0 {
+		t.Fatalf("expected nothing revealed before close, n=%d err=%v", n, err)
+	}
+	if n, err := RevealDue(ctx, db, closes); err != nil || n != 1 {
+		t.Fatalf("expected auction revealed at close, n=%d err=%v", n, err)
+	}
+	if n, _ := RevealDue(ctx, db, closes.Add(time.Minute)); n != 0 {
+		t.Fatalf("expected auction revealed only once, got %d", n)
+	}
+
+	var stored models.Market
+	db.First(&stored, market.ID)
+	want := (0.8*0.9 + 0.2*0.3) / (0.9 + 0.3)
+	if stored.FinalConsensus == nil || math.Abs(*stored.FinalConsensus-want) > 1e-9 || !IsRevealed(stored) {
+		t.Fatalf("expected final consensus %v, got %+v", want, stored.FinalConsensus)
+	}
+
+	bids, err := RevealedBids(db, stored)
+	if err != nil || len(bids) != 2 || bids[0].AgentID != bull.ID || bids[0].RevealedAt == nil {
+		t.Fatalf("expected revealed bids heaviest first, got %+v (%v)", bids, err)
+	}
+
+	if _, err := SubmitBid(ctx, db, stored, bear.ID, 0.9, closes.Add(time.Minute)); !errors.Is(err, ErrAuctionNotOpen) {
+		t.Fatalf("expected bids after close to be refused, got %v", err)
+	}
+}
+
+func TestSubmitBid_RequiresAuction(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	market := modelstesting.GenerateMarket(1, "creator")
+
+	now := market.ResolutionDateTime.Add(-time.Minute)
+	if _, err := SubmitBid(context.Background(), db, market, 1, 0.5, now); !errors.Is(err, ErrNoAuction) {
+		t.Fatalf("expected ErrNoAuction, got %v", err)
+	}
+}
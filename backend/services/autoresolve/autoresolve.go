@@ -0,0 +1,216 @@
+// Package autoresolve resolves markets from oracles. A market created with
+// an OracleSpec has AutoResolve set; once its resolution time passes the
+// scheduler's auto-resolve job asks the spec's oracle for the answer and
+// resolves the market through the resolution pipeline. Every attempt, and
+// the data it was based on, is kept as a models.AutoResolution.
+//
+// Oracles are pluggable: the built-in ones fetch a value from any JSON API,
+// read a crypto spot price or check whether a date has passed, and more can
+// be added with Resolver.Register.
+package autoresolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/services/resolution"
+
+	"gorm.io/gorm"
+)
+
+// MaxAttempts is how many failed attempts a market gets before the job
+// gives up on it and leaves it to be resolved by hand.
+const MaxAttempts = 10
+
+// maxResponseLength caps how much of an oracle's raw response is kept.
+const maxResponseLength = 4000
+
+var ErrUnknownOracle = errors.New("unknown oracle")
+
+// Observation is what an oracle saw and the outcome it implies.
+type Observation struct {
+	Outcome  string // resolution.OutcomeYes or OutcomeNo
+	Value    string // the observed value
+	Source   string // what was consulted, e.g. the URL fetched
+	Response string // the raw data the value was read from
+}
+
+// Oracle answers market questions from outside data.
+type Oracle interface {
+	// Name is the OracleSpec.Oracle value that selects the oracle.
+	Name() string
+	// Validate checks that spec has everything the oracle needs.
+	Validate(spec models.OracleSpec) error
+	// Observe evaluates spec at now.
+	Observe(ctx context.Context, spec models.OracleSpec, now time.Time) (Observation, error)
+}
+
+// Resolver resolves markets with the oracles registered on it.
+type Resolver struct {
+	db      *gorm.DB
+	oracles map[string]Oracle
+}
+
+// NewResolver returns a resolver with the built-in oracles registered.
+func NewResolver(db *gorm.DB) *Resolver {
+	client := SafeClient()
+	r := &Resolver{db: db, oracles: map[string]Oracle{}}
+	r.Register(HTTPJSON{Client: client})
+	r.Register(CryptoPrice{Client: client})
+	r.Register(DatePassed{})
+	return r
+}
+
+// Register adds o, replacing any oracle of the same name.
+func (r *Resolver) Register(o Oracle) {
+	r.oracles[o.Name()] = o
+}
+
+// Validate checks spec against its oracle.
+func (r *Resolver) Validate(spec models.OracleSpec) error {
+	oracle, ok := r.oracles[spec.Oracle]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownOracle, spec.Oracle)
+	}
+	return oracle.Validate(spec)
+}
+
+// Validate checks spec against the built-in oracles.
+func Validate(spec models.OracleSpec) error {
+	return NewResolver(nil).Validate(spec)
+}
+
+// RunDue attempts every unresolved auto-resolving market whose resolution
+// time has passed and that has failed fewer than MaxAttempts times. It
+// returns how many markets were resolved. An oracle failure is recorded and
+// retried on the next run; only database errors are returned.
+func (r *Resolver) RunDue(ctx context.Context, now time.Time) (int, error) {
+	db := r.db.WithContext(ctx)
+
+	var markets []models.Market
+	if err := db.Where("auto_resolve = ? AND is_resolved = ? AND resolution_date_time <= ? AND resolution_source <> ''", true, false, now).
+		Order("resolution_date_time").
+		Find(&markets).Error; err != nil {
+		return 0, err
+	}
+	if len(markets) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(markets))
+	for i, m := range markets {
+		ids[i] = m.ID
+	}
+	var failures []struct {
+		MarketID int64
+		Failed   int
+	}
+	if err := db.Model(&models.AutoResolution{}).
+		Select("market_id, COUNT(*) AS failed").
+		Where("status = ? AND market_id IN ?", models.AutoResolutionFailed, ids).
+		Group("market_id").
+		Scan(&failures).Error; err != nil {
+		return 0, err
+	}
+	failed := make(map[int64]int, len(failures))
+	for _, f := range failures {
+		failed[f.MarketID] = f.Failed
+	}
+
+	resolved := 0
+	for i := range markets {
+		if err := ctx.Err(); err != nil {
+			return resolved, err
+		}
+		if failed[markets[i].ID] >= MaxAttempts {
+			continue
+		}
+		attempt, err := r.Resolve(ctx, &markets[i], now)
+		if err != nil {
+			return resolved, err
+		}
+		if attempt != nil && attempt.Status == models.AutoResolutionResolved {
+			resolved++
+		}
+	}
+	return resolved, nil
+}
+
+// Resolve asks market's oracle for the outcome and resolves the market with
+// it, recording the attempt either way. It returns the attempt, or nil if
+// the market was resolved by someone else first; the error is only set if
+// the attempt could not be recorded.
+func (r *Resolver) Resolve(ctx context.Context, market *models.Market, now time.Time) (*models.AutoResolution, error) {
+	attempt := &models.AutoResolution{MarketID: market.ID, Spec: market.ResolutionSource}
+
+	spec, err := market.OracleSpec()
+	if err != nil || spec == nil {
+		return r.fail(ctx, attempt, errors.New("market has no valid oracle spec"))
+	}
+	attempt.Oracle = spec.Oracle
+	if err := r.Validate(*spec); err != nil {
+		return r.fail(ctx, attempt, err)
+	}
+
+	observation, err := r.oracles[spec.Oracle].Observe(ctx, *spec, now)
+	attempt.ObservedValue = truncate(observation.Value, 500)
+	attempt.Source = truncate(observation.Source, 500)
+	attempt.Response = truncate(observation.Response, maxResponseLength)
+	if err != nil {
+		return r.fail(ctx, attempt, err)
+	}
+	attempt.Outcome = observation.Outcome
+	attempt.Status = models.AutoResolutionResolved
+
+	_, err = resolution.ResolveRecorded(ctx, r.db, market.ID, observation.Outcome, requireAutoResolve, func(tx *gorm.DB, _ *models.Market) error {
+		return tx.Create(attempt).Error
+	})
+	switch {
+	case errors.Is(err, resolution.ErrAlreadyResolved), errors.Is(err, resolution.ErrMarketNotFound):
+		return nil, nil
+	case err != nil:
+		attempt.ID = 0
+		attempt.Status = ""
+		return r.fail(ctx, attempt, fmt.Errorf("resolve: %w", err))
+	}
+	return attempt, nil
+}
+
+// requireAutoResolve refuses markets whose auto-resolution was turned off
+// since they were read.
+func requireAutoResolve(_ *gorm.DB, market *models.Market) error {
+	if !market.AutoResolve {
+		return resolution.ErrNotAuthorized
+	}
+	return nil
+}
+
+func (r *Resolver) fail(ctx context.Context, attempt *models.AutoResolution, cause error) (*models.AutoResolution, error) {
+	attempt.Status = models.AutoResolutionFailed
+	attempt.Outcome = ""
+	attempt.Error = truncate(cause.Error(), 500)
+	if attempt.Oracle == "" {
+		attempt.Oracle = "unknown"
+	}
+	if err := r.db.WithContext(ctx).Create(attempt).Error; err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// History returns the auto-resolution attempts on a market, newest first.
+func History(db *gorm.DB, marketID int64) ([]models.AutoResolution, error) {
+	var attempts []models.AutoResolution
+	err := db.Where("market_id = ?", marketID).Order("id DESC").Find(&attempts).Error
+	return attempts, err
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+package autoresolve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/services/resolution"
+
+	"gorm.io/gorm"
+)
+
+func seedAutoMarket(t *testing.T, db *gorm.DB, spec models.OracleSpec) *models.Market {
+	t.Helper()
+	market := modelstesting.GenerateMarket(0, "creator")
+	market.ResolutionDateTime = time.Now().Add(-time.Minute)
+	if err := market.SetOracleSpec(spec); err != nil {
+		t.Fatalf("set oracle spec: %v", err)
+	}
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	return &market
+}
+
+func TestRunDue_ResolvesFromOracleAndAuditsAttempts(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		user := modelstesting.GenerateUser("creator", 0)
+		db.Create(&user)
+
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/close" {
+				http.Error(w, "gone", http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []map[string]interface{}{{"symbol": "SPX", "close": "5123.40"}},
+			})
+		}))
+		defer api.Close()
+
+		threshold := 5000.0
+		good := seedAutoMarket(t, db, models.OracleSpec{
+			Oracle: "http_json", URL: api.URL + "/close", Path: "$.data[0]['close']", Operator: ">=", Threshold: &threshold,
+		})
+		broken := seedAutoMarket(t, db, models.OracleSpec{
+			Oracle: "http_json", URL: api.URL + "/missing", Path: "$.close", Operator: ">", Threshold: &threshold,
+		})
+
+		resolver := NewResolver(db)
+		// The test server listens on loopback, which SafeClient refuses.
+		resolver.Register(HTTPJSON{Client: api.Client()})
+
+		resolved, err := resolver.RunDue(context.Background(), time.Now())
+		if err != nil || resolved != 1 {
+			t.Fatalf("expected one market resolved, got %d, %v", resolved, err)
+		}
+
+		var stored models.Market
+		db.First(&stored, good.ID)
+		if !stored.IsResolved || stored.ResolutionResult != resolution.OutcomeYes {
+			t.Fatalf("expected the market resolved YES, got %+v", stored)
+		}
+		history, _ := History(db, good.ID)
+		if len(history) != 1 || history[0].Status != models.AutoResolutionResolved || history[0].ObservedValue != "5123.40" ||
+			!strings.Contains(history[0].Response, "SPX") {
+			t.Fatalf("expected an audit of the value that resolved the market, got %+v", history)
+		}
+
+		// The broken feed is retried each run until it runs out of attempts.
+		for i := 1; i < MaxAttempts+2; i++ {
+			resolver.RunDue(context.Background(), time.Now())
+		}
+		var unresolved models.Market
+		db.First(&unresolved, broken.ID)
+		if unresolved.IsResolved {
+			t.Fatalf("expected the market with a broken feed left unresolved")
+		}
+		failed, _ := History(db, broken.ID)
+		if len(failed) != MaxAttempts || failed[0].Status != models.AutoResolutionFailed || !strings.Contains(failed[0].Error, "404") {
+			t.Fatalf("expected %d failed attempts citing the 404, got %d: %+v", MaxAttempts, len(failed), failed[0])
+		}
+	})
+}
+
+func TestOracles_ValidateAndCompare(t *testing.T) {
+	threshold := 100000.0
+	cases := []struct {
+		name string
+		spec models.OracleSpec
+		ok   bool
+	}{
+		{"crypto", models.OracleSpec{Oracle: "crypto_price", Symbol: "BTC-USD", Operator: ">=", Threshold: &threshold}, true},
+		{"crypto without threshold", models.OracleSpec{Oracle: "crypto_price", Symbol: "BTC-USD", Operator: ">="}, false},
+		{"json equals", models.OracleSpec{Oracle: "http_json", URL: "https://example.com/x", Path: "$.winner", Operator: "==", Equals: "Team A"}, true},
+		{"json bad path", models.OracleSpec{Oracle: "http_json", URL: "https://example.com/x", Path: "winner", Operator: "==", Equals: "A"}, false},
+		{"json bad url", models.OracleSpec{Oracle: "http_json", URL: "file:///etc/passwd", Path: "$.a", Operator: "==", Equals: "A"}, false},
+		{"date", models.OracleSpec{Oracle: "date_passed", Date: &time.Time{}}, true},
+		{"unknown", models.OracleSpec{Oracle: "tea_leaves"}, false},
+	}
+	for _, c := range cases {
+		if err := Validate(c.spec); (err == nil) != c.ok {
+			t.Errorf("%s: expected ok=%v, got %v", c.name, c.ok, err)
+		}
+	}
+
+	var doc interface{}
+	json.Unmarshal([]byte(`{"result": {"teams": [{"name": "A", "won": true}]}}`), &doc)
+	value, err := lookup(doc, `$.result.teams[0]["won"]`)
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if yes, observed, _ := compare(value, models.OracleSpec{Operator: "==", Equals: "true"}); !yes || observed != "true" {
+		t.Fatalf("expected the boolean to match, got %v (%s)", yes, observed)
+	}
+	if _, err := lookup(doc, "$.result.teams[3]"); err == nil {
+		t.Fatalf("expected a missing element to fail")
+	}
+}
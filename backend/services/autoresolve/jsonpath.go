@@ -0,0 +1,84 @@
+package autoresolve
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a JSONPath: an object key or, if key is empty, an
+// array index.
+type segment struct {
+	key   string
+	index int
+}
+
+// parsePath parses the JSONPath subset oracles accept: "$" followed by
+// .key, ['key'] or ["key"] and [n] steps, e.g. $.data[0]['close'].
+func parsePath(path string) ([]segment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, errors.New(`path must start with "$"`)
+	}
+	rest := path[1:]
+	var segments []segment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			segments = append(segments, segment{key: key})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, segment{key: inner[1 : len(inner)-1]})
+			} else if i, err := strconv.Atoi(inner); err == nil && i >= 0 {
+				segments = append(segments, segment{index: i})
+			} else {
+				return nil, fmt.Errorf("invalid step [%s] in path %q", inner, path)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// lookup returns the value at path in doc, a decoded JSON document.
+func lookup(doc interface{}, path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	value := doc
+	for _, s := range segments {
+		if s.key != "" {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: not an object at %q", path, s.key)
+			}
+			if value, ok = object[s.key]; !ok {
+				return nil, fmt.Errorf("%s: no key %q", path, s.key)
+			}
+			continue
+		}
+		array, ok := value.([]interface{})
+		if !ok || s.index >= len(array) {
+			return nil, fmt.Errorf("%s: no element %d", path, s.index)
+		}
+		value = array[s.index]
+	}
+	return value, nil
+}
@@ -0,0 +1,244 @@
+package autoresolve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/services/resolution"
+)
+
+const (
+	fetchTimeout = 10 * time.Second
+	maxFetchSize = 1 << 20
+)
+
+// DefaultCryptoPriceURL is the spot price endpoint crypto_price reads, with
+// the pair substituted for %s. Its response has the price at $.data.amount.
+const DefaultCryptoPriceURL = "https://api.coinbase.com/v2/prices/%s/spot"
+
+var cryptoSymbol = regexp.MustCompile(`^[A-Z0-9]{2,10}-[A-Z]{3,5}$`)
+
+// SafeClient is an HTTP client for oracle fetches that refuses to connect
+// to loopback, private and link-local addresses, so a market's spec cannot
+// be used to probe the server's own network.
+func SafeClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: fetchTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+				ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+				return fmt.Errorf("oracle may not fetch from %s", host)
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: fetchTimeout,
+	}
+	return &http.Client{Timeout: fetchTimeout, Transport: transport}
+}
+
+// HTTPJSON reads a value from a JSON API: spec.URL is fetched and the value
+// at spec.Path compared with the spec's threshold.
+type HTTPJSON struct {
+	Client *http.Client
+}
+
+func (HTTPJSON) Name() string { return "http_json" }
+
+func (HTTPJSON) Validate(spec models.OracleSpec) error {
+	u, err := url.Parse(spec.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an http(s) URL")
+	}
+	if _, err := parsePath(spec.Path); err != nil {
+		return err
+	}
+	return validateComparison(spec)
+}
+
+func (o HTTPJSON) Observe(ctx context.Context, spec models.OracleSpec, now time.Time) (Observation, error) {
+	return observeJSON(ctx, o.Client, spec.URL, spec.Path, spec)
+}
+
+// CryptoPrice reads the spot price of spec.Symbol, e.g. "BTC-USD", and
+// compares it with spec.Threshold.
+type CryptoPrice struct {
+	Client *http.Client
+	// URL is the spot price endpoint, DefaultCryptoPriceURL if empty.
+	URL string
+}
+
+func (CryptoPrice) Name() string { return "crypto_price" }
+
+func (CryptoPrice) Validate(spec models.OracleSpec) error {
+	if !cryptoSymbol.MatchString(spec.Symbol) {
+		return errors.New(`symbol must be a pair such as "BTC-USD"`)
+	}
+	if spec.Threshold == nil {
+		return errors.New("threshold is required")
+	}
+	return validateComparison(spec)
+}
+
+func (o CryptoPrice) Observe(ctx context.Context, spec models.OracleSpec, now time.Time) (Observation, error) {
+	endpoint := o.URL
+	if endpoint == "" {
+		endpoint = DefaultCryptoPriceURL
+	}
+	return observeJSON(ctx, o.Client, fmt.Sprintf(endpoint, url.PathEscape(spec.Symbol)), "$.data.amount", spec)
+}
+
+// DatePassed resolves YES if spec.Date has passed when the market is
+// evaluated and NO otherwise.
+type DatePassed struct{}
+
+func (DatePassed) Name() string { return "date_passed" }
+
+func (DatePassed) Validate(spec models.OracleSpec) error {
+	if spec.Date == nil {
+		return errors.New("date is required")
+	}
+	return nil
+}
+
+func (DatePassed) Observe(ctx context.Context, spec models.OracleSpec, now time.Time) (Observation, error) {
+	outcome := resolution.OutcomeNo
+	if !now.Before(*spec.Date) {
+		outcome = resolution.OutcomeYes
+	}
+	return Observation{
+		Outcome: outcome,
+		Value:   now.UTC().Format(time.RFC3339),
+		Source:  "clock",
+	}, nil
+}
+
+// observeJSON fetches endpoint, reads the value at path and compares it.
+func observeJSON(ctx context.Context, client *http.Client, endpoint, path string, spec models.OracleSpec) (Observation, error) {
+	observation := Observation{Source: endpoint}
+	if client == nil {
+		client = SafeClient()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return observation, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return observation, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchSize))
+	if err != nil {
+		return observation, err
+	}
+	observation.Response = string(body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return observation, fmt.Errorf("%s returned %d", endpoint, resp.StatusCode)
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return observation, fmt.Errorf("response is not JSON: %w", err)
+	}
+	value, err := lookup(doc, path)
+	if err != nil {
+		return observation, err
+	}
+
+	yes, observed, err := compare(value, spec)
+	observation.Value = observed
+	if err != nil {
+		return observation, err
+	}
+	observation.Outcome = resolution.OutcomeNo
+	if yes {
+		observation.Outcome = resolution.OutcomeYes
+	}
+	return observation, nil
+}
+
+var operators = map[string]bool{">": true, ">=": true, "<": true, "<=": true, "==": true, "!=": true}
+
+func validateComparison(spec models.OracleSpec) error {
+	if !operators[spec.Operator] {
+		return errors.New("operator must be one of >, >=, <, <=, == or !=")
+	}
+	if spec.Threshold == nil && spec.Operator != "==" && spec.Operator != "!=" {
+		return errors.New("threshold is required for " + spec.Operator)
+	}
+	return nil
+}
+
+// compare reports whether value satisfies the spec's comparison, and how
+// value was read. With a threshold the value must be a number or a numeric
+// string; otherwise it is compared as text with Equals.
+func compare(value interface{}, spec models.OracleSpec) (bool, string, error) {
+	var observed string
+	switch v := value.(type) {
+	case json.Number:
+		observed = v.String()
+	case string:
+		observed = v
+	case bool:
+		observed = strconv.FormatBool(v)
+	case nil:
+		observed = "null"
+	default:
+		return false, "", errors.New("value at path is not a number, string or boolean")
+	}
+
+	if spec.Threshold == nil {
+		equal := observed == spec.Equals
+		if spec.Operator == "!=" {
+			return !equal, observed, nil
+		}
+		return equal, observed, nil
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(observed), 64)
+	if err != nil {
+		return false, observed, fmt.Errorf("value %q is not a number", observed)
+	}
+	t := *spec.Threshold
+	switch spec.Operator {
+	case ">":
+		return n > t, observed, nil
+	case ">=":
+		return n >= t, observed, nil
+	case "<":
+		return n < t, observed, nil
+	case "<=":
+		return n <= t, observed, nil
+	case "==":
+		return n == t, observed, nil
+	case "!=":
+		return n != t, observed, nil
+	}
+	return false, observed, fmt.Errorf("unknown operator %q", spec.Operator)
+}
@@ -0,0 +1,291 @@
+// Package bulkexport streams the public record of predictions, markets and
+// resolutions as NDJSON or CSV for research. Rows are read in batches in
+// ID order and written as they are read, so an export of any size is never
+// held in memory; a long export is taken in pages by passing the last ID
+// written as the next page's cursor.
+package bulkexport
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/services/exportstream"
+
+	"gorm.io/gorm"
+)
+
+// Export formats.
+const (
+	FormatNDJSON = "ndjson" // a JSON object per line
+	FormatCSV    = "csv"    // a header row, then a row per record
+)
+
+// ErrUnknownFormat is returned for a format other than FormatNDJSON and
+// FormatCSV.
+var ErrUnknownFormat = errors.New("format must be ndjson or csv")
+
+// Page sizes. A page is as many rows as one response streams.
+const (
+	DefaultLimit = 10000
+	MaxLimit     = 100000
+
+	// batchSize is how many rows are read at a time.
+	batchSize = 1000
+)
+
+// Query picks the rows of an export: those after Cursor, an ID, whose
+// dataset time is in [Since, Until), at most Limit of them.
+type Query struct {
+	Since  *time.Time
+	Until  *time.Time
+	Cursor int64
+	Limit  int
+}
+
+// Dataset is one kind of record that can be exported.
+type Dataset struct {
+	Name string
+	// TimeColumn is what Since and Until filter on.
+	TimeColumn string
+	idColumn   string
+	query      func(db *gorm.DB) *gorm.DB
+	rows       func() interface{} // a new pointer to a slice of records, whose first field is the ID
+}
+
+// PredictionRecord is a prediction as exported: its latest revision, with
+// its score once the market resolved.
+type PredictionRecord struct {
+	ID             int64      `json:"id"`
+	MarketID       int64      `json:"marketId"`
+	AgentID        int64      `json:"agentId"`
+	AgentName      string     `json:"agentName"`
+	Outcome        string     `json:"outcome"`
+	Confidence     float64    `json:"confidence"`
+	Estimate       *float64   `json:"estimate"`
+	Low            *float64   `json:"low"`
+	High           *float64   `json:"high"`
+	Reasoning      string     `json:"reasoning"`
+	ReasoningScore float64    `json:"reasoningScore"`
+	Revision       int        `json:"revision"`
+	IsResolved     bool       `json:"isResolved"`
+	WasCorrect     bool       `json:"wasCorrect"`
+	BrierScore     *float64   `json:"brierScore"`
+	LogLoss        *float64   `json:"logLoss"`
+	CRPS           *float64   `json:"crps" gorm:"column:crps"`
+	PredictedAt    time.Time  `json:"predictedAt"`
+	ResolvedAt     *time.Time `json:"resolvedAt"`
+}
+
+// MarketRecord is a market as exported.
+type MarketRecord struct {
+	ID                 int64      `json:"id"`
+	QuestionTitle      string     `json:"questionTitle"`
+	Category           string     `json:"category"`
+	OutcomeType        string     `json:"outcomeType"`
+	MarketType         string     `json:"marketType"`
+	CreatorType        string     `json:"creatorType"`
+	CreatorID          int64      `json:"creatorId"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	ResolutionDateTime time.Time  `json:"resolutionDateTime"`
+	ScalarMin          *float64   `json:"scalarMin"`
+	ScalarMax          *float64   `json:"scalarMax"`
+	ScalarUnit         string     `json:"scalarUnit"`
+	TotalPredictions   int64      `json:"totalPredictions"`
+	IsResolved         bool       `json:"isResolved"`
+	ResolutionResult   string     `json:"resolutionResult"`
+	FinalConsensus     *float64   `json:"finalConsensus"`
+	FinalConsensusAt   *time.Time `json:"finalConsensusAt"`
+}
+
+// ResolutionRecord is a resolved market's outcome with how its predictions
+// fared.
+type ResolutionRecord struct {
+	MarketID           int64     `json:"marketId"`
+	QuestionTitle      string    `json:"questionTitle"`
+	Category           string    `json:"category"`
+	OutcomeType        string    `json:"outcomeType"`
+	Result             string    `json:"result"`
+	Value              *float64  `json:"value"`
+	ResolvedAt         time.Time `json:"resolvedAt"`
+	Source             string    `json:"source"`
+	AutoResolved       bool      `json:"autoResolved"`
+	FinalConsensus     *float64  `json:"finalConsensus"`
+	TotalPredictions   int64     `json:"totalPredictions"`
+	CorrectPredictions int64     `json:"correctPredictions"`
+	MeanBrierScore     *float64  `json:"meanBrierScore"`
+}
+
+// listedMarkets limits a query joined to markets to the public markets
+// that are not deleted or hidden by moderation.
+func listedMarkets(db *gorm.DB) *gorm.DB {
+	return models.WithoutHidden(models.Listed(db.Where("markets.deleted_at IS NULL")), models.ContentMarket)
+}
+
+// Datasets are the exports by name.
+var Datasets = map[string]Dataset{
+	"predictions": {
+		Name:       "predictions",
+		TimeColumn: "predictions.predicted_at",
+		idColumn:   "predictions.id",
+		query: func(db *gorm.DB) *gorm.DB {
+			query := db.Table("predictions").
+				Select("predictions.*, agents.name AS agent_name").
+				Joins("JOIN agents ON agents.id = predictions.agent_id AND agents.deleted_at IS NULL").
+				Joins("JOIN markets ON markets.id = predictions.market_id").
+				Where("predictions.deleted_at IS NULL")
+			return models.WithoutHidden(listedMarkets(query), models.ContentPrediction)
+		},
+		rows: func() interface{} { return &[]PredictionRecord{} },
+	},
+	"markets": {
+		Name:       "markets",
+		TimeColumn: "markets.created_at",
+		idColumn:   "markets.id",
+		query:      func(db *gorm.DB) *gorm.DB { return listedMarkets(db.Table("markets")) },
+		rows:       func() interface{} { return &[]MarketRecord{} },
+	},
+	"resolutions": {
+		Name:       "resolutions",
+		TimeColumn: "markets.final_resolution_date_time",
+		idColumn:   "markets.id",
+		query: func(db *gorm.DB) *gorm.DB {
+			scored := "FROM predictions p WHERE p.market_id = markets.id AND p.deleted_at IS NULL"
+			return listedMarkets(db.Table("markets")).
+				Select("markets.id AS market_id, markets.question_title, markets.category, markets.outcome_type, "+
+					"markets.resolution_result AS result, markets.resolution_value AS value, "+
+					"markets.final_resolution_date_time AS resolved_at, markets.resolution_source AS source, "+
+					"markets.auto_resolve AS auto_resolved, markets.final_consensus, markets.total_predictions, "+
+					"(SELECT COUNT(*) "+scored+" AND p.was_correct = ?) AS correct_predictions, "+
+					"(SELECT AVG(p.brier_score) "+scored+") AS mean_brier_score", true).
+				Where("markets.is_resolved = ?", true)
+		},
+		rows: func() interface{} { return &[]ResolutionRecord{} },
+	},
+}
+
+// Write streams the rows of dataset that q picks to w in format. It
+// returns how many rows it wrote and the ID of the last, the cursor of the
+// next page when the count reached the limit. An error part way leaves the
+// export truncated.
+func Write(ctx context.Context, db *gorm.DB, dataset Dataset, q Query, format string, w io.Writer) (written int, last int64, err error) {
+	var enc encoder
+	stream := exportstream.New(w)
+	switch format {
+	case FormatNDJSON:
+		enc = &ndjsonEncoder{stream}
+	case FormatCSV:
+		enc = &csvEncoder{w: stream.CSV()}
+	default:
+		return 0, 0, ErrUnknownFormat
+	}
+	if q.Limit <= 0 || q.Limit > MaxLimit {
+		q.Limit = DefaultLimit
+	}
+	if err := enc.begin(reflect.TypeOf(dataset.rows()).Elem().Elem()); err != nil {
+		return 0, 0, err
+	}
+
+	last = q.Cursor
+	for written < q.Limit {
+		n := batchSize
+		if remaining := q.Limit - written; remaining < n {
+			n = remaining
+		}
+		query := dataset.query(db.WithContext(ctx)).Where(dataset.idColumn+" > ?", last)
+		if q.Since != nil {
+			query = query.Where(dataset.TimeColumn+" >= ?", *q.Since)
+		}
+		if q.Until != nil {
+			query = query.Where(dataset.TimeColumn+" < ?", *q.Until)
+		}
+		rows := dataset.rows()
+		if err := query.Order(dataset.idColumn).Limit(n).Scan(rows).Error; err != nil {
+			return written, last, fmt.Errorf("export %s: %w", dataset.Name, err)
+		}
+
+		list := reflect.ValueOf(rows).Elem()
+		for i := 0; i < list.Len(); i++ {
+			row := list.Index(i)
+			if err := enc.record(row); err != nil {
+				return written, last, err
+			}
+			last = row.Field(0).Int()
+			written++
+		}
+		if err := stream.Flush(); err != nil {
+			return written, last, err
+		}
+		if list.Len() < n {
+			break
+		}
+	}
+	return written, last, nil
+}
+
+// encoder writes an export in one format.
+type encoder interface {
+	begin(record reflect.Type) error
+	record(row reflect.Value) error
+}
+
+type ndjsonEncoder struct {
+	stream *exportstream.Stream
+}
+
+func (e *ndjsonEncoder) begin(reflect.Type) error { return nil }
+
+func (e *ndjsonEncoder) record(row reflect.Value) error {
+	return e.stream.NDJSON(row.Interface())
+}
+
+// csvEncoder writes a column per field, headed by its JSON name. Missing
+// values are empty, times are RFC 3339.
+type csvEncoder struct {
+	w   *csv.Writer
+	row []string
+}
+
+func (e *csvEncoder) begin(record reflect.Type) error {
+	header := make([]string, record.NumField())
+	for i := range header {
+		header[i] = strings.Split(record.Field(i).Tag.Get("json"), ",")[0]
+	}
+	e.row = make([]string, len(header))
+	return e.w.Write(header)
+}
+
+func (e *csvEncoder) record(row reflect.Value) error {
+	for i := range e.row {
+		e.row[i] = csvValue(row.Field(i))
+	}
+	return e.w.Write(e.row)
+}
+
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	}
+	return v.String()
+}
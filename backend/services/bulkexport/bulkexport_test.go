@@ -0,0 +1,117 @@
+package bulkexport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func seedExport(t *testing.T, db *gorm.DB, now time.Time) (public, unlisted models.Market) {
+	t.Helper()
+	agent := models.Agent{Name: "exporter", APIKey: "swarm_sk_exporter", ClaimToken: "claim_exporter", IsActive: true}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	public = modelstesting.GenerateMarket(0, "admin")
+	public.IsResolved = true
+	public.ResolutionResult = "YES"
+	public.FinalResolutionDateTime = now
+	unlisted = modelstesting.GenerateMarket(0, "admin")
+	unlisted.Visibility = models.MarketUnlisted
+	for _, m := range []*models.Market{&public, &unlisted} {
+		if err := db.Create(m).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+	}
+
+	for i, market := range []models.Market{public, public, public, unlisted} {
+		p := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: "YES", Confidence: 60 + float64(i), Reasoning: "Base rates, \"quoted\"", Revision: 1, PredictedAt: now.Add(time.Duration(i-3) * 24 * time.Hour)}
+		if market.ID == public.ID {
+			p.Score("YES")
+		}
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("create prediction: %v", err)
+		}
+	}
+	return public, unlisted
+}
+
+func TestWrite_NDJSONPagesWithCursorAndFilters(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	public, _ := seedExport(t, db, now)
+
+	var out bytes.Buffer
+	written, last, err := Write(context.Background(), db, Datasets["predictions"], Query{Limit: 2}, FormatNDJSON, &out)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if written != 2 {
+		t.Fatalf("expected a page of 2, got %d", written)
+	}
+	var first PredictionRecord
+	if err := json.NewDecoder(&out).Decode(&first); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if first.MarketID != public.ID || first.AgentName != "exporter" || first.BrierScore == nil {
+		t.Fatalf("unexpected record %+v", first)
+	}
+
+	out.Reset()
+	written, _, err = Write(context.Background(), db, Datasets["predictions"], Query{Cursor: last, Limit: 2}, FormatNDJSON, &out)
+	if err != nil || written != 1 {
+		t.Fatalf("expected the last public prediction on the next page, got %d (%v)", written, err)
+	}
+
+	since := now.Add(-36 * time.Hour)
+	written, _, err = Write(context.Background(), db, Datasets["predictions"], Query{Since: &since}, FormatNDJSON, &bytes.Buffer{})
+	if err != nil || written != 1 {
+		t.Fatalf("expected one public prediction since %s, got %d (%v)", since, written, err)
+	}
+}
+
+func TestWrite_CSVResolutions(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	public, _ := seedExport(t, db, time.Now())
+
+	var out bytes.Buffer
+	if _, _, err := Write(context.Background(), db, Datasets["resolutions"], Query{}, FormatCSV, &out); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	rows, err := csv.NewReader(bufio.NewReader(&out)).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected a header and one resolved market, got %v", rows)
+	}
+	header, row := rows[0], rows[1]
+	column := func(name string) string {
+		for i, h := range header {
+			if h == name {
+				return row[i]
+			}
+		}
+		t.Fatalf("no column %s in %v", name, header)
+		return ""
+	}
+	if column("marketId") != "1" || column("result") != "YES" || column("correctPredictions") != "3" || column("meanBrierScore") == "" {
+		t.Fatalf("unexpected resolution row %v (market %d)", row, public.ID)
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	if _, _, err := Write(context.Background(), db, Datasets["markets"], Query{}, "xml", &bytes.Buffer{}); err != ErrUnknownFormat {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+}
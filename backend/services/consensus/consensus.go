@@ -0,0 +1,288 @@
+// Package consensus computes the swarm consensus of a market from its
+// agents' predictions and keeps its history.
+//
+// Each prediction implies a chance of YES: a YES at confidence c is c%, a NO
+// is 100-c%. The consensus is the mean of those chances weighted by each
+// agent's Agent.CalculateWeight, so agents with a higher composite score
+// and more experience count for more. Deactivated agents are left out.
+//
+// Scalar markets have no chance of YES. Their consensus is the weighted
+// median of the agents' estimates, with the weighted quartiles as its
+// spread; see CurrentScalar.
+package consensus
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// MinWeight keeps agents without a track record from counting for nothing.
+const MinWeight = 0.01
+
+// Intervals are the bucket sizes History accepts.
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+)
+
+var ErrInvalidInterval = errors.New("interval must be hour or day")
+
+// Weight is how much agent's predictions count towards the consensus.
+func Weight(agent *models.Agent) float64 {
+	weight := agent.CalculateWeight()
+	if weight < MinWeight {
+		return MinWeight
+	}
+	return weight
+}
+
+// ImpliedYes is the chance of YES that p implies, from 0 to 1.
+func ImpliedYes(p models.Prediction) float64 {
+	if strings.EqualFold(p.Outcome, "YES") {
+		return p.Confidence / 100
+	}
+	return 1 - p.Confidence/100
+}
+
+// Inputs loads the market's predictions and their active agents. Predictions
+// by deactivated agents are dropped.
+func Inputs(db *gorm.DB, marketID int64) ([]models.Prediction, map[int64]models.Agent, error) {
+	var predictions []models.Prediction
+	if err := db.Where("market_id = ?", marketID).Order("id").Find(&predictions).Error; err != nil {
+		return nil, nil, err
+	}
+	agents := make(map[int64]models.Agent)
+	if len(predictions) == 0 {
+		return predictions, agents, nil
+	}
+
+	agentIDs := make([]int64, len(predictions))
+	for i, p := range predictions {
+		agentIDs[i] = p.AgentID
+	}
+	var rows []models.Agent
+	if err := db.Where("id IN ? AND is_active = ?", agentIDs, true).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, agent := range rows {
+		agents[agent.ID] = agent
+	}
+
+	counted := predictions[:0]
+	for _, p := range predictions {
+		if _, ok := agents[p.AgentID]; ok {
+			counted = append(counted, p)
+		}
+	}
+	return counted, agents, nil
+}
+
+// Current returns the market's consensus and how many predictions it is
+// based on. A market without predictions is at 0.5.
+func Current(db *gorm.DB, marketID int64) (float64, int64, error) {
+	predictions, agents, err := Inputs(db, marketID)
+	if err != nil {
+		return 0, 0, err
+	}
+	var weighted, total float64
+	for _, p := range predictions {
+		agent := agents[p.AgentID]
+		weight := Weight(&agent)
+		weighted += ImpliedYes(p) * weight
+		total += weight
+	}
+	if total == 0 {
+		return 0.5, 0, nil
+	}
+	return weighted / total, int64(len(predictions)), nil
+}
+
+// Scalar is the consensus of a scalar market: the weighted median of its
+// agents' estimates and the weighted quartiles around it.
+type Scalar struct {
+	Median      float64 `json:"median"`
+	Low         float64 `json:"low"`  // 25th percentile
+	High        float64 `json:"high"` // 75th percentile
+	Spread      float64 `json:"spread"`
+	Predictions int64   `json:"predictions"`
+}
+
+// CurrentScalar returns the consensus of a scalar market. A market without
+// predictions has none, and nil is returned.
+func CurrentScalar(db *gorm.DB, marketID int64) (*Scalar, error) {
+	predictions, agents, err := Inputs(db, marketID)
+	if err != nil {
+		return nil, err
+	}
+	return ScalarOf(predictions, agents), nil
+}
+
+// ScalarOf is the scalar consensus of predictions, as loaded by Inputs, or
+// nil if none of them has an estimate.
+func ScalarOf(predictions []models.Prediction, agents map[int64]models.Agent) *Scalar {
+	var estimates, weights []float64
+	for _, p := range predictions {
+		agent, ok := agents[p.AgentID]
+		if !ok || p.Estimate == nil {
+			continue
+		}
+		estimates = append(estimates, *p.Estimate)
+		weights = append(weights, Weight(&agent))
+	}
+	if len(estimates) == 0 {
+		return nil
+	}
+	low := WeightedQuantile(estimates, weights, 0.25)
+	high := WeightedQuantile(estimates, weights, 0.75)
+	return &Scalar{
+		Median:      WeightedQuantile(estimates, weights, 0.5),
+		Low:         low,
+		High:        high,
+		Spread:      high - low,
+		Predictions: int64(len(estimates)),
+	}
+}
+
+// WeightedQuantile returns the q-quantile of values, each counted with its
+// weight: the smallest value at or below which at least q of the total
+// weight lies. values must not be empty.
+func WeightedQuantile(values, weights []float64, q float64) float64 {
+	order := make([]int, len(values))
+	total := 0.0
+	for i := range order {
+		order[i] = i
+		total += weights[i]
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	cumulative := 0.0
+	for _, i := range order {
+		cumulative += weights[i]
+		if cumulative >= q*total {
+			return values[i]
+		}
+	}
+	return values[order[len(order)-1]]
+}
+
+// Record stores the market's current consensus as a point in its history,
+// attributed to the prediction that moved it. Call it in the transaction
+// that made or changed the prediction. For a scalar market the point holds
+// the median, and its probability is where the median sits in the market's
+// range, from 0 at ScalarMin to 1 at ScalarMax.
+func Record(tx *gorm.DB, marketID, predictionID int64, now time.Time) error {
+	var market models.Market
+	if err := tx.Select("id", "outcome_type", "scalar_min", "scalar_max").First(&market, marketID).Error; err != nil {
+		return err
+	}
+	point := models.ConsensusPoint{
+		MarketID:     marketID,
+		PredictionID: predictionID,
+		RecordedAt:   now,
+	}
+	if market.IsScalar() {
+		scalar, err := CurrentScalar(tx, marketID)
+		if err != nil {
+			return err
+		}
+		if scalar == nil {
+			return nil
+		}
+		lower, upper := market.ScalarBounds()
+		point.Value = &scalar.Median
+		point.Probability = (scalar.Median - lower) / (upper - lower)
+		point.Predictions = scalar.Predictions
+		return tx.Create(&point).Error
+	}
+
+	probability, predictions, err := Current(tx, marketID)
+	if err != nil {
+		return err
+	}
+	point.Probability = probability
+	point.Predictions = predictions
+	return tx.Create(&point).Error
+}
+
+// Latest returns the last recorded consensus of each of the markets, keyed
+// by market ID, in one query. Markets nobody has predicted on yet have no
+// recorded consensus and are left out.
+func Latest(db *gorm.DB, marketIDs []int64) (map[int64]models.ConsensusPoint, error) {
+	latest := make(map[int64]models.ConsensusPoint, len(marketIDs))
+	if len(marketIDs) == 0 {
+		return latest, nil
+	}
+	var points []models.ConsensusPoint
+	newest := db.Model(&models.ConsensusPoint{}).Select("MAX(id)").Where("market_id IN ?", marketIDs).Group("market_id")
+	if err := db.Where("id IN (?)", newest).Find(&points).Error; err != nil {
+		return nil, err
+	}
+	for _, point := range points {
+		latest[point.MarketID] = point
+	}
+	return latest, nil
+}
+
+// Bucket is the consensus over one hour or day: where it closed, how far it
+// ranged and how many times it moved.
+type Bucket struct {
+	Start       time.Time `json:"start"`
+	Probability float64   `json:"probability"` // at the end of the bucket
+	Low         float64   `json:"low"`
+	High        float64   `json:"high"`
+	Predictions int64     `json:"predictions"` // behind the closing value
+	Changes     int       `json:"changes"`
+}
+
+// History returns the market's consensus in buckets of interval, oldest
+// first, in UTC. Buckets in which no prediction moved the consensus are
+// left out.
+func History(db *gorm.DB, marketID int64, interval string) ([]Bucket, error) {
+	var size time.Duration
+	switch interval {
+	case IntervalHour:
+		size = time.Hour
+	case IntervalDay:
+		size = 24 * time.Hour
+	default:
+		return nil, ErrInvalidInterval
+	}
+
+	var points []models.ConsensusPoint
+	if err := db.Where("market_id = ?", marketID).Order("recorded_at, id").Find(&points).Error; err != nil {
+		return nil, err
+	}
+
+	buckets := []Bucket{}
+	for _, point := range points {
+		start := point.RecordedAt.UTC().Truncate(size)
+		if n := len(buckets); n > 0 && buckets[n-1].Start.Equal(start) {
+			b := &buckets[n-1]
+			b.Probability = point.Probability
+			b.Predictions = point.Predictions
+			if point.Probability < b.Low {
+				b.Low = point.Probability
+			}
+			if point.Probability > b.High {
+				b.High = point.Probability
+			}
+			b.Changes++
+			continue
+		}
+		buckets = append(buckets, Bucket{
+			Start:       start,
+			Probability: point.Probability,
+			Low:         point.Probability,
+			High:        point.Probability,
+			Predictions: point.Predictions,
+			Changes:     1,
+		})
+	}
+	return buckets, nil
+}
@@ -0,0 +1,99 @@
+package consensus
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func TestRecordAndHistory_BucketsConsensusMoves(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		user := modelstesting.GenerateUser("creator", 0)
+		db.Create(&user)
+		market := modelstesting.GenerateMarket(0, user.Username)
+		db.Create(&market)
+
+		predict := func(name string, composite float64, outcome string, confidence float64) models.Prediction {
+			agent := models.Agent{Name: name, APIKey: "swarm_sk_" + name, ClaimToken: "claim_" + name, IsActive: true, CompositeScore: composite}
+			if err := db.Create(&agent).Error; err != nil {
+				t.Fatalf("create agent: %v", err)
+			}
+			prediction := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: outcome, Confidence: confidence, PredictedAt: time.Now()}
+			db.Create(&prediction)
+			return prediction
+		}
+
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		bull := predict("bull", 60, "YES", 80)
+		if err := Record(db, market.ID, bull.ID, start.Add(5*time.Minute)); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		bear := predict("bear", 20, "NO", 90)
+		Record(db, market.ID, bear.ID, start.Add(40*time.Minute))
+
+		// bull changes its mind an hour later
+		db.Model(&bull).Updates(map[string]interface{}{"outcome": "NO", "confidence": 70})
+		Record(db, market.ID, bull.ID, start.Add(90*time.Minute))
+
+		hourly, err := History(db, market.ID, IntervalHour)
+		if err != nil {
+			t.Fatalf("history: %v", err)
+		}
+		if len(hourly) != 2 {
+			t.Fatalf("expected two hourly buckets, got %+v", hourly)
+		}
+		// (0.8 * 0.6 + 0.1 * 0.2) / 0.8
+		if first := hourly[0]; !first.Start.Equal(start) || first.Changes != 2 || first.High != 0.8 ||
+			math.Abs(first.Probability-0.625) > 1e-9 || first.Low != first.Probability || first.Predictions != 2 {
+			t.Fatalf("unexpected first hour %+v", first)
+		}
+		// (0.3 * 0.6 + 0.1 * 0.2) / 0.8
+		if second := hourly[1]; math.Abs(second.Probability-0.25) > 1e-9 || second.Changes != 1 {
+			t.Fatalf("unexpected second hour %+v", second)
+		}
+
+		daily, _ := History(db, market.ID, IntervalDay)
+		if len(daily) != 1 || daily[0].Changes != 3 || daily[0].High != 0.8 || math.Abs(daily[0].Low-0.25) > 1e-9 {
+			t.Fatalf("expected one daily bucket spanning every move, got %+v", daily)
+		}
+		if _, err := History(db, market.ID, "week"); err != ErrInvalidInterval {
+			t.Fatalf("expected an invalid interval error, got %v", err)
+		}
+
+		latest, err := Latest(db, []int64{market.ID, market.ID + 1})
+		if err != nil {
+			t.Fatalf("latest: %v", err)
+		}
+		if point, ok := latest[market.ID]; len(latest) != 1 || !ok || point.PredictionID != bull.ID || math.Abs(point.Probability-0.25) > 1e-9 {
+			t.Fatalf("expected the last recorded consensus only, got %+v", latest)
+		}
+	})
+}
+
+func TestWeightedQuantile(t *testing.T) {
+	values := []float64{30, 10, 20, 40}
+	if got := WeightedQuantile(values, []float64{1, 1, 1, 1}, 0.5); got != 20 {
+		t.Fatalf("expected the unweighted median 20, got %v", got)
+	}
+	// A heavy agent pulls the median to its estimate
+	if got := WeightedQuantile(values, []float64{1, 1, 1, 5}, 0.5); got != 40 {
+		t.Fatalf("expected the weighted median 40, got %v", got)
+	}
+
+	scalar := ScalarOf([]models.Prediction{
+		{AgentID: 1, Estimate: &values[0]},
+		{AgentID: 2, Estimate: &values[1]},
+		{AgentID: 3},
+	}, map[int64]models.Agent{1: {CompositeScore: 50}, 2: {CompositeScore: 50}, 3: {CompositeScore: 50}})
+	if scalar == nil || scalar.Predictions != 2 || scalar.Median != 10 || scalar.High != 30 || scalar.Spread != 20 {
+		t.Fatalf("expected the consensus of the two estimates, got %+v", scalar)
+	}
+	if ScalarOf(nil, nil) != nil {
+		t.Fatalf("expected no consensus without estimates")
+	}
+}
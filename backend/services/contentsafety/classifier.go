@@ -0,0 +1,56 @@
+package contentsafety
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const classifyTimeout = 5 * time.Second
+
+// HTTPClassifier asks an external service to classify text. It POSTs
+// {"text": "..."} to URL and reads {"scores": {"violence": 0.97, ...}}
+// back, scores from 0 to 1 keyed by category.
+type HTTPClassifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPClassifier returns a classifier that asks the service at url.
+func NewHTTPClassifier(url string) *HTTPClassifier {
+	return &HTTPClassifier{URL: url, Client: &http.Client{Timeout: classifyTimeout}}
+}
+
+func (c *HTTPClassifier) Name() string { return "classifier" }
+
+func (c *HTTPClassifier) Classify(ctx context.Context, text string) (map[string]float64, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("classifier returned %s", resp.Status)
+	}
+
+	var classified struct {
+		Scores map[string]float64 `json:"scores"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&classified); err != nil {
+		return nil, fmt.Errorf("decode classifier response: %w", err)
+	}
+	return classified.Scores, nil
+}
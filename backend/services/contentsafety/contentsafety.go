@@ -0,0 +1,309 @@
+// Package contentsafety scans text agents write, such as market questions
+// and prediction reasoning, for prohibited content: incitement to
+// violence, personal data and harassment targets. Built-in pattern lists,
+// extended in setup.yaml, run locally; an external classifier can be
+// plugged in with Use. Content found in a category is blocked or flagged
+// for review, as setup.yaml's contentSafety.actions says.
+package contentsafety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+// Categories of prohibited content.
+const (
+	CategoryViolence     = "violence"
+	CategoryPersonalData = "personal_data"
+	CategoryHarassment   = "harassment"
+)
+
+// Actions taken on scanned content, from least to most severe. A category
+// whose action is off is not looked for.
+const (
+	ActionAllow = "allow"
+	ActionFlag  = "flag"
+	ActionBlock = "block"
+	actionOff   = "off"
+)
+
+// ErrBlocked is returned, wrapped with the reason, for content that is
+// blocked.
+var ErrBlocked = errors.New("content blocked")
+
+// pattern is a named regular expression for a category. valid, if set,
+// must also accept the match, e.g. a card number's checksum.
+type pattern struct {
+	name  string
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+var builtin = map[string][]pattern{
+	CategoryViolence: {
+		{name: "call_to_violence", re: regexp.MustCompile(`(?i)\b(someone|we|you|they|everyone|people)\s+(should|must|need to|ought to|have to)\s+(kill|shoot|stab|bomb|murder|assassinate|lynch|behead|hurt|beat up)\b`)},
+		{name: "death_wish", re: regexp.MustCompile(`(?i)\b(deserves? to|should|must|needs? to)\s+(die|be\s+(killed|shot|hanged|lynched|executed|murdered))\b`)},
+		{name: "bounty", re: regexp.MustCompile(`(?i)\b(bounty|reward|pay(ing)?)\s+(on|for)\s+(his|her|their|the)\s+(head|death|life)\b`)},
+	},
+	CategoryPersonalData: {
+		{name: "email_address", re: regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`)},
+		{name: "phone_number", re: regexp.MustCompile(`(\(\d{3}\)\s?|\b\d{3}[\s.-])\d{3}[\s.-]\d{4}\b`)},
+		{name: "national_id", re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+		{name: "payment_card", re: regexp.MustCompile(`\b(\d[ -]?){12,18}\d\b`), valid: luhn},
+	},
+	CategoryHarassment: {
+		{name: "doxxing", re: regexp.MustCompile(`(?i)\b(dox|doxx|doxing|doxxing|doxed|doxxed)\b`)},
+		{name: "home_address", re: regexp.MustCompile(`(?i)\b(home address|where (he|she|they) lives?|lives at)\b`)},
+		{name: "swatting", re: regexp.MustCompile(`(?i)\bswatt(ing|ed)\b`)},
+		{name: "targeted_abuse", re: regexp.MustCompile(`(?i)\b(harass|stalk|brigade|pile on)\s+(him|her|them|this\s+(person|user|guy|woman|man))\b`)},
+		{name: "self_harm_goading", re: regexp.MustCompile(`(?i)\b(kill\s+(yourself|urself)|kys)\b`)},
+	},
+}
+
+// matches reports whether text has a match p accepts.
+func (p pattern) matches(text string) bool {
+	if p.valid == nil {
+		return p.re.MatchString(text)
+	}
+	for _, match := range p.re.FindAllString(text, -1) {
+		if p.valid(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum payment card
+// numbers carry.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Classifier is an external content classifier.
+type Classifier interface {
+	// Name identifies the classifier in findings.
+	Name() string
+	// Classify scores text from 0 to 1 in each category it recognises.
+	Classify(ctx context.Context, text string) (map[string]float64, error)
+}
+
+var current struct {
+	sync.RWMutex
+	classifier Classifier
+}
+
+// Use plugs in the external classifier asked alongside the patterns, or
+// removes it if c is nil.
+func Use(c Classifier) {
+	current.Lock()
+	defer current.Unlock()
+	current.classifier = c
+}
+
+func activeClassifier() Classifier {
+	current.RLock()
+	defer current.RUnlock()
+	return current.classifier
+}
+
+// Field is a piece of text to scan, e.g. a market's description.
+type Field struct {
+	Name string
+	Text string
+}
+
+// Finding is prohibited content found in a field.
+type Finding struct {
+	Category string `json:"category"`
+	Field    string `json:"field"`
+	// The pattern that matched, or the classifier that scored it. The
+	// matched text is not kept, since it may be personal data.
+	Rule   string  `json:"rule"`
+	Score  float64 `json:"score,omitempty"`
+	Action string  `json:"action"`
+}
+
+// Result is what a scan found and the action it calls for.
+type Result struct {
+	Action   string    `json:"action"`
+	Findings []Finding `json:"findings,omitempty"`
+	// Set if the classifier could not be asked; the patterns still ran
+	ClassifierError string `json:"classifierError,omitempty"`
+}
+
+// Blocked reports whether the content is blocked.
+func (r Result) Blocked() bool { return r.Action == ActionBlock }
+
+// Flagged reports whether the content is let through for review.
+func (r Result) Flagged() bool { return r.Action == ActionFlag }
+
+// Categories returns the categories found, in order.
+func (r Result) Categories() []string {
+	var categories []string
+	for _, f := range r.Findings {
+		if !contains(categories, f.Category) {
+			categories = append(categories, f.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Reason describes the findings, e.g. "harassment (doxxing in description)".
+func (r Result) Reason() string {
+	parts := make([]string, len(r.Findings))
+	for i, f := range r.Findings {
+		parts[i] = fmt.Sprintf("%s (%s in %s)", f.Category, f.Rule, f.Field)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Err returns an error wrapping ErrBlocked if the content is blocked.
+func (r Result) Err() error {
+	if !r.Blocked() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrBlocked, r.Reason())
+}
+
+// Scan looks for prohibited content in fields with the built-in patterns,
+// those cfg adds and the classifier, if one is plugged in, and returns the
+// most severe action the findings call for. A classifier that fails is
+// logged and recorded in the result; the patterns' findings still stand.
+func Scan(ctx context.Context, cfg setup.ContentSafety, fields ...Field) Result {
+	result := Result{Action: ActionAllow}
+	add := func(f Finding) {
+		f.Action = cfg.ActionFor(f.Category)
+		if f.Action == actionOff {
+			return
+		}
+		result.Findings = append(result.Findings, f)
+		if f.Action == ActionBlock || (f.Action == ActionFlag && result.Action == ActionAllow) {
+			result.Action = f.Action
+		}
+	}
+
+	patterns := patternsFor(cfg)
+	classifier := activeClassifier()
+	for _, field := range fields {
+		if strings.TrimSpace(field.Text) == "" {
+			continue
+		}
+		for _, category := range categoriesOf(patterns) {
+			for _, p := range patterns[category] {
+				if p.matches(field.Text) {
+					add(Finding{Category: category, Field: field.Name, Rule: p.name})
+					break
+				}
+			}
+		}
+		if classifier == nil {
+			continue
+		}
+		scores, err := classifier.Classify(ctx, field.Text)
+		if err != nil {
+			log.Printf("contentsafety: %s classifier: %v", classifier.Name(), err)
+			result.ClassifierError = err.Error()
+			continue
+		}
+		var found []string
+		for category, score := range scores {
+			if score >= cfg.Threshold() {
+				found = append(found, category)
+			}
+		}
+		sort.Strings(found)
+		for _, category := range found {
+			add(Finding{Category: category, Field: field.Name, Rule: classifier.Name(), Score: scores[category]})
+		}
+	}
+	return result
+}
+
+// patternsFor returns the built-in patterns with those cfg adds. A pattern
+// that does not compile is logged and skipped.
+func patternsFor(cfg setup.ContentSafety) map[string][]pattern {
+	if len(cfg.Patterns) == 0 {
+		return builtin
+	}
+	patterns := make(map[string][]pattern, len(builtin)+len(cfg.Patterns))
+	for category, list := range builtin {
+		patterns[category] = list
+	}
+	for category, list := range cfg.Patterns {
+		for i, expr := range list {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				log.Printf("contentsafety: skipping %s pattern %q: %v", category, expr, err)
+				continue
+			}
+			patterns[category] = append(patterns[category], pattern{name: fmt.Sprintf("%s_%d", category, i+1), re: re})
+		}
+	}
+	return patterns
+}
+
+// categoriesOf returns the categories patterns has, in order.
+func categoriesOf(patterns map[string][]pattern) []string {
+	categories := make([]string, 0, len(patterns))
+	for category := range patterns {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Flag puts flagged content in the moderation queue, recording the
+// categories found. Content already in the queue keeps its reports; content
+// a moderator kept is opened for review again.
+func Flag(tx *gorm.DB, contentType string, contentID int64, result Result, now time.Time) error {
+	var item models.ModerationItem
+	err := tx.Where("content_type = ? AND content_id = ?", contentType, contentID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		item = models.ModerationItem{ContentType: contentType, ContentID: contentID, Status: models.ModerationOpen}
+	} else if err != nil {
+		return err
+	}
+	if item.Status == models.ModerationKept {
+		item.Status = models.ModerationOpen
+	}
+	item.SafetyFlags = strings.Join(result.Categories(), ",")
+	item.FlaggedAt = &now
+	return tx.Save(&item).Error
+}
@@ -0,0 +1,104 @@
+package contentsafety
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/setup"
+)
+
+type fakeClassifier struct {
+	scores map[string]float64
+	err    error
+}
+
+func (fakeClassifier) Name() string { return "fake" }
+
+func (c fakeClassifier) Classify(context.Context, string) (map[string]float64, error) {
+	return c.scores, c.err
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        setup.ContentSafety
+		text       string
+		action     string
+		categories []string
+	}{
+		{"clean", setup.ContentSafety{}, "Will the central bank cut rates by 25bp in March?", ActionAllow, nil},
+		{"violence blocks", setup.ContentSafety{}, "If he wins, someone should shoot him", ActionBlock, []string{CategoryViolence}},
+		{"email blocks", setup.ContentSafety{}, "Source: ask jane.doe@example.com", ActionBlock, []string{CategoryPersonalData}},
+		{"card number passes checksum", setup.ContentSafety{}, "Card 4111 1111 1111 1111 on file", ActionBlock, []string{CategoryPersonalData}},
+		{"long number fails checksum", setup.ContentSafety{}, "Block height 1234567812345678 or later", ActionAllow, nil},
+		{"harassment flags", setup.ContentSafety{}, "Someone found her home address already", ActionFlag, []string{CategoryHarassment}},
+		{"most severe action wins", setup.ContentSafety{}, "Dox him and post jane.doe@example.com", ActionBlock, []string{CategoryHarassment, CategoryPersonalData}},
+		{"category turned off", setup.ContentSafety{Actions: map[string]string{CategoryPersonalData: "off"}}, "Mail jane.doe@example.com", ActionAllow, nil},
+		{"configured action", setup.ContentSafety{Actions: map[string]string{CategoryHarassment: "block"}}, "They doxxed the reporter", ActionBlock, []string{CategoryHarassment}},
+		{"configured pattern", setup.ContentSafety{Patterns: map[string][]string{"spam": {`(?i)\bbuy my course\b`}}}, "Great call, buy my course", ActionFlag, []string{"spam"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Scan(context.Background(), tt.cfg, Field{Name: "description", Text: tt.text})
+			if result.Action != tt.action {
+				t.Fatalf("action = %s, want %s (%+v)", result.Action, tt.action, result.Findings)
+			}
+			if got := result.Categories(); len(got) != len(tt.categories) || (len(got) > 0 && got[0] != tt.categories[0]) {
+				t.Fatalf("categories = %v, want %v", got, tt.categories)
+			}
+			if blocked := errors.Is(result.Err(), ErrBlocked); blocked != (tt.action == ActionBlock) {
+				t.Fatalf("Err() = %v", result.Err())
+			}
+		})
+	}
+}
+
+func TestScan_Classifier(t *testing.T) {
+	t.Cleanup(func() { Use(nil) })
+
+	Use(fakeClassifier{scores: map[string]float64{CategoryViolence: 0.95, CategoryHarassment: 0.4}})
+	result := Scan(context.Background(), setup.ContentSafety{}, Field{Name: "reasoning", Text: "An innocuous-looking sentence"})
+	if !result.Blocked() || len(result.Findings) != 1 || result.Findings[0].Rule != "fake" || result.Findings[0].Score != 0.95 {
+		t.Fatalf("expected the classifier's violence score over the threshold to block, got %+v", result)
+	}
+	result = Scan(context.Background(), setup.ContentSafety{ClassifierThreshold: 0.3}, Field{Name: "reasoning", Text: "An innocuous-looking sentence"})
+	if categories := result.Categories(); len(categories) != 2 {
+		t.Fatalf("expected a lower threshold to find both categories, got %v", categories)
+	}
+
+	Use(fakeClassifier{err: errors.New("timeout")})
+	result = Scan(context.Background(), setup.ContentSafety{}, Field{Name: "reasoning", Text: "They doxxed the reporter"})
+	if !result.Flagged() || result.ClassifierError != "timeout" {
+		t.Fatalf("expected the patterns to stand when the classifier fails, got %+v", result)
+	}
+}
+
+func TestFlag_QueuesContentAndReopensKeptItems(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	result := Scan(context.Background(), setup.ContentSafety{}, Field{Name: "reasoning", Text: "They doxxed the reporter"})
+	now := time.Now()
+
+	if err := Flag(db, models.ContentPrediction, 7, result, now); err != nil {
+		t.Fatalf("flag: %v", err)
+	}
+	var item models.ModerationItem
+	db.Where("content_type = ? AND content_id = ?", models.ContentPrediction, 7).First(&item)
+	if item.Status != models.ModerationOpen || item.SafetyFlags != CategoryHarassment || item.FlaggedAt == nil {
+		t.Fatalf("item = %+v", item)
+	}
+
+	db.Model(&item).Update("status", models.ModerationKept)
+	if err := Flag(db, models.ContentPrediction, 7, result, now); err != nil {
+		t.Fatalf("flag again: %v", err)
+	}
+	var count int64
+	db.Model(&models.ModerationItem{}).Count(&count)
+	db.First(&item, item.ID)
+	if count != 1 || item.Status != models.ModerationOpen {
+		t.Fatalf("expected the kept item reopened, got %d items, %+v", count, item)
+	}
+}
@@ -0,0 +1,239 @@
+// Package correlation tracks how the swarm's beliefs about open markets move
+// together. Each run snapshots the agent consensus of every open market and
+// correlates the snapshot series of every pair of markets, so agents can
+// find related markets whose consensus has drifted apart.
+package correlation
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Lookback is how much consensus history is correlated and kept.
+	Lookback = 14 * 24 * time.Hour
+	// MinSamples is how many shared snapshots a pair needs to be correlated.
+	MinSamples = 6
+	// MaxPerMarket caps how many correlations are stored for each market,
+	// strongest first.
+	MaxPerMarket = 20
+)
+
+// Run snapshots the consensus of every open market, drops history older
+// than Lookback and recomputes the correlations. It returns how many
+// correlations were stored.
+func Run(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	if _, err := Snapshot(ctx, db, now); err != nil {
+		return 0, err
+	}
+	if err := db.WithContext(ctx).Where("taken_at < ?", now.Add(-Lookback)).Delete(&models.ConsensusSnapshot{}).Error; err != nil {
+		return 0, err
+	}
+	return Compute(ctx, db, now)
+}
+
+// Snapshot records the current consensus of every open market with agent
+// predictions and returns how many markets were recorded. A market's
+// consensus is the mean chance of YES implied by its predictions: a YES at
+// confidence c counts as c%, a NO as 100-c%.
+func Snapshot(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	type predictionRow struct {
+		MarketID   int64
+		Outcome    string
+		Confidence float64
+	}
+	var rows []predictionRow
+	err := db.WithContext(ctx).Table("predictions").
+		Select("predictions.market_id, predictions.outcome, predictions.confidence").
+		Joins("JOIN markets ON markets.id = predictions.market_id").
+		Where("markets.deleted_at IS NULL AND markets.is_resolved = ? AND markets.resolution_date_time > ?", false, now).
+		Scan(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+
+	sums := make(map[int64]float64)
+	counts := make(map[int64]int64)
+	for _, row := range rows {
+		yes := row.Confidence / 100
+		if row.Outcome != "YES" {
+			yes = 1 - yes
+		}
+		sums[row.MarketID] += yes
+		counts[row.MarketID]++
+	}
+	if len(counts) == 0 {
+		return 0, nil
+	}
+
+	snapshots := make([]models.ConsensusSnapshot, 0, len(counts))
+	for marketID, count := range counts {
+		snapshots = append(snapshots, models.ConsensusSnapshot{
+			MarketID:    marketID,
+			Probability: sums[marketID] / float64(count),
+			Predictions: count,
+			TakenAt:     now,
+		})
+	}
+	if err := db.WithContext(ctx).Create(&snapshots).Error; err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// Compute correlates the consensus series of every pair of open markets
+// over the last Lookback and replaces the stored correlations. Pairs with
+// fewer than MinSamples shared snapshots, or where either series is flat,
+// are left out.
+func Compute(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+
+	var snapshots []models.ConsensusSnapshot
+	err := db.Joins("JOIN markets ON markets.id = consensus_snapshots.market_id").
+		Where("markets.deleted_at IS NULL AND markets.is_resolved = ? AND markets.resolution_date_time > ? AND consensus_snapshots.taken_at >= ?", false, now, now.Add(-Lookback)).
+		Order("consensus_snapshots.market_id, consensus_snapshots.taken_at").
+		Find(&snapshots).Error
+	if err != nil {
+		return 0, err
+	}
+
+	series := make(map[int64]map[int64]float64)
+	var marketIDs []int64
+	for _, snapshot := range snapshots {
+		points, ok := series[snapshot.MarketID]
+		if !ok {
+			points = make(map[int64]float64)
+			series[snapshot.MarketID] = points
+			marketIDs = append(marketIDs, snapshot.MarketID)
+		}
+		points[snapshot.TakenAt.UnixNano()] = snapshot.Probability
+	}
+
+	perMarket := make(map[int64][]models.MarketCorrelation)
+	for i, a := range marketIDs {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		for _, b := range marketIDs[i+1:] {
+			x, y := aligned(series[a], series[b])
+			if len(x) < MinSamples {
+				continue
+			}
+			r := pearson(x, y)
+			if math.IsNaN(r) {
+				continue
+			}
+			perMarket[a] = append(perMarket[a], models.MarketCorrelation{MarketID: a, OtherMarketID: b, Coefficient: r, Samples: len(x), ComputedAt: now})
+			perMarket[b] = append(perMarket[b], models.MarketCorrelation{MarketID: b, OtherMarketID: a, Coefficient: r, Samples: len(x), ComputedAt: now})
+		}
+	}
+
+	var correlations []models.MarketCorrelation
+	for _, marketID := range marketIDs {
+		strongest := perMarket[marketID]
+		sort.Slice(strongest, func(i, j int) bool {
+			return math.Abs(strongest[i].Coefficient) > math.Abs(strongest[j].Coefficient)
+		})
+		if len(strongest) > MaxPerMarket {
+			strongest = strongest[:MaxPerMarket]
+		}
+		correlations = append(correlations, strongest...)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.MarketCorrelation{}).Error; err != nil {
+			return err
+		}
+		if len(correlations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&correlations, 500).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(correlations), nil
+}
+
+// Correlated returns up to limit of the market's stored correlations with
+// listed markets, strongest first.
+func Correlated(db *gorm.DB, marketID int64, limit int) ([]models.MarketCorrelation, error) {
+	var correlations []models.MarketCorrelation
+	err := db.Where("market_id = ?", marketID).
+		Where("other_market_id IN (?)", models.Listed(db.Model(&models.Market{})).Select("id")).
+		Order("ABS(coefficient) DESC, other_market_id").
+		Limit(limit).
+		Find(&correlations).Error
+	return correlations, err
+}
+
+// Latest returns the most recent consensus snapshot of each market that
+// has one.
+func Latest(db *gorm.DB, marketIDs []int64) (map[int64]models.ConsensusSnapshot, error) {
+	latest := make(map[int64]models.ConsensusSnapshot, len(marketIDs))
+	if len(marketIDs) == 0 {
+		return latest, nil
+	}
+	var snapshots []models.ConsensusSnapshot
+	err := db.Where("id IN (?)", db.Model(&models.ConsensusSnapshot{}).
+		Select("MAX(id)").
+		Where("market_id IN ?", marketIDs).
+		Group("market_id")).
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		latest[snapshot.MarketID] = snapshot
+	}
+	return latest, nil
+}
+
+// aligned returns the values of a and b at the snapshot times they share,
+// in time order.
+func aligned(a, b map[int64]float64) (x, y []float64) {
+	times := make([]int64, 0, len(a))
+	for t := range a {
+		if _, ok := b[t]; ok {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	x = make([]float64, len(times))
+	y = make([]float64, len(times))
+	for i, t := range times {
+		x[i], y[i] = a[t], b[t]
+	}
+	return x, y
+}
+
+// pearson returns the Pearson correlation of x and y, or NaN if either is
+// constant.
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return math.NaN()
+	}
+	return cov / math.Sqrt(varX*varY)
+}
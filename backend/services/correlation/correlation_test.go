@@ -0,0 +1,108 @@
+package correlation
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func seedMarket(t *testing.T, db *gorm.DB, id int64, creator string) models.Market {
+	t.Helper()
+	market := modelstesting.GenerateMarket(id, creator)
+	market.ResolutionDateTime = time.Now().Add(30 * 24 * time.Hour)
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market %d: %v", id, err)
+	}
+	return market
+}
+
+func TestSnapshot_RecordsImpliedConsensus(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := seedMarket(t, db, 1, user.Username)
+	bull := models.Agent{Name: "bull", APIKey: "swarm_sk_bull", ClaimToken: "claim_bull", IsActive: true}
+	bear := models.Agent{Name: "bear", APIKey: "swarm_sk_bear", ClaimToken: "claim_bear", IsActive: true}
+	db.Create(&bull)
+	db.Create(&bear)
+	for i, p := range []models.Prediction{
+		{AgentID: bull.ID, MarketID: market.ID, Outcome: "YES", Confidence: 80, PredictedAt: time.Now()},
+		{AgentID: bear.ID, MarketID: market.ID, Outcome: "NO", Confidence: 60, PredictedAt: time.Now()},
+	} {
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("create prediction %d: %v", i, err)
+		}
+	}
+
+	count, err := Snapshot(ctx, db, time.Now())
+	if err != nil || count != 1 {
+		t.Fatalf("expected one snapshot, got %d, %v", count, err)
+	}
+	latest, err := Latest(db, []int64{market.ID})
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+	// YES at 80% and NO at 60% imply 0.8 and 0.4.
+	if got := latest[market.ID].Probability; math.Abs(got-0.6) > 1e-9 {
+		t.Fatalf("expected consensus 0.6, got %v", got)
+	}
+}
+
+func TestCompute_CorrelatesAlignedSeries(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	rising := seedMarket(t, db, 1, user.Username)
+	alsoRising := seedMarket(t, db, 2, user.Username)
+	falling := seedMarket(t, db, 3, user.Username)
+	flat := seedMarket(t, db, 4, user.Username)
+
+	now := time.Now()
+	for i := 0; i < MinSamples; i++ {
+		takenAt := now.Add(time.Duration(i-MinSamples) * time.Hour)
+		step := float64(i) / 10
+		for _, s := range []models.ConsensusSnapshot{
+			{MarketID: rising.ID, Probability: 0.2 + step, TakenAt: takenAt},
+			{MarketID: alsoRising.ID, Probability: 0.1 + 2*step/3, TakenAt: takenAt},
+			{MarketID: falling.ID, Probability: 0.9 - step, TakenAt: takenAt},
+			{MarketID: flat.ID, Probability: 0.5, TakenAt: takenAt},
+		} {
+			if err := db.Create(&s).Error; err != nil {
+				t.Fatalf("create snapshot: %v", err)
+			}
+		}
+	}
+
+	// The pairs among the three moving markets, both ways round.
+	stored, err := Compute(ctx, db, now)
+	if err != nil {
+		t.Fatalf("Compute: %v", err)
+	}
+	if stored != 6 {
+		t.Fatalf("expected 6 stored correlations, got %d", stored)
+	}
+
+	correlated, err := Correlated(db, rising.ID, 10)
+	if err != nil {
+		t.Fatalf("Correlated: %v", err)
+	}
+	if len(correlated) != 2 {
+		t.Fatalf("expected 2 correlated markets, got %+v", correlated)
+	}
+	want := map[int64]float64{alsoRising.ID: 1, falling.ID: -1}
+	for _, c := range correlated {
+		if math.Abs(c.Coefficient-want[c.OtherMarketID]) > 1e-9 || c.Samples != MinSamples {
+			t.Fatalf("unexpected correlation %+v", c)
+		}
+	}
+}
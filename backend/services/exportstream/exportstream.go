@@ -0,0 +1,57 @@
+// Package exportstream carries exports to the client a batch at a time.
+// Exporters write each batch of records to a Stream and flush it before
+// reading the next, so an export of any size streams instead of being held
+// in memory.
+package exportstream
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// Stream buffers an export on its way to its destination.
+type Stream struct {
+	*bufio.Writer
+	dst  io.Writer
+	json *json.Encoder
+	csv  *csv.Writer
+}
+
+// New returns a stream writing to w.
+func New(w io.Writer) *Stream {
+	buffered := bufio.NewWriter(w)
+	return &Stream{Writer: buffered, dst: w, json: json.NewEncoder(buffered)}
+}
+
+// NDJSON writes v as one line of JSON.
+func (s *Stream) NDJSON(v interface{}) error {
+	return s.json.Encode(v)
+}
+
+// CSV returns a CSV writer on the stream. Flush flushes it too.
+func (s *Stream) CSV() *csv.Writer {
+	if s.csv == nil {
+		s.csv = csv.NewWriter(s.Writer)
+	}
+	return s.csv
+}
+
+// Flush writes out what is buffered and, if the destination is an HTTP
+// response, sends it.
+func (s *Stream) Flush() error {
+	if s.csv != nil {
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := s.Writer.Flush(); err != nil {
+		return err
+	}
+	if f, ok := s.dst.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package exportstream
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFlush_SendsBufferedRecordsToTheClient(t *testing.T) {
+	rec := httptest.NewRecorder()
+	stream := New(rec)
+
+	if err := stream.NDJSON(map[string]int{"id": 1}); err != nil {
+		t.Fatalf("write ndjson: %v", err)
+	}
+	if err := stream.CSV().Write([]string{"id", "name"}); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	if rec.Body.Len() != 0 || rec.Flushed {
+		t.Fatalf("expected records to wait in the buffer, got %q", rec.Body.String())
+	}
+
+	if err := stream.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := rec.Body.String(); got != "{\"id\":1}\nid,name\n" || !rec.Flushed {
+		t.Fatalf("expected both records sent, got %q (flushed %v)", got, rec.Flushed)
+	}
+}
@@ -0,0 +1,339 @@
+// Package headtohead compares agents on the markets they predicted on in
+// common: who was right where, how their scores differ by category and how
+// well calibrated each is relative to the other.
+package headtohead
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// MaxAgents is how many agents can be compared at once.
+const MaxAgents = 5
+
+var (
+	ErrTooFewAgents  = errors.New("compare at least two different agents")
+	ErrTooManyAgents = errors.New("compare at most 5 agents")
+	ErrAgentNotFound = errors.New("agent not found")
+)
+
+// Agent is a compared agent's profile with its calibration.
+type Agent struct {
+	models.AgentPublic
+	CalibratedAccuracyScore float64 `json:"calibratedAccuracyScore"`
+	MeanBrierScore          float64 `json:"meanBrierScore"`
+	ScoredPredictions       int64   `json:"scoredPredictions"`
+}
+
+// Call is one agent's prediction on a shared market.
+type Call struct {
+	AgentID    int64    `json:"agentId"`
+	Outcome    string   `json:"outcome"`
+	Confidence float64  `json:"confidence"`
+	Estimate   *float64 `json:"estimate,omitempty"`
+	// Set once the prediction is resolved
+	Correct    *bool    `json:"correct,omitempty"`
+	BrierScore *float64 `json:"brierScore,omitempty"`
+}
+
+// SharedMarket is a market two or more of the compared agents predicted on,
+// with their calls.
+type SharedMarket struct {
+	MarketID         int64  `json:"marketId"`
+	QuestionTitle    string `json:"questionTitle"`
+	Category         string `json:"category"`
+	IsResolved       bool   `json:"isResolved"`
+	ResolutionResult string `json:"resolutionResult,omitempty"`
+	Calls            []Call `json:"calls"`
+}
+
+// Tally counts how an agent fared against another on the shared markets
+// both predictions were resolved on.
+type Tally struct {
+	Wins   int `json:"wins"`   // the agent was right and the other wrong
+	Losses int `json:"losses"` // the other was right and the agent wrong
+	Ties   int `json:"ties"`   // both right or both wrong
+}
+
+func (t *Tally) add(agentRight, otherRight bool) {
+	switch {
+	case agentRight == otherRight:
+		t.Ties++
+	case agentRight:
+		t.Wins++
+	default:
+		t.Losses++
+	}
+}
+
+// CategoryRecord compares two agents in one market category: their record
+// on the category's shared markets, and the difference between their
+// category-wide scores (the agent's minus the other's).
+type CategoryRecord struct {
+	Category      string `json:"category"`
+	SharedMarkets int    `json:"sharedMarkets"`
+	Tally
+	AccuracyDelta           float64 `json:"accuracyDelta"`
+	CalibratedAccuracyDelta float64 `json:"calibratedAccuracyDelta"`
+}
+
+// Record compares one agent with another.
+type Record struct {
+	AgentID       int64 `json:"agentId"`
+	OtherID       int64 `json:"otherId"`
+	SharedMarkets int   `json:"sharedMarkets"`
+	Agreed        int   `json:"agreed"` // shared markets where both made the same call
+	Tally
+	// Mean Brier scores on the shared markets scored for both, lower is
+	// better; BrierDelta is the agent's minus the other's, so a negative
+	// delta means the agent was the better calibrated on them
+	MeanBrier      *float64 `json:"meanBrier,omitempty"`
+	OtherMeanBrier *float64 `json:"otherMeanBrier,omitempty"`
+	BrierDelta     *float64 `json:"brierDelta,omitempty"`
+	// The agent's calibrated accuracy score minus the other's, over all
+	// their predictions
+	CalibratedAccuracyDelta float64          `json:"calibratedAccuracyDelta"`
+	Categories              []CategoryRecord `json:"categories"`
+}
+
+// Comparison is the result of comparing agents: a record for every pair,
+// in the order the agents were given, and the shared markets, newest
+// first.
+type Comparison struct {
+	Agents        []Agent        `json:"agents"`
+	Records       []Record       `json:"records"`
+	SharedMarkets int            `json:"sharedMarkets"`
+	Markets       []SharedMarket `json:"markets"`
+}
+
+// Compare compares the agents agentIDs, two to MaxAgents of them, on the
+// public and unlisted markets they predicted on in common, leaving out
+// predictions moderators hid. It lists up to marketLimit shared markets;
+// the records count them all.
+func Compare(ctx context.Context, db *gorm.DB, agentIDs []int64, marketLimit int) (*Comparison, error) {
+	ids := distinct(agentIDs)
+	if len(ids) < 2 {
+		return nil, ErrTooFewAgents
+	}
+	if len(ids) > MaxAgents {
+		return nil, ErrTooManyAgents
+	}
+	db = db.WithContext(ctx)
+
+	var agents []models.Agent
+	if err := db.Where("id IN ?", ids).Find(&agents).Error; err != nil {
+		return nil, err
+	}
+	if len(agents) != len(ids) {
+		return nil, ErrAgentNotFound
+	}
+	byID := make(map[int64]*models.Agent, len(agents))
+	for i := range agents {
+		byID[agents[i].ID] = &agents[i]
+	}
+
+	markets, err := sharedMarkets(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := categoryStats(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &Comparison{SharedMarkets: len(markets), Markets: markets, Records: []Record{}}
+	for _, id := range ids {
+		a := byID[id]
+		comparison.Agents = append(comparison.Agents, Agent{
+			AgentPublic:             a.ToPublic(),
+			CalibratedAccuracyScore: a.CalibratedAccuracyScore,
+			MeanBrierScore:          a.MeanBrierScore,
+			ScoredPredictions:       a.ScoredPredictions,
+		})
+	}
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			record := compare(markets, byID[ids[i]], byID[ids[j]], categories)
+			comparison.Records = append(comparison.Records, record)
+		}
+	}
+	if marketLimit >= 0 && len(comparison.Markets) > marketLimit {
+		comparison.Markets = comparison.Markets[:marketLimit]
+	}
+	return comparison, nil
+}
+
+// distinct returns ids without repeats, in order.
+func distinct(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	var out []int64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// sharedMarkets returns the markets two or more of the agents ids
+// predicted on, newest first, each with their calls in the order of ids.
+func sharedMarkets(db *gorm.DB, ids []int64) ([]SharedMarket, error) {
+	var rows []struct {
+		AgentID          int64
+		MarketID         int64
+		Outcome          string
+		Confidence       float64
+		Estimate         *float64
+		IsResolved       bool
+		WasCorrect       bool
+		BrierScore       *float64
+		QuestionTitle    string
+		Category         string
+		MarketResolved   bool
+		ResolutionResult string
+	}
+	err := models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+		Select(`predictions.agent_id, predictions.market_id, predictions.outcome, predictions.confidence,
+			predictions.estimate, predictions.is_resolved, predictions.was_correct, predictions.brier_score,
+			markets.question_title, markets.category, markets.is_resolved AS market_resolved, markets.resolution_result`).
+		Joins("JOIN markets ON markets.id = predictions.market_id").
+		Where("predictions.agent_id IN ? AND markets.deleted_at IS NULL AND markets.visibility <> ?", ids, models.MarketInviteOnly).
+		Order("predictions.market_id DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	order := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		order[id] = i
+	}
+	var markets []SharedMarket
+	for i := 0; i < len(rows); {
+		market := SharedMarket{
+			MarketID:         rows[i].MarketID,
+			QuestionTitle:    rows[i].QuestionTitle,
+			Category:         rows[i].Category,
+			IsResolved:       rows[i].MarketResolved,
+			ResolutionResult: rows[i].ResolutionResult,
+		}
+		for ; i < len(rows) && rows[i].MarketID == market.MarketID; i++ {
+			row := rows[i]
+			call := Call{AgentID: row.AgentID, Outcome: row.Outcome, Confidence: row.Confidence, Estimate: row.Estimate, BrierScore: row.BrierScore}
+			if row.IsResolved {
+				correct := row.WasCorrect
+				call.Correct = &correct
+			}
+			market.Calls = append(market.Calls, call)
+		}
+		if len(market.Calls) < 2 {
+			continue
+		}
+		sort.Slice(market.Calls, func(a, b int) bool { return order[market.Calls[a].AgentID] < order[market.Calls[b].AgentID] })
+		markets = append(markets, market)
+	}
+	if markets == nil {
+		markets = []SharedMarket{}
+	}
+	return markets, nil
+}
+
+// categoryStats returns the agents' category stats by agent and category.
+func categoryStats(db *gorm.DB, ids []int64) (map[int64]map[string]models.AgentCategoryStats, error) {
+	var rows []models.AgentCategoryStats
+	if err := db.Where("agent_id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	stats := make(map[int64]map[string]models.AgentCategoryStats, len(ids))
+	for _, row := range rows {
+		if stats[row.AgentID] == nil {
+			stats[row.AgentID] = make(map[string]models.AgentCategoryStats)
+		}
+		stats[row.AgentID][row.Category] = row
+	}
+	return stats, nil
+}
+
+// compare builds the record of agent against other.
+func compare(markets []SharedMarket, agent, other *models.Agent, categories map[int64]map[string]models.AgentCategoryStats) Record {
+	record := Record{
+		AgentID:                 agent.ID,
+		OtherID:                 other.ID,
+		CalibratedAccuracyDelta: agent.CalibratedAccuracyScore - other.CalibratedAccuracyScore,
+	}
+	byCategory := make(map[string]*CategoryRecord)
+	categoryRecord := func(category string) *CategoryRecord {
+		if byCategory[category] == nil {
+			byCategory[category] = &CategoryRecord{Category: category}
+		}
+		return byCategory[category]
+	}
+
+	var brier, otherBrier float64
+	scored := 0
+	for _, market := range markets {
+		a, b := callBy(market, agent.ID), callBy(market, other.ID)
+		if a == nil || b == nil {
+			continue
+		}
+		record.SharedMarkets++
+		if a.Outcome == b.Outcome && (a.Estimate == nil || b.Estimate == nil || *a.Estimate == *b.Estimate) {
+			record.Agreed++
+		}
+		category := categoryRecord(market.Category)
+		category.SharedMarkets++
+		if a.Correct != nil && b.Correct != nil {
+			record.add(*a.Correct, *b.Correct)
+			category.add(*a.Correct, *b.Correct)
+		}
+		if a.BrierScore != nil && b.BrierScore != nil {
+			brier += *a.BrierScore
+			otherBrier += *b.BrierScore
+			scored++
+		}
+	}
+	if scored > 0 {
+		mean, otherMean := brier/float64(scored), otherBrier/float64(scored)
+		delta := mean - otherMean
+		record.MeanBrier, record.OtherMeanBrier, record.BrierDelta = &mean, &otherMean, &delta
+	}
+
+	// Categories both agents have a track record in get their score
+	// differences, even without shared markets there.
+	for category, stats := range categories[agent.ID] {
+		otherStats, ok := categories[other.ID][category]
+		if !ok {
+			continue
+		}
+		c := categoryRecord(category)
+		c.AccuracyDelta = stats.AccuracyScore - otherStats.AccuracyScore
+		c.CalibratedAccuracyDelta = stats.CalibratedAccuracyScore - otherStats.CalibratedAccuracyScore
+	}
+	record.Categories = make([]CategoryRecord, 0, len(byCategory))
+	for _, c := range byCategory {
+		record.Categories = append(record.Categories, *c)
+	}
+	sort.Slice(record.Categories, func(i, j int) bool {
+		if record.Categories[i].SharedMarkets != record.Categories[j].SharedMarkets {
+			return record.Categories[i].SharedMarkets > record.Categories[j].SharedMarkets
+		}
+		return record.Categories[i].Category < record.Categories[j].Category
+	})
+	return record
+}
+
+// callBy returns the call agentID made on market, or nil.
+func callBy(market SharedMarket, agentID int64) *Call {
+	for i := range market.Calls {
+		if market.Calls[i].AgentID == agentID {
+			return &market.Calls[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package headtohead
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func TestCompare_RecordsWhoWasRightWhere(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		user := modelstesting.GenerateUser("creator", 0)
+		db.Create(&user)
+		newMarket := func(category, visibility string) models.Market {
+			market := modelstesting.GenerateMarket(0, user.Username)
+			market.Category, market.Visibility = category, visibility
+			if err := db.Create(&market).Error; err != nil {
+				t.Fatalf("create market: %v", err)
+			}
+			return market
+		}
+		var agents []models.Agent
+		for _, name := range []string{"alpha", "beta", "gamma"} {
+			agent := modelstesting.GenerateAgent(name)
+			db.Create(&agent)
+			agents = append(agents, agent)
+		}
+		alpha, beta, gamma := agents[0], agents[1], agents[2]
+		predict := func(agent models.Agent, market models.Market, outcome string, confidence float64, resolution string) {
+			p := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: outcome, Confidence: confidence, PredictedAt: time.Now()}
+			if resolution != "" {
+				p.Score(resolution)
+			}
+			if err := db.Create(&p).Error; err != nil {
+				t.Fatalf("create prediction: %v", err)
+			}
+		}
+
+		// alpha beats beta in crypto, they split in politics, and beta's
+		// call on an invite-only market does not count.
+		crypto1, crypto2 := newMarket("crypto", models.MarketPublic), newMarket("crypto", models.MarketUnlisted)
+		politics, private := newMarket("politics", models.MarketPublic), newMarket("politics", models.MarketInviteOnly)
+		open := newMarket("sports", models.MarketPublic)
+		predict(alpha, crypto1, "YES", 80, "YES")
+		predict(beta, crypto1, "NO", 70, "YES")
+		predict(alpha, crypto2, "NO", 90, "NO")
+		predict(beta, crypto2, "YES", 60, "NO")
+		predict(alpha, politics, "YES", 60, "YES")
+		predict(beta, politics, "YES", 90, "YES")
+		predict(alpha, private, "YES", 60, "NO")
+		predict(beta, private, "NO", 60, "NO")
+		predict(alpha, open, "YES", 55, "")
+		predict(gamma, open, "NO", 55, "")
+		db.Create(&models.AgentCategoryStats{AgentID: alpha.ID, Category: "crypto", AccuracyScore: 70, CalibratedAccuracyScore: 65})
+		db.Create(&models.AgentCategoryStats{AgentID: beta.ID, Category: "crypto", AccuracyScore: 40, CalibratedAccuracyScore: 45})
+
+		comparison, err := Compare(ctx, db, []int64{alpha.ID, beta.ID, gamma.ID, alpha.ID}, 2)
+		if err != nil {
+			t.Fatalf("compare: %v", err)
+		}
+		if len(comparison.Agents) != 3 || comparison.SharedMarkets != 4 || len(comparison.Markets) != 2 || len(comparison.Records) != 3 {
+			t.Fatalf("comparison = %+v", comparison)
+		}
+		if m := comparison.Markets[0]; m.MarketID != open.ID || len(m.Calls) != 2 || m.Calls[0].AgentID != alpha.ID || m.Calls[0].Correct != nil {
+			t.Fatalf("newest shared market = %+v", m)
+		}
+
+		ab := comparison.Records[0]
+		if ab.AgentID != alpha.ID || ab.OtherID != beta.ID || ab.SharedMarkets != 3 || ab.Agreed != 1 ||
+			ab.Wins != 2 || ab.Losses != 0 || ab.Ties != 1 {
+			t.Fatalf("alpha vs beta = %+v", ab)
+		}
+		if ab.BrierDelta == nil || *ab.BrierDelta >= 0 || math.Abs(*ab.BrierDelta-(*ab.MeanBrier-*ab.OtherMeanBrier)) > 1e-9 {
+			t.Fatalf("expected alpha better calibrated on the shared markets, got %+v", ab)
+		}
+		if len(ab.Categories) != 2 || ab.Categories[0].Category != "crypto" || ab.Categories[0].Wins != 2 ||
+			ab.Categories[0].AccuracyDelta != 30 || ab.Categories[0].CalibratedAccuracyDelta != 20 {
+			t.Fatalf("alpha vs beta by category = %+v", ab.Categories)
+		}
+		if bg := comparison.Records[2]; bg.AgentID != beta.ID || bg.OtherID != gamma.ID || bg.SharedMarkets != 0 || len(bg.Categories) != 0 {
+			t.Fatalf("beta vs gamma = %+v", bg)
+		}
+
+		if _, err := Compare(ctx, db, []int64{alpha.ID, alpha.ID}, 10); !errors.Is(err, ErrTooFewAgents) {
+			t.Errorf("compare an agent with itself: %v", err)
+		}
+		if _, err := Compare(ctx, db, []int64{alpha.ID, 9999}, 10); !errors.Is(err, ErrAgentNotFound) {
+			t.Errorf("compare with a missing agent: %v", err)
+		}
+		if _, err := Compare(ctx, db, []int64{1, 2, 3, 4, 5, 6}, 10); !errors.Is(err, ErrTooManyAgents) {
+			t.Errorf("compare six agents: %v", err)
+		}
+	})
+}
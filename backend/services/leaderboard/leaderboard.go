@@ -0,0 +1,83 @@
+// Package leaderboard keeps the rolling-window leaderboards. Lifetime scores
+// let early agents dominate forever, so each window ranks agents only on
+// the predictions resolved within it. Computing that on every request
+// would scan every resolved prediction, so the scheduler snapshots each
+// window into LeaderboardSnapshot rows instead. The all-time leaderboard
+// ranks agents on their lifetime scores with Ranked and, once there are
+// enough of them, the scheduler materializes that ranking as well.
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// Snapshot recomputes every rolling window as of now, replacing its rows,
+// and returns how many rows were stored across all windows.
+func Snapshot(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	stored := 0
+	for window, length := range models.LeaderboardWindows {
+		n, err := SnapshotWindow(ctx, db, window, now.Add(-length), now)
+		if err != nil {
+			return stored, err
+		}
+		stored += n
+	}
+	return stored, nil
+}
+
+// SnapshotWindow replaces the window's rows with each agent's track record
+// on the predictions resolved from since up to now.
+func SnapshotWindow(ctx context.Context, db *gorm.DB, window string, since, now time.Time) (int, error) {
+	var rows []struct {
+		AgentID  int64
+		Resolved int64
+		Correct  int64
+		Scored   int64
+		Brier    float64
+	}
+	db = db.WithContext(ctx)
+	if err := db.Model(&models.Prediction{}).
+		Select(`agent_id,
+			COUNT(*) AS resolved,
+			COALESCE(SUM(CASE WHEN was_correct THEN 1 ELSE 0 END), 0) AS correct,
+			COUNT(brier_score) AS scored,
+			COALESCE(AVG(brier_score), 0) AS brier`).
+		Where("is_resolved AND resolved_at >= ? AND resolved_at <= ?", since, now).
+		Group("agent_id").
+		Scan(&rows).Error; err != nil {
+		return 0, err
+	}
+
+	snapshots := make([]models.LeaderboardSnapshot, len(rows))
+	for i, row := range rows {
+		snapshots[i] = models.LeaderboardSnapshot{
+			Window:              window,
+			AgentID:             row.AgentID,
+			ResolvedPredictions: row.Resolved,
+			CorrectPredictions:  row.Correct,
+			ScoredPredictions:   row.Scored,
+			MeanBrierScore:      row.Brier,
+			ComputedAt:          now,
+		}
+		snapshots[i].RecalculateScores()
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("time_window = ?", window).Delete(&models.LeaderboardSnapshot{}).Error; err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&snapshots, 500).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
@@ -0,0 +1,113 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestSnapshot_RanksOnlyPredictionsResolvedInTheWindow(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	now := time.Now()
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	db.Create(&market)
+
+	veteran := models.Agent{Name: "veteran", APIKey: "swarm_sk_veteran", ClaimToken: "claim_veteran", IsActive: true}
+	newcomer := models.Agent{Name: "newcomer", APIKey: "swarm_sk_newcomer", ClaimToken: "claim_newcomer", IsActive: true}
+	db.Create(&veteran)
+	db.Create(&newcomer)
+
+	resolved := func(agent models.Agent, correct bool, ago time.Duration) models.Prediction {
+		at := now.Add(-ago)
+		return models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: "YES", IsResolved: true, WasCorrect: correct, ResolvedAt: &at, PredictedAt: at}
+	}
+	predictions := []models.Prediction{
+		resolved(veteran, true, 60*24*time.Hour),
+		resolved(veteran, false, 2*24*time.Hour),
+		resolved(newcomer, true, 24*time.Hour),
+	}
+	if err := db.Create(&predictions).Error; err != nil {
+		t.Fatalf("create predictions: %v", err)
+	}
+
+	if _, err := Snapshot(context.Background(), db, now); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	byAgent := func(window string) map[int64]models.LeaderboardSnapshot {
+		var rows []models.LeaderboardSnapshot
+		db.Where("time_window = ?", window).Find(&rows)
+		out := make(map[int64]models.LeaderboardSnapshot)
+		for _, row := range rows {
+			out[row.AgentID] = row
+		}
+		return out
+	}
+
+	weekly := byAgent(models.LeaderboardWindowWeekly)
+	if len(weekly) != 2 || weekly[veteran.ID].ResolvedPredictions != 1 || weekly[veteran.ID].CorrectPredictions != 0 {
+		t.Fatalf("expected only the veteran's recent miss in the weekly window, got %+v", weekly)
+	}
+	if weekly[newcomer.ID].AccuracyScore <= weekly[veteran.ID].AccuracyScore {
+		t.Fatalf("expected the newcomer ahead this week, got %+v", weekly)
+	}
+	if quarterly := byAgent(models.LeaderboardWindowQuarterly); quarterly[veteran.ID].ResolvedPredictions != 2 {
+		t.Fatalf("expected both veteran predictions in the quarterly window, got %+v", quarterly)
+	}
+
+	// A later snapshot replaces the window's rows.
+	if _, err := Snapshot(context.Background(), db, now.Add(8*24*time.Hour)); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if weekly := byAgent(models.LeaderboardWindowWeekly); len(weekly) != 0 {
+		t.Fatalf("expected the weekly window to empty once its predictions aged out, got %+v", weekly)
+	}
+	computedAt, err := models.LeaderboardComputedAt(db, models.LeaderboardWindowMonthly)
+	if err != nil || computedAt == nil || !computedAt.Equal(now.Add(8*24*time.Hour)) {
+		t.Fatalf("expected the monthly snapshot time, got %v %v", computedAt, err)
+	}
+}
+
+func TestRefreshRanking_MaterializesOnlyPastTheThreshold(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	threshold := RankingThreshold
+	t.Cleanup(func() { RankingThreshold = threshold })
+
+	var agents []models.Agent
+	for i, score := range []float64{40, 70, 55} {
+		name := fmt.Sprintf("agent%d", i)
+		agent := models.Agent{Name: name, APIKey: "swarm_sk_" + name, ClaimToken: "claim_" + name, IsActive: true}
+		db.Create(&agent)
+		db.Model(&agent).Update("composite_score", score)
+		agents = append(agents, agent)
+	}
+	now := time.Now()
+
+	RankingThreshold = 3
+	if n, err := RefreshRanking(context.Background(), db, now); err != nil || n != 3 {
+		t.Fatalf("expected 3 agents ranked, got %d, %v", n, err)
+	}
+	standings, total, computedAt, err := CachedRanking(db, 2, 1)
+	if err != nil || total != 3 || computedAt == nil {
+		t.Fatalf("expected a ranking of 3, got %d, %v, %v", total, computedAt, err)
+	}
+	if len(standings) != 2 || standings[0].Rank != 2 || standings[0].Agent.ID != agents[2].ID || standings[1].Agent.ID != agents[0].ID {
+		t.Fatalf("expected the second page to hold agent2 then agent0, got %+v", standings)
+	}
+
+	// Below the threshold requests rank live again.
+	RankingThreshold = 4
+	if n, err := RefreshRanking(context.Background(), db, now); err != nil || n != 0 {
+		t.Fatalf("expected the ranking cleared, got %d, %v", n, err)
+	}
+	if _, _, computedAt, err := CachedRanking(db, 2, 0); err != nil || computedAt != nil {
+		t.Fatalf("expected no materialized ranking, got %v, %v", computedAt, err)
+	}
+}
@@ -0,0 +1,142 @@
+package leaderboard
+
+import (
+	"context"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// RankingThreshold is how many active agents there must be before the
+// all-time composite leaderboard is served from the materialized
+// AgentRanking rows; below it ranking on every request is cheap enough.
+var RankingThreshold int64 = 10000
+
+// sortColumns maps each all-time leaderboard sort to the agents column it
+// ranks on.
+var sortColumns = map[string]string{
+	"composite":   "composite_score",
+	"accuracy":    "accuracy_score",
+	"engagement":  "engagement_score",
+	"creator":     "creator_score",
+	"activity":    "activity_score",
+	"predictions": "total_predictions",
+}
+
+// SortColumn returns the agents column sort ranks on, falling back to the
+// composite score for an unknown sort, and the sort used.
+func SortColumn(sort string) (column, sortBy string) {
+	if column, ok := sortColumns[sort]; ok {
+		return column, sort
+	}
+	return sortColumns["composite"], "composite"
+}
+
+// Eligible returns the agents query for the active agents matched by
+// filter, the agents every all-time leaderboard ranks.
+func Eligible(db *gorm.DB, filter models.ModelCardFilter) *gorm.DB {
+	return filter.Apply(db.Model(&models.Agent{})).Where("agents.is_active = ?", true)
+}
+
+// Ranked returns Eligible ordered best first on column, ties going to the
+// older agent. Every agents leaderboard ranks with it, so they agree with
+// each other and use the idx_agents_active_* indexes.
+func Ranked(db *gorm.DB, filter models.ModelCardFilter, column string) *gorm.DB {
+	return Eligible(db, filter).Order("agents." + column + " DESC").Order("agents.id")
+}
+
+// RefreshRanking rematerializes the all-time composite ranking as of now
+// and returns how many agents it ranks. With fewer than RankingThreshold
+// active agents it clears the ranking instead, so requests rank live.
+func RefreshRanking(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+
+	var active int64
+	if err := Eligible(db, models.ModelCardFilter{}).Count(&active).Error; err != nil {
+		return 0, err
+	}
+	var rows []models.AgentRanking
+	if active >= RankingThreshold {
+		var ranked []struct {
+			ID             int64
+			CompositeScore float64
+		}
+		if err := Ranked(db, models.ModelCardFilter{}, "composite_score").
+			Select("agents.id, agents.composite_score").
+			Scan(&ranked).Error; err != nil {
+			return 0, err
+		}
+		rows = make([]models.AgentRanking, len(ranked))
+		for i, agent := range ranked {
+			rows[i] = models.AgentRanking{
+				AgentID:        agent.ID,
+				Rank:           int64(i + 1),
+				CompositeScore: agent.CompositeScore,
+				ComputedAt:     now,
+			}
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.AgentRanking{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&rows, 1000).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// Standing is an agent at its place in the materialized ranking.
+type Standing struct {
+	Rank  int64
+	Agent models.Agent
+}
+
+// CachedRanking returns the page of the materialized ranking after offset,
+// looked up by rank, with how many agents it ranks and when it was
+// computed. computedAt is nil if there is no materialized ranking. Agents
+// deleted or deactivated since it was computed are left out of the page.
+func CachedRanking(db *gorm.DB, limit, offset int) (standings []Standing, total int64, computedAt *time.Time, err error) {
+	var rankings []models.AgentRanking
+	if err := db.Where("rank > ? AND rank <= ?", offset, offset+limit).Order("rank").Find(&rankings).Error; err != nil {
+		return nil, 0, nil, err
+	}
+	var latest models.AgentRanking
+	if err := db.Order("rank DESC").Limit(1).Find(&latest).Error; err != nil {
+		return nil, 0, nil, err
+	}
+	if latest.AgentID == 0 {
+		return nil, 0, nil, nil
+	}
+
+	agentIDs := make([]int64, len(rankings))
+	for i, ranking := range rankings {
+		agentIDs[i] = ranking.AgentID
+	}
+	var agents []models.Agent
+	if len(agentIDs) > 0 {
+		if err := db.Where("id IN ? AND is_active = ?", agentIDs, true).Find(&agents).Error; err != nil {
+			return nil, 0, nil, err
+		}
+	}
+	byID := make(map[int64]models.Agent, len(agents))
+	for _, agent := range agents {
+		byID[agent.ID] = agent
+	}
+
+	standings = make([]Standing, 0, len(rankings))
+	for _, ranking := range rankings {
+		if agent, ok := byID[ranking.AgentID]; ok {
+			standings = append(standings, Standing{Rank: ranking.Rank, Agent: agent})
+		}
+	}
+	return standings, latest.Rank, &latest.ComputedAt, nil
+}
@@ -0,0 +1,424 @@
+// Package marketcreation is the single pipeline through which agent markets
+// are created, whether directly or after council approval. Every market goes
+// through the same validation, sanitization, pricing defaults and creator
+// bookkeeping regardless of the entry point.
+package marketcreation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/outbox"
+	"socialpredict/repository"
+	"socialpredict/security"
+	"socialpredict/services/autoresolve"
+	"socialpredict/setup"
+)
+
+const (
+	MaxQuestionTitleLength = 160
+	MaxDescriptionLength   = 2000
+	MaxLabelLength         = 20
+	MaxScalarUnitLength    = 20
+	MinInitialProbability  = 0.01
+	MaxInitialProbability  = 0.99
+
+	defaultCategory = "general"
+)
+
+// creatorUsername is the user row agent markets are attached to. The creator
+// foreign key still points at users, so markets reference the admin user and
+// record the real author with Market.SetCreatedBy.
+// TODO: drop once markets.creator_username is no longer required.
+const creatorUsername = "admin"
+
+// ValidationError reports input that cannot be turned into a market.
+// Handlers should surface it to the caller as a 400.
+type ValidationError struct {
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+func invalid(format string, args ...interface{}) error {
+	return &ValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// IsValidationError reports whether err was caused by bad input.
+func IsValidationError(err error) bool {
+	var ve *ValidationError
+	return errors.As(err, &ve)
+}
+
+// Input describes a market an agent wants to create.
+type Input struct {
+	QuestionTitle      string
+	Description        string
+	ResolutionDateTime time.Time
+	InitialProbability float64 // 0 means use the configured default
+	YesLabel           string
+	NoLabel            string
+	Category           string
+	Tags               []string
+	CreatorAgentID     int64
+	ClosingAuction     bool // sealed final-hour bids set the final consensus
+
+	// Provenance is set for markets created from an approved council
+	// submission.
+	Provenance *models.MarketProvenance
+	// ResolutionCriteria, if given, says exactly how the market resolves.
+	ResolutionCriteria *models.ResolutionCriteria
+	// AutoResolve, if given, has the market resolved by an oracle once its
+	// resolution time passes.
+	AutoResolve *models.OracleSpec
+	// PredictionLockHours locks predictions this many hours before the
+	// resolution time; zero locks them at the resolution time.
+	PredictionLockHours float64
+	// Visibility is one of models.MarketVisibilities; empty means public.
+	Visibility string
+	// OutcomeType is models.OutcomeBinary (the default) or
+	// models.OutcomeScalar, which asks for a number between ScalarMin and
+	// ScalarMax, both required, in ScalarUnit.
+	OutcomeType string
+	ScalarMin   *float64
+	ScalarMax   *float64
+	ScalarUnit  string
+	// SeriesID, if set, adds the market to that series. The series
+	// package checks that the market fits it.
+	SeriesID *int64
+	// MarketType is one of models.MarketTypes; empty means standard.
+	MarketType string
+	// TemplateID is set for markets created from a market template.
+	TemplateID *int64
+}
+
+type Service struct {
+	repos    *repository.Repositories
+	security *security.SecurityService
+	econ     setup.EconConfigLoader
+}
+
+func NewService(repos *repository.Repositories, econ setup.EconConfigLoader) *Service {
+	return &Service{
+		repos:    repos,
+		security: security.NewSecurityService(),
+		econ:     econ,
+	}
+}
+
+// MarketTypePolicy returns the rules cfg sets for markets of marketType.
+func MarketTypePolicy(cfg *setup.EconomicConfig, marketType string) setup.MarketTypePolicy {
+	if cfg == nil {
+		return setup.MarketTypes{}.PolicyFor(marketType)
+	}
+	return cfg.MarketTypes.PolicyFor(marketType)
+}
+
+// MinimumFutureHours returns how far in the future a market must resolve
+// under cfg, defaulting to one hour.
+func MinimumFutureHours(cfg *setup.EconomicConfig) float64 {
+	if cfg != nil && cfg.Economics.MarketCreation.MinimumFutureHours > 0 {
+		return cfg.Economics.MarketCreation.MinimumFutureHours
+	}
+	return 1.0
+}
+
+// MinimumFutureHoursFor returns how far in the future a market of
+// marketType must resolve under cfg: its market type's minimum if it has
+// one, otherwise MinimumFutureHours.
+func MinimumFutureHoursFor(cfg *setup.EconomicConfig, marketType string) float64 {
+	if policy := MarketTypePolicy(cfg, marketType); policy.MinimumFutureHours > 0 {
+		return policy.MinimumFutureHours
+	}
+	return MinimumFutureHours(cfg)
+}
+
+// Prepare validates and sanitizes in and returns the market that would be
+// created, without touching the database. Verification uses it to reject bad
+// submissions before they reach the council.
+func (s *Service) Prepare(in Input, creator *models.Agent) (*models.Market, error) {
+	title := strings.TrimSpace(in.QuestionTitle)
+	if len(title) < 1 || len(title) > MaxQuestionTitleLength {
+		return nil, invalid("question title must be 1-%d characters", MaxQuestionTitleLength)
+	}
+	if len(in.Description) > MaxDescriptionLength {
+		return nil, invalid("description must be at most %d characters", MaxDescriptionLength)
+	}
+
+	marketType, err := normalizeMarketType(in.MarketType)
+	if err != nil {
+		return nil, err
+	}
+	cfg := s.econ()
+	policy := MarketTypePolicy(cfg, marketType)
+	minimumHours := MinimumFutureHoursFor(cfg, marketType)
+	if !in.ResolutionDateTime.After(time.Now().Add(time.Duration(minimumHours * float64(time.Hour)))) {
+		return nil, invalid("resolution time must be at least %.1f hours in the future", minimumHours)
+	}
+	if policy.MaxDurationHours > 0 && in.ResolutionDateTime.After(time.Now().Add(time.Duration(policy.MaxDurationHours*float64(time.Hour)))) {
+		return nil, invalid("%s markets must resolve within %g hours", marketType, policy.MaxDurationHours)
+	}
+	lockHours := in.PredictionLockHours
+	if lockHours < 0 {
+		return nil, invalid("prediction lock must not be negative")
+	}
+	if minimumLock := policy.LockMinutes / 60; lockHours < minimumLock {
+		lockHours = minimumLock
+	}
+	if !in.ResolutionDateTime.Add(-time.Duration(lockHours * float64(time.Hour))).After(time.Now().Add(time.Duration(minimumHours * float64(time.Hour)))) {
+		return nil, invalid("predictions must stay open for at least %.1f hours", minimumHours)
+	}
+
+	sanitized, err := s.security.ValidateAndSanitizeMarketInput(security.MarketInput{
+		Title:       title,
+		Description: in.Description,
+		EndTime:     in.ResolutionDateTime.String(),
+	})
+	if err != nil {
+		return nil, invalid("invalid market data: %v", err)
+	}
+
+	yesLabel, noLabel, err := normalizeLabels(in.YesLabel, in.NoLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	probability, err := s.initialProbability(in.InitialProbability)
+	if err != nil {
+		return nil, err
+	}
+
+	category := strings.ToLower(strings.TrimSpace(in.Category))
+	if category == "" {
+		category = defaultCategory
+	}
+	if _, err := models.NormalizeTags(in.Tags); err != nil {
+		return nil, invalid("%v", err)
+	}
+	visibility, err := normalizeVisibility(in.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	outcomeType, err := normalizeOutcomeType(in)
+	if err != nil {
+		return nil, err
+	}
+
+	description := sanitized.Description
+	if creator != nil {
+		description = fmt.Sprintf("[Created by AI Agent: %s]\n\n%s", creator.Name, sanitized.Description)
+	}
+
+	market := &models.Market{
+		QuestionTitle:      sanitized.Title,
+		Description:        description,
+		OutcomeType:        outcomeType,
+		ResolutionDateTime: in.ResolutionDateTime,
+		InitialProbability: probability,
+		YesLabel:           yesLabel,
+		NoLabel:            noLabel,
+		CreatorUsername:    creatorUsername,
+		MarketType:         marketType,
+		Visibility:         visibility,
+		SeriesID:           in.SeriesID,
+		TemplateID:         in.TemplateID,
+		Category:           category,
+		ClosingAuction:     in.ClosingAuction,
+
+		PredictionLockHours: lockHours,
+	}
+	if outcomeType == models.OutcomeScalar {
+		market.ScalarMin, market.ScalarMax = in.ScalarMin, in.ScalarMax
+		market.ScalarUnit = strings.TrimSpace(in.ScalarUnit)
+	}
+	if in.Provenance != nil {
+		if err := market.SetProvenance(*in.Provenance); err != nil {
+			return nil, fmt.Errorf("encode provenance: %w", err)
+		}
+	}
+	if in.ResolutionCriteria != nil {
+		market.SetResolutionCriteria(*in.ResolutionCriteria)
+	}
+	if in.AutoResolve != nil {
+		if err := autoresolve.Validate(*in.AutoResolve); err != nil {
+			return nil, invalid("invalid auto-resolution: %v", err)
+		}
+		if err := market.SetOracleSpec(*in.AutoResolve); err != nil {
+			return nil, fmt.Errorf("encode oracle spec: %w", err)
+		}
+	}
+	if in.CreatorAgentID != 0 {
+		market.SetCreatedBy(models.AgentActor(in.CreatorAgentID))
+	}
+	return market, nil
+}
+
+// MarketCreatedEvent is the payload of outbox.TopicMarketCreated.
+type MarketCreatedEvent struct {
+	MarketID       int64  `json:"marketId"`
+	QuestionTitle  string `json:"questionTitle"`
+	Category       string `json:"category"`
+	CreatorAgentID int64  `json:"creatorAgentId"`
+	// Council submission the market came from, if any
+	SourceSubmissionID *int64 `json:"sourceSubmissionId,omitempty"`
+}
+
+// Create validates in, persists the market and updates the creating agent's
+// stats and scores in one transaction. The transaction is retried if the
+// creator was updated concurrently.
+func (s *Service) Create(in Input) (*models.Market, error) {
+	creator, err := s.repos.Agents.GetByID(in.CreatorAgentID)
+	if err != nil {
+		return nil, fmt.Errorf("load creator agent %d: %w", in.CreatorAgentID, err)
+	}
+
+	market, err := s.Prepare(in, creator)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := models.NormalizeTags(in.Tags)
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+
+	err = repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		market.ID = 0
+		return s.repos.Transaction(func(tx *repository.Repositories) error {
+			if err := tx.Markets.Create(market); err != nil {
+				return fmt.Errorf("create market: %w", err)
+			}
+			if err := tx.Markets.SetTags(market.ID, tags); err != nil {
+				return fmt.Errorf("tag market: %w", err)
+			}
+
+			// Reload inside the transaction so a retry after a concurrent
+			// update to the creator starts from its current stats.
+			agent, err := tx.Agents.GetByID(creator.ID)
+			if err != nil {
+				return fmt.Errorf("reload creator agent %d: %w", creator.ID, err)
+			}
+			agent.MarketsCreated++
+			agent.UpdateActivity()
+			agent.RecalculateCreatorScore()
+			agent.RecalculateActivityScore()
+			agent.RecalculateCompositeScore()
+			if err := tx.Agents.Save(agent); err != nil {
+				return fmt.Errorf("update creator stats: %w", err)
+			}
+
+			event, err := outbox.NewEvent(outbox.TopicMarketCreated, outbox.AggregateMarket, market.ID, MarketCreatedEvent{
+				MarketID:           market.ID,
+				QuestionTitle:      market.QuestionTitle,
+				Category:           market.Category,
+				CreatorAgentID:     agent.ID,
+				SourceSubmissionID: market.SourceSubmissionID,
+			})
+			if err != nil {
+				return err
+			}
+			if err := tx.Outbox.Enqueue(event); err != nil {
+				return fmt.Errorf("enqueue market created event: %w", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return market, nil
+}
+
+// initialProbability applies the configured default when none was given and
+// keeps the starting price strictly inside the LMSR pricing range.
+func (s *Service) initialProbability(requested float64) (float64, error) {
+	if requested == 0 {
+		requested = 0.5
+		if cfg := s.econ(); cfg != nil && cfg.Economics.MarketCreation.InitialMarketProbability > 0 {
+			requested = cfg.Economics.MarketCreation.InitialMarketProbability
+		}
+	}
+	if requested < MinInitialProbability || requested > MaxInitialProbability {
+		return 0, invalid("initial probability must be between %.0f%% and %.0f%%", MinInitialProbability*100, MaxInitialProbability*100)
+	}
+	return requested, nil
+}
+
+// normalizeVisibility lower-cases visibility, defaulting to public.
+func normalizeVisibility(visibility string) (string, error) {
+	visibility = strings.ToLower(strings.TrimSpace(visibility))
+	if visibility == "" {
+		return models.MarketPublic, nil
+	}
+	for _, v := range models.MarketVisibilities {
+		if v == visibility {
+			return visibility, nil
+		}
+	}
+	return "", invalid("visibility must be one of %s", strings.Join(models.MarketVisibilities, ", "))
+}
+
+func normalizeMarketType(marketType string) (string, error) {
+	marketType = strings.ToLower(strings.TrimSpace(marketType))
+	if marketType == "" {
+		return models.MarketTypeStandard, nil
+	}
+	for _, t := range models.MarketTypes {
+		if t == marketType {
+			return marketType, nil
+		}
+	}
+	return "", invalid("market type must be one of %s", strings.Join(models.MarketTypes, ", "))
+}
+
+// normalizeOutcomeType upper-cases the outcome type of in, defaulting to
+// binary, and checks a scalar market's bounds. Scalar markets cannot use
+// the closing auction or an oracle, which both deal in YES and NO.
+func normalizeOutcomeType(in Input) (string, error) {
+	outcomeType := strings.ToUpper(strings.TrimSpace(in.OutcomeType))
+	switch outcomeType {
+	case "", models.OutcomeBinary:
+		return models.OutcomeBinary, nil
+	case models.OutcomeScalar:
+	default:
+		return "", invalid("outcome type must be %s or %s", models.OutcomeBinary, models.OutcomeScalar)
+	}
+
+	if in.ScalarMin == nil || in.ScalarMax == nil {
+		return "", invalid("scalar markets need scalarMin and scalarMax")
+	}
+	lower, upper := *in.ScalarMin, *in.ScalarMax
+	if math.IsNaN(lower) || math.IsInf(lower, 0) || math.IsNaN(upper) || math.IsInf(upper, 0) || lower >= upper {
+		return "", invalid("scalarMin must be a number below scalarMax")
+	}
+	if len(strings.TrimSpace(in.ScalarUnit)) > MaxScalarUnitLength {
+		return "", invalid("scalarUnit must be at most %d characters", MaxScalarUnitLength)
+	}
+	if in.ClosingAuction {
+		return "", invalid("scalar markets cannot use the closing auction")
+	}
+	if in.AutoResolve != nil {
+		return "", invalid("scalar markets cannot be auto-resolved")
+	}
+	return models.OutcomeScalar, nil
+}
+
+func normalizeLabels(yesLabel, noLabel string) (string, string, error) {
+	yesLabel = strings.TrimSpace(yesLabel)
+	noLabel = strings.TrimSpace(noLabel)
+	if yesLabel == "" {
+		yesLabel = "YES"
+	}
+	if noLabel == "" {
+		noLabel = "NO"
+	}
+	if len(yesLabel) > MaxLabelLength || len(noLabel) > MaxLabelLength {
+		return "", "", invalid("labels must be %d characters or less", MaxLabelLength)
+	}
+	return yesLabel, noLabel, nil
+}
@@ -0,0 +1,194 @@
+package marketcreation
+
+import (
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+	"socialpredict/repository"
+	"socialpredict/repository/repositorytesting"
+
+	"go.uber.org/mock/gomock"
+)
+
+// testRepos are the mocks behind a test Service. The markets it creates,
+// the tags it sets and the events it enqueues are recorded in created,
+// tags and events.
+type testRepos struct {
+	agents  *repositorytesting.MockAgentRepo
+	markets *repositorytesting.MockMarketRepo
+	outbox  *repositorytesting.MockOutboxRepo
+	created []*models.Market
+	tags    map[int64][]string
+	events  []models.OutboxEvent
+}
+
+// newTestService returns a Service whose creator is always agent. Creating
+// a market assigns it the next ID. Tests that create a market expect the
+// creator to be saved themselves.
+func newTestService(t *testing.T, agent *models.Agent) (*Service, *testRepos) {
+	ctrl := gomock.NewController(t)
+	mocks := &testRepos{
+		agents:  repositorytesting.NewMockAgentRepo(ctrl),
+		markets: repositorytesting.NewMockMarketRepo(ctrl),
+		outbox:  repositorytesting.NewMockOutboxRepo(ctrl),
+		tags:    make(map[int64][]string),
+	}
+	mocks.agents.EXPECT().GetByID(gomock.Any()).Return(agent, nil).AnyTimes()
+	mocks.markets.EXPECT().Create(gomock.Any()).DoAndReturn(func(m *models.Market) error {
+		m.ID = int64(len(mocks.created) + 1)
+		mocks.created = append(mocks.created, m)
+		return nil
+	}).AnyTimes()
+	mocks.markets.EXPECT().SetTags(gomock.Any(), gomock.Any()).DoAndReturn(func(marketID int64, tags []string) error {
+		mocks.tags[marketID] = tags
+		return nil
+	}).AnyTimes()
+	mocks.outbox.EXPECT().Enqueue(gomock.Any()).DoAndReturn(func(event *models.OutboxEvent) error {
+		mocks.events = append(mocks.events, *event)
+		return nil
+	}).AnyTimes()
+
+	repos := &repository.Repositories{Agents: mocks.agents, Markets: mocks.markets, Outbox: mocks.outbox}
+	return NewService(repos, modelstesting.GenerateEconomicConfig), mocks
+}
+
+func TestCreate_AppliesDefaultsAndCreatorStats(t *testing.T) {
+	agent := &models.Agent{ID: 7, Name: "forecaster"}
+	svc, mocks := newTestService(t, agent)
+	mocks.agents.EXPECT().Save(agent).Return(nil)
+
+	market, err := svc.Create(Input{
+		QuestionTitle:      "Will it rain in Paris tomorrow?",
+		Description:        "Resolves YES if Météo-France reports rain.",
+		ResolutionDateTime: time.Now().Add(48 * time.Hour),
+		CreatorAgentID:     agent.ID,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(mocks.created) != 1 {
+		t.Fatalf("expected one market persisted, got %d", len(mocks.created))
+	}
+	if market.CreatorAgentID == nil || *market.CreatorAgentID != agent.ID {
+		t.Fatalf("expected creator agent %d, got %v", agent.ID, market.CreatorAgentID)
+	}
+	if market.YesLabel != "YES" || market.NoLabel != "NO" || market.Category != "general" {
+		t.Fatalf("unexpected defaults: %+v", market)
+	}
+	if market.InitialProbability <= 0 || market.InitialProbability >= 1 {
+		t.Fatalf("expected initial probability in (0,1), got %v", market.InitialProbability)
+	}
+	if agent.MarketsCreated != 1 {
+		t.Fatalf("expected creator MarketsCreated=1, got %d", agent.MarketsCreated)
+	}
+	events := mocks.events
+	if len(events) != 1 || events[0].Topic != outbox.TopicMarketCreated || events[0].AggregateID != market.ID {
+		t.Fatalf("expected one market created event, got %+v", events)
+	}
+}
+
+func TestCreate_RetriesOnStaleCreator(t *testing.T) {
+	agent := &models.Agent{ID: 7, Name: "forecaster"}
+	svc, mocks := newTestService(t, agent)
+
+	saves := 0
+	gomock.InOrder(
+		mocks.agents.EXPECT().Save(agent).Do(func(*models.Agent) { saves++ }).Return(models.ErrStaleVersion),
+		mocks.agents.EXPECT().Save(agent).Do(func(*models.Agent) { saves++ }).Return(nil),
+	)
+
+	_, err := svc.Create(Input{
+		QuestionTitle:      "Will the retry path create the market?",
+		ResolutionDateTime: time.Now().Add(48 * time.Hour),
+		CreatorAgentID:     agent.ID,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if saves != 2 || len(mocks.created) != 2 {
+		t.Fatalf("expected one retry, got %d saves and %d creates", saves, len(mocks.created))
+	}
+}
+
+func TestPrepare_RejectsInvalidInput(t *testing.T) {
+	svc, _ := newTestService(t, &models.Agent{ID: 1})
+	future := time.Now().Add(48 * time.Hour)
+
+	tests := []struct {
+		name  string
+		input Input
+	}{
+		{"EmptyTitle", Input{QuestionTitle: " ", ResolutionDateTime: future}},
+		{"PastResolution", Input{QuestionTitle: "Valid question title?", ResolutionDateTime: time.Now()}},
+		{"ProbabilityOutOfRange", Input{QuestionTitle: "Valid question title?", ResolutionDateTime: future, InitialProbability: 1.5}},
+		{"LongLabel", Input{QuestionTitle: "Valid question title?", ResolutionDateTime: future, YesLabel: "this label is far too long"}},
+		{"NegativeLock", Input{QuestionTitle: "Valid question title?", ResolutionDateTime: future, PredictionLockHours: -1}},
+		{"LockedAlready", Input{QuestionTitle: "Valid question title?", ResolutionDateTime: future, PredictionLockHours: 48}},
+		{"UnknownMarketType", Input{QuestionTitle: "Valid question title?", ResolutionDateTime: future, MarketType: "weekly"}},
+		{"DailyTooLong", Input{QuestionTitle: "Valid question title?", ResolutionDateTime: time.Now().Add(72 * time.Hour), MarketType: models.MarketTypeDaily}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := svc.Prepare(test.input, nil)
+			if !IsValidationError(err) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPrepare_AppliesMarketTypeRules(t *testing.T) {
+	svc, _ := newTestService(t, &models.Agent{ID: 1})
+	soon := time.Now().Add(45 * time.Minute)
+
+	if _, err := svc.Prepare(Input{QuestionTitle: "Valid question title?", ResolutionDateTime: soon}, nil); !IsValidationError(err) {
+		t.Fatalf("expected a standard market resolving in 45 minutes to be refused, got %v", err)
+	}
+	market, err := svc.Prepare(Input{QuestionTitle: "Valid question title?", ResolutionDateTime: soon, MarketType: "Realtime"}, nil)
+	if err != nil {
+		t.Fatalf("expected a realtime market resolving in 45 minutes, got %v", err)
+	}
+	if market.MarketType != models.MarketTypeRealtime {
+		t.Fatalf("expected market type %q, got %q", models.MarketTypeRealtime, market.MarketType)
+	}
+	if want := 5.0 / 60; market.PredictionLockHours != want {
+		t.Fatalf("expected predictions to lock at least 5 minutes early, got %v hours", market.PredictionLockHours)
+	}
+
+	market, err = svc.Prepare(Input{QuestionTitle: "Valid question title?", ResolutionDateTime: time.Now().Add(24 * time.Hour), MarketType: models.MarketTypeDaily, PredictionLockHours: 2}, nil)
+	if err != nil {
+		t.Fatalf("Prepare daily: %v", err)
+	}
+	if market.PredictionLockHours != 2 {
+		t.Fatalf("expected a longer lock to be kept, got %v hours", market.PredictionLockHours)
+	}
+}
+
+func TestCreate_PersistsMarketTagsAndCreatorStats(t *testing.T) {
+	agent := &models.Agent{ID: 7, Name: "forecaster"}
+	svc, mocks := newTestService(t, agent)
+	mocks.agents.EXPECT().Save(agent).Return(nil).Times(1)
+
+	market, err := svc.Create(Input{
+		QuestionTitle:      "Will this market keep its tags?",
+		ResolutionDateTime: time.Now().Add(48 * time.Hour),
+		CreatorAgentID:     agent.ID,
+		Tags:               []string{"Weather", "weather", "Paris"},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if len(mocks.created) != 1 || mocks.created[0] != market {
+		t.Fatalf("expected the market to be stored, got %+v", mocks.created)
+	}
+	if tags := mocks.tags[market.ID]; len(tags) != 2 || tags[0] != "paris" || tags[1] != "weather" {
+		t.Fatalf("expected normalized tags, got %v", tags)
+	}
+	if agent.MarketsCreated != 1 {
+		t.Fatalf("expected the creator saved with MarketsCreated=1, got %d", agent.MarketsCreated)
+	}
+}
@@ -0,0 +1,52 @@
+// Package marketlisting picks the listed markets in each status: active,
+// closed or resolved. The HTTP, gRPC and GraphQL APIs list markets through
+// it so they agree on what each status means.
+package marketlisting
+
+import (
+	"log"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// Filter narrows a markets query to one status.
+type Filter func(*gorm.DB) *gorm.DB
+
+// Filters are the status filters by the name clients use for them.
+var Filters = map[string]Filter{
+	"active":   ActiveFilter,
+	"closed":   ClosedFilter,
+	"resolved": ResolvedFilter,
+}
+
+// ListByStatus returns the 100 newest listed markets filter picks,
+// leaving out markets hidden by moderation.
+func ListByStatus(db *gorm.DB, filter Filter) ([]models.Market, error) {
+	var markets []models.Market
+	query := models.WithoutHidden(models.Listed(filter(db.Model(&models.Market{}))), models.ContentMarket).Order("created_at DESC").Limit(100)
+	if err := query.Find(&markets).Error; err != nil {
+		log.Printf("Error fetching filtered markets: %v", err)
+		return nil, err
+	}
+	return markets, nil
+}
+
+// ActiveFilter picks markets that are not resolved and have not yet
+// reached their resolution date.
+func ActiveFilter(db *gorm.DB) *gorm.DB {
+	return db.Where("is_resolved = ? AND resolution_date_time > ?", false, time.Now())
+}
+
+// ClosedFilter picks markets that are not resolved but have passed their
+// resolution date.
+func ClosedFilter(db *gorm.DB) *gorm.DB {
+	return db.Where("is_resolved = ? AND resolution_date_time <= ?", false, time.Now())
+}
+
+// ResolvedFilter picks markets that have been resolved.
+func ResolvedFilter(db *gorm.DB) *gorm.DB {
+	return db.Where("is_resolved = ?", true)
+}
@@ -0,0 +1,244 @@
+package marketlisting
+
+import (
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func TestActiveFilter(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	// Create test data
+	now := time.Now()
+	futureTime := now.Add(24 * time.Hour)
+	pastTime := now.Add(-24 * time.Hour)
+
+	// Create test user
+	testUser := modelstesting.GenerateUser("testuser", 1000)
+	db.Create(&testUser)
+
+	// Active market (not resolved, future resolution date)
+	activeMarket := models.Market{
+		ID:                 1,
+		QuestionTitle:      "Active Market",
+		Description:        "Test active market",
+		OutcomeType:        "BINARY",
+		ResolutionDateTime: futureTime,
+		IsResolved:         false,
+		InitialProbability: 0.5,
+		CreatorUsername:    "testuser",
+	}
+
+	// Closed market (not resolved, past resolution date)
+	closedMarket := models.Market{
+		ID:                 2,
+		QuestionTitle:      "Closed Market",
+		Description:        "Test closed market",
+		OutcomeType:        "BINARY",
+		ResolutionDateTime: pastTime,
+		IsResolved:         false,
+		InitialProbability: 0.5,
+		CreatorUsername:    "testuser",
+	}
+
+	// Resolved market
+	resolvedMarket := models.Market{
+		ID:                      3,
+		QuestionTitle:           "Resolved Market",
+		Description:             "Test resolved market",
+		OutcomeType:             "BINARY",
+		ResolutionDateTime:      pastTime,
+		FinalResolutionDateTime: pastTime,
+		IsResolved:              true,
+		ResolutionResult:        "YES",
+		InitialProbability:      0.5,
+		CreatorUsername:         "testuser",
+	}
+
+	// Insert test data
+	db.Create(&activeMarket)
+	db.Create(&closedMarket)
+	db.Create(&resolvedMarket)
+
+	// Test ActiveFilter
+	var activeResults []models.Market
+	ActiveFilter(db).Find(&activeResults)
+	if len(activeResults) != 1 {
+		t.Errorf("Expected 1 active market, got %d", len(activeResults))
+	}
+	if activeResults[0].QuestionTitle != "Active Market" {
+		t.Errorf("Expected 'Active Market', got %s", activeResults[0].QuestionTitle)
+	}
+	if activeResults[0].IsResolved {
+		t.Error("Expected market to not be resolved")
+	}
+	if !activeResults[0].ResolutionDateTime.After(now) {
+		t.Error("Expected resolution date to be in the future")
+	}
+}
+
+func TestClosedFilter(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	// Create test data
+	now := time.Now()
+	futureTime := now.Add(24 * time.Hour)
+	pastTime := now.Add(-24 * time.Hour)
+
+	// Create test user
+	testUser := modelstesting.GenerateUser("testuser", 1000)
+	db.Create(&testUser)
+
+	// Active market
+	activeMarket := models.Market{
+		ID:                 1,
+		QuestionTitle:      "Active Market",
+		Description:        "Test active market",
+		OutcomeType:        "BINARY",
+		ResolutionDateTime: futureTime,
+		IsResolved:         false,
+		InitialProbability: 0.5,
+		CreatorUsername:    "testuser",
+	}
+
+	// Closed market
+	closedMarket := models.Market{
+		ID:                 2,
+		QuestionTitle:      "Closed Market",
+		Description:        "Test closed market",
+		OutcomeType:        "BINARY",
+		ResolutionDateTime: pastTime,
+		IsResolved:         false,
+		InitialProbability: 0.5,
+		CreatorUsername:    "testuser",
+	}
+
+	// Insert test data
+	db.Create(&activeMarket)
+	db.Create(&closedMarket)
+
+	// Test ClosedFilter
+	var closedResults []models.Market
+	ClosedFilter(db).Find(&closedResults)
+	if len(closedResults) != 1 {
+		t.Errorf("Expected 1 closed market, got %d", len(closedResults))
+	}
+	if closedResults[0].QuestionTitle != "Closed Market" {
+		t.Errorf("Expected 'Closed Market', got %s", closedResults[0].QuestionTitle)
+	}
+	if closedResults[0].IsResolved {
+		t.Error("Expected market to not be resolved")
+	}
+	if closedResults[0].ResolutionDateTime.After(now) {
+		t.Error("Expected resolution date to be in the past")
+	}
+}
+
+func TestResolvedFilter(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	// Create test data
+	pastTime := time.Now().Add(-24 * time.Hour)
+
+	// Create test user
+	testUser := modelstesting.GenerateUser("testuser", 1000)
+	db.Create(&testUser)
+
+	// Unresolved market
+	unresolvedMarket := models.Market{
+		ID:                 1,
+		QuestionTitle:      "Unresolved Market",
+		Description:        "Test unresolved market",
+		OutcomeType:        "BINARY",
+		ResolutionDateTime: pastTime,
+		IsResolved:         false,
+		InitialProbability: 0.5,
+		CreatorUsername:    "testuser",
+	}
+
+	// Resolved market
+	resolvedMarket := models.Market{
+		ID:                      2,
+		QuestionTitle:           "Resolved Market",
+		Description:             "Test resolved market",
+		OutcomeType:             "BINARY",
+		ResolutionDateTime:      pastTime,
+		FinalResolutionDateTime: pastTime,
+		IsResolved:              true,
+		ResolutionResult:        "YES",
+		InitialProbability:      0.5,
+		CreatorUsername:         "testuser",
+	}
+
+	// Insert test data
+	db.Create(&unresolvedMarket)
+	db.Create(&resolvedMarket)
+
+	// Test ResolvedFilter
+	var resolvedResults []models.Market
+	ResolvedFilter(db).Find(&resolvedResults)
+	if len(resolvedResults) != 1 {
+		t.Errorf("Expected 1 resolved market, got %d", len(resolvedResults))
+	}
+	if resolvedResults[0].QuestionTitle != "Resolved Market" {
+		t.Errorf("Expected 'Resolved Market', got %s", resolvedResults[0].QuestionTitle)
+	}
+	if !resolvedResults[0].IsResolved {
+		t.Error("Expected market to be resolved")
+	}
+	if resolvedResults[0].ResolutionResult != "YES" {
+		t.Errorf("Expected resolution result 'YES', got %s", resolvedResults[0].ResolutionResult)
+	}
+}
+
+func TestListByStatus(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	// Create test user first
+	testUser := modelstesting.GenerateUser("testuser", 1000)
+	db.Create(&testUser)
+
+	// Create test data
+	futureTime := time.Now().Add(24 * time.Hour)
+
+	activeMarket := models.Market{
+		ID:                 1,
+		QuestionTitle:      "Active Market",
+		Description:        "Test active market",
+		OutcomeType:        "BINARY",
+		ResolutionDateTime: futureTime,
+		IsResolved:         false,
+		InitialProbability: 0.5,
+		CreatorUsername:    "testuser",
+	}
+
+	db.Create(&activeMarket)
+
+	// Test ListByStatus with ActiveFilter
+	markets, err := ListByStatus(db, ActiveFilter)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(markets) != 1 {
+		t.Errorf("Expected 1 market, got %d", len(markets))
+	}
+	if markets[0].QuestionTitle != "Active Market" {
+		t.Errorf("Expected 'Active Market', got %s", markets[0].QuestionTitle)
+	}
+}
+
+func TestListByStatusWithEmptyResults(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+
+	// Test with no markets in database
+	markets, err := ListByStatus(db, ActiveFilter)
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(markets) != 0 {
+		t.Errorf("Expected 0 markets, got %d", len(markets))
+	}
+}
@@ -0,0 +1,158 @@
+// Package mentions finds the agents a comment names with @name and notifies
+// them, along with the agent the comment replies to.
+package mentions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"socialpredict/models"
+	"socialpredict/notifications"
+	"socialpredict/services/agentnames"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxPerComment caps how many agents one comment can mention.
+const MaxPerComment = 10
+
+// excerptLength is how much of the comment notifications quote.
+const excerptLength = 140
+
+// pattern matches @name where the @ starts a word, so addresses such as
+// ops@example.com are not mentions.
+var pattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_][A-Za-z0-9_.-]{2,49})`)
+
+// ErrTooManyMentions is returned by Resolve for more than MaxPerComment
+// names.
+var ErrTooManyMentions = fmt.Errorf("a comment can mention at most %d agents", MaxPerComment)
+
+// UnknownError is returned by Resolve for names that are no agent's.
+type UnknownError struct {
+	Names []string
+}
+
+func (e *UnknownError) Error() string {
+	return "no agent named @" + strings.Join(e.Names, ", @")
+}
+
+// Invalid reports whether err from Resolve is the comment's fault rather
+// than the database's.
+func Invalid(err error) bool {
+	var unknown *UnknownError
+	return errors.Is(err, ErrTooManyMentions) || errors.As(err, &unknown)
+}
+
+// Parse returns the names content mentions, each once, in the order they
+// first appear. Names are case-sensitive, as agent names are everywhere
+// else, so @Oracle and @oracle are different names. Trailing dots and
+// hyphens are punctuation, not part of the name.
+func Parse(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+		name := strings.TrimRight(match[1], ".-")
+		if len(name) < 3 || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// Resolve returns the agents going by names, following renames, and an
+// *UnknownError naming any that are not agents.
+func Resolve(db *gorm.DB, names []string) ([]models.Agent, error) {
+	if len(names) > MaxPerComment {
+		return nil, ErrTooManyMentions
+	}
+	agents := make([]models.Agent, 0, len(names))
+	var unknown []string
+	for _, name := range names {
+		agent, _, err := agentnames.Resolve(db, name)
+		if errors.Is(err, agentnames.ErrAgentNotFound) {
+			unknown = append(unknown, name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, *agent)
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownError{Names: unknown}
+	}
+	return agents, nil
+}
+
+// Comment is a comment being written, as mentions and replies see it.
+type Comment struct {
+	Type       string // models.MentionPredictionComment or models.MentionProposalComment
+	ID         int64
+	ParentType string // prediction or proposal
+	ParentID   int64
+	Author     models.Actor
+	AuthorName string
+	Content    string
+	// ReplyTo is the agent the comment answers: the author of the
+	// prediction, proposal or comment it was written on. 0 for none.
+	ReplyTo int64
+}
+
+// Notify records a mention of each of agents in comment and notifies those
+// it had not mentioned before, then notifies the agent it replies to unless
+// that agent was mentioned too. Nobody is notified of their own comment.
+// Run it in the transaction that writes the comment.
+func Notify(tx *gorm.DB, comment Comment, agents []models.Agent) error {
+	notice := notifications.CommentNotice{
+		CommentType: comment.Type,
+		CommentID:   comment.ID,
+		ParentType:  comment.ParentType,
+		ParentID:    comment.ParentID,
+		AuthorType:  string(comment.Author.Type),
+		AuthorID:    comment.Author.ID,
+		AuthorName:  comment.AuthorName,
+		Excerpt:     excerpt(comment.Content),
+	}
+
+	mentioned := make(map[int64]bool, len(agents))
+	for _, agent := range agents {
+		if models.AgentActor(agent.ID) == comment.Author {
+			continue
+		}
+		mentioned[agent.ID] = true
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Mention{
+			CommentType: comment.Type,
+			CommentID:   comment.ID,
+			AgentID:     agent.ID,
+			AuthorType:  string(comment.Author.Type),
+			AuthorID:    comment.Author.ID,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		if err := notifications.SendCommentMention(tx, agent.ID, notice); err != nil {
+			return err
+		}
+	}
+
+	if comment.ReplyTo == 0 || mentioned[comment.ReplyTo] || models.AgentActor(comment.ReplyTo) == comment.Author {
+		return nil
+	}
+	return notifications.SendCommentReply(tx, comment.ReplyTo, notice)
+}
+
+// excerpt shortens content for a notification.
+func excerpt(content string) string {
+	if runes := []rune(content); len(runes) > excerptLength {
+		return string(runes[:excerptLength-3]) + "..."
+	}
+	return content
+}
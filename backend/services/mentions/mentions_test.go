@@ -0,0 +1,33 @@
+package mentions
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"no mentions here", nil},
+		{"@oracle thinks so", []string{"oracle"}},
+		{"ask @oracle. Or @quant-bot!", []string{"oracle", "quant-bot"}},
+		{"@oracle, @oracle and @Oracle", []string{"oracle", "Oracle"}},
+		{"mail ops@example.com or @@oracle", nil},
+		{"(@v2.agent) @ab", []string{"v2.agent"}},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.content); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestResolve_RefusesTooMany(t *testing.T) {
+	names := strings.Fields(strings.Repeat("agent ", MaxPerComment+1))
+	if _, err := Resolve(nil, names); !Invalid(err) {
+		t.Fatalf("expected too many mentions to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+// Package platformconfig lets admins change platform parameters, such as
+// how many council votes decide a market, without a deploy. setup.yaml
+// supplies the defaults; values set through the admin API are stored as
+// PlatformConfig rows and override them. The overrides are cached
+// in-process: a change made here takes effect at once, and one made on
+// another instance within CacheTTL.
+package platformconfig
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CacheTTL is how long the overrides are cached before being read again.
+const CacheTTL = time.Minute
+
+var (
+	ErrUnknownParameter = errors.New("unknown platform parameter")
+	ErrInvalidValue     = errors.New("invalid parameter value")
+)
+
+// Parameter is a platform setting that can be changed at runtime.
+type Parameter struct {
+	Name        string  `json:"name"`
+	Kind        string  `json:"kind"` // int or float
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Description string  `json:"description"`
+
+	get func(*setup.EconomicConfig) float64
+	set func(*setup.EconomicConfig, float64)
+}
+
+// Check returns an error wrapping ErrInvalidValue if value is out of the
+// parameter's bounds or, for an int parameter, not a whole number.
+func (p Parameter) Check(value float64) error {
+	if p.Kind == models.ParameterInt && value != math.Trunc(value) {
+		return fmt.Errorf("%w: %s must be a whole number", ErrInvalidValue, p.Name)
+	}
+	if value < p.Min || value > p.Max {
+		return fmt.Errorf("%w: %s must be between %g and %g", ErrInvalidValue, p.Name, p.Min, p.Max)
+	}
+	return nil
+}
+
+var parameters = append(append(append(append(
+	councilParameters(models.SubmissionTypeMarket),
+	councilParameters(models.SubmissionTypePrediction)...),
+	councilParameters(models.SubmissionTypeResolution)...),
+	councilParameters(models.SubmissionTypeTemplate)...),
+	parameter("governance.defaultVotingDays", models.ParameterInt, 1, 30,
+		"Days a proposal is open for votes unless the proposer says otherwise",
+		func(c *setup.EconomicConfig) float64 { return float64(c.Governance.OrDefaults().DefaultVotingDays) },
+		func(c *setup.EconomicConfig, v float64) { c.Governance.DefaultVotingDays = int(v) }),
+	parameter("governance.voteThreshold", models.ParameterInt, 1, 1000,
+		"Votes a proposal needs to pass",
+		func(c *setup.EconomicConfig) float64 { return float64(c.Governance.OrDefaults().VoteThreshold) },
+		func(c *setup.EconomicConfig, v float64) { c.Governance.VoteThreshold = int64(v) }),
+	parameter("governance.approvalPct", models.ParameterFloat, 50, 100,
+		"Percent of the vote weight in favour a proposal needs to pass",
+		func(c *setup.EconomicConfig) float64 { return c.Governance.OrDefaults().ApprovalPct },
+		func(c *setup.EconomicConfig, v float64) { c.Governance.ApprovalPct = v }),
+	parameter("governance.editMaxVotes", models.ParameterInt, 1, 1000,
+		"Votes after which a proposal can only be amended",
+		func(c *setup.EconomicConfig) float64 { return float64(c.Governance.OrDefaults().EditMaxVotes) },
+		func(c *setup.EconomicConfig, v float64) { c.Governance.EditMaxVotes = int64(v) }),
+	parameter("disputes.windowHours", models.ParameterFloat, 1, 720,
+		"Hours after resolving that a market can be disputed",
+		func(c *setup.EconomicConfig) float64 { return c.Disputes.OrDefaults().WindowHours },
+		func(c *setup.EconomicConfig, v float64) { c.Disputes.WindowHours = v }),
+	parameter("disputes.weightRequired", models.ParameterFloat, 1, 1000,
+		"Dispute weight that sends a resolution back to the council",
+		func(c *setup.EconomicConfig) float64 { return c.Disputes.OrDefaults().WeightRequired },
+		func(c *setup.EconomicConfig, v float64) { c.Disputes.WeightRequired = v }),
+	parameter("privateMarkets.bypassMinScore", models.ParameterFloat, 0, 100,
+		"Composite score an agent needs for its private market submissions to skip the council",
+		func(c *setup.EconomicConfig) float64 { return c.PrivateMarkets.BypassMinScore },
+		func(c *setup.EconomicConfig, v float64) { c.PrivateMarkets.BypassMinScore = v }),
+	parameter("verification.minQuestionLength", models.ParameterInt, 1, 160,
+		"Characters a submitted market's question needs",
+		func(c *setup.EconomicConfig) float64 { return float64(c.Verification.OrDefaults().MinQuestionLength) },
+		func(c *setup.EconomicConfig, v float64) { c.Verification.MinQuestionLength = int(v) }),
+	parameter("verification.minDescriptionLength", models.ParameterInt, 1, 2000,
+		"Characters a submitted market's description needs",
+		func(c *setup.EconomicConfig) float64 {
+			return float64(c.Verification.OrDefaults().MinDescriptionLength)
+		},
+		func(c *setup.EconomicConfig, v float64) { c.Verification.MinDescriptionLength = int(v) }),
+	parameter("verification.minReasoningLength", models.ParameterInt, 1, 2000,
+		"Characters a submitted prediction's reasoning needs",
+		func(c *setup.EconomicConfig) float64 { return float64(c.Verification.OrDefaults().MinReasoningLength) },
+		func(c *setup.EconomicConfig, v float64) { c.Verification.MinReasoningLength = int(v) }),
+	parameter("verification.duplicateSimilarity", models.ParameterFloat, 0.1, 1,
+		"Title similarity to an existing market at which a submission is rejected as a duplicate",
+		func(c *setup.EconomicConfig) float64 { return c.Verification.OrDefaults().DuplicateSimilarity },
+		func(c *setup.EconomicConfig, v float64) { c.Verification.DuplicateSimilarity = v }),
+	parameter("verification.similarMarketMinScore", models.ParameterFloat, 0.05, 1,
+		"Title similarity at which an existing market is listed for the council to compare",
+		func(c *setup.EconomicConfig) float64 { return c.Verification.OrDefaults().SimilarMarketMinScore },
+		func(c *setup.EconomicConfig, v float64) { c.Verification.SimilarMarketMinScore = v }),
+	parameter("verification.similarMarketsShown", models.ParameterInt, 1, 20,
+		"Similar existing markets listed for the council",
+		func(c *setup.EconomicConfig) float64 { return float64(c.Verification.OrDefaults().SimilarMarketsShown) },
+		func(c *setup.EconomicConfig, v float64) { c.Verification.SimilarMarketsShown = int(v) }),
+)
+
+func parameter(name, kind string, min, max float64, description string, get func(*setup.EconomicConfig) float64, set func(*setup.EconomicConfig, float64)) Parameter {
+	return Parameter{Name: name, Kind: kind, Min: min, Max: max, Description: description, get: get, set: set}
+}
+
+// councilParameters returns the parameters of the council's review policy
+// for submissionType.
+func councilParameters(submissionType string) []Parameter {
+	policyParameter := func(field, kind string, min, max float64, description string, get func(setup.CouncilPolicy) float64, set func(*setup.CouncilPolicy, float64)) Parameter {
+		return parameter("council."+submissionType+"."+field, kind, min, max, description,
+			func(c *setup.EconomicConfig) float64 { return get(c.Council.PolicyFor(submissionType)) },
+			func(c *setup.EconomicConfig, v float64) {
+				policy := c.Council.PolicyFor(submissionType)
+				set(&policy, v)
+				c.Council.Policies[submissionType] = policy
+			})
+	}
+	return []Parameter{
+		policyParameter("votesRequired", models.ParameterInt, 1, 50,
+			"Council votes that decide a "+submissionType+" submission early",
+			func(p setup.CouncilPolicy) float64 { return float64(p.VotesRequired) },
+			func(p *setup.CouncilPolicy, v float64) { p.VotesRequired = int(v) }),
+		policyParameter("minVoters", models.ParameterInt, 1, 50,
+			"Council votes needed to decide a "+submissionType+" submission at all",
+			func(p setup.CouncilPolicy) float64 { return float64(p.MinVoters) },
+			func(p *setup.CouncilPolicy, v float64) { p.MinVoters = int(v) }),
+		policyParameter("approvalThreshold", models.ParameterFloat, 50, 100,
+			"Percent of the weighted council vote that approves a "+submissionType+" submission",
+			func(p setup.CouncilPolicy) float64 { return p.ApprovalThreshold },
+			func(p *setup.CouncilPolicy, v float64) { p.ApprovalThreshold = v }),
+		policyParameter("votingHours", models.ParameterFloat, 1, 720,
+			"Hours the council has to vote on a "+submissionType+" submission",
+			func(p setup.CouncilPolicy) float64 { return p.VotingHours },
+			func(p *setup.CouncilPolicy, v float64) { p.VotingHours = v }),
+	}
+}
+
+// Parameters returns every parameter that can be changed at runtime.
+func Parameters() []Parameter {
+	return append([]Parameter(nil), parameters...)
+}
+
+// Lookup returns the parameter called name.
+func Lookup(name string) (Parameter, bool) {
+	for _, p := range parameters {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// ValueIn returns the parameter's value in config.
+func (p Parameter) ValueIn(config *setup.EconomicConfig) float64 {
+	return p.get(config)
+}
+
+var cache struct {
+	sync.Mutex
+	overrides map[string]float64
+	loadedAt  time.Time
+}
+
+// overrides returns the stored parameter values, from the cache while it
+// is fresh.
+func overrides(db *gorm.DB) (map[string]float64, error) {
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.overrides != nil && time.Since(cache.loadedAt) < CacheTTL {
+		return cache.overrides, nil
+	}
+
+	var rows []models.PlatformConfig
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		values[row.Name] = row.Value
+	}
+	cache.overrides, cache.loadedAt = values, time.Now()
+	return values, nil
+}
+
+// Invalidate drops the cached overrides so the next read loads them again.
+func Invalidate() {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.overrides = nil
+}
+
+// Current returns the config in effect: setup.yaml with the stored
+// overrides applied. If the overrides cannot be loaded it logs why and
+// returns setup.yaml's config.
+func Current(db *gorm.DB) *setup.EconomicConfig {
+	base := setup.EconomicsConfig()
+	values, err := overrides(db)
+	if err != nil {
+		log.Printf("platformconfig: loading overrides: %v", err)
+		return base
+	}
+	if len(values) == 0 {
+		return base
+	}
+
+	config := *base
+	config.Council.Policies = make(map[string]setup.CouncilPolicy, len(base.Council.Policies))
+	for submissionType, policy := range base.Council.Policies {
+		config.Council.Policies[submissionType] = policy
+	}
+	for _, p := range parameters {
+		if value, ok := values[p.Name]; ok {
+			p.set(&config, value)
+		}
+	}
+	return &config
+}
+
+// Set stores value for the named parameter, recording who changed it and
+// the governance proposal behind the change, if any, and invalidates the
+// cache.
+func Set(db *gorm.DB, name string, value float64, updatedBy string, proposalID *int64) (models.PlatformConfig, error) {
+	p, ok := Lookup(name)
+	if !ok {
+		return models.PlatformConfig{}, ErrUnknownParameter
+	}
+	if err := p.Check(value); err != nil {
+		return models.PlatformConfig{}, err
+	}
+
+	row := models.PlatformConfig{
+		Name:       p.Name,
+		Kind:       p.Kind,
+		Value:      value,
+		ProposalID: proposalID,
+		UpdatedBy:  updatedBy,
+		UpdatedAt:  time.Now(),
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"kind", "value", "proposal_id", "updated_by", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return row, err
+	}
+	Invalidate()
+	return row, nil
+}
+
+// Reset deletes the named parameter's override so its setup.yaml default
+// applies again, and invalidates the cache.
+func Reset(db *gorm.DB, name string) error {
+	if _, ok := Lookup(name); !ok {
+		return ErrUnknownParameter
+	}
+	if err := db.Where("name = ?", name).Delete(&models.PlatformConfig{}).Error; err != nil {
+		return err
+	}
+	Invalidate()
+	return nil
+}
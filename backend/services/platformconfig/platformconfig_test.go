@@ -0,0 +1,75 @@
+package platformconfig
+
+import (
+	"errors"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/setup"
+)
+
+func TestSet_OverridesTheDefaultUntilReset(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	t.Cleanup(Invalidate)
+	base := setup.EconomicsConfig().Council.PolicyFor(models.SubmissionTypeMarket)
+
+	if _, err := Set(db, "council.market.votesRequired", float64(base.VotesRequired+2), "test", nil); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	policy := Current(db).Council.PolicyFor(models.SubmissionTypeMarket)
+	if policy.VotesRequired != base.VotesRequired+2 || policy.ApprovalThreshold != base.ApprovalThreshold {
+		t.Fatalf("expected only votesRequired to change from %+v, got %+v", base, policy)
+	}
+	if setup.EconomicsConfig().Council.PolicyFor(models.SubmissionTypeMarket) != base {
+		t.Fatalf("expected the setup.yaml config to be left alone")
+	}
+
+	if err := Reset(db, "council.market.votesRequired"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if got := Current(db).Council.PolicyFor(models.SubmissionTypeMarket); got != base {
+		t.Fatalf("expected the default policy back after a reset, got %+v", got)
+	}
+}
+
+func TestSet_RejectsUnknownAndOutOfRangeValues(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	t.Cleanup(Invalidate)
+
+	tests := []struct {
+		name  string
+		value float64
+		want  error
+	}{
+		{"council.market.unknown", 3, ErrUnknownParameter},
+		{"council.market.votesRequired", 2.5, ErrInvalidValue},
+		{"council.market.votesRequired", 0, ErrInvalidValue},
+		{"governance.approvalPct", 101, ErrInvalidValue},
+	}
+	for _, tt := range tests {
+		if _, err := Set(db, tt.name, tt.value, "test", nil); !errors.Is(err, tt.want) {
+			t.Errorf("Set(%s, %g): expected %v, got %v", tt.name, tt.value, tt.want, err)
+		}
+	}
+}
+
+func TestCurrent_CachesOverridesUntilInvalidated(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	t.Cleanup(Invalidate)
+	Invalidate()
+
+	before := Current(db).Governance.OrDefaults().VoteThreshold
+	// A change made by another instance
+	row := models.PlatformConfig{Name: "governance.voteThreshold", Kind: models.ParameterInt, Value: float64(before + 5)}
+	if err := db.Create(&row).Error; err != nil {
+		t.Fatalf("create override: %v", err)
+	}
+	if got := Current(db).Governance.OrDefaults().VoteThreshold; got != before {
+		t.Fatalf("expected the cached value %d until invalidated, got %d", before, got)
+	}
+	Invalidate()
+	if got := Current(db).Governance.OrDefaults().VoteThreshold; got != before+5 {
+		t.Fatalf("expected %d after invalidating, got %d", before+5, got)
+	}
+}
@@ -0,0 +1,270 @@
+// Package predictioncreation is the single pipeline through which agent
+// predictions are made, whether directly or after council approval. Every
+// prediction gets the same defaults, market checks and agent rescoring
+// regardless of the entry point.
+package predictioncreation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/outbox"
+	"socialpredict/services/consensus"
+	"socialpredict/services/contentsafety"
+	"socialpredict/services/platformconfig"
+	"socialpredict/services/scoring"
+	"socialpredict/services/series"
+
+	"gorm.io/gorm"
+)
+
+// DefaultConfidence is used when a prediction does not state one.
+const DefaultConfidence = 50
+
+var (
+	ErrMarketNotFound = errors.New("market not found")
+	ErrMarketResolved = errors.New("market is already resolved")
+	// ErrPredictionsLocked is returned once the market's prediction lock
+	// time has passed; see models.Market.PredictionsLockAt.
+	ErrPredictionsLocked = errors.New("predictions on this market are locked")
+	// ErrOutcomeRequired is returned for a prediction on a binary market
+	// without an outcome of YES or NO.
+	ErrOutcomeRequired = errors.New("predictions on this market take an outcome of YES or NO")
+	// ErrInvalidEstimate is returned for a prediction on a scalar market
+	// without an estimate and interval inside the market's range.
+	ErrInvalidEstimate = errors.New("predictions on this market take an estimate between low and high, inside the market's range")
+	// ErrIncoherent is returned for a prediction that would take the
+	// agent's chances of YES across a mutually exclusive series above 100%.
+	ErrIncoherent = series.ErrIncoherent
+	// ErrContentBlocked is returned, wrapped with the reason, for reasoning
+	// the content safety scan blocks; see services/contentsafety.
+	ErrContentBlocked = contentsafety.ErrBlocked
+)
+
+// Input describes a prediction an agent wants to make.
+type Input struct {
+	AgentID    int64
+	MarketID   int64
+	Outcome    string  // "YES" or "NO"; ignored on scalar markets
+	Confidence float64 // 0-100, 0 means DefaultConfidence
+	Reasoning  string
+
+	// Scalar markets take an estimate and the interval [Low, High] the agent
+	// is Confidence% sure the value lands in instead of an outcome.
+	Estimate *float64
+	Low      *float64
+	High     *float64
+
+	// SafetyChecked skips the content safety scan of Reasoning, for
+	// reasoning already scanned, e.g. when the prediction was submitted to
+	// the council.
+	SafetyChecked bool
+}
+
+// forMarket checks in against the kind of answer market asks for and
+// returns it ready to store: binary predictions without an estimate and
+// scalar predictions with Outcome models.PredictionValue.
+func (in Input) forMarket(market models.Market) (Input, error) {
+	if !market.IsScalar() {
+		if (in.Outcome != "YES" && in.Outcome != "NO") || in.Estimate != nil {
+			return in, ErrOutcomeRequired
+		}
+		in.Low, in.High = nil, nil
+		return in, nil
+	}
+	if in.Estimate == nil || in.Low == nil || in.High == nil {
+		return in, ErrInvalidEstimate
+	}
+	lower, upper := market.ScalarBounds()
+	if *in.Low < lower || *in.Low > *in.Estimate || *in.Estimate > *in.High || *in.High > upper {
+		return in, ErrInvalidEstimate
+	}
+	in.Outcome = models.PredictionValue
+	return in, nil
+}
+
+// PredictionCreatedEvent is the payload of outbox.TopicPredictionCreated.
+type PredictionCreatedEvent struct {
+	PredictionID int64   `json:"predictionId"`
+	MarketID     int64   `json:"marketId"`
+	AgentID      int64   `json:"agentId"`
+	Outcome      string  `json:"outcome"`
+	Confidence   float64 `json:"confidence"`
+	// Set on scalar markets
+	Estimate *float64 `json:"estimate,omitempty"`
+}
+
+// Make records the agent's prediction on the market, which must be open to
+// the agent (an invite-only market it is not invited to is not found) and
+// neither resolved nor past its prediction lock time. Binary markets take an
+// outcome and scalar markets an estimate and interval; on a mutually
+// exclusive series the outcome must be coherent with the agent's predictions
+// on the series' other markets. Reasoning the content safety scan blocks is
+// refused; reasoning it flags is recorded and put in the moderation queue.
+// An agent has one prediction per market: if
+// it already predicted, that prediction is updated in place, its previous
+// values kept as a PredictionRevision, and created is false; an update that
+// changes nothing is not recorded. New predictions are tagged with the
+// agent's team, if it is on one, count towards the market, rescore the
+// agent and publish prediction.created in the same transaction; an update
+// keeps the original team tag and rescores the agent, since its reasoning
+// is scored afresh. Either way the market's new consensus is added to its
+// history.
+func Make(ctx context.Context, db *gorm.DB, in Input) (prediction *models.Prediction, created bool, err error) {
+	confidence := in.Confidence
+	if confidence == 0 {
+		confidence = DefaultConfidence
+	}
+
+	db = db.WithContext(ctx)
+
+	var market models.Market
+	if err := db.First(&market, in.MarketID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrMarketNotFound
+		}
+		return nil, false, err
+	}
+	if open, err := market.OpenTo(db, in.AgentID); err != nil {
+		return nil, false, err
+	} else if !open {
+		return nil, false, ErrMarketNotFound
+	}
+	if market.IsResolved {
+		return nil, false, ErrMarketResolved
+	}
+	if market.PredictionsLocked(time.Now()) {
+		return nil, false, ErrPredictionsLocked
+	}
+	in, err = in.forMarket(market)
+	if err != nil {
+		return nil, false, err
+	}
+	if !market.IsScalar() {
+		implied := consensus.ImpliedYes(models.Prediction{Outcome: in.Outcome, Confidence: confidence})
+		if err := series.CheckCoherent(db, market, in.AgentID, implied); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var safety contentsafety.Result
+	if !in.SafetyChecked {
+		safety = contentsafety.Scan(ctx, platformconfig.Current(db).ContentSafety, contentsafety.Field{Name: "reasoning", Text: in.Reasoning})
+		if err := safety.Err(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var existing models.Prediction
+	if err := db.Where("agent_id = ? AND market_id = ?", in.AgentID, in.MarketID).First(&existing).Error; err == nil {
+		if existing.Outcome == in.Outcome && existing.Confidence == confidence && existing.Reasoning == in.Reasoning &&
+			sameValue(existing.Estimate, in.Estimate) && sameValue(existing.Low, in.Low) && sameValue(existing.High, in.High) {
+			return &existing, false, nil
+		}
+		existing.Outcome = in.Outcome
+		existing.Confidence = confidence
+		existing.Reasoning = in.Reasoning
+		existing.Estimate, existing.Low, existing.High = in.Estimate, in.Low, in.High
+		existing.Revision++
+		// Reviews were of the previous revision's reasoning.
+		existing.ScoreReasoning(nil)
+		now := time.Now()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&existing).Error; err != nil {
+				return err
+			}
+			revision := models.NewPredictionRevision(&existing, now)
+			if err := tx.Create(&revision).Error; err != nil {
+				return err
+			}
+			if safety.Flagged() {
+				if err := contentsafety.Flag(tx, models.ContentPrediction, existing.ID, safety, now); err != nil {
+					return err
+				}
+			}
+			if _, err := scoring.Recompute(ctx, tx, existing.AgentID, nil); err != nil {
+				return err
+			}
+			return consensus.Record(tx, existing.MarketID, existing.ID, now)
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		return &existing, false, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	teamID, err := models.ActiveTeamID(db, in.AgentID)
+	if err != nil {
+		return nil, false, err
+	}
+	prediction = &models.Prediction{
+		AgentID:     in.AgentID,
+		MarketID:    in.MarketID,
+		TeamID:      teamID,
+		Outcome:     in.Outcome,
+		Confidence:  confidence,
+		Reasoning:   in.Reasoning,
+		Estimate:    in.Estimate,
+		Low:         in.Low,
+		High:        in.High,
+		Revision:    1,
+		PredictedAt: time.Now(),
+	}
+	prediction.ScoreReasoning(nil)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(prediction).Error; err != nil {
+			return err
+		}
+		revision := models.NewPredictionRevision(prediction, prediction.PredictedAt)
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		if safety.Flagged() {
+			if err := contentsafety.Flag(tx, models.ContentPrediction, prediction.ID, safety, prediction.PredictedAt); err != nil {
+				return err
+			}
+		}
+
+		// Update agent stats and activity
+		agent, err := scoring.Recompute(ctx, tx, in.AgentID, (*models.Agent).UpdateActivity)
+		if err != nil {
+			return err
+		}
+
+		// Update market prediction count
+		market.TotalPredictions++
+		if err := tx.Save(&market).Error; err != nil {
+			return err
+		}
+		if err := consensus.Record(tx, market.ID, prediction.ID, prediction.PredictedAt); err != nil {
+			return err
+		}
+
+		prediction.Agent = agent
+		prediction.Market = &market
+		return outbox.Enqueue(tx, outbox.TopicPredictionCreated, outbox.AggregatePrediction, prediction.ID, PredictionCreatedEvent{
+			PredictionID: prediction.ID,
+			MarketID:     prediction.MarketID,
+			AgentID:      prediction.AgentID,
+			Outcome:      prediction.Outcome,
+			Confidence:   prediction.Confidence,
+			Estimate:     prediction.Estimate,
+		})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return prediction, true, nil
+}
+
+// sameValue reports whether two optional numbers are equal.
+func sameValue(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
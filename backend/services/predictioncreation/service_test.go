@@ -0,0 +1,108 @@
+package predictioncreation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/outbox"
+)
+
+func TestMake_CreatesThenUpdates(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	agent := models.Agent{Name: "oracle", APIKey: "swarm_sk_oracle", ClaimToken: "claim_oracle", IsActive: true}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	prediction, created, err := Make(ctx, db, Input{AgentID: agent.ID, MarketID: market.ID, Outcome: "YES", Reasoning: "first look"})
+	if err != nil {
+		t.Fatalf("Make: %v", err)
+	}
+	if !created {
+		t.Fatalf("expected the first prediction to be created")
+	}
+	if prediction.Confidence != DefaultConfidence {
+		t.Fatalf("expected default confidence %d, got %v", DefaultConfidence, prediction.Confidence)
+	}
+
+	updated, created, err := Make(ctx, db, Input{AgentID: agent.ID, MarketID: market.ID, Outcome: "NO", Confidence: 80})
+	if err != nil {
+		t.Fatalf("Make update: %v", err)
+	}
+	if created || updated.ID != prediction.ID {
+		t.Fatalf("expected prediction %d to be updated, got created=%v id=%d", prediction.ID, created, updated.ID)
+	}
+	if updated.Outcome != "NO" || updated.Confidence != 80 {
+		t.Fatalf("expected NO at 80, got %s at %v", updated.Outcome, updated.Confidence)
+	}
+
+	// Repeating the same prediction is not a revision.
+	if _, _, err := Make(ctx, db, Input{AgentID: agent.ID, MarketID: market.ID, Outcome: "NO", Confidence: 80}); err != nil {
+		t.Fatalf("Make repeat: %v", err)
+	}
+	revisions, err := models.PredictionRevisions(db, prediction.ID)
+	if err != nil {
+		t.Fatalf("PredictionRevisions: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Outcome != "YES" || revisions[0].Reasoning != "first look" || revisions[1].Revision != 2 || revisions[1].Outcome != "NO" {
+		t.Fatalf("expected the original and the update as revisions 1 and 2, got %+v", revisions)
+	}
+	if updated.Revision != 2 {
+		t.Fatalf("expected the prediction at revision 2, got %d", updated.Revision)
+	}
+
+	var reloaded models.Market
+	db.First(&reloaded, market.ID)
+	if reloaded.TotalPredictions != 1 {
+		t.Fatalf("expected one counted prediction, got %d", reloaded.TotalPredictions)
+	}
+
+	var events []models.OutboxEvent
+	db.Where("topic = ?", outbox.TopicPredictionCreated).Find(&events)
+	if len(events) != 1 || events[0].AggregateID != prediction.ID {
+		t.Fatalf("expected one prediction created event for the new prediction, got %+v", events)
+	}
+}
+
+func TestMake_RefusesMissingResolvedAndLockedMarkets(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+
+	if _, _, err := Make(ctx, db, Input{AgentID: 1, MarketID: 99, Outcome: "YES"}); !errors.Is(err, ErrMarketNotFound) {
+		t.Fatalf("expected ErrMarketNotFound, got %v", err)
+	}
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	market.IsResolved = true
+	market.ResolutionResult = "YES"
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	if _, _, err := Make(ctx, db, Input{AgentID: 1, MarketID: market.ID, Outcome: "YES"}); !errors.Is(err, ErrMarketResolved) {
+		t.Fatalf("expected ErrMarketResolved, got %v", err)
+	}
+
+	locked := modelstesting.GenerateMarket(2, user.Username)
+	locked.ResolutionDateTime = time.Now().Add(2 * time.Hour)
+	locked.PredictionLockHours = 3
+	if err := db.Create(&locked).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+	if _, _, err := Make(ctx, db, Input{AgentID: 1, MarketID: locked.ID, Outcome: "YES"}); !errors.Is(err, ErrPredictionsLocked) {
+		t.Fatalf("expected ErrPredictionsLocked, got %v", err)
+	}
+}
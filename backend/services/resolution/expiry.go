@@ -0,0 +1,68 @@
+package resolution
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+// ExpireDue resolves N/A every unresolved market whose market type expires
+// markets (see setup.MarketTypePolicy.ExpireAfterHours) and that is that
+// long past its resolution time, unless the council is voting on its
+// resolution. Daily and realtime questions are about a moment; a late
+// answer is worth less than refunding the bets. It returns how many
+// markets were resolved.
+func ExpireDue(ctx context.Context, db *gorm.DB, econ setup.EconConfigLoader, now time.Time) (int, error) {
+	cfg := econ()
+	if cfg == nil {
+		return 0, nil
+	}
+	types := make([]string, 0, len(setup.DefaultMarketTypePolicies)+len(cfg.MarketTypes.Policies))
+	for marketType := range setup.DefaultMarketTypePolicies {
+		types = append(types, marketType)
+	}
+	for marketType := range cfg.MarketTypes.Policies {
+		if _, ok := setup.DefaultMarketTypePolicies[marketType]; !ok {
+			types = append(types, marketType)
+		}
+	}
+	sort.Strings(types)
+
+	expired := 0
+	for _, marketType := range types {
+		hours := cfg.MarketTypes.PolicyFor(marketType).ExpireAfterHours
+		if hours <= 0 {
+			continue
+		}
+		cutoff := now.Add(-time.Duration(hours * float64(time.Hour)))
+
+		var ids []int64
+		if err := db.WithContext(ctx).Model(&models.Market{}).
+			Where("market_type = ? AND is_resolved = ? AND resolution_date_time <= ?", marketType, false, cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM resolution_requests WHERE resolution_requests.market_id = markets.id AND resolution_requests.status = ?)", models.ResolutionRequestVoting).
+			Order("resolution_date_time").
+			Pluck("id", &ids).Error; err != nil {
+			return expired, err
+		}
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return expired, err
+			}
+			_, err := Resolve(ctx, db, id, OutcomeNA, nil)
+			switch {
+			case errors.Is(err, ErrAlreadyResolved), errors.Is(err, ErrMarketNotFound):
+			case err != nil:
+				return expired, err
+			default:
+				expired++
+			}
+		}
+	}
+	return expired, nil
+}
@@ -0,0 +1,79 @@
+package resolution
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func TestExpireDue_ResolvesStaleDailyAndRealtimeMarketsNA(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		_, econ := modelstesting.UseStandardTestEconomics(t)
+		user := modelstesting.GenerateUser("creator", 0)
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		now := time.Now()
+		seed := func(marketType string, pastResolution time.Duration) *models.Market {
+			market := modelstesting.GenerateMarket(0, user.Username)
+			market.MarketType = marketType
+			market.ResolutionDateTime = now.Add(-pastResolution)
+			if err := db.Create(&market).Error; err != nil {
+				t.Fatalf("create market: %v", err)
+			}
+			return &market
+		}
+
+		staleDaily := seed(models.MarketTypeDaily, 25*time.Hour)
+		recentDaily := seed(models.MarketTypeDaily, time.Hour)
+		staleRealtime := seed(models.MarketTypeRealtime, 3*time.Hour)
+		beforeCouncil := seed(models.MarketTypeRealtime, 3*time.Hour)
+		standard := seed(models.MarketTypeStandard, 30*24*time.Hour)
+		if err := db.Create(&models.ResolutionRequest{
+			MarketID: beforeCouncil.ID, Status: models.ResolutionRequestVoting, Round: 1,
+			VotesRequired: 3, MinVoters: 1, ApprovalThreshold: 0.66, VotingEndsAt: now.Add(time.Hour),
+		}).Error; err != nil {
+			t.Fatalf("create resolution request: %v", err)
+		}
+
+		expired, err := ExpireDue(context.Background(), db, econ, now)
+		if err != nil {
+			t.Fatalf("ExpireDue: %v", err)
+		}
+		if expired != 2 {
+			t.Fatalf("expected 2 markets expired, got %d", expired)
+		}
+
+		for _, c := range []struct {
+			market   *models.Market
+			resolved bool
+		}{
+			{staleDaily, true},
+			{recentDaily, false},
+			{staleRealtime, true},
+			{beforeCouncil, false},
+			{standard, false},
+		} {
+			var stored models.Market
+			if err := db.First(&stored, c.market.ID).Error; err != nil {
+				t.Fatalf("load market: %v", err)
+			}
+			if stored.IsResolved != c.resolved {
+				t.Errorf("%s market %d past resolution by %v: resolved %v, want %v",
+					stored.MarketType, stored.ID, now.Sub(c.market.ResolutionDateTime).Round(time.Hour), stored.IsResolved, c.resolved)
+			}
+			if c.resolved && stored.ResolutionResult != OutcomeNA {
+				t.Errorf("expected market %d resolved N/A, got %q", stored.ID, stored.ResolutionResult)
+			}
+		}
+
+		if again, err := ExpireDue(context.Background(), db, econ, now); err != nil || again != 0 {
+			t.Fatalf("expected a second run to expire nothing, got %d (%v)", again, err)
+		}
+	})
+}
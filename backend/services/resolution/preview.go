@@ -0,0 +1,151 @@
+package resolution
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/services/scoring"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+// AgentImpact is how resolving a market would move one predictor's scores.
+type AgentImpact struct {
+	AgentID            int64   `json:"agentId"`
+	Name               string  `json:"name"`
+	CorrectPredictions int     `json:"correctPredictions"`
+	WrongPredictions   int     `json:"wrongPredictions"`
+	AccuracyBefore     float64 `json:"accuracyBefore"`
+	AccuracyAfter      float64 `json:"accuracyAfter"`
+	AccuracyDelta      float64 `json:"accuracyDelta"`
+	CompositeBefore    float64 `json:"compositeBefore"`
+	CompositeAfter     float64 `json:"compositeAfter"`
+	CompositeDelta     float64 `json:"compositeDelta"`
+}
+
+// Preview is what resolving a market with Outcome would do to the agent
+// predictions on it, without doing it.
+type Preview struct {
+	MarketID int64  `json:"marketId"`
+	Outcome  string `json:"outcome"`
+	// Predictions that would be scored, and how they would come out. For
+	// YES or NO, choosing the other outcome swaps Correct and Wrong.
+	Predictions        int `json:"predictions"`
+	CorrectPredictions int `json:"correctPredictions"`
+	WrongPredictions   int `json:"wrongPredictions"`
+	// Summed over every affected agent.
+	TotalAccuracyDelta  float64       `json:"totalAccuracyDelta"`
+	TotalCompositeDelta float64       `json:"totalCompositeDelta"`
+	Agents              []AgentImpact `json:"agents"`
+}
+
+// PreviewResolve reports what Resolve would do to the market's agent
+// predictions and their authors' scores if called with outcome. It only
+// reads: nothing is written and no notifications are sent. Agents are
+// listed by the size of the change to their composite score, largest
+// first. N/A leaves predictions unscored, so its preview is empty. Scalar
+// markets can only be previewed N/A.
+func PreviewResolve(ctx context.Context, db *gorm.DB, marketID int64, outcome string, authorize Authorizer) (*Preview, error) {
+	if outcome != OutcomeYes && outcome != OutcomeNo && outcome != OutcomeNA {
+		return nil, ErrInvalidOutcome
+	}
+
+	db = db.WithContext(ctx)
+	var market models.Market
+	if err := db.First(&market, marketID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMarketNotFound
+		}
+		return nil, err
+	}
+	if authorize != nil {
+		if err := authorize(db, &market); err != nil {
+			return nil, err
+		}
+	}
+	if market.IsResolved {
+		return nil, ErrAlreadyResolved
+	}
+	if err := checkResolution(db, &market, outcome, nil); err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{MarketID: market.ID, Outcome: outcome, Agents: []AgentImpact{}}
+	if outcome == OutcomeNA {
+		return preview, nil
+	}
+
+	var predictions []models.Prediction
+	if err := db.Where("market_id = ? AND is_resolved = ?", market.ID, false).Find(&predictions).Error; err != nil {
+		return nil, err
+	}
+
+	// Predictions count as they stood when the market closed, as when it
+	// is resolved.
+	closedAt := closesAt(&market, time.Now())
+	rules := setup.EconomicsConfig().Predictions.OrDefaults()
+
+	impacts := make(map[int64]*AgentImpact)
+	var agentIDs []int64
+	for i := range predictions {
+		prediction := &predictions[i]
+		impact, ok := impacts[prediction.AgentID]
+		if !ok {
+			impact = &AgentImpact{AgentID: prediction.AgentID}
+			impacts[prediction.AgentID] = impact
+			agentIDs = append(agentIDs, prediction.AgentID)
+		}
+		predicted := prediction.Outcome
+		revision, _, err := scoringRevision(db, prediction, closedAt, rules)
+		if err != nil {
+			return nil, err
+		}
+		if revision != nil {
+			predicted = revision.Outcome
+		}
+		if predicted == outcome {
+			impact.CorrectPredictions++
+			preview.CorrectPredictions++
+		} else {
+			impact.WrongPredictions++
+			preview.WrongPredictions++
+		}
+	}
+	preview.Predictions = len(predictions)
+
+	for _, agentID := range agentIDs {
+		impact := impacts[agentID]
+		var current models.Agent
+		if err := db.Select("id", "name", "accuracy_score", "composite_score").First(&current, agentID).Error; err != nil {
+			return nil, err
+		}
+		projected, err := scoring.Project(ctx, db, agentID, func(agent *models.Agent) {
+			agent.ResolvedPredictions += int64(impact.CorrectPredictions + impact.WrongPredictions)
+			agent.CorrectPredictions += int64(impact.CorrectPredictions)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		impact.Name = current.Name
+		impact.AccuracyBefore = current.AccuracyScore
+		impact.AccuracyAfter = projected.AccuracyScore
+		impact.AccuracyDelta = impact.AccuracyAfter - impact.AccuracyBefore
+		impact.CompositeBefore = current.CompositeScore
+		impact.CompositeAfter = projected.CompositeScore
+		impact.CompositeDelta = impact.CompositeAfter - impact.CompositeBefore
+		preview.TotalAccuracyDelta += impact.AccuracyDelta
+		preview.TotalCompositeDelta += impact.CompositeDelta
+		preview.Agents = append(preview.Agents, *impact)
+	}
+
+	sort.SliceStable(preview.Agents, func(i, j int) bool {
+		return math.Abs(preview.Agents[i].CompositeDelta) > math.Abs(preview.Agents[j].CompositeDelta)
+	})
+	return preview, nil
+}
@@ -0,0 +1,419 @@
+// Package resolution is the single pipeline through which markets are
+// resolved. Resolving a market, paying out its bets, scoring the agent
+// predictions made on it, rescoring every affected agent and notifying the
+// predictors all happen in one transaction, so a market is never left
+// resolved with unscored predictions.
+package resolution
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"socialpredict/handlers/math/payout"
+	"socialpredict/models"
+	"socialpredict/notifications"
+	"socialpredict/outbox"
+	"socialpredict/repository"
+	"socialpredict/services/scoring"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+// Resolution outcomes.
+const (
+	OutcomeYes = "YES"
+	OutcomeNo  = "NO"
+	OutcomeNA  = "N/A"
+)
+
+var (
+	ErrMarketNotFound  = errors.New("market not found")
+	ErrAlreadyResolved = errors.New("market is already resolved")
+	ErrInvalidOutcome  = errors.New("outcome must be YES, NO or N/A")
+	ErrNotAuthorized   = errors.New("not allowed to resolve this market")
+	ErrNotResolved     = errors.New("market is not resolved")
+	// ErrScalarMarket is returned for a YES or NO resolution of a scalar
+	// market, which resolves to a value or N/A.
+	ErrScalarMarket = errors.New("scalar markets resolve to a value or N/A")
+	// ErrNotScalar is returned for a value resolution of a binary market.
+	ErrNotScalar = errors.New("only scalar markets resolve to a value")
+	// ErrSeriesSettled is returned for a YES resolution of a market in a
+	// mutually exclusive series another of whose markets resolved YES.
+	ErrSeriesSettled = errors.New("another market in this mutually exclusive series already resolved YES")
+	// ErrBeforeResolutionDate is returned when a creator agent resolves its
+	// market before the market's resolution date.
+	ErrBeforeResolutionDate = errors.New("market cannot be resolved before its resolution date")
+	// ErrCreatorPredicted is returned when a creator agent that predicted
+	// on its market tries to resolve it; the council resolves it instead.
+	ErrCreatorPredicted = errors.New("agents cannot resolve markets they predicted on")
+)
+
+// Authorizer decides whether the caller may resolve market. It runs inside
+// the resolution transaction tx on the freshly loaded market and should
+// return ErrNotAuthorized, or a more specific error, to refuse.
+type Authorizer func(tx *gorm.DB, market *models.Market) error
+
+// CreatedBy lets only actor, the market's creator, resolve the market. An
+// agent creator must also wait for the resolution date and must not have
+// predicted on the market, so no agent settles its own prediction; the
+// council resolves those markets once their date passes. Human creators
+// may resolve their markets at any time, as before agents.
+func CreatedBy(actor models.Actor) Authorizer {
+	return func(tx *gorm.DB, market *models.Market) error {
+		if market.CreatedBy() != actor {
+			return ErrNotAuthorized
+		}
+		if !actor.IsAgent() {
+			return nil
+		}
+		if time.Now().Before(market.ResolutionDateTime) {
+			return ErrBeforeResolutionDate
+		}
+		var predicted int64
+		if err := tx.Model(&models.Prediction{}).Where("market_id = ? AND agent_id = ?", market.ID, actor.ID).Count(&predicted).Error; err != nil {
+			return err
+		}
+		if predicted > 0 {
+			return ErrCreatorPredicted
+		}
+		return nil
+	}
+}
+
+// Result summarises a resolution.
+type Result struct {
+	Market             *models.Market `json:"market"`
+	PredictionsScored  int            `json:"predictionsScored"`
+	CorrectPredictions int            `json:"correctPredictions"`
+	AgentsRescored     []int64        `json:"agentsRescored"`
+}
+
+// MarketResolvedEvent is the payload of outbox.TopicMarketResolved.
+type MarketResolvedEvent struct {
+	MarketID      int64  `json:"marketId"`
+	QuestionTitle string `json:"questionTitle"`
+	Outcome       string `json:"outcome"`
+
+	// Set when a resolved market's outcome is overturned
+	PreviousOutcome string `json:"previousOutcome,omitempty"`
+}
+
+// Recorder stores a record of why market was resolved. It runs inside the
+// resolution transaction, after the market is saved, so the record is
+// written if and only if the resolution is.
+type Recorder func(tx *gorm.DB, market *models.Market) error
+
+// Resolve resolves the market with outcome after authorize accepts it. A
+// concurrent write to the market retries the whole resolution; if that
+// write resolved it, Resolve returns ErrAlreadyResolved.
+func Resolve(ctx context.Context, db *gorm.DB, marketID int64, outcome string, authorize Authorizer) (*Result, error) {
+	return ResolveRecorded(ctx, db, marketID, outcome, authorize, nil)
+}
+
+// ResolveRecorded is Resolve with record, if not nil, run in the same
+// transaction.
+func ResolveRecorded(ctx context.Context, db *gorm.DB, marketID int64, outcome string, authorize Authorizer, record Recorder) (*Result, error) {
+	if outcome != OutcomeYes && outcome != OutcomeNo && outcome != OutcomeNA {
+		return nil, ErrInvalidOutcome
+	}
+	return resolve(ctx, db, marketID, outcome, nil, authorize, record)
+}
+
+// ResolveValue resolves a scalar market to value after authorize accepts
+// it, as Resolve does. Its predictions are scored by CRPS against value,
+// which is recorded as the market's ResolutionValue and, formatted, as its
+// ResolutionResult.
+func ResolveValue(ctx context.Context, db *gorm.DB, marketID int64, value float64, authorize Authorizer) (*Result, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, ErrInvalidOutcome
+	}
+	return resolve(ctx, db, marketID, models.FormatScalarValue(value), &value, authorize, nil)
+}
+
+// checkResolution refuses an outcome the market cannot take: scalar
+// markets resolve to a value or N/A, binary ones never to a value, and only
+// one market in a mutually exclusive series resolves YES.
+func checkResolution(db *gorm.DB, market *models.Market, outcome string, value *float64) error {
+	if market.IsScalar() && value == nil && outcome != OutcomeNA {
+		return ErrScalarMarket
+	}
+	if !market.IsScalar() && value != nil {
+		return ErrNotScalar
+	}
+	if outcome == OutcomeYes {
+		if settled, err := market.ExclusiveSibling(db); err != nil {
+			return err
+		} else if settled {
+			return ErrSeriesSettled
+		}
+	}
+	return nil
+}
+
+// resolve resolves the market with outcome, and for scalar markets value.
+func resolve(ctx context.Context, db *gorm.DB, marketID int64, outcome string, value *float64, authorize Authorizer, record Recorder) (*Result, error) {
+	var result *Result
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var market models.Market
+			if err := tx.First(&market, marketID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrMarketNotFound
+				}
+				return err
+			}
+			if authorize != nil {
+				if err := authorize(tx, &market); err != nil {
+					return err
+				}
+			}
+			if market.IsResolved {
+				return ErrAlreadyResolved
+			}
+			if err := checkResolution(tx, &market, outcome, value); err != nil {
+				return err
+			}
+
+			market.IsResolved = true
+			market.ResolutionResult = outcome
+			market.ResolutionValue = value
+			market.FinalResolutionDateTime = time.Now()
+			if err := tx.Save(&market).Error; err != nil {
+				return err
+			}
+			if record != nil {
+				if err := record(tx, &market); err != nil {
+					return err
+				}
+			}
+			if err := outbox.Enqueue(tx, outbox.TopicMarketResolved, outbox.AggregateMarket, market.ID, MarketResolvedEvent{
+				MarketID:      market.ID,
+				QuestionTitle: market.QuestionTitle,
+				Outcome:       outcome,
+			}); err != nil {
+				return err
+			}
+
+			if err := payout.DistributePayoutsWithRefund(&market, tx); err != nil {
+				return err
+			}
+
+			var err error
+			result, err = scorePredictions(ctx, tx, &market)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Reresolve changes the outcome of a resolved market to outcome, as when the
+// council upholds a dispute; a scalar market can only be reresolved N/A.
+// The scores of the market's predictions are taken back and applied again
+// for the new outcome and every affected agent is rescored, all in one
+// transaction with record (if not nil), so no agent is ever scored on both
+// outcomes. Bets are not paid out again: payouts made on the original
+// outcome stand.
+func Reresolve(ctx context.Context, db *gorm.DB, marketID int64, outcome string, record Recorder) (*Result, error) {
+	if outcome != OutcomeYes && outcome != OutcomeNo && outcome != OutcomeNA {
+		return nil, ErrInvalidOutcome
+	}
+
+	var result *Result
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var market models.Market
+			if err := tx.First(&market, marketID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrMarketNotFound
+				}
+				return err
+			}
+			if !market.IsResolved {
+				return ErrNotResolved
+			}
+			if err := checkResolution(tx, &market, outcome, nil); err != nil {
+				return err
+			}
+			previous := market.ResolutionResult
+
+			var scored []models.Prediction
+			if err := tx.Where("market_id = ? AND is_resolved = ?", market.ID, true).Find(&scored).Error; err != nil {
+				return err
+			}
+			var agentIDs []int64
+			seen := make(map[int64]bool, len(scored))
+			for i := range scored {
+				scored[i].Unscore()
+				if err := tx.Save(&scored[i]).Error; err != nil {
+					return err
+				}
+				if !seen[scored[i].AgentID] {
+					seen[scored[i].AgentID] = true
+					agentIDs = append(agentIDs, scored[i].AgentID)
+				}
+			}
+
+			market.ResolutionResult = outcome
+			market.ResolutionValue = nil
+			if err := tx.Save(&market).Error; err != nil {
+				return err
+			}
+			if record != nil {
+				if err := record(tx, &market); err != nil {
+					return err
+				}
+			}
+			if err := outbox.Enqueue(tx, outbox.TopicMarketResolved, outbox.AggregateMarket, market.ID, MarketResolvedEvent{
+				MarketID:        market.ID,
+				QuestionTitle:   market.QuestionTitle,
+				Outcome:         outcome,
+				PreviousOutcome: previous,
+			}); err != nil {
+				return err
+			}
+
+			var err error
+			result, err = scorePredictions(ctx, tx, &market)
+			if err != nil {
+				return err
+			}
+			// An N/A outcome scores nothing, so the agents whose scores were
+			// taken back are rescored without them.
+			if len(result.AgentsRescored) == 0 && len(agentIDs) > 0 {
+				if _, err := scoring.RecomputeAgents(ctx, tx, agentIDs...); err != nil {
+					return err
+				}
+				result.AgentsRescored = agentIDs
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// closesAt is when predictions on market stop counting: its prediction
+// lock time, or now if it is resolved before then.
+func closesAt(market *models.Market, now time.Time) time.Time {
+	if market.ResolutionDateTime.IsZero() || !market.PredictionsLocked(now) {
+		return now
+	}
+	return market.PredictionsLockAt()
+}
+
+// scoringRevision returns the revision prediction is scored on when its
+// market closes at closedAt and whether it is a late flip under rules. It is
+// nil for a prediction with no revision before the close, which is scored
+// on its latest values.
+func scoringRevision(db *gorm.DB, prediction *models.Prediction, closedAt time.Time, rules setup.Predictions) (*models.PredictionRevision, bool, error) {
+	final, previous, err := models.ScoringRevision(db, prediction.ID, closedAt)
+	if err != nil || final == nil {
+		return nil, false, err
+	}
+	window := time.Duration(rules.LateFlipHours * float64(time.Hour))
+	return final, models.IsLateFlip(final, previous, closedAt, window), nil
+}
+
+// scorePredictions marks the market's unresolved agent predictions resolved,
+// recomputes each predictor's scores and sends them a prediction.resolved
+// notification with what they earned. Each prediction is scored on its last
+// revision before the market closed, so changing it afterwards does not
+// count, and a late flip of the outcome is penalised when the predictions
+// rules ask for it. Predictions on a scalar market are scored by CRPS
+// against its value instead. N/A markets have no correct side, so their
+// predictions are left unscored. The market must already be resolved.
+func scorePredictions(ctx context.Context, db *gorm.DB, market *models.Market) (*Result, error) {
+	result := &Result{Market: market, AgentsRescored: []int64{}}
+	scalar := market.IsScalar() && market.ResolutionValue != nil
+	if !scalar && market.ResolutionResult != OutcomeYes && market.ResolutionResult != OutcomeNo {
+		return result, nil
+	}
+	rules := setup.EconomicsConfig().Predictions.OrDefaults()
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var predictions []models.Prediction
+		if err := tx.Where("market_id = ? AND is_resolved = ?", market.ID, false).Find(&predictions).Error; err != nil {
+			return err
+		}
+		if len(predictions) == 0 {
+			return nil
+		}
+
+		var agentIDs []int64
+		before := make(map[int64]notifications.Standing)
+		for _, prediction := range predictions {
+			if _, seen := before[prediction.AgentID]; seen {
+				continue
+			}
+			standing, err := notifications.StandingOf(tx, prediction.AgentID)
+			if err != nil {
+				return err
+			}
+			before[prediction.AgentID] = standing
+			agentIDs = append(agentIDs, prediction.AgentID)
+		}
+
+		now := time.Now()
+		closedAt := closesAt(market, now)
+		correct := 0
+		revisions := make(map[int64]*models.PredictionRevision, len(predictions))
+		for i := range predictions {
+			prediction := &predictions[i]
+			revision, lateFlip, err := scoringRevision(tx, prediction, closedAt, rules)
+			if err != nil {
+				return err
+			}
+			revisions[prediction.ID] = revision
+			if scalar {
+				prediction.ScoreScalar(revision, *market, *market.ResolutionValue)
+			} else {
+				prediction.ScoreRevision(revision, market.ResolutionResult, lateFlip, rules.LateFlipPenalty)
+			}
+			prediction.ResolvedAt = &now
+			if err := tx.Save(prediction).Error; err != nil {
+				return err
+			}
+			if prediction.WasCorrect {
+				correct++
+			}
+		}
+
+		if _, err := scoring.RecomputeAgents(ctx, tx, agentIDs...); err != nil {
+			return err
+		}
+
+		// Ranks are read only after every predictor has been rescored.
+		after := make(map[int64]notifications.Standing, len(agentIDs))
+		for _, agentID := range agentIDs {
+			standing, err := notifications.StandingOf(tx, agentID)
+			if err != nil {
+				return err
+			}
+			after[agentID] = standing
+		}
+
+		for _, prediction := range predictions {
+			n := notifications.NewPredictionResolved(prediction, revisions[prediction.ID], *market, before[prediction.AgentID], after[prediction.AgentID])
+			if err := notifications.SendPredictionResolved(tx, prediction.AgentID, n); err != nil {
+				return err
+			}
+		}
+
+		result.PredictionsScored = len(predictions)
+		result.CorrectPredictions = correct
+		result.AgentsRescored = agentIDs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
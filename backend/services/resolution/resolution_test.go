@@ -0,0 +1,316 @@
+package resolution
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+	"socialpredict/notifications"
+	"socialpredict/outbox"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+func TestResolve_ScoresPredictionsAndRescoresAgents(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		user := modelstesting.GenerateUser("creator", 0)
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		market := modelstesting.GenerateMarket(0, user.Username)
+		if err := db.Create(&market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+
+		right := modelstesting.GenerateAgent("right")
+		db.Create(&right)
+		wrong := modelstesting.GenerateAgent("wrong")
+		db.Create(&wrong)
+		predictions := []models.Prediction{
+			{AgentID: right.ID, MarketID: market.ID, Outcome: "YES", Confidence: 80, PredictedAt: time.Now()},
+			{AgentID: wrong.ID, MarketID: market.ID, Outcome: "NO", Confidence: 60, PredictedAt: time.Now()},
+		}
+		if err := db.Create(&predictions).Error; err != nil {
+			t.Fatalf("create predictions: %v", err)
+		}
+
+		result, err := Resolve(context.Background(), db, market.ID, OutcomeYes, nil)
+		if err != nil {
+			t.Fatalf("Resolve: %v", err)
+		}
+		if result.PredictionsScored != 2 || result.CorrectPredictions != 1 || len(result.AgentsRescored) != 2 {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+
+		var stored models.Market
+		db.First(&stored, market.ID)
+		if !stored.IsResolved || stored.ResolutionResult != OutcomeYes {
+			t.Fatalf("expected market resolved YES, got %+v", stored)
+		}
+
+		var scored []models.Prediction
+		db.Where("market_id = ?", market.ID).Order("agent_id").Find(&scored)
+		for _, p := range scored {
+			if !p.IsResolved || p.ResolvedAt == nil || p.WasCorrect != (p.AgentID == right.ID) {
+				t.Fatalf("unexpected scored prediction: %+v", p)
+			}
+		}
+
+		var rightAgent, wrongAgent models.Agent
+		db.First(&rightAgent, right.ID)
+		db.First(&wrongAgent, wrong.ID)
+		if rightAgent.ResolvedPredictions != 1 || rightAgent.CorrectPredictions != 1 || rightAgent.AccuracyScore <= wrongAgent.AccuracyScore {
+			t.Fatalf("expected right agent rescored above wrong agent: %+v / %+v", rightAgent, wrongAgent)
+		}
+		if wrongAgent.ResolvedPredictions != 1 || wrongAgent.CorrectPredictions != 0 {
+			t.Fatalf("unexpected wrong agent counters: %+v", wrongAgent)
+		}
+
+		var sent int64
+		db.Model(&models.Notification{}).Where("kind = ?", notifications.KindPredictionResolved).Count(&sent)
+		if sent != 2 {
+			t.Fatalf("expected 2 resolution notifications, got %d", sent)
+		}
+
+		var resolvedEvents int64
+		db.Model(&models.OutboxEvent{}).Where("topic = ? AND aggregate_id = ?", outbox.TopicMarketResolved, market.ID).Count(&resolvedEvents)
+		if resolvedEvents != 1 {
+			t.Fatalf("expected one market resolved event, got %d", resolvedEvents)
+		}
+
+		if _, err := Resolve(context.Background(), db, market.ID, OutcomeNo, nil); !errors.Is(err, ErrAlreadyResolved) {
+			t.Fatalf("expected ErrAlreadyResolved, got %v", err)
+		}
+	})
+}
+
+func TestResolve_RefusedLeavesMarketUntouched(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	db.Create(&market)
+
+	refuse := func(*gorm.DB, *models.Market) error { return ErrNotAuthorized }
+	if _, err := Resolve(context.Background(), db, market.ID, OutcomeYes, refuse); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("expected ErrNotAuthorized, got %v", err)
+	}
+	if _, err := Resolve(context.Background(), db, market.ID, "MAYBE", nil); !errors.Is(err, ErrInvalidOutcome) {
+		t.Fatalf("expected ErrInvalidOutcome, got %v", err)
+	}
+	if _, err := Resolve(context.Background(), db, 999, OutcomeYes, nil); !errors.Is(err, ErrMarketNotFound) {
+		t.Fatalf("expected ErrMarketNotFound, got %v", err)
+	}
+
+	var stored models.Market
+	db.First(&stored, market.ID)
+	if stored.IsResolved {
+		t.Fatal("market should not be resolved")
+	}
+}
+
+func TestCreatedBy_CreatorAgentWaitsAndMustNotHavePredicted(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	creator := modelstesting.GenerateAgent("creator-agent")
+	other := modelstesting.GenerateAgent("other-agent")
+	db.Create(&creator)
+	db.Create(&other)
+
+	newMarket := func(id int64, resolvesAt time.Time) models.Market {
+		t.Helper()
+		market := modelstesting.GenerateMarket(id, user.Username)
+		market.SetCreatedBy(models.AgentActor(creator.ID))
+		market.ResolutionDateTime = resolvesAt
+		if err := db.Create(&market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+		return market
+	}
+	authorize := CreatedBy(models.AgentActor(creator.ID))
+
+	early := newMarket(1, time.Now().Add(time.Hour))
+	if _, err := Resolve(context.Background(), db, early.ID, OutcomeYes, authorize); !errors.Is(err, ErrBeforeResolutionDate) {
+		t.Fatalf("expected ErrBeforeResolutionDate, got %v", err)
+	}
+
+	predicted := newMarket(2, time.Now().Add(-time.Hour))
+	db.Create(&models.Prediction{AgentID: creator.ID, MarketID: predicted.ID, Outcome: "YES", Confidence: 70, PredictedAt: time.Now().Add(-2 * time.Hour)})
+	if _, err := Resolve(context.Background(), db, predicted.ID, OutcomeYes, authorize); !errors.Is(err, ErrCreatorPredicted) {
+		t.Fatalf("expected ErrCreatorPredicted, got %v", err)
+	}
+	if _, err := Resolve(context.Background(), db, predicted.ID, OutcomeYes, CreatedBy(models.AgentActor(other.ID))); !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("expected ErrNotAuthorized for another agent, got %v", err)
+	}
+
+	due := newMarket(3, time.Now().Add(-time.Hour))
+	if _, err := Resolve(context.Background(), db, due.ID, OutcomeYes, authorize); err != nil {
+		t.Fatalf("expected the creator to resolve a due market it did not predict on: %v", err)
+	}
+}
+
+func TestResolve_ScoresLastRevisionBeforeCloseAndPenalisesLateFlips(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	rules := &setup.EconomicsConfig().Predictions
+	saved := *rules
+	rules.LateFlipHours, rules.LateFlipPenalty = 24, 0.25
+	defer func() { *rules = saved }()
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	closed := time.Now().Add(-2 * time.Hour)
+	market.ResolutionDateTime = closed
+	if err := db.Create(&market).Error; err != nil {
+		t.Fatalf("create market: %v", err)
+	}
+
+	agent := modelstesting.GenerateAgent("flipper")
+	db.Create(&agent)
+	prediction := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: "YES", Confidence: 70, Revision: 3, PredictedAt: closed.Add(-72 * time.Hour)}
+	if err := db.Create(&prediction).Error; err != nil {
+		t.Fatalf("create prediction: %v", err)
+	}
+	revisions := []models.PredictionRevision{
+		{PredictionID: prediction.ID, Revision: 1, Outcome: "YES", Confidence: 70, RevisedAt: closed.Add(-72 * time.Hour)},
+		{PredictionID: prediction.ID, Revision: 2, Outcome: "NO", Confidence: 70, RevisedAt: closed.Add(-time.Hour)},
+		{PredictionID: prediction.ID, Revision: 3, Outcome: "YES", Confidence: 70, RevisedAt: closed.Add(time.Hour)},
+	}
+	if err := db.Create(&revisions).Error; err != nil {
+		t.Fatalf("create revisions: %v", err)
+	}
+
+	result, err := Resolve(context.Background(), db, market.ID, OutcomeYes, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if result.CorrectPredictions != 0 {
+		t.Fatalf("expected the pre-close NO to be scored, got %+v", result)
+	}
+
+	var scored models.Prediction
+	db.First(&scored, prediction.ID)
+	if scored.ScoredRevision == nil || *scored.ScoredRevision != 2 || !scored.LateFlip || scored.WasCorrect {
+		t.Fatalf("expected revision 2 scored as a late flip, got %+v", scored)
+	}
+	want := math.Min(1, models.BrierScore("NO", 70, OutcomeYes)+0.25)
+	if scored.BrierScore == nil || math.Abs(*scored.BrierScore-want) > 1e-9 {
+		t.Fatalf("expected Brier score %v with the late flip penalty, got %v", want, scored.BrierScore)
+	}
+}
+
+func TestPreviewResolve_MatchesResolveWithoutWriting(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	db.Create(&market)
+
+	right := modelstesting.GenerateAgent("right")
+	db.Create(&right)
+	wrong := modelstesting.GenerateAgent("wrong")
+	db.Create(&wrong)
+	predictions := []models.Prediction{
+		{AgentID: right.ID, MarketID: market.ID, Outcome: "YES", Confidence: 80, PredictedAt: time.Now()},
+		{AgentID: wrong.ID, MarketID: market.ID, Outcome: "NO", Confidence: 60, PredictedAt: time.Now()},
+	}
+	if err := db.Create(&predictions).Error; err != nil {
+		t.Fatalf("create predictions: %v", err)
+	}
+
+	preview, err := PreviewResolve(ctx, db, market.ID, OutcomeYes, nil)
+	if err != nil {
+		t.Fatalf("PreviewResolve: %v", err)
+	}
+	if preview.Predictions != 2 || preview.CorrectPredictions != 1 || preview.WrongPredictions != 1 || len(preview.Agents) != 2 {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+
+	var stored models.Market
+	db.First(&stored, market.ID)
+	var unresolved int64
+	db.Model(&models.Prediction{}).Where("market_id = ? AND is_resolved = ?", market.ID, false).Count(&unresolved)
+	if stored.IsResolved || unresolved != 2 {
+		t.Fatalf("preview must not write: market resolved %v, %d unresolved predictions", stored.IsResolved, unresolved)
+	}
+
+	if _, err := Resolve(ctx, db, market.ID, OutcomeYes, nil); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	for _, impact := range preview.Agents {
+		var agent models.Agent
+		db.First(&agent, impact.AgentID)
+		if math.Abs(agent.AccuracyScore-impact.AccuracyAfter) > 1e-9 || math.Abs(agent.CompositeScore-impact.CompositeAfter) > 1e-9 {
+			t.Fatalf("preview for %s said %+v, resolution gave accuracy %v composite %v", impact.Name, impact, agent.AccuracyScore, agent.CompositeScore)
+		}
+	}
+
+	if _, err := PreviewResolve(ctx, db, market.ID, OutcomeNo, nil); !errors.Is(err, ErrAlreadyResolved) {
+		t.Fatalf("expected ErrAlreadyResolved, got %v", err)
+	}
+}
+
+func TestReresolve_TakesBackScoresAndAppliesTheNewOutcome(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	market := modelstesting.GenerateMarket(1, user.Username)
+	db.Create(&market)
+
+	if _, err := Reresolve(ctx, db, market.ID, OutcomeNo, nil); !errors.Is(err, ErrNotResolved) {
+		t.Fatalf("expected ErrNotResolved, got %v", err)
+	}
+
+	yes := modelstesting.GenerateAgent("yes")
+	db.Create(&yes)
+	no := modelstesting.GenerateAgent("no")
+	db.Create(&no)
+	predictions := []models.Prediction{
+		{AgentID: yes.ID, MarketID: market.ID, Outcome: "YES", Confidence: 80, PredictedAt: time.Now()},
+		{AgentID: no.ID, MarketID: market.ID, Outcome: "NO", Confidence: 60, PredictedAt: time.Now()},
+	}
+	if err := db.Create(&predictions).Error; err != nil {
+		t.Fatalf("create predictions: %v", err)
+	}
+	if _, err := Resolve(ctx, db, market.ID, OutcomeYes, nil); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	result, err := Reresolve(ctx, db, market.ID, OutcomeNo, nil)
+	if err != nil {
+		t.Fatalf("Reresolve: %v", err)
+	}
+	if result.PredictionsScored != 2 || result.CorrectPredictions != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	var yesAgent, noAgent models.Agent
+	db.First(&yesAgent, yes.ID)
+	db.First(&noAgent, no.ID)
+	if yesAgent.ResolvedPredictions != 1 || yesAgent.CorrectPredictions != 0 || noAgent.CorrectPredictions != 1 {
+		t.Fatalf("expected only the NO agent credited, got %+v / %+v", yesAgent, noAgent)
+	}
+
+	// N/A scores nothing, so both agents lose the prediction entirely.
+	if _, err := Reresolve(ctx, db, market.ID, OutcomeNA, nil); err != nil {
+		t.Fatalf("Reresolve N/A: %v", err)
+	}
+	db.First(&yesAgent, yes.ID)
+	db.First(&noAgent, no.ID)
+	if yesAgent.ResolvedPredictions != 0 || noAgent.ResolvedPredictions != 0 || noAgent.CorrectPredictions != 0 {
+		t.Fatalf("expected no resolved predictions after N/A, got %+v / %+v", yesAgent, noAgent)
+	}
+
+	var events []models.OutboxEvent
+	db.Where("topic = ?", outbox.TopicMarketResolved).Order("id").Find(&events)
+	if len(events) != 3 {
+		t.Fatalf("expected a market resolved event per resolution, got %d", len(events))
+	}
+}
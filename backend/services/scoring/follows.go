@@ -0,0 +1,91 @@
+package scoring
+
+import (
+	"context"
+	"sort"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// AdjustFollows applies a follow (delta 1) or unfollow (delta -1) of
+// followed by follower to both agents' counters with atomic column updates,
+// so concurrent follows never overwrite each other's counts, then
+// recalculates both agents' scores. Run it in the transaction that creates
+// or deletes the follow, after that write. The returned agents reflect what
+// was saved.
+func AdjustFollows(ctx context.Context, db *gorm.DB, followerID, followedID int64, delta int) (map[int64]*models.Agent, error) {
+	ids := []int64{followerID, followedID}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	agents := make(map[int64]*models.Agent, len(ids))
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			if err := lockAgent(tx, id); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Agent{}).Where("id = ?", followerID).
+			Update("total_following", gorm.Expr("total_following + ?", delta)).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Agent{}).Where("id = ?", followedID).
+			Update("total_followers", gorm.Expr("total_followers + ?", delta)).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			var agent models.Agent
+			if err := tx.First(&agent, id).Error; err != nil {
+				return err
+			}
+			agent.RecalculateAllScores()
+			if err := tx.Save(&agent).Error; err != nil {
+				return err
+			}
+			agents[id] = &agent
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// ReconcileFollows recounts every agent's followers and following from
+// agent_follows and recomputes the agents whose stored counters have
+// drifted. It returns how many were corrected.
+func ReconcileFollows(ctx context.Context, db *gorm.DB) (int, error) {
+	agentType := string(models.ActorTypeAgent)
+	followers := db.Model(&models.AgentFollow{}).
+		Select("followed_id AS agent_id, COUNT(*) AS n").
+		Where("followed_type = ?", agentType).
+		Group("followed_id")
+	following := db.Model(&models.AgentFollow{}).
+		Select("follower_id AS agent_id, COUNT(*) AS n").
+		Where("follower_type = ?", agentType).
+		Group("follower_id")
+
+	var drifted []int64
+	err := db.WithContext(ctx).Model(&models.Agent{}).
+		Joins("LEFT JOIN (?) AS followers ON followers.agent_id = agents.id", followers).
+		Joins("LEFT JOIN (?) AS following ON following.agent_id = agents.id", following).
+		Where("agents.total_followers <> COALESCE(followers.n, 0) OR agents.total_following <> COALESCE(following.n, 0)").
+		Pluck("agents.id", &drifted).Error
+	if err != nil {
+		return 0, err
+	}
+
+	fixed := 0
+	for _, agentID := range drifted {
+		if _, err := Recompute(ctx, db, agentID, nil); err != nil {
+			if ctx.Err() != nil {
+				return fixed, ctx.Err()
+			}
+			continue
+		}
+		fixed++
+	}
+	return fixed, nil
+}
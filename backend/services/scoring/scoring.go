@@ -0,0 +1,425 @@
+// Package scoring is the single write path for agent scores. Predictions,
+// votes and follows all feed the same agent row, so instead of incrementing
+// counters on whatever copy of the agent a handler loaded, they call
+// Recompute, which takes a per-agent lock, rebuilds the counters from the
+// source tables and recalculates every score. Follows, the most frequent of
+// these writes, use AdjustFollows to move the two counters they touch in
+// the database instead of recounting.
+package scoring
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"socialpredict/models"
+	"socialpredict/repository"
+
+	"gorm.io/gorm"
+)
+
+// scoreLockClass namespaces the Postgres advisory locks taken here; the
+// agent ID is the second key.
+const scoreLockClass int32 = 1
+
+// RecomputeAll splits the agents into chunks of RecomputeChunkSize, each
+// recomputed in one transaction with its counters counted for the whole
+// chunk at once, and runs up to RecomputeWorkers chunks side by side.
+var (
+	RecomputeChunkSize = 100
+	RecomputeWorkers   = 4
+)
+
+// Recompute reloads the agent inside a transaction while holding its score
+// lock, applies touch (if any), rebuilds its counters and per-category stats
+// and saves them. When db is already a transaction the work runs in a
+// savepoint and the lock is held until the outer transaction ends, so
+// callers should recompute after their own writes. The returned agent reflects what was saved.
+func Recompute(ctx context.Context, db *gorm.DB, agentID int64, touch func(*models.Agent)) (*models.Agent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var agent models.Agent
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := lockAgent(tx, agentID); err != nil {
+				return err
+			}
+			agent = models.Agent{}
+			if err := tx.First(&agent, agentID).Error; err != nil {
+				return err
+			}
+			if touch != nil {
+				touch(&agent)
+			}
+			if err := recount(tx, &agent); err != nil {
+				return err
+			}
+			agent.RecalculateAllScores()
+			if err := tx.Save(&agent).Error; err != nil {
+				return err
+			}
+			return recountCategories(tx, agent.ID)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &agent, nil
+}
+
+// Project is Recompute without the write: it rebuilds the agent's counters,
+// applies adjust to them (if any) and recalculates its scores on a copy that
+// is returned and never saved. Use it to show what a pending change would do
+// to an agent's scores.
+func Project(ctx context.Context, db *gorm.DB, agentID int64, adjust func(*models.Agent)) (*models.Agent, error) {
+	var agent models.Agent
+	if err := db.WithContext(ctx).First(&agent, agentID).Error; err != nil {
+		return nil, err
+	}
+	if err := recount(db.WithContext(ctx), &agent); err != nil {
+		return nil, err
+	}
+	if adjust != nil {
+		adjust(&agent)
+	}
+	agent.RecalculateAllScores()
+	return &agent, nil
+}
+
+// RecomputeAll recomputes every agent and returns how many were updated out
+// of how many agents exist. Agents are recomputed a chunk at a time by a
+// pool of workers; a chunk that fails is retried one agent at a time, and
+// agents that still fail are skipped. It stops early only when ctx is
+// cancelled.
+func RecomputeAll(ctx context.Context, db *gorm.DB) (updated, total int, err error) {
+	return RecomputeAllWithProgress(ctx, db, nil)
+}
+
+// Progress is told how many of total agents have been processed so far.
+// Returning an error stops the run with that error.
+type Progress func(processed, total int) error
+
+// RecomputeAllWithProgress is RecomputeAll, calling progress, if not nil,
+// after each chunk. Calls are never concurrent.
+func RecomputeAllWithProgress(ctx context.Context, db *gorm.DB, progress Progress) (updated, total int, err error) {
+	var agentIDs []int64
+	if err := db.WithContext(ctx).Model(&models.Agent{}).Order("id").Pluck("id", &agentIDs).Error; err != nil {
+		return 0, 0, err
+	}
+	total = len(agentIDs)
+
+	// SQLite has a single writer, so more workers would only queue up.
+	workers := RecomputeWorkers
+	if db.Dialector.Name() != "postgres" || workers < 1 {
+		workers = 1
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		processed int
+		stopErr   error
+		wg        sync.WaitGroup
+	)
+	chunks := make(chan []int64)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ids := range chunks {
+				n := recomputeChunk(runCtx, db, ids)
+
+				mu.Lock()
+				updated += n
+				processed += len(ids)
+				if progress != nil && stopErr == nil {
+					if err := progress(processed, total); err != nil {
+						stopErr = err
+						cancel()
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for start := 0; start < total; start += RecomputeChunkSize {
+		end := start + RecomputeChunkSize
+		if end > total {
+			end = total
+		}
+		select {
+		case chunks <- agentIDs[start:end]:
+		case <-runCtx.Done():
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	if stopErr != nil {
+		return updated, total, stopErr
+	}
+	return updated, total, ctx.Err()
+}
+
+// recomputeChunk recomputes the agents ids, in ascending order, in one
+// transaction holding all their score locks, and returns how many were
+// updated. If that fails they are recomputed one at a time instead, so one
+// bad agent does not hold back the rest of its chunk.
+func recomputeChunk(ctx context.Context, db *gorm.DB, ids []int64) int {
+	var agents []models.Agent
+	err := repository.RetryOnConflict(repository.DefaultConflictRetries, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := lockAgents(tx, ids); err != nil {
+				return err
+			}
+			agents = nil
+			if err := tx.Where("id IN ?", ids).Order("id").Find(&agents).Error; err != nil {
+				return err
+			}
+			batch := make([]*models.Agent, len(agents))
+			for i := range agents {
+				batch[i] = &agents[i]
+			}
+			if err := recountMany(tx, batch); err != nil {
+				return err
+			}
+			for _, agent := range batch {
+				agent.RecalculateAllScores()
+				if err := tx.Save(agent).Error; err != nil {
+					return err
+				}
+			}
+			return recountCategories(tx, ids...)
+		})
+	})
+	if err == nil {
+		return len(agents)
+	}
+
+	updated := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := Recompute(ctx, db, id, nil); err == nil {
+			updated++
+		}
+	}
+	return updated
+}
+
+// RecomputeAgents recomputes several agents in one transaction. Locks are
+// taken in ascending ID order so two requests touching the same pair of
+// agents (a follow and a follow-back) cannot deadlock.
+func RecomputeAgents(ctx context.Context, db *gorm.DB, agentIDs ...int64) (map[int64]*models.Agent, error) {
+	ids := append([]int64(nil), agentIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	agents := make(map[int64]*models.Agent, len(ids))
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			if _, done := agents[id]; done {
+				continue
+			}
+			agent, err := Recompute(ctx, tx, id, nil)
+			if err != nil {
+				return err
+			}
+			agents[id] = agent
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// lockAgents takes the score locks of the agents ids, which must be in
+// ascending order, as lockAgent does for each.
+func lockAgents(tx *gorm.DB, ids []int64) error {
+	if tx.Dialector.Name() == "postgres" {
+		for _, id := range ids {
+			if err := lockAgent(tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return tx.Exec("UPDATE agents SET id = id WHERE id IN ?", ids).Error
+}
+
+// lockAgent serializes score updates for one agent until the surrounding
+// transaction ends.
+func lockAgent(tx *gorm.DB, agentID int64) error {
+	if tx.Dialector.Name() == "postgres" {
+		// IDs past 2^31 wrap, which at worst makes two agents share a lock.
+		return tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", scoreLockClass, int32(agentID)).Error
+	}
+	// SQLite has a single writer: a no-op write takes the database write lock
+	// before the counts are read.
+	return tx.Exec("UPDATE agents SET id = id WHERE id = ?", agentID).Error
+}
+
+// recount replaces the agent's derived counters with values computed from
+// the predictions, follows and markets tables.
+func recount(tx *gorm.DB, agent *models.Agent) error {
+	return recountMany(tx, []*models.Agent{agent})
+}
+
+// recountMany is recount for several agents, counting for all of them in
+// one query per source table.
+func recountMany(tx *gorm.DB, agents []*models.Agent) error {
+	if len(agents) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(agents))
+	for i, agent := range agents {
+		ids[i] = agent.ID
+	}
+	agentType := string(models.ActorTypeAgent)
+
+	type predictionCounts struct {
+		AgentID   int64
+		Total     int64
+		Resolved  int64
+		Correct   int64
+		Upvotes   int64
+		Downvotes int64
+		Comments  int64
+		Scored    int64
+		Brier     float64
+		LogLoss   float64
+		Reasoning float64
+	}
+	var predictionRows []predictionCounts
+	if err := tx.Model(&models.Prediction{}).
+		Select(`agent_id,
+			COUNT(*) AS total,
+			COALESCE(SUM(CASE WHEN is_resolved THEN 1 ELSE 0 END), 0) AS resolved,
+			COALESCE(SUM(CASE WHEN is_resolved AND was_correct THEN 1 ELSE 0 END), 0) AS correct,
+			COALESCE(SUM(upvotes), 0) AS upvotes,
+			COALESCE(SUM(downvotes), 0) AS downvotes,
+			COALESCE(SUM(comments), 0) AS comments,
+			COUNT(brier_score) AS scored,
+			COALESCE(AVG(brier_score), 0) AS brier,
+			COALESCE(AVG(log_loss), 0) AS log_loss,
+			COALESCE(AVG(reasoning_score), 0) AS reasoning`).
+		Where("agent_id IN ?", ids).
+		Group("agent_id").
+		Scan(&predictionRows).Error; err != nil {
+		return err
+	}
+	predictions := make(map[int64]predictionCounts, len(predictionRows))
+	for _, row := range predictionRows {
+		predictions[row.AgentID] = row
+	}
+
+	followers, err := countByAgent(tx.Model(&models.AgentFollow{}).
+		Where("followed_type = ? AND followed_id IN ?", agentType, ids), "followed_id")
+	if err != nil {
+		return err
+	}
+	following, err := countByAgent(tx.Model(&models.AgentFollow{}).
+		Where("follower_type = ? AND follower_id IN ?", agentType, ids), "follower_id")
+	if err != nil {
+		return err
+	}
+	marketsCreated, err := countByAgent(tx.Model(&models.Market{}).
+		Where("creator_type = ? AND creator_id IN ?", agentType, ids), "creator_id")
+	if err != nil {
+		return err
+	}
+
+	for _, agent := range agents {
+		p := predictions[agent.ID]
+		agent.TotalPredictions = p.Total
+		agent.ResolvedPredictions = p.Resolved
+		agent.CorrectPredictions = p.Correct
+		agent.ScoredPredictions = p.Scored
+		agent.MeanBrierScore = p.Brier
+		agent.MeanLogLoss = p.LogLoss
+		agent.MeanReasoningScore = p.Reasoning
+		agent.TotalUpvotesReceived = p.Upvotes
+		agent.TotalDownvotesReceived = p.Downvotes
+		agent.TotalCommentsReceived = p.Comments
+		agent.TotalFollowers = followers[agent.ID]
+		agent.TotalFollowing = following[agent.ID]
+		agent.MarketsCreated = marketsCreated[agent.ID]
+	}
+	return nil
+}
+
+// countByAgent counts the rows query matches for each agent, identified by
+// column.
+func countByAgent(query *gorm.DB, column string) (map[int64]int64, error) {
+	var rows []struct {
+		AgentID int64
+		N       int64
+	}
+	if err := query.Select(column + " AS agent_id, COUNT(*) AS n").Group(column).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.AgentID] = row.N
+	}
+	return counts, nil
+}
+
+// recountCategories replaces the per-category stats of the agents ids with
+// values computed from their resolved predictions, grouped by market
+// category.
+func recountCategories(tx *gorm.DB, agentIDs ...int64) error {
+	if len(agentIDs) == 0 {
+		return nil
+	}
+	var rows []struct {
+		AgentID  int64
+		Category string
+		Resolved int64
+		Correct  int64
+		Scored   int64
+		Brier    float64
+	}
+	if err := tx.Model(&models.Prediction{}).
+		Select(`predictions.agent_id AS agent_id,
+			markets.category AS category,
+			COUNT(*) AS resolved,
+			COALESCE(SUM(CASE WHEN predictions.was_correct THEN 1 ELSE 0 END), 0) AS correct,
+			COUNT(predictions.brier_score) AS scored,
+			COALESCE(AVG(predictions.brier_score), 0) AS brier`).
+		Joins("JOIN markets ON markets.id = predictions.market_id").
+		Where("predictions.agent_id IN ? AND predictions.is_resolved", agentIDs).
+		Group("predictions.agent_id, markets.category").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Where("agent_id IN ?", agentIDs).Delete(&models.AgentCategoryStats{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	stats := make([]models.AgentCategoryStats, len(rows))
+	for i, row := range rows {
+		stats[i] = models.AgentCategoryStats{
+			AgentID:             row.AgentID,
+			Category:            row.Category,
+			ResolvedPredictions: row.Resolved,
+			CorrectPredictions:  row.Correct,
+			ScoredPredictions:   row.Scored,
+			MeanBrierScore:      row.Brier,
+		}
+		stats[i].RecalculateScores()
+	}
+	return tx.Create(&stats).Error
+}
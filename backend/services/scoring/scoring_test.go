@@ -0,0 +1,310 @@
+package scoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func TestRecompute_RebuildsCountersFromSourceTables(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		user := modelstesting.GenerateUser("creator", 0)
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		market := modelstesting.GenerateMarket(0, user.Username)
+		if err := db.Create(&market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+
+		author := modelstesting.GenerateAgent("author")
+		db.Create(&author)
+		fan := modelstesting.GenerateAgent("fan")
+		db.Create(&fan)
+
+		predictions := []models.Prediction{
+			{AgentID: author.ID, MarketID: market.ID, Outcome: "YES", Upvotes: 3, Comments: 1, IsResolved: true, WasCorrect: true, PredictedAt: time.Now()},
+			{AgentID: author.ID, MarketID: market.ID, Outcome: "NO", Downvotes: 2, IsResolved: true, PredictedAt: time.Now()},
+		}
+		if err := db.Create(&predictions).Error; err != nil {
+			t.Fatalf("create predictions: %v", err)
+		}
+		follow := models.NewAgentFollow(models.AgentActor(fan.ID), models.AgentActor(author.ID))
+		if err := db.Create(&follow).Error; err != nil {
+			t.Fatalf("create follow: %v", err)
+		}
+
+		// Counters written by a racing handler that are now wrong.
+		if err := db.Model(&models.Agent{}).Where("id = ?", author.ID).
+			Updates(map[string]interface{}{"total_predictions": 7, "total_followers": 0}).Error; err != nil {
+			t.Fatalf("corrupt counters: %v", err)
+		}
+
+		touched := false
+		got, err := Recompute(context.Background(), db, author.ID, func(a *models.Agent) { touched = true })
+		if err != nil {
+			t.Fatalf("Recompute: %v", err)
+		}
+		if !touched {
+			t.Fatal("expected touch to be applied")
+		}
+		if got.TotalPredictions != 2 || got.ResolvedPredictions != 2 || got.CorrectPredictions != 1 {
+			t.Fatalf("unexpected prediction counters: %+v", got)
+		}
+		if got.TotalUpvotesReceived != 3 || got.TotalDownvotesReceived != 2 || got.TotalCommentsReceived != 1 || got.TotalFollowers != 1 {
+			t.Fatalf("unexpected engagement counters: %+v", got)
+		}
+
+		agents, err := RecomputeAgents(context.Background(), db, author.ID, fan.ID, author.ID)
+		if err != nil {
+			t.Fatalf("RecomputeAgents: %v", err)
+		}
+		if len(agents) != 2 || agents[fan.ID].TotalFollowing != 1 {
+			t.Fatalf("expected both agents recomputed with fan following 1, got %+v", agents)
+		}
+
+		var stored models.Agent
+		db.First(&stored, author.ID)
+		if stored.TotalPredictions != 2 || stored.CompositeScore != got.CompositeScore {
+			t.Fatalf("expected recomputed agent to be saved, got %+v", stored)
+		}
+	})
+}
+
+func TestRecompute_TracksAccuracyPerCategory(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		user := modelstesting.GenerateUser("creator", 0)
+		db.Create(&user)
+		crypto := modelstesting.GenerateMarket(0, user.Username)
+		crypto.Category = "crypto"
+		sports := modelstesting.GenerateMarket(0, user.Username)
+		sports.Category = "sports"
+		for _, market := range []*models.Market{&crypto, &sports} {
+			if err := db.Create(market).Error; err != nil {
+				t.Fatalf("create market: %v", err)
+			}
+		}
+
+		agent := modelstesting.GenerateAgent("specialist")
+		db.Create(&agent)
+		predictions := []models.Prediction{
+			{AgentID: agent.ID, MarketID: crypto.ID, Outcome: "YES", IsResolved: true, WasCorrect: true, PredictedAt: time.Now()},
+			{AgentID: agent.ID, MarketID: sports.ID, Outcome: "NO", IsResolved: true, PredictedAt: time.Now()},
+		}
+		if err := db.Create(&predictions).Error; err != nil {
+			t.Fatalf("create predictions: %v", err)
+		}
+
+		if _, err := Recompute(context.Background(), db, agent.ID, nil); err != nil {
+			t.Fatalf("Recompute: %v", err)
+		}
+		stats, err := models.CategoryStatsForAgent(db, agent.ID)
+		if err != nil {
+			t.Fatalf("CategoryStatsForAgent: %v", err)
+		}
+		if len(stats) != 2 || stats[0].Category != "crypto" || stats[0].CorrectPredictions != 1 || stats[1].Category != "sports" || stats[1].ResolvedPredictions != 1 {
+			t.Fatalf("expected crypto ahead of sports, got %+v", stats)
+		}
+		if stats[0].AccuracyScore <= 50 || stats[1].AccuracyScore >= 50 {
+			t.Fatalf("expected crypto above and sports below the prior, got %+v", stats)
+		}
+
+		// A category the agent no longer has resolved predictions in is dropped.
+		db.Delete(&predictions[0])
+		Recompute(context.Background(), db, agent.ID, nil)
+		stats, _ = models.CategoryStatsForAgent(db, agent.ID)
+		if len(stats) != 1 || stats[0].Category != "sports" {
+			t.Fatalf("expected only sports left, got %+v", stats)
+		}
+	})
+}
+
+func TestRecomputeAll_ChunksAcrossWorkers(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		chunkSize, workers := RecomputeChunkSize, RecomputeWorkers
+		RecomputeChunkSize, RecomputeWorkers = 2, 3
+		t.Cleanup(func() { RecomputeChunkSize, RecomputeWorkers = chunkSize, workers })
+
+		user := modelstesting.GenerateUser("creator", 0)
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		market := modelstesting.GenerateMarket(0, user.Username)
+		if err := db.Create(&market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+
+		// Agent i has made i predictions but its counter says otherwise.
+		var agents []*models.Agent
+		for i := 0; i < 5; i++ {
+			agent := modelstesting.GenerateAgent(fmt.Sprintf("agent%d", i))
+			db.Create(&agent)
+			for j := 0; j < i; j++ {
+				prediction := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: "YES", IsResolved: true, WasCorrect: true, PredictedAt: time.Now()}
+				if err := db.Create(&prediction).Error; err != nil {
+					t.Fatalf("create prediction: %v", err)
+				}
+			}
+			db.Model(&models.Agent{}).Where("id = ?", agent.ID).Update("total_predictions", 99)
+			agents = append(agents, &agent)
+		}
+
+		var reports []int
+		updated, total, err := RecomputeAllWithProgress(context.Background(), db, func(processed, total int) error {
+			reports = append(reports, processed)
+			return nil
+		})
+		if err != nil || updated != 5 || total != 5 {
+			t.Fatalf("expected 5 of 5 agents updated, got %d of %d, %v", updated, total, err)
+		}
+		if len(reports) != 3 || reports[2] != 5 {
+			t.Fatalf("expected progress after each of 3 chunks ending at 5, got %v", reports)
+		}
+		for i, agent := range agents {
+			var stored models.Agent
+			db.First(&stored, agent.ID)
+			if stored.TotalPredictions != int64(i) || stored.CorrectPredictions != int64(i) {
+				t.Fatalf("expected %s to have %d predictions, got %d (%d correct)", agent.Name, i, stored.TotalPredictions, stored.CorrectPredictions)
+			}
+			stats, _ := models.CategoryStatsForAgent(db, agent.ID)
+			if (i == 0) != (len(stats) == 0) {
+				t.Fatalf("expected category stats only for agents with predictions, %s has %+v", agent.Name, stats)
+			}
+		}
+
+		// Stopping through progress ends the run early.
+		stop := errors.New("stop")
+		_, _, err = RecomputeAllWithProgress(context.Background(), db, func(processed, total int) error { return stop })
+		if err != stop {
+			t.Fatalf("expected the progress error, got %v", err)
+		}
+	})
+}
+
+func TestRecompute_StopsOnCancelledContext(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	agent := modelstesting.GenerateAgent("idle")
+	db.Create(&agent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Recompute(ctx, db, agent.ID, nil); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWhatIf_ReportsRankChurnWithoutWriting(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		whatIfCache.Lock()
+		whatIfCache.reports = map[string]*WhatIfReport{}
+		whatIfCache.Unlock()
+
+		components := map[string][2]float64{"sharp": {90, 10}, "social": {40, 95}, "steady": {60, 50}}
+		for _, name := range []string{"sharp", "social", "steady", "retired"} {
+			agent := modelstesting.GenerateAgent(name)
+			db.Create(&agent)
+			c := components[name]
+			db.Model(&agent).Updates(map[string]interface{}{"accuracy_score": c[0], "engagement_score": c[1], "composite_score": 12.5})
+		}
+		db.Model(&models.Agent{}).Where("name = ?", "retired").Update("is_active", false)
+
+		// Under the defaults social leads on engagement; on accuracy alone
+		// the order becomes sharp, steady, social.
+		report, err := WhatIf(context.Background(), db, models.CompositeWeights{Accuracy: 2}, 1)
+		if err != nil {
+			t.Fatalf("what-if: %v", err)
+		}
+		if report.ProposedWeights.Accuracy != 1 {
+			t.Fatalf("expected the proposed weights normalized, got %+v", report.ProposedWeights)
+		}
+		order := make([]string, len(report.Agents))
+		for i, a := range report.Agents {
+			order[i] = a.AgentName
+		}
+		if len(order) != 3 || order[0] != "sharp" || order[1] != "steady" || order[2] != "social" {
+			t.Fatalf("expected active agents ordered sharp, steady, social, got %v", order)
+		}
+		if report.Agents[2].CurrentRank != 1 || report.Agents[2].RankChange != -2 {
+			t.Fatalf("expected social to drop from first to third, got %+v", report.Agents[2])
+		}
+		s := report.Summary
+		if s.AgentsMoved != 3 || s.MaxRankChange != 2 || s.TopNRetained != 0 || s.RankCorrelation != -0.5 {
+			t.Fatalf("unexpected summary %+v", s)
+		}
+		if movers := report.Movers(1); len(movers) != 1 || movers[0].AgentName != "social" {
+			t.Fatalf("expected social as the biggest mover, got %+v", movers)
+		}
+
+		var stored models.Agent
+		db.Where("name = ?", "sharp").First(&stored)
+		if stored.CompositeScore != 12.5 {
+			t.Fatalf("expected stored scores untouched, got %v", stored.CompositeScore)
+		}
+
+		again, _ := WhatIf(context.Background(), db, models.CompositeWeights{Accuracy: 5}, 1)
+		if again != report {
+			t.Fatalf("expected equivalent weights to reuse the cached report")
+		}
+		if _, err := WhatIf(context.Background(), db, models.CompositeWeights{}, 1); err == nil {
+			t.Fatalf("expected all-zero weights to be rejected")
+		}
+	})
+}
+
+func TestAdjustFollows_CountsAtomicallyAndReconcileFixesDrift(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		author := modelstesting.GenerateAgent("author")
+		db.Create(&author)
+		fan := modelstesting.GenerateAgent("fan")
+		db.Create(&fan)
+		other := modelstesting.GenerateAgent("other")
+		db.Create(&other)
+
+		for _, follower := range []models.Agent{fan, other} {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				follow := models.NewAgentFollow(models.AgentActor(follower.ID), models.AgentActor(author.ID))
+				if err := tx.Create(&follow).Error; err != nil {
+					return err
+				}
+				_, err := AdjustFollows(context.Background(), tx, follower.ID, author.ID, 1)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("follow: %v", err)
+			}
+		}
+		var got models.Agent
+		db.First(&got, author.ID)
+		if got.TotalFollowers != 2 {
+			t.Fatalf("author followers = %d, want 2", got.TotalFollowers)
+		}
+		var gotFan models.Agent
+		db.First(&gotFan, fan.ID)
+		if gotFan.TotalFollowing != 1 {
+			t.Fatalf("fan following = %d, want 1", gotFan.TotalFollowing)
+		}
+
+		// Counters that drifted anyway are recounted from agent_follows.
+		if err := db.Model(&models.Agent{}).Where("id = ?", author.ID).Update("total_followers", 9).Error; err != nil {
+			t.Fatalf("corrupt counters: %v", err)
+		}
+		fixed, err := ReconcileFollows(context.Background(), db)
+		if err != nil {
+			t.Fatalf("ReconcileFollows: %v", err)
+		}
+		if fixed != 1 {
+			t.Errorf("fixed %d agents, want 1", fixed)
+		}
+		db.First(&got, author.ID)
+		if got.TotalFollowers != 2 {
+			t.Errorf("author followers after reconcile = %d, want 2", got.TotalFollowers)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package scoring
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// WhatIfCacheTTL is how long a what-if analysis is reused for the same
+// weights before the agents are read again.
+const WhatIfCacheTTL = 5 * time.Minute
+
+// WhatIfAgent is one agent's standing under the current and the proposed
+// composite weights. RankChange is positive when the agent moves up.
+type WhatIfAgent struct {
+	AgentID       int64   `json:"agentId"`
+	AgentName     string  `json:"agentName"`
+	CurrentScore  float64 `json:"currentScore"`
+	CurrentRank   int     `json:"currentRank"`
+	ProposedScore float64 `json:"proposedScore"`
+	ProposedRank  int     `json:"proposedRank"`
+	RankChange    int     `json:"rankChange"`
+}
+
+// WhatIfSummary measures how much the proposed weights reorder the
+// leaderboard.
+type WhatIfSummary struct {
+	AgentsRanked      int     `json:"agentsRanked"`
+	AgentsMoved       int     `json:"agentsMoved"`
+	MeanAbsRankChange float64 `json:"meanAbsRankChange"`
+	MaxRankChange     int     `json:"maxRankChange"`
+	// Spearman correlation of the two rankings: 1 is unchanged, -1 reversed.
+	RankCorrelation float64 `json:"rankCorrelation"`
+	TopN            int     `json:"topN"`
+	TopNRetained    int     `json:"topNRetained"` // current top N still in the top N
+}
+
+// WhatIfReport is the leaderboard recomputed under proposed weights, with
+// every active agent ordered by proposed rank.
+type WhatIfReport struct {
+	CurrentWeights  models.CompositeWeights `json:"currentWeights"`
+	ProposedWeights models.CompositeWeights `json:"proposedWeights"`
+	Summary         WhatIfSummary           `json:"summary"`
+	Agents          []WhatIfAgent           `json:"agents"`
+	ComputedAt      time.Time               `json:"computedAt"`
+}
+
+// Movers returns up to n agents whose rank changes most, biggest first.
+func (r *WhatIfReport) Movers(n int) []WhatIfAgent {
+	movers := make([]WhatIfAgent, 0, len(r.Agents))
+	for _, a := range r.Agents {
+		if a.RankChange != 0 {
+			movers = append(movers, a)
+		}
+	}
+	sort.SliceStable(movers, func(i, j int) bool {
+		return abs(movers[i].RankChange) > abs(movers[j].RankChange)
+	})
+	if len(movers) > n {
+		movers = movers[:n]
+	}
+	return movers
+}
+
+var whatIfCache = struct {
+	sync.Mutex
+	reports map[string]*WhatIfReport
+}{reports: map[string]*WhatIfReport{}}
+
+// WhatIf ranks every active agent on their stored component scores under
+// both DefaultCompositeWeights and proposed, which is normalized to sum to
+// 1, and compares the rankings; topN sets the size of the top group whose
+// turnover is reported. Nothing is written. Reports are cached for
+// WhatIfCacheTTL per weights and topN.
+func WhatIf(ctx context.Context, db *gorm.DB, proposed models.CompositeWeights, topN int) (*WhatIfReport, error) {
+	proposed, err := proposed.Normalized()
+	if err != nil {
+		return nil, err
+	}
+	current := models.DefaultCompositeWeights
+
+	key := current.Key() + "|" + proposed.Key() + "|" + strconv.Itoa(topN)
+	whatIfCache.Lock()
+	cached, ok := whatIfCache.reports[key]
+	whatIfCache.Unlock()
+	if ok && time.Since(cached.ComputedAt) < WhatIfCacheTTL {
+		return cached, nil
+	}
+
+	var agents []models.Agent
+	if err := db.WithContext(ctx).
+		Select("id", "name", "accuracy_score", "engagement_score", "creator_score", "activity_score").
+		Where("is_active = ?", true).
+		Find(&agents).Error; err != nil {
+		return nil, err
+	}
+
+	rows := make([]WhatIfAgent, len(agents))
+	for i := range agents {
+		rows[i] = WhatIfAgent{
+			AgentID:       agents[i].ID,
+			AgentName:     agents[i].Name,
+			CurrentScore:  current.Composite(&agents[i]),
+			ProposedScore: proposed.Composite(&agents[i]),
+		}
+	}
+	rank(rows, func(a WhatIfAgent) float64 { return a.CurrentScore }, func(a *WhatIfAgent, r int) { a.CurrentRank = r })
+	rank(rows, func(a WhatIfAgent) float64 { return a.ProposedScore }, func(a *WhatIfAgent, r int) { a.ProposedRank = r })
+
+	report := &WhatIfReport{
+		CurrentWeights:  current,
+		ProposedWeights: proposed,
+		Agents:          rows,
+		ComputedAt:      time.Now(),
+	}
+	report.Summary = summarize(rows, topN)
+
+	whatIfCache.Lock()
+	for k, r := range whatIfCache.reports {
+		if time.Since(r.ComputedAt) >= WhatIfCacheTTL {
+			delete(whatIfCache.reports, k)
+		}
+	}
+	whatIfCache.reports[key] = report
+	whatIfCache.Unlock()
+	return report, nil
+}
+
+// rank sorts rows by score, highest first with ties broken by agent ID, and
+// numbers them from 1 with set.
+func rank(rows []WhatIfAgent, score func(WhatIfAgent) float64, set func(*WhatIfAgent, int)) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		si, sj := score(rows[i]), score(rows[j])
+		if si != sj {
+			return si > sj
+		}
+		return rows[i].AgentID < rows[j].AgentID
+	})
+	for i := range rows {
+		set(&rows[i], i+1)
+	}
+}
+
+func summarize(rows []WhatIfAgent, topN int) WhatIfSummary {
+	n := len(rows)
+	summary := WhatIfSummary{AgentsRanked: n, TopN: topN, RankCorrelation: 1}
+	if n == 0 {
+		return summary
+	}
+
+	var totalChange, squaredDiffs float64
+	for i := range rows {
+		change := rows[i].CurrentRank - rows[i].ProposedRank
+		rows[i].RankChange = change
+		if change != 0 {
+			summary.AgentsMoved++
+		}
+		if abs(change) > summary.MaxRankChange {
+			summary.MaxRankChange = abs(change)
+		}
+		totalChange += float64(abs(change))
+		squaredDiffs += float64(change * change)
+		if rows[i].CurrentRank <= topN && rows[i].ProposedRank <= topN {
+			summary.TopNRetained++
+		}
+	}
+	summary.MeanAbsRankChange = math.Round(totalChange/float64(n)*100) / 100
+	if n > 1 {
+		nf := float64(n)
+		summary.RankCorrelation = 1 - 6*squaredDiffs/(nf*(nf*nf-1))
+	}
+	return summary
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,272 @@
+// Package series groups related markets, such as one question asked for
+// each quarter of a year, into series that are created and read as a set.
+// A mutually exclusive series has at most one market resolve YES, and the
+// predictions made on it are kept coherent with that: an agent's implied
+// chances of YES across its open markets may not add up to more than 100%.
+package series
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"socialpredict/models"
+	"socialpredict/repository"
+	"socialpredict/services/consensus"
+	"socialpredict/services/marketcreation"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+)
+
+// Bounds on the number of markets in a series.
+const (
+	MinMarkets = 2
+	MaxMarkets = 20
+)
+
+// coherenceTolerance absorbs rounding in agents' stated confidences.
+const coherenceTolerance = 1e-9
+
+var (
+	ErrSeriesNotFound = errors.New("series not found")
+	ErrNotCreator     = errors.New("only the series' creator can add markets to it")
+	ErrMarketCount    = fmt.Errorf("a series has %d to %d markets", MinMarkets, MaxMarkets)
+	ErrSeriesFull     = fmt.Errorf("a series has at most %d markets", MaxMarkets)
+	// ErrNotBinary is returned for a scalar market in a mutually exclusive
+	// series, whose markets must each resolve YES or NO.
+	ErrNotBinary = errors.New("markets in a mutually exclusive series must be binary")
+	// ErrInviteOnly is returned for an invite-only market, which a series
+	// would show to agents it was not opened to.
+	ErrInviteOnly        = errors.New("invite-only markets cannot be part of a series")
+	ErrDuplicateQuestion = errors.New("the series already asks this question")
+	ErrSeriesSettled     = errors.New("a market in this mutually exclusive series has already resolved YES")
+	ErrIncoherent        = errors.New("chances of YES across a mutually exclusive series cannot add up to more than 100%")
+)
+
+// IsInvalid reports whether err was caused by a series or market the
+// caller asked for that cannot be created.
+func IsInvalid(err error) bool {
+	for _, target := range []error{ErrMarketCount, ErrNotBinary, ErrInviteOnly, ErrDuplicateQuestion} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return marketcreation.IsValidationError(err)
+}
+
+// Create stores the series and creates its markets in it, all in one
+// transaction, each through the market creation pipeline with the series'
+// creator as their creator. An error about one of the markets names its
+// position in inputs.
+func Create(db *gorm.DB, econ setup.EconConfigLoader, series *models.MarketSeries, inputs []marketcreation.Input) ([]models.Market, error) {
+	if len(inputs) < MinMarkets || len(inputs) > MaxMarkets {
+		return nil, ErrMarketCount
+	}
+	questions := make(map[string]bool, len(inputs))
+	for i, in := range inputs {
+		if err := fits(series, questions, in); err != nil {
+			return nil, fmt.Errorf("market %d: %w", i+1, err)
+		}
+	}
+
+	var markets []models.Market
+	err := db.Transaction(func(tx *gorm.DB) error {
+		markets = make([]models.Market, 0, len(inputs))
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+		creation := marketcreation.NewService(repository.NewGormRepositories(tx), econ)
+		for i, in := range inputs {
+			in.CreatorAgentID = series.CreatorAgentID
+			in.SeriesID = &series.ID
+			market, err := creation.Create(in)
+			if err != nil {
+				return fmt.Errorf("market %d: %w", i+1, err)
+			}
+			markets = append(markets, *market)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return markets, nil
+}
+
+// AddMarket creates a market in the series for its creator, agentID. A
+// mutually exclusive series that already has a market resolved YES takes
+// no more.
+func AddMarket(db *gorm.DB, econ setup.EconConfigLoader, seriesID, agentID int64, in marketcreation.Input) (*models.Market, error) {
+	series, err := Load(db, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if series.CreatorAgentID != agentID {
+		return nil, ErrNotCreator
+	}
+	existing, err := Markets(db, series.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxMarkets {
+		return nil, ErrSeriesFull
+	}
+	questions := make(map[string]bool, len(existing))
+	for _, market := range existing {
+		questions[question(market.QuestionTitle)] = true
+		if series.MutuallyExclusive && market.IsResolved && market.ResolutionResult == "YES" {
+			return nil, ErrSeriesSettled
+		}
+	}
+	if err := fits(series, questions, in); err != nil {
+		return nil, err
+	}
+
+	in.CreatorAgentID = agentID
+	in.SeriesID = &series.ID
+	return marketcreation.NewService(repository.NewGormRepositories(db), econ).Create(in)
+}
+
+// fits checks that the market in can join series, whose questions so far
+// are in questions, and adds its question.
+func fits(series *models.MarketSeries, questions map[string]bool, in marketcreation.Input) error {
+	if strings.EqualFold(strings.TrimSpace(in.Visibility), models.MarketInviteOnly) {
+		return ErrInviteOnly
+	}
+	if series.MutuallyExclusive && strings.EqualFold(strings.TrimSpace(in.OutcomeType), models.OutcomeScalar) {
+		return ErrNotBinary
+	}
+	q := question(in.QuestionTitle)
+	if questions[q] {
+		return ErrDuplicateQuestion
+	}
+	questions[q] = true
+	return nil
+}
+
+// question is how a market's title is compared with the others in its
+// series.
+func question(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// Load returns the series with the given ID.
+func Load(db *gorm.DB, seriesID int64) (*models.MarketSeries, error) {
+	var series models.MarketSeries
+	if err := db.First(&series, seriesID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, err
+	}
+	return &series, nil
+}
+
+// Markets returns the markets in the series, in the order they were
+// created.
+func Markets(db *gorm.DB, seriesID int64) ([]models.Market, error) {
+	var markets []models.Market
+	err := db.Where("series_id = ?", seriesID).Order("id").Find(&markets).Error
+	return markets, err
+}
+
+// MarketConsensus is a market in a series with its current consensus.
+type MarketConsensus struct {
+	MarketID         int64  `json:"marketId"`
+	QuestionTitle    string `json:"questionTitle"`
+	IsResolved       bool   `json:"isResolved"`
+	ResolutionResult string `json:"resolutionResult,omitempty"`
+	// Binary markets: the swarm's weighted chance of YES and, in a
+	// mutually exclusive series, its share of the series' total
+	Probability *float64 `json:"probability,omitempty"`
+	Share       *float64 `json:"share,omitempty"`
+	// Scalar markets
+	Scalar      *consensus.Scalar `json:"scalar,omitempty"`
+	Predictions int64             `json:"predictions"`
+}
+
+// Consensus is the consensus of every market in a series.
+type Consensus struct {
+	Markets []MarketConsensus `json:"markets"`
+	// Sum of the binary markets' chances of YES. In a mutually exclusive
+	// series a coherent swarm keeps it at or below 1.
+	TotalProbability float64 `json:"totalProbability"`
+}
+
+// ConsensusOf returns the current consensus of each of the series'
+// markets.
+func ConsensusOf(db *gorm.DB, series *models.MarketSeries, markets []models.Market) (*Consensus, error) {
+	result := &Consensus{Markets: make([]MarketConsensus, len(markets))}
+	for i, market := range markets {
+		entry := MarketConsensus{
+			MarketID:         market.ID,
+			QuestionTitle:    market.QuestionTitle,
+			IsResolved:       market.IsResolved,
+			ResolutionResult: market.ResolutionResult,
+		}
+		if market.IsScalar() {
+			scalar, err := consensus.CurrentScalar(db, market.ID)
+			if err != nil {
+				return nil, err
+			}
+			entry.Scalar = scalar
+			if scalar != nil {
+				entry.Predictions = scalar.Predictions
+			}
+		} else {
+			probability, predictions, err := consensus.Current(db, market.ID)
+			if err != nil {
+				return nil, err
+			}
+			entry.Probability = &probability
+			entry.Predictions = predictions
+			result.TotalProbability += probability
+		}
+		result.Markets[i] = entry
+	}
+
+	if series.MutuallyExclusive && result.TotalProbability > 0 {
+		for i := range result.Markets {
+			if p := result.Markets[i].Probability; p != nil {
+				share := *p / result.TotalProbability
+				result.Markets[i].Share = &share
+			}
+		}
+	}
+	return result, nil
+}
+
+// CheckCoherent returns ErrIncoherent if giving market a chance of YES of
+// impliedYes would take the agent's chances across the market's mutually
+// exclusive series above 100%. The other markets count with the agent's
+// current predictions on those still open. Markets outside a mutually
+// exclusive series always pass.
+func CheckCoherent(db *gorm.DB, market models.Market, agentID int64, impliedYes float64) error {
+	if market.SeriesID == nil {
+		return nil
+	}
+	var series models.MarketSeries
+	if err := db.Select("id", "mutually_exclusive").First(&series, *market.SeriesID).Error; err != nil {
+		return err
+	}
+	if !series.MutuallyExclusive {
+		return nil
+	}
+
+	var predictions []models.Prediction
+	if err := db.Where("agent_id = ? AND market_id IN (?)", agentID, db.Model(&models.Market{}).
+		Select("id").
+		Where("series_id = ? AND id <> ? AND is_resolved = ?", series.ID, market.ID, false)).
+		Find(&predictions).Error; err != nil {
+		return err
+	}
+	total := impliedYes
+	for _, p := range predictions {
+		total += consensus.ImpliedYes(p)
+	}
+	if total > 1+coherenceTolerance {
+		return fmt.Errorf("%w: yours would add up to %.0f%%", ErrIncoherent, total*100)
+	}
+	return nil
+}
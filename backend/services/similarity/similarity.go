@@ -0,0 +1,142 @@
+// Package similarity finds existing markets whose question resembles a new
+// one, so a rephrased duplicate is caught and a question that merely
+// contains another is not.
+//
+// Questions are compared by trigram similarity: each is broken into the set
+// of three-letter sequences of its words and scored by how much the two sets
+// overlap, from 0 (nothing shared) to 1 (same trigrams). On PostgreSQL with
+// the pg_trgm extension the scoring runs in the database against a trigram
+// index; elsewhere candidates are narrowed with LIKE and scored here, with
+// the same trigram rules so scores agree across databases.
+package similarity
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// maxCandidates caps how many markets are scored in Go when pg_trgm is not
+// available.
+const maxCandidates = 1000
+
+// Match is an existing market similar to the question searched for.
+type Match struct {
+	MarketID      int64   `json:"marketId"`
+	QuestionTitle string  `json:"questionTitle"`
+	IsResolved    bool    `json:"isResolved"`
+	Score         float64 `json:"score"` // 0 to 1
+}
+
+// Similar returns up to limit listed markets whose question title scores at
+// least minScore against title, most similar first. Unlisted and invite-only
+// markets are never matched, so their questions stay undiscoverable.
+func Similar(ctx context.Context, db *gorm.DB, title string, limit int, minScore float64) ([]Match, error) {
+	db = db.WithContext(ctx)
+	if hasTrigramExtension(db) {
+		return similarInDatabase(db, title, limit, minScore)
+	}
+	return similarInGo(db, title, limit, minScore)
+}
+
+// Score is the trigram similarity of a and b, as pg_trgm's similarity().
+func Score(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// trigrams returns the trigram set of s the way pg_trgm builds it: s is
+// lowercased and split into words of letters and digits, and each word is
+// padded with two spaces in front and one behind before its three-character
+// sequences are taken.
+func trigrams(s string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, word := range words(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func hasTrigramExtension(db *gorm.DB) bool {
+	if db.Dialector.Name() != "postgres" {
+		return false
+	}
+	var available bool
+	err := db.Raw("SELECT to_regprocedure('similarity(text,text)') IS NOT NULL").Scan(&available).Error
+	return err == nil && available
+}
+
+func similarInDatabase(db *gorm.DB, title string, limit int, minScore float64) ([]Match, error) {
+	matches := []Match{}
+	err := models.Listed(db.Model(&models.Market{})).
+		Select("id AS market_id, question_title, is_resolved, similarity(question_title, ?) AS score", title).
+		Where("question_title % ? AND similarity(question_title, ?) >= ?", title, title, minScore).
+		Order("score DESC, id DESC").
+		Limit(limit).
+		Scan(&matches).Error
+	return matches, err
+}
+
+// similarInGo scores the markets that share at least one word of three or
+// more letters with title.
+func similarInGo(db *gorm.DB, title string, limit int, minScore float64) ([]Match, error) {
+	matches := []Match{}
+	query := models.Listed(db.Model(&models.Market{})).Select("id", "question_title", "is_resolved")
+	var conditions []string
+	var args []interface{}
+	for _, word := range words(title) {
+		if len([]rune(word)) >= 3 {
+			conditions = append(conditions, "LOWER(question_title) LIKE ?")
+			args = append(args, "%"+word+"%")
+		}
+	}
+	if len(conditions) == 0 {
+		return matches, nil
+	}
+
+	var candidates []models.Market
+	if err := query.Where(strings.Join(conditions, " OR "), args...).
+		Order("id DESC").
+		Limit(maxCandidates).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	for _, market := range candidates {
+		if score := Score(title, market.QuestionTitle); score >= minScore {
+			matches = append(matches, Match{
+				MarketID:      market.ID,
+				QuestionTitle: market.QuestionTitle,
+				IsResolved:    market.IsResolved,
+				Score:         score,
+			})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
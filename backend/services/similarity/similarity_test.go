@@ -0,0 +1,67 @@
+package similarity
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func TestScore_MatchesPgTrgm(t *testing.T) {
+	// SELECT similarity('word', 'words') = 0.5714286
+	if got := Score("word", "words"); math.Abs(got-4.0/7) > 1e-9 {
+		t.Fatalf("expected 4/7, got %v", got)
+	}
+	if got := Score("Will BTC...", "will btc"); got != 1 {
+		t.Fatalf("expected case and punctuation to be ignored, got %v", got)
+	}
+	if got := Score("", "anything"); got != 0 {
+		t.Fatalf("expected 0 for an empty title, got %v", got)
+	}
+}
+
+func TestSimilar_RanksRephrasingsAndIgnoresSubstrings(t *testing.T) {
+	modelstesting.ForEachDialect(t, func(t *testing.T, db *gorm.DB) {
+		user := modelstesting.GenerateUser("creator", 0)
+		db.Create(&user)
+		titles := []string{
+			"Will Bitcoin close above $100,000 on December 31, 2026?",
+			"Will it rain in Paris on the day of the 2026 marathon final?",
+			"Will the Lakers win the 2026 NBA championship?",
+		}
+		ids := make([]int64, len(titles))
+		for i, title := range titles {
+			market := modelstesting.GenerateMarket(0, user.Username)
+			market.QuestionTitle = title
+			market.ResolutionDateTime = time.Now().Add(time.Hour)
+			if err := db.Create(&market).Error; err != nil {
+				t.Fatalf("create market: %v", err)
+			}
+			ids[i] = market.ID
+		}
+		similar := func(title string) []Match {
+			matches, err := Similar(context.Background(), db, title, 5, 0.3)
+			if err != nil {
+				t.Fatalf("similar %q: %v", title, err)
+			}
+			return matches
+		}
+
+		near := similar("Will Bitcoin close above $100,000 by December 31 2026?")
+		if len(near) != 1 || near[0].MarketID != ids[0] || near[0].Score < 0.9 {
+			t.Fatalf("expected the bitcoin market as a near match, got %+v", near)
+		}
+		rephrased := similar("Bitcoin closes above $100,000 on Dec 31 2026")
+		if len(rephrased) != 1 || rephrased[0].MarketID != ids[0] || rephrased[0].Score < 0.7 || rephrased[0].Score >= 0.8 {
+			t.Fatalf("expected the rephrasing to score as similar but not identical, got %+v", rephrased)
+		}
+		// A title contained in a longer, different question is not a match.
+		if substring := similar("Will it rain"); len(substring) != 0 {
+			t.Fatalf("expected no match for a short substring, got %+v", substring)
+		}
+	})
+}
@@ -0,0 +1,377 @@
+// Package teams lets agents form teams that compete as collective swarms.
+// A team is scored on its active members' current scores, and predictions
+// its members make while on it are tagged with it, so a multi-agent
+// framework can be ranked as one entrant as well as agent by agent.
+package teams
+
+import (
+	"errors"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/notifications"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTeamNotFound  = errors.New("team not found")
+	ErrAgentNotFound = errors.New("agent not found")
+	ErrNameTaken     = errors.New("team name already taken")
+	ErrOnAnotherTeam = errors.New("agent is already on a team")
+	ErrAlreadyMember = errors.New("agent is already on this team")
+	ErrNotCaptain    = errors.New("only the team captain can do this")
+	ErrNotInvited    = errors.New("agent has not been invited to this team")
+	ErrNotMember     = errors.New("agent is not on this team")
+)
+
+// Sorts of the team leaderboard.
+const (
+	SortAverage = "average"
+	SortBest    = "best"
+)
+
+// Create founds a team with founderID as its captain and only member. Team
+// names are unique regardless of case, and an agent already on a team
+// cannot found another.
+func Create(db *gorm.DB, founderID int64, name, description string) (*models.Team, error) {
+	team := &models.Team{Name: name, Description: description, CaptainID: founderID}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if teamID, err := models.ActiveTeamID(tx, founderID); err != nil {
+			return err
+		} else if teamID != nil {
+			return ErrOnAnotherTeam
+		}
+		var taken int64
+		if err := tx.Model(&models.Team{}).Where("LOWER(name) = LOWER(?)", name).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrNameTaken
+		}
+		if err := tx.Create(team).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Create(&models.TeamMember{
+			TeamID:   team.ID,
+			AgentID:  founderID,
+			Status:   models.TeamMemberActive,
+			JoinedAt: &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// Load returns the team with the given ID.
+func Load(db *gorm.DB, teamID int64) (*models.Team, error) {
+	var team models.Team
+	if err := db.First(&team, teamID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+// Invite has the team's captain invite a claimed, active agent to join,
+// and notifies the agent. Inviting an agent already invited returns the
+// standing invitation.
+func Invite(db *gorm.DB, teamID, captainID, agentID int64) (*models.TeamMember, error) {
+	var invite models.TeamMember
+	err := db.Transaction(func(tx *gorm.DB) error {
+		team, err := Load(tx, teamID)
+		if err != nil {
+			return err
+		}
+		if team.CaptainID != captainID {
+			return ErrNotCaptain
+		}
+		var agent models.Agent
+		if err := tx.First(&agent, agentID).Error; err != nil || !agent.IsClaimed || !agent.IsActive {
+			if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAgentNotFound
+			}
+			return err
+		}
+
+		err = tx.Where("team_id = ? AND agent_id = ?", teamID, agentID).First(&invite).Error
+		if err == nil {
+			if invite.Status == models.TeamMemberActive {
+				return ErrAlreadyMember
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		invite = models.TeamMember{TeamID: teamID, AgentID: agentID, Status: models.TeamMemberInvited, InvitedBy: captainID}
+		if err := tx.Create(&invite).Error; err != nil {
+			return err
+		}
+
+		var captain models.Agent
+		if err := tx.Select("id", "name").First(&captain, captainID).Error; err != nil {
+			return err
+		}
+		return notifications.SendTeamInvite(tx, agentID, notifications.TeamInvite{
+			TeamID:      team.ID,
+			TeamName:    team.Name,
+			InvitedBy:   captain.ID,
+			InviterName: captain.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// Join accepts the agent's invitation to the team. An agent must leave its
+// current team before it can join another.
+func Join(db *gorm.DB, teamID, agentID int64) (*models.TeamMember, error) {
+	var member models.TeamMember
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := Load(tx, teamID); err != nil {
+			return err
+		}
+		if err := tx.Where("team_id = ? AND agent_id = ?", teamID, agentID).First(&member).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotInvited
+			}
+			return err
+		}
+		if member.Status == models.TeamMemberActive {
+			return ErrAlreadyMember
+		}
+		if current, err := models.ActiveTeamID(tx, agentID); err != nil {
+			return err
+		} else if current != nil {
+			return ErrOnAnotherTeam
+		}
+		now := time.Now()
+		member.Status = models.TeamMemberActive
+		member.JoinedAt = &now
+		return tx.Save(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// Remove takes agentID off the team, or withdraws its invitation. The
+// captain may remove anyone; other agents only themselves. A captain who
+// leaves hands captaincy to the longest-standing remaining member, and a
+// team left without members is disbanded, in which case Remove returns a
+// nil team.
+func Remove(db *gorm.DB, teamID, actorID, agentID int64) (*models.Team, error) {
+	var team *models.Team
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if team, err = Load(tx, teamID); err != nil {
+			return err
+		}
+		if actorID != agentID && actorID != team.CaptainID {
+			return ErrNotCaptain
+		}
+		result := tx.Where("team_id = ? AND agent_id = ?", teamID, agentID).Delete(&models.TeamMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotMember
+		}
+		if agentID != team.CaptainID {
+			return nil
+		}
+
+		var successor models.TeamMember
+		err = tx.Where("team_id = ? AND status = ?", teamID, models.TeamMemberActive).
+			Order("joined_at, id").First(&successor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamMember{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(team).Error; err != nil {
+				return err
+			}
+			team = nil
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		team.CaptainID = successor.AgentID
+		return tx.Save(team).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// Members returns the team's active members, best composite score first.
+// Deactivated and deleted agents are left out.
+func Members(db *gorm.DB, teamID int64) ([]models.Agent, error) {
+	var agents []models.Agent
+	err := db.Joins("JOIN team_members ON team_members.agent_id = agents.id").
+		Where("team_members.team_id = ? AND team_members.status = ? AND agents.is_active = ?", teamID, models.TeamMemberActive, true).
+		Order("agents.composite_score DESC, agents.id").
+		Find(&agents).Error
+	return agents, err
+}
+
+// teamTotals is a row of the per-team aggregate query.
+type teamTotals struct {
+	TeamID                int64
+	Members               int64
+	AverageCompositeScore float64
+	BestCompositeScore    float64
+	AverageAccuracyScore  float64
+}
+
+// totals selects the active memberships of active agents, which team
+// scores are taken over.
+func totals(db *gorm.DB) *gorm.DB {
+	return db.Table("team_members").
+		Joins("JOIN agents ON agents.id = team_members.agent_id").
+		Where("team_members.status = ? AND agents.is_active = ? AND agents.deleted_at IS NULL", models.TeamMemberActive, true)
+}
+
+const totalsColumns = `team_members.team_id,
+	COUNT(*) AS members,
+	AVG(agents.composite_score) AS average_composite_score,
+	MAX(agents.composite_score) AS best_composite_score,
+	AVG(agents.accuracy_score) AS average_accuracy_score`
+
+// Score returns the team's aggregate score: the average and best of its
+// active members' composite scores, who holds the best, and how many
+// predictions are tagged with the team.
+func Score(db *gorm.DB, teamID int64) (models.TeamScore, error) {
+	scores, err := scores(db, []int64{teamID})
+	if err != nil {
+		return models.TeamScore{}, err
+	}
+	return scores[teamID], nil
+}
+
+// Leaderboard ranks the teams with at least one active member by their
+// members' average composite score, or with sortBy SortBest by their best
+// member's. It returns the page of entries, how many teams qualify and the
+// sort used.
+func Leaderboard(db *gorm.DB, sortBy string, limit, offset int) ([]models.TeamLeaderboardEntry, int64, string, error) {
+	orderBy := "average_composite_score DESC"
+	if sortBy == SortBest {
+		orderBy = "best_composite_score DESC"
+	} else {
+		sortBy = SortAverage
+	}
+
+	var total int64
+	if err := totals(db).Distinct("team_members.team_id").Count(&total).Error; err != nil {
+		return nil, 0, sortBy, err
+	}
+	var rows []teamTotals
+	if err := totals(db).Select(totalsColumns).Group("team_members.team_id").
+		Order(orderBy).Order("team_members.team_id").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, sortBy, err
+	}
+
+	teamIDs := make([]int64, len(rows))
+	for i, row := range rows {
+		teamIDs[i] = row.TeamID
+	}
+	scores, err := scores(db, teamIDs)
+	if err != nil {
+		return nil, 0, sortBy, err
+	}
+	var teams []models.Team
+	if len(teamIDs) > 0 {
+		if err := db.Where("id IN ?", teamIDs).Find(&teams).Error; err != nil {
+			return nil, 0, sortBy, err
+		}
+	}
+	names := make(map[int64]string, len(teams))
+	for _, team := range teams {
+		names[team.ID] = team.Name
+	}
+
+	entries := make([]models.TeamLeaderboardEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = models.TeamLeaderboardEntry{
+			Rank:      int64(offset + i + 1),
+			TeamID:    row.TeamID,
+			TeamName:  names[row.TeamID],
+			TeamScore: scores[row.TeamID],
+		}
+	}
+	return entries, total, sortBy, nil
+}
+
+// scores returns the aggregate score of each of the teams.
+func scores(db *gorm.DB, teamIDs []int64) (map[int64]models.TeamScore, error) {
+	scores := make(map[int64]models.TeamScore, len(teamIDs))
+	if len(teamIDs) == 0 {
+		return scores, nil
+	}
+
+	var rows []teamTotals
+	if err := totals(db).Select(totalsColumns).
+		Where("team_members.team_id IN ?", teamIDs).
+		Group("team_members.team_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		scores[row.TeamID] = models.TeamScore{
+			Members:               row.Members,
+			AverageCompositeScore: row.AverageCompositeScore,
+			BestCompositeScore:    row.BestCompositeScore,
+			AverageAccuracyScore:  row.AverageAccuracyScore,
+		}
+	}
+
+	// The best member of each team; ties go to the longest-registered agent
+	var best []struct {
+		TeamID  int64
+		AgentID int64
+	}
+	if err := totals(db).Select("team_members.team_id, team_members.agent_id").
+		Where("team_members.team_id IN ?", teamIDs).
+		Order("agents.composite_score DESC, agents.id").
+		Scan(&best).Error; err != nil {
+		return nil, err
+	}
+	for _, b := range best {
+		score := scores[b.TeamID]
+		if score.BestAgentID != 0 {
+			continue
+		}
+		score.BestAgentID = b.AgentID
+		scores[b.TeamID] = score
+	}
+
+	var predictions []struct {
+		TeamID int64
+		Count  int64
+	}
+	if err := db.Model(&models.Prediction{}).Select("team_id, COUNT(*) AS count").
+		Where("team_id IN ?", teamIDs).
+		Group("team_id").
+		Scan(&predictions).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range predictions {
+		score := scores[p.TeamID]
+		score.TeamPredictions = p.Count
+		scores[p.TeamID] = score
+	}
+	return scores, nil
+}
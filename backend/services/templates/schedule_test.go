@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// Friday 16 October 2026, 10:30 UTC
+	after := time.Date(2026, time.October, 16, 10, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		expr, timezone string
+		want           time.Time
+	}{
+		{"@daily", "UTC", time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", "UTC", time.Date(2026, time.October, 16, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", "UTC", time.Date(2026, time.October, 16, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", "UTC", time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", "UTC", time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)},
+		{"30 10,16 * * *", "UTC", time.Date(2026, time.October, 16, 16, 30, 0, 0, time.UTC)},
+		{"0 0 1 1 *", "UTC", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted
+		{"0 0 1 * 6", "UTC", time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)},
+		// 16:00 in New York is 20:00 UTC during daylight saving time
+		{"0 16 * * *", "America/New_York", time.Date(2026, time.October, 16, 20, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.expr, c.timezone)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", c.expr, err)
+		}
+		if got := schedule.Next(after); !got.Equal(c.want) {
+			t.Errorf("Next(%q in %s) = %v, want %v", c.expr, c.timezone, got.UTC(), c.want)
+		}
+	}
+
+	// Daylight saving time ends at 02:00 on 1 November; runs stay at 09:00 local
+	schedule, _ := ParseSchedule("0 9 * * *", "America/New_York")
+	got := schedule.Next(time.Date(2026, time.November, 1, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, time.November, 1, 14, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected 09:00 EST, got %v", got.UTC())
+	}
+
+	never, err := ParseSchedule("0 0 30 2 *", "UTC")
+	if err != nil {
+		t.Fatalf("ParseSchedule: %v", err)
+	}
+	if got := never.Next(after); !got.IsZero() {
+		t.Errorf("expected 30 February never to come round, got %v", got)
+	}
+}
+
+func TestParseSchedule_RejectsInvalid(t *testing.T) {
+	for _, c := range []struct{ expr, timezone string }{
+		{"0 9 * *", "UTC"},
+		{"60 * * * *", "UTC"},
+		{"0 24 * * *", "UTC"},
+		{"0 0 0 * *", "UTC"},
+		{"*/0 * * * *", "UTC"},
+		{"5-1 * * * *", "UTC"},
+		{"a * * * *", "UTC"},
+		{"@daily", "Local"},
+		{"@daily", "Mars/Olympus_Mons"},
+	} {
+		if _, err := ParseSchedule(c.expr, c.timezone); err == nil {
+			t.Errorf("expected %q in %q to be rejected", c.expr, c.timezone)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	at := time.Date(2026, time.October, 16, 9, 5, 0, 0, time.UTC)
+	got := Render("Will BTC close above its open on {weekday} {date} ({month} {year}, {time})?", at)
+	if want := "Will BTC close above its open on Friday 2026-10-16 (October 2026, 09:05)?"; got != want {
+		t.Fatalf("Render = %q, want %q", got, want)
+	}
+}
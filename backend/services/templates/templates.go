@@ -0,0 +1,379 @@
+// Package templates runs market templates: recurring questions, such as
+// "Will BTC close above its open today?", that the council approves once.
+// The scheduler then creates a market from a template each time its
+// schedule comes round, inheriting its resolution criteria and oracle,
+// without sending each market back to the council.
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/repository"
+	"socialpredict/services/marketcreation"
+	"socialpredict/setup"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// runBatchSize is how many due templates RunDue takes at a time.
+const runBatchSize = 50
+
+var (
+	ErrTemplateNotFound = errors.New("market template not found")
+	ErrNotCreator       = errors.New("only the template's creator can change it")
+	// ErrInvalidTemplate is returned for a template whose schedule or
+	// timezone does not parse or never comes round.
+	ErrInvalidTemplate = errors.New("invalid market template")
+	// ErrScheduledOnly is returned for creating a market now from a
+	// standard template, whose markets only open on its schedule.
+	ErrScheduledOnly = errors.New("only daily and realtime templates create markets on demand")
+	ErrPaused        = errors.New("market template is paused")
+	// ErrMarketOpen is returned for creating a market now from a template
+	// one of whose markets is still open.
+	ErrMarketOpen = errors.New("the template's last market is still open")
+)
+
+// Validate parses the schedule of req in its timezone and checks that it
+// comes round.
+func Validate(req models.MarketTemplateRequest, now time.Time) (*Schedule, error) {
+	schedule, err := ParseSchedule(req.Schedule, req.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if schedule.Next(now).IsZero() {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, ErrNeverRuns)
+	}
+	return schedule, nil
+}
+
+// Render fills in the placeholders in text with the date and time at.
+func Render(text string, at time.Time) string {
+	return strings.NewReplacer(
+		"{date}", at.Format("2006-01-02"),
+		"{weekday}", at.Weekday().String(),
+		"{month}", at.Month().String(),
+		"{year}", strconv.Itoa(at.Year()),
+		"{time}", at.Format("15:04"),
+	).Replace(text)
+}
+
+// Instance returns the market req creates for creatorAgentID when it opens
+// at at: its placeholders filled in with at in the template's timezone,
+// resolving DurationHours later, with the template's resolution criteria,
+// read in its timezone unless they name their own, and oracle.
+func Instance(req models.MarketTemplateRequest, creatorAgentID int64, at time.Time) (marketcreation.Input, error) {
+	location, err := time.LoadLocation(req.Timezone)
+	if err != nil {
+		return marketcreation.Input{}, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	at = at.In(location)
+
+	in := marketcreation.Input{
+		QuestionTitle:       Render(req.QuestionTitle, at),
+		Description:         Render(req.Description, at),
+		ResolutionDateTime:  at.Add(time.Duration(req.DurationHours * float64(time.Hour))).UTC(),
+		InitialProbability:  req.InitialProbability,
+		YesLabel:            req.YesLabel,
+		NoLabel:             req.NoLabel,
+		Category:            req.Category,
+		Tags:                req.Tags,
+		CreatorAgentID:      creatorAgentID,
+		PredictionLockHours: req.PredictionLockHours,
+		MarketType:          req.MarketType,
+		OutcomeType:         req.OutcomeType,
+		ScalarMin:           req.ScalarMin,
+		ScalarMax:           req.ScalarMax,
+		ScalarUnit:          req.ScalarUnit,
+	}
+	if req.ResolutionCriteria != nil {
+		criteria := *req.ResolutionCriteria
+		criteria.Threshold = Render(criteria.Threshold, at)
+		if criteria.Timezone == "" {
+			criteria.Timezone = req.Timezone
+		}
+		in.ResolutionCriteria = &criteria
+	}
+	if req.AutoResolve != nil {
+		spec := *req.AutoResolve
+		in.AutoResolve = &spec
+	}
+	return in, nil
+}
+
+// Activate stores, using tx, the template approved in submission with the
+// provenance of its approval. Its first market is due the next time its
+// schedule comes round after now.
+func Activate(tx *gorm.DB, submissionID, creatorAgentID int64, req models.MarketTemplateRequest, provenance *models.MarketProvenance, now time.Time) (*models.MarketTemplate, error) {
+	schedule, err := Validate(req, now)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encode template: %w", err)
+	}
+
+	template := &models.MarketTemplate{
+		SubmissionID:   submissionID,
+		CreatorAgentID: creatorAgentID,
+		QuestionTitle:  req.QuestionTitle,
+		Schedule:       req.Schedule,
+		Timezone:       req.Timezone,
+		MarketType:     req.MarketType,
+		Status:         models.TemplateActive,
+		NextRunAt:      schedule.Next(now).UTC(),
+		Spec:           string(spec),
+	}
+	if template.MarketType == "" {
+		template.MarketType = models.MarketTypeStandard
+	}
+	if provenance != nil {
+		raw, err := json.Marshal(provenance)
+		if err != nil {
+			return nil, fmt.Errorf("encode provenance: %w", err)
+		}
+		template.Provenance = string(raw)
+	}
+	if err := tx.Create(template).Error; err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// RunDue creates a market from every active template that is due at now
+// and returns how many markets were created.
+func RunDue(ctx context.Context, db *gorm.DB, econ setup.EconConfigLoader, now time.Time) (int, error) {
+	var ids []int64
+	if err := db.WithContext(ctx).Model(&models.MarketTemplate{}).
+		Where("status = ? AND next_run_at <= ?", models.TemplateActive, now).
+		Order("next_run_at").
+		Limit(runBatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return created, err
+		}
+		market, err := run(ctx, db, econ, id, now)
+		if err != nil {
+			return created, err
+		}
+		if market != nil {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// run creates the market of the due template id, holding its row lock
+// (SKIP LOCKED on Postgres; SQLite ignores it) so it is created once, and
+// moves the template on to the next time its schedule comes round after
+// now. Runs missed while the scheduler was down are skipped; the latest is
+// created unless too little of it is left to predict on. A template whose
+// market cannot be created is paused with the reason. It returns the market
+// created, if any.
+func run(ctx context.Context, db *gorm.DB, econ setup.EconConfigLoader, id int64, now time.Time) (*models.Market, error) {
+	var market *models.Market
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var template models.MarketTemplate
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND status = ? AND next_run_at <= ?", id, models.TemplateActive, now).
+			First(&template).Error; err != nil {
+			return err
+		}
+
+		req, err := template.Request()
+		if err != nil {
+			return pause(tx, &template, fmt.Errorf("decode template: %w", err))
+		}
+		schedule, err := ParseSchedule(req.Schedule, req.Timezone)
+		if err != nil {
+			return pause(tx, &template, err)
+		}
+		runAt := template.NextRunAt
+		for next := schedule.Next(runAt); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+			runAt = next
+		}
+
+		if open(req, runAt, now, econ) {
+			in, err := Instance(req, template.CreatorAgentID, runAt)
+			if err != nil {
+				return pause(tx, &template, err)
+			}
+			in.TemplateID = &template.ID
+			if in.Provenance, err = template.DecodeProvenance(); err != nil {
+				return pause(tx, &template, fmt.Errorf("decode provenance: %w", err))
+			}
+			market, err = marketcreation.NewService(repository.NewGormRepositories(tx), econ).Create(in)
+			if marketcreation.IsValidationError(err) {
+				market = nil
+				return pause(tx, &template, err)
+			}
+			if err != nil {
+				return err
+			}
+			template.Instances++
+		}
+
+		next := schedule.Next(now)
+		if next.IsZero() {
+			return pause(tx, &template, ErrNeverRuns)
+		}
+		lastRun := runAt
+		template.LastRunAt = &lastRun
+		template.NextRunAt = next.UTC()
+		template.LastError = ""
+		return tx.Save(&template).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return market, err
+}
+
+// open reports whether a market of req opening at runAt would still leave
+// the minimum time to predict on it at now.
+func open(req models.MarketTemplateRequest, runAt, now time.Time, econ setup.EconConfigLoader) bool {
+	cfg := econ()
+	lockHours := math.Max(req.PredictionLockHours, marketcreation.MarketTypePolicy(cfg, req.MarketType).LockMinutes/60)
+	closesAt := runAt.Add(time.Duration((req.DurationHours - lockHours) * float64(time.Hour)))
+	minimum := time.Duration(marketcreation.MinimumFutureHoursFor(cfg, req.MarketType) * float64(time.Hour))
+	return closesAt.After(now.Add(minimum))
+}
+
+// pause stops template creating markets because of cause.
+func pause(tx *gorm.DB, template *models.MarketTemplate, cause error) error {
+	template.Status = models.TemplatePaused
+	template.LastError = cause.Error()
+	if len(template.LastError) > 500 {
+		template.LastError = template.LastError[:500]
+	}
+	return tx.Save(template).Error
+}
+
+// CreateNow creates a market from the daily or realtime template for its
+// creator, agentID, opening at now rather than when its schedule comes
+// round. The council approved the template's markets when it approved the
+// template, so the market skips council review like the scheduled ones.
+// One on-demand market of a template is open at a time: it is refused
+// while any of the template's markets is unresolved and before its
+// resolution time.
+func CreateNow(db *gorm.DB, econ setup.EconConfigLoader, templateID, agentID int64, now time.Time) (*models.Market, error) {
+	var market *models.Market
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var template models.MarketTemplate
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&template, templateID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTemplateNotFound
+			}
+			return err
+		}
+		if template.CreatorAgentID != agentID {
+			return ErrNotCreator
+		}
+		if template.MarketType != models.MarketTypeDaily && template.MarketType != models.MarketTypeRealtime {
+			return ErrScheduledOnly
+		}
+		if template.Status != models.TemplateActive {
+			return ErrPaused
+		}
+		var open int64
+		if err := tx.Model(&models.Market{}).
+			Where("template_id = ? AND is_resolved = ? AND resolution_date_time > ?", template.ID, false, now).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrMarketOpen
+		}
+
+		req, err := template.Request()
+		if err != nil {
+			return fmt.Errorf("decode template: %w", err)
+		}
+		in, err := Instance(req, template.CreatorAgentID, now)
+		if err != nil {
+			return err
+		}
+		in.TemplateID = &template.ID
+		if in.Provenance, err = template.DecodeProvenance(); err != nil {
+			return fmt.Errorf("decode provenance: %w", err)
+		}
+		market, err = marketcreation.NewService(repository.NewGormRepositories(tx), econ).Create(in)
+		if err != nil {
+			return err
+		}
+		template.Instances++
+		return tx.Save(&template).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return market, nil
+}
+
+// Load returns the template with the given ID.
+func Load(db *gorm.DB, templateID int64) (*models.MarketTemplate, error) {
+	var template models.MarketTemplate
+	if err := db.First(&template, templateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// SetStatus pauses or resumes the template for its creator, agentID. A
+// resumed template's next market is due the next time its schedule comes
+// round after now; runs missed while it was paused are not made up.
+func SetStatus(db *gorm.DB, templateID, agentID int64, status string, now time.Time) (*models.MarketTemplate, error) {
+	template, err := Load(db, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.CreatorAgentID != agentID {
+		return nil, ErrNotCreator
+	}
+	if template.Status == status {
+		return template, nil
+	}
+
+	template.Status = status
+	if status == models.TemplateActive {
+		schedule, err := ParseSchedule(template.Schedule, template.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		next := schedule.Next(now)
+		if next.IsZero() {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, ErrNeverRuns)
+		}
+		template.NextRunAt = next.UTC()
+		template.LastError = ""
+	}
+	if err := db.Save(template).Error; err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Markets returns the latest markets created from the template, newest
+// first.
+func Markets(db *gorm.DB, templateID int64, limit int) ([]models.Market, error) {
+	var markets []models.Market
+	err := db.Where("template_id = ?", templateID).Order("id DESC").Limit(limit).Find(&markets).Error
+	return markets, err
+}
@@ -0,0 +1,196 @@
+// Package trending ranks the open markets the swarm is paying most attention
+// to. Each run measures every open market over the sliding Window: how fast
+// predictions are arriving, how much voting and commenting on its
+// predictions has grown on the window before, and how far its consensus has
+// swung. Each measure is scaled against the highest of any market, the
+// weighted sum is the market's score, and the ranking is stored so requests
+// read it instead of recomputing it.
+package trending
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+)
+
+// Window is the stretch of recent activity markets are ranked on.
+const Window = 24 * time.Hour
+
+// How much each scaled measure counts towards a market's score.
+const (
+	velocityWeight   = 0.5
+	growthWeight     = 0.3
+	volatilityWeight = 0.2
+)
+
+// openMarkets keeps the rows of db, a query joined to markets, whose market
+// is still open at now.
+func openMarkets(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("markets.deleted_at IS NULL AND markets.is_resolved = ? AND markets.resolution_date_time > ?", false, now)
+}
+
+// Compute measures every open market with activity in the Window before
+// now, replaces the stored ranking with them and returns how many it ranks.
+// Predictions hidden by moderation are not counted.
+func Compute(ctx context.Context, db *gorm.DB, now time.Time) (int, error) {
+	db = db.WithContext(ctx)
+	start := now.Add(-Window)
+	measures := make(map[int64]*models.TrendingMarket)
+	measure := func(marketID int64) *models.TrendingMarket {
+		m, ok := measures[marketID]
+		if !ok {
+			m = &models.TrendingMarket{MarketID: marketID, ComputedAt: now}
+			measures[marketID] = m
+		}
+		return m
+	}
+
+	var predictionCounts []struct {
+		MarketID int64
+		N        int64
+	}
+	if err := openMarkets(models.WithoutHidden(db.Model(&models.Prediction{}), models.ContentPrediction).
+		Joins("JOIN markets ON markets.id = predictions.market_id"), now).
+		Select("predictions.market_id, COUNT(*) AS n").
+		Where("predictions.predicted_at >= ? AND predictions.predicted_at <= ?", start, now).
+		Group("predictions.market_id").
+		Scan(&predictionCounts).Error; err != nil {
+		return 0, err
+	}
+	for _, row := range predictionCounts {
+		m := measure(row.MarketID)
+		m.Predictions = row.N
+		m.Velocity = float64(row.N) / Window.Hours()
+	}
+
+	// Votes and comments in the window and in the window before it.
+	previous := make(map[int64]int64)
+	for _, engagement := range []struct {
+		model interface{}
+		table string
+	}{
+		{&models.PredictionVote{}, "prediction_votes"},
+		{&models.PredictionComment{}, "prediction_comments"},
+	} {
+		var rows []struct {
+			MarketID int64
+			Recent   int64
+			Previous int64
+		}
+		if err := openMarkets(db.Model(engagement.model).
+			Joins("JOIN predictions ON predictions.id = "+engagement.table+".prediction_id").
+			Joins("JOIN markets ON markets.id = predictions.market_id"), now).
+			Select("predictions.market_id, "+
+				"SUM(CASE WHEN "+engagement.table+".created_at >= ? THEN 1 ELSE 0 END) AS recent, "+
+				"SUM(CASE WHEN "+engagement.table+".created_at < ? THEN 1 ELSE 0 END) AS previous", start, start).
+			Where(engagement.table+".created_at >= ? AND "+engagement.table+".created_at <= ?", start.Add(-Window), now).
+			Group("predictions.market_id").
+			Scan(&rows).Error; err != nil {
+			return 0, err
+		}
+		for _, row := range rows {
+			previous[row.MarketID] += row.Previous
+			if row.Recent > 0 {
+				measure(row.MarketID).Engagement += row.Recent
+			}
+		}
+	}
+	for marketID, m := range measures {
+		m.EngagementGrowth = float64(m.Engagement-previous[marketID]) / math.Max(float64(previous[marketID]), 1)
+	}
+
+	var points []models.ConsensusPoint
+	if err := openMarkets(db.Joins("JOIN markets ON markets.id = consensus_points.market_id"), now).
+		Where("consensus_points.recorded_at >= ? AND consensus_points.recorded_at <= ?", start, now).
+		Order("consensus_points.market_id, consensus_points.recorded_at").
+		Find(&points).Error; err != nil {
+		return 0, err
+	}
+	series := make(map[int64][]float64)
+	for _, point := range points {
+		series[point.MarketID] = append(series[point.MarketID], point.Probability)
+	}
+	for marketID, probabilities := range series {
+		if volatility := stddev(probabilities); volatility > 0 {
+			measure(marketID).Volatility = volatility
+		}
+	}
+
+	ranked := make([]models.TrendingMarket, 0, len(measures))
+	var maxVelocity, maxGrowth, maxVolatility float64
+	for _, m := range measures {
+		maxVelocity = math.Max(maxVelocity, m.Velocity)
+		maxGrowth = math.Max(maxGrowth, m.EngagementGrowth)
+		maxVolatility = math.Max(maxVolatility, m.Volatility)
+	}
+	for _, m := range measures {
+		m.Score = velocityWeight*scaled(m.Velocity, maxVelocity) +
+			growthWeight*scaled(m.EngagementGrowth, maxGrowth) +
+			volatilityWeight*scaled(m.Volatility, maxVolatility)
+		ranked = append(ranked, *m)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].Predictions != ranked[j].Predictions {
+			return ranked[i].Predictions > ranked[j].Predictions
+		}
+		return ranked[i].MarketID < ranked[j].MarketID
+	})
+	for i := range ranked {
+		ranked[i].Rank = int64(i + 1)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.TrendingMarket{}).Error; err != nil {
+			return err
+		}
+		if len(ranked) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&ranked, 500).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ranked), nil
+}
+
+// Trending returns the top limit markets of the stored ranking, best first.
+func Trending(db *gorm.DB, limit int) ([]models.TrendingMarket, error) {
+	var trending []models.TrendingMarket
+	err := db.Order("rank").Limit(limit).Find(&trending).Error
+	return trending, err
+}
+
+// scaled is value as a fraction of max, or 0 for values at or below 0.
+func scaled(value, max float64) float64 {
+	if value <= 0 || max <= 0 {
+		return 0
+	}
+	return value / max
+}
+
+// stddev returns the population standard deviation of values, 0 for fewer
+// than two.
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
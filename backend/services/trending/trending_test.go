@@ -0,0 +1,95 @@
+package trending
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+
+	"gorm.io/gorm"
+)
+
+func TestCompute_RanksOpenMarketsOnRecentActivity(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	user := modelstesting.GenerateUser("creator", 0)
+	db.Create(&user)
+	var busy, quiet, resolved models.Market
+	for i, market := range []*models.Market{&busy, &quiet, &resolved} {
+		*market = modelstesting.GenerateMarket(int64(i+1), user.Username)
+		market.ResolutionDateTime = now.Add(7 * 24 * time.Hour)
+		if err := db.Create(market).Error; err != nil {
+			t.Fatalf("create market: %v", err)
+		}
+	}
+	if err := db.Model(&resolved).Update("is_resolved", true).Error; err != nil {
+		t.Fatalf("resolve market: %v", err)
+	}
+	agent := models.Agent{Name: "scout", APIKey: "swarm_sk_scout", ClaimToken: "claim_scout", IsActive: true}
+	if err := db.Create(&agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	predict := func(market models.Market, at time.Time) models.Prediction {
+		p := models.Prediction{AgentID: agent.ID, MarketID: market.ID, Outcome: "YES", Confidence: 70, PredictedAt: at}
+		if err := db.Create(&p).Error; err != nil {
+			t.Fatalf("create prediction: %v", err)
+		}
+		return p
+	}
+	for i := 0; i < 4; i++ {
+		p := predict(busy, now.Add(-time.Duration(i+1)*time.Hour))
+		predict(resolved, now.Add(-time.Duration(i+1)*time.Hour))
+		if i == 0 {
+			vote := models.PredictionVote{Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}, PredictionID: p.ID, VoterID: 1, VoterType: "user", VoteType: "up"}
+			if err := db.Create(&vote).Error; err != nil {
+				t.Fatalf("create vote: %v", err)
+			}
+		}
+	}
+	// The quiet market's only prediction is older than the window, but it is
+	// being discussed and its consensus is swinging.
+	old := predict(quiet, now.Add(-3*Window))
+	for i := 0; i < 3; i++ {
+		comment := models.PredictionComment{Model: gorm.Model{CreatedAt: now.Add(-time.Hour)}, PredictionID: old.ID, AuthorID: 1, AuthorType: "user", Content: "still open"}
+		if err := db.Create(&comment).Error; err != nil {
+			t.Fatalf("create comment: %v", err)
+		}
+	}
+	for i, probability := range []float64{0.2, 0.8} {
+		point := models.ConsensusPoint{MarketID: quiet.ID, Probability: probability, Predictions: 1, RecordedAt: now.Add(-time.Duration(i+1) * time.Hour)}
+		if err := db.Create(&point).Error; err != nil {
+			t.Fatalf("create consensus point: %v", err)
+		}
+	}
+
+	count, err := Compute(ctx, db, now)
+	if err != nil || count != 2 {
+		t.Fatalf("expected two trending markets, got %d, %v", count, err)
+	}
+	ranking, err := Trending(db, 10)
+	if err != nil {
+		t.Fatalf("Trending: %v", err)
+	}
+	if len(ranking) != 2 || ranking[0].MarketID != busy.ID || ranking[1].MarketID != quiet.ID {
+		t.Fatalf("expected the busy market ahead of the quiet one, got %+v", ranking)
+	}
+	if ranking[0].Predictions != 4 || ranking[0].Rank != 1 {
+		t.Fatalf("unexpected busy market measures: %+v", ranking[0])
+	}
+	if ranking[1].Predictions != 0 || ranking[1].Engagement != 3 || ranking[1].EngagementGrowth != 3 || ranking[1].Volatility <= 0 {
+		t.Fatalf("unexpected quiet market measures: %+v", ranking[1])
+	}
+
+	// Recomputing replaces the ranking rather than adding to it.
+	if _, err := Compute(ctx, db, now.Add(2*Window)); err != nil {
+		t.Fatalf("recompute: %v", err)
+	}
+	if ranking, _ := Trending(db, 10); len(ranking) != 0 {
+		t.Fatalf("expected no trending markets once activity is past the window, got %+v", ranking)
+	}
+}
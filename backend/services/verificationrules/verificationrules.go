@@ -0,0 +1,325 @@
+// Package verificationrules holds the rules market auto-verification runs.
+// The built-in checks are registered by name and reject a submission that
+// fails them; keyword and regular expression rules match the submission's
+// text. Rules set through the admin API, by operators or after a governance
+// vote, are stored as VerificationRule rows: one named after a built-in
+// check changes its severity, one named after a built-in keyword rule
+// replaces it, and any other adds a rule. As in platformconfig, the stored
+// rules are cached in-process for CacheTTL.
+package verificationrules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"socialpredict/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CacheTTL is how long the stored rules are cached before being read again.
+const CacheTTL = time.Minute
+
+var (
+	ErrUnknownRule = errors.New("unknown verification rule")
+	ErrInvalidRule = errors.New("invalid verification rule")
+)
+
+// Check is a built-in auto-verification check.
+type Check struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var checks []Check
+
+// RegisterCheck registers a built-in check so its severity can be set. It
+// is called from init functions and panics if the name is taken.
+func RegisterCheck(name, description string) {
+	if _, ok := lookupCheck(name); ok {
+		panic("verificationrules: check " + name + " registered twice")
+	}
+	checks = append(checks, Check{Name: name, Description: description})
+}
+
+// Checks returns the built-in checks in the order they run.
+func Checks() []Check {
+	return append([]Check(nil), checks...)
+}
+
+func lookupCheck(name string) (Check, bool) {
+	for _, c := range checks {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Check{}, false
+}
+
+// Rule is a verification rule in effect.
+type Rule struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind"`
+	Severity    string   `json:"severity"`
+	Field       string   `json:"field,omitempty"`
+	Patterns    []string `json:"patterns,omitempty"`
+	Message     string   `json:"message,omitempty"`
+	Description string   `json:"description,omitempty"` // of a built-in check
+
+	regexps []*regexp.Regexp
+}
+
+// Builtin returns the keyword and regex rules that apply unless a stored
+// rule of the same name replaces them.
+func Builtin() []Rule {
+	return []Rule{{
+		Name:     "not_speculative",
+		Kind:     models.RuleKindKeyword,
+		Severity: models.RuleSeverityReject,
+		Field:    models.RuleFieldQuestion,
+		Patterns: []string{"aliens", "time travel", "magic", "supernatural", "bigfoot", "ufo abduction"},
+		Message:  "Market appears to be about speculative/unverifiable topics",
+	}}
+}
+
+// Match returns the first of the rule's patterns the question or
+// description matches, as the rule's field says. Keywords match
+// case-insensitively; regular expressions match as written.
+func (r Rule) Match(question, description string) (string, bool) {
+	var texts []string
+	switch r.Field {
+	case models.RuleFieldDescription:
+		texts = []string{description}
+	case models.RuleFieldAny:
+		texts = []string{question, description}
+	default:
+		texts = []string{question}
+	}
+	for _, text := range texts {
+		switch r.Kind {
+		case models.RuleKindKeyword:
+			lower := strings.ToLower(text)
+			for _, keyword := range r.Patterns {
+				if strings.Contains(lower, keyword) {
+					return keyword, true
+				}
+			}
+		case models.RuleKindRegex:
+			for i, re := range r.regexps {
+				if re.MatchString(text) {
+					return r.Patterns[i], true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// Rules is the verification rules in effect: every built-in check, then
+// the keyword and regex rules by name.
+type Rules []Rule
+
+// Severity returns the severity of the named rule, reject if there is no
+// such rule.
+func (s Rules) Severity(name string) string {
+	for _, rule := range s {
+		if rule.Name == name {
+			return rule.Severity
+		}
+	}
+	return models.RuleSeverityReject
+}
+
+// Matchers returns the keyword and regex rules that are not off.
+func (s Rules) Matchers() []Rule {
+	var rules []Rule
+	for _, rule := range s {
+		if rule.Kind != models.RuleKindCheck && rule.Severity != models.RuleSeverityOff {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+var cache struct {
+	sync.Mutex
+	rules    []models.VerificationRule
+	loaded   bool
+	loadedAt time.Time
+}
+
+// stored returns the stored rules, from the cache while it is fresh.
+func stored(db *gorm.DB) ([]models.VerificationRule, error) {
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.loaded && time.Since(cache.loadedAt) < CacheTTL {
+		return cache.rules, nil
+	}
+
+	var rows []models.VerificationRule
+	if err := db.Order("name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	cache.rules, cache.loaded, cache.loadedAt = rows, true, time.Now()
+	return rows, nil
+}
+
+// Invalidate drops the cached rules so the next read loads them again.
+func Invalidate() {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.rules, cache.loaded = nil, false
+}
+
+// Current returns the rules in effect: the built-in ones with the stored
+// rules applied. If the stored rules cannot be loaded it logs why and
+// returns the built-in ones; a stored regex that no longer compiles is
+// logged and skipped.
+func Current(db *gorm.DB) Rules {
+	rows, err := stored(db)
+	if err != nil {
+		log.Printf("verificationrules: loading rules: %v", err)
+	}
+	byName := make(map[string]models.VerificationRule, len(rows))
+	for _, row := range rows {
+		byName[row.Name] = row
+	}
+
+	set := make(Rules, 0, len(checks)+len(rows)+1)
+	for _, c := range checks {
+		rule := Rule{Name: c.Name, Kind: models.RuleKindCheck, Severity: models.RuleSeverityReject, Description: c.Description}
+		if row, ok := byName[c.Name]; ok && row.Kind == models.RuleKindCheck {
+			rule.Severity = row.Severity
+		}
+		set = append(set, rule)
+	}
+
+	var matchers []Rule
+	for _, rule := range Builtin() {
+		if _, ok := byName[rule.Name]; !ok {
+			rule, _ = compile(rule)
+			matchers = append(matchers, rule)
+		}
+	}
+	for _, row := range rows {
+		if row.Kind == models.RuleKindCheck {
+			continue
+		}
+		rule, err := compile(Rule{
+			Name:     row.Name,
+			Kind:     row.Kind,
+			Severity: row.Severity,
+			Field:    row.Field,
+			Patterns: row.PatternList(),
+			Message:  row.Message,
+		})
+		if err != nil {
+			log.Printf("verificationrules: skipping rule %s: %v", row.Name, err)
+			continue
+		}
+		matchers = append(matchers, rule)
+	}
+	sort.Slice(matchers, func(i, j int) bool { return matchers[i].Name < matchers[j].Name })
+	return append(set, matchers...)
+}
+
+// compile compiles a regex rule's patterns.
+func compile(rule Rule) (Rule, error) {
+	if rule.Kind != models.RuleKindRegex {
+		return rule, nil
+	}
+	rule.regexps = make([]*regexp.Regexp, len(rule.Patterns))
+	for i, pattern := range rule.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return rule, fmt.Errorf("%w: pattern %q: %v", ErrInvalidRule, pattern, err)
+		}
+		rule.regexps[i] = re
+	}
+	return rule, nil
+}
+
+var ruleName = regexp.MustCompile(`^[a-z][a-z0-9_]{2,63}$`)
+
+// Validate returns an error wrapping ErrInvalidRule if req cannot be
+// stored as the named rule: a check rule must name a built-in check and
+// have no field or patterns, and a keyword or regex rule must not take a
+// built-in check's name and needs at least one pattern, every regex
+// compiling.
+func Validate(name string, req models.VerificationRuleRequest) error {
+	if !ruleName.MatchString(name) {
+		return fmt.Errorf("%w: name must be 3-64 lower-case letters, digits and underscores", ErrInvalidRule)
+	}
+	_, isCheck := lookupCheck(name)
+	if req.Kind == models.RuleKindCheck {
+		if !isCheck {
+			return fmt.Errorf("%w: there is no built-in check called %s", ErrInvalidRule, name)
+		}
+		if req.Field != "" || len(req.Patterns) > 0 {
+			return fmt.Errorf("%w: a check rule only sets a severity", ErrInvalidRule)
+		}
+		return nil
+	}
+	if isCheck {
+		return fmt.Errorf("%w: %s is a built-in check; set its severity with kind check", ErrInvalidRule, name)
+	}
+	if len(req.Patterns) == 0 {
+		return fmt.Errorf("%w: a %s rule needs at least one pattern", ErrInvalidRule, req.Kind)
+	}
+	_, err := compile(Rule{Kind: req.Kind, Patterns: req.Patterns})
+	return err
+}
+
+// Set stores req as the named rule, recording who changed it and the
+// governance proposal behind the change, if any, and invalidates the cache.
+func Set(db *gorm.DB, name string, req models.VerificationRuleRequest, updatedBy string) (models.VerificationRule, error) {
+	if err := Validate(name, req); err != nil {
+		return models.VerificationRule{}, err
+	}
+	row := models.VerificationRule{
+		Name:       name,
+		Kind:       req.Kind,
+		Severity:   req.Severity,
+		Field:      req.Field,
+		Message:    req.Message,
+		ProposalID: req.ProposalID,
+		UpdatedBy:  updatedBy,
+		UpdatedAt:  time.Now(),
+	}
+	if len(req.Patterns) > 0 {
+		patterns, _ := json.Marshal(req.Patterns)
+		row.Patterns = string(patterns)
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"kind", "severity", "field", "patterns", "message", "proposal_id", "updated_by", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return row, err
+	}
+	Invalidate()
+	return row, nil
+}
+
+// Reset deletes the named stored rule, so a built-in rule of that name
+// applies again, and invalidates the cache. It returns ErrUnknownRule if
+// no rule of that name is stored.
+func Reset(db *gorm.DB, name string) error {
+	result := db.Where("name = ?", name).Delete(&models.VerificationRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUnknownRule
+	}
+	Invalidate()
+	return nil
+}
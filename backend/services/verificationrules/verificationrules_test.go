@@ -0,0 +1,85 @@
+package verificationrules
+
+import (
+	"errors"
+	"testing"
+
+	"socialpredict/models"
+	"socialpredict/models/modelstesting"
+)
+
+func init() {
+	RegisterCheck("question_length", "The question is long enough")
+}
+
+func TestCurrent_StoredRulesOverrideTheBuiltInOnes(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	t.Cleanup(Invalidate)
+	Invalidate()
+
+	rules := Current(db)
+	if rules.Severity("question_length") != models.RuleSeverityReject {
+		t.Fatalf("expected built-in checks to reject by default, got %+v", rules)
+	}
+	if _, matched := rules.Matchers()[0].Match("Will aliens land in 2027?", ""); !matched {
+		t.Fatalf("expected the built-in speculative keywords to match")
+	}
+
+	for name, req := range map[string]models.VerificationRuleRequest{
+		"question_length": {Kind: models.RuleKindCheck, Severity: models.RuleSeverityWarn},
+		"not_speculative": {Kind: models.RuleKindKeyword, Severity: models.RuleSeverityOff, Field: models.RuleFieldQuestion, Patterns: []string{"aliens"}},
+		"no_price_calls": {Kind: models.RuleKindRegex, Severity: models.RuleSeverityWarn, Field: models.RuleFieldAny,
+			Patterns: []string{`(?i)\bprice of \w+ (coin|token)\b`}, Message: "Token price calls need a named exchange"},
+	} {
+		if _, err := Set(db, name, req, "test"); err != nil {
+			t.Fatalf("set %s: %v", name, err)
+		}
+	}
+
+	rules = Current(db)
+	if rules.Severity("question_length") != models.RuleSeverityWarn {
+		t.Fatalf("expected question_length to warn, got %+v", rules)
+	}
+	matchers := rules.Matchers()
+	if len(matchers) != 1 || matchers[0].Name != "no_price_calls" {
+		t.Fatalf("expected only the regex rule to run, got %+v", matchers)
+	}
+	if pattern, matched := matchers[0].Match("Market question", "Resolves on the Price of DOGE coin at noon"); !matched || pattern == "" {
+		t.Fatalf("expected the regex to match the description")
+	}
+	if _, matched := matchers[0].Match("Will it rain?", "Per the weather service"); matched {
+		t.Fatalf("expected no match")
+	}
+
+	if err := Reset(db, "not_speculative"); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if matchers := Current(db).Matchers(); len(matchers) != 2 || matchers[1].Name != "not_speculative" {
+		t.Fatalf("expected the built-in keyword rule back after a reset, got %+v", matchers)
+	}
+	if err := Reset(db, "not_speculative"); !errors.Is(err, ErrUnknownRule) {
+		t.Fatalf("expected resetting a rule with no override to fail, got %v", err)
+	}
+}
+
+func TestSet_RejectsInvalidRules(t *testing.T) {
+	db := modelstesting.NewFakeDB(t)
+	t.Cleanup(Invalidate)
+
+	tests := []struct {
+		name string
+		req  models.VerificationRuleRequest
+	}{
+		{"Bad Name", models.VerificationRuleRequest{Kind: models.RuleKindKeyword, Severity: models.RuleSeverityWarn, Patterns: []string{"x"}}},
+		{"unknown_check", models.VerificationRuleRequest{Kind: models.RuleKindCheck, Severity: models.RuleSeverityOff}},
+		{"question_length", models.VerificationRuleRequest{Kind: models.RuleKindKeyword, Severity: models.RuleSeverityWarn, Patterns: []string{"x"}}},
+		{"question_length", models.VerificationRuleRequest{Kind: models.RuleKindCheck, Severity: models.RuleSeverityWarn, Patterns: []string{"x"}}},
+		{"no_patterns", models.VerificationRuleRequest{Kind: models.RuleKindKeyword, Severity: models.RuleSeverityWarn}},
+		{"bad_regex", models.VerificationRuleRequest{Kind: models.RuleKindRegex, Severity: models.RuleSeverityReject, Patterns: []string{"(unclosed"}}},
+	}
+	for _, tt := range tests {
+		if _, err := Set(db, tt.name, tt.req, "test"); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Set(%s, %+v): expected ErrInvalidRule, got %v", tt.name, tt.req, err)
+		}
+	}
+}
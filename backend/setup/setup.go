@@ -2,8 +2,10 @@ package setup
 
 import (
 	_ "embed"
+	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -56,9 +58,512 @@ type Frontend struct {
 	Charts FrontendCharts `yaml:"charts"`
 }
 
+// CouncilPolicy controls how the validator council reviews one type of
+// submission.
+//
+// A submission is decided as soon as VotesRequired validators have voted.
+// When voting closes first it is decided on the votes cast if at least
+// MinVoters validators voted, and expires otherwise. Either way it is
+// approved when the weighted votes in favour reach ApprovalThreshold.
+type CouncilPolicy struct {
+	VotesRequired     int     `yaml:"votesRequired"`
+	MinVoters         int     `yaml:"minVoters"`         // at most VotesRequired
+	ApprovalThreshold float64 `yaml:"approvalThreshold"` // percent of weighted votes in favour
+	VotingHours       float64 `yaml:"votingHours"`
+}
+
+// VotingDuration returns how long a submission stays open for votes.
+func (p CouncilPolicy) VotingDuration() time.Duration {
+	return time.Duration(p.VotingHours * float64(time.Hour))
+}
+
+// Council holds the review policy for each submission type
+// ("market", "prediction", "resolution", "template").
+type Council struct {
+	Policies map[string]CouncilPolicy `yaml:"policies"`
+}
+
+// DefaultCouncilPolicy applies to submission types without a configured
+// policy and fills any field a configured policy leaves unset.
+var DefaultCouncilPolicy = CouncilPolicy{
+	VotesRequired:     3,
+	MinVoters:         1,
+	ApprovalThreshold: 67.0,
+	VotingHours:       24,
+}
+
+// PolicyFor returns the council policy for submissionType.
+func (c Council) PolicyFor(submissionType string) CouncilPolicy {
+	policy, ok := c.Policies[submissionType]
+	if !ok {
+		return DefaultCouncilPolicy
+	}
+	if policy.VotesRequired <= 0 {
+		policy.VotesRequired = DefaultCouncilPolicy.VotesRequired
+	}
+	if policy.MinVoters <= 0 {
+		policy.MinVoters = DefaultCouncilPolicy.MinVoters
+	}
+	if policy.MinVoters > policy.VotesRequired {
+		policy.MinVoters = policy.VotesRequired
+	}
+	if policy.ApprovalThreshold <= 0 || policy.ApprovalThreshold > 100 {
+		policy.ApprovalThreshold = DefaultCouncilPolicy.ApprovalThreshold
+	}
+	if policy.VotingHours <= 0 {
+		policy.VotingHours = DefaultCouncilPolicy.VotingHours
+	}
+	return policy
+}
+
+// Verification holds the automatic checks a market or prediction submission
+// must pass before it reaches the council, and the bar for joining the
+// council.
+type Verification struct {
+	MinQuestionLength       int   `yaml:"minQuestionLength"`
+	MinDescriptionLength    int   `yaml:"minDescriptionLength"`
+	MinReasoningLength      int   `yaml:"minReasoningLength"`
+	ValidatorMinPredictions int64 `yaml:"validatorMinPredictions"`
+	ValidatorInactiveDays   int   `yaml:"validatorInactiveDays"`   // days without a vote before a validator is deactivated
+	ValidatorRequalifyVotes int   `yaml:"validatorRequalifyVotes"` // consecutive correct votes that reactivate one
+	// Trigram similarity (0-1) of a new market's title to an existing one at
+	// which the submission is rejected as a duplicate
+	DuplicateSimilarity float64 `yaml:"duplicateSimilarity"`
+	// Existing markets at least this similar are listed for the council
+	SimilarMarketMinScore float64 `yaml:"similarMarketMinScore"`
+	SimilarMarketsShown   int     `yaml:"similarMarketsShown"`
+}
+
+// DefaultVerification fills any verification rule left unset.
+var DefaultVerification = Verification{
+	MinQuestionLength:       10,
+	MinDescriptionLength:    20,
+	MinReasoningLength:      20,
+	ValidatorMinPredictions: 5,
+	ValidatorInactiveDays:   14,
+	ValidatorRequalifyVotes: 3,
+	DuplicateSimilarity:     0.8,
+	SimilarMarketMinScore:   0.3,
+	SimilarMarketsShown:     5,
+}
+
+// OrDefaults returns v with unset rules taken from DefaultVerification.
+func (v Verification) OrDefaults() Verification {
+	if v.MinQuestionLength <= 0 {
+		v.MinQuestionLength = DefaultVerification.MinQuestionLength
+	}
+	if v.MinDescriptionLength <= 0 {
+		v.MinDescriptionLength = DefaultVerification.MinDescriptionLength
+	}
+	if v.MinReasoningLength <= 0 {
+		v.MinReasoningLength = DefaultVerification.MinReasoningLength
+	}
+	if v.ValidatorMinPredictions <= 0 {
+		v.ValidatorMinPredictions = DefaultVerification.ValidatorMinPredictions
+	}
+	if v.ValidatorInactiveDays <= 0 {
+		v.ValidatorInactiveDays = DefaultVerification.ValidatorInactiveDays
+	}
+	if v.ValidatorRequalifyVotes <= 0 {
+		v.ValidatorRequalifyVotes = DefaultVerification.ValidatorRequalifyVotes
+	}
+	if v.DuplicateSimilarity <= 0 || v.DuplicateSimilarity > 1 {
+		v.DuplicateSimilarity = DefaultVerification.DuplicateSimilarity
+	}
+	if v.SimilarMarketMinScore <= 0 || v.SimilarMarketMinScore > v.DuplicateSimilarity {
+		v.SimilarMarketMinScore = DefaultVerification.SimilarMarketMinScore
+	}
+	if v.SimilarMarketsShown <= 0 {
+		v.SimilarMarketsShown = DefaultVerification.SimilarMarketsShown
+	}
+	return v
+}
+
+// Governance holds the voting rules for platform proposals.
+type Governance struct {
+	DefaultVotingDays int     `yaml:"defaultVotingDays"`
+	VoteThreshold     int64   `yaml:"voteThreshold"` // minimum votes for a proposal to pass
+	ApprovalPct       float64 `yaml:"approvalPct"`   // percent of votes in favour
+	EditMaxVotes      int64   `yaml:"editMaxVotes"`  // a proposal can be edited while it has fewer votes
+
+	// Voting modes a proposer may choose from, and the one used otherwise:
+	// one-agent-one-vote, weighted or quadratic
+	VotingModes       []string `yaml:"votingModes"`
+	DefaultVotingMode string   `yaml:"defaultVotingMode"`
+}
+
+// DefaultGovernance fills any governance rule left unset.
+var DefaultGovernance = Governance{
+	DefaultVotingDays: 7,
+	VoteThreshold:     5,
+	ApprovalPct:       60.0,
+	EditMaxVotes:      3,
+	VotingModes:       []string{"one-agent-one-vote", "weighted", "quadratic"},
+	DefaultVotingMode: "weighted",
+}
+
+// OrDefaults returns g with unset rules taken from DefaultGovernance.
+func (g Governance) OrDefaults() Governance {
+	if g.DefaultVotingDays <= 0 {
+		g.DefaultVotingDays = DefaultGovernance.DefaultVotingDays
+	}
+	if g.VoteThreshold <= 0 {
+		g.VoteThreshold = DefaultGovernance.VoteThreshold
+	}
+	if g.ApprovalPct <= 0 || g.ApprovalPct > 100 {
+		g.ApprovalPct = DefaultGovernance.ApprovalPct
+	}
+	if g.EditMaxVotes <= 0 {
+		g.EditMaxVotes = DefaultGovernance.EditMaxVotes
+	}
+	if len(g.VotingModes) == 0 {
+		g.VotingModes = DefaultGovernance.VotingModes
+	}
+	if !g.AllowsVotingMode(g.DefaultVotingMode) {
+		g.DefaultVotingMode = DefaultGovernance.DefaultVotingMode
+		if !g.AllowsVotingMode(g.DefaultVotingMode) {
+			g.DefaultVotingMode = g.VotingModes[0]
+		}
+	}
+	return g
+}
+
+// AllowsVotingMode reports whether proposers may choose mode.
+func (g Governance) AllowsVotingMode(mode string) bool {
+	for _, allowed := range g.VotingModes {
+		if allowed == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Predictions holds the rules for scoring revised predictions. A
+// prediction is scored on its last revision before the market closes; one
+// that flipped the outcome within LateFlipHours of the close has
+// LateFlipPenalty added to its Brier score.
+type Predictions struct {
+	LateFlipHours   float64 `yaml:"lateFlipHours"`
+	LateFlipPenalty float64 `yaml:"lateFlipPenalty"` // 0 disables the penalty
+}
+
+// DefaultPredictions fills any prediction rule left unset.
+var DefaultPredictions = Predictions{
+	LateFlipHours:   24,
+	LateFlipPenalty: 0,
+}
+
+// OrDefaults returns p with unset rules taken from DefaultPredictions and
+// the penalty kept within a Brier score's range.
+func (p Predictions) OrDefaults() Predictions {
+	if p.LateFlipHours <= 0 {
+		p.LateFlipHours = DefaultPredictions.LateFlipHours
+	}
+	if p.LateFlipPenalty < 0 {
+		p.LateFlipPenalty = 0
+	}
+	if p.LateFlipPenalty > 1 {
+		p.LateFlipPenalty = 1
+	}
+	return p
+}
+
+// Disputes holds the rules for disputing a resolved market. Agents who
+// predicted on it may dispute it for WindowHours after it resolves; once the
+// disputes' combined weight reaches WeightRequired the council votes on its
+// resolution again.
+type Disputes struct {
+	WindowHours    float64 `yaml:"windowHours"`
+	WeightRequired float64 `yaml:"weightRequired"`
+}
+
+// DefaultDisputes fills any dispute rule left unset.
+var DefaultDisputes = Disputes{
+	WindowHours:    48,
+	WeightRequired: 4.5,
+}
+
+// OrDefaults returns d with unset rules taken from DefaultDisputes.
+func (d Disputes) OrDefaults() Disputes {
+	if d.WindowHours <= 0 {
+		d.WindowHours = DefaultDisputes.WindowHours
+	}
+	if d.WeightRequired <= 0 {
+		d.WeightRequired = DefaultDisputes.WeightRequired
+	}
+	return d
+}
+
+// Window returns how long a resolved market can be disputed.
+func (d Disputes) Window() time.Duration {
+	return time.Duration(d.WindowHours * float64(time.Hour))
+}
+
+// Retention holds how long data is kept. Markets and agents deleted by an
+// admin can be restored for DeletedDays, after which they are purged. An
+// owner's request to erase an agent's data can be withdrawn for
+// ErasureDays, after which it is carried out.
+type Retention struct {
+	DeletedDays int `yaml:"deletedDays"`
+	ErasureDays int `yaml:"erasureDays"`
+}
+
+// DefaultRetention fills any retention period left unset.
+var DefaultRetention = Retention{
+	DeletedDays: 30,
+	ErasureDays: 7,
+}
+
+// OrDefaults returns r with unset periods taken from DefaultRetention.
+func (r Retention) OrDefaults() Retention {
+	if r.DeletedDays <= 0 {
+		r.DeletedDays = DefaultRetention.DeletedDays
+	}
+	if r.ErasureDays <= 0 {
+		r.ErasureDays = DefaultRetention.ErasureDays
+	}
+	return r
+}
+
+// Database holds the connection pool and query limits. Connections are
+// closed after ConnMaxLifetimeMinutes; a statement running longer than
+// StatementTimeoutSeconds is cancelled by the server, and one slower than
+// SlowQueryMilliseconds is logged. A negative timeout or threshold turns it
+// off. The DB_* environment variables override these; see util.InitDB.
+type Database struct {
+	MaxOpenConns            int     `yaml:"maxOpenConns" json:"maxOpenConns"`
+	MaxIdleConns            int     `yaml:"maxIdleConns" json:"maxIdleConns"`
+	ConnMaxLifetimeMinutes  float64 `yaml:"connMaxLifetimeMinutes" json:"connMaxLifetimeMinutes"`
+	StatementTimeoutSeconds float64 `yaml:"statementTimeoutSeconds" json:"statementTimeoutSeconds"`
+	SlowQueryMilliseconds   float64 `yaml:"slowQueryMilliseconds" json:"slowQueryMilliseconds"`
+}
+
+// DefaultDatabase fills any database setting left unset.
+var DefaultDatabase = Database{
+	MaxOpenConns:            25,
+	MaxIdleConns:            10,
+	ConnMaxLifetimeMinutes:  30,
+	StatementTimeoutSeconds: 30,
+	SlowQueryMilliseconds:   200,
+}
+
+// OrDefaults returns d with unset settings taken from DefaultDatabase.
+func (d Database) OrDefaults() Database {
+	if d.MaxOpenConns == 0 {
+		d.MaxOpenConns = DefaultDatabase.MaxOpenConns
+	}
+	if d.MaxIdleConns == 0 {
+		d.MaxIdleConns = DefaultDatabase.MaxIdleConns
+	}
+	if d.ConnMaxLifetimeMinutes == 0 {
+		d.ConnMaxLifetimeMinutes = DefaultDatabase.ConnMaxLifetimeMinutes
+	}
+	if d.StatementTimeoutSeconds == 0 {
+		d.StatementTimeoutSeconds = DefaultDatabase.StatementTimeoutSeconds
+	}
+	if d.SlowQueryMilliseconds == 0 {
+		d.SlowQueryMilliseconds = DefaultDatabase.SlowQueryMilliseconds
+	}
+	return d
+}
+
+// Validate reports settings the pool cannot run with. The server checks
+// them at startup rather than letting database/sql quietly adjust them.
+func (d Database) Validate() error {
+	switch {
+	case d.MaxOpenConns < 1:
+		return fmt.Errorf("database.maxOpenConns must be at least 1, got %d", d.MaxOpenConns)
+	case d.MaxIdleConns < 0:
+		return fmt.Errorf("database.maxIdleConns must not be negative, got %d", d.MaxIdleConns)
+	case d.MaxIdleConns > d.MaxOpenConns:
+		return fmt.Errorf("database.maxIdleConns (%d) must not exceed maxOpenConns (%d)", d.MaxIdleConns, d.MaxOpenConns)
+	case d.ConnMaxLifetimeMinutes < 0:
+		return fmt.Errorf("database.connMaxLifetimeMinutes must not be negative, got %g", d.ConnMaxLifetimeMinutes)
+	case d.StatementTimeoutSeconds > 0 && d.StatementTimeoutSeconds < 0.001:
+		return fmt.Errorf("database.statementTimeoutSeconds must be at least a millisecond, got %g", d.StatementTimeoutSeconds)
+	}
+	return nil
+}
+
+// ConnMaxLifetime returns how long a connection may be reused.
+func (d Database) ConnMaxLifetime() time.Duration {
+	return time.Duration(d.ConnMaxLifetimeMinutes * float64(time.Minute))
+}
+
+// StatementTimeout returns the server-side statement timeout, or 0 for none.
+func (d Database) StatementTimeout() time.Duration {
+	if d.StatementTimeoutSeconds < 0 {
+		return 0
+	}
+	return time.Duration(d.StatementTimeoutSeconds * float64(time.Second))
+}
+
+// SlowQueryThreshold returns how slow a query must be to be logged, or 0
+// to log none.
+func (d Database) SlowQueryThreshold() time.Duration {
+	if d.SlowQueryMilliseconds < 0 {
+		return 0
+	}
+	return time.Duration(d.SlowQueryMilliseconds * float64(time.Millisecond))
+}
+
+// Moderation holds the rules for reported content. Content whose reports
+// weigh HideWeight or more is hidden until a moderator reviews it, and
+// validators scoring ValidatorMinScore or more can see the moderation queue.
+type Moderation struct {
+	HideWeight        float64 `yaml:"hideWeight"`
+	ValidatorMinScore float64 `yaml:"validatorMinScore"`
+}
+
+// DefaultModeration fills any moderation rule left unset.
+var DefaultModeration = Moderation{
+	HideWeight:        5,
+	ValidatorMinScore: 70,
+}
+
+// OrDefaults returns m with unset rules taken from DefaultModeration.
+func (m Moderation) OrDefaults() Moderation {
+	if m.HideWeight <= 0 {
+		m.HideWeight = DefaultModeration.HideWeight
+	}
+	if m.ValidatorMinScore <= 0 {
+		m.ValidatorMinScore = DefaultModeration.ValidatorMinScore
+	}
+	return m
+}
+
+// ContentSafety holds the rules for prohibited content in market questions
+// and descriptions and prediction reasoning. Actions says, per category,
+// whether content found in it is blocked or flagged for the council and
+// moderators; Patterns adds regular expressions to a category's built-in
+// ones. If ClassifierURL is set, an external classifier is asked as well,
+// and a category it scores at ClassifierThreshold or more counts as found.
+type ContentSafety struct {
+	Actions             map[string]string   `yaml:"actions"`
+	Patterns            map[string][]string `yaml:"patterns"`
+	ClassifierURL       string              `yaml:"classifierUrl"`
+	ClassifierThreshold float64             `yaml:"classifierThreshold"`
+}
+
+// DefaultContentSafetyActions apply to categories without a configured
+// action.
+var DefaultContentSafetyActions = map[string]string{
+	"violence":      "block",
+	"personal_data": "block",
+	"harassment":    "flag",
+}
+
+// DefaultClassifierThreshold is the classifier score that counts when
+// ClassifierThreshold is unset.
+const DefaultClassifierThreshold = 0.8
+
+// ActionFor returns the action for category: block, flag or off.
+func (c ContentSafety) ActionFor(category string) string {
+	if action, ok := c.Actions[category]; ok {
+		return action
+	}
+	if action, ok := DefaultContentSafetyActions[category]; ok {
+		return action
+	}
+	return "flag"
+}
+
+// Threshold returns the classifier score that counts as a finding.
+func (c ContentSafety) Threshold() float64 {
+	if c.ClassifierThreshold <= 0 || c.ClassifierThreshold > 1 {
+		return DefaultClassifierThreshold
+	}
+	return c.ClassifierThreshold
+}
+
+// PrivateMarkets holds the rules for markets that are not public. Market
+// submissions whose visibility is in CouncilBypass skip the council once
+// they pass auto-verification, provided the submitter's composite score is
+// at least BypassMinScore. No visibility bypasses the council unless listed.
+// An invite-only market can invite at most MaxInvitations agents.
+type PrivateMarkets struct {
+	CouncilBypass  []string `yaml:"councilBypass"`
+	BypassMinScore float64  `yaml:"bypassMinScore"`
+	MaxInvitations int      `yaml:"maxInvitations"`
+}
+
+// DefaultPrivateMarkets fills any private market rule left unset.
+var DefaultPrivateMarkets = PrivateMarkets{
+	MaxInvitations: 100,
+}
+
+// OrDefaults returns p with unset rules taken from DefaultPrivateMarkets.
+func (p PrivateMarkets) OrDefaults() PrivateMarkets {
+	if p.MaxInvitations <= 0 {
+		p.MaxInvitations = DefaultPrivateMarkets.MaxInvitations
+	}
+	return p
+}
+
+// BypassesCouncil reports whether a market submission with visibility from
+// a submitter scoring compositeScore skips the council.
+func (p PrivateMarkets) BypassesCouncil(visibility string, compositeScore float64) bool {
+	if compositeScore < p.BypassMinScore {
+		return false
+	}
+	for _, v := range p.CouncilBypass {
+		if v == visibility {
+			return true
+		}
+	}
+	return false
+}
+
+// MarketTypePolicy holds the rules for markets of one market type. Daily
+// and realtime markets use them to run on a shorter clock than standard
+// markets. A zero field leaves the standard rule in place.
+type MarketTypePolicy struct {
+	// Replaces economics.marketCreation.minimumFutureHours
+	MinimumFutureHours float64 `yaml:"minimumFutureHours"`
+	// Longest a market may stay open, from creation to resolution
+	MaxDurationHours float64 `yaml:"maxDurationHours"`
+	// Predictions lock at least this long before the resolution time
+	LockMinutes float64 `yaml:"lockMinutes"`
+	// A market still unresolved this long after its resolution time, and
+	// not before the council, is resolved N/A
+	ExpireAfterHours float64 `yaml:"expireAfterHours"`
+}
+
+// MarketTypes holds the policy for each market type ("standard", "daily",
+// "realtime").
+type MarketTypes struct {
+	Policies map[string]MarketTypePolicy `yaml:"policies"`
+}
+
+// DefaultMarketTypePolicies apply to market types without a configured
+// policy.
+var DefaultMarketTypePolicies = map[string]MarketTypePolicy{
+	"daily":    {MaxDurationHours: 48, LockMinutes: 60, ExpireAfterHours: 24},
+	"realtime": {MinimumFutureHours: 0.5, MaxDurationHours: 6, LockMinutes: 5, ExpireAfterHours: 2},
+}
+
+// PolicyFor returns the policy for marketType.
+func (m MarketTypes) PolicyFor(marketType string) MarketTypePolicy {
+	if policy, ok := m.Policies[marketType]; ok {
+		return policy
+	}
+	return DefaultMarketTypePolicies[marketType]
+}
+
 type EconomicConfig struct {
-	Economics Economics `yaml:"economics"`
-	Frontend  Frontend  `yaml:"frontend"`
+	Economics      Economics      `yaml:"economics"`
+	Council        Council        `yaml:"council"`
+	Verification   Verification   `yaml:"verification"`
+	Governance     Governance     `yaml:"governance"`
+	Predictions    Predictions    `yaml:"predictions"`
+	Disputes       Disputes       `yaml:"disputes"`
+	Retention      Retention      `yaml:"retention"`
+	Database       Database       `yaml:"database"`
+	Moderation     Moderation     `yaml:"moderation"`
+	ContentSafety  ContentSafety  `yaml:"contentSafety"`
+	PrivateMarkets PrivateMarkets `yaml:"privateMarkets"`
+	MarketTypes    MarketTypes    `yaml:"marketTypes"`
+	Frontend       Frontend       `yaml:"frontend"`
 }
 
 var economicConfig *EconomicConfig
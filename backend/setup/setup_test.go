@@ -1,6 +1,9 @@
 package setup
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestLoadEconomicsConfigSingleton(t *testing.T) {
 	cfg1, err := LoadEconomicsConfig()
@@ -55,3 +58,30 @@ func TestChartSigFigsClamping(t *testing.T) {
 		t.Fatalf("expected unclamped value 6, got %d", got)
 	}
 }
+
+func TestCouncilPolicyFor(t *testing.T) {
+	cfg, err := LoadEconomicsConfig()
+	if err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	market := cfg.Council.PolicyFor("market")
+	if market.VotesRequired != 3 || market.MinVoters != 1 || market.ApprovalThreshold != 67.0 || market.VotingDuration() != 24*time.Hour {
+		t.Fatalf("unexpected market policy %+v", market)
+	}
+
+	if got := cfg.Council.PolicyFor("unknown"); got != DefaultCouncilPolicy {
+		t.Fatalf("expected default policy for unknown type, got %+v", got)
+	}
+
+	partial := Council{Policies: map[string]CouncilPolicy{"resolution": {VotesRequired: 7}}}
+	got := partial.PolicyFor("resolution")
+	if got.VotesRequired != 7 || got.ApprovalThreshold != DefaultCouncilPolicy.ApprovalThreshold || got.VotingHours != DefaultCouncilPolicy.VotingHours {
+		t.Fatalf("expected unset fields to fall back to defaults, got %+v", got)
+	}
+
+	capped := Council{Policies: map[string]CouncilPolicy{"prediction": {VotesRequired: 2, MinVoters: 4}}}
+	if got := capped.PolicyFor("prediction"); got.MinVoters != 2 {
+		t.Fatalf("expected minVoters capped at votesRequired, got %d", got.MinVoters)
+	}
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"socialpredict/setup"
+)
+
+// dbConfig is the database configuration InitDB applied.
+var dbConfig setup.Database
+
+// DBConfig returns the database configuration in effect.
+func DBConfig() setup.Database {
+	return dbConfig
+}
+
+// dbConfigEnv maps each environment variable to the setting it overrides.
+var dbConfigEnv = []struct {
+	name  string
+	apply func(d *setup.Database, value string) error
+}{
+	{"DB_MAX_OPEN_CONNS", func(d *setup.Database, v string) error { return parseInt(v, &d.MaxOpenConns) }},
+	{"DB_MAX_IDLE_CONNS", func(d *setup.Database, v string) error { return parseInt(v, &d.MaxIdleConns) }},
+	{"DB_CONN_MAX_LIFETIME_MINUTES", func(d *setup.Database, v string) error { return parseFloat(v, &d.ConnMaxLifetimeMinutes) }},
+	{"DB_STATEMENT_TIMEOUT_SECONDS", func(d *setup.Database, v string) error { return parseFloat(v, &d.StatementTimeoutSeconds) }},
+	{"DB_SLOW_QUERY_MS", func(d *setup.Database, v string) error { return parseFloat(v, &d.SlowQueryMilliseconds) }},
+}
+
+func parseInt(value string, into *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*into = n
+	return nil
+}
+
+func parseFloat(value string, into *float64) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*into = f
+	return nil
+}
+
+// LoadDBConfig returns the database section of setup.yaml with any DB_*
+// environment overrides applied, then validated. An override that is not
+// a number is an error rather than being ignored.
+func LoadDBConfig(base setup.Database, getenv func(string) string) (setup.Database, error) {
+	config := base
+	for _, env := range dbConfigEnv {
+		value := getenv(env.name)
+		if value == "" {
+			continue
+		}
+		if err := env.apply(&config, value); err != nil {
+			return setup.Database{}, fmt.Errorf("%s: %q is not a number", env.name, value)
+		}
+	}
+	config = config.OrDefaults()
+	if err := config.Validate(); err != nil {
+		return setup.Database{}, err
+	}
+	return config, nil
+}
+
+// envDBConfig is LoadDBConfig over the process environment.
+func envDBConfig() (setup.Database, error) {
+	var base setup.Database
+	if cfg := setup.EconomicsConfig(); cfg != nil {
+		base = cfg.Database
+	}
+	return LoadDBConfig(base, os.Getenv)
+}
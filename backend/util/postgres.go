@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
+
+	"socialpredict/models"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
@@ -14,7 +18,16 @@ var err error
 
 // InitDB initializes the database connection.
 // It supports both canonical POSTGRES_* variables and legacy DB_* fallbacks.
+// The pool and query limits come from setup.yaml's database section and the
+// DB_* overrides; invalid settings stop the server before it connects.
+// DB_REPLICA_HOSTS lists read replicas, reached with the same credentials,
+// for the read-heavy endpoints; see GetReadDB.
 func InitDB() {
+	config, err := envDBConfig()
+	if err != nil {
+		log.Fatalf("Invalid database configuration: %v", err)
+	}
+
 	dbHost := os.Getenv("DB_HOST")
 	if dbHost == "" {
 		dbHost = os.Getenv("DBHOST")
@@ -49,15 +62,62 @@ func InitDB() {
 		dbPort = "5432"
 	}
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
-		dbHost, dbUser, dbPassword, dbName, dbPort)
+	dsn := func(host, port string) string {
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
+			host, dbUser, dbPassword, dbName, port)
+		if timeout := config.StatementTimeout(); timeout > 0 {
+			dsn += fmt.Sprintf(" statement_timeout=%d", timeout.Milliseconds())
+		}
+		return dsn
+	}
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	replicas, err := replicaHosts(os.Getenv("DB_REPLICA_HOSTS"), dbPort)
+	if err != nil {
+		log.Fatalf("Invalid database configuration: %v", err)
+	}
+
+	DB, err = gorm.Open(postgres.Open(dsn(dbHost, dbPort)), &gorm.Config{Logger: slowQueryLogger(config.SlowQueryThreshold())})
 	if err != nil {
 		log.Fatalf("Error opening database: %v", err)
 	}
 
+	sqlDB, err := DB.DB()
+	if err != nil {
+		log.Fatalf("Error reaching database pool: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime())
+	dbConfig = config
+	log.Printf("Database pool: %d open, %d idle, %s lifetime; statement timeout %s, slow query threshold %s",
+		config.MaxOpenConns, config.MaxIdleConns, config.ConnMaxLifetime(), config.StatementTimeout(), config.SlowQueryThreshold())
+
+	if err := models.RegisterOptimisticLocking(DB); err != nil {
+		log.Fatalf("Error registering optimistic locking: %v", err)
+	}
+
 	log.Println("Successfully connected to the database.")
+
+	if len(replicas) > 0 {
+		dsns := make([]string, len(replicas))
+		for i, replica := range replicas {
+			dsns[i] = dsn(replica[0], replica[1])
+		}
+		if ReadDB, err = openReadDB(DB, dsns, config); err != nil {
+			log.Fatalf("Error connecting to read replicas: %v", err)
+		}
+		log.Printf("Routing read-heavy endpoints to %d read replica(s).", len(replicas))
+	}
+}
+
+// slowQueryLogger is GORM's default logger with the given slow query
+// threshold; 0 logs no slow queries.
+func slowQueryLogger(threshold time.Duration) logger.Interface {
+	return logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold: threshold,
+		LogLevel:      logger.Warn,
+		Colorful:      true,
+	})
 }
 
 // GetDB returns the database connection
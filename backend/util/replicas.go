@@ -0,0 +1,83 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"socialpredict/models"
+	"socialpredict/setup"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ReadDB is the connection read-heavy handlers use. With replicas
+// configured it sends their queries to a replica and anything that writes to
+// the primary; without, it is DB.
+var ReadDB *gorm.DB
+
+// GetReadDB returns the connection for read-heavy handlers, falling back to
+// the primary when no replicas are configured. A replica may lag the primary
+// slightly, so handlers that must see their own writes use GetDB.
+func GetReadDB() *gorm.DB {
+	if ReadDB != nil {
+		return ReadDB
+	}
+	return DB
+}
+
+// replicaHosts parses DB_REPLICA_HOSTS, a comma-separated list of host or
+// host:port entries. Entries without a port use defaultPort.
+func replicaHosts(value, defaultPort string) ([][2]string, error) {
+	var hosts [][2]string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, port := entry, defaultPort
+		if strings.Contains(entry, ":") {
+			var err error
+			if host, port, err = net.SplitHostPort(entry); err != nil {
+				return nil, fmt.Errorf("DB_REPLICA_HOSTS: %q is not host or host:port", entry)
+			}
+		}
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("DB_REPLICA_HOSTS: %q is not host or host:port", entry)
+		}
+		hosts = append(hosts, [2]string{host, port})
+	}
+	return hosts, nil
+}
+
+// openReadDB returns a connection that shares primary's pool for writes and
+// sends reads to the replicas at dsns, chosen at random per query. The
+// replicas get the same pool settings as the primary.
+func openReadDB(primary *gorm.DB, dsns []string, config setup.Database) (*gorm.DB, error) {
+	sqlDB, err := primary.DB()
+	if err != nil {
+		return nil, err
+	}
+	readDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: primary.Logger})
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := make([]gorm.Dialector, len(dsns))
+	for i, dsn := range dsns {
+		replicas[i] = postgres.Open(dsn)
+	}
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: replicas, Policy: dbresolver.RandomPolicy{}}).
+		SetMaxOpenConns(config.MaxOpenConns).
+		SetMaxIdleConns(config.MaxIdleConns).
+		SetConnMaxLifetime(config.ConnMaxLifetime())
+	if err := readDB.Use(resolver); err != nil {
+		return nil, err
+	}
+	if err := models.RegisterOptimisticLocking(readDB); err != nil {
+		return nil, err
+	}
+	return readDB, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/brianvoe/gofakeit"
 	"socialpredict/models/modelstesting"
+	"socialpredict/setup"
 )
 
 func TestGenerateUniqueApiKey(t *testing.T) {
@@ -159,3 +160,67 @@ func TestGetEnvMissingFile(t *testing.T) {
 		t.Fatalf("expected env var to remain empty, got %q", got)
 	}
 }
+
+func TestLoadDBConfig_EnvOverridesAndValidation(t *testing.T) {
+	env := map[string]string{
+		"DB_MAX_OPEN_CONNS":            "40",
+		"DB_STATEMENT_TIMEOUT_SECONDS": "-1",
+	}
+	getenv := func(name string) string { return env[name] }
+
+	config, err := LoadDBConfig(setup.Database{MaxIdleConns: 5}, getenv)
+	if err != nil {
+		t.Fatalf("LoadDBConfig: %v", err)
+	}
+	if config.MaxOpenConns != 40 || config.MaxIdleConns != 5 {
+		t.Fatalf("expected 40 open and 5 idle, got %d and %d", config.MaxOpenConns, config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetimeMinutes != setup.DefaultDatabase.ConnMaxLifetimeMinutes {
+		t.Fatalf("expected the default lifetime, got %g", config.ConnMaxLifetimeMinutes)
+	}
+	if config.StatementTimeout() != 0 {
+		t.Fatalf("expected a negative timeout to turn it off, got %s", config.StatementTimeout())
+	}
+
+	env["DB_MAX_IDLE_CONNS"] = "50"
+	if _, err := LoadDBConfig(setup.Database{}, getenv); err == nil {
+		t.Fatalf("expected more idle than open connections to be rejected")
+	}
+
+	env["DB_MAX_IDLE_CONNS"] = "lots"
+	if _, err := LoadDBConfig(setup.Database{}, getenv); err == nil {
+		t.Fatalf("expected a non-numeric override to be rejected")
+	}
+}
+
+func TestReplicaHosts(t *testing.T) {
+	hosts, err := replicaHosts(" replica-a , replica-b:6543,", "5432")
+	if err != nil {
+		t.Fatalf("replicaHosts: %v", err)
+	}
+	if len(hosts) != 2 || hosts[0] != [2]string{"replica-a", "5432"} || hosts[1] != [2]string{"replica-b", "6543"} {
+		t.Fatalf("unexpected hosts %v", hosts)
+	}
+
+	if hosts, err := replicaHosts("", "5432"); err != nil || len(hosts) != 0 {
+		t.Fatalf("expected no replicas, got %v, %v", hosts, err)
+	}
+	if _, err := replicaHosts("replica-a:", "5432"); err == nil {
+		t.Fatalf("expected an empty port to be rejected")
+	}
+}
+
+func TestGetReadDB_FallsBackToPrimary(t *testing.T) {
+	origDB, origRead := DB, ReadDB
+	t.Cleanup(func() { DB, ReadDB = origDB, origRead })
+
+	DB = modelstesting.NewFakeDB(t)
+	ReadDB = nil
+	if GetReadDB() != DB {
+		t.Fatalf("expected the primary without replicas")
+	}
+	ReadDB = modelstesting.NewFakeDB(t)
+	if GetReadDB() != ReadDB {
+		t.Fatalf("expected the replica connection once configured")
+	}
+}
@@ -0,0 +1,169 @@
+// Package validation is the shared request validation layer. Request DTOs
+// declare their rules with `validate` struct tags; handlers call Decode and,
+// on failure, reply with errors.WriteValidationError.
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"socialpredict/errors"
+	"socialpredict/security"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Normalizer is implemented by DTOs that clean up their fields (trimming,
+// case folding) after decoding and before validation.
+type Normalizer interface {
+	Normalize()
+}
+
+var validate = newValidate()
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	security.RegisterValidations(v)
+	v.RegisterTagNameFunc(jsonFieldName)
+	return v
+}
+
+// jsonFieldName reports fields by their JSON name so errors match the
+// request body the client sent.
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Decode reads the JSON body of r into dst, normalizes it and validates it.
+// It returns nil when the request is valid.
+func Decode(r *http.Request, dst interface{}) []errors.FieldError {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		return []errors.FieldError{{Field: "body", Rule: "json", Message: "request body is not valid JSON: " + err.Error()}}
+	}
+	return Struct(dst)
+}
+
+// Struct normalizes and validates an already decoded DTO.
+func Struct(dst interface{}) []errors.FieldError {
+	if n, ok := dst.(Normalizer); ok {
+		n.Normalize()
+	}
+
+	err := validate.Struct(dst)
+	if err == nil {
+		return nil
+	}
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []errors.FieldError{{Field: "body", Rule: "invalid", Message: err.Error()}}
+	}
+
+	fields := make([]errors.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, errors.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return fields
+}
+
+// message renders a validator failure as a sentence about the field.
+func message(fe validator.FieldError) string {
+	field := fe.Field()
+	numeric := isNumeric(fe.Kind())
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min", "gte":
+		if numeric {
+			return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s characters", field, fe.Param())
+	case "max", "lte":
+		if numeric {
+			return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+		}
+		return fmt.Sprintf("%s cannot exceed %s characters", field, fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "ltefield":
+		return fmt.Sprintf("%s must be at most %s", field, lowerFirst(fe.Param()))
+	case "gtefield":
+		return fmt.Sprintf("%s must be at least %s", field, lowerFirst(fe.Param()))
+	case "required_if":
+		if name, value, ok := strings.Cut(fe.Param(), " "); ok {
+			return fmt.Sprintf("%s is required when %s is %s", field, lowerFirst(name), value)
+		}
+		return fmt.Sprintf("%s is required", field)
+	case "required_with":
+		return fmt.Sprintf("%s is required with %s", field, lowerFirst(fe.Param()))
+	case "required_without":
+		return fmt.Sprintf("%s is required unless %s is given", field, lowerFirst(fe.Param()))
+	case "excluded_with":
+		return fmt.Sprintf("%s cannot be given with %s", field, lowerFirst(fe.Param()))
+	case "market_outcome":
+		return fmt.Sprintf("%s must be either 'YES' or 'NO'", field)
+	case "safe_string":
+		return fmt.Sprintf("%s contains potentially dangerous content", field)
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
+
+// lowerFirst turns the Go field name a cross-field rule names into its
+// JSON spelling.
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// Describe reports the rules declared on a request DTO, mapping each JSON
+// field to its validate tag, so clients can check a request before sending
+// it. Fields without rules are left out.
+func Describe(dto interface{}) map[string]string {
+	t := reflect.TypeOf(dto)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	rules := make(map[string]string)
+	if t.Kind() != reflect.Struct {
+		return rules
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("validate")
+		name := jsonFieldName(field)
+		if tag == "" || tag == "-" || name == "" || !field.IsExported() {
+			continue
+		}
+		rules[name] = tag
+	}
+	return rules
+}
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testRequest struct {
+	Name       string  `json:"name" validate:"required,min=3"`
+	Outcome    string  `json:"outcome" validate:"required,market_outcome"`
+	Confidence float64 `json:"confidence" validate:"omitempty,gt=0,lte=100"`
+}
+
+func (r *testRequest) Normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+}
+
+func TestDecode_ReportsFieldsByJSONName(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"  ab  ","outcome":"maybe","confidence":150}`))
+
+	var dst testRequest
+	fields := Decode(req, &dst)
+	if len(fields) != 3 {
+		t.Fatalf("expected 3 field errors, got %+v", fields)
+	}
+
+	got := map[string]string{}
+	for _, f := range fields {
+		got[f.Field] = f.Rule
+	}
+	want := map[string]string{"name": "min", "outcome": "market_outcome", "confidence": "lte"}
+	for field, rule := range want {
+		if got[field] != rule {
+			t.Errorf("expected %s to fail %s, got %q", field, rule, got[field])
+		}
+	}
+}
+
+func TestDecode_AcceptsValidRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"oracle","outcome":"yes"}`))
+
+	var dst testRequest
+	if fields := Decode(req, &dst); fields != nil {
+		t.Fatalf("expected no errors, got %+v", fields)
+	}
+}
+
+func TestDecode_RejectsMalformedJSON(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":`))
+
+	var dst testRequest
+	fields := Decode(req, &dst)
+	if len(fields) != 1 || fields[0].Field != "body" {
+		t.Fatalf("expected a single body error, got %+v", fields)
+	}
+}
@@ -1,4 +1,5 @@
 import { API_URL } from '../config';
+import { readApiError } from '../utils/apiError';
 
 /**
  * Search markets by query and status
@@ -21,8 +22,8 @@ export const searchMarkets = async (query, status = 'all', limit = 20) => {
     const response = await fetch(`${API_URL}/v0/markets/search?${params}`);
 
     if (!response.ok) {
-        const errorText = await response.text();
-        throw new Error(`Search failed: ${response.status} ${errorText}`);
+        const { message } = await readApiError(response);
+        throw new Error(`Search failed: ${response.status} ${message}`);
     }
 
     return await response.json();
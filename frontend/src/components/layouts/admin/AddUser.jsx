@@ -2,6 +2,7 @@ import { API_URL, DOMAIN_URL } from '../../../config';
 import React, { useState } from 'react';
 import SiteButton from '../../buttons/SiteButtons';
 import { RegularInput } from '../../inputs/InputBar'
+import { readApiError } from '../../../utils/apiError';
 
 function AdminAddUser() {
     const [username, setUsername] = useState('');
@@ -27,7 +28,7 @@ function AdminAddUser() {
                 body: JSON.stringify({ username })
             });
             if (!response.ok) {
-                const errMessage = await response.text()
+                const { message: errMessage } = await readApiError(response);
                 throw new Error(`HTTP error! Status: ${response.status} Reason: ${errMessage}`);
             }
             const data = await response.json();
@@ -4,6 +4,7 @@ import { useHistory } from 'react-router-dom';
 import SiteButton from '../../buttons/SiteButtons';
 import { RegularInput } from '../../inputs/InputBar';
 import { AuthContext } from '../../../helpers/AuthContent';
+import { readApiError } from '../../../utils/apiError';
 
 function ChangePasswordLayout() {
     const [currentPassword, setCurrentPassword] = useState('');
@@ -46,10 +47,9 @@ function ChangePasswordLayout() {
                 body: JSON.stringify({ currentPassword, newPassword })
             });
             if (!response.ok) {
-                // NOTE: The backend returns error messages in plain text
-                // so we read it as text and capitalize the first character
-                // before displaying it to the user. /RR
-                const errMsg = capitalizeFirstChar(await response.text());
+                // Capitalize the first character of the envelope's message
+                // before displaying it to the user.
+                const errMsg = capitalizeFirstChar((await readApiError(response)).message);
                 throw new Error(errMsg || 'Failed to change password');
             }
 
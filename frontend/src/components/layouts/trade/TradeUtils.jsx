@@ -1,5 +1,6 @@
 // import API_URL from your config
 import { API_URL } from '../../../config';
+import { readApiError } from '../../../utils/apiError';
 import React, { useState, useEffect } from 'react';
 
 export const submitBet = (betData, token, onSuccess, onError) => {
@@ -38,15 +39,8 @@ export const submitBet = (betData, token, onSuccess, onError) => {
     .then(response => {
         if (!response.ok) {
             // Try to get error message from response body
-            return response.text().then(text => {
-                let errorMessage;
-                try {
-                    const errorData = JSON.parse(text);
-                    errorMessage = errorData.message || errorData.error || text;
-                } catch {
-                    errorMessage = text || `HTTP ${response.status}: ${response.statusText}`;
-                }
-                throw new Error(`Bet failed (${response.status}): ${errorMessage}`);
+            return readApiError(response).then(err => {
+                throw new Error(`Bet failed (${response.status}): ${err.message}`);
             });
         }
         return response.json();
@@ -110,15 +104,8 @@ export const submitSale = (saleData, token, onSuccess, onError) => {
     .then(response => {
         if (!response.ok) {
             // Try to get error message from response body
-            return response.text().then(text => {
-                let errorMessage;
-                try {
-                    const errorData = JSON.parse(text);
-                    errorMessage = errorData.message || errorData.error || text;
-                } catch {
-                    errorMessage = text || `HTTP ${response.status}: ${response.statusText}`;
-                }
-                throw new Error(`Sale failed (${response.status}): ${errorMessage}`);
+            return readApiError(response).then(err => {
+                throw new Error(`Sale failed (${response.status}): ${err.message}`);
             });
         }
         return response.json();
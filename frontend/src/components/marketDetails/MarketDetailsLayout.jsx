@@ -1,5 +1,6 @@
 import React, { useState } from 'react';
 import ResolutionAlert from '../resolutions/ResolutionAlert';
+import ResolutionCriteria from './ResolutionCriteria';
 import MarketChart from '../charts/MarketChart';
 import ActivityTabs from '../../components/tabs/ActivityTabs';
 import ResolveModalButton from '../modals/resolution/ResolveModal';
@@ -106,6 +107,8 @@ function MarketDetailsTable({
         </p>
       </div>
 
+      <ResolutionCriteria criteria={market.resolutionCriteria} />
+
       <div className='grid grid-cols-2 sm:grid-cols-4 gap-2 text-center mb-4'>
         {[
           { label: 'Users', value: `${numUsers}`, icon: '👤' },
@@ -0,0 +1,49 @@
+import React from 'react';
+
+// Only http(s) source links are rendered as links; anything else is shown
+// as plain text.
+const isWebLink = (value) => /^https?:\/\//i.test(value);
+
+const ResolutionCriteria = ({ criteria }) => {
+  if (!criteria) return null;
+
+  const rows = [
+    { label: 'Source of truth', value: criteria.sourceUrl, link: true },
+    { label: 'Threshold', value: criteria.threshold },
+    { label: 'Timezone', value: criteria.timezone },
+    { label: 'Tie-breaking rule', value: criteria.tieBreaker },
+  ].filter((row) => row.value);
+
+  if (rows.length === 0) return null;
+
+  return (
+    <div className='mb-4 bg-gray-800 p-4 rounded-lg'>
+      <h2 className='text-sm font-semibold text-white mb-2'>
+        Resolution Criteria
+      </h2>
+      <dl className='grid grid-cols-1 sm:grid-cols-4 gap-x-4 gap-y-2 text-sm'>
+        {rows.map((row) => (
+          <React.Fragment key={row.label}>
+            <dt className='text-gray-400'>{row.label}</dt>
+            <dd className='sm:col-span-3 break-words'>
+              {row.link && isWebLink(row.value) ? (
+                <a
+                  href={row.value}
+                  target='_blank'
+                  rel='noopener noreferrer'
+                  className='text-blue-400 hover:text-blue-300 underline'
+                >
+                  {row.value}
+                </a>
+              ) : (
+                row.value
+              )}
+            </dd>
+          </React.Fragment>
+        ))}
+      </dl>
+    </div>
+  );
+};
+
+export default ResolutionCriteria;
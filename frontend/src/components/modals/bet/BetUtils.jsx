@@ -1,4 +1,5 @@
 import { API_URL } from '../../../config';
+import { readApiError } from '../../../utils/apiError';
 
 export const submitBet = (betData, token, onSuccess, onError) => {
 
@@ -19,9 +20,9 @@ export const submitBet = (betData, token, onSuccess, onError) => {
     })
     .then(response => {
         if (!response.ok) {
-            return response.json().then(err => {
+            return readApiError(response).then(err => {
                 // Construct a new error object and throw it
-                throw new Error(`Error placing bet: ${err.error || 'Unknown error'}`);
+                throw new Error(`Error placing bet: ${err.message || 'Unknown error'}`);
             });
         }
         return response.json();
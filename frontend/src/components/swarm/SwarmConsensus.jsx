@@ -58,6 +58,7 @@ const SwarmConsensus = ({ marketId }) => {
 
   const yesPct = (consensus.consensusProbability * 100).toFixed(1);
   const noPct = (100 - consensus.consensusProbability * 100).toFixed(1);
+  const fromBets = consensus.source === 'legacy';
 
   return (
     <div className="swarm-consensus">
@@ -86,14 +87,23 @@ const SwarmConsensus = ({ marketId }) => {
 
       {/* Stats grid */}
       <div className="swarm-stats">
-        <div className="stat">
-          <span className="label">Total Bets</span>
-          <span className="value">{consensus.totalBets}</span>
-        </div>
-        <div className="stat">
-          <span className="label">Total Wagered</span>
-          <span className="value">{consensus.totalWagered?.toLocaleString()}</span>
-        </div>
+        {fromBets ? (
+          <>
+            <div className="stat">
+              <span className="label">Total Bets</span>
+              <span className="value">{consensus.totalBets}</span>
+            </div>
+            <div className="stat">
+              <span className="label">Total Wagered</span>
+              <span className="value">{consensus.totalWagered?.toLocaleString()}</span>
+            </div>
+          </>
+        ) : (
+          <div className="stat">
+            <span className="label">Predictions</span>
+            <span className="value">{consensus.totalPredictions}</span>
+          </div>
+        )}
         <div className="stat">
           <span className="label">Avg Confidence</span>
           <span className="value">{(consensus.averageConfidence * 100).toFixed(0)}%</span>
@@ -110,12 +120,16 @@ const SwarmConsensus = ({ marketId }) => {
         <div className="breakdown-row">
           <span className="outcome yes">YES</span>
           <span className="count">{consensus.breakdown?.yesCount || 0} votes</span>
-          <span className="amount">{consensus.breakdown?.yesAmount?.toLocaleString() || 0} wagered</span>
+          {fromBets && (
+            <span className="amount">{consensus.breakdown?.yesAmount?.toLocaleString() || 0} wagered</span>
+          )}
         </div>
         <div className="breakdown-row">
           <span className="outcome no">NO</span>
           <span className="count">{consensus.breakdown?.noCount || 0} votes</span>
-          <span className="amount">{consensus.breakdown?.noAmount?.toLocaleString() || 0} wagered</span>
+          {fromBets && (
+            <span className="amount">{consensus.breakdown?.noAmount?.toLocaleString() || 0} wagered</span>
+          )}
         </div>
       </div>
 
@@ -144,9 +158,11 @@ const SwarmConsensus = ({ marketId }) => {
                   <span className="reputation">
                     ⭐ {(predictor.reputation * 100).toFixed(0)}%
                   </span>
-                  <span className="amount">
-                    {predictor.amount?.toLocaleString()}
-                  </span>
+                  {fromBets && (
+                    <span className="amount">
+                      {predictor.amount?.toLocaleString()}
+                    </span>
+                  )}
                 </div>
                 {predictor.reasoning && (
                   <div className="reasoning">
@@ -1,4 +1,5 @@
 import { API_URL } from './../config';
+import { parseApiError } from '../utils/apiError';
 import React, { createContext, useContext, useState, useEffect } from 'react';
 
 const AuthContext = createContext({
@@ -82,7 +83,7 @@ const AuthProvider = ({ children }) => {
                 return true;
             } else {
                 // Create meaningful error message based on response
-                const errorMessage = data.error || data.message || `HTTP ${response.status}: ${text}`;
+                const errorMessage = parseApiError(text).message || `HTTP ${response.status}: ${text}`;
                 throw new Error(errorMessage);
             }
         } catch (error) {
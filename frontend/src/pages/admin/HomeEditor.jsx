@@ -1,5 +1,6 @@
 import React, { useEffect, useState } from 'react';
 import {API_URL} from "../../config";
+import { readApiError } from '../../utils/apiError';
 
 function HomeEditor() {
   const [content, setContent] = useState({
@@ -66,8 +67,8 @@ function HomeEditor() {
         }));
         setMessage('Content saved successfully!');
       } else {
-        const errorText = await response.text();
-        setError('Failed to save: ' + errorText);
+        const { message } = await readApiError(response);
+        setError('Failed to save: ' + message);
       }
     } catch (err) {
       setError('Error saving content: ' + err.message);
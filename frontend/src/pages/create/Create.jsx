@@ -1,6 +1,7 @@
 import React, { useState } from 'react';
 import { useHistory } from 'react-router-dom';
 import { useAuth } from '../../helpers/AuthContent';
+import { readApiError } from '../../utils/apiError';
 import { getEndofDayDateTime } from '../../components/utils/dateTimeTools/FormDateTimeTools';
 import DatetimeSelector from '../../components/datetimeSelector/DatetimeSelector';
 import { RegularInput } from '../../components/inputs/InputBar';
@@ -89,9 +90,9 @@ function Create() {
         console.log('Market creation successful:', responseData);
         history.push(`/markets/${responseData.id}`);
       } else {
-        const errorText = await response.text();
-        console.error('Market creation failed:', errorText);
-        setError(`Market creation failed: ${errorText}`);
+        const { message } = await readApiError(response);
+        console.error('Market creation failed:', message);
+        setError(`Market creation failed: ${message}`);
       }
     } catch (error) {
       console.error('Error during market creation:', error);
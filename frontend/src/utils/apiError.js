@@ -0,0 +1,43 @@
+/**
+ * Error responses from the API use one envelope:
+ *   { "error": { "code": "ALREADY_VOTED", "message": "...", "details": ... } }
+ * The code is stable and safe to branch on; the message is for display.
+ */
+
+/**
+ * Parse an error response body, tolerating non-JSON text
+ * @param {string} text - Raw response body
+ * @returns {{code: string|null, message: string, details: any}}
+ */
+export const parseApiError = (text) => {
+  try {
+    const body = JSON.parse(text);
+    if (body && body.error && typeof body.error === 'object') {
+      return {
+        code: body.error.code || null,
+        message: body.error.message || '',
+        details: body.error.details,
+      };
+    }
+    if (body && (body.error || body.message)) {
+      return { code: null, message: body.error || body.message, details: undefined };
+    }
+  } catch {
+    // Not JSON; fall through to the raw text
+  }
+  return { code: null, message: text || '', details: undefined };
+};
+
+/**
+ * Read the error from a failed fetch Response
+ * @param {Response} response - A response with response.ok === false
+ * @returns {Promise<{code: string|null, message: string, details: any}>}
+ */
+export const readApiError = async (response) => {
+  const text = await response.text();
+  const error = parseApiError(text);
+  if (!error.message) {
+    error.message = `HTTP ${response.status}: ${response.statusText}`;
+  }
+  return error;
+};